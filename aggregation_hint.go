@@ -0,0 +1,73 @@
+package influxdb
+
+import (
+	"fmt"
+)
+
+// AggregationHint is the aggregate function and window used for the data of
+// a bucket, or of one of its measurements, when a query aggregates it
+// without naming them: by the auto-aggregation of the UI, and by InfluxQL
+// queries which group raw fields by time. It keeps counters, for instance,
+// from being averaged by default.
+type AggregationHint struct {
+	// Measurement restricts the hint to the data of a measurement. The hint
+	// without a measurement is the default of the bucket.
+	Measurement string `json:"measurement,omitempty"`
+	// Function is one of AggregationHintFunctions.
+	Function string `json:"function"`
+	// Window is the default window period. Zero leaves it to the client.
+	Window Duration `json:"window,omitempty"`
+}
+
+// AggregationHintFunctions are the aggregate functions of aggregation hints,
+// which have the same name in Flux and InfluxQL.
+var AggregationHintFunctions = []string{"mean", "median", "min", "max", "sum", "count", "first", "last", "spread"}
+
+// ValidAggregationHints returns an error if any of the aggregation hints of
+// a bucket is invalid, or if two of them apply to the same measurement.
+func ValidAggregationHints(hints []AggregationHint) error {
+	seen := make(map[string]bool, len(hints))
+	for i, h := range hints {
+		if err := h.valid(); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid aggregation hint %d", i),
+				Err:  err,
+			}
+		}
+		if seen[h.Measurement] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("duplicate aggregation hint for measurement %q", h.Measurement),
+			}
+		}
+		seen[h.Measurement] = true
+	}
+	return nil
+}
+
+func (h *AggregationHint) valid() error {
+	if !containsString(AggregationHintFunctions, h.Function) {
+		return fmt.Errorf("unknown aggregate function %q", h.Function)
+	}
+	if h.Window.Duration < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+	return nil
+}
+
+// FindAggregationHint returns the hint for the data of measurement, which is
+// the hint of the measurement if there is one and the default of the bucket
+// otherwise. It returns nil if neither exists.
+func FindAggregationHint(hints []AggregationHint, measurement string) *AggregationHint {
+	var def *AggregationHint
+	for i := range hints {
+		switch hints[i].Measurement {
+		case measurement:
+			return &hints[i]
+		case "":
+			def = &hints[i]
+		}
+	}
+	return def
+}
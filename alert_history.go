@@ -0,0 +1,176 @@
+package influxdb
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	OpFindAlertTransitions         = "FindAlertTransitions"
+	OpRecordAlertStatuses          = "RecordAlertStatuses"
+	OpDeleteAlertTransitionsBefore = "DeleteAlertTransitionsBefore"
+)
+
+// DefaultAlertHistoryRetention is how long alert transitions are kept by
+// default.
+const DefaultAlertHistoryRetention = 30 * 24 * time.Hour
+
+// AlertHistoryService keeps the history of the transitions of the levels of
+// the statuses written by checks.
+type AlertHistoryService interface {
+	// RecordAlertStatuses records a transition for each status whose level
+	// differs from the previous level of its series.
+	RecordAlertStatuses(ctx context.Context, statuses []AlertStatus) error
+
+	// FindAlertTransitions returns the transitions that match filter, in
+	// the order of their times.
+	FindAlertTransitions(ctx context.Context, filter AlertTransitionFilter) ([]*AlertTransition, error)
+
+	// DeleteAlertTransitionsBefore removes the transitions older than t.
+	DeleteAlertTransitionsBefore(ctx context.Context, t time.Time) error
+}
+
+// AlertStatus is a status written by a check for one of its series.
+type AlertStatus struct {
+	OrgID     ID
+	CheckID   ID
+	CheckName string
+	// Tags are the tags of the status, other than its level, which identify
+	// its series.
+	Tags    map[string]string
+	Level   string
+	Message string
+	Time    time.Time
+}
+
+// SeriesKey returns the key identifying the series of the status among the
+// series of its check.
+func (s AlertStatus) SeriesKey() string {
+	keys := make([]string, 0, len(s.Tags))
+	for k := range s.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(s.Tags[k])
+	}
+	return b.String()
+}
+
+// AlertTransition is a change of the level of the statuses of a series of a
+// check. A series without a previous status is considered ok.
+type AlertTransition struct {
+	ID            ID                `json:"id"`
+	OrgID         ID                `json:"orgID"`
+	CheckID       ID                `json:"checkID"`
+	CheckName     string            `json:"checkName"`
+	SeriesKey     string            `json:"seriesKey"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	PreviousLevel string            `json:"previousLevel"`
+	Level         string            `json:"level"`
+	Message       string            `json:"message,omitempty"`
+	Time          time.Time         `json:"time"`
+}
+
+// AlertTransitionFilter represents a set of filters that restrict the
+// returned alert transitions.
+type AlertTransitionFilter struct {
+	OrgID   *ID
+	CheckID *ID
+	// Start and Stop bound the times of the transitions, Start included and
+	// Stop excluded. Zero values are unbounded.
+	Start time.Time
+	Stop  time.Time
+	// Limit is the maximum number of transitions returned. Zero is no limit.
+	Limit int
+}
+
+// Match returns true if the transition matches the filter.
+func (f AlertTransitionFilter) Match(t *AlertTransition) bool {
+	return (f.OrgID == nil || *f.OrgID == t.OrgID) &&
+		(f.CheckID == nil || *f.CheckID == t.CheckID) &&
+		(f.Start.IsZero() || !t.Time.Before(f.Start)) &&
+		(f.Stop.IsZero() || t.Time.Before(f.Stop))
+}
+
+// AlertCheckStats are the analytics of the alerts of a check.
+type AlertCheckStats struct {
+	CheckID   ID     `json:"checkID"`
+	CheckName string `json:"checkName"`
+	// Alerts is the number of transitions of series from ok to another
+	// level.
+	Alerts int `json:"alerts"`
+	// Resolved is the number of alerts followed by a transition back to ok.
+	Resolved int `json:"resolved"`
+	// MTTR is the mean time from an alert to its resolution.
+	MTTR Duration `json:"mttr"`
+	// Flappiness is the share of the resolved alerts which lasted less than
+	// the flap threshold, from 0 to 1.
+	Flappiness float64 `json:"flappiness"`
+}
+
+// AnalyzeAlertTransitions returns the analytics of the checks of ts, which
+// are ordered by time, in the order of the IDs of the checks. An alert
+// resolved in less than flapThreshold counts as a flap.
+func AnalyzeAlertTransitions(ts []*AlertTransition, flapThreshold time.Duration) []AlertCheckStats {
+	type series struct {
+		checkID ID
+		key     string
+	}
+	var (
+		stats   = make(map[ID]*AlertCheckStats)
+		total   = make(map[ID]time.Duration)
+		flaps   = make(map[ID]int)
+		alertAt = make(map[series]time.Time)
+	)
+	for _, t := range ts {
+		st, ok := stats[t.CheckID]
+		if !ok {
+			st = &AlertCheckStats{CheckID: t.CheckID}
+			stats[t.CheckID] = st
+		}
+		st.CheckName = t.CheckName
+
+		s := series{checkID: t.CheckID, key: t.SeriesKey}
+		switch {
+		case t.PreviousLevel == "ok" && t.Level != "ok":
+			st.Alerts++
+			alertAt[s] = t.Time
+		case t.PreviousLevel != "ok" && t.Level == "ok":
+			start, ok := alertAt[s]
+			if !ok {
+				// The alert started before the transitions analyzed.
+				continue
+			}
+			delete(alertAt, s)
+			d := t.Time.Sub(start)
+			st.Resolved++
+			total[t.CheckID] += d
+			if d < flapThreshold {
+				flaps[t.CheckID]++
+			}
+		}
+	}
+
+	res := make([]AlertCheckStats, 0, len(stats))
+	for id, st := range stats {
+		if st.Resolved > 0 {
+			st.MTTR.Duration = total[id] / time.Duration(st.Resolved)
+			st.Flappiness = float64(flaps[id]) / float64(st.Resolved)
+		}
+		res = append(res, *st)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CheckID < res[j].CheckID
+	})
+	return res
+}
@@ -0,0 +1,61 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestAnalyzeAlertTransitions(t *testing.T) {
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+	transition := func(check influxdb.ID, key, from, to string, d time.Duration) *influxdb.AlertTransition {
+		return &influxdb.AlertTransition{
+			CheckID:       check,
+			CheckName:     "check",
+			SeriesKey:     key,
+			PreviousLevel: from,
+			Level:         to,
+			Time:          at(d),
+		}
+	}
+
+	got := influxdb.AnalyzeAlertTransitions([]*influxdb.AlertTransition{
+		// Resolved before the transitions analyzed started.
+		transition(2, "host=c", "crit", "ok", 0),
+		transition(1, "host=a", "ok", "crit", time.Minute),
+		transition(1, "host=b", "ok", "warn", 2*time.Minute),
+		// A change of level is not a new alert.
+		transition(1, "host=a", "crit", "warn", 3*time.Minute),
+		transition(1, "host=b", "warn", "ok", 3*time.Minute),
+		transition(1, "host=a", "warn", "ok", 31*time.Minute),
+		transition(1, "host=b", "ok", "crit", 40*time.Minute),
+	}, 5*time.Minute)
+
+	want := []influxdb.AlertCheckStats{
+		{
+			CheckID:    1,
+			CheckName:  "check",
+			Alerts:     3,
+			Resolved:   2,
+			MTTR:       influxdb.Duration{Duration: 15 * time.Minute},
+			Flappiness: 0.5,
+		},
+		{
+			CheckID:   2,
+			CheckName: "check",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected stats -want/+got:\n%s", diff)
+	}
+}
+
+func TestAlertStatus_SeriesKey(t *testing.T) {
+	s := influxdb.AlertStatus{Tags: map[string]string{"host": "a", "_source_measurement": "cpu"}}
+	if got, want := s.SeriesKey(), "_source_measurement=cpu,host=a"; got != want {
+		t.Fatalf("got series key %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package alerthistory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// checkInterval is how often transitions older than the retention are
+// removed.
+var checkInterval = time.Hour
+
+// Retention removes the transitions of an alert history older than a
+// retention period.
+type Retention struct {
+	log       *zap.Logger
+	history   influxdb.AlertHistoryService
+	retention time.Duration
+	now       func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRetention returns a Retention removing the transitions of history
+// older than retention.
+func NewRetention(log *zap.Logger, history influxdb.AlertHistoryService, retention time.Duration) *Retention {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Retention{
+		log:       log,
+		history:   history,
+		retention: retention,
+		now:       time.Now,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Open starts removing old transitions periodically. A retention of zero
+// keeps transitions forever.
+func (r *Retention) Open(ctx context.Context) error {
+	if r.retention <= 0 {
+		return nil
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			if err := r.history.DeleteAlertTransitionsBefore(r.ctx, r.now().Add(-r.retention)); err != nil {
+				r.log.Error("Failed to remove old alert transitions", zap.Error(err))
+			}
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops removing old transitions, and waits for a removal in progress
+// to finish.
+func (r *Retention) Close() error {
+	r.cancel()
+	r.wg.Wait()
+	return nil
+}
@@ -0,0 +1,102 @@
+// Package alerthistory records the transitions of the statuses written by
+// checks and removes them once they are older than their retention.
+package alerthistory
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	statusesMeasurement = "statuses"
+	messageField        = "_message"
+	checkIDTag          = "_check_id"
+	checkNameTag        = "_check_name"
+	levelTag            = "_level"
+)
+
+// PointsWriter writes points with another writer, and records the statuses
+// written by checks in an alert history.
+type PointsWriter struct {
+	log     *zap.Logger
+	w       storage.PointsWriter
+	history influxdb.AlertHistoryService
+}
+
+// NewPointsWriter returns a PointsWriter writing with w and recording
+// statuses in history.
+func NewPointsWriter(log *zap.Logger, w storage.PointsWriter, history influxdb.AlertHistoryService) *PointsWriter {
+	return &PointsWriter{
+		log:     log,
+		w:       w,
+		history: history,
+	}
+}
+
+// WritePoints writes points, then records the statuses among them. Failing
+// to record statuses does not fail the write.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if err := w.w.WritePoints(ctx, points); err != nil {
+		return err
+	}
+
+	statuses := Statuses(points)
+	if len(statuses) == 0 {
+		return nil
+	}
+	if err := w.history.RecordAlertStatuses(ctx, statuses); err != nil {
+		w.log.Info("Failed to record alert statuses", zap.Error(err))
+	}
+	return nil
+}
+
+// Statuses returns the statuses written by checks among points, which have
+// one field per point. A status is the point of its message field.
+func Statuses(points []models.Point) []influxdb.AlertStatus {
+	var statuses []influxdb.AlertStatus
+	for _, p := range points {
+		tags := p.Tags()
+		if string(tags.Get(models.MeasurementTagKeyBytes)) != statusesMeasurement ||
+			string(tags.Get(models.FieldKeyTagKeyBytes)) != messageField {
+			continue
+		}
+
+		var checkID influxdb.ID
+		if err := checkID.DecodeFromString(tags.GetString(checkIDTag)); err != nil {
+			continue
+		}
+		level := tags.GetString(levelTag)
+		if level == "" {
+			continue
+		}
+
+		orgID, _ := tsdb.DecodeNameSlice(p.Name())
+		st := influxdb.AlertStatus{
+			OrgID:     orgID,
+			CheckID:   checkID,
+			CheckName: tags.GetString(checkNameTag),
+			Tags:      make(map[string]string),
+			Level:     level,
+			Time:      p.Time().UTC(),
+		}
+		for _, t := range tags {
+			switch string(t.Key) {
+			case models.MeasurementTagKey, models.FieldKeyTagKey, checkIDTag, checkNameTag, levelTag:
+				continue
+			}
+			st.Tags[string(t.Key)] = string(t.Value)
+		}
+		if fields, err := p.Fields(); err == nil {
+			if msg, ok := fields[messageField].(string); ok {
+				st.Message = msg
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
@@ -0,0 +1,78 @@
+package alerthistory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/alerthistory"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+type alertHistory struct {
+	influxdb.AlertHistoryService
+	statuses []influxdb.AlertStatus
+}
+
+func (h *alertHistory) RecordAlertStatuses(ctx context.Context, statuses []influxdb.AlertStatus) error {
+	h.statuses = append(h.statuses, statuses...)
+	return nil
+}
+
+func TestPointsWriter(t *testing.T) {
+	var (
+		orgID    = influxdb.ID(1)
+		bucketID = influxdb.ID(2)
+		now      = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	points, err := tsdb.ExplodePoints(orgID, bucketID, []models.Point{
+		models.MustNewPoint("statuses",
+			models.NewTags(map[string]string{
+				"_check_id":           "000000000000000a",
+				"_check_name":         "cpu check",
+				"_level":              "crit",
+				"_source_measurement": "cpu",
+				"host":                "a",
+			}),
+			models.Fields{"_message": "cpu is high", "usage": 99.0},
+			now),
+		// Not a status.
+		models.MustNewPoint("cpu",
+			models.NewTags(map[string]string{"host": "a"}),
+			models.Fields{"usage": 99.0},
+			now),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pw := &mock.PointsWriter{}
+	history := &alertHistory{}
+	w := alerthistory.NewPointsWriter(zaptest.NewLogger(t), pw, history)
+	if err := w.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(pw.Points); got != len(points) {
+		t.Fatalf("expected %d points to be written, got %d", len(points), got)
+	}
+	want := []influxdb.AlertStatus{
+		{
+			OrgID:     orgID,
+			CheckID:   10,
+			CheckName: "cpu check",
+			Tags:      map[string]string{"_source_measurement": "cpu", "host": "a"},
+			Level:     "crit",
+			Message:   "cpu is high",
+			Time:      now,
+		},
+	}
+	if diff := cmp.Diff(want, history.statuses); diff != "" {
+		t.Fatalf("unexpected statuses -want/+got:\n%s", diff)
+	}
+}
@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // AuthorizationKind is returned by (*Authorization).Kind().
@@ -23,9 +24,62 @@ type Authorization struct {
 	OrgID       ID           `json:"orgID"`
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions"`
+	// RoleIDs are the roles of the organization whose permissions are
+	// granted to the authorization in addition to Permissions. They are
+	// resolved each time the authorization is found by its token, so that
+	// changes to the roles apply to existing authorizations.
+	RoleIDs []ID `json:"roleIDs,omitempty"`
+	// DBRP, if set, restricts the authorization to the bucket mapped to a
+	// database and retention policy, for use by legacy v1 clients.
+	DBRP *AuthorizationDBRP `json:"dbrp,omitempty"`
+	// DefaultBucketID, if set, is the bucket written to by write requests
+	// made with the authorization which omit the bucket. It takes precedence
+	// over the default bucket of the organization.
+	DefaultBucketID *ID `json:"defaultBucketID,omitempty"`
+	// LastUsedAt and LastUsedFrom record when and from which address the
+	// authorization was last used. Usage is sampled, so LastUsedAt may lag
+	// behind the actual last use.
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedFrom string     `json:"lastUsedFrom,omitempty"`
 	CRUDLog
 }
 
+// AuthorizationDBRP identifies the database and retention policy that a
+// DBRP-scoped authorization may be used with.
+type AuthorizationDBRP struct {
+	Database        string `json:"database"`
+	RetentionPolicy string `json:"retentionPolicy"`
+}
+
+// valid ensures the permissions of a DBRP-scoped authorization only grant
+// access to a single bucket.
+func (d *AuthorizationDBRP) valid(ps []Permission) error {
+	if !validName(d.Database) || !validName(d.RetentionPolicy) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "dbrp scoped authorization must specify a valid database and retention policy",
+		}
+	}
+
+	var bucketID *ID
+	for _, p := range ps {
+		if p.Resource.Type != BucketsResourceType || p.Resource.ID == nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("dbrp scoped authorization may only grant access to a single bucket; got permission %s", p),
+			}
+		}
+		if bucketID != nil && *bucketID != *p.Resource.ID {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "dbrp scoped authorization may only grant access to a single bucket",
+			}
+		}
+		bucketID = p.Resource.ID
+	}
+	return nil
+}
+
 // AuthorizationUpdate is the authorization update request.
 type AuthorizationUpdate struct {
 	Status      *Status `json:"status,omitempty"`
@@ -43,6 +97,16 @@ func (a *Authorization) Valid() error {
 		}
 	}
 
+	if a.DBRP != nil {
+		if len(a.RoleIDs) > 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "dbrp scoped authorization may not be granted roles",
+			}
+		}
+		return a.DBRP.valid(a.Permissions)
+	}
+
 	return nil
 }
 
@@ -56,6 +120,21 @@ func (a *Authorization) Allowed(p Permission) bool {
 	return PermissionAllowed(p, a.Permissions)
 }
 
+// AllowedDBRP returns true if the authorization may perform action on the
+// bucket of mapping m. An authorization scoped to a DBRP may only be used
+// through a mapping of the same database and retention policy.
+func (a *Authorization) AllowedDBRP(action Action, m *DBRPMapping) bool {
+	if a.DBRP != nil && (a.DBRP.Database != m.Database || a.DBRP.RetentionPolicy != m.RetentionPolicy) {
+		return false
+	}
+
+	p, err := NewPermissionAtID(m.BucketID, action, BucketsResourceType, m.OrganizationID)
+	if err != nil {
+		return false
+	}
+	return a.Allowed(*p)
+}
+
 // IsActive is a stub for idpe.
 func IsActive(a *Authorization) bool {
 	return a.IsActive()
@@ -71,6 +150,17 @@ func (a *Authorization) GetUserID() ID {
 	return a.UserID
 }
 
+// UnusedSince returns true if the authorization has not been used since t.
+// Authorizations that have never been used are considered unused since they
+// were created.
+func (a *Authorization) UnusedSince(t time.Time) bool {
+	last := a.CreatedAt
+	if a.LastUsedAt != nil {
+		last = *a.LastUsedAt
+	}
+	return last.Before(t)
+}
+
 // Kind returns session and is used for auditing.
 func (a *Authorization) Kind() string { return AuthorizationKind }
 
@@ -119,4 +209,15 @@ type AuthorizationFilter struct {
 
 	OrgID *ID
 	Org   *string
+
+	// UnusedSince, if set, restricts the results to authorizations that
+	// have not been used since the time.
+	UnusedSince *time.Time
+}
+
+// AuthorizationUsageService records the use of authorizations.
+type AuthorizationUsageService interface {
+	// RecordAuthorizationUsage records that the authorization was used at
+	// the time from the address.
+	RecordAuthorizationUsage(ctx context.Context, id ID, at time.Time, from string) error
 }
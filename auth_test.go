@@ -0,0 +1,103 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func TestAuthorization_ValidDBRP(t *testing.T) {
+	bucketPerm := func(action influxdb.Action, bucketID influxdb.ID) influxdb.Permission {
+		return influxdb.Permission{
+			Action: action,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: influxdbtesting.IDPtr(1),
+				ID:    &bucketID,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		dbrp        *influxdb.AuthorizationDBRP
+		permissions []influxdb.Permission
+		wantErr     bool
+	}{
+		{
+			name:        "read and write a single bucket",
+			dbrp:        &influxdb.AuthorizationDBRP{Database: "telegraf", RetentionPolicy: "autogen"},
+			permissions: []influxdb.Permission{bucketPerm(influxdb.ReadAction, 2), bucketPerm(influxdb.WriteAction, 2)},
+		},
+		{
+			name:        "multiple buckets",
+			dbrp:        &influxdb.AuthorizationDBRP{Database: "telegraf", RetentionPolicy: "autogen"},
+			permissions: []influxdb.Permission{bucketPerm(influxdb.WriteAction, 2), bucketPerm(influxdb.WriteAction, 3)},
+			wantErr:     true,
+		},
+		{
+			name: "all buckets",
+			dbrp: &influxdb.AuthorizationDBRP{Database: "telegraf", RetentionPolicy: "autogen"},
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.WriteAction,
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: influxdbtesting.IDPtr(1)},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "other resource",
+			dbrp: &influxdb.AuthorizationDBRP{Database: "telegraf", RetentionPolicy: "autogen"},
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.ReadAction,
+				Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, OrgID: influxdbtesting.IDPtr(1)},
+			}},
+			wantErr: true,
+		},
+		{
+			name:        "missing retention policy",
+			dbrp:        &influxdb.AuthorizationDBRP{Database: "telegraf"},
+			permissions: []influxdb.Permission{bucketPerm(influxdb.WriteAction, 2)},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &influxdb.Authorization{OrgID: 1, Permissions: tt.permissions, DBRP: tt.dbrp}
+			if err := a.Valid(); (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: got %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthorization_AllowedDBRP(t *testing.T) {
+	a := &influxdb.Authorization{
+		OrgID:  1,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: influxdbtesting.IDPtr(1),
+				ID:    influxdbtesting.IDPtr(2),
+			},
+		}},
+		DBRP: &influxdb.AuthorizationDBRP{Database: "telegraf", RetentionPolicy: "autogen"},
+	}
+
+	mapping := &influxdb.DBRPMapping{Database: "telegraf", RetentionPolicy: "autogen", OrganizationID: 1, BucketID: 2}
+	if !a.AllowedDBRP(influxdb.WriteAction, mapping) {
+		t.Error("expected write through mapping to be allowed")
+	}
+	if a.AllowedDBRP(influxdb.ReadAction, mapping) {
+		t.Error("expected read through mapping to be denied")
+	}
+
+	// A different mapping of the same bucket must not be usable.
+	other := &influxdb.DBRPMapping{Database: "other", RetentionPolicy: "autogen", OrganizationID: 1, BucketID: 2}
+	if a.AllowedDBRP(influxdb.WriteAction, other) {
+		t.Error("expected write through other mapping to be denied")
+	}
+}
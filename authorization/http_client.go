@@ -3,6 +3,7 @@ package authorization
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
@@ -47,6 +48,9 @@ func (s *AuthorizationClientService) FindAuthorizations(ctx context.Context, fil
 	if filter.Org != nil {
 		params = append(params, [2]string{"org", *filter.Org})
 	}
+	if filter.UnusedSince != nil {
+		params = append(params, [2]string{"unusedSince", filter.UnusedSince.Format(time.RFC3339)})
+	}
 
 	var as authsResponse
 	err := s.Client.
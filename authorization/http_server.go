@@ -95,26 +95,32 @@ func (h *AuthHandler) handlePostAuthorization(w http.ResponseWriter, r *http.Req
 }
 
 type postAuthorizationRequest struct {
-	Status      influxdb.Status       `json:"status"`
-	OrgID       influxdb.ID           `json:"orgID"`
-	UserID      *influxdb.ID          `json:"userID,omitempty"`
-	Description string                `json:"description"`
-	Permissions []influxdb.Permission `json:"permissions"`
+	Status          influxdb.Status             `json:"status"`
+	OrgID           influxdb.ID                 `json:"orgID"`
+	UserID          *influxdb.ID                `json:"userID,omitempty"`
+	Description     string                      `json:"description"`
+	Permissions     []influxdb.Permission       `json:"permissions"`
+	DBRP            *influxdb.AuthorizationDBRP `json:"dbrp,omitempty"`
+	DefaultBucketID *influxdb.ID                `json:"defaultBucketID,omitempty"`
 }
 
 type authResponse struct {
-	ID          influxdb.ID          `json:"id"`
-	Token       string               `json:"token"`
-	Status      influxdb.Status      `json:"status"`
-	Description string               `json:"description"`
-	OrgID       influxdb.ID          `json:"orgID"`
-	Org         string               `json:"org"`
-	UserID      influxdb.ID          `json:"userID"`
-	User        string               `json:"user"`
-	Permissions []permissionResponse `json:"permissions"`
-	Links       map[string]string    `json:"links"`
-	CreatedAt   time.Time            `json:"createdAt"`
-	UpdatedAt   time.Time            `json:"updatedAt"`
+	ID              influxdb.ID                 `json:"id"`
+	Token           string                      `json:"token"`
+	Status          influxdb.Status             `json:"status"`
+	Description     string                      `json:"description"`
+	OrgID           influxdb.ID                 `json:"orgID"`
+	Org             string                      `json:"org"`
+	UserID          influxdb.ID                 `json:"userID"`
+	User            string                      `json:"user"`
+	Permissions     []permissionResponse        `json:"permissions"`
+	DBRP            *influxdb.AuthorizationDBRP `json:"dbrp,omitempty"`
+	DefaultBucketID *influxdb.ID                `json:"defaultBucketID,omitempty"`
+	LastUsedAt      *time.Time                  `json:"lastUsedAt,omitempty"`
+	LastUsedFrom    string                      `json:"lastUsedFrom,omitempty"`
+	Links           map[string]string           `json:"links"`
+	CreatedAt       time.Time                   `json:"createdAt"`
+	UpdatedAt       time.Time                   `json:"updatedAt"`
 }
 
 // In the future, we would like only the service layer to look up the user and org to see if they are valid
@@ -132,15 +138,19 @@ func (h *AuthHandler) newAuthResponse(ctx context.Context, a *influxdb.Authoriza
 		return nil, err
 	}
 	res := &authResponse{
-		ID:          a.ID,
-		Token:       a.Token,
-		Status:      a.Status,
-		Description: a.Description,
-		OrgID:       a.OrgID,
-		UserID:      a.UserID,
-		User:        user.Name,
-		Org:         org.Name,
-		Permissions: ps,
+		ID:              a.ID,
+		Token:           a.Token,
+		Status:          a.Status,
+		Description:     a.Description,
+		OrgID:           a.OrgID,
+		UserID:          a.UserID,
+		User:            user.Name,
+		Org:             org.Name,
+		Permissions:     ps,
+		DBRP:            a.DBRP,
+		DefaultBucketID: a.DefaultBucketID,
+		LastUsedAt:      a.LastUsedAt,
+		LastUsedFrom:    a.LastUsedFrom,
 		Links: map[string]string{
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
@@ -153,22 +163,28 @@ func (h *AuthHandler) newAuthResponse(ctx context.Context, a *influxdb.Authoriza
 
 func (p *postAuthorizationRequest) toInfluxdb(userID influxdb.ID) *influxdb.Authorization {
 	return &influxdb.Authorization{
-		OrgID:       p.OrgID,
-		Status:      p.Status,
-		Description: p.Description,
-		Permissions: p.Permissions,
-		UserID:      userID,
+		OrgID:           p.OrgID,
+		Status:          p.Status,
+		Description:     p.Description,
+		Permissions:     p.Permissions,
+		UserID:          userID,
+		DBRP:            p.DBRP,
+		DefaultBucketID: p.DefaultBucketID,
 	}
 }
 
 func (a *authResponse) toInfluxdb() *influxdb.Authorization {
 	res := &influxdb.Authorization{
-		ID:          a.ID,
-		Token:       a.Token,
-		Status:      a.Status,
-		Description: a.Description,
-		OrgID:       a.OrgID,
-		UserID:      a.UserID,
+		ID:              a.ID,
+		Token:           a.Token,
+		Status:          a.Status,
+		Description:     a.Description,
+		OrgID:           a.OrgID,
+		UserID:          a.UserID,
+		DBRP:            a.DBRP,
+		DefaultBucketID: a.DefaultBucketID,
+		LastUsedAt:      a.LastUsedAt,
+		LastUsedFrom:    a.LastUsedFrom,
 		CRUDLog: influxdb.CRUDLog{
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
@@ -197,10 +213,12 @@ func newAuthsResponse(as []*authResponse) *authsResponse {
 
 func newPostAuthorizationRequest(a *influxdb.Authorization) (*postAuthorizationRequest, error) {
 	res := &postAuthorizationRequest{
-		OrgID:       a.OrgID,
-		Description: a.Description,
-		Permissions: a.Permissions,
-		Status:      a.Status,
+		OrgID:           a.OrgID,
+		Description:     a.Description,
+		Permissions:     a.Permissions,
+		Status:          a.Status,
+		DBRP:            a.DBRP,
+		DefaultBucketID: a.DefaultBucketID,
 	}
 
 	if a.UserID.Valid() {
@@ -420,6 +438,18 @@ func decodeGetAuthorizationsRequest(ctx context.Context, r *http.Request) (*getA
 		req.filter.ID = id
 	}
 
+	if unusedSince := qp.Get("unusedSince"); unusedSince != "" {
+		t, err := time.Parse(time.RFC3339, unusedSince)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "unusedSince must be an RFC3339 timestamp",
+				Err:  err,
+			}
+		}
+		req.filter.UnusedSince = &t
+	}
+
 	return req, nil
 }
 
@@ -10,6 +10,7 @@ import (
 )
 
 var _ influxdb.AuthorizationService = (*Service)(nil)
+var _ influxdb.AuthorizationUsageService = (*Service)(nil)
 
 type Service struct {
 	store          *Store
@@ -209,6 +210,27 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *
 	return auth, err
 }
 
+// RecordAuthorizationUsage records that the authorization was used at the
+// time from the address.
+func (s *Service) RecordAuthorizationUsage(ctx context.Context, id influxdb.ID, at time.Time, from string) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		a, err := s.store.GetAuthorizationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		// Usage may be recorded out of order by concurrent requests.
+		if a.LastUsedAt != nil && a.LastUsedAt.After(at) {
+			return nil
+		}
+		a.LastUsedAt = &at
+		a.LastUsedFrom = from
+
+		_, err = s.store.UpdateAuthorization(ctx, tx, id, a)
+		return err
+	})
+}
+
 func (s *Service) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
 	return s.store.Update(ctx, func(tx kv.Tx) (err error) {
 		return s.store.DeleteAuthorization(ctx, tx, id)
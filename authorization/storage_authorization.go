@@ -174,7 +174,7 @@ func (s *Store) ListAuthorizations(ctx context.Context, tx kv.Tx, f influxdb.Aut
 	pred := authorizationsPredicateFn(f)
 	filterFn := filterAuthorizationsFn(f)
 	err := s.forEachAuthorization(ctx, tx, pred, func(a *influxdb.Authorization) bool {
-		if filterFn(a) {
+		if filterFn(a) && (f.UnusedSince == nil || a.UnusedSince(*f.UnusedSince)) {
 			as = append(as, a)
 		}
 		return true
@@ -0,0 +1,80 @@
+package authorizer
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.AlertHistoryService = (*AlertHistoryService)(nil)
+
+// AlertHistoryService wraps a influxdb.AlertHistoryService and authorizes actions
+// against it appropriately.
+type AlertHistoryService struct {
+	s influxdb.AlertHistoryService
+}
+
+// NewAlertHistoryService constructs an instance of an authorizing alert history service.
+func NewAlertHistoryService(s influxdb.AlertHistoryService) *AlertHistoryService {
+	return &AlertHistoryService{
+		s: s,
+	}
+}
+
+// RecordAlertStatuses checks to see if the authorizer on context has write access to the checks of the statuses.
+func (s *AlertHistoryService) RecordAlertStatuses(ctx context.Context, statuses []influxdb.AlertStatus) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	for _, st := range statuses {
+		if _, _, err := AuthorizeWrite(ctx, influxdb.ChecksResourceType, st.CheckID, st.OrgID); err != nil {
+			return err
+		}
+	}
+	return s.s.RecordAlertStatuses(ctx, statuses)
+}
+
+// FindAlertTransitions retrieves all alert transitions that match the provided filter and then filters the list down to only the ones of checks that are authorized.
+func (s *AlertHistoryService) FindAlertTransitions(ctx context.Context, filter influxdb.AlertTransitionFilter) ([]*influxdb.AlertTransition, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.OrgID != nil {
+		if _, _, err := AuthorizeOrgReadResource(ctx, influxdb.ChecksResourceType, *filter.OrgID); err != nil {
+			return nil, err
+		}
+	}
+
+	ts, err := s.s.FindAlertTransitions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rts := ts[:0]
+	for _, t := range ts {
+		_, _, err := AuthorizeRead(ctx, influxdb.ChecksResourceType, t.CheckID, t.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rts = append(rts, t)
+	}
+	return rts, nil
+}
+
+// DeleteAlertTransitionsBefore checks to see if the authorizer on context has write access to all checks.
+func (s *AlertHistoryService) DeleteAlertTransitionsBefore(ctx context.Context, t time.Time) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWriteGlobal(ctx, influxdb.ChecksResourceType); err != nil {
+		return err
+	}
+	return s.s.DeleteAlertTransitionsBefore(ctx, t)
+}
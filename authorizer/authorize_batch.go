@@ -0,0 +1,63 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+// BatchAuthorizer checks the permission of the authorizer on a context to
+// perform an action on many resources of a type, such as the resources
+// returned by a find. The authorizer is retrieved from the context once,
+// unauthorized resources do not build errors, and the permission on all the
+// resources of an organization is only looked up once per organization.
+type BatchAuthorizer struct {
+	auth   influxdb.Authorizer
+	action influxdb.Action
+	rt     influxdb.ResourceType
+
+	// orgs records whether the authorizer may act on all the resources of
+	// each organization checked so far.
+	orgs map[influxdb.ID]bool
+}
+
+// NewBatchAuthorizer returns a BatchAuthorizer checking the permission of the
+// authorizer on ctx to perform action on resources of type rt.
+func NewBatchAuthorizer(ctx context.Context, action influxdb.Action, rt influxdb.ResourceType) (*BatchAuthorizer, error) {
+	p := influxdb.Permission{Action: action, Resource: influxdb.Resource{Type: rt}}
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchAuthorizer{
+		auth:   auth,
+		action: action,
+		rt:     rt,
+		orgs:   make(map[influxdb.ID]bool),
+	}, nil
+}
+
+// Allowed returns true if the authorizer may perform the action on the
+// resource identified by id in the organization orgID. It is equivalent to
+// checking the permission built by influxdb.NewPermissionAtID.
+func (b *BatchAuthorizer) Allowed(id, orgID influxdb.ID) bool {
+	all, ok := b.orgs[orgID]
+	if !ok {
+		all = b.auth.Allowed(influxdb.Permission{
+			Action:   b.action,
+			Resource: influxdb.Resource{Type: b.rt, OrgID: &orgID},
+		})
+		b.orgs[orgID] = all
+	}
+	if all {
+		return true
+	}
+	return b.auth.Allowed(influxdb.Permission{
+		Action:   b.action,
+		Resource: influxdb.Resource{Type: b.rt, ID: &id, OrgID: &orgID},
+	})
+}
@@ -0,0 +1,170 @@
+package authorizer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func TestBatchAuthorizer(t *testing.T) {
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{
+			Action: influxdb.ReadAction,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: influxdbtesting.IDPtr(1),
+			},
+		},
+		{
+			Action: influxdb.ReadAction,
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   influxdbtesting.IDPtr(5),
+			},
+		},
+	}))
+
+	ba, err := authorizer.NewBatchAuthorizer(ctx, influxdb.ReadAction, influxdb.BucketsResourceType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		id, orgID influxdb.ID
+		want      bool
+	}{
+		{id: 3, orgID: 1, want: true},
+		{id: 4, orgID: 1, want: true},
+		{id: 5, orgID: 2, want: true},
+		{id: 6, orgID: 2, want: false},
+		{id: 7, orgID: 3, want: false},
+	} {
+		if got := ba.Allowed(tt.id, tt.orgID); got != tt.want {
+			t.Errorf("Allowed(%v, %v) = %v, want %v", tt.id, tt.orgID, got, tt.want)
+		}
+
+		// The batch authorizer must agree with the authorization of a single resource.
+		_, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, tt.id, tt.orgID)
+		if got := err == nil; got != tt.want {
+			t.Errorf("AuthorizeRead(%v, %v) allowed = %v, want %v", tt.id, tt.orgID, got, tt.want)
+		}
+	}
+
+	wa, err := authorizer.NewBatchAuthorizer(ctx, influxdb.WriteAction, influxdb.BucketsResourceType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wa.Allowed(3, 1) {
+		t.Error("expected write to be unauthorized")
+	}
+
+	if _, err := authorizer.NewBatchAuthorizer(ctx, influxdb.ReadAction, influxdb.ResourceType("unknown")); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected invalid resource type error, got %v", err)
+	}
+	if _, err := authorizer.NewBatchAuthorizer(context.Background(), influxdb.ReadAction, influxdb.BucketsResourceType); err == nil {
+		t.Error("expected error without an authorizer on context")
+	}
+}
+
+// benchmarkContext returns a context whose authorizer may read the resources
+// of type rt of the first of 10 organizations, and the first resource of each
+// of the others, where resource i belongs to organization i%10+1.
+func benchmarkContext(rt influxdb.ResourceType) context.Context {
+	var ps []influxdb.Permission
+	for i := 0; i < 10; i++ {
+		p, _ := influxdb.NewPermissionAtID(influxdb.ID(i+1), influxdb.ReadAction, rt, influxdb.ID(i%10+1))
+		ps = append(ps, *p)
+	}
+	p, _ := influxdb.NewPermission(influxdb.ReadAction, rt, 1)
+	ps = append(ps, *p)
+
+	// Finds are called with the authorizer deep in the context, below the
+	// values added by the HTTP and tracing middlewares.
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, ps))
+	for i := 0; i < 10; i++ {
+		ctx = context.WithValue(ctx, struct{ i int }{i}, i)
+	}
+	return ctx
+}
+
+func BenchmarkAuthorizeFindBuckets(b *testing.B) {
+	ctx := benchmarkContext(influxdb.BucketsResourceType)
+	for _, n := range []int{10, 100, 1000} {
+		bs := make([]*influxdb.Bucket, n)
+		for i := range bs {
+			bs[i] = &influxdb.Bucket{ID: influxdb.ID(i + 1), OrgID: influxdb.ID(i%10 + 1)}
+		}
+		rs := make([]*influxdb.Bucket, n)
+
+		b.Run(fmt.Sprintf("per-resource/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				copy(rs, bs)
+				rrs := rs[:0]
+				for _, r := range rs {
+					_, _, err := authorizer.AuthorizeReadBucket(ctx, r.Type, r.ID, r.OrgID)
+					if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+						b.Fatal(err)
+					}
+					if err == nil {
+						rrs = append(rrs, r)
+					}
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("batch/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				copy(rs, bs)
+				if _, _, err := authorizer.AuthorizeFindBuckets(ctx, rs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkAuthorizeFindDashboards(b *testing.B) {
+	ctx := benchmarkContext(influxdb.DashboardsResourceType)
+	for _, n := range []int{10, 100, 1000} {
+		ds := make([]*influxdb.Dashboard, n)
+		for i := range ds {
+			ds[i] = &influxdb.Dashboard{ID: influxdb.ID(i + 1), OrganizationID: influxdb.ID(i%10 + 1)}
+		}
+		rs := make([]*influxdb.Dashboard, n)
+
+		b.Run(fmt.Sprintf("per-resource/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				copy(rs, ds)
+				rrs := rs[:0]
+				for _, r := range rs {
+					_, _, err := authorizer.AuthorizeRead(ctx, influxdb.DashboardsResourceType, r.ID, r.OrganizationID)
+					if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+						b.Fatal(err)
+					}
+					if err == nil {
+						rrs = append(rrs, r)
+					}
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("batch/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				copy(rs, ds)
+				if _, _, err := authorizer.AuthorizeFindDashboards(ctx, rs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -33,15 +33,27 @@ func AuthorizeFindAuthorizations(ctx context.Context, rs []*influxdb.Authorizati
 
 // AuthorizeFindBuckets takes the given items and returns only the ones that the user is authorized to read.
 func AuthorizeFindBuckets(ctx context.Context, rs []*influxdb.Bucket) ([]*influxdb.Bucket, int, error) {
+	if len(rs) == 0 {
+		return rs, 0, nil
+	}
+	ba, err := NewBatchAuthorizer(ctx, influxdb.ReadAction, influxdb.BucketsResourceType)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// This filters without allocating
 	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
 	rrs := rs[:0]
 	for _, r := range rs {
-		_, _, err := AuthorizeReadBucket(ctx, r.Type, r.ID, r.OrgID)
-		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
-			return nil, 0, err
-		}
-		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+		if r.Type == influxdb.BucketTypeSystem {
+			_, _, err := AuthorizeReadBucket(ctx, r.Type, r.ID, r.OrgID)
+			if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+				return nil, 0, err
+			}
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+		} else if !ba.Allowed(r.ID, r.OrgID) {
 			continue
 		}
 		rrs = append(rrs, r)
@@ -51,15 +63,19 @@ func AuthorizeFindBuckets(ctx context.Context, rs []*influxdb.Bucket) ([]*influx
 
 // AuthorizeFindDashboards takes the given items and returns only the ones that the user is authorized to read.
 func AuthorizeFindDashboards(ctx context.Context, rs []*influxdb.Dashboard) ([]*influxdb.Dashboard, int, error) {
+	if len(rs) == 0 {
+		return rs, 0, nil
+	}
+	ba, err := NewBatchAuthorizer(ctx, influxdb.ReadAction, influxdb.DashboardsResourceType)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// This filters without allocating
 	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
 	rrs := rs[:0]
 	for _, r := range rs {
-		_, _, err := AuthorizeRead(ctx, influxdb.DashboardsResourceType, r.ID, r.OrganizationID)
-		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
-			return nil, 0, err
-		}
-		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+		if !ba.Allowed(r.ID, r.OrganizationID) {
 			continue
 		}
 		rrs = append(rrs, r)
@@ -0,0 +1,125 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.BucketFamilyService = (*BucketFamilyService)(nil)
+
+// BucketFamilyService wraps a influxdb.BucketFamilyService and authorizes actions
+// against it appropriately.
+//
+// Families create and delete the buckets of their members, so changing a
+// family requires write access to every bucket of its organization. Reading a
+// family only requires read access to its organization; the members read by
+// a query of the family are the buckets the query may read.
+type BucketFamilyService struct {
+	s influxdb.BucketFamilyService
+}
+
+// NewBucketFamilyService constructs an instance of an authorizing bucket family service.
+func NewBucketFamilyService(s influxdb.BucketFamilyService) *BucketFamilyService {
+	return &BucketFamilyService{
+		s: s,
+	}
+}
+
+// FindBucketFamilyByID checks to see if the authorizer on context has read access to the organization of the family.
+func (s *BucketFamilyService) FindBucketFamilyByID(ctx context.Context, id influxdb.ID) (*influxdb.BucketFamily, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindBucketFamilyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, f.OrgID); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// FindBucketFamily checks to see if the authorizer on context has read access to the organization of the family.
+func (s *BucketFamilyService) FindBucketFamily(ctx context.Context, filter influxdb.BucketFamilyFilter) (*influxdb.BucketFamily, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindBucketFamily(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, f.OrgID); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// FindBucketFamilies retrieves all families that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *BucketFamilyService) FindBucketFamilies(ctx context.Context, filter influxdb.BucketFamilyFilter) ([]*influxdb.BucketFamily, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	fs, err := s.s.FindBucketFamilies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	families := fs[:0]
+	for _, f := range fs {
+		_, _, err := AuthorizeReadOrg(ctx, f.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		families = append(families, f)
+	}
+	return families, nil
+}
+
+// CreateBucketFamily checks to see if the authorizer on context has write access to the buckets of the organization.
+func (s *BucketFamilyService) CreateBucketFamily(ctx context.Context, f *influxdb.BucketFamily) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.BucketsResourceType, f.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateBucketFamily(ctx, f)
+}
+
+// UpdateBucketFamily checks to see if the authorizer on context has write access to the buckets of the organization.
+func (s *BucketFamilyService) UpdateBucketFamily(ctx context.Context, id influxdb.ID, upd influxdb.BucketFamilyUpdate) (*influxdb.BucketFamily, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindBucketFamilyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.BucketsResourceType, f.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateBucketFamily(ctx, id, upd)
+}
+
+// DeleteBucketFamily checks to see if the authorizer on context has write access to the buckets of the organization.
+func (s *BucketFamilyService) DeleteBucketFamily(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindBucketFamilyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.BucketsResourceType, f.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteBucketFamily(ctx, id)
+}
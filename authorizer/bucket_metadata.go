@@ -0,0 +1,56 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.BucketMetadataService = (*BucketMetadataService)(nil)
+
+// BucketMetadataService wraps a influxdb.BucketMetadataService and
+// authorizes actions against it appropriately.
+type BucketMetadataService struct {
+	s influxdb.BucketMetadataService
+}
+
+// NewBucketMetadataService constructs an instance of an authorizing bucket metadata service.
+func NewBucketMetadataService(s influxdb.BucketMetadataService) *BucketMetadataService {
+	return &BucketMetadataService{
+		s: s,
+	}
+}
+
+// FindBucketMeasurements checks to see if the authorizer on context has read access to the bucket.
+func (s *BucketMetadataService) FindBucketMeasurements(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.MeasurementMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindBucketMeasurements(ctx, orgID, bucketID)
+}
+
+// FindBucketTagKeys checks to see if the authorizer on context has read access to the bucket.
+func (s *BucketMetadataService) FindBucketTagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*influxdb.TagKeyMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindBucketTagKeys(ctx, orgID, bucketID, measurement)
+}
+
+// FindBucketFieldKeys checks to see if the authorizer on context has read access to the bucket.
+func (s *BucketMetadataService) FindBucketFieldKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*influxdb.FieldKeyMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindBucketFieldKeys(ctx, orgID, bucketID, measurement)
+}
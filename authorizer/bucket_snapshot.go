@@ -0,0 +1,109 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.BucketSnapshotService = (*BucketSnapshotService)(nil)
+
+// BucketSnapshotService wraps a influxdb.BucketSnapshotService and authorizes actions
+// against it appropriately.
+type BucketSnapshotService struct {
+	s influxdb.BucketSnapshotService
+}
+
+// NewBucketSnapshotService constructs an instance of an authorizing bucket snapshot service.
+func NewBucketSnapshotService(s influxdb.BucketSnapshotService) *BucketSnapshotService {
+	return &BucketSnapshotService{
+		s: s,
+	}
+}
+
+// CreateBucketSnapshot checks to see if the authorizer on context has read access to the bucket.
+func (s *BucketSnapshotService) CreateBucketSnapshot(ctx context.Context, snap *influxdb.BucketSnapshot) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, snap.BucketID, snap.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateBucketSnapshot(ctx, snap)
+}
+
+// FindBucketSnapshotByID checks to see if the authorizer on context has read access to the bucket of the snapshot.
+func (s *BucketSnapshotService) FindBucketSnapshotByID(ctx context.Context, id influxdb.ID) (*influxdb.BucketSnapshot, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	snap, err := s.s.FindBucketSnapshotByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, snap.BucketID, snap.OrgID); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// FindBucketSnapshots retrieves all snapshots that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *BucketSnapshotService) FindBucketSnapshots(ctx context.Context, filter influxdb.BucketSnapshotFilter) ([]*influxdb.BucketSnapshot, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ss, err := s.s.FindBucketSnapshots(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	snaps := ss[:0]
+	for _, snap := range ss {
+		_, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, snap.BucketID, snap.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, nil
+}
+
+// DeleteBucketSnapshot checks to see if the authorizer on context has write access to the bucket of the snapshot.
+func (s *BucketSnapshotService) DeleteBucketSnapshot(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	snap, err := s.s.FindBucketSnapshotByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, snap.BucketID, snap.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteBucketSnapshot(ctx, id)
+}
+
+// CloneBucketSnapshot checks to see if the authorizer on context has read access to the bucket of the snapshot,
+// and can create buckets in its organization.
+func (s *BucketSnapshotService) CloneBucketSnapshot(ctx context.Context, id influxdb.ID, b *influxdb.Bucket) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	snap, err := s.s.FindBucketSnapshotByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, snap.BucketID, snap.OrgID); err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeCreate(ctx, influxdb.BucketsResourceType, snap.OrgID); err != nil {
+		return err
+	}
+	return s.s.CloneBucketSnapshot(ctx, id, b)
+}
@@ -0,0 +1,34 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.SeriesCardinalityService = (*SeriesCardinalityService)(nil)
+
+// SeriesCardinalityService wraps a influxdb.SeriesCardinalityService and
+// authorizes actions against it appropriately.
+type SeriesCardinalityService struct {
+	s influxdb.SeriesCardinalityService
+}
+
+// NewSeriesCardinalityService constructs an instance of an authorizing series cardinality service.
+func NewSeriesCardinalityService(s influxdb.SeriesCardinalityService) *SeriesCardinalityService {
+	return &SeriesCardinalityService{
+		s: s,
+	}
+}
+
+// SeriesCardinalityReport checks to see if the authorizer on context has read access to the bucket.
+func (s *SeriesCardinalityService) SeriesCardinalityReport(ctx context.Context, orgID, bucketID influxdb.ID, limit int) (*influxdb.SeriesCardinalityReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.SeriesCardinalityReport(ctx, orgID, bucketID, limit)
+}
@@ -0,0 +1,57 @@
+package authorizer
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.DBRPImportService = (*DBRPImportService)(nil)
+
+// DBRPImportService wraps a influxdb.DBRPImportService and authorizes actions
+// against it appropriately.
+type DBRPImportService struct {
+	s influxdb.DBRPImportService
+}
+
+// NewDBRPImportService constructs an instance of an authorizing DBRP import service.
+func NewDBRPImportService(s influxdb.DBRPImportService) *DBRPImportService {
+	return &DBRPImportService{
+		s: s,
+	}
+}
+
+// ImportDBRPMappings checks to see if the authorizer on context has write access to the buckets of the organization.
+func (s *DBRPImportService) ImportDBRPMappings(ctx context.Context, orgID influxdb.ID, meta io.Reader, opts influxdb.DBRPImportOptions) (*influxdb.DBRPImportResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.BucketsResourceType, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.ImportDBRPMappings(ctx, orgID, meta, opts)
+}
+
+// ImportDBRPPackage checks to see if the authorizer on context has write access to the buckets of the organization.
+func (s *DBRPImportService) ImportDBRPPackage(ctx context.Context, orgID influxdb.ID, pkg *influxdb.DBRPPackage, opts influxdb.DBRPImportOptions) (*influxdb.DBRPImportResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.BucketsResourceType, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.ImportDBRPPackage(ctx, orgID, pkg, opts)
+}
+
+// ExportDBRPMappings checks to see if the authorizer on context has read access to the buckets of the organization.
+func (s *DBRPImportService) ExportDBRPMappings(ctx context.Context, orgID influxdb.ID) (*influxdb.DBRPPackage, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeOrgReadResource(ctx, influxdb.BucketsResourceType, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.ExportDBRPMappings(ctx, orgID)
+}
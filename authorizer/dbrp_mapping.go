@@ -0,0 +1,157 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.DBRPMappingService = (*DBRPMappingService)(nil)
+
+// DBRPMappingService wraps a influxdb.DBRPMappingService and authorizes actions
+// against it appropriately.
+type DBRPMappingService struct {
+	s influxdb.DBRPMappingService
+}
+
+// NewDBRPMappingService constructs an instance of an authorizing DBRP mapping service.
+func NewDBRPMappingService(s influxdb.DBRPMappingService) *DBRPMappingService {
+	return &DBRPMappingService{
+		s: s,
+	}
+}
+
+// FindBy checks to see if the authorizer on context has read access to the bucket of the mapping.
+func (s *DBRPMappingService) FindBy(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	m, err := s.s.FindBy(ctx, cluster, db, rp)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrganizationID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Find checks to see if the authorizer on context has read access to the bucket of the mapping.
+func (s *DBRPMappingService) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	m, err := s.s.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrganizationID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FindMany checks to see if the authorizer on context has read access to the buckets of the organization
+// of the filter. The organization is required, so that the mappings are paged and counted as they are found.
+func (s *DBRPMappingService) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.OrganizationID == nil {
+		return nil, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "organization is required to find dbrp mappings",
+		}
+	}
+	if _, _, err := AuthorizeOrgReadResource(ctx, influxdb.BucketsResourceType, *filter.OrganizationID); err != nil {
+		return nil, 0, err
+	}
+	return s.s.FindMany(ctx, filter, opt...)
+}
+
+// Create checks to see if the authorizer on context has write access to the bucket of the mapping.
+func (s *DBRPMappingService) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrganizationID); err != nil {
+		return err
+	}
+	return s.s.Create(ctx, m)
+}
+
+// Upsert checks to see if the authorizer on context has write access to the bucket of the mapping, and to the
+// bucket of the mapping it replaces, if any.
+func (s *DBRPMappingService) Upsert(ctx context.Context, m *influxdb.DBRPMapping) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrganizationID); err != nil {
+		return err
+	}
+	existing, err := s.s.FindBy(ctx, m.Cluster, m.Database, m.RetentionPolicy)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	if err == nil && existing.BucketID != m.BucketID {
+		if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, existing.BucketID, existing.OrganizationID); err != nil {
+			return err
+		}
+	}
+	return s.s.Upsert(ctx, m)
+}
+
+// Replace checks to see if the authorizer on context has write access to the bucket of the mapping, and to the
+// bucket of the mapping it replaces.
+func (s *DBRPMappingService) Replace(ctx context.Context, m *influxdb.DBRPMapping, revision string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrganizationID); err != nil {
+		return err
+	}
+	existing, err := s.s.FindBy(ctx, m.Cluster, m.Database, m.RetentionPolicy)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	if err == nil && existing.BucketID != m.BucketID {
+		if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, existing.BucketID, existing.OrganizationID); err != nil {
+			return err
+		}
+	}
+	return s.s.Replace(ctx, m, revision)
+}
+
+// SetDefault checks to see if the authorizer on context has write access to the bucket of the mapping.
+func (s *DBRPMappingService) SetDefault(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	m, err := s.s.FindBy(ctx, cluster, db, rp)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrganizationID); err != nil {
+		return nil, err
+	}
+	return s.s.SetDefault(ctx, cluster, db, rp)
+}
+
+// Delete checks to see if the authorizer on context has write access to the bucket of the mapping.
+func (s *DBRPMappingService) Delete(ctx context.Context, cluster, db, rp string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	m, err := s.s.FindBy(ctx, cluster, db, rp)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrganizationID); err != nil {
+		return err
+	}
+	return s.s.Delete(ctx, cluster, db, rp)
+}
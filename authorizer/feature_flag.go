@@ -0,0 +1,141 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.FeatureFlagService = (*FeatureFlagService)(nil)
+
+// FeatureFlagService wraps a influxdb.FeatureFlagService and authorizes actions
+// against it appropriately. The settings of the instance may be read by an
+// authorizer which may read all resources, and changed by an operator. The
+// settings of an organization may be read and changed by an authorizer which
+// may read and write the organization.
+type FeatureFlagService struct {
+	s influxdb.FeatureFlagService
+}
+
+// NewFeatureFlagService constructs an instance of an authorizing feature flag service.
+func NewFeatureFlagService(s influxdb.FeatureFlagService) *FeatureFlagService {
+	return &FeatureFlagService{
+		s: s,
+	}
+}
+
+// featureFlagReadAuthorizer returns a function which checks whether the
+// authorizer on ctx may read the settings of the instance, or of the
+// organization orgID if it is not nil.
+func featureFlagReadAuthorizer(ctx context.Context) func(orgID *influxdb.ID) error {
+	var instanceErr error
+	var instanceChecked bool
+	return func(orgID *influxdb.ID) error {
+		if orgID != nil {
+			_, _, err := AuthorizeReadOrg(ctx, *orgID)
+			return err
+		}
+		if !instanceChecked {
+			instanceErr = IsAllowedAll(ctx, influxdb.ReadAllPermissions())
+			instanceChecked = true
+		}
+		return instanceErr
+	}
+}
+
+// authorizeWriteFeatureFlag checks to see if the authorizer on context may
+// change the settings of the instance, or of the organization orgID if it
+// is not nil.
+func authorizeWriteFeatureFlag(ctx context.Context, orgID *influxdb.ID) error {
+	if orgID != nil {
+		_, _, err := AuthorizeWriteOrg(ctx, *orgID)
+		return err
+	}
+	return IsAllowedAll(ctx, influxdb.OperPermissions())
+}
+
+// FindFeatureFlagSettings retrieves all settings that match the provided filter and then filters the list down to only the settings that are authorized.
+func (s *FeatureFlagService) FindFeatureFlagSettings(ctx context.Context, filter influxdb.FeatureFlagSettingFilter) ([]*influxdb.FeatureFlagSetting, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ss, err := s.s.FindFeatureFlagSettings(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizeRead := featureFlagReadAuthorizer(ctx)
+	settings := ss[:0]
+	for _, fs := range ss {
+		err := authorizeRead(fs.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		settings = append(settings, fs)
+	}
+	return settings, nil
+}
+
+// SetFeatureFlagSetting checks to see if the authorizer on context may change the setting.
+func (s *FeatureFlagService) SetFeatureFlagSetting(ctx context.Context, fs *influxdb.FeatureFlagSetting) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteFeatureFlag(ctx, fs.OrgID); err != nil {
+		return err
+	}
+	return s.s.SetFeatureFlagSetting(ctx, fs)
+}
+
+// DeleteFeatureFlagSetting checks to see if the authorizer on context may change the setting.
+func (s *FeatureFlagService) DeleteFeatureFlagSetting(ctx context.Context, key string, orgID *influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteFeatureFlag(ctx, orgID); err != nil {
+		return err
+	}
+	return s.s.DeleteFeatureFlagSetting(ctx, key, orgID)
+}
+
+// FindFeatureFlagChanges retrieves all changes that match the provided filter and then filters the list down to only the changes of settings that are authorized.
+func (s *FeatureFlagService) FindFeatureFlagChanges(ctx context.Context, filter influxdb.FeatureFlagChangeFilter, opts ...influxdb.FindOptions) ([]*influxdb.FeatureFlagChange, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// The changes are filtered before the offset and limit are applied.
+	var opt influxdb.FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	cs, err := s.s.FindFeatureFlagChanges(ctx, filter, influxdb.FindOptions{Descending: opt.Descending})
+	if err != nil {
+		return nil, err
+	}
+
+	authorizeRead := featureFlagReadAuthorizer(ctx)
+	changes := cs[:0]
+	offset := opt.Offset
+	for _, c := range cs {
+		err := authorizeRead(c.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		changes = append(changes, c)
+		if opt.Limit > 0 && len(changes) >= opt.Limit {
+			break
+		}
+	}
+	return changes, nil
+}
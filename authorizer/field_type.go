@@ -0,0 +1,48 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.FieldTypeConflictService = (*FieldTypeConflictService)(nil)
+
+// FieldTypeConflictService wraps a influxdb.FieldTypeConflictService and
+// authorizes actions against it appropriately.
+type FieldTypeConflictService struct {
+	s influxdb.FieldTypeConflictService
+}
+
+// NewFieldTypeConflictService constructs an instance of an authorizing field type conflict service.
+func NewFieldTypeConflictService(s influxdb.FieldTypeConflictService) *FieldTypeConflictService {
+	return &FieldTypeConflictService{
+		s: s,
+	}
+}
+
+// FindFieldTypeConflicts checks to see if the authorizer on context has read access to the bucket.
+func (s *FieldTypeConflictService) FindFieldTypeConflicts(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.FieldTypeConflict, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindFieldTypeConflicts(ctx, orgID, bucketID)
+}
+
+// ConvertFieldType checks to see if the authorizer on context has read and write access to the bucket.
+func (s *FieldTypeConflictService) ConvertFieldType(ctx context.Context, c influxdb.FieldTypeConversion) (*influxdb.Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, c.BucketID, c.OrgID); err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, c.BucketID, c.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.ConvertFieldType(ctx, c)
+}
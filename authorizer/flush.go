@@ -0,0 +1,34 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.CacheFlushService = (*CacheFlushService)(nil)
+
+// CacheFlushService wraps a influxdb.CacheFlushService and authorizes actions
+// against it appropriately.
+type CacheFlushService struct {
+	s influxdb.CacheFlushService
+}
+
+// NewCacheFlushService constructs an instance of an authorizing cache flush service.
+func NewCacheFlushService(s influxdb.CacheFlushService) *CacheFlushService {
+	return &CacheFlushService{
+		s: s,
+	}
+}
+
+// FlushBucket checks to see if the authorizer on context has write access to the bucket.
+func (s *CacheFlushService) FlushBucket(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.CacheFlushResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FlushBucket(ctx, orgID, bucketID)
+}
@@ -0,0 +1,154 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.FluxFragmentService = (*FluxFragmentService)(nil)
+
+// FluxFragmentService wraps a influxdb.FluxFragmentService and authorizes actions
+// against it appropriately.
+//
+// Fragments are shared by the queries of their organization, so reading a
+// fragment requires read access to its organization, and changing it write
+// access to its organization.
+type FluxFragmentService struct {
+	s influxdb.FluxFragmentService
+}
+
+// NewFluxFragmentService constructs an instance of an authorizing flux fragment service.
+func NewFluxFragmentService(s influxdb.FluxFragmentService) *FluxFragmentService {
+	return &FluxFragmentService{
+		s: s,
+	}
+}
+
+// FindFluxFragmentByID checks to see if the authorizer on context has read access to the organization of the fragment.
+func (s *FluxFragmentService) FindFluxFragmentByID(ctx context.Context, id influxdb.ID) (*influxdb.FluxFragment, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindFluxFragmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, f.OrgID); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// FindFluxFragment checks to see if the authorizer on context has read access to the organization of the fragment.
+func (s *FluxFragmentService) FindFluxFragment(ctx context.Context, filter influxdb.FluxFragmentFilter) (*influxdb.FluxFragment, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindFluxFragment(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, f.OrgID); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// FindFluxFragments retrieves all fragments that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *FluxFragmentService) FindFluxFragments(ctx context.Context, filter influxdb.FluxFragmentFilter) ([]*influxdb.FluxFragment, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	fs, err := s.s.FindFluxFragments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	fragments := fs[:0]
+	for _, f := range fs {
+		_, _, err := AuthorizeReadOrg(ctx, f.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		fragments = append(fragments, f)
+	}
+	return fragments, nil
+}
+
+// CreateFluxFragment checks to see if the authorizer on context has write access to the organization.
+func (s *FluxFragmentService) CreateFluxFragment(ctx context.Context, f *influxdb.FluxFragment) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWriteOrg(ctx, f.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateFluxFragment(ctx, f)
+}
+
+// UpdateFluxFragment checks to see if the authorizer on context has write access to the organization of the fragment.
+func (s *FluxFragmentService) UpdateFluxFragment(ctx context.Context, id influxdb.ID, upd influxdb.FluxFragmentUpdate) (*influxdb.FluxFragment, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindFluxFragmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, f.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateFluxFragment(ctx, id, upd)
+}
+
+// DeleteFluxFragment checks to see if the authorizer on context has write access to the organization of the fragment.
+func (s *FluxFragmentService) DeleteFluxFragment(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindFluxFragmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, f.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteFluxFragment(ctx, id)
+}
+
+// FindFluxFragmentVersion checks to see if the authorizer on context has read access to the organization of the fragment.
+func (s *FluxFragmentService) FindFluxFragmentVersion(ctx context.Context, id influxdb.ID, version int) (*influxdb.FluxFragmentVersion, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindFluxFragmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, f.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindFluxFragmentVersion(ctx, id, version)
+}
+
+// FindFluxFragmentVersions checks to see if the authorizer on context has read access to the organization of the fragment.
+func (s *FluxFragmentService) FindFluxFragmentVersions(ctx context.Context, id influxdb.ID) ([]*influxdb.FluxFragmentVersion, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := s.s.FindFluxFragmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, f.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindFluxFragmentVersions(ctx, id)
+}
@@ -0,0 +1,120 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.HTTPSinkService = (*HTTPSinkService)(nil)
+
+// HTTPSinkService wraps a influxdb.HTTPSinkService and authorizes actions
+// against it appropriately.
+type HTTPSinkService struct {
+	s influxdb.HTTPSinkService
+}
+
+// NewHTTPSinkService constructs an instance of an authorizing http sink service.
+func NewHTTPSinkService(s influxdb.HTTPSinkService) *HTTPSinkService {
+	return &HTTPSinkService{
+		s: s,
+	}
+}
+
+// FindHTTPSinkByID checks to see if the authorizer on context has read access to the organization of the http sink.
+func (s *HTTPSinkService) FindHTTPSinkByID(ctx context.Context, id influxdb.ID) (*influxdb.HTTPSink, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	hs, err := s.s.FindHTTPSinkByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, hs.OrgID); err != nil {
+		return nil, err
+	}
+	return hs, nil
+}
+
+// FindHTTPSink checks to see if the authorizer on context has read access to the organization of the http sink.
+func (s *HTTPSinkService) FindHTTPSink(ctx context.Context, filter influxdb.HTTPSinkFilter) (*influxdb.HTTPSink, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	hs, err := s.s.FindHTTPSink(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, hs.OrgID); err != nil {
+		return nil, err
+	}
+	return hs, nil
+}
+
+// FindHTTPSinks retrieves all http sinks that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *HTTPSinkService) FindHTTPSinks(ctx context.Context, filter influxdb.HTTPSinkFilter) ([]*influxdb.HTTPSink, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	hss, err := s.s.FindHTTPSinks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	sinks := hss[:0]
+	for _, hs := range hss {
+		_, _, err := AuthorizeReadOrg(ctx, hs.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		sinks = append(sinks, hs)
+	}
+	return sinks, nil
+}
+
+// CreateHTTPSink checks to see if the authorizer on context has write access to the organization of the http sink.
+func (s *HTTPSinkService) CreateHTTPSink(ctx context.Context, hs *influxdb.HTTPSink) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWriteOrg(ctx, hs.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateHTTPSink(ctx, hs)
+}
+
+// UpdateHTTPSink checks to see if the authorizer on context has write access to the organization of the http sink.
+func (s *HTTPSinkService) UpdateHTTPSink(ctx context.Context, id influxdb.ID, upd influxdb.HTTPSinkUpdate) (*influxdb.HTTPSink, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	hs, err := s.s.FindHTTPSinkByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, hs.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateHTTPSink(ctx, id, upd)
+}
+
+// DeleteHTTPSink checks to see if the authorizer on context has write access to the organization of the http sink.
+func (s *HTTPSinkService) DeleteHTTPSink(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	hs, err := s.s.FindHTTPSinkByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, hs.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteHTTPSink(ctx, id)
+}
@@ -0,0 +1,93 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.JobService = (*JobService)(nil)
+
+// JobService wraps a influxdb.JobService and authorizes actions against it
+// appropriately. The jobs of an organization are authorized against it, and
+// the jobs of the instance may be read by an authorizer which may read all
+// resources, and canceled by an operator.
+type JobService struct {
+	s influxdb.JobService
+}
+
+// NewJobService constructs an instance of an authorizing job service.
+func NewJobService(s influxdb.JobService) *JobService {
+	return &JobService{
+		s: s,
+	}
+}
+
+func authorizeReadJob(ctx context.Context, j *influxdb.Job) error {
+	if !j.OrgID.Valid() {
+		return IsAllowedAll(ctx, influxdb.ReadAllPermissions())
+	}
+	_, _, err := AuthorizeReadOrg(ctx, j.OrgID)
+	return err
+}
+
+// FindJobByID checks to see if the authorizer on context may read the job.
+func (s *JobService) FindJobByID(ctx context.Context, id influxdb.ID) (*influxdb.Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	j, err := s.s.FindJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeReadJob(ctx, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// FindJobs retrieves all jobs that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *JobService) FindJobs(ctx context.Context, filter influxdb.JobFilter) ([]*influxdb.Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	js, err := s.s.FindJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	jobs := js[:0]
+	for _, j := range js {
+		err := authorizeReadJob(ctx, j)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// CancelJob checks to see if the authorizer on context has write access to the organization of the job.
+func (s *JobService) CancelJob(ctx context.Context, id influxdb.ID) (*influxdb.Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	j, err := s.s.FindJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !j.OrgID.Valid() {
+		if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+			return nil, err
+		}
+	} else if _, _, err := AuthorizeWriteOrg(ctx, j.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.CancelJob(ctx, id)
+}
@@ -0,0 +1,34 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.LastValueService = (*LastValueService)(nil)
+
+// LastValueService wraps a influxdb.LastValueService and authorizes actions
+// against it appropriately.
+type LastValueService struct {
+	s influxdb.LastValueService
+}
+
+// NewLastValueService constructs an instance of an authorizing last value service.
+func NewLastValueService(s influxdb.LastValueService) *LastValueService {
+	return &LastValueService{
+		s: s,
+	}
+}
+
+// FindLastValues checks to see if the authorizer on context has read access to the bucket.
+func (s *LastValueService) FindLastValues(ctx context.Context, orgID, bucketID influxdb.ID, filter influxdb.LastValueFilter) ([]*influxdb.LastValue, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindLastValues(ctx, orgID, bucketID, filter)
+}
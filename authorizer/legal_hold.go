@@ -0,0 +1,88 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.LegalHoldService = (*LegalHoldService)(nil)
+
+// LegalHoldService wraps a influxdb.LegalHoldService and authorizes actions
+// against it appropriately. Holds are read by those who may read their
+// bucket, but only placed and released by those who may write to the
+// organization.
+type LegalHoldService struct {
+	s influxdb.LegalHoldService
+}
+
+// NewLegalHoldService constructs an instance of an authorizing legal hold service.
+func NewLegalHoldService(s influxdb.LegalHoldService) *LegalHoldService {
+	return &LegalHoldService{
+		s: s,
+	}
+}
+
+// FindLegalHoldByID checks to see if the authorizer on context has read access to the bucket of the hold.
+func (s *LegalHoldService) FindLegalHoldByID(ctx context.Context, id influxdb.ID) (*influxdb.LegalHold, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	h, err := s.s.FindLegalHoldByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, h.BucketID, h.OrgID); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// FindLegalHolds retrieves all holds that match the provided filter and then filters the list down to only the holds of the buckets that are authorized.
+func (s *LegalHoldService) FindLegalHolds(ctx context.Context, filter influxdb.LegalHoldFilter) ([]*influxdb.LegalHold, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	hs, err := s.s.FindLegalHolds(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	ba, err := NewBatchAuthorizer(ctx, influxdb.ReadAction, influxdb.BucketsResourceType)
+	if err != nil {
+		return nil, err
+	}
+	hhs := hs[:0]
+	for _, h := range hs {
+		if ba.Allowed(h.BucketID, h.OrgID) {
+			hhs = append(hhs, h)
+		}
+	}
+	return hhs, nil
+}
+
+// CreateLegalHold checks to see if the authorizer on context has write access to the organization of the hold.
+func (s *LegalHoldService) CreateLegalHold(ctx context.Context, h *influxdb.LegalHold) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWriteOrg(ctx, h.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateLegalHold(ctx, h)
+}
+
+// ReleaseLegalHold checks to see if the authorizer on context has write access to the organization of the hold.
+func (s *LegalHoldService) ReleaseLegalHold(ctx context.Context, id influxdb.ID, reason string) (*influxdb.LegalHold, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	h, err := s.s.FindLegalHoldByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, h.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.ReleaseLegalHold(ctx, id, reason)
+}
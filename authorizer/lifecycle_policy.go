@@ -0,0 +1,139 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.LifecyclePolicyService = (*LifecyclePolicyService)(nil)
+
+// LifecyclePolicyService wraps a influxdb.LifecyclePolicyService and authorizes actions
+// against it appropriately.
+type LifecyclePolicyService struct {
+	s influxdb.LifecyclePolicyService
+}
+
+// NewLifecyclePolicyService constructs an instance of an authorizing lifecycle policy service.
+func NewLifecyclePolicyService(s influxdb.LifecyclePolicyService) *LifecyclePolicyService {
+	return &LifecyclePolicyService{
+		s: s,
+	}
+}
+
+// authorizeWritePolicy checks to see if the authorizer on context has write access to the bucket
+// of p and to the buckets it downsamples to.
+func authorizeWritePolicy(ctx context.Context, p *influxdb.LifecyclePolicy) error {
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, p.BucketID, p.OrgID); err != nil {
+		return err
+	}
+	for _, r := range p.Downsample {
+		if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, r.DestinationBucketID, p.OrgID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindLifecyclePolicyByID checks to see if the authorizer on context has read access to the bucket of the policy.
+func (s *LifecyclePolicyService) FindLifecyclePolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.LifecyclePolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindLifecyclePolicyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, p.BucketID, p.OrgID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindLifecyclePolicies retrieves all policies that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *LifecyclePolicyService) FindLifecyclePolicies(ctx context.Context, filter influxdb.LifecyclePolicyFilter) ([]*influxdb.LifecyclePolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ps, err := s.s.FindLifecyclePolicies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	policies := ps[:0]
+	for _, p := range ps {
+		_, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, p.BucketID, p.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// CreateLifecyclePolicy checks to see if the authorizer on context has write access to the buckets of the policy.
+func (s *LifecyclePolicyService) CreateLifecyclePolicy(ctx context.Context, p *influxdb.LifecyclePolicy) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWritePolicy(ctx, p); err != nil {
+		return err
+	}
+	return s.s.CreateLifecyclePolicy(ctx, p)
+}
+
+// UpdateLifecyclePolicy checks to see if the authorizer on context has write access to the buckets of the policy,
+// before and after the update.
+func (s *LifecyclePolicyService) UpdateLifecyclePolicy(ctx context.Context, id influxdb.ID, upd influxdb.LifecyclePolicyUpdate) (*influxdb.LifecyclePolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindLifecyclePolicyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWritePolicy(ctx, p); err != nil {
+		return nil, err
+	}
+	upd.Apply(p)
+	if err := authorizeWritePolicy(ctx, p); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateLifecyclePolicy(ctx, id, upd)
+}
+
+// UpdateLifecyclePolicyStatus checks to see if the authorizer on context has write access to the bucket of the policy.
+func (s *LifecyclePolicyService) UpdateLifecyclePolicyStatus(ctx context.Context, id influxdb.ID, status influxdb.LifecyclePolicyStatus) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindLifecyclePolicyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, p.BucketID, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.UpdateLifecyclePolicyStatus(ctx, id, status)
+}
+
+// DeleteLifecyclePolicy checks to see if the authorizer on context has write access to the bucket of the policy.
+func (s *LifecyclePolicyService) DeleteLifecyclePolicy(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindLifecyclePolicyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, p.BucketID, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteLifecyclePolicy(ctx, id)
+}
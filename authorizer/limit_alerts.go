@@ -0,0 +1,45 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.LimitAlertsService = (*LimitAlertsService)(nil)
+
+// LimitAlertsService wraps a influxdb.LimitAlertsService and authorizes actions
+// against it appropriately.
+type LimitAlertsService struct {
+	s influxdb.LimitAlertsService
+}
+
+// NewLimitAlertsService constructs an instance of an authorizing limit alerts service.
+func NewLimitAlertsService(s influxdb.LimitAlertsService) *LimitAlertsService {
+	return &LimitAlertsService{
+		s: s,
+	}
+}
+
+// FindLimitAlerts checks to see if the authorizer on context has read access to the organization.
+func (s *LimitAlertsService) FindLimitAlerts(ctx context.Context, orgID influxdb.ID) (*influxdb.LimitAlerts, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindLimitAlerts(ctx, orgID)
+}
+
+// UpdateLimitAlerts checks to see if the authorizer on context has write access to the organization.
+func (s *LimitAlertsService) UpdateLimitAlerts(ctx context.Context, orgID influxdb.ID, upd influxdb.LimitAlertsUpdate) (*influxdb.LimitAlerts, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWriteOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateLimitAlerts(ctx, orgID, upd)
+}
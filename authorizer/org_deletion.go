@@ -0,0 +1,65 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.OrgDeletionService = (*OrgDeletionService)(nil)
+
+// OrgDeletionService wraps a influxdb.OrgDeletionService and authorizes actions
+// against it appropriately.
+type OrgDeletionService struct {
+	s influxdb.OrgDeletionService
+}
+
+// NewOrgDeletionService constructs an instance of an authorizing organization deletion service.
+func NewOrgDeletionService(s influxdb.OrgDeletionService) *OrgDeletionService {
+	return &OrgDeletionService{
+		s: s,
+	}
+}
+
+// StartOrgDeletion checks to see if the authorizer on context has write access to the organization.
+func (s *OrgDeletionService) StartOrgDeletion(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgDeletion, error) {
+	if _, _, err := AuthorizeWriteOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.StartOrgDeletion(ctx, orgID)
+}
+
+// FindOrgDeletionByID checks to see if the authorizer on context has read access to the organization of the deletion.
+func (s *OrgDeletionService) FindOrgDeletionByID(ctx context.Context, id influxdb.ID) (*influxdb.OrgDeletion, error) {
+	d, err := s.s.FindOrgDeletionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, d.OrgID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// FindOrgDeletions retrieves all deletions that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *OrgDeletionService) FindOrgDeletions(ctx context.Context, filter influxdb.OrgDeletionFilter) ([]*influxdb.OrgDeletion, error) {
+	ds, err := s.s.FindOrgDeletions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	deletions := ds[:0]
+	for _, d := range ds {
+		_, _, err := AuthorizeReadOrg(ctx, d.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		deletions = append(deletions, d)
+	}
+	return deletions, nil
+}
@@ -0,0 +1,83 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.OrgDomainService = (*OrgDomainService)(nil)
+
+// OrgDomainService wraps a influxdb.OrgDomainService and authorizes actions
+// against it appropriately. Subdomains are read by those who may read their
+// organization, and mapped and unmapped by those who may write to it.
+type OrgDomainService struct {
+	s influxdb.OrgDomainService
+}
+
+// NewOrgDomainService constructs an instance of an authorizing org domain service.
+func NewOrgDomainService(s influxdb.OrgDomainService) *OrgDomainService {
+	return &OrgDomainService{
+		s: s,
+	}
+}
+
+// FindOrgDomain checks to see if the authorizer on context has read access to the organization of the subdomain.
+func (s *OrgDomainService) FindOrgDomain(ctx context.Context, subdomain string) (*influxdb.OrgDomain, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	d, err := s.s.FindOrgDomain(ctx, subdomain)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, d.OrgID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// FindOrgDomains retrieves all subdomains that match the provided filter and then filters the list down to only the subdomains of the organizations that are authorized.
+func (s *OrgDomainService) FindOrgDomains(ctx context.Context, filter influxdb.OrgDomainFilter) ([]*influxdb.OrgDomain, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ds, err := s.s.FindOrgDomains(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	dds := ds[:0]
+	for _, d := range ds {
+		if _, _, err := AuthorizeReadOrg(ctx, d.OrgID); err == nil {
+			dds = append(dds, d)
+		}
+	}
+	return dds, nil
+}
+
+// CreateOrgDomain checks to see if the authorizer on context has write access to the organization of the subdomain.
+func (s *OrgDomainService) CreateOrgDomain(ctx context.Context, d *influxdb.OrgDomain) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWriteOrg(ctx, d.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateOrgDomain(ctx, d)
+}
+
+// DeleteOrgDomain checks to see if the authorizer on context has write access to the organization of the subdomain.
+func (s *OrgDomainService) DeleteOrgDomain(ctx context.Context, subdomain string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	d, err := s.s.FindOrgDomain(ctx, subdomain)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, d.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteOrgDomain(ctx, subdomain)
+}
@@ -0,0 +1,46 @@
+package authorizer
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.ParquetExportService = (*ParquetExportService)(nil)
+
+// ParquetExportService wraps a influxdb.ParquetExportService and authorizes actions
+// against it appropriately.
+type ParquetExportService struct {
+	s influxdb.ParquetExportService
+}
+
+// NewParquetExportService constructs an instance of an authorizing parquet export service.
+func NewParquetExportService(s influxdb.ParquetExportService) *ParquetExportService {
+	return &ParquetExportService{
+		s: s,
+	}
+}
+
+// FindExportMeasurements checks to see if the authorizer on context has read access to the bucket.
+func (s *ParquetExportService) FindExportMeasurements(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64) ([]string, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindExportMeasurements(ctx, orgID, bucketID, start, end)
+}
+
+// ExportParquet checks to see if the authorizer on context has read access to the bucket.
+func (s *ParquetExportService) ExportParquet(ctx context.Context, w io.Writer, orgID, bucketID influxdb.ID, measurement string, start, end int64) (int64, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return 0, err
+	}
+	return s.s.ExportParquet(ctx, w, orgID, bucketID, measurement, start, end)
+}
@@ -35,3 +35,21 @@ func (s *PasswordService) ComparePassword(ctx context.Context, userID influxdb.I
 func (s *PasswordService) CompareAndSetPassword(ctx context.Context, userID influxdb.ID, old string, new string) error {
 	panic("not implemented")
 }
+
+// PasswordLockoutService is a new authorization middleware for a password lockout service.
+type PasswordLockoutService struct {
+	next influxdb.PasswordLockoutService
+}
+
+// NewPasswordLockoutService wraps an existing password lockout service with auth middlware.
+func NewPasswordLockoutService(svc influxdb.PasswordLockoutService) *PasswordLockoutService {
+	return &PasswordLockoutService{next: svc}
+}
+
+// UnlockUser checks to see if the authorizer on context has write access to the user.
+func (s *PasswordLockoutService) UnlockUser(ctx context.Context, userID influxdb.ID) error {
+	if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return err
+	}
+	return s.next.UnlockUser(ctx, userID)
+}
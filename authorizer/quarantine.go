@@ -0,0 +1,51 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.BlockQuarantineService = (*BlockQuarantineService)(nil)
+
+// BlockQuarantineService wraps a influxdb.BlockQuarantineService and authorizes
+// actions against it appropriately.
+type BlockQuarantineService struct {
+	s influxdb.BlockQuarantineService
+}
+
+// NewBlockQuarantineService constructs an instance of an authorizing block
+// quarantine service.
+func NewBlockQuarantineService(s influxdb.BlockQuarantineService) *BlockQuarantineService {
+	return &BlockQuarantineService{
+		s: s,
+	}
+}
+
+// FindQuarantinedBlocks returns only the quarantined blocks of the buckets the
+// authorizer on context has read access to.
+func (s *BlockQuarantineService) FindQuarantinedBlocks(ctx context.Context, filter influxdb.QuarantinedBlockFilter) ([]*influxdb.QuarantinedBlock, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	bs, err := s.s.FindQuarantinedBlocks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rbs := bs[:0]
+	for _, b := range bs {
+		_, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, b.BucketID, b.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rbs = append(rbs, b)
+	}
+	return rbs, nil
+}
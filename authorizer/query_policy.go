@@ -0,0 +1,160 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.QueryPolicyService = (*QueryPolicyService)(nil)
+
+// QueryPolicyService wraps a influxdb.QueryPolicyService and authorizes
+// actions against it appropriately. The policies of every organization may
+// be read by an authorizer which may read all resources, and changed by an
+// operator. The policies of an organization and the evaluations of the
+// policies for its queries may be read by an authorizer which may read the
+// organization, and the policies changed by one which may write it.
+type QueryPolicyService struct {
+	s influxdb.QueryPolicyService
+}
+
+// NewQueryPolicyService constructs an instance of an authorizing query policy service.
+func NewQueryPolicyService(s influxdb.QueryPolicyService) *QueryPolicyService {
+	return &QueryPolicyService{
+		s: s,
+	}
+}
+
+// authorizeReadQueryPolicy checks to see if the authorizer on context may
+// read the policies of every organization, or of the organization orgID if
+// it is not nil.
+func authorizeReadQueryPolicy(ctx context.Context, orgID *influxdb.ID) error {
+	if orgID != nil {
+		_, _, err := AuthorizeReadOrg(ctx, *orgID)
+		return err
+	}
+	return IsAllowedAll(ctx, influxdb.ReadAllPermissions())
+}
+
+// authorizeWriteQueryPolicy checks to see if the authorizer on context may
+// change the policies of every organization, or of the organization orgID if
+// it is not nil.
+func authorizeWriteQueryPolicy(ctx context.Context, orgID *influxdb.ID) error {
+	if orgID != nil {
+		_, _, err := AuthorizeWriteOrg(ctx, *orgID)
+		return err
+	}
+	return IsAllowedAll(ctx, influxdb.OperPermissions())
+}
+
+// FindQueryPolicyByID checks to see if the authorizer on context may read the policy.
+func (s *QueryPolicyService) FindQueryPolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.QueryPolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindQueryPolicyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeReadQueryPolicy(ctx, p.OrgID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindQueryPolicies retrieves all policies that match the provided filter and then filters the list down to only the policies that are authorized.
+func (s *QueryPolicyService) FindQueryPolicies(ctx context.Context, filter influxdb.QueryPolicyFilter) ([]*influxdb.QueryPolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ps, err := s.s.FindQueryPolicies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	policies := ps[:0]
+	for _, p := range ps {
+		err := authorizeReadQueryPolicy(ctx, p.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// CreateQueryPolicy checks to see if the authorizer on context may change the policies of the organization of the policy.
+func (s *QueryPolicyService) CreateQueryPolicy(ctx context.Context, p *influxdb.QueryPolicy) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteQueryPolicy(ctx, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateQueryPolicy(ctx, p)
+}
+
+// UpdateQueryPolicy checks to see if the authorizer on context may change the policies of the organizations of both the policy and its replacement.
+func (s *QueryPolicyService) UpdateQueryPolicy(ctx context.Context, p *influxdb.QueryPolicy) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	existing, err := s.s.FindQueryPolicyByID(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeWriteQueryPolicy(ctx, existing.OrgID); err != nil {
+		return err
+	}
+	if err := authorizeWriteQueryPolicy(ctx, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.UpdateQueryPolicy(ctx, p)
+}
+
+// DeleteQueryPolicy checks to see if the authorizer on context may change the policies of the organization of the policy.
+func (s *QueryPolicyService) DeleteQueryPolicy(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p, err := s.s.FindQueryPolicyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeWriteQueryPolicy(ctx, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteQueryPolicy(ctx, id)
+}
+
+// RecordQueryPolicyEvaluation checks to see if the authorizer on context is an operator.
+func (s *QueryPolicyService) RecordQueryPolicyEvaluation(ctx context.Context, e *influxdb.QueryPolicyEvaluation) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.RecordQueryPolicyEvaluation(ctx, e)
+}
+
+// FindQueryPolicyEvaluations retrieves all evaluations that match the provided filter and then filters the list down to only the evaluations of the organizations that are authorized.
+func (s *QueryPolicyService) FindQueryPolicyEvaluations(ctx context.Context, filter influxdb.QueryPolicyEvaluationFilter, opts ...influxdb.FindOptions) ([]*influxdb.QueryPolicyEvaluation, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	es, err := s.s.FindQueryPolicyEvaluations(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	evaluations := es[:0]
+	for _, e := range es {
+		if _, _, err := AuthorizeReadOrg(ctx, e.OrgID); err == nil {
+			evaluations = append(evaluations, e)
+		}
+	}
+	return evaluations, nil
+}
@@ -0,0 +1,140 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.ReportService = (*ReportService)(nil)
+
+// ReportService wraps a influxdb.ReportService and authorizes actions
+// against it appropriately. Reports run queries on a schedule like tasks do,
+// so they are authorized as tasks of their organization.
+type ReportService struct {
+	s influxdb.ReportService
+}
+
+// NewReportService constructs an instance of an authorizing report service.
+func NewReportService(s influxdb.ReportService) *ReportService {
+	return &ReportService{
+		s: s,
+	}
+}
+
+// authorizeWriteReport checks to see if the authorizer on context has write access to the tasks
+// of the organization of r, and read access to the authorization and dashboard it runs with.
+func authorizeWriteReport(ctx context.Context, r *influxdb.Report) error {
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.TasksResourceType, r.OrgID); err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.AuthorizationsResourceType, r.AuthorizationID, r.OrgID); err != nil {
+		return err
+	}
+	if r.DashboardID.Valid() {
+		if _, _, err := AuthorizeRead(ctx, influxdb.DashboardsResourceType, r.DashboardID, r.OrgID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindReportByID checks to see if the authorizer on context has read access to the tasks of the organization of the report.
+func (s *ReportService) FindReportByID(ctx context.Context, id influxdb.ID) (*influxdb.Report, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	r, err := s.s.FindReportByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeOrgReadResource(ctx, influxdb.TasksResourceType, r.OrgID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// FindReports retrieves all reports that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *ReportService) FindReports(ctx context.Context, filter influxdb.ReportFilter) ([]*influxdb.Report, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	rs, err := s.s.FindReports(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	reports := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeOrgReadResource(ctx, influxdb.TasksResourceType, r.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// CreateReport checks to see if the authorizer on context has write access to the tasks of the organization of the report.
+func (s *ReportService) CreateReport(ctx context.Context, r *influxdb.Report) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteReport(ctx, r); err != nil {
+		return err
+	}
+	return s.s.CreateReport(ctx, r)
+}
+
+// UpdateReport checks to see if the authorizer on context has write access to the tasks of the organization of the report,
+// and read access to the dashboard it reports after the update.
+func (s *ReportService) UpdateReport(ctx context.Context, id influxdb.ID, upd influxdb.ReportUpdate) (*influxdb.Report, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	r, err := s.s.FindReportByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	upd.Apply(r)
+	if err := authorizeWriteReport(ctx, r); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateReport(ctx, id, upd)
+}
+
+// UpdateReportStatus checks to see if the authorizer on context has write access to the tasks of the organization of the report.
+func (s *ReportService) UpdateReportStatus(ctx context.Context, id influxdb.ID, status influxdb.ReportStatus) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	r, err := s.s.FindReportByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.TasksResourceType, r.OrgID); err != nil {
+		return err
+	}
+	return s.s.UpdateReportStatus(ctx, id, status)
+}
+
+// DeleteReport checks to see if the authorizer on context has write access to the tasks of the organization of the report.
+func (s *ReportService) DeleteReport(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	r, err := s.s.FindReportByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.TasksResourceType, r.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteReport(ctx, id)
+}
@@ -0,0 +1,129 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.ResourceGrantService = (*ResourceGrantService)(nil)
+
+// ResourceGrantService wraps a influxdb.ResourceGrantService and authorizes actions
+// against it appropriately.
+type ResourceGrantService struct {
+	s influxdb.ResourceGrantService
+}
+
+// NewResourceGrantService constructs an instance of an authorizing resource grant service.
+func NewResourceGrantService(s influxdb.ResourceGrantService) *ResourceGrantService {
+	return &ResourceGrantService{
+		s: s,
+	}
+}
+
+// authorizeReadResourceGrant checks that the authorizer on context may read
+// the shared resource, either as a member of the owning organization or
+// of the grantee organization.
+func authorizeReadResourceGrant(ctx context.Context, g *influxdb.ResourceGrant) error {
+	return IsAllowedAny(ctx, []influxdb.Permission{g.Permission(), g.GranteePermission()})
+}
+
+// FindResourceGrantByID checks to see if the authorizer on context has read access to the grant provided.
+func (s *ResourceGrantService) FindResourceGrantByID(ctx context.Context, id influxdb.ID) (*influxdb.ResourceGrant, error) {
+	g, err := s.s.FindResourceGrantByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeReadResourceGrant(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// FindResourceGrants retrieves all grants that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *ResourceGrantService) FindResourceGrants(ctx context.Context, filter influxdb.ResourceGrantFilter, opt ...influxdb.FindOptions) ([]*influxdb.ResourceGrant, int, error) {
+	// TODO: we'll likely want to push this operation into the database eventually since fetching the whole list of data
+	// will likely be expensive.
+	gs, _, err := s.s.FindResourceGrants(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rgs := gs[:0]
+	for _, g := range gs {
+		err := authorizeReadResourceGrant(ctx, g)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rgs = append(rgs, g)
+	}
+	return rgs, len(rgs), nil
+}
+
+// CreateResourceGrant checks to see if the authorizer on context has write access to the resource being shared.
+func (s *ResourceGrantService) CreateResourceGrant(ctx context.Context, g *influxdb.ResourceGrant) error {
+	if _, _, err := AuthorizeWrite(ctx, g.ResourceType, g.ResourceID, g.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateResourceGrant(ctx, g)
+}
+
+// DeleteResourceGrant checks to see if the authorizer on context has write access to the shared resource.
+func (s *ResourceGrantService) DeleteResourceGrant(ctx context.Context, id influxdb.ID) error {
+	g, err := s.s.FindResourceGrantByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, g.ResourceType, g.ResourceID, g.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteResourceGrant(ctx, id)
+}
+
+// WithResourceGrants returns a copy of the authorizer that is also allowed
+// to read the resources shared with its organizations. A resource is shared
+// with the authorizer if it may read resources of the same type within the
+// grantee organization. Authorizers other than authorizations and sessions
+// are returned unchanged.
+func WithResourceGrants(ctx context.Context, svc influxdb.ResourceGrantService, a influxdb.Authorizer) (influxdb.Authorizer, error) {
+	var filter influxdb.ResourceGrantFilter
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		filter.GranteeOrgID = &a.OrgID
+	case *influxdb.Session:
+	default:
+		return a, nil
+	}
+
+	grants, _, err := svc.FindResourceGrants(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var granted []influxdb.Permission
+	for _, g := range grants {
+		if a.Allowed(g.GranteePermission()) {
+			granted = append(granted, g.Permission())
+		}
+	}
+	if len(granted) == 0 {
+		return a, nil
+	}
+
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		c := *a
+		c.Permissions = append(granted, a.Permissions...)
+		return &c, nil
+	case *influxdb.Session:
+		c := *a
+		c.Permissions = append(granted, a.Permissions...)
+		return &c, nil
+	}
+	return a, nil
+}
@@ -0,0 +1,126 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+// resourceGrantService is an in memory influxdb.ResourceGrantService.
+type resourceGrantService struct {
+	grants []*influxdb.ResourceGrant
+}
+
+func (s *resourceGrantService) FindResourceGrantByID(ctx context.Context, id influxdb.ID) (*influxdb.ResourceGrant, error) {
+	for _, g := range s.grants {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+	return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrResourceGrantNotFound}
+}
+
+func (s *resourceGrantService) FindResourceGrants(ctx context.Context, filter influxdb.ResourceGrantFilter, opt ...influxdb.FindOptions) ([]*influxdb.ResourceGrant, int, error) {
+	var gs []*influxdb.ResourceGrant
+	for _, g := range s.grants {
+		if filter.Match(g) {
+			gs = append(gs, g)
+		}
+	}
+	return gs, len(gs), nil
+}
+
+func (s *resourceGrantService) CreateResourceGrant(ctx context.Context, g *influxdb.ResourceGrant) error {
+	s.grants = append(s.grants, g)
+	return nil
+}
+
+func (s *resourceGrantService) DeleteResourceGrant(ctx context.Context, id influxdb.ID) error {
+	return nil
+}
+
+func TestWithResourceGrants(t *testing.T) {
+	var (
+		platformOrgID = influxdb.ID(1)
+		tenantOrgID   = influxdb.ID(2)
+		sharedBucket  = influxdb.ID(10)
+		privateBucket = influxdb.ID(11)
+	)
+	svc := &resourceGrantService{
+		grants: []*influxdb.ResourceGrant{{
+			ID:           100,
+			OrgID:        platformOrgID,
+			ResourceType: influxdb.BucketsResourceType,
+			ResourceID:   sharedBucket,
+			GranteeOrgID: tenantOrgID,
+		}},
+	}
+
+	readBucket := func(id influxdb.ID) influxdb.Permission {
+		return influxdb.Permission{
+			Action: influxdb.ReadAction,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: influxdbtesting.IDPtr(platformOrgID),
+				ID:    &id,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		allowed     bool
+	}{
+		{
+			name: "member of grantee org",
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.ReadAction,
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: influxdbtesting.IDPtr(tenantOrgID)},
+			}},
+			allowed: true,
+		},
+		{
+			name: "no bucket access in grantee org",
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.ReadAction,
+				Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, OrgID: influxdbtesting.IDPtr(tenantOrgID)},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &influxdb.Authorization{
+				OrgID:       tenantOrgID,
+				Status:      influxdb.Active,
+				Permissions: tt.permissions,
+			}
+			ctx := context.Background()
+			auth, err := authorizer.WithResourceGrants(ctx, svc, a)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := auth.Allowed(readBucket(sharedBucket)); got != tt.allowed {
+				t.Errorf("unexpected access to shared bucket: got %t, exp %t", got, tt.allowed)
+			}
+			if auth.Allowed(readBucket(privateBucket)) {
+				t.Error("expected no access to bucket that is not shared")
+			}
+			if len(a.Permissions) != len(tt.permissions) {
+				t.Error("expected original authorization to be unchanged")
+			}
+
+			// Only the owning organization can stop sharing the bucket.
+			ctx = influxdbcontext.SetAuthorizer(ctx, auth)
+			if err := authorizer.NewResourceGrantService(svc).DeleteResourceGrant(ctx, 100); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+				t.Errorf("expected unauthorized error deleting grant, got %v", err)
+			}
+		})
+	}
+}
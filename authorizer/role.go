@@ -0,0 +1,133 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// RoleService wraps a influxdb.RoleService and authorizes actions
+// against it appropriately.
+type RoleService struct {
+	s influxdb.RoleService
+}
+
+// NewRoleService constructs an instance of an authorizing role service.
+func NewRoleService(s influxdb.RoleService) *RoleService {
+	return &RoleService{
+		s: s,
+	}
+}
+
+// FindRoleByID checks to see if the authorizer on context has read access to the organization of the role.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, r.OrgID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// FindRoles retrieves all roles that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	// TODO: we'll likely want to push this operation into the database eventually since fetching the whole list of data
+	// will likely be expensive.
+	rs, _, err := s.s.FindRoles(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	roles := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeReadOrg(ctx, r.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		roles = append(roles, r)
+	}
+	return roles, len(roles), nil
+}
+
+// CreateRole checks to see if the authorizer on context has write access to the
+// organization of the role and holds every permission the role grants.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	if _, _, err := AuthorizeWriteOrg(ctx, r.OrgID); err != nil {
+		return err
+	}
+	if err := IsAllowedAll(ctx, r.Permissions); err != nil {
+		return err
+	}
+	return s.s.CreateRole(ctx, r)
+}
+
+// UpdateRole checks to see if the authorizer on context has write access to the
+// organization of the role and holds every permission the role grants.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	if _, err := s.authorizeWriteRole(ctx, id); err != nil {
+		return nil, err
+	}
+	if upd.Permissions != nil {
+		if err := IsAllowedAll(ctx, *upd.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	return s.s.UpdateRole(ctx, id, upd)
+}
+
+// DeleteRole checks to see if the authorizer on context has write access to the organization of the role.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	if _, err := s.authorizeWriteRole(ctx, id); err != nil {
+		return err
+	}
+	return s.s.DeleteRole(ctx, id)
+}
+
+// AddRoleMember checks to see if the authorizer on context has write access to
+// the organization of the role and holds every permission the role grants.
+func (s *RoleService) AddRoleMember(ctx context.Context, roleID, userID influxdb.ID) error {
+	r, err := s.authorizeWriteRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if err := IsAllowedAll(ctx, r.Permissions); err != nil {
+		return err
+	}
+	return s.s.AddRoleMember(ctx, roleID, userID)
+}
+
+// RemoveRoleMember checks to see if the authorizer on context has write access to the organization of the role.
+func (s *RoleService) RemoveRoleMember(ctx context.Context, roleID, userID influxdb.ID) error {
+	if _, err := s.authorizeWriteRole(ctx, roleID); err != nil {
+		return err
+	}
+	return s.s.RemoveRoleMember(ctx, roleID, userID)
+}
+
+// FindRoleMembers checks to see if the authorizer on context has read access to the organization of the role.
+func (s *RoleService) FindRoleMembers(ctx context.Context, roleID influxdb.ID) ([]influxdb.ID, error) {
+	if _, err := s.FindRoleByID(ctx, roleID); err != nil {
+		return nil, err
+	}
+	return s.s.FindRoleMembers(ctx, roleID)
+}
+
+func (s *RoleService) authorizeWriteRole(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, r.OrgID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,56 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.ScriptRevisionService = (*ScriptRevisionService)(nil)
+
+// ScriptRevisionService wraps a influxdb.ScriptRevisionService and authorizes actions
+// against it appropriately.
+type ScriptRevisionService struct {
+	s influxdb.ScriptRevisionService
+}
+
+// NewScriptRevisionService constructs an instance of an authorizing script revision service.
+func NewScriptRevisionService(s influxdb.ScriptRevisionService) *ScriptRevisionService {
+	return &ScriptRevisionService{
+		s: s,
+	}
+}
+
+// FindScriptRevisionByID checks to see if the authorizer on context has read access to the resource of the revision.
+func (s *ScriptRevisionService) FindScriptRevisionByID(ctx context.Context, id influxdb.ID) (*influxdb.ScriptRevision, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	rev, err := s.s.FindScriptRevisionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, rev.ResourceType, rev.ResourceID, rev.OrgID); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// FindScriptRevisions checks to see if the authorizer on context has read access to the resource of the revisions.
+func (s *ScriptRevisionService) FindScriptRevisions(ctx context.Context, filter influxdb.ScriptRevisionFilter) ([]*influxdb.ScriptRevision, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	revs, err := s.s.FindScriptRevisions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	// The revisions all belong to the same resource.
+	if len(revs) > 0 {
+		if _, _, err := AuthorizeRead(ctx, revs[0].ResourceType, revs[0].ResourceID, revs[0].OrgID); err != nil {
+			return nil, err
+		}
+	}
+	return revs, nil
+}
@@ -0,0 +1,38 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.SignedQueryService = (*SignedQueryService)(nil)
+
+// SignedQueryService wraps a influxdb.SignedQueryService and authorizes actions
+// against it appropriately.
+type SignedQueryService struct {
+	s influxdb.SignedQueryService
+}
+
+// NewSignedQueryService constructs an instance of an authorizing signed query service.
+func NewSignedQueryService(s influxdb.SignedQueryService) *SignedQueryService {
+	return &SignedQueryService{
+		s: s,
+	}
+}
+
+// SignQuery checks to see if the authorizer on context has read access to the bucket of the query.
+func (s *SignedQueryService) SignQuery(ctx context.Context, q *influxdb.SignedQuery) (string, error) {
+	a, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, q.BucketID, q.OrgID)
+	if err != nil {
+		return "", err
+	}
+	q.UserID = a.GetUserID()
+	return s.s.SignQuery(ctx, q)
+}
+
+// VerifySignedQuery requires no authorization as the signature itself
+// authorizes the query.
+func (s *SignedQueryService) VerifySignedQuery(ctx context.Context, signature string) (*influxdb.SignedQuery, error) {
+	return s.s.VerifySignedQuery(ctx, signature)
+}
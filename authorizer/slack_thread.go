@@ -0,0 +1,89 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.SlackThreadService = (*SlackThreadService)(nil)
+
+// SlackThreadService wraps a influxdb.SlackThreadService and authorizes actions
+// against it appropriately. The threads of an endpoint are authorized as the
+// endpoint.
+type SlackThreadService struct {
+	s influxdb.SlackThreadService
+}
+
+// NewSlackThreadService constructs an instance of an authorizing slack thread service.
+func NewSlackThreadService(s influxdb.SlackThreadService) *SlackThreadService {
+	return &SlackThreadService{
+		s: s,
+	}
+}
+
+// FindSlackThreadByID checks to see if the authorizer on context has read access to the endpoint of the thread.
+func (s *SlackThreadService) FindSlackThreadByID(ctx context.Context, id influxdb.ID) (*influxdb.SlackThread, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	t, err := s.s.FindSlackThreadByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.NotificationEndpointResourceType, t.EndpointID, t.OrgID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// FindSlackThreads retrieves all threads that match the provided filter and then filters the list down to only the threads that are authorized.
+func (s *SlackThreadService) FindSlackThreads(ctx context.Context, filter influxdb.SlackThreadFilter) ([]*influxdb.SlackThread, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ts, err := s.s.FindSlackThreads(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := ts[:0]
+	for _, t := range ts {
+		_, _, err := AuthorizeRead(ctx, influxdb.NotificationEndpointResourceType, t.EndpointID, t.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		threads = append(threads, t)
+	}
+	return threads, nil
+}
+
+// PutSlackThread checks to see if the authorizer on context has write access to the endpoint of the thread.
+func (s *SlackThreadService) PutSlackThread(ctx context.Context, t *influxdb.SlackThread) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWrite(ctx, influxdb.NotificationEndpointResourceType, t.EndpointID, t.OrgID); err != nil {
+		return err
+	}
+	return s.s.PutSlackThread(ctx, t)
+}
+
+// DeleteSlackThread checks to see if the authorizer on context has write access to the endpoint of the thread.
+func (s *SlackThreadService) DeleteSlackThread(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	t, err := s.s.FindSlackThreadByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.NotificationEndpointResourceType, t.EndpointID, t.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteSlackThread(ctx, id)
+}
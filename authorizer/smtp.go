@@ -0,0 +1,57 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.SMTPConfigService = (*SMTPConfigService)(nil)
+
+// SMTPConfigService wraps a influxdb.SMTPConfigService and authorizes actions
+// against it appropriately. The SMTP server of the instance may be read by an
+// authorizer which may read all resources, and changed by an operator.
+type SMTPConfigService struct {
+	s influxdb.SMTPConfigService
+}
+
+// NewSMTPConfigService constructs an instance of an authorizing smtp config service.
+func NewSMTPConfigService(s influxdb.SMTPConfigService) *SMTPConfigService {
+	return &SMTPConfigService{
+		s: s,
+	}
+}
+
+// FindSMTPConfig checks to see if the authorizer on context may read all resources.
+func (s *SMTPConfigService) FindSMTPConfig(ctx context.Context) (*influxdb.SMTPConfig, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FindSMTPConfig(ctx)
+}
+
+// PutSMTPConfig checks to see if the authorizer on context is an operator.
+func (s *SMTPConfigService) PutSMTPConfig(ctx context.Context, c *influxdb.SMTPConfig) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.PutSMTPConfig(ctx, c)
+}
+
+// DeleteSMTPConfig checks to see if the authorizer on context is an operator.
+func (s *SMTPConfigService) DeleteSMTPConfig(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.DeleteSMTPConfig(ctx)
+}
@@ -0,0 +1,58 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.StaleResourceReportService = (*StaleResourceReportService)(nil)
+
+// StaleResourceReportService wraps a influxdb.StaleResourceReportService and
+// authorizes actions against it appropriately. Reports cover the resources
+// of every organization, so they may be read by an authorizer which may read
+// all resources, and started by an operator.
+type StaleResourceReportService struct {
+	s influxdb.StaleResourceReportService
+}
+
+// NewStaleResourceReportService constructs an instance of an authorizing stale resource report service.
+func NewStaleResourceReportService(s influxdb.StaleResourceReportService) *StaleResourceReportService {
+	return &StaleResourceReportService{
+		s: s,
+	}
+}
+
+// StartStaleResourceReport checks to see if the authorizer on context is an operator.
+func (s *StaleResourceReportService) StartStaleResourceReport(ctx context.Context, days int) (*influxdb.StaleResourceReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.StartStaleResourceReport(ctx, days)
+}
+
+// FindStaleResourceReportByID checks to see if the authorizer on context may read all resources.
+func (s *StaleResourceReportService) FindStaleResourceReportByID(ctx context.Context, id influxdb.ID) (*influxdb.StaleResourceReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FindStaleResourceReportByID(ctx, id)
+}
+
+// FindStaleResourceReports checks to see if the authorizer on context may read all resources.
+func (s *StaleResourceReportService) FindStaleResourceReports(ctx context.Context, filter influxdb.StaleResourceReportFilter) ([]*influxdb.StaleResourceReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FindStaleResourceReports(ctx, filter)
+}
@@ -0,0 +1,35 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.SystemInfoService = (*SystemInfoService)(nil)
+
+// SystemInfoService wraps a influxdb.SystemInfoService and authorizes actions
+// against it appropriately. The environment of the server is that of every
+// organization, so it may be read by an authorizer which may read all resources.
+type SystemInfoService struct {
+	s influxdb.SystemInfoService
+}
+
+// NewSystemInfoService constructs an instance of an authorizing system info service.
+func NewSystemInfoService(s influxdb.SystemInfoService) *SystemInfoService {
+	return &SystemInfoService{
+		s: s,
+	}
+}
+
+// FindSystemInfo checks to see if the authorizer on context may read all resources.
+func (s *SystemInfoService) FindSystemInfo(ctx context.Context) (*influxdb.SystemInfo, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FindSystemInfo(ctx)
+}
@@ -0,0 +1,51 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.UserPreferencesService = (*UserPreferencesService)(nil)
+
+// UserPreferencesService wraps a influxdb.UserPreferencesService and authorizes actions
+// against it appropriately.
+type UserPreferencesService struct {
+	s influxdb.UserPreferencesService
+}
+
+// NewUserPreferencesService constructs an instance of an authorizing user preferences service.
+func NewUserPreferencesService(s influxdb.UserPreferencesService) *UserPreferencesService {
+	return &UserPreferencesService{
+		s: s,
+	}
+}
+
+// FindUserPreferences checks to see if the authorizer on context has read access to the user.
+func (s *UserPreferencesService) FindUserPreferences(ctx context.Context, userID influxdb.ID) (*influxdb.UserPreferences, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeReadResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return nil, err
+	}
+	return s.s.FindUserPreferences(ctx, userID)
+}
+
+// UpdateUserPreferences checks to see if the authorizer on context has write access to the user,
+// and read access to the organization it selects as the default of the user.
+func (s *UserPreferencesService) UpdateUserPreferences(ctx context.Context, userID influxdb.ID, upd influxdb.UserPreferencesUpdate) (*influxdb.UserPreferences, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return nil, err
+	}
+	if upd.DefaultOrgID != nil && upd.DefaultOrgID.Valid() {
+		if _, _, err := AuthorizeReadOrg(ctx, *upd.DefaultOrgID); err != nil {
+			return nil, err
+		}
+	}
+	return s.s.UpdateUserPreferences(ctx, userID, upd)
+}
@@ -0,0 +1,131 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.WebhookService = (*WebhookService)(nil)
+
+// WebhookService wraps a influxdb.WebhookService and authorizes actions
+// against it appropriately.
+type WebhookService struct {
+	s influxdb.WebhookService
+}
+
+// NewWebhookService constructs an instance of an authorizing webhook service.
+func NewWebhookService(s influxdb.WebhookService) *WebhookService {
+	return &WebhookService{
+		s: s,
+	}
+}
+
+// FindWebhookByID checks to see if the authorizer on context has read access to the organization of the webhook.
+func (s *WebhookService) FindWebhookByID(ctx context.Context, id influxdb.ID) (*influxdb.Webhook, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	w, err := s.s.FindWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadOrg(ctx, w.OrgID); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// FindWebhooks retrieves all webhooks that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *WebhookService) FindWebhooks(ctx context.Context, filter influxdb.WebhookFilter) ([]*influxdb.Webhook, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ws, err := s.s.FindWebhooks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	webhooks := ws[:0]
+	for _, w := range ws {
+		_, _, err := AuthorizeReadOrg(ctx, w.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook checks to see if the authorizer on context has write access to the organization of the webhook.
+func (s *WebhookService) CreateWebhook(ctx context.Context, w *influxdb.Webhook) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWriteOrg(ctx, w.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateWebhook(ctx, w)
+}
+
+// UpdateWebhook checks to see if the authorizer on context has write access to the organization of the webhook.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, id influxdb.ID, upd influxdb.WebhookUpdate) (*influxdb.Webhook, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	w, err := s.s.FindWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, w.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateWebhook(ctx, id, upd)
+}
+
+// DeleteWebhook checks to see if the authorizer on context has write access to the organization of the webhook.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	w, err := s.s.FindWebhookByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, w.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteWebhook(ctx, id)
+}
+
+// FindWebhookDeliveries checks to see if the authorizer on context has read access to the organization of the webhook.
+func (s *WebhookService) FindWebhookDeliveries(ctx context.Context, webhookID influxdb.ID) ([]*influxdb.WebhookDelivery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, err := s.FindWebhookByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.s.FindWebhookDeliveries(ctx, webhookID)
+}
+
+// AddWebhookDelivery checks to see if the authorizer on context has write access to the organization of the webhook.
+func (s *WebhookService) AddWebhookDelivery(ctx context.Context, d *influxdb.WebhookDelivery) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	w, err := s.s.FindWebhookByID(ctx, d.WebhookID)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, w.OrgID); err != nil {
+		return err
+	}
+	return s.s.AddWebhookDelivery(ctx, d)
+}
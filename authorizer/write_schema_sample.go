@@ -0,0 +1,35 @@
+package authorizer
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.WriteSchemaSampleService = (*WriteSchemaSampleService)(nil)
+
+// WriteSchemaSampleService wraps a influxdb.WriteSchemaSampleService and
+// authorizes actions against it appropriately.
+type WriteSchemaSampleService struct {
+	s influxdb.WriteSchemaSampleService
+}
+
+// NewWriteSchemaSampleService constructs an instance of an authorizing write schema sample service.
+func NewWriteSchemaSampleService(s influxdb.WriteSchemaSampleService) *WriteSchemaSampleService {
+	return &WriteSchemaSampleService{
+		s: s,
+	}
+}
+
+// SampleWriteSchema checks to see if the authorizer on context has read access to the bucket.
+func (s *WriteSchemaSampleService) SampleWriteSchema(ctx context.Context, orgID, bucketID influxdb.ID, window time.Duration) (*influxdb.WriteSchemaSample, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.SampleWriteSchema(ctx, orgID, bucketID, window)
+}
@@ -129,6 +129,9 @@ const (
 	NotificationEndpointResourceType = ResourceType("notificationEndpoints") // 15
 	// ChecksResourceType gives permission to one or more Checks.
 	ChecksResourceType = ResourceType("checks") // 16
+	// UnmaskedResourceType gives permission to read the values of one or more
+	// buckets masked by their masking rules.
+	UnmaskedResourceType = ResourceType("unmasked") // 17
 )
 
 // AllResourceTypes is the list of all known resource types.
@@ -150,6 +153,7 @@ var AllResourceTypes = []ResourceType{
 	NotificationRuleResourceType,     // 14
 	NotificationEndpointResourceType, // 15
 	ChecksResourceType,               // 16
+	UnmaskedResourceType,             // 17
 	// NOTE: when modifying this list, please update the swagger for components.schemas.Permission resource enum.
 }
 
@@ -167,6 +171,7 @@ var OrgResourceTypes = []ResourceType{
 	NotificationRuleResourceType,     // 14
 	NotificationEndpointResourceType, // 15
 	ChecksResourceType,               // 16
+	UnmaskedResourceType,             // 17
 }
 
 // Valid checks if the resource type is a member of the ResourceType enum.
@@ -194,6 +199,7 @@ func (t ResourceType) Valid() (err error) {
 	case NotificationRuleResourceType: // 14
 	case NotificationEndpointResourceType: // 15
 	case ChecksResourceType: // 16
+	case UnmaskedResourceType: // 17
 	default:
 		err = ErrInvalidResourceType
 	}
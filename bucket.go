@@ -34,13 +34,23 @@ const InfiniteRetention = 0
 
 // Bucket is a bucket. 🎉
 type Bucket struct {
-	ID                  ID            `json:"id,omitempty"`
-	OrgID               ID            `json:"orgID,omitempty"`
-	Type                BucketType    `json:"type"`
-	Name                string        `json:"name"`
-	Description         string        `json:"description"`
-	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
-	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	ID                  ID                   `json:"id,omitempty"`
+	OrgID               ID                   `json:"orgID,omitempty"`
+	Type                BucketType           `json:"type"`
+	Name                string               `json:"name"`
+	Description         string               `json:"description"`
+	RetentionPolicyName string               `json:"rp,omitempty"` // This to support v1 sources
+	RetentionPeriod     time.Duration        `json:"retentionPeriod"`
+	DuplicatePolicy     DuplicatePointPolicy `json:"duplicatePolicy,omitempty"`
+	WriteWindow         *WriteWindow         `json:"writeWindow,omitempty"`
+	DedupeWindow        time.Duration        `json:"dedupeWindow,omitempty"`
+	JSONWriteRules      []JSONWriteRule      `json:"jsonWriteRules,omitempty"`
+	AggregationHints    []AggregationHint    `json:"aggregationHints,omitempty"`
+	CompressionCodec    CompressionCodec     `json:"compressionCodec,omitempty"`
+	SoftTTLs            []SoftTTL            `json:"softTTLs,omitempty"`
+	LastValueCache      bool                 `json:"lastValueCache,omitempty"`
+	MaskingRules        []MaskingRule        `json:"maskingRules,omitempty"`
+	WriteConsistency    WriteConsistency     `json:"writeConsistency,omitempty"`
 	CRUDLog
 }
 
@@ -63,6 +73,143 @@ func ParseBucketType(s string) BucketType {
 	return BucketTypeUser
 }
 
+// DuplicatePointPolicy determines how points sharing a series key and
+// timestamp are resolved when written to a bucket. Policies other than
+// DuplicatePointLastWriteWins check the written points against the stored
+// ones, while compactions always resolve the duplicates written before the
+// policy was set as last-write-wins.
+type DuplicatePointPolicy string
+
+const (
+	// DuplicatePointLastWriteWins replaces an existing point with the most recently
+	// written one. It is the default policy.
+	DuplicatePointLastWriteWins DuplicatePointPolicy = "last-write-wins"
+	// DuplicatePointFirstWriteWins keeps the existing point and silently drops
+	// any later writes for the same series and timestamp.
+	DuplicatePointFirstWriteWins DuplicatePointPolicy = "first-write-wins"
+	// DuplicatePointReject keeps the existing point and rejects any later writes
+	// for the same series and timestamp.
+	DuplicatePointReject DuplicatePointPolicy = "reject"
+)
+
+// Valid returns an error if the policy is not one of the known policies.
+// The empty policy is valid and equivalent to DuplicatePointLastWriteWins.
+func (p DuplicatePointPolicy) Valid() error {
+	switch p {
+	case "", DuplicatePointLastWriteWins, DuplicatePointFirstWriteWins, DuplicatePointReject:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid duplicate point policy %q; expected one of %q, %q or %q", string(p), DuplicatePointLastWriteWins, DuplicatePointFirstWriteWins, DuplicatePointReject),
+	}
+}
+
+// OrDefault returns the policy, or DuplicatePointLastWriteWins if it is unset.
+func (p DuplicatePointPolicy) OrDefault() DuplicatePointPolicy {
+	if p == "" {
+		return DuplicatePointLastWriteWins
+	}
+	return p
+}
+
+// CompressionCodec is the compression applied to the string values of the
+// TSM files written for a bucket.
+type CompressionCodec string
+
+const (
+	// CompressionCodecSnappy compresses string values with snappy. It is the
+	// default codec.
+	CompressionCodecSnappy CompressionCodec = "snappy"
+	// CompressionCodecZstd compresses string values with zstd, which uses more
+	// CPU than snappy but takes significantly less disk space.
+	CompressionCodecZstd CompressionCodec = "zstd"
+)
+
+// Valid returns an error if the codec is not one of the known codecs.
+// The empty codec is valid and equivalent to CompressionCodecSnappy.
+func (c CompressionCodec) Valid() error {
+	switch c {
+	case "", CompressionCodecSnappy, CompressionCodecZstd:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid compression codec %q; expected one of %q or %q", string(c), CompressionCodecSnappy, CompressionCodecZstd),
+	}
+}
+
+// OrDefault returns the codec, or CompressionCodecSnappy if it is unset.
+func (c CompressionCodec) OrDefault() CompressionCodec {
+	if c == "" {
+		return CompressionCodecSnappy
+	}
+	return c
+}
+
+// WriteWindow bounds the timestamps of points that may be written to a bucket,
+// relative to the time they are written.
+type WriteWindow struct {
+	// Past is how far in the past a point's timestamp may be. Zero means
+	// there is no bound.
+	Past time.Duration `json:"past,omitempty"`
+	// Future is how far in the future a point's timestamp may be. Zero means
+	// there is no bound.
+	Future time.Duration `json:"future,omitempty"`
+	// ErrorBucketID, if set, identifies a bucket in the same organization to
+	// which points outside of the window are written. Otherwise they are rejected.
+	ErrorBucketID *ID `json:"errorBucketID,omitempty"`
+}
+
+// Valid returns an error if the write window is invalid.
+func (w *WriteWindow) Valid() error {
+	if w == nil {
+		return nil
+	}
+	if w.Past < 0 || w.Future < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "write window bounds must not be negative",
+		}
+	}
+	if w.ErrorBucketID != nil && !w.ErrorBucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "write window error bucket ID is invalid",
+		}
+	}
+	return nil
+}
+
+// Contains reports whether a point with timestamp t written at now is within
+// the write window.
+func (w *WriteWindow) Contains(now, t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	if w.Past > 0 && t.Before(now.Add(-w.Past)) {
+		return false
+	}
+	if w.Future > 0 && t.After(now.Add(w.Future)) {
+		return false
+	}
+	return true
+}
+
+// ValidDedupeWindow returns an error if the dedupe window of a bucket is
+// invalid. The dedupe window is how long the IDs of write batches are
+// remembered so that retried batches are not written twice; zero disables
+// deduplication.
+func ValidDedupeWindow(d time.Duration) error {
+	if d < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "dedupe window must not be negative",
+		}
+	}
+	return nil
+}
+
 // ops for buckets error and buckets op logs.
 var (
 	OpFindBucketByID = "FindBucketByID"
@@ -101,9 +248,19 @@ type BucketService interface {
 // BucketUpdate represents updates to a bucket.
 // Only fields which are set are updated.
 type BucketUpdate struct {
-	Name            *string        `json:"name,omitempty"`
-	Description     *string        `json:"description,omitempty"`
-	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	Name             *string               `json:"name,omitempty"`
+	Description      *string               `json:"description,omitempty"`
+	RetentionPeriod  *time.Duration        `json:"retentionPeriod,omitempty"`
+	DuplicatePolicy  *DuplicatePointPolicy `json:"duplicatePolicy,omitempty"`
+	WriteWindow      *WriteWindow          `json:"writeWindow,omitempty"`
+	DedupeWindow     *time.Duration        `json:"dedupeWindow,omitempty"`
+	JSONWriteRules   *[]JSONWriteRule      `json:"jsonWriteRules,omitempty"`
+	AggregationHints *[]AggregationHint    `json:"aggregationHints,omitempty"`
+	CompressionCodec *CompressionCodec     `json:"compressionCodec,omitempty"`
+	SoftTTLs         *[]SoftTTL            `json:"softTTLs,omitempty"`
+	LastValueCache   *bool                 `json:"lastValueCache,omitempty"`
+	MaskingRules     *[]MaskingRule        `json:"maskingRules,omitempty"`
+	WriteConsistency *WriteConsistency     `json:"writeConsistency,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
@@ -0,0 +1,199 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrBucketFamilyNotFound is the error for a missing bucket family.
+const ErrBucketFamilyNotFound = "bucket family not found"
+
+const (
+	OpFindBucketFamilyByID = "FindBucketFamilyByID"
+	OpFindBucketFamily     = "FindBucketFamily"
+	OpFindBucketFamilies   = "FindBucketFamilies"
+	OpCreateBucketFamily   = "CreateBucketFamily"
+	OpUpdateBucketFamily   = "UpdateBucketFamily"
+	OpDeleteBucketFamily   = "DeleteBucketFamily"
+)
+
+// Periods a bucket family rolls over to a new member bucket.
+const (
+	BucketFamilyPeriodWeek  = "week"
+	BucketFamilyPeriodMonth = "month"
+)
+
+// BucketFamilyService manages bucket families.
+type BucketFamilyService interface {
+	// FindBucketFamilyByID returns a single bucket family by ID.
+	FindBucketFamilyByID(ctx context.Context, id ID) (*BucketFamily, error)
+
+	// FindBucketFamily returns the first bucket family that matches filter.
+	FindBucketFamily(ctx context.Context, filter BucketFamilyFilter) (*BucketFamily, error)
+
+	// FindBucketFamilies returns a list of bucket families that match filter.
+	FindBucketFamilies(ctx context.Context, filter BucketFamilyFilter) ([]*BucketFamily, error)
+
+	// CreateBucketFamily creates a new bucket family and sets f.ID with the
+	// new identifier.
+	CreateBucketFamily(ctx context.Context, f *BucketFamily) error
+
+	// UpdateBucketFamily updates a single bucket family with changeset.
+	UpdateBucketFamily(ctx context.Context, id ID, upd BucketFamilyUpdate) (*BucketFamily, error)
+
+	// DeleteBucketFamily removes a bucket family by ID. Its member buckets
+	// are kept.
+	DeleteBucketFamily(ctx context.Context, id ID) error
+}
+
+// BucketFamilyRouter routes the writes to a bucket family to the member
+// bucket of the period they arrive in.
+type BucketFamilyRouter interface {
+	// CurrentBucket returns the member of the family named name which is
+	// current at the time of the call, creating it if it does not exist.
+	CurrentBucket(ctx context.Context, orgID ID, name string) (*Bucket, error)
+}
+
+// BucketFamily is a time-partitioned family of buckets. Writes to the name
+// of the family go to the member of the current period, named after the
+// family and the period, such as metrics-2024-06 or metrics-2024-W23, and
+// queries of the family read every member. Once a family has more than
+// MaxMembers members, the oldest are deleted.
+type BucketFamily struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Period is how often the family rolls over to a new member, either
+	// week or month. Weeks are ISO 8601 weeks in UTC.
+	Period string `json:"period"`
+
+	// MaxMembers is the number of members kept. Zero keeps every member.
+	MaxMembers int `json:"maxMembers"`
+
+	CRUDLog
+}
+
+// Valid returns an error if the bucket family is invalid.
+func (f *BucketFamily) Valid() error {
+	if !f.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	if f.Name == "" || strings.HasPrefix(f.Name, "_") {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket family name must not be empty or begin with an underscore",
+		}
+	}
+	switch f.Period {
+	case BucketFamilyPeriodWeek, BucketFamilyPeriodMonth:
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket family period must be week or month",
+		}
+	}
+	if f.MaxMembers < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket family max members must not be negative",
+		}
+	}
+	return nil
+}
+
+// PeriodStart returns the start of the period of the family containing t.
+func (f *BucketFamily) PeriodStart(t time.Time) time.Time {
+	t = t.UTC()
+	if f.Period == BucketFamilyPeriodWeek {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// MemberName returns the name of the member of the family of the period
+// containing t.
+func (f *BucketFamily) MemberName(t time.Time) string {
+	t = t.UTC()
+	if f.Period == BucketFamilyPeriodWeek {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%s-%04d-W%02d", f.Name, year, week)
+	}
+	return fmt.Sprintf("%s-%s", f.Name, t.Format("2006-01"))
+}
+
+// MemberPeriod returns the start of the period of the member of the family
+// named name, or false if name is not the name of a member.
+func (f *BucketFamily) MemberPeriod(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, f.Name+"-") {
+		return time.Time{}, false
+	}
+	suffix := name[len(f.Name)+1:]
+
+	if f.Period == BucketFamilyPeriodWeek {
+		// The suffix is YYYY-Www, where the week is an ISO 8601 week.
+		if len(suffix) != 8 || suffix[4:6] != "-W" {
+			return time.Time{}, false
+		}
+		year, err := strconv.Atoi(suffix[:4])
+		if err != nil {
+			return time.Time{}, false
+		}
+		week, err := strconv.Atoi(suffix[6:])
+		if err != nil || week < 1 || week > 53 {
+			return time.Time{}, false
+		}
+		// The 4th of January is always in the first week of its year.
+		start := f.PeriodStart(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)).AddDate(0, 0, 7*(week-1))
+		if y, w := start.ISOWeek(); y != year || w != week {
+			return time.Time{}, false
+		}
+		return start, true
+	}
+
+	t, err := time.Parse("2006-01", suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// BucketFamilyUpdate is the changeset of a bucket family. The name and
+// period of a family cannot change, since they name its members.
+type BucketFamilyUpdate struct {
+	Description *string `json:"description,omitempty"`
+	MaxMembers  *int    `json:"maxMembers,omitempty"`
+}
+
+// Apply applies the changeset to f.
+func (u BucketFamilyUpdate) Apply(f *BucketFamily) {
+	if u.Description != nil {
+		f.Description = *u.Description
+	}
+	if u.MaxMembers != nil {
+		f.MaxMembers = *u.MaxMembers
+	}
+}
+
+// BucketFamilyFilter represents a set of filters that restrict the returned
+// bucket families.
+type BucketFamilyFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+}
+
+// Match returns true if the bucket family matches the filter.
+func (f BucketFamilyFilter) Match(bf *BucketFamily) bool {
+	return (f.ID == nil || *f.ID == bf.ID) &&
+		(f.OrgID == nil || *f.OrgID == bf.OrgID) &&
+		(f.Name == nil || *f.Name == bf.Name)
+}
@@ -0,0 +1,56 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestBucketFamily_MemberName(t *testing.T) {
+	tests := []struct {
+		period string
+		t      time.Time
+		name   string
+		start  time.Time
+	}{
+		{
+			period: influxdb.BucketFamilyPeriodMonth,
+			t:      time.Date(2024, 6, 17, 13, 0, 0, 0, time.UTC),
+			name:   "metrics-2024-06",
+			start:  time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			period: influxdb.BucketFamilyPeriodWeek,
+			t:      time.Date(2024, 6, 9, 23, 0, 0, 0, time.UTC),
+			name:   "metrics-2024-W23",
+			start:  time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// The first ISO week of 2025 starts in 2024.
+			period: influxdb.BucketFamilyPeriodWeek,
+			t:      time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+			name:   "metrics-2025-W01",
+			start:  time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		f := &influxdb.BucketFamily{Name: "metrics", Period: tt.period}
+		if got := f.MemberName(tt.t); got != tt.name {
+			t.Errorf("MemberName(%s) = %q, want %q", tt.t, got, tt.name)
+		}
+		if got := f.PeriodStart(tt.t); !got.Equal(tt.start) {
+			t.Errorf("PeriodStart(%s) = %s, want %s", tt.t, got, tt.start)
+		}
+		if got, ok := f.MemberPeriod(tt.name); !ok || !got.Equal(tt.start) {
+			t.Errorf("MemberPeriod(%q) = %s, %v, want %s", tt.name, got, ok, tt.start)
+		}
+	}
+
+	f := &influxdb.BucketFamily{Name: "metrics", Period: influxdb.BucketFamilyPeriodMonth}
+	for _, name := range []string{"metrics", "metrics-2024", "metrics-prod-2024-06", "metrics-2024-W23", "other-2024-06"} {
+		if _, ok := f.MemberPeriod(name); ok {
+			t.Errorf("MemberPeriod(%q) is a member, want not a member", name)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// BucketMetadataService reports the measurements, tag keys and field keys
+// of a bucket from the indexes of the storage engine, without querying the
+// data of the bucket, for clients exploring the schema of a bucket.
+type BucketMetadataService interface {
+	// FindBucketMeasurements returns the measurements of the bucket, in the
+	// order of their names.
+	FindBucketMeasurements(ctx context.Context, orgID, bucketID ID) ([]*MeasurementMetadata, error)
+
+	// FindBucketTagKeys returns the tag keys of the series of the measurement
+	// of the bucket, or of all of its series if measurement is empty, in the
+	// order of their keys.
+	FindBucketTagKeys(ctx context.Context, orgID, bucketID ID, measurement string) ([]*TagKeyMetadata, error)
+
+	// FindBucketFieldKeys returns the field keys of the measurement of the
+	// bucket, or of all of its measurements if measurement is empty, in the
+	// order of their measurements and keys.
+	FindBucketFieldKeys(ctx context.Context, orgID, bucketID ID, measurement string) ([]*FieldKeyMetadata, error)
+}
+
+// MeasurementMetadata describes a measurement of a bucket.
+type MeasurementMetadata struct {
+	Name string `json:"name"`
+	// SeriesN is the number of series of the measurement.
+	SeriesN int64 `json:"seriesN"`
+	// FieldN is the number of field keys of the measurement.
+	FieldN int64 `json:"fieldN"`
+	// LastWritten is the greatest timestamp of the values of the
+	// measurement. It is missing for a measurement without values.
+	LastWritten *time.Time `json:"lastWritten,omitempty"`
+}
+
+// TagKeyMetadata describes a tag key of the series of a bucket.
+type TagKeyMetadata struct {
+	Key string `json:"key"`
+	// SeriesN is the number of series with the key.
+	SeriesN int64 `json:"seriesN"`
+	// ValueN is the number of values of the key.
+	ValueN int64 `json:"valueN"`
+}
+
+// FieldKeyMetadata describes a field key of a measurement of a bucket.
+type FieldKeyMetadata struct {
+	Measurement string `json:"measurement"`
+	Key         string `json:"key"`
+	// Types are the types of the values of the field. A field has more than
+	// one type if its series do not all have the same type.
+	Types []FieldType `json:"types"`
+	// SeriesN is the number of series of the field.
+	SeriesN int64 `json:"seriesN"`
+	// LastWritten is the greatest timestamp of the values of the field.
+	LastWritten *time.Time `json:"lastWritten,omitempty"`
+}
@@ -0,0 +1,72 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrBucketSnapshotNotFound is the error for a missing bucket snapshot.
+const ErrBucketSnapshotNotFound = "bucket snapshot not found"
+
+const (
+	OpCreateBucketSnapshot   = "CreateBucketSnapshot"
+	OpFindBucketSnapshotByID = "FindBucketSnapshotByID"
+	OpFindBucketSnapshots    = "FindBucketSnapshots"
+	OpDeleteBucketSnapshot   = "DeleteBucketSnapshot"
+	OpCloneBucketSnapshot    = "CloneBucketSnapshot"
+)
+
+// BucketSnapshot is a point-in-time copy of the data of a bucket. Snapshots
+// hard link the TSM files of the storage engine, so they take no space until
+// the engine compacts or deletes the files they link.
+//
+// Name is optional, and unique among the snapshots of a bucket. Queries
+// read a snapshot by name with from(bucket: "b", snapshot: "name").
+type BucketSnapshot struct {
+	ID          ID         `json:"id,omitempty"`
+	OrgID       ID         `json:"orgID"`
+	BucketID    ID         `json:"bucketID"`
+	Name        string     `json:"name,omitempty"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+
+	// Files is the number of TSM files linked by the snapshot, and Size
+	// their total size in bytes. The files of the engine are shared by all
+	// buckets, so they may hold data of other buckets.
+	Files int   `json:"files"`
+	Size  int64 `json:"size"`
+}
+
+// Expired reports whether the snapshot has expired at now.
+func (s *BucketSnapshot) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
+
+// BucketSnapshotFilter represents a set of filters that restrict the returned snapshots.
+type BucketSnapshotFilter struct {
+	OrgID    *ID
+	BucketID *ID
+	Name     *string
+}
+
+// BucketSnapshotService snapshots the data of buckets, and clones snapshots
+// into new buckets.
+type BucketSnapshotService interface {
+	// CreateBucketSnapshot snapshots the data written to the bucket of s
+	// before the call, and sets the ID, creation time and size of s.
+	CreateBucketSnapshot(ctx context.Context, s *BucketSnapshot) error
+
+	// FindBucketSnapshotByID returns a single snapshot by ID.
+	FindBucketSnapshotByID(ctx context.Context, id ID) (*BucketSnapshot, error)
+
+	// FindBucketSnapshots returns a list of snapshots that match filter.
+	FindBucketSnapshots(ctx context.Context, filter BucketSnapshotFilter) ([]*BucketSnapshot, error)
+
+	// DeleteBucketSnapshot removes a snapshot, releasing the files it links.
+	DeleteBucketSnapshot(ctx context.Context, id ID) error
+
+	// CloneBucketSnapshot creates b in the organization of the snapshot, and
+	// writes the data of the snapshot to it.
+	CloneBucketSnapshot(ctx context.Context, id ID, b *Bucket) error
+}
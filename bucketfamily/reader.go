@@ -0,0 +1,199 @@
+package bucketfamily
+
+import (
+	"context"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query/merge"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+// BucketLookup is an influxdb.BucketLookup which looks up the name of a
+// bucket family, which is not the name of a bucket, as the ID of the family.
+type BucketLookup struct {
+	lookup   influxdb.BucketLookup
+	families platform.BucketFamilyService
+}
+
+var _ influxdb.BucketLookup = (*BucketLookup)(nil)
+
+// NewBucketLookup returns a BucketLookup which looks up buckets with lookup
+// and the families it does not find with families.
+func NewBucketLookup(lookup influxdb.BucketLookup, families platform.BucketFamilyService) *BucketLookup {
+	return &BucketLookup{
+		lookup:   lookup,
+		families: families,
+	}
+}
+
+// Lookup returns the ID of the bucket or family named name.
+func (l *BucketLookup) Lookup(ctx context.Context, orgID platform.ID, name string) (platform.ID, bool) {
+	if id, ok := l.lookup.Lookup(ctx, orgID, name); ok {
+		return id, true
+	}
+	f, err := l.families.FindBucketFamily(ctx, platform.BucketFamilyFilter{
+		OrgID: &orgID,
+		Name:  &name,
+	})
+	if err != nil {
+		return platform.InvalidID(), false
+	}
+	return f.ID, true
+}
+
+// LookupName returns the name of the bucket or family with the ID id.
+func (l *BucketLookup) LookupName(ctx context.Context, orgID platform.ID, id platform.ID) string {
+	if name := l.lookup.LookupName(ctx, orgID, id); name != "" {
+		return name
+	}
+	f, err := l.families.FindBucketFamilyByID(ctx, id)
+	if err != nil || f.OrgID != orgID {
+		return ""
+	}
+	return f.Name
+}
+
+// Reader is an influxdb.Reader which reads every member of a bucket family
+// when the bucket read is a family.
+//
+// Members are read one after the other, and tables with the same group key
+// are merged. Only the members the query is authorized to read are read,
+// since they are found with the bucket service of the query.
+type Reader struct {
+	reader   influxdb.Reader
+	families platform.BucketFamilyService
+	buckets  platform.BucketService
+}
+
+var _ influxdb.Reader = (*Reader)(nil)
+
+// NewReader returns a Reader which reads the families of families through
+// reader. Members are found with buckets, which must authorize the buckets
+// found with the authorizer of the query.
+func NewReader(reader influxdb.Reader, families platform.BucketFamilyService, buckets platform.BucketService) *Reader {
+	return &Reader{
+		reader:   reader,
+		families: families,
+		buckets:  buckets,
+	}
+}
+
+// members returns the members of the family read by spec, or false if spec
+// reads a bucket.
+func (r *Reader) members(ctx context.Context, spec influxdb.ReadFilterSpec) ([]*platform.Bucket, bool, error) {
+	// Snapshots are of buckets, not families.
+	if spec.Snapshot != "" {
+		return nil, false, nil
+	}
+	f, err := r.families.FindBucketFamilyByID(ctx, spec.BucketID)
+	if platform.ErrorCode(err) == platform.ENotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if f.OrgID != spec.OrganizationID {
+		return nil, false, nil
+	}
+	ms, err := Members(ctx, r.buckets, f)
+	if err != nil {
+		return nil, false, err
+	}
+	return ms, true, nil
+}
+
+func (r *Reader) ReadFilter(ctx context.Context, spec influxdb.ReadFilterSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	ms, ok, err := r.members(ctx, spec)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return r.reader.ReadFilter(ctx, spec, alloc)
+	}
+	return r.merge(ms, execute.DefaultTimeColLabel, alloc, func(id platform.ID) (influxdb.TableIterator, error) {
+		spec.BucketID = id
+		return r.reader.ReadFilter(ctx, spec, alloc)
+	}), nil
+}
+
+func (r *Reader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	ms, ok, err := r.members(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return r.reader.ReadGroup(ctx, spec, alloc)
+	}
+	return r.merge(ms, execute.DefaultTimeColLabel, alloc, func(id platform.ID) (influxdb.TableIterator, error) {
+		spec.BucketID = id
+		return r.reader.ReadGroup(ctx, spec, alloc)
+	}), nil
+}
+
+func (r *Reader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeysSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	ms, ok, err := r.members(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return r.reader.ReadTagKeys(ctx, spec, alloc)
+	}
+	return r.merge(ms, execute.DefaultValueColLabel, alloc, func(id platform.ID) (influxdb.TableIterator, error) {
+		spec.BucketID = id
+		return r.reader.ReadTagKeys(ctx, spec, alloc)
+	}), nil
+}
+
+func (r *Reader) ReadTagValues(ctx context.Context, spec influxdb.ReadTagValuesSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	ms, ok, err := r.members(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return r.reader.ReadTagValues(ctx, spec, alloc)
+	}
+	return r.merge(ms, execute.DefaultValueColLabel, alloc, func(id platform.ID) (influxdb.TableIterator, error) {
+		spec.BucketID = id
+		return r.reader.ReadTagValues(ctx, spec, alloc)
+	}), nil
+}
+
+func (r *Reader) Close() {
+	r.reader.Close()
+}
+
+func (r *Reader) merge(members []*platform.Bucket, sortBy string, alloc *memory.Allocator, read func(platform.ID) (influxdb.TableIterator, error)) *familyIterator {
+	return &familyIterator{
+		members: members,
+		sortBy:  sortBy,
+		alloc:   alloc,
+		read:    read,
+	}
+}
+
+// familyIterator reads the tables of every member of a family, and merges
+// them once all of them have been read.
+type familyIterator struct {
+	members []*platform.Bucket
+	sortBy  string
+	alloc   *memory.Allocator
+	read    func(platform.ID) (influxdb.TableIterator, error)
+	stats   cursors.CursorStats
+}
+
+func (fi *familyIterator) Statistics() cursors.CursorStats { return fi.stats }
+
+func (fi *familyIterator) Do(f func(flux.Table) error) error {
+	m := merge.New(fi.sortBy)
+	for _, b := range fi.members {
+		ti, err := fi.read(b.ID)
+		if err != nil {
+			return err
+		}
+		if err := ti.Do(m.Add); err != nil {
+			return err
+		}
+		fi.stats.Add(ti.Statistics())
+	}
+	return m.Do(fi.alloc, f)
+}
@@ -0,0 +1,210 @@
+// Package bucketfamily rolls bucket families over to new member buckets,
+// routes the writes to a family to its current member, and reads every
+// member in queries of a family.
+package bucketfamily
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	platform "github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// checkInterval is how often families are checked to see if they rolled
+// over.
+var checkInterval = time.Minute
+
+// Service creates the member of the current period of each bucket family,
+// and deletes the oldest members of families which have more members than
+// they keep.
+//
+// Members are created when a family rolls over, or by the first write to a
+// family in a period if that comes first. They are ordinary buckets, named
+// after the family and their period; deleting a family keeps them.
+type Service struct {
+	log      *zap.Logger
+	families platform.BucketFamilyService
+	buckets  platform.BucketService
+	now      func() time.Time
+
+	// mu serializes creating members, so that concurrent writes to a
+	// family create its member once.
+	mu sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ platform.BucketFamilyRouter = (*Service)(nil)
+
+// NewService returns a Service managing the members of the families of
+// families, which are the buckets of buckets.
+func NewService(log *zap.Logger, families platform.BucketFamilyService, buckets platform.BucketService) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		log:      log,
+		families: families,
+		buckets:  buckets,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Open starts rolling families over as their periods end.
+func (s *Service) Open(ctx context.Context) error {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.rolloverAll(s.ctx); err != nil {
+				s.log.Error("Failed to roll bucket families over", zap.Error(err))
+			}
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops rolling families over, and waits for the running rollover to
+// finish.
+func (s *Service) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Service) rolloverAll(ctx context.Context) error {
+	fs, err := s.families.FindBucketFamilies(ctx, platform.BucketFamilyFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := s.Rollover(ctx, f); err != nil {
+			s.log.Info("Bucket family rollover failed",
+				zap.Stringer("family_id", f.ID),
+				zap.String("family", f.Name),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Rollover creates the current member of f if it does not exist, and
+// deletes the oldest members of f beyond the number of members it keeps.
+func (s *Service) Rollover(ctx context.Context, f *platform.BucketFamily) error {
+	current, err := s.currentMember(ctx, f)
+	if err != nil {
+		return err
+	}
+	if f.MaxMembers == 0 {
+		return nil
+	}
+
+	ms, err := Members(ctx, s.buckets, f)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(ms)-f.MaxMembers; i++ {
+		if ms[i].ID == current.ID {
+			continue
+		}
+		if err := s.buckets.DeleteBucket(ctx, ms[i].ID); err != nil {
+			return err
+		}
+		s.log.Info("Deleted bucket family member",
+			zap.String("family", f.Name),
+			zap.String("bucket", ms[i].Name),
+			zap.Stringer("bucket_id", ms[i].ID))
+	}
+	return nil
+}
+
+// CurrentBucket returns the member of the family named name which is current
+// at the time of the call, creating it if it does not exist. It returns a
+// not found error if there is no such family.
+func (s *Service) CurrentBucket(ctx context.Context, orgID platform.ID, name string) (*platform.Bucket, error) {
+	f, err := s.families.FindBucketFamily(ctx, platform.BucketFamilyFilter{
+		OrgID: &orgID,
+		Name:  &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.currentMember(ctx, f)
+}
+
+func (s *Service) currentMember(ctx context.Context, f *platform.BucketFamily) (*platform.Bucket, error) {
+	name := f.MemberName(s.now())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.buckets.FindBucket(ctx, platform.BucketFilter{
+		OrganizationID: &f.OrgID,
+		Name:           &name,
+	})
+	if platform.ErrorCode(err) != platform.ENotFound {
+		return b, err
+	}
+
+	b = &platform.Bucket{
+		OrgID:       f.OrgID,
+		Type:        platform.BucketTypeUser,
+		Name:        name,
+		Description: fmt.Sprintf("Member of the bucket family %s", f.Name),
+	}
+	if err := s.buckets.CreateBucket(ctx, b); err != nil {
+		return nil, err
+	}
+	s.log.Info("Created bucket family member",
+		zap.String("family", f.Name),
+		zap.String("bucket", b.Name),
+		zap.Stringer("bucket_id", b.ID))
+	return b, nil
+}
+
+// Members returns the members of f among the buckets of buckets, oldest
+// first.
+func Members(ctx context.Context, buckets platform.BucketService, f *platform.BucketFamily) ([]*platform.Bucket, error) {
+	bs, _, err := buckets.FindBuckets(ctx, platform.BucketFilter{OrganizationID: &f.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	type member struct {
+		b      *platform.Bucket
+		period time.Time
+	}
+	var ms []member
+	for _, b := range bs {
+		if period, ok := f.MemberPeriod(b.Name); ok {
+			ms = append(ms, member{b: b, period: period})
+		}
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].period.Before(ms[j].period)
+	})
+
+	members := make([]*platform.Bucket, 0, len(ms))
+	for _, m := range ms {
+		members = append(members, m.b)
+	}
+	return members, nil
+}
@@ -0,0 +1,85 @@
+package bucketfamily
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_Rollover(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	// Not a member, although its name begins with the name of the family.
+	other := &influxdb.Bucket{OrgID: org.ID, Name: "metrics-prod"}
+	if err := store.CreateBucket(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &influxdb.BucketFamily{
+		OrgID:      org.ID,
+		Name:       "metrics",
+		Period:     influxdb.BucketFamilyPeriodMonth,
+		MaxMembers: 2,
+	}
+	if err := store.CreateBucketFamily(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewService(zaptest.NewLogger(t), store, store)
+	now := time.Date(2024, 4, 20, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	b, err := s.CurrentBucket(ctx, org.ID, "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Name != "metrics-2024-04" {
+		t.Errorf("got current member %q, want metrics-2024-04", b.Name)
+	}
+	again, err := s.CurrentBucket(ctx, org.ID, "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != b.ID {
+		t.Errorf("expected the current member to be created once, got %s and %s", b.ID, again.ID)
+	}
+
+	if _, err := s.CurrentBucket(ctx, org.ID, "missing"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the current member of a missing family to be not found, got %v", err)
+	}
+
+	for _, month := range []time.Month{time.May, time.June} {
+		now = time.Date(2024, month, 1, 0, 0, 0, 0, time.UTC)
+		if err := s.Rollover(ctx, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ms, err := Members(ctx, store, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range ms {
+		names = append(names, m.Name)
+	}
+	if len(names) != 2 || names[0] != "metrics-2024-05" || names[1] != "metrics-2024-06" {
+		t.Errorf("got members %v, want [metrics-2024-05 metrics-2024-06]", names)
+	}
+	if _, err := store.FindBucketByID(ctx, other.ID); err != nil {
+		t.Errorf("expected a bucket which is not a member to be kept, got %v", err)
+	}
+}
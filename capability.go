@@ -0,0 +1,54 @@
+package influxdb
+
+import "context"
+
+// The names of the capabilities of a server.
+const (
+	CapabilityFlux           = "flux"
+	CapabilityInfluxQL       = "influxql"
+	CapabilityWrite          = "write"
+	CapabilityDelete         = "delete"
+	CapabilityBackup         = "backup"
+	CapabilitySignedQueries  = "signed_queries"
+	CapabilityDBRPImport     = "dbrp_import"
+	CapabilityLastValues     = "last_values"
+	CapabilityLegalHolds     = "legal_holds"
+	CapabilityParquetExport  = "parquet_export"
+	CapabilityBucketSnapshot = "bucket_snapshots"
+	CapabilityQueryPages     = "query_result_pages"
+)
+
+// CapabilityService returns the capabilities of a server, so that clients
+// adapt to the features it has rather than probing for them.
+type CapabilityService interface {
+	FindCapabilities(ctx context.Context) (*Capabilities, error)
+}
+
+// Capabilities are the features enabled on a server.
+type Capabilities struct {
+	// Version is the version of the server.
+	Version      string       `json:"version"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// Capability is a feature enabled on a server. Features missing from the
+// capabilities of a server are disabled, or not supported by it.
+type Capability struct {
+	Name string `json:"name"`
+	// Version is the version of the API of the feature, incremented when it
+	// changes in a way clients must adapt to.
+	Version int `json:"version"`
+	// Limits are the limits of the feature by name. A limit of zero is
+	// unlimited.
+	Limits map[string]int64 `json:"limits,omitempty"`
+}
+
+// Find returns the capability named name, and whether it is enabled.
+func (c *Capabilities) Find(name string) (Capability, bool) {
+	for _, cp := range c.Capabilities {
+		if cp.Name == name {
+			return cp, true
+		}
+	}
+	return Capability{}, false
+}
@@ -0,0 +1,44 @@
+package influxdb
+
+import (
+	"context"
+)
+
+// SeriesCardinalityService reports which tags contribute the most series to
+// a bucket, to find the source of a high series cardinality.
+type SeriesCardinalityService interface {
+	// SeriesCardinalityReport returns the series cardinality of the bucket
+	// broken down by tag key and value. At most limit tag keys, and limit
+	// values of each key, are reported if limit is greater than 0.
+	SeriesCardinalityReport(ctx context.Context, orgID, bucketID ID, limit int) (*SeriesCardinalityReport, error)
+}
+
+// SeriesCardinalityReport is the series cardinality of a bucket broken down
+// by tag key and value.
+type SeriesCardinalityReport struct {
+	OrgID    ID    `json:"orgID"`
+	BucketID ID    `json:"bucketID"`
+	SeriesN  int64 `json:"seriesN"`
+	// TagKeys are the tag keys of the series of the bucket, the keys with
+	// the most values first. The measurement and the field of the series
+	// are reported as the _measurement and _field keys.
+	TagKeys []TagKeyCardinality `json:"tagKeys"`
+}
+
+// TagKeyCardinality is the series cardinality of a tag key.
+type TagKeyCardinality struct {
+	Key string `json:"key"`
+	// SeriesN is the number of series with the key.
+	SeriesN int64 `json:"seriesN"`
+	// ValueN is the number of values of the key.
+	ValueN int64 `json:"valueN"`
+	// Values are the values of the key, the values with the most series
+	// first.
+	Values []TagValueCardinality `json:"values"`
+}
+
+// TagValueCardinality is the series cardinality of a tag value.
+type TagValueCardinality struct {
+	Value   string `json:"value"`
+	SeriesN int64  `json:"seriesN"`
+}
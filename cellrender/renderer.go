@@ -0,0 +1,254 @@
+// Package cellrender renders the results of the queries of dashboard cells
+// as images on the server, without a browser.
+package cellrender
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/influxdb/v2"
+)
+
+// The margins of the plot leave room for the axes.
+const (
+	marginLeft   = 40
+	marginRight  = 10
+	marginTop    = 10
+	marginBottom = 30
+
+	gridLines = 4
+)
+
+var (
+	background = color.RGBA{R: 0x20, G: 0x20, B: 0x2a, A: 0xff}
+	axisColor  = color.RGBA{R: 0x99, G: 0x99, B: 0xa6, A: 0xff}
+	gridColor  = color.RGBA{R: 0x34, G: 0x34, B: 0x3f, A: 0xff}
+
+	// palette is the color scheme of the series, as used by the UI.
+	palette = []color.RGBA{
+		{R: 0x31, G: 0xc0, B: 0xf6, A: 0xff},
+		{R: 0xa5, G: 0x00, B: 0xa5, A: 0xff},
+		{R: 0xff, G: 0x7e, B: 0x27, A: 0xff},
+		{R: 0x7c, G: 0xe4, B: 0x90, A: 0xff},
+		{R: 0xf9, G: 0x5f, B: 0x53, A: 0xff},
+		{R: 0xff, G: 0xd2, B: 0x55, A: 0xff},
+	}
+)
+
+// Renderer renders the values of each table of the results over time as the
+// lines of a chart.
+type Renderer struct{}
+
+var _ influxdb.DashboardCellRenderer = (*Renderer)(nil)
+
+// NewRenderer returns a new renderer of dashboard cells.
+func NewRenderer() *Renderer {
+	return &Renderer{}
+}
+
+// RenderPNG writes the numeric _value column of each table plotted against
+// its _time column as a PNG image. Tables without both columns are skipped.
+func (r *Renderer) RenderPNG(ctx context.Context, w io.Writer, view *influxdb.View, results flux.ResultIterator, width, height int) error {
+	defer results.Release()
+
+	var series [][]point
+	for results.More() {
+		if err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			ps, err := readPoints(tbl)
+			if err != nil {
+				return err
+			}
+			if len(ps) > 0 {
+				series = append(series, ps)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if err := results.Err(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return png.Encode(w, plot(series, width, height))
+}
+
+type point struct {
+	t int64
+	v float64
+}
+
+func readPoints(tbl flux.Table) ([]point, error) {
+	timeIdx, valueIdx := -1, -1
+	for j, c := range tbl.Cols() {
+		switch c.Label {
+		case execute.DefaultTimeColLabel:
+			if c.Type == flux.TTime {
+				timeIdx = j
+			}
+		case execute.DefaultValueColLabel:
+			if c.Type == flux.TFloat || c.Type == flux.TInt || c.Type == flux.TUInt {
+				valueIdx = j
+			}
+		}
+	}
+	if timeIdx < 0 || valueIdx < 0 {
+		tbl.Done()
+		return nil, nil
+	}
+	valueType := tbl.Cols()[valueIdx].Type
+
+	var ps []point
+	err := tbl.Do(func(cr flux.ColReader) error {
+		ts := cr.Times(timeIdx)
+		for i := 0; i < cr.Len(); i++ {
+			if !ts.IsValid(i) {
+				continue
+			}
+			var v float64
+			switch valueType {
+			case flux.TFloat:
+				vs := cr.Floats(valueIdx)
+				if !vs.IsValid(i) {
+					continue
+				}
+				v = vs.Value(i)
+			case flux.TInt:
+				vs := cr.Ints(valueIdx)
+				if !vs.IsValid(i) {
+					continue
+				}
+				v = float64(vs.Value(i))
+			case flux.TUInt:
+				vs := cr.UInts(valueIdx)
+				if !vs.IsValid(i) {
+					continue
+				}
+				v = float64(vs.Value(i))
+			}
+			ps = append(ps, point{t: ts.Value(i), v: v})
+		}
+		return nil
+	})
+	return ps, err
+}
+
+func plot(series [][]point, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+
+	left, right := marginLeft, width-marginRight
+	top, bottom := marginTop, height-marginBottom
+	if right <= left || bottom <= top {
+		return img
+	}
+
+	for i := 1; i <= gridLines; i++ {
+		y := bottom - i*(bottom-top)/gridLines
+		line(img, left, y, right, y, gridColor)
+	}
+	line(img, left, top, left, bottom, axisColor)
+	line(img, left, bottom, right, bottom, axisColor)
+
+	if len(series) == 0 {
+		return img
+	}
+
+	tmin, tmax := series[0][0].t, series[0][0].t
+	vmin, vmax := series[0][0].v, series[0][0].v
+	for _, ps := range series {
+		for _, p := range ps {
+			if p.t < tmin {
+				tmin = p.t
+			}
+			if p.t > tmax {
+				tmax = p.t
+			}
+			if p.v < vmin {
+				vmin = p.v
+			}
+			if p.v > vmax {
+				vmax = p.v
+			}
+		}
+	}
+	if vmin == vmax {
+		vmin, vmax = vmin-1, vmax+1
+	}
+
+	x := func(t int64) int {
+		if tmin == tmax {
+			return (left + right) / 2
+		}
+		return left + int(float64(t-tmin)/float64(tmax-tmin)*float64(right-left))
+	}
+	y := func(v float64) int {
+		return bottom - int((v-vmin)/(vmax-vmin)*float64(bottom-top))
+	}
+
+	for i, ps := range series {
+		c := palette[i%len(palette)]
+		if len(ps) == 1 {
+			px, py := x(ps[0].t), y(ps[0].v)
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					img.SetRGBA(px+dx, py+dy, c)
+				}
+			}
+			continue
+		}
+		for j := 1; j < len(ps); j++ {
+			x0, y0 := x(ps[j-1].t), y(ps[j-1].v)
+			x1, y1 := x(ps[j].t), y(ps[j].v)
+			line(img, x0, y0, x1, y1, c)
+			// Draw the line again one pixel below so that it is legible
+			// when scaled down.
+			line(img, x0, y0+1, x1, y1+1, c)
+		}
+	}
+	return img
+}
+
+// line draws a line from x0, y0 to x1, y1 with Bresenham's algorithm.
+func line(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,56 @@
+package cellrender_test
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/cellrender"
+)
+
+const results = `#datatype,string,long,dateTime:RFC3339,double,string
+#group,false,false,false,false,true
+#default,_result,,,,
+,result,table,_time,_value,host
+,,0,2020-01-01T00:00:00Z,1,a
+,,0,2020-01-01T00:01:00Z,3,a
+,,0,2020-01-01T00:02:00Z,2,a
+,,1,2020-01-01T00:00:00Z,5,b
+,,1,2020-01-01T00:02:00Z,4,b
+`
+
+func TestRenderer_RenderPNG(t *testing.T) {
+	itr, err := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(ioutil.NopCloser(strings.NewReader(results)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	view := &influxdb.View{Properties: influxdb.XYViewProperties{Type: influxdb.ViewPropertyTypeXY}}
+	if err := cellrender.NewRenderer().RenderPNG(context.Background(), &buf, view, itr, 200, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Fatalf("expected a 200x100 image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	// The second point of the first series is in the middle of the plot,
+	// which is otherwise empty at that time below it.
+	background := img.At(199, 0)
+	if img.At(115, 41) == background {
+		t.Error("expected the first series in the middle of the plot")
+	}
+	if img.At(115, 60) != background {
+		t.Error("expected no series below the first one")
+	}
+}
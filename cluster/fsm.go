@@ -0,0 +1,139 @@
+package cluster
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/hashicorp/raft"
+	"go.uber.org/zap"
+)
+
+// fsm applies replicated ops to the bolt database of the metadata store.
+type fsm struct {
+	db  *bolt.DB
+	log *zap.Logger
+}
+
+var _ raft.FSM = (*fsm)(nil)
+
+// Apply applies the ops of a log entry in a single transaction. It returns
+// the error of the transaction, if any.
+func (f *fsm) Apply(l *raft.Log) interface{} {
+	ops, err := decodeOps(l.Data)
+	if err != nil {
+		f.log.Error("Failed to decode replicated ops", zap.Uint64("index", l.Index), zap.Error(err))
+		return err
+	}
+	return applyOps(f.db, ops)
+}
+
+func applyOps(db *bolt.DB, ops []op) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, o := range ops {
+			b, err := tx.CreateBucketIfNotExists(o.Bucket)
+			if err != nil {
+				return err
+			}
+			switch o.Type {
+			case opPut:
+				v := o.Value
+				if v == nil {
+					v = []byte{}
+				}
+				err = b.Put(o.Key, v)
+			case opDelete:
+				err = b.Delete(o.Key)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Snapshot returns a snapshot of the whole bolt database.
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	tx, err := f.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return &fsmSnapshot{tx: tx}, nil
+}
+
+// Restore replaces the buckets of the bolt database with those of the snapshot.
+// Buckets which are not in the snapshot, such as those of services which
+// do not use the metadata store, are left alone.
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	tmp, err := ioutil.TempFile("", "influxd-cluster-snapshot")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, rc); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	snap, err := bolt.Open(tmp.Name(), 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer snap.Close()
+
+	return snap.View(func(stx *bolt.Tx) error {
+		return f.db.Update(func(tx *bolt.Tx) error {
+			return stx.ForEach(func(name []byte, sb *bolt.Bucket) error {
+				if tx.Bucket(name) != nil {
+					if err := tx.DeleteBucket(name); err != nil {
+						return err
+					}
+				}
+				b, err := tx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return copyBucket(b, sb)
+			})
+		})
+	})
+}
+
+// copyBucket copies the keys and nested buckets of src to dst.
+func copyBucket(dst, src *bolt.Bucket) error {
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+		nested, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(nested, src.Bucket(k))
+	})
+}
+
+// fsmSnapshot writes a consistent copy of the bolt database.
+type fsmSnapshot struct {
+	tx *bolt.Tx
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if _, err := s.tx.WriteTo(sink); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {
+	_ = s.tx.Rollback()
+}
@@ -0,0 +1,146 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	ibolt "github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func mustOpenDB(t *testing.T, dir, name string) *bolt.DB {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(dir, name), 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func bucketContents(t *testing.T, db *bolt.DB, name string) map[string]string {
+	t.Helper()
+	contents := make(map[string]string)
+	if err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(name))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			contents[string(k)] = string(v)
+			return nil
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return contents
+}
+
+type snapshotSink struct {
+	bytes.Buffer
+}
+
+func (s *snapshotSink) ID() string    { return "test" }
+func (s *snapshotSink) Cancel() error { return nil }
+func (s *snapshotSink) Close() error  { return nil }
+
+func TestFSM_Replication(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cluster-fsm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	leaderDB := mustOpenDB(t, dir, "leader.db")
+	defer leaderDB.Close()
+	leader := ibolt.NewKVStore(zaptest.NewLogger(t), "")
+	leader.WithDB(leaderDB)
+
+	// Mutations are recorded and rolled back from the local copy.
+	var rec *recordingTx
+	err = leader.Update(context.Background(), func(tx kv.Tx) error {
+		rec = newRecordingTx(tx)
+		b, err := rec.Bucket([]byte("orgsv1"))
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte("a"), []byte("1")); err != nil {
+			return err
+		}
+		if err := b.Put([]byte("b"), []byte("2")); err != nil {
+			return err
+		}
+		// The transaction reads its own writes.
+		if v, err := b.Get([]byte("a")); err != nil || string(v) != "1" {
+			t.Errorf("expected transaction to read its own write, got %q, %v", v, err)
+		}
+		if err := b.Delete([]byte("a")); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != errRollback {
+		t.Fatal(err)
+	}
+	if !rec.mutates() {
+		t.Fatal("expected transaction to be recorded as mutating")
+	}
+	if got := bucketContents(t, leaderDB, "orgsv1"); len(got) != 0 {
+		t.Fatalf("expected local transaction to be rolled back, got %v", got)
+	}
+
+	// The replicated ops are applied to the local copy.
+	cmd, err := encodeOps(rec.ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, err := decodeOps(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := applyOps(leaderDB, ops); err != nil {
+		t.Fatal(err)
+	}
+	if got := bucketContents(t, leaderDB, "orgsv1"); len(got) != 1 || got["b"] != "2" {
+		t.Fatalf("unexpected contents after applying ops: %v", got)
+	}
+
+	// Nodes restored from a snapshot receive the whole store, and keep the
+	// buckets which are not in it.
+	f := &fsm{db: leaderDB, log: zaptest.NewLogger(t)}
+	snap, err := f.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sink snapshotSink
+	if err := snap.Persist(&sink); err != nil {
+		t.Fatal(err)
+	}
+	snap.Release()
+
+	followerDB := mustOpenDB(t, dir, "follower.db")
+	defer followerDB.Close()
+	if err := applyOps(followerDB, []op{
+		{Type: opPut, Bucket: []byte("orgsv1"), Key: []byte("stale"), Value: []byte("x")},
+		{Type: opPut, Bucket: []byte("local"), Key: []byte("k"), Value: []byte("v")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	follower := &fsm{db: followerDB, log: zaptest.NewLogger(t)}
+	if err := follower.Restore(ioutil.NopCloser(&sink)); err != nil {
+		t.Fatal(err)
+	}
+	if got := bucketContents(t, followerDB, "orgsv1"); len(got) != 1 || got["b"] != "2" {
+		t.Fatalf("unexpected contents after restore: %v", got)
+	}
+	if got := bucketContents(t, followerDB, "local"); got["k"] != "v" {
+		t.Fatalf("expected buckets missing from the snapshot to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	"crypto/subtle"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const prefixCluster = "/api/v2/cluster"
+
+// Handler serves the requests nodes of the cluster send to each other, and
+// the status of the cluster. Requests are authenticated with the shared
+// cluster secret rather than tokens, so that they work while the metadata
+// store is unavailable.
+type Handler struct {
+	chi.Router
+	api   *kithttp.API
+	log   *zap.Logger
+	store *Store
+}
+
+// NewHandler returns a Handler for the cluster of the store.
+func NewHandler(log *zap.Logger, store *Store) *Handler {
+	h := &Handler{
+		api:   kithttp.NewAPI(kithttp.WithLog(log)),
+		log:   log,
+		store: store,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+		h.authenticate,
+	)
+
+	r.Get("/", h.handleGetStatus)
+	r.Post("/join", h.handlePostJoin)
+	r.Post("/apply", h.handlePostApply)
+	r.Delete("/nodes/{id}", h.handleDeleteNode)
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix of the routes of the handler.
+func (h *Handler) Prefix() string {
+	return prefixCluster
+}
+
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get(SecretHeader)
+		if h.store.config.Secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(h.store.config.Secret)) != 1 {
+			h.api.Err(w, &influxdb.Error{
+				Code: influxdb.EUnauthorized,
+				Msg:  "invalid cluster secret",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGetStatus is the HTTP handler for the GET /api/v2/cluster route.
+func (h *Handler) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	st, err := h.store.Status()
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusOK, st)
+}
+
+// handlePostJoin is the HTTP handler for the POST /api/v2/cluster/join route.
+func (h *Handler) handlePostJoin(w http.ResponseWriter, r *http.Request) {
+	var n Node
+	if err := h.api.DecodeJSON(r.Body, &n); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	if n.ID == "" || n.RaftAddress == "" {
+		h.api.Err(w, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "node ID and raft address are required",
+		})
+		return
+	}
+
+	if err := h.store.Join(r.Context(), n); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusNoContent, nil)
+}
+
+type applyResponse struct {
+	Index uint64 `json:"index"`
+}
+
+// handlePostApply is the HTTP handler for the POST /api/v2/cluster/apply
+// route. Followers send the mutations of their transactions to the leader
+// through it.
+func (h *Handler) handlePostApply(w http.ResponseWriter, r *http.Request) {
+	cmd, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	if _, err := decodeOps(cmd); err != nil {
+		h.api.Err(w, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid replicated ops",
+			Err:  err,
+		})
+		return
+	}
+
+	index, err := h.store.applyLeader(cmd)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusOK, applyResponse{Index: index})
+}
+
+// handleDeleteNode is the HTTP handler for the DELETE /api/v2/cluster/nodes/:id route.
+func (h *Handler) handleDeleteNode(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.RemoveNode(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusNoContent, nil)
+}
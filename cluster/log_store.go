@@ -0,0 +1,185 @@
+package cluster
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/hashicorp/raft"
+)
+
+var (
+	logsBucket   = []byte("logs")
+	stableBucket = []byte("stable")
+
+	// errKeyNotFound is returned by the stable store for unknown keys, as
+	// expected by raft.
+	errKeyNotFound = errors.New("not found")
+)
+
+// logStore is a raft.LogStore and raft.StableStore backed by a bolt database
+// separate from the metadata store.
+type logStore struct {
+	db *bolt.DB
+}
+
+var (
+	_ raft.LogStore    = (*logStore)(nil)
+	_ raft.StableStore = (*logStore)(nil)
+)
+
+func openLogStore(path string) (*logStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(logsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(stableBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &logStore{db: db}, nil
+}
+
+func (s *logStore) Close() error {
+	return s.db.Close()
+}
+
+// FirstIndex returns the first index written. 0 for no entries.
+func (s *logStore) FirstIndex() (uint64, error) {
+	var idx uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if k, _ := tx.Bucket(logsBucket).Cursor().First(); k != nil {
+			idx = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	return idx, err
+}
+
+// LastIndex returns the last index written. 0 for no entries.
+func (s *logStore) LastIndex() (uint64, error) {
+	var idx uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if k, _ := tx.Bucket(logsBucket).Cursor().Last(); k != nil {
+			idx = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	return idx, err
+}
+
+// GetLog gets the log entry at the given index.
+func (s *logStore) GetLog(index uint64, l *raft.Log) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(logsBucket).Get(uint64Key(index))
+		if v == nil {
+			return raft.ErrLogNotFound
+		}
+		return decodeLog(v, l)
+	})
+}
+
+// StoreLog stores a log entry.
+func (s *logStore) StoreLog(l *raft.Log) error {
+	return s.StoreLogs([]*raft.Log{l})
+}
+
+// StoreLogs stores multiple log entries.
+func (s *logStore) StoreLogs(logs []*raft.Log) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(logsBucket)
+		for _, l := range logs {
+			if err := b.Put(uint64Key(l.Index), encodeLog(l)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRange deletes the log entries from min to max, inclusive.
+func (s *logStore) DeleteRange(min, max uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(logsBucket)
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(uint64Key(min)); k != nil && binary.BigEndian.Uint64(k) <= max; k, _ = c.Next() {
+			keys = append(keys, k)
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Set sets the value of key.
+func (s *logStore) Set(key, val []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stableBucket).Put(key, val)
+	})
+}
+
+// Get returns the value of key.
+func (s *logStore) Get(key []byte) ([]byte, error) {
+	var val []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(stableBucket).Get(key)
+		if v == nil {
+			return errKeyNotFound
+		}
+		val = copyBytes(v)
+		return nil
+	})
+	return val, err
+}
+
+// SetUint64 sets the value of key to val.
+func (s *logStore) SetUint64(key []byte, val uint64) error {
+	return s.Set(key, uint64Key(val))
+}
+
+// GetUint64 returns the value of key.
+func (s *logStore) GetUint64(key []byte) (uint64, error) {
+	v, err := s.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+func uint64Key(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// encodeLog encodes the index, term and type of the log entry followed by
+// its data.
+func encodeLog(l *raft.Log) []byte {
+	b := make([]byte, 17, 17+len(l.Data))
+	binary.BigEndian.PutUint64(b[0:8], l.Index)
+	binary.BigEndian.PutUint64(b[8:16], l.Term)
+	b[16] = byte(l.Type)
+	return append(b, l.Data...)
+}
+
+func decodeLog(b []byte, l *raft.Log) error {
+	if len(b) < 17 {
+		return errors.New("truncated raft log entry")
+	}
+	l.Index = binary.BigEndian.Uint64(b[0:8])
+	l.Term = binary.BigEndian.Uint64(b[8:16])
+	l.Type = raft.LogType(b[16])
+	l.Data = copyBytes(b[17:])
+	return nil
+}
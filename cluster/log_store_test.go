@@ -0,0 +1,67 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/raft"
+)
+
+func TestLogStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cluster-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := openLogStore(filepath.Join(dir, "raft.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if idx, err := s.LastIndex(); err != nil || idx != 0 {
+		t.Fatalf("expected empty log, got last index %d, %v", idx, err)
+	}
+
+	var logs []*raft.Log
+	for i := uint64(1); i <= 5; i++ {
+		logs = append(logs, &raft.Log{Index: i, Term: 2, Type: raft.LogCommand, Data: []byte{byte(i)}})
+	}
+	if err := s.StoreLogs(logs); err != nil {
+		t.Fatal(err)
+	}
+
+	var l raft.Log
+	if err := s.GetLog(3, &l); err != nil {
+		t.Fatal(err)
+	}
+	if l.Index != 3 || l.Term != 2 || l.Type != raft.LogCommand || len(l.Data) != 1 || l.Data[0] != 3 {
+		t.Fatalf("unexpected log entry %+v", l)
+	}
+
+	if err := s.DeleteRange(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if idx, err := s.FirstIndex(); err != nil || idx != 4 {
+		t.Fatalf("expected first index 4, got %d, %v", idx, err)
+	}
+	if idx, err := s.LastIndex(); err != nil || idx != 5 {
+		t.Fatalf("expected last index 5, got %d, %v", idx, err)
+	}
+	if err := s.GetLog(2, &l); err != raft.ErrLogNotFound {
+		t.Fatalf("expected deleted log entry not to be found, got %v", err)
+	}
+
+	if _, err := s.GetUint64([]byte("CurrentTerm")); err == nil || err.Error() != "not found" {
+		t.Fatalf("expected missing key not to be found, got %v", err)
+	}
+	if err := s.SetUint64([]byte("CurrentTerm"), 7); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := s.GetUint64([]byte("CurrentTerm")); err != nil || v != 7 {
+		t.Fatalf("expected term 7, got %d, %v", v, err)
+	}
+}
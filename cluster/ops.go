@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+type opType uint8
+
+const (
+	opCreateBucket opType = iota + 1
+	opPut
+	opDelete
+)
+
+// op is a single mutation of the metadata store. The ops of a transaction
+// are replicated as one raft log entry and applied atomically on every node.
+type op struct {
+	Type   opType `json:"type"`
+	Bucket []byte `json:"bucket"`
+	Key    []byte `json:"key,omitempty"`
+	Value  []byte `json:"value,omitempty"`
+}
+
+func encodeOps(ops []op) ([]byte, error) {
+	return json.Marshal(ops)
+}
+
+func decodeOps(b []byte) ([]op, error) {
+	var ops []op
+	if err := json.Unmarshal(b, &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// recordingTx records the mutations made in a transaction so that they can
+// be replicated. Mutations are also applied to the wrapped transaction so
+// that the transaction reads its own writes.
+type recordingTx struct {
+	kv.Tx
+	ops     []op
+	buckets map[string]bool
+}
+
+func newRecordingTx(tx kv.Tx) *recordingTx {
+	return &recordingTx{Tx: tx, buckets: make(map[string]bool)}
+}
+
+// Bucket returns the bucket named b, creating it if it does not exist.
+func (tx *recordingTx) Bucket(b []byte) (kv.Bucket, error) {
+	bkt, err := tx.Tx.Bucket(b)
+	if err != nil {
+		return nil, err
+	}
+	name := copyBytes(b)
+	if !tx.buckets[string(name)] {
+		tx.buckets[string(name)] = true
+		tx.ops = append(tx.ops, op{Type: opCreateBucket, Bucket: name})
+	}
+	return &recordingBucket{Bucket: bkt, tx: tx, name: name}, nil
+}
+
+// mutates reports whether the transaction did more than open buckets.
+func (tx *recordingTx) mutates() bool {
+	for _, o := range tx.ops {
+		if o.Type != opCreateBucket {
+			return true
+		}
+	}
+	return false
+}
+
+type recordingBucket struct {
+	kv.Bucket
+	tx   *recordingTx
+	name []byte
+}
+
+// Put sets the value of key and records the mutation.
+func (b *recordingBucket) Put(key, value []byte) error {
+	if err := b.Bucket.Put(key, value); err != nil {
+		return err
+	}
+	b.tx.ops = append(b.tx.ops, op{Type: opPut, Bucket: b.name, Key: copyBytes(key), Value: copyBytes(value)})
+	return nil
+}
+
+// Delete deletes key and records the mutation.
+func (b *recordingBucket) Delete(key []byte) error {
+	if err := b.Bucket.Delete(key); err != nil {
+		return err
+	}
+	b.tx.ops = append(b.tx.ops, op{Type: opDelete, Bucket: b.name, Key: copyBytes(key)})
+	return nil
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
@@ -0,0 +1,582 @@
+// Package cluster replicates the metadata store across nodes with raft.
+//
+// Every node keeps a full copy of the metadata store in its bolt database
+// and serves reads from it. Transactions which mutate the store are run
+// against the local copy to record their mutations, rolled back, and the
+// mutations are replicated through the raft log and applied on every node.
+// Followers forward their mutations to the leader. Time series data is not
+// replicated and remains local to each node.
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/hashicorp/raft"
+	"github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap"
+)
+
+const (
+	applyTimeout = 10 * time.Second
+	joinInterval = time.Second
+
+	// SecretHeader is the header in which nodes send the shared cluster secret.
+	SecretHeader = "X-Influx-Cluster-Secret"
+)
+
+var nodesBucket = []byte("clusternodesv1")
+
+// errRollback rolls back the local transaction once its mutations are recorded.
+var errRollback = errors.New("rollback")
+
+// Config configures a node of the cluster.
+type Config struct {
+	// NodeID uniquely identifies the node in the cluster.
+	NodeID string
+
+	// BindAddress is the address raft listens on.
+	BindAddress string
+
+	// AdvertiseAddress is the raft address other nodes use to reach this
+	// node. It defaults to BindAddress.
+	AdvertiseAddress string
+
+	// HTTPAddress is the URL of the HTTP API of this node, used by other
+	// nodes to forward mutations to it while it is the leader.
+	HTTPAddress string
+
+	// Join is the URL of the HTTP API of a node of an existing cluster. If
+	// it is empty, a new cluster is bootstrapped with this node.
+	Join string
+
+	// Secret authenticates the requests nodes send to each other.
+	Secret string
+
+	// Path is the directory holding the raft log and snapshots.
+	Path string
+}
+
+// Node is a member of the cluster.
+type Node struct {
+	ID          string `json:"id"`
+	RaftAddress string `json:"raftAddress"`
+	HTTPAddress string `json:"httpAddress"`
+}
+
+// NodeStatus is the status of a member of the cluster.
+type NodeStatus struct {
+	Node
+	Leader bool `json:"leader"`
+}
+
+// Status is the status of the cluster as seen by a node.
+type Status struct {
+	ID           string       `json:"id"`
+	State        string       `json:"state"`
+	AppliedIndex uint64       `json:"appliedIndex"`
+	Nodes        []NodeStatus `json:"nodes"`
+}
+
+// Store is a kv.Store whose mutations are replicated to every node of the
+// cluster.
+type Store struct {
+	log    *zap.Logger
+	config Config
+	kv     kv.Store
+	db     *bolt.DB
+	client *http.Client
+
+	// mu serializes transactions so that the mutations of a transaction are
+	// recorded against the state left by the previous one.
+	mu sync.Mutex
+
+	raft      *raft.Raft
+	transport *raft.NetworkTransport
+	logs      *logStore
+}
+
+var (
+	_ kv.Store              = (*Store)(nil)
+	_ kv.AutoMigrationStore = (*Store)(nil)
+)
+
+// NewStore returns a Store replicating the metadata store s, whose data is
+// held in the bolt database db.
+func NewStore(log *zap.Logger, config Config, s kv.Store, db *bolt.DB) *Store {
+	if config.AdvertiseAddress == "" {
+		config.AdvertiseAddress = config.BindAddress
+	}
+	return &Store{
+		log:    log,
+		config: config,
+		kv:     s,
+		db:     db,
+		client: &http.Client{Timeout: applyTimeout},
+	}
+}
+
+// Open starts raft and joins or bootstraps the cluster. It returns once the
+// node has caught up with the leader.
+func (s *Store) Open(ctx context.Context) error {
+	if s.config.NodeID == "" || s.config.HTTPAddress == "" {
+		return errors.New("cluster node ID and HTTP address are required")
+	}
+	if err := os.MkdirAll(s.config.Path, 0700); err != nil {
+		return err
+	}
+
+	stdlog := zap.NewStdLog(s.log.With(zap.String("component", "raft")))
+
+	logs, err := openLogStore(filepath.Join(s.config.Path, "raft.db"))
+	if err != nil {
+		return err
+	}
+	s.logs = logs
+
+	snaps, err := raft.NewFileSnapshotStoreWithLogger(s.config.Path, 2, stdlog)
+	if err != nil {
+		return err
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", s.config.AdvertiseAddress)
+	if err != nil {
+		return err
+	}
+	s.transport, err = raft.NewTCPTransportWithLogger(s.config.BindAddress, addr, 3, applyTimeout, stdlog)
+	if err != nil {
+		return err
+	}
+
+	existing, err := raft.HasExistingState(logs, logs, snaps)
+	if err != nil {
+		return err
+	}
+
+	rc := raft.DefaultConfig()
+	rc.LocalID = raft.ServerID(s.config.NodeID)
+	rc.Logger = stdlog
+	s.raft, err = raft.NewRaft(rc, &fsm{db: s.db, log: s.log}, logs, logs, snaps, s.transport)
+	if err != nil {
+		return err
+	}
+
+	bootstrap := !existing && s.config.Join == ""
+	if bootstrap {
+		s.log.Info("Bootstrapping cluster", zap.String("node_id", s.config.NodeID))
+		err := s.raft.BootstrapCluster(raft.Configuration{
+			Servers: []raft.Server{{ID: rc.LocalID, Address: s.transport.LocalAddr()}},
+		}).Error()
+		if err != nil {
+			return err
+		}
+	} else if !existing {
+		if err := s.join(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := s.waitReady(ctx); err != nil {
+		return err
+	}
+
+	if err := s.register(ctx); err != nil {
+		return err
+	}
+
+	if bootstrap {
+		// Snapshot the store so that nodes joining the cluster receive the
+		// metadata which existed before it was bootstrapped.
+		if err := s.raft.Snapshot().Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops raft.
+func (s *Store) Close() error {
+	var err error
+	if s.raft != nil {
+		err = s.raft.Shutdown().Error()
+	}
+	if s.transport != nil {
+		if cerr := s.transport.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if s.logs != nil {
+		if cerr := s.logs.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (s *Store) node() Node {
+	return Node{
+		ID:          s.config.NodeID,
+		RaftAddress: string(s.transport.LocalAddr()),
+		HTTPAddress: s.config.HTTPAddress,
+	}
+}
+
+// join asks the configured node to add this node to its cluster, retrying
+// until it succeeds.
+func (s *Store) join(ctx context.Context) error {
+	body, err := json.Marshal(s.node())
+	if err != nil {
+		return err
+	}
+	for {
+		_, err := s.do(ctx, "POST", s.config.Join, prefixCluster+"/join", body)
+		if err == nil {
+			return nil
+		}
+		s.log.Info("Failed to join cluster, retrying", zap.String("join", s.config.Join), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(joinInterval):
+		}
+	}
+}
+
+// register records the addresses of the node if they have changed. Followers
+// retry until the HTTP API of the leader is available.
+func (s *Store) register(ctx context.Context) error {
+	nodes, err := s.nodes()
+	if err != nil {
+		return err
+	}
+	n := s.node()
+	if nodes[n.ID] == n {
+		return nil
+	}
+
+	for {
+		err := s.putNode(ctx, n)
+		if err == nil || s.raft.State() == raft.Leader {
+			return err
+		}
+		s.log.Info("Failed to register node with cluster leader, retrying", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(joinInterval):
+		}
+	}
+}
+
+// waitReady waits until there is a leader and the node has applied every
+// committed log entry.
+func (s *Store) waitReady(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if s.raft.State() == raft.Leader {
+			return s.raft.Barrier(applyTimeout).Error()
+		}
+		if s.raft.Leader() != "" {
+			commit, _ := strconv.ParseUint(s.raft.Stats()["commit_index"], 10, 64)
+			if commit > 0 && s.raft.AppliedIndex() >= commit {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// View opens a read transaction against the local copy of the store.
+func (s *Store) View(ctx context.Context, fn func(kv.Tx) error) error {
+	return s.kv.View(ctx, fn)
+}
+
+// Update runs fn and replicates its mutations to every node. It returns
+// once they are applied to the local copy of the store.
+func (s *Store) Update(ctx context.Context, fn func(kv.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rec *recordingTx
+	err := s.kv.Update(ctx, func(tx kv.Tx) error {
+		rec = newRecordingTx(tx)
+		if err := fn(rec); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if err != errRollback {
+		return err
+	}
+	if !rec.mutates() && s.bucketsExist(rec.ops) {
+		return nil
+	}
+
+	cmd, err := encodeOps(rec.ops)
+	if err != nil {
+		return err
+	}
+	return s.apply(ctx, cmd)
+}
+
+// Backup copies the local copy of the store to w.
+func (s *Store) Backup(ctx context.Context, w io.Writer) error {
+	return s.kv.Backup(ctx, w)
+}
+
+// AutoMigrate returns the store itself so that migrations are replicated.
+func (s *Store) AutoMigrate() kv.Store {
+	return s
+}
+
+// bucketsExist reports whether every bucket the ops are applied to exists.
+func (s *Store) bucketsExist(ops []op) bool {
+	exist := true
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		for _, o := range ops {
+			if tx.Bucket(o.Bucket) == nil {
+				exist = false
+				return nil
+			}
+		}
+		return nil
+	})
+	return exist
+}
+
+// apply replicates the encoded ops, forwarding them to the leader if this
+// node is a follower, and waits for them to be applied locally.
+func (s *Store) apply(ctx context.Context, cmd []byte) error {
+	if s.raft.State() == raft.Leader {
+		_, err := s.applyLeader(cmd)
+		return err
+	}
+
+	b, err := s.forward(ctx, "POST", prefixCluster+"/apply", cmd)
+	if err != nil {
+		return err
+	}
+	var res applyResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return err
+	}
+	return s.waitApplied(ctx, res.Index)
+}
+
+// applyLeader replicates the encoded ops from the leader and returns the
+// index of their log entry.
+func (s *Store) applyLeader(cmd []byte) (uint64, error) {
+	f := s.raft.Apply(cmd, applyTimeout)
+	if err := f.Error(); err == raft.ErrNotLeader {
+		return 0, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "node is not the cluster leader",
+			Err:  err,
+		}
+	} else if err != nil {
+		return 0, err
+	}
+	if err, ok := f.Response().(error); ok && err != nil {
+		return 0, err
+	}
+	return f.Index(), nil
+}
+
+// waitApplied waits until the log entry at index is applied locally so that
+// the node reads its own writes.
+func (s *Store) waitApplied(ctx context.Context, index uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, applyTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for s.raft.AppliedIndex() < index {
+		select {
+		case <-ctx.Done():
+			return &influxdb.Error{
+				Code: influxdb.EUnavailable,
+				Msg:  "timed out waiting for replicated write",
+				Err:  ctx.Err(),
+			}
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Join adds the node to the cluster.
+func (s *Store) Join(ctx context.Context, n Node) error {
+	if s.raft.State() != raft.Leader {
+		body, err := json.Marshal(n)
+		if err != nil {
+			return err
+		}
+		_, err = s.forward(ctx, "POST", prefixCluster+"/join", body)
+		return err
+	}
+
+	s.log.Info("Adding node to cluster", zap.String("node_id", n.ID), zap.String("raft_address", n.RaftAddress))
+	if err := s.raft.AddVoter(raft.ServerID(n.ID), raft.ServerAddress(n.RaftAddress), 0, applyTimeout).Error(); err != nil {
+		return err
+	}
+	return s.putNode(ctx, n)
+}
+
+// RemoveNode removes the node from the cluster.
+func (s *Store) RemoveNode(ctx context.Context, id string) error {
+	if s.raft.State() != raft.Leader {
+		_, err := s.forward(ctx, "DELETE", prefixCluster+"/nodes/"+id, nil)
+		return err
+	}
+
+	s.log.Info("Removing node from cluster", zap.String("node_id", id))
+	if err := s.raft.RemoveServer(raft.ServerID(id), 0, applyTimeout).Error(); err != nil {
+		return err
+	}
+	cmd, err := encodeOps([]op{{Type: opDelete, Bucket: nodesBucket, Key: []byte(id)}})
+	if err != nil {
+		return err
+	}
+	_, err = s.applyLeader(cmd)
+	return err
+}
+
+func (s *Store) putNode(ctx context.Context, n Node) error {
+	v, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	cmd, err := encodeOps([]op{{Type: opPut, Bucket: nodesBucket, Key: []byte(n.ID), Value: v}})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.apply(ctx, cmd)
+}
+
+// nodes returns the registered nodes of the cluster by ID.
+func (s *Store) nodes() (map[string]Node, error) {
+	nodes := make(map[string]Node)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(nodesBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var n Node
+			if err := json.Unmarshal(v, &n); err != nil {
+				return err
+			}
+			nodes[n.ID] = n
+			return nil
+		})
+	})
+	return nodes, err
+}
+
+// Status returns the status of the cluster as seen by this node.
+func (s *Store) Status() (*Status, error) {
+	f := s.raft.GetConfiguration()
+	if err := f.Error(); err != nil {
+		return nil, err
+	}
+	nodes, err := s.nodes()
+	if err != nil {
+		return nil, err
+	}
+
+	leader := s.raft.Leader()
+	st := &Status{
+		ID:           s.config.NodeID,
+		State:        s.raft.State().String(),
+		AppliedIndex: s.raft.AppliedIndex(),
+	}
+	for _, srv := range f.Configuration().Servers {
+		n, ok := nodes[string(srv.ID)]
+		if !ok {
+			n = Node{ID: string(srv.ID), RaftAddress: string(srv.Address)}
+		}
+		st.Nodes = append(st.Nodes, NodeStatus{Node: n, Leader: srv.Address == leader})
+	}
+	return st, nil
+}
+
+// leaderHTTPAddress returns the URL of the HTTP API of the leader.
+func (s *Store) leaderHTTPAddress() (string, error) {
+	st, err := s.Status()
+	if err != nil {
+		return "", err
+	}
+	for _, n := range st.Nodes {
+		if n.Leader && n.HTTPAddress != "" {
+			return n.HTTPAddress, nil
+		}
+	}
+	return "", &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  "cluster has no leader",
+	}
+}
+
+// forward sends the request to the leader.
+func (s *Store) forward(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	addr, err := s.leaderHTTPAddress()
+	if err != nil {
+		return nil, err
+	}
+	return s.do(ctx, method, addr, path, body)
+}
+
+// do sends the request to the node with the HTTP API at addr.
+func (s *Store) do(ctx context.Context, method, addr, path string, body []byte) ([]byte, error) {
+	u, err := ihttp.NewURL(addr, path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SecretHeader, s.config.Secret)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := ihttp.CheckError(resp); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return nil, fmt.Errorf("reading response from %s: %v", addr, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -392,6 +392,15 @@ func (m dbrpMapper) FindMany(ctx context.Context, filter influxdb.DBRPMappingFil
 func (m dbrpMapper) Create(ctx context.Context, dbrpMap *influxdb.DBRPMapping) error {
 	return errors.New("dbrpMapper does not support creating new mappings")
 }
+func (m dbrpMapper) Upsert(ctx context.Context, dbrpMap *influxdb.DBRPMapping) error {
+	return errors.New("dbrpMapper does not support upserting mappings")
+}
+func (m dbrpMapper) Replace(ctx context.Context, dbrpMap *influxdb.DBRPMapping, revision string) error {
+	return errors.New("dbrpMapper does not support replacing mappings")
+}
+func (m dbrpMapper) SetDefault(ctx context.Context, cluster string, db string, rp string) (*influxdb.DBRPMapping, error) {
+	return nil, errors.New("dbrpMapper does not support changing default mappings")
+}
 func (m dbrpMapper) Delete(ctx context.Context, cluster string, db string, rp string) error {
 	return errors.New("dbrpMapper does not support deleteing mappings")
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/kit/prom"
+	"github.com/influxdata/influxdb/v2/kit/signals"
+	influxlogger "github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/router"
+	"go.uber.org/zap"
+)
+
+var (
+	log            = influxlogger.New(os.Stdout)
+	addr           string
+	config         router.Config
+	maxHandoffSize int
+)
+
+func main() {
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(fmt.Errorf("failed to determine influx directory: %v", err))
+	}
+
+	prog := &cli.Program{
+		Run:  run,
+		Name: "influx-router",
+		Opts: []cli.Opt{
+			{
+				DestP:   &addr,
+				Flag:    "http-bind-address",
+				Default: ":8086",
+				Desc:    "bind address for the REST HTTP API",
+			},
+			{
+				DestP: &config.Nodes,
+				Flag:  "nodes",
+				Desc:  "the addresses of the influxd nodes writes are sharded across. Nodes added or removed through the API take precedence on restart",
+			},
+			{
+				DestP:   &config.ReplicationFactor,
+				Flag:    "replication-factor",
+				Default: router.DefaultReplicationFactor,
+				Desc:    "the number of nodes each series is written to",
+			},
+			{
+				DestP:   &config.Path,
+				Flag:    "router-path",
+				Default: filepath.Join(dir, "router"),
+				Desc:    "path to the nodes of the router and the writes queued for unavailable nodes",
+			},
+			{
+				DestP:   &maxHandoffSize,
+				Flag:    "handoff-max-size",
+				Default: router.DefaultMaxHandoffSize,
+				Desc:    "the maximum size in bytes of the writes queued for each unavailable node. Writes which cannot be queued fail",
+			},
+			{
+				DestP:   &config.HandoffInterval,
+				Flag:    "handoff-interval",
+				Default: router.DefaultHandoffInterval,
+				Desc:    "how often queued writes are delivered",
+			},
+			{
+				DestP:   &config.RepairShardDuration,
+				Flag:    "repair-shard-duration",
+				Default: router.DefaultRepairShardDuration,
+				Desc:    "the duration of the shards whose digests are compared between the nodes owning a series when repairing",
+			},
+			{
+				DestP: &config.Token,
+				Flag:  "token",
+				Desc:  "the token authorizing the admin API of the router, and the reads and writes of nodes while rebalancing or repairing",
+			},
+			{
+				DestP: &config.InsecureSkipVerify,
+				Flag:  "skip-verify",
+				Desc:  "skip verification of the TLS certificates of nodes",
+			},
+		},
+	}
+	cmd := cli.NewCommand(prog)
+
+	var exitCode int
+	if err := cmd.Execute(); err != nil {
+		exitCode = 1
+		log.Error("Command returned error", zap.Error(err))
+	}
+
+	if err := log.Sync(); err != nil {
+		exitCode = 1
+		fmt.Fprintf(os.Stderr, "Error syncing logs: %v\n", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	os.Exit(exitCode)
+}
+
+func run() error {
+	log := log.With(zap.String("service", "influx-router"))
+	ctx := signals.WithStandardSignals(context.Background())
+
+	config.MaxHandoffSize = int64(maxHandoffSize)
+	r := router.NewRouter(log, config)
+	if err := r.Open(ctx); err != nil {
+		return err
+	}
+	defer r.Close()
+
+	reg := prom.NewRegistry(log.With(zap.String("service", "prom_registry")))
+	reg.MustRegister(r.PrometheusCollectors()...)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", reg.HTTPHandler())
+	mux.Handle("/", router.NewHandler(log, r))
+
+	srv := http.Server{
+		Addr:     addr,
+		Handler:  mux,
+		ErrorLog: zap.NewStdLog(log),
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("Starting influx-router", zap.String("addr", addr))
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
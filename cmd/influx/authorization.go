@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"io"
+	"time"
 
 	platform "github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/cmd/influx/internal"
@@ -17,6 +18,7 @@ type token struct {
 	UserName    string      `json:"userName"`
 	UserID      platform.ID `json:"userID"`
 	Permissions []string    `json:"permissions"`
+	LastUsedAt  *time.Time  `json:"lastUsedAt,omitempty"`
 }
 
 func cmdAuth(f *globalFlags, opt genericCLIOpts) *cobra.Command {
@@ -43,8 +45,9 @@ var authCRUDFlags struct {
 }
 
 var authCreateFlags struct {
-	user string
-	org  organization
+	user            string
+	org             organization
+	defaultBucketID string
 
 	writeUserPermission bool
 	readUserPermission  bool
@@ -96,6 +99,7 @@ func authCreateCmd() *cobra.Command {
 
 	cmd.Flags().StringArrayVarP(&authCreateFlags.writeBucketPermissions, "write-bucket", "", []string{}, "The bucket id")
 	cmd.Flags().StringArrayVarP(&authCreateFlags.readBucketPermissions, "read-bucket", "", []string{}, "The bucket id")
+	cmd.Flags().StringVarP(&authCreateFlags.defaultBucketID, "default-bucket-id", "", "", "The ID of the bucket written to by write requests which omit the bucket")
 
 	cmd.Flags().BoolVarP(&authCreateFlags.writeTasksPermission, "write-tasks", "", false, "Grants the permission to create tasks")
 	cmd.Flags().BoolVarP(&authCreateFlags.readTasksPermission, "read-tasks", "", false, "Grants the permission to read tasks")
@@ -241,6 +245,14 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		OrgID:       orgID,
 	}
 
+	if authCreateFlags.defaultBucketID != "" {
+		bucketID, err := platform.IDFromString(authCreateFlags.defaultBucketID)
+		if err != nil {
+			return err
+		}
+		authorization.DefaultBucketID = bucketID
+	}
+
 	if userName := authCreateFlags.user; userName != "" {
 		user, err := userSvc.FindUser(context.Background(), platform.UserFilter{
 			Name: &userName,
@@ -285,9 +297,10 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 }
 
 var authorizationFindFlags struct {
-	org    organization
-	user   string
-	userID string
+	org        organization
+	user       string
+	userID     string
+	unusedDays int
 }
 
 func authFindCmd() *cobra.Command {
@@ -302,6 +315,7 @@ func authFindCmd() *cobra.Command {
 	registerPrintOptions(cmd, &authCRUDFlags.hideHeaders, &authCRUDFlags.json)
 	cmd.Flags().StringVarP(&authorizationFindFlags.user, "user", "u", "", "The user")
 	cmd.Flags().StringVarP(&authorizationFindFlags.userID, "user-id", "", "", "The user ID")
+	cmd.Flags().IntVarP(&authorizationFindFlags.unusedDays, "unused-days", "", 0, "Only list authorizations that have not been used for this many days")
 
 	cmd.Flags().StringVarP(&authCRUDFlags.id, "id", "i", "", "The authorization ID")
 
@@ -347,6 +361,10 @@ func authorizationFindF(cmd *cobra.Command, args []string) error {
 		}
 		filter.OrgID = oID
 	}
+	if authorizationFindFlags.unusedDays > 0 {
+		since := time.Now().Add(-time.Duration(authorizationFindFlags.unusedDays) * 24 * time.Hour)
+		filter.UnusedSince = &since
+	}
 
 	authorizations, _, err := s.FindAuthorizations(context.Background(), filter)
 	if err != nil {
@@ -372,6 +390,7 @@ func authorizationFindF(cmd *cobra.Command, args []string) error {
 			UserName:    user.Name,
 			UserID:      a.UserID,
 			Permissions: permissions,
+			LastUsedAt:  a.LastUsedAt,
 		})
 	}
 
@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/pkg/deltalake"
+	"github.com/influxdata/influxdb/v2/pkg/parquet"
+	"github.com/spf13/cobra"
+)
+
+func cmdExportParquet(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("export-parquet", exportParquetF, true)
+	cmd.Short = "Export the data of a bucket as Parquet files"
+	cmd.Long = `Exports the data of a bucket in a time range as Apache Parquet files, so
+that it can be queried by engines such as Spark or Trino.
+
+A file is written for each measurement and UTC day, to
+<path>/<measurement>/date=<YYYY-MM-DD>/part-00000.parquet. Files have a "time"
+column, a column for each tag key and a column for each field key of the
+measurement. Times are stored in microseconds.
+
+The path is a local directory, or an s3://bucket/prefix URL. Objects are
+uploaded with the credentials and region of the AWS environment.
+
+With --table-format delta, the files of each measurement are registered in a
+Delta Lake table at <path>/<measurement>. Days already in the table are
+replaced by the new export, unless retention may have deleted some of their
+data, so that exporting on a schedule keeps the table up to date while the
+table keeps data the bucket no longer has. With --glue-database, the tables
+are registered in the AWS Glue Data Catalog too.
+
+The time range defaults to the retention period of the bucket, up to now.
+Data held in the cache of the storage engine is exported; flush the bucket
+first to export the data of TSM files only.`
+
+	exportParquetFlags.org.register(cmd, false)
+	opts := flagOpts{
+		{
+			DestP: &exportParquetFlags.bucketID,
+			Flag:  "bucket-id",
+			Desc:  "The ID of the bucket to export",
+		},
+		{
+			DestP:  &exportParquetFlags.bucket,
+			Flag:   "bucket",
+			Short:  'b',
+			EnvVar: "BUCKET_NAME",
+			Desc:   "The name of the bucket to export",
+		},
+		{
+			DestP: &exportParquetFlags.start,
+			Flag:  "start",
+			Desc:  "the start time in RFC3339Nano format, exp 2009-01-02T23:00:00Z",
+		},
+		{
+			DestP: &exportParquetFlags.stop,
+			Flag:  "stop",
+			Desc:  "the stop time in RFC3339Nano format, exp 2009-01-02T23:00:00Z",
+		},
+		{
+			DestP:    &exportParquetFlags.path,
+			Flag:     "path",
+			Short:    'p',
+			Desc:     "local directory or s3://bucket/prefix URL to write files to",
+			Required: true,
+		},
+		{
+			DestP: &exportParquetFlags.s3Endpoint,
+			Flag:  "s3-endpoint",
+			Desc:  "endpoint of an S3 compatible object store, instead of AWS",
+		},
+		{
+			DestP:   &exportParquetFlags.tableFormat,
+			Flag:    "table-format",
+			Default: "none",
+			Desc:    "format of the tables to register files in: none or delta",
+		},
+		{
+			DestP: &exportParquetFlags.glueDatabase,
+			Flag:  "glue-database",
+			Desc:  "AWS Glue database to register Delta tables in",
+		},
+	}
+	opts.mustRegister(cmd)
+
+	return cmd
+}
+
+var exportParquetFlags struct {
+	org          organization
+	bucketID     string
+	bucket       string
+	start        string
+	stop         string
+	path         string
+	s3Endpoint   string
+	tableFormat  string
+	glueDatabase string
+}
+
+func exportParquetF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for export-parquet command")
+	}
+
+	now := time.Now().UTC()
+	var start time.Time
+	if exportParquetFlags.start != "" {
+		var err error
+		if start, err = time.Parse(time.RFC3339Nano, exportParquetFlags.start); err != nil {
+			return fmt.Errorf("invalid start time %q: %v", exportParquetFlags.start, err)
+		}
+	}
+	stop := now
+	if exportParquetFlags.stop != "" {
+		var err error
+		if stop, err = time.Parse(time.RFC3339Nano, exportParquetFlags.stop); err != nil {
+			return fmt.Errorf("invalid stop time %q: %v", exportParquetFlags.stop, err)
+		}
+	}
+
+	var delta bool
+	switch exportParquetFlags.tableFormat {
+	case "none":
+	case "delta":
+		delta = true
+	default:
+		return fmt.Errorf("invalid table format %q, expected none or delta", exportParquetFlags.tableFormat)
+	}
+	if exportParquetFlags.glueDatabase != "" {
+		if !delta {
+			return fmt.Errorf("glue-database requires the delta table format")
+		}
+		if !strings.HasPrefix(exportParquetFlags.path, "s3://") {
+			return fmt.Errorf("glue-database requires an s3:// path")
+		}
+	}
+
+	var filter influxdb.BucketFilter
+	if exportParquetFlags.bucketID != "" {
+		id, err := influxdb.IDFromString(exportParquetFlags.bucketID)
+		if err != nil {
+			return fmt.Errorf("failed to decode bucket id %q: %v", exportParquetFlags.bucketID, err)
+		}
+		filter.ID = id
+	} else if exportParquetFlags.bucket != "" {
+		if err := exportParquetFlags.org.validOrgFlags(&flags); err != nil {
+			return err
+		}
+		filter.Name = &exportParquetFlags.bucket
+		if exportParquetFlags.org.id != "" {
+			id, err := influxdb.IDFromString(exportParquetFlags.org.id)
+			if err != nil {
+				return err
+			}
+			filter.OrganizationID = id
+		} else {
+			filter.Org = &exportParquetFlags.org.name
+		}
+	} else {
+		return fmt.Errorf("please specify one of bucket or bucket-id")
+	}
+
+	dest, err := newParquetDestination(exportParquetFlags.path, exportParquetFlags.s3Endpoint)
+	if err != nil {
+		return err
+	}
+
+	bktSVC, _, err := newBucketSVCs()
+	if err != nil {
+		return err
+	}
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+	exportSVC := &http.ParquetExportService{Client: httpClient}
+
+	ctx := context.Background()
+	bkt, err := bktSVC.FindBucket(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find bucket: %v", err)
+	}
+
+	// Days starting before the retention horizon may have lost data to
+	// retention since they were exported.
+	var horizon time.Time
+	if bkt.RetentionPeriod > 0 {
+		horizon = now.Add(-bkt.RetentionPeriod)
+	}
+	if start.IsZero() {
+		if horizon.IsZero() {
+			return fmt.Errorf("please specify start, bucket %q has infinite retention", bkt.Name)
+		}
+		start = horizon
+	}
+	if !start.Before(stop) {
+		return fmt.Errorf("start must be before stop")
+	}
+
+	names, err := exportSVC.FindExportMeasurements(ctx, bkt.OrgID, bkt.ID, start.UnixNano(), stop.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to find measurements of bucket %q: %v", bkt.Name, err)
+	}
+
+	var files int
+	for _, name := range names {
+		dir := url.PathEscape(name)
+
+		var (
+			table *deltalake.Table
+			days  = make(map[string]deltalake.AddFile)
+		)
+		if delta {
+			if table, err = deltalake.Open(ctx, dest.table(dir), []string{"date"}); err != nil {
+				return fmt.Errorf("failed to open table %s: %v", dir, err)
+			}
+			for _, f := range table.Files() {
+				days[f.PartitionValues["date"]] = f
+			}
+		}
+
+		var (
+			schema []deltalake.Field
+			add    []deltalake.AddFile
+			remove []string
+		)
+		for _, day := range exportDays(start, stop) {
+			date := day.Format("2006-01-02")
+			old, exported := days[date]
+			if exported && day.Before(horizon) {
+				continue
+			}
+
+			from, to := day, day.Add(24*time.Hour)
+			if from.Before(start) {
+				from = start
+			}
+			if to.After(stop) {
+				to = stop
+			}
+
+			file := "part-00000.parquet"
+			if delta {
+				// Files of a table are never overwritten, since readers
+				// of previous versions may read them.
+				file = fmt.Sprintf("part-%d.parquet", now.UnixNano())
+			}
+			p := path.Join("date="+date, file)
+			f, err := dest.write(ctx, path.Join(dir, p), func(f *os.File) (int64, error) {
+				return exportSVC.ExportParquet(ctx, f, bkt.OrgID, bkt.ID, name, from.UnixNano(), to.UnixNano())
+			})
+			if err == influxdb.ErrNoExportData {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to export %s: %v", path.Join(dir, p), err)
+			}
+			fmt.Printf("Exported %d rows to %s\n", f.rows, path.Join(dir, p))
+			files++
+
+			if !delta {
+				continue
+			}
+			schema = append(schema, deltaFields(f.cols)...)
+			add = append(add, deltalake.AddFile{
+				Path:             p,
+				PartitionValues:  map[string]string{"date": date},
+				Size:             f.size,
+				ModificationTime: now.UnixNano() / int64(time.Millisecond),
+				DataChange:       true,
+			})
+			if exported {
+				remove = append(remove, old.Path)
+			}
+		}
+
+		if len(add) == 0 {
+			continue
+		}
+		schema = append(schema, deltalake.Field{Name: "date", Type: "date", Nullable: true})
+		if err := table.Commit(ctx, schema, add, remove); err != nil {
+			return fmt.Errorf("failed to update table %s: %v", dir, err)
+		}
+		fmt.Printf("Committed version %d of table %s\n", table.Version(), dir)
+
+		if exportParquetFlags.glueDatabase != "" {
+			tableName := glueTableName(name)
+			if err := dest.registerGlueTable(ctx, exportParquetFlags.glueDatabase, tableName, dir, table.Schema()); err != nil {
+				return fmt.Errorf("failed to register table %s in glue: %v", tableName, err)
+			}
+		}
+	}
+
+	fmt.Printf("Export complete, wrote %d files\n", files)
+	return nil
+}
+
+// exportDays returns the start of each UTC day overlapping [start, stop),
+// which partition an export.
+func exportDays(start, stop time.Time) []time.Time {
+	var days []time.Time
+	for day := start.UTC().Truncate(24 * time.Hour); day.Before(stop); day = day.Add(24 * time.Hour) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// deltaFields returns the Delta Lake fields of the columns of an exported
+// file.
+func deltaFields(cols []parquet.Column) []deltalake.Field {
+	fields := make([]deltalake.Field, len(cols))
+	for i, col := range cols {
+		var typ string
+		switch col.Type {
+		case parquet.Boolean:
+			typ = "boolean"
+		case parquet.Int64:
+			typ = "long"
+		case parquet.Uint64:
+			// Readers read unsigned 64-bit integers as decimals, since
+			// they may not fit in a long.
+			typ = "decimal(20,0)"
+		case parquet.Double:
+			typ = "double"
+		case parquet.String:
+			typ = "string"
+		case parquet.Timestamp:
+			typ = "timestamp"
+		}
+		fields[i] = deltalake.Field{Name: col.Name, Type: typ, Nullable: col.Optional}
+	}
+	return fields
+}
+
+// glueTableName returns the name of the Glue table of a measurement, which
+// may only have lower case letters, digits and underscores.
+func glueTableName(measurement string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '_'
+		}
+	}, measurement)
+}
+
+// parquetDestination writes exported files to a local directory or to an S3
+// bucket. Files are exported to a temporary file first, so that a failed
+// export leaves no partial file behind.
+type parquetDestination struct {
+	dir string
+
+	sess     *session.Session
+	s3       *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+func newParquetDestination(p, s3Endpoint string) (*parquetDestination, error) {
+	if !strings.HasPrefix(p, "s3://") {
+		if err := os.MkdirAll(p, 0777); err != nil {
+			return nil, err
+		}
+		return &parquetDestination{dir: p}, nil
+	}
+
+	u, err := url.Parse(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 URL %q: %v", p, err)
+	}
+	cfg := aws.NewConfig()
+	if s3Endpoint != "" {
+		cfg = cfg.WithEndpoint(s3Endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %v", err)
+	}
+	client := s3.New(sess)
+	return &parquetDestination{
+		sess:     sess,
+		s3:       client,
+		uploader: s3manager.NewUploaderWithClient(client),
+		bucket:   u.Host,
+		prefix:   strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+// exportedFile describes a file written to a parquetDestination.
+type exportedFile struct {
+	rows int64
+	size int64
+	cols []parquet.Column
+}
+
+// write writes the file at path p with fn.
+func (d *parquetDestination) write(ctx context.Context, p string, fn func(f *os.File) (int64, error)) (*exportedFile, error) {
+	dir := os.TempDir()
+	if d.s3 == nil {
+		dir = d.localPath(path.Dir(p))
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			return nil, err
+		}
+	}
+	f, err := ioutil.TempFile(dir, ".export-*.parquet")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	rows, err := fn(f)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	cols, _, err := parquet.ReadColumns(f, fi.Size())
+	if err != nil {
+		return nil, err
+	}
+	ef := &exportedFile{rows: rows, size: fi.Size(), cols: cols}
+
+	if d.s3 == nil {
+		if err := f.Close(); err != nil {
+			return nil, err
+		}
+		return ef, os.Rename(f.Name(), d.localPath(p))
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	_, err = d.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(d.key(p)),
+		Body:   f,
+	})
+	return ef, err
+}
+
+func (d *parquetDestination) localPath(p string) string {
+	return filepath.Join(d.dir, filepath.FromSlash(p))
+}
+
+func (d *parquetDestination) key(p string) string {
+	return path.Join(d.prefix, p)
+}
+
+// location returns the URL of path p.
+func (d *parquetDestination) location(p string) string {
+	return "s3://" + d.bucket + "/" + d.key(p)
+}
+
+// table returns the store of the Delta Lake table in dir.
+func (d *parquetDestination) table(dir string) deltalake.Store {
+	return &destinationStore{d: d, dir: dir}
+}
+
+// registerGlueTable creates or updates the Glue table of the Delta Lake table
+// in dir, partitioned by date.
+func (d *parquetDestination) registerGlueTable(ctx context.Context, database, name, dir string, schema []deltalake.Field) error {
+	var cols, partitionKeys []*glue.Column
+	for _, f := range schema {
+		// Glue types are those of Hive.
+		typ := f.Type
+		if typ == "long" {
+			typ = "bigint"
+		}
+		col := &glue.Column{Name: aws.String(f.Name), Type: aws.String(typ)}
+		if f.Name == "date" {
+			partitionKeys = append(partitionKeys, col)
+		} else {
+			cols = append(cols, col)
+		}
+	}
+
+	location := d.location(dir)
+	input := &glue.TableInput{
+		Name:      aws.String(name),
+		TableType: aws.String("EXTERNAL_TABLE"),
+		Parameters: aws.StringMap(map[string]string{
+			"EXTERNAL":                   "TRUE",
+			"table_type":                 "DELTA",
+			"spark.sql.sources.provider": "delta",
+		}),
+		PartitionKeys: partitionKeys,
+		StorageDescriptor: &glue.StorageDescriptor{
+			Columns:  cols,
+			Location: aws.String(location),
+			SerdeInfo: &glue.SerDeInfo{
+				Parameters: aws.StringMap(map[string]string{"path": location}),
+			},
+		},
+	}
+
+	client := glue.New(d.sess)
+	_, err := client.GetTableWithContext(ctx, &glue.GetTableInput{
+		DatabaseName: aws.String(database),
+		Name:         aws.String(name),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == glue.ErrCodeEntityNotFoundException {
+		_, err = client.CreateTableWithContext(ctx, &glue.CreateTableInput{
+			DatabaseName: aws.String(database),
+			TableInput:   input,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	_, err = client.UpdateTableWithContext(ctx, &glue.UpdateTableInput{
+		DatabaseName: aws.String(database),
+		TableInput:   input,
+	})
+	return err
+}
+
+// destinationStore is a deltalake.Store of a directory of a
+// parquetDestination.
+type destinationStore struct {
+	d   *parquetDestination
+	dir string
+}
+
+func (s *destinationStore) List(ctx context.Context, dir string) ([]string, error) {
+	p := path.Join(s.dir, dir)
+	if s.d.s3 == nil {
+		fis, err := ioutil.ReadDir(s.d.localPath(p))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(fis))
+		for _, fi := range fis {
+			names = append(names, fi.Name())
+		}
+		return names, nil
+	}
+
+	var names []string
+	prefix := s.d.key(p) + "/"
+	err := s.d.s3.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.d.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}, func(out *s3.ListObjectsV2Output, last bool) bool {
+		for _, obj := range out.Contents {
+			names = append(names, strings.TrimPrefix(aws.StringValue(obj.Key), prefix))
+		}
+		return true
+	})
+	return names, err
+}
+
+func (s *destinationStore) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	p := path.Join(s.dir, name)
+	if s.d.s3 == nil {
+		return ioutil.ReadFile(s.d.localPath(p))
+	}
+
+	out, err := s.d.s3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.d.bucket),
+		Key:    aws.String(s.d.key(p)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+// CreateFile creates a file, failing if it exists. S3 has no such operation,
+// so tables in S3 must only be written by a single export at a time.
+func (s *destinationStore) CreateFile(ctx context.Context, name string, data []byte) error {
+	p := path.Join(s.dir, name)
+	if s.d.s3 == nil {
+		if err := os.MkdirAll(s.d.localPath(path.Dir(p)), 0777); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(s.d.localPath(p), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+
+	_, err := s.d.s3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.d.bucket),
+		Key:    aws.String(s.d.key(p)),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
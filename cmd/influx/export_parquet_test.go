@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/pkg/deltalake"
+)
+
+func TestExportDays(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		name  string
+		start string
+		stop  string
+		want  []time.Time
+	}{
+		{
+			name:  "within a day",
+			start: "2020-01-01T10:00:00Z",
+			stop:  "2020-01-01T11:00:00Z",
+			want:  []time.Time{day("2020-01-01")},
+		},
+		{
+			name:  "stop at midnight",
+			start: "2020-01-01T00:00:00Z",
+			stop:  "2020-01-03T00:00:00Z",
+			want:  []time.Time{day("2020-01-01"), day("2020-01-02")},
+		},
+		{
+			name:  "partial days",
+			start: "2020-01-01T23:00:00Z",
+			stop:  "2020-01-03T01:00:00Z",
+			want:  []time.Time{day("2020-01-01"), day("2020-01-02"), day("2020-01-03")},
+		},
+		{
+			name:  "time zone",
+			start: "2020-01-02T01:00:00+02:00",
+			stop:  "2020-01-02T03:00:00+02:00",
+			want:  []time.Time{day("2020-01-01"), day("2020-01-02")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, err := time.Parse(time.RFC3339, tt.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stop, err := time.Parse(time.RFC3339, tt.stop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := exportDays(start, stop); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParquetDestination_Table(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export-parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dest, err := newParquetDestination(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	schema := []deltalake.Field{{Name: "time", Type: "timestamp"}, {Name: "date", Type: "date", Nullable: true}}
+	add := []deltalake.AddFile{{Path: "date=2020-01-01/part-1.parquet", PartitionValues: map[string]string{"date": "2020-01-01"}}}
+	for i := 0; i < 2; i++ {
+		table, err := deltalake.Open(ctx, dest.table("cpu"), []string{"date"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := table.Version(); got != int64(i-1) {
+			t.Fatalf("got version %d, want %d", got, i-1)
+		}
+		if err := table.Commit(ctx, schema, add, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A commit of a version that exists fails.
+	if err := dest.table("cpu").CreateFile(ctx, "_delta_log/00000000000000000001.json", nil); err == nil {
+		t.Fatal("expected error creating existing commit")
+	}
+}
+
+func TestGlueTableName(t *testing.T) {
+	if got, want := glueTableName("CPU-usage.total_2"), "cpu_usage_total_2"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/spf13/cobra"
+)
+
+type featureFlagSVCsFn func() (*http.FeatureFlagService, influxdb.OrganizationService, error)
+
+func cmdFeatureFlag(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdFeatureFlagBuilder(newFeatureFlagSVCs, opt)
+	builder.globalFlags = f
+	return builder.cmd()
+}
+
+type cmdFeatureFlagBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn featureFlagSVCsFn
+
+	json        bool
+	hideHeaders bool
+	key         string
+	value       string
+	limit       int
+	org         organization
+}
+
+func newCmdFeatureFlagBuilder(svcsFn featureFlagSVCsFn, opt genericCLIOpts) *cmdFeatureFlagBuilder {
+	return &cmdFeatureFlagBuilder{
+		genericCLIOpts: opt,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdFeatureFlagBuilder) cmd() *cobra.Command {
+	cmd := b.newCmd("flags", nil, false)
+	cmd.Short = "Feature flag management commands"
+	cmd.Long = `Manages the values of feature flags set for the instance, which change
+the defaults of the flags, and for organizations, which take precedence over
+the values set for the instance. Changing the values for the instance
+requires an operator token.`
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdChanges(),
+		b.cmdList(),
+		b.cmdSet(),
+		b.cmdUnset(),
+	)
+	return cmd
+}
+
+// orgID returns the organization given by the organization flags, or nil
+// for the instance if none is given.
+func (b *cmdFeatureFlagBuilder) orgID(orgSVC influxdb.OrganizationService) (*influxdb.ID, error) {
+	if b.org.id == "" && b.org.name == "" {
+		return nil, nil
+	}
+	id, err := b.org.getID(orgSVC)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (b *cmdFeatureFlagBuilder) cmdList() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdListRunEFn, true)
+	cmd.Short = "List feature flags and the values set for them"
+	cmd.Aliases = []string{"find", "ls"}
+
+	cmd.Flags().StringVarP(&b.key, "key", "k", "", "The feature flag key")
+	b.org.register(cmd, false)
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+
+	return cmd
+}
+
+func (b *cmdFeatureFlagBuilder) cmdListRunEFn(cmd *cobra.Command, args []string) error {
+	svc, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.orgID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	var filter influxdb.FeatureFlagSettingFilter
+	if b.key != "" {
+		filter.Key = &b.key
+	}
+	filter.OrgID = orgID
+
+	flags, err := svc.FindFeatureFlags(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve feature flags: %v", err)
+	}
+
+	if b.json {
+		return b.writeJSON(flags)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+	w.WriteHeaders("Key", "Default", "Scope", "Value", "Updated At")
+	for _, f := range flags {
+		if len(f.Settings) == 0 {
+			w.Write(map[string]interface{}{
+				"Key":        f.Key,
+				"Default":    f.Default,
+				"Scope":      "",
+				"Value":      "",
+				"Updated At": "",
+			})
+			continue
+		}
+		for _, fs := range f.Settings {
+			w.Write(map[string]interface{}{
+				"Key":        f.Key,
+				"Default":    f.Default,
+				"Scope":      featureFlagScope(fs.OrgID),
+				"Value":      fs.Value,
+				"Updated At": fs.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+	}
+	return nil
+}
+
+func featureFlagScope(orgID *influxdb.ID) string {
+	if orgID == nil {
+		return "instance"
+	}
+	return orgID.String()
+}
+
+func (b *cmdFeatureFlagBuilder) cmdSet() *cobra.Command {
+	cmd := b.newCmd("set", b.cmdSetRunEFn, true)
+	cmd.Short = "Set the value of a feature flag for the instance or an organization"
+
+	cmd.Flags().StringVarP(&b.key, "key", "k", "", "The feature flag key (required)")
+	cmd.Flags().StringVarP(&b.value, "value", "v", "", "The feature flag value (required)")
+	cmd.MarkFlagRequired("key")
+	cmd.MarkFlagRequired("value")
+	b.org.register(cmd, false)
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+
+	return cmd
+}
+
+func (b *cmdFeatureFlagBuilder) cmdSetRunEFn(cmd *cobra.Command, args []string) error {
+	svc, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.orgID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	fs := &influxdb.FeatureFlagSetting{
+		Key:   b.key,
+		OrgID: orgID,
+		Value: b.value,
+	}
+	if err := svc.SetFeatureFlagSetting(context.Background(), fs); err != nil {
+		return fmt.Errorf("failed to set feature flag %q: %v", b.key, err)
+	}
+
+	if b.json {
+		return b.writeJSON(fs)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+	w.WriteHeaders("Key", "Scope", "Value", "Updated At")
+	w.Write(map[string]interface{}{
+		"Key":        fs.Key,
+		"Scope":      featureFlagScope(fs.OrgID),
+		"Value":      fs.Value,
+		"Updated At": fs.UpdatedAt.Format(time.RFC3339),
+	})
+	return nil
+}
+
+func (b *cmdFeatureFlagBuilder) cmdUnset() *cobra.Command {
+	cmd := b.newCmd("unset", b.cmdUnsetRunEFn, true)
+	cmd.Short = "Remove the value of a feature flag set for the instance or an organization"
+
+	cmd.Flags().StringVarP(&b.key, "key", "k", "", "The feature flag key (required)")
+	cmd.MarkFlagRequired("key")
+	b.org.register(cmd, false)
+
+	return cmd
+}
+
+func (b *cmdFeatureFlagBuilder) cmdUnsetRunEFn(cmd *cobra.Command, args []string) error {
+	svc, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.orgID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.DeleteFeatureFlagSetting(context.Background(), b.key, orgID); err != nil {
+		return fmt.Errorf("failed to unset feature flag %q: %v", b.key, err)
+	}
+	return nil
+}
+
+func (b *cmdFeatureFlagBuilder) cmdChanges() *cobra.Command {
+	cmd := b.newCmd("changes", b.cmdChangesRunEFn, true)
+	cmd.Short = "List the latest changes made to the values of feature flags"
+
+	cmd.Flags().StringVarP(&b.key, "key", "k", "", "The feature flag key")
+	cmd.Flags().IntVar(&b.limit, "limit", influxdb.DefaultPageSize, "The maximum number of changes to list")
+	b.org.register(cmd, false)
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+
+	return cmd
+}
+
+func (b *cmdFeatureFlagBuilder) cmdChangesRunEFn(cmd *cobra.Command, args []string) error {
+	svc, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.orgID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	var filter influxdb.FeatureFlagChangeFilter
+	if b.key != "" {
+		filter.Key = &b.key
+	}
+	filter.OrgID = orgID
+
+	cs, err := svc.FindFeatureFlagChanges(context.Background(), filter, influxdb.FindOptions{
+		Limit:      b.limit,
+		Descending: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve feature flag changes: %v", err)
+	}
+
+	if b.json {
+		return b.writeJSON(cs)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+	w.WriteHeaders("Time", "Key", "Scope", "Old Value", "New Value", "User ID")
+	for _, c := range cs {
+		m := map[string]interface{}{
+			"Time":      c.Time.Format(time.RFC3339),
+			"Key":       c.Key,
+			"Scope":     featureFlagScope(c.OrgID),
+			"Old Value": "",
+			"New Value": "",
+			"User ID":   "",
+		}
+		if c.OldValue != nil {
+			m["Old Value"] = *c.OldValue
+		}
+		if c.NewValue != nil {
+			m["New Value"] = *c.NewValue
+		}
+		if c.UserID.Valid() {
+			m["User ID"] = c.UserID.String()
+		}
+		w.Write(m)
+	}
+	return nil
+}
+
+func newFeatureFlagSVCs() (*http.FeatureFlagService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &http.FeatureFlagService{Client: httpClient}, &http.OrganizationService{Client: httpClient}, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/spf13/cobra"
+)
+
+func cmdFlush(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("flush", flushF, true)
+	cmd.Short = "Flush cached data for a bucket to disk"
+	cmd.Long = `Flushes the data for a bucket held in memory by the storage engine to TSM
+files, for example before taking a filesystem snapshot. The cache is shared
+by all buckets, so data for other buckets is flushed too.`
+
+	flushFlags.org.register(cmd, false)
+	opts := flagOpts{
+		{
+			DestP: &flushFlags.bucketID,
+			Flag:  "bucket-id",
+			Desc:  "The ID of the bucket to flush",
+		},
+		{
+			DestP:  &flushFlags.bucket,
+			Flag:   "bucket",
+			Short:  'b',
+			EnvVar: "BUCKET_NAME",
+			Desc:   "The name of the bucket to flush",
+		},
+	}
+	opts.mustRegister(cmd)
+
+	return cmd
+}
+
+var flushFlags struct {
+	org      organization
+	bucketID string
+	bucket   string
+}
+
+func flushF(cmd *cobra.Command, args []string) error {
+	if flags.local {
+		return fmt.Errorf("local flag not supported for flush command")
+	}
+
+	var filter influxdb.BucketFilter
+	if flushFlags.bucketID != "" {
+		id, err := influxdb.IDFromString(flushFlags.bucketID)
+		if err != nil {
+			return fmt.Errorf("failed to decode bucket id %q: %v", flushFlags.bucketID, err)
+		}
+		filter.ID = id
+	} else if flushFlags.bucket != "" {
+		if err := flushFlags.org.validOrgFlags(&flags); err != nil {
+			return err
+		}
+		filter.Name = &flushFlags.bucket
+		if flushFlags.org.id != "" {
+			id, err := influxdb.IDFromString(flushFlags.org.id)
+			if err != nil {
+				return err
+			}
+			filter.OrganizationID = id
+		} else {
+			filter.Org = &flushFlags.org.name
+		}
+	} else {
+		return fmt.Errorf("please specify one of bucket or bucket-id")
+	}
+
+	bktSVC, _, err := newBucketSVCs()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	bkt, err := bktSVC.FindBucket(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find bucket: %v", err)
+	}
+
+	s := &http.FlushService{
+		Addr:               flags.Host,
+		Token:              flags.Token,
+		InsecureSkipVerify: flags.skipVerify,
+	}
+	res, err := s.FlushBucket(ctx, bkt.OrgID, bkt.ID)
+	if err != nil {
+		return fmt.Errorf("failed to flush bucket %q: %v", bkt.Name, err)
+	}
+
+	fmt.Printf("Flushed %d bytes in %s\n", res.FlushedBytes, res.Duration)
+	return nil
+}
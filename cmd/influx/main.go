@@ -224,6 +224,9 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdBackup,
 		cmdBucket,
 		cmdDelete,
+		cmdExportParquet,
+		cmdFeatureFlag,
+		cmdFlush,
 		cmdOrganization,
 		cmdPing,
 		cmdPkg,
@@ -235,6 +238,7 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdSetup,
 		cmdTask,
 		cmdUser,
+		cmdV1,
 		cmdWrite,
 	)
 }
@@ -24,12 +24,13 @@ type cmdOrgBuilder struct {
 
 	svcFn orgSVCFn
 
-	json        bool
-	hideHeaders bool
-	description string
-	id          string
-	memberID    string
-	name        string
+	json            bool
+	hideHeaders     bool
+	description     string
+	defaultBucketID string
+	id              string
+	memberID        string
+	name            string
 }
 
 func newCmdOrgBuilder(svcFn orgSVCFn, opts genericCLIOpts) *cmdOrgBuilder {
@@ -213,6 +214,11 @@ func (b *cmdOrgBuilder) cmdUpdate() *cobra.Command {
 			EnvVar: "ORG_DESCRIPTION",
 			Desc:   "The organization name",
 		},
+		{
+			DestP: &b.defaultBucketID,
+			Flag:  "default-bucket-id",
+			Desc:  "The ID of the bucket written to by write requests which omit the bucket",
+		},
 	}
 	opts.mustRegister(cmd)
 	b.registerPrintFlags(cmd)
@@ -238,6 +244,13 @@ func (b *cmdOrgBuilder) updateRunEFn(cmd *cobra.Command, args []string) error {
 	if b.description != "" {
 		update.Description = &b.description
 	}
+	if b.defaultBucketID != "" {
+		bucketID, err := influxdb.IDFromString(b.defaultBucketID)
+		if err != nil {
+			return fmt.Errorf("failed to decode default bucket id %s: %v", b.defaultBucketID, err)
+		}
+		update.DefaultBucketID = bucketID
+	}
 
 	o, err := orgSvc.UpdateOrganization(context.Background(), id, update)
 	if err != nil {
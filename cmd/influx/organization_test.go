@@ -242,6 +242,8 @@ func TestCmdOrg(t *testing.T) {
 	})
 
 	t.Run("update", func(t *testing.T) {
+		defaultBucketID := influxdb.ID(4)
+
 		tests := []struct {
 			name     string
 			expected influxdb.OrganizationUpdate
@@ -270,6 +272,16 @@ func TestCmdOrg(t *testing.T) {
 					Description: strPtr("desc"),
 				},
 			},
+			{
+				name: "default bucket",
+				flags: []string{
+					"--id=" + influxdb.ID(3).String(),
+					"--default-bucket-id=" + defaultBucketID.String(),
+				},
+				expected: influxdb.OrganizationUpdate{
+					DefaultBucketID: &defaultBucketID,
+				},
+			},
 			{
 				name: "shorts",
 				flags: []string{
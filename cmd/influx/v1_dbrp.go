@@ -0,0 +1,453 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/spf13/cobra"
+)
+
+type dbrpSVCsFn func() (influxdb.DBRPMappingService, influxdb.OrganizationService, error)
+
+func cmdV1(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdV1DBRPBuilder(newDBRPSVCs, opt)
+	builder.globalFlags = f
+
+	cmd := opt.newCmd("v1", nil, false)
+	cmd.Short = "InfluxDB v1 management commands"
+	cmd.Run = seeHelp
+	cmd.AddCommand(builder.cmd())
+	return cmd
+}
+
+type cmdV1DBRPBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn dbrpSVCsFn
+
+	json        bool
+	hideHeaders bool
+	org         organization
+	cluster     string
+	db          string
+	rp          string
+	bucketID    string
+	dbPrefix    string
+	dbPattern   string
+	dflt        bool
+	revision    string
+	file        string
+}
+
+func newCmdV1DBRPBuilder(svcsFn dbrpSVCsFn, opt genericCLIOpts) *cmdV1DBRPBuilder {
+	return &cmdV1DBRPBuilder{
+		genericCLIOpts: opt,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdV1DBRPBuilder) cmd() *cobra.Command {
+	cmd := b.newCmd("dbrp", nil, false)
+	cmd.Short = "Mappings of v1 databases and retention policies to buckets"
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdCreate(),
+		b.cmdDelete(),
+		b.cmdList(),
+		b.cmdUpdate(),
+	)
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) registerKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&b.cluster, "cluster", "", "The v1 cluster of the mapping")
+	cmd.Flags().StringVar(&b.db, "db", "", "The v1 database of the mapping")
+	cmd.Flags().StringVar(&b.rp, "rp", "", "The v1 retention policy of the mapping")
+}
+
+func (b *cmdV1DBRPBuilder) registerDatabaseMatchFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&b.dbPrefix, "db-prefix", "", "Only the mappings of the databases beginning with this prefix")
+	cmd.Flags().StringVar(&b.dbPattern, "db-pattern", "", "Only the mappings of the databases matching this glob pattern, such as 'tenant_*'")
+}
+
+// filter returns the filter of the mappings of the org selected by the key
+// and database match flags.
+func (b *cmdV1DBRPBuilder) filter(cmd *cobra.Command, orgID influxdb.ID) influxdb.DBRPMappingFilter {
+	filter := influxdb.DBRPMappingFilter{OrganizationID: &orgID}
+	if cmd.Flags().Changed("cluster") {
+		filter.Cluster = &b.cluster
+	}
+	if b.db != "" {
+		filter.Database = &b.db
+	}
+	if b.rp != "" {
+		filter.RetentionPolicy = &b.rp
+	}
+	if b.dbPrefix != "" {
+		filter.DatabasePrefix = &b.dbPrefix
+	}
+	if b.dbPattern != "" {
+		filter.DatabasePattern = &b.dbPattern
+	}
+	return filter
+}
+
+// findDBRPs returns all of the mappings matching filter, a page at a time.
+func findDBRPs(svc influxdb.DBRPMappingService, filter influxdb.DBRPMappingFilter) ([]*influxdb.DBRPMapping, error) {
+	var mappings []*influxdb.DBRPMapping
+	opts := influxdb.FindOptions{Limit: influxdb.MaxPageSize}
+	for {
+		ms, n, err := svc.FindMany(context.Background(), filter, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve dbrp mappings: %v", err)
+		}
+		mappings = append(mappings, ms...)
+		opts.Offset += len(ms)
+		if len(ms) == 0 || opts.Offset >= n {
+			return mappings, nil
+		}
+	}
+}
+
+func (b *cmdV1DBRPBuilder) cmdList() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdListRunEFn, true)
+	cmd.Short = "List mappings"
+	cmd.Aliases = []string{"find", "ls"}
+
+	b.registerKeyFlags(cmd)
+	b.registerDatabaseMatchFlags(cmd)
+	cmd.Flags().BoolVar(&b.dflt, "default", false, "Only list the mappings which are, or with --default=false are not, the default of their database")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdListRunEFn(cmd *cobra.Command, args []string) error {
+	dbrpSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	filter := b.filter(cmd, orgID)
+	if cmd.Flags().Changed("default") {
+		filter.Default = &b.dflt
+	}
+
+	mappings, err := findDBRPs(dbrpSVC, filter)
+	if err != nil {
+		return err
+	}
+
+	return b.printDBRPs(dbrpPrintOpt{mappings: mappings})
+}
+
+func (b *cmdV1DBRPBuilder) cmdCreate() *cobra.Command {
+	cmd := b.newCmd("create", b.cmdCreateRunEFn, true)
+	cmd.Short = "Create a mapping, or the mappings of a file"
+
+	b.registerKeyFlags(cmd)
+	cmd.Flags().StringVar(&b.bucketID, "bucket-id", "", "The ID of the bucket the database and retention policy are mapped to")
+	cmd.Flags().BoolVar(&b.dflt, "default", false, "Make the mapping the default of its database")
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "Path to a JSON array of mappings, such as listed with --json, to create instead of the flags")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdCreateRunEFn(cmd *cobra.Command, args []string) error {
+	dbrpSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	if b.file != "" {
+		ms, err := readDBRPFile(b.file)
+		if err != nil {
+			return err
+		}
+		created := make([]*influxdb.DBRPMapping, 0, len(ms))
+		for _, m := range ms {
+			if !m.OrganizationID.Valid() {
+				m.OrganizationID = orgID
+			}
+			if err := dbrpSVC.Create(context.Background(), &m.DBRPMapping); err != nil {
+				return fmt.Errorf("failed to create dbrp mapping of %s/%s: %v", m.Database, m.RetentionPolicy, err)
+			}
+			created = append(created, &m.DBRPMapping)
+		}
+		return b.printDBRPs(dbrpPrintOpt{mappings: created})
+	}
+
+	if b.db == "" || b.rp == "" || b.bucketID == "" {
+		return errors.New("must specify db, rp and bucket-id, or file")
+	}
+	bucketID, err := influxdb.IDFromString(b.bucketID)
+	if err != nil {
+		return fmt.Errorf("invalid bucket ID provided: %v", err)
+	}
+
+	m := &influxdb.DBRPMapping{
+		Cluster:         b.cluster,
+		Database:        b.db,
+		RetentionPolicy: b.rp,
+		Default:         b.dflt,
+		OrganizationID:  orgID,
+		BucketID:        *bucketID,
+	}
+	if err := dbrpSVC.Create(context.Background(), m); err != nil {
+		return fmt.Errorf("failed to create dbrp mapping: %v", err)
+	}
+
+	return b.printDBRPs(dbrpPrintOpt{mapping: m})
+}
+
+func (b *cmdV1DBRPBuilder) cmdUpdate() *cobra.Command {
+	cmd := b.newCmd("update", b.cmdUpdateRunEFn, true)
+	cmd.Short = "Update a mapping, or the mappings of a file"
+
+	b.registerKeyFlags(cmd)
+	cmd.Flags().StringVar(&b.bucketID, "bucket-id", "", "The ID of the bucket to map the database and retention policy to")
+	cmd.Flags().BoolVar(&b.dflt, "default", false, "Whether the mapping is the default of its database")
+	cmd.Flags().StringVar(&b.revision, "revision", "", "Only update the mapping if it is still at this revision")
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "Path to a JSON array of mappings, such as listed with --json, to update instead of the flags. Mappings with a revision are only updated if they are still at it")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) error {
+	dbrpSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	if b.file != "" {
+		ms, err := readDBRPFile(b.file)
+		if err != nil {
+			return err
+		}
+		updated := make([]*influxdb.DBRPMapping, 0, len(ms))
+		for _, m := range ms {
+			if !m.OrganizationID.Valid() {
+				m.OrganizationID = orgID
+			}
+			revision := m.Revision
+			if revision == "" {
+				revision = "*"
+			}
+			if err := dbrpSVC.Replace(context.Background(), &m.DBRPMapping, revision); err != nil {
+				return fmt.Errorf("failed to update dbrp mapping of %s/%s: %v", m.Database, m.RetentionPolicy, err)
+			}
+			updated = append(updated, &m.DBRPMapping)
+		}
+		return b.printDBRPs(dbrpPrintOpt{mappings: updated})
+	}
+
+	if b.db == "" || b.rp == "" {
+		return errors.New("must specify db and rp, or file")
+	}
+
+	m, err := dbrpSVC.Find(context.Background(), influxdb.DBRPMappingFilter{
+		OrganizationID:  &orgID,
+		Cluster:         &b.cluster,
+		Database:        &b.db,
+		RetentionPolicy: &b.rp,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find dbrp mapping: %v", err)
+	}
+	revision := m.Revision()
+	if b.revision != "" {
+		revision = b.revision
+	}
+
+	if b.bucketID != "" {
+		bucketID, err := influxdb.IDFromString(b.bucketID)
+		if err != nil {
+			return fmt.Errorf("invalid bucket ID provided: %v", err)
+		}
+		m.BucketID = *bucketID
+	}
+	if cmd.Flags().Changed("default") {
+		m.Default = b.dflt
+	}
+
+	if err := dbrpSVC.Replace(context.Background(), m, revision); err != nil {
+		return fmt.Errorf("failed to update dbrp mapping: %v", err)
+	}
+
+	return b.printDBRPs(dbrpPrintOpt{mapping: m})
+}
+
+func (b *cmdV1DBRPBuilder) cmdDelete() *cobra.Command {
+	cmd := b.newCmd("delete", b.cmdDeleteRunEFn, true)
+	cmd.Short = "Delete a mapping, or all of the mappings of the databases matching a prefix or pattern"
+
+	b.registerKeyFlags(cmd)
+	b.registerDatabaseMatchFlags(cmd)
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdDeleteRunEFn(cmd *cobra.Command, args []string) error {
+	dbrpSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	if b.dbPrefix != "" || b.dbPattern != "" {
+		orgID, err := b.org.getID(orgSVC)
+		if err != nil {
+			return err
+		}
+		// All of the matching mappings are found before any is deleted, as
+		// deleting them would shift the pages.
+		mappings, err := findDBRPs(dbrpSVC, b.filter(cmd, orgID))
+		if err != nil {
+			return err
+		}
+		for _, m := range mappings {
+			if err := dbrpSVC.Delete(context.Background(), m.Cluster, m.Database, m.RetentionPolicy); err != nil {
+				return fmt.Errorf("failed to delete dbrp mapping of %s/%s: %v", m.Database, m.RetentionPolicy, err)
+			}
+		}
+		return b.printDBRPs(dbrpPrintOpt{deleted: true, mappings: mappings})
+	}
+
+	if b.db == "" || b.rp == "" {
+		return errors.New("must specify db and rp, or db-prefix or db-pattern")
+	}
+	if err := dbrpSVC.Delete(context.Background(), b.cluster, b.db, b.rp); err != nil {
+		return fmt.Errorf("failed to delete dbrp mapping: %v", err)
+	}
+
+	return b.printDBRPs(dbrpPrintOpt{
+		deleted: true,
+		mapping: &influxdb.DBRPMapping{
+			Cluster:         b.cluster,
+			Database:        b.db,
+			RetentionPolicy: b.rp,
+		},
+	})
+}
+
+func (b *cmdV1DBRPBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+// dbrpFileMapping is a mapping as written by list --json and read by the
+// --file of create and update.
+type dbrpFileMapping struct {
+	influxdb.DBRPMapping
+	Revision string `json:"revision,omitempty"`
+}
+
+func newDBRPFileMapping(m *influxdb.DBRPMapping) dbrpFileMapping {
+	return dbrpFileMapping{
+		DBRPMapping: *m,
+		Revision:    m.Revision(),
+	}
+}
+
+func readDBRPFile(path string) ([]*dbrpFileMapping, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dbrp mappings: %v", err)
+	}
+	var ms []*dbrpFileMapping
+	if err := json.Unmarshal(b, &ms); err != nil {
+		return nil, fmt.Errorf("failed to decode dbrp mappings of %s: %v", path, err)
+	}
+	return ms, nil
+}
+
+func (b *cmdV1DBRPBuilder) printDBRPs(opt dbrpPrintOpt) error {
+	if b.json {
+		if opt.mapping != nil {
+			if opt.deleted {
+				return b.writeJSON(opt.mapping)
+			}
+			return b.writeJSON(newDBRPFileMapping(opt.mapping))
+		}
+		ms := make([]dbrpFileMapping, 0, len(opt.mappings))
+		for _, m := range opt.mappings {
+			ms = append(ms, newDBRPFileMapping(m))
+		}
+		return b.writeJSON(ms)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{"Cluster", "Database", "Retention Policy"}
+	if opt.deleted {
+		headers = append(headers, "Deleted")
+	} else {
+		headers = append(headers, "Default", "Organization ID", "Bucket ID", "Revision")
+	}
+	w.WriteHeaders(headers...)
+
+	if opt.mapping != nil {
+		opt.mappings = append(opt.mappings, opt.mapping)
+	}
+
+	for _, m := range opt.mappings {
+		row := map[string]interface{}{
+			"Cluster":          m.Cluster,
+			"Database":         m.Database,
+			"Retention Policy": m.RetentionPolicy,
+		}
+		if opt.deleted {
+			row["Deleted"] = true
+		} else {
+			row["Default"] = m.Default
+			row["Organization ID"] = m.OrganizationID.String()
+			row["Bucket ID"] = m.BucketID.String()
+			row["Revision"] = m.Revision()
+		}
+		w.Write(row)
+	}
+
+	return nil
+}
+
+type dbrpPrintOpt struct {
+	deleted  bool
+	mapping  *influxdb.DBRPMapping
+	mappings []*influxdb.DBRPMapping
+}
+
+func newDBRPSVCs() (influxdb.DBRPMappingService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	orgSvc := &http.OrganizationService{Client: httpClient}
+
+	return &http.DBRPMappingService{Client: httpClient}, orgSvc, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdV1DBRP(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	fakeSVCFn := func(svc influxdb.DBRPMappingService) dbrpSVCsFn {
+		return func() (influxdb.DBRPMappingService, influxdb.OrganizationService, error) {
+			return svc, &mock.OrganizationService{
+				FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+					return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+				},
+			}, nil
+		}
+	}
+
+	run := func(t *testing.T, svc influxdb.DBRPMappingService, args ...string) string {
+		t.Helper()
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		buf := new(bytes.Buffer)
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(buf),
+		)
+		cmd := builder.cmd(func(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+			b := newCmdV1DBRPBuilder(fakeSVCFn(svc), opt)
+			v1 := opt.newCmd("v1", nil, false)
+			v1.AddCommand(b.cmd())
+			return v1
+		})
+		cmd.SetArgs(append([]string{"v1", "dbrp"}, args...))
+		require.NoError(t, cmd.Execute())
+		return buf.String()
+	}
+
+	mapping := func(rp string, bucketID influxdb.ID) *influxdb.DBRPMapping {
+		return &influxdb.DBRPMapping{
+			Database:        "telegraf",
+			RetentionPolicy: rp,
+			OrganizationID:  orgID,
+			BucketID:        bucketID,
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		var got *influxdb.DBRPMapping
+		svc := mock.NewDBRPMappingService()
+		svc.CreateFn = func(ctx context.Context, m *influxdb.DBRPMapping) error {
+			got = m
+			return nil
+		}
+
+		run(t, svc, "create", "--org=influxdata", "--db=telegraf", "--rp=autogen", "--bucket-id="+influxdb.ID(3).String(), "--default")
+
+		want := mapping("autogen", 3)
+		want.Default = true
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("create from file", func(t *testing.T) {
+		var got []*influxdb.DBRPMapping
+		svc := mock.NewDBRPMappingService()
+		svc.CreateFn = func(ctx context.Context, m *influxdb.DBRPMapping) error {
+			got = append(got, m)
+			return nil
+		}
+
+		b, err := json.Marshal([]*influxdb.DBRPMapping{mapping("autogen", 3), mapping("week", 4)})
+		require.NoError(t, err)
+		path, remove := writeDBRPFile(t, b)
+		defer remove()
+
+		out := run(t, svc, "create", "--org=influxdata", "--file="+path, "--json")
+
+		assert.Equal(t, []*influxdb.DBRPMapping{mapping("autogen", 3), mapping("week", 4)}, got)
+		var printed []dbrpFileMapping
+		require.NoError(t, json.Unmarshal([]byte(out), &printed))
+		require.Len(t, printed, 2)
+		assert.Equal(t, got[1].Revision(), printed[1].Revision)
+	})
+
+	t.Run("list pages", func(t *testing.T) {
+		var filter influxdb.DBRPMappingFilter
+		svc := mock.NewDBRPMappingService()
+		svc.FindManyFn = func(ctx context.Context, f influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+			filter = f
+			ms := make([]*influxdb.DBRPMapping, 0, opt[0].Limit)
+			for i := opt[0].Offset; i < 150 && len(ms) < opt[0].Limit; i++ {
+				ms = append(ms, mapping("rp", influxdb.ID(i+1)))
+			}
+			return ms, 150, nil
+		}
+
+		out := run(t, svc, "list", "--org=influxdata", "--db=telegraf", "--db-prefix=tele", "--default=false", "--json")
+
+		var printed []dbrpFileMapping
+		require.NoError(t, json.Unmarshal([]byte(out), &printed))
+		assert.Len(t, printed, 150)
+		assert.Equal(t, orgID, *filter.OrganizationID)
+		assert.Equal(t, "telegraf", *filter.Database)
+		assert.Equal(t, "tele", *filter.DatabasePrefix)
+		assert.Nil(t, filter.DatabasePattern)
+		assert.False(t, *filter.Default)
+		assert.Nil(t, filter.RetentionPolicy)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		existing := mapping("autogen", 3)
+		var (
+			got      *influxdb.DBRPMapping
+			revision string
+		)
+		svc := mock.NewDBRPMappingService()
+		svc.FindFn = func(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+			m := *existing
+			return &m, nil
+		}
+		svc.ReplaceFn = func(ctx context.Context, m *influxdb.DBRPMapping, rev string) error {
+			got, revision = m, rev
+			return nil
+		}
+
+		run(t, svc, "update", "--org=influxdata", "--db=telegraf", "--rp=autogen", "--bucket-id="+influxdb.ID(5).String())
+
+		assert.Equal(t, mapping("autogen", 5), got)
+		assert.Equal(t, existing.Revision(), revision)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		var got []string
+		svc := mock.NewDBRPMappingService()
+		svc.DeleteFn = func(ctx context.Context, cluster, db, rp string) error {
+			got = []string{cluster, db, rp}
+			return nil
+		}
+
+		run(t, svc, "delete", "--db=telegraf", "--rp=autogen")
+
+		assert.Equal(t, []string{"", "telegraf", "autogen"}, got)
+	})
+
+	t.Run("delete by pattern", func(t *testing.T) {
+		var (
+			filter influxdb.DBRPMappingFilter
+			got    []string
+		)
+		svc := mock.NewDBRPMappingService()
+		svc.FindManyFn = func(ctx context.Context, f influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+			filter = f
+			ms := []*influxdb.DBRPMapping{mapping("autogen", 3), mapping("autogen", 4)}
+			ms[0].Database, ms[1].Database = "tenant_a", "tenant_b"
+			return ms, len(ms), nil
+		}
+		svc.DeleteFn = func(ctx context.Context, cluster, db, rp string) error {
+			got = append(got, db)
+			return nil
+		}
+
+		run(t, svc, "delete", "--org=influxdata", "--db-prefix=tenant_", "--db-pattern=*_[ab]")
+
+		assert.Equal(t, []string{"tenant_a", "tenant_b"}, got)
+		assert.Equal(t, orgID, *filter.OrganizationID)
+		assert.Equal(t, "tenant_", *filter.DatabasePrefix)
+		assert.Equal(t, "*_[ab]", *filter.DatabasePattern)
+		assert.Nil(t, filter.Database)
+	})
+}
+
+func TestReadDBRPFile(t *testing.T) {
+	path, remove := writeDBRPFile(t, []byte(`{"database": "telegraf"}`))
+	defer remove()
+	_, err := readDBRPFile(path)
+	assert.Error(t, err, "expected a JSON array of mappings")
+
+	_, err = readDBRPFile(filepath.Join(filepath.Dir(path), "missing.json"))
+	assert.Error(t, err, "expected the missing file to be reported")
+}
+
+func writeDBRPFile(t *testing.T, b []byte) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "influx-dbrp")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "dbrps.json")
+	require.NoError(t, ioutil.WriteFile(path, b, 0600))
+	return path, func() { os.RemoveAll(dir) }
+}
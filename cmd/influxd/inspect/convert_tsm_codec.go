@@ -0,0 +1,79 @@
+package inspect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// convertTSMCodecFlags defines the `convert-tsm-codec` Command.
+var convertTSMCodecFlags = struct {
+	cli.OrgBucket
+	codec string
+}{}
+
+func NewConvertTSMCodecCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert-tsm-codec <pathspec>...",
+		Short: "Converts the compression codec of the string blocks of TSM files",
+		Long: `
+This command rewrites a set of TSM files with their string blocks compressed
+with the given codec. Files are replaced in place, and files without blocks to
+convert are left untouched. influxd must not be running.
+
+Both codecs are always readable, and compactions compress the blocks they
+write with the codec of their bucket, so converting is only needed to apply a
+change of codec to existing data sooner.
+
+OPTIONS
+
+   <pathspec>...
+      A list of files or directories to search for TSM files.
+
+An optional organization or organization and bucket may be specified to limit
+the conversion to their blocks.
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: convertTSMCodecF,
+	}
+
+	convertTSMCodecFlags.AddFlags(cmd)
+	cmd.Flags().StringVar(&convertTSMCodecFlags.codec, "codec", "zstd", "compression codec of the string blocks, snappy or zstd")
+
+	return cmd
+}
+
+func convertTSMCodecF(cmd *cobra.Command, args []string) error {
+	codec, err := tsm1.ParseCompressionCodec(convertTSMCodecFlags.codec)
+	if err != nil {
+		return err
+	}
+
+	convert := tsm1.ConvertTSMCodec{
+		Stdout:   os.Stdout,
+		OrgID:    convertTSMCodecFlags.Org,
+		BucketID: convertTSMCodecFlags.Bucket,
+		Codec:    codec,
+	}
+
+	// resolve all pathspecs
+	for _, arg := range args {
+		fi, err := os.Stat(arg)
+		if err != nil {
+			return fmt.Errorf("error processing path %q: %v", arg, err)
+		}
+
+		if fi.IsDir() {
+			files, _ := filepath.Glob(filepath.Join(arg, "*."+tsm1.TSMFileExtension))
+			convert.Paths = append(convert.Paths, files...)
+		} else {
+			convert.Paths = append(convert.Paths, arg)
+		}
+	}
+
+	return convert.Run()
+}
@@ -16,6 +16,7 @@ func NewCommand() *cobra.Command {
 	subCommands := []*cobra.Command{
 		NewBuildTSICommand(),
 		NewCompactSeriesFileCommand(),
+		NewConvertTSMCodecCommand(),
 		NewExportBlocksCommand(),
 		NewExportIndexCommand(),
 		NewReportTSMCommand(),
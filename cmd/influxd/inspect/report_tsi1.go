@@ -27,7 +27,7 @@ var reportTSIFlags = struct {
 	// Reporting options
 	TopN          int
 	ByMeasurement bool
-	byTagKey      bool // currently unused
+	ByTagKey      bool
 }{}
 
 // NewReportTsiCommand returns a new instance of Command with default setting applied.
@@ -49,13 +49,18 @@ func NewReportTSICommand() *cobra.Command {
 		
 			* Series cardinality for each organization;
 			* Series cardinality for each bucket;
-			* Series cardinality for each measurement;`,
+			* Series cardinality for each measurement;
+		
+		Depending on the --tags flag, the tag keys of each bucket are listed with
+		their number of values, the keys with the most values first, followed by
+		the values contributing the most series.`,
 		RunE: RunReportTSI,
 	}
 
 	cmd.Flags().StringVar(&reportTSIFlags.Path, "path", os.Getenv("HOME")+"/.influxdbv2/engine/index", "Path to index. Defaults $HOME/.influxdbv2/engine/index")
 	cmd.Flags().StringVar(&reportTSIFlags.SeriesFilePath, "series-file", os.Getenv("HOME")+"/.influxdbv2/engine/_series", "Optional path to series file. Defaults $HOME/.influxdbv2/engine/_series")
 	cmd.Flags().BoolVarP(&reportTSIFlags.ByMeasurement, "measurements", "m", false, "Segment cardinality by measurements")
+	cmd.Flags().BoolVarP(&reportTSIFlags.ByTagKey, "tags", "k", false, "Segment cardinality by tag keys and values")
 	cmd.Flags().IntVarP(&reportTSIFlags.TopN, "top", "t", 0, "Limit results to top n")
 	cmd.Flags().StringVarP(&reportTSIFlags.Bucket, "bucket_id", "b", "", "If bucket is specified, org must be specified. A bucket id must be a base-16 string")
 	cmd.Flags().StringVarP(&reportTSIFlags.Org, "org_id", "o", "", "Only specified org data will be reported. An org id must be a base-16 string")
@@ -70,6 +75,7 @@ func RunReportTSI(cmd *cobra.Command, args []string) error {
 	report := tsi1.NewReportCommand()
 	report.DataPath = reportTSIFlags.Path
 	report.ByMeasurement = reportTSIFlags.ByMeasurement
+	report.ByTagKey = reportTSIFlags.ByTagKey
 	report.TopN = reportTSIFlags.TopN
 	report.SeriesDirPath = reportTSIFlags.SeriesFilePath
 
@@ -10,10 +10,13 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/kit/prom"
+	"github.com/influxdata/influxdb/v2/limitalert"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxdb/v2/tsdb/tsi1"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
 	"github.com/influxdata/influxql"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -30,6 +33,13 @@ type Engine interface {
 	storage.BucketDeleter
 	prom.PrometheusCollector
 	influxdb.BackupService
+	influxdb.CacheFlushService
+	influxdb.BlockQuarantineService
+	influxdb.SeriesCardinalityService
+	influxdb.BucketMetadataService
+	storage.SnapshotEngine
+	storage.FieldTypeEngine
+	limitalert.Usage
 
 	SeriesCardinality() int64
 
@@ -175,6 +185,54 @@ func (t *TemporaryEngine) FetchBackupFile(ctx context.Context, backupID int, bac
 	return t.engine.FetchBackupFile(ctx, backupID, backupFile, w)
 }
 
+func (t *TemporaryEngine) FlushBucket(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.CacheFlushResult, error) {
+	return t.engine.FlushBucket(ctx, orgID, bucketID)
+}
+
+func (t *TemporaryEngine) FindQuarantinedBlocks(ctx context.Context, filter influxdb.QuarantinedBlockFilter) ([]*influxdb.QuarantinedBlock, error) {
+	return t.engine.FindQuarantinedBlocks(ctx, filter)
+}
+
+func (t *TemporaryEngine) SeriesCardinalityReport(ctx context.Context, orgID, bucketID influxdb.ID, limit int) (*influxdb.SeriesCardinalityReport, error) {
+	return t.engine.SeriesCardinalityReport(ctx, orgID, bucketID, limit)
+}
+
+func (t *TemporaryEngine) FindBucketMeasurements(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.MeasurementMetadata, error) {
+	return t.engine.FindBucketMeasurements(ctx, orgID, bucketID)
+}
+
+func (t *TemporaryEngine) FindBucketTagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*influxdb.TagKeyMetadata, error) {
+	return t.engine.FindBucketTagKeys(ctx, orgID, bucketID, measurement)
+}
+
+func (t *TemporaryEngine) FindBucketFieldKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*influxdb.FieldKeyMetadata, error) {
+	return t.engine.FindBucketFieldKeys(ctx, orgID, bucketID, measurement)
+}
+
+func (t *TemporaryEngine) FieldTypeConflicts(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.FieldTypeConflict, error) {
+	return t.engine.FieldTypeConflicts(ctx, orgID, bucketID)
+}
+
+func (t *TemporaryEngine) ConvertFieldType(ctx context.Context, c influxdb.FieldTypeConversion, progress func(done, total int)) (int, error) {
+	return t.engine.ConvertFieldType(ctx, c, progress)
+}
+
+func (t *TemporaryEngine) MeasurementCardinalityStats() (tsi1.MeasurementCardinalityStats, error) {
+	return t.engine.MeasurementCardinalityStats()
+}
+
+func (t *TemporaryEngine) MeasurementStats() (tsm1.MeasurementStats, error) {
+	return t.engine.MeasurementStats()
+}
+
+func (t *TemporaryEngine) LinkSnapshot(ctx context.Context, dir string) error {
+	return t.engine.LinkSnapshot(ctx, dir)
+}
+
+func (t *TemporaryEngine) SnapshotsPath() string {
+	return t.engine.SnapshotsPath()
+}
+
 func (t *TemporaryEngine) InternalBackupPath(backupID int) string {
 	return t.engine.InternalBackupPath(backupID)
 }
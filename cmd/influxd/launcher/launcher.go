@@ -3,9 +3,11 @@ package launcher
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"net"
 	nethttp "net/http"
@@ -17,35 +19,63 @@ import (
 
 	"github.com/influxdata/flux"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/alerthistory"
 	"github.com/influxdata/influxdb/v2/authorizer"
 	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/bucketfamily"
+	"github.com/influxdata/influxdb/v2/cellrender"
 	"github.com/influxdata/influxdb/v2/chronograf/server"
+	"github.com/influxdata/influxdb/v2/cluster"
 	"github.com/influxdata/influxdb/v2/cmd/influxd/inspect"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/edge"
 	"github.com/influxdata/influxdb/v2/endpoints"
+	"github.com/influxdata/influxdb/v2/federation"
+	"github.com/influxdata/influxdb/v2/fluxfragment"
 	"github.com/influxdata/influxdb/v2/gather"
 	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/http/metric"
 	"github.com/influxdata/influxdb/v2/inmem"
 	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/internalstats"
+	"github.com/influxdata/influxdb/v2/jobs"
 	"github.com/influxdata/influxdb/v2/kit/cli"
 	"github.com/influxdata/influxdb/v2/kit/feature"
 	overrideflagger "github.com/influxdata/influxdb/v2/kit/feature/override"
+	featuresettings "github.com/influxdata/influxdb/v2/kit/feature/settings"
 	"github.com/influxdata/influxdb/v2/kit/prom"
 	"github.com/influxdata/influxdb/v2/kit/signals"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/lastvalue"
+	"github.com/influxdata/influxdb/v2/lifecycle"
+	"github.com/influxdata/influxdb/v2/limitalert"
 	influxlogger "github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/nats"
+	"github.com/influxdata/influxdb/v2/orgdeletion"
+	"github.com/influxdata/influxdb/v2/otlp"
 	"github.com/influxdata/influxdb/v2/pkger"
 	infprom "github.com/influxdata/influxdb/v2/prometheus"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/control"
+	fluxhttp "github.com/influxdata/influxdb/v2/query/stdlib/http"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	fluxemail "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb/email"
+	fluxslackapp "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb/slackapp"
+	influxdbv1 "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb/v1"
+	"github.com/influxdata/influxdb/v2/reports"
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/source"
+	"github.com/influxdata/influxdb/v2/staleresource"
+	"github.com/influxdata/influxdb/v2/statsd"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/export"
 	storageflux "github.com/influxdata/influxdb/v2/storage/flux"
+	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/influxdata/influxdb/v2/storage/readservice"
+	"github.com/influxdata/influxdb/v2/streamcheck"
+	"github.com/influxdata/influxdb/v2/systeminfo"
 	taskbackend "github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/task/backend/coordinator"
 	"github.com/influxdata/influxdb/v2/task/backend/executor"
@@ -54,8 +84,11 @@ import (
 	"github.com/influxdata/influxdb/v2/telemetry"
 	"github.com/influxdata/influxdb/v2/tenant"
 	_ "github.com/influxdata/influxdb/v2/tsdb/tsi1" // needed for tsi1
-	_ "github.com/influxdata/influxdb/v2/tsdb/tsm1" // needed for tsm1
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"github.com/influxdata/influxdb/v2/udp"
 	"github.com/influxdata/influxdb/v2/vault"
+	"github.com/influxdata/influxdb/v2/webhook"
+	"github.com/influxdata/influxdb/v2/writesample"
 	pzap "github.com/influxdata/influxdb/v2/zap"
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
@@ -63,6 +96,9 @@ import (
 	jaegerconfig "github.com/uber/jaeger-client-go/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -148,6 +184,92 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: ":9999",
 			Desc:    "bind address for the REST HTTP API",
 		},
+		{
+			DestP:   &l.httpWriteDedupeMaxBatches,
+			Flag:    "http-write-dedupe-max-batches",
+			Default: http.DefaultWriteDedupeMaxBatches,
+			Desc:    "the maximum number of write batch IDs remembered for buckets with a dedupe window. Set to 0 to disable write deduplication",
+		},
+		{
+			DestP: &l.httpMaxConnections,
+			Flag:  "http-max-connections",
+			Desc:  "the maximum number of HTTP connections served at once. Connections over the limit wait for http-max-connections-queue-timeout and are then closed. Set to 0 for no limit",
+		},
+		{
+			DestP:   &l.httpMaxConnectionsQueueTimeout,
+			Flag:    "http-max-connections-queue-timeout",
+			Default: time.Second,
+			Desc:    "how long a connection over http-max-connections waits for another connection to close before it is closed",
+		},
+		{
+			DestP: &l.httpMaxConcurrentStreams,
+			Flag:  "http-max-concurrent-streams",
+			Desc:  "the maximum number of concurrent HTTP/2 streams per connection. Set to 0 to use the default of 250",
+		},
+		{
+			DestP:   &l.httpReadHeaderTimeout,
+			Flag:    "http-read-header-timeout",
+			Default: 10 * time.Second,
+			Desc:    "the maximum duration to read the headers of an HTTP request. Set to 0 for no timeout",
+		},
+		{
+			DestP: &l.httpReadTimeout,
+			Flag:  "http-read-timeout",
+			Desc:  "the maximum duration to read an HTTP request, including its body. Set to 0 for no timeout",
+		},
+		{
+			DestP: &l.httpWriteTimeout,
+			Flag:  "http-write-timeout",
+			Desc:  "the maximum duration to write an HTTP response, including the execution of queries. Set to 0 for no timeout",
+		},
+		{
+			DestP:   &l.httpIdleTimeout,
+			Flag:    "http-idle-timeout",
+			Default: 3 * time.Minute,
+			Desc:    "how long an idle keep-alive HTTP connection is kept open. Set to 0 to keep idle connections open until the read timeout",
+		},
+		{
+			DestP: &l.httpTrustedProxies,
+			Flag:  "http-trusted-proxies",
+			Desc:  "CIDRs or addresses of the reverse proxies and load balancers whose X-Forwarded-For header identifies the client of a request. X-Forwarded-For is ignored when empty",
+		},
+		{
+			DestP:   &l.httpAuthenticators,
+			Flag:    "http-authenticators",
+			Default: http.DefaultAuthenticators,
+			Desc:    "ordered authenticators of HTTP requests, of token, session, v1-basic, mtls and signed-url. The first authenticator finding credentials in a request authenticates it",
+		},
+		{
+			DestP: &l.httpOrgDomain,
+			Flag:  "http-org-domain",
+			Desc:  "the domain of the instance, such as example.com, whose subdomains mapped with /api/v2/orgDomains scope the requests made to them to an organization. Subdomains are not routed when empty",
+		},
+		{
+			DestP:   &l.httpCrashReportPath,
+			Flag:    "http-crash-report-path",
+			Default: filepath.Join(dir, "crash-reports"),
+			Desc:    "path to write the crash reports of panics handling HTTP requests to. Crash reports are only logged when empty",
+		},
+		{
+			DestP: &l.otlpGRPCBindAddress,
+			Flag:  "otlp-grpc-bind-address",
+			Desc:  "bind address for receiving OpenTelemetry metrics with OTLP/gRPC, such as :4317. Disabled when empty",
+		},
+		{
+			DestP: &l.grpcBindAddress,
+			Flag:  "grpc-bind-address",
+			Desc:  "bind address of the gRPC API for resolving and managing DBRP mappings, such as :8087. Disabled when empty",
+		},
+		{
+			DestP: &l.statsdListeners,
+			Flag:  "statsd-listeners",
+			Desc:  "StatsD listeners in the form <udp|tcp>://<bind address>?bucket=<bucket id>[&flush-interval=<duration>][&percentiles=<percentile>,...]. Metrics are aggregated and written to the bucket every flush interval",
+		},
+		{
+			DestP: &l.udpListeners,
+			Flag:  "udp-listeners",
+			Desc:  "UDP line protocol listeners in the form udp://<bind address>?bucket=<bucket id>[&batch-size=<points>][&batch-pending=<batches>][&batch-timeout=<duration>][&precision=<ns|us|ms|s>][&read-buffer=<bytes>]. Points are batched and written to the bucket, and dropped when writes fall behind",
+		},
 		{
 			DestP:   &l.boltPath,
 			Flag:    "bolt-path",
@@ -159,6 +281,21 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Flag:  "assets-path",
 			Desc:  "override default assets by serving from a specific directory (developer mode)",
 		},
+		{
+			DestP: &l.branding.LogoPath,
+			Flag:  "ui-logo-path",
+			Desc:  "path of an image file served as the logo of the UI",
+		},
+		{
+			DestP: &l.branding.LoginBanner,
+			Flag:  "ui-login-banner",
+			Desc:  "text shown on the login page of the UI",
+		},
+		{
+			DestP: &l.branding.BasePath,
+			Flag:  "ui-base-path",
+			Desc:  "URL path prefix, such as /influxdb, under which the UI and the API are served when behind a proxy",
+		},
 		{
 			DestP:   &l.storeType,
 			Flag:    "store",
@@ -201,6 +338,48 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disables automatically extending session ttl on request",
 		},
+		{
+			DestP:   &l.tokenUsageInterval,
+			Flag:    "token-usage-interval",
+			Default: 10 * time.Minute,
+			Desc:    "minimum interval between recording the last use of a token; 0 disables recording token usage",
+		},
+		{
+			DestP:   &l.resourceActivityInterval,
+			Flag:    "resource-activity-interval",
+			Default: time.Hour,
+			Desc:    "minimum interval between recording the last read or write of a bucket or dashboard, for the stale resource reports; 0 disables recording resource activity",
+		},
+		{
+			DestP:   &l.passwordPolicy.MinLength,
+			Flag:    "password-min-length",
+			Default: kv.MinPasswordLength,
+			Desc:    "minimum number of characters in user passwords",
+		},
+		{
+			DestP:   &l.passwordPolicy.MinCharacterClasses,
+			Flag:    "password-min-character-classes",
+			Default: 0,
+			Desc:    "minimum number of character classes (lower case, upper case, digits and symbols) in user passwords",
+		},
+		{
+			DestP:   &l.passwordPolicy.HistorySize,
+			Flag:    "password-history-size",
+			Default: 0,
+			Desc:    "number of most recent passwords, including the current one, that a user may not reuse",
+		},
+		{
+			DestP:   &l.passwordPolicy.MaxFailedAttempts,
+			Flag:    "password-max-failed-attempts",
+			Default: 0,
+			Desc:    "number of consecutive failed password attempts after which a user is locked out; 0 disables lockout",
+		},
+		{
+			DestP:   &l.passwordPolicy.LockoutDuration,
+			Flag:    "password-lockout-duration",
+			Default: 15 * time.Minute,
+			Desc:    "duration a user is locked out after too many failed password attempts; 0 locks out until the user is unlocked",
+		},
 		{
 			DestP: &vaultConfig.Address,
 			Flag:  "vault-addr",
@@ -263,6 +442,11 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: "",
 			Desc:    "TLS key for HTTPs",
 		},
+		{
+			DestP: &l.httpTLSClientCA,
+			Flag:  "tls-client-ca",
+			Desc:  "path to the PEM-encoded CA certificates verifying the TLS client certificates of the mtls authenticator. Client certificates are not requested when empty",
+		},
 		{
 			DestP:   &l.enableNewMetaStore,
 			Flag:    "new-meta-store",
@@ -305,11 +489,198 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: 10,
 			Desc:    "the number of queries that are allowed to be awaiting execution before new queries are rejected",
 		},
+		{
+			DestP:   &l.memoryAutoTune,
+			Flag:    "memory-auto-tune",
+			Default: true,
+			Desc:    "size the Go memory limit, query-max-memory-bytes, query-memory-bytes and the storage cache to the memory limit of influxd. Settings given explicitly, and GOMEMLIMIT, are kept",
+		},
+		{
+			DestP:   &l.memoryLimitBytes,
+			Flag:    "memory-limit-bytes",
+			Default: 0,
+			Desc:    "the memory influxd may use, to which memory-auto-tune sizes its memory. If this is unset, the memory limit of its cgroup is used, if any",
+		},
+		{
+			DestP:   &l.queryResultSpillPath,
+			Flag:    "query-result-spill-path",
+			Default: filepath.Join(dir, "query-results"),
+			Desc:    "path to spill the results of queries paged through with nextResultToken to. Paging query results is disabled when empty",
+		},
+		{
+			DestP:   &l.queryResultTTL,
+			Flag:    "query-result-ttl",
+			Default: http.DefaultQueryResultTTL,
+			Desc:    "how long the spilled results of a paged query are kept for",
+		},
+		{
+			DestP:   &l.queryResultMaxBytes,
+			Flag:    "query-result-max-bytes",
+			Default: http.DefaultQueryResultMaxBytes,
+			Desc:    "the largest result of a paged query spilled. If this is unset, results are unlimited",
+		},
+		{
+			DestP:   &l.storageReadMaxSeries,
+			Flag:    "storage-read-max-series",
+			Default: 0,
+			Desc:    "the maximum number of series a single storage read may match. If this is unset, the number of series is unlimited",
+		},
+		{
+			DestP:   &l.storageReadMaxPoints,
+			Flag:    "storage-read-max-points",
+			Default: 0,
+			Desc:    "the maximum number of points a single storage read may scan. If this is unset, the number of points is unlimited",
+		},
+		{
+			DestP: &l.storageReadOrgLimits,
+			Flag:  "storage-read-org-limits",
+			Desc:  "per-organization overrides of the storage read limits, in the form <org id>=<max series>:<max points>",
+		},
+		{
+			DestP:   &l.clusterEnabled,
+			Flag:    "cluster-enabled",
+			Default: false,
+			Desc:    "replicate the metadata store across the nodes of a cluster with raft. Requires the bolt store",
+		},
+		{
+			DestP: &l.clusterConfig.NodeID,
+			Flag:  "cluster-node-id",
+			Desc:  "the ID of this node, unique within the cluster",
+		},
+		{
+			DestP:   &l.clusterConfig.BindAddress,
+			Flag:    "cluster-bind-address",
+			Default: ":8089",
+			Desc:    "bind address for raft replication between nodes of the cluster",
+		},
+		{
+			DestP: &l.clusterConfig.AdvertiseAddress,
+			Flag:  "cluster-advertise-address",
+			Desc:  "the raft address other nodes use to reach this node. Defaults to the bind address",
+		},
+		{
+			DestP: &l.clusterConfig.HTTPAddress,
+			Flag:  "cluster-http-address",
+			Desc:  "the URL other nodes use to reach the HTTP API of this node",
+		},
+		{
+			DestP: &l.clusterConfig.Join,
+			Flag:  "cluster-join",
+			Desc:  "the URL of the HTTP API of a node of the cluster to join. If unset, a new cluster is bootstrapped",
+		},
+		{
+			DestP: &l.clusterConfig.Secret,
+			Flag:  "cluster-secret",
+			Desc:  "the secret shared by the nodes of the cluster to authenticate each other",
+		},
+		{
+			DestP:   &l.clusterConfig.Path,
+			Flag:    "cluster-path",
+			Default: filepath.Join(dir, "raft"),
+			Desc:    "path to the raft log and snapshots of the cluster",
+		},
+		{
+			DestP:   &l.edgeMode,
+			Flag:    "edge-mode",
+			Default: false,
+			Desc:    "run as an edge instance which keeps a rolling window of recent data and forwards all writes to a central instance",
+		},
+		{
+			DestP: &l.edgeConfig.URL,
+			Flag:  "edge-forward-url",
+			Desc:  "the URL of the central instance writes are forwarded to in edge mode",
+		},
+		{
+			DestP: &l.edgeConfig.Token,
+			Flag:  "edge-forward-token",
+			Desc:  "the token used to write to the central instance in edge mode",
+		},
+		{
+			DestP: &l.edgeConfig.Org,
+			Flag:  "edge-forward-org",
+			Desc:  "the organization on the central instance whose buckets writes are forwarded to in edge mode. Points are written to the bucket with the same name as the local bucket",
+		},
+		{
+			DestP:   &l.edgeConfig.QueuePath,
+			Flag:    "edge-queue-path",
+			Default: filepath.Join(dir, "edge-queue"),
+			Desc:    "path to the queue of writes waiting to be forwarded in edge mode",
+		},
+		{
+			DestP:   &l.edgeQueueMaxSize,
+			Flag:    "edge-queue-max-size",
+			Default: edge.DefaultMaxQueueSize,
+			Desc:    "the maximum size in bytes of the queue of writes waiting to be forwarded in edge mode. Writes are rejected while the queue is full",
+		},
+		{
+			DestP:   &l.edgeRetention,
+			Flag:    "edge-retention",
+			Default: 24 * time.Hour,
+			Desc:    "the maximum age of data kept locally in edge mode, regardless of the retention period of buckets",
+		},
+		{
+			DestP: &l.federationConfig.Nodes,
+			Flag:  "query-federation-nodes",
+			Desc:  "the addresses of the nodes queries read from in addition to this one. Their results are merged, and points stored on several nodes are only returned once",
+		},
+		{
+			DestP: &l.federationConfig.Token,
+			Flag:  "query-federation-token",
+			Desc:  "the token used to read from the nodes of query-federation-nodes",
+		},
+		{
+			DestP:   &l.storageIOBandwidth,
+			Flag:    "storage-io-bandwidth",
+			Default: 0,
+			Desc:    "the read and write bandwidth in bytes per second shared fairly between organizations when more than one of them uses the storage engine. If this is unset, IO is not throttled",
+		},
+		{
+			DestP:   &l.storageReadOnly,
+			Flag:    "storage-read-only",
+			Default: false,
+			Desc:    "open the storage engine read-only to inspect a damaged data directory. The WAL is not replayed, nothing is compacted, and writes and deletes are rejected",
+		},
+		{
+			DestP:   &l.StorageConfig.Engine.VerifyChecksums,
+			Flag:    "storage-tsm-verify-checksums",
+			Default: tsm1.DefaultVerifyChecksums,
+			Desc:    "verify the checksum of each TSM block read. Blocks which fail verification, for example after bit rot, are quarantined and listed at /api/v2/quarantine, and their reads fail rather than return corrupt data",
+		},
+		{
+			DestP:   &l.storageLastValueMaxSeries,
+			Flag:    "storage-last-value-cache-max-series",
+			Default: lastvalue.DefaultMaxSeries,
+			Desc:    "the maximum number of series whose last values are kept in memory for the buckets with a last value cache. The values of new series are not cached once that many are",
+		},
+		{
+			DestP:   &l.alertHistoryRetention,
+			Flag:    "alert-history-retention",
+			Default: platform.DefaultAlertHistoryRetention,
+			Desc:    "how long the transitions of the statuses written by checks are kept for alert analytics. If this is 0, they are kept forever",
+		},
+		{
+			DestP:   &l.limitAlertsInterval,
+			Flag:    "limit-alerts-interval",
+			Default: limitalert.DefaultInterval,
+			Desc:    "how often the series cardinality, storage and task failure rate of each organization are checked against its budgets. If this is 0, they are not checked",
+		},
+		{
+			DestP:   &l.streamingChecksInterval,
+			Flag:    "streaming-checks-refresh-interval",
+			Default: streamcheck.DefaultRefreshInterval,
+			Desc:    "how often the streaming threshold checks evaluated against written points are reloaded. If this is 0, streaming checks are not evaluated",
+		},
 		{
 			DestP: &l.featureFlags,
 			Flag:  "feature-flags",
 			Desc:  "feature flag overrides",
 		},
+		{
+			DestP:   &l.featureFlagRefreshInterval,
+			Flag:    "feature-flag-refresh-interval",
+			Default: featuresettings.DefaultRefreshInterval,
+			Desc:    "how often the feature flag values set for the instance and for organizations through the API are reloaded",
+		},
 	}
 	cli.BindOptions(cmd, opts)
 	cmd.AddCommand(inspect.NewCommand())
@@ -320,12 +691,18 @@ type Launcher struct {
 	wg      sync.WaitGroup
 	cancel  func()
 	running bool
+	startup *startup
 
 	storeType            string
 	assetsPath           string
+	branding             http.Branding
 	testing              bool
 	sessionLength        int // in minutes
 	sessionRenewDisabled bool
+	passwordPolicy       platform.PasswordPolicy
+	tokenUsageInterval   time.Duration
+
+	resourceActivityInterval time.Duration
 
 	logLevel          string
 	tracingType       string
@@ -338,7 +715,8 @@ type Launcher struct {
 
 	enableNewMetaStore bool
 
-	featureFlags map[string]string
+	featureFlags               map[string]string
+	featureFlagRefreshInterval time.Duration
 
 	// Query options.
 	concurrencyQuota                int
@@ -347,6 +725,81 @@ type Launcher struct {
 	maxMemoryBytes                  int
 	queueSize                       int
 
+	// The memory limit the Go runtime, queries and the storage cache are
+	// sized to, if set or detected.
+	memoryLimitBytes int
+	memoryAutoTune   bool
+
+	// Paged query results.
+	queryResultSpillPath string
+	queryResultTTL       time.Duration
+	queryResultMaxBytes  int
+	queryResultSpill     *http.QueryResultSpill
+
+	// Storage read limits.
+	storageReadMaxSeries int
+	storageReadMaxPoints int
+	storageReadOrgLimits map[string]string
+
+	// Storage IO bandwidth shared between organizations.
+	storageIOBandwidth int
+
+	// Open the storage engine read-only.
+	storageReadOnly bool
+
+	// Last values of the series of buckets with a last value cache.
+	storageLastValueMaxSeries int
+	lastValues                *lastvalue.Cache
+
+	// Sampler of the points written, to infer the schema of their producers.
+	writeSchemaSampler *writesample.Sampler
+
+	// Maximum number of batch IDs remembered to deduplicate writes.
+	httpWriteDedupeMaxBatches int
+
+	httpMaxConnections             int
+	httpMaxConnectionsQueueTimeout time.Duration
+	httpMaxConcurrentStreams       int
+	httpReadHeaderTimeout          time.Duration
+	httpReadTimeout                time.Duration
+	httpWriteTimeout               time.Duration
+	httpIdleTimeout                time.Duration
+	httpTrustedProxies             []string
+	httpAuthenticators             []string
+	httpCrashReportPath            string
+	httpOrgDomain                  string
+
+	// OTLP/gRPC receiver of OpenTelemetry metrics.
+	otlpGRPCBindAddress string
+	otlpGRPCServer      *grpc.Server
+
+	// gRPC API of DBRP mappings.
+	grpcBindAddress string
+	grpcServer      *grpc.Server
+
+	// StatsD listeners aggregate metrics written to buckets.
+	statsdListeners []string
+	statsd          []*statsd.Listener
+
+	// UDP listeners write line protocol to buckets.
+	udpListeners []string
+	udp          []*udp.Listener
+
+	// Cluster mode replicates the metadata store.
+	clusterEnabled bool
+	clusterConfig  cluster.Config
+	clusterStore   *cluster.Store
+
+	// Edge mode forwards writes to a central instance.
+	edgeMode         bool
+	edgeConfig       edge.Config
+	edgeQueueMaxSize int
+	edgeRetention    time.Duration
+	edgeForwarder    *edge.Forwarder
+
+	// Query federation reads from other nodes.
+	federationConfig federation.Config
+
 	boltClient    *bolt.Client
 	kvStore       kv.Store
 	kvService     *kv.Service
@@ -359,6 +812,8 @@ type Launcher struct {
 	httpServer  *nethttp.Server
 	httpTLSCert string
 	httpTLSKey  string
+	// httpTLSClientCA verifies the client certificates of mTLS.
+	httpTLSClientCA string
 
 	natsServer *nats.Server
 	natsPort   int
@@ -368,6 +823,29 @@ type Launcher struct {
 	executor           *executor.Executor
 	taskControlService taskbackend.TaskControlService
 
+	orgDeletionService   *orgdeletion.Service
+	staleResourceService *staleresource.Service
+	jobs                 *jobs.Manager
+
+	bucketSnapshotService *storage.BucketSnapshotService
+	lifecycleRunner       *lifecycle.Runner
+	reportRunner          *reports.Runner
+	bucketFamilyService   *bucketfamily.Service
+	fieldTypeService      *storage.FieldTypeConflictService
+	webhookDispatcher     *webhook.Dispatcher
+
+	// Alert history keeps the transitions of check statuses.
+	alertHistoryRetention time.Duration
+	alertHistory          *alerthistory.Retention
+
+	// Limit alerts check the usage of organizations against their budgets.
+	limitAlertsInterval time.Duration
+	limitAlerts         *limitalert.Checker
+
+	// Streaming checks evaluate thresholds against written points.
+	streamingChecksInterval time.Duration
+	streamingChecks         *streamcheck.Evaluator
+
 	jaegerTracerCloser io.Closer
 	log                *zap.Logger
 	reg                *prom.Registry
@@ -398,6 +876,15 @@ func (m *Launcher) Running() bool {
 	return m.running
 }
 
+// StartupReport returns the startup status of each subsystem of the
+// launcher, in the order they start.
+func (m *Launcher) StartupReport() []SubsystemStatus {
+	if m.startup == nil {
+		return nil
+	}
+	return m.startup.Report()
+}
+
 // ReportingDisabled is true if opted out of usage stats.
 func (m *Launcher) ReportingDisabled() bool {
 	return m.reportingDisabled
@@ -431,15 +918,111 @@ func (m *Launcher) Engine() Engine {
 
 // Shutdown shuts down the HTTP server and waits for all services to clean up.
 func (m *Launcher) Shutdown(ctx context.Context) {
+	if m.otlpGRPCServer != nil {
+		m.otlpGRPCServer.GracefulStop()
+	}
+	if m.grpcServer != nil {
+		m.grpcServer.GracefulStop()
+	}
 	m.httpServer.Shutdown(ctx)
 
+	if m.queryResultSpill != nil {
+		m.log.Info("Stopping", zap.String("service", "query-results"))
+		if err := m.queryResultSpill.Close(); err != nil {
+			m.log.Info("Failed removing spilled query results", zap.Error(err))
+		}
+	}
+
+	for _, l := range m.statsd {
+		m.log.Info("Stopping", zap.String("service", "statsd"))
+		if err := l.Close(); err != nil {
+			m.log.Info("Failed closing StatsD listener", zap.Error(err))
+		}
+	}
+
+	for _, l := range m.udp {
+		m.log.Info("Stopping", zap.String("service", "udp"))
+		if err := l.Close(); err != nil {
+			m.log.Info("Failed closing UDP listener", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "task"))
 
 	m.scheduler.Stop()
 
+	m.log.Info("Stopping", zap.String("service", "org-deletion"))
+	if err := m.orgDeletionService.Close(); err != nil {
+		m.log.Info("Failed closing organization deletion service", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "stale-resource"))
+	if err := m.staleResourceService.Close(); err != nil {
+		m.log.Info("Failed closing stale resource service", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "lifecycle"))
+	if err := m.lifecycleRunner.Close(); err != nil {
+		m.log.Info("Failed closing lifecycle runner", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "reports"))
+	if err := m.reportRunner.Close(); err != nil {
+		m.log.Info("Failed closing report runner", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "bucket-family"))
+	if err := m.bucketFamilyService.Close(); err != nil {
+		m.log.Info("Failed closing bucket family service", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "limit-alerts"))
+	if err := m.limitAlerts.Close(); err != nil {
+		m.log.Info("Failed closing limit alerts", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "streaming-checks"))
+	if err := m.streamingChecks.Close(); err != nil {
+		m.log.Info("Failed closing streaming checks", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "alert-history"))
+	if err := m.alertHistory.Close(); err != nil {
+		m.log.Info("Failed closing alert history retention", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "webhook"))
+	if err := m.webhookDispatcher.Close(); err != nil {
+		m.log.Info("Failed closing webhook dispatcher", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "bucket-snapshot"))
+	if err := m.bucketSnapshotService.Close(); err != nil {
+		m.log.Info("Failed closing bucket snapshot service", zap.Error(err))
+	}
+
+	m.log.Info("Stopping", zap.String("service", "field-type"))
+	if err := m.fieldTypeService.Close(); err != nil {
+		m.log.Info("Failed closing field type service", zap.Error(err))
+	}
+
+	// Jobs are canceled once the services running them are closed, so that
+	// they are stopped as the service would have stopped them.
+	m.log.Info("Stopping", zap.String("service", "jobs"))
+	if err := m.jobs.Close(); err != nil {
+		m.log.Info("Failed closing jobs", zap.Error(err))
+	}
+
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
 
+	if m.clusterStore != nil {
+		m.log.Info("Stopping", zap.String("service", "cluster"))
+		if err := m.clusterStore.Close(); err != nil {
+			m.log.Info("Failed closing cluster", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "bolt"))
 	if err := m.boltClient.Close(); err != nil {
 		m.log.Info("Failed closing bolt", zap.Error(err))
@@ -450,6 +1033,13 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 		m.log.Info("Failed closing query service", zap.Error(err))
 	}
 
+	if m.edgeForwarder != nil {
+		m.log.Info("Stopping", zap.String("service", "edge-forwarder"))
+		if err := m.edgeForwarder.Close(); err != nil {
+			m.log.Info("Failed closing edge forwarder", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "storage-engine"))
 	if err := m.engine.Close(); err != nil {
 		m.log.Error("Failed to close engine", zap.Error(err))
@@ -514,6 +1104,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		zap.String("build_date", info.Date),
 	)
 
+	m.tuneMemory()
+
 	switch m.tracingType {
 	case LogTracing:
 		m.log.Info("Tracing via zap logging")
@@ -536,14 +1128,56 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.jaegerTracerCloser = closer
 	}
 
+	// Subsystems start in the order they are declared, after the subsystems
+	// they depend on. The listeners accepting traffic start last, so that
+	// nothing is served by a partially started launcher.
+	m.startup = newStartup(m.log)
+	m.startup.declare("bolt")
+	m.startup.declare("cluster", "bolt")
+	m.startup.declare("kv", "bolt", "cluster")
+	m.startup.declare("storage", "kv")
+	m.startup.declare("edge-forwarder", "storage")
+	m.startup.declare("alert-history", "kv")
+	m.startup.declare("query", "storage")
+	m.startup.declare("webhook", "kv")
+	m.startup.declare("limit-alerts", "kv", "storage", "alert-history")
+	m.startup.declare("streaming-checks", "kv", "storage", "alert-history")
+	m.startup.declare("scheduler", "kv", "query", "webhook")
+	m.startup.declare("org-deletion", "storage", "scheduler")
+	m.startup.declare("bucket-snapshot", "storage")
+	m.startup.declare("lifecycle", "storage", "query")
+	m.startup.declare("reports", "storage", "query")
+	m.startup.declare("bucket-family", "storage")
+	m.startup.declare("nats")
+	m.startup.declare("scraper", "nats", "storage")
+	m.startup.declare("listeners", "kv", "storage", "edge-forwarder", "alert-history", "query", "webhook", "limit-alerts", "streaming-checks", "scheduler", "org-deletion", "bucket-snapshot", "lifecycle", "reports", "bucket-family", "scraper")
+
 	m.boltClient = bolt.NewClient(m.log.With(zap.String("service", "bolt")))
 	m.boltClient.Path = m.boltPath
 
-	if err := m.boltClient.Open(ctx); err != nil {
+	if err := m.startup.start("bolt", func() error {
+		return m.boltClient.Open(ctx)
+	}); err != nil {
 		m.log.Error("Failed opening bolt", zap.Error(err))
 		return err
 	}
 
+	if err := m.passwordPolicy.Valid(); err != nil {
+		m.log.Error("Invalid password policy", zap.Error(err))
+		return err
+	}
+
+	if err := m.branding.Valid(); err != nil {
+		m.log.Error("Invalid UI branding", zap.Error(err))
+		return err
+	}
+
+	trustedProxies, err := kithttp.ParseTrustedProxies(m.httpTrustedProxies)
+	if err != nil {
+		m.log.Error("Invalid trusted proxies", zap.Error(err))
+		return err
+	}
+
 	serviceConfig := kv.ServiceConfig{
 		SessionLength: time.Duration(m.sessionLength) * time.Minute,
 	}
@@ -554,11 +1188,26 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		store := bolt.NewKVStore(m.log.With(zap.String("service", "kvstore-bolt")), m.boltPath)
 		store.WithDB(m.boltClient.DB())
 		m.kvStore = store
-		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
+		if m.clusterEnabled {
+			m.clusterStore = cluster.NewStore(m.log.With(zap.String("service", "cluster")), m.clusterConfig, store, m.boltClient.DB())
+			if err := m.startup.start("cluster", func() error {
+				return m.clusterStore.Open(ctx)
+			}); err != nil {
+				m.log.Error("Failed to open cluster", zap.Error(err))
+				return err
+			}
+			m.kvStore = m.clusterStore
+		}
+		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), m.kvStore, serviceConfig)
 		if m.testing {
 			flushers = append(flushers, store)
 		}
 	case MemoryStore:
+		if m.clusterEnabled {
+			err := errors.New("cluster mode requires the bolt store")
+			m.log.Error("Failed opening cluster", zap.Error(err))
+			return err
+		}
 		store := inmem.NewKVStore()
 		m.kvStore = store
 		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
@@ -571,7 +1220,12 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
-	if err := m.kvService.Initialize(ctx); err != nil {
+	if !m.clusterEnabled {
+		m.startup.skip("cluster")
+	}
+	if err := m.startup.start("kv", func() error {
+		return m.kvService.Initialize(ctx)
+	}); err != nil {
 		m.log.Error("Failed to initialize kv service", zap.Error(err))
 		return err
 	}
@@ -613,6 +1267,22 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	dbrpSvc, err := dbrp.NewService(m.kvStore,
+		dbrp.WithBucketService(m.kvService),
+		dbrp.WithLogger(m.log.With(zap.String("service", "dbrp"))),
+	)
+	if err != nil {
+		m.log.Error("Failed creating DBRP mapping store", zap.Error(err))
+		return err
+	}
+	// The mappings to a bucket are deleted along with it, by whichever of
+	// the bucket services is wired below.
+	m.kvService.AddBucketDeleteHook(dbrpSvc.DeleteBucketMappings)
+	store.AddBucketDeleteHook(dbrpSvc.DeleteBucketMappings)
+	dbrpMappingSvc := dbrp.NewTracingService(
+		dbrp.NewLoggingService(m.log.With(zap.String("service", "dbrp")), dbrpSvc),
+	)
+
 	if m.enableNewMetaStore {
 		ts := tenant.NewService(store)
 		userSvc = tenant.NewUserLogger(m.log.With(zap.String("store", "new")), tenant.NewUserMetrics(m.reg, ts, tenant.WithSuffix("new")))
@@ -622,6 +1292,10 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		passwdsSvc = tenant.NewPasswordLogger(m.log.With(zap.String("store", "new")), tenant.NewPasswordMetrics(m.reg, ts, tenant.WithSuffix("new")))
 	}
 
+	// The password policy applies to the passwords of either store.
+	passwordPolicySvc := kv.NewPasswordPolicyService(m.kvService, passwdsSvc, m.passwordPolicy)
+	passwdsSvc = passwordPolicySvc
+
 	switch m.secretStore {
 	case "bolt":
 		// If it is bolt, then we already set it above.
@@ -646,32 +1320,148 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	var maxRetention time.Duration
+	if m.edgeMode {
+		if m.edgeConfig.URL == "" || m.edgeConfig.Org == "" {
+			err := errors.New("edge mode requires --edge-forward-url and --edge-forward-org")
+			m.log.Error("Failed configuring edge mode", zap.Error(err))
+			return err
+		}
+		maxRetention = m.edgeRetention
+	}
+
 	if m.testing {
 		// the testing engine will write/read into a temporary directory
-		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		engine := NewTemporaryEngine(m.StorageConfig, storage.WithDuplicatePolicies(bucketSvc), storage.WithCompressionCodecs(bucketSvc), storage.WithWriteWindows(bucketSvc), storage.WithIOBandwidth(int64(m.storageIOBandwidth)), storage.WithMaxRetention(maxRetention), storage.WithLegalHolds(m.kvService), storage.WithRetentionEnforcer(bucketSvc))
 		flushers = append(flushers, engine)
 		m.engine = engine
 	} else {
-		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		opts := []storage.Option{storage.WithDuplicatePolicies(bucketSvc), storage.WithCompressionCodecs(bucketSvc), storage.WithWriteWindows(bucketSvc), storage.WithIOBandwidth(int64(m.storageIOBandwidth)), storage.WithMaxRetention(maxRetention), storage.WithLegalHolds(m.kvService), storage.WithRetentionEnforcer(bucketSvc)}
+		if m.storageReadOnly {
+			opts = append(opts, storage.WithReadOnly())
+		}
+		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, opts...)
 	}
 	m.engine.WithLogger(m.log)
-	if err := m.engine.Open(ctx); err != nil {
+	if err := m.startup.start("storage", func() error {
+		return m.engine.Open(ctx)
+	}); err != nil {
 		m.log.Error("Failed to open engine", zap.Error(err))
 		return err
 	}
 	// The Engine's metrics must be registered after it opens.
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
+	// The last values of the series of buckets with a last value cache are
+	// kept as they are written to the engine.
+	m.lastValues = lastvalue.NewCache(m.log.With(zap.String("service", "last-value-cache")), bucketSvc, m.storageLastValueMaxSeries)
+	m.reg.MustRegister(m.lastValues.PrometheusCollectors()...)
+	lastValueWriter := lastvalue.NewPointsWriter(m.engine, m.lastValues)
+
+	// The streaming checks are evaluated against the points written to the
+	// engine. Their statuses are written without being evaluated again.
+	m.streamingChecks = streamcheck.NewEvaluator(
+		m.log.With(zap.String("service", "streaming-checks")),
+		orgSvc,
+		m.kvService,
+		m.kvService,
+		bucketSvc,
+		alerthistory.NewPointsWriter(m.log.With(zap.String("service", "alert-history")), lastValueWriter, m.kvService),
+		m.streamingChecksInterval,
+	)
+	engineWriter := streamcheck.NewPointsWriter(lastValueWriter, m.streamingChecks)
+
 	var (
 		deleteService platform.DeleteService = m.engine
-		pointsWriter  storage.PointsWriter   = m.engine
+		pointsWriter  storage.PointsWriter   = engineWriter
 		backupService platform.BackupService = m.engine
 	)
 
+	if m.edgeMode {
+		m.edgeConfig.MaxQueueSize = int64(m.edgeQueueMaxSize)
+		m.edgeForwarder = edge.NewForwarder(m.log.With(zap.String("service", "edge-forwarder")), m.edgeConfig, engineWriter, bucketSvc)
+		if err := m.startup.start("edge-forwarder", func() error {
+			return m.edgeForwarder.Open(ctx)
+		}); err != nil {
+			m.log.Error("Failed to open edge forwarder", zap.Error(err))
+			return err
+		}
+		m.reg.MustRegister(m.edgeForwarder.PrometheusCollectors()...)
+		pointsWriter = m.edgeForwarder
+	} else {
+		m.startup.skip("edge-forwarder")
+	}
+
+	// The points written by clients are sampled while the schema written to
+	// their bucket is being sampled.
+	m.writeSchemaSampler = writesample.NewSampler(m.log.With(zap.String("service", "write-schema-sample")))
+	pointsWriter = writesample.NewPointsWriter(pointsWriter, m.writeSchemaSampler)
+
+	// The StatsD and UDP listeners are opened along with the HTTP listener, once all
+	// of the subsystems have started.
+	statsdConfigs := make([]statsd.Config, 0, len(m.statsdListeners))
+	for _, s := range m.statsdListeners {
+		config, err := statsd.ParseConfig(s)
+		if err != nil {
+			m.log.Error("Failed to parse StatsD listener", zap.Error(err))
+			return err
+		}
+		statsdConfigs = append(statsdConfigs, config)
+	}
+
+	udpConfigs := make([]udp.Config, 0, len(m.udpListeners))
+	for _, s := range m.udpListeners {
+		config, err := udp.ParseConfig(s)
+		if err != nil {
+			m.log.Error("Failed to parse UDP listener", zap.Error(err))
+			return err
+		}
+		udpConfigs = append(udpConfigs, config)
+	}
+
+	readLimits, err := readservice.ParseOrgLimits(m.storageReadOrgLimits)
+	if err != nil {
+		m.log.Error("Failed to parse storage read limits", zap.Error(err))
+		return err
+	}
+	readLimitsFn := readservice.NewLimitsFunc(readservice.Limits{
+		MaxSeries: int64(m.storageReadMaxSeries),
+		MaxPoints: int64(m.storageReadMaxPoints),
+	}, readLimits)
+
+	var reader influxdb.Reader = storageflux.NewReader(
+		readservice.NewStore(m.engine, readservice.WithLimits(readLimitsFn)),
+		storageflux.WithSnapshots(func(ctx context.Context, orgID, bucketID platform.ID, name string) (reads.Store, error) {
+			v, err := m.bucketSnapshotService.SnapshotViewer(ctx, orgID, bucketID, name)
+			if err != nil {
+				return nil, err
+			}
+			return readservice.NewStore(v, readservice.WithLimits(readLimitsFn)), nil
+		}),
+	)
+	if len(m.federationConfig.Nodes) > 0 {
+		reader = federation.NewReader(m.log.With(zap.String("service", "query-federation")), m.federationConfig, reader)
+	}
+	// Points older than the soft TTLs of their bucket are hidden from
+	// queries, but kept in storage.
+	reader = influxdb.NewSoftTTLReader(reader, bucketSvc)
+	// Values matched by the masking rules of their bucket are masked for
+	// tokens without the unmasked permission of the bucket.
+	reader = influxdb.NewMaskingReader(reader, bucketSvc)
+
+	m.alertHistory = alerthistory.NewRetention(m.log.With(zap.String("service", "alert-history")), m.kvService, m.alertHistoryRetention)
+	if err := m.startup.start("alert-history", func() error {
+		return m.alertHistory.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to open alert history retention", zap.Error(err))
+		return err
+	}
+
+	queryBucketSvc := authorizer.NewBucketService(bucketSvc, userResourceSvc)
 	deps, err := influxdb.NewDependencies(
-		storageflux.NewReader(readservice.NewStore(m.engine)),
-		m.engine,
-		authorizer.NewBucketService(bucketSvc, userResourceSvc),
+		reader,
+		alerthistory.NewPointsWriter(m.log.With(zap.String("service", "alert-history")), engineWriter, m.kvService),
+		queryBucketSvc,
 		authorizer.NewOrgService(orgSvc),
 		authorizer.NewSecretService(secretSvc),
 		nil,
@@ -680,24 +1470,74 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.log.Error("Failed to get query controller dependencies", zap.Error(err))
 		return err
 	}
-
-	m.queryController, err = control.New(control.Config{
-		ConcurrencyQuota:                m.concurrencyQuota,
-		InitialMemoryBytesQuotaPerQuery: int64(m.initialMemoryBytesQuotaPerQuery),
-		MemoryBytesQuotaPerQuery:        int64(m.memoryBytesQuotaPerQuery),
-		MaxMemoryBytes:                  int64(m.maxMemoryBytes),
-		QueueSize:                       m.queueSize,
-		Logger:                          m.log.With(zap.String("service", "storage-reads")),
-		ExecutorDependencies:            []flux.Dependency{deps},
-	})
-	if err != nil {
+	// Reads of a bucket family read each of its members. Writes with to()
+	// name buckets, not families.
+	deps.StorageDeps.FromDeps.BucketLookup = bucketfamily.NewBucketLookup(deps.StorageDeps.FromDeps.BucketLookup, authorizer.NewBucketFamilyService(m.kvService))
+	deps.StorageDeps.FromDeps.Reader = bucketfamily.NewReader(deps.StorageDeps.FromDeps.Reader, m.kvService, queryBucketSvc)
+
+	// The counters of the write and query requests, read by the
+	// internalStats() source so that ops dashboards work without scraping.
+	stats := internalstats.New()
+
+	if err := m.startup.start("query", func() (err error) {
+		m.queryController, err = control.New(control.Config{
+			ConcurrencyQuota:                m.concurrencyQuota,
+			InitialMemoryBytesQuotaPerQuery: int64(m.initialMemoryBytesQuotaPerQuery),
+			MemoryBytesQuotaPerQuery:        int64(m.memoryBytesQuotaPerQuery),
+			MaxMemoryBytes:                  int64(m.maxMemoryBytes),
+			QueueSize:                       m.queueSize,
+			Logger:                          m.log.With(zap.String("service", "storage-reads")),
+			ExecutorDependencies:            []flux.Dependency{deps, fluxhttp.NewSinkDependencies(m.kvService, m.kvService), fluxemail.NewDependencies(m.kvService, m.kvService), fluxslackapp.NewDependencies(m.kvService), influxdb.InternalStatsDependencies{Stats: stats}, influxdbv1.DatabasesDependencies{DBRP: dbrpMappingSvc, BucketLookup: queryBucketSvc}},
+		})
+		return err
+	}); err != nil {
 		m.log.Error("Failed to create query controller", zap.Error(err))
 		return err
 	}
 
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
-	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
+	// Queries and tasks import the Flux fragments of their organization,
+	// which are expanded before the queries are compiled.
+	fragmentQueryService := fluxfragment.NewQueryService(m.queryController, m.kvService)
+	var storageQueryService = readservice.NewProxyQueryService(fragmentQueryService)
+
+	m.webhookDispatcher = webhook.NewDispatcher(m.log.With(zap.String("service", "webhook")), m.kvService, m.kvService)
+	// Mappings are changed through the API, the v1 import and the deletes of
+	// their buckets, so their changes are published by the mapping service.
+	dbrpSvc.Subscribe(webhook.NewDBRPEventListener(m.webhookDispatcher))
+	if err := m.startup.start("webhook", func() error {
+		return m.webhookDispatcher.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to open webhook dispatcher", zap.Error(err))
+		return err
+	}
+
+	// The statuses of the checks of the platform limits are recorded in the
+	// alert history as those of other checks.
+	m.limitAlerts = limitalert.NewChecker(
+		m.log.With(zap.String("service", "limit-alerts")),
+		m.kvService,
+		orgSvc,
+		bucketSvc,
+		m.engine,
+		alerthistory.NewPointsWriter(m.log.With(zap.String("service", "alert-history")), engineWriter, m.kvService),
+		m.limitAlertsInterval,
+	)
+	if err := m.startup.start("limit-alerts", func() error {
+		return m.limitAlerts.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to open limit alerts", zap.Error(err))
+		return err
+	}
+
+	if err := m.startup.start("streaming-checks", func() error {
+		return m.streamingChecks.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to open streaming checks", zap.Error(err))
+		return err
+	}
+
 	var taskSvc platform.TaskService
 	{
 		// create the task stack
@@ -705,10 +1545,14 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 		executor, executorMetrics := executor.NewExecutor(
 			m.log.With(zap.String("service", "task-executor")),
-			query.QueryServiceBridge{AsyncQueryService: m.queryController},
+			query.QueryServiceBridge{AsyncQueryService: fragmentQueryService},
 			authSvc,
 			combinedTaskService,
-			combinedTaskService,
+			limitalert.NewTaskControlService(
+				webhook.NewTaskControlService(combinedTaskService, combinedTaskService, m.webhookDispatcher),
+				combinedTaskService,
+				m.limitAlerts,
+			),
 		)
 		m.executor = executor
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
@@ -716,25 +1560,30 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 		var sch stoppingScheduler = &scheduler.NoopScheduler{}
 		if !m.noTasks {
-			var (
-				sm  *scheduler.SchedulerMetrics
-				err error
-			)
-			sch, sm, err = scheduler.NewScheduler(
-				executor,
-				taskbackend.NewSchedulableTaskService(m.kvService),
-				scheduler.WithOnErrorFn(func(ctx context.Context, taskID scheduler.ID, scheduledAt time.Time, err error) {
-					schLogger.Info(
-						"error in scheduler run",
-						zap.String("taskID", platform.ID(taskID).String()),
-						zap.Time("scheduledAt", scheduledAt),
-						zap.Error(err))
-				}),
-			)
-			if err != nil {
-				m.log.Fatal("could not start task scheduler", zap.Error(err))
+			if err := m.startup.start("scheduler", func() error {
+				s, sm, err := scheduler.NewScheduler(
+					executor,
+					taskbackend.NewSchedulableTaskService(m.kvService),
+					scheduler.WithOnErrorFn(func(ctx context.Context, taskID scheduler.ID, scheduledAt time.Time, err error) {
+						schLogger.Info(
+							"error in scheduler run",
+							zap.String("taskID", platform.ID(taskID).String()),
+							zap.Time("scheduledAt", scheduledAt),
+							zap.Error(err))
+					}),
+				)
+				if err != nil {
+					return err
+				}
+				sch = s
+				m.reg.MustRegister(sm.PrometheusCollectors()...)
+				return nil
+			}); err != nil {
+				m.log.Error("Failed to start task scheduler", zap.Error(err))
+				return err
 			}
-			m.reg.MustRegister(sm.PrometheusCollectors()...)
+		} else {
+			m.startup.skip("scheduler")
 		}
 
 		m.scheduler = sch
@@ -761,6 +1610,77 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		}
 	}
 
+	// The bucket service is not wrapped in a storage backed one as the
+	// organization deletion removes shards of system buckets as well.
+	m.jobs = jobs.NewManager(m.log.With(zap.String("service", "jobs")))
+
+	m.orgDeletionService = orgdeletion.NewService(m.log.With(zap.String("service", "org-deletion")), m.kvService, m.engine, orgSvc, bucketSvc, dashboardSvc, authSvc, taskSvc)
+	m.orgDeletionService.Jobs = m.jobs
+	if err := m.startup.start("org-deletion", func() error {
+		return m.orgDeletionService.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to resume organization deletions", zap.Error(err))
+		return err
+	}
+
+	m.staleResourceService = staleresource.NewService(m.log.With(zap.String("service", "stale-resource")), m.kvService, m.kvService, bucketSvc, dashboardSvc, authSvc, taskSvc)
+	m.staleResourceService.Jobs = m.jobs
+	if err := m.startup.start("stale-resource", func() error {
+		return m.staleResourceService.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to restart stale resource reports", zap.Error(err))
+		return err
+	}
+	var resourceActivitySvc platform.ResourceActivityService
+	if m.resourceActivityInterval > 0 {
+		resourceActivitySvc = staleresource.NewActivityRecorder(m.kvService, m.resourceActivityInterval)
+	}
+
+	// Clones are written straight to the engine, and a clone which fails is
+	// deleted along with the data written to it.
+	storageBucketSvc := storage.NewBucketService(bucketSvc, m.engine)
+	storageBucketSvc.Jobs = m.jobs
+	storageBucketSvc.LegalHolds = m.kvService
+	m.bucketSnapshotService = storage.NewBucketSnapshotService(m.log.With(zap.String("service", "bucket-snapshot")), m.engine, m.engine, storageBucketSvc)
+	m.bucketSnapshotService.Jobs = m.jobs
+	if err := m.startup.start("bucket-snapshot", func() error {
+		return m.bucketSnapshotService.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to open bucket snapshot service", zap.Error(err))
+		return err
+	}
+
+	m.fieldTypeService = storage.NewFieldTypeConflictService(m.log.With(zap.String("service", "field-type")), m.engine)
+	m.fieldTypeService.Jobs = m.jobs
+
+	m.lifecycleRunner = lifecycle.NewRunner(m.log.With(zap.String("service", "lifecycle")), m.kvService, bucketSvc, deleteService, query.QueryServiceBridge{AsyncQueryService: m.queryController})
+	if err := m.startup.start("lifecycle", func() error {
+		return m.lifecycleRunner.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to open lifecycle runner", zap.Error(err))
+		return err
+	}
+
+	m.reportRunner = reports.NewRunner(m.log.With(zap.String("service", "reports")), m.kvService, dashboardSvc, authSvc, storageQueryService, map[string]reports.Delivery{
+		platform.ReportDeliveryEmail:   reports.NewEmailDelivery(m.kvService, m.kvService),
+		platform.ReportDeliveryWebhook: reports.NewWebhookDelivery(),
+		platform.ReportDeliveryS3:      reports.NewS3Delivery(),
+	})
+	if err := m.startup.start("reports", func() error {
+		return m.reportRunner.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to open report runner", zap.Error(err))
+		return err
+	}
+
+	m.bucketFamilyService = bucketfamily.NewService(m.log.With(zap.String("service", "bucket-family")), m.kvService, storageBucketSvc)
+	if err := m.startup.start("bucket-family", func() error {
+		return m.bucketFamilyService.Open(ctx)
+	}); err != nil {
+		m.log.Error("Failed to open bucket family service", zap.Error(err))
+		return err
+	}
+
 	var checkSvc platform.CheckService
 	{
 		coordinator := coordinator.NewCoordinator(m.log, m.scheduler, m.executor)
@@ -812,27 +1732,31 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	m.natsServer = nats.NewServer(&natsOpts)
 	m.natsPort = natsOpts.Port
 
-	if err := m.natsServer.Open(); err != nil {
-		m.log.Error("Failed to start nats streaming server", zap.Error(err))
-		return err
-	}
-
 	publisher := nats.NewAsyncPublisher(m.log, fmt.Sprintf("nats-publisher-%d", m.natsPort), m.NatsURL())
-	if err := publisher.Open(); err != nil {
-		m.log.Error("Failed to connect to streaming server", zap.Error(err))
-		return err
-	}
-
 	// TODO(jm): this is an example of using a subscriber to consume from the channel. It should be removed.
 	subscriber := nats.NewQueueSubscriber(fmt.Sprintf("nats-subscriber-%d", m.natsPort), m.NatsURL())
-	if err := subscriber.Open(); err != nil {
-		m.log.Error("Failed to connect to streaming server", zap.Error(err))
+	if err := m.startup.start("nats", func() error {
+		if err := m.natsServer.Open(); err != nil {
+			return fmt.Errorf("failed to start nats streaming server: %v", err)
+		}
+		if err := publisher.Open(); err != nil {
+			return fmt.Errorf("failed to connect to streaming server: %v", err)
+		}
+		if err := subscriber.Open(); err != nil {
+			return fmt.Errorf("failed to connect to streaming server: %v", err)
+		}
+		return nil
+	}); err != nil {
+		m.log.Error("Failed to start nats", zap.Error(err))
 		return err
 	}
 
-	subscriber.Subscribe(gather.MetricsSubject, "metrics", gather.NewRecorderHandler(m.log, gather.PointWriter{Writer: pointsWriter}))
-	scraperScheduler, err := gather.NewScheduler(m.log, 10, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second)
-	if err != nil {
+	var scraperScheduler *gather.Scheduler
+	if err := m.startup.start("scraper", func() (err error) {
+		subscriber.Subscribe(gather.MetricsSubject, "metrics", gather.NewRecorderHandler(m.log, gather.PointWriter{Writer: pointsWriter}))
+		scraperScheduler, err = gather.NewScheduler(m.log, 10, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second)
+		return err
+	}); err != nil {
 		m.log.Error("Failed to create scraper subscriber", zap.Error(err))
 		return err
 	}
@@ -848,7 +1772,11 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}(m.log)
 
 	m.httpServer = &nethttp.Server{
-		Addr: m.httpBindAddress,
+		Addr:              m.httpBindAddress,
+		ReadHeaderTimeout: m.httpReadHeaderTimeout,
+		ReadTimeout:       m.httpReadTimeout,
+		WriteTimeout:      m.httpWriteTimeout,
+		IdleTimeout:       m.httpIdleTimeout,
 	}
 
 	flagger := feature.DefaultFlagger()
@@ -863,34 +1791,97 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		flagger = f
 	}
 
+	// Values set through the API take precedence over the overrides, which
+	// are the defaults of the instance until a value is set.
+	featureFlagService := featuresettings.NewService(m.kvService, flagger, m.featureFlagRefreshInterval)
+	flagger = featureFlagService
+
+	var writeDeduplicator *http.WriteDeduplicator
+	if m.httpWriteDedupeMaxBatches > 0 {
+		writeDeduplicator = http.NewWriteDeduplicator(m.httpWriteDedupeMaxBatches)
+	}
+
+	if m.queryResultSpillPath != "" {
+		spill, err := http.NewQueryResultSpill(m.queryResultSpillPath)
+		if err != nil {
+			m.log.Error("Failed to create query result spill", zap.Error(err))
+			return err
+		}
+		spill.TTL = m.queryResultTTL
+		spill.MaxBytes = int64(m.queryResultMaxBytes)
+		m.queryResultSpill = spill
+	}
+
 	m.apibackend = &http.APIBackend{
-		AssetsPath:           m.assetsPath,
-		HTTPErrorHandler:     kithttp.ErrorHandler(0),
-		Logger:               m.log,
-		SessionRenewDisabled: m.sessionRenewDisabled,
-		NewBucketService:     source.NewBucketService,
-		NewQueryService:      source.NewQueryService,
-		PointsWriter:         pointsWriter,
-		DeleteService:        deleteService,
-		BackupService:        backupService,
-		KVBackupService:      m.kvService,
-		AuthorizationService: authSvc,
-		AlgoWProxy:           &http.NoopProxyHandler{},
+		AssetsPath:             m.assetsPath,
+		Branding:               m.branding,
+		HTTPErrorHandler:       kithttp.ErrorHandler(0),
+		Logger:                 m.log,
+		SessionRenewDisabled:   m.sessionRenewDisabled,
+		NewBucketService:       source.NewBucketService,
+		NewQueryService:        source.NewQueryService,
+		PointsWriter:           pointsWriter,
+		DeleteService:          deleteService,
+		BackupService:          backupService,
+		KVBackupService:        m.kvService,
+		CacheFlushService:      m.engine,
+		BlockQuarantineService: m.engine,
+		BucketSnapshotService:  m.bucketSnapshotService,
+		LifecyclePolicyService: m.kvService,
+		ReportService:          m.kvService,
+		ParquetExportService:   export.NewExporter(m.engine),
+		FeatureFlagService:     featureFlagService,
+		WebhookService:         m.kvService,
+		HTTPSinkService:        m.kvService,
+		SMTPConfigService:      m.kvService,
+		SlackThreadService:     m.kvService,
+		ScriptRevisionService:  m.kvService,
+		AlertHistoryService:    m.kvService,
+		AuthorizationService:   webhook.NewAuthorizationService(authSvc, m.webhookDispatcher),
+		AlgoWProxy:             &http.NoopProxyHandler{},
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
-		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
+		BucketService:                   webhook.NewBucketService(storageBucketSvc, m.webhookDispatcher),
 		SessionService:                  sessionSvc,
+		AuthorizationUsageService:       m.kvService,
+		AuthorizationUsageInterval:      m.tokenUsageInterval,
 		UserService:                     userSvc,
 		OrganizationService:             orgSvc,
 		UserResourceMappingService:      userResourceSvc,
 		LabelService:                    labelSvc,
 		DashboardService:                dashboardSvc,
 		DashboardOperationLogService:    dashboardLogSvc,
+		DashboardCellRenderer:           cellrender.NewRenderer(),
 		BucketOperationLogService:       bucketLogSvc,
 		UserOperationLogService:         userLogSvc,
 		OrganizationOperationLogService: orgLogSvc,
 		SourceService:                   sourceSvc,
 		VariableService:                 variableSvc,
 		PasswordsService:                passwdsSvc,
+		PasswordLockoutService:          passwordPolicySvc,
+		UserPreferencesService:          m.kvService,
+		ResourceGrantService:            m.kvService,
+		RoleService:                     m.kvService,
+		SignedQueryService:              m.kvService,
+		QueryResultSpill:                m.queryResultSpill,
+		OrgDeletionService:              m.orgDeletionService,
+		JobService:                      m.jobs,
+		StaleResourceReportService:      m.staleResourceService,
+		ResourceActivityService:         resourceActivitySvc,
+		SystemInfoService:               systeminfo.NewService(m.log.With(zap.String("service", "system_info"))),
+		BucketFamilyService:             m.kvService,
+		BucketFamilyRouter:              m.bucketFamilyService,
+		LimitAlertsService:              m.kvService,
+		FluxFragmentService:             m.kvService,
+		SeriesCardinalityService:        m.engine,
+		BucketMetadataService:           m.engine,
+		LastValueService:                m.lastValues,
+		DBRPImportService:               dbrp.NewImportService(bucketSvc, dbrpMappingSvc),
+		DBRPMappingService:              dbrpMappingSvc,
+		LegalHoldService:                m.kvService,
+		OrgDomainService:                m.kvService,
+		QueryPolicyService:              m.kvService,
+		FieldTypeConflictService:        m.fieldTypeService,
+		WriteSchemaSampleService:        m.writeSchemaSampler,
 		InfluxQLService:                 storageQueryService,
 		FluxService:                     storageQueryService,
 		TaskService:                     taskSvc,
@@ -904,12 +1895,27 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		LookupService:                   lookupSvc,
 		DocumentService:                 m.kvService,
 		OrgLookupService:                m.kvService,
-		WriteEventRecorder:              infprom.NewEventRecorder("write"),
-		QueryEventRecorder:              infprom.NewEventRecorder("query"),
+		WriteEventRecorder:              metric.EventRecorders{infprom.NewEventRecorder("write"), stats.Recorder(internalstats.KindWrite)},
+		WriteDeduplicator:               writeDeduplicator,
+		QueryEventRecorder:              metric.EventRecorders{infprom.NewEventRecorder("query"), stats.Recorder(internalstats.KindQuery)},
 		Flagger:                         flagger,
 		FlagsHandler:                    feature.NewFlagsHandler(kithttp.ErrorHandler(0), feature.ByKey),
 	}
 
+	for _, name := range m.httpAuthenticators {
+		if name == http.AuthenticatorMTLS && m.httpTLSClientCA == "" {
+			err := errors.New("the mtls authenticator requires tls-client-ca")
+			m.log.Error("Invalid HTTP authenticators", zap.Error(err))
+			return err
+		}
+	}
+	authenticators, err := http.NewAuthenticators(m.apibackend, m.httpAuthenticators...)
+	if err != nil {
+		m.log.Error("Invalid HTTP authenticators", zap.Error(err))
+		return err
+	}
+	m.apibackend.Authenticators = authenticators
+
 	m.reg.MustRegister(m.apibackend.PrometheusCollectors()...)
 
 	authAgent := new(authorizer.AuthAgent)
@@ -958,7 +1964,14 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	{
-		platformHandler := http.NewPlatformHandler(m.apibackend, http.WithResourceHandler(pkgHTTPServer), http.WithResourceHandler(onboardHTTPServer))
+		opts := []http.APIHandlerOptFn{
+			http.WithResourceHandler(pkgHTTPServer),
+			http.WithResourceHandler(onboardHTTPServer),
+		}
+		if m.clusterStore != nil {
+			opts = append(opts, http.WithResourceHandler(cluster.NewHandler(m.log.With(zap.String("handler", "cluster")), m.clusterStore)))
+		}
+		platformHandler := http.NewPlatformHandler(m.apibackend, opts...)
 
 		httpLogger := m.log.With(zap.String("service", "http"))
 		m.httpServer.Handler = http.NewHandlerFromRegistry(
@@ -966,43 +1979,135 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			m.reg,
 			http.WithLog(httpLogger),
 			http.WithAPIHandler(platformHandler),
+			http.WithReadyHandler(m.startup.ReadyHandler()),
+			http.WithCrashReporter(http.NewCrashReporter(httpLogger, m.httpCrashReportPath)),
 		)
 
+		// Requests are scoped to the organization of their subdomain before
+		// they are logged.
+		if m.httpOrgDomain != "" {
+			m.httpServer.Handler = http.OrgDomainMW(m.httpOrgDomain, m.kvService, orgSvc, kithttp.ErrorHandler(0))(m.httpServer.Handler)
+		}
 		if logconf.Level == zap.DebugLevel {
 			m.httpServer.Handler = http.LoggingMW(httpLogger)(m.httpServer.Handler)
 		}
+		// The client of a request is identified before it is logged.
+		if len(trustedProxies) > 0 {
+			m.httpServer.Handler = kithttp.RealIP(trustedProxies)(m.httpServer.Handler)
+		}
 		// If we are in testing mode we allow all data to be flushed and removed.
 		if m.testing {
 			m.httpServer.Handler = http.DebugFlush(ctx, m.httpServer.Handler, flushers)
 		}
 	}
 
-	ln, err := net.Listen("tcp", m.httpBindAddress)
-	if err != nil {
-		m.log.Error("failed http listener", zap.Error(err))
-		m.log.Info("Stopping")
-		return err
-	}
-
-	var cer tls.Certificate
-	transport := "http"
+	// The HTTP, gRPC, OTLP and StatsD listeners are opened together, once all of
+	// the subsystems have started, so that traffic is either served by a
+	// fully started launcher or not accepted at all.
+	var (
+		ln        net.Listener
+		otlpLn    net.Listener
+		grpcLn    net.Listener
+		cer       tls.Certificate
+		transport = "http"
+	)
+	if err := m.startup.start("listeners", func() (err error) {
+		defer func() {
+			if err == nil {
+				return
+			}
+			if ln != nil {
+				ln.Close()
+			}
+			if otlpLn != nil {
+				otlpLn.Close()
+			}
+			if grpcLn != nil {
+				grpcLn.Close()
+			}
+		}()
 
-	if m.httpTLSCert != "" && m.httpTLSKey != "" {
-		var err error
-		cer, err = tls.LoadX509KeyPair(m.httpTLSCert, m.httpTLSKey)
+		if m.httpTLSClientCA != "" && (m.httpTLSCert == "" || m.httpTLSKey == "") {
+			return errors.New("tls-client-ca requires tls-cert and tls-key")
+		}
+		if m.httpTLSCert != "" && m.httpTLSKey != "" {
+			cer, err = tls.LoadX509KeyPair(m.httpTLSCert, m.httpTLSKey)
+			if err != nil {
+				return fmt.Errorf("failed to load x509 key pair: %v", err)
+			}
+			transport = "https"
+
+			m.httpServer.TLSConfig = &tls.Config{}
+			if m.httpTLSClientCA != "" {
+				pem, err := ioutil.ReadFile(m.httpTLSClientCA)
+				if err != nil {
+					return fmt.Errorf("failed to read TLS client CA: %v", err)
+				}
+				pool := x509.NewCertPool()
+				if !pool.AppendCertsFromPEM(pem) {
+					return fmt.Errorf("no certificates found in TLS client CA %s", m.httpTLSClientCA)
+				}
+				m.httpServer.TLSConfig.ClientCAs = pool
+				m.httpServer.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+			if err := http2.ConfigureServer(m.httpServer, &http2.Server{
+				MaxConcurrentStreams: uint32(m.httpMaxConcurrentStreams),
+				IdleTimeout:          m.httpIdleTimeout,
+			}); err != nil {
+				return fmt.Errorf("failed to configure http2: %v", err)
+			}
+		}
 
+		ln, err = net.Listen("tcp", m.httpBindAddress)
 		if err != nil {
-			m.log.Error("failed to load x509 key pair", zap.Error(err))
-			m.log.Info("Stopping")
-			return err
+			return fmt.Errorf("failed http listener: %v", err)
+		}
+		if m.httpMaxConnections > 0 {
+			lln := http.NewLimitListener(ln, m.httpMaxConnections, m.httpMaxConnectionsQueueTimeout)
+			m.reg.MustRegister(lln.PrometheusCollectors()...)
+			ln = lln
+		}
+		if addr, ok := ln.Addr().(*net.TCPAddr); ok {
+			m.httpPort = addr.Port
 		}
-		transport = "https"
 
-		m.httpServer.TLSConfig = &tls.Config{}
-	}
+		if m.otlpGRPCBindAddress != "" {
+			otlpLn, err = net.Listen("tcp", m.otlpGRPCBindAddress)
+			if err != nil {
+				return fmt.Errorf("failed OTLP gRPC listener: %v", err)
+			}
+		}
 
-	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
-		m.httpPort = addr.Port
+		if m.grpcBindAddress != "" {
+			grpcLn, err = net.Listen("tcp", m.grpcBindAddress)
+			if err != nil {
+				return fmt.Errorf("failed gRPC listener: %v", err)
+			}
+		}
+
+		for _, config := range statsdConfigs {
+			l := statsd.NewListener(m.log.With(zap.String("service", "statsd")), config, pointsWriter, bucketSvc)
+			if err := l.Open(ctx); err != nil {
+				return fmt.Errorf("failed to open StatsD listener on %s: %v", config.BindAddress, err)
+			}
+			m.log.Info("Listening", zap.String("transport", "statsd-"+config.Network), zap.String("addr", config.BindAddress))
+			m.statsd = append(m.statsd, l)
+		}
+
+		for _, config := range udpConfigs {
+			l := udp.NewListener(m.log.With(zap.String("service", "udp")), config, pointsWriter, bucketSvc)
+			if err := l.Open(ctx); err != nil {
+				return fmt.Errorf("failed to open UDP listener on %s: %v", config.BindAddress, err)
+			}
+			m.reg.MustRegister(l.PrometheusCollectors()...)
+			m.log.Info("Listening", zap.String("transport", "udp"), zap.String("addr", config.BindAddress))
+			m.udp = append(m.udp, l)
+		}
+		return nil
+	}); err != nil {
+		m.log.Error("Failed to open listeners", zap.Error(err))
+		m.log.Info("Stopping")
+		return err
 	}
 
 	m.wg.Add(1)
@@ -1022,6 +2127,47 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		log.Info("Stopping")
 	}(m.log)
 
+	if otlpLn != nil {
+		var opts []grpc.ServerOption
+		if cer.Certificate != nil {
+			opts = append(opts, grpc.Creds(credentials.NewServerTLSFromCert(&cer)))
+		}
+		m.otlpGRPCServer = grpc.NewServer(opts...)
+		otlp.RegisterMetricsService(m.otlpGRPCServer, m.httpServer.Handler, http.OTLPMetricsPath)
+
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			log.Info("Listening", zap.String("transport", "otlp-grpc"), zap.String("addr", m.otlpGRPCBindAddress))
+
+			if err := m.otlpGRPCServer.Serve(otlpLn); err != nil {
+				log.Error("Failed OTLP gRPC service", zap.Error(err))
+			}
+			log.Info("Stopping")
+		}(m.log)
+	}
+
+	if grpcLn != nil {
+		var opts []grpc.ServerOption
+		if cer.Certificate != nil {
+			opts = append(opts, grpc.Creds(credentials.NewServerTLSFromCert(&cer)))
+		}
+		m.grpcServer = grpc.NewServer(opts...)
+		dbrp.RegisterGRPCService(m.grpcServer, authorizer.NewDBRPMappingService(dbrpMappingSvc), authSvc, userSvc)
+
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			log.Info("Listening", zap.String("transport", "grpc"), zap.String("addr", m.grpcBindAddress))
+
+			if err := m.grpcServer.Serve(grpcLn); err != nil {
+				log.Error("Failed gRPC service", zap.Error(err))
+			}
+			log.Info("Stopping")
+		}(m.log)
+	}
+
+	m.startup.done()
 	return nil
 }
 
@@ -83,6 +83,8 @@ func (tl *TestLauncher) Run(ctx context.Context, args ...string) error {
 	largs := make([]string, 0, len(args)+8)
 	largs = append(largs, "--bolt-path", filepath.Join(tl.Path, bolt.DefaultFilename))
 	largs = append(largs, "--engine-path", filepath.Join(tl.Path, "engine"))
+	largs = append(largs, "--query-result-spill-path", filepath.Join(tl.Path, "query-results"))
+	largs = append(largs, "--http-crash-report-path", filepath.Join(tl.Path, "crash-reports"))
 	largs = append(largs, "--http-bind-address", "127.0.0.1:0")
 	largs = append(largs, "--log-level", "debug")
 	largs = append(largs, args...)
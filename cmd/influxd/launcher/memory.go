@@ -0,0 +1,94 @@
+package launcher
+
+import (
+	"math"
+	"os"
+
+	"github.com/influxdata/influxdb/v2/pkg/cgroup"
+	"github.com/influxdata/influxdb/v2/toml"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+// The tenths of the memory limit of influxd given to the Go runtime, to
+// queries and to the cache of the storage engine.
+const (
+	goMemoryLimitTenths = 9
+	queryMemoryTenths   = 4
+	cacheMemoryTenths   = 2
+)
+
+// memoryBudget is how the memory limit of influxd is shared.
+type memoryBudget struct {
+	// goMemoryLimit is the soft memory limit of the Go runtime, leaving
+	// room for memory it does not manage, such as mmapped TSM files.
+	goMemoryLimit int64
+	// queryMemory is the memory shared by the queries.
+	queryMemory int64
+	// cacheMemory is the memory of the cache of the storage engine.
+	cacheMemory int64
+}
+
+// newMemoryBudget returns the budget of the memory limit. The cache can
+// always hold a snapshot, or writes would be rejected before the cache is
+// ever written to disk.
+func newMemoryBudget(limit int64) memoryBudget {
+	b := memoryBudget{
+		goMemoryLimit: limit / 10 * goMemoryLimitTenths,
+		queryMemory:   limit / 10 * queryMemoryTenths,
+		cacheMemory:   limit / 10 * cacheMemoryTenths,
+	}
+	if min := 2 * int64(tsm1.DefaultCacheSnapshotMemorySize); b.cacheMemory < min {
+		b.cacheMemory = min
+	}
+	return b
+}
+
+// tuneMemory sizes the Go memory limit, the memory of queries and the cache
+// of the storage engine to the memory limit of influxd, or of its cgroup if
+// it is unset, so that a container running influxd with the default
+// settings is not killed for running out of memory. Settings given
+// explicitly, and GOMEMLIMIT, are kept.
+func (m *Launcher) tuneMemory() {
+	if !m.memoryAutoTune {
+		return
+	}
+
+	limit, source := int64(m.memoryLimitBytes), "memory-limit-bytes"
+	if limit <= 0 {
+		l, err := cgroup.Detect()
+		if err != nil {
+			m.log.Warn("Failed to detect the memory limit of the cgroup", zap.Error(err))
+			return
+		}
+		if l == nil || l.MemoryBytes == 0 {
+			return
+		}
+		limit, source = l.MemoryBytes, "cgroup"
+	}
+	b := newMemoryBudget(limit)
+	log := m.log.With(zap.Int64("memory_limit_bytes", limit), zap.String("source", source))
+
+	if os.Getenv("GOMEMLIMIT") != "" {
+		log.Info("Keeping the Go memory limit of GOMEMLIMIT")
+	} else if setMemoryLimit(b.goMemoryLimit) {
+		log.Info("Set the Go memory limit", zap.Int64("go_memory_limit_bytes", b.goMemoryLimit))
+	}
+
+	if m.maxMemoryBytes == 0 && m.memoryBytesQuotaPerQuery == math.MaxInt64 && m.concurrencyQuota > 0 {
+		perQuery := b.queryMemory / int64(m.concurrencyQuota)
+		if int64(m.initialMemoryBytesQuotaPerQuery) <= perQuery {
+			m.maxMemoryBytes = int(b.queryMemory)
+			m.memoryBytesQuotaPerQuery = int(perQuery)
+			log.Info("Set the memory of queries",
+				zap.Int("query_max_memory_bytes", m.maxMemoryBytes),
+				zap.Int("query_memory_bytes", m.memoryBytesQuotaPerQuery))
+		}
+	}
+
+	cache := &m.StorageConfig.Engine.Cache
+	if cache.MaxMemorySize == tsm1.DefaultCacheMaxMemorySize {
+		cache.MaxMemorySize = toml.Size(b.cacheMemory)
+		log.Info("Set the memory of the storage cache", zap.Int64("cache_max_memory_bytes", b.cacheMemory))
+	}
+}
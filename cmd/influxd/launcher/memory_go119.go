@@ -0,0 +1,12 @@
+// +build go1.19
+
+package launcher
+
+import "runtime/debug"
+
+// setMemoryLimit sets the soft memory limit of the Go runtime, and reports
+// whether the runtime has one.
+func setMemoryLimit(limit int64) bool {
+	debug.SetMemoryLimit(limit)
+	return true
+}
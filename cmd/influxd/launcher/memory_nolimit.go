@@ -0,0 +1,10 @@
+// +build !go1.19
+
+package launcher
+
+// setMemoryLimit reports that the Go runtime has no soft memory limit
+// before Go 1.19, so that only the memory of queries and of the storage
+// cache is sized to the memory limit.
+func setMemoryLimit(limit int64) bool {
+	return false
+}
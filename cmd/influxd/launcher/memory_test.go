@@ -0,0 +1,26 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+func TestNewMemoryBudget(t *testing.T) {
+	b := newMemoryBudget(10 << 30)
+	if b.goMemoryLimit != 9<<30 {
+		t.Errorf("got Go memory limit %d, want %d", b.goMemoryLimit, int64(9<<30))
+	}
+	if b.queryMemory != 4<<30 {
+		t.Errorf("got query memory %d, want %d", b.queryMemory, int64(4<<30))
+	}
+	if b.cacheMemory != 2<<30 {
+		t.Errorf("got cache memory %d, want %d", b.cacheMemory, int64(2<<30))
+	}
+
+	// The cache of a small limit still holds a snapshot.
+	b = newMemoryBudget(64 << 20)
+	if min := 2 * int64(tsm1.DefaultCacheSnapshotMemorySize); b.cacheMemory != min {
+		t.Errorf("got cache memory %d, want %d", b.cacheMemory, min)
+	}
+}
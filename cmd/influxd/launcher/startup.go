@@ -0,0 +1,229 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/toml"
+	"go.uber.org/zap"
+)
+
+// Startup statuses of a subsystem.
+const (
+	SubsystemPending = "pending"
+	SubsystemStarted = "started"
+	SubsystemFailed  = "failed"
+	SubsystemSkipped = "skipped"
+)
+
+// SubsystemStatus is the startup status of a subsystem of the launcher.
+type SubsystemStatus struct {
+	Name      string        `json:"name"`
+	DependsOn []string      `json:"dependsOn,omitempty"`
+	Status    string        `json:"status"`
+	Took      toml.Duration `json:"took"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// startup starts the subsystems of the launcher in the order of their
+// dependencies. A subsystem is only started once all of the subsystems it
+// depends on have started or were skipped, so that a failure stops the
+// startup and is reported per subsystem instead of leaving a partially
+// started server.
+type startup struct {
+	log *zap.Logger
+
+	mu         sync.RWMutex
+	began      time.Time
+	subsystems []*SubsystemStatus
+	byName     map[string]*SubsystemStatus
+	ready      bool
+	failed     bool
+}
+
+func newStartup(log *zap.Logger) *startup {
+	return &startup{
+		log:    log,
+		began:  time.Now(),
+		byName: make(map[string]*SubsystemStatus),
+	}
+}
+
+// declare adds the subsystem name, which depends on the subsystems
+// dependsOn, to the startup. Subsystems are declared in the order they
+// start, so the report shows which were never reached after a failure.
+func (s *startup) declare(name string, dependsOn ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := &SubsystemStatus{
+		Name:      name,
+		DependsOn: dependsOn,
+		Status:    SubsystemPending,
+	}
+	s.subsystems = append(s.subsystems, st)
+	s.byName[name] = st
+}
+
+// start starts the subsystem name with fn once its dependencies have
+// started. The startup report is logged if the subsystem fails to start.
+func (s *startup) start(name string, fn func() error) error {
+	s.mu.Lock()
+	st, err := s.lookup(name)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	err = s.dependenciesStarted(st)
+	s.mu.Unlock()
+
+	if err != nil {
+		s.fail(name, err)
+		return err
+	}
+
+	began := time.Now()
+	err = fn()
+	took := time.Since(began)
+
+	s.mu.Lock()
+	st.Took = toml.Duration(took)
+	s.mu.Unlock()
+	if err != nil {
+		s.fail(name, err)
+		return err
+	}
+
+	s.mu.Lock()
+	st.Status = SubsystemStarted
+	s.mu.Unlock()
+	s.log.Debug("Started subsystem", zap.String("subsystem", name), zap.Duration("took", took))
+	return nil
+}
+
+// skip marks the subsystem name as disabled by the configuration. The
+// subsystems which depend on it are started without it.
+func (s *startup) skip(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st, ok := s.byName[name]; ok && st.Status == SubsystemPending {
+		st.Status = SubsystemSkipped
+	}
+}
+
+// done marks the startup as complete, from which point the launcher is
+// ready.
+func (s *startup) done() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ready = true
+	s.log.Info("Started", zap.Duration("took", time.Since(s.began)))
+}
+
+func (s *startup) lookup(name string) (*SubsystemStatus, error) {
+	st, ok := s.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("subsystem %q was not declared", name)
+	}
+	if st.Status != SubsystemPending {
+		return nil, fmt.Errorf("subsystem %q is already %s", name, st.Status)
+	}
+	return st, nil
+}
+
+func (s *startup) dependenciesStarted(st *SubsystemStatus) error {
+	for _, dep := range st.DependsOn {
+		d, ok := s.byName[dep]
+		if !ok {
+			return fmt.Errorf("subsystem %q depends on undeclared subsystem %q", st.Name, dep)
+		}
+		if d.Status != SubsystemStarted && d.Status != SubsystemSkipped {
+			return fmt.Errorf("subsystem %q depends on subsystem %q, which is %s", st.Name, dep, d.Status)
+		}
+	}
+	return nil
+}
+
+func (s *startup) fail(name string, err error) {
+	s.mu.Lock()
+	if st, ok := s.byName[name]; ok {
+		st.Status = SubsystemFailed
+		st.Error = err.Error()
+	}
+	s.failed = true
+	s.mu.Unlock()
+
+	s.log.Error("Failed to start subsystem", zap.String("subsystem", name), zap.Error(err))
+	for _, st := range s.Report() {
+		fields := []zap.Field{
+			zap.String("subsystem", st.Name),
+			zap.String("status", st.Status),
+		}
+		if len(st.DependsOn) > 0 {
+			fields = append(fields, zap.String("depends_on", strings.Join(st.DependsOn, ",")))
+		}
+		if st.Error != "" {
+			fields = append(fields, zap.String("error", st.Error))
+		}
+		s.log.Info("Startup report", fields...)
+	}
+}
+
+// Report returns the startup status of each subsystem, in the order they
+// start.
+func (s *startup) Report() []SubsystemStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report := make([]SubsystemStatus, 0, len(s.subsystems))
+	for _, st := range s.subsystems {
+		report = append(report, *st)
+	}
+	return report
+}
+
+// ReadyHandler is the readiness handler of the launcher. It responds with
+// 503 Service Unavailable until all of the subsystems have started, and
+// includes the startup report.
+func (s *startup) ReadyHandler() nethttp.Handler {
+	fn := func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		s.mu.RLock()
+		ready, failed := s.ready, s.failed
+		s.mu.RUnlock()
+
+		var status = struct {
+			Status     string            `json:"status"`
+			Start      time.Time         `json:"started"`
+			Up         toml.Duration     `json:"up"`
+			Subsystems []SubsystemStatus `json:"subsystems"`
+		}{
+			Status:     "ready",
+			Start:      s.began,
+			Up:         toml.Duration(time.Since(s.began)),
+			Subsystems: s.Report(),
+		}
+
+		code := nethttp.StatusOK
+		switch {
+		case failed:
+			status.Status, code = "failed", nethttp.StatusServiceUnavailable
+		case !ready:
+			status.Status, code = "starting", nethttp.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(status); err != nil {
+			fmt.Fprintf(w, "Error encoding status data: %v\n", err)
+		}
+	}
+	return nethttp.HandlerFunc(fn)
+}
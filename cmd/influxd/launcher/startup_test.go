@@ -0,0 +1,133 @@
+package launcher
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStartup_Start(t *testing.T) {
+	s := newStartup(zaptest.NewLogger(t))
+	s.declare("kv")
+	s.declare("cluster")
+	s.declare("storage", "kv", "cluster")
+	s.declare("scheduler", "storage")
+	s.declare("listeners", "scheduler")
+
+	var started []string
+	start := func(name string, err error) error {
+		return s.start(name, func() error {
+			started = append(started, name)
+			return err
+		})
+	}
+
+	if err := start("storage", nil); err == nil {
+		t.Fatal("expected storage not to start before kv")
+	}
+	if len(started) != 0 {
+		t.Fatalf("expected no subsystem to be started, got %v", started)
+	}
+
+	s = newStartup(zaptest.NewLogger(t))
+	s.declare("kv")
+	s.declare("cluster")
+	s.declare("storage", "kv", "cluster")
+	s.declare("scheduler", "storage")
+	s.declare("listeners", "scheduler")
+
+	if err := start("kv", nil); err != nil {
+		t.Fatal(err)
+	}
+	s.skip("cluster")
+	if err := start("storage", nil); err != nil {
+		t.Fatalf("expected storage to start without the skipped cluster: %v", err)
+	}
+	if err := start("storage", nil); err == nil {
+		t.Error("expected storage not to start twice")
+	}
+	want := errors.New("scheduler failed")
+	if err := start("scheduler", want); err != want {
+		t.Fatalf("got error %v, want %v", err, want)
+	}
+	if err := start("listeners", nil); err == nil {
+		t.Error("expected the listeners not to start after the scheduler failed")
+	}
+	if err := start("unknown", nil); err == nil {
+		t.Error("expected an undeclared subsystem not to start")
+	}
+
+	if got, want := len(started), 3; got != want {
+		t.Errorf("got %d subsystems started, want %d: %v", got, want, started)
+	}
+	statuses := map[string]string{
+		"kv":        SubsystemStarted,
+		"cluster":   SubsystemSkipped,
+		"storage":   SubsystemStarted,
+		"scheduler": SubsystemFailed,
+		"listeners": SubsystemFailed,
+	}
+	report := s.Report()
+	if len(report) != len(statuses) {
+		t.Fatalf("got %d subsystems in the report, want %d", len(report), len(statuses))
+	}
+	for _, st := range report {
+		if st.Status != statuses[st.Name] {
+			t.Errorf("got status %q for %s, want %q", st.Status, st.Name, statuses[st.Name])
+		}
+	}
+	if report[3].Error != "scheduler failed" {
+		t.Errorf("got error %q for the scheduler, want %q", report[3].Error, "scheduler failed")
+	}
+}
+
+func TestStartup_ReadyHandler(t *testing.T) {
+	s := newStartup(zaptest.NewLogger(t))
+	s.declare("kv")
+	s.declare("listeners", "kv")
+	h := s.ReadyHandler()
+
+	ready := func() (int, string, []SubsystemStatus) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+
+		var res struct {
+			Status     string            `json:"status"`
+			Subsystems []SubsystemStatus `json:"subsystems"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, res.Status, res.Subsystems
+	}
+
+	if code, status, _ := ready(); code != http.StatusServiceUnavailable || status != "starting" {
+		t.Errorf("got %d %q while starting, want 503 starting", code, status)
+	}
+
+	for _, name := range []string{"kv", "listeners"} {
+		if err := s.start(name, func() error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.done()
+	code, status, subsystems := ready()
+	if code != http.StatusOK || status != "ready" {
+		t.Errorf("got %d %q once started, want 200 ready", code, status)
+	}
+	if len(subsystems) != 2 || subsystems[1].Name != "listeners" || subsystems[1].Status != SubsystemStarted {
+		t.Errorf("unexpected subsystems %+v", subsystems)
+	}
+
+	s = newStartup(zaptest.NewLogger(t))
+	s.declare("kv")
+	h = s.ReadyHandler()
+	s.start("kv", func() error { return errors.New("migration failed") })
+	if code, status, _ := ready(); code != http.StatusServiceUnavailable || status != "failed" {
+		t.Errorf("got %d %q after a failure, want 503 failed", code, status)
+	}
+}
@@ -0,0 +1,92 @@
+package launcher_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/cmd/influxd/launcher"
+	"github.com/influxdata/influxdb/v2/webhook"
+)
+
+type webhookDelivery struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+func TestLauncher_WebhookDBRPChanged(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	deliveries := make(chan webhookDelivery, 16)
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		deliveries <- webhookDelivery{
+			event:     r.Header.Get(webhook.HeaderEvent),
+			signature: r.Header.Get(webhook.HeaderSignature),
+			body:      body,
+		}
+		w.WriteHeader(nethttp.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	const secret = "hush"
+	do := func(method, path, body string, want int) {
+		t.Helper()
+		req := l.NewHTTPRequestOrFail(t, method, path, l.Auth.Token, body)
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("%s %s: unexpected status %d: %s", method, path, resp.StatusCode, b)
+		}
+	}
+
+	do("POST", "/api/v2/webhooks", fmt.Sprintf(`{"orgID":%q,"name":"dbrps","url":%q,"secret":%q,"events":["dbrp.changed"]}`,
+		l.Org.ID, srv.URL, secret), nethttp.StatusCreated)
+
+	mapping := fmt.Sprintf(`{"cluster":"c","database":"db","retention_policy":"rp","default":%%t,"organization_id":%q,"bucket_id":%q}`,
+		l.Org.ID, l.Bucket.ID)
+	do("PUT", "/api/v2/dbrps", fmt.Sprintf(mapping, false), nethttp.StatusOK)
+	do("PUT", "/api/v2/dbrps", fmt.Sprintf(mapping, true), nethttp.StatusOK)
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case d := <-deliveries:
+			if d.event != "dbrp.changed" {
+				t.Fatalf("unexpected event %q", d.event)
+			}
+			if want := webhook.Sign(secret, d.body); d.signature != want {
+				t.Fatalf("unexpected signature %q, want %q", d.signature, want)
+			}
+
+			var e struct {
+				Resource struct {
+					Action  string `json:"action"`
+					Default bool   `json:"default"`
+				} `json:"resource"`
+			}
+			if err := json.Unmarshal(d.body, &e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Resource.Action == "updated" {
+				if !e.Resource.Default {
+					t.Fatalf("expected the updated mapping to be the default: %s", d.body)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the delivery of the update of the mapping")
+		}
+	}
+}
@@ -79,6 +79,12 @@ type Dashboard struct {
 	Description    string        `json:"description"`
 	Cells          []*Cell       `json:"cells"`
 	Meta           DashboardMeta `json:"meta"`
+
+	// Slug is a stable identifier of the dashboard within its organization.
+	// Packages name the dashboards they export by their slug, and applying
+	// a package updates the dashboard with the slug of its name rather than
+	// creating another one.
+	Slug string `json:"slug,omitempty"`
 }
 
 // DashboardMeta contains meta information about dashboards
@@ -226,6 +232,7 @@ type DashboardUpdate struct {
 	Name        *string  `json:"name"`
 	Description *string  `json:"description"`
 	Cells       *[]*Cell `json:"cells"`
+	Slug        *string  `json:"slug,omitempty"`
 }
 
 // Apply applies an update to a dashboard.
@@ -242,12 +249,16 @@ func (u DashboardUpdate) Apply(d *Dashboard) error {
 		d.Cells = *u.Cells
 	}
 
+	if u.Slug != nil {
+		d.Slug = *u.Slug
+	}
+
 	return nil
 }
 
 // Valid returns an error if the dashboard update is invalid.
 func (u DashboardUpdate) Valid() *Error {
-	if u.Name == nil && u.Description == nil {
+	if u.Name == nil && u.Description == nil && u.Slug == nil {
 		return &Error{
 			Code: EInvalid,
 			Msg:  "must update at least one attribute",
@@ -0,0 +1,52 @@
+package influxdb
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/flux"
+)
+
+const (
+	// DefaultCellRenderWidth is the width in pixels of a rendered dashboard cell.
+	DefaultCellRenderWidth = 800
+	// DefaultCellRenderHeight is the height in pixels of a rendered dashboard cell.
+	DefaultCellRenderHeight = 400
+	// MaxCellRenderSize is the largest width or height in pixels of a rendered dashboard cell.
+	MaxCellRenderSize = 4096
+)
+
+// DashboardCellRenderer renders the results of the queries of a dashboard
+// cell as an image, so that alerts and reports can embed its chart.
+type DashboardCellRenderer interface {
+	// RenderPNG writes the results rendered for the view as a PNG image of
+	// width by height pixels.
+	RenderPNG(ctx context.Context, w io.Writer, view *View, results flux.ResultIterator, width, height int) error
+}
+
+// ViewQueries returns the queries of the view properties, or nil for views
+// without queries such as markdown.
+func ViewQueries(p ViewProperties) []DashboardQuery {
+	switch v := p.(type) {
+	case XYViewProperties:
+		return v.Queries
+	case LinePlusSingleStatProperties:
+		return v.Queries
+	case SingleStatViewProperties:
+		return v.Queries
+	case HistogramViewProperties:
+		return v.Queries
+	case HeatmapViewProperties:
+		return v.Queries
+	case ScatterViewProperties:
+		return v.Queries
+	case GaugeViewProperties:
+		return v.Queries
+	case TableViewProperties:
+		return v.Queries
+	case CheckViewProperties:
+		return v.Queries
+	default:
+		return nil
+	}
+}
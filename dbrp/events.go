@@ -0,0 +1,102 @@
+package dbrp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// EventType is the kind of change of a mapping.
+type EventType string
+
+// The kinds of change of a mapping.
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+// Event is a change of a mapping. The mapping of a deleted event is the
+// mapping as it was before it was deleted.
+type Event struct {
+	Type    EventType
+	Mapping influxdb.DBRPMapping
+}
+
+// EventListener is notified of the changes of the mappings of a Service,
+// such as to invalidate the mappings it caches. Listeners are notified in
+// the order they subscribed, once the changes are committed, in the
+// goroutine making them, so they must return quickly.
+type EventListener interface {
+	DBRPMappingChanged(ctx context.Context, e Event)
+}
+
+// EventListenerFunc is an EventListener calling the function.
+type EventListenerFunc func(ctx context.Context, e Event)
+
+// DBRPMappingChanged calls f(ctx, e).
+func (f EventListenerFunc) DBRPMappingChanged(ctx context.Context, e Event) {
+	f(ctx, e)
+}
+
+// WithEventListener subscribes l to the changes of the mappings of the
+// Service.
+func WithEventListener(l EventListener) ServiceOption {
+	return func(s *Service) {
+		s.Subscribe(l)
+	}
+}
+
+// eventBus notifies its listeners of the changes of mappings.
+type eventBus struct {
+	mu        sync.RWMutex
+	nextID    int
+	listeners []subscription
+}
+
+type subscription struct {
+	id int
+	l  EventListener
+}
+
+// Subscribe subscribes l to the changes of the mappings of s, and returns a
+// function unsubscribing it.
+func (s *Service) Subscribe(l EventListener) (unsubscribe func()) {
+	b := &s.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.listeners = append(b.listeners, subscription{id: id, l: l})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.listeners {
+			if sub.id == id {
+				b.listeners = append(b.listeners[:i:i], b.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// publish notifies the listeners of s of the events, in order.
+func (s *Service) publish(ctx context.Context, events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	b := &s.events
+	b.mu.RLock()
+	listeners := b.listeners
+	b.mu.RUnlock()
+
+	for _, e := range events {
+		for _, sub := range listeners {
+			sub.l.DBRPMappingChanged(ctx, e)
+		}
+	}
+}
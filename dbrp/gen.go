@@ -0,0 +1,3 @@
+package dbrp
+
+//go:generate protoc -I . --plugin ../scripts/protoc-gen-gogofaster --gogofaster_out=plugins=grpc:. dbrp.proto
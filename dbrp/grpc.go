@@ -0,0 +1,254 @@
+package dbrp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterGRPCService registers the DBRPMappingService of dbrp.proto on s,
+// so that agents can resolve and manage mappings without the overhead of
+// the HTTP API.
+//
+// Calls are authenticated with the API token in their "authorization"
+// metadata, such as "Token <token>", found in auths, and served by svc,
+// which must authorize them.
+func RegisterGRPCService(s *grpc.Server, svc influxdb.DBRPMappingService, auths influxdb.AuthorizationService, users influxdb.UserService) {
+	RegisterDBRPMappingServiceServer(s, &grpcService{
+		svc:   svc,
+		auths: auths,
+		users: users,
+	})
+}
+
+var _ DBRPMappingServiceServer = (*grpcService)(nil)
+
+type grpcService struct {
+	svc   influxdb.DBRPMappingService
+	auths influxdb.AuthorizationService
+	users influxdb.UserService
+}
+
+// authenticate returns ctx with the authorization of the token of the call,
+// like the authentication of the HTTP API does.
+func (s *grpcService) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	v := md.Get("authorization")
+	if len(v) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "token required")
+	}
+	const scheme = "Token "
+	if !strings.HasPrefix(v[0], scheme) {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be of the form Token <token>")
+	}
+
+	a, err := s.auths.FindAuthorizationByToken(ctx, strings.TrimPrefix(v[0], scheme))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "authorization not found")
+	}
+	if a.UserID.Valid() {
+		u, err := s.users.FindUserByID(ctx, a.UserID)
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		if u.Status == influxdb.Inactive {
+			return nil, status.Error(codes.PermissionDenied, "User is inactive")
+		}
+	}
+	return icontext.SetAuthorizer(ctx, a), nil
+}
+
+func (s *grpcService) FindBy(ctx context.Context, req *MappingKey) (*Mapping, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.svc.FindBy(ctx, req.Cluster, req.Database, req.RetentionPolicy)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return mappingToMessage(m), nil
+}
+
+func (s *grpcService) FindMany(ctx context.Context, req *FindManyRequest) (*FindManyResponse, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.OrganizationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "organization_id is required")
+	}
+	orgID, err := influxdb.IDFromString(req.OrganizationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid organization_id")
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset and limit must not be negative")
+	}
+
+	filter := influxdb.DBRPMappingFilter{OrganizationID: orgID}
+	for _, f := range []struct {
+		v   string
+		dst **string
+	}{
+		{req.Cluster, &filter.Cluster},
+		{req.Database, &filter.Database},
+		{req.RetentionPolicy, &filter.RetentionPolicy},
+		{req.DatabasePrefix, &filter.DatabasePrefix},
+		{req.DatabasePattern, &filter.DatabasePattern},
+	} {
+		if f.v != "" {
+			v := f.v
+			*f.dst = &v
+		}
+	}
+	for _, v := range req.BucketIds {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid bucket_ids")
+		}
+		filter.BucketIDs = append(filter.BucketIDs, *id)
+	}
+	switch req.Default {
+	case DefaultFilter_DEFAULT, DefaultFilter_NOT_DEFAULT:
+		dflt := req.Default == DefaultFilter_DEFAULT
+		filter.Default = &dflt
+	}
+
+	ms, n, err := s.svc.FindMany(ctx, filter, influxdb.FindOptions{
+		Offset: int(req.Offset),
+		Limit:  int(req.Limit),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	res := &FindManyResponse{
+		Mappings: make([]*Mapping, 0, len(ms)),
+		Total:    int64(n),
+	}
+	for _, m := range ms {
+		res.Mappings = append(res.Mappings, mappingToMessage(m))
+	}
+	return res, nil
+}
+
+func (s *grpcService) Create(ctx context.Context, req *Mapping) (*Mapping, error) {
+	return s.put(ctx, req, s.svc.Create)
+}
+
+func (s *grpcService) Upsert(ctx context.Context, req *Mapping) (*Mapping, error) {
+	return s.put(ctx, req, s.svc.Upsert)
+}
+
+func (s *grpcService) Replace(ctx context.Context, req *ReplaceRequest) (*Mapping, error) {
+	if req.Mapping == nil {
+		return nil, status.Error(codes.InvalidArgument, "mapping is required")
+	}
+	return s.put(ctx, req.Mapping, func(ctx context.Context, m *influxdb.DBRPMapping) error {
+		return s.svc.Replace(ctx, m, req.Revision)
+	})
+}
+
+// put writes the mapping of req with fn, and returns the mapping written.
+func (s *grpcService) put(ctx context.Context, req *Mapping, fn func(context.Context, *influxdb.DBRPMapping) error) (*Mapping, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, err := mappingFromMessage(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(ctx, m); err != nil {
+		return nil, grpcError(err)
+	}
+	return mappingToMessage(m), nil
+}
+
+func (s *grpcService) SetDefault(ctx context.Context, req *MappingKey) (*Mapping, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.svc.SetDefault(ctx, req.Cluster, req.Database, req.RetentionPolicy)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return mappingToMessage(m), nil
+}
+
+func (s *grpcService) Delete(ctx context.Context, req *MappingKey) (*DeleteResponse, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.svc.Delete(ctx, req.Cluster, req.Database, req.RetentionPolicy); err != nil {
+		return nil, grpcError(err)
+	}
+	return &DeleteResponse{}, nil
+}
+
+func mappingToMessage(m *influxdb.DBRPMapping) *Mapping {
+	return &Mapping{
+		Cluster:         m.Cluster,
+		Database:        m.Database,
+		RetentionPolicy: m.RetentionPolicy,
+		Default:         m.Default,
+		OrganizationId:  m.OrganizationID.String(),
+		BucketId:        m.BucketID.String(),
+		Revision:        m.Revision(),
+	}
+}
+
+// mappingFromMessage returns the mapping of mm. Missing IDs are left for the
+// validation of the mapping to report.
+func mappingFromMessage(mm *Mapping) (*influxdb.DBRPMapping, error) {
+	m := &influxdb.DBRPMapping{
+		Cluster:         mm.Cluster,
+		Database:        mm.Database,
+		RetentionPolicy: mm.RetentionPolicy,
+		Default:         mm.Default,
+	}
+	for _, id := range []struct {
+		name string
+		v    string
+		dst  *influxdb.ID
+	}{
+		{"organization_id", mm.OrganizationId, &m.OrganizationID},
+		{"bucket_id", mm.BucketId, &m.BucketID},
+	} {
+		if id.v == "" {
+			continue
+		}
+		if err := id.dst.DecodeFromString(id.v); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s", id.name)
+		}
+	}
+	return m, nil
+}
+
+// grpcError returns the gRPC status of err.
+func grpcError(err error) error {
+	code := codes.Internal
+	switch influxdb.ErrorCode(err) {
+	case influxdb.ENotFound:
+		code = codes.NotFound
+	case influxdb.EInvalid, influxdb.EEmptyValue, influxdb.EUnprocessableEntity:
+		code = codes.InvalidArgument
+	case influxdb.EConflict:
+		code = codes.AlreadyExists
+	case influxdb.EPreconditionFailed:
+		code = codes.FailedPrecondition
+	case influxdb.EUnauthorized, influxdb.EForbidden:
+		code = codes.PermissionDenied
+	case influxdb.ETooManyRequests, influxdb.EUnavailable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, influxdb.ErrorMessage(err))
+}
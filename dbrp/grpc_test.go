@@ -0,0 +1,128 @@
+package dbrp
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRegisterGRPCService(t *testing.T) {
+	auth := &influxdb.Authorization{ID: 1, OrgID: 2, UserID: 3, Status: influxdb.Active}
+	auths := &mock.AuthorizationService{
+		FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+			if token != "secret" {
+				return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+			}
+			return auth, nil
+		},
+	}
+	users := mock.NewUserService()
+	users.FindUserByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.User, error) {
+		return &influxdb.User{ID: id, Status: influxdb.Active}, nil
+	}
+
+	mapping := &influxdb.DBRPMapping{
+		Cluster:         "c",
+		Database:        "telegraf",
+		RetentionPolicy: "autogen",
+		Default:         true,
+		OrganizationID:  2,
+		BucketID:        4,
+	}
+	var filter influxdb.DBRPMappingFilter
+	svc := mock.NewDBRPMappingService()
+	svc.FindByFn = func(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+		if a, err := icontext.GetAuthorizer(ctx); err != nil || a.Identifier() != auth.ID {
+			t.Errorf("expected the call to be authorized by %s, got %v", auth.ID, a)
+		}
+		if cluster != mapping.Cluster || db != mapping.Database || rp != mapping.RetentionPolicy {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "dbrp mapping not found"}
+		}
+		return mapping, nil
+	}
+	svc.FindManyFn = func(ctx context.Context, f influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+		filter = f
+		return []*influxdb.DBRPMapping{mapping}, 3, nil
+	}
+	svc.CreateFn = func(ctx context.Context, m *influxdb.DBRPMapping) error {
+		if !reflect.DeepEqual(m, mapping) {
+			t.Errorf("created %+v, want %+v", m, mapping)
+		}
+		return nil
+	}
+
+	s := grpc.NewServer()
+	RegisterGRPCService(s, svc, auths, users)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := NewDBRPMappingServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Token secret")
+	key := &MappingKey{Cluster: "c", Database: "telegraf", RetentionPolicy: "autogen"}
+	want := mappingToMessage(mapping)
+
+	got, err := client.FindBy(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("found %+v, want %+v", got, want)
+	}
+
+	_, err = client.FindBy(ctx, &MappingKey{Database: "missing"})
+	if s, ok := status.FromError(err); !ok || s.Code() != codes.NotFound {
+		t.Errorf("expected a missing mapping not to be found, got %v", err)
+	}
+
+	req := &FindManyRequest{OrganizationId: "0000000000000002", Database: "telegraf", Default: DefaultFilter_DEFAULT}
+	res, err := client.FindMany(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 3 || len(res.Mappings) != 1 || !reflect.DeepEqual(res.Mappings[0], want) {
+		t.Errorf("unexpected mappings %+v", res)
+	}
+	if *filter.OrganizationID != 2 || *filter.Database != "telegraf" || !*filter.Default || filter.Cluster != nil || filter.RetentionPolicy != nil {
+		t.Errorf("unexpected filter %+v", filter)
+	}
+
+	_, err = client.FindMany(ctx, &FindManyRequest{})
+	if s, ok := status.FromError(err); !ok || s.Code() != codes.InvalidArgument {
+		t.Errorf("expected an organization to be required, got %v", err)
+	}
+
+	if _, err := client.Create(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, md := range [][]string{
+		nil,
+		{"authorization", "Bearer secret"},
+		{"authorization", "Token wrong"},
+	} {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), md...)
+		_, err := client.FindBy(ctx, key)
+		if s, ok := status.FromError(err); !ok || s.Code() != codes.Unauthenticated {
+			t.Errorf("expected %v to be unauthenticated, got %v", md, err)
+		}
+	}
+}
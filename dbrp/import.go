@@ -0,0 +1,256 @@
+package dbrp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/tsdb/migrate"
+)
+
+// internalDatabase is the database of the statistics of a 1.x server, which
+// is never imported.
+const internalDatabase = "_internal"
+
+// ImportFromMeta reads the meta.db of an InfluxDB 1.x server, and maps each
+// of its databases and retention policies to the bucket of the organization
+// named database/retention policy, the name given to the buckets by
+// `influxd migrate`. The buckets which do not exist are created if
+// opts.CreateBuckets is set, with the duration of their retention policy.
+//
+// Retention policies already mapped to the same bucket are returned with
+// the mappings created; those mapped elsewhere are skipped.
+func ImportFromMeta(ctx context.Context, meta io.Reader, orgID influxdb.ID, buckets influxdb.BucketService, mappings influxdb.DBRPMappingService, opts influxdb.DBRPImportOptions) (*influxdb.DBRPImportResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	buf, err := ioutil.ReadAll(meta)
+	if err != nil {
+		return nil, err
+	}
+	var data migrate.Data
+	if err := data.UnmarshalBinary(buf); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid meta.db",
+			Err:  err,
+		}
+	}
+
+	im := newImporter(orgID, buckets, mappings, opts)
+	for _, db := range data.Databases {
+		if db.Name == internalDatabase {
+			continue
+		}
+		for _, rp := range db.RetentionPolicies {
+			err := im.importMapping(ctx, "", db.Name, rp.Name, rp.Name == db.DefaultRetentionPolicy, db.Name+"/"+rp.Name, func(b *influxdb.Bucket) {
+				b.Description = fmt.Sprintf("Imported from the retention policy %q of the 1.x database %q", rp.Name, db.Name)
+				b.RetentionPolicyName = rp.Name
+				b.RetentionPeriod = rp.Duration
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return im.res, nil
+}
+
+// ImportPackage maps the databases and retention policies of pkg to the
+// buckets of the organization named in the package. The buckets which do not
+// exist are created if opts.CreateBuckets is set, without a retention period.
+//
+// Retention policies already mapped to the same bucket are returned with
+// the mappings created; those mapped elsewhere are skipped.
+func ImportPackage(ctx context.Context, pkg *influxdb.DBRPPackage, orgID influxdb.ID, buckets influxdb.BucketService, mappings influxdb.DBRPMappingService, opts influxdb.DBRPImportOptions) (*influxdb.DBRPImportResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := pkg.Valid(); err != nil {
+		return nil, err
+	}
+
+	im := newImporter(orgID, buckets, mappings, opts)
+	for _, m := range pkg.Mappings {
+		err := im.importMapping(ctx, m.Cluster, m.Database, m.RetentionPolicy, m.Default, m.Bucket, func(b *influxdb.Bucket) {
+			b.Description = fmt.Sprintf("Imported for the retention policy %q of the 1.x database %q", m.RetentionPolicy, m.Database)
+			b.RetentionPolicyName = m.RetentionPolicy
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return im.res, nil
+}
+
+// importer maps retention policies to the buckets of an organization,
+// collecting the result of an import.
+type importer struct {
+	orgID    influxdb.ID
+	buckets  influxdb.BucketService
+	mappings influxdb.DBRPMappingService
+	opts     influxdb.DBRPImportOptions
+	res      *influxdb.DBRPImportResult
+}
+
+func newImporter(orgID influxdb.ID, buckets influxdb.BucketService, mappings influxdb.DBRPMappingService, opts influxdb.DBRPImportOptions) *importer {
+	return &importer{
+		orgID:    orgID,
+		buckets:  buckets,
+		mappings: mappings,
+		opts:     opts,
+		res: &influxdb.DBRPImportResult{
+			Mappings: []*influxdb.DBRPMapping{},
+			Buckets:  []*influxdb.Bucket{},
+			Skipped:  []influxdb.DBRPImportSkip{},
+		},
+	}
+}
+
+// importMapping maps the retention policy rp of the database db to the
+// bucket named name, in cluster unless the import has a cluster of its own.
+// A missing bucket is created, after being set up by init, if the import
+// creates buckets.
+func (im *importer) importMapping(ctx context.Context, cluster, db, rp string, def bool, name string, init func(*influxdb.Bucket)) error {
+	skip := func(reason string) {
+		im.res.Skipped = append(im.res.Skipped, influxdb.DBRPImportSkip{
+			Database:        db,
+			RetentionPolicy: rp,
+			Reason:          reason,
+		})
+	}
+
+	if im.opts.Cluster != "" {
+		cluster = im.opts.Cluster
+	}
+	if cluster == "" {
+		cluster = influxdb.DefaultDBRPCluster
+	}
+
+	b, err := im.buckets.FindBucketByName(ctx, im.orgID, name)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		if !im.opts.CreateBuckets {
+			skip(fmt.Sprintf("bucket %q not found", name))
+			return nil
+		}
+		b = &influxdb.Bucket{
+			OrgID: im.orgID,
+			Name:  name,
+		}
+		init(b)
+		if err := im.buckets.CreateBucket(ctx, b); err != nil {
+			return err
+		}
+		im.res.Buckets = append(im.res.Buckets, b)
+	} else if err != nil {
+		return err
+	}
+
+	m := &influxdb.DBRPMapping{
+		Cluster:         cluster,
+		Database:        db,
+		RetentionPolicy: rp,
+		Default:         def,
+		OrganizationID:  im.orgID,
+		BucketID:        b.ID,
+	}
+	err = im.mappings.Create(ctx, m)
+	switch influxdb.ErrorCode(err) {
+	case "":
+	case influxdb.EConflict:
+		skip("mapped to another bucket")
+		return nil
+	case influxdb.EInvalid:
+		skip(influxdb.ErrorMessage(err))
+		return nil
+	default:
+		return err
+	}
+	im.res.Mappings = append(im.res.Mappings, m)
+	return nil
+}
+
+// Export returns the mappings to the buckets of the organization as a
+// package. Mappings to buckets which no longer exist are left out.
+func Export(ctx context.Context, orgID influxdb.ID, buckets influxdb.BucketService, mappings influxdb.DBRPMappingService) (*influxdb.DBRPPackage, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ms, _, err := mappings.FindMany(ctx, influxdb.DBRPMappingFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &influxdb.DBRPPackage{
+		Version:  influxdb.DBRPPackageVersion,
+		Mappings: []influxdb.DBRPPackageMapping{},
+	}
+	names := make(map[influxdb.ID]string)
+	for _, m := range ms {
+		if m.OrganizationID != orgID {
+			continue
+		}
+		name, ok := names[m.BucketID]
+		if !ok {
+			b, err := buckets.FindBucketByID(ctx, m.BucketID)
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				names[m.BucketID] = ""
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			name = b.Name
+			names[m.BucketID] = name
+		}
+		if name == "" {
+			continue
+		}
+		pkg.Mappings = append(pkg.Mappings, influxdb.DBRPPackageMapping{
+			Cluster:         m.Cluster,
+			Database:        m.Database,
+			RetentionPolicy: m.RetentionPolicy,
+			Default:         m.Default,
+			Bucket:          name,
+		})
+	}
+	return pkg, nil
+}
+
+// ImportService is an influxdb.DBRPImportService importing with
+// ImportFromMeta and ImportPackage, and exporting with Export.
+type ImportService struct {
+	buckets  influxdb.BucketService
+	mappings influxdb.DBRPMappingService
+}
+
+var _ influxdb.DBRPImportService = (*ImportService)(nil)
+
+// NewImportService returns an ImportService finding and creating buckets
+// with buckets, and creating mappings with mappings.
+func NewImportService(buckets influxdb.BucketService, mappings influxdb.DBRPMappingService) *ImportService {
+	return &ImportService{
+		buckets:  buckets,
+		mappings: mappings,
+	}
+}
+
+// ImportDBRPMappings imports the mappings of the databases and retention
+// policies of meta to the buckets of the organization.
+func (s *ImportService) ImportDBRPMappings(ctx context.Context, orgID influxdb.ID, meta io.Reader, opts influxdb.DBRPImportOptions) (*influxdb.DBRPImportResult, error) {
+	return ImportFromMeta(ctx, meta, orgID, s.buckets, s.mappings, opts)
+}
+
+// ImportDBRPPackage imports the mappings of pkg to the buckets of the
+// organization.
+func (s *ImportService) ImportDBRPPackage(ctx context.Context, orgID influxdb.ID, pkg *influxdb.DBRPPackage, opts influxdb.DBRPImportOptions) (*influxdb.DBRPImportResult, error) {
+	return ImportPackage(ctx, pkg, orgID, s.buckets, s.mappings, opts)
+}
+
+// ExportDBRPMappings exports the mappings to the buckets of the
+// organization.
+func (s *ImportService) ExportDBRPMappings(ctx context.Context, orgID influxdb.ID) (*influxdb.DBRPPackage, error) {
+	return Export(ctx, orgID, s.buckets, s.mappings)
+}
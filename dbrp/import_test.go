@@ -0,0 +1,200 @@
+package dbrp_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+// encodeMeta returns the protobuf encoding of the meta.db of a 1.x server
+// with databases, whose retention policies are given by name and duration,
+// the first being the default.
+func encodeMeta(t *testing.T, databases map[string][][2]interface{}) []byte {
+	t.Helper()
+	message := func(fn func(b *proto.Buffer)) []byte {
+		b := proto.NewBuffer(nil)
+		fn(b)
+		return b.Bytes()
+	}
+	varint := func(b *proto.Buffer, field, v uint64) {
+		b.EncodeVarint(field<<3 | proto.WireVarint)
+		b.EncodeVarint(v)
+	}
+	bytesField := func(b *proto.Buffer, field uint64, v []byte) {
+		b.EncodeVarint(field<<3 | proto.WireBytes)
+		b.EncodeRawBytes(v)
+	}
+
+	return message(func(b *proto.Buffer) {
+		// Term, Index and ClusterID.
+		for field := uint64(1); field <= 3; field++ {
+			varint(b, field, 1)
+		}
+		for name, rps := range databases {
+			bytesField(b, 5, message(func(db *proto.Buffer) {
+				bytesField(db, 1, []byte(name))
+				bytesField(db, 2, []byte(rps[0][0].(string)))
+				for _, rp := range rps {
+					bytesField(db, 3, message(func(rpb *proto.Buffer) {
+						bytesField(rpb, 1, []byte(rp[0].(string)))
+						varint(rpb, 2, uint64(rp[1].(time.Duration)))
+						varint(rpb, 3, uint64(time.Hour))
+						varint(rpb, 4, 1)
+					}))
+				}
+			}))
+		}
+		// MaxShardGroupID and MaxShardID.
+		varint(b, 8, 1)
+		varint(b, 9, 1)
+	})
+}
+
+func TestImportFromMeta(t *testing.T) {
+	ctx := context.Background()
+	const orgID = influxdb.ID(10)
+	meta := encodeMeta(t, map[string][][2]interface{}{
+		"telegraf":  {{"autogen", time.Duration(0)}, {"week", 7 * 24 * time.Hour}},
+		"_internal": {{"monitor", 7 * 24 * time.Hour}},
+	})
+
+	existing := &influxdb.Bucket{ID: 1, OrgID: orgID, Name: "telegraf/autogen"}
+	var created []*influxdb.Bucket
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByNameFn = func(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+		if name == existing.Name {
+			return existing, nil
+		}
+		for _, b := range created {
+			if b.Name == name {
+				return b, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	buckets.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+		b.ID = influxdb.ID(len(created) + 2)
+		created = append(created, b)
+		return nil
+	}
+	mappings, err := dbrp.NewService(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without creating buckets, the retention policies without one are
+	// skipped.
+	res, err := dbrp.ImportFromMeta(ctx, bytes.NewReader(meta), orgID, buckets, mappings, influxdb.DBRPImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Mappings) != 1 || len(res.Skipped) != 1 || len(res.Buckets) != 0 {
+		t.Fatalf("expected 1 mapping and 1 retention policy skipped, got %+v", res)
+	}
+	if m := res.Mappings[0]; m.Cluster != influxdb.DefaultDBRPCluster || m.Database != "telegraf" || m.RetentionPolicy != "autogen" || !m.Default || m.BucketID != existing.ID {
+		t.Errorf("unexpected mapping %+v", m)
+	}
+
+	res, err = dbrp.ImportFromMeta(ctx, bytes.NewReader(meta), orgID, buckets, mappings, influxdb.DBRPImportOptions{CreateBuckets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Mappings) != 2 || len(res.Skipped) != 0 || len(res.Buckets) != 1 {
+		t.Fatalf("expected 2 mappings and 1 bucket created, got %+v", res)
+	}
+	if b := res.Buckets[0]; b.Name != "telegraf/week" || b.RetentionPeriod != 7*24*time.Hour || b.RetentionPolicyName != "week" {
+		t.Errorf("unexpected bucket %+v", b)
+	}
+	ms, n, err := mappings.FindMany(ctx, influxdb.DBRPMappingFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected the _internal database not to be mapped, got %+v", ms)
+	}
+
+	if _, err := dbrp.ImportFromMeta(ctx, bytes.NewReader([]byte("not a meta.db")), orgID, buckets, mappings, influxdb.DBRPImportOptions{}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an invalid meta.db to be rejected, got %v", err)
+	}
+}
+
+func TestExport_ImportPackage(t *testing.T) {
+	ctx := context.Background()
+	const orgID = influxdb.ID(10)
+
+	bucketsByID := map[influxdb.ID]*influxdb.Bucket{
+		1: {ID: 1, OrgID: orgID, Name: "telegraf/autogen"},
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		if b, ok := bucketsByID[id]; ok {
+			return b, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	mappings, err := dbrp.NewService(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*influxdb.DBRPMapping{
+		{Cluster: "c", Database: "telegraf", RetentionPolicy: "autogen", Default: true, OrganizationID: orgID, BucketID: 1},
+		{Cluster: "c", Database: "telegraf", RetentionPolicy: "gone", OrganizationID: orgID, BucketID: 2},
+		{Cluster: "c", Database: "other", RetentionPolicy: "autogen", OrganizationID: 11, BucketID: 3},
+	} {
+		if err := mappings.Create(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkg, err := dbrp.Export(ctx, orgID, buckets, mappings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := influxdb.DBRPPackageMapping{Cluster: "c", Database: "telegraf", RetentionPolicy: "autogen", Default: true, Bucket: "telegraf/autogen"}
+	if pkg.Version != influxdb.DBRPPackageVersion || len(pkg.Mappings) != 1 || pkg.Mappings[0] != want {
+		t.Fatalf("expected the mapping of the organization to an existing bucket to be exported, got %+v", pkg)
+	}
+
+	// Import the package on another instance, with buckets of other IDs.
+	var created []*influxdb.Bucket
+	other := mock.NewBucketService()
+	other.FindBucketByNameFn = func(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+		for _, b := range created {
+			if b.Name == name {
+				return b, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	other.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+		b.ID = influxdb.ID(len(created) + 100)
+		created = append(created, b)
+		return nil
+	}
+	otherMappings, err := dbrp.NewService(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := dbrp.ImportPackage(ctx, pkg, 20, other, otherMappings, influxdb.DBRPImportOptions{CreateBuckets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Mappings) != 1 || len(res.Buckets) != 1 {
+		t.Fatalf("expected 1 mapping and 1 bucket created, got %+v", res)
+	}
+	if m := res.Mappings[0]; m.Cluster != "c" || !m.Default || m.OrganizationID != 20 || m.BucketID != 100 {
+		t.Errorf("unexpected mapping %+v", m)
+	}
+
+	pkg.Version = 2
+	if _, err := dbrp.ImportPackage(ctx, pkg, 20, other, otherMappings, influxdb.DBRPImportOptions{}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a package of an unsupported version to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,596 @@
+// Package dbrp stores the mappings of InfluxDB 1.x databases and retention
+// policies to buckets, and imports them from the meta.db of a 1.x server.
+package dbrp
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+)
+
+var dbrpBucket = []byte("dbrpmappingsv1")
+
+var errDBRPMappingNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "dbrp mapping not found",
+}
+
+var errDBRPMappingOtherOrg = &influxdb.Error{
+	Code: influxdb.EConflict,
+	Msg:  "dbrp mapping already exists in another organization",
+}
+
+// ErrBucketNotFound is returned when creating a mapping to a bucket which
+// does not exist.
+var ErrBucketNotFound = &influxdb.Error{
+	Code: influxdb.EInvalid,
+	Msg:  "dbrp mapping bucket not found",
+}
+
+// ErrBucketOtherOrg is returned when creating a mapping to a bucket of an
+// organization other than the organization of the mapping.
+var ErrBucketOtherOrg = &influxdb.Error{
+	Code: influxdb.EInvalid,
+	Msg:  "dbrp mapping bucket belongs to another organization",
+}
+
+var _ influxdb.DBRPMappingService = (*Service)(nil)
+
+// Service is an influxdb.DBRPMappingService storing the mappings in a kv
+// store, by their cluster, database and retention policy.
+type Service struct {
+	store   kv.Store
+	buckets influxdb.BucketService
+	log     *zap.Logger
+	events  eventBus
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithBucketService checks the buckets of the mappings created against
+// buckets. Without it, the buckets of the mappings are not checked.
+func WithBucketService(buckets influxdb.BucketService) ServiceOption {
+	return func(s *Service) {
+		s.buckets = buckets
+	}
+}
+
+// WithLogger sets the logger warning of mappings to buckets whose retention
+// period conflicts with the name of their retention policy.
+func WithLogger(log *zap.Logger) ServiceOption {
+	return func(s *Service) {
+		s.log = log
+	}
+}
+
+// NewService returns a Service storing the mappings in store.
+func NewService(store kv.Store, opts ...ServiceOption) (*Service, error) {
+	s := &Service{store: store, log: zap.NewNop()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	err := store.Update(context.Background(), func(tx kv.Tx) error {
+		_, err := tx.Bucket(dbrpBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func encodeDBRPMappingKey(cluster, db, rp string) []byte {
+	// The names of clusters, databases and retention policies cannot
+	// contain slashes.
+	return []byte(path.Join(cluster, db, rp))
+}
+
+func findDBRPMapping(tx kv.Tx, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	b, err := tx.Bucket(dbrpBucket)
+	if err != nil {
+		return nil, err
+	}
+	v, err := b.Get(encodeDBRPMappingKey(cluster, db, rp))
+	if kv.IsNotFound(err) {
+		return nil, errDBRPMappingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m influxdb.DBRPMapping
+	if err := json.Unmarshal(v, &m); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &m, nil
+}
+
+// FindBy returns the mapping of the cluster, database and retention policy.
+func (s *Service) FindBy(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var m *influxdb.DBRPMapping
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		m, err = findDBRPMapping(tx, cluster, db, rp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Find returns the first mapping matching filter.
+func (s *Service) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+	if filter.Cluster == nil && filter.Database == nil && filter.RetentionPolicy == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "no filter parameters provided",
+		}
+	}
+
+	ms, n, err := s.FindMany(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, errDBRPMappingNotFound
+	}
+	return ms[0], nil
+}
+
+// FindMany returns the mappings matching filter and their number. The
+// mappings are sorted and paged by the first of opt, if any, and the number
+// is of all of the mappings matching filter.
+func (s *Service) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if len(opt) > 0 {
+		if err := influxdb.ValidateDBRPSort(opt[0]); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	matches := func(m *influxdb.DBRPMapping) bool {
+		return (filter.Cluster == nil || *filter.Cluster == m.Cluster) &&
+			filter.MatchesDatabase(m.Database) &&
+			(filter.RetentionPolicy == nil || *filter.RetentionPolicy == m.RetentionPolicy) &&
+			(filter.Default == nil || *filter.Default == m.Default) &&
+			(filter.OrganizationID == nil || *filter.OrganizationID == m.OrganizationID) &&
+			filter.MatchesBucket(m.BucketID)
+	}
+
+	ms := []*influxdb.DBRPMapping{}
+	if filter.Cluster != nil && filter.Database != nil && filter.RetentionPolicy != nil {
+		m, err := s.FindBy(ctx, *filter.Cluster, *filter.Database, *filter.RetentionPolicy)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return nil, 0, err
+		}
+		if err == nil && matches(m) {
+			ms = append(ms, m)
+		}
+	} else {
+		err := s.store.View(ctx, func(tx kv.Tx) error {
+			b, err := tx.Bucket(dbrpBucket)
+			if err != nil {
+				return err
+			}
+			cur, err := b.ForwardCursor(nil)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+
+			for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+				var m influxdb.DBRPMapping
+				if err := json.Unmarshal(v, &m); err != nil {
+					return &influxdb.Error{
+						Code: influxdb.EInternal,
+						Err:  err,
+					}
+				}
+				if matches(&m) {
+					ms = append(ms, &m)
+				}
+			}
+			return cur.Err()
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	n := len(ms)
+	if len(opt) > 0 {
+		influxdb.SortDBRPMappings(opt[0], ms)
+		ms = influxdb.PageDBRPMappings(opt[0], ms)
+	}
+	return ms, n, nil
+}
+
+// Create creates a mapping. Creating a mapping identical to an existing
+// one is not an error. The bucket of the mapping must exist and be of its
+// organization if s checks buckets. Creating a default mapping clears the
+// default of the other mappings of its organization, cluster and database.
+func (s *Service) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkBucket(ctx, m); err != nil {
+		return err
+	}
+	v, err := json.Marshal(m)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	var events []Event
+	err = s.store.Update(ctx, func(tx kv.Tx) error {
+		existing, err := findDBRPMapping(tx, m.Cluster, m.Database, m.RetentionPolicy)
+		if err == nil && !existing.Equal(m) {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "dbrp mapping already exists",
+			}
+		} else if err != nil && err != errDBRPMappingNotFound {
+			return err
+		}
+
+		b, err := tx.Bucket(dbrpBucket)
+		if err != nil {
+			return err
+		}
+		if m.Default {
+			if events, err = clearOtherDefaults(b, m); err != nil {
+				return err
+			}
+		}
+		if existing == nil {
+			events = append(events, Event{Type: EventCreated, Mapping: *m})
+		}
+		return b.Put(encodeDBRPMappingKey(m.Cluster, m.Database, m.RetentionPolicy), v)
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, events)
+	return nil
+}
+
+// Upsert creates a mapping, or replaces the mapping of its cluster, database
+// and retention policy if it is of the same organization. Upserting a default
+// mapping clears the default of the other mappings of its organization,
+// cluster and database.
+func (s *Service) Upsert(ctx context.Context, m *influxdb.DBRPMapping) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkBucket(ctx, m); err != nil {
+		return err
+	}
+	v, err := json.Marshal(m)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	var events []Event
+	err = s.store.Update(ctx, func(tx kv.Tx) error {
+		existing, err := findDBRPMapping(tx, m.Cluster, m.Database, m.RetentionPolicy)
+		if err == nil && existing.OrganizationID != m.OrganizationID {
+			return errDBRPMappingOtherOrg
+		} else if err != nil && err != errDBRPMappingNotFound {
+			return err
+		}
+
+		b, err := tx.Bucket(dbrpBucket)
+		if err != nil {
+			return err
+		}
+		if m.Default {
+			if events, err = clearOtherDefaults(b, m); err != nil {
+				return err
+			}
+		}
+		if existing == nil {
+			events = append(events, Event{Type: EventCreated, Mapping: *m})
+		} else if !existing.Equal(m) {
+			events = append(events, Event{Type: EventUpdated, Mapping: *m})
+		}
+		return b.Put(encodeDBRPMappingKey(m.Cluster, m.Database, m.RetentionPolicy), v)
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, events)
+	return nil
+}
+
+// Replace replaces the mapping of the cluster, database and retention
+// policy of m only if its revision is revision.
+func (s *Service) Replace(ctx context.Context, m *influxdb.DBRPMapping, revision string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkBucket(ctx, m); err != nil {
+		return err
+	}
+
+	var events []Event
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		existing, err := findDBRPMapping(tx, m.Cluster, m.Database, m.RetentionPolicy)
+		if err == errDBRPMappingNotFound {
+			return influxdb.ErrDBRPMappingChanged
+		} else if err != nil {
+			return err
+		}
+		if existing.OrganizationID != m.OrganizationID {
+			return errDBRPMappingOtherOrg
+		}
+		if existing.Revision() != revision {
+			return influxdb.ErrDBRPMappingChanged
+		}
+
+		b, err := tx.Bucket(dbrpBucket)
+		if err != nil {
+			return err
+		}
+		if m.Default {
+			if events, err = clearOtherDefaults(b, m); err != nil {
+				return err
+			}
+		}
+		if !existing.Equal(m) {
+			events = append(events, Event{Type: EventUpdated, Mapping: *m})
+		}
+		return putDBRPMapping(b, m)
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, events)
+	return nil
+}
+
+// SetDefault makes the mapping of the cluster, database and retention
+// policy the default for its cluster and database, clearing the default of
+// the other mappings of its organization, cluster and database.
+func (s *Service) SetDefault(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var (
+		m      *influxdb.DBRPMapping
+		events []Event
+	)
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		m, err = findDBRPMapping(tx, cluster, db, rp)
+		if err != nil {
+			return err
+		}
+		wasDefault := m.Default
+		m.Default = true
+
+		b, err := tx.Bucket(dbrpBucket)
+		if err != nil {
+			return err
+		}
+		if events, err = clearOtherDefaults(b, m); err != nil {
+			return err
+		}
+		if !wasDefault {
+			events = append(events, Event{Type: EventUpdated, Mapping: *m})
+		}
+		return putDBRPMapping(b, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, events)
+	return m, nil
+}
+
+func putDBRPMapping(bkt kv.Bucket, m *influxdb.DBRPMapping) error {
+	v, err := json.Marshal(m)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return bkt.Put(encodeDBRPMappingKey(m.Cluster, m.Database, m.RetentionPolicy), v)
+}
+
+// clearOtherDefaults clears the default of the mappings of the organization,
+// cluster and database of m to other retention policies, and returns the
+// events of their updates.
+func clearOtherDefaults(bkt kv.Bucket, m *influxdb.DBRPMapping) ([]Event, error) {
+	defaults, err := otherDefaultMappings(bkt, m)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for _, o := range defaults {
+		o.Default = false
+		if err := putDBRPMapping(bkt, o); err != nil {
+			return nil, err
+		}
+		events = append(events, Event{Type: EventUpdated, Mapping: *o})
+	}
+	return events, nil
+}
+
+// otherDefaultMappings returns the default mappings of the organization,
+// cluster and database of m to other retention policies.
+func otherDefaultMappings(bkt kv.Bucket, m *influxdb.DBRPMapping) ([]*influxdb.DBRPMapping, error) {
+	cur, err := bkt.ForwardCursor(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	var ms []*influxdb.DBRPMapping
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		var o influxdb.DBRPMapping
+		if err := json.Unmarshal(v, &o); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		if o.Default &&
+			o.OrganizationID == m.OrganizationID &&
+			o.Cluster == m.Cluster &&
+			o.Database == m.Database &&
+			o.RetentionPolicy != m.RetentionPolicy {
+			ms = append(ms, &o)
+		}
+	}
+	return ms, cur.Err()
+}
+
+// checkBucket returns an error if the bucket of m does not exist or is of
+// another organization. It warns if the retention policy of m is named for a
+// duration other than the retention period of the bucket, such as a 1w
+// retention policy mapped to a bucket keeping its data for 30 days.
+func (s *Service) checkBucket(ctx context.Context, m *influxdb.DBRPMapping) error {
+	if s.buckets == nil {
+		return nil
+	}
+
+	b, err := s.buckets.FindBucketByID(ctx, m.BucketID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return ErrBucketNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if b.OrgID != m.OrganizationID {
+		return ErrBucketOtherOrg
+	}
+
+	if d, err := influxql.ParseDuration(m.RetentionPolicy); err == nil && d != b.RetentionPeriod {
+		s.log.Warn("Retention policy name conflicts with the retention period of its bucket",
+			zap.String("database", m.Database),
+			zap.String("retention_policy", m.RetentionPolicy),
+			zap.String("bucket_id", b.ID.String()),
+			zap.Duration("retention_period", b.RetentionPeriod))
+	}
+	return nil
+}
+
+// Delete deletes the mapping of the cluster, database and retention policy.
+// Deleting a mapping which does not exist is not an error.
+func (s *Service) Delete(ctx context.Context, cluster, db, rp string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var events []Event
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		m, err := findDBRPMapping(tx, cluster, db, rp)
+		if err == errDBRPMappingNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(dbrpBucket)
+		if err != nil {
+			return err
+		}
+		events = append(events, Event{Type: EventDeleted, Mapping: *m})
+		return b.Delete(encodeDBRPMappingKey(cluster, db, rp))
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, events)
+	return nil
+}
+
+// DeleteBucketMappings deletes the mappings to the bucket b in tx. It is a
+// kv.BucketDeleteHook, which deletes the mappings to a bucket along with it
+// when the buckets are stored in the store of s. As tx is committed by the
+// caller, the listeners of s are only notified of the deletions by the
+// returned function, once the deletion of the bucket has committed.
+func (s *Service) DeleteBucketMappings(ctx context.Context, tx kv.Tx, b *influxdb.Bucket) (func(), error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	bkt, err := tx.Bucket(dbrpBucket)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := bucketMappings(bkt, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	events := make([]Event, 0, len(ms))
+	for _, m := range ms {
+		if err := bkt.Delete(encodeDBRPMappingKey(m.Cluster, m.Database, m.RetentionPolicy)); err != nil {
+			return nil, err
+		}
+		events = append(events, Event{Type: EventDeleted, Mapping: *m})
+	}
+	return func() {
+		s.publish(ctx, events)
+	}, nil
+}
+
+// bucketMappings returns the mappings to the bucket with the ID.
+func bucketMappings(bkt kv.Bucket, id influxdb.ID) ([]*influxdb.DBRPMapping, error) {
+	cur, err := bkt.ForwardCursor(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	var ms []*influxdb.DBRPMapping
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		var m influxdb.DBRPMapping
+		if err := json.Unmarshal(v, &m); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		if m.BucketID == id {
+			ms = append(ms, &m)
+		}
+	}
+	return ms, cur.Err()
+}
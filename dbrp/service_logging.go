@@ -0,0 +1,152 @@
+package dbrp
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"go.uber.org/zap"
+)
+
+// LoggingService logs the calls to a dbrp mapping service. The changes to the
+// mappings are logged at info level with who made them, and the lookups at
+// debug level.
+type LoggingService struct {
+	logger *zap.Logger
+	next   influxdb.DBRPMappingService
+}
+
+var _ influxdb.DBRPMappingService = (*LoggingService)(nil)
+
+// NewLoggingService returns a dbrp mapping service logging the calls to next.
+func NewLoggingService(log *zap.Logger, next influxdb.DBRPMappingService) *LoggingService {
+	return &LoggingService{
+		logger: log,
+		next:   next,
+	}
+}
+
+func (l *LoggingService) FindBy(ctx context.Context, cluster, db, rp string) (m *influxdb.DBRPMapping, err error) {
+	defer func(start time.Time) {
+		fields := append(keyFields(cluster, db, rp), zap.Duration("took", time.Since(start)))
+		if err != nil {
+			l.logLookupError("failed to find dbrp mapping", err, fields...)
+			return
+		}
+		l.logger.Debug("dbrp mapping find by key", append(fields, zap.Stringer("bucketID", m.BucketID))...)
+	}(time.Now())
+	return l.next.FindBy(ctx, cluster, db, rp)
+}
+
+func (l *LoggingService) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (m *influxdb.DBRPMapping, err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logLookupError("failed to find dbrp mapping matching the given filter", err, dur)
+			return
+		}
+		l.logger.Debug("dbrp mapping find", append(mappingFields(m), dur)...)
+	}(time.Now())
+	return l.next.Find(ctx, filter)
+}
+
+func (l *LoggingService) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) (ms []*influxdb.DBRPMapping, n int, err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Error("failed to find dbrp mappings matching the given filter", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("dbrp mappings find", zap.Int("count", n), dur)
+	}(time.Now())
+	return l.next.FindMany(ctx, filter, opt...)
+}
+
+func (l *LoggingService) Create(ctx context.Context, m *influxdb.DBRPMapping) (err error) {
+	defer func(start time.Time) {
+		l.logMutation(ctx, "create", m, err, start)
+	}(time.Now())
+	return l.next.Create(ctx, m)
+}
+
+func (l *LoggingService) Upsert(ctx context.Context, m *influxdb.DBRPMapping) (err error) {
+	defer func(start time.Time) {
+		l.logMutation(ctx, "upsert", m, err, start)
+	}(time.Now())
+	return l.next.Upsert(ctx, m)
+}
+
+func (l *LoggingService) Replace(ctx context.Context, m *influxdb.DBRPMapping, revision string) (err error) {
+	defer func(start time.Time) {
+		l.logMutation(ctx, "replace", m, err, start)
+	}(time.Now())
+	return l.next.Replace(ctx, m, revision)
+}
+
+func (l *LoggingService) SetDefault(ctx context.Context, cluster, db, rp string) (m *influxdb.DBRPMapping, err error) {
+	defer func(start time.Time) {
+		logged := m
+		if logged == nil {
+			logged = &influxdb.DBRPMapping{Cluster: cluster, Database: db, RetentionPolicy: rp}
+		}
+		l.logMutation(ctx, "set default", logged, err, start)
+	}(time.Now())
+	return l.next.SetDefault(ctx, cluster, db, rp)
+}
+
+func (l *LoggingService) Delete(ctx context.Context, cluster, db, rp string) (err error) {
+	defer func(start time.Time) {
+		l.logMutation(ctx, "delete", &influxdb.DBRPMapping{Cluster: cluster, Database: db, RetentionPolicy: rp}, err, start)
+	}(time.Now())
+	return l.next.Delete(ctx, cluster, db, rp)
+}
+
+// logLookupError logs the failed lookup at error level, unless the mapping
+// was not found, which the 1.x endpoints often expect.
+func (l *LoggingService) logLookupError(msg string, err error, fields ...zap.Field) {
+	fields = append(fields, zap.Error(err))
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		l.logger.Debug(msg, fields...)
+		return
+	}
+	l.logger.Error(msg, fields...)
+}
+
+// logMutation logs the operation on the mapping at info level, along with the
+// user and authorization which made it, or at error level if it failed.
+func (l *LoggingService) logMutation(ctx context.Context, op string, m *influxdb.DBRPMapping, err error, start time.Time) {
+	fields := append(mappingFields(m), zap.Duration("took", time.Since(start)))
+	if a, aerr := icontext.GetAuthorizer(ctx); aerr == nil {
+		fields = append(fields,
+			zap.Stringer("userID", a.GetUserID()),
+			zap.Stringer("authorizerID", a.Identifier()),
+			zap.String("authorizerKind", a.Kind()))
+	}
+	if err != nil {
+		l.logger.Error("failed to "+op+" dbrp mapping", append(fields, zap.Error(err))...)
+		return
+	}
+	l.logger.Info("dbrp mapping "+op, fields...)
+}
+
+func keyFields(cluster, db, rp string) []zap.Field {
+	return []zap.Field{
+		zap.String("cluster", cluster),
+		zap.String("database", db),
+		zap.String("retentionPolicy", rp),
+	}
+}
+
+func mappingFields(m *influxdb.DBRPMapping) []zap.Field {
+	fields := keyFields(m.Cluster, m.Database, m.RetentionPolicy)
+	if m.OrganizationID.Valid() {
+		fields = append(fields, zap.Stringer("orgID", m.OrganizationID))
+	}
+	if m.BucketID.Valid() {
+		fields = append(fields,
+			zap.Stringer("bucketID", m.BucketID),
+			zap.Bool("default", m.Default))
+	}
+	return fields
+}
@@ -0,0 +1,75 @@
+package dbrp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func initDBRPMappingLoggingService(f influxdbtesting.DBRPMappingFields, t *testing.T) (influxdb.DBRPMappingService, func()) {
+	s, closer := initDBRPMappingService(f, t)
+	return dbrp.NewLoggingService(zaptest.NewLogger(t), s), closer
+}
+
+func TestDBRPMappingLoggingService(t *testing.T) {
+	influxdbtesting.CreateDBRPMapping(initDBRPMappingLoggingService, t)
+	influxdbtesting.FindDBRPMappingByKey(initDBRPMappingLoggingService, t)
+	influxdbtesting.SetDefaultDBRPMapping(initDBRPMappingLoggingService, t)
+	influxdbtesting.DeleteDBRPMapping(initDBRPMappingLoggingService, t)
+}
+
+func TestDBRPMappingLoggingService_Mutations(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	s := dbrp.NewLoggingService(zap.New(core), &mock.DBRPMappingService{
+		CreateFn: func(ctx context.Context, m *influxdb.DBRPMapping) error {
+			return nil
+		},
+		FindByFn: func(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound}
+		},
+	})
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{ID: 3, UserID: 4})
+	if err := s.Create(ctx, &influxdb.DBRPMapping{
+		Cluster:         "c",
+		Database:        "db",
+		RetentionPolicy: "rp",
+		OrganizationID:  1,
+		BucketID:        2,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindBy(ctx, "c", "db", "other"); err == nil {
+		t.Fatal("expected the mapping not to be found")
+	}
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+	created := entries[0]
+	if created.Level != zap.InfoLevel || created.Message != "dbrp mapping create" {
+		t.Errorf("unexpected creation log: %s %q", created.Level, created.Message)
+	}
+	fields := created.ContextMap()
+	for k, want := range map[string]interface{}{
+		"database": "db",
+		"bucketID": influxdb.ID(2).String(),
+		"userID":   influxdb.ID(4).String(),
+	} {
+		if got := fields[k]; got != want {
+			t.Errorf("got %s=%v, want %v", k, got, want)
+		}
+	}
+	if entries[1].Level != zap.DebugLevel {
+		t.Errorf("expected a mapping not found to be logged at debug level, got %s", entries[1].Level)
+	}
+}
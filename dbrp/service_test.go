@@ -0,0 +1,350 @@
+package dbrp_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func initDBRPMappingService(f influxdbtesting.DBRPMappingFields, t *testing.T) (influxdb.DBRPMappingService, func()) {
+	s, err := dbrp.NewService(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Populate(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+	return s, func() {}
+}
+
+func TestDBRPMappingService_CreateDBRPMapping(t *testing.T) {
+	influxdbtesting.CreateDBRPMapping(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_FindDBRPMappingByKey(t *testing.T) {
+	influxdbtesting.FindDBRPMappingByKey(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_FindDBRPMappings(t *testing.T) {
+	influxdbtesting.FindDBRPMappings(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_DeleteDBRPMapping(t *testing.T) {
+	influxdbtesting.DeleteDBRPMapping(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_UpsertDBRPMapping(t *testing.T) {
+	influxdbtesting.UpsertDBRPMapping(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_SetDefaultDBRPMapping(t *testing.T) {
+	influxdbtesting.SetDefaultDBRPMapping(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_ReplaceDBRPMapping(t *testing.T) {
+	influxdbtesting.ReplaceDBRPMapping(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_FindDBRPMapping(t *testing.T) {
+	influxdbtesting.FindDBRPMapping(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_CreateChecksBucket(t *testing.T) {
+	ctx := context.Background()
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		switch id {
+		case 10:
+			return &influxdb.Bucket{ID: id, OrgID: 1, RetentionPeriod: 30 * 24 * time.Hour}, nil
+		case 11:
+			return &influxdb.Bucket{ID: id, OrgID: 2}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	core, logs := observer.New(zap.WarnLevel)
+	s, err := dbrp.NewService(inmem.NewKVStore(), dbrp.WithBucketService(buckets), dbrp.WithLogger(zap.New(core)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &influxdb.DBRPMapping{Cluster: "c", Database: "db", RetentionPolicy: "autogen", OrganizationID: 1, BucketID: 12}
+	if err := s.Create(ctx, m); err != dbrp.ErrBucketNotFound {
+		t.Errorf("expected a mapping to a missing bucket to be rejected, got %v", err)
+	}
+	m.BucketID = 11
+	if err := s.Create(ctx, m); err != dbrp.ErrBucketOtherOrg {
+		t.Errorf("expected a mapping to a bucket of another organization to be rejected, got %v", err)
+	}
+	if err := s.Upsert(ctx, m); err != dbrp.ErrBucketOtherOrg {
+		t.Errorf("expected upserting a mapping to a bucket of another organization to be rejected, got %v", err)
+	}
+
+	m.BucketID = 10
+	if err := s.Create(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	if n := logs.Len(); n != 0 {
+		t.Errorf("expected no warning for a retention policy not named for a duration, got %d", n)
+	}
+	if err := s.Create(ctx, &influxdb.DBRPMapping{Cluster: "c", Database: "db", RetentionPolicy: "1w", OrganizationID: 1, BucketID: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if n := logs.Len(); n != 1 {
+		t.Errorf("expected a warning for a 1w retention policy mapped to a 30 day bucket, got %d", n)
+	}
+}
+
+func TestDBRPMappingService_FindManyPaging(t *testing.T) {
+	ctx := context.Background()
+	s, err := dbrp.NewService(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*influxdb.DBRPMapping{
+		{Cluster: "c", Database: "a", RetentionPolicy: "autogen", OrganizationID: 1, BucketID: 10},
+		{Cluster: "c", Database: "b", RetentionPolicy: "autogen", OrganizationID: 1, BucketID: 11},
+		{Cluster: "c", Database: "c", RetentionPolicy: "autogen", OrganizationID: 1, BucketID: 12},
+		{Cluster: "c", Database: "d", RetentionPolicy: "autogen", OrganizationID: 2, BucketID: 13},
+	} {
+		if err := s.Create(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orgID := influxdb.ID(1)
+	filter := influxdb.DBRPMappingFilter{OrganizationID: &orgID}
+	for _, tt := range []struct {
+		name string
+		opts influxdb.FindOptions
+		want []string
+	}{
+		{name: "first page", opts: influxdb.FindOptions{Limit: 2}, want: []string{"a", "b"}},
+		{name: "last page", opts: influxdb.FindOptions{Limit: 2, Offset: 2}, want: []string{"c"}},
+		{name: "past the end", opts: influxdb.FindOptions{Limit: 2, Offset: 4}, want: []string{}},
+		{name: "descending", opts: influxdb.FindOptions{Limit: 2, SortBy: "Database", Descending: true}, want: []string{"c", "b"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, n, err := s.FindMany(ctx, filter, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != 3 {
+				t.Errorf("got total %d, want 3", n)
+			}
+			got := []string{}
+			for _, m := range ms {
+				got = append(got, m.Database)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got databases %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDBRPMappingService_FindManySorting(t *testing.T) {
+	ctx := context.Background()
+	s, err := dbrp.NewService(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*influxdb.DBRPMapping{
+		{Cluster: "c", Database: "a", RetentionPolicy: "b", OrganizationID: 1, BucketID: 11},
+		{Cluster: "c", Database: "b", RetentionPolicy: "c", OrganizationID: 1, BucketID: 10},
+		{Cluster: "c", Database: "c", RetentionPolicy: "a", OrganizationID: 1, BucketID: 12},
+	} {
+		if err := s.Create(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orgID := influxdb.ID(1)
+	filter := influxdb.DBRPMappingFilter{OrganizationID: &orgID}
+	for _, tt := range []struct {
+		name string
+		opts influxdb.FindOptions
+		want []string
+	}{
+		{name: "default", opts: influxdb.FindOptions{}, want: []string{"a", "b", "c"}},
+		{name: "database descending", opts: influxdb.FindOptions{SortBy: influxdb.DBRPSortByDatabase, Descending: true}, want: []string{"c", "b", "a"}},
+		{name: "retention policy", opts: influxdb.FindOptions{SortBy: influxdb.DBRPSortByRetentionPolicy}, want: []string{"c", "a", "b"}},
+		{name: "bucket ID", opts: influxdb.FindOptions{SortBy: influxdb.DBRPSortByBucketID}, want: []string{"b", "a", "c"}},
+		{name: "bucket ID descending page", opts: influxdb.FindOptions{SortBy: influxdb.DBRPSortByBucketID, Descending: true, Limit: 2}, want: []string{"c", "a"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, _, err := s.FindMany(ctx, filter, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, m := range ms {
+				got = append(got, m.Database)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got databases %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, _, err = s.FindMany(ctx, filter, influxdb.FindOptions{SortBy: "org"})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an invalid sort to be rejected, got %v", err)
+	}
+}
+
+func TestDBRPMappingService_DeleteBucketMappings(t *testing.T) {
+	ctx := context.Background()
+	// The bolt store rolls back the transactions which fail.
+	f, err := ioutil.TempFile("", "influxdata-bolt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	store := bolt.NewKVStore(zaptest.NewLogger(t), f.Name())
+	if err := store.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	buckets := kv.NewService(zaptest.NewLogger(t), store)
+	if err := buckets.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s, err := dbrp.NewService(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buckets.AddBucketDeleteHook(s.DeleteBucketMappings)
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := buckets.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	deleted := &influxdb.Bucket{OrgID: org.ID, Name: "db/autogen"}
+	kept := &influxdb.Bucket{OrgID: org.ID, Name: "db/weekly"}
+	for _, b := range []*influxdb.Bucket{deleted, kept} {
+		if err := buckets.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, m := range []*influxdb.DBRPMapping{
+		{Cluster: "c", Database: "db", RetentionPolicy: "autogen", Default: true, OrganizationID: org.ID, BucketID: deleted.ID},
+		{Cluster: "other", Database: "db", RetentionPolicy: "autogen", OrganizationID: org.ID, BucketID: deleted.ID},
+		{Cluster: "c", Database: "db", RetentionPolicy: "weekly", OrganizationID: org.ID, BucketID: kept.ID},
+	} {
+		if err := s.Create(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var events []dbrp.Event
+	s.Subscribe(dbrp.EventListenerFunc(func(ctx context.Context, e dbrp.Event) {
+		events = append(events, e)
+	}))
+
+	// A delete which is rolled back deletes no mappings, and notifies
+	// nothing.
+	fail := true
+	buckets.AddBucketDeleteHook(func(ctx context.Context, tx kv.Tx, b *influxdb.Bucket) (func(), error) {
+		if fail {
+			return nil, errors.New("bucket delete failed")
+		}
+		return nil, nil
+	})
+	if err := buckets.DeleteBucket(ctx, deleted.ID); err == nil {
+		t.Fatal("expected the bucket delete to fail")
+	}
+	if _, n, err := s.FindMany(ctx, influxdb.DBRPMappingFilter{}); err != nil || n != 3 {
+		t.Fatalf("expected the mappings to be kept, got %d: %v", n, err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events of a rolled back delete, got %+v", events)
+	}
+
+	fail = false
+	if err := buckets.DeleteBucket(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	ms, n, err := s.FindMany(ctx, influxdb.DBRPMappingFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || ms[0].BucketID != kept.ID {
+		t.Fatalf("expected only the mapping to the bucket kept, got %+v", ms)
+	}
+	if len(events) != 2 || events[0].Type != dbrp.EventDeleted || events[1].Type != dbrp.EventDeleted {
+		t.Fatalf("expected the deletes of the 2 mappings to the bucket, got %+v", events)
+	}
+}
+
+func TestDBRPMappingService_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	s, err := dbrp.NewService(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []dbrp.Event
+	unsubscribe := s.Subscribe(dbrp.EventListenerFunc(func(ctx context.Context, e dbrp.Event) {
+		got = append(got, e)
+	}))
+
+	autogen := influxdb.DBRPMapping{Cluster: "c", Database: "db", RetentionPolicy: "autogen", Default: true, OrganizationID: 1, BucketID: 10}
+	weekly := influxdb.DBRPMapping{Cluster: "c", Database: "db", RetentionPolicy: "weekly", Default: true, OrganizationID: 1, BucketID: 11}
+
+	m := autogen
+	if err := s.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+	// Creating an identical mapping changes nothing.
+	m = autogen
+	if err := s.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+	m = weekly
+	if err := s.Upsert(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "c", "db", "autogen"); err != nil {
+		t.Fatal(err)
+	}
+	// Deleting a missing mapping changes nothing.
+	if err := s.Delete(ctx, "c", "db", "autogen"); err != nil {
+		t.Fatal(err)
+	}
+
+	unsubscribe()
+	m = autogen
+	if err := s.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	cleared := autogen
+	cleared.Default = false
+	want := []dbrp.Event{
+		{Type: dbrp.EventCreated, Mapping: autogen},
+		{Type: dbrp.EventUpdated, Mapping: cleared},
+		{Type: dbrp.EventCreated, Mapping: weekly},
+		{Type: dbrp.EventDeleted, Mapping: cleared},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected events:\ngot  %+v\nwant %+v", got, want)
+	}
+}
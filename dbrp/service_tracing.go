@@ -0,0 +1,168 @@
+package dbrp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/opentracing/opentracing-go"
+)
+
+// TracingService traces the calls to a dbrp mapping service, tagging the spans
+// with the organization, cluster, database and retention policy of the mappings
+// so that slow lookups of the 1.x query and write paths show in traces.
+type TracingService struct {
+	next influxdb.DBRPMappingService
+}
+
+var _ influxdb.DBRPMappingService = (*TracingService)(nil)
+
+// NewTracingService returns a dbrp mapping service tracing the calls to next.
+func NewTracingService(next influxdb.DBRPMappingService) *TracingService {
+	return &TracingService{next: next}
+}
+
+func (s *TracingService) FindBy(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, "DBRPMappingService.FindBy")
+	defer span.Finish()
+	setKeyTags(span, cluster, db, rp)
+
+	m, err := s.next.FindBy(ctx, cluster, db, rp)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	setMappingTags(span, m)
+	return m, nil
+}
+
+func (s *TracingService) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, "DBRPMappingService.Find")
+	defer span.Finish()
+	setFilterTags(span, filter)
+
+	m, err := s.next.Find(ctx, filter)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	setMappingTags(span, m)
+	return m, nil
+}
+
+func (s *TracingService) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, "DBRPMappingService.FindMany")
+	defer span.Finish()
+	setFilterTags(span, filter)
+
+	ms, n, err := s.next.FindMany(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, tracing.LogError(span, err)
+	}
+	span.LogKV("num_mappings", n)
+	return ms, n, nil
+}
+
+func (s *TracingService) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, "DBRPMappingService.Create")
+	defer span.Finish()
+	setMappingTags(span, m)
+
+	if err := s.next.Create(ctx, m); err != nil {
+		return tracing.LogError(span, err)
+	}
+	return nil
+}
+
+func (s *TracingService) Upsert(ctx context.Context, m *influxdb.DBRPMapping) error {
+	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, "DBRPMappingService.Upsert")
+	defer span.Finish()
+	setMappingTags(span, m)
+
+	if err := s.next.Upsert(ctx, m); err != nil {
+		return tracing.LogError(span, err)
+	}
+	return nil
+}
+
+func (s *TracingService) Replace(ctx context.Context, m *influxdb.DBRPMapping, revision string) error {
+	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, "DBRPMappingService.Replace")
+	defer span.Finish()
+	setMappingTags(span, m)
+	span.LogKV("revision", revision)
+
+	if err := s.next.Replace(ctx, m, revision); err != nil {
+		return tracing.LogError(span, err)
+	}
+	return nil
+}
+
+func (s *TracingService) SetDefault(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, "DBRPMappingService.SetDefault")
+	defer span.Finish()
+	setKeyTags(span, cluster, db, rp)
+
+	m, err := s.next.SetDefault(ctx, cluster, db, rp)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	setMappingTags(span, m)
+	return m, nil
+}
+
+func (s *TracingService) Delete(ctx context.Context, cluster, db, rp string) error {
+	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, "DBRPMappingService.Delete")
+	defer span.Finish()
+	setKeyTags(span, cluster, db, rp)
+
+	if err := s.next.Delete(ctx, cluster, db, rp); err != nil {
+		return tracing.LogError(span, err)
+	}
+	return nil
+}
+
+func setKeyTags(span opentracing.Span, cluster, db, rp string) {
+	span.SetTag("cluster", cluster)
+	span.SetTag("db", db)
+	span.SetTag("rp", rp)
+}
+
+func setFilterTags(span opentracing.Span, filter influxdb.DBRPMappingFilter) {
+	if filter.OrganizationID != nil {
+		span.SetTag("org_id", filter.OrganizationID.String())
+	}
+	if filter.Cluster != nil {
+		span.SetTag("cluster", *filter.Cluster)
+	}
+	if filter.Database != nil {
+		span.SetTag("db", *filter.Database)
+	}
+	if filter.RetentionPolicy != nil {
+		span.SetTag("rp", *filter.RetentionPolicy)
+	}
+	if filter.Default != nil {
+		span.SetTag("default", *filter.Default)
+	}
+	if len(filter.BucketIDs) > 0 {
+		ids := make([]string, 0, len(filter.BucketIDs))
+		for _, id := range filter.BucketIDs {
+			ids = append(ids, id.String())
+		}
+		span.SetTag("bucket_ids", strings.Join(ids, ","))
+	}
+	if filter.DatabasePrefix != nil {
+		span.SetTag("db_prefix", *filter.DatabasePrefix)
+	}
+	if filter.DatabasePattern != nil {
+		span.SetTag("db_pattern", *filter.DatabasePattern)
+	}
+}
+
+func setMappingTags(span opentracing.Span, m *influxdb.DBRPMapping) {
+	if m == nil {
+		return
+	}
+	setKeyTags(span, m.Cluster, m.Database, m.RetentionPolicy)
+	span.SetTag("org_id", m.OrganizationID.String())
+	span.SetTag("bucket_id", m.BucketID.String())
+	span.SetTag("default", m.Default)
+}
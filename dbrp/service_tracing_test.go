@@ -0,0 +1,53 @@
+package dbrp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestTracingService_FindBy(t *testing.T) {
+	tracer := mocktracer.New()
+	oldTracer := opentracing.GlobalTracer()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(oldTracer)
+
+	svc := dbrp.NewTracingService(&mock.DBRPMappingService{
+		FindByFn: func(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+			return &influxdb.DBRPMapping{
+				Cluster:         cluster,
+				Database:        db,
+				RetentionPolicy: rp,
+				OrganizationID:  influxdb.ID(1),
+				BucketID:        influxdb.ID(2),
+			}, nil
+		},
+	})
+	if _, err := svc.FindBy(context.Background(), "c", "db", "rp"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := tracer.FinishedSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if got, want := spans[0].OperationName, "DBRPMappingService.FindBy"; got != want {
+		t.Errorf("got operation %q, want %q", got, want)
+	}
+	for k, want := range map[string]interface{}{
+		"cluster":   "c",
+		"db":        "db",
+		"rp":        "rp",
+		"org_id":    influxdb.ID(1).String(),
+		"bucket_id": influxdb.ID(2).String(),
+	} {
+		if got := spans[0].Tag(k); got != want {
+			t.Errorf("got tag %s=%v, want %v", k, got, want)
+		}
+	}
+}
@@ -2,6 +2,12 @@ package influxdb
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -14,14 +20,34 @@ type DBRPMappingService interface {
 	// Find returns the first dbrp mapping the matches the filter.
 	Find(ctx context.Context, filter DBRPMappingFilter) (*DBRPMapping, error)
 	// FindMany returns a list of dbrp mappings that match filter and the total count of matching dbrp mappings.
+	// The count is of all of the matching mappings, regardless of the offset and limit of opt.
 	FindMany(ctx context.Context, filter DBRPMappingFilter, opt ...FindOptions) ([]*DBRPMapping, int, error)
 	// Create creates a new dbrp mapping, if a different mapping exists an error is returned.
+	// Creating a default mapping clears the default of the other mappings of its organization, cluster and db.
 	Create(ctx context.Context, dbrpMap *DBRPMapping) error
+	// Upsert creates a dbrp mapping, or replaces the mapping of its cluster, db and rp.
+	// The mapping of another organization is not replaced, and an error is returned.
+	// Upserting a default mapping clears the default of the other mappings of its organization, cluster and db.
+	Upsert(ctx context.Context, dbrpMap *DBRPMapping) error
+	// Replace replaces the dbrp mapping of the cluster, db and rp of dbrpMap only if its revision is revision,
+	// so that concurrent updates of a mapping do not overwrite each other. Otherwise an EPreconditionFailed error is returned.
+	// Replacing a mapping as the default clears the default of the other mappings of its organization, cluster and db.
+	Replace(ctx context.Context, dbrpMap *DBRPMapping, revision string) error
+	// SetDefault makes the dbrp mapping for the cluster, db and rp the default for its cluster and db,
+	// clearing the default of the other mappings of its organization, cluster and db, and returns it.
+	SetDefault(ctx context.Context, cluster, db, rp string) (*DBRPMapping, error)
 	// Delete removes a dbrp mapping.
 	// Deleting a mapping that does not exists is not an error.
 	Delete(ctx context.Context, cluster, db, rp string) error
 }
 
+// ErrDBRPMappingChanged is returned when replacing a dbrp mapping which was
+// changed or deleted since the revision it was read at.
+var ErrDBRPMappingChanged = &Error{
+	Code: EPreconditionFailed,
+	Msg:  "dbrp mapping changed since it was read",
+}
+
 // DBRPMapping represents a mapping of a cluster, database and retention policy to an organization ID and bucket ID.
 type DBRPMapping struct {
 	Cluster         string `json:"cluster"`
@@ -35,6 +61,14 @@ type DBRPMapping struct {
 	BucketID       ID `json:"bucket_id"`
 }
 
+// Revision returns the revision of the mapping, which changes whenever any
+// of its fields change.
+func (m *DBRPMapping) Revision() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%t\x00%s\x00%s", m.Cluster, m.Database, m.RetentionPolicy, m.Default, m.OrganizationID, m.BucketID)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
 // Validate reports any validation errors for the mapping.
 func (m DBRPMapping) Validate() error {
 	if !validName(m.Cluster) {
@@ -109,6 +143,89 @@ type DBRPMappingFilter struct {
 	Database        *string
 	RetentionPolicy *string
 	Default         *bool
+	OrganizationID  *ID
+	// BucketIDs matches the mappings to any of the buckets, or to any
+	// bucket when empty.
+	BucketIDs []ID
+	// DatabasePrefix matches the mappings of the databases beginning with
+	// it, and DatabasePattern those of the databases matching the glob
+	// pattern, in the syntax of path.Match.
+	DatabasePrefix  *string
+	DatabasePattern *string
+}
+
+// Validate returns an error if the DatabasePattern of f is malformed.
+func (f DBRPMappingFilter) Validate() error {
+	if f.DatabasePattern != nil {
+		if _, err := path.Match(*f.DatabasePattern, ""); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "invalid database pattern",
+				Err:  err,
+			}
+		}
+	}
+	return nil
+}
+
+// MatchesDatabase returns whether the mappings of the database match the
+// Database, DatabasePrefix and DatabasePattern of f.
+func (f DBRPMappingFilter) MatchesDatabase(db string) bool {
+	if f.Database != nil && *f.Database != db {
+		return false
+	}
+	if f.DatabasePrefix != nil && !strings.HasPrefix(db, *f.DatabasePrefix) {
+		return false
+	}
+	if f.DatabasePattern != nil {
+		if ok, _ := path.Match(*f.DatabasePattern, db); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesBucket returns whether the mappings to the bucket match f.
+func (f DBRPMappingFilter) MatchesBucket(bucketID ID) bool {
+	if len(f.BucketIDs) == 0 {
+		return true
+	}
+	for _, id := range f.BucketIDs {
+		if id == bucketID {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryParams returns a map containing url query params.
+func (f DBRPMappingFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{}
+	if f.Cluster != nil {
+		qp["cluster"] = []string{*f.Cluster}
+	}
+	if f.Database != nil {
+		qp["db"] = []string{*f.Database}
+	}
+	if f.RetentionPolicy != nil {
+		qp["rp"] = []string{*f.RetentionPolicy}
+	}
+	if f.Default != nil {
+		qp["default"] = []string{strconv.FormatBool(*f.Default)}
+	}
+	if f.OrganizationID != nil {
+		qp["orgID"] = []string{f.OrganizationID.String()}
+	}
+	for _, id := range f.BucketIDs {
+		qp["bucketID"] = append(qp["bucketID"], id.String())
+	}
+	if f.DatabasePrefix != nil {
+		qp["dbPrefix"] = []string{*f.DatabasePrefix}
+	}
+	if f.DatabasePattern != nil {
+		qp["dbPattern"] = []string{*f.DatabasePattern}
+	}
+	return qp
 }
 
 func (f DBRPMappingFilter) String() string {
@@ -141,6 +258,181 @@ func (f DBRPMappingFilter) String() string {
 	} else {
 		s.WriteString("<nil>")
 	}
+
+	s.WriteString(" org:")
+	if f.OrganizationID != nil {
+		s.WriteString(f.OrganizationID.String())
+	} else {
+		s.WriteString("<nil>")
+	}
+
+	if len(f.BucketIDs) > 0 {
+		s.WriteString(" buckets:")
+		for i, id := range f.BucketIDs {
+			if i > 0 {
+				s.WriteString(",")
+			}
+			s.WriteString(id.String())
+		}
+	}
+	if f.DatabasePrefix != nil {
+		s.WriteString(" dbPrefix:")
+		s.WriteString(*f.DatabasePrefix)
+	}
+	if f.DatabasePattern != nil {
+		s.WriteString(" dbPattern:")
+		s.WriteString(*f.DatabasePattern)
+	}
 	s.WriteString("}")
 	return s.String()
 }
+
+// The fields dbrp mappings may be sorted by. DBRPSortByDatabase and
+// DBRPSortByRetentionPolicy may also be given as "Database" and
+// "RetentionPolicy".
+const (
+	DBRPSortByDatabase        = "database"
+	DBRPSortByRetentionPolicy = "retention_policy"
+	DBRPSortByBucketID        = "bucketID"
+)
+
+// ValidateDBRPSort returns an error if the mappings can't be sorted by the
+// field of opts.
+func ValidateDBRPSort(opts FindOptions) error {
+	switch opts.SortBy {
+	case "", DBRPSortByDatabase, "Database", DBRPSortByRetentionPolicy, "RetentionPolicy", DBRPSortByBucketID:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("dbrp mappings can't be sorted by %q, sortBy must be one of %s, %s or %s", opts.SortBy, DBRPSortByDatabase, DBRPSortByRetentionPolicy, DBRPSortByBucketID),
+	}
+}
+
+// SortDBRPMappings sorts a slice of dbrp mappings by a field. They are
+// sorted by cluster, database and retention policy by default, and ties are
+// broken in that order.
+func SortDBRPMappings(opts FindOptions, ms []*DBRPMapping) {
+	key := func(m *DBRPMapping) [4]string {
+		switch opts.SortBy {
+		case DBRPSortByDatabase, "Database":
+			return [4]string{m.Database, m.Cluster, m.RetentionPolicy}
+		case DBRPSortByRetentionPolicy, "RetentionPolicy":
+			return [4]string{m.RetentionPolicy, m.Cluster, m.Database}
+		case DBRPSortByBucketID:
+			// IDs are encoded with a fixed width, so they sort as numbers.
+			return [4]string{m.BucketID.String(), m.Cluster, m.Database, m.RetentionPolicy}
+		default:
+			return [4]string{m.Cluster, m.Database, m.RetentionPolicy}
+		}
+	}
+	less := func(a, b [4]string) bool {
+		for i := range a {
+			if a[i] != b[i] {
+				return a[i] < b[i]
+			}
+		}
+		return false
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if opts.Descending {
+			return less(key(ms[j]), key(ms[i]))
+		}
+		return less(key(ms[i]), key(ms[j]))
+	})
+}
+
+// PageDBRPMappings returns the page of a slice of sorted dbrp mappings
+// selected by the offset and limit of opts.
+func PageDBRPMappings(opts FindOptions, ms []*DBRPMapping) []*DBRPMapping {
+	if opts.Offset >= len(ms) {
+		return []*DBRPMapping{}
+	}
+	if opts.Offset > 0 {
+		ms = ms[opts.Offset:]
+	}
+	if opts.Limit > 0 && opts.Limit < len(ms) {
+		ms = ms[:opts.Limit]
+	}
+	return ms
+}
+
+// DBRPImportService imports the DBRP mappings of the databases and retention
+// policies of an InfluxDB 1.x server, and exports them as portable packages.
+type DBRPImportService interface {
+	// ImportDBRPMappings maps the databases and retention policies of the
+	// meta.db of a 1.x server to the buckets of an organization.
+	ImportDBRPMappings(ctx context.Context, orgID ID, meta io.Reader, opts DBRPImportOptions) (*DBRPImportResult, error)
+
+	// ImportDBRPPackage maps the databases and retention policies of a
+	// package exported by ExportDBRPMappings to the buckets of an
+	// organization.
+	ImportDBRPPackage(ctx context.Context, orgID ID, pkg *DBRPPackage, opts DBRPImportOptions) (*DBRPImportResult, error)
+
+	// ExportDBRPMappings returns the mappings to the buckets of an
+	// organization as a package.
+	ExportDBRPMappings(ctx context.Context, orgID ID) (*DBRPPackage, error)
+}
+
+// DBRPPackageVersion is the version of the DBRP packages exported.
+const DBRPPackageVersion = 1
+
+// DBRPPackage is a portable document of the DBRP mappings of an
+// organization. It refers to buckets by name, so that it may be imported
+// on another instance.
+type DBRPPackage struct {
+	Version  int                  `json:"version" yaml:"version"`
+	Mappings []DBRPPackageMapping `json:"mappings" yaml:"mappings"`
+}
+
+// DBRPPackageMapping is a mapping of a DBRP package.
+type DBRPPackageMapping struct {
+	Cluster         string `json:"cluster" yaml:"cluster"`
+	Database        string `json:"database" yaml:"database"`
+	RetentionPolicy string `json:"retention_policy" yaml:"retention_policy"`
+	Default         bool   `json:"default" yaml:"default"`
+	Bucket          string `json:"bucket" yaml:"bucket"`
+}
+
+// Valid returns an error if the package is not of a supported version.
+func (p *DBRPPackage) Valid() error {
+	if p.Version != DBRPPackageVersion {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "unsupported dbrp package version " + strconv.Itoa(p.Version),
+		}
+	}
+	return nil
+}
+
+// DBRPImportOptions are the options of an import of DBRP mappings.
+type DBRPImportOptions struct {
+	// Cluster is the cluster of the mappings imported. If empty, it is the
+	// cluster of the mappings of a package, or else DefaultDBRPCluster.
+	Cluster string
+	// CreateBuckets creates the buckets, named database/retention policy,
+	// of the retention policies without one. The retention policies
+	// without a bucket are skipped otherwise.
+	CreateBuckets bool
+}
+
+// DefaultDBRPCluster is the cluster of the DBRP mappings imported without
+// one.
+const DefaultDBRPCluster = "default"
+
+// DBRPImportResult is the result of an import of DBRP mappings.
+type DBRPImportResult struct {
+	// Mappings are the mappings created or already existing.
+	Mappings []*DBRPMapping `json:"mappings"`
+	// Buckets are the buckets created.
+	Buckets []*Bucket `json:"buckets"`
+	// Skipped are the retention policies not mapped.
+	Skipped []DBRPImportSkip `json:"skipped"`
+}
+
+// DBRPImportSkip is a retention policy not mapped by an import, and why.
+type DBRPImportSkip struct {
+	Database        string `json:"database"`
+	RetentionPolicy string `json:"retention_policy"`
+	Reason          string `json:"reason"`
+}
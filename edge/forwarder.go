@@ -0,0 +1,436 @@
+// Package edge implements store-and-forward of writes from an edge instance
+// to a central instance.
+package edge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxQueueSize is the default maximum size of the forward queue in bytes.
+	DefaultMaxQueueSize = 1 << 30
+
+	// DefaultMaxBatchSize is the default maximum size in bytes of a batch
+	// forwarded to the central instance.
+	DefaultMaxBatchSize = 1 << 20
+
+	// DefaultFlushInterval is the default interval at which queued writes
+	// are forwarded.
+	DefaultFlushInterval = time.Second
+
+	minBatchSize = 1 << 10
+	segmentSize  = 8 << 20
+
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// Config configures a Forwarder.
+type Config struct {
+	// URL is the address of the central instance.
+	URL string
+
+	// Token authorizes writes to the central instance.
+	Token string
+
+	// Org is the name of the organization on the central instance that owns
+	// the buckets writes are forwarded to. Points are written to the bucket
+	// with the same name as the local bucket they were written to.
+	Org string
+
+	// InsecureSkipVerify skips verification of the central instance's TLS
+	// certificate.
+	InsecureSkipVerify bool
+
+	// QueuePath is the directory holding writes waiting to be forwarded.
+	QueuePath string
+
+	// MaxQueueSize is the maximum size of the queue in bytes. Writes are
+	// rejected while the queue is full. A value of zero means the queue is
+	// unbounded.
+	MaxQueueSize int64
+
+	// MaxBatchSize is the maximum size in bytes of a forwarded batch.
+	MaxBatchSize int
+
+	// FlushInterval is how often queued writes are forwarded.
+	FlushInterval time.Duration
+}
+
+// BucketFinder finds the buckets points were written to.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// Forwarder is a storage.PointsWriter which writes points locally and queues
+// them on disk to be forwarded to a central instance.
+//
+// Points are forwarded in batches which shrink when the central instance
+// rejects them as too large and grow back as batches succeed. While the
+// central instance is unreachable or asks to slow down, forwarding backs off
+// and points accumulate in the queue. Once the queue is full, writes are
+// rejected so that producers slow down rather than lose data.
+//
+// Delivery is at least once: a batch may be forwarded again if the forwarder
+// stops before its segment of the queue is removed. Points written to the
+// system buckets of an organization are not forwarded.
+type Forwarder struct {
+	log     *zap.Logger
+	config  Config
+	writer  storage.PointsWriter
+	buckets BucketFinder
+	client  *http.Client
+
+	queue     *queue
+	batchSize int
+	notify    chan struct{}
+	metrics   *forwarderMetrics
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ storage.PointsWriter = (*Forwarder)(nil)
+
+// NewForwarder returns a Forwarder which writes points to w and forwards
+// them according to config. It must be opened before use.
+func NewForwarder(log *zap.Logger, config Config, w storage.PointsWriter, buckets BucketFinder) *Forwarder {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = DefaultMaxBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	return &Forwarder{
+		log:       log,
+		config:    config,
+		writer:    w,
+		buckets:   buckets,
+		batchSize: config.MaxBatchSize,
+		notify:    make(chan struct{}, 1),
+		metrics:   newForwarderMetrics(),
+	}
+}
+
+// Open opens the queue and starts forwarding queued writes.
+func (f *Forwarder) Open(ctx context.Context) error {
+	u, err := ihttp.NewURL(f.config.URL, "/api/v2/write")
+	if err != nil {
+		return err
+	}
+	f.client = ihttp.NewClient(u.Scheme, f.config.InsecureSkipVerify)
+
+	q, err := openQueue(f.config.QueuePath, f.config.MaxQueueSize, segmentSize)
+	if err != nil {
+		return err
+	}
+	f.queue = q
+	f.metrics.queueBytes.Set(float64(q.Size()))
+	f.metrics.batchBytes.Set(float64(f.batchSize))
+
+	ctx, f.cancel = context.WithCancel(context.Background())
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.run(ctx)
+	}()
+	return nil
+}
+
+// Close stops forwarding and closes the queue. Queued writes are forwarded
+// once the forwarder is opened again.
+func (f *Forwarder) Close() error {
+	if f.cancel == nil {
+		return nil
+	}
+	f.cancel()
+	f.wg.Wait()
+	return f.queue.Close()
+}
+
+// WritePoints writes the points locally and queues them to be forwarded.
+func (f *Forwarder) WritePoints(ctx context.Context, points []models.Point) error {
+	b, err := encodePoints(points)
+	if err != nil {
+		return err
+	}
+	if !f.queue.Available(int64(len(b))) {
+		return &influxdb.Error{
+			Code: influxdb.ETooManyRequests,
+			Op:   "edge/WritePoints",
+			Msg:  "edge forward queue is full",
+		}
+	}
+
+	if err := f.writer.WritePoints(ctx, points); err != nil {
+		return err
+	}
+
+	full, err := f.queue.Append(b)
+	f.metrics.queueBytes.Set(float64(f.queue.Size()))
+	if err != nil {
+		// The points are already written locally, so losing them from the
+		// queue is not reported to the producer.
+		f.log.Error("Failed to queue points for forwarding", zap.Int("points", len(points)), zap.Error(err))
+		f.metrics.droppedPoints.WithLabelValues("queue").Add(float64(len(points)))
+		return nil
+	}
+	if full {
+		select {
+		case f.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// encodePoints encodes the exploded points as queue records in line protocol.
+func encodePoints(points []models.Point) ([]byte, error) {
+	var (
+		b    []byte
+		line []byte
+		tags models.Tags
+	)
+	for _, pt := range points {
+		name := pt.Name()
+		if len(name) != influxdb.IDLength {
+			continue
+		}
+		_, bucketID := tsdb.DecodeNameSlice(name)
+
+		var measurement []byte
+		tags = tags[:0]
+		pt.ForEachTag(func(k, v []byte) bool {
+			switch {
+			case bytes.Equal(k, models.MeasurementTagKeyBytes):
+				measurement = v
+			case bytes.Equal(k, models.FieldKeyTagKeyBytes):
+			default:
+				tags = append(tags, models.NewTag(k, v))
+			}
+			return true
+		})
+
+		fields, err := pt.Fields()
+		if err != nil {
+			return nil, err
+		}
+		p, err := models.NewPoint(string(measurement), tags, fields, pt.Time())
+		if err != nil {
+			return nil, err
+		}
+		line = p.AppendString(line[:0])
+		b = appendRecord(b, bucketID, line)
+	}
+	return b, nil
+}
+
+func (f *Forwarder) run(ctx context.Context) {
+	ticker := time.NewTicker(f.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-f.notify:
+		}
+
+		backoff := minBackoff
+		for {
+			wait, err := f.forwardOldest(ctx)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			f.metrics.forwardErrors.Inc()
+			if wait <= 0 {
+				wait = backoff
+				if backoff *= 2; backoff > maxBackoff {
+					backoff = maxBackoff
+				}
+			}
+			f.log.Warn("Failed to forward writes, retrying", zap.Duration("retry_in", wait), zap.Error(err))
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// forwardOldest forwards segments of the queue until it is empty. If it
+// fails, it returns how long the central instance asked it to wait, if at all.
+func (f *Forwarder) forwardOldest(ctx context.Context) (time.Duration, error) {
+	for {
+		id, ok, err := f.queue.Oldest()
+		if err != nil || !ok {
+			return 0, err
+		}
+
+		records, err := f.queue.Read(id)
+		if err != nil {
+			// Forward whatever precedes a truncated record rather than
+			// blocking the queue on it.
+			f.log.Error("Failed to read queued writes", zap.Uint64("segment", id), zap.Error(err))
+		}
+		if wait, err := f.forwardRecords(ctx, records); err != nil {
+			return wait, err
+		}
+
+		if err := f.queue.Remove(id); err != nil {
+			return 0, err
+		}
+		f.metrics.queueBytes.Set(float64(f.queue.Size()))
+	}
+}
+
+// forwardRecords forwards the records in batches per bucket.
+func (f *Forwarder) forwardRecords(ctx context.Context, records []record) (time.Duration, error) {
+	var (
+		order []influxdb.ID
+		lines = make(map[influxdb.ID][][]byte)
+	)
+	for _, r := range records {
+		if _, ok := lines[r.bucketID]; !ok {
+			order = append(order, r.bucketID)
+		}
+		lines[r.bucketID] = append(lines[r.bucketID], r.line)
+	}
+
+	for _, bucketID := range order {
+		b, err := f.buckets.FindBucketByID(ctx, bucketID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			f.metrics.droppedPoints.WithLabelValues("bucket_not_found").Add(float64(len(lines[bucketID])))
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		if b.Type == influxdb.BucketTypeSystem {
+			continue
+		}
+
+		if wait, err := f.forwardBucket(ctx, b.Name, lines[bucketID]); err != nil {
+			return wait, err
+		}
+	}
+	return 0, nil
+}
+
+// forwardBucket forwards lines to the bucket of the central instance.
+func (f *Forwarder) forwardBucket(ctx context.Context, bucket string, lines [][]byte) (time.Duration, error) {
+	var body bytes.Buffer
+	for len(lines) > 0 {
+		body.Reset()
+		n := 0
+		for n < len(lines) && (n == 0 || body.Len()+len(lines[n])+1 <= f.batchSize) {
+			body.Write(lines[n])
+			body.WriteByte('\n')
+			n++
+		}
+
+		wait, err := f.send(ctx, bucket, body.Bytes())
+		switch {
+		case err == errBatchTooLarge && n > 1 && f.batchSize > minBatchSize:
+			f.setBatchSize(f.batchSize / 2)
+			continue
+		case err == errBatchTooLarge, err == errBatchRejected:
+			f.log.Error("Central instance rejected forwarded points", zap.String("bucket", bucket), zap.Int("points", n))
+			f.metrics.droppedPoints.WithLabelValues("rejected").Add(float64(n))
+		case err != nil:
+			return wait, err
+		default:
+			f.metrics.forwardedPoints.Add(float64(n))
+			f.setBatchSize(f.batchSize * 2)
+		}
+		lines = lines[n:]
+	}
+	return 0, nil
+}
+
+func (f *Forwarder) setBatchSize(n int) {
+	if n < minBatchSize {
+		n = minBatchSize
+	}
+	if n > f.config.MaxBatchSize {
+		n = f.config.MaxBatchSize
+	}
+	f.batchSize = n
+	f.metrics.batchBytes.Set(float64(n))
+}
+
+var (
+	// errBatchTooLarge is returned when the central instance rejects a batch
+	// because of its size.
+	errBatchTooLarge = errors.New("batch too large")
+
+	// errBatchRejected is returned when the central instance rejects the
+	// points of a batch. Retrying the batch will not succeed.
+	errBatchRejected = errors.New("batch rejected")
+)
+
+// send writes the line protocol to the bucket of the central instance.
+func (f *Forwarder) send(ctx context.Context, bucket string, body []byte) (time.Duration, error) {
+	u, err := ihttp.NewURL(f.config.URL, "/api/v2/write")
+	if err != nil {
+		return 0, err
+	}
+	params := u.Query()
+	params.Set("org", f.config.Org)
+	params.Set("bucket", bucket)
+	params.Set("precision", "ns")
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	ihttp.SetToken(f.config.Token, req)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusRequestEntityTooLarge:
+		return 0, errBatchTooLarge
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return 0, errBatchRejected
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		var wait time.Duration
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+			wait = time.Duration(s) * time.Second
+		}
+		return wait, ihttp.CheckError(resp)
+	}
+	return 0, ihttp.CheckError(resp)
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (f *Forwarder) PrometheusCollectors() []prometheus.Collector {
+	return f.metrics.PrometheusCollectors()
+}
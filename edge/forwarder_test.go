@@ -0,0 +1,153 @@
+package edge
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+type pointsWriter struct {
+	points []models.Point
+}
+
+func (w *pointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	w.points = append(w.points, points...)
+	return nil
+}
+
+type bucketFinder map[influxdb.ID]*influxdb.Bucket
+
+func (f bucketFinder) FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	if b, ok := f[id]; ok {
+		return b, nil
+	}
+	return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+}
+
+// mustParsePoints parses the line protocol as it is parsed when written to the bucket.
+func mustParsePoints(t *testing.T, orgID, bucketID influxdb.ID, lp string) []models.Point {
+	t.Helper()
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	points, err := models.ParsePointsString(lp, string(models.EscapeMeasurement(encoded[:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+func TestForwarder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edge-forwarder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		mu       sync.Mutex
+		received = make(map[string][]string)
+		attempts int
+	)
+	central := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		// The central instance is unavailable for the first attempt.
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("org") != "central" {
+			t.Errorf("unexpected org %q", r.URL.Query().Get("org"))
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		bucket := r.URL.Query().Get("bucket")
+		received[bucket] = append(received[bucket], strings.Split(strings.TrimSpace(string(body)), "\n")...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer central.Close()
+
+	const (
+		orgID    = influxdb.ID(0x5000)
+		bucketID = influxdb.ID(0x6000)
+		systemID = influxdb.ID(0x6001)
+	)
+	local := &pointsWriter{}
+	f := NewForwarder(zaptest.NewLogger(t), Config{
+		URL:           central.URL,
+		Org:           "central",
+		QueuePath:     dir,
+		FlushInterval: 10 * time.Millisecond,
+	}, local, bucketFinder{
+		bucketID: {ID: bucketID, OrgID: orgID, Name: "factory"},
+		systemID: {ID: systemID, OrgID: orgID, Name: "_tasks", Type: influxdb.BucketTypeSystem},
+	})
+	if err := f.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	points := mustParsePoints(t, orgID, bucketID, "cpu,host=a usage=1,idle=2i 1000\nmem,host=a used=3i 1000")
+	points = append(points, mustParsePoints(t, orgID, systemID, "runs count=1i 1000")...)
+	if err := f.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+	if len(local.points) != len(points) {
+		t.Fatalf("expected points to be written locally, got %d", len(local.points))
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for f.queue.Size() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("queued points were not forwarded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || len(received["factory"]) != 2 {
+		t.Fatalf("unexpected forwarded points: %v", received)
+	}
+	if want := []string{"cpu,host=a idle=2i,usage=1 1000", "mem,host=a used=3i 1000"}; !reflect.DeepEqual(received["factory"], want) {
+		t.Errorf("unexpected forwarded points:\n%q\nexpected\n%q", received["factory"], want)
+	}
+}
+
+func TestForwarder_QueueFull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edge-forwarder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	local := &pointsWriter{}
+	f := NewForwarder(zaptest.NewLogger(t), Config{
+		URL:           "http://localhost:1",
+		QueuePath:     dir,
+		MaxQueueSize:  1,
+		FlushInterval: time.Hour,
+	}, local, bucketFinder{})
+	if err := f.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	points := mustParsePoints(t, 0x5000, 0x6000, "cpu usage=1 1000")
+	if err := f.WritePoints(context.Background(), points); influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+		t.Fatalf("expected write to be rejected while the queue is full, got %v", err)
+	}
+	if len(local.points) != 0 {
+		t.Fatal("expected rejected points not to be written locally")
+	}
+}
@@ -0,0 +1,64 @@
+package edge
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "edge"
+	subsystem = "forwarder"
+)
+
+type forwarderMetrics struct {
+	queueBytes      prometheus.Gauge
+	batchBytes      prometheus.Gauge
+	forwardedPoints prometheus.Counter
+	forwardErrors   prometheus.Counter
+	droppedPoints   *prometheus.CounterVec
+}
+
+func newForwarderMetrics() *forwarderMetrics {
+	return &forwarderMetrics{
+		queueBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queue_bytes",
+			Help:      "Size of the writes waiting to be forwarded.",
+		}),
+		batchBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "batch_bytes",
+			Help:      "Current maximum size of a forwarded batch.",
+		}),
+		forwardedPoints: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "points_total",
+			Help:      "Number of points forwarded to the central instance.",
+		}),
+		forwardErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "errors_total",
+			Help:      "Number of failed attempts to forward writes.",
+		}),
+		droppedPoints: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dropped_points_total",
+			Help:      "Number of points which were written locally but not forwarded.",
+		}, []string{"reason"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *forwarderMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.queueBytes,
+		m.batchBytes,
+		m.forwardedPoints,
+		m.forwardErrors,
+		m.droppedPoints,
+	}
+}
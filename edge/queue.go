@@ -0,0 +1,224 @@
+package edge
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+const segmentExt = ".seg"
+
+// errQueueFull is returned when appending to a queue would exceed its maximum size.
+var errQueueFull = errors.New("edge queue is full")
+
+// queue is a disk-backed FIFO of line protocol records waiting to be
+// forwarded. Records are appended to an active segment file, which is closed
+// once it reaches segmentSize or when the forwarder asks for the oldest
+// segment and there is no other. Closed segments are forwarded and removed
+// whole, oldest first.
+//
+// Each record is the ID of the bucket the point was written to, the length
+// of the line and the line protocol of a single point.
+type queue struct {
+	dir         string
+	maxSize     int64
+	segmentSize int64
+
+	mu         sync.Mutex
+	segments   []uint64 // closed segments, oldest first
+	active     *os.File
+	activeID   uint64
+	activeSize int64
+	size       int64
+}
+
+func openQueue(dir string, maxSize, segmentSize int64) (*queue, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &queue{dir: dir, maxSize: maxSize, segmentSize: segmentSize}
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(fi.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		q.segments = append(q.segments, id)
+		q.size += fi.Size()
+		if id >= q.activeID {
+			q.activeID = id + 1
+		}
+	}
+	sort.Slice(q.segments, func(i, j int) bool { return q.segments[i] < q.segments[j] })
+	return q, nil
+}
+
+func (q *queue) path(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// Size returns the number of bytes in the queue.
+func (q *queue) Size() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// Available reports whether n more bytes fit in the queue.
+func (q *queue) Available(n int64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.maxSize <= 0 || q.size+n <= q.maxSize
+}
+
+// Append appends the records of b to the queue. It reports whether the
+// active segment was closed because it is full.
+func (q *queue) Append(b []byte) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.maxSize > 0 && q.size+int64(len(b)) > q.maxSize {
+		return false, errQueueFull
+	}
+
+	if q.active == nil {
+		f, err := os.OpenFile(q.path(q.activeID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return false, err
+		}
+		q.active = f
+	}
+
+	n, err := q.active.Write(b)
+	q.activeSize += int64(n)
+	q.size += int64(n)
+	if err != nil {
+		return false, err
+	}
+
+	if q.activeSize < q.segmentSize {
+		return false, nil
+	}
+	return true, q.closeActiveLocked()
+}
+
+func (q *queue) closeActiveLocked() error {
+	if q.active == nil {
+		return nil
+	}
+	err := q.active.Sync()
+	if cerr := q.active.Close(); err == nil {
+		err = cerr
+	}
+	q.segments = append(q.segments, q.activeID)
+	q.active = nil
+	q.activeID++
+	q.activeSize = 0
+	return err
+}
+
+// Oldest returns the ID of the oldest closed segment. If there is none, the
+// active segment is closed first so that records do not wait for it to fill
+// up.
+func (q *queue) Oldest() (uint64, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.segments) == 0 {
+		if q.activeSize == 0 {
+			return 0, false, nil
+		}
+		if err := q.closeActiveLocked(); err != nil {
+			return 0, false, err
+		}
+	}
+	return q.segments[0], true, nil
+}
+
+// Read returns the records of the segment.
+func (q *queue) Read(id uint64) ([]record, error) {
+	b, err := ioutil.ReadFile(q.path(id))
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecords(b)
+}
+
+// Remove removes the oldest segment, which must have been forwarded.
+func (q *queue) Remove(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.segments) == 0 || q.segments[0] != id {
+		return fmt.Errorf("segment %d is not the oldest segment", id)
+	}
+
+	fi, err := os.Stat(q.path(id))
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(q.path(id)); err != nil {
+		return err
+	}
+	q.segments = q.segments[1:]
+	q.size -= fi.Size()
+	return nil
+}
+
+// Close closes the active segment.
+func (q *queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closeActiveLocked()
+}
+
+const recordHeaderSize = influxdb.IDLength + 4
+
+// record is a point waiting to be forwarded.
+type record struct {
+	bucketID influxdb.ID
+	line     []byte
+}
+
+// appendRecord appends the encoded record to b.
+func appendRecord(b []byte, bucketID influxdb.ID, line []byte) []byte {
+	var hdr [recordHeaderSize]byte
+	binary.BigEndian.PutUint64(hdr[:influxdb.IDLength], uint64(bucketID))
+	binary.BigEndian.PutUint32(hdr[influxdb.IDLength:], uint32(len(line)))
+	return append(append(b, hdr[:]...), line...)
+}
+
+func decodeRecords(b []byte) ([]record, error) {
+	var records []record
+	for len(b) > 0 {
+		if len(b) < recordHeaderSize {
+			return records, errors.New("truncated edge queue record header")
+		}
+		bucketID := influxdb.ID(binary.BigEndian.Uint64(b[:influxdb.IDLength]))
+		n := int(binary.BigEndian.Uint32(b[influxdb.IDLength:recordHeaderSize]))
+		b = b[recordHeaderSize:]
+		if len(b) < n {
+			return records, errors.New("truncated edge queue record")
+		}
+		records = append(records, record{bucketID: bucketID, line: b[:n]})
+		b = b[n:]
+	}
+	return records, nil
+}
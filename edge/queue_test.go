@@ -0,0 +1,70 @@
+package edge
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edge-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := openQueue(dir, 0, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first record fills its segment.
+	if full, err := q.Append(appendRecord(nil, 1, []byte("cpu usage=1 1000 cpu usage=2 2000"))); err != nil || !full {
+		t.Fatalf("expected segment to be full, got %v, %v", full, err)
+	}
+	if _, err := q.Append(appendRecord(nil, 2, []byte("mem used=1 1000"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queued records survive reopening the queue.
+	q, err = openQueue(dir, 0, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	for _, want := range []struct {
+		bucketID influxdb.ID
+		line     string
+	}{
+		{1, "cpu usage=1 1000 cpu usage=2 2000"},
+		{2, "mem used=1 1000"},
+	} {
+		id, ok, err := q.Oldest()
+		if err != nil || !ok {
+			t.Fatalf("expected a segment, got %v, %v", ok, err)
+		}
+		records, err := q.Read(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].bucketID != want.bucketID || string(records[0].line) != want.line {
+			t.Fatalf("unexpected records %+v", records)
+		}
+		if err := q.Remove(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok, err := q.Oldest(); err != nil || ok {
+		t.Fatalf("expected queue to be empty, got %v, %v", ok, err)
+	}
+	if q.Size() != 0 {
+		t.Fatalf("expected queue to be empty, got size %d", q.Size())
+	}
+}
@@ -0,0 +1,114 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrFeatureFlagSettingNotFound is the error for a missing feature flag setting.
+const ErrFeatureFlagSettingNotFound = "feature flag setting not found"
+
+const (
+	OpFindFeatureFlagSettings  = "FindFeatureFlagSettings"
+	OpSetFeatureFlagSetting    = "SetFeatureFlagSetting"
+	OpDeleteFeatureFlagSetting = "DeleteFeatureFlagSetting"
+	OpFindFeatureFlagChanges   = "FindFeatureFlagChanges"
+)
+
+// FeatureFlagService persists the values of feature flags set for the
+// instance and for organizations, and records every change made to them.
+type FeatureFlagService interface {
+	// FindFeatureFlagSettings returns the settings that match filter.
+	FindFeatureFlagSettings(ctx context.Context, filter FeatureFlagSettingFilter) ([]*FeatureFlagSetting, error)
+
+	// SetFeatureFlagSetting sets the value of a flag for the instance, or
+	// for an organization if s.OrgID is set.
+	SetFeatureFlagSetting(ctx context.Context, s *FeatureFlagSetting) error
+
+	// DeleteFeatureFlagSetting removes the value of a flag set for the
+	// instance, or for the organization orgID if it is not nil.
+	DeleteFeatureFlagSetting(ctx context.Context, key string, orgID *ID) error
+
+	// FindFeatureFlagChanges returns the changes made to the settings that
+	// match filter, from the oldest to the latest unless opts are descending.
+	FindFeatureFlagChanges(ctx context.Context, filter FeatureFlagChangeFilter, opts ...FindOptions) ([]*FeatureFlagChange, error)
+}
+
+// FeatureFlagSetting is a value of a feature flag which takes precedence over
+// its default. A value set for an organization takes precedence over the
+// value set for the instance. The value is the text representation of the
+// value of the flag, such as "true" for a boolean flag.
+type FeatureFlagSetting struct {
+	Key string `json:"key"`
+	// OrgID is the organization the value is set for. The value of a
+	// setting without one is the default of the instance.
+	OrgID     *ID       `json:"orgID,omitempty"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Valid returns an error if the setting is invalid.
+func (s *FeatureFlagSetting) Valid() error {
+	if s.Key == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "feature flag key is required",
+		}
+	}
+	if s.OrgID != nil && !s.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "feature flag setting organization ID is invalid",
+		}
+	}
+	return nil
+}
+
+// FeatureFlagSettingFilter selects feature flag settings. If OrgID is set,
+// only the settings of that organization are selected, and if Instance is
+// set, only the settings of the instance are.
+type FeatureFlagSettingFilter struct {
+	Key      *string
+	OrgID    *ID
+	Instance bool
+}
+
+// Match returns true if the setting matches the filter.
+func (f FeatureFlagSettingFilter) Match(s *FeatureFlagSetting) bool {
+	return f.match(s.Key, s.OrgID)
+}
+
+func (f FeatureFlagSettingFilter) match(key string, orgID *ID) bool {
+	if f.Key != nil && *f.Key != key {
+		return false
+	}
+	if f.Instance && orgID != nil {
+		return false
+	}
+	if f.OrgID != nil && (orgID == nil || *orgID != *f.OrgID) {
+		return false
+	}
+	return true
+}
+
+// FeatureFlagChange is a change made to the value of a feature flag set for
+// the instance or for an organization. OldValue is nil if the value was
+// not set before the change, and NewValue is nil if it was removed.
+type FeatureFlagChange struct {
+	ID       ID      `json:"id"`
+	Key      string  `json:"key"`
+	OrgID    *ID     `json:"orgID,omitempty"`
+	OldValue *string `json:"oldValue"`
+	NewValue *string `json:"newValue"`
+	// UserID is the user who made the change, if it was made by one.
+	UserID ID        `json:"userID,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// FeatureFlagChangeFilter selects the changes made to feature flag settings.
+type FeatureFlagChangeFilter FeatureFlagSettingFilter
+
+// Match returns true if the change matches the filter.
+func (f FeatureFlagChangeFilter) Match(c *FeatureFlagChange) bool {
+	return FeatureFlagSettingFilter(f).match(c.Key, c.OrgID)
+}
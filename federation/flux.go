@@ -0,0 +1,217 @@
+package federation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+)
+
+// filterScript returns the Flux query which reads what spec reads from the
+// storage of a node.
+func filterScript(spec influxdb.ReadFilterSpec) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucketID: %s)\n", formatString(spec.BucketID.String()))
+	fmt.Fprintf(&b, "\t|> range(start: %s, stop: %s)", formatTime(spec.Bounds.Start), formatTime(spec.Bounds.Stop))
+	if spec.Predicate != nil {
+		fn, err := formatPredicate(spec.Predicate)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "\n\t|> filter(fn: %s)", fn)
+	}
+	return b.String(), nil
+}
+
+func groupScript(spec influxdb.ReadGroupSpec) (string, error) {
+	if spec.AggregateMethod != "" {
+		return "", &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("aggregate %q cannot be federated", spec.AggregateMethod),
+		}
+	}
+
+	script, err := filterScript(spec.ReadFilterSpec)
+	if err != nil {
+		return "", err
+	}
+
+	switch spec.GroupMode {
+	case influxdb.GroupModeNone:
+		return script + "\n\t|> group(columns: [\"_start\", \"_stop\"])", nil
+	case influxdb.GroupModeBy:
+		// Storage always groups by the _start and _stop columns.
+		columns := []string{
+			formatString(execute.DefaultStartColLabel),
+			formatString(execute.DefaultStopColLabel),
+		}
+		for _, k := range spec.GroupKeys {
+			if k == execute.DefaultStartColLabel || k == execute.DefaultStopColLabel {
+				continue
+			}
+			columns = append(columns, formatString(k))
+		}
+		return fmt.Sprintf("%s\n\t|> group(columns: [%s], mode: \"by\")", script, strings.Join(columns, ", ")), nil
+	}
+	return "", fmt.Errorf("unknown group mode: %v", spec.GroupMode)
+}
+
+func tagKeysScript(spec influxdb.ReadTagKeysSpec) (string, error) {
+	script, err := filterScript(spec.ReadFilterSpec)
+	if err != nil {
+		return "", err
+	}
+	// Storage returns the keys of the tags and of the _start and _stop
+	// columns, but not of the _time and _value columns.
+	return script + `
+	|> keys()
+	|> keep(columns: ["_value"])
+	|> group()
+	|> distinct()
+	|> filter(fn: (r) => r._value != "_time" and r._value != "_value")`, nil
+}
+
+func tagValuesScript(spec influxdb.ReadTagValuesSpec) (string, error) {
+	script, err := filterScript(spec.ReadFilterSpec)
+	if err != nil {
+		return "", err
+	}
+	tagKey := formatString(spec.TagKey)
+	return fmt.Sprintf(`%s
+	|> keep(columns: [%s])
+	|> group()
+	|> distinct(column: %s)
+	|> filter(fn: (r) => exists r._value)`, script, tagKey, tagKey), nil
+}
+
+// formatPredicate formats a predicate pushed down to storage as a Flux
+// function. Predicates only hold the expressions storage can evaluate.
+func formatPredicate(f *semantic.FunctionExpression) (string, error) {
+	if f.Block.Parameters == nil || len(f.Block.Parameters.List) != 1 {
+		return "", errors.New("storage predicate functions must have exactly one parameter")
+	}
+	body, ok := f.Block.Body.(semantic.Expression)
+	if !ok {
+		return "", fmt.Errorf("unsupported predicate body %T", f.Block.Body)
+	}
+
+	param := f.Block.Parameters.List[0].Key.Name
+	expr, err := formatExpression(body, param)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s) => %s", param, expr), nil
+}
+
+func formatExpression(n semantic.Expression, param string) (string, error) {
+	switch n := n.(type) {
+	case *semantic.LogicalExpression:
+		left, err := formatExpression(n.Left, param)
+		if err != nil {
+			return "", err
+		}
+		right, err := formatExpression(n.Right, param)
+		if err != nil {
+			return "", err
+		}
+		var op string
+		switch n.Operator {
+		case ast.AndOperator:
+			op = "and"
+		case ast.OrOperator:
+			op = "or"
+		default:
+			return "", fmt.Errorf("unknown logical operator %v", n.Operator)
+		}
+		return fmt.Sprintf("(%s %s %s)", left, op, right), nil
+	case *semantic.UnaryExpression:
+		arg, err := formatExpression(n.Argument, param)
+		if err != nil {
+			return "", err
+		}
+		switch n.Operator {
+		case ast.NotOperator:
+			return fmt.Sprintf("(not %s)", arg), nil
+		case ast.ExistsOperator:
+			return fmt.Sprintf("(exists %s)", arg), nil
+		}
+		return "", fmt.Errorf("unknown unary operator %v", n.Operator)
+	case *semantic.BinaryExpression:
+		left, err := formatExpression(n.Left, param)
+		if err != nil {
+			return "", err
+		}
+		right, err := formatExpression(n.Right, param)
+		if err != nil {
+			return "", err
+		}
+		op, err := formatComparisonOperator(n.Operator)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", left, op, right), nil
+	case *semantic.MemberExpression:
+		if id, ok := n.Object.(*semantic.IdentifierExpression); !ok || id.Name != param {
+			return "", fmt.Errorf("unsupported member expression of %T", n.Object)
+		}
+		return fmt.Sprintf("%s[%s]", param, formatString(n.Property)), nil
+	case *semantic.StringLiteral:
+		return formatString(n.Value), nil
+	case *semantic.RegexpLiteral:
+		return "/" + strings.Replace(n.Value.String(), "/", `\/`, -1) + "/", nil
+	case *semantic.IntegerLiteral:
+		return strconv.FormatInt(n.Value, 10), nil
+	case *semantic.FloatLiteral:
+		s := strconv.FormatFloat(n.Value, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s, nil
+	case *semantic.BooleanLiteral:
+		return strconv.FormatBool(n.Value), nil
+	default:
+		return "", fmt.Errorf("unsupported predicate expression %T", n)
+	}
+}
+
+func formatComparisonOperator(op ast.OperatorKind) (string, error) {
+	switch op {
+	case ast.EqualOperator:
+		return "==", nil
+	case ast.NotEqualOperator:
+		return "!=", nil
+	case ast.RegexpMatchOperator:
+		return "=~", nil
+	case ast.NotRegexpMatchOperator:
+		return "!~", nil
+	case ast.LessThanOperator:
+		return "<", nil
+	case ast.LessThanEqualOperator:
+		return "<=", nil
+	case ast.GreaterThanOperator:
+		return ">", nil
+	case ast.GreaterThanEqualOperator:
+		return ">=", nil
+	default:
+		return "", fmt.Errorf("unknown comparison operator %v", op)
+	}
+}
+
+var stringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`)
+
+// formatString formats s as a Flux string literal.
+func formatString(s string) string {
+	return `"` + stringEscaper.Replace(s) + `"`
+}
+
+func formatTime(t values.Time) string {
+	return t.Time().UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,120 @@
+package federation
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+)
+
+func predicate(body semantic.Expression) *semantic.FunctionExpression {
+	return &semantic.FunctionExpression{
+		Block: &semantic.FunctionBlock{
+			Parameters: &semantic.FunctionParameters{
+				List: []*semantic.FunctionParameter{
+					{Key: &semantic.Identifier{Name: "r"}},
+				},
+			},
+			Body: body,
+		},
+	}
+}
+
+func member(property string) *semantic.MemberExpression {
+	return &semantic.MemberExpression{
+		Object:   &semantic.IdentifierExpression{Name: "r"},
+		Property: property,
+	}
+}
+
+func testFilterSpec() influxdb.ReadFilterSpec {
+	return influxdb.ReadFilterSpec{
+		OrganizationID: platform.ID(1),
+		BucketID:       platform.ID(2),
+		Bounds: execute.Bounds{
+			Start: values.ConvertTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+			Stop:  values.ConvertTime(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+}
+
+func TestFilterScript(t *testing.T) {
+	spec := testFilterSpec()
+	spec.Predicate = predicate(&semantic.LogicalExpression{
+		Operator: ast.AndOperator,
+		Left: &semantic.BinaryExpression{
+			Operator: ast.EqualOperator,
+			Left:     member("_measurement"),
+			Right:    &semantic.StringLiteral{Value: `c"p${u}`},
+		},
+		Right: &semantic.LogicalExpression{
+			Operator: ast.OrOperator,
+			Left: &semantic.BinaryExpression{
+				Operator: ast.RegexpMatchOperator,
+				Left:     member("host"),
+				Right:    &semantic.RegexpLiteral{Value: regexp.MustCompile("a/b")},
+			},
+			Right: &semantic.BinaryExpression{
+				Operator: ast.GreaterThanOperator,
+				Left:     member("_value"),
+				Right:    &semantic.FloatLiteral{Value: 1},
+			},
+		},
+	})
+
+	got, err := filterScript(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `from(bucketID: "0000000000000002")
+	|> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-02T00:00:00Z)
+	|> filter(fn: (r) => (r["_measurement"] == "c\"p\${u}" and (r["host"] =~ /a\/b/ or r["_value"] > 1.0)))`
+	if got != want {
+		t.Errorf("unexpected script:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGroupScript(t *testing.T) {
+	got, err := groupScript(influxdb.ReadGroupSpec{
+		ReadFilterSpec: testFilterSpec(),
+		GroupMode:      influxdb.GroupModeBy,
+		GroupKeys:      []string{"_start", "host"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `from(bucketID: "0000000000000002")
+	|> range(start: 2020-01-01T00:00:00Z, stop: 2020-01-02T00:00:00Z)
+	|> group(columns: ["_start", "_stop", "host"], mode: "by")`
+	if got != want {
+		t.Errorf("unexpected script:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := groupScript(influxdb.ReadGroupSpec{
+		ReadFilterSpec:  testFilterSpec(),
+		GroupMode:       influxdb.GroupModeBy,
+		AggregateMethod: "count",
+	}); err == nil {
+		t.Error("expected aggregates not to be federated")
+	}
+}
+
+func TestFormatPredicate_Unsupported(t *testing.T) {
+	_, err := formatPredicate(predicate(&semantic.BinaryExpression{
+		Operator: ast.EqualOperator,
+		Left: &semantic.MemberExpression{
+			Object:   &semantic.IdentifierExpression{Name: "other"},
+			Property: "host",
+		},
+		Right: &semantic.StringLiteral{Value: "a"},
+	}))
+	if err == nil {
+		t.Error("expected members of other objects not to be formatted")
+	}
+}
@@ -0,0 +1,227 @@
+// Package federation implements queries which read from the storage of
+// several influxd nodes and merge what they read into a single result.
+package federation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	platform "github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/merge"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Config configures a Reader.
+type Config struct {
+	// Nodes are the addresses of the nodes reads are federated across, in
+	// addition to the local node.
+	Nodes []string
+
+	// Token authorizes queries against the nodes. It must be able to read
+	// every bucket which is queried.
+	Token string
+
+	// InsecureSkipVerify skips verification of the nodes' TLS certificates.
+	InsecureSkipVerify bool
+}
+
+// Reader is an influxdb.Reader which scatters each read to the local storage
+// and to every configured node, and gathers the tables they return.
+//
+// Reads are sent to the nodes as the equivalent Flux query, so nodes must
+// share the IDs of their organizations and buckets, as they do when their
+// metadata is replicated. Tables with the same group key are merged, and rows
+// returned by more than one node, such as those of shards which are stored
+// on several nodes, are only returned once. A read fails when any node fails,
+// rather than returning partial results.
+//
+// Queries a Reader sends are marked as federated, so the nodes receiving them
+// only read their own storage even when they federate reads themselves.
+//
+// Snapshots of buckets are local to each node, so reads of a snapshot only
+// read the local storage.
+type Reader struct {
+	log   *zap.Logger
+	local influxdb.Reader
+	nodes []*ihttp.FluxQueryService
+}
+
+var _ influxdb.Reader = (*Reader)(nil)
+
+// NewReader returns a Reader which federates the reads of local across the
+// nodes of config.
+func NewReader(log *zap.Logger, config Config, local influxdb.Reader) *Reader {
+	r := &Reader{
+		log:   log,
+		local: local,
+	}
+	for _, addr := range config.Nodes {
+		r.nodes = append(r.nodes, &ihttp.FluxQueryService{
+			Addr:               addr,
+			Token:              config.Token,
+			Name:               "influxd-federation",
+			InsecureSkipVerify: config.InsecureSkipVerify,
+		})
+	}
+	return r
+}
+
+func (r *Reader) ReadFilter(ctx context.Context, spec influxdb.ReadFilterSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	if query.IsFederated(ctx) || spec.Snapshot != "" {
+		return r.local.ReadFilter(ctx, spec, alloc)
+	}
+
+	script, err := filterScript(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.gather(ctx, spec.OrganizationID, script, execute.DefaultTimeColLabel, alloc, func() (influxdb.TableIterator, error) {
+		return r.local.ReadFilter(ctx, spec, alloc)
+	}), nil
+}
+
+func (r *Reader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	if query.IsFederated(ctx) || spec.Snapshot != "" {
+		return r.local.ReadGroup(ctx, spec, alloc)
+	}
+
+	script, err := groupScript(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.gather(ctx, spec.OrganizationID, script, execute.DefaultTimeColLabel, alloc, func() (influxdb.TableIterator, error) {
+		return r.local.ReadGroup(ctx, spec, alloc)
+	}), nil
+}
+
+func (r *Reader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeysSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	if query.IsFederated(ctx) || spec.Snapshot != "" {
+		return r.local.ReadTagKeys(ctx, spec, alloc)
+	}
+
+	script, err := tagKeysScript(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.gather(ctx, spec.OrganizationID, script, execute.DefaultValueColLabel, alloc, func() (influxdb.TableIterator, error) {
+		return r.local.ReadTagKeys(ctx, spec, alloc)
+	}), nil
+}
+
+func (r *Reader) ReadTagValues(ctx context.Context, spec influxdb.ReadTagValuesSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	if query.IsFederated(ctx) || spec.Snapshot != "" {
+		return r.local.ReadTagValues(ctx, spec, alloc)
+	}
+
+	script, err := tagValuesScript(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.gather(ctx, spec.OrganizationID, script, execute.DefaultValueColLabel, alloc, func() (influxdb.TableIterator, error) {
+		return r.local.ReadTagValues(ctx, spec, alloc)
+	}), nil
+}
+
+func (r *Reader) Close() {
+	r.local.Close()
+}
+
+func (r *Reader) gather(ctx context.Context, orgID platform.ID, script, sortBy string, alloc *memory.Allocator, local func() (influxdb.TableIterator, error)) *gatherIterator {
+	return &gatherIterator{
+		ctx:    ctx,
+		r:      r,
+		orgID:  orgID,
+		script: script,
+		sortBy: sortBy,
+		local:  local,
+		alloc:  alloc,
+	}
+}
+
+// gatherIterator reads the tables of the local storage and of every node
+// concurrently, and merges them once all of them have been read.
+type gatherIterator struct {
+	ctx    context.Context
+	r      *Reader
+	orgID  platform.ID
+	script string
+	sortBy string
+	local  func() (influxdb.TableIterator, error)
+	alloc  *memory.Allocator
+	stats  cursors.CursorStats
+}
+
+func (gi *gatherIterator) Statistics() cursors.CursorStats { return gi.stats }
+
+func (gi *gatherIterator) Do(f func(flux.Table) error) error {
+	li, err := gi.local()
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	m := merge.New(gi.sortBy)
+	add := func(tbl flux.Table) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return m.Add(tbl)
+	}
+
+	g, ctx := errgroup.WithContext(gi.ctx)
+	g.Go(func() error {
+		if err := li.Do(add); err != nil {
+			return err
+		}
+		gi.stats = li.Statistics()
+		return nil
+	})
+	for _, node := range gi.r.nodes {
+		node := node
+		g.Go(func() error {
+			if err := gi.readNode(ctx, node, add); err != nil {
+				gi.r.log.Info("Failed to read from node", zap.String("node", node.Addr), zap.Error(err))
+				return &platform.Error{
+					Code: platform.EUnavailable,
+					Msg:  fmt.Sprintf("failed to read from node %s", node.Addr),
+					Err:  err,
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return m.Do(gi.alloc, f)
+}
+
+func (gi *gatherIterator) readNode(ctx context.Context, node *ihttp.FluxQueryService, add func(flux.Table) error) error {
+	req := &query.Request{
+		OrganizationID: gi.orgID,
+		Compiler:       lang.FluxCompiler{Query: gi.script},
+	}
+	req.WithFederated()
+
+	results, err := node.Query(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer results.Release()
+
+	for results.More() {
+		if err := results.Next().Tables().Do(add); err != nil {
+			return err
+		}
+	}
+	return results.Err()
+}
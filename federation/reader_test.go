@@ -0,0 +1,186 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"go.uber.org/zap/zaptest"
+)
+
+type tableIterator struct {
+	tables []*executetest.Table
+}
+
+func (ti *tableIterator) Do(f func(flux.Table) error) error {
+	for _, tbl := range ti.tables {
+		if err := f(tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ti *tableIterator) Statistics() cursors.CursorStats {
+	return cursors.CursorStats{ScannedValues: 2}
+}
+
+// localReader returns the same tables for every read.
+type localReader struct {
+	influxdb.Reader
+	tables []*executetest.Table
+}
+
+func (r *localReader) ReadFilter(ctx context.Context, spec influxdb.ReadFilterSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	return &tableIterator{tables: r.tables}, nil
+}
+
+var (
+	start = execute.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	stop  = execute.Time(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).UnixNano())
+)
+
+func ts(sec int) execute.Time {
+	return start + execute.Time(time.Duration(sec)*time.Second)
+}
+
+func seriesTable(host string, rows ...[]interface{}) *executetest.Table {
+	tbl := &executetest.Table{
+		KeyCols: []string{"_start", "_stop", "_field", "_measurement", "host"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_start", Type: flux.TTime},
+			{Label: "_stop", Type: flux.TTime},
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+			{Label: "_field", Type: flux.TString},
+			{Label: "_measurement", Type: flux.TString},
+			{Label: "host", Type: flux.TString},
+		},
+	}
+	for _, row := range rows {
+		tbl.Data = append(tbl.Data, append([]interface{}{start, stop}, append(row, "v", "cpu", host)...))
+	}
+	return tbl
+}
+
+const remoteCSV = "#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string\r\n" +
+	"#group,false,false,true,true,false,false,true,true,true\r\n" +
+	"#default,_result,,,,,,,,\r\n" +
+	",result,table,_start,_stop,_time,_value,_field,_measurement,host\r\n" +
+	",,0,2020-01-01T00:00:00Z,2020-01-02T00:00:00Z,2020-01-01T00:00:10Z,2,v,cpu,a\r\n" +
+	",,0,2020-01-01T00:00:00Z,2020-01-02T00:00:00Z,2020-01-01T00:00:20Z,3,v,cpu,a\r\n" +
+	",,1,2020-01-01T00:00:00Z,2020-01-02T00:00:00Z,2020-01-01T00:00:05Z,4,v,cpu,b\r\n" +
+	"\r\n"
+
+func TestReader_ReadFilter(t *testing.T) {
+	var script string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(query.FederatedHeaderKey) == "" {
+			t.Error("expected query to be marked as federated")
+		}
+		if got := r.Header.Get("Authorization"); got != "Token secret" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		script = body.Query
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		_, _ = w.Write([]byte(remoteCSV))
+	}))
+	defer node.Close()
+
+	local := &localReader{tables: []*executetest.Table{
+		seriesTable("a", []interface{}{ts(0), 1.0}, []interface{}{ts(10), 2.0}),
+	}}
+	r := NewReader(zaptest.NewLogger(t), Config{Nodes: []string{node.URL}, Token: "secret"}, local)
+
+	spec := influxdb.ReadFilterSpec{Bounds: execute.Bounds{Start: start, Stop: stop}}
+	ti, err := r.ReadFilter(context.Background(), spec, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*executetest.Table
+	if err := ti.Do(func(tbl flux.Table) error {
+		tt, err := executetest.ConvertTable(tbl)
+		if err != nil {
+			return err
+		}
+		got = append(got, tt)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(script, `from(bucketID: "`) {
+		t.Errorf("unexpected script sent to node:\n%s", script)
+	}
+
+	// The point at 10s is stored on both nodes, and is only returned once.
+	want := []*executetest.Table{
+		seriesTable("a", []interface{}{ts(0), 1.0}, []interface{}{ts(10), 2.0}, []interface{}{ts(20), 3.0}),
+		seriesTable("b", []interface{}{ts(5), 4.0}),
+	}
+	executetest.NormalizeTables(got)
+	executetest.NormalizeTables(want)
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected tables -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	if stats := ti.Statistics(); stats.ScannedValues != 2 {
+		t.Errorf("expected statistics of the local read, got %+v", stats)
+	}
+}
+
+func TestReader_Federated(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected federated query not to be sent to other nodes")
+	}))
+	defer node.Close()
+
+	local := &localReader{tables: []*executetest.Table{
+		seriesTable("a", []interface{}{ts(0), 1.0}),
+	}}
+	r := NewReader(zaptest.NewLogger(t), Config{Nodes: []string{node.URL}}, local)
+
+	ctx := query.ContextWithFederated(context.Background())
+	ti, err := r.ReadFilter(ctx, influxdb.ReadFilterSpec{}, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ti.Do(func(tbl flux.Table) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReader_NodeError(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"code":"internal error","message":"boom"}`))
+	}))
+	defer node.Close()
+
+	r := NewReader(zaptest.NewLogger(t), Config{Nodes: []string{node.URL}}, &localReader{})
+	spec := influxdb.ReadFilterSpec{Bounds: execute.Bounds{Start: start, Stop: stop}}
+	ti, err := r.ReadFilter(context.Background(), spec, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ti.Do(func(tbl flux.Table) error { return nil }); err == nil {
+		t.Fatal("expected the read to fail when a node fails")
+	}
+}
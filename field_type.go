@@ -0,0 +1,102 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	OpFindFieldTypeConflicts = "FindFieldTypeConflicts"
+	OpConvertFieldType       = "ConvertFieldType"
+)
+
+// FieldType is the type of the values of a field.
+type FieldType string
+
+const (
+	FieldTypeFloat    FieldType = "float"
+	FieldTypeInteger  FieldType = "integer"
+	FieldTypeUnsigned FieldType = "unsigned"
+	FieldTypeString   FieldType = "string"
+	FieldTypeBoolean  FieldType = "boolean"
+)
+
+// Valid returns an error if t is not a field type.
+func (t FieldType) Valid() error {
+	switch t {
+	case FieldTypeFloat, FieldTypeInteger, FieldTypeUnsigned, FieldTypeString, FieldTypeBoolean:
+		return nil
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid field type %q", t),
+		}
+	}
+}
+
+// FieldTypeConflict is a field of a bucket of which values of more than one
+// type are stored. Queries reading the values of the field fail, or return
+// a table per type, depending on how the types are mixed.
+type FieldTypeConflict struct {
+	Measurement string `json:"measurement"`
+	Field       string `json:"field"`
+	// Types are the types of the values of the field, sorted by name.
+	Types []FieldType `json:"types"`
+	// SeriesN is the number of series of the field.
+	SeriesN int64 `json:"seriesN"`
+	// ConflictingSeriesN is the number of series of the field which have
+	// values of more than one type themselves.
+	ConflictingSeriesN int64 `json:"conflictingSeriesN"`
+}
+
+// FieldTypeConversion casts the values of a field of a bucket to a single
+// type, to resolve a type conflict.
+//
+// Values are cast as follows: numbers are converted to one another, with
+// floats truncated toward zero when converted to integers; booleans are 1
+// or 0 as numbers; numbers are true as booleans unless they are 0; strings
+// are parsed as numbers and booleans, and numbers and booleans formatted as
+// strings. The series of which a value cannot be cast are not converted.
+type FieldTypeConversion struct {
+	OrgID       ID        `json:"orgID"`
+	BucketID    ID        `json:"bucketID"`
+	Measurement string    `json:"measurement"`
+	Field       string    `json:"field"`
+	Type        FieldType `json:"type"`
+}
+
+// Valid returns an error if the conversion is missing the field to convert
+// or has an invalid type.
+func (c *FieldTypeConversion) Valid() error {
+	if !c.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	if !c.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucketID is required",
+		}
+	}
+	if c.Measurement == "" || c.Field == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "measurement and field are required",
+		}
+	}
+	return c.Type.Valid()
+}
+
+// FieldTypeConflictService finds the fields of a bucket with values of more
+// than one type, and converts them to a single type.
+type FieldTypeConflictService interface {
+	// FindFieldTypeConflicts returns the fields of the bucket with values
+	// of more than one type, sorted by measurement and field.
+	FindFieldTypeConflicts(ctx context.Context, orgID, bucketID ID) ([]*FieldTypeConflict, error)
+
+	// ConvertFieldType starts casting the values of the field of c to the
+	// type of c in the background, and returns the job converting them.
+	ConvertFieldType(ctx context.Context, c FieldTypeConversion) (*Job, error)
+}
@@ -0,0 +1,24 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// CacheFlushService flushes data held in memory by the storage engine to TSM
+// files, for example before taking a filesystem snapshot.
+type CacheFlushService interface {
+	// FlushBucket ensures all data written to the bucket before the call is
+	// persisted to TSM files and removed from the cache and WAL.
+	FlushBucket(ctx context.Context, orgID, bucketID ID) (*CacheFlushResult, error)
+}
+
+// CacheFlushResult describes the outcome of a cache flush.
+type CacheFlushResult struct {
+	// FlushedBytes is the size of the cache that was written to TSM files.
+	// The cache is shared by all buckets, so this may include data from
+	// buckets other than the one requested.
+	FlushedBytes uint64
+	// Duration is how long the flush took.
+	Duration time.Duration
+}
@@ -0,0 +1,189 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+)
+
+// ErrFluxFragmentNotFound is the error for a missing Flux fragment or
+// fragment version.
+const ErrFluxFragmentNotFound = "flux fragment not found"
+
+const (
+	OpFindFluxFragmentByID     = "FindFluxFragmentByID"
+	OpFindFluxFragment         = "FindFluxFragment"
+	OpFindFluxFragments        = "FindFluxFragments"
+	OpCreateFluxFragment       = "CreateFluxFragment"
+	OpUpdateFluxFragment       = "UpdateFluxFragment"
+	OpDeleteFluxFragment       = "DeleteFluxFragment"
+	OpFindFluxFragmentVersion  = "FindFluxFragmentVersion"
+	OpFindFluxFragmentVersions = "FindFluxFragmentVersions"
+)
+
+// FluxFragmentImportPrefix is the prefix of the import paths of fragments.
+// Queries import the latest version of the fragment named name with
+//
+//	import "fragments/name"
+//
+// or a given version with
+//
+//	import "fragments/name@3"
+//
+// and refer to its variables through the fragment name, or the alias of the
+// import, as they do with the packages of the standard library.
+const FluxFragmentImportPrefix = "fragments/"
+
+// FluxFragmentService manages the Flux fragments of organizations.
+type FluxFragmentService interface {
+	// FindFluxFragmentByID returns a single fragment by ID.
+	FindFluxFragmentByID(ctx context.Context, id ID) (*FluxFragment, error)
+
+	// FindFluxFragment returns the first fragment that matches filter.
+	FindFluxFragment(ctx context.Context, filter FluxFragmentFilter) (*FluxFragment, error)
+
+	// FindFluxFragments returns a list of fragments that match filter.
+	FindFluxFragments(ctx context.Context, filter FluxFragmentFilter) ([]*FluxFragment, error)
+
+	// CreateFluxFragment creates a new fragment at version 1 and sets f.ID
+	// with the new identifier.
+	CreateFluxFragment(ctx context.Context, f *FluxFragment) error
+
+	// UpdateFluxFragment updates a single fragment with changeset. A change
+	// of its Flux adds a new version of the fragment.
+	UpdateFluxFragment(ctx context.Context, id ID, upd FluxFragmentUpdate) (*FluxFragment, error)
+
+	// DeleteFluxFragment removes a fragment and all its versions by ID.
+	DeleteFluxFragment(ctx context.Context, id ID) error
+
+	// FindFluxFragmentVersion returns a single version of a fragment.
+	FindFluxFragmentVersion(ctx context.Context, id ID, version int) (*FluxFragmentVersion, error)
+
+	// FindFluxFragmentVersions returns the versions of a fragment, from the
+	// oldest to the latest.
+	FindFluxFragmentVersions(ctx context.Context, id ID) ([]*FluxFragmentVersion, error)
+}
+
+// FluxFragment is a named piece of Flux of an organization, which dashboard
+// cells and tasks import by name rather than repeat. A fragment holds only
+// imports and variable assignments, such as the filters and functions shared
+// by many queries. Every change of its Flux is kept as a new version, so
+// that a query may pin the version it imports.
+type FluxFragment struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Version is the latest version of the fragment, starting at 1.
+	Version int    `json:"version"`
+	Flux    string `json:"flux"`
+
+	CRUDLog
+}
+
+var fluxFragmentNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Valid returns an error if the fragment is invalid.
+func (f *FluxFragment) Valid() error {
+	if !f.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	// The name of a fragment is the identifier of its import.
+	if !fluxFragmentNameRegexp.MatchString(f.Name) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "flux fragment name must be a Flux identifier",
+		}
+	}
+	if _, err := ParseFluxFragment(f.Flux); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseFluxFragment parses the Flux of a fragment, and returns an error if
+// it is not valid Flux or holds anything but imports and variable
+// assignments.
+func ParseFluxFragment(flux string) (*ast.File, error) {
+	if strings.TrimSpace(flux) == "" {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  "flux fragment must not be empty",
+		}
+	}
+	pkg, err := safeParseSource(flux)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Check(pkg) > 0 {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  "invalid flux fragment",
+			Err:  ast.GetError(pkg),
+		}
+	}
+	file := pkg.Files[0]
+	if file.Package != nil {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  "flux fragment must not have a package clause",
+		}
+	}
+	for _, stmt := range file.Body {
+		if _, ok := stmt.(*ast.VariableAssignment); !ok {
+			return nil, &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("flux fragment may only hold variable assignments, found %s", stmt.Type()),
+			}
+		}
+	}
+	return file, nil
+}
+
+// FluxFragmentVersion is a version of the Flux of a fragment.
+type FluxFragmentVersion struct {
+	FragmentID ID        `json:"fragmentID"`
+	Version    int       `json:"version"`
+	Flux       string    `json:"flux"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// FluxFragmentUpdate is the changeset of a fragment. The name of a fragment
+// cannot change, since queries import it by name.
+type FluxFragmentUpdate struct {
+	Description *string `json:"description,omitempty"`
+	Flux        *string `json:"flux,omitempty"`
+}
+
+// Apply applies the changeset to f. It does not change the version of f.
+func (u FluxFragmentUpdate) Apply(f *FluxFragment) {
+	if u.Description != nil {
+		f.Description = *u.Description
+	}
+	if u.Flux != nil {
+		f.Flux = *u.Flux
+	}
+}
+
+// FluxFragmentFilter represents a set of filters that restrict the returned
+// fragments.
+type FluxFragmentFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+}
+
+// Match returns true if the fragment matches the filter.
+func (f FluxFragmentFilter) Match(ff *FluxFragment) bool {
+	return (f.ID == nil || *f.ID == ff.ID) &&
+		(f.OrgID == nil || *f.OrgID == ff.OrgID) &&
+		(f.Name == nil || *f.Name == ff.Name)
+}
@@ -0,0 +1,296 @@
+// Package fluxfragment expands the imports of Flux fragments in queries
+// into the variables of the fragments.
+//
+// The import of a fragment
+//
+//	import f "fragments/filters@2"
+//
+// becomes the assignment of an object holding the variables of the fragment
+//
+//	_fragment_f = () => {
+//		cpu = (tables=<-) => tables |> filter(fn: (r) => r._measurement == "cpu")
+//		return {cpu: cpu}
+//	}
+//	f = _fragment_f()
+//
+// so that its variables are referred to as f.cpu, as are the members of a
+// package, and may refer to each other. The imports of the standard library
+// by a fragment are moved to the file importing it, and the imports of other
+// fragments are expanded within it.
+package fluxfragment
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	platform "github.com/influxdata/influxdb/v2"
+)
+
+// IsFragmentImport returns true if imp imports a fragment.
+func IsFragmentImport(imp *ast.ImportDeclaration) bool {
+	return imp.Path != nil && strings.HasPrefix(imp.Path.Value, platform.FluxFragmentImportPrefix)
+}
+
+// HasFragmentImports returns true if a file of pkg imports a fragment.
+func HasFragmentImports(pkg *ast.Package) bool {
+	for _, file := range pkg.Files {
+		if hasFragmentImports(file) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFragmentImports(file *ast.File) bool {
+	for _, imp := range file.Imports {
+		if IsFragmentImport(imp) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseImportPath returns the name and version of the fragment imported by
+// path, such as fragments/name or fragments/name@3. The version is zero for
+// the latest version.
+func ParseImportPath(p string) (name string, version int, err error) {
+	name = strings.TrimPrefix(p, platform.FluxFragmentImportPrefix)
+	if i := strings.LastIndexByte(name, '@'); i >= 0 {
+		version, err = strconv.Atoi(name[i+1:])
+		if err != nil || version < 1 {
+			return "", 0, fmt.Errorf("invalid version of fragment import %q", p)
+		}
+		name = name[:i]
+	}
+	if name == "" {
+		return "", 0, fmt.Errorf("invalid fragment import %q", p)
+	}
+	return name, version, nil
+}
+
+// Expand replaces the imports of fragments in the files of pkg with the
+// variables of the fragments of the organization. It returns a new package
+// and leaves pkg unchanged.
+func Expand(ctx context.Context, fragments platform.FluxFragmentService, orgID platform.ID, pkg *ast.Package) (*ast.Package, error) {
+	expanded := pkg.Copy().(*ast.Package)
+	for i, file := range expanded.Files {
+		if !hasFragmentImports(file) {
+			continue
+		}
+		e := &expander{
+			ctx:       ctx,
+			fragments: fragments,
+			orgID:     orgID,
+			imported:  make(map[string]string),
+			visiting:  make(map[string]bool),
+		}
+		if err := e.expandFile(file); err != nil {
+			return nil, err
+		}
+
+		// The nodes of the fragments are parsed again with the file, so
+		// that they have the locations of the expanded query.
+		parsed := parser.ParseSource(ast.Format(file))
+		if ast.Check(parsed) > 0 {
+			return nil, ast.GetError(parsed)
+		}
+		parsed.Files[0].Name = file.Name
+		expanded.Files[i] = parsed.Files[0]
+	}
+	return expanded, nil
+}
+
+// ExpandQuery returns query with the imports of fragments of the
+// organization replaced with their variables. A query without fragments is
+// returned as is.
+func ExpandQuery(ctx context.Context, fragments platform.FluxFragmentService, orgID platform.ID, query string) (string, error) {
+	// Most queries do not import fragments, and are not parsed twice.
+	if !strings.Contains(query, platform.FluxFragmentImportPrefix) {
+		return query, nil
+	}
+	pkg := parser.ParseSource(query)
+	if ast.Check(pkg) > 0 || !HasFragmentImports(pkg) {
+		// The errors are reported by the compiler of the query.
+		return query, nil
+	}
+	expanded, err := Expand(ctx, fragments, orgID, pkg)
+	if err != nil {
+		return "", err
+	}
+	return ast.Format(expanded), nil
+}
+
+type expander struct {
+	ctx       context.Context
+	fragments platform.FluxFragmentService
+	orgID     platform.ID
+
+	// imported maps the identifiers of the imports of the standard library
+	// of the file to their paths.
+	imported map[string]string
+	// visiting holds the names of the fragments being expanded, to detect
+	// fragments importing each other.
+	visiting map[string]bool
+	// hoisted are the imports of the standard library by fragments which
+	// the file does not import.
+	hoisted []*ast.ImportDeclaration
+}
+
+func (e *expander) expandFile(file *ast.File) error {
+	var imports []*ast.ImportDeclaration
+	for _, imp := range file.Imports {
+		if !IsFragmentImport(imp) {
+			imports = append(imports, imp)
+			e.imported[importIdentifier(imp)] = imp.Path.Value
+		}
+	}
+
+	var stmts []ast.Statement
+	for _, imp := range file.Imports {
+		if !IsFragmentImport(imp) {
+			continue
+		}
+		s, err := e.expandImport(imp)
+		if err != nil {
+			return err
+		}
+		stmts = append(stmts, s...)
+	}
+
+	file.Imports = append(imports, e.hoisted...)
+	file.Body = append(stmts, file.Body...)
+	return nil
+}
+
+// expandImport returns the statements assigning the variables of the
+// fragment imported by imp.
+func (e *expander) expandImport(imp *ast.ImportDeclaration) ([]ast.Statement, error) {
+	name, version, err := ParseImportPath(imp.Path.Value)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Err:  err,
+		}
+	}
+	if e.visiting[name] {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("fragment %q imports itself", name),
+		}
+	}
+	e.visiting[name] = true
+	defer delete(e.visiting, name)
+
+	file, err := e.findFragment(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []ast.Statement
+	for _, fi := range file.Imports {
+		if IsFragmentImport(fi) {
+			s, err := e.expandImport(fi)
+			if err != nil {
+				return nil, err
+			}
+			body = append(body, s...)
+			continue
+		}
+		if err := e.hoist(fi); err != nil {
+			return nil, err
+		}
+	}
+
+	ret := &ast.ObjectExpression{}
+	for _, stmt := range file.Body {
+		body = append(body, stmt)
+		// Fragments hold only variable assignments.
+		id := stmt.(*ast.VariableAssignment).ID
+		ret.Properties = append(ret.Properties, &ast.Property{
+			Key:   &ast.Identifier{Name: id.Name},
+			Value: &ast.Identifier{Name: id.Name},
+		})
+	}
+	body = append(body, &ast.ReturnStatement{Argument: ret})
+
+	id := name
+	if imp.As != nil {
+		id = imp.As.Name
+	}
+	fn := "_fragment_" + id
+	return []ast.Statement{
+		&ast.VariableAssignment{
+			ID: &ast.Identifier{Name: fn},
+			Init: &ast.FunctionExpression{
+				Body: &ast.Block{Body: body},
+			},
+		},
+		&ast.VariableAssignment{
+			ID: &ast.Identifier{Name: id},
+			Init: &ast.CallExpression{
+				Callee: &ast.Identifier{Name: fn},
+			},
+		},
+	}, nil
+}
+
+// findFragment returns the parsed Flux of the version of the fragment named
+// name, or of its latest version if version is zero.
+func (e *expander) findFragment(name string, version int) (*ast.File, error) {
+	f, err := e.fragments.FindFluxFragment(e.ctx, platform.FluxFragmentFilter{
+		OrgID: &e.orgID,
+		Name:  &name,
+	})
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.ErrorCode(err),
+			Msg:  fmt.Sprintf("unable to import fragment %q", name),
+			Err:  err,
+		}
+	}
+
+	flux := f.Flux
+	if version > 0 && version != f.Version {
+		fv, err := e.fragments.FindFluxFragmentVersion(e.ctx, f.ID, version)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.ErrorCode(err),
+				Msg:  fmt.Sprintf("unable to import version %d of fragment %q", version, name),
+				Err:  err,
+			}
+		}
+		flux = fv.Flux
+	}
+	return platform.ParseFluxFragment(flux)
+}
+
+// hoist moves an import of the standard library by a fragment to the file,
+// unless the file already imports it.
+func (e *expander) hoist(imp *ast.ImportDeclaration) error {
+	id := importIdentifier(imp)
+	if p, ok := e.imported[id]; ok {
+		if p != imp.Path.Value {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("import of %q by a fragment conflicts with the import of %q as %s", imp.Path.Value, p, id),
+			}
+		}
+		return nil
+	}
+	e.imported[id] = imp.Path.Value
+	e.hoisted = append(e.hoisted, imp)
+	return nil
+}
+
+// importIdentifier returns the identifier imp binds its package to.
+func importIdentifier(imp *ast.ImportDeclaration) string {
+	if imp.As != nil {
+		return imp.As.Name
+	}
+	return path.Base(imp.Path.Value)
+}
@@ -0,0 +1,106 @@
+package fluxfragment
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestExpandQuery(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := &influxdb.FluxFragment{
+		OrgID: org.ID,
+		Name:  "hosts",
+		Flux: `import "strings"
+prod = (r) => strings.hasPrefix(v: r.host, prefix: "prod-")`,
+	}
+	filters := &influxdb.FluxFragment{
+		OrgID: org.ID,
+		Name:  "filters",
+		Flux: `import "fragments/hosts"
+cpu = (tables=<-) => tables |> filter(fn: (r) => r._measurement == "cpu")`,
+	}
+	for _, f := range []*influxdb.FluxFragment{hosts, filters} {
+		if err := store.CreateFluxFragment(ctx, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flux := `import "fragments/hosts"
+import "strings"
+cpu = (tables=<-) => tables |> filter(fn: (r) => r._measurement == "cpu" and hosts.prod(r))`
+	if _, err := store.UpdateFluxFragment(ctx, filters.ID, influxdb.FluxFragmentUpdate{Flux: &flux}); err != nil {
+		t.Fatal(err)
+	}
+
+	q := `import "fragments/filters"
+import f1 "fragments/filters@1"
+
+from(bucket: "telegraf") |> range(start: -1h) |> filters.cpu()`
+	expanded, err := ExpandQuery(ctx, store, org.ID, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := parser.ParseSource(expanded)
+	if ast.Check(pkg) > 0 {
+		t.Fatalf("expanded query is invalid: %v\n%s", ast.GetError(pkg), expanded)
+	}
+	file := pkg.Files[0]
+	if len(file.Imports) != 1 || file.Imports[0].Path.Value != "strings" {
+		t.Errorf("expected the imports of the fragments to be replaced by their import of strings, got:\n%s", expanded)
+	}
+	for _, want := range []string{
+		"filters = _fragment_filters()",
+		"f1 = _fragment_f1()",
+		"hosts = _fragment_hosts()",
+		`r._measurement == "cpu" and hosts.prod(r)`,
+		"return {cpu: cpu}",
+	} {
+		if !strings.Contains(expanded, want) {
+			t.Errorf("expected expanded query to contain %q, got:\n%s", want, expanded)
+		}
+	}
+
+	// Queries without fragments are left as they are.
+	plain := `from(bucket: "telegraf") |> range(start: -1h)`
+	if got, err := ExpandQuery(ctx, store, org.ID, plain); err != nil || got != plain {
+		t.Errorf("expected a query without fragments to be unchanged, got %q, %v", got, err)
+	}
+
+	for _, q := range []string{
+		`import "fragments/missing"` + "\n" + plain,
+		`import "fragments/filters@9"` + "\n" + plain,
+		`import "fragments/filters@x"` + "\n" + plain,
+	} {
+		if _, err := ExpandQuery(ctx, store, org.ID, q); err == nil {
+			t.Errorf("expected an error expanding %q", q)
+		}
+	}
+
+	// Fragments may not import each other.
+	cyclic := `import "fragments/filters"
+x = 1`
+	if _, err := store.UpdateFluxFragment(ctx, hosts.ID, influxdb.FluxFragmentUpdate{Flux: &cyclic}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExpandQuery(ctx, store, org.ID, `import "fragments/filters"`+"\n"+plain); err == nil {
+		t.Error("expected fragments importing each other to fail")
+	}
+}
@@ -0,0 +1,57 @@
+package fluxfragment
+
+import (
+	"context"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+// QueryService is a query.AsyncQueryService which expands the imports of
+// fragments in Flux queries before passing them on. Fragments are looked up
+// in the organization of the request.
+type QueryService struct {
+	s         query.AsyncQueryService
+	fragments platform.FluxFragmentService
+}
+
+var _ query.AsyncQueryService = (*QueryService)(nil)
+
+// NewQueryService returns a QueryService passing the queries to s, with the
+// fragments of fragments.
+func NewQueryService(s query.AsyncQueryService, fragments platform.FluxFragmentService) *QueryService {
+	return &QueryService{
+		s:         s,
+		fragments: fragments,
+	}
+}
+
+// Query expands the fragments imported by the query of req and submits it.
+// The queries of other languages are submitted as is.
+func (s *QueryService) Query(ctx context.Context, req *query.Request) (flux.Query, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	switch c := req.Compiler.(type) {
+	case lang.FluxCompiler:
+		q, err := ExpandQuery(ctx, s.fragments, req.OrganizationID, c.Query)
+		if err != nil {
+			return nil, err
+		}
+		c.Query = q
+		req.Compiler = c
+	case lang.ASTCompiler:
+		if c.AST != nil && HasFragmentImports(c.AST) {
+			pkg, err := Expand(ctx, s.fragments, req.OrganizationID, c.AST)
+			if err != nil {
+				return nil, err
+			}
+			c.AST = pkg
+			req.Compiler = c
+		}
+	}
+	return s.s.Query(ctx, req)
+}
@@ -0,0 +1,280 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// defaultFlapThreshold is the duration under which resolved alerts count as
+// flaps when the flapThreshold parameter is not set.
+const defaultFlapThreshold = 5 * time.Minute
+
+// AlertHistoryBackend is all services and associated parameters required to construct
+// the AlertHistoryHandler.
+type AlertHistoryBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	AlertHistoryService influxdb.AlertHistoryService
+}
+
+// NewAlertHistoryBackend returns a new instance of AlertHistoryBackend.
+func NewAlertHistoryBackend(log *zap.Logger, b *APIBackend) *AlertHistoryBackend {
+	return &AlertHistoryBackend{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		log:                 log,
+		AlertHistoryService: b.AlertHistoryService,
+	}
+}
+
+// AlertHistoryHandler represents an HTTP API handler for the history of the
+// statuses of checks.
+type AlertHistoryHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	AlertHistoryService influxdb.AlertHistoryService
+}
+
+const (
+	prefixAlertHistory    = "/api/v2/alertHistory"
+	alertHistoryAnalytics = "/api/v2/alertHistory/analytics"
+)
+
+// NewAlertHistoryHandler returns a new instance of AlertHistoryHandler.
+func NewAlertHistoryHandler(log *zap.Logger, b *AlertHistoryBackend) *AlertHistoryHandler {
+	h := &AlertHistoryHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		AlertHistoryService: b.AlertHistoryService,
+	}
+
+	h.HandlerFunc("GET", prefixAlertHistory, h.handleGetAlertTransitions)
+	h.HandlerFunc("GET", alertHistoryAnalytics, h.handleGetAlertAnalytics)
+
+	return h
+}
+
+type alertTransitionsResponse struct {
+	Links       map[string]string           `json:"links"`
+	Transitions []*influxdb.AlertTransition `json:"transitions"`
+}
+
+type alertAnalyticsResponse struct {
+	Links  map[string]string          `json:"links"`
+	Checks []influxdb.AlertCheckStats `json:"checks"`
+}
+
+func alertHistoryLinks(path string, filter influxdb.AlertTransitionFilter) map[string]string {
+	qp := url.Values{}
+	for _, p := range alertHistoryQueryParams(filter) {
+		qp.Set(p[0], p[1])
+	}
+	return map[string]string{
+		"self": path + "?" + qp.Encode(),
+	}
+}
+
+func decodeAlertTransitionFilter(r *http.Request) (*influxdb.AlertTransitionFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.AlertTransitionFilter{}
+
+	orgID, err := influxdb.IDFromString(qp.Get("orgID"))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	filter.OrgID = orgID
+
+	if v := qp.Get("checkID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid checkID",
+				Err:  err,
+			}
+		}
+		filter.CheckID = id
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"start", &filter.Start},
+		{"stop", &filter.Stop},
+	} {
+		v := qp.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid RFC3339Nano for field %s, please format your time with RFC3339Nano format, example: 2009-01-02T23:00:00Z", p.name),
+			}
+		}
+		*p.dst = t
+	}
+	if !filter.Start.IsZero() && !filter.Stop.IsZero() && !filter.Start.Before(filter.Stop) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "start must be before stop",
+		}
+	}
+	return filter, nil
+}
+
+// handleGetAlertTransitions is the HTTP handler for the GET /api/v2/alertHistory route.
+func (h *AlertHistoryHandler) handleGetAlertTransitions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeAlertTransitionFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "limit must be a positive integer",
+			}, w)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	ts, err := h.AlertHistoryService.FindAlertTransitions(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Alert transitions retrieved", zap.Int("count", len(ts)))
+
+	res := &alertTransitionsResponse{
+		Links:       alertHistoryLinks(prefixAlertHistory, *filter),
+		Transitions: ts,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetAlertAnalytics is the HTTP handler for the GET /api/v2/alertHistory/analytics route.
+func (h *AlertHistoryHandler) handleGetAlertAnalytics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeAlertTransitionFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	flapThreshold := defaultFlapThreshold
+	if v := r.URL.Query().Get("flapThreshold"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "flapThreshold must be a positive duration",
+			}, w)
+			return
+		}
+		flapThreshold = d
+	}
+
+	ts, err := h.AlertHistoryService.FindAlertTransitions(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Alert analytics computed", zap.Int("transitions", len(ts)))
+
+	res := &alertAnalyticsResponse{
+		Links:  alertHistoryLinks(alertHistoryAnalytics, *filter),
+		Checks: influxdb.AnalyzeAlertTransitions(ts, flapThreshold),
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// AlertHistoryService connects to Influx via HTTP using tokens to find the
+// history of the statuses of checks.
+type AlertHistoryService struct {
+	Client *httpc.Client
+}
+
+func alertHistoryQueryParams(filter influxdb.AlertTransitionFilter) [][2]string {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.CheckID != nil {
+		params = append(params, [2]string{"checkID", filter.CheckID.String()})
+	}
+	if !filter.Start.IsZero() {
+		params = append(params, [2]string{"start", filter.Start.Format(time.RFC3339Nano)})
+	}
+	if !filter.Stop.IsZero() {
+		params = append(params, [2]string{"stop", filter.Stop.Format(time.RFC3339Nano)})
+	}
+	return params
+}
+
+// FindAlertTransitions returns the transitions that match filter.
+func (s *AlertHistoryService) FindAlertTransitions(ctx context.Context, filter influxdb.AlertTransitionFilter) ([]*influxdb.AlertTransition, error) {
+	params := alertHistoryQueryParams(filter)
+	if filter.Limit > 0 {
+		params = append(params, [2]string{"limit", strconv.Itoa(filter.Limit)})
+	}
+
+	var res alertTransitionsResponse
+	err := s.Client.
+		Get(prefixAlertHistory).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Transitions, nil
+}
+
+// FindAlertCheckStats returns the analytics of the alerts of the checks of
+// the transitions that match filter.
+func (s *AlertHistoryService) FindAlertCheckStats(ctx context.Context, filter influxdb.AlertTransitionFilter, flapThreshold time.Duration) ([]influxdb.AlertCheckStats, error) {
+	params := alertHistoryQueryParams(filter)
+	if flapThreshold > 0 {
+		params = append(params, [2]string{"flapThreshold", flapThreshold.String()})
+	}
+
+	var res alertAnalyticsResponse
+	err := s.Client.
+		Get(alertHistoryAnalytics).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Checks, nil
+}
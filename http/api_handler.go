@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/influxdata/influxdb/v2"
@@ -26,9 +27,16 @@ type APIHandler struct {
 // an APIHandler.
 type APIBackend struct {
 	AssetsPath string // if empty then assets are served from bindata.
+	Branding   Branding
 	Logger     *zap.Logger
 	influxdb.HTTPErrorHandler
 	SessionRenewDisabled bool
+	// Authenticators is the chain of authenticators of requests. The
+	// DefaultAuthenticators are used when nil.
+	Authenticators []Authenticator
+	// AuthorizationUsageInterval is the minimum interval between recording
+	// the use of a token. Zero disables recording token usage.
+	AuthorizationUsageInterval time.Duration
 	// MaxBatchSizeBytes is the maximum number of bytes which can be written
 	// in a single points batch
 	MaxBatchSizeBytes int64
@@ -45,6 +53,10 @@ type APIBackend struct {
 	// write request. A value of zero specifies there is no limit.
 	WriteParserMaxValues int
 
+	// WriteDeduplicator, if set, drops write batches which were already
+	// written to a bucket within its dedupe window.
+	WriteDeduplicator *WriteDeduplicator
+
 	NewBucketService func(*influxdb.Source) (influxdb.BucketService, error)
 	NewQueryService  func(*influxdb.Source) (query.ProxyQueryService, error)
 
@@ -57,7 +69,35 @@ type APIBackend struct {
 	DeleteService                   influxdb.DeleteService
 	BackupService                   influxdb.BackupService
 	KVBackupService                 influxdb.KVBackupService
+	CacheFlushService               influxdb.CacheFlushService
+	BlockQuarantineService          influxdb.BlockQuarantineService
+	SeriesCardinalityService        influxdb.SeriesCardinalityService
+	FieldTypeConflictService        influxdb.FieldTypeConflictService
+	WriteSchemaSampleService        influxdb.WriteSchemaSampleService
+	BucketSnapshotService           influxdb.BucketSnapshotService
+	BucketMetadataService           influxdb.BucketMetadataService
+	LastValueService                influxdb.LastValueService
+	DBRPImportService               influxdb.DBRPImportService
+	DBRPMappingService              influxdb.DBRPMappingService
+	LegalHoldService                influxdb.LegalHoldService
+	OrgDomainService                influxdb.OrgDomainService
+	QueryPolicyService              influxdb.QueryPolicyService
+	LifecyclePolicyService          influxdb.LifecyclePolicyService
+	ReportService                   influxdb.ReportService
+	BucketFamilyService             influxdb.BucketFamilyService
+	BucketFamilyRouter              influxdb.BucketFamilyRouter
+	FluxFragmentService             influxdb.FluxFragmentService
+	WebhookService                  influxdb.WebhookService
+	HTTPSinkService                 influxdb.HTTPSinkService
+	SMTPConfigService               influxdb.SMTPConfigService
+	SlackThreadService              influxdb.SlackThreadService
+	ScriptRevisionService           influxdb.ScriptRevisionService
+	AlertHistoryService             influxdb.AlertHistoryService
+	LimitAlertsService              influxdb.LimitAlertsService
+	ParquetExportService            influxdb.ParquetExportService
+	FeatureFlagService              influxdb.FeatureFlagService
 	AuthorizationService            influxdb.AuthorizationService
+	AuthorizationUsageService       influxdb.AuthorizationUsageService
 	BucketService                   influxdb.BucketService
 	SessionService                  influxdb.SessionService
 	UserService                     influxdb.UserService
@@ -66,12 +106,24 @@ type APIBackend struct {
 	LabelService                    influxdb.LabelService
 	DashboardService                influxdb.DashboardService
 	DashboardOperationLogService    influxdb.DashboardOperationLogService
+	DashboardCellRenderer           influxdb.DashboardCellRenderer
 	BucketOperationLogService       influxdb.BucketOperationLogService
 	UserOperationLogService         influxdb.UserOperationLogService
 	OrganizationOperationLogService influxdb.OrganizationOperationLogService
 	SourceService                   influxdb.SourceService
 	VariableService                 influxdb.VariableService
 	PasswordsService                influxdb.PasswordsService
+	PasswordLockoutService          influxdb.PasswordLockoutService
+	UserPreferencesService          influxdb.UserPreferencesService
+	ResourceGrantService            influxdb.ResourceGrantService
+	RoleService                     influxdb.RoleService
+	SignedQueryService              influxdb.SignedQueryService
+	QueryResultSpill                *QueryResultSpill
+	OrgDeletionService              influxdb.OrgDeletionService
+	JobService                      influxdb.JobService
+	StaleResourceReportService      influxdb.StaleResourceReportService
+	ResourceActivityService         influxdb.ResourceActivityService
+	SystemInfoService               influxdb.SystemInfoService
 	InfluxQLService                 query.ProxyQueryService
 	FluxService                     query.ProxyQueryService
 	TaskService                     influxdb.TaskService
@@ -101,6 +153,10 @@ func (b *APIBackend) PrometheusCollectors() []prometheus.Collector {
 		cs = append(cs, pc.PrometheusCollectors()...)
 	}
 
+	if b.WriteDeduplicator != nil {
+		cs = append(cs, b.WriteDeduplicator.PrometheusCollectors()...)
+	}
+
 	return cs
 }
 
@@ -133,6 +189,8 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	bucketBackend := NewBucketBackend(b.Logger.With(zap.String("handler", "bucket")), b)
 	bucketBackend.BucketService = authorizer.NewBucketService(b.BucketService, noAuthUserResourceMappingService)
+	bucketBackend.BucketMetadataService = authorizer.NewBucketMetadataService(b.BucketMetadataService)
+	bucketBackend.LastValueService = authorizer.NewLastValueService(b.LastValueService)
 	h.Mount(prefixBuckets, NewBucketHandler(b.Logger, bucketBackend))
 
 	checkBackend := NewCheckBackend(b.Logger.With(zap.String("handler", "check")), b)
@@ -154,10 +212,15 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	h.Mount(prefixDocuments, NewDocumentHandler(documentBackend))
 
 	fluxBackend := NewFluxBackend(b.Logger.With(zap.String("handler", "query")), b)
+	fluxBackend.SignedQueryService = authorizer.NewSignedQueryService(b.SignedQueryService)
 	h.Mount(prefixQuery, NewFluxHandler(b.Logger, fluxBackend))
 
 	h.Mount(prefixLabels, NewLabelHandler(b.Logger, b.LabelService, b.HTTPErrorHandler))
 
+	resourceGrantBackend := NewResourceGrantBackend(b.Logger.With(zap.String("handler", "grant")), b)
+	resourceGrantBackend.ResourceGrantService = authorizer.NewResourceGrantService(b.ResourceGrantService)
+	h.Mount(prefixResourceGrants, NewResourceGrantHandler(b.Logger, resourceGrantBackend))
+
 	notificationEndpointBackend := NewNotificationEndpointBackend(b.Logger.With(zap.String("handler", "notificationEndpoint")), b)
 	notificationEndpointBackend.NotificationEndpointService = authorizer.NewNotificationEndpointService(b.NotificationEndpointService,
 		b.UserResourceMappingService, b.OrganizationService)
@@ -179,6 +242,27 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 		b.OrganizationService)
 	h.Mount(prefixTargets, NewScraperHandler(b.Logger, scraperBackend))
 
+	roleBackend := NewRoleBackend(b.Logger.With(zap.String("handler", "role")), b)
+	roleBackend.RoleService = authorizer.NewRoleService(b.RoleService)
+	h.Mount(prefixRoles, NewRoleHandler(b.Logger, roleBackend))
+
+	orgDeletionBackend := NewOrgDeletionBackend(b.Logger.With(zap.String("handler", "org_deletion")), b)
+	orgDeletionBackend.OrgDeletionService = authorizer.NewOrgDeletionService(b.OrgDeletionService)
+	h.Mount(prefixOrgDeletions, NewOrgDeletionHandler(b.Logger, orgDeletionBackend))
+
+	jobBackend := NewJobBackend(b.Logger.With(zap.String("handler", "job")), b)
+	jobBackend.JobService = authorizer.NewJobService(b.JobService)
+	h.Mount(prefixJobs, NewJobHandler(b.Logger, jobBackend))
+
+	staleResourceBackend := NewStaleResourceBackend(b.Logger.With(zap.String("handler", "stale_resource")), b)
+	staleResourceBackend.StaleResourceReportService = authorizer.NewStaleResourceReportService(b.StaleResourceReportService)
+	h.Mount(prefixStaleResources, NewStaleResourceHandler(b.Logger, staleResourceBackend))
+
+	scimBackend := NewSCIMBackend(b.Logger.With(zap.String("handler", "scim")), b)
+	scimBackend.UserService = authorizer.NewUserService(b.UserService)
+	scimBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	h.Mount(prefixSCIM, NewSCIMHandler(b.Logger, scimBackend))
+
 	sessionBackend := newSessionBackend(b.Logger.With(zap.String("handler", "session")), b)
 	sessionHandler := NewSessionHandler(b.Logger, sessionBackend)
 	h.Mount(prefixSignIn, sessionHandler)
@@ -205,11 +289,19 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	userBackend := NewUserBackend(b.Logger.With(zap.String("handler", "user")), b)
 	userBackend.UserService = authorizer.NewUserService(b.UserService)
 	userBackend.PasswordsService = authorizer.NewPasswordService(b.PasswordsService)
+	userBackend.PasswordLockoutService = authorizer.NewPasswordLockoutService(b.PasswordLockoutService)
+	userBackend.UserPreferencesService = authorizer.NewUserPreferencesService(b.UserPreferencesService)
 	userHandler := NewUserHandler(b.Logger, userBackend)
 	h.Mount(prefixMe, userHandler)
 	h.Mount(prefixUsers, userHandler)
 	h.Mount("/api/v2/flags", b.FlagsHandler)
 
+	featureFlagBackend := NewFeatureFlagBackend(b.Logger.With(zap.String("handler", "feature_flag")), b)
+	featureFlagBackend.FeatureFlagService = authorizer.NewFeatureFlagService(b.FeatureFlagService)
+	featureFlagHandler := NewFeatureFlagHandler(b.Logger, featureFlagBackend)
+	h.Mount(prefixFeatureFlagSettings, featureFlagHandler)
+	h.Mount(prefixFeatureFlagChanges, featureFlagHandler)
+
 	variableBackend := NewVariableBackend(b.Logger.With(zap.String("handler", "variable")), b)
 	variableBackend.VariableService = authorizer.NewVariableService(b.VariableService)
 	h.Mount(prefixVariables, NewVariableHandler(b.Logger, variableBackend))
@@ -218,12 +310,116 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	backupBackend.BackupService = authorizer.NewBackupService(backupBackend.BackupService)
 	h.Mount(prefixBackup, NewBackupHandler(backupBackend))
 
+	flushBackend := NewFlushBackend(b)
+	flushBackend.CacheFlushService = authorizer.NewCacheFlushService(flushBackend.CacheFlushService)
+	h.Mount(prefixFlush, NewFlushHandler(flushBackend))
+
+	quarantineBackend := NewBlockQuarantineBackend(b)
+	quarantineBackend.BlockQuarantineService = authorizer.NewBlockQuarantineService(quarantineBackend.BlockQuarantineService)
+	h.Mount(prefixQuarantine, NewBlockQuarantineHandler(quarantineBackend))
+
+	cardinalityBackend := NewSeriesCardinalityBackend(b)
+	cardinalityBackend.SeriesCardinalityService = authorizer.NewSeriesCardinalityService(cardinalityBackend.SeriesCardinalityService)
+	h.Mount(prefixCardinality, NewSeriesCardinalityHandler(cardinalityBackend))
+
+	fieldTypeBackend := NewFieldTypeConflictBackend(b)
+	fieldTypeBackend.FieldTypeConflictService = authorizer.NewFieldTypeConflictService(fieldTypeBackend.FieldTypeConflictService)
+	h.Mount(prefixFieldTypeConflicts, NewFieldTypeConflictHandler(fieldTypeBackend))
+
+	writeSchemaSampleBackend := NewWriteSchemaSampleBackend(b)
+	writeSchemaSampleBackend.WriteSchemaSampleService = authorizer.NewWriteSchemaSampleService(writeSchemaSampleBackend.WriteSchemaSampleService)
+	h.Mount(prefixWriteSchemaSample, NewWriteSchemaSampleHandler(writeSchemaSampleBackend))
+
+	snapshotBackend := NewBucketSnapshotBackend(b.Logger.With(zap.String("handler", "snapshot")), b)
+	snapshotBackend.BucketSnapshotService = authorizer.NewBucketSnapshotService(b.BucketSnapshotService)
+	h.Mount(prefixSnapshots, NewBucketSnapshotHandler(b.Logger, snapshotBackend))
+
+	lifecycleBackend := NewLifecyclePolicyBackend(b.Logger.With(zap.String("handler", "lifecycle_policy")), b)
+	lifecycleBackend.LifecyclePolicyService = authorizer.NewLifecyclePolicyService(b.LifecyclePolicyService)
+	h.Mount(prefixLifecyclePolicies, NewLifecyclePolicyHandler(b.Logger, lifecycleBackend))
+
+	reportBackend := NewReportBackend(b.Logger.With(zap.String("handler", "report")), b)
+	reportBackend.ReportService = authorizer.NewReportService(b.ReportService)
+	h.Mount(prefixReports, NewReportHandler(b.Logger, reportBackend))
+
+	bucketFamilyBackend := NewBucketFamilyBackend(b.Logger.With(zap.String("handler", "bucket_family")), b)
+	bucketFamilyBackend.BucketFamilyService = authorizer.NewBucketFamilyService(b.BucketFamilyService)
+	h.Mount(prefixBucketFamilies, NewBucketFamilyHandler(b.Logger, bucketFamilyBackend))
+
+	fluxFragmentBackend := NewFluxFragmentBackend(b.Logger.With(zap.String("handler", "flux_fragment")), b)
+	fluxFragmentBackend.FluxFragmentService = authorizer.NewFluxFragmentService(b.FluxFragmentService)
+	h.Mount(prefixFluxFragments, NewFluxFragmentHandler(b.Logger, fluxFragmentBackend))
+
+	webhookBackend := NewWebhookBackend(b.Logger.With(zap.String("handler", "webhook")), b)
+	webhookBackend.WebhookService = authorizer.NewWebhookService(b.WebhookService)
+	h.Mount(prefixWebhooks, NewWebhookHandler(b.Logger, webhookBackend))
+
+	httpSinkBackend := NewHTTPSinkBackend(b.Logger.With(zap.String("handler", "http_sink")), b)
+	httpSinkBackend.HTTPSinkService = authorizer.NewHTTPSinkService(b.HTTPSinkService)
+	h.Mount(prefixHTTPSinks, NewHTTPSinkHandler(b.Logger, httpSinkBackend))
+
+	smtpConfigBackend := NewSMTPConfigBackend(b.Logger.With(zap.String("handler", "smtp_config")), b)
+	smtpConfigBackend.SMTPConfigService = authorizer.NewSMTPConfigService(b.SMTPConfigService)
+	h.Mount(prefixSMTP, NewSMTPConfigHandler(b.Logger, smtpConfigBackend))
+
+	h.Mount(prefixBranding, NewBrandingHandler(b.Logger.With(zap.String("handler", "branding")), b.HTTPErrorHandler, b.Branding))
+	h.Mount(prefixCapabilities, NewCapabilitiesHandler(b.Logger.With(zap.String("handler", "capabilities")), b.HTTPErrorHandler, NewCapabilities(b)))
+
+	systemInfoBackend := NewSystemInfoBackend(b.Logger.With(zap.String("handler", "system_info")), b)
+	systemInfoBackend.SystemInfoService = authorizer.NewSystemInfoService(b.SystemInfoService)
+	h.Mount(prefixSystem, NewSystemInfoHandler(b.Logger, systemInfoBackend))
+
+	slackAppBackend := NewSlackAppBackend(b.Logger.With(zap.String("handler", "slack_app")), b)
+	slackAppBackend.SlackThreadService = authorizer.NewSlackThreadService(b.SlackThreadService)
+	h.Mount(prefixSlack, NewSlackAppHandler(b.Logger, slackAppBackend))
+
+	scriptRevisionBackend := NewScriptRevisionBackend(b.Logger.With(zap.String("handler", "script_revision")), b)
+	scriptRevisionBackend.ScriptRevisionService = authorizer.NewScriptRevisionService(b.ScriptRevisionService)
+	scriptRevisionBackend.TaskService = authorizer.NewTaskService(taskLogger, b.TaskService)
+	scriptRevisionBackend.CheckService = authorizer.NewCheckService(b.CheckService,
+		b.UserResourceMappingService, b.OrganizationService)
+	scriptRevisionBackend.NotificationRuleStore = authorizer.NewNotificationRuleStore(b.NotificationRuleStore,
+		b.UserResourceMappingService, b.OrganizationService)
+	h.Mount(prefixScriptRevisions, NewScriptRevisionHandler(b.Logger, scriptRevisionBackend))
+
+	alertHistoryBackend := NewAlertHistoryBackend(b.Logger.With(zap.String("handler", "alert_history")), b)
+	alertHistoryBackend.AlertHistoryService = authorizer.NewAlertHistoryService(b.AlertHistoryService)
+	h.Mount(prefixAlertHistory, NewAlertHistoryHandler(b.Logger, alertHistoryBackend))
+
+	limitAlertsBackend := NewLimitAlertsBackend(b.Logger.With(zap.String("handler", "limit_alerts")), b)
+	limitAlertsBackend.LimitAlertsService = authorizer.NewLimitAlertsService(b.LimitAlertsService)
+	h.Mount(prefixLimitAlerts, NewLimitAlertsHandler(b.Logger, limitAlertsBackend))
+
+	dbrpBackend := NewDBRPBackend(b.Logger.With(zap.String("handler", "dbrp")), b)
+	dbrpBackend.DBRPImportService = authorizer.NewDBRPImportService(b.DBRPImportService)
+	dbrpBackend.DBRPMappingService = authorizer.NewDBRPMappingService(b.DBRPMappingService)
+	h.Mount(prefixDBRPs, NewDBRPHandler(b.Logger, dbrpBackend))
+
+	legalHoldBackend := NewLegalHoldBackend(b.Logger.With(zap.String("handler", "legal_hold")), b)
+	legalHoldBackend.LegalHoldService = authorizer.NewLegalHoldService(b.LegalHoldService)
+	h.Mount(prefixLegalHolds, NewLegalHoldHandler(b.Logger, legalHoldBackend))
+
+	orgDomainBackend := NewOrgDomainBackend(b.Logger.With(zap.String("handler", "org_domain")), b)
+	orgDomainBackend.OrgDomainService = authorizer.NewOrgDomainService(b.OrgDomainService)
+	h.Mount(prefixOrgDomains, NewOrgDomainHandler(b.Logger, orgDomainBackend))
+
+	queryPolicyBackend := NewQueryPolicyBackend(b.Logger.With(zap.String("handler", "query_policy")), b)
+	queryPolicyBackend.QueryPolicyService = authorizer.NewQueryPolicyService(b.QueryPolicyService)
+	queryPolicyHandler := NewQueryPolicyHandler(b.Logger, queryPolicyBackend)
+	h.Mount(prefixQueryPolicies, queryPolicyHandler)
+	h.Mount(prefixQueryPolicyEvaluations, queryPolicyHandler)
+
+	parquetExportBackend := NewParquetExportBackend(b.Logger.With(zap.String("handler", "parquet_export")), b)
+	parquetExportBackend.ParquetExportService = authorizer.NewParquetExportService(b.ParquetExportService)
+	h.Mount(prefixParquetExport, NewParquetExportHandler(b.Logger, parquetExportBackend))
+
 	writeBackend := NewWriteBackend(b.Logger.With(zap.String("handler", "write")), b)
 	h.Mount(prefixWrite, NewWriteHandler(b.Logger, writeBackend,
 		WithMaxBatchSizeBytes(b.MaxBatchSizeBytes),
 		WithParserMaxBytes(b.WriteParserMaxBytes),
 		WithParserMaxLines(b.WriteParserMaxLines),
 		WithParserMaxValues(b.WriteParserMaxValues),
+		WithWriteDeduplicator(b.WriteDeduplicator),
 	))
 
 	for _, o := range opts {
@@ -238,6 +434,7 @@ var apiLinks = map[string]interface{}{
 	"authorizations": "/api/v2/authorizations",
 	"backup":         "/api/v2/backup",
 	"buckets":        "/api/v2/buckets",
+	"capabilities":   "/api/v2/capabilities",
 	"dashboards":     "/api/v2/dashboards",
 	"external": map[string]string{
 		"statusFeed": "https://www.influxdata.com/feed/json",
@@ -265,6 +462,7 @@ var apiLinks = map[string]interface{}{
 		"metrics": "/metrics",
 		"debug":   "/debug/pprof",
 		"health":  "/health",
+		"info":    "/api/v2/system",
 	},
 	"tasks":     "/api/v2/tasks",
 	"checks":    "/api/v2/checks",
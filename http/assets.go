@@ -7,6 +7,7 @@ import (
 	// TODO: use platform version of the code
 	"github.com/influxdata/influxdb/v2/chronograf"
 	"github.com/influxdata/influxdb/v2/chronograf/dist"
+	"github.com/influxdata/influxdb/v2/chronograf/server"
 )
 
 const (
@@ -23,6 +24,9 @@ const (
 // AssetHandler is an http handler for serving chronograf assets.
 type AssetHandler struct {
 	Path string
+	// Branding is the custom logo and base path of the UI. The paths of the
+	// requests served have had the base path removed.
+	Branding Branding
 }
 
 // NewAssetHandler is the constructor an asset handler.
@@ -32,6 +36,15 @@ func NewAssetHandler() *AssetHandler {
 
 // ServeHTTP implements the http handler interface for serving assets.
 func (h *AssetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == brandingLogoPath {
+		if h.Branding.LogoPath == "" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, h.Branding.LogoPath)
+		return
+	}
+
 	var assets chronograf.Assets
 	if h.Path != "" {
 		assets = &dist.DebugAssets{
@@ -46,5 +59,10 @@ func (h *AssetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	assets.Handler().ServeHTTP(w, r)
+	handler := assets.Handler()
+	if h.Branding.BasePath != "" {
+		// Prefix the URLs of the assets with the base path, as chronograf does.
+		handler = server.NewDefaultURLPrefixer(h.Branding.BasePath, handler, &chronograf.NoopLogger{})
+	}
+	handler.ServeHTTP(w, r)
 }
@@ -77,31 +77,41 @@ func NewAuthorizationHandler(log *zap.Logger, b *AuthorizationBackend) *Authoriz
 }
 
 type authResponse struct {
-	ID          platform.ID          `json:"id"`
-	Token       string               `json:"token"`
-	Status      platform.Status      `json:"status"`
-	Description string               `json:"description"`
-	OrgID       platform.ID          `json:"orgID"`
-	Org         string               `json:"org"`
-	UserID      platform.ID          `json:"userID"`
-	User        string               `json:"user"`
-	Permissions []permissionResponse `json:"permissions"`
-	Links       map[string]string    `json:"links"`
-	CreatedAt   time.Time            `json:"createdAt"`
-	UpdatedAt   time.Time            `json:"updatedAt"`
+	ID              platform.ID                 `json:"id"`
+	Token           string                      `json:"token"`
+	Status          platform.Status             `json:"status"`
+	Description     string                      `json:"description"`
+	OrgID           platform.ID                 `json:"orgID"`
+	Org             string                      `json:"org"`
+	UserID          platform.ID                 `json:"userID"`
+	User            string                      `json:"user"`
+	Permissions     []permissionResponse        `json:"permissions"`
+	RoleIDs         []platform.ID               `json:"roleIDs,omitempty"`
+	DBRP            *platform.AuthorizationDBRP `json:"dbrp,omitempty"`
+	DefaultBucketID *platform.ID                `json:"defaultBucketID,omitempty"`
+	LastUsedAt      *time.Time                  `json:"lastUsedAt,omitempty"`
+	LastUsedFrom    string                      `json:"lastUsedFrom,omitempty"`
+	Links           map[string]string           `json:"links"`
+	CreatedAt       time.Time                   `json:"createdAt"`
+	UpdatedAt       time.Time                   `json:"updatedAt"`
 }
 
 func newAuthResponse(a *platform.Authorization, org *platform.Organization, user *platform.User, ps []permissionResponse) *authResponse {
 	res := &authResponse{
-		ID:          a.ID,
-		Token:       a.Token,
-		Status:      a.Status,
-		Description: a.Description,
-		OrgID:       a.OrgID,
-		UserID:      a.UserID,
-		User:        user.Name,
-		Org:         org.Name,
-		Permissions: ps,
+		ID:              a.ID,
+		Token:           a.Token,
+		Status:          a.Status,
+		Description:     a.Description,
+		OrgID:           a.OrgID,
+		UserID:          a.UserID,
+		User:            user.Name,
+		Org:             org.Name,
+		Permissions:     ps,
+		RoleIDs:         a.RoleIDs,
+		DBRP:            a.DBRP,
+		DefaultBucketID: a.DefaultBucketID,
+		LastUsedAt:      a.LastUsedAt,
+		LastUsedFrom:    a.LastUsedFrom,
 		Links: map[string]string{
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
@@ -114,12 +124,17 @@ func newAuthResponse(a *platform.Authorization, org *platform.Organization, user
 
 func (a *authResponse) toPlatform() *platform.Authorization {
 	res := &platform.Authorization{
-		ID:          a.ID,
-		Token:       a.Token,
-		Status:      a.Status,
-		Description: a.Description,
-		OrgID:       a.OrgID,
-		UserID:      a.UserID,
+		ID:              a.ID,
+		Token:           a.Token,
+		Status:          a.Status,
+		Description:     a.Description,
+		OrgID:           a.OrgID,
+		UserID:          a.UserID,
+		RoleIDs:         a.RoleIDs,
+		DBRP:            a.DBRP,
+		DefaultBucketID: a.DefaultBucketID,
+		LastUsedAt:      a.LastUsedAt,
+		LastUsedFrom:    a.LastUsedFrom,
 		CRUDLog: platform.CRUDLog{
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
@@ -241,29 +256,38 @@ func (h *AuthorizationHandler) handlePostAuthorization(w http.ResponseWriter, r
 }
 
 type postAuthorizationRequest struct {
-	Status      platform.Status       `json:"status"`
-	OrgID       platform.ID           `json:"orgID"`
-	UserID      *platform.ID          `json:"userID,omitempty"`
-	Description string                `json:"description"`
-	Permissions []platform.Permission `json:"permissions"`
+	Status          platform.Status             `json:"status"`
+	OrgID           platform.ID                 `json:"orgID"`
+	UserID          *platform.ID                `json:"userID,omitempty"`
+	Description     string                      `json:"description"`
+	Permissions     []platform.Permission       `json:"permissions"`
+	RoleIDs         []platform.ID               `json:"roleIDs,omitempty"`
+	DBRP            *platform.AuthorizationDBRP `json:"dbrp,omitempty"`
+	DefaultBucketID *platform.ID                `json:"defaultBucketID,omitempty"`
 }
 
 func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Authorization {
 	return &platform.Authorization{
-		OrgID:       p.OrgID,
-		Status:      p.Status,
-		Description: p.Description,
-		Permissions: p.Permissions,
-		UserID:      userID,
+		OrgID:           p.OrgID,
+		Status:          p.Status,
+		Description:     p.Description,
+		Permissions:     p.Permissions,
+		UserID:          userID,
+		RoleIDs:         p.RoleIDs,
+		DBRP:            p.DBRP,
+		DefaultBucketID: p.DefaultBucketID,
 	}
 }
 
 func newPostAuthorizationRequest(a *platform.Authorization) (*postAuthorizationRequest, error) {
 	res := &postAuthorizationRequest{
-		OrgID:       a.OrgID,
-		Description: a.Description,
-		Permissions: a.Permissions,
-		Status:      a.Status,
+		OrgID:           a.OrgID,
+		Description:     a.Description,
+		Permissions:     a.Permissions,
+		Status:          a.Status,
+		RoleIDs:         a.RoleIDs,
+		DBRP:            a.DBRP,
+		DefaultBucketID: a.DefaultBucketID,
 	}
 
 	if a.UserID.Valid() {
@@ -282,10 +306,10 @@ func (p *postAuthorizationRequest) SetDefaults() {
 }
 
 func (p *postAuthorizationRequest) Validate() error {
-	if len(p.Permissions) == 0 {
+	if len(p.Permissions) == 0 && len(p.RoleIDs) == 0 {
 		return &platform.Error{
 			Code: platform.EInvalid,
-			Msg:  "authorization must include permissions",
+			Msg:  "authorization must include permissions or roles",
 		}
 	}
 
@@ -426,6 +450,18 @@ func decodeGetAuthorizationsRequest(ctx context.Context, r *http.Request) (*getA
 		req.filter.ID = id
 	}
 
+	if unusedSince := qp.Get("unusedSince"); unusedSince != "" {
+		t, err := time.Parse(time.RFC3339, unusedSince)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "unusedSince must be an RFC3339 timestamp",
+				Err:  err,
+			}
+		}
+		req.filter.UnusedSince = &t
+	}
+
 	return req, nil
 }
 
@@ -674,6 +710,9 @@ func (s *AuthorizationService) FindAuthorizations(ctx context.Context, filter pl
 	if filter.Org != nil {
 		params = append(params, [2]string{"org", *filter.Org})
 	}
+	if filter.UnusedSince != nil {
+		params = append(params, [2]string{"unusedSince", filter.UnusedSince.Format(time.RFC3339)})
+	}
 
 	var as authsResponse
 	err := s.Client.
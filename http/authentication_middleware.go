@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/influxdata/httprouter"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	platcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/jsonweb"
 	"github.com/opentracing/opentracing-go"
@@ -20,15 +22,32 @@ type AuthenticationHandler struct {
 	platform.HTTPErrorHandler
 	log *zap.Logger
 
+	// Authenticators is the chain of authenticators tried in order. When
+	// empty, requests are authenticated with their token or session by the
+	// services below.
+	Authenticators []Authenticator
+
 	AuthorizationService platform.AuthorizationService
 	SessionService       platform.SessionService
 	UserService          platform.UserService
+	// ResourceGrantService, if set, extends authorizers with the permissions
+	// on resources shared with their organizations.
+	ResourceGrantService platform.ResourceGrantService
 	TokenParser          *jsonweb.TokenParser
 	SessionRenewDisabled bool
 
+	// AuthorizationUsageService, if set, records the use of tokens. Usage of
+	// each token is recorded at most once per AuthorizationUsageInterval to
+	// limit writes; an interval of zero disables recording.
+	AuthorizationUsageService  platform.AuthorizationUsageService
+	AuthorizationUsageInterval time.Duration
+
 	// This is only really used for it's lookup method the specific http
 	// handler used to register routes does not matter.
 	noAuthRouter *httprouter.Router
+	// dbrpRouter looks up the routes that authorizations scoped to a dbrp
+	// may be used with.
+	dbrpRouter *httprouter.Router
 
 	Handler http.Handler
 }
@@ -41,6 +60,7 @@ func NewAuthenticationHandler(log *zap.Logger, h platform.HTTPErrorHandler) *Aut
 		Handler:          http.DefaultServeMux,
 		TokenParser:      jsonweb.NewTokenParser(jsonweb.EmptyKeyStore),
 		noAuthRouter:     httprouter.New(),
+		dbrpRouter:       httprouter.New(),
 	}
 }
 
@@ -50,6 +70,14 @@ func (h *AuthenticationHandler) RegisterNoAuthRoute(method, path string) {
 	h.noAuthRouter.HandlerFunc(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 }
 
+// RegisterDBRPRoute allows authorizations scoped to a dbrp to be used with
+// the route. The handler of the route must only let them access the bucket
+// of their dbrp mapping; they are forbidden on every other route.
+func (h *AuthenticationHandler) RegisterDBRPRoute(method, path string) {
+	// the handler specified here does not matter.
+	h.dbrpRouter.HandlerFunc(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+}
+
 const (
 	tokenAuthScheme   = "token"
 	sessionAuthScheme = "session"
@@ -84,26 +112,23 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 
 	ctx := r.Context()
-	scheme, err := ProbeAuthScheme(r)
+	auth, err := h.authenticate(r)
 	if err != nil {
 		h.unauthorized(ctx, w, err)
 		return
 	}
 
-	var auth platform.Authorizer
-	switch scheme {
-	case tokenAuthScheme:
-		auth, err = h.extractAuthorization(ctx, r)
-	case sessionAuthScheme:
-		auth, err = h.extractSession(ctx, r)
-	default:
-		// TODO: this error will be nil if it gets here, this should be remedied with some
-		//  sentinel error I'm thinking
-		err = errors.New("invalid auth scheme")
-	}
-	if err != nil {
-		h.unauthorized(ctx, w, err)
-		return
+	if a, ok := auth.(*platform.Authorization); ok {
+		if a.DBRP != nil {
+			if handler, _, _ := h.dbrpRouter.Lookup(r.Method, r.URL.Path); handler == nil {
+				h.HandleHTTPError(ctx, &platform.Error{
+					Code: platform.EForbidden,
+					Msg:  "dbrp scoped authorizations may only be used to write and query through their dbrp mapping",
+				}, w)
+				return
+			}
+		}
+		h.recordUsage(ctx, a, r)
 	}
 
 	// jwt based auth is permission based rather than identity based
@@ -116,6 +141,13 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	if h.ResourceGrantService != nil {
+		if auth, err = authorizer.WithResourceGrants(ctx, h.ResourceGrantService, auth); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
 	ctx = platcontext.SetAuthorizer(ctx, auth)
 
 	if span := opentracing.SpanFromContext(ctx); span != nil {
@@ -125,59 +157,65 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	h.Handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-func (h *AuthenticationHandler) isUserActive(ctx context.Context, auth platform.Authorizer) error {
-	u, err := h.UserService.FindUserByID(ctx, auth.GetUserID())
-	if err != nil {
-		return err
-	}
-
-	if u.Status != "inactive" {
-		return nil
+// recordUsage records the use of the authorization if it has not been
+// recorded within the usage interval.
+func (h *AuthenticationHandler) recordUsage(ctx context.Context, a *platform.Authorization, r *http.Request) {
+	if h.AuthorizationUsageService == nil || h.AuthorizationUsageInterval <= 0 {
+		return
 	}
 
-	return &platform.Error{Code: platform.EForbidden, Msg: "User is inactive"}
-}
-
-func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *http.Request) (platform.Authorizer, error) {
-	t, err := GetToken(r)
-	if err != nil {
-		return nil, err
+	now := time.Now()
+	if a.LastUsedAt != nil && now.Sub(*a.LastUsedAt) < h.AuthorizationUsageInterval {
+		return
 	}
 
-	token, err := h.TokenParser.Parse(t)
-	if err == nil {
-		return token, nil
+	from := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(from); err == nil {
+		from = host
 	}
 
-	// if the error returned signifies ths token is
-	// not a well formed JWT then use it as a lookup
-	// key for its associated authorization
-	// otherwise return the error
-	if !jsonweb.IsMalformedError(err) {
-		return nil, err
+	// Failing to record usage must not fail the request.
+	if err := h.AuthorizationUsageService.RecordAuthorizationUsage(ctx, a.ID, now, from); err != nil {
+		h.log.Info("Failed to record authorization usage", zap.String("authID", a.ID.String()), zap.Error(err))
 	}
-
-	return h.AuthorizationService.FindAuthorizationByToken(ctx, t)
 }
 
-func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Request) (*platform.Session, error) {
-	k, err := decodeCookieSession(ctx, r)
+func (h *AuthenticationHandler) isUserActive(ctx context.Context, auth platform.Authorizer) error {
+	u, err := h.UserService.FindUserByID(ctx, auth.GetUserID())
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	s, err := h.SessionService.FindSession(ctx, k)
-	if err != nil {
-		return nil, err
+	if u.Status != "inactive" {
+		return nil
 	}
 
-	if !h.SessionRenewDisabled {
-		// if the session is not expired, renew the session
-		err = h.SessionService.RenewSession(ctx, s, time.Now().Add(platform.RenewSessionTime))
-		if err != nil {
-			return nil, err
+	return &platform.Error{Code: platform.EForbidden, Msg: "User is inactive"}
+}
+
+// authenticate returns the authorizer of r found by the first authenticator
+// of the chain with credentials in r.
+func (h *AuthenticationHandler) authenticate(r *http.Request) (platform.Authorizer, error) {
+	chain := h.Authenticators
+	if len(chain) == 0 {
+		chain = []Authenticator{
+			&TokenAuthenticator{
+				AuthorizationService: h.AuthorizationService,
+				TokenParser:          h.TokenParser,
+			},
+			&SessionAuthenticator{
+				SessionService: h.SessionService,
+				RenewDisabled:  h.SessionRenewDisabled,
+			},
 		}
 	}
 
-	return s, err
+	for _, a := range chain {
+		auth, err := a.Authenticate(r)
+		if err == ErrNoCredentials {
+			continue
+		}
+		return auth, err
+	}
+	return nil, errors.New("token required")
 }
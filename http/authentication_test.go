@@ -397,3 +397,56 @@ func TestAuthenticationHandler_NoAuthRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticationHandler_DBRPRoutes(t *testing.T) {
+	tests := []struct {
+		name string
+		dbrp *platform.AuthorizationDBRP
+		path string
+		code int
+	}{
+		{
+			name: "dbrp scoped token on a dbrp route",
+			dbrp: &platform.AuthorizationDBRP{Database: "db", RetentionPolicy: "rp"},
+			path: "/api/v2/write",
+			code: http.StatusOK,
+		},
+		{
+			name: "dbrp scoped token on another route",
+			dbrp: &platform.AuthorizationDBRP{Database: "db", RetentionPolicy: "rp"},
+			path: "/api/v2/buckets",
+			code: http.StatusForbidden,
+		},
+		{
+			name: "token on another route",
+			path: "/api/v2/buckets",
+			code: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := platformhttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+			h.AuthorizationService = &mock.AuthorizationService{
+				FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+					return &platform.Authorization{DBRP: tt.dbrp}, nil
+				},
+			}
+			h.SessionService = mock.NewSessionService()
+			h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			h.RegisterDBRPRoute("POST", "/api/v2/write")
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", tt.path, nil)
+			platformhttp.SetToken("abc123", r)
+
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("expected status code to be %d got %d", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/jsonweb"
+)
+
+// ErrNoCredentials is returned by an Authenticator when a request carries
+// no credentials of its mechanism, so that the next authenticator of the
+// chain is tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator authenticates requests with one mechanism. The
+// AuthenticationHandler tries its chain of authenticators in order, and the
+// first one to find credentials in a request decides whether it is
+// authenticated.
+type Authenticator interface {
+	// Authenticate returns the authorizer of the credentials of r, or
+	// ErrNoCredentials if r has none for this mechanism.
+	Authenticate(r *http.Request) (platform.Authorizer, error)
+}
+
+// AuthenticatorFunc is an adapter to use a function as an Authenticator.
+type AuthenticatorFunc func(r *http.Request) (platform.Authorizer, error)
+
+// Authenticate calls fn(r).
+func (fn AuthenticatorFunc) Authenticate(r *http.Request) (platform.Authorizer, error) {
+	return fn(r)
+}
+
+// The names of the built-in authenticators.
+const (
+	AuthenticatorToken     = "token"
+	AuthenticatorSession   = "session"
+	AuthenticatorV1Basic   = "v1-basic"
+	AuthenticatorMTLS      = "mtls"
+	AuthenticatorSignedURL = "signed-url"
+)
+
+// DefaultAuthenticators are the authenticators enabled unless configured
+// otherwise.
+var DefaultAuthenticators = []string{
+	AuthenticatorToken,
+	AuthenticatorSession,
+	AuthenticatorSignedURL,
+}
+
+// NewAuthenticators returns the chain of the built-in authenticators named,
+// in order, backed by the services of b.
+func NewAuthenticators(b *APIBackend, names ...string) ([]Authenticator, error) {
+	chain := make([]Authenticator, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			return nil, fmt.Errorf("authenticator %q is configured more than once", name)
+		}
+		seen[name] = true
+
+		var a Authenticator
+		switch name {
+		case AuthenticatorToken:
+			a = &TokenAuthenticator{
+				AuthorizationService: b.AuthorizationService,
+				TokenParser:          jsonweb.NewTokenParser(jsonweb.EmptyKeyStore),
+			}
+		case AuthenticatorSession:
+			a = &SessionAuthenticator{
+				SessionService: b.SessionService,
+				RenewDisabled:  b.SessionRenewDisabled,
+			}
+		case AuthenticatorV1Basic:
+			a = &V1BasicAuthenticator{AuthorizationService: b.AuthorizationService}
+		case AuthenticatorMTLS:
+			a = &MTLSAuthenticator{AuthorizationService: b.AuthorizationService}
+		case AuthenticatorSignedURL:
+			a = &SignedURLAuthenticator{SignedQueryService: b.SignedQueryService}
+		default:
+			return nil, fmt.Errorf("unknown authenticator %q, expected one of %s", name, strings.Join([]string{
+				AuthenticatorToken,
+				AuthenticatorSession,
+				AuthenticatorV1Basic,
+				AuthenticatorMTLS,
+				AuthenticatorSignedURL,
+			}, ", "))
+		}
+		chain = append(chain, a)
+	}
+	return chain, nil
+}
+
+// TokenAuthenticator authenticates requests with the API token or JWT of
+// their "Authorization: Token <token>" header.
+type TokenAuthenticator struct {
+	AuthorizationService platform.AuthorizationService
+	TokenParser          *jsonweb.TokenParser
+}
+
+// Authenticate returns the authorization of the token of r.
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (platform.Authorizer, error) {
+	t, err := GetToken(r)
+	if err != nil {
+		return nil, ErrNoCredentials
+	}
+
+	token, err := a.TokenParser.Parse(t)
+	if err == nil {
+		return token, nil
+	}
+
+	// if the error returned signifies ths token is
+	// not a well formed JWT then use it as a lookup
+	// key for its associated authorization
+	// otherwise return the error
+	if !jsonweb.IsMalformedError(err) {
+		return nil, err
+	}
+
+	return a.AuthorizationService.FindAuthorizationByToken(r.Context(), t)
+}
+
+// SessionAuthenticator authenticates requests with the session of their
+// cookie, which is renewed unless RenewDisabled.
+type SessionAuthenticator struct {
+	SessionService platform.SessionService
+	RenewDisabled  bool
+}
+
+// Authenticate returns the session of r.
+func (a *SessionAuthenticator) Authenticate(r *http.Request) (platform.Authorizer, error) {
+	ctx := r.Context()
+	k, err := decodeCookieSession(ctx, r)
+	if err != nil {
+		return nil, ErrNoCredentials
+	}
+
+	s, err := a.SessionService.FindSession(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.RenewDisabled {
+		// if the session is not expired, renew the session
+		err = a.SessionService.RenewSession(ctx, s, time.Now().Add(platform.RenewSessionTime))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// V1BasicAuthenticator authenticates the requests of InfluxDB 1.x clients,
+// which send an API token as the password of HTTP basic authentication or
+// of the "p" query parameter. The user name is ignored.
+type V1BasicAuthenticator struct {
+	AuthorizationService platform.AuthorizationService
+}
+
+// Authenticate returns the authorization of the password of r.
+func (a *V1BasicAuthenticator) Authenticate(r *http.Request) (platform.Authorizer, error) {
+	_, p, ok := r.BasicAuth()
+	if !ok {
+		p = r.URL.Query().Get("p")
+	}
+	if p == "" {
+		return nil, ErrNoCredentials
+	}
+	return a.AuthorizationService.FindAuthorizationByToken(r.Context(), p)
+}
+
+// MTLSAuthenticator authenticates requests with their verified TLS client
+// certificate. The subject common name of the certificate is the ID of the
+// authorization the client acts with, so the permissions of clients are
+// managed like those of tokens.
+type MTLSAuthenticator struct {
+	AuthorizationService platform.AuthorizationService
+}
+
+// Authenticate returns the authorization of the client certificate of r.
+func (a *MTLSAuthenticator) Authenticate(r *http.Request) (platform.Authorizer, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	cn := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	id, err := platform.IDFromString(cn)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EUnauthorized,
+			Msg:  fmt.Sprintf("client certificate %q does not name an authorization", cn),
+		}
+	}
+	return a.AuthorizationService.FindAuthorizationByID(r.Context(), *id)
+}
+
+// SignedURLAuthenticator authenticates the GET requests of signed query
+// URLs with their signature, which grants read access to the bucket of the
+// query only.
+type SignedURLAuthenticator struct {
+	SignedQueryService platform.SignedQueryService
+}
+
+// Authenticate returns the authorization of the signed query of r.
+func (a *SignedURLAuthenticator) Authenticate(r *http.Request) (platform.Authorizer, error) {
+	if a.SignedQueryService == nil || r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, prefixSignedQuery+"/") {
+		return nil, ErrNoCredentials
+	}
+
+	signature := strings.TrimPrefix(r.URL.Path, prefixSignedQuery+"/")
+	if signature == "" || strings.Contains(signature, "/") {
+		return nil, ErrNoCredentials
+	}
+	q, err := a.SignedQueryService.VerifySignedQuery(r.Context(), signature)
+	if err != nil {
+		return nil, err
+	}
+	return q.Authorization(), nil
+}
@@ -0,0 +1,215 @@
+package http_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	platformhttp "github.com/influxdata/influxdb/v2/http"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+type signedQueryService struct {
+	influxdb.SignedQueryService
+}
+
+func (signedQueryService) VerifySignedQuery(ctx context.Context, signature string) (*influxdb.SignedQuery, error) {
+	if signature != "valid" {
+		return nil, &influxdb.Error{Code: influxdb.EUnauthorized, Msg: "invalid signature"}
+	}
+	return &influxdb.SignedQuery{OrgID: 2, BucketID: 3}, nil
+}
+
+func TestAuthenticationHandler_Authenticators(t *testing.T) {
+	auths := &mock.AuthorizationService{
+		FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+			if token != "secret" {
+				return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+			}
+			return &influxdb.Authorization{ID: 1, Status: influxdb.Active}, nil
+		},
+		FindAuthorizationByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+			return &influxdb.Authorization{ID: id, Status: influxdb.Active}, nil
+		},
+	}
+	b := &platformhttp.APIBackend{
+		AuthorizationService: auths,
+		SessionService:       mock.NewSessionService(),
+		SignedQueryService:   signedQueryService{},
+	}
+
+	clientCert := func(cn string) *tls.ConnectionState {
+		return &tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		authenticators []string
+		request        func() *http.Request
+		code           int
+		authID         influxdb.ID
+	}{
+		{
+			name:           "token",
+			authenticators: platformhttp.DefaultAuthenticators,
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/api/v2/buckets", nil)
+				platformhttp.SetToken("secret", r)
+				return r
+			},
+			code:   http.StatusOK,
+			authID: 1,
+		},
+		{
+			name:           "no credentials",
+			authenticators: platformhttp.DefaultAuthenticators,
+			request: func() *http.Request {
+				return httptest.NewRequest("GET", "/api/v2/buckets", nil)
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:           "v1 basic disabled",
+			authenticators: platformhttp.DefaultAuthenticators,
+			request: func() *http.Request {
+				r := httptest.NewRequest("POST", "/api/v2/write", nil)
+				r.SetBasicAuth("telegraf", "secret")
+				return r
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:           "v1 basic",
+			authenticators: []string{"token", "v1-basic"},
+			request: func() *http.Request {
+				r := httptest.NewRequest("POST", "/api/v2/write", nil)
+				r.SetBasicAuth("telegraf", "secret")
+				return r
+			},
+			code:   http.StatusOK,
+			authID: 1,
+		},
+		{
+			name:           "v1 query parameter",
+			authenticators: []string{"v1-basic"},
+			request: func() *http.Request {
+				return httptest.NewRequest("POST", "/api/v2/write?u=telegraf&p=secret", nil)
+			},
+			code:   http.StatusOK,
+			authID: 1,
+		},
+		{
+			name:           "v1 wrong password",
+			authenticators: []string{"v1-basic"},
+			request: func() *http.Request {
+				return httptest.NewRequest("POST", "/api/v2/write?u=telegraf&p=wrong", nil)
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:           "mtls",
+			authenticators: []string{"token", "mtls"},
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/api/v2/buckets", nil)
+				r.TLS = clientCert("0000000000000007")
+				return r
+			},
+			code:   http.StatusOK,
+			authID: 7,
+		},
+		{
+			name:           "mtls token first",
+			authenticators: []string{"token", "mtls"},
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/api/v2/buckets", nil)
+				r.TLS = clientCert("0000000000000007")
+				platformhttp.SetToken("secret", r)
+				return r
+			},
+			code:   http.StatusOK,
+			authID: 1,
+		},
+		{
+			name:           "mtls without authorization",
+			authenticators: []string{"mtls"},
+			request: func() *http.Request {
+				r := httptest.NewRequest("GET", "/api/v2/buckets", nil)
+				r.TLS = clientCert("agent")
+				return r
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:           "signed url",
+			authenticators: platformhttp.DefaultAuthenticators,
+			request: func() *http.Request {
+				return httptest.NewRequest("GET", "/api/v2/query/signed/valid", nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:           "invalid signed url",
+			authenticators: platformhttp.DefaultAuthenticators,
+			request: func() *http.Request {
+				return httptest.NewRequest("GET", "/api/v2/query/signed/forged", nil)
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:           "signed url disabled",
+			authenticators: []string{"token", "session"},
+			request: func() *http.Request {
+				return httptest.NewRequest("GET", "/api/v2/query/signed/valid", nil)
+			},
+			code: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := platformhttp.NewAuthenticators(b, tt.authenticators...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got influxdb.Authorizer
+			h := platformhttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+			h.Authenticators = chain
+			h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = icontext.GetAuthorizer(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, tt.request())
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status code %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.authID.Valid() && (got == nil || got.Identifier() != tt.authID) {
+				t.Errorf("expected authorization %s, got %v", tt.authID, got)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticators(t *testing.T) {
+	for _, names := range [][]string{
+		{"token", "kerberos"},
+		{"token", "session", "token"},
+	} {
+		if _, err := platformhttp.NewAuthenticators(&platformhttp.APIBackend{}, names...); err == nil {
+			t.Errorf("expected %q to be invalid", names)
+		}
+	}
+}
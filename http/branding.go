@@ -0,0 +1,136 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixBranding = "/api/v2/branding"
+	// brandingLogoPath is the path, under the base path, of the custom logo
+	// served by the asset handler.
+	brandingLogoPath = "/branding/logo"
+)
+
+var basePathRegexp = regexp.MustCompile(`^(/[\w-]+)+$`)
+
+// Branding customizes the UI served by the asset handler, for embedding the
+// product behind a portal.
+type Branding struct {
+	// LogoPath is the path of an image file served as the logo of the UI.
+	LogoPath string
+	// LoginBanner is the text shown on the login page.
+	LoginBanner string
+	// BasePath is the URL path prefix, such as /influxdb, under which the UI
+	// and the API are served.
+	BasePath string
+}
+
+// Valid returns an error if the base path is malformed or the logo is not a
+// readable file.
+func (b Branding) Valid() error {
+	if b.BasePath != "" && !basePathRegexp.MatchString(b.BasePath) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid base path %q, must follow the format /mybasepath", b.BasePath),
+		}
+	}
+	if b.LogoPath != "" {
+		fi, err := os.Stat(b.LogoPath)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "logo is not readable",
+				Err:  err,
+			}
+		}
+		if !fi.Mode().IsRegular() {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("logo %q is not a file", b.LogoPath),
+			}
+		}
+	}
+	return nil
+}
+
+// stripBasePath returns r with the base path removed from its URL path, or
+// r itself if its path is not under the base path.
+func (b Branding) stripBasePath(r *http.Request) *http.Request {
+	if b.BasePath == "" {
+		return r
+	}
+	p := r.URL.Path
+	if p != b.BasePath && !strings.HasPrefix(p, b.BasePath+"/") {
+		return r
+	}
+	p = strings.TrimPrefix(p, b.BasePath)
+	if p == "" {
+		p = "/"
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = p
+	r2.URL.RawPath = ""
+	return r2
+}
+
+// BrandingHandler represents an HTTP API handler for the branding of the UI.
+type BrandingHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	Branding Branding
+}
+
+// NewBrandingHandler returns a new instance of BrandingHandler.
+func NewBrandingHandler(log *zap.Logger, he influxdb.HTTPErrorHandler, b Branding) *BrandingHandler {
+	h := &BrandingHandler{
+		Router:           NewRouter(he),
+		HTTPErrorHandler: he,
+		log:              log,
+		Branding:         b,
+	}
+
+	h.HandlerFunc("GET", prefixBranding, h.handleGetBranding)
+
+	return h
+}
+
+type brandingResponse struct {
+	Links       map[string]string `json:"links"`
+	LoginBanner string            `json:"loginBanner"`
+	BasePath    string            `json:"basePath"`
+}
+
+// handleGetBranding is the HTTP handler for the GET /api/v2/branding route.
+func (h *BrandingHandler) handleGetBranding(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	res := &brandingResponse{
+		Links: map[string]string{
+			"self": h.Branding.BasePath + prefixBranding,
+		},
+		LoginBanner: h.Branding.LoginBanner,
+		BasePath:    h.Branding.BasePath,
+	}
+	if h.Branding.LogoPath != "" {
+		res.Links["logo"] = h.Branding.BasePath + brandingLogoPath
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
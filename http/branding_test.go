@@ -0,0 +1,115 @@
+package http
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBranding_Valid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "branding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logo := filepath.Join(dir, "logo.svg")
+	if err := ioutil.WriteFile(logo, []byte("<svg/>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		branding Branding
+		wantErr  bool
+	}{
+		{name: "empty"},
+		{name: "valid", branding: Branding{LogoPath: logo, LoginBanner: "Authorized use only", BasePath: "/portal/influxdb"}},
+		{name: "base path without leading slash", branding: Branding{BasePath: "influxdb"}, wantErr: true},
+		{name: "base path with trailing slash", branding: Branding{BasePath: "/influxdb/"}, wantErr: true},
+		{name: "missing logo", branding: Branding{LogoPath: filepath.Join(dir, "missing.png")}, wantErr: true},
+		{name: "logo directory", branding: Branding{LogoPath: dir}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.branding.Valid(); (err != nil) != tt.wantErr {
+				t.Errorf("Valid() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBranding_stripBasePath(t *testing.T) {
+	b := Branding{BasePath: "/influxdb"}
+	for path, want := range map[string]string{
+		"/influxdb":              "/",
+		"/influxdb/":             "/",
+		"/influxdb/api/v2/query": "/api/v2/query",
+		"/influxdbx/api/v2":      "/influxdbx/api/v2",
+		"/api/v2/query":          "/api/v2/query",
+	} {
+		r := httptest.NewRequest("GET", path, nil)
+		if got := b.stripBasePath(r).URL.Path; got != want {
+			t.Errorf("stripBasePath(%q) = %q, want %q", path, got, want)
+		}
+		if r.URL.Path != path {
+			t.Errorf("expected the request of %q not to be modified", path)
+		}
+	}
+}
+
+func TestBrandingHandler_handleGetBranding(t *testing.T) {
+	h := NewBrandingHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0), Branding{
+		LogoPath:    "logo.png",
+		LoginBanner: "Authorized use only",
+		BasePath:    "/influxdb",
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/branding", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", w.Code)
+	}
+
+	var res brandingResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.LoginBanner != "Authorized use only" || res.BasePath != "/influxdb" || res.Links["logo"] != "/influxdb/branding/logo" {
+		t.Errorf("unexpected branding %+v", res)
+	}
+}
+
+func TestAssetHandler_Logo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "branding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logo := filepath.Join(dir, "logo.svg")
+	if err := ioutil.WriteFile(logo, []byte("<svg/>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &AssetHandler{Branding: Branding{LogoPath: logo}}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/branding/logo", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "<svg/>" {
+		t.Errorf("expected the logo to be served, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("got content type %q, want image/svg+xml", ct)
+	}
+
+	h = &AssetHandler{}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/branding/logo", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected no logo without one configured, got %d", w.Code)
+	}
+}
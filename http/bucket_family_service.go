@@ -0,0 +1,379 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// BucketFamilyBackend is all services and associated parameters required to construct
+// the BucketFamilyHandler.
+type BucketFamilyBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	BucketFamilyService influxdb.BucketFamilyService
+}
+
+// NewBucketFamilyBackend returns a new instance of BucketFamilyBackend.
+func NewBucketFamilyBackend(log *zap.Logger, b *APIBackend) *BucketFamilyBackend {
+	return &BucketFamilyBackend{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		log:                 log,
+		BucketFamilyService: b.BucketFamilyService,
+	}
+}
+
+// BucketFamilyHandler represents an HTTP API handler for bucket families.
+type BucketFamilyHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	BucketFamilyService influxdb.BucketFamilyService
+}
+
+const (
+	prefixBucketFamilies = "/api/v2/bucket-families"
+	bucketFamiliesIDPath = "/api/v2/bucket-families/:id"
+)
+
+// NewBucketFamilyHandler returns a new instance of BucketFamilyHandler.
+func NewBucketFamilyHandler(log *zap.Logger, b *BucketFamilyBackend) *BucketFamilyHandler {
+	h := &BucketFamilyHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		BucketFamilyService: b.BucketFamilyService,
+	}
+
+	h.HandlerFunc("POST", prefixBucketFamilies, h.handlePostBucketFamily)
+	h.HandlerFunc("GET", prefixBucketFamilies, h.handleGetBucketFamilies)
+	h.HandlerFunc("GET", bucketFamiliesIDPath, h.handleGetBucketFamily)
+	h.HandlerFunc("PATCH", bucketFamiliesIDPath, h.handlePatchBucketFamily)
+	h.HandlerFunc("DELETE", bucketFamiliesIDPath, h.handleDeleteBucketFamily)
+
+	return h
+}
+
+type bucketFamilyResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.BucketFamily
+}
+
+func newBucketFamilyResponse(f *influxdb.BucketFamily) *bucketFamilyResponse {
+	return &bucketFamilyResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/bucket-families/%s", f.ID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", f.OrgID),
+		},
+		BucketFamily: *f,
+	}
+}
+
+type bucketFamiliesResponse struct {
+	Links    map[string]string       `json:"links"`
+	Families []*bucketFamilyResponse `json:"families"`
+}
+
+func newBucketFamiliesResponse(fs []*influxdb.BucketFamily) *bucketFamiliesResponse {
+	res := &bucketFamiliesResponse{
+		Links: map[string]string{
+			"self": prefixBucketFamilies,
+		},
+		Families: make([]*bucketFamilyResponse, 0, len(fs)),
+	}
+	for _, f := range fs {
+		res.Families = append(res.Families, newBucketFamilyResponse(f))
+	}
+	return res
+}
+
+type postBucketFamilyRequest struct {
+	OrgID       influxdb.ID `json:"orgID"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Period      string      `json:"period"`
+	MaxMembers  int         `json:"maxMembers"`
+}
+
+// handlePostBucketFamily is the HTTP handler for the POST /api/v2/bucket-families route.
+func (h *BucketFamilyHandler) handlePostBucketFamily(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postBucketFamilyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode bucket family request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	f := &influxdb.BucketFamily{
+		OrgID:       req.OrgID,
+		Name:        req.Name,
+		Description: req.Description,
+		Period:      req.Period,
+		MaxMembers:  req.MaxMembers,
+	}
+	if err := f.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.BucketFamilyService.CreateBucketFamily(ctx, f); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket family created", zap.String("family", fmt.Sprint(f)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newBucketFamilyResponse(f)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetBucketFamiliesRequest(r *http.Request) (*influxdb.BucketFamilyFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.BucketFamilyFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("name"); v != "" {
+		filter.Name = &v
+	}
+	return filter, nil
+}
+
+// handleGetBucketFamilies is the HTTP handler for the GET /api/v2/bucket-families route.
+func (h *BucketFamilyHandler) handleGetBucketFamilies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetBucketFamiliesRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	fs, err := h.BucketFamilyService.FindBucketFamilies(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket families retrieved", zap.String("families", fmt.Sprint(fs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketFamiliesResponse(fs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeBucketFamilyID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetBucketFamily is the HTTP handler for the GET /api/v2/bucket-families/:id route.
+func (h *BucketFamilyHandler) handleGetBucketFamily(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeBucketFamilyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	f, err := h.BucketFamilyService.FindBucketFamilyByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket family retrieved", zap.String("family", fmt.Sprint(f)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketFamilyResponse(f)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchBucketFamily is the HTTP handler for the PATCH /api/v2/bucket-families/:id route.
+func (h *BucketFamilyHandler) handlePatchBucketFamily(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeBucketFamilyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.BucketFamilyUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode bucket family update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	f, err := h.BucketFamilyService.UpdateBucketFamily(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket family updated", zap.String("family", fmt.Sprint(f)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newBucketFamilyResponse(f)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteBucketFamily is the HTTP handler for the DELETE /api/v2/bucket-families/:id route.
+func (h *BucketFamilyHandler) handleDeleteBucketFamily(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeBucketFamilyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.BucketFamilyService.DeleteBucketFamily(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket family deleted", zap.String("familyID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BucketFamilyService connects to Influx via HTTP using tokens to manage bucket families.
+type BucketFamilyService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.BucketFamilyService = (*BucketFamilyService)(nil)
+
+// FindBucketFamilyByID returns a single bucket family by ID.
+func (s *BucketFamilyService) FindBucketFamilyByID(ctx context.Context, id influxdb.ID) (*influxdb.BucketFamily, error) {
+	var fr bucketFamilyResponse
+	err := s.Client.
+		Get(path.Join(prefixBucketFamilies, id.String())).
+		DecodeJSON(&fr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fr.BucketFamily, nil
+}
+
+// FindBucketFamily returns the first bucket family that matches filter.
+func (s *BucketFamilyService) FindBucketFamily(ctx context.Context, filter influxdb.BucketFamilyFilter) (*influxdb.BucketFamily, error) {
+	if filter.ID != nil {
+		return s.FindBucketFamilyByID(ctx, *filter.ID)
+	}
+
+	fs, err := s.FindBucketFamilies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(fs) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Op:   influxdb.OpFindBucketFamily,
+			Msg:  influxdb.ErrBucketFamilyNotFound,
+		}
+	}
+	return fs[0], nil
+}
+
+// FindBucketFamilies returns a list of bucket families that match filter.
+func (s *BucketFamilyService) FindBucketFamilies(ctx context.Context, filter influxdb.BucketFamilyFilter) ([]*influxdb.BucketFamily, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var fr bucketFamiliesResponse
+	err := s.Client.
+		Get(prefixBucketFamilies).
+		QueryParams(params...).
+		DecodeJSON(&fr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := make([]*influxdb.BucketFamily, 0, len(fr.Families))
+	for _, f := range fr.Families {
+		if filter.Match(&f.BucketFamily) {
+			fs = append(fs, &f.BucketFamily)
+		}
+	}
+	return fs, nil
+}
+
+// CreateBucketFamily creates a new bucket family and sets f.ID with the new identifier.
+func (s *BucketFamilyService) CreateBucketFamily(ctx context.Context, f *influxdb.BucketFamily) error {
+	var fr bucketFamilyResponse
+	err := s.Client.
+		PostJSON(postBucketFamilyRequest{
+			OrgID:       f.OrgID,
+			Name:        f.Name,
+			Description: f.Description,
+			Period:      f.Period,
+			MaxMembers:  f.MaxMembers,
+		}, prefixBucketFamilies).
+		DecodeJSON(&fr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*f = fr.BucketFamily
+	return nil
+}
+
+// UpdateBucketFamily updates a single bucket family with changeset.
+func (s *BucketFamilyService) UpdateBucketFamily(ctx context.Context, id influxdb.ID, upd influxdb.BucketFamilyUpdate) (*influxdb.BucketFamily, error) {
+	var fr bucketFamilyResponse
+	err := s.Client.
+		PatchJSON(upd, path.Join(prefixBucketFamilies, id.String())).
+		DecodeJSON(&fr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fr.BucketFamily, nil
+}
+
+// DeleteBucketFamily removes a bucket family by ID.
+func (s *BucketFamilyService) DeleteBucketFamily(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixBucketFamilies, id.String())).
+		Do(ctx)
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+type bucketMeasurementsResponse struct {
+	Links        map[string]string               `json:"links"`
+	Measurements []*influxdb.MeasurementMetadata `json:"measurements"`
+}
+
+type bucketTagKeysResponse struct {
+	Links   map[string]string          `json:"links"`
+	TagKeys []*influxdb.TagKeyMetadata `json:"tagKeys"`
+}
+
+type bucketFieldKeysResponse struct {
+	Links     map[string]string            `json:"links"`
+	FieldKeys []*influxdb.FieldKeyMetadata `json:"fieldKeys"`
+}
+
+// decodeBucketMetadataRequest returns the bucket of the request, and the
+// measurement of its query, if any.
+func (h *BucketHandler) decodeBucketMetadataRequest(r *http.Request) (*influxdb.Bucket, string, error) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		return nil, "", err
+	}
+	b, err := h.BucketService.FindBucketByID(r.Context(), id)
+	if err != nil {
+		return nil, "", err
+	}
+	return b, r.URL.Query().Get("measurement"), nil
+}
+
+// handleGetBucketMeasurements is the HTTP handler for the GET /api/v2/buckets/:id/measurements route.
+func (h *BucketHandler) handleGetBucketMeasurements(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "BucketHandler.handleGetBucketMeasurements")
+	defer span.Finish()
+
+	b, _, err := h.decodeBucketMetadataRequest(r)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	ms, err := h.BucketMetadataService.FindBucketMeasurements(r.Context(), b.OrgID, b.ID)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Bucket measurements retrieved", zap.Stringer("bucketID", b.ID), zap.Int("measurements", len(ms)))
+
+	if ms == nil {
+		ms = []*influxdb.MeasurementMetadata{}
+	}
+	h.api.Respond(w, http.StatusOK, &bucketMeasurementsResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/measurements", b.ID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", b.ID),
+		},
+		Measurements: ms,
+	})
+}
+
+// handleGetBucketTagKeys is the HTTP handler for the GET /api/v2/buckets/:id/tag-keys route.
+func (h *BucketHandler) handleGetBucketTagKeys(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "BucketHandler.handleGetBucketTagKeys")
+	defer span.Finish()
+
+	b, measurement, err := h.decodeBucketMetadataRequest(r)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	keys, err := h.BucketMetadataService.FindBucketTagKeys(r.Context(), b.OrgID, b.ID, measurement)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Bucket tag keys retrieved", zap.Stringer("bucketID", b.ID), zap.Int("tagKeys", len(keys)))
+
+	if keys == nil {
+		keys = []*influxdb.TagKeyMetadata{}
+	}
+	h.api.Respond(w, http.StatusOK, &bucketTagKeysResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/tag-keys", b.ID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", b.ID),
+		},
+		TagKeys: keys,
+	})
+}
+
+// handleGetBucketFieldKeys is the HTTP handler for the GET /api/v2/buckets/:id/field-keys route.
+func (h *BucketHandler) handleGetBucketFieldKeys(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "BucketHandler.handleGetBucketFieldKeys")
+	defer span.Finish()
+
+	b, measurement, err := h.decodeBucketMetadataRequest(r)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	keys, err := h.BucketMetadataService.FindBucketFieldKeys(r.Context(), b.OrgID, b.ID, measurement)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Bucket field keys retrieved", zap.Stringer("bucketID", b.ID), zap.Int("fieldKeys", len(keys)))
+
+	if keys == nil {
+		keys = []*influxdb.FieldKeyMetadata{}
+	}
+	h.api.Respond(w, http.StatusOK, &bucketFieldKeysResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/field-keys", b.ID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", b.ID),
+		},
+		FieldKeys: keys,
+	})
+}
+
+// BucketMetadataService is the client implementation of influxdb.BucketMetadataService.
+type BucketMetadataService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.BucketMetadataService = (*BucketMetadataService)(nil)
+
+// FindBucketMeasurements returns the measurements of the bucket. The
+// organization of the bucket is found by the server.
+func (s *BucketMetadataService) FindBucketMeasurements(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.MeasurementMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var resp bucketMeasurementsResponse
+	err := s.Client.
+		Get(prefixBuckets, bucketID.String(), "measurements").
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Measurements, nil
+}
+
+// FindBucketTagKeys returns the tag keys of the series of the measurement of
+// the bucket, or of all of its series if measurement is empty.
+func (s *BucketMetadataService) FindBucketTagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*influxdb.TagKeyMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var resp bucketTagKeysResponse
+	err := s.Client.
+		Get(prefixBuckets, bucketID.String(), "tag-keys").
+		QueryParams(bucketMetadataQueryParams(measurement)...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.TagKeys, nil
+}
+
+// FindBucketFieldKeys returns the field keys of the measurement of the
+// bucket, or of all of its measurements if measurement is empty.
+func (s *BucketMetadataService) FindBucketFieldKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*influxdb.FieldKeyMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var resp bucketFieldKeysResponse
+	err := s.Client.
+		Get(prefixBuckets, bucketID.String(), "field-keys").
+		QueryParams(bucketMetadataQueryParams(measurement)...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.FieldKeys, nil
+}
+
+func bucketMetadataQueryParams(measurement string) [][2]string {
+	if measurement == "" {
+		return nil
+	}
+	return [][2]string{{"measurement", measurement}}
+}
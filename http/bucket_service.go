@@ -24,6 +24,8 @@ type BucketBackend struct {
 
 	BucketService              influxdb.BucketService
 	BucketOperationLogService  influxdb.BucketOperationLogService
+	BucketMetadataService      influxdb.BucketMetadataService
+	LastValueService           influxdb.LastValueService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -38,6 +40,8 @@ func NewBucketBackend(log *zap.Logger, b *APIBackend) *BucketBackend {
 
 		BucketService:              b.BucketService,
 		BucketOperationLogService:  b.BucketOperationLogService,
+		BucketMetadataService:      b.BucketMetadataService,
+		LastValueService:           b.LastValueService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -53,6 +57,8 @@ type BucketHandler struct {
 
 	BucketService              influxdb.BucketService
 	BucketOperationLogService  influxdb.BucketOperationLogService
+	BucketMetadataService      influxdb.BucketMetadataService
+	LastValueService           influxdb.LastValueService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -60,15 +66,19 @@ type BucketHandler struct {
 }
 
 const (
-	prefixBuckets          = "/api/v2/buckets"
-	bucketsIDPath          = "/api/v2/buckets/:id"
-	bucketsIDLogPath       = "/api/v2/buckets/:id/logs"
-	bucketsIDMembersPath   = "/api/v2/buckets/:id/members"
-	bucketsIDMembersIDPath = "/api/v2/buckets/:id/members/:userID"
-	bucketsIDOwnersPath    = "/api/v2/buckets/:id/owners"
-	bucketsIDOwnersIDPath  = "/api/v2/buckets/:id/owners/:userID"
-	bucketsIDLabelsPath    = "/api/v2/buckets/:id/labels"
-	bucketsIDLabelsIDPath  = "/api/v2/buckets/:id/labels/:lid"
+	prefixBuckets             = "/api/v2/buckets"
+	bucketsIDPath             = "/api/v2/buckets/:id"
+	bucketsIDLogPath          = "/api/v2/buckets/:id/logs"
+	bucketsIDMembersPath      = "/api/v2/buckets/:id/members"
+	bucketsIDMembersIDPath    = "/api/v2/buckets/:id/members/:userID"
+	bucketsIDOwnersPath       = "/api/v2/buckets/:id/owners"
+	bucketsIDOwnersIDPath     = "/api/v2/buckets/:id/owners/:userID"
+	bucketsIDLabelsPath       = "/api/v2/buckets/:id/labels"
+	bucketsIDLabelsIDPath     = "/api/v2/buckets/:id/labels/:lid"
+	bucketsIDMeasurementsPath = "/api/v2/buckets/:id/measurements"
+	bucketsIDTagKeysPath      = "/api/v2/buckets/:id/tag-keys"
+	bucketsIDFieldKeysPath    = "/api/v2/buckets/:id/field-keys"
+	bucketsIDLastValuesPath   = "/api/v2/buckets/:id/last-values"
 )
 
 // NewBucketHandler returns a new instance of BucketHandler.
@@ -80,6 +90,8 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 
 		BucketService:              b.BucketService,
 		BucketOperationLogService:  b.BucketOperationLogService,
+		BucketMetadataService:      b.BucketMetadataService,
+		LastValueService:           b.LastValueService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -90,6 +102,10 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 	h.HandlerFunc("GET", prefixBuckets, h.handleGetBuckets)
 	h.HandlerFunc("GET", bucketsIDPath, h.handleGetBucket)
 	h.HandlerFunc("GET", bucketsIDLogPath, h.handleGetBucketLog)
+	h.HandlerFunc("GET", bucketsIDMeasurementsPath, h.handleGetBucketMeasurements)
+	h.HandlerFunc("GET", bucketsIDTagKeysPath, h.handleGetBucketTagKeys)
+	h.HandlerFunc("GET", bucketsIDFieldKeysPath, h.handleGetBucketFieldKeys)
+	h.HandlerFunc("GET", bucketsIDLastValuesPath, h.handleGetBucketLastValues)
 	h.HandlerFunc("PATCH", bucketsIDPath, h.handlePatchBucket)
 	h.HandlerFunc("DELETE", bucketsIDPath, h.handleDeleteBucket)
 
@@ -132,13 +148,23 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
-	ID                  influxdb.ID     `json:"id,omitempty"`
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Type                string          `json:"type"`
-	Description         string          `json:"description,omitempty"`
-	Name                string          `json:"name"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	ID                  influxdb.ID                   `json:"id,omitempty"`
+	OrgID               influxdb.ID                   `json:"orgID,omitempty"`
+	Type                string                        `json:"type"`
+	Description         string                        `json:"description,omitempty"`
+	Name                string                        `json:"name"`
+	RetentionPolicyName string                        `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule               `json:"retentionRules"`
+	DuplicatePolicy     influxdb.DuplicatePointPolicy `json:"duplicatePolicy,omitempty"`
+	WriteWindow         *writeWindow                  `json:"writeWindow,omitempty"`
+	DedupeWindowSeconds int64                         `json:"dedupeWindowSeconds,omitempty"`
+	JSONWriteRules      []influxdb.JSONWriteRule      `json:"jsonWriteRules,omitempty"`
+	AggregationHints    []influxdb.AggregationHint    `json:"aggregationHints,omitempty"`
+	CompressionCodec    influxdb.CompressionCodec     `json:"compressionCodec,omitempty"`
+	SoftTTLs            []influxdb.SoftTTL            `json:"softTTLs,omitempty"`
+	LastValueCache      bool                          `json:"lastValueCache,omitempty"`
+	MaskingRules        []influxdb.MaskingRule        `json:"maskingRules,omitempty"`
+	WriteConsistency    influxdb.WriteConsistency     `json:"writeConsistency,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -160,6 +186,37 @@ func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
 	return t, nil
 }
 
+// writeWindow bounds the timestamps of points written to a bucket.
+type writeWindow struct {
+	PastSeconds   int64        `json:"pastSeconds,omitempty"`
+	FutureSeconds int64        `json:"futureSeconds,omitempty"`
+	ErrorBucketID *influxdb.ID `json:"errorBucketID,omitempty"`
+}
+
+func (ww *writeWindow) toInfluxDB() *influxdb.WriteWindow {
+	if ww == nil {
+		return nil
+	}
+
+	return &influxdb.WriteWindow{
+		Past:          time.Duration(ww.PastSeconds) * time.Second,
+		Future:        time.Duration(ww.FutureSeconds) * time.Second,
+		ErrorBucketID: ww.ErrorBucketID,
+	}
+}
+
+func newWriteWindow(ww *influxdb.WriteWindow) *writeWindow {
+	if ww == nil {
+		return nil
+	}
+
+	return &writeWindow{
+		PastSeconds:   int64(ww.Past.Round(time.Second) / time.Second),
+		FutureSeconds: int64(ww.Future.Round(time.Second) / time.Second),
+		ErrorBucketID: ww.ErrorBucketID,
+	}
+}
+
 func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	if b == nil {
 		return nil, nil
@@ -186,6 +243,16 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		DuplicatePolicy:     b.DuplicatePolicy,
+		WriteWindow:         b.WriteWindow.toInfluxDB(),
+		DedupeWindow:        time.Duration(b.DedupeWindowSeconds) * time.Second,
+		JSONWriteRules:      b.JSONWriteRules,
+		AggregationHints:    b.AggregationHints,
+		CompressionCodec:    b.CompressionCodec,
+		SoftTTLs:            b.SoftTTLs,
+		LastValueCache:      b.LastValueCache,
+		MaskingRules:        b.MaskingRules,
+		WriteConsistency:    b.WriteConsistency,
 		CRUDLog:             b.CRUDLog,
 	}, nil
 }
@@ -212,15 +279,35 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		DuplicatePolicy:     pb.DuplicatePolicy,
+		WriteWindow:         newWriteWindow(pb.WriteWindow),
+		DedupeWindowSeconds: int64(pb.DedupeWindow.Round(time.Second) / time.Second),
+		JSONWriteRules:      pb.JSONWriteRules,
+		AggregationHints:    pb.AggregationHints,
+		CompressionCodec:    pb.CompressionCodec,
+		SoftTTLs:            pb.SoftTTLs,
+		LastValueCache:      pb.LastValueCache,
+		MaskingRules:        pb.MaskingRules,
+		WriteConsistency:    pb.WriteConsistency,
 		CRUDLog:             pb.CRUDLog,
 	}
 }
 
 // bucketUpdate is used for serialization/deserialization with retention rules.
 type bucketUpdate struct {
-	Name           *string         `json:"name,omitempty"`
-	Description    *string         `json:"description,omitempty"`
-	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	Name                *string                        `json:"name,omitempty"`
+	Description         *string                        `json:"description,omitempty"`
+	RetentionRules      []retentionRule                `json:"retentionRules,omitempty"`
+	DuplicatePolicy     *influxdb.DuplicatePointPolicy `json:"duplicatePolicy,omitempty"`
+	WriteWindow         *writeWindow                   `json:"writeWindow,omitempty"`
+	DedupeWindowSeconds *int64                         `json:"dedupeWindowSeconds,omitempty"`
+	JSONWriteRules      *[]influxdb.JSONWriteRule      `json:"jsonWriteRules,omitempty"`
+	AggregationHints    *[]influxdb.AggregationHint    `json:"aggregationHints,omitempty"`
+	CompressionCodec    *influxdb.CompressionCodec     `json:"compressionCodec,omitempty"`
+	SoftTTLs            *[]influxdb.SoftTTL            `json:"softTTLs,omitempty"`
+	LastValueCache      *bool                          `json:"lastValueCache,omitempty"`
+	MaskingRules        *[]influxdb.MaskingRule        `json:"maskingRules,omitempty"`
+	WriteConsistency    *influxdb.WriteConsistency     `json:"writeConsistency,omitempty"`
 }
 
 func (b *bucketUpdate) OK() error {
@@ -230,6 +317,49 @@ func (b *bucketUpdate) OK() error {
 			return err
 		}
 	}
+	if b.DuplicatePolicy != nil {
+		if err := b.DuplicatePolicy.Valid(); err != nil {
+			return err
+		}
+	}
+	if err := b.WriteWindow.toInfluxDB().Valid(); err != nil {
+		return err
+	}
+	if b.DedupeWindowSeconds != nil {
+		if err := influxdb.ValidDedupeWindow(time.Duration(*b.DedupeWindowSeconds) * time.Second); err != nil {
+			return err
+		}
+	}
+	if b.JSONWriteRules != nil {
+		if err := influxdb.ValidJSONWriteRules(*b.JSONWriteRules); err != nil {
+			return err
+		}
+	}
+	if b.AggregationHints != nil {
+		if err := influxdb.ValidAggregationHints(*b.AggregationHints); err != nil {
+			return err
+		}
+	}
+	if b.CompressionCodec != nil {
+		if err := b.CompressionCodec.Valid(); err != nil {
+			return err
+		}
+	}
+	if b.SoftTTLs != nil {
+		if err := influxdb.ValidSoftTTLs(*b.SoftTTLs); err != nil {
+			return err
+		}
+	}
+	if b.MaskingRules != nil {
+		if err := influxdb.ValidMaskingRules(*b.MaskingRules); err != nil {
+			return err
+		}
+	}
+	if b.WriteConsistency != nil {
+		if err := b.WriteConsistency.Valid(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -244,11 +374,25 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 		d, _ = b.RetentionRules[0].RetentionPeriod()
 	}
 
-	return &influxdb.BucketUpdate{
-		Name:            b.Name,
-		Description:     b.Description,
-		RetentionPeriod: &d,
+	upd := &influxdb.BucketUpdate{
+		Name:             b.Name,
+		Description:      b.Description,
+		RetentionPeriod:  &d,
+		DuplicatePolicy:  b.DuplicatePolicy,
+		WriteWindow:      b.WriteWindow.toInfluxDB(),
+		JSONWriteRules:   b.JSONWriteRules,
+		AggregationHints: b.AggregationHints,
+		CompressionCodec: b.CompressionCodec,
+		SoftTTLs:         b.SoftTTLs,
+		LastValueCache:   b.LastValueCache,
+		MaskingRules:     b.MaskingRules,
+		WriteConsistency: b.WriteConsistency,
 	}
+	if b.DedupeWindowSeconds != nil {
+		dw := time.Duration(*b.DedupeWindowSeconds) * time.Second
+		upd.DedupeWindow = &dw
+	}
+	return upd
 }
 
 func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
@@ -257,9 +401,23 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 	}
 
 	up := &bucketUpdate{
-		Name:           pb.Name,
-		Description:    pb.Description,
-		RetentionRules: []retentionRule{},
+		Name:             pb.Name,
+		Description:      pb.Description,
+		RetentionRules:   []retentionRule{},
+		DuplicatePolicy:  pb.DuplicatePolicy,
+		WriteWindow:      newWriteWindow(pb.WriteWindow),
+		JSONWriteRules:   pb.JSONWriteRules,
+		AggregationHints: pb.AggregationHints,
+		CompressionCodec: pb.CompressionCodec,
+		SoftTTLs:         pb.SoftTTLs,
+		LastValueCache:   pb.LastValueCache,
+		MaskingRules:     pb.MaskingRules,
+		WriteConsistency: pb.WriteConsistency,
+	}
+
+	if pb.DedupeWindow != nil {
+		dw := int64((*pb.DedupeWindow).Round(time.Second) / time.Second)
+		up.DedupeWindowSeconds = &dw
 	}
 
 	if pb.RetentionPeriod != nil {
@@ -336,11 +494,21 @@ func (h *BucketHandler) handlePostBucket(w http.ResponseWriter, r *http.Request)
 }
 
 type postBucketRequest struct {
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Name                string          `json:"name"`
-	Description         string          `json:"description"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	OrgID               influxdb.ID                   `json:"orgID,omitempty"`
+	Name                string                        `json:"name"`
+	Description         string                        `json:"description"`
+	RetentionPolicyName string                        `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule               `json:"retentionRules"`
+	DuplicatePolicy     influxdb.DuplicatePointPolicy `json:"duplicatePolicy,omitempty"`
+	WriteWindow         *writeWindow                  `json:"writeWindow,omitempty"`
+	DedupeWindowSeconds int64                         `json:"dedupeWindowSeconds,omitempty"`
+	JSONWriteRules      []influxdb.JSONWriteRule      `json:"jsonWriteRules,omitempty"`
+	AggregationHints    []influxdb.AggregationHint    `json:"aggregationHints,omitempty"`
+	CompressionCodec    influxdb.CompressionCodec     `json:"compressionCodec,omitempty"`
+	SoftTTLs            []influxdb.SoftTTL            `json:"softTTLs,omitempty"`
+	LastValueCache      bool                          `json:"lastValueCache,omitempty"`
+	MaskingRules        []influxdb.MaskingRule        `json:"maskingRules,omitempty"`
+	WriteConsistency    influxdb.WriteConsistency     `json:"writeConsistency,omitempty"`
 }
 
 func (b *postBucketRequest) OK() error {
@@ -361,6 +529,42 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
+	if err := b.DuplicatePolicy.Valid(); err != nil {
+		return err
+	}
+
+	if err := b.WriteWindow.toInfluxDB().Valid(); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidDedupeWindow(time.Duration(b.DedupeWindowSeconds) * time.Second); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidJSONWriteRules(b.JSONWriteRules); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidAggregationHints(b.AggregationHints); err != nil {
+		return err
+	}
+
+	if err := b.CompressionCodec.Valid(); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidSoftTTLs(b.SoftTTLs); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidMaskingRules(b.MaskingRules); err != nil {
+		return err
+	}
+
+	if err := b.WriteConsistency.Valid(); err != nil {
+		return err
+	}
+
 	// names starting with an underscore are reserved for system buckets
 	if err := validBucketName(b.toInfluxDB()); err != nil {
 		return &influxdb.Error{
@@ -386,6 +590,16 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		Type:                influxdb.BucketTypeUser,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     dur,
+		DuplicatePolicy:     b.DuplicatePolicy,
+		WriteWindow:         b.WriteWindow.toInfluxDB(),
+		DedupeWindow:        time.Duration(b.DedupeWindowSeconds) * time.Second,
+		JSONWriteRules:      b.JSONWriteRules,
+		AggregationHints:    b.AggregationHints,
+		CompressionCodec:    b.CompressionCodec,
+		SoftTTLs:            b.SoftTTLs,
+		LastValueCache:      b.LastValueCache,
+		MaskingRules:        b.MaskingRules,
+		WriteConsistency:    b.WriteConsistency,
 	}
 }
 
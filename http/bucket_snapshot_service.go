@@ -0,0 +1,430 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// BucketSnapshotBackend is all services and associated parameters required to construct
+// the BucketSnapshotHandler.
+type BucketSnapshotBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	BucketSnapshotService influxdb.BucketSnapshotService
+}
+
+// NewBucketSnapshotBackend returns a new instance of BucketSnapshotBackend.
+func NewBucketSnapshotBackend(log *zap.Logger, b *APIBackend) *BucketSnapshotBackend {
+	return &BucketSnapshotBackend{
+		HTTPErrorHandler:      b.HTTPErrorHandler,
+		log:                   log,
+		BucketSnapshotService: b.BucketSnapshotService,
+	}
+}
+
+// BucketSnapshotHandler represents an HTTP API handler for bucket snapshots.
+type BucketSnapshotHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	BucketSnapshotService influxdb.BucketSnapshotService
+}
+
+const (
+	prefixSnapshots      = "/api/v2/snapshots"
+	snapshotsIDPath      = "/api/v2/snapshots/:id"
+	snapshotsIDClonePath = "/api/v2/snapshots/:id/clone"
+)
+
+// NewBucketSnapshotHandler returns a new instance of BucketSnapshotHandler.
+func NewBucketSnapshotHandler(log *zap.Logger, b *BucketSnapshotBackend) *BucketSnapshotHandler {
+	h := &BucketSnapshotHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		BucketSnapshotService: b.BucketSnapshotService,
+	}
+
+	h.HandlerFunc("POST", prefixSnapshots, h.handlePostSnapshot)
+	h.HandlerFunc("GET", prefixSnapshots, h.handleGetSnapshots)
+	h.HandlerFunc("GET", snapshotsIDPath, h.handleGetSnapshot)
+	h.HandlerFunc("DELETE", snapshotsIDPath, h.handleDeleteSnapshot)
+	h.HandlerFunc("POST", snapshotsIDClonePath, h.handlePostSnapshotClone)
+
+	return h
+}
+
+type snapshotResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.BucketSnapshot
+}
+
+func newSnapshotResponse(s *influxdb.BucketSnapshot) *snapshotResponse {
+	return &snapshotResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/snapshots/%s", s.ID),
+			"clone":  fmt.Sprintf("/api/v2/snapshots/%s/clone", s.ID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", s.BucketID),
+		},
+		BucketSnapshot: *s,
+	}
+}
+
+type snapshotsResponse struct {
+	Links     map[string]string   `json:"links"`
+	Snapshots []*snapshotResponse `json:"snapshots"`
+}
+
+func newSnapshotsResponse(ss []*influxdb.BucketSnapshot) *snapshotsResponse {
+	res := &snapshotsResponse{
+		Links: map[string]string{
+			"self": prefixSnapshots,
+		},
+		Snapshots: make([]*snapshotResponse, 0, len(ss)),
+	}
+	for _, s := range ss {
+		res.Snapshots = append(res.Snapshots, newSnapshotResponse(s))
+	}
+	return res
+}
+
+type postSnapshotRequest struct {
+	OrgID       influxdb.ID `json:"orgID"`
+	BucketID    influxdb.ID `json:"bucketID"`
+	Name        string      `json:"name,omitempty"`
+	Description string      `json:"description,omitempty"`
+	ExpiresAt   *time.Time  `json:"expiresAt,omitempty"`
+}
+
+// handlePostSnapshot is the HTTP handler for the POST /api/v2/snapshots route.
+func (h *BucketSnapshotHandler) handlePostSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode bucket snapshot request",
+			Err:  err,
+		}, w)
+		return
+	}
+	if !req.OrgID.Valid() || !req.BucketID.Valid() {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "organization and bucket IDs are required",
+		}, w)
+		return
+	}
+
+	s := &influxdb.BucketSnapshot{
+		OrgID:       req.OrgID,
+		BucketID:    req.BucketID,
+		Name:        req.Name,
+		Description: req.Description,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := h.BucketSnapshotService.CreateBucketSnapshot(ctx, s); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket snapshot created", zap.String("snapshot", fmt.Sprint(s)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newSnapshotResponse(s)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetSnapshotsRequest(r *http.Request) (*influxdb.BucketSnapshotFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.BucketSnapshotFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("bucketID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}
+		}
+		filter.BucketID = id
+	}
+	if v := qp.Get("name"); v != "" {
+		filter.Name = &v
+	}
+	return filter, nil
+}
+
+// handleGetSnapshots is the HTTP handler for the GET /api/v2/snapshots route.
+func (h *BucketSnapshotHandler) handleGetSnapshots(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetSnapshotsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ss, err := h.BucketSnapshotService.FindBucketSnapshots(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket snapshots retrieved", zap.String("snapshots", fmt.Sprint(ss)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSnapshotsResponse(ss)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeSnapshotID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetSnapshot is the HTTP handler for the GET /api/v2/snapshots/:id route.
+func (h *BucketSnapshotHandler) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSnapshotID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	s, err := h.BucketSnapshotService.FindBucketSnapshotByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket snapshot retrieved", zap.String("snapshot", fmt.Sprint(s)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSnapshotResponse(s)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteSnapshot is the HTTP handler for the DELETE /api/v2/snapshots/:id route.
+func (h *BucketSnapshotHandler) handleDeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSnapshotID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.BucketSnapshotService.DeleteBucketSnapshot(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket snapshot deleted", zap.String("snapshotID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type postSnapshotCloneRequest struct {
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+}
+
+func (req *postSnapshotCloneRequest) toInfluxDB() (*influxdb.Bucket, error) {
+	b := &influxdb.Bucket{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        influxdb.BucketTypeUser,
+	}
+	if b.Name == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucket name is required",
+		}
+	}
+	if err := validBucketName(b); err != nil {
+		return nil, err
+	}
+
+	// Only support a single retention period for the moment
+	if len(req.RetentionRules) > 0 {
+		dur, err := req.RetentionRules[0].RetentionPeriod()
+		if err != nil {
+			return nil, err
+		}
+		b.RetentionPeriod = dur
+	}
+	return b, nil
+}
+
+// handlePostSnapshotClone is the HTTP handler for the POST /api/v2/snapshots/:id/clone route.
+func (h *BucketSnapshotHandler) handlePostSnapshotClone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSnapshotID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req postSnapshotCloneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode bucket snapshot clone request",
+			Err:  err,
+		}, w)
+		return
+	}
+	b, err := req.toInfluxDB()
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.BucketSnapshotService.CloneBucketSnapshot(ctx, id, b); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket snapshot cloned", zap.String("snapshotID", id.String()), zap.String("bucket", fmt.Sprint(b)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, NewBucketResponse(b, []*influxdb.Label{})); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// BucketSnapshotService connects to Influx via HTTP using tokens to manage bucket snapshots.
+type BucketSnapshotService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.BucketSnapshotService = (*BucketSnapshotService)(nil)
+
+// CreateBucketSnapshot snapshots the data of the bucket of s.
+func (s *BucketSnapshotService) CreateBucketSnapshot(ctx context.Context, snap *influxdb.BucketSnapshot) error {
+	var sr snapshotResponse
+	err := s.Client.
+		PostJSON(postSnapshotRequest{
+			OrgID:       snap.OrgID,
+			BucketID:    snap.BucketID,
+			Name:        snap.Name,
+			Description: snap.Description,
+			ExpiresAt:   snap.ExpiresAt,
+		}, prefixSnapshots).
+		DecodeJSON(&sr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*snap = sr.BucketSnapshot
+	return nil
+}
+
+// FindBucketSnapshotByID returns a single snapshot by ID.
+func (s *BucketSnapshotService) FindBucketSnapshotByID(ctx context.Context, id influxdb.ID) (*influxdb.BucketSnapshot, error) {
+	var sr snapshotResponse
+	err := s.Client.
+		Get(path.Join(prefixSnapshots, id.String())).
+		DecodeJSON(&sr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sr.BucketSnapshot, nil
+}
+
+// FindBucketSnapshots returns a list of snapshots that match filter.
+func (s *BucketSnapshotService) FindBucketSnapshots(ctx context.Context, filter influxdb.BucketSnapshotFilter) ([]*influxdb.BucketSnapshot, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.BucketID != nil {
+		params = append(params, [2]string{"bucketID", filter.BucketID.String()})
+	}
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var sr snapshotsResponse
+	err := s.Client.
+		Get(prefixSnapshots).
+		QueryParams(params...).
+		DecodeJSON(&sr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := make([]*influxdb.BucketSnapshot, 0, len(sr.Snapshots))
+	for _, snap := range sr.Snapshots {
+		ss = append(ss, &snap.BucketSnapshot)
+	}
+	return ss, nil
+}
+
+// DeleteBucketSnapshot removes a snapshot.
+func (s *BucketSnapshotService) DeleteBucketSnapshot(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixSnapshots, id.String())).
+		Do(ctx)
+}
+
+// CloneBucketSnapshot creates b from the snapshot.
+func (s *BucketSnapshotService) CloneBucketSnapshot(ctx context.Context, id influxdb.ID, b *influxdb.Bucket) error {
+	req := postSnapshotCloneRequest{
+		Name:        b.Name,
+		Description: b.Description,
+	}
+	if b.RetentionPeriod > 0 {
+		req.RetentionRules = []retentionRule{{
+			Type:         "expire",
+			EverySeconds: int64(b.RetentionPeriod / time.Second),
+		}}
+	}
+
+	var br bucketResponse
+	err := s.Client.
+		PostJSON(req, path.Join(prefixSnapshots, id.String(), "clone")).
+		DecodeJSON(&br).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	nb, err := br.toInfluxDB()
+	if err != nil {
+		return err
+	}
+	*b = *nb
+	return nil
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const prefixCapabilities = "/api/v2/capabilities"
+
+// capabilityVersion is the version of the API of the capabilities, none of
+// which has changed in a way clients must adapt to.
+const capabilityVersion = 1
+
+// NewCapabilities returns the capabilities of the server of the services of
+// b: the features whose services are set, with their limits.
+func NewCapabilities(b *APIBackend) *influxdb.Capabilities {
+	var cs []influxdb.Capability
+	add := func(enabled bool, name string, limits map[string]int64) {
+		if enabled {
+			cs = append(cs, influxdb.Capability{
+				Name:    name,
+				Version: capabilityVersion,
+				Limits:  limits,
+			})
+		}
+	}
+
+	add(b.FluxService != nil, influxdb.CapabilityFlux, nil)
+	add(b.InfluxQLService != nil, influxdb.CapabilityInfluxQL, nil)
+	add(b.PointsWriter != nil, influxdb.CapabilityWrite, map[string]int64{
+		"maxBatchSizeBytes": b.MaxBatchSizeBytes,
+		"parserMaxBytes":    int64(b.WriteParserMaxBytes),
+		"parserMaxLines":    int64(b.WriteParserMaxLines),
+		"parserMaxValues":   int64(b.WriteParserMaxValues),
+	})
+	add(b.DeleteService != nil, influxdb.CapabilityDelete, nil)
+	add(b.BackupService != nil, influxdb.CapabilityBackup, nil)
+	add(b.SignedQueryService != nil, influxdb.CapabilitySignedQueries, nil)
+	add(b.DBRPImportService != nil, influxdb.CapabilityDBRPImport, map[string]int64{
+		"maxBytes": maxDBRPImportSize,
+	})
+	add(b.LastValueService != nil, influxdb.CapabilityLastValues, nil)
+	add(b.LegalHoldService != nil, influxdb.CapabilityLegalHolds, nil)
+	add(b.ParquetExportService != nil, influxdb.CapabilityParquetExport, nil)
+	add(b.BucketSnapshotService != nil, influxdb.CapabilityBucketSnapshot, nil)
+	if b.QueryResultSpill != nil {
+		add(true, influxdb.CapabilityQueryPages, map[string]int64{
+			"maxPageSize": maxQueryResultPageSize,
+			"maxBytes":    b.QueryResultSpill.MaxBytes,
+			"ttlSeconds":  int64(b.QueryResultSpill.TTL / time.Second),
+		})
+	}
+
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Name < cs[j].Name })
+	return &influxdb.Capabilities{
+		Version:      influxdb.GetBuildInfo().Version,
+		Capabilities: cs,
+	}
+}
+
+// CapabilitiesHandler represents an HTTP API handler for the capabilities
+// of the server.
+type CapabilitiesHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	Capabilities *influxdb.Capabilities
+}
+
+// NewCapabilitiesHandler returns a new instance of CapabilitiesHandler.
+func NewCapabilitiesHandler(log *zap.Logger, he influxdb.HTTPErrorHandler, c *influxdb.Capabilities) *CapabilitiesHandler {
+	h := &CapabilitiesHandler{
+		Router:           NewRouter(he),
+		HTTPErrorHandler: he,
+		log:              log,
+		Capabilities:     c,
+	}
+
+	h.HandlerFunc("GET", prefixCapabilities, h.handleGetCapabilities)
+
+	return h
+}
+
+type capabilitiesResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.Capabilities
+}
+
+// handleGetCapabilities is the HTTP handler for the GET /api/v2/capabilities route.
+func (h *CapabilitiesHandler) handleGetCapabilities(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	res := &capabilitiesResponse{
+		Links: map[string]string{
+			"self": prefixCapabilities,
+		},
+		Capabilities: *h.Capabilities,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// CapabilityService connects to Influx via HTTP to find the capabilities of
+// the server.
+type CapabilityService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.CapabilityService = (*CapabilityService)(nil)
+
+// FindCapabilities returns the capabilities of the server.
+func (s *CapabilityService) FindCapabilities(ctx context.Context) (*influxdb.Capabilities, error) {
+	var res capabilitiesResponse
+	err := s.Client.
+		Get(prefixCapabilities).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Capabilities, nil
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCapabilitiesHandler_handleGetCapabilities(t *testing.T) {
+	c := NewCapabilities(&APIBackend{
+		LegalHoldService:  &LegalHoldService{},
+		DBRPImportService: &DBRPImportService{},
+	})
+	h := NewCapabilitiesHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0), c)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/capabilities", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", w.Code)
+	}
+
+	var res capabilitiesResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Capabilities.Capabilities) != 2 {
+		t.Fatalf("unexpected capabilities %+v", res.Capabilities)
+	}
+	dbrp, ok := res.Find(influxdb.CapabilityDBRPImport)
+	if !ok || dbrp.Version != 1 || dbrp.Limits["maxBytes"] != maxDBRPImportSize {
+		t.Errorf("unexpected dbrp import capability %+v", dbrp)
+	}
+	if _, ok := res.Find(influxdb.CapabilityLegalHolds); !ok {
+		t.Error("expected legal holds to be enabled")
+	}
+	if _, ok := res.Find(influxdb.CapabilityWrite); ok {
+		t.Error("expected writes to be disabled without a points writer")
+	}
+}
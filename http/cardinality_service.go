@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// SeriesCardinalityBackend is all services and associated parameters required
+// to construct the SeriesCardinalityHandler.
+type SeriesCardinalityBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	SeriesCardinalityService influxdb.SeriesCardinalityService
+}
+
+// NewSeriesCardinalityBackend returns a new instance of SeriesCardinalityBackend.
+func NewSeriesCardinalityBackend(b *APIBackend) *SeriesCardinalityBackend {
+	return &SeriesCardinalityBackend{
+		Logger: b.Logger.With(zap.String("handler", "cardinality")),
+
+		HTTPErrorHandler:         b.HTTPErrorHandler,
+		SeriesCardinalityService: b.SeriesCardinalityService,
+	}
+}
+
+// SeriesCardinalityHandler is the http handler for the series cardinality reports
+// of buckets.
+type SeriesCardinalityHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	SeriesCardinalityService influxdb.SeriesCardinalityService
+}
+
+const prefixCardinality = "/api/v2/cardinality"
+
+// NewSeriesCardinalityHandler creates a new handler at /api/v2/cardinality
+// reporting the tags contributing the most series to a bucket.
+func NewSeriesCardinalityHandler(b *SeriesCardinalityBackend) *SeriesCardinalityHandler {
+	h := &SeriesCardinalityHandler{
+		HTTPErrorHandler:         b.HTTPErrorHandler,
+		Router:                   NewRouter(b.HTTPErrorHandler),
+		Logger:                   b.Logger,
+		SeriesCardinalityService: b.SeriesCardinalityService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixCardinality, h.handleGetSeriesCardinality)
+
+	return h
+}
+
+type seriesCardinalityRequest struct {
+	orgID    influxdb.ID
+	bucketID influxdb.ID
+	limit    int
+	csv      bool
+}
+
+func decodeSeriesCardinalityRequest(r *http.Request) (*seriesCardinalityRequest, error) {
+	qp := r.URL.Query()
+	req := &seriesCardinalityRequest{
+		csv: qp.Get("format") == "csv" || r.Header.Get("Accept") == "text/csv",
+	}
+	if err := req.orgID.DecodeFromString(qp.Get("orgID")); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	if err := req.bucketID.DecodeFromString(qp.Get("bucketID")); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		}
+	}
+	if v := qp.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "limit must be a positive integer",
+			}
+		}
+		req.limit = n
+	}
+	return req, nil
+}
+
+func (h *SeriesCardinalityHandler) handleGetSeriesCardinality(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SeriesCardinalityHandler.handleGetSeriesCardinality")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	req, err := decodeSeriesCardinalityRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	report, err := h.SeriesCardinalityService.SeriesCardinalityReport(ctx, req.orgID, req.bucketID, req.limit)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("Series cardinality reported",
+		zap.Stringer("orgID", req.orgID),
+		zap.Stringer("bucketID", req.bucketID),
+		zap.Int64("seriesN", report.SeriesN))
+
+	if req.csv {
+		if err := encodeSeriesCardinalityCSV(w, report); err != nil {
+			logEncodingError(h.Logger, r, err)
+		}
+		return
+	}
+
+	if report.TagKeys == nil {
+		report.TagKeys = []influxdb.TagKeyCardinality{}
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, report); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// encodeSeriesCardinalityCSV writes a row for each tag value of the report,
+// with the number of values of its key.
+func encodeSeriesCardinalityCSV(w http.ResponseWriter, report *influxdb.SeriesCardinalityReport) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="cardinality-`+report.BucketID.String()+`.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"key", "key_values", "key_series", "value", "value_series"}); err != nil {
+		return err
+	}
+	for _, k := range report.TagKeys {
+		for _, v := range k.Values {
+			if err := cw.Write([]string{
+				k.Key,
+				strconv.FormatInt(k.ValueN, 10),
+				strconv.FormatInt(k.SeriesN, 10),
+				v.Value,
+				strconv.FormatInt(v.SeriesN, 10),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// SeriesCardinalityService is the client implementation of influxdb.SeriesCardinalityService.
+type SeriesCardinalityService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.SeriesCardinalityService = (*SeriesCardinalityService)(nil)
+
+// SeriesCardinalityReport returns the series cardinality of the bucket broken
+// down by tag key and value.
+func (s *SeriesCardinalityService) SeriesCardinalityReport(ctx context.Context, orgID, bucketID influxdb.ID, limit int) (*influxdb.SeriesCardinalityReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var report influxdb.SeriesCardinalityReport
+	err := s.Client.
+		Get(prefixCardinality).
+		QueryParams(
+			[2]string{"orgID", orgID.String()},
+			[2]string{"bucketID", bucketID.String()},
+			[2]string{"limit", strconv.Itoa(limit)},
+		).
+		DecodeJSON(&report).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+type seriesCardinalityReportFunc func(ctx context.Context, orgID, bucketID influxdb.ID, limit int) (*influxdb.SeriesCardinalityReport, error)
+
+func (f seriesCardinalityReportFunc) SeriesCardinalityReport(ctx context.Context, orgID, bucketID influxdb.ID, limit int) (*influxdb.SeriesCardinalityReport, error) {
+	return f(ctx, orgID, bucketID, limit)
+}
+
+func TestSeriesCardinalityHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		statusCode  int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "reports bucket",
+			query:       "?orgID=020f755c3c082000&bucketID=020f755c3c082001&limit=2",
+			statusCode:  http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        `{"orgID": "020f755c3c082000", "bucketID": "020f755c3c082001", "seriesN": 3, "tagKeys": [{"key": "host", "seriesN": 3, "valueN": 3, "values": [{"value": "a", "seriesN": 1}, {"value": "b", "seriesN": 1}]}]}`,
+		},
+		{
+			name:        "exports csv",
+			query:       "?orgID=020f755c3c082000&bucketID=020f755c3c082001&limit=2",
+			accept:      "text/csv",
+			statusCode:  http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			body:        "key,key_values,key_series,value,value_series\nhost,3,3,a,1\nhost,3,3,b,1\n",
+		},
+		{
+			name:        "invalid limit",
+			query:       "?orgID=020f755c3c082000&bucketID=020f755c3c082001&limit=-1",
+			statusCode:  http.StatusBadRequest,
+			contentType: "application/json; charset=utf-8",
+			body:        `{"code": "invalid", "message": "limit must be a positive integer"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewSeriesCardinalityHandler(&SeriesCardinalityBackend{
+				Logger:           zaptest.NewLogger(t),
+				HTTPErrorHandler: kithttp.ErrorHandler(0),
+				SeriesCardinalityService: seriesCardinalityReportFunc(func(ctx context.Context, orgID, bucketID influxdb.ID, limit int) (*influxdb.SeriesCardinalityReport, error) {
+					if orgID != 0x020f755c3c082000 || bucketID != 0x020f755c3c082001 || limit != 2 {
+						t.Errorf("unexpected org, bucket and limit: %s, %s, %d", orgID, bucketID, limit)
+					}
+					return &influxdb.SeriesCardinalityReport{
+						OrgID:    orgID,
+						BucketID: bucketID,
+						SeriesN:  3,
+						TagKeys: []influxdb.TagKeyCardinality{{
+							Key:     "host",
+							SeriesN: 3,
+							ValueN:  3,
+							Values:  []influxdb.TagValueCardinality{{Value: "a", SeriesN: 1}, {Value: "b", SeriesN: 1}},
+						}},
+					}, nil
+				}),
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "http://any.url/api/v2/cardinality"+tt.query, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("got status code %d, want %d: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if got := res.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("got content type %q, want %q", got, tt.contentType)
+			}
+			if tt.accept == "text/csv" {
+				if string(body) != tt.body {
+					t.Errorf("got body %q, want %q", body, tt.body)
+				}
+				return
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.body); err != nil || !eq {
+				t.Errorf("unexpected body -want/+got:\n%s (%v)", diff, err)
+			}
+		})
+	}
+}
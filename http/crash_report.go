@@ -0,0 +1,269 @@
+package http
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader is the header of the response to a request which panicked,
+// with the ID of the crash report of the request.
+const RequestIDHeader = "X-Influx-Request-ID"
+
+// redactedQueryParams are the query parameters whose values are credentials,
+// such as the user and password of v1 requests and the signature of signed
+// URLs, which are redacted from crash reports.
+var redactedQueryParams = map[string]bool{
+	"u":         true,
+	"p":         true,
+	"token":     true,
+	"signature": true,
+}
+
+// redactedHeaders are the headers which are left out of crash reports.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+}
+
+// CrashReport is the report of a panic handling a request.
+type CrashReport struct {
+	ID        string             `json:"id"`
+	Time      time.Time          `json:"time"`
+	Handler   string             `json:"handler"`
+	Panic     string             `json:"panic"`
+	Stack     string             `json:"stack"`
+	Request   CrashReportRequest `json:"request"`
+	Build     influxdb.BuildInfo `json:"build"`
+	GoVersion string             `json:"goVersion"`
+}
+
+// CrashReportRequest is the request which panicked in a crash report.
+type CrashReportRequest struct {
+	Method        string            `json:"method"`
+	Host          string            `json:"host"`
+	Path          string            `json:"path"`
+	Query         string            `json:"query,omitempty"`
+	Proto         string            `json:"proto"`
+	Remote        string            `json:"remote"`
+	ContentLength int64             `json:"contentLength"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+// CrashReporter recovers panics handling requests. The request is answered
+// with an internal error carrying a request ID, and a crash report with the
+// same ID is logged and written to the crash report directory.
+type CrashReporter struct {
+	dir string
+	log *zap.Logger
+	now func() time.Time
+
+	panics *prometheus.CounterVec
+}
+
+// NewCrashReporter returns a CrashReporter writing crash reports to files in
+// dir, which is created with the first report. If dir is empty, reports are
+// only logged.
+func NewCrashReporter(log *zap.Logger, dir string) *CrashReporter {
+	return &CrashReporter{
+		dir: dir,
+		log: log,
+		now: time.Now,
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "http",
+			Subsystem: "api",
+			Name:      "panics_total",
+			Help:      "Number of panics recovered handling http requests",
+		}, []string{"handler", "method"}),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the reporter.
+func (c *CrashReporter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{c.panics}
+}
+
+// Recover returns middleware recovering panics in the handlers of name.
+func (c *CrashReporter) Recover(name string) kithttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			rw := &crashResponseWriter{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				// ErrAbortHandler aborts a response on purpose and is
+				// recovered, quietly, by the server.
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				id := c.report(name, r, v, debug.Stack())
+				if rw.written {
+					// The response is already underway, so the best that
+					// can be done is to abort it.
+					panic(http.ErrAbortHandler)
+				}
+				w.Header().Set(RequestIDHeader, id)
+				kithttp.ErrorHandler(0).HandleHTTPError(r.Context(), &influxdb.Error{
+					Code: influxdb.EInternal,
+					Msg:  fmt.Sprintf("internal error handling request %s", id),
+				}, w)
+			}()
+			next.ServeHTTP(rw, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// report logs the crash report of a panic handling r and writes it to the
+// crash report directory. It returns the ID of the report.
+func (c *CrashReporter) report(name string, r *http.Request, v interface{}, stack []byte) string {
+	c.panics.WithLabelValues(name, r.Method).Inc()
+
+	b := make([]byte, 8)
+	rand.Read(b)
+	cr := CrashReport{
+		ID:      hex.EncodeToString(b),
+		Time:    c.now().UTC(),
+		Handler: name,
+		Panic:   fmt.Sprint(v),
+		Stack:   string(stack),
+		Request: CrashReportRequest{
+			Method:        r.Method,
+			Host:          r.Host,
+			Path:          r.URL.Path,
+			Query:         redactQuery(r.URL.RawQuery),
+			Proto:         r.Proto,
+			Remote:        r.RemoteAddr,
+			ContentLength: r.ContentLength,
+			Headers:       make(map[string]string, len(r.Header)),
+		},
+		Build:     influxdb.GetBuildInfo(),
+		GoVersion: runtime.Version(),
+	}
+	for k, v := range r.Header {
+		if len(v) == 0 || redactedHeaders[k] {
+			continue
+		}
+		cr.Request.Headers[k] = v[0]
+	}
+
+	log := c.log.With(zap.String("request_id", cr.ID))
+	log.Error("Recovered panic handling request",
+		zap.String("handler", name),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.String("panic", cr.Panic),
+		zap.ByteString("stack", stack),
+	)
+
+	if c.dir != "" {
+		if err := c.write(&cr); err != nil {
+			log.Warn("Failed to write crash report", zap.String("dir", c.dir), zap.Error(err))
+		}
+	}
+	return cr.ID
+}
+
+// redactQuery returns the raw query with the values of the parameters that
+// are credentials replaced, keeping the other parameters as they are.
+func redactQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	params := strings.Split(raw, "&")
+	for i, param := range params {
+		k := param
+		if j := strings.IndexByte(param, '='); j >= 0 {
+			k = param[:j]
+		}
+		if unescaped, err := url.QueryUnescape(k); err == nil {
+			k = unescaped
+		}
+		if redactedQueryParams[strings.ToLower(k)] {
+			params[i] = url.QueryEscape(k) + "=REDACTED"
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// write writes a crash report to its file in the crash report directory.
+func (c *CrashReporter) write(cr *CrashReport) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cr, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("crash-%s-%s.json", cr.Time.Format("20060102T150405Z"), cr.ID)
+	return ioutil.WriteFile(filepath.Join(c.dir, name), data, 0600)
+}
+
+// crashResponseWriter tracks whether a response has been started, after
+// which the error of a panic can no longer be written.
+type crashResponseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *crashResponseWriter) WriteHeader(code int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *crashResponseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the response, if the underlying writer supports it.
+func (w *crashResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.written = true
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection, if the underlying writer supports it.
+// The error of a panic is not written to a hijacked connection.
+func (w *crashResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking the connection")
+	}
+	w.written = true
+	return h.Hijack()
+}
+
+// CloseNotify returns the channel of the underlying writer notifying that
+// the client has gone away. If the writer does not support it, the channel
+// is never notified.
+func (w *crashResponseWriter) CloseNotify() <-chan bool {
+	//lint:ignore SA1019 CloseNotifier is forwarded for the handlers still using it.
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
+}
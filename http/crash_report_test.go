@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCrashReporter_Recover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crash-reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewCrashReporter(zaptest.NewLogger(t), filepath.Join(dir, "reports"))
+	h := c.Recover("test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	r := httptest.NewRequest("POST", "/api/v2/write?org=o", nil)
+	r.Header.Set("Authorization", "Token secret")
+	r.Header.Set("User-Agent", "test")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("got status %d, want 500", w.Code)
+	}
+	id := w.Header().Get(RequestIDHeader)
+	if id == "" {
+		t.Fatal("expected a request ID")
+	}
+	if !strings.Contains(w.Body.String(), id) {
+		t.Errorf("expected the error to contain the request ID, got %s", w.Body.String())
+	}
+	if got := testutil.ToFloat64(c.panics.WithLabelValues("test", "POST")); got != 1 {
+		t.Errorf("got %v panics, want 1", got)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "reports", "crash-*-"+id+".json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one crash report, got %v: %v", files, err)
+	}
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cr CrashReport
+	if err := json.Unmarshal(data, &cr); err != nil {
+		t.Fatal(err)
+	}
+	if cr.ID != id || cr.Handler != "test" || cr.Panic != "boom" || cr.Request.Path != "/api/v2/write" || cr.Request.Query != "org=o" {
+		t.Errorf("unexpected crash report %+v", cr)
+	}
+	if !strings.Contains(cr.Stack, "TestCrashReporter_Recover") {
+		t.Errorf("expected the stack of the panic, got %s", cr.Stack)
+	}
+	if _, ok := cr.Request.Headers["Authorization"]; ok {
+		t.Error("expected the authorization header to be left out of the crash report")
+	}
+	if cr.Request.Headers["User-Agent"] != "test" {
+		t.Errorf("unexpected headers %v", cr.Request.Headers)
+	}
+}
+
+func TestCrashReporter_RecoverAfterWrite(t *testing.T) {
+	c := NewCrashReporter(zaptest.NewLogger(t), "")
+	h := c.Recover("test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("boom")
+	}))
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("expected the response to be aborted, got %v", v)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestCrashReporter_RedactsCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crash-reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewCrashReporter(zaptest.NewLogger(t), dir)
+	h := c.Recover("test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	r := httptest.NewRequest("GET", "/query?db=telegraf&u=admin&p=hunter2&q=SHOW+DATABASES&token=t0ken&signature=s1g", nil)
+	r.Header.Set("Proxy-Authorization", "Basic secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	files, err := filepath.Glob(filepath.Join(dir, "crash-*-"+w.Header().Get(RequestIDHeader)+".json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one crash report, got %v: %v", files, err)
+	}
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"admin", "hunter2", "t0ken", "s1g", "Basic secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %q to be redacted from the crash report:\n%s", secret, data)
+		}
+	}
+
+	var cr CrashReport
+	if err := json.Unmarshal(data, &cr); err != nil {
+		t.Fatal(err)
+	}
+	if want := "db=telegraf&u=REDACTED&p=REDACTED&q=SHOW+DATABASES&token=REDACTED&signature=REDACTED"; cr.Request.Query != want {
+		t.Errorf("got query %q, want %q", cr.Request.Query, want)
+	}
+}
+
+func TestCrashReporter_ForwardsResponseWriterInterfaces(t *testing.T) {
+	c := NewCrashReporter(zaptest.NewLogger(t), "")
+	h := c.Recover("test")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.CloseNotifier); !ok {
+			t.Error("expected the response writer to be a CloseNotifier")
+		}
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("expected the response writer to be a Hijacker")
+		}
+		conn, rw, err := hj.Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+		rw.Flush()
+	}))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("unexpected response %d %q", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+const (
+	dashboardsIDCellsIDCSVPath = "/api/v2/dashboards/:id/cells/:cellID/csv"
+	dashboardsIDCellsIDPNGPath = "/api/v2/dashboards/:id/cells/:cellID/png"
+
+	// defaultCellExportRange is how far back from its stop the queries of an
+	// exported cell start by default.
+	defaultCellExportRange = time.Hour
+)
+
+var errDashboardCellExportDisabled = &influxdb.Error{
+	Code: influxdb.ENotImplemented,
+	Msg:  "dashboard cell export is not enabled",
+}
+
+type dashboardCellExportRequest struct {
+	dashboardID influxdb.ID
+	cellID      influxdb.ID
+	now         time.Time
+	start       time.Time
+	stop        time.Time
+	width       int
+	height      int
+}
+
+func decodeDashboardCellExportRequest(ctx context.Context, r *http.Request) (*dashboardCellExportRequest, error) {
+	ids, err := decodeGetDashboardCellViewRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := &dashboardCellExportRequest{
+		dashboardID: ids.dashboardID,
+		cellID:      ids.cellID,
+		now:         time.Now().UTC(),
+		width:       influxdb.DefaultCellRenderWidth,
+		height:      influxdb.DefaultCellRenderHeight,
+	}
+
+	qp := r.URL.Query()
+	req.stop = req.now
+	if v := qp.Get("stop"); v != "" {
+		if req.stop, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "stop must be an RFC3339 time",
+			}
+		}
+	}
+	req.start = req.stop.Add(-defaultCellExportRange)
+	if v := qp.Get("start"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d < 0 {
+			req.start = req.stop.Add(d)
+		} else if req.start, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "start must be an RFC3339 time or a negative duration, such as -1h",
+			}
+		}
+	}
+	if !req.start.Before(req.stop) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "start must be before stop",
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		v    *int
+	}{{"width", &req.width}, {"height", &req.height}} {
+		v := qp.Get(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > influxdb.MaxCellRenderSize {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("%s must be a number of pixels between 1 and %d", p.name, influxdb.MaxCellRenderSize),
+			}
+		}
+		*p.v = n
+	}
+	return req, nil
+}
+
+// dashboardCellQueries returns the view of the cell and the requests of its
+// queries, over the time range of the export.
+func (h *DashboardHandler) dashboardCellQueries(ctx context.Context, r *http.Request, req *dashboardCellExportRequest) (*influxdb.View, []*query.ProxyRequest, error) {
+	d, err := h.DashboardService.FindDashboardByID(ctx, req.dashboardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	view, err := h.DashboardService.GetDashboardCellView(ctx, req.dashboardID, req.cellID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the export request",
+			Err:  err,
+		}
+	}
+	auth, err := queryAuthorization(a, d.OrganizationID)
+	if err != nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Err:  err,
+		}
+	}
+
+	extern := query.DashboardExtern(req.start, req.stop, req.width)
+	var reqs []*query.ProxyRequest
+	for _, q := range influxdb.ViewQueries(view.Properties) {
+		if strings.TrimSpace(q.Text) == "" {
+			continue
+		}
+		reqs = append(reqs, &query.ProxyRequest{
+			Request: query.Request{
+				Authorization:  auth,
+				OrganizationID: d.OrganizationID,
+				Compiler: lang.FluxCompiler{
+					Now:    req.now,
+					Extern: extern,
+					Query:  q.Text,
+				},
+				Source: r.Header.Get("User-Agent"),
+			},
+			Dialect: &csv.Dialect{
+				ResultEncoderConfig: csv.ResultEncoderConfig{
+					Delimiter:   ',',
+					Annotations: []string{"datatype", "group", "default"},
+				},
+			},
+		})
+	}
+	if len(reqs) == 0 {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "dashboard cell has no queries to export",
+		}
+	}
+	return view, reqs, nil
+}
+
+// queryDashboardCell writes the results of the queries as annotated CSV,
+// separating the results of each query with an empty line. The queries run
+// with their authorization, so they only read the buckets it can read.
+func (h *DashboardHandler) queryDashboardCell(ctx context.Context, w io.Writer, reqs []*query.ProxyRequest) error {
+	for i, req := range reqs {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\r\n"); err != nil {
+				return err
+			}
+		}
+		ctx := pcontext.SetAuthorizer(ctx, req.Request.Authorization)
+		if _, err := h.QueryService.Query(ctx, w, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *DashboardHandler) handleGetDashboardCellCSV(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DashboardHandler.handleGetDashboardCellCSV")
+	defer span.Finish()
+
+	ctx := r.Context()
+	if h.QueryService == nil {
+		h.HandleHTTPError(ctx, errDashboardCellExportDisabled, w)
+		return
+	}
+	req, err := decodeDashboardCellExportRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	_, reqs, err := h.dashboardCellQueries(ctx, r, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, req.cellID))
+	cw := iocounter.Writer{Writer: w}
+	if err := h.queryDashboardCell(ctx, &cw, reqs); err != nil {
+		if cw.Count() == 0 {
+			w.Header().Del("Content-Disposition")
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		_ = tracing.LogError(span, err)
+		h.log.Info("Error writing dashboard cell CSV to client",
+			zap.String("dashboardID", req.dashboardID.String()),
+			zap.String("cellID", req.cellID.String()),
+			zap.Error(err))
+	}
+}
+
+func (h *DashboardHandler) handleGetDashboardCellPNG(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DashboardHandler.handleGetDashboardCellPNG")
+	defer span.Finish()
+
+	ctx := r.Context()
+	if h.QueryService == nil || h.CellRenderer == nil {
+		h.HandleHTTPError(ctx, errDashboardCellExportDisabled, w)
+		return
+	}
+	req, err := decodeDashboardCellExportRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	view, reqs, err := h.dashboardCellQueries(ctx, r, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var results bytes.Buffer
+	if err := h.queryDashboardCell(ctx, &results, reqs); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	itr, err := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(ioutil.NopCloser(&results))
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	var img bytes.Buffer
+	if err := h.CellRenderer.RenderPNG(ctx, &img, view, itr, req.width, req.height); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.png"`, req.cellID))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(img.Bytes()); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
+)
+
+func TestService_handleGetDashboardCellCSV(t *testing.T) {
+	const csvResult = "#datatype,string,long,dateTime:RFC3339,double\r\n#group,false,false,false,false\r\n#default,_result,,,\r\n,result,table,_time,_value\r\n,,0,2020-01-01T00:00:00Z,1\r\n\r\n"
+
+	auth := &platform.Authorization{ID: 1, OrgID: 2}
+	tests := []struct {
+		name       string
+		query      string
+		properties platform.ViewProperties
+		statusCode int
+		body       string
+	}{
+		{
+			name:  "exports the results of the queries",
+			query: "?start=2020-01-01T00:00:00Z&stop=2020-01-01T01:00:00Z",
+			properties: platform.XYViewProperties{
+				Type: platform.ViewPropertyTypeXY,
+				Queries: []platform.DashboardQuery{
+					{Text: "from(bucket: \"a\") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)"},
+					{Text: " "},
+					{Text: "from(bucket: \"b\") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)"},
+				},
+			},
+			statusCode: http.StatusOK,
+			body:       csvResult + "\r\n" + csvResult,
+		},
+		{
+			name:       "cell without queries",
+			properties: platform.MarkdownViewProperties{Type: platform.ViewPropertyTypeMarkdown},
+			statusCode: http.StatusBadRequest,
+			body:       `{"code":"invalid","message":"dashboard cell has no queries to export"}`,
+		},
+		{
+			name:       "start after stop",
+			query:      "?start=2020-01-01T02:00:00Z&stop=2020-01-01T01:00:00Z",
+			statusCode: http.StatusBadRequest,
+			body:       `{"code":"invalid","message":"start must be before stop"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dashboardService := mock.NewDashboardService()
+			dashboardService.FindDashboardByIDF = func(ctx context.Context, id platform.ID) (*platform.Dashboard, error) {
+				return &platform.Dashboard{ID: id, OrganizationID: 2}, nil
+			}
+			dashboardService.GetDashboardCellViewF = func(ctx context.Context, dashboardID, cellID platform.ID) (*platform.View, error) {
+				return &platform.View{Properties: tt.properties}, nil
+			}
+
+			backend := NewMockDashboardBackend(t)
+			backend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			backend.DashboardService = dashboardService
+			backend.QueryService = &querymock.ProxyQueryService{
+				QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+					if req.Request.Authorization != auth || req.Request.OrganizationID != 2 {
+						t.Errorf("unexpected authorization and organization: %v, %s", req.Request.Authorization, req.Request.OrganizationID)
+					}
+					c, ok := req.Request.Compiler.(lang.FluxCompiler)
+					if !ok || c.Extern == nil {
+						t.Errorf("expected a flux compiler with the time range, got %#v", req.Request.Compiler)
+					}
+					_, err := io.WriteString(w, csvResult)
+					return flux.Statistics{}, err
+				},
+			}
+			h := NewDashboardHandler(backend.log, backend)
+
+			r := httptest.NewRequest(http.MethodGet, "http://any.url/api/v2/dashboards/020f755c3c082000/cells/020f755c3c082001/csv"+tt.query, nil)
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), auth))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("got status code %d, want %d: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if tt.statusCode != http.StatusOK {
+				if eq, diff, err := jsonEqual(string(body), tt.body); err != nil || !eq {
+					t.Errorf("unexpected body -want/+got:\n%s (%v)", diff, err)
+				}
+				return
+			}
+			if got := string(body); got != tt.body {
+				t.Errorf("got body %q, want %q", got, tt.body)
+			}
+			if got, want := res.Header.Get("Content-Type"), "text/csv; charset=utf-8"; got != want {
+				t.Errorf("got content type %q, want %q", got, want)
+			}
+		})
+	}
+}
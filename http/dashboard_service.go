@@ -10,6 +10,7 @@ import (
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"github.com/influxdata/influxdb/v2/query"
 	"go.uber.org/zap"
 )
 
@@ -24,6 +25,12 @@ type DashboardBackend struct {
 	UserResourceMappingService   influxdb.UserResourceMappingService
 	LabelService                 influxdb.LabelService
 	UserService                  influxdb.UserService
+	ResourceActivityService      influxdb.ResourceActivityService
+
+	// QueryService runs the queries of the exported cells, which are
+	// rendered as images by CellRenderer.
+	QueryService query.ProxyQueryService
+	CellRenderer influxdb.DashboardCellRenderer
 }
 
 // NewDashboardBackend creates a backend used by the dashboard handler.
@@ -37,6 +44,9 @@ func NewDashboardBackend(log *zap.Logger, b *APIBackend) *DashboardBackend {
 		UserResourceMappingService:   b.UserResourceMappingService,
 		LabelService:                 b.LabelService,
 		UserService:                  b.UserService,
+		ResourceActivityService:      b.ResourceActivityService,
+		QueryService:                 b.FluxService,
+		CellRenderer:                 b.DashboardCellRenderer,
 	}
 }
 
@@ -52,6 +62,9 @@ type DashboardHandler struct {
 	UserResourceMappingService   influxdb.UserResourceMappingService
 	LabelService                 influxdb.LabelService
 	UserService                  influxdb.UserService
+	ResourceActivityService      influxdb.ResourceActivityService
+	QueryService                 query.ProxyQueryService
+	CellRenderer                 influxdb.DashboardCellRenderer
 }
 
 const (
@@ -81,6 +94,9 @@ func NewDashboardHandler(log *zap.Logger, b *DashboardBackend) *DashboardHandler
 		UserResourceMappingService:   b.UserResourceMappingService,
 		LabelService:                 b.LabelService,
 		UserService:                  b.UserService,
+		ResourceActivityService:      b.ResourceActivityService,
+		QueryService:                 b.QueryService,
+		CellRenderer:                 b.CellRenderer,
 	}
 
 	h.HandlerFunc("POST", prefixDashboards, h.handlePostDashboard)
@@ -97,6 +113,8 @@ func NewDashboardHandler(log *zap.Logger, b *DashboardBackend) *DashboardHandler
 
 	h.HandlerFunc("GET", dashboardsIDCellsIDViewPath, h.handleGetDashboardCellView)
 	h.HandlerFunc("PATCH", dashboardsIDCellsIDViewPath, h.handlePatchDashboardCellView)
+	h.HandlerFunc("GET", dashboardsIDCellsIDCSVPath, h.handleGetDashboardCellCSV)
+	h.HandlerFunc("GET", dashboardsIDCellsIDPNGPath, h.handleGetDashboardCellPNG)
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -150,6 +168,7 @@ type dashboardResponse struct {
 	OrganizationID influxdb.ID             `json:"orgID,omitempty"`
 	Name           string                  `json:"name"`
 	Description    string                  `json:"description"`
+	Slug           string                  `json:"slug,omitempty"`
 	Meta           influxdb.DashboardMeta  `json:"meta"`
 	Cells          []dashboardCellResponse `json:"cells"`
 	Labels         []influxdb.Label        `json:"labels"`
@@ -169,6 +188,7 @@ func (d dashboardResponse) toinfluxdb() *influxdb.Dashboard {
 		OrganizationID: d.OrganizationID,
 		Name:           d.Name,
 		Description:    d.Description,
+		Slug:           d.Slug,
 		Meta:           d.Meta,
 		Cells:          cells,
 	}
@@ -189,6 +209,7 @@ func newDashboardResponse(d *influxdb.Dashboard, labels []*influxdb.Label) dashb
 		OrganizationID: d.OrganizationID,
 		Name:           d.Name,
 		Description:    d.Description,
+		Slug:           d.Slug,
 		Meta:           d.Meta,
 		Labels:         []influxdb.Label{},
 		Cells:          []dashboardCellResponse{},
@@ -517,6 +538,7 @@ func (h *DashboardHandler) handleGetDashboard(w http.ResponseWriter, r *http.Req
 	}
 
 	h.log.Debug("Dashboard retrieved", zap.String("dashboard", fmt.Sprint(dashboard)))
+	recordResourceActivity(ctx, h.log, h.ResourceActivityService, influxdb.DashboardsResourceType, dashboard.ID, influxdb.ResourceActivityRead)
 
 	if err := encodeResponse(ctx, w, http.StatusOK, newDashboardResponse(dashboard, labels)); err != nil {
 		logEncodingError(h.log, r, err)
@@ -0,0 +1,780 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// maxDBRPImportSize is the largest meta.db imported.
+const maxDBRPImportSize = 64 << 20
+
+// DBRPBackend is all services and associated parameters required to construct
+// the DBRPHandler.
+type DBRPBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	DBRPImportService  influxdb.DBRPImportService
+	DBRPMappingService influxdb.DBRPMappingService
+}
+
+// NewDBRPBackend returns a new instance of DBRPBackend.
+func NewDBRPBackend(log *zap.Logger, b *APIBackend) *DBRPBackend {
+	return &DBRPBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		log:                log,
+		DBRPImportService:  b.DBRPImportService,
+		DBRPMappingService: b.DBRPMappingService,
+	}
+}
+
+// DBRPHandler represents an HTTP API handler for the mappings of InfluxDB
+// 1.x databases and retention policies to buckets.
+type DBRPHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	DBRPImportService  influxdb.DBRPImportService
+	DBRPMappingService influxdb.DBRPMappingService
+}
+
+const (
+	prefixDBRPs      = "/api/v2/dbrps"
+	dbrpsDefaultPath = "/api/v2/dbrps/default"
+	dbrpsImportPath  = "/api/v2/dbrps/import"
+	dbrpsExportPath  = "/api/v2/dbrps/export"
+)
+
+// NewDBRPHandler returns a new instance of DBRPHandler.
+func NewDBRPHandler(log *zap.Logger, b *DBRPBackend) *DBRPHandler {
+	h := &DBRPHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		DBRPImportService:  b.DBRPImportService,
+		DBRPMappingService: b.DBRPMappingService,
+	}
+
+	h.HandlerFunc("GET", prefixDBRPs, h.handleGetDBRPs)
+	h.HandlerFunc("PUT", prefixDBRPs, h.handlePutDBRP)
+	h.HandlerFunc("DELETE", prefixDBRPs, h.handleDeleteDBRP)
+	h.HandlerFunc("POST", dbrpsDefaultPath, h.handlePostDBRPDefault)
+	h.HandlerFunc("POST", dbrpsImportPath, h.handlePostDBRPImport)
+	h.HandlerFunc("GET", dbrpsExportPath, h.handleGetDBRPExport)
+
+	return h
+}
+
+// dbrpMappingResponse is a mapping with its revision, which is given as
+// If-Match when replacing it.
+type dbrpMappingResponse struct {
+	*influxdb.DBRPMapping
+	Revision string `json:"revision"`
+}
+
+func newDBRPMappingResponse(m *influxdb.DBRPMapping) *dbrpMappingResponse {
+	return &dbrpMappingResponse{
+		DBRPMapping: m,
+		Revision:    m.Revision(),
+	}
+}
+
+type dbrpMappingsResponse struct {
+	Links      *influxdb.PagingLinks  `json:"links"`
+	Mappings   []*dbrpMappingResponse `json:"dbrps"`
+	TotalCount int                    `json:"totalCount"`
+}
+
+func decodeGetDBRPsRequest(r *http.Request) (influxdb.DBRPMappingFilter, influxdb.FindOptions, error) {
+	var filter influxdb.DBRPMappingFilter
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		return filter, influxdb.FindOptions{}, err
+	}
+	if opts.Offset < 0 {
+		return filter, influxdb.FindOptions{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "offset is invalid",
+		}
+	}
+	if err := influxdb.ValidateDBRPSort(*opts); err != nil {
+		return filter, influxdb.FindOptions{}, err
+	}
+
+	orgID, err := decodeDBRPOrgID(r)
+	if err != nil {
+		return filter, influxdb.FindOptions{}, err
+	}
+	filter.OrganizationID = &orgID
+
+	qp := r.URL.Query()
+	if v := qp.Get("cluster"); v != "" {
+		filter.Cluster = &v
+	}
+	if v := qp.Get("db"); v != "" {
+		filter.Database = &v
+	}
+	if v := qp.Get("rp"); v != "" {
+		filter.RetentionPolicy = &v
+	}
+	if v := qp.Get("default"); v != "" {
+		def, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, influxdb.FindOptions{}, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid default",
+				Err:  err,
+			}
+		}
+		filter.Default = &def
+	}
+	for _, v := range qp["bucketID"] {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, influxdb.FindOptions{}, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}
+		}
+		filter.BucketIDs = append(filter.BucketIDs, *id)
+	}
+	if v := qp.Get("dbPrefix"); v != "" {
+		filter.DatabasePrefix = &v
+	}
+	if v := qp.Get("dbPattern"); v != "" {
+		filter.DatabasePattern = &v
+	}
+	if err := filter.Validate(); err != nil {
+		return filter, influxdb.FindOptions{}, err
+	}
+	return filter, *opts, nil
+}
+
+// handleGetDBRPs is the HTTP handler for the GET /api/v2/dbrps route.
+func (h *DBRPHandler) handleGetDBRPs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, opts, err := decodeGetDBRPsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ms, n, err := h.DBRPMappingService.FindMany(ctx, filter, opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("DBRP mappings retrieved", zap.Int("count", len(ms)))
+
+	links := influxdb.NewPagingLinks(prefixDBRPs, opts, filter, len(ms))
+	if opts.Offset+len(ms) >= n {
+		// The total is known, so there is no next page to link to past
+		// the last mapping.
+		links.Next = ""
+	}
+	res := &dbrpMappingsResponse{
+		Links:      links,
+		Mappings:   make([]*dbrpMappingResponse, 0, len(ms)),
+		TotalCount: n,
+	}
+	for _, m := range ms {
+		res.Mappings = append(res.Mappings, newDBRPMappingResponse(m))
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePutDBRP is the HTTP handler for the PUT /api/v2/dbrps route. It
+// creates the mapping of the body, or replaces the mapping of its cluster,
+// database and retention policy. If the request has an If-Match header, the
+// mapping is only replaced if its revision still matches, and the request
+// fails with 412 otherwise. With "If-None-Match: *", the mapping is only
+// created, and the request fails with 409 if another mapping exists.
+func (h *DBRPHandler) handlePutDBRP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var m influxdb.DBRPMapping
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode dbrp mapping",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if strings.TrimSpace(r.Header.Get("If-None-Match")) == "*" {
+		if err := h.DBRPMappingService.Create(ctx, &m); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.log.Debug("DBRP mapping created", zap.String("database", m.Database), zap.String("rp", m.RetentionPolicy))
+	} else if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		revision := strings.Trim(strings.TrimSpace(ifMatch), `"`)
+		if revision == "*" {
+			// Any revision matches, as long as the mapping exists.
+			existing, err := h.DBRPMappingService.FindBy(ctx, m.Cluster, m.Database, m.RetentionPolicy)
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				err = influxdb.ErrDBRPMappingChanged
+			}
+			if err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+			revision = existing.Revision()
+		}
+		if err := h.DBRPMappingService.Replace(ctx, &m, revision); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.log.Debug("DBRP mapping replaced", zap.String("database", m.Database), zap.String("rp", m.RetentionPolicy))
+	} else {
+		if err := h.DBRPMappingService.Upsert(ctx, &m); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.log.Debug("DBRP mapping upserted", zap.String("database", m.Database), zap.String("rp", m.RetentionPolicy))
+	}
+
+	h.encodeDBRPMapping(w, r, &m)
+}
+
+// handleDeleteDBRP is the HTTP handler for the DELETE /api/v2/dbrps route. It
+// removes the mapping of the cluster, db and rp query parameters, and
+// succeeds if there is none. With a dbPrefix or dbPattern, it instead removes
+// all the mappings of the organization matching the same filter as
+// GET /api/v2/dbrps.
+func (h *DBRPHandler) handleDeleteDBRP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	qp := r.URL.Query()
+	if qp.Get("dbPrefix") != "" || qp.Get("dbPattern") != "" {
+		h.handleDeleteDBRPs(w, r)
+		return
+	}
+
+	cluster, db, rp := qp.Get("cluster"), qp.Get("db"), qp.Get("rp")
+	if db == "" || rp == "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "db and rp are required",
+		}, w)
+		return
+	}
+
+	if err := h.DBRPMappingService.Delete(ctx, cluster, db, rp); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("DBRP mapping deleted", zap.String("database", db), zap.String("rp", rp))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type deleteDBRPsResponse struct {
+	Mappings []*dbrpMappingResponse `json:"dbrps"`
+}
+
+// handleDeleteDBRPs removes the mappings matching the filter of the request,
+// and responds with the mappings it removed. Each mapping is deleted on its
+// own, and authorized as such, so the request stops at the first mapping
+// which cannot be deleted, keeping it and the ones after it.
+func (h *DBRPHandler) handleDeleteDBRPs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, _, err := decodeGetDBRPsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ms, _, err := h.DBRPMappingService.FindMany(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	res := &deleteDBRPsResponse{
+		Mappings: make([]*dbrpMappingResponse, 0, len(ms)),
+	}
+	for _, m := range ms {
+		if err := h.DBRPMappingService.Delete(ctx, m.Cluster, m.Database, m.RetentionPolicy); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		res.Mappings = append(res.Mappings, newDBRPMappingResponse(m))
+	}
+	h.log.Debug("DBRP mappings deleted", zap.Int("count", len(res.Mappings)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// encodeDBRPMapping writes the mapping with its revision, which is also
+// its ETag.
+func (h *DBRPHandler) encodeDBRPMapping(w http.ResponseWriter, r *http.Request, m *influxdb.DBRPMapping) {
+	res := newDBRPMappingResponse(m)
+	w.Header().Set("ETag", strconv.Quote(res.Revision))
+	if err := encodeResponse(r.Context(), w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// postDBRPDefaultRequest identifies the mapping made the default by a
+// POST /api/v2/dbrps/default request, as mappings have no ID.
+type postDBRPDefaultRequest struct {
+	Cluster         string `json:"cluster"`
+	Database        string `json:"database"`
+	RetentionPolicy string `json:"retention_policy"`
+}
+
+// handlePostDBRPDefault is the HTTP handler for the POST /api/v2/dbrps/default
+// route. It makes the mapping of the cluster, database and retention policy
+// of the body the default for its cluster and database. Mappings have no ID,
+// and are instead identified by their cluster, database and retention
+// policy, so there is no /api/v2/dbrps/{id}/default route.
+func (h *DBRPHandler) handlePostDBRPDefault(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req postDBRPDefaultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode dbrp mapping",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	m, err := h.DBRPMappingService.SetDefault(ctx, req.Cluster, req.Database, req.RetentionPolicy)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("DBRP mapping made default", zap.String("database", m.Database), zap.String("rp", m.RetentionPolicy))
+
+	h.encodeDBRPMapping(w, r, m)
+}
+
+// The encodings of DBRP packages.
+const (
+	dbrpPackageJSON = "application/json"
+	dbrpPackageYAML = "application/x-yaml"
+)
+
+// dbrpPackageEncoding returns the encoding of DBRP packages of the media
+// type mt, or "" if it is not one of them.
+func dbrpPackageEncoding(mt string) string {
+	switch mt {
+	case "application/json":
+		return dbrpPackageJSON
+	case "application/x-yaml", "application/yaml", "text/yaml", "text/yml":
+		return dbrpPackageYAML
+	default:
+		return ""
+	}
+}
+
+func decodeDBRPOrgID(r *http.Request) (influxdb.ID, error) {
+	orgID, err := influxdb.IDFromString(r.URL.Query().Get("orgID"))
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	return *orgID, nil
+}
+
+func decodeDBRPImportRequest(r *http.Request) (influxdb.ID, influxdb.DBRPImportOptions, error) {
+	qp := r.URL.Query()
+	var opts influxdb.DBRPImportOptions
+	orgID, err := decodeDBRPOrgID(r)
+	if err != nil {
+		return 0, opts, err
+	}
+	opts.Cluster = qp.Get("cluster")
+	if v := qp.Get("createBuckets"); v != "" {
+		if opts.CreateBuckets, err = strconv.ParseBool(v); err != nil {
+			return 0, opts, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid createBuckets",
+				Err:  err,
+			}
+		}
+	}
+	return orgID, opts, nil
+}
+
+// handlePostDBRPImport is the HTTP handler for the POST /api/v2/dbrps/import route.
+// JSON and YAML bodies are packages exported by GET /api/v2/dbrps/export, and
+// other bodies the meta.db of a 1.x server.
+func (h *DBRPHandler) handlePostDBRPImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, opts, err := decodeDBRPImportRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxDBRPImportSize)
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var res *influxdb.DBRPImportResult
+	if enc := dbrpPackageEncoding(mt); enc != "" {
+		// Packages exported by an instance are imported as they are.
+		var pkg influxdb.DBRPPackage
+		if enc == dbrpPackageYAML {
+			err = yaml.NewDecoder(body).Decode(&pkg)
+		} else {
+			err = json.NewDecoder(body).Decode(&pkg)
+		}
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "unable to decode dbrp package",
+				Err:  err,
+			}, w)
+			return
+		}
+		res, err = h.DBRPImportService.ImportDBRPPackage(ctx, orgID, &pkg, opts)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	} else {
+		res, err = h.DBRPImportService.ImportDBRPMappings(ctx, orgID, body, opts)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+	h.log.Debug("DBRP mappings imported",
+		zap.Int("mappings", len(res.Mappings)),
+		zap.Int("buckets", len(res.Buckets)),
+		zap.Int("skipped", len(res.Skipped)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetDBRPExport is the HTTP handler for the GET /api/v2/dbrps/export route.
+// The package is encoded as YAML if it is the media type accepted, and as
+// JSON otherwise.
+func (h *DBRPHandler) handleGetDBRPExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, err := decodeDBRPOrgID(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	pkg, err := h.DBRPImportService.ExportDBRPMappings(ctx, orgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("DBRP mappings exported", zap.Int("mappings", len(pkg.Mappings)))
+
+	enc := dbrpPackageJSON
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, _ := mime.ParseMediaType(strings.TrimSpace(accept))
+		if e := dbrpPackageEncoding(mt); e != "" {
+			enc = e
+			break
+		}
+	}
+	if enc != dbrpPackageYAML {
+		if err := encodeResponse(ctx, w, http.StatusOK, pkg); err != nil {
+			logEncodingError(h.log, r, err)
+		}
+		return
+	}
+
+	b, err := yaml.Marshal(pkg)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}, w)
+		return
+	}
+	w.Header().Set("Content-Type", dbrpPackageYAML)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// DBRPImportService connects to Influx via HTTP using tokens to import and
+// export DBRP mappings.
+type DBRPImportService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.DBRPImportService = (*DBRPImportService)(nil)
+
+// ImportDBRPMappings uploads meta to import the mappings of its databases
+// and retention policies to the buckets of the organization.
+func (s *DBRPImportService) ImportDBRPMappings(ctx context.Context, orgID influxdb.ID, meta io.Reader, opts influxdb.DBRPImportOptions) (*influxdb.DBRPImportResult, error) {
+	params := [][2]string{
+		{"orgID", orgID.String()},
+		{"createBuckets", fmt.Sprint(opts.CreateBuckets)},
+	}
+	if opts.Cluster != "" {
+		params = append(params, [2]string{"cluster", opts.Cluster})
+	}
+
+	body := func(w io.Writer) (string, string, error) {
+		_, err := io.Copy(w, meta)
+		return "Content-Type", "application/octet-stream", err
+	}
+	var res influxdb.DBRPImportResult
+	err := s.Client.
+		Post(body, dbrpsImportPath).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ImportDBRPPackage uploads pkg to import its mappings to the buckets of
+// the organization.
+func (s *DBRPImportService) ImportDBRPPackage(ctx context.Context, orgID influxdb.ID, pkg *influxdb.DBRPPackage, opts influxdb.DBRPImportOptions) (*influxdb.DBRPImportResult, error) {
+	params := [][2]string{
+		{"orgID", orgID.String()},
+		{"createBuckets", fmt.Sprint(opts.CreateBuckets)},
+	}
+	if opts.Cluster != "" {
+		params = append(params, [2]string{"cluster", opts.Cluster})
+	}
+
+	var res influxdb.DBRPImportResult
+	err := s.Client.
+		PostJSON(pkg, dbrpsImportPath).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ExportDBRPMappings returns the mappings to the buckets of the
+// organization as a package.
+func (s *DBRPImportService) ExportDBRPMappings(ctx context.Context, orgID influxdb.ID) (*influxdb.DBRPPackage, error) {
+	var pkg influxdb.DBRPPackage
+	err := s.Client.
+		Get(dbrpsExportPath).
+		QueryParams([2]string{"orgID", orgID.String()}).
+		DecodeJSON(&pkg).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// authorizeDBRP ensures that auth, if it is an authorization scoped to a
+// dbrp, may perform action on the bucket through the mapping of its database
+// and retention policy. Other authorizers are left to the permission checks
+// of the bucket.
+func authorizeDBRP(ctx context.Context, svc influxdb.DBRPMappingService, auth influxdb.Authorizer, action influxdb.Action, bucketID influxdb.ID) error {
+	a, ok := auth.(*influxdb.Authorization)
+	if !ok || a.DBRP == nil {
+		return nil
+	}
+
+	forbidden := &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  fmt.Sprintf("authorization is scoped to database %q and retention policy %q, which are not mapped to the bucket", a.DBRP.Database, a.DBRP.RetentionPolicy),
+	}
+	if svc == nil {
+		return forbidden
+	}
+	m, err := svc.Find(ctx, influxdb.DBRPMappingFilter{
+		OrganizationID:  &a.OrgID,
+		Database:        &a.DBRP.Database,
+		RetentionPolicy: &a.DBRP.RetentionPolicy,
+	})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return forbidden
+		}
+		return err
+	}
+	if m.BucketID != bucketID || !a.AllowedDBRP(action, m) {
+		return forbidden
+	}
+	return nil
+}
+
+// DBRPMappingService connects to Influx via HTTP using tokens to manage
+// DBRP mappings.
+type DBRPMappingService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.DBRPMappingService = (*DBRPMappingService)(nil)
+
+// FindBy is not implemented for http, as mappings are found by
+// organization.
+func (s *DBRPMappingService) FindBy(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	return nil, &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "find dbrp mapping by key is not implemented for http",
+	}
+}
+
+// Find returns the first mapping matching filter.
+func (s *DBRPMappingService) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+	ms, _, err := s.FindMany(ctx, filter, influxdb.FindOptions{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "dbrp mapping not found",
+		}
+	}
+	return ms[0], nil
+}
+
+// FindMany returns the mappings matching filter, which must have an
+// organization, and their number.
+func (s *DBRPMappingService) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+	if filter.OrganizationID == nil {
+		return nil, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "organization is required to find dbrp mappings",
+		}
+	}
+
+	params := append([][2]string{{"orgID", filter.OrganizationID.String()}}, influxdb.FindOptionParams(opt...)...)
+	for _, p := range []struct {
+		k string
+		v *string
+	}{
+		{"cluster", filter.Cluster},
+		{"db", filter.Database},
+		{"rp", filter.RetentionPolicy},
+	} {
+		if p.v != nil {
+			params = append(params, [2]string{p.k, *p.v})
+		}
+	}
+	if filter.Default != nil {
+		params = append(params, [2]string{"default", strconv.FormatBool(*filter.Default)})
+	}
+	for _, id := range filter.BucketIDs {
+		params = append(params, [2]string{"bucketID", id.String()})
+	}
+	if filter.DatabasePrefix != nil {
+		params = append(params, [2]string{"dbPrefix", *filter.DatabasePrefix})
+	}
+	if filter.DatabasePattern != nil {
+		params = append(params, [2]string{"dbPattern", *filter.DatabasePattern})
+	}
+
+	var res dbrpMappingsResponse
+	err := s.Client.
+		Get(prefixDBRPs).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ms := make([]*influxdb.DBRPMapping, 0, len(res.Mappings))
+	for _, m := range res.Mappings {
+		ms = append(ms, m.DBRPMapping)
+	}
+	return ms, res.TotalCount, nil
+}
+
+// Create creates m, and fails if another mapping of its cluster, database
+// and retention policy exists.
+func (s *DBRPMappingService) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	return s.put(ctx, m, "If-None-Match", "*")
+}
+
+// Upsert creates m, or replaces the mapping of its cluster, database and
+// retention policy.
+func (s *DBRPMappingService) Upsert(ctx context.Context, m *influxdb.DBRPMapping) error {
+	return s.put(ctx, m, "", "")
+}
+
+// Replace replaces the mapping of the cluster, database and retention policy
+// of m only if it is at revision, or exists if revision is "*".
+func (s *DBRPMappingService) Replace(ctx context.Context, m *influxdb.DBRPMapping, revision string) error {
+	if revision != "*" {
+		revision = strconv.Quote(revision)
+	}
+	return s.put(ctx, m, "If-Match", revision)
+}
+
+// put writes m with the precondition header k, if any, and updates m with
+// the mapping written.
+func (s *DBRPMappingService) put(ctx context.Context, m *influxdb.DBRPMapping, k, v string) error {
+	req := s.Client.PutJSON(m, prefixDBRPs)
+	if k != "" {
+		req = req.Header(k, v)
+	}
+	return req.
+		DecodeJSON(&dbrpMappingResponse{DBRPMapping: m}).
+		Do(ctx)
+}
+
+// SetDefault makes the mapping of the cluster, database and retention policy
+// the default of its cluster and database.
+func (s *DBRPMappingService) SetDefault(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	res := &dbrpMappingResponse{DBRPMapping: &influxdb.DBRPMapping{}}
+	err := s.Client.
+		PostJSON(postDBRPDefaultRequest{
+			Cluster:         cluster,
+			Database:        db,
+			RetentionPolicy: rp,
+		}, dbrpsDefaultPath).
+		DecodeJSON(res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.DBRPMapping, nil
+}
+
+// Delete removes the mapping of the cluster, database and retention policy.
+func (s *DBRPMappingService) Delete(ctx context.Context, cluster, db, rp string) error {
+	return s.Client.
+		Delete(prefixDBRPs).
+		QueryParams(
+			[2]string{"cluster", cluster},
+			[2]string{"db", db},
+			[2]string{"rp", rp},
+		).
+		Do(ctx)
+}
@@ -0,0 +1,297 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/inmem"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDBRPHandler_handlePutDBRP_IfMatch(t *testing.T) {
+	ctx := context.Background()
+	svc := inmem.NewService()
+	m := &influxdb.DBRPMapping{
+		Cluster:         "cluster",
+		Database:        "db",
+		RetentionPolicy: "autogen",
+		OrganizationID:  1,
+		BucketID:        2,
+	}
+	if err := svc.Create(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	read := m.Revision()
+
+	h := NewDBRPHandler(zaptest.NewLogger(t), &DBRPBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		DBRPMappingService: svc,
+	})
+	put := func(bucketID influxdb.ID, ifMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		update := *m
+		update.BucketID = bucketID
+		b, err := json.Marshal(update)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest("PUT", "/api/v2/dbrps", bytes.NewReader(b))
+		r.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// The first operator replaces the mapping they read.
+	w := put(3, strconv.Quote(read))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var res dbrpMappingResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.BucketID != 3 || res.Revision == read || w.Header().Get("ETag") != strconv.Quote(res.Revision) {
+		t.Fatalf("unexpected mapping %+v with ETag %q", res, w.Header().Get("ETag"))
+	}
+
+	// The second operator read the mapping before it was replaced.
+	if w := put(4, strconv.Quote(read)); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("got status %d, want 412: %s", w.Code, w.Body.String())
+	}
+	got, err := svc.FindBy(ctx, m.Cluster, m.Database, m.RetentionPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BucketID != 3 {
+		t.Errorf("expected the first update to be kept, got bucket %s", got.BucketID)
+	}
+
+	if w := put(4, "*"); w.Code != http.StatusOK {
+		t.Errorf("got status %d, want 200: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDBRPHandler_handleGetDBRPs_TotalCount(t *testing.T) {
+	ctx := context.Background()
+	svc := inmem.NewService()
+	for _, db := range []string{"a", "b", "c"} {
+		m := &influxdb.DBRPMapping{
+			Cluster:         "cluster",
+			Database:        db,
+			RetentionPolicy: "autogen",
+			OrganizationID:  1,
+			BucketID:        2,
+		}
+		if err := svc.Create(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewDBRPHandler(zaptest.NewLogger(t), &DBRPBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		DBRPMappingService: svc,
+	})
+
+	for _, tt := range []struct {
+		query    string
+		mappings int
+		next     bool
+	}{
+		{query: "&limit=2", mappings: 2, next: true},
+		{query: "&limit=2&offset=2", mappings: 1},
+		{query: "&offset=3", mappings: 0},
+	} {
+		r := httptest.NewRequest(http.MethodGet, prefixDBRPs+"?orgID="+influxdb.ID(1).String()+tt.query, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d, want 200: %s", tt.query, w.Code, w.Body.String())
+		}
+
+		var res dbrpMappingsResponse
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if res.TotalCount != 3 || len(res.Mappings) != tt.mappings || (res.Links.Next != "") != tt.next {
+			t.Errorf("%s: got %d of a total of %d mappings with next link %q", tt.query, len(res.Mappings), res.TotalCount, res.Links.Next)
+		}
+	}
+}
+
+func TestDBRPHandler_handleDeleteDBRPs(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+	svc := inmem.NewService()
+	for _, m := range []struct {
+		db       string
+		bucketID influxdb.ID
+	}{
+		{"telegraf_a", 2},
+		{"telegraf_b", 3},
+		{"metrics", 2},
+	} {
+		if err := svc.Create(ctx, &influxdb.DBRPMapping{
+			Cluster:         "cluster",
+			Database:        m.db,
+			RetentionPolicy: "autogen",
+			OrganizationID:  orgID,
+			BucketID:        m.bucketID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewDBRPHandler(zaptest.NewLogger(t), &DBRPBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		DBRPMappingService: authorizer.NewDBRPMappingService(svc),
+	})
+	del := func(a influxdb.Authorizer, query string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodDelete, prefixDBRPs+"?orgID="+orgID.String()+query, nil)
+		r = r.WithContext(icontext.SetAuthorizer(r.Context(), a))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	exists := func(db string) bool {
+		t.Helper()
+		_, err := svc.FindBy(ctx, "cluster", db, "autogen")
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	// Each mapping is authorized on its own bucket, so a token which can
+	// only write to bucket 2 cannot remove the mapping to bucket 3.
+	bucketID := influxdb.ID(2)
+	restricted := mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID}},
+		{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: &bucketID, OrgID: &orgID}},
+	})
+	if w := del(restricted, "&dbPrefix=telegraf_"); w.Code == http.StatusOK {
+		t.Fatalf("expected the mapping to bucket 3 not to be deleted: %s", w.Body.String())
+	}
+	if !exists("telegraf_b") || !exists("metrics") {
+		t.Fatal("expected the mapping to bucket 3 and the mapping not matching the prefix to be kept")
+	}
+
+	w := del(mock.NewMockAuthorizer(true, nil), "&dbPrefix=telegraf_")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var res deleteDBRPsResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range res.Mappings {
+		if !strings.HasPrefix(m.Database, "telegraf_") {
+			t.Errorf("unexpected deleted mapping of %s", m.Database)
+		}
+	}
+	if exists("telegraf_a") || exists("telegraf_b") || !exists("metrics") {
+		t.Error("expected only the mappings matching the prefix to be deleted")
+	}
+}
+
+func TestDBRPMappingService_Client(t *testing.T) {
+	ctx := context.Background()
+	h := NewDBRPHandler(zaptest.NewLogger(t), &DBRPBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		DBRPMappingService: inmem.NewService(),
+	})
+	server := httptest.NewServer(h)
+	defer server.Close()
+	client := &DBRPMappingService{Client: mustNewHTTPClient(t, server.URL, "")}
+
+	m := &influxdb.DBRPMapping{
+		Database:        "telegraf",
+		RetentionPolicy: "autogen",
+		OrganizationID:  1,
+		BucketID:        2,
+	}
+	if err := client.Create(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	conflict := *m
+	conflict.BucketID = 3
+	if err := client.Create(ctx, &conflict); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected creating a different mapping of the key to conflict, got %v", err)
+	}
+
+	orgID := influxdb.ID(1)
+	got, err := client.Find(ctx, influxdb.DBRPMappingFilter{OrganizationID: &orgID, Database: &m.Database})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *m {
+		t.Fatalf("found %+v, want %+v", got, m)
+	}
+
+	if err := client.Replace(ctx, &conflict, "stale"); influxdb.ErrorCode(err) != influxdb.EPreconditionFailed {
+		t.Fatalf("expected replacing a stale revision to fail, got %v", err)
+	}
+	if err := client.Replace(ctx, &conflict, m.Revision()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		bucketIDs []influxdb.ID
+		n         int
+	}{
+		{[]influxdb.ID{3, 9}, 1},
+		{[]influxdb.ID{2}, 0},
+	} {
+		if _, n, err := client.FindMany(ctx, influxdb.DBRPMappingFilter{OrganizationID: &orgID, BucketIDs: c.bucketIDs}); err != nil || n != c.n {
+			t.Errorf("expected %d mappings to buckets %v, got %d and %v", c.n, c.bucketIDs, n, err)
+		}
+	}
+
+	prefix, pattern, bad := m.Database[:2], "*"+m.Database[2:], "["
+	if _, n, err := client.FindMany(ctx, influxdb.DBRPMappingFilter{OrganizationID: &orgID, DatabasePrefix: &prefix, DatabasePattern: &pattern}); err != nil || n != 1 {
+		t.Errorf("expected the mapping of %s to match prefix %q and pattern %q, got %d and %v", m.Database, prefix, pattern, n, err)
+	}
+	if _, _, err := client.FindMany(ctx, influxdb.DBRPMappingFilter{OrganizationID: &orgID, DatabasePattern: &bad}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a malformed pattern to be invalid, got %v", err)
+	}
+
+	other := &influxdb.DBRPMapping{
+		Database:        "metrics",
+		RetentionPolicy: "autogen",
+		OrganizationID:  1,
+		BucketID:        1,
+	}
+	if err := client.Create(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	ms, _, err := client.FindMany(ctx, influxdb.DBRPMappingFilter{OrganizationID: &orgID}, influxdb.FindOptions{SortBy: influxdb.DBRPSortByBucketID, Descending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 || ms[0].Database != m.Database || ms[1].Database != other.Database {
+		t.Errorf("expected the mappings sorted by descending bucket ID, got %+v", ms)
+	}
+	if _, _, err := client.FindMany(ctx, influxdb.DBRPMappingFilter{OrganizationID: &orgID}, influxdb.FindOptions{SortBy: "org"}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an unknown sort field to be invalid, got %v", err)
+	}
+	if err := client.Delete(ctx, "", other.Database, other.RetentionPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Delete(ctx, "", m.Database, m.RetentionPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if _, n, err := client.FindMany(ctx, influxdb.DBRPMappingFilter{OrganizationID: &orgID}); err != nil || n != 0 {
+		t.Fatalf("expected the mapping to be deleted, got %d mappings and %v", n, err)
+	}
+}
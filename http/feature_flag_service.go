@@ -0,0 +1,427 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// FeatureFlagBackend is all services and associated parameters required to construct
+// the FeatureFlagHandler.
+type FeatureFlagBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	FeatureFlagService influxdb.FeatureFlagService
+}
+
+// NewFeatureFlagBackend returns a new instance of FeatureFlagBackend.
+func NewFeatureFlagBackend(log *zap.Logger, b *APIBackend) *FeatureFlagBackend {
+	return &FeatureFlagBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		log:                log,
+		FeatureFlagService: b.FeatureFlagService,
+	}
+}
+
+// FeatureFlagHandler represents an HTTP API handler for the values of feature
+// flags set for the instance and for organizations.
+type FeatureFlagHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	FeatureFlagService influxdb.FeatureFlagService
+}
+
+const (
+	prefixFeatureFlagSettings  = "/api/v2/flags/settings"
+	featureFlagSettingsKeyPath = "/api/v2/flags/settings/:key"
+	prefixFeatureFlagChanges   = "/api/v2/flags/changes"
+)
+
+// NewFeatureFlagHandler returns a new instance of FeatureFlagHandler.
+func NewFeatureFlagHandler(log *zap.Logger, b *FeatureFlagBackend) *FeatureFlagHandler {
+	h := &FeatureFlagHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		FeatureFlagService: b.FeatureFlagService,
+	}
+
+	h.HandlerFunc("GET", prefixFeatureFlagSettings, h.handleGetFeatureFlagSettings)
+	h.HandlerFunc("PUT", featureFlagSettingsKeyPath, h.handlePutFeatureFlagSetting)
+	h.HandlerFunc("DELETE", featureFlagSettingsKeyPath, h.handleDeleteFeatureFlagSetting)
+	h.HandlerFunc("GET", prefixFeatureFlagChanges, h.handleGetFeatureFlagChanges)
+
+	return h
+}
+
+// FeatureFlag is a feature flag with the values set for it. The default of
+// a flag which has been removed, but which still has values set, is nil.
+type FeatureFlag struct {
+	Key      string                         `json:"key"`
+	Default  interface{}                    `json:"default"`
+	Expose   bool                           `json:"expose"`
+	Settings []*influxdb.FeatureFlagSetting `json:"settings"`
+}
+
+type featureFlagsResponse struct {
+	Links map[string]string `json:"links"`
+	Flags []*FeatureFlag    `json:"flags"`
+}
+
+// newFeatureFlagsResponse returns the flags selected by filter, in the order
+// they are defined, with the settings ss, followed by the flags which are
+// not defined but have settings.
+func newFeatureFlagsResponse(filter influxdb.FeatureFlagSettingFilter, ss []*influxdb.FeatureFlagSetting) *featureFlagsResponse {
+	res := &featureFlagsResponse{
+		Links: map[string]string{
+			"self":    prefixFeatureFlagSettings,
+			"changes": prefixFeatureFlagChanges,
+		},
+		Flags: []*FeatureFlag{},
+	}
+
+	byKey := make(map[string]*FeatureFlag)
+	for _, flag := range feature.Flags() {
+		if filter.Key != nil && *filter.Key != flag.Key() {
+			continue
+		}
+		f := &FeatureFlag{
+			Key:      flag.Key(),
+			Default:  flag.Default(),
+			Expose:   flag.Expose(),
+			Settings: []*influxdb.FeatureFlagSetting{},
+		}
+		byKey[f.Key] = f
+		res.Flags = append(res.Flags, f)
+	}
+
+	var removed []*FeatureFlag
+	for _, fs := range ss {
+		f, ok := byKey[fs.Key]
+		if !ok {
+			f = &FeatureFlag{Key: fs.Key}
+			byKey[f.Key] = f
+			removed = append(removed, f)
+		}
+		f.Settings = append(f.Settings, fs)
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Key < removed[j].Key
+	})
+	res.Flags = append(res.Flags, removed...)
+	return res
+}
+
+func decodeFeatureFlagSettingFilter(r *http.Request) (*influxdb.FeatureFlagSettingFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.FeatureFlagSettingFilter{}
+	if v := qp.Get("key"); v != "" {
+		filter.Key = &v
+	}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("instance"); v != "" {
+		instance, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "instance is invalid",
+				Err:  err,
+			}
+		}
+		filter.Instance = instance
+	}
+	return filter, nil
+}
+
+// handleGetFeatureFlagSettings is the HTTP handler for the GET /api/v2/flags/settings route.
+func (h *FeatureFlagHandler) handleGetFeatureFlagSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeFeatureFlagSettingFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ss, err := h.FeatureFlagService.FindFeatureFlagSettings(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Feature flag settings retrieved", zap.String("settings", fmt.Sprint(ss)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newFeatureFlagsResponse(*filter, ss)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// putFeatureFlagSettingRequest is the value of a flag, which may be given
+// either as a JSON value of the type of the flag or as its text representation.
+type putFeatureFlagSettingRequest struct {
+	Value json.RawMessage `json:"value"`
+	OrgID *influxdb.ID    `json:"orgID,omitempty"`
+}
+
+// value returns the text representation of the value of the request.
+func (req *putFeatureFlagSettingRequest) value() (string, error) {
+	raw := bytes.TrimSpace(req.Value)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "feature flag value is required",
+		}
+	}
+	if raw[0] != '"' {
+		return string(raw), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid feature flag value",
+			Err:  err,
+		}
+	}
+	return s, nil
+}
+
+// handlePutFeatureFlagSetting is the HTTP handler for the PUT /api/v2/flags/settings/:key route.
+func (h *FeatureFlagHandler) handlePutFeatureFlagSetting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := httprouter.ParamsFromContext(ctx).ByName("key")
+
+	var req putFeatureFlagSettingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode feature flag setting request",
+			Err:  err,
+		}, w)
+		return
+	}
+	value, err := req.value()
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	fs := &influxdb.FeatureFlagSetting{
+		Key:   key,
+		OrgID: req.OrgID,
+		Value: value,
+	}
+	if err := fs.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.FeatureFlagService.SetFeatureFlagSetting(ctx, fs); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Feature flag setting updated", zap.String("setting", fmt.Sprint(fs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, fs); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteFeatureFlagSetting is the HTTP handler for the DELETE /api/v2/flags/settings/:key route.
+func (h *FeatureFlagHandler) handleDeleteFeatureFlagSetting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := httprouter.ParamsFromContext(ctx).ByName("key")
+
+	var orgID *influxdb.ID
+	if v := r.URL.Query().Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}, w)
+			return
+		}
+		orgID = id
+	}
+
+	if err := h.FeatureFlagService.DeleteFeatureFlagSetting(ctx, key, orgID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Feature flag setting deleted", zap.String("key", key))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type featureFlagChangesResponse struct {
+	Links   map[string]string             `json:"links"`
+	Changes []*influxdb.FeatureFlagChange `json:"changes"`
+}
+
+// handleGetFeatureFlagChanges is the HTTP handler for the GET /api/v2/flags/changes route.
+func (h *FeatureFlagHandler) handleGetFeatureFlagChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeFeatureFlagSettingFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	cs, err := h.FeatureFlagService.FindFeatureFlagChanges(ctx, influxdb.FeatureFlagChangeFilter(*filter), *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Feature flag changes retrieved", zap.String("changes", fmt.Sprint(cs)))
+
+	res := &featureFlagChangesResponse{
+		Links: map[string]string{
+			"self":     prefixFeatureFlagChanges,
+			"settings": prefixFeatureFlagSettings,
+		},
+		Changes: cs,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// FeatureFlagService connects to Influx via HTTP using tokens to manage the
+// values of feature flags.
+type FeatureFlagService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.FeatureFlagService = (*FeatureFlagService)(nil)
+
+func featureFlagSettingFilterParams(filter influxdb.FeatureFlagSettingFilter) [][2]string {
+	var params [][2]string
+	if filter.Key != nil {
+		params = append(params, [2]string{"key", *filter.Key})
+	}
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Instance {
+		params = append(params, [2]string{"instance", "true"})
+	}
+	return params
+}
+
+// FindFeatureFlags returns the feature flags known to the server with the
+// settings that match filter.
+func (s *FeatureFlagService) FindFeatureFlags(ctx context.Context, filter influxdb.FeatureFlagSettingFilter) ([]*FeatureFlag, error) {
+	var res featureFlagsResponse
+	err := s.Client.
+		Get(prefixFeatureFlagSettings).
+		QueryParams(featureFlagSettingFilterParams(filter)...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Flags, nil
+}
+
+// FindFeatureFlagSettings returns the settings that match filter.
+func (s *FeatureFlagService) FindFeatureFlagSettings(ctx context.Context, filter influxdb.FeatureFlagSettingFilter) ([]*influxdb.FeatureFlagSetting, error) {
+	flags, err := s.FindFeatureFlags(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []*influxdb.FeatureFlagSetting{}
+	for _, f := range flags {
+		ss = append(ss, f.Settings...)
+	}
+	return ss, nil
+}
+
+// SetFeatureFlagSetting sets the value of a flag for the instance, or for an
+// organization if fs.OrgID is set.
+func (s *FeatureFlagService) SetFeatureFlagSetting(ctx context.Context, fs *influxdb.FeatureFlagSetting) error {
+	value, err := json.Marshal(fs.Value)
+	if err != nil {
+		return err
+	}
+
+	var res influxdb.FeatureFlagSetting
+	err = s.Client.
+		PutJSON(putFeatureFlagSettingRequest{
+			Value: value,
+			OrgID: fs.OrgID,
+		}, prefixFeatureFlagSettings, fs.Key).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*fs = res
+	return nil
+}
+
+// DeleteFeatureFlagSetting removes the value of a flag set for the instance,
+// or for the organization orgID if it is not nil.
+func (s *FeatureFlagService) DeleteFeatureFlagSetting(ctx context.Context, key string, orgID *influxdb.ID) error {
+	var params [][2]string
+	if orgID != nil {
+		params = append(params, [2]string{"orgID", orgID.String()})
+	}
+	return s.Client.
+		Delete(path.Join(prefixFeatureFlagSettings, key)).
+		QueryParams(params...).
+		StatusFn(func(resp *http.Response) error {
+			return CheckErrorStatus(http.StatusNoContent, resp)
+		}).
+		Do(ctx)
+}
+
+// FindFeatureFlagChanges returns the changes made to the settings that match filter.
+func (s *FeatureFlagService) FindFeatureFlagChanges(ctx context.Context, filter influxdb.FeatureFlagChangeFilter, opts ...influxdb.FindOptions) ([]*influxdb.FeatureFlagChange, error) {
+	params := featureFlagSettingFilterParams(influxdb.FeatureFlagSettingFilter(filter))
+	params = append(params, influxdb.FindOptionParams(opts...)...)
+
+	var res featureFlagChangesResponse
+	err := s.Client.
+		Get(prefixFeatureFlagChanges).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Changes, nil
+}
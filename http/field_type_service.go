@@ -0,0 +1,215 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// FieldTypeConflictBackend is all services and associated parameters required
+// to construct the FieldTypeConflictHandler.
+type FieldTypeConflictBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	FieldTypeConflictService influxdb.FieldTypeConflictService
+}
+
+// NewFieldTypeConflictBackend returns a new instance of FieldTypeConflictBackend.
+func NewFieldTypeConflictBackend(b *APIBackend) *FieldTypeConflictBackend {
+	return &FieldTypeConflictBackend{
+		Logger: b.Logger.With(zap.String("handler", "field_type_conflict")),
+
+		HTTPErrorHandler:         b.HTTPErrorHandler,
+		FieldTypeConflictService: b.FieldTypeConflictService,
+	}
+}
+
+// FieldTypeConflictHandler is the http handler finding the fields of buckets
+// with values of more than one type, and converting them to a single type.
+type FieldTypeConflictHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	FieldTypeConflictService influxdb.FieldTypeConflictService
+}
+
+const (
+	prefixFieldTypeConflicts  = "/api/v2/field-type-conflicts"
+	fieldTypeConflictsConvert = "/api/v2/field-type-conflicts/convert"
+)
+
+// NewFieldTypeConflictHandler creates a new handler at /api/v2/field-type-conflicts.
+func NewFieldTypeConflictHandler(b *FieldTypeConflictBackend) *FieldTypeConflictHandler {
+	h := &FieldTypeConflictHandler{
+		HTTPErrorHandler:         b.HTTPErrorHandler,
+		Router:                   NewRouter(b.HTTPErrorHandler),
+		Logger:                   b.Logger,
+		FieldTypeConflictService: b.FieldTypeConflictService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixFieldTypeConflicts, h.handleGetFieldTypeConflicts)
+	h.HandlerFunc(http.MethodPost, fieldTypeConflictsConvert, h.handlePostFieldTypeConversion)
+
+	return h
+}
+
+type fieldTypeConflictsResponse struct {
+	Links     map[string]string             `json:"links"`
+	Conflicts []*influxdb.FieldTypeConflict `json:"conflicts"`
+}
+
+func decodeGetFieldTypeConflictsRequest(r *http.Request) (orgID, bucketID influxdb.ID, err error) {
+	qp := r.URL.Query()
+	if err := orgID.DecodeFromString(qp.Get("orgID")); err != nil {
+		return 0, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	if err := bucketID.DecodeFromString(qp.Get("bucketID")); err != nil {
+		return 0, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		}
+	}
+	return orgID, bucketID, nil
+}
+
+// handleGetFieldTypeConflicts is the HTTP handler for the GET /api/v2/field-type-conflicts route.
+func (h *FieldTypeConflictHandler) handleGetFieldTypeConflicts(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FieldTypeConflictHandler.handleGetFieldTypeConflicts")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	orgID, bucketID, err := decodeGetFieldTypeConflictsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	cs, err := h.FieldTypeConflictService.FindFieldTypeConflicts(ctx, orgID, bucketID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("Field type conflicts found", zap.Stringer("bucketID", bucketID), zap.Int("conflicts", len(cs)))
+
+	res := &fieldTypeConflictsResponse{
+		Links: map[string]string{
+			"self":    prefixFieldTypeConflicts + "?" + r.URL.RawQuery,
+			"convert": fieldTypeConflictsConvert,
+		},
+		Conflicts: cs,
+	}
+	if res.Conflicts == nil {
+		res.Conflicts = []*influxdb.FieldTypeConflict{}
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePostFieldTypeConversion is the HTTP handler for the POST /api/v2/field-type-conflicts/convert route.
+func (h *FieldTypeConflictHandler) handlePostFieldTypeConversion(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FieldTypeConflictHandler.handlePostFieldTypeConversion")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var c influxdb.FieldTypeConversion
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode field type conversion request",
+			Err:  err,
+		}, w)
+		return
+	}
+	if err := c.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	j, err := h.FieldTypeConflictService.ConvertFieldType(ctx, c)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("Field type conversion started", zap.Stringer("bucketID", c.BucketID), zap.String("measurement", c.Measurement), zap.String("field", c.Field))
+
+	// Without jobs, the field is converted before the service returns.
+	if j == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusAccepted, newJobResponse(j)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// FieldTypeConflictService is the client implementation of influxdb.FieldTypeConflictService.
+type FieldTypeConflictService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.FieldTypeConflictService = (*FieldTypeConflictService)(nil)
+
+// FindFieldTypeConflicts returns the fields of the bucket with values of more than one type.
+func (s *FieldTypeConflictService) FindFieldTypeConflicts(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.FieldTypeConflict, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res fieldTypeConflictsResponse
+	err := s.Client.
+		Get(prefixFieldTypeConflicts).
+		QueryParams(
+			[2]string{"orgID", orgID.String()},
+			[2]string{"bucketID", bucketID.String()},
+		).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Conflicts, nil
+}
+
+// ConvertFieldType starts casting the values of the field of c to the type of c.
+func (s *FieldTypeConflictService) ConvertFieldType(ctx context.Context, c influxdb.FieldTypeConversion) (*influxdb.Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var j *influxdb.Job
+	err := s.Client.
+		PostJSON(c, fieldTypeConflictsConvert).
+		Decode(func(resp *http.Response) error {
+			// Without jobs, the field is converted before the server responds.
+			if resp.StatusCode == http.StatusNoContent {
+				return nil
+			}
+			var jr jobResponse
+			if err := json.NewDecoder(resp.Body).Decode(&jr); err != nil {
+				return err
+			}
+			j = &jr.Job
+			return nil
+		}).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"go.uber.org/zap"
+)
+
+// FlushBackend is all services and associated parameters required to construct the FlushHandler.
+type FlushBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	CacheFlushService influxdb.CacheFlushService
+}
+
+// NewFlushBackend returns a new instance of FlushBackend.
+func NewFlushBackend(b *APIBackend) *FlushBackend {
+	return &FlushBackend{
+		Logger: b.Logger.With(zap.String("handler", "flush")),
+
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		CacheFlushService: b.CacheFlushService,
+	}
+}
+
+// FlushHandler is http handler for the cache flush service.
+type FlushHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	CacheFlushService influxdb.CacheFlushService
+}
+
+const prefixFlush = "/api/v2/flush"
+
+// NewFlushHandler creates a new handler at /api/v2/flush to receive cache flush requests.
+func NewFlushHandler(b *FlushBackend) *FlushHandler {
+	h := &FlushHandler{
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		Router:            NewRouter(b.HTTPErrorHandler),
+		Logger:            b.Logger,
+		CacheFlushService: b.CacheFlushService,
+	}
+
+	h.HandlerFunc(http.MethodPost, prefixFlush, h.handleFlush)
+
+	return h
+}
+
+type flushResult struct {
+	FlushedBytes uint64 `json:"flushedBytes"`
+	Duration     string `json:"duration"`
+}
+
+func (h *FlushHandler) handleFlush(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FlushHandler.handleFlush")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	orgID, bucketID, err := decodeFlushRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	res, err := h.CacheFlushService.FlushBucket(ctx, orgID, bucketID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Info("Cache flushed",
+		zap.Stringer("orgID", orgID),
+		zap.Stringer("bucketID", bucketID),
+		zap.Uint64("flushedBytes", res.FlushedBytes),
+		zap.Duration("duration", res.Duration))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, flushResult{
+		FlushedBytes: res.FlushedBytes,
+		Duration:     res.Duration.String(),
+	}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeFlushRequest(r *http.Request) (orgID, bucketID influxdb.ID, err error) {
+	qp := r.URL.Query()
+	if err := orgID.DecodeFromString(qp.Get("orgID")); err != nil {
+		return 0, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	if err := bucketID.DecodeFromString(qp.Get("bucketID")); err != nil {
+		return 0, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		}
+	}
+	return orgID, bucketID, nil
+}
+
+// FlushService is the client implementation of influxdb.CacheFlushService.
+type FlushService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ influxdb.CacheFlushService = (*FlushService)(nil)
+
+// FlushBucket requests the server flush its cache for the bucket.
+func (s *FlushService) FlushBucket(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.CacheFlushResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, prefixFlush)
+	if err != nil {
+		return nil, err
+	}
+	params := u.Query()
+	params.Set("orgID", orgID.String())
+	params.Set("bucketID", bucketID.String())
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, req)
+	req = req.WithContext(ctx)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	hc.Timeout = httpClientTimeout
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var res flushResult
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+
+	d, err := time.ParseDuration(res.Duration)
+	if err != nil {
+		return nil, err
+	}
+	return &influxdb.CacheFlushResult{
+		FlushedBytes: res.FlushedBytes,
+		Duration:     d,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+type flushBucketFunc func(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.CacheFlushResult, error)
+
+func (f flushBucketFunc) FlushBucket(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.CacheFlushResult, error) {
+	return f(ctx, orgID, bucketID)
+}
+
+func TestFlushHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "flushes bucket",
+			query:      "?orgID=020f755c3c082000&bucketID=020f755c3c082001",
+			statusCode: http.StatusOK,
+			body:       `{"flushedBytes": 1024, "duration": "1.5s"}`,
+		},
+		{
+			name:       "invalid bucket id",
+			query:      "?orgID=020f755c3c082000&bucketID=bad",
+			statusCode: http.StatusBadRequest,
+			body:       `{"code": "invalid", "message": "invalid bucketID: id must have a length of 16 bytes"}`,
+		},
+		{
+			name:       "snapshot in progress",
+			query:      "?orgID=020f755c3c082000&bucketID=020f755c3c082001",
+			err:        &influxdb.Error{Code: influxdb.EConflict, Msg: "a cache snapshot is already in progress"},
+			statusCode: http.StatusConflict,
+			body:       `{"code": "conflict", "message": "a cache snapshot is already in progress"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewFlushHandler(&FlushBackend{
+				Logger:           zaptest.NewLogger(t),
+				HTTPErrorHandler: kithttp.ErrorHandler(0),
+				CacheFlushService: flushBucketFunc(func(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.CacheFlushResult, error) {
+					if orgID != 0x020f755c3c082000 || bucketID != 0x020f755c3c082001 {
+						t.Errorf("unexpected org and bucket: %s, %s", orgID, bucketID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &influxdb.CacheFlushResult{FlushedBytes: 1024, Duration: 1500 * time.Millisecond}, nil
+				}),
+			})
+
+			r := httptest.NewRequest(http.MethodPost, prefixFlush+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("unexpected status code: got %d, exp %d", res.StatusCode, tt.statusCode)
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			if eq, diff, err := jsonEqual(string(body), tt.body); err != nil {
+				t.Errorf("failed to compare json: %v\n%s", err, body)
+			} else if !eq {
+				t.Errorf("unexpected body: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,473 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// FluxFragmentBackend is all services and associated parameters required to construct
+// the FluxFragmentHandler.
+type FluxFragmentBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	FluxFragmentService influxdb.FluxFragmentService
+}
+
+// NewFluxFragmentBackend returns a new instance of FluxFragmentBackend.
+func NewFluxFragmentBackend(log *zap.Logger, b *APIBackend) *FluxFragmentBackend {
+	return &FluxFragmentBackend{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		log:                 log,
+		FluxFragmentService: b.FluxFragmentService,
+	}
+}
+
+// FluxFragmentHandler represents an HTTP API handler for flux fragments.
+type FluxFragmentHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	FluxFragmentService influxdb.FluxFragmentService
+}
+
+const (
+	prefixFluxFragments         = "/api/v2/fragments"
+	fluxFragmentsIDPath         = "/api/v2/fragments/:id"
+	fluxFragmentsVersionsPath   = "/api/v2/fragments/:id/versions"
+	fluxFragmentsVersionsIDPath = "/api/v2/fragments/:id/versions/:version"
+)
+
+// NewFluxFragmentHandler returns a new instance of FluxFragmentHandler.
+func NewFluxFragmentHandler(log *zap.Logger, b *FluxFragmentBackend) *FluxFragmentHandler {
+	h := &FluxFragmentHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		FluxFragmentService: b.FluxFragmentService,
+	}
+
+	h.HandlerFunc("POST", prefixFluxFragments, h.handlePostFluxFragment)
+	h.HandlerFunc("GET", prefixFluxFragments, h.handleGetFluxFragments)
+	h.HandlerFunc("GET", fluxFragmentsIDPath, h.handleGetFluxFragment)
+	h.HandlerFunc("PATCH", fluxFragmentsIDPath, h.handlePatchFluxFragment)
+	h.HandlerFunc("DELETE", fluxFragmentsIDPath, h.handleDeleteFluxFragment)
+	h.HandlerFunc("GET", fluxFragmentsVersionsPath, h.handleGetFluxFragmentVersions)
+	h.HandlerFunc("GET", fluxFragmentsVersionsIDPath, h.handleGetFluxFragmentVersion)
+
+	return h
+}
+
+type fluxFragmentResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.FluxFragment
+}
+
+func newFluxFragmentResponse(f *influxdb.FluxFragment) *fluxFragmentResponse {
+	return &fluxFragmentResponse{
+		Links: map[string]string{
+			"self":     fmt.Sprintf("/api/v2/fragments/%s", f.ID),
+			"versions": fmt.Sprintf("/api/v2/fragments/%s/versions", f.ID),
+			"org":      fmt.Sprintf("/api/v2/orgs/%s", f.OrgID),
+		},
+		FluxFragment: *f,
+	}
+}
+
+type fluxFragmentsResponse struct {
+	Links     map[string]string       `json:"links"`
+	Fragments []*fluxFragmentResponse `json:"fragments"`
+}
+
+func newFluxFragmentsResponse(fs []*influxdb.FluxFragment) *fluxFragmentsResponse {
+	res := &fluxFragmentsResponse{
+		Links: map[string]string{
+			"self": prefixFluxFragments,
+		},
+		Fragments: make([]*fluxFragmentResponse, 0, len(fs)),
+	}
+	for _, f := range fs {
+		res.Fragments = append(res.Fragments, newFluxFragmentResponse(f))
+	}
+	return res
+}
+
+type fluxFragmentVersionsResponse struct {
+	Links    map[string]string               `json:"links"`
+	Versions []*influxdb.FluxFragmentVersion `json:"versions"`
+}
+
+type postFluxFragmentRequest struct {
+	OrgID       influxdb.ID `json:"orgID"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Flux        string      `json:"flux"`
+}
+
+// handlePostFluxFragment is the HTTP handler for the POST /api/v2/fragments route.
+func (h *FluxFragmentHandler) handlePostFluxFragment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postFluxFragmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode flux fragment request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	f := &influxdb.FluxFragment{
+		OrgID:       req.OrgID,
+		Name:        req.Name,
+		Description: req.Description,
+		Flux:        req.Flux,
+	}
+	if err := f.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.FluxFragmentService.CreateFluxFragment(ctx, f); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Flux fragment created", zap.String("fragment", fmt.Sprint(f)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newFluxFragmentResponse(f)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetFluxFragmentsRequest(r *http.Request) (*influxdb.FluxFragmentFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.FluxFragmentFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("name"); v != "" {
+		filter.Name = &v
+	}
+	return filter, nil
+}
+
+// handleGetFluxFragments is the HTTP handler for the GET /api/v2/fragments route.
+func (h *FluxFragmentHandler) handleGetFluxFragments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetFluxFragmentsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	fs, err := h.FluxFragmentService.FindFluxFragments(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Flux fragments retrieved", zap.String("fragments", fmt.Sprint(fs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newFluxFragmentsResponse(fs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeFluxFragmentID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetFluxFragment is the HTTP handler for the GET /api/v2/fragments/:id route.
+func (h *FluxFragmentHandler) handleGetFluxFragment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeFluxFragmentID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	f, err := h.FluxFragmentService.FindFluxFragmentByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Flux fragment retrieved", zap.String("fragment", fmt.Sprint(f)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newFluxFragmentResponse(f)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchFluxFragment is the HTTP handler for the PATCH /api/v2/fragments/:id route.
+func (h *FluxFragmentHandler) handlePatchFluxFragment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeFluxFragmentID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.FluxFragmentUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode flux fragment update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	f, err := h.FluxFragmentService.UpdateFluxFragment(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Flux fragment updated", zap.String("fragment", fmt.Sprint(f)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newFluxFragmentResponse(f)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteFluxFragment is the HTTP handler for the DELETE /api/v2/fragments/:id route.
+func (h *FluxFragmentHandler) handleDeleteFluxFragment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeFluxFragmentID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.FluxFragmentService.DeleteFluxFragment(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Flux fragment deleted", zap.String("fragmentID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetFluxFragmentVersions is the HTTP handler for the GET /api/v2/fragments/:id/versions route.
+func (h *FluxFragmentHandler) handleGetFluxFragmentVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeFluxFragmentID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	fvs, err := h.FluxFragmentService.FindFluxFragmentVersions(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Flux fragment versions retrieved", zap.String("fragmentID", id.String()), zap.Int("versions", len(fvs)))
+
+	res := &fluxFragmentVersionsResponse{
+		Links: map[string]string{
+			"self":     fmt.Sprintf("/api/v2/fragments/%s/versions", id),
+			"fragment": fmt.Sprintf("/api/v2/fragments/%s", id),
+		},
+		Versions: fvs,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetFluxFragmentVersion is the HTTP handler for the GET /api/v2/fragments/:id/versions/:version route.
+func (h *FluxFragmentHandler) handleGetFluxFragmentVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeFluxFragmentID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	version, err := strconv.Atoi(httprouter.ParamsFromContext(ctx).ByName("version"))
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid version provided in route",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	fv, err := h.FluxFragmentService.FindFluxFragmentVersion(ctx, id, version)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Flux fragment version retrieved", zap.String("fragmentID", id.String()), zap.Int("version", version))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, fv); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// FluxFragmentService connects to Influx via HTTP using tokens to manage flux fragments.
+type FluxFragmentService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.FluxFragmentService = (*FluxFragmentService)(nil)
+
+// FindFluxFragmentByID returns a single fragment by ID.
+func (s *FluxFragmentService) FindFluxFragmentByID(ctx context.Context, id influxdb.ID) (*influxdb.FluxFragment, error) {
+	var fr fluxFragmentResponse
+	err := s.Client.
+		Get(path.Join(prefixFluxFragments, id.String())).
+		DecodeJSON(&fr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fr.FluxFragment, nil
+}
+
+// FindFluxFragment returns the first fragment that matches filter.
+func (s *FluxFragmentService) FindFluxFragment(ctx context.Context, filter influxdb.FluxFragmentFilter) (*influxdb.FluxFragment, error) {
+	if filter.ID != nil {
+		return s.FindFluxFragmentByID(ctx, *filter.ID)
+	}
+
+	fs, err := s.FindFluxFragments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(fs) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Op:   influxdb.OpFindFluxFragment,
+			Msg:  influxdb.ErrFluxFragmentNotFound,
+		}
+	}
+	return fs[0], nil
+}
+
+// FindFluxFragments returns a list of fragments that match filter.
+func (s *FluxFragmentService) FindFluxFragments(ctx context.Context, filter influxdb.FluxFragmentFilter) ([]*influxdb.FluxFragment, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var fr fluxFragmentsResponse
+	err := s.Client.
+		Get(prefixFluxFragments).
+		QueryParams(params...).
+		DecodeJSON(&fr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := make([]*influxdb.FluxFragment, 0, len(fr.Fragments))
+	for _, f := range fr.Fragments {
+		if filter.Match(&f.FluxFragment) {
+			fs = append(fs, &f.FluxFragment)
+		}
+	}
+	return fs, nil
+}
+
+// CreateFluxFragment creates a new fragment and sets f.ID with the new identifier.
+func (s *FluxFragmentService) CreateFluxFragment(ctx context.Context, f *influxdb.FluxFragment) error {
+	var fr fluxFragmentResponse
+	err := s.Client.
+		PostJSON(postFluxFragmentRequest{
+			OrgID:       f.OrgID,
+			Name:        f.Name,
+			Description: f.Description,
+			Flux:        f.Flux,
+		}, prefixFluxFragments).
+		DecodeJSON(&fr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*f = fr.FluxFragment
+	return nil
+}
+
+// UpdateFluxFragment updates a single fragment with changeset.
+func (s *FluxFragmentService) UpdateFluxFragment(ctx context.Context, id influxdb.ID, upd influxdb.FluxFragmentUpdate) (*influxdb.FluxFragment, error) {
+	var fr fluxFragmentResponse
+	err := s.Client.
+		PatchJSON(upd, path.Join(prefixFluxFragments, id.String())).
+		DecodeJSON(&fr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fr.FluxFragment, nil
+}
+
+// DeleteFluxFragment removes a fragment and all its versions by ID.
+func (s *FluxFragmentService) DeleteFluxFragment(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixFluxFragments, id.String())).
+		Do(ctx)
+}
+
+// FindFluxFragmentVersion returns a single version of a fragment.
+func (s *FluxFragmentService) FindFluxFragmentVersion(ctx context.Context, id influxdb.ID, version int) (*influxdb.FluxFragmentVersion, error) {
+	var fv influxdb.FluxFragmentVersion
+	err := s.Client.
+		Get(path.Join(prefixFluxFragments, id.String(), "versions", strconv.Itoa(version))).
+		DecodeJSON(&fv).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fv, nil
+}
+
+// FindFluxFragmentVersions returns the versions of a fragment, from the oldest to the latest.
+func (s *FluxFragmentService) FindFluxFragmentVersions(ctx context.Context, id influxdb.ID) ([]*influxdb.FluxFragmentVersion, error) {
+	var res fluxFragmentVersionsResponse
+	err := s.Client.
+		Get(path.Join(prefixFluxFragments, id.String(), "versions")).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Versions, nil
+}
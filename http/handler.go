@@ -33,6 +33,8 @@ type Handler struct {
 	requests   *prometheus.CounterVec
 	requestDur *prometheus.HistogramVec
 
+	crashReporter *CrashReporter
+
 	// log logs all HTTP requests as they are served
 	log *zap.Logger
 }
@@ -45,6 +47,7 @@ type (
 		healthHandler  http.Handler
 		metricsHandler http.Handler
 		readyHandler   http.Handler
+		crashReporter  *CrashReporter
 	}
 
 	HandlerOptFn func(opts *handlerOpts)
@@ -86,6 +89,14 @@ func WithReadyHandler(h http.Handler) HandlerOptFn {
 	}
 }
 
+// WithCrashReporter sets the reporter of panics handling requests. By
+// default, panics are recovered and only logged.
+func WithCrashReporter(c *CrashReporter) HandlerOptFn {
+	return func(opts *handlerOpts) {
+		opts.crashReporter = c
+	}
+}
+
 // NewHandlerFromRegistry creates a new handler with the given name,
 // and sets the /metrics endpoint to use the metrics from the given registry,
 // after self-registering h's metrics.
@@ -101,9 +112,14 @@ func NewHandlerFromRegistry(name string, reg *prom.Registry, opts ...HandlerOptF
 		o(&opt)
 	}
 
+	if opt.crashReporter == nil {
+		opt.crashReporter = NewCrashReporter(opt.log, "")
+	}
+
 	h := &Handler{
-		name: name,
-		log:  opt.log,
+		name:          name,
+		log:           opt.log,
+		crashReporter: opt.crashReporter,
 	}
 	h.initMetrics()
 
@@ -112,6 +128,7 @@ func NewHandlerFromRegistry(name string, reg *prom.Registry, opts ...HandlerOptF
 	r.Group(func(r chi.Router) {
 		r.Use(
 			kithttp.Metrics(name, h.requests, h.requestDur),
+			h.crashReporter.Recover(name),
 		)
 		{
 			r.Mount(MetricsPath, opt.metricsHandler)
@@ -126,6 +143,7 @@ func NewHandlerFromRegistry(name string, reg *prom.Registry, opts ...HandlerOptF
 		r.Use(
 			kithttp.Trace(name),
 			kithttp.Metrics(name, h.requests, h.requestDur),
+			h.crashReporter.Recover(name),
 		)
 		{
 			r.Mount("/", opt.apiHandler)
@@ -145,10 +163,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // PrometheusCollectors satisifies prom.PrometheusCollector.
 func (h *Handler) PrometheusCollectors() []prometheus.Collector {
-	return []prometheus.Collector{
+	return append([]prometheus.Collector{
 		h.requests,
 		h.requestDur,
-	}
+	}, h.crashReporter.PrometheusCollectors()...)
 }
 
 func (h *Handler) initMetrics() {
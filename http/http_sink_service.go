@@ -0,0 +1,406 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// HTTPSinkBackend is all services and associated parameters required to construct
+// the HTTPSinkHandler.
+type HTTPSinkBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	HTTPSinkService influxdb.HTTPSinkService
+}
+
+// NewHTTPSinkBackend returns a new instance of HTTPSinkBackend.
+func NewHTTPSinkBackend(log *zap.Logger, b *APIBackend) *HTTPSinkBackend {
+	return &HTTPSinkBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		HTTPSinkService:  b.HTTPSinkService,
+	}
+}
+
+// HTTPSinkHandler represents an HTTP API handler for http sinks.
+type HTTPSinkHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	HTTPSinkService influxdb.HTTPSinkService
+}
+
+const (
+	prefixHTTPSinks = "/api/v2/httpSinks"
+	httpSinksIDPath = "/api/v2/httpSinks/:id"
+)
+
+// NewHTTPSinkHandler returns a new instance of HTTPSinkHandler.
+func NewHTTPSinkHandler(log *zap.Logger, b *HTTPSinkBackend) *HTTPSinkHandler {
+	h := &HTTPSinkHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		HTTPSinkService: b.HTTPSinkService,
+	}
+
+	h.HandlerFunc("POST", prefixHTTPSinks, h.handlePostHTTPSink)
+	h.HandlerFunc("GET", prefixHTTPSinks, h.handleGetHTTPSinks)
+	h.HandlerFunc("GET", httpSinksIDPath, h.handleGetHTTPSink)
+	h.HandlerFunc("PATCH", httpSinksIDPath, h.handlePatchHTTPSink)
+	h.HandlerFunc("DELETE", httpSinksIDPath, h.handleDeleteHTTPSink)
+
+	return h
+}
+
+type httpSinkResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.HTTPSink
+}
+
+func newHTTPSinkResponse(hs *influxdb.HTTPSink) *httpSinkResponse {
+	return &httpSinkResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/httpSinks/%s", hs.ID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", hs.OrgID),
+		},
+		HTTPSink: *hs,
+	}
+}
+
+type httpSinksResponse struct {
+	Links map[string]string   `json:"links"`
+	Sinks []*httpSinkResponse `json:"httpSinks"`
+}
+
+func newHTTPSinksResponse(hss []*influxdb.HTTPSink) *httpSinksResponse {
+	res := &httpSinksResponse{
+		Links: map[string]string{
+			"self": prefixHTTPSinks,
+		},
+		Sinks: make([]*httpSinkResponse, 0, len(hss)),
+	}
+	for _, hs := range hss {
+		res.Sinks = append(res.Sinks, newHTTPSinkResponse(hs))
+	}
+	return res
+}
+
+type postHTTPSinkRequest struct {
+	OrgID       influxdb.ID            `json:"orgID"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	URL         string                 `json:"url"`
+	Method      string                 `json:"method,omitempty"`
+	Headers     map[string]string      `json:"headers,omitempty"`
+	AuthMethod  string                 `json:"authMethod,omitempty"`
+	Username    string                 `json:"username,omitempty"`
+	Credentials string                 `json:"credentials,omitempty"`
+	Retry       influxdb.HTTPSinkRetry `json:"retry"`
+	RateLimit   float64                `json:"rateLimit,omitempty"`
+}
+
+func (r postHTTPSinkRequest) toHTTPSink() *influxdb.HTTPSink {
+	hs := &influxdb.HTTPSink{
+		OrgID:       r.OrgID,
+		Name:        r.Name,
+		Description: r.Description,
+		URL:         r.URL,
+		Method:      r.Method,
+		Headers:     r.Headers,
+		AuthMethod:  r.AuthMethod,
+		Username:    r.Username,
+		Retry:       r.Retry,
+		RateLimit:   r.RateLimit,
+	}
+	if r.Credentials != "" {
+		hs.Credentials.Value = &r.Credentials
+	}
+	return hs
+}
+
+// handlePostHTTPSink is the HTTP handler for the POST /api/v2/httpSinks route.
+func (h *HTTPSinkHandler) handlePostHTTPSink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postHTTPSinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode http sink request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	hs := req.toHTTPSink()
+	hs.SetDefaults()
+	if err := hs.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.HTTPSinkService.CreateHTTPSink(ctx, hs); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("HTTP sink created", zap.String("httpSinkID", hs.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newHTTPSinkResponse(hs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetHTTPSinksRequest(r *http.Request) (*influxdb.HTTPSinkFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.HTTPSinkFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("name"); v != "" {
+		filter.Name = &v
+	}
+	return filter, nil
+}
+
+// handleGetHTTPSinks is the HTTP handler for the GET /api/v2/httpSinks route.
+func (h *HTTPSinkHandler) handleGetHTTPSinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetHTTPSinksRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	hss, err := h.HTTPSinkService.FindHTTPSinks(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("HTTP sinks retrieved", zap.Int("count", len(hss)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newHTTPSinksResponse(hss)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeHTTPSinkID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetHTTPSink is the HTTP handler for the GET /api/v2/httpSinks/:id route.
+func (h *HTTPSinkHandler) handleGetHTTPSink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeHTTPSinkID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	hs, err := h.HTTPSinkService.FindHTTPSinkByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("HTTP sink retrieved", zap.String("httpSinkID", id.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newHTTPSinkResponse(hs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchHTTPSink is the HTTP handler for the PATCH /api/v2/httpSinks/:id route.
+func (h *HTTPSinkHandler) handlePatchHTTPSink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeHTTPSinkID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.HTTPSinkUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode http sink update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	hs, err := h.HTTPSinkService.UpdateHTTPSink(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("HTTP sink updated", zap.String("httpSinkID", id.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newHTTPSinkResponse(hs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteHTTPSink is the HTTP handler for the DELETE /api/v2/httpSinks/:id route.
+func (h *HTTPSinkHandler) handleDeleteHTTPSink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeHTTPSinkID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.HTTPSinkService.DeleteHTTPSink(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("HTTP sink deleted", zap.String("httpSinkID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HTTPSinkService connects to Influx via HTTP using tokens to manage http sinks.
+type HTTPSinkService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.HTTPSinkService = (*HTTPSinkService)(nil)
+
+// FindHTTPSinkByID returns a single http sink by ID.
+func (s *HTTPSinkService) FindHTTPSinkByID(ctx context.Context, id influxdb.ID) (*influxdb.HTTPSink, error) {
+	var hr httpSinkResponse
+	err := s.Client.
+		Get(path.Join(prefixHTTPSinks, id.String())).
+		DecodeJSON(&hr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &hr.HTTPSink, nil
+}
+
+// FindHTTPSink returns the first http sink that matches filter.
+func (s *HTTPSinkService) FindHTTPSink(ctx context.Context, filter influxdb.HTTPSinkFilter) (*influxdb.HTTPSink, error) {
+	if filter.ID != nil {
+		return s.FindHTTPSinkByID(ctx, *filter.ID)
+	}
+	hss, err := s.FindHTTPSinks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(hss) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Op:   influxdb.OpFindHTTPSink,
+			Msg:  influxdb.ErrHTTPSinkNotFound,
+		}
+	}
+	return hss[0], nil
+}
+
+// FindHTTPSinks returns a list of http sinks that match filter.
+func (s *HTTPSinkService) FindHTTPSinks(ctx context.Context, filter influxdb.HTTPSinkFilter) ([]*influxdb.HTTPSink, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var hr httpSinksResponse
+	err := s.Client.
+		Get(prefixHTTPSinks).
+		QueryParams(params...).
+		DecodeJSON(&hr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hss := make([]*influxdb.HTTPSink, 0, len(hr.Sinks))
+	for _, hs := range hr.Sinks {
+		hss = append(hss, &hs.HTTPSink)
+	}
+	return hss, nil
+}
+
+// CreateHTTPSink creates a new http sink and sets hs.ID with the new identifier.
+func (s *HTTPSinkService) CreateHTTPSink(ctx context.Context, hs *influxdb.HTTPSink) error {
+	req := postHTTPSinkRequest{
+		OrgID:       hs.OrgID,
+		Name:        hs.Name,
+		Description: hs.Description,
+		URL:         hs.URL,
+		Method:      hs.Method,
+		Headers:     hs.Headers,
+		AuthMethod:  hs.AuthMethod,
+		Username:    hs.Username,
+		Retry:       hs.Retry,
+		RateLimit:   hs.RateLimit,
+	}
+	if hs.Credentials.Value != nil {
+		req.Credentials = *hs.Credentials.Value
+	}
+
+	var hr httpSinkResponse
+	err := s.Client.
+		PostJSON(req, prefixHTTPSinks).
+		DecodeJSON(&hr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*hs = hr.HTTPSink
+	return nil
+}
+
+// UpdateHTTPSink updates a single http sink with changeset.
+func (s *HTTPSinkService) UpdateHTTPSink(ctx context.Context, id influxdb.ID, upd influxdb.HTTPSinkUpdate) (*influxdb.HTTPSink, error) {
+	var hr httpSinkResponse
+	err := s.Client.
+		PatchJSON(upd, path.Join(prefixHTTPSinks, id.String())).
+		DecodeJSON(&hr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &hr.HTTPSink, nil
+}
+
+// DeleteHTTPSink removes a http sink by ID.
+func (s *HTTPSinkService) DeleteHTTPSink(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixHTTPSinks, id.String())).
+		Do(ctx)
+}
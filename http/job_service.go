@@ -0,0 +1,263 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// JobBackend is all services and associated parameters required to construct
+// the JobHandler.
+type JobBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	JobService influxdb.JobService
+}
+
+// NewJobBackend returns a new instance of JobBackend.
+func NewJobBackend(log *zap.Logger, b *APIBackend) *JobBackend {
+	return &JobBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		JobService:       b.JobService,
+	}
+}
+
+// JobHandler represents an HTTP API handler for the background jobs of the server.
+type JobHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	JobService influxdb.JobService
+}
+
+const (
+	prefixJobs     = "/api/v2/jobs"
+	jobsIDPath     = "/api/v2/jobs/:id"
+	jobsCancelPath = "/api/v2/jobs/:id/cancel"
+)
+
+// NewJobHandler returns a new instance of JobHandler.
+func NewJobHandler(log *zap.Logger, b *JobBackend) *JobHandler {
+	h := &JobHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		JobService: b.JobService,
+	}
+
+	h.HandlerFunc("GET", prefixJobs, h.handleGetJobs)
+	h.HandlerFunc("GET", jobsIDPath, h.handleGetJob)
+	h.HandlerFunc("POST", jobsCancelPath, h.handlePostJobCancel)
+
+	return h
+}
+
+type jobResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.Job
+}
+
+func newJobResponse(j *influxdb.Job) *jobResponse {
+	return &jobResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/jobs/%s", j.ID),
+			"cancel": fmt.Sprintf("/api/v2/jobs/%s/cancel", j.ID),
+		},
+		Job: *j,
+	}
+}
+
+type jobsResponse struct {
+	Links map[string]string `json:"links"`
+	Jobs  []*jobResponse    `json:"jobs"`
+}
+
+func newJobsResponse(js []*influxdb.Job) *jobsResponse {
+	res := &jobsResponse{
+		Links: map[string]string{
+			"self": prefixJobs,
+		},
+		Jobs: make([]*jobResponse, 0, len(js)),
+	}
+	for _, j := range js {
+		res.Jobs = append(res.Jobs, newJobResponse(j))
+	}
+	return res
+}
+
+func decodeGetJobsRequest(r *http.Request) (*influxdb.JobFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.JobFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("kind"); v != "" {
+		kind := influxdb.JobKind(v)
+		filter.Kind = &kind
+	}
+	if v := qp.Get("status"); v != "" {
+		status := influxdb.JobStatus(v)
+		filter.Status = &status
+	}
+	return filter, nil
+}
+
+// handleGetJobs is the HTTP handler for the GET /api/v2/jobs route.
+func (h *JobHandler) handleGetJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetJobsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	js, err := h.JobService.FindJobs(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Jobs retrieved", zap.String("jobs", fmt.Sprint(js)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newJobsResponse(js)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeJobID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetJob is the HTTP handler for the GET /api/v2/jobs/:id route.
+func (h *JobHandler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeJobID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	j, err := h.JobService.FindJobByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Job retrieved", zap.String("job", fmt.Sprint(j)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newJobResponse(j)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostJobCancel is the HTTP handler for the POST /api/v2/jobs/:id/cancel route.
+func (h *JobHandler) handlePostJobCancel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeJobID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	j, err := h.JobService.CancelJob(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Job canceled", zap.String("job", fmt.Sprint(j)))
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, newJobResponse(j)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// JobService connects to Influx via HTTP using tokens to list and cancel jobs.
+type JobService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.JobService = (*JobService)(nil)
+
+// FindJobByID returns a single job by ID.
+func (s *JobService) FindJobByID(ctx context.Context, id influxdb.ID) (*influxdb.Job, error) {
+	var jr jobResponse
+	err := s.Client.
+		Get(path.Join(prefixJobs, id.String())).
+		DecodeJSON(&jr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &jr.Job, nil
+}
+
+// FindJobs returns the jobs that match filter.
+func (s *JobService) FindJobs(ctx context.Context, filter influxdb.JobFilter) ([]*influxdb.Job, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Kind != nil {
+		params = append(params, [2]string{"kind", string(*filter.Kind)})
+	}
+	if filter.Status != nil {
+		params = append(params, [2]string{"status", string(*filter.Status)})
+	}
+
+	var jr jobsResponse
+	err := s.Client.
+		Get(prefixJobs).
+		QueryParams(params...).
+		DecodeJSON(&jr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	js := make([]*influxdb.Job, 0, len(jr.Jobs))
+	for _, j := range jr.Jobs {
+		js = append(js, &j.Job)
+	}
+	return js, nil
+}
+
+// CancelJob cancels a running job.
+func (s *JobService) CancelJob(ctx context.Context, id influxdb.ID) (*influxdb.Job, error) {
+	var jr jobResponse
+	err := s.Client.
+		Post(nil, path.Join(prefixJobs, id.String(), "cancel")).
+		DecodeJSON(&jr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &jr.Job, nil
+}
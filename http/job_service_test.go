@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/jobs"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestJobHandler(t *testing.T) {
+	m := jobs.NewManager(zaptest.NewLogger(t))
+	defer m.Close()
+
+	orgID := influxdb.ID(1)
+	j := m.Start(context.Background(), &influxdb.Job{Kind: influxdb.JobKindOrgDeletion, OrgID: orgID}, func(ctx context.Context, p *jobs.Progress) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	h := NewJobHandler(zaptest.NewLogger(t), &JobBackend{
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		log:              zaptest.NewLogger(t),
+		JobService:       m,
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/jobs?orgID="+orgID.String()+"&kind=org-deletion", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var res jobsResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Jobs) != 1 || res.Jobs[0].ID != j.ID || res.Jobs[0].Status != influxdb.JobRunning {
+		t.Fatalf("unexpected jobs %+v", res.Jobs)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/v2/jobs/"+j.ID.String()+"/cancel", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want 202: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/jobs/"+influxdb.ID(42).String(), nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("got status %d for a missing job, want 404", w.Code)
+	}
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+type lastValuesResponse struct {
+	Links  map[string]string     `json:"links"`
+	Values []*influxdb.LastValue `json:"values"`
+}
+
+// decodeLastValueFilter decodes the filter of the query of a request, whose
+// tags are key:value pairs.
+func decodeLastValueFilter(r *http.Request) (influxdb.LastValueFilter, error) {
+	qp := r.URL.Query()
+	f := influxdb.LastValueFilter{
+		Measurement: qp.Get("measurement"),
+		Field:       qp.Get("field"),
+	}
+	for _, tag := range qp["tag"] {
+		kv := strings.SplitN(tag, ":", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return f, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid tag %q: tags must be key:value pairs", tag),
+			}
+		}
+		if f.Tags == nil {
+			f.Tags = make(map[string]string)
+		}
+		f.Tags[kv[0]] = kv[1]
+	}
+	return f, nil
+}
+
+// handleGetBucketLastValues is the HTTP handler for the GET /api/v2/buckets/:id/last-values route.
+func (h *BucketHandler) handleGetBucketLastValues(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "BucketHandler.handleGetBucketLastValues")
+	defer span.Finish()
+
+	filter, err := decodeLastValueFilter(r)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	b, _, err := h.decodeBucketMetadataRequest(r)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	vs, err := h.LastValueService.FindLastValues(r.Context(), b.OrgID, b.ID, filter)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Bucket last values retrieved", zap.Stringer("bucketID", b.ID), zap.Int("values", len(vs)))
+
+	if vs == nil {
+		vs = []*influxdb.LastValue{}
+	}
+	h.api.Respond(w, http.StatusOK, &lastValuesResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/last-values", b.ID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", b.ID),
+		},
+		Values: vs,
+	})
+}
+
+// LastValueService is the client implementation of influxdb.LastValueService.
+type LastValueService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.LastValueService = (*LastValueService)(nil)
+
+// FindLastValues returns the last values of the series of the bucket matching
+// filter. The organization of the bucket is found by the server.
+func (s *LastValueService) FindLastValues(ctx context.Context, orgID, bucketID influxdb.ID, filter influxdb.LastValueFilter) ([]*influxdb.LastValue, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.Measurement != "" {
+		params = append(params, [2]string{"measurement", filter.Measurement})
+	}
+	if filter.Field != "" {
+		params = append(params, [2]string{"field", filter.Field})
+	}
+	for k, v := range filter.Tags {
+		params = append(params, [2]string{"tag", k + ":" + v})
+	}
+
+	var resp lastValuesResponse
+	err := s.Client.
+		Get(prefixBuckets, bucketID.String(), "last-values").
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Values, nil
+}
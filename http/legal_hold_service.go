@@ -0,0 +1,388 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"go.uber.org/zap"
+)
+
+// LegalHoldBackend is all services and associated parameters required to construct
+// the LegalHoldHandler.
+type LegalHoldBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	LegalHoldService influxdb.LegalHoldService
+}
+
+// NewLegalHoldBackend returns a new instance of LegalHoldBackend.
+func NewLegalHoldBackend(log *zap.Logger, b *APIBackend) *LegalHoldBackend {
+	return &LegalHoldBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		LegalHoldService: b.LegalHoldService,
+	}
+}
+
+// LegalHoldHandler represents an HTTP API handler for the legal holds of
+// buckets.
+type LegalHoldHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	LegalHoldService influxdb.LegalHoldService
+}
+
+const (
+	prefixLegalHolds      = "/api/v2/legalHolds"
+	legalHoldsIDPath      = "/api/v2/legalHolds/:id"
+	legalHoldsReleasePath = "/api/v2/legalHolds/:id/release"
+)
+
+// NewLegalHoldHandler returns a new instance of LegalHoldHandler.
+func NewLegalHoldHandler(log *zap.Logger, b *LegalHoldBackend) *LegalHoldHandler {
+	h := &LegalHoldHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		LegalHoldService: b.LegalHoldService,
+	}
+
+	h.HandlerFunc("POST", prefixLegalHolds, h.handlePostLegalHold)
+	h.HandlerFunc("GET", prefixLegalHolds, h.handleGetLegalHolds)
+	h.HandlerFunc("GET", legalHoldsIDPath, h.handleGetLegalHold)
+	h.HandlerFunc("POST", legalHoldsReleasePath, h.handlePostLegalHoldRelease)
+
+	return h
+}
+
+type legalHoldResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.LegalHold
+}
+
+func newLegalHoldResponse(lh *influxdb.LegalHold) *legalHoldResponse {
+	return &legalHoldResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/legalHolds/%s", lh.ID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", lh.BucketID),
+			"org":    fmt.Sprintf("/api/v2/orgs/%s", lh.OrgID),
+		},
+		LegalHold: *lh,
+	}
+}
+
+type legalHoldsResponse struct {
+	Links      map[string]string    `json:"links"`
+	LegalHolds []*legalHoldResponse `json:"legalHolds"`
+}
+
+func newLegalHoldsResponse(hs []*influxdb.LegalHold) *legalHoldsResponse {
+	res := &legalHoldsResponse{
+		Links: map[string]string{
+			"self": prefixLegalHolds,
+		},
+		LegalHolds: make([]*legalHoldResponse, 0, len(hs)),
+	}
+	for _, lh := range hs {
+		res.LegalHolds = append(res.LegalHolds, newLegalHoldResponse(lh))
+	}
+	return res
+}
+
+type postLegalHoldRequest struct {
+	OrgID     influxdb.ID `json:"orgID"`
+	BucketID  influxdb.ID `json:"bucketID"`
+	Predicate string      `json:"predicate,omitempty"`
+	Start     *time.Time  `json:"start,omitempty"`
+	Stop      *time.Time  `json:"stop,omitempty"`
+	Reason    string      `json:"reason"`
+}
+
+// handlePostLegalHold is the HTTP handler for the POST /api/v2/legalHolds route.
+func (h *LegalHoldHandler) handlePostLegalHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postLegalHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode legal hold request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	lh := &influxdb.LegalHold{
+		OrgID:     req.OrgID,
+		BucketID:  req.BucketID,
+		Predicate: req.Predicate,
+		Start:     req.Start,
+		Stop:      req.Stop,
+		Reason:    req.Reason,
+	}
+	if err := lh.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := validateLegalHoldPredicate(lh.Predicate); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.LegalHoldService.CreateLegalHold(ctx, lh); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Legal hold created", zap.String("legalHold", fmt.Sprint(lh)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newLegalHoldResponse(lh)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// validateLegalHoldPredicate returns an error if pred is not a predicate of
+// deletes.
+func validateLegalHoldPredicate(pred string) error {
+	n, err := predicate.Parse(pred)
+	if err == nil {
+		_, err = predicate.New(n)
+	}
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid legal hold predicate",
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+func decodeGetLegalHoldsRequest(r *http.Request) (*influxdb.LegalHoldFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.LegalHoldFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("bucketID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}
+		}
+		filter.BucketID = id
+	}
+	if v := qp.Get("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid active",
+				Err:  err,
+			}
+		}
+		filter.Active = &active
+	}
+	return filter, nil
+}
+
+// handleGetLegalHolds is the HTTP handler for the GET /api/v2/legalHolds route.
+func (h *LegalHoldHandler) handleGetLegalHolds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetLegalHoldsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	hs, err := h.LegalHoldService.FindLegalHolds(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Legal holds retrieved", zap.String("legalHolds", fmt.Sprint(hs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLegalHoldsResponse(hs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeLegalHoldID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetLegalHold is the HTTP handler for the GET /api/v2/legalHolds/:id route.
+func (h *LegalHoldHandler) handleGetLegalHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeLegalHoldID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	lh, err := h.LegalHoldService.FindLegalHoldByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Legal hold retrieved", zap.String("legalHold", fmt.Sprint(lh)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLegalHoldResponse(lh)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type postLegalHoldReleaseRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// handlePostLegalHoldRelease is the HTTP handler for the POST /api/v2/legalHolds/:id/release route.
+func (h *LegalHoldHandler) handlePostLegalHoldRelease(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeLegalHoldID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req postLegalHoldReleaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode legal hold release request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	lh, err := h.LegalHoldService.ReleaseLegalHold(ctx, id, req.Reason)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Legal hold released", zap.String("legalHold", fmt.Sprint(lh)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLegalHoldResponse(lh)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// LegalHoldService connects to Influx via HTTP using tokens to manage the
+// legal holds of buckets.
+type LegalHoldService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.LegalHoldService = (*LegalHoldService)(nil)
+
+// FindLegalHoldByID returns a single hold by ID.
+func (s *LegalHoldService) FindLegalHoldByID(ctx context.Context, id influxdb.ID) (*influxdb.LegalHold, error) {
+	var res legalHoldResponse
+	err := s.Client.
+		Get(path.Join(prefixLegalHolds, id.String())).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.LegalHold, nil
+}
+
+// FindLegalHolds returns the holds matching filter, oldest first.
+func (s *LegalHoldService) FindLegalHolds(ctx context.Context, filter influxdb.LegalHoldFilter) ([]*influxdb.LegalHold, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.BucketID != nil {
+		params = append(params, [2]string{"bucketID", filter.BucketID.String()})
+	}
+	if filter.Active != nil {
+		params = append(params, [2]string{"active", strconv.FormatBool(*filter.Active)})
+	}
+
+	var res legalHoldsResponse
+	err := s.Client.
+		Get(prefixLegalHolds).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hs := make([]*influxdb.LegalHold, 0, len(res.LegalHolds))
+	for _, lh := range res.LegalHolds {
+		hs = append(hs, &lh.LegalHold)
+	}
+	return hs, nil
+}
+
+// CreateLegalHold places a hold on the data of a bucket.
+func (s *LegalHoldService) CreateLegalHold(ctx context.Context, lh *influxdb.LegalHold) error {
+	var res legalHoldResponse
+	err := s.Client.
+		PostJSON(postLegalHoldRequest{
+			OrgID:     lh.OrgID,
+			BucketID:  lh.BucketID,
+			Predicate: lh.Predicate,
+			Start:     lh.Start,
+			Stop:      lh.Stop,
+			Reason:    lh.Reason,
+		}, prefixLegalHolds).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*lh = res.LegalHold
+	return nil
+}
+
+// ReleaseLegalHold releases an active hold.
+func (s *LegalHoldService) ReleaseLegalHold(ctx context.Context, id influxdb.ID, reason string) (*influxdb.LegalHold, error) {
+	var res legalHoldResponse
+	err := s.Client.
+		PostJSON(postLegalHoldReleaseRequest{Reason: reason}, path.Join(prefixLegalHolds, id.String(), "release")).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.LegalHold, nil
+}
@@ -0,0 +1,379 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// LifecyclePolicyBackend is all services and associated parameters required to construct
+// the LifecyclePolicyHandler.
+type LifecyclePolicyBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	LifecyclePolicyService influxdb.LifecyclePolicyService
+}
+
+// NewLifecyclePolicyBackend returns a new instance of LifecyclePolicyBackend.
+func NewLifecyclePolicyBackend(log *zap.Logger, b *APIBackend) *LifecyclePolicyBackend {
+	return &LifecyclePolicyBackend{
+		HTTPErrorHandler:       b.HTTPErrorHandler,
+		log:                    log,
+		LifecyclePolicyService: b.LifecyclePolicyService,
+	}
+}
+
+// LifecyclePolicyHandler represents an HTTP API handler for lifecycle policies.
+type LifecyclePolicyHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	LifecyclePolicyService influxdb.LifecyclePolicyService
+}
+
+const (
+	prefixLifecyclePolicies = "/api/v2/lifecycle-policies"
+	lifecyclePoliciesIDPath = "/api/v2/lifecycle-policies/:id"
+)
+
+// NewLifecyclePolicyHandler returns a new instance of LifecyclePolicyHandler.
+func NewLifecyclePolicyHandler(log *zap.Logger, b *LifecyclePolicyBackend) *LifecyclePolicyHandler {
+	h := &LifecyclePolicyHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		LifecyclePolicyService: b.LifecyclePolicyService,
+	}
+
+	h.HandlerFunc("POST", prefixLifecyclePolicies, h.handlePostLifecyclePolicy)
+	h.HandlerFunc("GET", prefixLifecyclePolicies, h.handleGetLifecyclePolicies)
+	h.HandlerFunc("GET", lifecyclePoliciesIDPath, h.handleGetLifecyclePolicy)
+	h.HandlerFunc("PATCH", lifecyclePoliciesIDPath, h.handlePatchLifecyclePolicy)
+	h.HandlerFunc("DELETE", lifecyclePoliciesIDPath, h.handleDeleteLifecyclePolicy)
+
+	return h
+}
+
+type lifecyclePolicyResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.LifecyclePolicy
+}
+
+func newLifecyclePolicyResponse(p *influxdb.LifecyclePolicy) *lifecyclePolicyResponse {
+	return &lifecyclePolicyResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/lifecycle-policies/%s", p.ID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", p.BucketID),
+		},
+		LifecyclePolicy: *p,
+	}
+}
+
+type lifecyclePoliciesResponse struct {
+	Links    map[string]string          `json:"links"`
+	Policies []*lifecyclePolicyResponse `json:"policies"`
+}
+
+func newLifecyclePoliciesResponse(ps []*influxdb.LifecyclePolicy) *lifecyclePoliciesResponse {
+	res := &lifecyclePoliciesResponse{
+		Links: map[string]string{
+			"self": prefixLifecyclePolicies,
+		},
+		Policies: make([]*lifecyclePolicyResponse, 0, len(ps)),
+	}
+	for _, p := range ps {
+		res.Policies = append(res.Policies, newLifecyclePolicyResponse(p))
+	}
+	return res
+}
+
+type postLifecyclePolicyRequest struct {
+	OrgID       influxdb.ID               `json:"orgID"`
+	BucketID    influxdb.ID               `json:"bucketID"`
+	Description string                    `json:"description,omitempty"`
+	Every       influxdb.Duration         `json:"every"`
+	Retention   influxdb.Duration         `json:"retention"`
+	Downsample  []influxdb.DownsampleRule `json:"downsample,omitempty"`
+	Deletes     []influxdb.DeleteRule     `json:"deletes,omitempty"`
+}
+
+// handlePostLifecyclePolicy is the HTTP handler for the POST /api/v2/lifecycle-policies route.
+func (h *LifecyclePolicyHandler) handlePostLifecyclePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postLifecyclePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode lifecycle policy request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	p := &influxdb.LifecyclePolicy{
+		OrgID:       req.OrgID,
+		BucketID:    req.BucketID,
+		Description: req.Description,
+		Every:       req.Every,
+		Retention:   req.Retention,
+		Downsample:  req.Downsample,
+		Deletes:     req.Deletes,
+	}
+	if err := p.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.LifecyclePolicyService.CreateLifecyclePolicy(ctx, p); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Lifecycle policy created", zap.String("policy", fmt.Sprint(p)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newLifecyclePolicyResponse(p)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetLifecyclePoliciesRequest(r *http.Request) (*influxdb.LifecyclePolicyFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.LifecyclePolicyFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("bucketID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}
+		}
+		filter.BucketID = id
+	}
+	return filter, nil
+}
+
+// handleGetLifecyclePolicies is the HTTP handler for the GET /api/v2/lifecycle-policies route.
+func (h *LifecyclePolicyHandler) handleGetLifecyclePolicies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetLifecyclePoliciesRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ps, err := h.LifecyclePolicyService.FindLifecyclePolicies(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Lifecycle policies retrieved", zap.String("policies", fmt.Sprint(ps)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLifecyclePoliciesResponse(ps)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeLifecyclePolicyID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetLifecyclePolicy is the HTTP handler for the GET /api/v2/lifecycle-policies/:id route.
+func (h *LifecyclePolicyHandler) handleGetLifecyclePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeLifecyclePolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	p, err := h.LifecyclePolicyService.FindLifecyclePolicyByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Lifecycle policy retrieved", zap.String("policy", fmt.Sprint(p)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLifecyclePolicyResponse(p)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchLifecyclePolicy is the HTTP handler for the PATCH /api/v2/lifecycle-policies/:id route.
+func (h *LifecyclePolicyHandler) handlePatchLifecyclePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeLifecyclePolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.LifecyclePolicyUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode lifecycle policy update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	p, err := h.LifecyclePolicyService.UpdateLifecyclePolicy(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Lifecycle policy updated", zap.String("policy", fmt.Sprint(p)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLifecyclePolicyResponse(p)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteLifecyclePolicy is the HTTP handler for the DELETE /api/v2/lifecycle-policies/:id route.
+func (h *LifecyclePolicyHandler) handleDeleteLifecyclePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeLifecyclePolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.LifecyclePolicyService.DeleteLifecyclePolicy(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Lifecycle policy deleted", zap.String("policyID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LifecyclePolicyService connects to Influx via HTTP using tokens to manage lifecycle policies.
+type LifecyclePolicyService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.LifecyclePolicyService = (*LifecyclePolicyService)(nil)
+
+// FindLifecyclePolicyByID returns a single lifecycle policy by ID.
+func (s *LifecyclePolicyService) FindLifecyclePolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.LifecyclePolicy, error) {
+	var pr lifecyclePolicyResponse
+	err := s.Client.
+		Get(path.Join(prefixLifecyclePolicies, id.String())).
+		DecodeJSON(&pr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pr.LifecyclePolicy, nil
+}
+
+// FindLifecyclePolicies returns a list of lifecycle policies that match filter.
+func (s *LifecyclePolicyService) FindLifecyclePolicies(ctx context.Context, filter influxdb.LifecyclePolicyFilter) ([]*influxdb.LifecyclePolicy, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.BucketID != nil {
+		params = append(params, [2]string{"bucketID", filter.BucketID.String()})
+	}
+
+	var pr lifecyclePoliciesResponse
+	err := s.Client.
+		Get(prefixLifecyclePolicies).
+		QueryParams(params...).
+		DecodeJSON(&pr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ps := make([]*influxdb.LifecyclePolicy, 0, len(pr.Policies))
+	for _, p := range pr.Policies {
+		ps = append(ps, &p.LifecyclePolicy)
+	}
+	return ps, nil
+}
+
+// CreateLifecyclePolicy creates a new lifecycle policy and sets p.ID with the new identifier.
+func (s *LifecyclePolicyService) CreateLifecyclePolicy(ctx context.Context, p *influxdb.LifecyclePolicy) error {
+	var pr lifecyclePolicyResponse
+	err := s.Client.
+		PostJSON(postLifecyclePolicyRequest{
+			OrgID:       p.OrgID,
+			BucketID:    p.BucketID,
+			Description: p.Description,
+			Every:       p.Every,
+			Retention:   p.Retention,
+			Downsample:  p.Downsample,
+			Deletes:     p.Deletes,
+		}, prefixLifecyclePolicies).
+		DecodeJSON(&pr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*p = pr.LifecyclePolicy
+	return nil
+}
+
+// UpdateLifecyclePolicy updates a single lifecycle policy with changeset.
+func (s *LifecyclePolicyService) UpdateLifecyclePolicy(ctx context.Context, id influxdb.ID, upd influxdb.LifecyclePolicyUpdate) (*influxdb.LifecyclePolicy, error) {
+	var pr lifecyclePolicyResponse
+	err := s.Client.
+		PatchJSON(upd, path.Join(prefixLifecyclePolicies, id.String())).
+		DecodeJSON(&pr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pr.LifecyclePolicy, nil
+}
+
+// UpdateLifecyclePolicyStatus is not implemented for http.
+func (s *LifecyclePolicyService) UpdateLifecyclePolicyStatus(ctx context.Context, id influxdb.ID, status influxdb.LifecyclePolicyStatus) error {
+	return &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "update lifecycle policy status is not implemented for http",
+	}
+}
+
+// DeleteLifecyclePolicy removes a lifecycle policy by ID.
+func (s *LifecyclePolicyService) DeleteLifecyclePolicy(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixLifecyclePolicies, id.String())).
+		Do(ctx)
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// LimitAlertsBackend is all services and associated parameters required to construct
+// the LimitAlertsHandler.
+type LimitAlertsBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	LimitAlertsService influxdb.LimitAlertsService
+}
+
+// NewLimitAlertsBackend returns a new instance of LimitAlertsBackend.
+func NewLimitAlertsBackend(log *zap.Logger, b *APIBackend) *LimitAlertsBackend {
+	return &LimitAlertsBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		log:                log,
+		LimitAlertsService: b.LimitAlertsService,
+	}
+}
+
+// LimitAlertsHandler represents an HTTP API handler for the built-in checks
+// of the platform limits of organizations.
+type LimitAlertsHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	LimitAlertsService influxdb.LimitAlertsService
+}
+
+const prefixLimitAlerts = "/api/v2/limitAlerts"
+
+// NewLimitAlertsHandler returns a new instance of LimitAlertsHandler.
+func NewLimitAlertsHandler(log *zap.Logger, b *LimitAlertsBackend) *LimitAlertsHandler {
+	h := &LimitAlertsHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		LimitAlertsService: b.LimitAlertsService,
+	}
+
+	h.HandlerFunc("GET", prefixLimitAlerts, h.handleGetLimitAlerts)
+	h.HandlerFunc("PATCH", prefixLimitAlerts, h.handlePatchLimitAlerts)
+
+	return h
+}
+
+type limitAlertsResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.LimitAlerts
+}
+
+func newLimitAlertsResponse(a *influxdb.LimitAlerts) *limitAlertsResponse {
+	return &limitAlertsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s?orgID=%s", prefixLimitAlerts, a.OrgID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", a.OrgID),
+		},
+		LimitAlerts: *a,
+	}
+}
+
+func decodeLimitAlertsOrgID(r *http.Request) (influxdb.ID, error) {
+	orgID, err := influxdb.IDFromString(r.URL.Query().Get("orgID"))
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	return *orgID, nil
+}
+
+// handleGetLimitAlerts is the HTTP handler for the GET /api/v2/limitAlerts route.
+func (h *LimitAlertsHandler) handleGetLimitAlerts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, err := decodeLimitAlertsOrgID(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	a, err := h.LimitAlertsService.FindLimitAlerts(ctx, orgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Limit alerts retrieved", zap.String("limitAlerts", fmt.Sprint(a)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLimitAlertsResponse(a)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchLimitAlerts is the HTTP handler for the PATCH /api/v2/limitAlerts route.
+func (h *LimitAlertsHandler) handlePatchLimitAlerts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, err := decodeLimitAlertsOrgID(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.LimitAlertsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode limit alerts update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	a, err := h.LimitAlertsService.UpdateLimitAlerts(ctx, orgID, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Limit alerts updated", zap.String("limitAlerts", fmt.Sprint(a)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLimitAlertsResponse(a)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// LimitAlertsService connects to Influx via HTTP using tokens to manage the
+// built-in checks of the platform limits of organizations.
+type LimitAlertsService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.LimitAlertsService = (*LimitAlertsService)(nil)
+
+// FindLimitAlerts returns the checks of an organization.
+func (s *LimitAlertsService) FindLimitAlerts(ctx context.Context, orgID influxdb.ID) (*influxdb.LimitAlerts, error) {
+	var res limitAlertsResponse
+	err := s.Client.
+		Get(prefixLimitAlerts).
+		QueryParams([2]string{"orgID", orgID.String()}).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.LimitAlerts, nil
+}
+
+// UpdateLimitAlerts updates the checks of an organization with changeset.
+func (s *LimitAlertsService) UpdateLimitAlerts(ctx context.Context, orgID influxdb.ID, upd influxdb.LimitAlertsUpdate) (*influxdb.LimitAlerts, error) {
+	var res limitAlertsResponse
+	err := s.Client.
+		PatchJSON(upd, prefixLimitAlerts).
+		QueryParams([2]string{"orgID", orgID.String()}).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.LimitAlerts, nil
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LimitListener is a net.Listener which serves at most a maximum number of
+// connections at once. A connection accepted while the limit is reached waits
+// up to a queue timeout for another connection to close, and is closed if none
+// does, so a storm of reconnecting clients is shed quickly rather than
+// exhausting the file descriptors and memory of the server.
+type LimitListener struct {
+	net.Listener
+
+	sem          chan struct{}
+	queueTimeout time.Duration
+
+	done      chan struct{}
+	closeOnce sync.Once
+
+	active   prometheus.Gauge
+	queued   prometheus.Gauge
+	accepted prometheus.Counter
+	rejected prometheus.Counter
+}
+
+// NewLimitListener returns a LimitListener serving at most maxConns
+// connections of ln at once. A connection over the limit waits up to
+// queueTimeout for a slot before it is rejected. If queueTimeout is 0, it is
+// rejected immediately.
+func NewLimitListener(ln net.Listener, maxConns int, queueTimeout time.Duration) *LimitListener {
+	const namespace = "http"
+	const subsystem = "listener"
+
+	return &LimitListener{
+		Listener:     ln,
+		sem:          make(chan struct{}, maxConns),
+		queueTimeout: queueTimeout,
+		done:         make(chan struct{}),
+		active: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "connections_active",
+			Help:      "Number of connections being served",
+		}),
+		queued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "connections_queued",
+			Help:      "Number of accepted connections waiting for the number of connections served to drop below the limit",
+		}),
+		accepted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "connections_accepted_total",
+			Help:      "Number of connections accepted and served",
+		}),
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "connections_rejected_total",
+			Help:      "Number of connections closed without being served because the connection limit was reached",
+		}),
+	}
+}
+
+// Accept waits for and returns the next connection which may be served.
+func (l *LimitListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.acquire() {
+			return l.serve(c), nil
+		}
+		l.rejected.Inc()
+		c.Close()
+	}
+}
+
+// acquire takes a connection slot, waiting up to the queue timeout for one
+// to be released. It returns false if no slot was taken.
+func (l *LimitListener) acquire() bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+	}
+	if l.queueTimeout <= 0 {
+		return false
+	}
+
+	l.queued.Inc()
+	defer l.queued.Dec()
+
+	t := time.NewTimer(l.queueTimeout)
+	defer t.Stop()
+
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-t.C:
+		return false
+	case <-l.done:
+		return false
+	}
+}
+
+func (l *LimitListener) serve(c net.Conn) net.Conn {
+	l.accepted.Inc()
+	l.active.Inc()
+	return &limitListenerConn{Conn: c, release: l.release}
+}
+
+func (l *LimitListener) release() {
+	l.active.Dec()
+	<-l.sem
+}
+
+// Close closes the listener. A connection waiting for a slot is rejected.
+func (l *LimitListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+// PrometheusCollectors satisifies prom.PrometheusCollector.
+func (l *LimitListener) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		l.active,
+		l.queued,
+		l.accepted,
+		l.rejected,
+	}
+}
+
+// limitListenerConn releases its connection slot when it is closed.
+type limitListenerConn struct {
+	net.Conn
+	releaseOnce sync.Once
+	release     func()
+}
+
+func (c *limitListenerConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
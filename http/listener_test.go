@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/prom"
+	"github.com/influxdata/influxdb/v2/kit/prom/promtest"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLimitListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLimitListener(ln, 1, 50*time.Millisecond)
+	defer l.Close()
+
+	reg := prom.NewRegistry(zaptest.NewLogger(t))
+	reg.MustRegister(l.PrometheusCollectors()...)
+
+	accepted := make(chan net.Conn)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				close(accepted)
+				return
+			}
+			accepted <- c
+		}
+	}()
+
+	dial := func() net.Conn {
+		c, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	c1 := dial()
+	defer c1.Close()
+	s1 := <-accepted
+
+	// The second connection is over the limit and is rejected once it has
+	// waited for the queue timeout.
+	c2 := dial()
+	defer c2.Close()
+	c2.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c2.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected connection over the limit to be closed")
+	} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Fatal("expected connection over the limit to be closed, timed out instead")
+	}
+
+	// Once the first connection is closed, another one is served.
+	s1.Close()
+	c3 := dial()
+	defer c3.Close()
+	select {
+	case s3 := <-accepted:
+		s3.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected connection to be accepted")
+	}
+
+	mfs := promtest.MustGather(t, reg)
+	for name, exp := range map[string]float64{
+		"http_listener_connections_accepted_total": 2,
+		"http_listener_connections_rejected_total": 1,
+	} {
+		if got := promtest.MustFindMetric(t, mfs, name, nil).GetCounter().GetValue(); got != exp {
+			t.Errorf("unexpected %s: got %v, exp %v", name, got, exp)
+		}
+	}
+	for name, exp := range map[string]float64{
+		"http_listener_connections_active": 0,
+		"http_listener_connections_queued": 0,
+	} {
+		if got := promtest.MustFindMetric(t, mfs, name, nil).GetGauge().GetValue(); got != exp {
+			t.Errorf("unexpected %s: got %v, exp %v", name, got, exp)
+		}
+	}
+}
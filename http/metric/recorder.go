@@ -2,8 +2,11 @@ package metric
 
 import (
 	"context"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/prom"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // EventRecorder records meta-data associated with http requests.
@@ -18,6 +21,13 @@ type Event struct {
 	RequestBytes  int
 	ResponseBytes int
 	Status        int
+
+	// BucketID is the bucket written to by a write request.
+	BucketID influxdb.ID
+	// Points is the number of points of a write request.
+	Points int
+	// Duration is the time taken to serve the request.
+	Duration time.Duration
 }
 
 // NopEventRecorder never records events.
@@ -25,3 +35,25 @@ type NopEventRecorder struct{}
 
 // Record never records events.
 func (n *NopEventRecorder) Record(ctx context.Context, e Event) {}
+
+// EventRecorders records events with each of its recorders.
+type EventRecorders []EventRecorder
+
+// Record records the event with each recorder.
+func (rs EventRecorders) Record(ctx context.Context, e Event) {
+	for _, r := range rs {
+		r.Record(ctx, e)
+	}
+}
+
+// PrometheusCollectors returns the collectors of the recorders which are
+// prometheus collectors.
+func (rs EventRecorders) PrometheusCollectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	for _, r := range rs {
+		if pc, ok := r.(prom.PrometheusCollector); ok {
+			collectors = append(collectors, pc.PrometheusCollectors()...)
+		}
+	}
+	return collectors
+}
@@ -134,6 +134,9 @@ var blacklistEndpoints = map[string]isValidMethodFn{
 	usersPasswordPath:                ignoreMethod(),
 	"/api/v2/packages/apply":         ignoreMethod(),
 	prefixWrite:                      ignoreMethod("POST"),
+	prefixWriteCSV:                   ignoreMethod("POST"),
+	prefixWriteJSON:                  ignoreMethod("POST"),
+	OTLPMetricsPath:                  ignoreMethod("POST"),
 	organizationsIDSecretsPath:       ignoreMethod("PATCH"),
 	organizationsIDSecretsDeletePath: ignoreMethod("POST"),
 	prefixSetup:                      ignoreMethod("POST"),
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// OrgDeletionBackend is all services and associated parameters required to construct
+// the OrgDeletionHandler.
+type OrgDeletionBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	OrgDeletionService influxdb.OrgDeletionService
+}
+
+// NewOrgDeletionBackend returns a new instance of OrgDeletionBackend.
+func NewOrgDeletionBackend(log *zap.Logger, b *APIBackend) *OrgDeletionBackend {
+	return &OrgDeletionBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		log:                log,
+		OrgDeletionService: b.OrgDeletionService,
+	}
+}
+
+// OrgDeletionHandler represents an HTTP API handler for organization deletions.
+type OrgDeletionHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	OrgDeletionService influxdb.OrgDeletionService
+}
+
+const (
+	prefixOrgDeletions = "/api/v2/orgdeletions"
+	orgDeletionsIDPath = "/api/v2/orgdeletions/:id"
+)
+
+// NewOrgDeletionHandler returns a new instance of OrgDeletionHandler.
+func NewOrgDeletionHandler(log *zap.Logger, b *OrgDeletionBackend) *OrgDeletionHandler {
+	h := &OrgDeletionHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		OrgDeletionService: b.OrgDeletionService,
+	}
+
+	h.HandlerFunc("POST", prefixOrgDeletions, h.handlePostOrgDeletion)
+	h.HandlerFunc("GET", prefixOrgDeletions, h.handleGetOrgDeletions)
+	h.HandlerFunc("GET", orgDeletionsIDPath, h.handleGetOrgDeletion)
+
+	return h
+}
+
+type orgDeletionResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.OrgDeletion
+}
+
+func newOrgDeletionResponse(d *influxdb.OrgDeletion) *orgDeletionResponse {
+	return &orgDeletionResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/orgdeletions/%s", d.ID),
+		},
+		OrgDeletion: *d,
+	}
+}
+
+type orgDeletionsResponse struct {
+	Links        map[string]string      `json:"links"`
+	OrgDeletions []*orgDeletionResponse `json:"orgDeletions"`
+}
+
+func newOrgDeletionsResponse(ds []*influxdb.OrgDeletion) *orgDeletionsResponse {
+	res := &orgDeletionsResponse{
+		Links: map[string]string{
+			"self": prefixOrgDeletions,
+		},
+		OrgDeletions: make([]*orgDeletionResponse, 0, len(ds)),
+	}
+	for _, d := range ds {
+		res.OrgDeletions = append(res.OrgDeletions, newOrgDeletionResponse(d))
+	}
+	return res
+}
+
+type postOrgDeletionRequest struct {
+	OrgID influxdb.ID `json:"orgID"`
+}
+
+// handlePostOrgDeletion is the HTTP handler for the POST /api/v2/orgdeletions route.
+func (h *OrgDeletionHandler) handlePostOrgDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postOrgDeletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode organization deletion request",
+			Err:  err,
+		}, w)
+		return
+	}
+	if !req.OrgID.Valid() {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "organization ID is required",
+		}, w)
+		return
+	}
+
+	d, err := h.OrgDeletionService.StartOrgDeletion(ctx, req.OrgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Organization deletion started", zap.String("orgDeletion", fmt.Sprint(d)))
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, newOrgDeletionResponse(d)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetOrgDeletionsRequest(r *http.Request) (*influxdb.OrgDeletionFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.OrgDeletionFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("status"); v != "" {
+		status := influxdb.OrgDeletionStatus(v)
+		filter.Status = &status
+	}
+	return filter, nil
+}
+
+// handleGetOrgDeletions is the HTTP handler for the GET /api/v2/orgdeletions route.
+func (h *OrgDeletionHandler) handleGetOrgDeletions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetOrgDeletionsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ds, err := h.OrgDeletionService.FindOrgDeletions(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Organization deletions retrieved", zap.String("orgDeletions", fmt.Sprint(ds)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newOrgDeletionsResponse(ds)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetOrgDeletion is the HTTP handler for the GET /api/v2/orgdeletions/:id route.
+func (h *OrgDeletionHandler) handleGetOrgDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	d, err := h.OrgDeletionService.FindOrgDeletionByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Organization deletion retrieved", zap.String("orgDeletion", fmt.Sprint(d)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newOrgDeletionResponse(d)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// OrgDeletionService connects to Influx via HTTP using tokens to delete organizations.
+type OrgDeletionService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.OrgDeletionService = (*OrgDeletionService)(nil)
+
+// StartOrgDeletion starts deleting the organization in the background.
+func (s *OrgDeletionService) StartOrgDeletion(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgDeletion, error) {
+	var dr orgDeletionResponse
+	err := s.Client.
+		PostJSON(postOrgDeletionRequest{OrgID: orgID}, prefixOrgDeletions).
+		DecodeJSON(&dr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dr.OrgDeletion, nil
+}
+
+// FindOrgDeletionByID returns a single organization deletion by ID.
+func (s *OrgDeletionService) FindOrgDeletionByID(ctx context.Context, id influxdb.ID) (*influxdb.OrgDeletion, error) {
+	var dr orgDeletionResponse
+	err := s.Client.
+		Get(path.Join(prefixOrgDeletions, id.String())).
+		DecodeJSON(&dr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dr.OrgDeletion, nil
+}
+
+// FindOrgDeletions returns a list of organization deletions that match filter.
+func (s *OrgDeletionService) FindOrgDeletions(ctx context.Context, filter influxdb.OrgDeletionFilter) ([]*influxdb.OrgDeletion, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Status != nil {
+		params = append(params, [2]string{"status", string(*filter.Status)})
+	}
+
+	var dr orgDeletionsResponse
+	err := s.Client.
+		Get(prefixOrgDeletions).
+		QueryParams(params...).
+		DecodeJSON(&dr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := make([]*influxdb.OrgDeletion, 0, len(dr.OrgDeletions))
+	for _, d := range dr.OrgDeletions {
+		ds = append(ds, &d.OrgDeletion)
+	}
+	return ds, nil
+}
@@ -0,0 +1,359 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// OrgDomainBackend is all services and associated parameters required to construct
+// the OrgDomainHandler.
+type OrgDomainBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	OrgDomainService influxdb.OrgDomainService
+}
+
+// NewOrgDomainBackend returns a new instance of OrgDomainBackend.
+func NewOrgDomainBackend(log *zap.Logger, b *APIBackend) *OrgDomainBackend {
+	return &OrgDomainBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		OrgDomainService: b.OrgDomainService,
+	}
+}
+
+// OrgDomainHandler represents an HTTP API handler for the subdomains which
+// scope requests to organizations.
+type OrgDomainHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	OrgDomainService influxdb.OrgDomainService
+}
+
+const (
+	prefixOrgDomains        = "/api/v2/orgDomains"
+	orgDomainsSubdomainPath = "/api/v2/orgDomains/:subdomain"
+)
+
+// NewOrgDomainHandler returns a new instance of OrgDomainHandler.
+func NewOrgDomainHandler(log *zap.Logger, b *OrgDomainBackend) *OrgDomainHandler {
+	h := &OrgDomainHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		OrgDomainService: b.OrgDomainService,
+	}
+
+	h.HandlerFunc("POST", prefixOrgDomains, h.handlePostOrgDomain)
+	h.HandlerFunc("GET", prefixOrgDomains, h.handleGetOrgDomains)
+	h.HandlerFunc("GET", orgDomainsSubdomainPath, h.handleGetOrgDomain)
+	h.HandlerFunc("DELETE", orgDomainsSubdomainPath, h.handleDeleteOrgDomain)
+
+	return h
+}
+
+type orgDomainResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.OrgDomain
+}
+
+func newOrgDomainResponse(d *influxdb.OrgDomain) *orgDomainResponse {
+	return &orgDomainResponse{
+		Links: map[string]string{
+			"self": path.Join(prefixOrgDomains, d.Subdomain),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", d.OrgID),
+		},
+		OrgDomain: *d,
+	}
+}
+
+type orgDomainsResponse struct {
+	Links      map[string]string    `json:"links"`
+	OrgDomains []*orgDomainResponse `json:"orgDomains"`
+}
+
+func newOrgDomainsResponse(ds []*influxdb.OrgDomain) *orgDomainsResponse {
+	res := &orgDomainsResponse{
+		Links: map[string]string{
+			"self": prefixOrgDomains,
+		},
+		OrgDomains: make([]*orgDomainResponse, 0, len(ds)),
+	}
+	for _, d := range ds {
+		res.OrgDomains = append(res.OrgDomains, newOrgDomainResponse(d))
+	}
+	return res
+}
+
+type postOrgDomainRequest struct {
+	Subdomain string      `json:"subdomain"`
+	OrgID     influxdb.ID `json:"orgID"`
+}
+
+// handlePostOrgDomain is the HTTP handler for the POST /api/v2/orgDomains route.
+func (h *OrgDomainHandler) handlePostOrgDomain(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postOrgDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode org domain request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	d := &influxdb.OrgDomain{
+		Subdomain: strings.ToLower(req.Subdomain),
+		OrgID:     req.OrgID,
+	}
+	if err := d.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.OrgDomainService.CreateOrgDomain(ctx, d); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Org domain created", zap.String("orgDomain", fmt.Sprint(d)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newOrgDomainResponse(d)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetOrgDomainsRequest(r *http.Request) (*influxdb.OrgDomainFilter, error) {
+	filter := &influxdb.OrgDomainFilter{}
+	if v := r.URL.Query().Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	return filter, nil
+}
+
+// handleGetOrgDomains is the HTTP handler for the GET /api/v2/orgDomains route.
+func (h *OrgDomainHandler) handleGetOrgDomains(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetOrgDomainsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ds, err := h.OrgDomainService.FindOrgDomains(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Org domains retrieved", zap.String("orgDomains", fmt.Sprint(ds)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newOrgDomainsResponse(ds)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetOrgDomain is the HTTP handler for the GET /api/v2/orgDomains/:subdomain route.
+func (h *OrgDomainHandler) handleGetOrgDomain(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	subdomain := httprouter.ParamsFromContext(ctx).ByName("subdomain")
+
+	d, err := h.OrgDomainService.FindOrgDomain(ctx, subdomain)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Org domain retrieved", zap.String("orgDomain", fmt.Sprint(d)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newOrgDomainResponse(d)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteOrgDomain is the HTTP handler for the DELETE /api/v2/orgDomains/:subdomain route.
+func (h *OrgDomainHandler) handleDeleteOrgDomain(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	subdomain := httprouter.ParamsFromContext(ctx).ByName("subdomain")
+
+	if err := h.OrgDomainService.DeleteOrgDomain(ctx, subdomain); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Org domain deleted", zap.String("subdomain", subdomain))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// OrgDomainMW scopes the requests made to a subdomain of domain, such as
+// org1 of org1.example.com for the domain example.com, to the organization
+// the subdomain is mapped to. The org and orgID query parameters of a
+// request are set to the name and ID of the organization, and the request is
+// forbidden if it names another. Requests to the domain itself, and to subdomains which are
+// not mapped, are served as they are.
+func OrgDomainMW(domain string, domains influxdb.OrgDomainService, orgs influxdb.OrganizationService, errorHandler influxdb.HTTPErrorHandler) kithttp.Middleware {
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			host := r.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			host = strings.ToLower(strings.TrimSuffix(host, "."))
+			subdomain := strings.TrimSuffix(host, suffix)
+			if subdomain == host || subdomain == "" || strings.Contains(subdomain, ".") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			d, err := domains.FindOrgDomain(ctx, subdomain)
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				errorHandler.HandleHTTPError(ctx, err, w)
+				return
+			}
+
+			if err := scopeOrgDomainRequest(ctx, r, d.OrgID, orgs); err != nil {
+				errorHandler.HandleHTTPError(ctx, err, w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// scopeOrgDomainRequest sets the org query parameter of r to the name of the
+// organization orgID and its orgID query parameter to orgID, as handlers read
+// org as a name. It returns an error if r names another organization.
+func scopeOrgDomainRequest(ctx context.Context, r *http.Request, orgID influxdb.ID, orgs influxdb.OrganizationService) error {
+	errOtherOrg := &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  "request names an organization other than the organization of its subdomain",
+	}
+
+	qp := r.URL.Query()
+	if v := qp.Get(OrgID); v != "" && v != orgID.String() {
+		return errOtherOrg
+	}
+	var org *influxdb.Organization
+	if v := qp.Get(Org); v != "" && v != orgID.String() {
+		o, err := orgs.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &v})
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		if o == nil || o.ID != orgID {
+			return errOtherOrg
+		}
+		org = o
+	}
+	if org == nil {
+		o, err := orgs.FindOrganizationByID(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		org = o
+	}
+
+	qp.Set(Org, org.Name)
+	qp.Set(OrgID, orgID.String())
+	r.URL.RawQuery = qp.Encode()
+	return nil
+}
+
+// OrgDomainService connects to Influx via HTTP using tokens to manage the
+// subdomains of organizations.
+type OrgDomainService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.OrgDomainService = (*OrgDomainService)(nil)
+
+// FindOrgDomain returns the mapping of a subdomain.
+func (s *OrgDomainService) FindOrgDomain(ctx context.Context, subdomain string) (*influxdb.OrgDomain, error) {
+	var res orgDomainResponse
+	err := s.Client.
+		Get(prefixOrgDomains, subdomain).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.OrgDomain, nil
+}
+
+// FindOrgDomains returns the mappings matching filter, by subdomain.
+func (s *OrgDomainService) FindOrgDomains(ctx context.Context, filter influxdb.OrgDomainFilter) ([]*influxdb.OrgDomain, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+
+	var res orgDomainsResponse
+	err := s.Client.
+		Get(prefixOrgDomains).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := make([]*influxdb.OrgDomain, 0, len(res.OrgDomains))
+	for _, d := range res.OrgDomains {
+		ds = append(ds, &d.OrgDomain)
+	}
+	return ds, nil
+}
+
+// CreateOrgDomain maps a subdomain to an organization.
+func (s *OrgDomainService) CreateOrgDomain(ctx context.Context, d *influxdb.OrgDomain) error {
+	var res orgDomainResponse
+	err := s.Client.
+		PostJSON(postOrgDomainRequest{
+			Subdomain: d.Subdomain,
+			OrgID:     d.OrgID,
+		}, prefixOrgDomains).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*d = res.OrgDomain
+	return nil
+}
+
+// DeleteOrgDomain removes the mapping of a subdomain.
+func (s *OrgDomainService) DeleteOrgDomain(ctx context.Context, subdomain string) error {
+	return s.Client.
+		Delete(prefixOrgDomains, subdomain).
+		Do(ctx)
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+type orgDomainsMap map[string]influxdb.ID
+
+func (m orgDomainsMap) FindOrgDomain(ctx context.Context, subdomain string) (*influxdb.OrgDomain, error) {
+	id, ok := m[subdomain]
+	if !ok {
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	return &influxdb.OrgDomain{Subdomain: subdomain, OrgID: id}, nil
+}
+
+func (m orgDomainsMap) FindOrgDomains(ctx context.Context, filter influxdb.OrgDomainFilter) ([]*influxdb.OrgDomain, error) {
+	return nil, nil
+}
+
+func (m orgDomainsMap) CreateOrgDomain(ctx context.Context, d *influxdb.OrgDomain) error {
+	return nil
+}
+
+func (m orgDomainsMap) DeleteOrgDomain(ctx context.Context, subdomain string) error {
+	return nil
+}
+
+func TestOrgDomainMW(t *testing.T) {
+	orgID := influxdb.ID(0x0a)
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		if filter.Name != nil && *filter.Name == "org1" {
+			return &influxdb.Organization{ID: orgID, Name: "org1"}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+		if id == orgID {
+			return &influxdb.Organization{ID: orgID, Name: "org1"}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	mw := OrgDomainMW("example.com", orgDomainsMap{"org1": orgID}, orgs, kithttp.ErrorHandler(0))
+
+	tests := []struct {
+		name      string
+		host      string
+		query     string
+		wantCode  int
+		wantQuery string
+	}{
+		{name: "scoped", host: "org1.example.com", query: "bucket=b", wantCode: http.StatusOK, wantQuery: "bucket=b&org=org1&orgID=000000000000000a"},
+		{name: "case and port", host: "ORG1.Example.com:8086", wantCode: http.StatusOK, wantQuery: "org=org1&orgID=000000000000000a"},
+		{name: "same org by name", host: "org1.example.com", query: "org=org1", wantCode: http.StatusOK, wantQuery: "org=org1&orgID=000000000000000a"},
+		{name: "other org by name", host: "org1.example.com", query: "org=org2", wantCode: http.StatusForbidden},
+		{name: "other org by ID", host: "org1.example.com", query: "orgID=000000000000000b", wantCode: http.StatusForbidden},
+		{name: "unmapped subdomain", host: "org2.example.com", query: "org=org2", wantCode: http.StatusOK, wantQuery: "org=org2"},
+		{name: "bare domain", host: "example.com", query: "org=org2", wantCode: http.StatusOK, wantQuery: "org=org2"},
+		{name: "nested subdomain", host: "a.org1.example.com", wantCode: http.StatusOK},
+		{name: "other domain", host: "org1.example.org", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotQuery string
+			h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotQuery = r.URL.RawQuery
+			}))
+
+			r := httptest.NewRequest("GET", "/api/v2/buckets?"+tt.query, nil)
+			r.Host = tt.host
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if gotQuery != tt.wantQuery {
+				t.Errorf("got query %q, want %q", gotQuery, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestOrgDomainMW_BucketHandler(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range []*influxdb.Organization{
+		{ID: 0x0a, Name: "org1"},
+		{ID: 0x0b, Name: "org2"},
+	} {
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, b := range []*influxdb.Bucket{
+		{ID: 0x100, OrgID: 0x0a, Name: "b1"},
+		{ID: 0x101, OrgID: 0x0b, Name: "b2"},
+	} {
+		if err := svc.PutBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backend := NewMockBucketBackend(t)
+	backend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	backend.BucketService = svc
+	backend.OrganizationService = svc
+	mw := OrgDomainMW("example.com", orgDomainsMap{"org1": 0x0a}, svc, kithttp.ErrorHandler(0))
+	h := mw(NewBucketHandler(zaptest.NewLogger(t), backend))
+
+	for _, query := range []string{"", "?org=org1", "?orgID=000000000000000a"} {
+		r := httptest.NewRequest("GET", "/api/v2/buckets"+query, nil)
+		r.Host = "org1.example.com"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: got status %d, want 200: %s", query, w.Code, w.Body.String())
+		}
+
+		var res struct {
+			Buckets []struct {
+				Name string `json:"name"`
+			} `json:"buckets"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, b := range res.Buckets {
+			// Skip the mocked system buckets listed with the buckets of
+			// every organization.
+			if !strings.HasPrefix(b.Name, "_") {
+				names = append(names, b.Name)
+			}
+		}
+		if want := []string{"b1"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("%q: got buckets %v, want %v", query, names, want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// ParquetExportBackend is all services and associated parameters required to construct
+// the ParquetExportHandler.
+type ParquetExportBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ParquetExportService influxdb.ParquetExportService
+}
+
+// NewParquetExportBackend returns a new instance of ParquetExportBackend.
+func NewParquetExportBackend(log *zap.Logger, b *APIBackend) *ParquetExportBackend {
+	return &ParquetExportBackend{
+		HTTPErrorHandler:     b.HTTPErrorHandler,
+		log:                  log,
+		ParquetExportService: b.ParquetExportService,
+	}
+}
+
+// ParquetExportHandler represents an HTTP API handler for exporting buckets as Parquet files.
+type ParquetExportHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ParquetExportService influxdb.ParquetExportService
+}
+
+const (
+	prefixParquetExport           = "/api/v2/export/parquet"
+	parquetExportMeasurementsPath = "/api/v2/export/parquet/measurements"
+
+	parquetContentType = "application/vnd.apache.parquet"
+
+	// parquetExportRowsTrailer is the trailer of an export with the number
+	// of rows exported. It is missing if the export failed after it started
+	// writing the file.
+	parquetExportRowsTrailer = "X-Influxdb-Export-Rows"
+)
+
+// NewParquetExportHandler returns a new instance of ParquetExportHandler.
+func NewParquetExportHandler(log *zap.Logger, b *ParquetExportBackend) *ParquetExportHandler {
+	h := &ParquetExportHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		ParquetExportService: b.ParquetExportService,
+	}
+
+	h.HandlerFunc("GET", prefixParquetExport, h.handleGetParquetExport)
+	h.HandlerFunc("GET", parquetExportMeasurementsPath, h.handleGetParquetExportMeasurements)
+
+	return h
+}
+
+type parquetExportRequest struct {
+	OrgID       influxdb.ID
+	BucketID    influxdb.ID
+	Measurement string
+	Start, Stop int64
+}
+
+func decodeParquetExportRequest(r *http.Request) (*parquetExportRequest, error) {
+	qp := r.URL.Query()
+	req := &parquetExportRequest{Measurement: qp.Get("measurement")}
+	if err := req.OrgID.DecodeFromString(qp.Get("orgID")); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	if err := req.BucketID.DecodeFromString(qp.Get("bucketID")); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		}
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, qp.Get("start"))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid RFC3339Nano for field start, please format your time with RFC3339Nano format, example: 2009-01-02T23:00:00Z",
+		}
+	}
+	stop, err := time.Parse(time.RFC3339Nano, qp.Get("stop"))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid RFC3339Nano for field stop, please format your time with RFC3339Nano format, example: 2009-01-01T23:00:00Z",
+		}
+	}
+	if !start.Before(stop) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "start must be before stop",
+		}
+	}
+	req.Start, req.Stop = start.UnixNano(), stop.UnixNano()
+	return req, nil
+}
+
+// writeCounter counts the bytes written to a response, to know if an error
+// can still be returned to the client.
+type writeCounter struct {
+	w http.ResponseWriter
+	n int64
+}
+
+func (c *writeCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// handleGetParquetExport is the HTTP handler for the GET /api/v2/export/parquet route.
+func (h *ParquetExportHandler) handleGetParquetExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeParquetExportRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if req.Measurement == "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "measurement is required",
+		}, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", parquetContentType)
+	w.Header().Set("Trailer", parquetExportRowsTrailer)
+	cw := &writeCounter{w: w}
+	rows, err := h.ParquetExportService.ExportParquet(ctx, cw, req.OrgID, req.BucketID, req.Measurement, req.Start, req.Stop)
+	if err == influxdb.ErrNoExportData {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		if cw.n == 0 {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		// The file is truncated, which the client detects as the trailer
+		// is missing.
+		h.log.Info("Failed to export measurement", zap.String("measurement", req.Measurement), zap.Error(err))
+		return
+	}
+	w.Header().Set(parquetExportRowsTrailer, strconv.FormatInt(rows, 10))
+	h.log.Debug("Measurement exported", zap.String("measurement", req.Measurement), zap.Int64("rows", rows))
+}
+
+type parquetExportMeasurementsResponse struct {
+	Measurements []string `json:"measurements"`
+}
+
+// handleGetParquetExportMeasurements is the HTTP handler for the GET /api/v2/export/parquet/measurements route.
+func (h *ParquetExportHandler) handleGetParquetExportMeasurements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeParquetExportRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	names, err := h.ParquetExportService.FindExportMeasurements(ctx, req.OrgID, req.BucketID, req.Start, req.Stop)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if names == nil {
+		names = []string{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, parquetExportMeasurementsResponse{Measurements: names}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// ParquetExportService connects to Influx via HTTP using tokens to export buckets as Parquet files.
+type ParquetExportService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.ParquetExportService = (*ParquetExportService)(nil)
+
+func parquetExportParams(orgID, bucketID influxdb.ID, start, end int64) [][2]string {
+	return [][2]string{
+		{"orgID", orgID.String()},
+		{"bucketID", bucketID.String()},
+		{"start", time.Unix(0, start).UTC().Format(time.RFC3339Nano)},
+		{"stop", time.Unix(0, end).UTC().Format(time.RFC3339Nano)},
+	}
+}
+
+// FindExportMeasurements returns the measurements of a bucket with data in [start, end).
+func (s *ParquetExportService) FindExportMeasurements(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64) ([]string, error) {
+	var res parquetExportMeasurementsResponse
+	err := s.Client.
+		Get(parquetExportMeasurementsPath).
+		QueryParams(parquetExportParams(orgID, bucketID, start, end)...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Measurements, nil
+}
+
+// ExportParquet writes the data of a measurement of a bucket in [start, end) to w as a Parquet file.
+func (s *ParquetExportService) ExportParquet(ctx context.Context, w io.Writer, orgID, bucketID influxdb.ID, measurement string, start, end int64) (int64, error) {
+	params := append(parquetExportParams(orgID, bucketID, start, end), [2]string{"measurement", measurement})
+	var rows int64
+	err := s.Client.
+		Get(prefixParquetExport).
+		QueryParams(params...).
+		Accept(parquetContentType).
+		RespFn(func(resp *http.Response) error {
+			if resp.StatusCode == http.StatusNoContent {
+				return influxdb.ErrNoExportData
+			}
+			return nil
+		}).
+		Decode(func(resp *http.Response) error {
+			if _, err := io.Copy(w, resp.Body); err != nil {
+				return err
+			}
+			// Trailers are only read once the body has been read.
+			v := resp.Trailer.Get(parquetExportRowsTrailer)
+			if v == "" {
+				return fmt.Errorf("export of measurement %q was interrupted", measurement)
+			}
+			var err error
+			rows, err = strconv.ParseInt(v, 10, 64)
+			return err
+		}).
+		Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
@@ -13,6 +13,9 @@ type PlatformHandler struct {
 	AssetHandler *AssetHandler
 	DocsHandler  http.HandlerFunc
 	APIHandler   http.Handler
+	// Branding is the branding of the UI, whose base path is removed from
+	// the requests before they are delegated.
+	Branding Branding
 }
 
 // NewPlatformHandler returns a platform handler that serves the API and associated assets.
@@ -23,6 +26,14 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.SessionService = b.SessionService
 	h.SessionRenewDisabled = b.SessionRenewDisabled
 	h.UserService = b.UserService
+	h.ResourceGrantService = b.ResourceGrantService
+	h.AuthorizationUsageService = b.AuthorizationUsageService
+	h.AuthorizationUsageInterval = b.AuthorizationUsageInterval
+	h.Authenticators = b.Authenticators
+	if h.Authenticators == nil {
+		// The default authenticators are all known.
+		h.Authenticators, _ = NewAuthenticators(b, DefaultAuthenticators...)
+	}
 
 	h.RegisterNoAuthRoute("GET", "/api/v2")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signin")
@@ -30,9 +41,28 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
+	h.RegisterNoAuthRoute("GET", prefixBranding)
+	h.RegisterNoAuthRoute("GET", prefixCapabilities)
+
+	// Nodes of a cluster authenticate with the shared cluster secret.
+	h.RegisterNoAuthRoute("GET", "/api/v2/cluster")
+	h.RegisterNoAuthRoute("POST", "/api/v2/cluster/join")
+	h.RegisterNoAuthRoute("POST", "/api/v2/cluster/apply")
+	h.RegisterNoAuthRoute("DELETE", "/api/v2/cluster/nodes/:id")
+
+	// Slack signs its interactions with the signing secret of the endpoint.
+	h.RegisterNoAuthRoute("POST", slackInteractionsIDPath)
+
+	// Legacy clients write and query the bucket of the dbrp mapping that
+	// their authorizations are scoped to.
+	h.RegisterDBRPRoute("POST", prefixQuery)
+	for _, path := range []string{prefixWrite, prefixWriteCSV, prefixWriteJSON, OTLPMetricsPath} {
+		h.RegisterDBRPRoute("POST", path)
+	}
 
 	assetHandler := NewAssetHandler()
 	assetHandler.Path = b.AssetsPath
+	assetHandler.Branding = b.Branding
 
 	wrappedHandler := kithttp.SetCORS(h)
 	wrappedHandler = kithttp.SkipOptions(wrappedHandler)
@@ -41,11 +71,14 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 		AssetHandler: assetHandler,
 		DocsHandler:  Redoc("/api/v2/swagger.json"),
 		APIHandler:   wrappedHandler,
+		Branding:     b.Branding,
 	}
 }
 
 // ServeHTTP delegates a request to the appropriate subhandler.
 func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = h.Branding.stripBasePath(r)
+
 	if strings.HasPrefix(r.URL.Path, "/docs") {
 		h.DocsHandler.ServeHTTP(w, r)
 		return
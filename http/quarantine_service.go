@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// BlockQuarantineBackend is all services and associated parameters required
+// to construct the BlockQuarantineHandler.
+type BlockQuarantineBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	BlockQuarantineService influxdb.BlockQuarantineService
+}
+
+// NewBlockQuarantineBackend returns a new instance of BlockQuarantineBackend.
+func NewBlockQuarantineBackend(b *APIBackend) *BlockQuarantineBackend {
+	return &BlockQuarantineBackend{
+		Logger: b.Logger.With(zap.String("handler", "quarantine")),
+
+		HTTPErrorHandler:       b.HTTPErrorHandler,
+		BlockQuarantineService: b.BlockQuarantineService,
+	}
+}
+
+// BlockQuarantineHandler is the http handler for the storage blocks
+// quarantined after failing checksum verification.
+type BlockQuarantineHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	BlockQuarantineService influxdb.BlockQuarantineService
+}
+
+const prefixQuarantine = "/api/v2/quarantine"
+
+// NewBlockQuarantineHandler creates a new handler at /api/v2/quarantine
+// listing the quarantined storage blocks.
+func NewBlockQuarantineHandler(b *BlockQuarantineBackend) *BlockQuarantineHandler {
+	h := &BlockQuarantineHandler{
+		HTTPErrorHandler:       b.HTTPErrorHandler,
+		Router:                 NewRouter(b.HTTPErrorHandler),
+		Logger:                 b.Logger,
+		BlockQuarantineService: b.BlockQuarantineService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixQuarantine, h.handleGetQuarantinedBlocks)
+
+	return h
+}
+
+type quarantinedBlocksResponse struct {
+	Blocks []*influxdb.QuarantinedBlock `json:"blocks"`
+}
+
+func decodeQuarantinedBlockFilter(r *http.Request) (influxdb.QuarantinedBlockFilter, error) {
+	qp := r.URL.Query()
+	var filter influxdb.QuarantinedBlockFilter
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("bucketID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}
+		}
+		filter.BucketID = id
+	}
+	return filter, nil
+}
+
+func (h *BlockQuarantineHandler) handleGetQuarantinedBlocks(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "BlockQuarantineHandler.handleGetQuarantinedBlocks")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeQuarantinedBlockFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	blocks, err := h.BlockQuarantineService.FindQuarantinedBlocks(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("Quarantined blocks retrieved", zap.Int("blocks", len(blocks)))
+
+	if blocks == nil {
+		blocks = []*influxdb.QuarantinedBlock{}
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, quarantinedBlocksResponse{Blocks: blocks}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// BlockQuarantineService is the client implementation of influxdb.BlockQuarantineService.
+type BlockQuarantineService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.BlockQuarantineService = (*BlockQuarantineService)(nil)
+
+// FindQuarantinedBlocks returns the quarantined storage blocks matching the filter.
+func (s *BlockQuarantineService) FindQuarantinedBlocks(ctx context.Context, filter influxdb.QuarantinedBlockFilter) ([]*influxdb.QuarantinedBlock, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.BucketID != nil {
+		params = append(params, [2]string{"bucketID", filter.BucketID.String()})
+	}
+
+	var res quarantinedBlocksResponse
+	err := s.Client.
+		Get(prefixQuarantine).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Blocks, nil
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+type findQuarantinedBlocksFunc func(ctx context.Context, filter influxdb.QuarantinedBlockFilter) ([]*influxdb.QuarantinedBlock, error)
+
+func (f findQuarantinedBlocksFunc) FindQuarantinedBlocks(ctx context.Context, filter influxdb.QuarantinedBlockFilter) ([]*influxdb.QuarantinedBlock, error) {
+	return f(ctx, filter)
+}
+
+func TestBlockQuarantineHandler(t *testing.T) {
+	block := &influxdb.QuarantinedBlock{
+		OrgID:         0x020f755c3c082000,
+		BucketID:      0x020f755c3c082001,
+		Measurement:   "cpu",
+		Tags:          map[string]string{"host": "a"},
+		Field:         "usage_user",
+		MinTime:       time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+		MaxTime:       time.Date(2020, 6, 1, 1, 0, 0, 0, time.UTC),
+		Path:          "/var/lib/influxdb/engine/data/000000001-000000001.tsm",
+		Offset:        5,
+		QuarantinedAt: time.Date(2020, 6, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	var got influxdb.QuarantinedBlockFilter
+	h := NewBlockQuarantineHandler(&BlockQuarantineBackend{
+		Logger:           zaptest.NewLogger(t),
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		BlockQuarantineService: findQuarantinedBlocksFunc(func(ctx context.Context, filter influxdb.QuarantinedBlockFilter) ([]*influxdb.QuarantinedBlock, error) {
+			got = filter
+			if filter.BucketID != nil && *filter.BucketID != block.BucketID {
+				return nil, nil
+			}
+			return []*influxdb.QuarantinedBlock{block}, nil
+		}),
+	})
+	server := httptest.NewServer(h)
+	defer server.Close()
+	client := &BlockQuarantineService{Client: mustNewHTTPClient(t, server.URL, "")}
+
+	blocks, err := client.FindQuarantinedBlocks(context.Background(), influxdb.QuarantinedBlockFilter{OrgID: &block.OrgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(blocks, []*influxdb.QuarantinedBlock{block}) {
+		t.Errorf("unexpected blocks %+v", blocks)
+	}
+	if got.OrgID == nil || *got.OrgID != block.OrgID || got.BucketID != nil {
+		t.Errorf("unexpected filter %+v", got)
+	}
+
+	other := influxdb.ID(1)
+	blocks, err = client.FindQuarantinedBlocks(context.Background(), influxdb.QuarantinedBlockFilter{BucketID: &other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks == nil || len(blocks) != 0 {
+		t.Errorf("expected an empty list of blocks, got %+v", blocks)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, prefixQuarantine+"?bucketID=bad", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid bucket ID to be rejected, got status code %d", w.Code)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/influxdata/influxdb/v2/jsonweb"
 	"github.com/influxdata/influxdb/v2/query"
 	transpiler "github.com/influxdata/influxdb/v2/query/influxql"
+	"github.com/influxdata/influxdb/v2/query/lint"
 	"github.com/influxdata/influxql"
 )
 
@@ -142,9 +143,11 @@ func (r QueryRequest) Validate() error {
 	return nil
 }
 
-// QueryAnalysis is a structured response of errors.
+// QueryAnalysis is a structured response of errors, and of the lint
+// findings of valid Flux queries.
 type QueryAnalysis struct {
-	Errors []queryParseError `json:"errors"`
+	Errors   []queryParseError `json:"errors"`
+	Findings []lint.Finding    `json:"findings,omitempty"`
 }
 
 type queryParseError struct {
@@ -173,6 +176,7 @@ func (r QueryRequest) analyzeFluxQuery() (*QueryAnalysis, error) {
 	errCount := ast.Check(pkg)
 	if errCount == 0 {
 		a.Errors = []queryParseError{}
+		a.Findings = lint.Lint(pkg)
 		return a, nil
 	}
 	a.Errors = make([]queryParseError, 0, errCount)
@@ -438,18 +442,26 @@ func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb
 		return nil, n, err
 	}
 
-	var token *influxdb.Authorization
+	token, err := queryAuthorization(auth, req.Org.ID)
+	if err != nil {
+		return pr, n, err
+	}
+
+	pr.Request.Authorization = token
+	return pr, n, nil
+}
+
+// queryAuthorization returns the authorization that the queries of auth run
+// with in the organization.
+func queryAuthorization(auth influxdb.Authorizer, orgID influxdb.ID) (*influxdb.Authorization, error) {
 	switch a := auth.(type) {
 	case *influxdb.Authorization:
-		token = a
+		return a, nil
 	case *influxdb.Session:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	case *jsonweb.Token:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	default:
-		return pr, n, influxdb.ErrAuthorizerNotSupported
+		return nil, influxdb.ErrAuthorizerNotSupported
 	}
-
-	pr.Request.Authorization = token
-	return pr, n, nil
 }
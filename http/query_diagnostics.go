@@ -0,0 +1,81 @@
+package http
+
+import (
+	"github.com/influxdata/flux"
+)
+
+// QueryDiagnosticsHeader is the header of a query request which opts in to
+// the diagnostics of the query. They are sent as JSON in the trailer of the
+// same name of the response, once the results have been written.
+const QueryDiagnosticsHeader = "X-Influx-Query-Diagnostics"
+
+// QueryDiagnostics are the statistics of the execution of a query, which
+// help explain why it is slow.
+type QueryDiagnostics struct {
+	// TotalDuration is the time spent on the query from its submission.
+	TotalDuration string `json:"totalDuration"`
+	// CompileDuration is the time spent compiling and planning the query.
+	CompileDuration string `json:"compileDuration"`
+	// QueueDuration is the time spent waiting for the query to be executed.
+	QueueDuration string `json:"queueDuration"`
+	// ExecuteDuration is the time spent executing the query.
+	ExecuteDuration string `json:"executeDuration"`
+	// MaxAllocated is the maximum number of bytes allocated by the query.
+	MaxAllocated int64 `json:"maxAllocated"`
+
+	// Pushdowns are the operations of the query performed by the storage
+	// engine while reading, such as range, filter, group or aggregates.
+	Pushdowns []string `json:"pushdowns"`
+
+	ScannedSeries int64 `json:"scannedSeries"`
+	ScannedValues int64 `json:"scannedValues"`
+	ScannedBytes  int64 `json:"scannedBytes"`
+	BlocksDecoded int64 `json:"blocksDecoded"`
+	BlocksSkipped int64 `json:"blocksSkipped"`
+
+	RuntimeErrors []string `json:"runtimeErrors,omitempty"`
+}
+
+// NewQueryDiagnostics returns the diagnostics of a query from its statistics.
+// The statistics reported by each storage read of the query are summed.
+func NewQueryDiagnostics(stats flux.Statistics) QueryDiagnostics {
+	d := QueryDiagnostics{
+		TotalDuration:   stats.TotalDuration.String(),
+		CompileDuration: stats.CompileDuration.String(),
+		QueueDuration:   stats.QueueDuration.String(),
+		ExecuteDuration: stats.ExecuteDuration.String(),
+		MaxAllocated:    stats.MaxAllocated,
+		Pushdowns:       []string{},
+		ScannedSeries:   sumMetadata(stats.Metadata, "influxdb/scanned-series"),
+		ScannedValues:   sumMetadata(stats.Metadata, "influxdb/scanned-values"),
+		ScannedBytes:    sumMetadata(stats.Metadata, "influxdb/scanned-bytes"),
+		BlocksDecoded:   sumMetadata(stats.Metadata, "influxdb/blocks-decoded"),
+		BlocksSkipped:   sumMetadata(stats.Metadata, "influxdb/blocks-skipped"),
+		RuntimeErrors:   stats.RuntimeErrors,
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range stats.Metadata["influxdb/pushdowns"] {
+		if p, ok := v.(string); ok && !seen[p] {
+			seen[p] = true
+			d.Pushdowns = append(d.Pushdowns, p)
+		}
+	}
+	return d
+}
+
+// sumMetadata returns the sum of the numbers of the metadata key.
+func sumMetadata(md flux.Metadata, key string) int64 {
+	var n int64
+	for _, v := range md[key] {
+		switch v := v.(type) {
+		case int:
+			n += int64(v)
+		case int64:
+			n += v
+		case float64:
+			n += int64(v)
+		}
+	}
+	return n
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/NYTimes/gziphandler"
@@ -48,6 +49,18 @@ type FluxBackend struct {
 
 	OrganizationService influxdb.OrganizationService
 	ProxyQueryService   query.ProxyQueryService
+	SignedQueryService  influxdb.SignedQueryService
+	QueryResultSpill    *QueryResultSpill
+	// QueryPolicyService and BucketService evaluate the query policies
+	// of the organizations for their queries, if set.
+	QueryPolicyService influxdb.QueryPolicyService
+	BucketService      influxdb.BucketService
+	// DBRPMappingService resolves the buckets that authorizations scoped to
+	// a dbrp may query.
+	DBRPMappingService influxdb.DBRPMappingService
+	// ResourceActivityService, if set, records the reads of the buckets
+	// of queries.
+	ResourceActivityService influxdb.ResourceActivityService
 }
 
 // NewFluxBackend returns a new instance of FluxBackend.
@@ -62,6 +75,13 @@ func NewFluxBackend(log *zap.Logger, b *APIBackend) *FluxBackend {
 			DefaultService:  b.FluxService,
 		},
 		OrganizationService: b.OrganizationService,
+		SignedQueryService:  b.SignedQueryService,
+		QueryResultSpill:    b.QueryResultSpill,
+		QueryPolicyService:  b.QueryPolicyService,
+		BucketService:       b.BucketService,
+		DBRPMappingService:  b.DBRPMappingService,
+
+		ResourceActivityService: b.ResourceActivityService,
 	}
 }
 
@@ -79,6 +99,13 @@ type FluxHandler struct {
 	Now                 func() time.Time
 	OrganizationService influxdb.OrganizationService
 	ProxyQueryService   query.ProxyQueryService
+	SignedQueryService  influxdb.SignedQueryService
+	QueryResultSpill    *QueryResultSpill
+	QueryPolicyService  influxdb.QueryPolicyService
+	BucketService       influxdb.BucketService
+	DBRPMappingService  influxdb.DBRPMappingService
+
+	ResourceActivityService influxdb.ResourceActivityService
 
 	EventRecorder metric.EventRecorder
 }
@@ -98,7 +125,14 @@ func NewFluxHandler(log *zap.Logger, b *FluxBackend) *FluxHandler {
 
 		ProxyQueryService:   b.ProxyQueryService,
 		OrganizationService: b.OrganizationService,
+		SignedQueryService:  b.SignedQueryService,
+		QueryResultSpill:    b.QueryResultSpill,
+		QueryPolicyService:  b.QueryPolicyService,
+		BucketService:       b.BucketService,
+		DBRPMappingService:  b.DBRPMappingService,
 		EventRecorder:       b.QueryEventRecorder,
+
+		ResourceActivityService: b.ResourceActivityService,
 	}
 
 	// query reponses can optionally be gzip encoded
@@ -108,6 +142,9 @@ func NewFluxHandler(log *zap.Logger, b *FluxBackend) *FluxHandler {
 	h.HandlerFunc("POST", "/api/v2/query/analyze", h.postQueryAnalyze)
 	h.HandlerFunc("GET", "/api/v2/query/suggestions", h.getFluxSuggestions)
 	h.HandlerFunc("GET", "/api/v2/query/suggestions/:name", h.getFluxSuggestion)
+	h.HandlerFunc("POST", prefixSignedQuery, h.handlePostSignedQuery)
+	h.Handler("GET", signedQueryPath, gziphandler.GzipHandler(http.HandlerFunc(h.handleGetSignedQuery)))
+	h.Handler("GET", queryResultsPath, gziphandler.GzipHandler(http.HandlerFunc(h.handleGetQueryResults)))
 	return h
 }
 
@@ -126,6 +163,7 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 	// Ideally this will be moved when we solve https://github.com/influxdata/influxdb/issues/13403
 	var orgID influxdb.ID
 	var requestBytes int
+	start := h.Now()
 	sw := kithttp.NewStatusResponseWriter(w)
 	w = sw
 	defer func() {
@@ -135,6 +173,7 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 			RequestBytes:  requestBytes,
 			ResponseBytes: sw.ResponseBytes(),
 			Status:        sw.Code(),
+			Duration:      h.Now().Sub(start),
 		})
 	}()
 
@@ -165,8 +204,36 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 	orgID = req.Request.OrganizationID
 	requestBytes = n
 
+	if err := h.authorizeDBRPQuery(ctx, req); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	pageSize, err := decodeQueryPageSize(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if pageSize > 0 && h.QueryResultSpill == nil {
+		h.HandleHTTPError(ctx, errQueryResultsDisabled, w)
+		return
+	}
+
 	// Transform the context into one with the request's authorization.
 	ctx = pcontext.SetAuthorizer(ctx, req.Request.Authorization)
+	if r.Header.Get(query.FederatedHeaderKey) != "" {
+		ctx = query.ContextWithFederated(ctx)
+	}
+	ctx, err = h.enforceQueryPolicies(ctx, r, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if h.ResourceActivityService != nil {
+		for _, id := range h.queryBucketIDs(ctx, req) {
+			recordResourceActivity(ctx, h.log, h.ResourceActivityService, influxdb.BucketsResourceType, id, influxdb.ResourceActivityRead)
+		}
+	}
 
 	hd, ok := req.Dialect.(HTTPDialect)
 	if !ok {
@@ -180,8 +247,46 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 	}
 	hd.SetHeaders(w)
 
+	diagnostics, _ := strconv.ParseBool(r.Header.Get(QueryDiagnosticsHeader))
+	if pageSize > 0 {
+		// The results are spilled and their first page written once the
+		// query completes, so the diagnostics are sent as a header.
+		id, stats, err := h.QueryResultSpill.spill(a.Identifier(), w.Header().Get("Content-Type"), pageSize,
+			func(w io.Writer) (flux.Statistics, error) {
+				return h.ProxyQueryService.Query(ctx, w, req)
+			})
+		if diagnostics {
+			if b, err := json.Marshal(NewQueryDiagnostics(stats)); err == nil {
+				w.Header().Set(QueryDiagnosticsHeader, string(b))
+			}
+		}
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		res, err := h.QueryResultSpill.find(id, a.Identifier())
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.writeQueryResultPage(ctx, w, id, res, 0, pageSize)
+		return
+	}
+	if diagnostics {
+		w.Header().Set("Trailer", QueryDiagnosticsHeader)
+	}
+
 	cw := iocounter.Writer{Writer: w}
-	if _, err := h.ProxyQueryService.Query(ctx, &cw, req); err != nil {
+	stats, err := h.ProxyQueryService.Query(ctx, &cw, req)
+	if diagnostics {
+		// The diagnostics are sent in the trailer, unless the query failed
+		// before writing results, in which case they are sent as a header
+		// of the error response.
+		if b, err := json.Marshal(NewQueryDiagnostics(stats)); err == nil {
+			w.Header().Set(QueryDiagnosticsHeader, string(b))
+		}
+	}
+	if err != nil {
 		if cw.Count() == 0 {
 			// Only record the error headers IFF nothing has been written to w.
 			h.HandleHTTPError(ctx, err, w)
@@ -642,6 +747,31 @@ func (s routingQueryService) Check(ctx context.Context) check.Response {
 	return response
 }
 
+// authorizeDBRPQuery ensures that a query made with an authorization scoped
+// to a dbrp is an InfluxQL query of the bucket of its dbrp mapping.
+func (h *FluxHandler) authorizeDBRPQuery(ctx context.Context, req *query.ProxyRequest) error {
+	a := req.Request.Authorization
+	if a == nil || a.DBRP == nil {
+		return nil
+	}
+
+	c, ok := req.Request.Compiler.(*influxql.Compiler)
+	if !ok {
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "dbrp scoped authorizations may only be used with influxql queries",
+		}
+	}
+	b, err := h.BucketService.FindBucket(ctx, influxdb.BucketFilter{
+		OrganizationID: &req.Request.OrganizationID,
+		Name:           &c.Bucket,
+	})
+	if err != nil {
+		return err
+	}
+	return authorizeDBRP(ctx, h.DBRPMappingService, a, influxdb.ReadAction, b.ID)
+}
+
 func (s routingQueryService) Query(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
 	if req.Request.Compiler.CompilerType() == influxql.CompilerType {
 		return s.InfluxQLService.Query(ctx, w, req)
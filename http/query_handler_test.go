@@ -449,6 +449,89 @@ func TestFluxHandler_PostQuery_Errors(t *testing.T) {
 	})
 }
 
+func TestFluxHandler_PostQuery_Diagnostics(t *testing.T) {
+	orgSVC := newInMemKVSVC(t)
+	org := influxdb.Organization{Name: t.Name()}
+	if err := orgSVC.CreateOrganization(context.Background(), &org); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &FluxBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		log:                 zaptest.NewLogger(t),
+		QueryEventRecorder:  noopEventRecorder{},
+		OrganizationService: orgSVC,
+		ProxyQueryService: &mock.ProxyQueryService{
+			QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+				if _, err := io.WriteString(w, "#datatype,string,long\r\n"); err != nil {
+					return flux.Statistics{}, err
+				}
+				return flux.Statistics{
+					TotalDuration:   3 * time.Second,
+					CompileDuration: time.Second,
+					ExecuteDuration: 2 * time.Second,
+					Metadata: flux.Metadata{
+						"influxdb/scanned-series": []interface{}{2, 3},
+						"influxdb/scanned-values": []interface{}{20, 30},
+						"influxdb/pushdowns":      []interface{}{"range", "filter", "range", "group", "count"},
+					},
+				}, nil
+			},
+		},
+	}
+	h := NewFluxHandler(zaptest.NewLogger(t), b)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.handleQuery(w, r.WithContext(icontext.SetAuthorizer(r.Context(), &influxdb.Authorization{})))
+	}))
+	defer ts.Close()
+
+	doQuery := func(diagnostics bool) *http.Response {
+		req, err := http.NewRequest("POST", ts.URL+"/api/v2/query?orgID="+org.ID.String(), strings.NewReader("buckets()"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/vnd.flux")
+		if diagnostics {
+			req.Header.Set(QueryDiagnosticsHeader, "true")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		// The trailer is only available once the body has been read.
+		if _, err := ioutil.ReadAll(resp.Body); err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code: %d", resp.StatusCode)
+		}
+		return resp
+	}
+
+	if got := doQuery(false).Trailer.Get(QueryDiagnosticsHeader); got != "" {
+		t.Errorf("unexpected diagnostics without opting in: %s", got)
+	}
+
+	var got QueryDiagnostics
+	if err := json.Unmarshal([]byte(doQuery(true).Trailer.Get(QueryDiagnosticsHeader)), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := QueryDiagnostics{
+		TotalDuration:   "3s",
+		CompileDuration: "1s",
+		QueueDuration:   "0s",
+		ExecuteDuration: "2s",
+		Pushdowns:       []string{"range", "filter", "group", "count"},
+		ScannedSeries:   5,
+		ScannedValues:   50,
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("unexpected diagnostics -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
 func TestFluxService_Query_gzip(t *testing.T) {
 	// orgService is just to mock out orgs by returning
 	// the same org every time.
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+// enforceQueryPolicies evaluates the query policies of the organization of
+// req for the query, recording the evaluation of the policy applied to it,
+// if any. It returns an error if the query is denied, and otherwise a
+// context limiting the rows of its results if a policy limits them.
+func (h *FluxHandler) enforceQueryPolicies(ctx context.Context, r *http.Request, req *query.ProxyRequest) (context.Context, error) {
+	if h.QueryPolicyService == nil || query.IsFederated(ctx) {
+		return ctx, nil
+	}
+
+	orgID := req.Request.OrganizationID
+	ps, err := h.QueryPolicyService.FindQueryPolicies(ctx, influxdb.QueryPolicyFilter{OrgID: &orgID})
+	if err != nil {
+		return ctx, err
+	}
+	if len(ps) == 0 {
+		return ctx, nil
+	}
+
+	pr := influxdb.QueryPolicyRequest{
+		OrgID:     orgID,
+		BucketIDs: h.queryBucketIDs(ctx, req),
+		RemoteIP:  remoteIP(r),
+	}
+	if a := req.Request.Authorization; a != nil {
+		pr.UserID = a.GetUserID()
+	}
+	d := influxdb.EvaluateQueryPolicies(ps, pr)
+	if !d.PolicyID.Valid() {
+		return ctx, nil
+	}
+
+	record := func(effect influxdb.QueryPolicyEffect, reason string) {
+		e := &influxdb.QueryPolicyEvaluation{
+			PolicyID:   d.PolicyID,
+			Effect:     effect,
+			Reason:     reason,
+			OrgID:      orgID,
+			UserID:     pr.UserID,
+			BucketIDs:  pr.BucketIDs,
+			RemoteAddr: r.RemoteAddr,
+		}
+		if c, ok := req.Request.Compiler.(lang.FluxCompiler); ok {
+			e.Query = c.Query
+		}
+		if err := h.QueryPolicyService.RecordQueryPolicyEvaluation(ctx, e); err != nil {
+			h.log.Error("Failed to record query policy evaluation",
+				zap.String("policy_id", d.PolicyID.String()),
+				zap.String("effect", string(effect)),
+				zap.Error(err))
+		}
+	}
+
+	if d.Effect == influxdb.QueryPolicyDeny {
+		record(influxdb.QueryPolicyDeny, "")
+		return ctx, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  fmt.Sprintf("query denied by query policy %s", d.PolicyID),
+		}
+	}
+	if d.MaxRows == 0 {
+		record(influxdb.QueryPolicyAllow, "")
+		return ctx, nil
+	}
+
+	record(influxdb.QueryPolicyAllow, fmt.Sprintf("rows limited to %d", d.MaxRows))
+	return query.ContextWithRowLimit(ctx, query.RowLimit{
+		Max: d.MaxRows,
+		Err: &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  fmt.Sprintf("query results exceed the %d rows allowed by query policy %s", d.MaxRows, d.PolicyID),
+		},
+		OnExceeded: func() {
+			record(influxdb.QueryPolicyDeny, fmt.Sprintf("rows exceeded %d", d.MaxRows))
+		},
+	}), nil
+}
+
+// queryBucketIDs returns the IDs of the buckets read by the Flux query of
+// req. The buckets of queries of other languages, of queries which cannot be
+// compiled, and of imported fragments, are unknown.
+func (h *FluxHandler) queryBucketIDs(ctx context.Context, req *query.ProxyRequest) []influxdb.ID {
+	var pkg *ast.Package
+	switch c := req.Request.Compiler.(type) {
+	case lang.FluxCompiler:
+		pkg = parser.ParseSource(c.Query)
+		if ast.Check(pkg) > 0 {
+			return nil
+		}
+	case lang.ASTCompiler:
+		pkg = c.AST
+	}
+	if pkg == nil {
+		return nil
+	}
+
+	orgID := req.Request.OrganizationID
+	filters, _, err := query.BucketsAccessed(pkg, &orgID)
+	if err != nil {
+		return nil
+	}
+
+	var ids []influxdb.ID
+	for _, f := range filters {
+		if f.ID != nil {
+			ids = append(ids, *f.ID)
+			continue
+		}
+		if f.Name == nil || h.BucketService == nil {
+			continue
+		}
+		b, err := h.BucketService.FindBucket(ctx, influxdb.BucketFilter{OrganizationID: &orgID, Name: f.Name})
+		if err == nil {
+			ids = append(ids, b.ID)
+		}
+	}
+	return ids
+}
+
+// remoteIP returns the IP address r was sent from, or nil if it is unknown.
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
@@ -0,0 +1,479 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// QueryPolicyBackend is all services and associated parameters required to construct
+// the QueryPolicyHandler.
+type QueryPolicyBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	QueryPolicyService influxdb.QueryPolicyService
+}
+
+// NewQueryPolicyBackend returns a new instance of QueryPolicyBackend.
+func NewQueryPolicyBackend(log *zap.Logger, b *APIBackend) *QueryPolicyBackend {
+	return &QueryPolicyBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		log:                log,
+		QueryPolicyService: b.QueryPolicyService,
+	}
+}
+
+// QueryPolicyHandler represents an HTTP API handler for the policies
+// allowing and denying queries, and their evaluations.
+type QueryPolicyHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	QueryPolicyService influxdb.QueryPolicyService
+}
+
+const (
+	prefixQueryPolicies          = "/api/v2/queryPolicies"
+	queryPoliciesIDPath          = "/api/v2/queryPolicies/:id"
+	prefixQueryPolicyEvaluations = "/api/v2/queryPolicyEvaluations"
+)
+
+// NewQueryPolicyHandler returns a new instance of QueryPolicyHandler.
+func NewQueryPolicyHandler(log *zap.Logger, b *QueryPolicyBackend) *QueryPolicyHandler {
+	h := &QueryPolicyHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		QueryPolicyService: b.QueryPolicyService,
+	}
+
+	h.HandlerFunc("POST", prefixQueryPolicies, h.handlePostQueryPolicy)
+	h.HandlerFunc("GET", prefixQueryPolicies, h.handleGetQueryPolicies)
+	h.HandlerFunc("GET", queryPoliciesIDPath, h.handleGetQueryPolicy)
+	h.HandlerFunc("PUT", queryPoliciesIDPath, h.handlePutQueryPolicy)
+	h.HandlerFunc("DELETE", queryPoliciesIDPath, h.handleDeleteQueryPolicy)
+	h.HandlerFunc("GET", prefixQueryPolicyEvaluations, h.handleGetQueryPolicyEvaluations)
+
+	return h
+}
+
+type queryPolicyResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.QueryPolicy
+}
+
+func newQueryPolicyResponse(p *influxdb.QueryPolicy) *queryPolicyResponse {
+	res := &queryPolicyResponse{
+		Links: map[string]string{
+			"self":        fmt.Sprintf("/api/v2/queryPolicies/%s", p.ID),
+			"evaluations": fmt.Sprintf("/api/v2/queryPolicyEvaluations?policyID=%s", p.ID),
+		},
+		QueryPolicy: *p,
+	}
+	if p.OrgID != nil {
+		res.Links["org"] = fmt.Sprintf("/api/v2/orgs/%s", p.OrgID)
+	}
+	return res
+}
+
+type queryPoliciesResponse struct {
+	Links         map[string]string      `json:"links"`
+	QueryPolicies []*queryPolicyResponse `json:"queryPolicies"`
+}
+
+func newQueryPoliciesResponse(ps []*influxdb.QueryPolicy) *queryPoliciesResponse {
+	res := &queryPoliciesResponse{
+		Links: map[string]string{
+			"self": prefixQueryPolicies,
+		},
+		QueryPolicies: make([]*queryPolicyResponse, 0, len(ps)),
+	}
+	for _, p := range ps {
+		res.QueryPolicies = append(res.QueryPolicies, newQueryPolicyResponse(p))
+	}
+	return res
+}
+
+type queryPolicyRequest struct {
+	OrgID           *influxdb.ID               `json:"orgID,omitempty"`
+	Name            string                     `json:"name"`
+	Description     string                     `json:"description,omitempty"`
+	Effect          influxdb.QueryPolicyEffect `json:"effect"`
+	BucketIDs       []influxdb.ID              `json:"bucketIDs,omitempty"`
+	Networks        []string                   `json:"networks,omitempty"`
+	OutsideNetworks bool                       `json:"outsideNetworks,omitempty"`
+	MaxRows         int64                      `json:"maxRows,omitempty"`
+}
+
+func newQueryPolicyRequest(p *influxdb.QueryPolicy) queryPolicyRequest {
+	return queryPolicyRequest{
+		OrgID:           p.OrgID,
+		Name:            p.Name,
+		Description:     p.Description,
+		Effect:          p.Effect,
+		BucketIDs:       p.BucketIDs,
+		Networks:        p.Networks,
+		OutsideNetworks: p.OutsideNetworks,
+		MaxRows:         p.MaxRows,
+	}
+}
+
+func decodeQueryPolicyRequest(r *http.Request) (*influxdb.QueryPolicy, error) {
+	var req queryPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode query policy request",
+			Err:  err,
+		}
+	}
+	p := &influxdb.QueryPolicy{
+		OrgID:           req.OrgID,
+		Name:            req.Name,
+		Description:     req.Description,
+		Effect:          req.Effect,
+		BucketIDs:       req.BucketIDs,
+		Networks:        req.Networks,
+		OutsideNetworks: req.OutsideNetworks,
+		MaxRows:         req.MaxRows,
+	}
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// handlePostQueryPolicy is the HTTP handler for the POST /api/v2/queryPolicies route.
+func (h *QueryPolicyHandler) handlePostQueryPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p, err := decodeQueryPolicyRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.QueryPolicyService.CreateQueryPolicy(ctx, p); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query policy created", zap.String("queryPolicy", fmt.Sprint(p)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newQueryPolicyResponse(p)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeQueryPolicyOrgID(r *http.Request) (*influxdb.ID, error) {
+	v := r.URL.Query().Get("orgID")
+	if v == "" {
+		return nil, nil
+	}
+	id, err := influxdb.IDFromString(v)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetQueryPolicies is the HTTP handler for the GET /api/v2/queryPolicies route.
+func (h *QueryPolicyHandler) handleGetQueryPolicies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, err := decodeQueryPolicyOrgID(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ps, err := h.QueryPolicyService.FindQueryPolicies(ctx, influxdb.QueryPolicyFilter{OrgID: orgID})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query policies retrieved", zap.String("queryPolicies", fmt.Sprint(ps)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newQueryPoliciesResponse(ps)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeQueryPolicyID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetQueryPolicy is the HTTP handler for the GET /api/v2/queryPolicies/:id route.
+func (h *QueryPolicyHandler) handleGetQueryPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeQueryPolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	p, err := h.QueryPolicyService.FindQueryPolicyByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query policy retrieved", zap.String("queryPolicy", fmt.Sprint(p)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newQueryPolicyResponse(p)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePutQueryPolicy is the HTTP handler for the PUT /api/v2/queryPolicies/:id route.
+func (h *QueryPolicyHandler) handlePutQueryPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeQueryPolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	p, err := decodeQueryPolicyRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	p.ID = id
+
+	if err := h.QueryPolicyService.UpdateQueryPolicy(ctx, p); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query policy updated", zap.String("queryPolicy", fmt.Sprint(p)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newQueryPolicyResponse(p)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteQueryPolicy is the HTTP handler for the DELETE /api/v2/queryPolicies/:id route.
+func (h *QueryPolicyHandler) handleDeleteQueryPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeQueryPolicyID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.QueryPolicyService.DeleteQueryPolicy(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query policy deleted", zap.String("queryPolicyID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type queryPolicyEvaluationsResponse struct {
+	Links       map[string]string                 `json:"links"`
+	Evaluations []*influxdb.QueryPolicyEvaluation `json:"evaluations"`
+}
+
+func decodeQueryPolicyEvaluationFilter(r *http.Request) (*influxdb.QueryPolicyEvaluationFilter, error) {
+	orgID, err := decodeQueryPolicyOrgID(r)
+	if err != nil {
+		return nil, err
+	}
+	filter := &influxdb.QueryPolicyEvaluationFilter{OrgID: orgID}
+
+	qp := r.URL.Query()
+	if v := qp.Get("policyID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid policyID",
+				Err:  err,
+			}
+		}
+		filter.PolicyID = id
+	}
+	if v := qp.Get("effect"); v != "" {
+		effect := influxdb.QueryPolicyEffect(v)
+		if effect != influxdb.QueryPolicyAllow && effect != influxdb.QueryPolicyDeny {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "effect must be allow or deny",
+			}
+		}
+		filter.Effect = &effect
+	}
+	return filter, nil
+}
+
+// handleGetQueryPolicyEvaluations is the HTTP handler for the GET /api/v2/queryPolicyEvaluations route.
+func (h *QueryPolicyHandler) handleGetQueryPolicyEvaluations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeQueryPolicyEvaluationFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	es, err := h.QueryPolicyService.FindQueryPolicyEvaluations(ctx, *filter, *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query policy evaluations retrieved", zap.String("evaluations", fmt.Sprint(es)))
+
+	res := &queryPolicyEvaluationsResponse{
+		Links: map[string]string{
+			"self":     prefixQueryPolicyEvaluations,
+			"policies": prefixQueryPolicies,
+		},
+		Evaluations: es,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// QueryPolicyService connects to Influx via HTTP using tokens to manage the
+// policies allowing and denying queries.
+type QueryPolicyService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.QueryPolicyService = (*QueryPolicyService)(nil)
+
+// FindQueryPolicyByID returns a single policy by ID.
+func (s *QueryPolicyService) FindQueryPolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.QueryPolicy, error) {
+	var res queryPolicyResponse
+	err := s.Client.
+		Get(path.Join(prefixQueryPolicies, id.String())).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.QueryPolicy, nil
+}
+
+// FindQueryPolicies returns the policies matching filter, oldest first.
+func (s *QueryPolicyService) FindQueryPolicies(ctx context.Context, filter influxdb.QueryPolicyFilter) ([]*influxdb.QueryPolicy, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+
+	var res queryPoliciesResponse
+	err := s.Client.
+		Get(prefixQueryPolicies).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ps := make([]*influxdb.QueryPolicy, 0, len(res.QueryPolicies))
+	for _, p := range res.QueryPolicies {
+		ps = append(ps, &p.QueryPolicy)
+	}
+	return ps, nil
+}
+
+// CreateQueryPolicy creates a policy.
+func (s *QueryPolicyService) CreateQueryPolicy(ctx context.Context, p *influxdb.QueryPolicy) error {
+	var res queryPolicyResponse
+	err := s.Client.
+		PostJSON(newQueryPolicyRequest(p), prefixQueryPolicies).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*p = res.QueryPolicy
+	return nil
+}
+
+// UpdateQueryPolicy replaces the policy with the ID of p by p.
+func (s *QueryPolicyService) UpdateQueryPolicy(ctx context.Context, p *influxdb.QueryPolicy) error {
+	var res queryPolicyResponse
+	err := s.Client.
+		PutJSON(newQueryPolicyRequest(p), prefixQueryPolicies, p.ID.String()).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*p = res.QueryPolicy
+	return nil
+}
+
+// DeleteQueryPolicy removes a policy.
+func (s *QueryPolicyService) DeleteQueryPolicy(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(prefixQueryPolicies, id.String()).
+		Do(ctx)
+}
+
+// RecordQueryPolicyEvaluation is not implemented for http. The evaluations
+// are recorded by the server evaluating the policies.
+func (s *QueryPolicyService) RecordQueryPolicyEvaluation(ctx context.Context, e *influxdb.QueryPolicyEvaluation) error {
+	return &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "record query policy evaluation is not implemented for http",
+	}
+}
+
+// FindQueryPolicyEvaluations returns the evaluations matching filter.
+func (s *QueryPolicyService) FindQueryPolicyEvaluations(ctx context.Context, filter influxdb.QueryPolicyEvaluationFilter, opts ...influxdb.FindOptions) ([]*influxdb.QueryPolicyEvaluation, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.PolicyID != nil {
+		params = append(params, [2]string{"policyID", filter.PolicyID.String()})
+	}
+	if filter.Effect != nil {
+		params = append(params, [2]string{"effect", string(*filter.Effect)})
+	}
+	params = append(params, influxdb.FindOptionParams(opts...)...)
+
+	var res queryPolicyEvaluationsResponse
+	err := s.Client.
+		Get(prefixQueryPolicyEvaluations).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Evaluations, nil
+}
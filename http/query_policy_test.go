@@ -0,0 +1,69 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeQueryPolicyService struct {
+	influxdb.QueryPolicyService
+	policies    []*influxdb.QueryPolicy
+	evaluations []*influxdb.QueryPolicyEvaluation
+}
+
+func (s *fakeQueryPolicyService) FindQueryPolicies(ctx context.Context, filter influxdb.QueryPolicyFilter) ([]*influxdb.QueryPolicy, error) {
+	return s.policies, nil
+}
+
+func (s *fakeQueryPolicyService) RecordQueryPolicyEvaluation(ctx context.Context, e *influxdb.QueryPolicyEvaluation) error {
+	s.evaluations = append(s.evaluations, e)
+	return nil
+}
+
+func TestFluxHandler_enforceQueryPolicies(t *testing.T) {
+	policies := &fakeQueryPolicyService{
+		policies: []*influxdb.QueryPolicy{
+			{ID: 100, Name: "outside", Effect: influxdb.QueryPolicyDeny, Networks: []string{"10.0.0.0/8"}, OutsideNetworks: true},
+			{ID: 101, Name: "exports", Effect: influxdb.QueryPolicyDeny, MaxRows: 10},
+		},
+	}
+	h := &FluxHandler{
+		log:                zaptest.NewLogger(t),
+		QueryPolicyService: policies,
+	}
+	req := &query.ProxyRequest{
+		Request: query.Request{
+			OrganizationID: 1,
+			Compiler:       lang.FluxCompiler{Query: `from(bucket: "b") |> range(start: -1h)`},
+		},
+	}
+
+	r := httptest.NewRequest("POST", "/api/v2/query", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	if _, err := h.enforceQueryPolicies(context.Background(), r, req); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected a query from outside the networks to be denied, got %v", err)
+	}
+	if len(policies.evaluations) != 1 || policies.evaluations[0].PolicyID != 100 || policies.evaluations[0].Effect != influxdb.QueryPolicyDeny {
+		t.Fatalf("expected the denial to be recorded, got %+v", policies.evaluations)
+	}
+
+	r.RemoteAddr = "10.1.2.3:1234"
+	ctx, err := h.enforceQueryPolicies(context.Background(), r, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, ok := query.RowLimitFromContext(ctx)
+	if !ok || l.Max != 10 {
+		t.Fatalf("expected the rows of the query to be limited to 10, got %+v", l)
+	}
+	l.OnExceeded()
+	if len(policies.evaluations) != 3 || policies.evaluations[2].PolicyID != 101 || policies.evaluations[2].Effect != influxdb.QueryPolicyDeny {
+		t.Errorf("expected the limit and exceeding it to be recorded, got %+v", policies.evaluations)
+	}
+}
@@ -0,0 +1,331 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	queryResultsPath = "/api/v2/query/results"
+
+	// NextResultTokenHeader is the header of a page of the results of a
+	// query with the token of the next page, if there is one.
+	NextResultTokenHeader = "X-Influx-Next-Result-Token"
+
+	// maxQueryResultPageSize is the largest page of query results served.
+	maxQueryResultPageSize = 64 << 20
+)
+
+const (
+	// DefaultQueryResultTTL is the default time the spilled results of a
+	// query are kept for.
+	DefaultQueryResultTTL = 10 * time.Minute
+
+	// DefaultQueryResultMaxBytes is the default largest result of a query
+	// spilled.
+	DefaultQueryResultMaxBytes = 1 << 30
+)
+
+var (
+	errQueryResultNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "query result not found or expired",
+	}
+	errQueryResultsDisabled = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "paging query results is disabled",
+	}
+)
+
+// QueryResultSpill spills the results of queries to files, from which they
+// are served a page at a time. Results which no response could hold are
+// paged through by clients rather than truncated, and run to completion
+// however slowly the client reads them.
+type QueryResultSpill struct {
+	dir string
+
+	// TTL is how long a result is kept after it is spilled.
+	TTL time.Duration
+	// MaxBytes is the largest result spilled. If 0, results are unlimited.
+	MaxBytes int64
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	results map[string]*spilledResult
+}
+
+type spilledResult struct {
+	path        string
+	owner       influxdb.ID
+	contentType string
+	size        int64
+	pageSize    int64
+	expiresAt   time.Time
+}
+
+// NewQueryResultSpill returns a QueryResultSpill spilling results to files
+// in dir. The results spilled by a previous run are removed.
+func NewQueryResultSpill(dir string) (*QueryResultSpill, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &QueryResultSpill{
+		dir:      dir,
+		TTL:      DefaultQueryResultTTL,
+		MaxBytes: DefaultQueryResultMaxBytes,
+		now:      time.Now,
+		results:  make(map[string]*spilledResult),
+	}, nil
+}
+
+// Close removes the results spilled.
+func (s *QueryResultSpill) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, r := range s.results {
+		os.Remove(r.path)
+		delete(s.results, id)
+	}
+	return nil
+}
+
+// errQueryResultTooLarge is returned by a spillWriter past the largest
+// result spilled.
+var errQueryResultTooLarge = &influxdb.Error{
+	Code: influxdb.ETooLarge,
+	Msg:  "query result is too large to spill",
+}
+
+// spillWriter writes to a file up to a limit.
+type spillWriter struct {
+	w     io.Writer
+	n     int64
+	limit int64
+}
+
+func (w *spillWriter) Write(p []byte) (int, error) {
+	if w.limit > 0 && w.n+int64(len(p)) > w.limit {
+		return 0, errQueryResultTooLarge
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// spill runs query, spilling what it writes, and returns the ID of the
+// result spilled.
+func (s *QueryResultSpill) spill(owner influxdb.ID, contentType string, pageSize int64, query func(w io.Writer) (flux.Statistics, error)) (string, flux.Statistics, error) {
+	f, err := ioutil.TempFile(s.dir, "result-")
+	if err != nil {
+		return "", flux.Statistics{}, err
+	}
+	bw := bufio.NewWriter(f)
+	w := &spillWriter{w: bw, limit: s.MaxBytes}
+	stats, err := query(w)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", stats, err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		os.Remove(f.Name())
+		return "", stats, err
+	}
+	id := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	s.results[id] = &spilledResult{
+		path:        f.Name(),
+		owner:       owner,
+		contentType: contentType,
+		size:        w.n,
+		pageSize:    pageSize,
+		expiresAt:   s.now().Add(s.TTL),
+	}
+	return id, stats, nil
+}
+
+// find returns the result spilled with the ID, if owner spilled it.
+func (s *QueryResultSpill) find(id string, owner influxdb.ID) (*spilledResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	r, ok := s.results[id]
+	if !ok || r.owner != owner {
+		return nil, errQueryResultNotFound
+	}
+	return r, nil
+}
+
+// remove removes the result spilled with the ID.
+func (s *QueryResultSpill) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.results[id]; ok {
+		os.Remove(r.path)
+		delete(s.results, id)
+	}
+}
+
+// expire removes the results which have expired. s.mu must be held.
+func (s *QueryResultSpill) expire() {
+	now := s.now()
+	for id, r := range s.results {
+		if !now.Before(r.expiresAt) {
+			os.Remove(r.path)
+			delete(s.results, id)
+		}
+	}
+}
+
+// page returns the page of the result at offset: the lines from offset up
+// to the page size, and at least one of them.
+func (r *spilledResult) page(offset, pageSize int64) ([]byte, error) {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var page bytes.Buffer
+	br := bufio.NewReader(io.LimitReader(f, r.size-offset))
+	for int64(page.Len()) < pageSize {
+		line, err := br.ReadBytes('\n')
+		page.Write(line)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return page.Bytes(), nil
+}
+
+// decodeQueryPageSize returns the page size of the results of a query
+// request, or 0 if they are not paged.
+func decodeQueryPageSize(r *http.Request) (int64, error) {
+	v := r.URL.Query().Get("pageSize")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 1 || n > maxQueryResultPageSize {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("pageSize must be between 1 and %d bytes", maxQueryResultPageSize),
+		}
+	}
+	return n, nil
+}
+
+// writeQueryResultPage writes the page of the result spilled with the ID at
+// offset, with the token of the next page, if any. The result is removed
+// once its last page is written.
+func (h *FluxHandler) writeQueryResultPage(ctx context.Context, w http.ResponseWriter, id string, r *spilledResult, offset, pageSize int64) {
+	page, err := r.page(offset, pageSize)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if next := offset + int64(len(page)); next < r.size {
+		w.Header().Set(NextResultTokenHeader, fmt.Sprintf("%s.%d", id, next))
+	} else {
+		h.QueryResultSpill.remove(id)
+	}
+	if r.contentType != "" {
+		w.Header().Set("Content-Type", r.contentType)
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(page); err != nil {
+		h.log.Info("Error writing response to client",
+			zap.String("handler", "flux"),
+			zap.Error(err),
+		)
+	}
+}
+
+// handleGetQueryResults is the HTTP handler for the GET /api/v2/query/results
+// route. It serves the page of the results of a query of its
+// nextResultToken, to the authorization which ran the query only.
+func (h *FluxHandler) handleGetQueryResults(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	if h.QueryResultSpill == nil {
+		h.HandleHTTPError(ctx, errQueryResultsDisabled, w)
+		return
+	}
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	token := r.URL.Query().Get("nextResultToken")
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		h.HandleHTTPError(ctx, errQueryResultNotFound, w)
+		return
+	}
+	id := token[:i]
+	offset, err := strconv.ParseInt(token[i+1:], 10, 64)
+	if err != nil || offset < 0 {
+		h.HandleHTTPError(ctx, errQueryResultNotFound, w)
+		return
+	}
+
+	res, err := h.QueryResultSpill.find(id, a.Identifier())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if offset >= res.size {
+		h.HandleHTTPError(ctx, errQueryResultNotFound, w)
+		return
+	}
+
+	pageSize := res.pageSize
+	if n, err := decodeQueryPageSize(r); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	} else if n > 0 {
+		pageSize = n
+	}
+	h.writeQueryResultPage(ctx, w, id, res, offset, pageSize)
+}
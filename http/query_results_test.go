@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFluxHandler_QueryResultPages(t *testing.T) {
+	orgSVC := newInMemKVSVC(t)
+	org := influxdb.Organization{Name: t.Name()}
+	if err := orgSVC.CreateOrganization(context.Background(), &org); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "query-results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	spill, err := NewQueryResultSpill(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spill.Close()
+
+	const result = "#datatype,string,long\r\n,result,table\r\n,_result,0\r\n,_result,1\r\n"
+	h := NewFluxHandler(zaptest.NewLogger(t), &FluxBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		log:                 zaptest.NewLogger(t),
+		QueryEventRecorder:  noopEventRecorder{},
+		OrganizationService: orgSVC,
+		QueryResultSpill:    spill,
+		ProxyQueryService: &mock.ProxyQueryService{
+			QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+				_, err := io.WriteString(w, result)
+				return flux.Statistics{}, err
+			},
+		},
+	})
+
+	owner := &influxdb.Authorization{ID: 1}
+	do := func(r *http.Request, a influxdb.Authorizer, handle http.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handle(w, r.WithContext(icontext.SetAuthorizer(r.Context(), a)))
+		return w
+	}
+
+	r := httptest.NewRequest("POST", "/api/v2/query?pageSize=30&orgID="+org.ID.String(), strings.NewReader("buckets()"))
+	r.Header.Set("Content-Type", "application/vnd.flux")
+	w := do(r, owner, h.handleQuery)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+
+	// Pages are split after the line which fills them, and concatenate to
+	// the whole result.
+	got := w.Body.String()
+	if got != "#datatype,string,long\r\n,result,table\r\n" {
+		t.Fatalf("unexpected first page %q", got)
+	}
+	token := w.Header().Get(NextResultTokenHeader)
+	if token == "" {
+		t.Fatal("expected a next result token")
+	}
+
+	next := httptest.NewRequest("GET", "/api/v2/query/results?nextResultToken="+url.QueryEscape(token), nil)
+	if w := do(next, &influxdb.Authorization{ID: 2}, h.handleGetQueryResults); w.Code != http.StatusNotFound {
+		t.Fatalf("expected the results of another authorization to be not found, got status %d", w.Code)
+	}
+
+	for token != "" {
+		next := httptest.NewRequest("GET", "/api/v2/query/results?nextResultToken="+url.QueryEscape(token), nil)
+		w := do(next, owner, h.handleGetQueryResults)
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+		}
+		got += w.Body.String()
+		token = w.Header().Get(NextResultTokenHeader)
+	}
+	if got != result {
+		t.Fatalf("got result %q, want %q", got, result)
+	}
+	if len(spill.results) != 0 {
+		t.Fatal("expected the result to be removed once its last page was served")
+	}
+}
@@ -0,0 +1,387 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// ReportBackend is all services and associated parameters required to construct
+// the ReportHandler.
+type ReportBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ReportService influxdb.ReportService
+}
+
+// NewReportBackend returns a new instance of ReportBackend.
+func NewReportBackend(log *zap.Logger, b *APIBackend) *ReportBackend {
+	return &ReportBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		ReportService:    b.ReportService,
+	}
+}
+
+// ReportHandler represents an HTTP API handler for scheduled reports.
+type ReportHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ReportService influxdb.ReportService
+}
+
+const (
+	prefixReports = "/api/v2/reports"
+	reportsIDPath = "/api/v2/reports/:id"
+)
+
+// NewReportHandler returns a new instance of ReportHandler.
+func NewReportHandler(log *zap.Logger, b *ReportBackend) *ReportHandler {
+	h := &ReportHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		ReportService: b.ReportService,
+	}
+
+	h.HandlerFunc("POST", prefixReports, h.handlePostReport)
+	h.HandlerFunc("GET", prefixReports, h.handleGetReports)
+	h.HandlerFunc("GET", reportsIDPath, h.handleGetReport)
+	h.HandlerFunc("PATCH", reportsIDPath, h.handlePatchReport)
+	h.HandlerFunc("DELETE", reportsIDPath, h.handleDeleteReport)
+
+	return h
+}
+
+type reportResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.Report
+}
+
+func newReportResponse(r *influxdb.Report) *reportResponse {
+	links := map[string]string{
+		"self": fmt.Sprintf("/api/v2/reports/%s", r.ID),
+		"org":  fmt.Sprintf("/api/v2/orgs/%s", r.OrgID),
+	}
+	if r.DashboardID.Valid() {
+		links["dashboard"] = fmt.Sprintf("/api/v2/dashboards/%s", r.DashboardID)
+	}
+	return &reportResponse{
+		Links:  links,
+		Report: *r,
+	}
+}
+
+type reportsResponse struct {
+	Links   map[string]string `json:"links"`
+	Reports []*reportResponse `json:"reports"`
+}
+
+func newReportsResponse(rs []*influxdb.Report) *reportsResponse {
+	res := &reportsResponse{
+		Links: map[string]string{
+			"self": prefixReports,
+		},
+		Reports: make([]*reportResponse, 0, len(rs)),
+	}
+	for _, r := range rs {
+		res.Reports = append(res.Reports, newReportResponse(r))
+	}
+	return res
+}
+
+type postReportRequest struct {
+	OrgID           influxdb.ID             `json:"orgID"`
+	Name            string                  `json:"name"`
+	Description     string                  `json:"description,omitempty"`
+	AuthorizationID influxdb.ID             `json:"authorizationID,omitempty"`
+	Every           influxdb.Duration       `json:"every"`
+	Range           influxdb.Duration       `json:"range"`
+	DashboardID     influxdb.ID             `json:"dashboardID,omitempty"`
+	Queries         []influxdb.ReportQuery  `json:"queries,omitempty"`
+	Format          string                  `json:"format"`
+	Delivery        influxdb.ReportDelivery `json:"delivery"`
+}
+
+// handlePostReport is the HTTP handler for the POST /api/v2/reports route.
+// Reports run with the authorization of the request unless another one is given.
+func (h *ReportHandler) handlePostReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode report request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if !req.AuthorizationID.Valid() {
+		if a, err := pcontext.GetAuthorizer(ctx); err == nil {
+			if auth, ok := a.(*influxdb.Authorization); ok {
+				req.AuthorizationID = auth.ID
+			}
+		}
+	}
+
+	rep := &influxdb.Report{
+		OrgID:           req.OrgID,
+		Name:            req.Name,
+		Description:     req.Description,
+		AuthorizationID: req.AuthorizationID,
+		Every:           req.Every,
+		Range:           req.Range,
+		DashboardID:     req.DashboardID,
+		Queries:         req.Queries,
+		Format:          req.Format,
+		Delivery:        req.Delivery,
+	}
+	if err := rep.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.ReportService.CreateReport(ctx, rep); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Report created", zap.String("report", fmt.Sprint(rep)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newReportResponse(rep)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetReportsRequest(r *http.Request) (*influxdb.ReportFilter, error) {
+	filter := &influxdb.ReportFilter{}
+	if v := r.URL.Query().Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	return filter, nil
+}
+
+// handleGetReports is the HTTP handler for the GET /api/v2/reports route.
+func (h *ReportHandler) handleGetReports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetReportsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rs, err := h.ReportService.FindReports(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Reports retrieved", zap.String("reports", fmt.Sprint(rs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReportsResponse(rs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeReportID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetReport is the HTTP handler for the GET /api/v2/reports/:id route.
+func (h *ReportHandler) handleGetReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeReportID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rep, err := h.ReportService.FindReportByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Report retrieved", zap.String("report", fmt.Sprint(rep)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReportResponse(rep)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchReport is the HTTP handler for the PATCH /api/v2/reports/:id route.
+func (h *ReportHandler) handlePatchReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeReportID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.ReportUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode report update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	rep, err := h.ReportService.UpdateReport(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Report updated", zap.String("report", fmt.Sprint(rep)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newReportResponse(rep)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteReport is the HTTP handler for the DELETE /api/v2/reports/:id route.
+func (h *ReportHandler) handleDeleteReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeReportID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.ReportService.DeleteReport(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Report deleted", zap.String("reportID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReportService connects to Influx via HTTP using tokens to manage scheduled reports.
+type ReportService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.ReportService = (*ReportService)(nil)
+
+// FindReportByID returns a single report by ID.
+func (s *ReportService) FindReportByID(ctx context.Context, id influxdb.ID) (*influxdb.Report, error) {
+	var rr reportResponse
+	err := s.Client.
+		Get(path.Join(prefixReports, id.String())).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rr.Report, nil
+}
+
+// FindReports returns a list of reports that match filter.
+func (s *ReportService) FindReports(ctx context.Context, filter influxdb.ReportFilter) ([]*influxdb.Report, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+
+	var rr reportsResponse
+	err := s.Client.
+		Get(prefixReports).
+		QueryParams(params...).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := make([]*influxdb.Report, 0, len(rr.Reports))
+	for _, r := range rr.Reports {
+		rs = append(rs, &r.Report)
+	}
+	return rs, nil
+}
+
+// CreateReport creates a new report and sets r.ID with the new identifier.
+func (s *ReportService) CreateReport(ctx context.Context, r *influxdb.Report) error {
+	var rr reportResponse
+	err := s.Client.
+		PostJSON(postReportRequest{
+			OrgID:           r.OrgID,
+			Name:            r.Name,
+			Description:     r.Description,
+			AuthorizationID: r.AuthorizationID,
+			Every:           r.Every,
+			Range:           r.Range,
+			DashboardID:     r.DashboardID,
+			Queries:         r.Queries,
+			Format:          r.Format,
+			Delivery:        r.Delivery,
+		}, prefixReports).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*r = rr.Report
+	return nil
+}
+
+// UpdateReport updates a single report with changeset.
+func (s *ReportService) UpdateReport(ctx context.Context, id influxdb.ID, upd influxdb.ReportUpdate) (*influxdb.Report, error) {
+	var rr reportResponse
+	err := s.Client.
+		PatchJSON(upd, path.Join(prefixReports, id.String())).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rr.Report, nil
+}
+
+// UpdateReportStatus is not implemented for http.
+func (s *ReportService) UpdateReportStatus(ctx context.Context, id influxdb.ID, status influxdb.ReportStatus) error {
+	return &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "update report status is not implemented for http",
+	}
+}
+
+// DeleteReport removes a report by ID.
+func (s *ReportService) DeleteReport(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixReports, id.String())).
+		Do(ctx)
+}
@@ -0,0 +1,319 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// ResourceGrantBackend is all services and associated parameters required to construct
+// the ResourceGrantHandler.
+type ResourceGrantBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ResourceGrantService influxdb.ResourceGrantService
+}
+
+// NewResourceGrantBackend returns a new instance of ResourceGrantBackend.
+func NewResourceGrantBackend(log *zap.Logger, b *APIBackend) *ResourceGrantBackend {
+	return &ResourceGrantBackend{
+		HTTPErrorHandler:     b.HTTPErrorHandler,
+		log:                  log,
+		ResourceGrantService: b.ResourceGrantService,
+	}
+}
+
+// ResourceGrantHandler represents an HTTP API handler for resource grants.
+type ResourceGrantHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ResourceGrantService influxdb.ResourceGrantService
+}
+
+const (
+	prefixResourceGrants = "/api/v2/grants"
+	resourceGrantsIDPath = "/api/v2/grants/:id"
+)
+
+// NewResourceGrantHandler returns a new instance of ResourceGrantHandler.
+func NewResourceGrantHandler(log *zap.Logger, b *ResourceGrantBackend) *ResourceGrantHandler {
+	h := &ResourceGrantHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		ResourceGrantService: b.ResourceGrantService,
+	}
+
+	h.HandlerFunc("POST", prefixResourceGrants, h.handlePostResourceGrant)
+	h.HandlerFunc("GET", prefixResourceGrants, h.handleGetResourceGrants)
+	h.HandlerFunc("GET", resourceGrantsIDPath, h.handleGetResourceGrant)
+	h.HandlerFunc("DELETE", resourceGrantsIDPath, h.handleDeleteResourceGrant)
+
+	return h
+}
+
+type resourceGrantResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.ResourceGrant
+}
+
+func newResourceGrantResponse(g *influxdb.ResourceGrant) *resourceGrantResponse {
+	return &resourceGrantResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/grants/%s", g.ID),
+		},
+		ResourceGrant: *g,
+	}
+}
+
+type resourceGrantsResponse struct {
+	Links  map[string]string        `json:"links"`
+	Grants []*resourceGrantResponse `json:"grants"`
+}
+
+func newResourceGrantsResponse(gs []*influxdb.ResourceGrant) *resourceGrantsResponse {
+	res := &resourceGrantsResponse{
+		Links: map[string]string{
+			"self": prefixResourceGrants,
+		},
+		Grants: make([]*resourceGrantResponse, 0, len(gs)),
+	}
+	for _, g := range gs {
+		res.Grants = append(res.Grants, newResourceGrantResponse(g))
+	}
+	return res
+}
+
+// handlePostResourceGrant is the HTTP handler for the POST /api/v2/grants route.
+func (h *ResourceGrantHandler) handlePostResourceGrant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	g := &influxdb.ResourceGrant{}
+	if err := json.NewDecoder(r.Body).Decode(g); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode resource grant request",
+			Err:  err,
+		}, w)
+		return
+	}
+	if err := g.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.ResourceGrantService.CreateResourceGrant(ctx, g); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Resource grant created", zap.String("grant", fmt.Sprint(g)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newResourceGrantResponse(g)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetResourceGrantsRequest(r *http.Request) (*influxdb.ResourceGrantFilter, *influxdb.FindOptions, error) {
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	qp := r.URL.Query()
+	filter := &influxdb.ResourceGrantFilter{}
+	for _, p := range []struct {
+		name string
+		dst  **influxdb.ID
+	}{
+		{"orgID", &filter.OrgID},
+		{"granteeOrgID", &filter.GranteeOrgID},
+		{"resourceID", &filter.ResourceID},
+	} {
+		if v := qp.Get(p.name); v != "" {
+			id, err := influxdb.IDFromString(v)
+			if err != nil {
+				return nil, nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("invalid %s", p.name),
+					Err:  err,
+				}
+			}
+			*p.dst = id
+		}
+	}
+	if v := qp.Get("resourceType"); v != "" {
+		rt := influxdb.ResourceType(v)
+		if err := rt.Valid(); err != nil {
+			return nil, nil, err
+		}
+		filter.ResourceType = &rt
+	}
+	return filter, opts, nil
+}
+
+// handleGetResourceGrants is the HTTP handler for the GET /api/v2/grants route.
+func (h *ResourceGrantHandler) handleGetResourceGrants(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, opts, err := decodeGetResourceGrantsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	gs, _, err := h.ResourceGrantService.FindResourceGrants(ctx, *filter, *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Resource grants retrieved", zap.String("grants", fmt.Sprint(gs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newResourceGrantsResponse(gs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeResourceGrantID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid grant ID provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetResourceGrant is the HTTP handler for the GET /api/v2/grants/:id route.
+func (h *ResourceGrantHandler) handleGetResourceGrant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeResourceGrantID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	g, err := h.ResourceGrantService.FindResourceGrantByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Resource grant retrieved", zap.String("grant", fmt.Sprint(g)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newResourceGrantResponse(g)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteResourceGrant is the HTTP handler for the DELETE /api/v2/grants/:id route.
+func (h *ResourceGrantHandler) handleDeleteResourceGrant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeResourceGrantID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.ResourceGrantService.DeleteResourceGrant(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Resource grant deleted", zap.String("grantID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResourceGrantService connects to Influx via HTTP using tokens to manage resource grants.
+type ResourceGrantService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.ResourceGrantService = (*ResourceGrantService)(nil)
+
+// FindResourceGrantByID returns a single resource grant by ID.
+func (s *ResourceGrantService) FindResourceGrantByID(ctx context.Context, id influxdb.ID) (*influxdb.ResourceGrant, error) {
+	var gr resourceGrantResponse
+	err := s.Client.
+		Get(path.Join(prefixResourceGrants, id.String())).
+		DecodeJSON(&gr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &gr.ResourceGrant, nil
+}
+
+// FindResourceGrants returns a list of resource grants that match filter.
+func (s *ResourceGrantService) FindResourceGrants(ctx context.Context, filter influxdb.ResourceGrantFilter, opt ...influxdb.FindOptions) ([]*influxdb.ResourceGrant, int, error) {
+	params := influxdb.FindOptionParams(opt...)
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.GranteeOrgID != nil {
+		params = append(params, [2]string{"granteeOrgID", filter.GranteeOrgID.String()})
+	}
+	if filter.ResourceType != nil {
+		params = append(params, [2]string{"resourceType", string(*filter.ResourceType)})
+	}
+	if filter.ResourceID != nil {
+		params = append(params, [2]string{"resourceID", filter.ResourceID.String()})
+	}
+
+	var gr resourceGrantsResponse
+	err := s.Client.
+		Get(prefixResourceGrants).
+		QueryParams(params...).
+		DecodeJSON(&gr).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	gs := make([]*influxdb.ResourceGrant, 0, len(gr.Grants))
+	for _, g := range gr.Grants {
+		if filter.ID != nil && *filter.ID != g.ID {
+			continue
+		}
+		gs = append(gs, &g.ResourceGrant)
+	}
+	return gs, len(gs), nil
+}
+
+// CreateResourceGrant creates a new resource grant and sets g.ID with the new identifier.
+func (s *ResourceGrantService) CreateResourceGrant(ctx context.Context, g *influxdb.ResourceGrant) error {
+	var gr resourceGrantResponse
+	err := s.Client.
+		PostJSON(g, prefixResourceGrants).
+		DecodeJSON(&gr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*g = gr.ResourceGrant
+	return nil
+}
+
+// DeleteResourceGrant removes a resource grant by ID.
+func (s *ResourceGrantService) DeleteResourceGrant(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixResourceGrants, id.String())).
+		StatusFn(func(resp *http.Response) error {
+			return CheckErrorStatus(http.StatusNoContent, resp)
+		}).
+		Do(ctx)
+}
@@ -0,0 +1,500 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// RoleBackend is all services and associated parameters required to construct
+// the RoleHandler.
+type RoleBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	RoleService influxdb.RoleService
+}
+
+// NewRoleBackend returns a new instance of RoleBackend.
+func NewRoleBackend(log *zap.Logger, b *APIBackend) *RoleBackend {
+	return &RoleBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		RoleService:      b.RoleService,
+	}
+}
+
+// RoleHandler represents an HTTP API handler for roles.
+type RoleHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	RoleService influxdb.RoleService
+}
+
+const (
+	prefixRoles        = "/api/v2/roles"
+	rolesIDPath        = "/api/v2/roles/:id"
+	rolesMembersPath   = "/api/v2/roles/:id/members"
+	rolesMembersIDPath = "/api/v2/roles/:id/members/:userID"
+)
+
+// NewRoleHandler returns a new instance of RoleHandler.
+func NewRoleHandler(log *zap.Logger, b *RoleBackend) *RoleHandler {
+	h := &RoleHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		RoleService: b.RoleService,
+	}
+
+	h.HandlerFunc("POST", prefixRoles, h.handlePostRole)
+	h.HandlerFunc("GET", prefixRoles, h.handleGetRoles)
+	h.HandlerFunc("GET", rolesIDPath, h.handleGetRole)
+	h.HandlerFunc("PATCH", rolesIDPath, h.handlePatchRole)
+	h.HandlerFunc("DELETE", rolesIDPath, h.handleDeleteRole)
+
+	h.HandlerFunc("GET", rolesMembersPath, h.handleGetRoleMembers)
+	h.HandlerFunc("POST", rolesMembersPath, h.handlePostRoleMember)
+	h.HandlerFunc("DELETE", rolesMembersIDPath, h.handleDeleteRoleMember)
+
+	return h
+}
+
+type roleResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.Role
+}
+
+func newRoleResponse(r *influxdb.Role) *roleResponse {
+	return &roleResponse{
+		Links: map[string]string{
+			"self":    fmt.Sprintf("/api/v2/roles/%s", r.ID),
+			"members": fmt.Sprintf("/api/v2/roles/%s/members", r.ID),
+			"org":     fmt.Sprintf("/api/v2/orgs/%s", r.OrgID),
+		},
+		Role: *r,
+	}
+}
+
+type rolesResponse struct {
+	Links map[string]string `json:"links"`
+	Roles []*roleResponse   `json:"roles"`
+}
+
+func newRolesResponse(rs []*influxdb.Role) *rolesResponse {
+	res := &rolesResponse{
+		Links: map[string]string{
+			"self": prefixRoles,
+		},
+		Roles: make([]*roleResponse, 0, len(rs)),
+	}
+	for _, r := range rs {
+		res.Roles = append(res.Roles, newRoleResponse(r))
+	}
+	return res
+}
+
+type roleMember struct {
+	ID influxdb.ID `json:"id"`
+}
+
+type roleMembersResponse struct {
+	Links   map[string]string `json:"links"`
+	Members []roleMember      `json:"members"`
+}
+
+// handlePostRole is the HTTP handler for the POST /api/v2/roles route.
+func (h *RoleHandler) handlePostRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	role := &influxdb.Role{}
+	if err := json.NewDecoder(r.Body).Decode(role); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode role request",
+			Err:  err,
+		}, w)
+		return
+	}
+	if err := role.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.CreateRole(ctx, role); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Role created", zap.String("role", fmt.Sprint(role)))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newRoleResponse(role)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetRolesRequest(r *http.Request) (*influxdb.RoleFilter, *influxdb.FindOptions, error) {
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	qp := r.URL.Query()
+	filter := &influxdb.RoleFilter{}
+	for _, p := range []struct {
+		name string
+		dst  **influxdb.ID
+	}{
+		{"orgID", &filter.OrgID},
+		{"userID", &filter.UserID},
+	} {
+		if v := qp.Get(p.name); v != "" {
+			id, err := influxdb.IDFromString(v)
+			if err != nil {
+				return nil, nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("invalid %s", p.name),
+					Err:  err,
+				}
+			}
+			*p.dst = id
+		}
+	}
+	if name := qp.Get("name"); name != "" {
+		filter.Name = &name
+	}
+	return filter, opts, nil
+}
+
+// handleGetRoles is the HTTP handler for the GET /api/v2/roles route.
+func (h *RoleHandler) handleGetRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, opts, err := decodeGetRolesRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rs, _, err := h.RoleService.FindRoles(ctx, *filter, *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Roles retrieved", zap.String("roles", fmt.Sprint(rs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRolesResponse(rs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeRoleRouteID(ctx context.Context, name string) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName(name)); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid %s provided in route", name),
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetRole is the HTTP handler for the GET /api/v2/roles/:id route.
+func (h *RoleHandler) handleGetRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeRoleRouteID(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	role, err := h.RoleService.FindRoleByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Role retrieved", zap.String("role", fmt.Sprint(role)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRoleResponse(role)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchRole is the HTTP handler for the PATCH /api/v2/roles/:id route.
+func (h *RoleHandler) handlePatchRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeRoleRouteID(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.RoleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode role update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	role, err := h.RoleService.UpdateRole(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Role updated", zap.String("role", fmt.Sprint(role)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRoleResponse(role)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteRole is the HTTP handler for the DELETE /api/v2/roles/:id route.
+func (h *RoleHandler) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeRoleRouteID(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.DeleteRole(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Role deleted", zap.String("roleID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetRoleMembers is the HTTP handler for the GET /api/v2/roles/:id/members route.
+func (h *RoleHandler) handleGetRoleMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeRoleRouteID(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ids, err := h.RoleService.FindRoleMembers(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	res := &roleMembersResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/roles/%s/members", id),
+		},
+		Members: make([]roleMember, 0, len(ids)),
+	}
+	for _, userID := range ids {
+		res.Members = append(res.Members, roleMember{ID: userID})
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostRoleMember is the HTTP handler for the POST /api/v2/roles/:id/members route.
+func (h *RoleHandler) handlePostRoleMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeRoleRouteID(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var m roleMember
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode role member",
+			Err:  err,
+		}, w)
+		return
+	}
+	if !m.ID.Valid() {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "user id is required",
+		}, w)
+		return
+	}
+
+	if err := h.RoleService.AddRoleMember(ctx, id, m.ID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Role member added", zap.String("roleID", id.String()), zap.String("userID", m.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, m); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteRoleMember is the HTTP handler for the DELETE /api/v2/roles/:id/members/:userID route.
+func (h *RoleHandler) handleDeleteRoleMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeRoleRouteID(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	userID, err := decodeRoleRouteID(ctx, "userID")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.RemoveRoleMember(ctx, id, userID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Role member removed", zap.String("roleID", id.String()), zap.String("userID", userID.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RoleService connects to Influx via HTTP using tokens to manage roles.
+type RoleService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// FindRoleByID returns a single role by ID.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	var rr roleResponse
+	err := s.Client.
+		Get(path.Join(prefixRoles, id.String())).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rr.Role, nil
+}
+
+// FindRoles returns a list of roles that match filter and the total count of matching roles.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	params := influxdb.FindOptionParams(opt...)
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.UserID != nil {
+		params = append(params, [2]string{"userID", filter.UserID.String()})
+	}
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var rr rolesResponse
+	err := s.Client.
+		Get(prefixRoles).
+		QueryParams(params...).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rs := make([]*influxdb.Role, 0, len(rr.Roles))
+	for _, r := range rr.Roles {
+		if filter.ID != nil && *filter.ID != r.ID {
+			continue
+		}
+		rs = append(rs, &r.Role)
+	}
+	return rs, len(rs), nil
+}
+
+// CreateRole creates a new role and sets r.ID with the new identifier.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	var rr roleResponse
+	err := s.Client.
+		PostJSON(r, prefixRoles).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*r = rr.Role
+	return nil
+}
+
+// UpdateRole updates a single role with changeset.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	var rr roleResponse
+	err := s.Client.
+		PatchJSON(upd, path.Join(prefixRoles, id.String())).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rr.Role, nil
+}
+
+// DeleteRole removes a role by ID along with its memberships.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixRoles, id.String())).
+		StatusFn(func(resp *http.Response) error {
+			return CheckErrorStatus(http.StatusNoContent, resp)
+		}).
+		Do(ctx)
+}
+
+// AddRoleMember grants the permissions of the role to the user.
+func (s *RoleService) AddRoleMember(ctx context.Context, roleID, userID influxdb.ID) error {
+	return s.Client.
+		PostJSON(roleMember{ID: userID}, path.Join(prefixRoles, roleID.String(), "members")).
+		Do(ctx)
+}
+
+// RemoveRoleMember revokes the permissions of the role from the user.
+func (s *RoleService) RemoveRoleMember(ctx context.Context, roleID, userID influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixRoles, roleID.String(), "members", userID.String())).
+		StatusFn(func(resp *http.Response) error {
+			return CheckErrorStatus(http.StatusNoContent, resp)
+		}).
+		Do(ctx)
+}
+
+// FindRoleMembers returns the IDs of the users that are members of the role.
+func (s *RoleService) FindRoleMembers(ctx context.Context, roleID influxdb.ID) ([]influxdb.ID, error) {
+	var rr roleMembersResponse
+	err := s.Client.
+		Get(path.Join(prefixRoles, roleID.String(), "members")).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]influxdb.ID, 0, len(rr.Members))
+	for _, m := range rr.Members {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
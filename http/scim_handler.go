@@ -0,0 +1,766 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+// SCIM schema URNs as defined by RFC 7643 and RFC 7644.
+const (
+	scimUserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// SCIMBackend is all services and associated parameters required to construct
+// the SCIMHandler.
+type SCIMBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	UserService                influxdb.UserService
+	OrganizationService        influxdb.OrganizationService
+	UserResourceMappingService influxdb.UserResourceMappingService
+}
+
+// NewSCIMBackend returns a new instance of SCIMBackend.
+func NewSCIMBackend(log *zap.Logger, b *APIBackend) *SCIMBackend {
+	return &SCIMBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		UserService:                b.UserService,
+		OrganizationService:        b.OrganizationService,
+		UserResourceMappingService: b.UserResourceMappingService,
+	}
+}
+
+// SCIMHandler is a SCIM 2.0 service provider used by identity providers to
+// provision users. SCIM users are influxdb users and SCIM groups are
+// organizations, the members of a group being the members of the organization.
+type SCIMHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	UserService                influxdb.UserService
+	OrganizationService        influxdb.OrganizationService
+	UserResourceMappingService influxdb.UserResourceMappingService
+}
+
+const (
+	prefixSCIM       = "/api/v2/scim/v2"
+	scimUsersPath    = "/api/v2/scim/v2/Users"
+	scimUsersIDPath  = "/api/v2/scim/v2/Users/:id"
+	scimGroupsPath   = "/api/v2/scim/v2/Groups"
+	scimGroupsIDPath = "/api/v2/scim/v2/Groups/:id"
+)
+
+// NewSCIMHandler returns a new instance of SCIMHandler.
+func NewSCIMHandler(log *zap.Logger, b *SCIMBackend) *SCIMHandler {
+	h := &SCIMHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		UserService:                b.UserService,
+		OrganizationService:        b.OrganizationService,
+		UserResourceMappingService: b.UserResourceMappingService,
+	}
+
+	h.HandlerFunc("GET", scimUsersPath, h.handleGetUsers)
+	h.HandlerFunc("POST", scimUsersPath, h.handlePostUser)
+	h.HandlerFunc("GET", scimUsersIDPath, h.handleGetUser)
+	h.HandlerFunc("PUT", scimUsersIDPath, h.handlePutUser)
+	h.HandlerFunc("PATCH", scimUsersIDPath, h.handlePatchUser)
+	h.HandlerFunc("DELETE", scimUsersIDPath, h.handleDeleteUser)
+
+	h.HandlerFunc("GET", scimGroupsPath, h.handleGetGroups)
+	h.HandlerFunc("POST", scimGroupsPath, h.handlePostGroup)
+	h.HandlerFunc("GET", scimGroupsIDPath, h.handleGetGroup)
+	h.HandlerFunc("PATCH", scimGroupsIDPath, h.handlePatchGroup)
+
+	return h
+}
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type scimUser struct {
+	Schemas  []string  `json:"schemas"`
+	ID       string    `json:"id,omitempty"`
+	UserName string    `json:"userName"`
+	Active   *bool     `json:"active,omitempty"`
+	Meta     *scimMeta `json:"meta,omitempty"`
+}
+
+func newSCIMUser(u *influxdb.User) *scimUser {
+	active := u.Status != influxdb.Inactive
+	return &scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       u.ID.String(),
+		UserName: u.Name,
+		Active:   &active,
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Location:     fmt.Sprintf("%s/%s", scimUsersPath, u.ID),
+		},
+	}
+}
+
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members"`
+	Meta        *scimMeta    `json:"meta,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+type scimError struct {
+	Schemas []string `json:"schemas"`
+	Status  string   `json:"status"`
+	Detail  string   `json:"detail,omitempty"`
+}
+
+type scimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+type scimPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+// handleSCIMError writes err in the error format of RFC 7644 section 3.12,
+// which is what identity providers expect instead of the influxdb error body.
+func (h *SCIMHandler) handleSCIMError(ctx context.Context, err error, w http.ResponseWriter) {
+	code := kithttp.ErrorCodeToStatusCode(influxdb.ErrorCode(err))
+	res := scimError{
+		Schemas: []string{scimErrorSchema},
+		Status:  strconv.Itoa(code),
+		Detail:  influxdb.ErrorMessage(err),
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func encodeSCIMResponse(w http.ResponseWriter, code int, res interface{}) error {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(code)
+	return json.NewEncoder(w).Encode(res)
+}
+
+func decodeSCIMID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "resource not found",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// decodeSCIMFilter parses the equality filters identity providers use to
+// look up resources, e.g. `userName eq "jdoe"`. No other filter expressions
+// are supported.
+func decodeSCIMFilter(filter, attr string) (*string, error) {
+	if filter == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(filter, " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[0], attr) || !strings.EqualFold(parts[1], "eq") {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported filter %q: only %s eq is supported", filter, attr),
+		}
+	}
+
+	v, err := strconv.Unquote(parts[2])
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid filter value %s", parts[2]),
+			Err:  err,
+		}
+	}
+	return &v, nil
+}
+
+// decodeSCIMFindOptions converts the 1-based SCIM paging parameters into find options.
+func decodeSCIMFindOptions(r *http.Request) (influxdb.FindOptions, int, error) {
+	qp := r.URL.Query()
+	opts := influxdb.FindOptions{}
+	start := 1
+	if v := qp.Get("startIndex"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, 0, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "startIndex must be an integer",
+				Err:  err,
+			}
+		}
+		if i > 1 {
+			start = i
+		}
+	}
+	opts.Offset = start - 1
+
+	if v := qp.Get("count"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return opts, 0, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "count must be a non-negative integer",
+				Err:  err,
+			}
+		}
+		opts.Limit = i
+	}
+	return opts, start, nil
+}
+
+// handleGetUsers is the HTTP handler for the GET /api/v2/scim/v2/Users route.
+func (h *SCIMHandler) handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name, err := decodeSCIMFilter(r.URL.Query().Get("filter"), "userName")
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	opts, start, err := decodeSCIMFindOptions(r)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	users, _, err := h.UserService.FindUsers(ctx, influxdb.UserFilter{Name: name})
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	res := make([]*scimUser, 0, len(users))
+	for _, i := range scimPage(len(users), opts) {
+		res = append(res, newSCIMUser(users[i]))
+	}
+	if err := encodeSCIMResponse(w, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: len(users),
+		StartIndex:   start,
+		ItemsPerPage: len(res),
+		Resources:    res,
+	}); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// scimPage returns the indexes of the n results selected by opts.
+func scimPage(n int, opts influxdb.FindOptions) []int {
+	lo := opts.Offset
+	if lo > n {
+		lo = n
+	}
+	hi := n
+	if opts.Limit > 0 && lo+opts.Limit < hi {
+		hi = lo + opts.Limit
+	}
+
+	idx := make([]int, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+func decodeSCIMUser(r *http.Request) (*scimUser, error) {
+	u := &scimUser{}
+	if err := json.NewDecoder(r.Body).Decode(u); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode SCIM user",
+			Err:  err,
+		}
+	}
+	if u.UserName == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "userName is required",
+		}
+	}
+	return u, nil
+}
+
+func scimStatus(active *bool) influxdb.Status {
+	if active != nil && !*active {
+		return influxdb.Inactive
+	}
+	return influxdb.Active
+}
+
+// handlePostUser is the HTTP handler for the POST /api/v2/scim/v2/Users route.
+func (h *SCIMHandler) handlePostUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	su, err := decodeSCIMUser(r)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	u := &influxdb.User{
+		Name:   su.UserName,
+		Status: scimStatus(su.Active),
+	}
+	if err := h.UserService.CreateUser(ctx, u); err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	h.log.Debug("SCIM user provisioned", zap.String("user", fmt.Sprint(u)))
+
+	if err := encodeSCIMResponse(w, http.StatusCreated, newSCIMUser(u)); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handleGetUser is the HTTP handler for the GET /api/v2/scim/v2/Users/:id route.
+func (h *SCIMHandler) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSCIMID(ctx)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	u, err := h.UserService.FindUserByID(ctx, id)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	if err := encodeSCIMResponse(w, http.StatusOK, newSCIMUser(u)); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handlePutUser is the HTTP handler for the PUT /api/v2/scim/v2/Users/:id route.
+func (h *SCIMHandler) handlePutUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSCIMID(ctx)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	su, err := decodeSCIMUser(r)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	status := scimStatus(su.Active)
+	h.updateUser(ctx, w, r, id, influxdb.UserUpdate{
+		Name:   &su.UserName,
+		Status: &status,
+	})
+}
+
+// handlePatchUser is the HTTP handler for the PATCH /api/v2/scim/v2/Users/:id route.
+func (h *SCIMHandler) handlePatchUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSCIMID(ctx)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	req, err := decodeSCIMPatchRequest(r)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	upd, err := req.userUpdate()
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	h.updateUser(ctx, w, r, id, upd)
+}
+
+func (h *SCIMHandler) updateUser(ctx context.Context, w http.ResponseWriter, r *http.Request, id influxdb.ID, upd influxdb.UserUpdate) {
+	u, err := h.UserService.UpdateUser(ctx, id, upd)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	h.log.Debug("SCIM user updated", zap.String("user", fmt.Sprint(u)))
+
+	if err := encodeSCIMResponse(w, http.StatusOK, newSCIMUser(u)); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handleDeleteUser is the HTTP handler for the DELETE /api/v2/scim/v2/Users/:id route.
+func (h *SCIMHandler) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSCIMID(ctx)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	if err := h.UserService.DeleteUser(ctx, id); err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	h.log.Debug("SCIM user deprovisioned", zap.String("userID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeSCIMPatchRequest(r *http.Request) (*scimPatchRequest, error) {
+	req := &scimPatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode SCIM patch request",
+			Err:  err,
+		}
+	}
+	return req, nil
+}
+
+// userUpdate converts the replace operations of the request into a user
+// update. Identity providers differ in whether they set the path or send
+// the attributes as the value, and some send booleans as strings.
+func (req *scimPatchRequest) userUpdate() (influxdb.UserUpdate, error) {
+	var upd influxdb.UserUpdate
+	for _, op := range req.Operations {
+		if !strings.EqualFold(op.Op, "replace") && !strings.EqualFold(op.Op, "add") {
+			return upd, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("unsupported patch operation %q on user", op.Op),
+			}
+		}
+
+		attrs := map[string]json.RawMessage{}
+		if op.Path != "" {
+			attrs[op.Path] = op.Value
+		} else if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return upd, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "patch operation without path must have an object value",
+				Err:  err,
+			}
+		}
+
+		for k, v := range attrs {
+			switch {
+			case strings.EqualFold(k, "active"):
+				var active interface{}
+				if err := json.Unmarshal(v, &active); err != nil {
+					return upd, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+				}
+				b, err := strconv.ParseBool(fmt.Sprint(active))
+				if err != nil {
+					return upd, &influxdb.Error{
+						Code: influxdb.EInvalid,
+						Msg:  "active must be a boolean",
+						Err:  err,
+					}
+				}
+				status := scimStatus(&b)
+				upd.Status = &status
+			case strings.EqualFold(k, "userName"):
+				var name string
+				if err := json.Unmarshal(v, &name); err != nil {
+					return upd, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+				}
+				upd.Name = &name
+			}
+		}
+	}
+	return upd, nil
+}
+
+func (h *SCIMHandler) newSCIMGroup(ctx context.Context, o *influxdb.Organization) (*scimGroup, error) {
+	urms, _, err := h.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   o.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g := &scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          o.ID.String(),
+		DisplayName: o.Name,
+		Members:     make([]scimMember, 0, len(urms)),
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Location:     fmt.Sprintf("%s/%s", scimGroupsPath, o.ID),
+		},
+	}
+	for _, m := range urms {
+		member := scimMember{Value: m.UserID.String()}
+		if u, err := h.UserService.FindUserByID(ctx, m.UserID); err == nil {
+			member.Display = u.Name
+		}
+		g.Members = append(g.Members, member)
+	}
+	return g, nil
+}
+
+// handleGetGroups is the HTTP handler for the GET /api/v2/scim/v2/Groups route.
+func (h *SCIMHandler) handleGetGroups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name, err := decodeSCIMFilter(r.URL.Query().Get("filter"), "displayName")
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	opts, start, err := decodeSCIMFindOptions(r)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	orgs, _, err := h.OrganizationService.FindOrganizations(ctx, influxdb.OrganizationFilter{Name: name})
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	res := make([]*scimGroup, 0, len(orgs))
+	for _, i := range scimPage(len(orgs), opts) {
+		g, err := h.newSCIMGroup(ctx, orgs[i])
+		if err != nil {
+			h.handleSCIMError(ctx, err, w)
+			return
+		}
+		res = append(res, g)
+	}
+	if err := encodeSCIMResponse(w, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: len(orgs),
+		StartIndex:   start,
+		ItemsPerPage: len(res),
+		Resources:    res,
+	}); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handlePostGroup is the HTTP handler for the POST /api/v2/scim/v2/Groups route.
+func (h *SCIMHandler) handlePostGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sg := &scimGroup{}
+	if err := json.NewDecoder(r.Body).Decode(sg); err != nil {
+		h.handleSCIMError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode SCIM group",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	o := &influxdb.Organization{Name: sg.DisplayName}
+	if err := h.OrganizationService.CreateOrganization(ctx, o); err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	h.log.Debug("SCIM group provisioned", zap.String("org", fmt.Sprint(o)))
+
+	for _, m := range sg.Members {
+		if err := h.addGroupMember(ctx, o.ID, m.Value); err != nil {
+			h.handleSCIMError(ctx, err, w)
+			return
+		}
+	}
+	h.writeGroup(ctx, w, r, http.StatusCreated, o)
+}
+
+// handleGetGroup is the HTTP handler for the GET /api/v2/scim/v2/Groups/:id route.
+func (h *SCIMHandler) handleGetGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSCIMID(ctx)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	o, err := h.OrganizationService.FindOrganizationByID(ctx, id)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	h.writeGroup(ctx, w, r, http.StatusOK, o)
+}
+
+// handlePatchGroup is the HTTP handler for the PATCH /api/v2/scim/v2/Groups/:id
+// route. Members added to the group become members of the organization and
+// members removed from the group lose their access to the organization.
+func (h *SCIMHandler) handlePatchGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeSCIMID(ctx)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	req, err := decodeSCIMPatchRequest(r)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	o, err := h.OrganizationService.FindOrganizationByID(ctx, id)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+
+	for _, op := range req.Operations {
+		if err := h.applyGroupOperation(ctx, o, op); err != nil {
+			h.handleSCIMError(ctx, err, w)
+			return
+		}
+	}
+	h.writeGroup(ctx, w, r, http.StatusOK, o)
+}
+
+func (h *SCIMHandler) applyGroupOperation(ctx context.Context, o *influxdb.Organization, op scimPatchOperation) error {
+	switch {
+	case strings.EqualFold(op.Op, "add") && strings.EqualFold(op.Path, "members"):
+		var members []scimMember
+		if err := json.Unmarshal(op.Value, &members); err != nil {
+			return &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid members", Err: err}
+		}
+		for _, m := range members {
+			if err := h.addGroupMember(ctx, o.ID, m.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case strings.EqualFold(op.Op, "remove"):
+		// Members are either selected by the path, as in
+		// members[value eq "id"], or listed in the value.
+		var values []string
+		if v, err := decodeSCIMFilter(strings.TrimSuffix(strings.TrimPrefix(op.Path, "members["), "]"), "value"); err == nil && v != nil {
+			values = append(values, *v)
+		} else if strings.EqualFold(op.Path, "members") {
+			var members []scimMember
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid members", Err: err}
+			}
+			for _, m := range members {
+				values = append(values, m.Value)
+			}
+		} else {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("unsupported remove path %q on group", op.Path),
+			}
+		}
+
+		for _, v := range values {
+			userID, err := influxdb.IDFromString(v)
+			if err != nil {
+				return &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid member ID", Err: err}
+			}
+			if err := h.UserResourceMappingService.DeleteUserResourceMapping(ctx, o.ID, *userID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+				return err
+			}
+		}
+		return nil
+	case strings.EqualFold(op.Op, "replace") && strings.EqualFold(op.Path, "displayName"):
+		var name string
+		if err := json.Unmarshal(op.Value, &name); err != nil {
+			return &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid displayName", Err: err}
+		}
+		updated, err := h.OrganizationService.UpdateOrganization(ctx, o.ID, influxdb.OrganizationUpdate{Name: &name})
+		if err != nil {
+			return err
+		}
+		*o = *updated
+		return nil
+	}
+
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("unsupported patch operation %q on group", op.Op),
+	}
+}
+
+// addGroupMember makes the user a member of the organization. Users that
+// already have access to the organization are left unchanged.
+func (h *SCIMHandler) addGroupMember(ctx context.Context, orgID influxdb.ID, value string) error {
+	userID, err := influxdb.IDFromString(value)
+	if err != nil {
+		return &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid member ID", Err: err}
+	}
+	if _, err := h.UserService.FindUserByID(ctx, *userID); err != nil {
+		return err
+	}
+
+	_, n, err := h.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   orgID,
+		UserID:       *userID,
+	})
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	return h.UserResourceMappingService.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		UserID:       *userID,
+		UserType:     influxdb.Member,
+		MappingType:  influxdb.UserMappingType,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   orgID,
+	})
+}
+
+func (h *SCIMHandler) writeGroup(ctx context.Context, w http.ResponseWriter, r *http.Request, code int, o *influxdb.Organization) {
+	g, err := h.newSCIMGroup(ctx, o)
+	if err != nil {
+		h.handleSCIMError(ctx, err, w)
+		return
+	}
+	if err := encodeSCIMResponse(w, code, g); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
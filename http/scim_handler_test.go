@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSCIMHandler(t *testing.T) {
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewSCIMHandler(zaptest.NewLogger(t), &SCIMBackend{
+		HTTPErrorHandler:           kithttp.ErrorHandler(0),
+		log:                        zaptest.NewLogger(t),
+		UserService:                svc,
+		OrganizationService:        svc,
+		UserResourceMappingService: svc,
+	})
+
+	do := func(method, path, body string, code int, v interface{}) {
+		t.Helper()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != code {
+			t.Fatalf("%s %s: unexpected status code: got %d, exp %d: %s", method, path, w.Code, code, w.Body.String())
+		}
+		if v != nil {
+			if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var user scimUser
+	do("POST", scimUsersPath, `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"jdoe","active":true}`, http.StatusCreated, &user)
+	if user.UserName != "jdoe" || user.Active == nil || !*user.Active {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	var list scimListResponse
+	do("GET", scimUsersPath+`?filter=userName+eq+"jdoe"`, "", http.StatusOK, &list)
+	if list.TotalResults != 1 {
+		t.Errorf("expected user to be found by userName, got %d results", list.TotalResults)
+	}
+
+	var group scimGroup
+	do("POST", scimGroupsPath, `{"displayName":"engineering","members":[{"value":"`+user.ID+`"}]}`, http.StatusCreated, &group)
+	if len(group.Members) != 1 || group.Members[0].Value != user.ID || group.Members[0].Display != "jdoe" {
+		t.Fatalf("unexpected group members: %+v", group.Members)
+	}
+
+	do("PATCH", scimGroupsPath+"/"+group.ID, `{"Operations":[{"op":"remove","path":"members[value eq \"`+user.ID+`\"]"}]}`, http.StatusOK, &group)
+	if len(group.Members) != 0 {
+		t.Errorf("expected member to be removed, got %+v", group.Members)
+	}
+
+	// Azure AD deactivates users with string values.
+	do("PATCH", scimUsersPath+"/"+user.ID, `{"Operations":[{"op":"Replace","path":"active","value":"False"}]}`, http.StatusOK, &user)
+	if user.Active == nil || *user.Active {
+		t.Errorf("expected user to be deactivated")
+	}
+	id, err := influxdb.IDFromString(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, err := svc.FindUserByID(ctx, *id); err != nil || u.Status != influxdb.Inactive {
+		t.Errorf("expected stored user to be inactive: %v, %v", u, err)
+	}
+
+	var scimErr scimError
+	do("GET", scimUsersPath+`?filter=emails+co+"x"`, "", http.StatusBadRequest, &scimErr)
+	if scimErr.Status != "400" || len(scimErr.Schemas) != 1 || scimErr.Schemas[0] != scimErrorSchema {
+		t.Errorf("unexpected error response: %+v", scimErr)
+	}
+
+	do("DELETE", scimUsersPath+"/"+user.ID, "", http.StatusNoContent, nil)
+	do("GET", scimUsersPath+"/"+user.ID, "", http.StatusNotFound, nil)
+}
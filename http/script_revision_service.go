@@ -0,0 +1,388 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/andreyvit/diff"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	pctx "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// ScriptRevisionBackend is all services and associated parameters required to construct
+// the ScriptRevisionHandler.
+type ScriptRevisionBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ScriptRevisionService influxdb.ScriptRevisionService
+	TaskService           influxdb.TaskService
+	CheckService          influxdb.CheckService
+	NotificationRuleStore influxdb.NotificationRuleStore
+}
+
+// NewScriptRevisionBackend returns a new instance of ScriptRevisionBackend.
+func NewScriptRevisionBackend(log *zap.Logger, b *APIBackend) *ScriptRevisionBackend {
+	return &ScriptRevisionBackend{
+		HTTPErrorHandler:      b.HTTPErrorHandler,
+		log:                   log,
+		ScriptRevisionService: b.ScriptRevisionService,
+		TaskService:           b.TaskService,
+		CheckService:          b.CheckService,
+		NotificationRuleStore: b.NotificationRuleStore,
+	}
+}
+
+// ScriptRevisionHandler represents an HTTP API handler for the revisions of
+// the scripts of tasks, checks and notification rules.
+type ScriptRevisionHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ScriptRevisionService influxdb.ScriptRevisionService
+	TaskService           influxdb.TaskService
+	CheckService          influxdb.CheckService
+	NotificationRuleStore influxdb.NotificationRuleStore
+}
+
+const (
+	prefixScriptRevisions       = "/api/v2/scriptRevisions"
+	scriptRevisionsIDPath       = "/api/v2/scriptRevisions/:id"
+	scriptRevisionsRollbackPath = "/api/v2/scriptRevisions/:id/rollback"
+)
+
+// NewScriptRevisionHandler returns a new instance of ScriptRevisionHandler.
+func NewScriptRevisionHandler(log *zap.Logger, b *ScriptRevisionBackend) *ScriptRevisionHandler {
+	h := &ScriptRevisionHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		ScriptRevisionService: b.ScriptRevisionService,
+		TaskService:           b.TaskService,
+		CheckService:          b.CheckService,
+		NotificationRuleStore: b.NotificationRuleStore,
+	}
+
+	h.HandlerFunc("GET", prefixScriptRevisions, h.handleGetScriptRevisions)
+	h.HandlerFunc("GET", scriptRevisionsIDPath, h.handleGetScriptRevision)
+	h.HandlerFunc("POST", scriptRevisionsRollbackPath, h.handlePostScriptRevisionRollback)
+
+	return h
+}
+
+type scriptRevisionResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.ScriptRevision
+	// Diff is the line diff of the script from the previous revision.
+	Diff string `json:"diff,omitempty"`
+}
+
+func newScriptRevisionResponse(rev, prev *influxdb.ScriptRevision) *scriptRevisionResponse {
+	res := &scriptRevisionResponse{
+		Links: map[string]string{
+			"self":     fmt.Sprintf("/api/v2/scriptRevisions/%s", rev.ID),
+			"rollback": fmt.Sprintf("/api/v2/scriptRevisions/%s/rollback", rev.ID),
+			"resource": fmt.Sprintf("/api/v2/%s/%s", rev.ResourceType, rev.ResourceID),
+		},
+		ScriptRevision: *rev,
+	}
+	if prev != nil {
+		res.Diff = diff.LineDiff(prev.Script, rev.Script)
+	}
+	return res
+}
+
+type scriptRevisionsResponse struct {
+	Links     map[string]string         `json:"links"`
+	Revisions []*scriptRevisionResponse `json:"revisions"`
+}
+
+func newScriptRevisionsResponse(resourceID influxdb.ID, revs []*influxdb.ScriptRevision) *scriptRevisionsResponse {
+	res := &scriptRevisionsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s?resourceID=%s", prefixScriptRevisions, resourceID),
+		},
+		Revisions: make([]*scriptRevisionResponse, 0, len(revs)),
+	}
+	for i, rev := range revs {
+		var prev *influxdb.ScriptRevision
+		if i > 0 {
+			prev = revs[i-1]
+		}
+		res.Revisions = append(res.Revisions, newScriptRevisionResponse(rev, prev))
+	}
+	return res
+}
+
+func decodeGetScriptRevisionsRequest(r *http.Request) (*influxdb.ScriptRevisionFilter, error) {
+	v := r.URL.Query().Get("resourceID")
+	if v == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "resourceID is required",
+		}
+	}
+	id, err := influxdb.IDFromString(v)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid resourceID",
+			Err:  err,
+		}
+	}
+	return &influxdb.ScriptRevisionFilter{ResourceID: *id}, nil
+}
+
+// handleGetScriptRevisions is the HTTP handler for the GET /api/v2/scriptRevisions route.
+func (h *ScriptRevisionHandler) handleGetScriptRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetScriptRevisionsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	revs, err := h.ScriptRevisionService.FindScriptRevisions(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Script revisions retrieved", zap.Int("count", len(revs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newScriptRevisionsResponse(filter.ResourceID, revs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeScriptRevisionID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// previousScriptRevision returns the revision before rev, or nil if rev is
+// the first one kept.
+func (h *ScriptRevisionHandler) previousScriptRevision(ctx context.Context, rev *influxdb.ScriptRevision) (*influxdb.ScriptRevision, error) {
+	revs, err := h.ScriptRevisionService.FindScriptRevisions(ctx, influxdb.ScriptRevisionFilter{ResourceID: rev.ResourceID})
+	if err != nil {
+		return nil, err
+	}
+	var prev *influxdb.ScriptRevision
+	for _, r := range revs {
+		if r.Version >= rev.Version {
+			break
+		}
+		prev = r
+	}
+	return prev, nil
+}
+
+// handleGetScriptRevision is the HTTP handler for the GET /api/v2/scriptRevisions/:id route.
+func (h *ScriptRevisionHandler) handleGetScriptRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeScriptRevisionID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rev, err := h.ScriptRevisionService.FindScriptRevisionByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	prev, err := h.previousScriptRevision(ctx, rev)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Script revision retrieved", zap.String("scriptRevisionID", id.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newScriptRevisionResponse(rev, prev)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostScriptRevisionRollback is the HTTP handler for the POST /api/v2/scriptRevisions/:id/rollback route.
+// It restores the script of the revision to its resource, which records it as
+// its latest revision.
+func (h *ScriptRevisionHandler) handlePostScriptRevisionRollback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeScriptRevisionID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rev, err := h.ScriptRevisionService.FindScriptRevisionByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.rollback(ctx, rev); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Script revision rolled back", zap.String("scriptRevisionID", id.String()))
+
+	revs, err := h.ScriptRevisionService.FindScriptRevisions(ctx, influxdb.ScriptRevisionFilter{ResourceID: rev.ResourceID})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	res := newScriptRevisionsResponse(rev.ResourceID, revs)
+	if len(res.Revisions) == 0 {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "rollback did not record a revision",
+		}, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res.Revisions[len(res.Revisions)-1]); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// rollback updates the resource of rev with its script.
+func (h *ScriptRevisionHandler) rollback(ctx context.Context, rev *influxdb.ScriptRevision) error {
+	switch rev.ResourceType {
+	case influxdb.TasksResourceType:
+		_, err := h.TaskService.UpdateTask(ctx, rev.ResourceID, influxdb.TaskUpdate{Flux: &rev.Script})
+		return err
+	case influxdb.ChecksResourceType:
+		chk, err := check.UnmarshalJSON([]byte(rev.Script))
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  "malformed check revision",
+				Err:  err,
+			}
+		}
+		current, err := h.CheckService.FindCheckByID(ctx, rev.ResourceID)
+		if err != nil {
+			return err
+		}
+		status, err := h.taskStatus(ctx, current.GetTaskID())
+		if err != nil {
+			return err
+		}
+		_, err = h.CheckService.UpdateCheck(ctx, rev.ResourceID, influxdb.CheckCreate{
+			Check:  chk,
+			Status: status,
+		})
+		return err
+	case influxdb.NotificationRuleResourceType:
+		nr, err := rule.UnmarshalJSON([]byte(rev.Script))
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  "malformed notification rule revision",
+				Err:  err,
+			}
+		}
+		current, err := h.NotificationRuleStore.FindNotificationRuleByID(ctx, rev.ResourceID)
+		if err != nil {
+			return err
+		}
+		status, err := h.taskStatus(ctx, current.GetTaskID())
+		if err != nil {
+			return err
+		}
+		auth, err := pctx.GetAuthorizer(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = h.NotificationRuleStore.UpdateNotificationRule(ctx, rev.ResourceID, influxdb.NotificationRuleCreate{
+			NotificationRule: nr,
+			Status:           status,
+		}, auth.GetUserID())
+		return err
+	}
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("cannot roll back revisions of %s", rev.ResourceType),
+	}
+}
+
+// taskStatus returns the status of the task of a check or notification rule,
+// which is kept by a rollback.
+func (h *ScriptRevisionHandler) taskStatus(ctx context.Context, taskID influxdb.ID) (influxdb.Status, error) {
+	t, err := h.TaskService.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	return influxdb.Status(t.Status), nil
+}
+
+// ScriptRevisionService connects to Influx via HTTP using tokens to find script revisions.
+type ScriptRevisionService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.ScriptRevisionService = (*ScriptRevisionService)(nil)
+
+// FindScriptRevisionByID returns a single script revision by ID.
+func (s *ScriptRevisionService) FindScriptRevisionByID(ctx context.Context, id influxdb.ID) (*influxdb.ScriptRevision, error) {
+	var sr scriptRevisionResponse
+	err := s.Client.
+		Get(path.Join(prefixScriptRevisions, id.String())).
+		DecodeJSON(&sr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sr.ScriptRevision, nil
+}
+
+// FindScriptRevisions returns the revisions of the script of a resource.
+func (s *ScriptRevisionService) FindScriptRevisions(ctx context.Context, filter influxdb.ScriptRevisionFilter) ([]*influxdb.ScriptRevision, error) {
+	var sr scriptRevisionsResponse
+	err := s.Client.
+		Get(prefixScriptRevisions).
+		QueryParams([2]string{"resourceID", filter.ResourceID.String()}).
+		DecodeJSON(&sr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revs := make([]*influxdb.ScriptRevision, 0, len(sr.Revisions))
+	for _, rev := range sr.Revisions {
+		revs = append(revs, &rev.ScriptRevision)
+	}
+	return revs, nil
+}
+
+// RollbackScriptRevision restores the script of a revision to its resource and
+// returns the revision recorded by the rollback.
+func (s *ScriptRevisionService) RollbackScriptRevision(ctx context.Context, id influxdb.ID) (*influxdb.ScriptRevision, error) {
+	var sr scriptRevisionResponse
+	err := s.Client.
+		Post(nil, path.Join(prefixScriptRevisions, id.String(), "rollback")).
+		DecodeJSON(&sr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sr.ScriptRevision, nil
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/http/metric"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixSignedQuery = "/api/v2/query/signed"
+	signedQueryPath   = "/api/v2/query/signed/:signature"
+)
+
+type signedQueryRequest struct {
+	OrgID     influxdb.ID `json:"orgID"`
+	BucketID  influxdb.ID `json:"bucketID"`
+	Query     string      `json:"query"`
+	ExpiresAt *time.Time  `json:"expiresAt,omitempty"`
+	// ExpiresIn is a duration such as "24h" and is used if ExpiresAt is not set.
+	ExpiresIn string `json:"expiresIn,omitempty"`
+}
+
+type signedQueryResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (r signedQueryRequest) signedQuery(now time.Time) (*influxdb.SignedQuery, error) {
+	q := &influxdb.SignedQuery{
+		OrgID:    r.OrgID,
+		BucketID: r.BucketID,
+		Query:    r.Query,
+	}
+
+	switch {
+	case r.ExpiresAt != nil:
+		q.ExpiresAt = *r.ExpiresAt
+	case r.ExpiresIn != "":
+		d, err := time.ParseDuration(r.ExpiresIn)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid expiresIn duration",
+				Err:  err,
+			}
+		}
+		q.ExpiresAt = now.Add(d)
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "expiresAt or expiresIn is required",
+		}
+	}
+	return q, q.Valid(now)
+}
+
+// handlePostSignedQuery is the HTTP handler for the POST /api/v2/query/signed route.
+func (h *FluxHandler) handlePostSignedQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	var req signedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	q, err := req.signedQuery(h.Now())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	signature, err := h.SignedQueryService.SignQuery(ctx, q)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query signed",
+		zap.String("orgID", q.OrgID.String()),
+		zap.String("bucketID", q.BucketID.String()),
+		zap.Time("expiresAt", q.ExpiresAt))
+
+	res := signedQueryResponse{
+		URL:       fmt.Sprintf("%s/%s", prefixSignedQuery, signature),
+		ExpiresAt: q.ExpiresAt,
+	}
+	if err := encodeResponse(ctx, w, http.StatusCreated, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetSignedQuery is the HTTP handler for the GET /api/v2/query/signed/:signature
+// route. It requires no token; the query runs with read access to the bucket
+// of the signed query only.
+func (h *FluxHandler) handleGetSignedQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	log := h.log.With(logger.TraceFields(ctx)...)
+
+	var orgID influxdb.ID
+	start := h.Now()
+	sw := kithttp.NewStatusResponseWriter(w)
+	w = sw
+	defer func() {
+		h.EventRecorder.Record(ctx, metric.Event{
+			OrgID:         orgID,
+			Endpoint:      prefixSignedQuery,
+			ResponseBytes: sw.ResponseBytes(),
+			Status:        sw.Code(),
+			Duration:      h.Now().Sub(start),
+		})
+	}()
+
+	signature := httprouter.ParamsFromContext(ctx).ByName("signature")
+	q, err := h.SignedQueryService.VerifySignedQuery(ctx, signature)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	orgID = q.OrgID
+
+	qr := QueryRequest{
+		Query: q.Query,
+		Org:   &influxdb.Organization{ID: q.OrgID},
+	}.WithDefaults()
+	req, err := qr.proxyRequest(h.Now)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid signed query",
+			Err:  err,
+		}, w)
+		return
+	}
+	req.Request.Authorization = q.Authorization()
+	req.Request.Source = r.Header.Get("User-Agent")
+	ctx = pcontext.SetAuthorizer(ctx, req.Request.Authorization)
+
+	if hd, ok := req.Dialect.(HTTPDialect); ok {
+		hd.SetHeaders(w)
+	}
+
+	cw := iocounter.Writer{Writer: w}
+	if _, err := h.ProxyQueryService.Query(ctx, &cw, req); err != nil {
+		if cw.Count() == 0 {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		_ = tracing.LogError(span, err)
+		log.Info("Error writing response to client",
+			zap.String("handler", "flux"),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,280 @@
+package http
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/slackapp"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSlackInteractionSize bounds the body of the interaction requests of slack.
+	maxSlackInteractionSize = 1 << 20
+	// slackAPITimeout bounds the calls to the Web API of slack made when
+	// handling an interaction.
+	slackAPITimeout = 10 * time.Second
+)
+
+// SlackAppBackend is all services and associated parameters required to construct
+// the SlackAppHandler.
+type SlackAppBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	SlackThreadService influxdb.SlackThreadService
+
+	// The interactions of slack are not authorized by a token but by the
+	// signing secret of their endpoint, so they are handled with services
+	// which are not wrapped by an authorizer.
+	InteractionSlackThreadService influxdb.SlackThreadService
+	NotificationEndpointService   influxdb.NotificationEndpointService
+	SecretService                 influxdb.SecretService
+	HTTPClient                    *http.Client
+}
+
+// NewSlackAppBackend returns a new instance of SlackAppBackend.
+func NewSlackAppBackend(log *zap.Logger, b *APIBackend) *SlackAppBackend {
+	return &SlackAppBackend{
+		HTTPErrorHandler:              b.HTTPErrorHandler,
+		log:                           log,
+		SlackThreadService:            b.SlackThreadService,
+		InteractionSlackThreadService: b.SlackThreadService,
+		NotificationEndpointService:   b.NotificationEndpointService,
+		SecretService:                 b.SecretService,
+		HTTPClient:                    &http.Client{Timeout: slackAPITimeout},
+	}
+}
+
+// SlackAppHandler represents an HTTP API handler for the slack threads of
+// the alerts of slack app endpoints, and for the interactions of users with
+// their messages.
+type SlackAppHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	SlackThreadService            influxdb.SlackThreadService
+	InteractionSlackThreadService influxdb.SlackThreadService
+	NotificationEndpointService   influxdb.NotificationEndpointService
+	SecretService                 influxdb.SecretService
+	HTTPClient                    *http.Client
+}
+
+const (
+	prefixSlack             = "/api/v2/slack"
+	slackThreadsPath        = "/api/v2/slack/threads"
+	slackInteractionsIDPath = "/api/v2/slack/interactions/:endpointID"
+)
+
+// NewSlackAppHandler returns a new instance of SlackAppHandler.
+func NewSlackAppHandler(log *zap.Logger, b *SlackAppBackend) *SlackAppHandler {
+	h := &SlackAppHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		SlackThreadService:            b.SlackThreadService,
+		InteractionSlackThreadService: b.InteractionSlackThreadService,
+		NotificationEndpointService:   b.NotificationEndpointService,
+		SecretService:                 b.SecretService,
+		HTTPClient:                    b.HTTPClient,
+	}
+
+	h.HandlerFunc("GET", slackThreadsPath, h.handleGetSlackThreads)
+	h.HandlerFunc("POST", slackInteractionsIDPath, h.handlePostSlackInteraction)
+
+	return h
+}
+
+type slackThreadsResponse struct {
+	Links   map[string]string       `json:"links"`
+	Threads []*influxdb.SlackThread `json:"threads"`
+}
+
+func decodeSlackThreadFilter(r *http.Request) (*influxdb.SlackThreadFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.SlackThreadFilter{}
+
+	orgID, err := influxdb.IDFromString(qp.Get("orgID"))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	filter.OrgID = orgID
+
+	if v := qp.Get("endpointID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid endpointID",
+				Err:  err,
+			}
+		}
+		filter.EndpointID = id
+	}
+	return filter, nil
+}
+
+// handleGetSlackThreads is the HTTP handler for the GET /api/v2/slack/threads route.
+func (h *SlackAppHandler) handleGetSlackThreads(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeSlackThreadFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ts, err := h.SlackThreadService.FindSlackThreads(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Slack threads retrieved", zap.Int("count", len(ts)))
+
+	qp := url.Values{}
+	qp.Set("orgID", filter.OrgID.String())
+	if filter.EndpointID != nil {
+		qp.Set("endpointID", filter.EndpointID.String())
+	}
+	res := &slackThreadsResponse{
+		Links: map[string]string{
+			"self": slackThreadsPath + "?" + qp.Encode(),
+		},
+		Threads: ts,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostSlackInteraction is the HTTP handler for the POST /api/v2/slack/interactions/:endpointID route,
+// the interactivity request URL of the slack app of the endpoint.
+func (h *SlackAppHandler) handlePostSlackInteraction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	endpointID, err := influxdb.IDFromString(httprouter.ParamsFromContext(ctx).ByName("endpointID"))
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid endpointID",
+			Err:  err,
+		}, w)
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSlackInteractionSize))
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	e, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, *endpointID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	s, ok := e.(*endpoint.Slack)
+	if !ok || !s.App || s.SigningSecret.Key == "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "slack app endpoint with a signing secret not found",
+		}, w)
+		return
+	}
+	signingSecret, err := h.SecretService.LoadSecret(ctx, s.GetOrgID(), s.SigningSecret.Key)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := slackapp.VerifyRequest(signingSecret, r.Header, body, time.Now()); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "slack request could not be verified",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode slack interaction",
+			Err:  err,
+		}, w)
+		return
+	}
+	i, err := slackapp.ParseInteraction(form.Get("payload"))
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode slack interaction",
+			Err:  err,
+		}, w)
+		return
+	}
+	v, ok := i.Acknowledgement()
+	if !ok {
+		// Slack expects every interaction to be acknowledged.
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	threadID, err := influxdb.IDFromString(v)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid slack thread ID",
+			Err:  err,
+		}, w)
+		return
+	}
+	t, err := h.InteractionSlackThreadService.FindSlackThreadByID(ctx, *threadID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		// The alert was resolved since its message was posted.
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if t.EndpointID != *endpointID {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrSlackThreadNotFound,
+		}, w)
+		return
+	}
+
+	token, err := h.SecretService.LoadSecret(ctx, s.GetOrgID(), s.Token.Key)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	n := &slackapp.Notifier{
+		Client: &slackapp.Client{
+			HTTPClient: h.HTTPClient,
+			URL:        s.APIURL(),
+			Token:      token,
+		},
+		Threads: h.InteractionSlackThreadService,
+	}
+	if err := n.Acknowledge(ctx, t, i.User.ID, time.Now()); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Slack alert acknowledged", zap.String("thread", t.ID.String()), zap.String("user", i.User.ID))
+
+	w.WriteHeader(http.StatusOK)
+}
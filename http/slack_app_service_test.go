@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSlackAppHandler_handlePostSlackInteraction(t *testing.T) {
+	var methods []string
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer xoxb-token" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		methods = append(methods, strings.TrimPrefix(r.URL.Path, "/"))
+		w.Write([]byte(`{"ok":true,"channel":"C1","ts":"2.0"}`))
+	}))
+	defer slack.Close()
+
+	ctx := context.Background()
+	svc := newInMemKVSVC(t)
+	orgID, endpointID := influxdb.ID(1), influxdb.ID(2)
+	th := &influxdb.SlackThread{
+		OrgID:      orgID,
+		EndpointID: endpointID,
+		AlertKey:   "rule/check/host=a",
+		Channel:    "C1",
+		TS:         "1.0",
+		Level:      "crit",
+	}
+	if err := svc.PutSlackThread(ctx, th); err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints := mock.NewNotificationEndpointService()
+	endpoints.FindNotificationEndpointByIDF = func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+		return &endpoint.Slack{
+			Base:          endpoint.Base{ID: &endpointID, OrgID: &orgID, Name: "slack"},
+			App:           true,
+			URL:           slack.URL,
+			Token:         influxdb.SecretField{Key: "slack-token"},
+			SigningSecret: influxdb.SecretField{Key: "slack-signing-secret"},
+		}, nil
+	}
+	secrets := mock.NewSecretService()
+	secrets.LoadSecretFn = func(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+		return map[string]string{
+			"slack-token":          "xoxb-token",
+			"slack-signing-secret": "secret",
+		}[k], nil
+	}
+
+	h := NewSlackAppHandler(zaptest.NewLogger(t), &SlackAppBackend{
+		HTTPErrorHandler:              kithttp.ErrorHandler(0),
+		log:                           zaptest.NewLogger(t),
+		SlackThreadService:            svc,
+		InteractionSlackThreadService: svc,
+		NotificationEndpointService:   endpoints,
+		SecretService:                 secrets,
+		HTTPClient:                    slack.Client(),
+	})
+
+	body := url.Values{
+		"payload": {`{"type":"block_actions","user":{"id":"U1"},"actions":[{"action_id":"acknowledge","value":"` + th.ID.String() + `"}]}`},
+	}.Encode()
+	post := func(secret string) *httptest.ResponseRecorder {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + ts + ":" + body))
+
+		r := httptest.NewRequest("POST", "/api/v2/slack/interactions/"+endpointID.String(), strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("X-Slack-Request-Timestamp", ts)
+		r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := post("other"); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected an interaction with an invalid signature to be unauthorized, got %d", w.Code)
+	}
+	if len(methods) != 0 {
+		t.Errorf("expected no slack calls, got %v", methods)
+	}
+
+	if w := post("secret"); w.Code != http.StatusOK {
+		t.Fatalf("expected the interaction to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	got, err := svc.FindSlackThreadByID(ctx, th.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Acknowledged() || got.AcknowledgedBy != "U1" {
+		t.Errorf("expected the alert to be acknowledged by U1, got %+v", got)
+	}
+	if want := []string{"chat.postMessage", "chat.update"}; strings.Join(methods, ",") != strings.Join(want, ",") {
+		t.Errorf("got slack calls %v, want %v", methods, want)
+	}
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// SMTPConfigBackend is all services and associated parameters required to construct
+// the SMTPConfigHandler.
+type SMTPConfigBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	SMTPConfigService influxdb.SMTPConfigService
+}
+
+// NewSMTPConfigBackend returns a new instance of SMTPConfigBackend.
+func NewSMTPConfigBackend(log *zap.Logger, b *APIBackend) *SMTPConfigBackend {
+	return &SMTPConfigBackend{
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		log:               log,
+		SMTPConfigService: b.SMTPConfigService,
+	}
+}
+
+// SMTPConfigHandler represents an HTTP API handler for the SMTP server of the instance.
+type SMTPConfigHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	SMTPConfigService influxdb.SMTPConfigService
+}
+
+const prefixSMTP = "/api/v2/smtp"
+
+// NewSMTPConfigHandler returns a new instance of SMTPConfigHandler.
+func NewSMTPConfigHandler(log *zap.Logger, b *SMTPConfigBackend) *SMTPConfigHandler {
+	h := &SMTPConfigHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		SMTPConfigService: b.SMTPConfigService,
+	}
+
+	h.HandlerFunc("GET", prefixSMTP, h.handleGetSMTPConfig)
+	h.HandlerFunc("PUT", prefixSMTP, h.handlePutSMTPConfig)
+	h.HandlerFunc("DELETE", prefixSMTP, h.handleDeleteSMTPConfig)
+
+	return h
+}
+
+type smtpConfigResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.SMTPConfig
+}
+
+func newSMTPConfigResponse(c *influxdb.SMTPConfig) *smtpConfigResponse {
+	return &smtpConfigResponse{
+		Links: map[string]string{
+			"self": prefixSMTP,
+		},
+		SMTPConfig: *c,
+	}
+}
+
+type putSMTPConfigRequest struct {
+	Host               string `json:"host"`
+	Port               int    `json:"port,omitempty"`
+	TLS                string `json:"tls,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	Username           string `json:"username,omitempty"`
+	Password           string `json:"password,omitempty"`
+	From               string `json:"from"`
+}
+
+func (r putSMTPConfigRequest) toSMTPConfig() *influxdb.SMTPConfig {
+	c := &influxdb.SMTPConfig{
+		Host:               r.Host,
+		Port:               r.Port,
+		TLS:                r.TLS,
+		InsecureSkipVerify: r.InsecureSkipVerify,
+		Username:           r.Username,
+		From:               r.From,
+	}
+	if r.Password != "" {
+		c.Password.Value = &r.Password
+	}
+	return c
+}
+
+// handleGetSMTPConfig is the HTTP handler for the GET /api/v2/smtp route.
+func (h *SMTPConfigHandler) handleGetSMTPConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	c, err := h.SMTPConfigService.FindSMTPConfig(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("SMTP config retrieved", zap.String("host", c.Host))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSMTPConfigResponse(c)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePutSMTPConfig is the HTTP handler for the PUT /api/v2/smtp route.
+func (h *SMTPConfigHandler) handlePutSMTPConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req putSMTPConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode smtp config request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	c := req.toSMTPConfig()
+	if err := h.SMTPConfigService.PutSMTPConfig(ctx, c); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("SMTP config updated", zap.String("host", c.Host))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSMTPConfigResponse(c)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteSMTPConfig is the HTTP handler for the DELETE /api/v2/smtp route.
+func (h *SMTPConfigHandler) handleDeleteSMTPConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.SMTPConfigService.DeleteSMTPConfig(ctx); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("SMTP config deleted")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SMTPConfigService connects to Influx via HTTP using tokens to manage the
+// SMTP server of the instance.
+type SMTPConfigService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.SMTPConfigService = (*SMTPConfigService)(nil)
+
+// FindSMTPConfig returns the SMTP server of the instance.
+func (s *SMTPConfigService) FindSMTPConfig(ctx context.Context) (*influxdb.SMTPConfig, error) {
+	var res smtpConfigResponse
+	err := s.Client.
+		Get(prefixSMTP).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.SMTPConfig, nil
+}
+
+// PutSMTPConfig replaces the SMTP server of the instance.
+func (s *SMTPConfigService) PutSMTPConfig(ctx context.Context, c *influxdb.SMTPConfig) error {
+	req := putSMTPConfigRequest{
+		Host:               c.Host,
+		Port:               c.Port,
+		TLS:                c.TLS,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		Username:           c.Username,
+		From:               c.From,
+	}
+	if c.Password.Value != nil {
+		req.Password = *c.Password.Value
+	}
+
+	var res smtpConfigResponse
+	err := s.Client.
+		PutJSON(req, prefixSMTP).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*c = res.SMTPConfig
+	return nil
+}
+
+// DeleteSMTPConfig removes the SMTP server of the instance.
+func (s *SMTPConfigService) DeleteSMTPConfig(ctx context.Context) error {
+	return s.Client.
+		Delete(prefixSMTP).
+		Do(ctx)
+}
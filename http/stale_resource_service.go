@@ -0,0 +1,295 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// StaleResourceBackend is all services and associated parameters required to construct
+// the StaleResourceHandler.
+type StaleResourceBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	StaleResourceReportService influxdb.StaleResourceReportService
+}
+
+// NewStaleResourceBackend returns a new instance of StaleResourceBackend.
+func NewStaleResourceBackend(log *zap.Logger, b *APIBackend) *StaleResourceBackend {
+	return &StaleResourceBackend{
+		HTTPErrorHandler:           b.HTTPErrorHandler,
+		log:                        log,
+		StaleResourceReportService: b.StaleResourceReportService,
+	}
+}
+
+// StaleResourceHandler represents an HTTP API handler for stale resource reports.
+type StaleResourceHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	StaleResourceReportService influxdb.StaleResourceReportService
+}
+
+const (
+	prefixStaleResources = "/api/v2/staleresources"
+	staleResourcesIDPath = "/api/v2/staleresources/:id"
+)
+
+// NewStaleResourceHandler returns a new instance of StaleResourceHandler.
+func NewStaleResourceHandler(log *zap.Logger, b *StaleResourceBackend) *StaleResourceHandler {
+	h := &StaleResourceHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		StaleResourceReportService: b.StaleResourceReportService,
+	}
+
+	h.HandlerFunc("POST", prefixStaleResources, h.handlePostStaleResourceReport)
+	h.HandlerFunc("GET", prefixStaleResources, h.handleGetStaleResourceReports)
+	h.HandlerFunc("GET", staleResourcesIDPath, h.handleGetStaleResourceReport)
+
+	return h
+}
+
+type staleResourceReportResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.StaleResourceReport
+}
+
+func newStaleResourceReportResponse(rep *influxdb.StaleResourceReport) *staleResourceReportResponse {
+	return &staleResourceReportResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/staleresources/%s", rep.ID),
+		},
+		StaleResourceReport: *rep,
+	}
+}
+
+type staleResourceReportsResponse struct {
+	Links   map[string]string              `json:"links"`
+	Reports []*staleResourceReportResponse `json:"reports"`
+}
+
+func newStaleResourceReportsResponse(rs []*influxdb.StaleResourceReport) *staleResourceReportsResponse {
+	res := &staleResourceReportsResponse{
+		Links: map[string]string{
+			"self": prefixStaleResources,
+		},
+		Reports: make([]*staleResourceReportResponse, 0, len(rs)),
+	}
+	for _, rep := range rs {
+		res.Reports = append(res.Reports, newStaleResourceReportResponse(rep))
+	}
+	return res
+}
+
+type postStaleResourceReportRequest struct {
+	Days int `json:"days"`
+}
+
+// handlePostStaleResourceReport is the HTTP handler for the POST /api/v2/staleresources route.
+func (h *StaleResourceHandler) handlePostStaleResourceReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postStaleResourceReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode stale resource report request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	rep, err := h.StaleResourceReportService.StartStaleResourceReport(ctx, req.Days)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Stale resource report started", zap.Stringer("reportID", rep.ID), zap.Int("days", rep.Days))
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, newStaleResourceReportResponse(rep)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetStaleResourceReports is the HTTP handler for the GET /api/v2/staleresources route.
+func (h *StaleResourceHandler) handleGetStaleResourceReports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter influxdb.StaleResourceReportFilter
+	if v := r.URL.Query().Get("status"); v != "" {
+		status := influxdb.StaleResourceReportStatus(v)
+		filter.Status = &status
+	}
+
+	rs, err := h.StaleResourceReportService.FindStaleResourceReports(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Stale resource reports retrieved", zap.Int("reports", len(rs)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newStaleResourceReportsResponse(rs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetStaleResourceReport is the HTTP handler for the GET /api/v2/staleresources/:id route.
+// The resources of the report are written as CSV if the format is csv or
+// CSV is the media type accepted.
+func (h *StaleResourceHandler) handleGetStaleResourceReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	rep, err := h.StaleResourceReportService.FindStaleResourceReportByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Stale resource report retrieved", zap.Stringer("reportID", rep.ID), zap.Int("resources", len(rep.Resources)))
+
+	if r.URL.Query().Get("format") == "csv" || r.Header.Get("Accept") == "text/csv" {
+		if err := encodeStaleResourcesCSV(w, rep); err != nil {
+			logEncodingError(h.log, r, err)
+		}
+		return
+	}
+
+	if rep.Resources == nil {
+		rep.Resources = []influxdb.StaleResource{}
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newStaleResourceReportResponse(rep)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// encodeStaleResourcesCSV writes a row for each resource of the report.
+// The times of resources which were never active are empty.
+func encodeStaleResourcesCSV(w http.ResponseWriter, rep *influxdb.StaleResourceReport) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="stale-resources-`+rep.ID.String()+`.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"type", "id", "org_id", "name", "created_at", "last_activity", "last_active_at"}); err != nil {
+		return err
+	}
+	for _, res := range rep.Resources {
+		var lastActiveAt string
+		if res.LastActiveAt != nil {
+			lastActiveAt = res.LastActiveAt.UTC().Format(time.RFC3339)
+		}
+		if err := cw.Write([]string{
+			string(res.Type),
+			res.ID.String(),
+			res.OrgID.String(),
+			res.Name,
+			res.CreatedAt.UTC().Format(time.RFC3339),
+			string(res.LastActivity),
+			lastActiveAt,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// recordResourceActivity records the activity of the resource in s, if it
+// is set. Failing to record activity must not fail the request.
+func recordResourceActivity(ctx context.Context, log *zap.Logger, s influxdb.ResourceActivityService, rt influxdb.ResourceType, id influxdb.ID, kind influxdb.ResourceActivityKind) {
+	if s == nil {
+		return
+	}
+	if err := s.RecordResourceActivity(ctx, rt, id, kind, time.Now()); err != nil {
+		log.Info("Failed to record resource activity",
+			zap.String("resourceType", string(rt)),
+			zap.Stringer("resourceID", id),
+			zap.Error(err))
+	}
+}
+
+// StaleResourceReportService connects to Influx via HTTP using tokens to report stale resources.
+type StaleResourceReportService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.StaleResourceReportService = (*StaleResourceReportService)(nil)
+
+// StartStaleResourceReport starts finding the resources with no activity in
+// the last days days in the background.
+func (s *StaleResourceReportService) StartStaleResourceReport(ctx context.Context, days int) (*influxdb.StaleResourceReport, error) {
+	var rr staleResourceReportResponse
+	err := s.Client.
+		PostJSON(postStaleResourceReportRequest{Days: days}, prefixStaleResources).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rr.StaleResourceReport, nil
+}
+
+// FindStaleResourceReportByID returns a single report by ID.
+func (s *StaleResourceReportService) FindStaleResourceReportByID(ctx context.Context, id influxdb.ID) (*influxdb.StaleResourceReport, error) {
+	var rr staleResourceReportResponse
+	err := s.Client.
+		Get(path.Join(prefixStaleResources, id.String())).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rr.StaleResourceReport, nil
+}
+
+// FindStaleResourceReports returns the reports that match filter, without
+// their resources.
+func (s *StaleResourceReportService) FindStaleResourceReports(ctx context.Context, filter influxdb.StaleResourceReportFilter) ([]*influxdb.StaleResourceReport, error) {
+	var params [][2]string
+	if filter.Status != nil {
+		params = append(params, [2]string{"status", string(*filter.Status)})
+	}
+
+	var rr staleResourceReportsResponse
+	err := s.Client.
+		Get(prefixStaleResources).
+		QueryParams(params...).
+		DecodeJSON(&rr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := make([]*influxdb.StaleResourceReport, 0, len(rr.Reports))
+	for _, rep := range rr.Reports {
+		rs = append(rs, &rep.StaleResourceReport)
+	}
+	return rs, nil
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const prefixSystem = "/api/v2/system"
+
+// SystemInfoBackend is all services and associated parameters required to construct
+// the SystemInfoHandler.
+type SystemInfoBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	SystemInfoService influxdb.SystemInfoService
+}
+
+// NewSystemInfoBackend returns a new instance of SystemInfoBackend.
+func NewSystemInfoBackend(log *zap.Logger, b *APIBackend) *SystemInfoBackend {
+	return &SystemInfoBackend{
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		log:               log,
+		SystemInfoService: b.SystemInfoService,
+	}
+}
+
+// SystemInfoHandler represents an HTTP API handler for the build and
+// runtime environment of the server.
+type SystemInfoHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	SystemInfoService influxdb.SystemInfoService
+}
+
+// NewSystemInfoHandler returns a new instance of SystemInfoHandler.
+func NewSystemInfoHandler(log *zap.Logger, b *SystemInfoBackend) *SystemInfoHandler {
+	h := &SystemInfoHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		SystemInfoService: b.SystemInfoService,
+	}
+
+	h.HandlerFunc("GET", prefixSystem, h.handleGetSystemInfo)
+
+	return h
+}
+
+type systemInfoResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.SystemInfo
+}
+
+// handleGetSystemInfo is the HTTP handler for the GET /api/v2/system route.
+func (h *SystemInfoHandler) handleGetSystemInfo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	info, err := h.SystemInfoService.FindSystemInfo(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("System info retrieved")
+
+	res := &systemInfoResponse{
+		Links: map[string]string{
+			"self":   prefixSystem,
+			"health": HealthPath,
+			"ready":  ReadyPath,
+		},
+		SystemInfo: *info,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// SystemInfoService connects to Influx via HTTP using tokens to find the
+// build and runtime environment of the server.
+type SystemInfoService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.SystemInfoService = (*SystemInfoService)(nil)
+
+// FindSystemInfo returns the build and runtime environment of the server.
+func (s *SystemInfoService) FindSystemInfo(ctx context.Context) (*influxdb.SystemInfo, error) {
+	var res systemInfoResponse
+	err := s.Client.
+		Get(prefixSystem).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.SystemInfo, nil
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+type systemInfoService struct {
+	info *influxdb.SystemInfo
+}
+
+func (s *systemInfoService) FindSystemInfo(ctx context.Context) (*influxdb.SystemInfo, error) {
+	return s.info, nil
+}
+
+func TestSystemInfoHandler_handleGetSystemInfo(t *testing.T) {
+	svc := &systemInfoService{info: &influxdb.SystemInfo{
+		Build:          influxdb.BuildInfo{Version: "2.0.0", Commit: "abc123"},
+		FluxVersion:    "v0.90.0",
+		StorageFormats: map[string]int{"tsm": 1},
+		Runtime:        influxdb.SystemRuntime{GOMAXPROCS: 2},
+		Cgroup:         &influxdb.SystemCgroupLimits{Version: 2, CPUs: 1.5},
+	}}
+	h := NewSystemInfoHandler(zaptest.NewLogger(t), &SystemInfoBackend{
+		HTTPErrorHandler:  kithttp.ErrorHandler(0),
+		SystemInfoService: svc,
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v2/system", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", w.Code)
+	}
+
+	var res systemInfoResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Build.Commit != "abc123" || res.FluxVersion != "v0.90.0" || res.StorageFormats["tsm"] != 1 {
+		t.Errorf("unexpected system info %+v", res.SystemInfo)
+	}
+	if res.Runtime.GOMAXPROCS != 2 || res.Cgroup == nil || res.Cgroup.CPUs != 1.5 {
+		t.Errorf("unexpected runtime %+v, cgroup %+v", res.Runtime, res.Cgroup)
+	}
+	if res.Links["health"] != HealthPath {
+		t.Errorf("expected a link to health, got %v", res.Links)
+	}
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+type userPreferencesResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.UserPreferences
+}
+
+func newUserPreferencesResponse(p *influxdb.UserPreferences) *userPreferencesResponse {
+	return &userPreferencesResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/users/%s/preferences", p.UserID),
+			"user": fmt.Sprintf("/api/v2/users/%s", p.UserID),
+		},
+		UserPreferences: *p,
+	}
+}
+
+// decodeUserPreferencesUserID returns the user of the /api/v2/users/:id/preferences
+// route, or the user of the authorizer on context for the /api/v2/me/preferences route.
+func decodeUserPreferencesUserID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	if id := params.ByName("id"); id != "" {
+		var i influxdb.ID
+		if err := i.DecodeFromString(id); err != nil {
+			return 0, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid user ID provided in route",
+				Err:  err,
+			}
+		}
+		return i, nil
+	}
+
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return a.GetUserID(), nil
+}
+
+// handleGetUserPreferences is the HTTP handler for the GET /api/v2/users/:id/preferences
+// and GET /api/v2/me/preferences routes.
+func (h *UserHandler) handleGetUserPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := decodeUserPreferencesUserID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	p, err := h.UserPreferencesService.FindUserPreferences(ctx, userID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("User preferences retrieved", zap.String("preferences", fmt.Sprint(p)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newUserPreferencesResponse(p)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// patchUserPreferencesRequest is the update of the preferences of a user.
+// An empty default organization ID resets the default organization.
+type patchUserPreferencesRequest struct {
+	Timezone     *string               `json:"timezone,omitempty"`
+	DefaultOrgID *string               `json:"defaultOrgID,omitempty"`
+	AlertDigest  *influxdb.AlertDigest `json:"alertDigest,omitempty"`
+	UIDensity    *influxdb.UIDensity   `json:"uiDensity,omitempty"`
+}
+
+func newPatchUserPreferencesRequest(upd influxdb.UserPreferencesUpdate) patchUserPreferencesRequest {
+	req := patchUserPreferencesRequest{
+		Timezone:    upd.Timezone,
+		AlertDigest: upd.AlertDigest,
+		UIDensity:   upd.UIDensity,
+	}
+	if upd.DefaultOrgID != nil {
+		var id string
+		if upd.DefaultOrgID.Valid() {
+			id = upd.DefaultOrgID.String()
+		}
+		req.DefaultOrgID = &id
+	}
+	return req
+}
+
+func (req patchUserPreferencesRequest) update() (influxdb.UserPreferencesUpdate, error) {
+	upd := influxdb.UserPreferencesUpdate{
+		Timezone:    req.Timezone,
+		AlertDigest: req.AlertDigest,
+		UIDensity:   req.UIDensity,
+	}
+	if req.DefaultOrgID != nil {
+		var id influxdb.ID
+		if *req.DefaultOrgID != "" {
+			if err := id.DecodeFromString(*req.DefaultOrgID); err != nil {
+				return upd, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "invalid defaultOrgID",
+					Err:  err,
+				}
+			}
+		}
+		upd.DefaultOrgID = &id
+	}
+	return upd, nil
+}
+
+// handlePatchUserPreferences is the HTTP handler for the PATCH /api/v2/users/:id/preferences
+// and PATCH /api/v2/me/preferences routes.
+func (h *UserHandler) handlePatchUserPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := decodeUserPreferencesUserID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req patchUserPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode user preferences update",
+			Err:  err,
+		}, w)
+		return
+	}
+	upd, err := req.update()
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	p, err := h.UserPreferencesService.UpdateUserPreferences(ctx, userID, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("User preferences updated", zap.String("preferences", fmt.Sprint(p)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newUserPreferencesResponse(p)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// UserPreferencesService connects to Influx via HTTP using tokens to manage
+// the preferences of users.
+type UserPreferencesService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.UserPreferencesService = (*UserPreferencesService)(nil)
+
+// FindUserPreferences returns the preferences of a user.
+func (s *UserPreferencesService) FindUserPreferences(ctx context.Context, userID influxdb.ID) (*influxdb.UserPreferences, error) {
+	var res userPreferencesResponse
+	err := s.Client.
+		Get(prefixUsers, userID.String(), "preferences").
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.UserPreferences, nil
+}
+
+// UpdateUserPreferences updates the preferences of a user with changeset.
+func (s *UserPreferencesService) UpdateUserPreferences(ctx context.Context, userID influxdb.ID, upd influxdb.UserPreferencesUpdate) (*influxdb.UserPreferences, error) {
+	var res userPreferencesResponse
+	err := s.Client.
+		PatchJSON(newPatchUserPreferencesRequest(upd), prefixUsers, userID.String(), "preferences").
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res.UserPreferences, nil
+}
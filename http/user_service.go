@@ -21,6 +21,8 @@ type UserBackend struct {
 	UserService             influxdb.UserService
 	UserOperationLogService influxdb.UserOperationLogService
 	PasswordsService        influxdb.PasswordsService
+	PasswordLockoutService  influxdb.PasswordLockoutService
+	UserPreferencesService  influxdb.UserPreferencesService
 }
 
 // NewUserBackend creates a UserBackend using information in the APIBackend.
@@ -31,6 +33,8 @@ func NewUserBackend(log *zap.Logger, b *APIBackend) *UserBackend {
 		UserService:             b.UserService,
 		UserOperationLogService: b.UserOperationLogService,
 		PasswordsService:        b.PasswordsService,
+		PasswordLockoutService:  b.PasswordLockoutService,
+		UserPreferencesService:  b.UserPreferencesService,
 	}
 }
 
@@ -42,15 +46,20 @@ type UserHandler struct {
 	UserService             influxdb.UserService
 	UserOperationLogService influxdb.UserOperationLogService
 	PasswordsService        influxdb.PasswordsService
+	PasswordLockoutService  influxdb.PasswordLockoutService
+	UserPreferencesService  influxdb.UserPreferencesService
 }
 
 const (
-	prefixUsers       = "/api/v2/users"
-	prefixMe          = "/api/v2/me"
-	mePasswordPath    = "/api/v2/me/password"
-	usersIDPath       = "/api/v2/users/:id"
-	usersPasswordPath = "/api/v2/users/:id/password"
-	usersLogPath      = "/api/v2/users/:id/logs"
+	prefixUsers          = "/api/v2/users"
+	prefixMe             = "/api/v2/me"
+	mePasswordPath       = "/api/v2/me/password"
+	mePreferencesPath    = "/api/v2/me/preferences"
+	usersIDPath          = "/api/v2/users/:id"
+	usersPasswordPath    = "/api/v2/users/:id/password"
+	usersLockoutPath     = "/api/v2/users/:id/lockout"
+	usersPreferencesPath = "/api/v2/users/:id/preferences"
+	usersLogPath         = "/api/v2/users/:id/logs"
 )
 
 // NewUserHandler returns a new instance of UserHandler.
@@ -63,6 +72,8 @@ func NewUserHandler(log *zap.Logger, b *UserBackend) *UserHandler {
 		UserService:             b.UserService,
 		UserOperationLogService: b.UserOperationLogService,
 		PasswordsService:        b.PasswordsService,
+		PasswordLockoutService:  b.PasswordLockoutService,
+		UserPreferencesService:  b.UserPreferencesService,
 	}
 
 	h.HandlerFunc("POST", prefixUsers, h.handlePostUser)
@@ -76,9 +87,15 @@ func NewUserHandler(log *zap.Logger, b *UserBackend) *UserHandler {
 	// removes coupling with userid.
 	h.HandlerFunc("POST", usersPasswordPath, h.handlePostUserPassword)
 	h.HandlerFunc("PUT", usersPasswordPath, h.handlePutUserPassword)
+	h.HandlerFunc("DELETE", usersLockoutPath, h.handleDeleteUserLockout)
+
+	h.HandlerFunc("GET", usersPreferencesPath, h.handleGetUserPreferences)
+	h.HandlerFunc("PATCH", usersPreferencesPath, h.handlePatchUserPreferences)
 
 	h.HandlerFunc("GET", prefixMe, h.handleGetMe)
 	h.HandlerFunc("PUT", mePasswordPath, h.handlePutUserPassword)
+	h.HandlerFunc("GET", mePreferencesPath, h.handleGetUserPreferences)
+	h.HandlerFunc("PATCH", mePreferencesPath, h.handlePatchUserPreferences)
 
 	return h
 }
@@ -151,6 +168,28 @@ func (h *UserHandler) handlePutUserPassword(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleDeleteUserLockout is the HTTP handler for the DELETE /api/v2/users/:id/lockout route.
+func (h *UserHandler) handleDeleteUserLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := httprouter.ParamsFromContext(ctx)
+	userID, err := influxdb.IDFromString(params.ByName("id"))
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid user ID provided in route",
+		}, w)
+		return
+	}
+
+	if err := h.PasswordLockoutService.UnlockUser(ctx, *userID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("User unlocked", zap.String("userID", userID.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 type passwordResetRequest struct {
 	Username    string
 	PasswordOld string
@@ -685,6 +724,16 @@ func (s *PasswordService) ComparePassword(ctx context.Context, userID influxdb.I
 	panic("not implemented")
 }
 
+// UnlockUser clears the failed password attempts of the user.
+func (s *PasswordService) UnlockUser(ctx context.Context, userID influxdb.ID) error {
+	return s.Client.
+		Delete(prefixUsers, userID.String(), "lockout").
+		StatusFn(func(resp *http.Response) error {
+			return CheckErrorStatus(http.StatusNoContent, resp)
+		}).
+		Do(ctx)
+}
+
 // CompareAndSetPassword compares the old and new password and submits the new password if possoble.
 // Note: is not implemented.
 func (s *PasswordService) CompareAndSetPassword(ctx context.Context, userID influxdb.ID, old string, new string) error {
@@ -0,0 +1,427 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// WebhookBackend is all services and associated parameters required to construct
+// the WebhookHandler.
+type WebhookBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	WebhookService influxdb.WebhookService
+}
+
+// NewWebhookBackend returns a new instance of WebhookBackend.
+func NewWebhookBackend(log *zap.Logger, b *APIBackend) *WebhookBackend {
+	return &WebhookBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		WebhookService:   b.WebhookService,
+	}
+}
+
+// WebhookHandler represents an HTTP API handler for webhooks.
+type WebhookHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	WebhookService influxdb.WebhookService
+}
+
+const (
+	prefixWebhooks         = "/api/v2/webhooks"
+	webhooksIDPath         = "/api/v2/webhooks/:id"
+	webhooksDeliveriesPath = "/api/v2/webhooks/:id/deliveries"
+)
+
+// NewWebhookHandler returns a new instance of WebhookHandler.
+func NewWebhookHandler(log *zap.Logger, b *WebhookBackend) *WebhookHandler {
+	h := &WebhookHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		WebhookService: b.WebhookService,
+	}
+
+	h.HandlerFunc("POST", prefixWebhooks, h.handlePostWebhook)
+	h.HandlerFunc("GET", prefixWebhooks, h.handleGetWebhooks)
+	h.HandlerFunc("GET", webhooksIDPath, h.handleGetWebhook)
+	h.HandlerFunc("PATCH", webhooksIDPath, h.handlePatchWebhook)
+	h.HandlerFunc("DELETE", webhooksIDPath, h.handleDeleteWebhook)
+	h.HandlerFunc("GET", webhooksDeliveriesPath, h.handleGetWebhookDeliveries)
+
+	return h
+}
+
+type webhookResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.Webhook
+}
+
+func newWebhookResponse(w *influxdb.Webhook) *webhookResponse {
+	return &webhookResponse{
+		Links: map[string]string{
+			"self":       fmt.Sprintf("/api/v2/webhooks/%s", w.ID),
+			"deliveries": fmt.Sprintf("/api/v2/webhooks/%s/deliveries", w.ID),
+			"org":        fmt.Sprintf("/api/v2/orgs/%s", w.OrgID),
+		},
+		Webhook: *w,
+	}
+}
+
+type webhooksResponse struct {
+	Links    map[string]string  `json:"links"`
+	Webhooks []*webhookResponse `json:"webhooks"`
+}
+
+func newWebhooksResponse(ws []*influxdb.Webhook) *webhooksResponse {
+	res := &webhooksResponse{
+		Links: map[string]string{
+			"self": prefixWebhooks,
+		},
+		Webhooks: make([]*webhookResponse, 0, len(ws)),
+	}
+	for _, w := range ws {
+		res.Webhooks = append(res.Webhooks, newWebhookResponse(w))
+	}
+	return res
+}
+
+type webhookDeliveriesResponse struct {
+	Links      map[string]string           `json:"links"`
+	Deliveries []*influxdb.WebhookDelivery `json:"deliveries"`
+}
+
+type postWebhookRequest struct {
+	OrgID       influxdb.ID     `json:"orgID"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	URL         string          `json:"url"`
+	Secret      string          `json:"secret,omitempty"`
+	Events      []string        `json:"events"`
+	Status      influxdb.Status `json:"status,omitempty"`
+}
+
+// handlePostWebhook is the HTTP handler for the POST /api/v2/webhooks route.
+func (h *WebhookHandler) handlePostWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode webhook request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	wh := &influxdb.Webhook{
+		OrgID:       req.OrgID,
+		Name:        req.Name,
+		Description: req.Description,
+		URL:         req.URL,
+		Events:      req.Events,
+		Status:      req.Status,
+	}
+	if req.Secret != "" {
+		wh.Secret.Value = &req.Secret
+	}
+	if err := wh.Valid(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := h.WebhookService.CreateWebhook(ctx, wh); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Webhook created", zap.String("webhookID", wh.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newWebhookResponse(wh)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetWebhooksRequest(r *http.Request) (*influxdb.WebhookFilter, error) {
+	qp := r.URL.Query()
+	filter := &influxdb.WebhookFilter{}
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := qp.Get("event"); v != "" {
+		filter.Event = &v
+	}
+	return filter, nil
+}
+
+// handleGetWebhooks is the HTTP handler for the GET /api/v2/webhooks route.
+func (h *WebhookHandler) handleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := decodeGetWebhooksRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ws, err := h.WebhookService.FindWebhooks(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Webhooks retrieved", zap.Int("count", len(ws)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newWebhooksResponse(ws)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeWebhookID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	var id influxdb.ID
+	if err := id.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid id provided in route",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// handleGetWebhook is the HTTP handler for the GET /api/v2/webhooks/:id route.
+func (h *WebhookHandler) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeWebhookID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	wh, err := h.WebhookService.FindWebhookByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Webhook retrieved", zap.String("webhookID", id.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newWebhookResponse(wh)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchWebhook is the HTTP handler for the PATCH /api/v2/webhooks/:id route.
+func (h *WebhookHandler) handlePatchWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeWebhookID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.WebhookUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to decode webhook update",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	wh, err := h.WebhookService.UpdateWebhook(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Webhook updated", zap.String("webhookID", id.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newWebhookResponse(wh)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteWebhook is the HTTP handler for the DELETE /api/v2/webhooks/:id route.
+func (h *WebhookHandler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeWebhookID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.WebhookService.DeleteWebhook(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Webhook deleted", zap.String("webhookID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetWebhookDeliveries is the HTTP handler for the GET /api/v2/webhooks/:id/deliveries route.
+func (h *WebhookHandler) handleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeWebhookID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ds, err := h.WebhookService.FindWebhookDeliveries(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Webhook deliveries retrieved", zap.String("webhookID", id.String()), zap.Int("count", len(ds)))
+
+	res := &webhookDeliveriesResponse{
+		Links: map[string]string{
+			"self":    fmt.Sprintf("/api/v2/webhooks/%s/deliveries", id),
+			"webhook": fmt.Sprintf("/api/v2/webhooks/%s", id),
+		},
+		Deliveries: ds,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// WebhookService connects to Influx via HTTP using tokens to manage webhooks.
+type WebhookService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.WebhookService = (*WebhookService)(nil)
+
+// FindWebhookByID returns a single webhook by ID.
+func (s *WebhookService) FindWebhookByID(ctx context.Context, id influxdb.ID) (*influxdb.Webhook, error) {
+	var wr webhookResponse
+	err := s.Client.
+		Get(path.Join(prefixWebhooks, id.String())).
+		DecodeJSON(&wr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wr.Webhook, nil
+}
+
+// FindWebhooks returns a list of webhooks that match filter.
+func (s *WebhookService) FindWebhooks(ctx context.Context, filter influxdb.WebhookFilter) ([]*influxdb.Webhook, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Event != nil {
+		params = append(params, [2]string{"event", *filter.Event})
+	}
+
+	var wr webhooksResponse
+	err := s.Client.
+		Get(prefixWebhooks).
+		QueryParams(params...).
+		DecodeJSON(&wr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := make([]*influxdb.Webhook, 0, len(wr.Webhooks))
+	for _, w := range wr.Webhooks {
+		ws = append(ws, &w.Webhook)
+	}
+	return ws, nil
+}
+
+// CreateWebhook creates a new webhook and sets w.ID with the new identifier.
+func (s *WebhookService) CreateWebhook(ctx context.Context, w *influxdb.Webhook) error {
+	req := postWebhookRequest{
+		OrgID:       w.OrgID,
+		Name:        w.Name,
+		Description: w.Description,
+		URL:         w.URL,
+		Events:      w.Events,
+		Status:      w.Status,
+	}
+	if w.Secret.Value != nil {
+		req.Secret = *w.Secret.Value
+	}
+
+	var wr webhookResponse
+	err := s.Client.
+		PostJSON(req, prefixWebhooks).
+		DecodeJSON(&wr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*w = wr.Webhook
+	return nil
+}
+
+// UpdateWebhook updates a single webhook with changeset.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, id influxdb.ID, upd influxdb.WebhookUpdate) (*influxdb.Webhook, error) {
+	var wr webhookResponse
+	err := s.Client.
+		PatchJSON(upd, path.Join(prefixWebhooks, id.String())).
+		DecodeJSON(&wr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wr.Webhook, nil
+}
+
+// DeleteWebhook removes a webhook by ID.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(path.Join(prefixWebhooks, id.String())).
+		Do(ctx)
+}
+
+// FindWebhookDeliveries returns the latest deliveries of a webhook, newest first.
+func (s *WebhookService) FindWebhookDeliveries(ctx context.Context, webhookID influxdb.ID) ([]*influxdb.WebhookDelivery, error) {
+	var dr webhookDeliveriesResponse
+	err := s.Client.
+		Get(path.Join(prefixWebhooks, webhookID.String(), "deliveries")).
+		DecodeJSON(&dr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dr.Deliveries, nil
+}
+
+// AddWebhookDelivery is not implemented for http.
+func (s *WebhookService) AddWebhookDelivery(ctx context.Context, d *influxdb.WebhookDelivery) error {
+	return &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "add webhook delivery is not implemented for http",
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BatchIDHeader is the header in which producers that retry writes send the
+// ID of a batch. Writing a batch with the same ID to a bucket again within the
+// dedupe window of the bucket is acknowledged without writing its points.
+const BatchIDHeader = "X-Influx-Batch-ID"
+
+// DefaultWriteDedupeMaxBatches is the default number of batch IDs tracked by
+// a WriteDeduplicator.
+const DefaultWriteDedupeMaxBatches = 100000
+
+// WriteDeduplicator remembers the IDs of the batches written to each bucket
+// for the dedupe window of the bucket. At most maxBatches IDs are remembered;
+// once that many are tracked the oldest ones are forgotten first, even if
+// their window has not passed.
+type WriteDeduplicator struct {
+	maxBatches int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	batches map[writeBatchKey]*list.Element
+	order   *list.List // of *writeBatch, oldest first
+
+	duplicateBatches *prometheus.CounterVec
+	duplicatePoints  *prometheus.CounterVec
+	trackedBatches   prometheus.Gauge
+	evictedBatches   prometheus.Counter
+}
+
+type writeBatchKey struct {
+	bucketID influxdb.ID
+	batchID  string
+}
+
+type writeBatch struct {
+	key     writeBatchKey
+	expires time.Time
+}
+
+// NewWriteDeduplicator returns a WriteDeduplicator which tracks at most
+// maxBatches batch IDs.
+func NewWriteDeduplicator(maxBatches int) *WriteDeduplicator {
+	if maxBatches <= 0 {
+		maxBatches = DefaultWriteDedupeMaxBatches
+	}
+	const namespace, subsystem = "http", "write_dedupe"
+	return &WriteDeduplicator{
+		maxBatches: maxBatches,
+		now:        time.Now,
+		batches:    make(map[writeBatchKey]*list.Element),
+		order:      list.New(),
+		duplicateBatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "duplicate_batches_total",
+			Help:      "Number of write batches dropped because they were already written.",
+		}, []string{"org_id"}),
+		duplicatePoints: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "duplicate_points_total",
+			Help:      "Number of points dropped because their batch was already written.",
+		}, []string{"org_id"}),
+		trackedBatches: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "tracked_batches",
+			Help:      "Number of batch IDs currently remembered.",
+		}),
+		evictedBatches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evicted_batches_total",
+			Help:      "Number of batch IDs forgotten before their dedupe window passed to bound memory use.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (d *WriteDeduplicator) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		d.duplicateBatches,
+		d.duplicatePoints,
+		d.trackedBatches,
+		d.evictedBatches,
+	}
+}
+
+// Seen reports whether the batch was written to the bucket within its dedupe window.
+func (d *WriteDeduplicator) Seen(bucketID influxdb.ID, batchID string) bool {
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.batches[writeBatchKey{bucketID: bucketID, batchID: batchID}]
+	if !ok {
+		return false
+	}
+	if now.After(e.Value.(*writeBatch).expires) {
+		d.removeLocked(e)
+		return false
+	}
+	return true
+}
+
+// Add remembers that the batch was written to the bucket until window has passed.
+func (d *WriteDeduplicator) Add(bucketID influxdb.ID, batchID string, window time.Duration) {
+	now := d.now()
+	key := writeBatchKey{bucketID: bucketID, batchID: batchID}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if e, ok := d.batches[key]; ok {
+		d.removeLocked(e)
+	}
+
+	// Forget expired batches from the front of the list, then the oldest
+	// batches if there are still too many.
+	for e := d.order.Front(); e != nil && now.After(e.Value.(*writeBatch).expires); e = d.order.Front() {
+		d.removeLocked(e)
+	}
+	for d.order.Len() >= d.maxBatches {
+		d.removeLocked(d.order.Front())
+		d.evictedBatches.Inc()
+	}
+
+	d.batches[key] = d.order.PushBack(&writeBatch{key: key, expires: now.Add(window)})
+	d.trackedBatches.Set(float64(d.order.Len()))
+}
+
+func (d *WriteDeduplicator) removeLocked(e *list.Element) {
+	delete(d.batches, e.Value.(*writeBatch).key)
+	d.order.Remove(e)
+	d.trackedBatches.Set(float64(d.order.Len()))
+}
+
+// dropped records a duplicate batch of n points written to the organization.
+func (d *WriteDeduplicator) dropped(orgID influxdb.ID, n int) {
+	d.duplicateBatches.WithLabelValues(orgID.String()).Inc()
+	d.duplicatePoints.WithLabelValues(orgID.String()).Add(float64(n))
+}
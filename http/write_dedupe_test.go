@@ -0,0 +1,47 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestWriteDeduplicator(t *testing.T) {
+	var (
+		bucket1 = influxdb.ID(0x5000)
+		bucket2 = influxdb.ID(0x5001)
+		now     = time.Unix(1000000, 0)
+	)
+
+	d := NewWriteDeduplicator(2)
+	d.now = func() time.Time { return now }
+
+	if d.Seen(bucket1, "a") {
+		t.Fatal("expected unwritten batch not to be seen")
+	}
+	d.Add(bucket1, "a", time.Minute)
+	if !d.Seen(bucket1, "a") {
+		t.Fatal("expected written batch to be seen")
+	}
+	if d.Seen(bucket2, "a") {
+		t.Fatal("expected batch IDs to be tracked per bucket")
+	}
+
+	// Batches are forgotten once their window has passed.
+	now = now.Add(2 * time.Minute)
+	if d.Seen(bucket1, "a") {
+		t.Fatal("expected batch to be forgotten after its window")
+	}
+
+	// The oldest batches are forgotten first when too many are tracked.
+	d.Add(bucket1, "b", time.Hour)
+	d.Add(bucket1, "c", time.Hour)
+	d.Add(bucket1, "d", time.Hour)
+	if d.Seen(bucket1, "b") {
+		t.Fatal("expected oldest batch to be evicted")
+	}
+	if !d.Seen(bucket1, "c") || !d.Seen(bucket1, "d") {
+		t.Fatal("expected newest batches to be remembered")
+	}
+}
@@ -1,13 +1,18 @@
 package http
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"time"
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
@@ -16,8 +21,10 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/otlp"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/write"
 	"go.uber.org/zap"
 )
 
@@ -38,6 +45,12 @@ type WriteBackend struct {
 	PointsWriter        storage.PointsWriter
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
+	BucketFamilyRouter  influxdb.BucketFamilyRouter
+	// DBRPMappingService resolves the buckets that authorizations scoped to
+	// a dbrp may write to.
+	DBRPMappingService influxdb.DBRPMappingService
+
+	ResourceActivityService influxdb.ResourceActivityService
 }
 
 // NewWriteBackend returns a new instance of WriteBackend.
@@ -50,6 +63,10 @@ func NewWriteBackend(log *zap.Logger, b *APIBackend) *WriteBackend {
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+		BucketFamilyRouter:  b.BucketFamilyRouter,
+		DBRPMappingService:  b.DBRPMappingService,
+
+		ResourceActivityService: b.ResourceActivityService,
 	}
 }
 
@@ -62,10 +79,26 @@ type WriteHandler struct {
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
 
+	// BucketFamilyRouter, if set, routes writes to the name of a bucket
+	// family which is not the name of a bucket to the current member of the
+	// family.
+	BucketFamilyRouter influxdb.BucketFamilyRouter
+
+	// DBRPMappingService resolves the buckets that authorizations scoped to
+	// a dbrp may write to.
+	DBRPMappingService influxdb.DBRPMappingService
+
 	PointsWriter storage.PointsWriter
 
 	EventRecorder metric.EventRecorder
 
+	// Deduplicator, if set, drops batches which are written to a bucket
+	// again within the dedupe window of the bucket.
+	Deduplicator *WriteDeduplicator
+
+	// ResourceActivityService, if set, records the writes to buckets.
+	ResourceActivityService influxdb.ResourceActivityService
+
 	maxBatchSizeBytes int64
 	parserOptions     []models.ParserOption
 	parserMaxBytes    int
@@ -84,6 +117,15 @@ func WithMaxBatchSizeBytes(n int64) WriteHandlerOption {
 	}
 }
 
+// WithWriteDeduplicator configures the write handler to drop batches whose
+// ID, sent in the BatchIDHeader, was already written to the bucket within
+// its dedupe window. When d is nil, batches are never dropped.
+func WithWriteDeduplicator(d *WriteDeduplicator) WriteHandlerOption {
+	return func(w *WriteHandler) {
+		w.Deduplicator = d
+	}
+}
+
 // WithParserMaxBytes specifies the maximum number of bytes that may be allocated when processing a single
 // write request. When n is zero, there is no limit.
 func WithParserMaxBytes(n int) WriteHandlerOption {
@@ -113,8 +155,14 @@ func (*WriteHandler) Prefix() string {
 	return prefixWrite
 }
 
+// OTLPMetricsPath is the path of the OTLP/HTTP endpoint, which receives
+// OpenTelemetry metrics.
+const OTLPMetricsPath = "/api/v2/write/otlp/v1/metrics"
+
 const (
 	prefixWrite          = "/api/v2/write"
+	prefixWriteCSV       = "/api/v2/write/csv"
+	prefixWriteJSON      = "/api/v2/write/json"
 	errInvalidGzipHeader = "gzipped HTTP body contains an invalid header"
 	errInvalidPrecision  = "invalid precision; valid precision units are ns, us, ms, and s"
 )
@@ -129,7 +177,11 @@ func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOptio
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+		BucketFamilyRouter:  b.BucketFamilyRouter,
+		DBRPMappingService:  b.DBRPMappingService,
 		EventRecorder:       b.WriteEventRecorder,
+
+		ResourceActivityService: b.ResourceActivityService,
 	}
 
 	for _, opt := range opts {
@@ -148,10 +200,57 @@ func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOptio
 	}
 
 	h.HandlerFunc("POST", prefixWrite, h.handleWrite)
+	h.HandlerFunc("POST", prefixWriteCSV, h.handleWriteCSV)
+	h.HandlerFunc("POST", prefixWriteJSON, h.handleWriteJSON)
+	h.HandlerFunc("POST", OTLPMetricsPath, h.handleWriteOTLPMetrics)
 	return h
 }
 
 func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, "http/handleWrite", nil)
+}
+
+// handleWriteCSV is the HTTP handler for the POST /api/v2/write/csv route. The
+// body is a multipart form with a "mapping" part, a write.CsvMapping in JSON,
+// and a "data" part with CSV data without annotations, which is converted to
+// line protocol with the mapping.
+func (h *WriteHandler) handleWriteCSV(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, "http/handleWriteCSV", decodeCSVWrite)
+}
+
+// handleWriteJSON is the HTTP handler for the POST /api/v2/write/json route.
+// The body is a JSON document, which is converted to line protocol with the
+// JSON write rules of the bucket.
+func (h *WriteHandler) handleWriteJSON(w http.ResponseWriter, r *http.Request) {
+	h.write(w, r, "http/handleWriteJSON", decodeJSONWrite)
+}
+
+// handleWriteOTLPMetrics is the HTTP handler for the POST
+// /api/v2/write/otlp/v1/metrics route. The body is an OTLP
+// ExportMetricsServiceRequest in protobuf, whose metrics are converted to
+// points as described by the otlp package.
+func (h *WriteHandler) handleWriteOTLPMetrics(w http.ResponseWriter, r *http.Request) {
+	h.write(otlpResponseWriter{w}, r, "http/handleWriteOTLPMetrics", decodeOTLPMetricsWrite)
+}
+
+// otlpResponseWriter responds to successful writes with 200 and an empty
+// ExportMetricsServiceResponse, as required by OTLP/HTTP.
+type otlpResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w otlpResponseWriter) WriteHeader(code int) {
+	if code == http.StatusNoContent {
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		code = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// write writes the points of the body of r. If convert is not nil, it
+// converts the body to line protocol in nanoseconds for the bucket written
+// to, and the precision of the request is ignored.
+func (h *WriteHandler) write(w http.ResponseWriter, r *http.Request, op string, convert func(r *http.Request, bucket *influxdb.Bucket, data []byte) ([]byte, error)) {
 	span, r := tracing.ExtractFromHTTPRequest(r, "WriteHandler")
 	defer span.Finish()
 
@@ -162,12 +261,15 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	// Ideally this will be moved when we solve https://github.com/influxdata/influxdb/issues/13403
 	var (
 		orgID        influxdb.ID
+		bucketID     influxdb.ID
 		requestBytes int
+		points       []models.Point
+		start        = time.Now()
 		sw           = kithttp.NewStatusResponseWriter(w)
 		handleError  = func(err error, code, message string) {
 			h.HandleHTTPError(ctx, &influxdb.Error{
 				Code: code,
-				Op:   "http/handleWrite",
+				Op:   op,
 				Msg:  message,
 				Err:  err,
 			}, w)
@@ -181,6 +283,9 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 			RequestBytes:  requestBytes,
 			ResponseBytes: sw.ResponseBytes(),
 			Status:        sw.Code(),
+			BucketID:      bucketID,
+			Points:        len(points),
+			Duration:      time.Since(start),
 		})
 	}()
 
@@ -199,7 +304,7 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	log := h.log.With(zap.String("org", req.Org), zap.String("bucket", req.Bucket))
 
 	var org *influxdb.Organization
-	org, err = queryOrganization(ctx, r, h.OrganizationService)
+	org, err = h.findOrganization(ctx, r, a)
 	if err != nil {
 		log.Info("Failed to find organization", zap.Error(err))
 		h.HandleHTTPError(ctx, err, w)
@@ -210,7 +315,15 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	span.LogKV("org_id", orgID)
 
 	var bucket *influxdb.Bucket
-	if id, err := influxdb.IDFromString(req.Bucket); err == nil {
+	if req.Bucket == "" {
+		b, err := h.findDefaultBucket(ctx, a, org)
+		if err != nil {
+			log.Info("Failed to find default bucket", zap.Error(err))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		bucket = b
+	} else if id, err := influxdb.IDFromString(req.Bucket); err == nil {
 		// Decoded ID successfully. Make sure it's a real bucket.
 		b, err := h.BucketService.FindBucket(ctx, influxdb.BucketFilter{
 			OrganizationID: &org.ID,
@@ -229,6 +342,13 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 			OrganizationID: &org.ID,
 			Name:           &req.Bucket,
 		})
+		if influxdb.ErrorCode(err) == influxdb.ENotFound && h.BucketFamilyRouter != nil {
+			if fb, ferr := h.BucketFamilyRouter.CurrentBucket(ctx, org.ID, req.Bucket); ferr == nil {
+				b, err = fb, nil
+			} else if influxdb.ErrorCode(ferr) != influxdb.ENotFound {
+				err = ferr
+			}
+		}
 		if err != nil {
 			h.HandleHTTPError(ctx, err, w)
 			return
@@ -236,6 +356,7 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 
 		bucket = b
 	}
+	bucketID = bucket.ID
 	span.LogKV("bucket_id", bucket.ID)
 
 	p, err := influxdb.NewPermissionAtID(bucket.ID, influxdb.WriteAction, influxdb.BucketsResourceType, org.ID)
@@ -249,28 +370,11 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := readWriteRequest(ctx, r.Body, r.Header.Get("Content-Encoding"), h.maxBatchSizeBytes)
-	if err != nil {
-		log.Error("Error reading body", zap.Error(err))
-
-		code := influxdb.EInternal
-		if errors.Is(err, ErrMaxBatchSizeExceeded) {
-			code = influxdb.ETooLarge
-		} else if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) {
-			code = influxdb.EInvalid
-		}
-
-		handleError(err, code, "unable to read data")
-		return
-	}
-
-	requestBytes = len(data)
-	if requestBytes == 0 {
-		handleError(err, influxdb.EInvalid, "writing requires points")
+	if err := authorizeDBRP(ctx, h.DBRPMappingService, a, influxdb.WriteAction, bucket.ID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
 		return
 	}
 
-	span, _ = tracing.StartSpanFromContextWithOperationName(ctx, "encoding and parsing")
 	encoded := tsdb.EncodeName(org.ID, bucket.ID)
 	mm := models.EscapeMeasurement(encoded[:])
 
@@ -280,36 +384,156 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		options = append(options, h.parserOptions...)
 	}
 
-	if req.Precision != nil {
-		options = append(options, req.Precision)
-	}
+	if convert == nil {
+		// Line protocol is parsed as it is read, so that the body is never
+		// entirely held in memory.
+		if req.Precision != nil {
+			options = append(options, req.Precision)
+		}
 
-	points, err := models.ParsePointsWithOptions(data, mm, options...)
-	span.LogKV("values_total", len(points))
-	span.Finish()
-	if err != nil {
-		log.Error("Error parsing points", zap.Error(err))
+		span, _ := tracing.StartSpanFromContextWithOperationName(ctx, "reading, encoding and parsing")
+		body, err := openWriteRequest(r.Body, r.Header.Get("Content-Encoding"), h.maxBatchSizeBytes)
+		if err != nil {
+			span.Finish()
+			log.Error("Error reading body", zap.Error(err))
+			handleError(err, readWriteRequestErrorCode(err), "unable to read data")
+			return
+		}
+
+		br := &writeBodyReader{Reader: body}
+		points, err = models.ParsePointsReader(br, mm, 0, options...)
+		if cerr := body.Close(); cerr != nil && br.err == nil {
+			br.err = cerr
+		}
+		requestBytes = br.n
+		span.LogKV("request_bytes", requestBytes, "values_total", len(points))
+		span.Finish()
 
-		code := influxdb.EInvalid
-		if errors.Is(err, models.ErrLimitMaxBytesExceeded) ||
-			errors.Is(err, models.ErrLimitMaxLinesExceeded) ||
-			errors.Is(err, models.ErrLimitMaxValuesExceeded) {
-			code = influxdb.ETooLarge
+		if br.err != nil {
+			log.Error("Error reading body", zap.Error(br.err))
+			handleError(br.err, readWriteRequestErrorCode(br.err), "unable to read data")
+			return
+		}
+		if requestBytes == 0 {
+			handleError(nil, influxdb.EInvalid, "writing requires points")
+			return
+		}
+		if err != nil {
+			log.Error("Error parsing points", zap.Error(err))
+			handleError(err, parseErrorCode(err), "")
+			return
+		}
+	} else {
+		data, err := readWriteRequest(ctx, r.Body, r.Header.Get("Content-Encoding"), h.maxBatchSizeBytes)
+		if err != nil {
+			log.Error("Error reading body", zap.Error(err))
+			handleError(err, readWriteRequestErrorCode(err), "unable to read data")
+			return
+		}
+
+		requestBytes = len(data)
+		if requestBytes == 0 {
+			handleError(err, influxdb.EInvalid, "writing requires points")
+			return
+		}
+
+		span, _ := tracing.StartSpanFromContextWithOperationName(ctx, "converting to line protocol")
+		data, err = convert(r, bucket, data)
+		span.Finish()
+		if err != nil {
+			log.Info("Error converting data", zap.Error(err))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if len(data) == 0 {
+			handleError(nil, influxdb.EInvalid, "writing requires points")
+			return
+		}
+
+		span, _ = tracing.StartSpanFromContextWithOperationName(ctx, "encoding and parsing")
+		points, err = models.ParsePointsWithOptions(data, mm, options...)
+		span.LogKV("values_total", len(points))
+		span.Finish()
+		if err != nil {
+			log.Error("Error parsing points", zap.Error(err))
+			handleError(err, parseErrorCode(err), "")
+			return
 		}
+	}
 
-		handleError(err, code, "")
+	// Batches are only remembered once written so that producers can retry
+	// failed writes with the same batch ID.
+	batchID := r.Header.Get(BatchIDHeader)
+	dedupe := h.Deduplicator != nil && batchID != "" && bucket.DedupeWindow > 0
+	if dedupe && h.Deduplicator.Seen(bucket.ID, batchID) {
+		log.Debug("Dropping duplicate batch", zap.String("batch_id", batchID), zap.Int("points", len(points)))
+		h.Deduplicator.dropped(org.ID, len(points))
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
+	consistency := req.Consistency
+	if consistency == "" {
+		consistency = bucket.WriteConsistency.OrDefault()
+	}
+	ctx = influxdb.ContextWithWriteConsistency(ctx, consistency)
+
 	if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
 		log.Error("Error writing points", zap.Error(err))
+		if _, ok := err.(tsdb.PartialWriteError); ok {
+			handleError(err, influxdb.EUnprocessableEntity, "failure writing points to database")
+			return
+		}
+		if influxdb.ErrorCode(err) == influxdb.ETooManyRequests {
+			handleError(err, influxdb.ETooManyRequests, "unable to accept points, try again later")
+			return
+		}
+		if err == storage.ErrEngineReadOnly {
+			handleError(err, influxdb.EForbidden, "unable to accept points, the storage engine is read-only")
+			return
+		}
 		handleError(err, influxdb.EInternal, "unexpected error writing points to database")
 		return
 	}
+	if dedupe {
+		h.Deduplicator.Add(bucket.ID, batchID, bucket.DedupeWindow)
+	}
+	recordResourceActivity(ctx, h.log, h.ResourceActivityService, influxdb.BucketsResourceType, bucket.ID, influxdb.ResourceActivityWrite)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// findOrganization returns the organization written to by r. Requests which
+// specify neither org nor orgID write to the organization of their token.
+func (h *WriteHandler) findOrganization(ctx context.Context, r *http.Request, a influxdb.Authorizer) (*influxdb.Organization, error) {
+	qp := r.URL.Query()
+	if auth, ok := a.(*influxdb.Authorization); ok && qp.Get(Org) == "" && qp.Get(OrgID) == "" {
+		return h.OrganizationService.FindOrganizationByID(ctx, auth.OrgID)
+	}
+	return queryOrganization(ctx, r, h.OrganizationService)
+}
+
+// findDefaultBucket returns the bucket written to by requests to org which
+// omit the bucket: the default bucket of their token or, if it has none, of
+// org.
+func (h *WriteHandler) findDefaultBucket(ctx context.Context, a influxdb.Authorizer, org *influxdb.Organization) (*influxdb.Bucket, error) {
+	id := org.DefaultBucketID
+	if auth, ok := a.(*influxdb.Authorization); ok && auth.OrgID == org.ID && auth.DefaultBucketID != nil {
+		id = auth.DefaultBucketID
+	}
+	if id == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "http/findDefaultBucket",
+			Msg:  "bucket is required, as neither the token nor the organization has a default bucket",
+		}
+	}
+	return h.BucketService.FindBucket(ctx, influxdb.BucketFilter{
+		OrganizationID: &org.ID,
+		ID:             id,
+	})
+}
+
 func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest, error) {
 	qp := r.URL.Query()
 	p := qp.Get("precision")
@@ -330,25 +554,176 @@ func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest
 		precision = models.WithParserPrecision(p)
 	}
 
+	consistency := influxdb.WriteConsistency(qp.Get("consistency"))
+	if err := consistency.Valid(); err != nil {
+		return nil, err
+	}
+
 	return &postWriteRequest{
-		Bucket:    qp.Get("bucket"),
-		Org:       qp.Get("org"),
-		Precision: precision,
+		Bucket:      qp.Get("bucket"),
+		Org:         qp.Get("org"),
+		Precision:   precision,
+		Consistency: consistency,
 	}, nil
 }
 
-func readWriteRequest(ctx context.Context, rc io.ReadCloser, encoding string, maxBatchSizeBytes int64) (v []byte, err error) {
-	defer func() {
-		// close the reader now that all bytes have been consumed
-		// this will return non-nil in the case of a configured limit
-		// being exceeded
-		if cerr := rc.Close(); err == nil {
-			err = cerr
+// decodeCSVWrite returns the line protocol of the data of a CSV write request,
+// converted with its mapping.
+func decodeCSVWrite(r *http.Request, _ *influxdb.Bucket, data []byte) ([]byte, error) {
+	const op = "http/decodeCSVWrite"
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   op,
+			Msg:  "csv writes require a multipart/form-data body",
 		}
-	}()
+	}
+
+	var (
+		mapping *write.CsvMapping
+		csvData []byte
+	)
+	mr := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   op,
+				Msg:  "invalid multipart body",
+				Err:  err,
+			}
+		}
+
+		switch part.FormName() {
+		case "mapping":
+			mapping = &write.CsvMapping{}
+			err = json.NewDecoder(part).Decode(mapping)
+		case "data":
+			csvData, err = ioutil.ReadAll(part)
+		}
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   op,
+				Msg:  fmt.Sprintf("invalid %s part", part.FormName()),
+				Err:  err,
+			}
+		}
+	}
+
+	if mapping == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   op,
+			Msg:  "mapping is required",
+		}
+	}
+	if err := mapping.Validate(); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   op,
+			Msg:  "invalid mapping",
+			Err:  err,
+		}
+	}
+
+	lines, err := ioutil.ReadAll(write.CsvToProtocolLinesWithMapping(bytes.NewReader(csvData), mapping))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   op,
+			Msg:  "unable to convert csv",
+			Err:  err,
+		}
+	}
+	return lines, nil
+}
+
+// decodeOTLPMetricsWrite returns the line protocol of the metrics of an
+// OTLP/HTTP request. Only the protobuf encoding of OTLP is supported.
+func decodeOTLPMetricsWrite(r *http.Request, _ *influxdb.Bucket, data []byte) ([]byte, error) {
+	const op = "http/decodeOTLPMetricsWrite"
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/x-protobuf" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   op,
+			Msg:  "OTLP writes require an application/x-protobuf body",
+		}
+	}
+
+	lines, err := otlp.MetricsToLineProtocol(data)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   op,
+			Msg:  "unable to convert OTLP metrics",
+			Err:  err,
+		}
+	}
+	return lines, nil
+}
+
+// decodeJSONWrite returns the line protocol extracted from the JSON document
+// of a write request with the JSON write rules of bucket.
+func decodeJSONWrite(_ *http.Request, bucket *influxdb.Bucket, data []byte) ([]byte, error) {
+	const op = "http/decodeJSONWrite"
+
+	if len(bucket.JSONWriteRules) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   op,
+			Msg:  fmt.Sprintf("bucket %q has no JSON write rules", bucket.Name),
+		}
+	}
+
+	lines, err := write.JSONToProtocolLines(data, bucket.JSONWriteRules)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   op,
+			Msg:  "unable to convert json",
+			Err:  err,
+		}
+	}
+	return lines, nil
+}
+
+// readWriteRequestErrorCode returns the code of an error reading the body of
+// a write request.
+func readWriteRequestErrorCode(err error) string {
+	if errors.Is(err, ErrMaxBatchSizeExceeded) {
+		return influxdb.ETooLarge
+	} else if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) {
+		return influxdb.EInvalid
+	}
+	return influxdb.EInternal
+}
+
+// parseErrorCode returns the code of an error parsing the points of a write
+// request.
+func parseErrorCode(err error) string {
+	if errors.Is(err, models.ErrLimitMaxBytesExceeded) ||
+		errors.Is(err, models.ErrLimitMaxLinesExceeded) ||
+		errors.Is(err, models.ErrLimitMaxValuesExceeded) {
+		return influxdb.ETooLarge
+	}
+	return influxdb.EInvalid
+}
 
+// openWriteRequest returns a reader of the decompressed body rc of a write
+// request, which fails once more than maxBatchSizeBytes are read if it is
+// positive.
+func openWriteRequest(rc io.ReadCloser, encoding string, maxBatchSizeBytes int64) (io.ReadCloser, error) {
 	switch encoding {
 	case "gzip", "x-gzip":
+		var err error
 		rc, err = gzip.NewReader(rc)
 		if err != nil {
 			return nil, err
@@ -360,6 +735,22 @@ func readWriteRequest(ctx context.Context, rc io.ReadCloser, encoding string, ma
 	if maxBatchSizeBytes > 0 {
 		rc = newLimitedReadCloser(rc, maxBatchSizeBytes)
 	}
+	return rc, nil
+}
+
+func readWriteRequest(ctx context.Context, rc io.ReadCloser, encoding string, maxBatchSizeBytes int64) (v []byte, err error) {
+	rc, err = openWriteRequest(rc, encoding, maxBatchSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// close the reader now that all bytes have been consumed
+		// this will return non-nil in the case of a configured limit
+		// being exceeded
+		if cerr := rc.Close(); err == nil {
+			err = cerr
+		}
+	}()
 
 	span, _ := tracing.StartSpanFromContextWithOperationName(ctx, "read request body")
 	defer func() {
@@ -370,10 +761,30 @@ func readWriteRequest(ctx context.Context, rc io.ReadCloser, encoding string, ma
 	return ioutil.ReadAll(rc)
 }
 
+// writeBodyReader counts the bytes read from the body of a write request, and
+// records the error reading it, to tell it apart from errors parsing it.
+type writeBodyReader struct {
+	io.Reader
+	n   int
+	err error
+}
+
+func (r *writeBodyReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += n
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
 type postWriteRequest struct {
 	Org       string
 	Bucket    string
 	Precision models.ParserOption
+	// Consistency is when the write is acknowledged. If empty, the default
+	// of the bucket is used.
+	Consistency influxdb.WriteConsistency
 }
 
 // WriteService sends data over HTTP to influxdb via line protocol.
@@ -382,6 +793,9 @@ type WriteService struct {
 	Token              string
 	Precision          string
 	InsecureSkipVerify bool
+	// Consistency is when writes are acknowledged. If empty, the default of
+	// the bucket written to is used.
+	Consistency influxdb.WriteConsistency
 }
 
 var _ influxdb.WriteService = (*WriteService)(nil)
@@ -433,6 +847,9 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID influxdb.ID, r
 	params.Set("org", string(org))
 	params.Set("bucket", string(bucket))
 	params.Set("precision", string(precision))
+	if s.Consistency != "" {
+		params.Set("consistency", string(s.Consistency))
+	}
 	req.URL.RawQuery = params.Encode()
 
 	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
@@ -476,6 +893,16 @@ func newLimitedReadCloser(r io.ReadCloser, n int64) *limitedReader {
 	}
 }
 
+// Read fails with ErrMaxBatchSizeExceeded once more bytes than the limit
+// have been read, so that callers stop reading.
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.LimitedReader.Read(p)
+	if l.N < 1 {
+		return n, ErrMaxBatchSizeExceeded
+	}
+	return n, err
+}
+
 // Close returns an ErrMaxBatchSizeExceeded when the wrapped reader
 // exceeds the set limit for number of bytes.
 // This is safe to call more than once but not concurrently.
@@ -1,11 +1,15 @@
 package http
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -16,7 +20,10 @@ import (
 	httpmock "github.com/influxdata/influxdb/v2/http/mock"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
 	influxtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -376,6 +383,221 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 	}
 }
 
+func TestWriteHandler_handleWrite_defaultBucket(t *testing.T) {
+	const (
+		orgID         = "043e0780ee2b1000"
+		otherOrgID    = "043e0780ee2b2000"
+		orgBucketID   = "04504b356e23b000"
+		tokenBucketID = "04504b356e23c000"
+	)
+
+	withDefaultBucket := func(a *influxdb.Authorization, bucket string) *influxdb.Authorization {
+		id := influxtesting.MustIDBase16(bucket)
+		a.DefaultBucketID = &id
+		return a
+	}
+
+	tests := []struct {
+		name       string
+		org        string
+		auth       *influxdb.Authorization
+		orgBucket  string // default bucket of the organization
+		wantBucket string // bucket written to
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name:       "token default bucket",
+			org:        orgID,
+			auth:       withDefaultBucket(bucketWritePermission(orgID, tokenBucketID), tokenBucketID),
+			orgBucket:  orgBucketID,
+			wantBucket: tokenBucketID,
+			wantCode:   http.StatusNoContent,
+		},
+		{
+			name:       "organization default bucket",
+			org:        orgID,
+			auth:       bucketWritePermission(orgID, orgBucketID),
+			orgBucket:  orgBucketID,
+			wantBucket: orgBucketID,
+			wantCode:   http.StatusNoContent,
+		},
+		{
+			name:       "organization of the token when org is omitted",
+			auth:       withDefaultBucket(bucketWritePermission(orgID, tokenBucketID), tokenBucketID),
+			wantBucket: tokenBucketID,
+			wantCode:   http.StatusNoContent,
+		},
+		{
+			name:     "no default bucket returns 400",
+			org:      orgID,
+			auth:     bucketWritePermission(orgID, orgBucketID),
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"code":"invalid","message":"bucket is required, as neither the token nor the organization has a default bucket"}`,
+		},
+		{
+			name:     "token default bucket of another organization is ignored",
+			org:      otherOrgID,
+			auth:     withDefaultBucket(bucketWritePermission(orgID, tokenBucketID), tokenBucketID),
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"code":"invalid","message":"bucket is required, as neither the token nor the organization has a default bucket"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findOrg := func(id influxdb.ID) *influxdb.Organization {
+				org := &influxdb.Organization{ID: id}
+				if tt.orgBucket != "" {
+					bid := influxtesting.MustIDBase16(tt.orgBucket)
+					org.DefaultBucketID = &bid
+				}
+				return org
+			}
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return findOrg(*filter.ID), nil
+			}
+			orgs.FindOrganizationByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+				return findOrg(id), nil
+			}
+			var found *influxdb.Bucket
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				if filter.ID == nil {
+					t.Fatalf("expected the bucket to be found by ID, got filter %+v", filter)
+				}
+				found = &influxdb.Bucket{ID: *filter.ID, OrgID: *filter.OrganizationID}
+				return found, nil
+			}
+
+			points := &mock.PointsWriter{}
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        points,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, tt.auth)
+
+			target := "http://localhost:9999/api/v2/write"
+			if tt.org != "" {
+				target += "?org=" + tt.org
+			}
+			r := httptest.NewRequest("POST", target, strings.NewReader("m1,t1=v1 f1=1"))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.wantCode; got != want {
+				t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body.String())
+			}
+			if got, want := w.Body.String(), tt.wantBody; got != want {
+				t.Errorf("unexpected body: got %s want %s", got, want)
+			}
+			if tt.wantBucket == "" {
+				return
+			}
+
+			if got, want := found.ID, influxtesting.MustIDBase16(tt.wantBucket); got != want {
+				t.Errorf("unexpected bucket: got %s want %s", got, want)
+			}
+			if got, want := found.OrgID, influxtesting.MustIDBase16(orgID); got != want {
+				t.Errorf("unexpected organization: got %s want %s", got, want)
+			}
+			if got, want := len(points.Points), 1; got != want {
+				t.Errorf("unexpected number of points: got %d want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWrite_dbrp(t *testing.T) {
+	const (
+		orgID         = "043e0780ee2b1000"
+		bucketID      = "04504b356e23b000"
+		otherBucketID = "04504b356e23c000"
+	)
+
+	withDBRP := func(a *influxdb.Authorization, db, rp string) *influxdb.Authorization {
+		a.DBRP = &influxdb.AuthorizationDBRP{Database: db, RetentionPolicy: rp}
+		return a
+	}
+
+	tests := []struct {
+		name     string
+		bucket   string
+		auth     *influxdb.Authorization
+		wantCode int
+	}{
+		{
+			name:     "bucket of the dbrp mapping",
+			bucket:   bucketID,
+			auth:     withDBRP(bucketWritePermission(orgID, bucketID), "telegraf", "autogen"),
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "bucket of another dbrp mapping",
+			bucket:   otherBucketID,
+			auth:     withDBRP(bucketWritePermission(orgID, otherBucketID), "telegraf", "autogen"),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "dbrp without a mapping",
+			bucket:   bucketID,
+			auth:     withDBRP(bucketWritePermission(orgID, bucketID), "telegraf", "weekly"),
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg(orgID), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return &influxdb.Bucket{ID: *filter.ID, OrgID: *filter.OrganizationID}, nil
+			}
+			dbrps := mock.NewDBRPMappingService()
+			dbrps.FindFn = func(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+				if *filter.Database != "telegraf" || *filter.RetentionPolicy != "autogen" {
+					return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "dbrp mapping not found"}
+				}
+				return &influxdb.DBRPMapping{
+					Database:        "telegraf",
+					RetentionPolicy: "autogen",
+					OrganizationID:  influxtesting.MustIDBase16(orgID),
+					BucketID:        influxtesting.MustIDBase16(bucketID),
+				}, nil
+			}
+
+			points := &mock.PointsWriter{}
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				DBRPMappingService:  dbrps,
+				PointsWriter:        points,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, tt.auth)
+
+			r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org="+orgID+"&bucket="+tt.bucket, strings.NewReader("m1,t1=v1 f1=1"))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.wantCode; got != want {
+				t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body.String())
+			}
+			if tt.wantCode != http.StatusNoContent && len(points.Points) != 0 {
+				t.Errorf("expected no points to be written, got %d", len(points.Points))
+			}
+		})
+	}
+}
+
 var DefaultErrorHandler = kithttp.ErrorHandler(0)
 
 func bucketWritePermission(org, bucket string) *influxdb.Authorization {
@@ -413,3 +635,468 @@ func testBucket(org, bucket string) *influxdb.Bucket {
 		OrgID: oid,
 	}
 }
+
+func TestWriteHandler_handleWriteCSV(t *testing.T) {
+	// request is sent to the HTTP endpoint
+	type request struct {
+		mapping string
+		data    string
+	}
+
+	// want is the expected output of the HTTP endpoint
+	type wants struct {
+		body   string
+		code   int
+		points int
+	}
+
+	const mapping = `{"measurement":"cpu","columns":[{"column":"host","type":"tag"},{"column":"usage","type":"field"},{"column":"ts","type":"time","format":"s"}]}`
+
+	tests := []struct {
+		name    string
+		request request
+		wants   wants
+	}{
+		{
+			name: "csv is written with mapping",
+			request: request{
+				mapping: mapping,
+				data:    "ts,host,usage\n1,a,0.5\n2,b,1.5\n",
+			},
+			wants: wants{
+				code:   204,
+				points: 2,
+			},
+		},
+		{
+			name: "missing mapping returns 400",
+			request: request{
+				data: "ts,host,usage\n1,a,0.5\n",
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"mapping is required"}`,
+			},
+		},
+		{
+			name: "invalid mapping returns 400",
+			request: request{
+				mapping: `{"measurement":"cpu","columns":[{"column":"host","type":"tag"}]}`,
+				data:    "ts,host,usage\n1,a,0.5\n",
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"invalid mapping: no field column"}`,
+			},
+		},
+		{
+			name: "invalid value returns 400",
+			request: request{
+				mapping: mapping,
+				data:    "ts,host,usage\n1,a,0.5\n2,b,high\n",
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"unable to convert csv: line 3: column 'usage': strconv.ParseFloat: parsing \"high\": invalid syntax"}`,
+			},
+		},
+		{
+			name: "header only returns 400",
+			request: request{
+				mapping: mapping,
+				data:    "ts,host,usage\n",
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"writing requires points"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg("043e0780ee2b1000"), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+			}
+			points := &mock.PointsWriter{}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        points,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			if tt.request.mapping != "" {
+				if err := mw.WriteField("mapping", tt.request.mapping); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := mw.WriteField("data", tt.request.data); err != nil {
+				t.Fatal(err)
+			}
+			if err := mw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write/csv", &body)
+			r.Header.Set("Content-Type", mw.FormDataContentType())
+			params := r.URL.Query()
+			params.Set("org", "043e0780ee2b1000")
+			params.Set("bucket", "04504b356e23b000")
+			r.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.wants.code; got != want {
+				t.Errorf("unexpected status code: got %d want %d", got, want)
+			}
+			if got, want := w.Body.String(), tt.wants.body; got != want {
+				t.Errorf("unexpected body: got %s want %s", got, want)
+			}
+			if got, want := len(points.Points), tt.wants.points; got != want {
+				t.Errorf("unexpected number of points: got %d want %d", got, want)
+			}
+			if tt.wants.points > 0 {
+				if got, want := points.Points[0].UnixNano(), int64(1e9); got != want {
+					t.Errorf("unexpected time: got %d want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWriteJSON(t *testing.T) {
+	// want is the expected output of the HTTP endpoint
+	type wants struct {
+		body   string
+		code   int
+		points int
+	}
+
+	rules := []influxdb.JSONWriteRule{{
+		Path:        "$.readings[*]",
+		Measurement: "cpu",
+		Tags:        []influxdb.JSONWriteValue{{Key: "host", Path: "$.host"}},
+		Fields:      []influxdb.JSONWriteValue{{Key: "usage", Path: "@.usage"}},
+		TimePath:    "@.ts",
+		TimeFormat:  "s",
+	}}
+
+	tests := []struct {
+		name  string
+		rules []influxdb.JSONWriteRule
+		body  string
+		wants wants
+	}{
+		{
+			name:  "json is written with bucket rules",
+			rules: rules,
+			body:  `{"host":"a","readings":[{"ts":1,"usage":0.5},{"ts":2,"usage":1.5}]}`,
+			wants: wants{
+				code:   204,
+				points: 2,
+			},
+		},
+		{
+			name: "bucket without rules returns 400",
+			body: `{"host":"a","readings":[{"ts":1,"usage":0.5}]}`,
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"bucket \"telemetry\" has no JSON write rules"}`,
+			},
+		},
+		{
+			name:  "invalid value returns 400",
+			rules: rules,
+			body:  `{"host":"a","readings":[{"ts":1,"usage":[0.5]}]}`,
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"unable to convert json: rule 0: field 'usage': value is not a string, number or boolean"}`,
+			},
+		},
+		{
+			name:  "nothing extracted returns 400",
+			rules: rules,
+			body:  `{"host":"a","readings":[]}`,
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"writing requires points"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg("043e0780ee2b1000"), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				b := testBucket("043e0780ee2b1000", "04504b356e23b000")
+				b.Name = "telemetry"
+				b.JSONWriteRules = tt.rules
+				return b, nil
+			}
+			points := &mock.PointsWriter{}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        points,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+			r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write/json", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			params := r.URL.Query()
+			params.Set("org", "043e0780ee2b1000")
+			params.Set("bucket", "04504b356e23b000")
+			r.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.wants.code; got != want {
+				t.Errorf("unexpected status code: got %d want %d", got, want)
+			}
+			if got, want := w.Body.String(), tt.wants.body; got != want {
+				t.Errorf("unexpected body: got %s want %s", got, want)
+			}
+			if got, want := len(points.Points), tt.wants.points; got != want {
+				t.Errorf("unexpected number of points: got %d want %d", got, want)
+			}
+			if tt.wants.points > 0 {
+				if got, want := points.Points[0].UnixNano(), int64(1e9); got != want {
+					t.Errorf("unexpected time: got %d want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWriteOTLPMetrics(t *testing.T) {
+	// embed encodes a protobuf field with an embedded message.
+	embed := func(field byte, msg []byte) []byte {
+		return append([]byte{field<<3 | 2, byte(len(msg))}, msg...)
+	}
+	// fixed64 encodes a protobuf fixed64 or double field.
+	fixed64 := func(field byte, v uint64) []byte {
+		b := make([]byte, 9)
+		b[0] = field<<3 | 1
+		binary.LittleEndian.PutUint64(b[1:], v)
+		return b
+	}
+	point := append(fixed64(3, 1e9), fixed64(4, math.Float64bits(0.5))...)
+	gauge := append(embed(1, []byte("cpu")), embed(5, embed(1, point))...)
+	metrics := embed(1, embed(2, embed(2, gauge)))
+
+	// want is the expected output of the HTTP endpoint
+	type wants struct {
+		body   string
+		code   int
+		points int
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		wants       wants
+	}{
+		{
+			name:        "metrics are written",
+			contentType: "application/x-protobuf",
+			body:        metrics,
+			wants: wants{
+				code:   200,
+				points: 1,
+			},
+		},
+		{
+			name:        "json encoding returns 400",
+			contentType: "application/json",
+			body:        []byte(`{"resourceMetrics":[]}`),
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"OTLP writes require an application/x-protobuf body"}`,
+			},
+		},
+		{
+			name:        "invalid protobuf returns 400",
+			contentType: "application/x-protobuf",
+			body:        []byte{0x0a, 0x05},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"unable to convert OTLP metrics: truncated protobuf message"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg("043e0780ee2b1000"), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+			}
+			points := &mock.PointsWriter{}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        points,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+			r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write/otlp/v1/metrics", bytes.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			params := r.URL.Query()
+			params.Set("org", "043e0780ee2b1000")
+			params.Set("bucket", "04504b356e23b000")
+			r.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.wants.code; got != want {
+				t.Errorf("unexpected status code: got %d want %d", got, want)
+			}
+			if got, want := w.Body.String(), tt.wants.body; got != want {
+				t.Errorf("unexpected body: got %s want %s", got, want)
+			}
+			if got, want := len(points.Points), tt.wants.points; got != want {
+				t.Errorf("unexpected number of points: got %d want %d", got, want)
+			}
+			if tt.wants.points > 0 {
+				if got, want := points.Points[0].UnixNano(), int64(1e9); got != want {
+					t.Errorf("unexpected time: got %d want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWrite_gzip(t *testing.T) {
+	var body bytes.Buffer
+	gw := gzip.NewWriter(&body)
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(gw, "m1,t1=v%d f1=%d,f2=\"value %d\" %d\n", i%10, i, i, i+1)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	points := &mock.PointsWriter{}
+	handler := newTestWriteHandler(points, WithMaxBatchSizeBytes(1<<30))
+
+	r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000&precision=s", &body)
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNoContent; got != want {
+		t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body.String())
+	}
+
+	if got, want := len(points.Points), 20000; got != want {
+		t.Fatalf("unexpected number of points: got %d want %d", got, want)
+	}
+	for i, p := range points.Points {
+		if got, want := p.UnixNano(), int64(i/2+1)*1e9; got != want {
+			t.Fatalf("unexpected time of point %d: got %d want %d", i, got, want)
+		}
+	}
+	fields, err := points.Points[len(points.Points)-1].Fields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fields["f2"], "value 9999"; got != want {
+		t.Errorf("unexpected field value: got %v want %v", got, want)
+	}
+}
+
+// discardPointsWriter drops the points written to it.
+type discardPointsWriter struct{}
+
+func (discardPointsWriter) WritePoints(context.Context, []models.Point) error { return nil }
+
+// newTestWriteHandler returns a write handler authorized to write to the test
+// bucket, which writes points to pw.
+func newTestWriteHandler(pw storage.PointsWriter, opts ...WriteHandlerOption) http.Handler {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg("043e0780ee2b1000"), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+	}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zap.NewNop(),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        pw,
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+	}
+	writeHandler := NewWriteHandler(zap.NewNop(), NewWriteBackend(zap.NewNop(), b), opts...)
+	return httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+}
+
+func BenchmarkWriteHandler_handleWrite(b *testing.B) {
+	for _, n := range []int{1000, 100000} {
+		var lines bytes.Buffer
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&lines, "cpu,host=server%02d,region=us-west,cpu=cpu%d usage_user=%d.5,usage_system=%d.25,usage_idle=%di %d\n", i%100, i%8, i%100, i%10, i, 1e18+i)
+		}
+		var gzipped bytes.Buffer
+		gw := gzip.NewWriter(&gzipped)
+		gw.Write(lines.Bytes())
+		gw.Close()
+
+		handler := newTestWriteHandler(discardPointsWriter{})
+		for _, tc := range []struct {
+			name     string
+			body     []byte
+			encoding string
+		}{
+			{name: "plain", body: lines.Bytes()},
+			{name: "gzip", body: gzipped.Bytes(), encoding: "gzip"},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", tc.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(lines.Len()))
+				for i := 0; i < b.N; i++ {
+					r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000", bytes.NewReader(tc.body))
+					if tc.encoding != "" {
+						r.Header.Set("Content-Encoding", tc.encoding)
+					}
+					w := httptest.NewRecorder()
+					handler.ServeHTTP(w, r)
+					if w.Code != http.StatusNoContent {
+						b.Fatalf("unexpected status code: %d: %s", w.Code, w.Body.String())
+					}
+				}
+			})
+		}
+	}
+}
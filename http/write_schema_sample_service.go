@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// WriteSchemaSampleBackend is all services and associated parameters
+// required to construct the WriteSchemaSampleHandler.
+type WriteSchemaSampleBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	WriteSchemaSampleService influxdb.WriteSchemaSampleService
+}
+
+// NewWriteSchemaSampleBackend returns a new instance of WriteSchemaSampleBackend.
+func NewWriteSchemaSampleBackend(b *APIBackend) *WriteSchemaSampleBackend {
+	return &WriteSchemaSampleBackend{
+		Logger: b.Logger.With(zap.String("handler", "write_schema_sample")),
+
+		HTTPErrorHandler:         b.HTTPErrorHandler,
+		WriteSchemaSampleService: b.WriteSchemaSampleService,
+	}
+}
+
+// WriteSchemaSampleHandler is the http handler sampling the schema written
+// to buckets.
+type WriteSchemaSampleHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	WriteSchemaSampleService influxdb.WriteSchemaSampleService
+}
+
+const prefixWriteSchemaSample = "/api/v2/write-schema-sample"
+
+// NewWriteSchemaSampleHandler creates a new handler at
+// /api/v2/write-schema-sample sampling the schema written to a bucket.
+func NewWriteSchemaSampleHandler(b *WriteSchemaSampleBackend) *WriteSchemaSampleHandler {
+	h := &WriteSchemaSampleHandler{
+		HTTPErrorHandler:         b.HTTPErrorHandler,
+		Router:                   NewRouter(b.HTTPErrorHandler),
+		Logger:                   b.Logger,
+		WriteSchemaSampleService: b.WriteSchemaSampleService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixWriteSchemaSample, h.handleGetWriteSchemaSample)
+
+	return h
+}
+
+type writeSchemaSampleRequest struct {
+	orgID    influxdb.ID
+	bucketID influxdb.ID
+	window   time.Duration
+}
+
+func decodeWriteSchemaSampleRequest(r *http.Request) (*writeSchemaSampleRequest, error) {
+	qp := r.URL.Query()
+	req := &writeSchemaSampleRequest{
+		window: influxdb.DefaultWriteSchemaSampleWindow,
+	}
+	if err := req.orgID.DecodeFromString(qp.Get("orgID")); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	if err := req.bucketID.DecodeFromString(qp.Get("bucketID")); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		}
+	}
+	if v := qp.Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "window must be a duration, such as 30s",
+			}
+		}
+		req.window = d
+	}
+	return req, nil
+}
+
+func (h *WriteSchemaSampleHandler) handleGetWriteSchemaSample(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "WriteSchemaSampleHandler.handleGetWriteSchemaSample")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	req, err := decodeWriteSchemaSampleRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	sample, err := h.WriteSchemaSampleService.SampleWriteSchema(ctx, req.orgID, req.bucketID, req.window)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("Write schema sampled",
+		zap.Stringer("orgID", req.orgID),
+		zap.Stringer("bucketID", req.bucketID),
+		zap.Int64("lineN", sample.LineN))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, sample); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// WriteSchemaSampleService is the client implementation of influxdb.WriteSchemaSampleService.
+type WriteSchemaSampleService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.WriteSchemaSampleService = (*WriteSchemaSampleService)(nil)
+
+// SampleWriteSchema samples the points written to the bucket for the window.
+func (s *WriteSchemaSampleService) SampleWriteSchema(ctx context.Context, orgID, bucketID influxdb.ID, window time.Duration) (*influxdb.WriteSchemaSample, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var sample influxdb.WriteSchemaSample
+	err := s.Client.
+		Get(prefixWriteSchemaSample).
+		QueryParams(
+			[2]string{"orgID", orgID.String()},
+			[2]string{"bucketID", bucketID.String()},
+			[2]string{"window", window.String()},
+		).
+		DecodeJSON(&sample).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sample, nil
+}
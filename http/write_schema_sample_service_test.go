@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+type sampleWriteSchemaFunc func(ctx context.Context, orgID, bucketID influxdb.ID, window time.Duration) (*influxdb.WriteSchemaSample, error)
+
+func (f sampleWriteSchemaFunc) SampleWriteSchema(ctx context.Context, orgID, bucketID influxdb.ID, window time.Duration) (*influxdb.WriteSchemaSample, error) {
+	return f(ctx, orgID, bucketID, window)
+}
+
+func TestWriteSchemaSampleHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		window     time.Duration
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "samples bucket",
+			query:      "?orgID=020f755c3c082000&bucketID=020f755c3c082001&window=30s",
+			window:     30 * time.Second,
+			statusCode: http.StatusOK,
+			body:       `{"orgID": "020f755c3c082000", "bucketID": "020f755c3c082001", "start": "2020-01-01T00:00:00Z", "window": "30s", "lineN": 2, "truncated": false, "measurements": [{"name": "cpu", "lineN": 2, "tags": [{"key": "host", "valueN": 2}], "fields": [{"name": "usage", "types": ["float", "integer"]}]}], "producers": [{"authorizationID": "020f755c3c082002", "kind": "authorization", "userID": "020f755c3c082003", "lineN": 2, "linesPerSecond": 0.06666666666666667, "seriesN": 2, "measurements": ["cpu"]}]}`,
+		},
+		{
+			name:       "default window",
+			query:      "?orgID=020f755c3c082000&bucketID=020f755c3c082001",
+			window:     influxdb.DefaultWriteSchemaSampleWindow,
+			statusCode: http.StatusOK,
+			body:       `{"orgID": "020f755c3c082000", "bucketID": "020f755c3c082001", "start": "2020-01-01T00:00:00Z", "window": "10s", "lineN": 2, "truncated": false, "measurements": [{"name": "cpu", "lineN": 2, "tags": [{"key": "host", "valueN": 2}], "fields": [{"name": "usage", "types": ["float", "integer"]}]}], "producers": [{"authorizationID": "020f755c3c082002", "kind": "authorization", "userID": "020f755c3c082003", "lineN": 2, "linesPerSecond": 0.2, "seriesN": 2, "measurements": ["cpu"]}]}`,
+		},
+		{
+			name:       "invalid window",
+			query:      "?orgID=020f755c3c082000&bucketID=020f755c3c082001&window=soon",
+			statusCode: http.StatusBadRequest,
+			body:       `{"code": "invalid", "message": "window must be a duration, such as 30s"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewWriteSchemaSampleHandler(&WriteSchemaSampleBackend{
+				Logger:           zaptest.NewLogger(t),
+				HTTPErrorHandler: kithttp.ErrorHandler(0),
+				WriteSchemaSampleService: sampleWriteSchemaFunc(func(ctx context.Context, orgID, bucketID influxdb.ID, window time.Duration) (*influxdb.WriteSchemaSample, error) {
+					if orgID != 0x020f755c3c082000 || bucketID != 0x020f755c3c082001 || window != tt.window {
+						t.Errorf("unexpected org, bucket and window: %s, %s, %s", orgID, bucketID, window)
+					}
+					return &influxdb.WriteSchemaSample{
+						OrgID:    orgID,
+						BucketID: bucketID,
+						Start:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+						Window:   influxdb.Duration{Duration: window},
+						LineN:    2,
+						Measurements: []influxdb.MeasurementSchemaSample{{
+							Name:   "cpu",
+							LineN:  2,
+							Tags:   []influxdb.TagKeySample{{Key: "host", ValueN: 2}},
+							Fields: []influxdb.FieldSample{{Name: "usage", Types: []influxdb.FieldType{influxdb.FieldTypeFloat, influxdb.FieldTypeInteger}}},
+						}},
+						Producers: []influxdb.WriteProducerSample{{
+							AuthorizationID: 0x020f755c3c082002,
+							Kind:            influxdb.AuthorizationKind,
+							UserID:          0x020f755c3c082003,
+							LineN:           2,
+							LinesPerSecond:  2 / window.Seconds(),
+							SeriesN:         2,
+							Measurements:    []string{"cpu"},
+						}},
+					}, nil
+				}),
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "http://any.url/api/v2/write-schema-sample"+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("got status code %d, want %d: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.body); err != nil || !eq {
+				t.Errorf("unexpected body -want/+got:\n%s (%v)", diff, err)
+			}
+		})
+	}
+}
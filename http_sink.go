@@ -0,0 +1,256 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrHTTPSinkNotFound is the error for a missing HTTP sink.
+const ErrHTTPSinkNotFound = "http sink not found"
+
+const (
+	OpFindHTTPSinkByID = "FindHTTPSinkByID"
+	OpFindHTTPSink     = "FindHTTPSink"
+	OpFindHTTPSinks    = "FindHTTPSinks"
+	OpCreateHTTPSink   = "CreateHTTPSink"
+	OpUpdateHTTPSink   = "UpdateHTTPSink"
+	OpDeleteHTTPSink   = "DeleteHTTPSink"
+)
+
+// Authentication methods of HTTP sinks.
+const (
+	HTTPSinkAuthNone   = "none"
+	HTTPSinkAuthBasic  = "basic"
+	HTTPSinkAuthBearer = "bearer"
+)
+
+// Defaults of the retry policy of HTTP sinks.
+const (
+	DefaultHTTPSinkMaxAttempts = 3
+	DefaultHTTPSinkMinBackoff  = time.Second
+	DefaultHTTPSinkMaxBackoff  = 30 * time.Second
+)
+
+// HTTPSinkService manages the HTTP sinks of organizations, the named
+// endpoints which Flux scripts post data to with http.post(sink: name).
+type HTTPSinkService interface {
+	// FindHTTPSinkByID returns a single HTTP sink by ID.
+	FindHTTPSinkByID(ctx context.Context, id ID) (*HTTPSink, error)
+
+	// FindHTTPSink returns the first HTTP sink that matches filter.
+	FindHTTPSink(ctx context.Context, filter HTTPSinkFilter) (*HTTPSink, error)
+
+	// FindHTTPSinks returns a list of HTTP sinks that match filter.
+	FindHTTPSinks(ctx context.Context, filter HTTPSinkFilter) ([]*HTTPSink, error)
+
+	// CreateHTTPSink creates a new HTTP sink and sets s.ID with the new
+	// identifier. The value of its credentials is stored in the secrets of
+	// its organization.
+	CreateHTTPSink(ctx context.Context, s *HTTPSink) error
+
+	// UpdateHTTPSink updates a single HTTP sink with changeset.
+	UpdateHTTPSink(ctx context.Context, id ID, upd HTTPSinkUpdate) (*HTTPSink, error)
+
+	// DeleteHTTPSink removes an HTTP sink and its credentials by ID.
+	DeleteHTTPSink(ctx context.Context, id ID) error
+}
+
+// HTTPSink is a named endpoint of an organization that Flux scripts post
+// data to. Its credentials are kept in the secret store, so that they do not
+// appear in the scripts which use it.
+//
+// With basic authentication Credentials is the password of Username, and with
+// bearer authentication it is the token.
+type HTTPSink struct {
+	ID          ID                `json:"id,omitempty"`
+	OrgID       ID                `json:"orgID"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	AuthMethod  string            `json:"authMethod"`
+	Username    string            `json:"username,omitempty"`
+	Credentials SecretField       `json:"credentials"`
+	Retry       HTTPSinkRetry     `json:"retry"`
+	// RateLimit is the maximum number of requests per second made to the
+	// sink. Zero means no limit.
+	RateLimit float64 `json:"rateLimit,omitempty"`
+	CRUDLog
+}
+
+// HTTPSinkRetry is the retry policy of an HTTP sink. A request which fails
+// with a network error, a server error or too many requests is retried with
+// an exponential backoff between MinBackoff and MaxBackoff, up to MaxAttempts
+// requests.
+type HTTPSinkRetry struct {
+	MaxAttempts int      `json:"maxAttempts"`
+	MinBackoff  Duration `json:"minBackoff"`
+	MaxBackoff  Duration `json:"maxBackoff"`
+}
+
+// SecretKey returns the key of the secret of the credentials of the sink.
+func (s *HTTPSink) SecretKey() string {
+	return s.ID.String() + "-http-sink-credentials"
+}
+
+// SetDefaults sets the default method, authentication and retry policy of
+// the sink.
+func (s *HTTPSink) SetDefaults() {
+	if s.Method == "" {
+		s.Method = http.MethodPost
+	}
+	if s.AuthMethod == "" {
+		s.AuthMethod = HTTPSinkAuthNone
+	}
+	if s.Retry.MaxAttempts == 0 {
+		s.Retry.MaxAttempts = DefaultHTTPSinkMaxAttempts
+	}
+	if s.Retry.MinBackoff.Duration == 0 {
+		s.Retry.MinBackoff.Duration = DefaultHTTPSinkMinBackoff
+	}
+	if s.Retry.MaxBackoff.Duration == 0 {
+		s.Retry.MaxBackoff.Duration = DefaultHTTPSinkMaxBackoff
+	}
+}
+
+// Valid returns an error if the sink is invalid.
+func (s *HTTPSink) Valid() error {
+	if !s.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	if s.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "http sink name is required",
+		}
+	}
+	if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "http sink URL must be an absolute http or https URL",
+		}
+	}
+	if s.Method != http.MethodPost && s.Method != http.MethodPut {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("http sink method must be %s or %s", http.MethodPost, http.MethodPut),
+		}
+	}
+	switch s.AuthMethod {
+	case HTTPSinkAuthNone:
+	case HTTPSinkAuthBasic:
+		if s.Username == "" || (s.Credentials.Key == "" && s.Credentials.Value == nil) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "http sink basic authentication requires a username and credentials",
+			}
+		}
+	case HTTPSinkAuthBearer:
+		if s.Credentials.Key == "" && s.Credentials.Value == nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "http sink bearer authentication requires credentials",
+			}
+		}
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("unknown http sink authentication method %q", s.AuthMethod),
+		}
+	}
+	if s.Retry.MaxAttempts < 1 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "http sink must make at least one attempt",
+		}
+	}
+	if s.Retry.MinBackoff.Duration <= 0 || s.Retry.MaxBackoff.Duration < s.Retry.MinBackoff.Duration {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "http sink backoff must be positive and its maximum at least its minimum",
+		}
+	}
+	if s.RateLimit < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "http sink rate limit must not be negative",
+		}
+	}
+	return nil
+}
+
+// HTTPSinkUpdate is the changeset of an HTTP sink.
+type HTTPSinkUpdate struct {
+	Name        *string           `json:"name,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	URL         *string           `json:"url,omitempty"`
+	Method      *string           `json:"method,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	AuthMethod  *string           `json:"authMethod,omitempty"`
+	Username    *string           `json:"username,omitempty"`
+	Credentials *string           `json:"credentials,omitempty"`
+	Retry       *HTTPSinkRetry    `json:"retry,omitempty"`
+	RateLimit   *float64          `json:"rateLimit,omitempty"`
+}
+
+// Apply applies the changeset to s. A new value of the credentials is kept in
+// s.Credentials to be stored, and an empty one removes the credentials.
+func (u HTTPSinkUpdate) Apply(s *HTTPSink) {
+	if u.Name != nil {
+		s.Name = *u.Name
+	}
+	if u.Description != nil {
+		s.Description = *u.Description
+	}
+	if u.URL != nil {
+		s.URL = *u.URL
+	}
+	if u.Method != nil {
+		s.Method = *u.Method
+	}
+	if u.Headers != nil {
+		s.Headers = u.Headers
+	}
+	if u.AuthMethod != nil {
+		s.AuthMethod = *u.AuthMethod
+	}
+	if u.Username != nil {
+		s.Username = *u.Username
+	}
+	if u.Credentials != nil {
+		if *u.Credentials == "" {
+			s.Credentials = SecretField{}
+		} else {
+			s.Credentials.Value = u.Credentials
+		}
+	}
+	if u.Retry != nil {
+		s.Retry = *u.Retry
+	}
+	if u.RateLimit != nil {
+		s.RateLimit = *u.RateLimit
+	}
+	s.SetDefaults()
+}
+
+// HTTPSinkFilter represents a set of filters that restrict the returned
+// HTTP sinks.
+type HTTPSinkFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+}
+
+// Match returns true if the sink matches the filter.
+func (f HTTPSinkFilter) Match(s *HTTPSink) bool {
+	return (f.ID == nil || *f.ID == s.ID) &&
+		(f.OrgID == nil || *f.OrgID == s.OrgID) &&
+		(f.Name == nil || *f.Name == s.Name)
+}
@@ -97,20 +97,36 @@ func (s *Service) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (
 // FindMany returns a list of dbrpMappings that match filter and the total count of matching dbrp mappings.
 // Additional options provide pagination & sorting.
 func (s *Service) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
-	// filter by dbrpMapping id
-	if filter.Cluster != nil && filter.Database != nil && filter.RetentionPolicy != nil {
-		m, err := s.FindBy(ctx, *filter.Cluster, *filter.Database, *filter.RetentionPolicy)
-		if err != nil {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if len(opt) > 0 {
+		if err := influxdb.ValidateDBRPSort(opt[0]); err != nil {
 			return nil, 0, err
 		}
-		return []*influxdb.DBRPMapping{m}, 1, nil
 	}
 
 	filterFunc := func(mapping *influxdb.DBRPMapping) bool {
 		return (filter.Cluster == nil || (*filter.Cluster) == mapping.Cluster) &&
-			(filter.Database == nil || (*filter.Database) == mapping.Database) &&
+			filter.MatchesDatabase(mapping.Database) &&
 			(filter.RetentionPolicy == nil || (*filter.RetentionPolicy) == mapping.RetentionPolicy) &&
-			(filter.Default == nil || (*filter.Default) == mapping.Default)
+			(filter.Default == nil || (*filter.Default) == mapping.Default) &&
+			filter.MatchesBucket(mapping.BucketID)
+	}
+
+	// filter by dbrpMapping id
+	if filter.Cluster != nil && filter.Database != nil && filter.RetentionPolicy != nil {
+		m, err := s.FindBy(ctx, *filter.Cluster, *filter.Database, *filter.RetentionPolicy)
+		if err == errDBRPMappingNotFound {
+			return []*influxdb.DBRPMapping{}, 0, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if !filterFunc(m) {
+			return []*influxdb.DBRPMapping{}, 0, nil
+		}
+		return []*influxdb.DBRPMapping{m}, 1, nil
 	}
 
 	mappings, err := s.filterDBRPMappings(ctx, filterFunc)
@@ -118,7 +134,12 @@ func (s *Service) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilte
 		return nil, 0, err
 	}
 
-	return mappings, len(mappings), nil
+	n := len(mappings)
+	if len(opt) > 0 {
+		influxdb.SortDBRPMappings(opt[0], mappings)
+		mappings = influxdb.PageDBRPMappings(opt[0], mappings)
+	}
+	return mappings, n, nil
 }
 
 // Create creates a new dbrp mapping.
@@ -127,23 +148,120 @@ func (s *Service) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
 		return nil
 	}
 	existing, err := s.loadDBRPMapping(ctx, m.Cluster, m.Database, m.RetentionPolicy)
-	if err != nil {
-		if err == errDBRPMappingNotFound {
-			return s.PutDBRPMapping(ctx, m)
-		}
+	if err != nil && err != errDBRPMappingNotFound {
 		return err
 	}
 
-	if !existing.Equal(m) {
+	if err == nil && !existing.Equal(m) {
 		return &influxdb.Error{
 			Code: influxdb.EConflict,
 			Msg:  "dbrp mapping already exists",
 		}
 	}
 
+	if m.Default {
+		if err := s.clearOtherDefaults(ctx, m); err != nil {
+			return err
+		}
+	}
+	return s.PutDBRPMapping(ctx, m)
+}
+
+// Upsert creates a dbrp mapping, or replaces the mapping of its cluster, db
+// and rp if it is of the same organization.
+func (s *Service) Upsert(ctx context.Context, m *influxdb.DBRPMapping) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	existing, err := s.loadDBRPMapping(ctx, m.Cluster, m.Database, m.RetentionPolicy)
+	if err != nil && err != errDBRPMappingNotFound {
+		return err
+	}
+	if err == nil && existing.OrganizationID != m.OrganizationID {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "dbrp mapping already exists in another organization",
+		}
+	}
+
+	if m.Default {
+		if err := s.clearOtherDefaults(ctx, m); err != nil {
+			return err
+		}
+	}
+	return s.PutDBRPMapping(ctx, m)
+}
+
+// Replace replaces the dbrp mapping of the cluster, db and rp of m only if
+// its revision is revision.
+func (s *Service) Replace(ctx context.Context, m *influxdb.DBRPMapping, revision string) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	existing, err := s.loadDBRPMapping(ctx, m.Cluster, m.Database, m.RetentionPolicy)
+	if err == errDBRPMappingNotFound {
+		return influxdb.ErrDBRPMappingChanged
+	} else if err != nil {
+		return err
+	}
+	if existing.OrganizationID != m.OrganizationID {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "dbrp mapping already exists in another organization",
+		}
+	}
+	if existing.Revision() != revision {
+		return influxdb.ErrDBRPMappingChanged
+	}
+
+	if m.Default {
+		if err := s.clearOtherDefaults(ctx, m); err != nil {
+			return err
+		}
+	}
 	return s.PutDBRPMapping(ctx, m)
 }
 
+// SetDefault makes the dbrp mapping of the cluster, db and rp the default for
+// its cluster and db, clearing the default of the other mappings of its
+// organization, cluster and db.
+func (s *Service) SetDefault(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	m, err := s.loadDBRPMapping(ctx, cluster, db, rp)
+	if err != nil {
+		return nil, err
+	}
+	m.Default = true
+	if err := s.clearOtherDefaults(ctx, m); err != nil {
+		return nil, err
+	}
+	if err := s.PutDBRPMapping(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// clearOtherDefaults clears the default of the dbrp mappings of the
+// organization, cluster and db of m to other rps.
+func (s *Service) clearOtherDefaults(ctx context.Context, m *influxdb.DBRPMapping) error {
+	defaults, err := s.filterDBRPMappings(ctx, func(o *influxdb.DBRPMapping) bool {
+		return o.Default &&
+			o.OrganizationID == m.OrganizationID &&
+			o.Cluster == m.Cluster &&
+			o.Database == m.Database &&
+			o.RetentionPolicy != m.RetentionPolicy
+	})
+	if err != nil {
+		return err
+	}
+	for _, o := range defaults {
+		o.Default = false
+		if err := s.PutDBRPMapping(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PutDBRPMapping sets dbrpMapping with the current ID.
 func (s *Service) PutDBRPMapping(ctx context.Context, m *influxdb.DBRPMapping) error {
 	k := encodeDBRPMappingKey(m.Cluster, m.Database, m.RetentionPolicy)
@@ -37,6 +37,21 @@ func TestDBRPMappingService_DeleteDBRPMapping(t *testing.T) {
 	platformtesting.DeleteDBRPMapping(initDBRPMappingService, t)
 }
 
+func TestDBRPMappingService_UpsertDBRPMapping(t *testing.T) {
+	t.Parallel()
+	platformtesting.UpsertDBRPMapping(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_SetDefaultDBRPMapping(t *testing.T) {
+	t.Parallel()
+	platformtesting.SetDefaultDBRPMapping(initDBRPMappingService, t)
+}
+
+func TestDBRPMappingService_ReplaceDBRPMapping(t *testing.T) {
+	t.Parallel()
+	platformtesting.ReplaceDBRPMapping(initDBRPMappingService, t)
+}
+
 func TestDBRPMappingService_FindDBRPMapping(t *testing.T) {
 	t.Parallel()
 	platformtesting.FindDBRPMapping(initDBRPMappingService, t)
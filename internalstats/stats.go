@@ -0,0 +1,137 @@
+// Package internalstats keeps the counters of the write and query requests
+// served by the instance, per organization and bucket, so that they may be
+// queried with Flux without scraping the prometheus metrics of the server.
+package internalstats
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http/metric"
+)
+
+// Kinds of requests counted.
+const (
+	KindWrite = "write"
+	KindQuery = "query"
+)
+
+// Counters are the counters of the requests of a kind to a bucket.
+type Counters struct {
+	// Accepted and Rejected are the numbers of requests which succeeded
+	// and failed.
+	Accepted int64
+	Rejected int64
+	// PointsAccepted and PointsRejected are the numbers of points of the
+	// write requests which succeeded and failed.
+	PointsAccepted int64
+	PointsRejected int64
+	// RequestBytes and ResponseBytes are the sizes of the bodies of the
+	// requests and of their responses.
+	RequestBytes  int64
+	ResponseBytes int64
+	// DurationTotal and DurationMax are the total and the longest time
+	// taken to serve a request.
+	DurationTotal time.Duration
+	DurationMax   time.Duration
+}
+
+// Stat are the counters of the requests of a kind to a bucket of an
+// organization. The bucket is not set for queries.
+type Stat struct {
+	Kind     string
+	OrgID    influxdb.ID
+	BucketID influxdb.ID
+	Counters
+}
+
+type key struct {
+	kind     string
+	orgID    influxdb.ID
+	bucketID influxdb.ID
+}
+
+// Stats counts the requests served by the instance since it started.
+type Stats struct {
+	mu    sync.Mutex
+	stats map[key]*Counters
+}
+
+// New returns new, empty, Stats.
+func New() *Stats {
+	return &Stats{
+		stats: make(map[key]*Counters),
+	}
+}
+
+// Recorder returns an event recorder counting the events as requests of
+// the given kind.
+func (s *Stats) Recorder(kind string) metric.EventRecorder {
+	return &recorder{s: s, kind: kind}
+}
+
+type recorder struct {
+	s    *Stats
+	kind string
+}
+
+// Record counts the request of the event. The requests of no organization,
+// such as the unauthorized ones, are not counted.
+func (r *recorder) Record(ctx context.Context, e metric.Event) {
+	if !e.OrgID.Valid() {
+		return
+	}
+	k := key{kind: r.kind, orgID: e.OrgID, bucketID: e.BucketID}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.stats[k]
+	if !ok {
+		c = &Counters{}
+		r.s.stats[k] = c
+	}
+	if e.Status < http.StatusBadRequest {
+		c.Accepted++
+		c.PointsAccepted += int64(e.Points)
+	} else {
+		c.Rejected++
+		c.PointsRejected += int64(e.Points)
+	}
+	c.RequestBytes += int64(e.RequestBytes)
+	c.ResponseBytes += int64(e.ResponseBytes)
+	c.DurationTotal += e.Duration
+	if e.Duration > c.DurationMax {
+		c.DurationMax = e.Duration
+	}
+}
+
+// Find returns the stats of the organization, sorted by kind and bucket.
+func (s *Stats) Find(orgID influxdb.ID) []Stat {
+	s.mu.Lock()
+	var stats []Stat
+	for k, c := range s.stats {
+		if k.orgID != orgID {
+			continue
+		}
+		stats = append(stats, Stat{
+			Kind:     k.kind,
+			OrgID:    k.orgID,
+			BucketID: k.bucketID,
+			Counters: *c,
+		})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Kind != stats[j].Kind {
+			return stats[i].Kind < stats[j].Kind
+		}
+		return stats[i].BucketID < stats[j].BucketID
+	})
+	return stats
+}
@@ -0,0 +1,38 @@
+package internalstats
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http/metric"
+)
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	write, query := s.Recorder(KindWrite), s.Recorder(KindQuery)
+
+	org, other := influxdb.ID(1), influxdb.ID(2)
+	write.Record(ctx, metric.Event{OrgID: org, BucketID: 20, Status: http.StatusNoContent, Points: 5, RequestBytes: 100, Duration: time.Second})
+	write.Record(ctx, metric.Event{OrgID: org, BucketID: 10, Status: http.StatusNoContent, Points: 3, RequestBytes: 50, Duration: 2 * time.Second})
+	write.Record(ctx, metric.Event{OrgID: org, BucketID: 10, Status: http.StatusBadRequest, Points: 2, RequestBytes: 10, Duration: time.Second})
+	write.Record(ctx, metric.Event{OrgID: other, BucketID: 30, Status: http.StatusNoContent, Points: 1})
+	write.Record(ctx, metric.Event{Status: http.StatusUnauthorized})
+	query.Record(ctx, metric.Event{OrgID: org, Status: http.StatusOK, ResponseBytes: 42, Duration: time.Second})
+
+	want := []Stat{
+		{Kind: KindQuery, OrgID: org, Counters: Counters{Accepted: 1, ResponseBytes: 42, DurationTotal: time.Second, DurationMax: time.Second}},
+		{Kind: KindWrite, OrgID: org, BucketID: 10, Counters: Counters{Accepted: 1, Rejected: 1, PointsAccepted: 3, PointsRejected: 2, RequestBytes: 60, DurationTotal: 3 * time.Second, DurationMax: 2 * time.Second}},
+		{Kind: KindWrite, OrgID: org, BucketID: 20, Counters: Counters{Accepted: 1, PointsAccepted: 5, RequestBytes: 100, DurationTotal: time.Second, DurationMax: time.Second}},
+	}
+	if diff := cmp.Diff(want, s.Find(org)); diff != "" {
+		t.Errorf("unexpected stats -want/+got:\n%s", diff)
+	}
+	if got := s.Find(influxdb.ID(3)); len(got) != 0 {
+		t.Errorf("expected no stats for an unknown organization, got %v", got)
+	}
+}
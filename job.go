@@ -0,0 +1,94 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrJobNotFound is the error for a missing job.
+const ErrJobNotFound = "job not found"
+
+const (
+	OpFindJobByID = "FindJobByID"
+	OpFindJobs    = "FindJobs"
+	OpCancelJob   = "CancelJob"
+)
+
+// JobKind is the kind of operation a job runs.
+type JobKind string
+
+const (
+	// JobKindOrgDeletion deletes an organization and all of its resources.
+	JobKindOrgDeletion JobKind = "org-deletion"
+	// JobKindBucketDeletion deletes the data of a bucket from the storage engine.
+	JobKindBucketDeletion JobKind = "bucket-deletion"
+	// JobKindBucketSnapshotClone writes the data of a bucket snapshot into a new bucket.
+	JobKindBucketSnapshotClone JobKind = "bucket-snapshot-clone"
+	// JobKindFieldTypeConversion casts the values of a field to a single type.
+	JobKindFieldTypeConversion JobKind = "field-type-conversion"
+	// JobKindStaleResourceReport finds the resources of an instance which are no longer in use.
+	JobKindStaleResourceReport JobKind = "stale-resource-report"
+)
+
+// JobStatus is the status of a job.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// Job is a long-running operation of the server, such as the deletion of an
+// organization, which runs in the background of the request that started it.
+type Job struct {
+	ID    ID      `json:"id"`
+	Kind  JobKind `json:"kind"`
+	OrgID ID      `json:"orgID,omitempty"`
+	// ResourceID is the ID of the resource the job operates on.
+	ResourceID ID        `json:"resourceID,omitempty"`
+	Status     JobStatus `json:"status"`
+	// Stage describes the step the job is running, if it runs in steps.
+	Stage    string      `json:"stage,omitempty"`
+	Progress JobProgress `json:"progress"`
+	Error    string      `json:"error,omitempty"`
+
+	StartedAt   time.Time  `json:"startedAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Done returns true if the job is no longer running.
+func (j *Job) Done() bool {
+	return j.Status != JobRunning
+}
+
+// JobProgress is the amount of work a job has done. Total is zero if the
+// amount of work is not known in advance.
+type JobProgress struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total,omitempty"`
+}
+
+// JobFilter represents a set of filters that restrict the returned jobs.
+type JobFilter struct {
+	OrgID  *ID
+	Kind   *JobKind
+	Status *JobStatus
+}
+
+// JobService lists the jobs running in the background of the server and
+// cancels them.
+type JobService interface {
+	// FindJobByID returns a single job by ID.
+	FindJobByID(ctx context.Context, id ID) (*Job, error)
+
+	// FindJobs returns the jobs that match filter, most recently started
+	// first. Jobs are kept for a while once they are done.
+	FindJobs(ctx context.Context, filter JobFilter) ([]*Job, error)
+
+	// CancelJob cancels a running job. The job stops at the end of its
+	// current step.
+	CancelJob(ctx context.Context, id ID) (*Job, error)
+}
@@ -0,0 +1,276 @@
+// Package jobs runs the long-running operations of the server, such as
+// organization deletions, as jobs whose progress can be listed and which can
+// be canceled.
+package jobs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxDoneJobs is the number of jobs kept once they are done.
+const DefaultMaxDoneJobs = 1000
+
+// Func runs a job. It returns when the job is done or once ctx is canceled,
+// and reports its progress to p. It is called even if the job is canceled
+// before it starts, so that it may release what it holds.
+type Func func(ctx context.Context, p *Progress) error
+
+// Manager runs jobs and keeps track of them. Jobs are kept in memory, so
+// only the jobs started since the server started are listed.
+type Manager struct {
+	log   *zap.Logger
+	idGen influxdb.IDGenerator
+	now   func() time.Time
+
+	// MaxDoneJobs is the number of jobs kept once they are done. The
+	// oldest jobs are forgotten first.
+	MaxDoneJobs int
+
+	wg sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+	jobs   map[influxdb.ID]*job
+	done   []influxdb.ID
+}
+
+type job struct {
+	influxdb.Job
+	cancel context.CancelFunc
+}
+
+var _ influxdb.JobService = (*Manager)(nil)
+
+// NewManager returns a new Manager.
+func NewManager(log *zap.Logger) *Manager {
+	return &Manager{
+		log:         log,
+		idGen:       snowflake.NewIDGenerator(),
+		now:         time.Now,
+		MaxDoneJobs: DefaultMaxDoneJobs,
+		jobs:        make(map[influxdb.ID]*job),
+	}
+}
+
+// Close cancels the running jobs and waits for the ones started with Start
+// to return.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	for _, j := range m.jobs {
+		if !j.Done() {
+			j.cancel()
+		}
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+	return nil
+}
+
+// Start runs fn in the background as the job j, of which the kind and the
+// resource should be set. The job is canceled when ctx is canceled, so ctx
+// should outlive the request starting the job. A copy of the started job is
+// returned.
+func (m *Manager) Start(ctx context.Context, j *influxdb.Job, fn Func) *influxdb.Job {
+	ctx, jj := m.create(ctx, j)
+	res := jj.Job
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.run(ctx, jj, fn)
+	}()
+	return &res
+}
+
+// Run runs fn as the job j, of which the kind and the resource should be
+// set, and returns once it is done. The job is listed while it runs and may
+// be canceled like the jobs started with Start.
+func (m *Manager) Run(ctx context.Context, j *influxdb.Job, fn Func) error {
+	ctx, jj := m.create(ctx, j)
+	return m.run(ctx, jj, fn)
+}
+
+func (m *Manager) create(ctx context.Context, j *influxdb.Job) (context.Context, *job) {
+	ctx, cancel := context.WithCancel(ctx)
+	now := m.now().UTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jj := &job{Job: *j, cancel: cancel}
+	jj.ID = m.idGen.ID()
+	jj.Status = influxdb.JobRunning
+	jj.Error = ""
+	jj.StartedAt = now
+	jj.UpdatedAt = now
+	jj.CompletedAt = nil
+	m.jobs[jj.ID] = jj
+	if m.closed {
+		cancel()
+	}
+	*j = jj.Job
+	return ctx, jj
+}
+
+func (m *Manager) run(ctx context.Context, j *job, fn Func) error {
+	log := m.log.With(zap.Stringer("jobID", j.ID), zap.String("kind", string(j.Kind)))
+	log.Debug("Job started")
+
+	err := fn(ctx, &Progress{m: m, id: j.ID})
+	canceled := ctx.Err() != nil
+	j.cancel()
+
+	now := m.now().UTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case err == nil:
+		j.Status = influxdb.JobSucceeded
+	case canceled:
+		j.Status = influxdb.JobCanceled
+		j.Error = err.Error()
+	default:
+		j.Status = influxdb.JobFailed
+		j.Error = err.Error()
+	}
+	j.UpdatedAt = now
+	j.CompletedAt = &now
+
+	m.done = append(m.done, j.ID)
+	for len(m.done) > m.MaxDoneJobs {
+		delete(m.jobs, m.done[0])
+		m.done = m.done[1:]
+	}
+
+	if j.Status == influxdb.JobFailed {
+		log.Error("Job failed", zap.Error(err))
+	} else {
+		log.Debug("Job done", zap.String("status", string(j.Status)))
+	}
+	return err
+}
+
+// FindJobByID returns a single job by ID.
+func (m *Manager) FindJobByID(ctx context.Context, id influxdb.ID) (*influxdb.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Op:   influxdb.OpFindJobByID,
+			Msg:  influxdb.ErrJobNotFound,
+		}
+	}
+	res := j.Job
+	return &res, nil
+}
+
+// FindJobs returns the jobs that match filter, most recently started first.
+func (m *Manager) FindJobs(ctx context.Context, filter influxdb.JobFilter) ([]*influxdb.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	js := make([]*influxdb.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		if filter.OrgID != nil && j.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.Kind != nil && j.Kind != *filter.Kind {
+			continue
+		}
+		if filter.Status != nil && j.Status != *filter.Status {
+			continue
+		}
+		res := j.Job
+		js = append(js, &res)
+	}
+	sort.Slice(js, func(i, k int) bool {
+		if !js[i].StartedAt.Equal(js[k].StartedAt) {
+			return js[i].StartedAt.After(js[k].StartedAt)
+		}
+		return js[i].ID > js[k].ID
+	})
+	return js, nil
+}
+
+// CancelJob cancels a running job. The job stops at the end of its current
+// step, so it is still running when CancelJob returns.
+func (m *Manager) CancelJob(ctx context.Context, id influxdb.ID) (*influxdb.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Op:   influxdb.OpCancelJob,
+			Msg:  influxdb.ErrJobNotFound,
+		}
+	}
+	if j.Done() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Op:   influxdb.OpCancelJob,
+			Msg:  "job is not running",
+		}
+	}
+	j.cancel()
+	res := j.Job
+	return &res, nil
+}
+
+// Progress reports the progress of a job. A nil Progress discards the
+// progress, for operations which run outside of a Manager.
+type Progress struct {
+	m  *Manager
+	id influxdb.ID
+}
+
+func (p *Progress) update(fn func(j *influxdb.Job)) {
+	if p == nil {
+		return
+	}
+	now := p.m.now().UTC()
+
+	p.m.mu.Lock()
+	defer p.m.mu.Unlock()
+
+	if j, ok := p.m.jobs[p.id]; ok && !j.Done() {
+		fn(&j.Job)
+		j.UpdatedAt = now
+	}
+}
+
+// SetStage sets the step the job is running.
+func (p *Progress) SetStage(stage string) {
+	p.update(func(j *influxdb.Job) {
+		j.Stage = stage
+	})
+}
+
+// SetTotal sets the total amount of work of the job, once it is known.
+func (p *Progress) SetTotal(n int64) {
+	p.update(func(j *influxdb.Job) {
+		j.Progress.Total = n
+	})
+}
+
+// Add adds n to the amount of work the job has done.
+func (p *Progress) Add(n int64) {
+	p.update(func(j *influxdb.Job) {
+		j.Progress.Done += n
+	})
+}
@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap/zaptest"
+)
+
+func waitForJob(t *testing.T, m *Manager, id influxdb.ID) *influxdb.Job {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		j, err := m.FindJobByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j.Done() {
+			return j
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s is still running", id)
+	return nil
+}
+
+func TestManager_Start(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(zaptest.NewLogger(t))
+	defer m.Close()
+
+	orgID := influxdb.ID(1)
+	release := make(chan struct{})
+	j := m.Start(ctx, &influxdb.Job{Kind: influxdb.JobKindBucketDeletion, OrgID: orgID}, func(ctx context.Context, p *Progress) error {
+		p.SetTotal(2)
+		p.SetStage("delete")
+		p.Add(1)
+		<-release
+		p.Add(1)
+		return nil
+	})
+	if !j.ID.Valid() || j.Status != influxdb.JobRunning {
+		t.Fatalf("unexpected job %+v", j)
+	}
+
+	running := influxdb.JobRunning
+	js, err := m.FindJobs(ctx, influxdb.JobFilter{OrgID: &orgID, Status: &running})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(js) != 1 || js[0].ID != j.ID {
+		t.Fatalf("expected the job to be running, got %+v", js)
+	}
+
+	close(release)
+	got := waitForJob(t, m, j.ID)
+	if got.Status != influxdb.JobSucceeded || got.CompletedAt == nil {
+		t.Errorf("expected the job to succeed, got %+v", got)
+	}
+	if got.Stage != "delete" || got.Progress != (influxdb.JobProgress{Done: 2, Total: 2}) {
+		t.Errorf("unexpected progress %q %+v", got.Stage, got.Progress)
+	}
+
+	other := influxdb.ID(2)
+	if js, err := m.FindJobs(ctx, influxdb.JobFilter{OrgID: &other}); err != nil || len(js) != 0 {
+		t.Errorf("expected no job of another organization, got %v %v", js, err)
+	}
+}
+
+func TestManager_CancelJob(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(zaptest.NewLogger(t))
+	defer m.Close()
+
+	j := m.Start(ctx, &influxdb.Job{Kind: influxdb.JobKindOrgDeletion}, func(ctx context.Context, p *Progress) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if _, err := m.CancelJob(ctx, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := waitForJob(t, m, j.ID); got.Status != influxdb.JobCanceled {
+		t.Errorf("expected the job to be canceled, got %+v", got)
+	}
+	if _, err := m.CancelJob(ctx, j.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected a conflict canceling a canceled job, got %v", err)
+	}
+	if _, err := m.CancelJob(ctx, influxdb.ID(42)); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected a missing job not to be found, got %v", err)
+	}
+}
+
+func TestManager_Run(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(zaptest.NewLogger(t))
+	m.MaxDoneJobs = 1
+	defer m.Close()
+
+	want := errors.New("clone failed")
+	j := &influxdb.Job{Kind: influxdb.JobKindBucketSnapshotClone}
+	if err := m.Run(ctx, j, func(ctx context.Context, p *Progress) error { return want }); err != want {
+		t.Fatalf("got error %v, want %v", err, want)
+	}
+	got, err := m.FindJobByID(ctx, j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != influxdb.JobFailed || got.Error != "clone failed" {
+		t.Errorf("expected the job to fail, got %+v", got)
+	}
+
+	if err := m.Run(ctx, &influxdb.Job{}, func(ctx context.Context, p *Progress) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.FindJobByID(ctx, j.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the oldest done job to be forgotten, got %v", err)
+	}
+}
+
+func TestManager_Close(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(zaptest.NewLogger(t))
+
+	j := m.Start(ctx, &influxdb.Job{}, func(ctx context.Context, p *Progress) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.FindJobByID(ctx, j.ID); got.Status != influxdb.JobCanceled {
+		t.Errorf("expected the job to be canceled by Close, got %+v", got)
+	}
+}
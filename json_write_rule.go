@@ -0,0 +1,130 @@
+package influxdb
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2/pkg/jsonpath"
+)
+
+// JSONWriteRule extracts points from the JSON documents written to a bucket,
+// so that sources such as webhooks can write without converting their
+// documents to line protocol.
+//
+// Paths are JSONPath expressions. Path selects the values of a document that
+// a point is extracted from; the paths of the measurement, tags, fields and
+// time select a single value, relative to the selected value when they start
+// with "@", or to the document when they start with "$".
+type JSONWriteRule struct {
+	Path string `json:"path"`
+
+	// Measurement is the measurement of points with no value at
+	// MeasurementPath.
+	Measurement     string `json:"measurement,omitempty"`
+	MeasurementPath string `json:"measurementPath,omitempty"`
+
+	Tags   []JSONWriteValue `json:"tags,omitempty"`
+	Fields []JSONWriteValue `json:"fields"`
+
+	// TimePath selects the time of points, which is the time they are
+	// written when it has no value.
+	TimePath string `json:"timePath,omitempty"`
+	// TimeFormat is the format of times: "RFC3339" (the default),
+	// "RFC3339Nano" or a time.Parse layout for strings, or "s", "ms", "us" or
+	// "ns" for numbers since the epoch.
+	TimeFormat string `json:"timeFormat,omitempty"`
+}
+
+// JSONWriteValue is a tag or field whose value is extracted by a JSONWriteRule.
+type JSONWriteValue struct {
+	Key  string `json:"key"`
+	Path string `json:"path"`
+	// Type is the type of a field: "float", "integer", "unsigned", "string"
+	// or "boolean". When empty, it is the type of the JSON value, with numbers
+	// as floats.
+	Type string `json:"type,omitempty"`
+}
+
+// JSONWriteFieldTypes are the types of the fields of JSON write rules.
+var JSONWriteFieldTypes = []string{"float", "integer", "unsigned", "string", "boolean"}
+
+// ValidJSONWriteRules returns an error if any of the JSON write rules of a
+// bucket is invalid.
+func ValidJSONWriteRules(rules []JSONWriteRule) error {
+	for i, r := range rules {
+		if err := r.valid(); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid JSON write rule %d", i),
+				Err:  err,
+			}
+		}
+	}
+	return nil
+}
+
+func (r *JSONWriteRule) valid() error {
+	if _, err := jsonpath.Parse(r.Path); err != nil {
+		return err
+	}
+	if r.Measurement == "" && r.MeasurementPath == "" {
+		return fmt.Errorf("measurement or measurementPath is required")
+	}
+	if len(r.Fields) == 0 {
+		return fmt.Errorf("at least one field is required")
+	}
+	for _, p := range []string{r.MeasurementPath, r.TimePath} {
+		if p != "" {
+			if err := validValuePath(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	keys := make(map[string]bool)
+	for _, values := range [][]JSONWriteValue{r.Tags, r.Fields} {
+		for _, v := range values {
+			if v.Key == "" {
+				return fmt.Errorf("tag and field keys are required")
+			}
+			if keys[v.Key] {
+				return fmt.Errorf("key %q is extracted more than once", v.Key)
+			}
+			keys[v.Key] = true
+			if err := validValuePath(v.Path); err != nil {
+				return err
+			}
+		}
+	}
+	for _, v := range r.Tags {
+		if v.Type != "" {
+			return fmt.Errorf("tag %q must not have a type", v.Key)
+		}
+	}
+	for _, v := range r.Fields {
+		if v.Type != "" && !containsString(JSONWriteFieldTypes, v.Type) {
+			return fmt.Errorf("field %q has invalid type %q", v.Key, v.Type)
+		}
+	}
+	return nil
+}
+
+// validValuePath returns an error if p does not select a single value.
+func validValuePath(p string) error {
+	path, err := jsonpath.Parse(p)
+	if err != nil {
+		return err
+	}
+	if !path.Definite() {
+		return fmt.Errorf("path %q must select a single value", p)
+	}
+	return nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
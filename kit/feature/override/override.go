@@ -29,7 +29,7 @@ func (f Flagger) Flags(_ context.Context, flags ...feature.Flag) (map[string]int
 	m := make(map[string]interface{}, len(flags))
 	for _, flag := range flags {
 		if s, overridden := f.overrides[flag.Key()]; overridden {
-			iface, err := f.coerce(s, flag)
+			iface, err := Coerce(s, flag)
 			if err != nil {
 				return nil, err
 			}
@@ -42,7 +42,9 @@ func (f Flagger) Flags(_ context.Context, flags ...feature.Flag) (map[string]int
 	return m, nil
 }
 
-func (Flagger) coerce(s string, flag feature.Flag) (iface interface{}, err error) {
+// Coerce parses the text representation s of a value of flag into a value
+// of the type of the flag.
+func Coerce(s string, flag feature.Flag) (iface interface{}, err error) {
 	switch flag.(type) {
 	case feature.BoolFlag:
 		iface, err = strconv.ParseBool(s)
@@ -0,0 +1,195 @@
+// Package settings computes feature flags with the values persisted for the
+// instance and for organizations by a influxdb.FeatureFlagService, so flags
+// may be changed at runtime and rolled out to some organizations first.
+package settings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/kit/feature/override"
+)
+
+// DefaultRefreshInterval is how long the settings are cached for by default.
+const DefaultRefreshInterval = 10 * time.Second
+
+var (
+	_ influxdb.FeatureFlagService = (*Service)(nil)
+	_ feature.Flagger             = (*Service)(nil)
+)
+
+// Service is both a influxdb.FeatureFlagService which only accepts values
+// which may be parsed for a known flag, and a feature.Flagger which applies
+// the settings to the values of a base flagger. The value set for the
+// organization of the authorization on the context takes precedence over
+// the value set for the instance, which takes precedence over the base.
+//
+// The settings are cached and loaded again once the refresh interval has
+// passed, or once they are changed through the Service.
+type Service struct {
+	next    influxdb.FeatureFlagService
+	base    feature.Flagger
+	byKey   feature.ByKeyFn
+	refresh time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	instance map[string]interface{}
+	orgs     map[influxdb.ID]map[string]interface{}
+}
+
+// NewService returns a Service persisting settings with next and applying
+// them to the flags computed by base. The settings are loaded again at most
+// every refresh interval.
+func NewService(next influxdb.FeatureFlagService, base feature.Flagger, refresh time.Duration) *Service {
+	return &Service{
+		next:    next,
+		base:    base,
+		byKey:   feature.ByKey,
+		refresh: refresh,
+		now:     time.Now,
+	}
+}
+
+// Flags returns the values of flags computed by the base flagger, with the
+// settings of the instance and of the organization on the context applied.
+func (s *Service) Flags(ctx context.Context, flags ...feature.Flag) (map[string]interface{}, error) {
+	m, err := s.base.Flags(ctx, flags...)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, orgs, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range instance {
+		if _, ok := m[k]; ok {
+			m[k] = v
+		}
+	}
+	if orgID, ok := orgFromContext(ctx); ok {
+		for k, v := range orgs[orgID] {
+			if _, ok := m[k]; ok {
+				m[k] = v
+			}
+		}
+	}
+	return m, nil
+}
+
+// orgFromContext returns the organization of the authorization on ctx. A
+// session is not tied to an organization, so only the settings of the
+// instance apply to it.
+func orgFromContext(ctx context.Context) (influxdb.ID, bool) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return 0, false
+	}
+	auth, ok := a.(*influxdb.Authorization)
+	if !ok {
+		return 0, false
+	}
+	return auth.OrgID, auth.OrgID.Valid()
+}
+
+// settings returns the parsed values of the settings of the instance and of
+// each organization, loading them if the cache has expired.
+func (s *Service) settings(ctx context.Context) (map[string]interface{}, map[influxdb.ID]map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.instance != nil && s.now().Sub(s.loadedAt) < s.refresh {
+		return s.instance, s.orgs, nil
+	}
+
+	ss, err := s.next.FindFeatureFlagSettings(ctx, influxdb.FeatureFlagSettingFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	instance := make(map[string]interface{})
+	orgs := make(map[influxdb.ID]map[string]interface{})
+	for _, fs := range ss {
+		// Settings of flags which have been removed, or whose type has
+		// changed since they were set, are ignored.
+		flag, ok := s.byKey(fs.Key)
+		if !ok {
+			continue
+		}
+		v, err := override.Coerce(fs.Value, flag)
+		if err != nil {
+			continue
+		}
+
+		if fs.OrgID == nil {
+			instance[fs.Key] = v
+			continue
+		}
+		if orgs[*fs.OrgID] == nil {
+			orgs[*fs.OrgID] = make(map[string]interface{})
+		}
+		orgs[*fs.OrgID][fs.Key] = v
+	}
+
+	s.instance, s.orgs, s.loadedAt = instance, orgs, s.now()
+	return instance, orgs, nil
+}
+
+// invalidate discards the cached settings.
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.instance, s.orgs = nil, nil
+	s.mu.Unlock()
+}
+
+// FindFeatureFlagSettings returns the settings that match filter.
+func (s *Service) FindFeatureFlagSettings(ctx context.Context, filter influxdb.FeatureFlagSettingFilter) ([]*influxdb.FeatureFlagSetting, error) {
+	return s.next.FindFeatureFlagSettings(ctx, filter)
+}
+
+// SetFeatureFlagSetting sets the value of a flag after checking that the
+// flag exists and that the value may be parsed for its type. The value is
+// stored in its canonical form, such as "true" for "TRUE".
+func (s *Service) SetFeatureFlagSetting(ctx context.Context, fs *influxdb.FeatureFlagSetting) error {
+	flag, ok := s.byKey(fs.Key)
+	if !ok {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpSetFeatureFlagSetting,
+			Msg:  fmt.Sprintf("unknown feature flag %q", fs.Key),
+		}
+	}
+	v, err := override.Coerce(fs.Value, flag)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpSetFeatureFlagSetting,
+			Msg:  fmt.Sprintf("invalid value for feature flag %q", fs.Key),
+			Err:  err,
+		}
+	}
+	fs.Value = fmt.Sprint(v)
+
+	defer s.invalidate()
+	return s.next.SetFeatureFlagSetting(ctx, fs)
+}
+
+// DeleteFeatureFlagSetting removes the value of a flag set for the instance,
+// or for the organization orgID if it is not nil.
+func (s *Service) DeleteFeatureFlagSetting(ctx context.Context, key string, orgID *influxdb.ID) error {
+	defer s.invalidate()
+	return s.next.DeleteFeatureFlagSetting(ctx, key, orgID)
+}
+
+// FindFeatureFlagChanges returns the changes made to the settings that match filter.
+func (s *Service) FindFeatureFlagChanges(ctx context.Context, filter influxdb.FeatureFlagChangeFilter, opts ...influxdb.FindOptions) ([]*influxdb.FeatureFlagChange, error) {
+	return s.next.FindFeatureFlagChanges(ctx, filter, opts...)
+}
@@ -0,0 +1,183 @@
+package settings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+type baseFlagger map[string]interface{}
+
+func (b baseFlagger) Flags(_ context.Context, flags ...feature.Flag) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(flags))
+	for _, flag := range flags {
+		m[flag.Key()] = flag.Default()
+		if v, ok := b[flag.Key()]; ok {
+			m[flag.Key()] = v
+		}
+	}
+	return m, nil
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	kvSvc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := kvSvc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := kvSvc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	boolFlag := feature.MakeBoolFlag("Bool", "boolFlag", "owner", false, feature.Temporary, false)
+	intFlag := feature.MakeIntFlag("Int", "intFlag", "owner", 1, feature.Temporary, false)
+	flags := []feature.Flag{boolFlag, intFlag}
+
+	now := time.Now()
+	svc := NewService(kvSvc, baseFlagger{"intFlag": 2}, time.Minute)
+	svc.now = func() time.Time { return now }
+	svc.byKey = func(k string) (feature.Flag, bool) {
+		for _, flag := range flags {
+			if flag.Key() == k {
+				return flag, true
+			}
+		}
+		return nil, false
+	}
+
+	orgCtx := icontext.SetAuthorizer(ctx, &influxdb.Authorization{OrgID: org.ID})
+	assertFlags := func(ctx context.Context, exp map[string]interface{}) {
+		t.Helper()
+		got, err := svc.Flags(ctx, flags...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range exp {
+			if got[k] != v {
+				t.Errorf("unexpected value of %s: got %v, exp %v", k, got[k], v)
+			}
+		}
+	}
+	assertFlags(orgCtx, map[string]interface{}{"boolFlag": false, "intFlag": 2})
+
+	if err := svc.SetFeatureFlagSetting(ctx, &influxdb.FeatureFlagSetting{Key: "unknown", Value: "true"}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected unknown flag to be invalid, got %v", err)
+	}
+	if err := svc.SetFeatureFlagSetting(ctx, &influxdb.FeatureFlagSetting{Key: "boolFlag", Value: "yes"}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected value which is not a bool to be invalid, got %v", err)
+	}
+
+	instance := &influxdb.FeatureFlagSetting{Key: "boolFlag", Value: "TRUE"}
+	if err := svc.SetFeatureFlagSetting(ctx, instance); err != nil {
+		t.Fatal(err)
+	}
+	if instance.Value != "true" {
+		t.Errorf("expected value to be stored in canonical form, got %q", instance.Value)
+	}
+	if err := svc.SetFeatureFlagSetting(ctx, &influxdb.FeatureFlagSetting{Key: "intFlag", OrgID: &org.ID, Value: "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes made through the service are applied immediately.
+	assertFlags(ctx, map[string]interface{}{"boolFlag": true, "intFlag": 2})
+	assertFlags(orgCtx, map[string]interface{}{"boolFlag": true, "intFlag": 3})
+
+	// Changes made elsewhere are applied once the settings are refreshed.
+	if err := kvSvc.DeleteFeatureFlagSetting(ctx, "boolFlag", nil); err != nil {
+		t.Fatal(err)
+	}
+	assertFlags(orgCtx, map[string]interface{}{"boolFlag": true})
+	now = now.Add(time.Minute)
+	assertFlags(orgCtx, map[string]interface{}{"boolFlag": false, "intFlag": 3})
+
+	if err := svc.DeleteFeatureFlagSetting(ctx, "boolFlag", nil); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected deleting a missing setting to be not found, got %v", err)
+	}
+}
+
+func TestService_Changes(t *testing.T) {
+	ctx := context.Background()
+	kvSvc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := kvSvc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := kvSvc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	userCtx := icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: 10, OrgID: org.ID})
+	for _, fs := range []*influxdb.FeatureFlagSetting{
+		{Key: "flag", Value: "1"},
+		{Key: "flag", Value: "1"},
+		{Key: "flag", Value: "2"},
+		{Key: "flag", OrgID: &org.ID, Value: "3"},
+	} {
+		if err := kvSvc.SetFeatureFlagSetting(userCtx, fs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := kvSvc.DeleteFeatureFlagSetting(ctx, "flag", nil); err != nil {
+		t.Fatal(err)
+	}
+	missing := influxdb.ID(1000)
+	if err := kvSvc.SetFeatureFlagSetting(ctx, &influxdb.FeatureFlagSetting{Key: "flag", OrgID: &missing, Value: "1"}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected setting of a missing organization to be not found, got %v", err)
+	}
+
+	str := func(s string) *string { return &s }
+	cs, err := kvSvc.FindFeatureFlagChanges(ctx, influxdb.FeatureFlagChangeFilter{Instance: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []struct {
+		old, new *string
+		userID   influxdb.ID
+	}{
+		{nil, str("1"), 10},
+		{str("1"), str("2"), 10},
+		{str("2"), nil, 0},
+	}
+	if len(cs) != len(exp) {
+		t.Fatalf("unexpected number of changes: got %d, exp %d", len(cs), len(exp))
+	}
+	for i, c := range cs {
+		if !equalStr(c.OldValue, exp[i].old) || !equalStr(c.NewValue, exp[i].new) || c.UserID != exp[i].userID || c.OrgID != nil {
+			t.Errorf("unexpected change %d: %+v", i, c)
+		}
+	}
+
+	cs, err = kvSvc.FindFeatureFlagChanges(ctx, influxdb.FeatureFlagChangeFilter{}, influxdb.FindOptions{Limit: 1, Descending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 1 || !equalStr(cs[0].OldValue, str("2")) {
+		t.Errorf("expected the latest change, got %+v", cs)
+	}
+
+	if err := kvSvc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	ss, err := kvSvc.FindFeatureFlagSettings(ctx, influxdb.FeatureFlagSettingFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 0 {
+		t.Errorf("expected the settings of the deleted organization to be removed, got %+v", ss)
+	}
+}
+
+func equalStr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
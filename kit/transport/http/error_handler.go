@@ -74,6 +74,7 @@ var influxDBErrorToStatusCode = map[string]int{
 	influxdb.EUnauthorized:        http.StatusUnauthorized,
 	influxdb.EMethodNotAllowed:    http.StatusMethodNotAllowed,
 	influxdb.ETooLarge:            http.StatusRequestEntityTooLarge,
+	influxdb.EPreconditionFailed:  http.StatusPreconditionFailed,
 }
 
 var httpStatusCodeToInfluxDBError = map[int]string{}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies are the networks of the reverse proxies and load balancers
+// trusted to report the clients of the requests they forward in the
+// X-Forwarded-For header.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a list of CIDRs, such as 10.0.0.0/8, or of
+// single IP addresses.
+func ParseTrustedProxies(ss []string) (TrustedProxies, error) {
+	var ps TrustedProxies
+	for _, s := range ss {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			ps = append(ps, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %v", s, err)
+		}
+		ps = append(ps, n)
+	}
+	return ps, nil
+}
+
+func (ps TrustedProxies) trusted(ip net.IP) bool {
+	for _, n := range ps {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP address of the client of r. The addresses of
+// X-Forwarded-For are taken from right to left while they were reported by
+// a trusted proxy, so the client is the first address that is not a trusted
+// proxy. A request which was not forwarded by a trusted proxy is from its
+// remote address, whatever its X-Forwarded-For.
+func (ps TrustedProxies) ClientIP(r *http.Request) string {
+	client := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(client); err == nil {
+		client = host
+	}
+	ip := net.ParseIP(client)
+	if ip == nil || !ps.trusted(ip) {
+		return client
+	}
+
+	var forwarded []string
+	for _, h := range r.Header["X-Forwarded-For"] {
+		forwarded = append(forwarded, strings.Split(h, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			// A malformed address cannot be a client, so the last trusted
+			// proxy is.
+			break
+		}
+		client = ip.String()
+		if !ps.trusted(ip) {
+			break
+		}
+	}
+	return client
+}
+
+// RealIP sets the remote address of requests to the address of their client,
+// as reported by the trusted proxies ps, so that the client and not the
+// proxy is logged and identified by the handlers.
+func RealIP(ps TrustedProxies) Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(r.Context())
+			r.RemoteAddr = ps.ClientIP(r)
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	ps, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", " ", "fd00::/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{
+			name:   "direct",
+			remote: "203.0.113.7:51234",
+			want:   "203.0.113.7",
+		},
+		{
+			name:      "untrusted remote forwarding",
+			remote:    "203.0.113.7:51234",
+			forwarded: []string{"198.51.100.1"},
+			want:      "203.0.113.7",
+		},
+		{
+			name:      "trusted proxy",
+			remote:    "10.1.2.3:443",
+			forwarded: []string{"198.51.100.1"},
+			want:      "198.51.100.1",
+		},
+		{
+			name:      "chain of trusted proxies",
+			remote:    "10.1.2.3:443",
+			forwarded: []string{"198.51.100.1, 192.168.1.1", "10.9.9.9"},
+			want:      "198.51.100.1",
+		},
+		{
+			name:      "spoofed address before the client",
+			remote:    "10.1.2.3:443",
+			forwarded: []string{"1.2.3.4, 198.51.100.1"},
+			want:      "198.51.100.1",
+		},
+		{
+			name:      "malformed address",
+			remote:    "10.1.2.3:443",
+			forwarded: []string{"unknown, 10.9.9.9"},
+			want:      "10.9.9.9",
+		},
+		{
+			name:   "trusted proxy without forwarding",
+			remote: "10.1.2.3:443",
+			want:   "10.1.2.3",
+		},
+		{
+			name:      "ipv6",
+			remote:    "[fd00::1]:443",
+			forwarded: []string{"2001:db8::1"},
+			want:      "2001:db8::1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, f := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", f)
+			}
+			if got := ps.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/33", "proxy.example.com"} {
+		if _, err := ParseTrustedProxies([]string{s}); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	ps, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	h := RealIP(ps)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.1.2.3:443"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got != "198.51.100.1" {
+		t.Errorf("got remote address %q, want 198.51.100.1", got)
+	}
+	if r.RemoteAddr != "10.1.2.3:443" {
+		t.Errorf("expected the request not to be modified, got %q", r.RemoteAddr)
+	}
+}
@@ -0,0 +1,239 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	alertTransitionBucket = []byte("alerttransitionsv1")
+	alertSeriesBucket     = []byte("alertseriesv1")
+)
+
+var _ influxdb.AlertHistoryService = (*Service)(nil)
+
+func (s *Service) initializeAlertHistory(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(alertTransitionBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(alertSeriesBucket)
+		return err
+	})
+}
+
+// RecordAlertStatuses records a transition for each status whose level
+// differs from the previous level of its series.
+func (s *Service) RecordAlertStatuses(ctx context.Context, statuses []influxdb.AlertStatus) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		for _, st := range statuses {
+			if err := s.recordAlertStatus(ctx, tx, st); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRecordAlertStatuses,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) recordAlertStatus(ctx context.Context, tx Tx, st influxdb.AlertStatus) error {
+	seriesKey := st.SeriesKey()
+	key, err := alertSeriesKey(st.OrgID, st.CheckID, seriesKey)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(alertSeriesBucket)
+	if err != nil {
+		return err
+	}
+
+	previous := "ok"
+	v, err := b.Get(key)
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		previous = string(v)
+	}
+	if previous == st.Level {
+		return nil
+	}
+
+	t := &influxdb.AlertTransition{
+		ID:            s.IDGenerator.ID(),
+		OrgID:         st.OrgID,
+		CheckID:       st.CheckID,
+		CheckName:     st.CheckName,
+		SeriesKey:     seriesKey,
+		Tags:          st.Tags,
+		PreviousLevel: previous,
+		Level:         st.Level,
+		Message:       st.Message,
+		Time:          st.Time,
+	}
+	if err := s.putAlertTransition(ctx, tx, t); err != nil {
+		return err
+	}
+	return b.Put(key, []byte(st.Level))
+}
+
+// alertSeriesKey returns the key of the last level of a series of a check.
+func alertSeriesKey(orgID, checkID influxdb.ID, seriesKey string) ([]byte, error) {
+	encodedOrgID, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	encodedCheckID, err := checkID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	key := make([]byte, 0, len(encodedOrgID)+len(encodedCheckID)+len(seriesKey))
+	key = append(key, encodedOrgID...)
+	key = append(key, encodedCheckID...)
+	return append(key, seriesKey...), nil
+}
+
+// alertTransitionKey returns the key of a transition. Transitions are stored
+// in the order of their times.
+func alertTransitionKey(t time.Time, id influxdb.ID) ([]byte, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	key := make([]byte, 8, 8+len(encodedID))
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return append(key, encodedID...), nil
+}
+
+func (s *Service) putAlertTransition(ctx context.Context, tx Tx, t *influxdb.AlertTransition) error {
+	key, err := alertTransitionKey(t.Time, t.ID)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(t)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(alertTransitionBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, v)
+}
+
+// FindAlertTransitions returns the transitions that match filter, in the
+// order of their times.
+func (s *Service) FindAlertTransitions(ctx context.Context, filter influxdb.AlertTransitionFilter) ([]*influxdb.AlertTransition, error) {
+	ts := []*influxdb.AlertTransition{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachAlertTransition(ctx, tx, filter.Start, func(k []byte, t *influxdb.AlertTransition) bool {
+			if !filter.Stop.IsZero() && !t.Time.Before(filter.Stop) {
+				return false
+			}
+			if filter.Match(t) {
+				ts = append(ts, t)
+			}
+			return filter.Limit <= 0 || len(ts) < filter.Limit
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindAlertTransitions,
+			Err: err,
+		}
+	}
+	return ts, nil
+}
+
+// DeleteAlertTransitionsBefore removes the transitions older than t.
+func (s *Service) DeleteAlertTransitionsBefore(ctx context.Context, t time.Time) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var keys [][]byte
+		err := s.forEachAlertTransition(ctx, tx, time.Time{}, func(k []byte, at *influxdb.AlertTransition) bool {
+			if !at.Time.Before(t) {
+				return false
+			}
+			keys = append(keys, k)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(alertTransitionBucket)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteAlertTransitionsBefore,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// forEachAlertTransition calls fn with the transitions from start in the
+// order of their times, until fn returns false.
+func (s *Service) forEachAlertTransition(ctx context.Context, tx Tx, start time.Time, fn func(k []byte, t *influxdb.AlertTransition) bool) error {
+	b, err := tx.Bucket(alertTransitionBucket)
+	if err != nil {
+		return err
+	}
+
+	var seek []byte
+	if !start.IsZero() {
+		seek = make([]byte, 8)
+		binary.BigEndian.PutUint64(seek, uint64(start.UnixNano()))
+	}
+	cur, err := b.ForwardCursor(seek)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		var t influxdb.AlertTransition
+		if err := json.Unmarshal(v, &t); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		if !fn(append([]byte(nil), k...), &t) {
+			break
+		}
+	}
+	return cur.Err()
+}
@@ -0,0 +1,95 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_AlertHistory(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing alert history service: %v", err)
+	}
+
+	var (
+		orgID   = influxdb.ID(1)
+		checkID = influxdb.ID(2)
+		t0      = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	status := func(host, level string, d time.Duration) influxdb.AlertStatus {
+		return influxdb.AlertStatus{
+			OrgID:     orgID,
+			CheckID:   checkID,
+			CheckName: "cpu",
+			Tags:      map[string]string{"host": host},
+			Level:     level,
+			Time:      t0.Add(d),
+		}
+	}
+
+	statuses := []influxdb.AlertStatus{
+		// Series start as ok, so an ok status is not a transition.
+		status("a", "ok", 0),
+		status("a", "crit", time.Minute),
+		status("b", "warn", time.Minute),
+		status("a", "crit", 2*time.Minute),
+		status("a", "ok", 3*time.Minute),
+	}
+	if err := svc.RecordAlertStatuses(ctx, statuses); err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := svc.FindAlertTransitions(ctx, influxdb.AlertTransitionFilter{CheckID: &checkID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		series, from, to string
+	}{
+		{"host=a", "ok", "crit"},
+		{"host=b", "ok", "warn"},
+		{"host=a", "crit", "ok"},
+	}
+	if len(ts) != len(want) {
+		t.Fatalf("expected %d transitions, got %d", len(want), len(ts))
+	}
+	for i, w := range want {
+		if ts[i].SeriesKey != w.series || ts[i].PreviousLevel != w.from || ts[i].Level != w.to || ts[i].OrgID != orgID {
+			t.Fatalf("unexpected transition %d: %+v", i, ts[i])
+		}
+	}
+
+	ts, err = svc.FindAlertTransitions(ctx, influxdb.AlertTransitionFilter{
+		Start: t0.Add(2 * time.Minute),
+		Stop:  t0.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].Level != "ok" {
+		t.Fatalf("expected the transitions from the start, got %+v", ts)
+	}
+
+	if err := svc.DeleteAlertTransitionsBefore(ctx, t0.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	ts, err = svc.FindAlertTransitions(ctx, influxdb.AlertTransitionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 {
+		t.Fatalf("expected the older transitions to be removed, got %d", len(ts))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/buger/jsonparser"
 	influxdb "github.com/influxdata/influxdb/v2"
@@ -16,6 +17,7 @@ var (
 )
 
 var _ influxdb.AuthorizationService = (*Service)(nil)
+var _ influxdb.AuthorizationUsageService = (*Service)(nil)
 
 func (s *Service) initializeAuths(ctx context.Context, tx Tx) error {
 	if _, err := tx.Bucket(authBucket); err != nil {
@@ -85,6 +87,7 @@ func (s *Service) findAuthorizationByID(ctx context.Context, tx Tx, id influxdb.
 }
 
 // FindAuthorizationByToken returns a authorization by token for a particular authorization.
+// The permissions of the roles of the authorization are included in its permissions.
 func (s *Service) FindAuthorizationByToken(ctx context.Context, n string) (*influxdb.Authorization, error) {
 	var a *influxdb.Authorization
 	err := s.kv.View(ctx, func(tx Tx) error {
@@ -93,6 +96,10 @@ func (s *Service) FindAuthorizationByToken(ctx context.Context, n string) (*infl
 			return err
 		}
 
+		if err := s.resolveAuthorizationRoles(ctx, tx, auth); err != nil {
+			return err
+		}
+
 		a = auth
 
 		return nil
@@ -284,7 +291,7 @@ func (s *Service) findAuthorizations(ctx context.Context, tx Tx, f influxdb.Auth
 	pred := authorizationsPredicateFn(f)
 	filterFn := filterAuthorizationsFn(f)
 	err := s.forEachAuthorization(ctx, tx, pred, func(a *influxdb.Authorization) bool {
-		if filterFn(a) {
+		if filterFn(a) && (f.UnusedSince == nil || a.UnusedSince(*f.UnusedSince)) {
 			as = append(as, a)
 		}
 		return true
@@ -323,6 +330,10 @@ func (s *Service) createAuthorization(ctx context.Context, tx Tx, a *influxdb.Au
 		return influxdb.ErrUnableToCreateToken
 	}
 
+	if err := s.validateAuthorizationRoles(ctx, tx, a); err != nil {
+		return err
+	}
+
 	if err := s.uniqueAuthToken(ctx, tx, a); err != nil {
 		return err
 	}
@@ -540,6 +551,26 @@ func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	return a, nil
 }
 
+// RecordAuthorizationUsage records that the authorization was used at the
+// time from the address.
+func (s *Service) RecordAuthorizationUsage(ctx context.Context, id influxdb.ID, at time.Time, from string) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		a, err := s.findAuthorizationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		// Usage may be recorded out of order by concurrent requests.
+		if a.LastUsedAt != nil && a.LastUsedAt.After(at) {
+			return nil
+		}
+		a.LastUsedAt = &at
+		a.LastUsedFrom = from
+
+		return s.putAuthorization(ctx, tx, a)
+	})
+}
+
 func authIndexBucket(tx Tx) (Bucket, error) {
 	b, err := tx.Bucket([]byte(authIndex))
 	if err != nil {
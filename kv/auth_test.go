@@ -3,6 +3,7 @@ package kv_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kv"
@@ -76,3 +77,53 @@ func initAuthorizationService(s kv.Store, f influxdbtesting.AuthorizationFields,
 		}
 	}
 }
+
+func TestService_RecordAuthorizationUsage(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing authorization service: %v", err)
+	}
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, a := range []*influxdb.Authorization{
+		{ID: 1, Token: "used", OrgID: 10, UserID: 20, Status: influxdb.Active, CRUDLog: influxdb.CRUDLog{CreatedAt: created}},
+		{ID: 2, Token: "unused", OrgID: 10, UserID: 20, Status: influxdb.Active, CRUDLog: influxdb.CRUDLog{CreatedAt: created}},
+	} {
+		if err := svc.PutAuthorization(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	used := created.Add(60 * 24 * time.Hour)
+	if err := svc.RecordAuthorizationUsage(ctx, 1, used, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	// Usage recorded out of order must not move the last use back.
+	if err := svc.RecordAuthorizationUsage(ctx, 1, used.Add(-time.Hour), "10.0.0.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := svc.FindAuthorizationByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.LastUsedAt == nil || !a.LastUsedAt.Equal(used) || a.LastUsedFrom != "10.0.0.1" {
+		t.Fatalf("unexpected last use: %v from %q", a.LastUsedAt, a.LastUsedFrom)
+	}
+
+	since := created.Add(30 * 24 * time.Hour)
+	as, _, err := svc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{UnusedSince: &since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 1 || as[0].ID != 2 {
+		t.Fatalf("expected only the unused authorization, got %v", as)
+	}
+}
@@ -477,6 +477,42 @@ func (s *Service) createBucket(ctx context.Context, tx Tx, b *influxdb.Bucket) (
 		return err
 	}
 
+	if err := b.DuplicatePolicy.Valid(); err != nil {
+		return err
+	}
+
+	if err := b.WriteWindow.Valid(); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidDedupeWindow(b.DedupeWindow); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidJSONWriteRules(b.JSONWriteRules); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidAggregationHints(b.AggregationHints); err != nil {
+		return err
+	}
+
+	if err := b.CompressionCodec.Valid(); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidSoftTTLs(b.SoftTTLs); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidMaskingRules(b.MaskingRules); err != nil {
+		return err
+	}
+
+	if err := b.WriteConsistency.Valid(); err != nil {
+		return err
+	}
+
 	if b.ID, err = s.generateBucketID(ctx, tx); err != nil {
 		return err
 	}
@@ -687,6 +723,77 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.DuplicatePolicy != nil {
+		if err := upd.DuplicatePolicy.Valid(); err != nil {
+			return nil, err
+		}
+		b.DuplicatePolicy = *upd.DuplicatePolicy
+	}
+
+	if upd.WriteWindow != nil {
+		if err := upd.WriteWindow.Valid(); err != nil {
+			return nil, err
+		}
+		// An empty write window removes the bounds from the bucket.
+		b.WriteWindow = upd.WriteWindow
+		if *upd.WriteWindow == (influxdb.WriteWindow{}) {
+			b.WriteWindow = nil
+		}
+	}
+
+	if upd.DedupeWindow != nil {
+		if err := influxdb.ValidDedupeWindow(*upd.DedupeWindow); err != nil {
+			return nil, err
+		}
+		b.DedupeWindow = *upd.DedupeWindow
+	}
+
+	if upd.JSONWriteRules != nil {
+		if err := influxdb.ValidJSONWriteRules(*upd.JSONWriteRules); err != nil {
+			return nil, err
+		}
+		b.JSONWriteRules = *upd.JSONWriteRules
+	}
+
+	if upd.AggregationHints != nil {
+		if err := influxdb.ValidAggregationHints(*upd.AggregationHints); err != nil {
+			return nil, err
+		}
+		b.AggregationHints = *upd.AggregationHints
+	}
+
+	if upd.CompressionCodec != nil {
+		if err := upd.CompressionCodec.Valid(); err != nil {
+			return nil, err
+		}
+		b.CompressionCodec = *upd.CompressionCodec
+	}
+
+	if upd.SoftTTLs != nil {
+		if err := influxdb.ValidSoftTTLs(*upd.SoftTTLs); err != nil {
+			return nil, err
+		}
+		b.SoftTTLs = *upd.SoftTTLs
+	}
+
+	if upd.LastValueCache != nil {
+		b.LastValueCache = *upd.LastValueCache
+	}
+
+	if upd.MaskingRules != nil {
+		if err := influxdb.ValidMaskingRules(*upd.MaskingRules); err != nil {
+			return nil, err
+		}
+		b.MaskingRules = *upd.MaskingRules
+	}
+
+	if upd.WriteConsistency != nil {
+		if err := upd.WriteConsistency.Valid(); err != nil {
+			return nil, err
+		}
+		b.WriteConsistency = *upd.WriteConsistency
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
@@ -749,7 +856,8 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 
 // DeleteBucket deletes a bucket and prunes it from the index.
 func (s *Service) DeleteBucket(ctx context.Context, id influxdb.ID) error {
-	return s.kv.Update(ctx, func(tx Tx) error {
+	var committed []func()
+	err := s.kv.Update(ctx, func(tx Tx) error {
 		bucket, err := s.findBucketByID(ctx, tx, id)
 		if err != nil && !IsNotFound(err) {
 			return err
@@ -762,7 +870,7 @@ func (s *Service) DeleteBucket(ctx context.Context, id influxdb.ID) error {
 			}
 		}
 
-		if err := s.deleteBucket(ctx, tx, id); err != nil {
+		if committed, err = s.deleteBucket(ctx, tx, id); err != nil {
 			return err
 		}
 
@@ -776,33 +884,43 @@ func (s *Service) DeleteBucket(ctx context.Context, id influxdb.ID) error {
 			Time:           time.Now(),
 		})
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range committed {
+		fn()
+	}
+	return nil
 }
 
-func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) error {
+// deleteBucket deletes the bucket with the id in tx, and returns the
+// functions of the bucket delete hooks to call once tx commits.
+func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) ([]func(), error) {
 	b, pe := s.findBucketByID(ctx, tx, id)
 	if pe != nil {
-		return pe
+		return nil, pe
 	}
 
 	key, pe := bucketIndexKey(b)
 	if pe != nil {
-		return pe
+		return nil, pe
 	}
 
 	idx, err := s.bucketsIndexBucket(tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := idx.Delete(key); err != nil {
-		return &influxdb.Error{
+		return nil, &influxdb.Error{
 			Err: err,
 		}
 	}
 
 	encodedID, err := id.Encode()
 	if err != nil {
-		return &influxdb.Error{
+		return nil, &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Err:  err,
 		}
@@ -810,11 +928,11 @@ func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) error
 
 	bkt, err := s.bucketsBucket(tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := bkt.Delete(encodedID); err != nil {
-		return &influxdb.Error{
+		return nil, &influxdb.Error{
 			Err: err,
 		}
 	}
@@ -823,10 +941,34 @@ func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) error
 		ResourceID:   id,
 		ResourceType: influxdb.BucketsResourceType,
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	var committed []func()
+	for _, hook := range s.bucketDeleteHooks {
+		fn, err := hook(ctx, tx, b)
+		if err != nil {
+			return nil, err
+		}
+		if fn != nil {
+			committed = append(committed, fn)
+		}
+	}
+
+	return committed, nil
+}
+
+// BucketDeleteHook is called in the transaction deleting a bucket, to delete
+// the resources of the store referring to the bucket along with it. The
+// function it returns, if not nil, is called once the transaction commits,
+// for effects such as notifications which must not be seen if the delete is
+// rolled back.
+type BucketDeleteHook func(ctx context.Context, tx Tx, b *influxdb.Bucket) (committed func(), err error)
+
+// AddBucketDeleteHook adds a hook called in the transaction deleting a
+// bucket. Hooks must be added before the service is used.
+func (s *Service) AddBucketDeleteHook(hook BucketDeleteHook) {
+	s.bucketDeleteHooks = append(s.bucketDeleteHooks, hook)
 }
 
 const bucketOperationLogKeyPrefix = "bucket"
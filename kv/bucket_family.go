@@ -0,0 +1,285 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	bucketFamilyBucket = []byte("bucketfamiliesv1")
+)
+
+var _ influxdb.BucketFamilyService = (*Service)(nil)
+
+func (s *Service) initializeBucketFamilies(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(bucketFamilyBucket)
+		return err
+	})
+}
+
+// FindBucketFamilyByID returns a single bucket family by ID.
+func (s *Service) FindBucketFamilyByID(ctx context.Context, id influxdb.ID) (*influxdb.BucketFamily, error) {
+	var f *influxdb.BucketFamily
+	err := s.kv.View(ctx, func(tx Tx) error {
+		family, err := s.findBucketFamilyByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		f = family
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindBucketFamilyByID,
+			Err: err,
+		}
+	}
+	return f, nil
+}
+
+func (s *Service) findBucketFamilyByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.BucketFamily, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(bucketFamilyBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrBucketFamilyNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f influxdb.BucketFamily
+	if err := json.Unmarshal(v, &f); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &f, nil
+}
+
+// FindBucketFamily returns the first bucket family that matches filter.
+func (s *Service) FindBucketFamily(ctx context.Context, filter influxdb.BucketFamilyFilter) (*influxdb.BucketFamily, error) {
+	if filter.ID != nil {
+		return s.FindBucketFamilyByID(ctx, *filter.ID)
+	}
+
+	var f *influxdb.BucketFamily
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachBucketFamily(ctx, tx, func(family *influxdb.BucketFamily) bool {
+			if filter.Match(family) {
+				f = family
+				return false
+			}
+			return true
+		})
+	})
+	if err == nil && f == nil {
+		err = &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrBucketFamilyNotFound,
+		}
+	}
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindBucketFamily,
+			Err: err,
+		}
+	}
+	return f, nil
+}
+
+// FindBucketFamilies returns a list of bucket families that match filter.
+func (s *Service) FindBucketFamilies(ctx context.Context, filter influxdb.BucketFamilyFilter) ([]*influxdb.BucketFamily, error) {
+	fs := []*influxdb.BucketFamily{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachBucketFamily(ctx, tx, func(f *influxdb.BucketFamily) bool {
+			if filter.Match(f) {
+				fs = append(fs, f)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindBucketFamilies,
+			Err: err,
+		}
+	}
+	return fs, nil
+}
+
+// forEachBucketFamily will iterate through all bucket families while fn returns true.
+func (s *Service) forEachBucketFamily(ctx context.Context, tx Tx, fn func(*influxdb.BucketFamily) bool) error {
+	b, err := tx.Bucket(bucketFamilyBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		f := &influxdb.BucketFamily{}
+		if err := json.Unmarshal(v, f); err != nil {
+			return err
+		}
+		if !fn(f) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// CreateBucketFamily creates a new bucket family and sets f.ID with the new
+// identifier.
+func (s *Service) CreateBucketFamily(ctx context.Context, f *influxdb.BucketFamily) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.createBucketFamily(ctx, tx, f)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateBucketFamily,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) createBucketFamily(ctx context.Context, tx Tx, f *influxdb.BucketFamily) error {
+	if err := f.Valid(); err != nil {
+		return err
+	}
+	if _, err := s.findOrganizationByID(ctx, tx, f.OrgID); err != nil {
+		return err
+	}
+
+	// The name of a family is looked up like the name of a bucket, so it
+	// must not be the name of a bucket or of another family.
+	if _, err := s.findBucketByName(ctx, tx, f.OrgID, f.Name); err == nil {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "a bucket with the name of the family already exists",
+		}
+	} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+
+	var exists bool
+	err := s.forEachBucketFamily(ctx, tx, func(e *influxdb.BucketFamily) bool {
+		exists = e.OrgID == f.OrgID && e.Name == f.Name
+		return !exists
+	})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "bucket family with name " + f.Name + " already exists",
+		}
+	}
+
+	f.ID = s.IDGenerator.ID()
+	f.SetCreatedAt(s.Now())
+	f.SetUpdatedAt(s.Now())
+	return s.putBucketFamily(ctx, tx, f)
+}
+
+// UpdateBucketFamily updates a single bucket family with changeset.
+func (s *Service) UpdateBucketFamily(ctx context.Context, id influxdb.ID, upd influxdb.BucketFamilyUpdate) (*influxdb.BucketFamily, error) {
+	var f *influxdb.BucketFamily
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		family, err := s.findBucketFamilyByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		upd.Apply(family)
+		if err := family.Valid(); err != nil {
+			return err
+		}
+		family.SetUpdatedAt(s.Now())
+		f = family
+		return s.putBucketFamily(ctx, tx, family)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateBucketFamily,
+			Err: err,
+		}
+	}
+	return f, nil
+}
+
+func (s *Service) putBucketFamily(ctx context.Context, tx Tx, f *influxdb.BucketFamily) error {
+	v, err := json.Marshal(f)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := f.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(bucketFamilyBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// DeleteBucketFamily removes a bucket family by ID. Its member buckets are
+// kept.
+func (s *Service) DeleteBucketFamily(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findBucketFamilyByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(bucketFamilyBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteBucketFamily,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_BucketFamilies(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing bucket family service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateBucket(ctx, &influxdb.Bucket{OrgID: org.ID, Name: "bucket"}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &influxdb.BucketFamily{OrgID: org.ID, Name: "metrics", Period: influxdb.BucketFamilyPeriodMonth}
+	if err := svc.CreateBucketFamily(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if !f.ID.Valid() || f.CreatedAt.IsZero() {
+		t.Fatalf("expected a new family to be given an ID and a creation time, got %+v", f)
+	}
+
+	for _, name := range []string{"metrics", "bucket"} {
+		dup := &influxdb.BucketFamily{OrgID: org.ID, Name: name, Period: influxdb.BucketFamilyPeriodWeek}
+		if err := svc.CreateBucketFamily(ctx, dup); influxdb.ErrorCode(err) != influxdb.EConflict {
+			t.Errorf("expected creating a family named %q to conflict, got %v", name, err)
+		}
+	}
+
+	name := "metrics"
+	got, err := svc.FindBucketFamily(ctx, influxdb.BucketFamilyFilter{OrgID: &org.ID, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != f.ID {
+		t.Errorf("expected to find family %s, got %s", f.ID, got.ID)
+	}
+
+	max := 3
+	if _, err := svc.UpdateBucketFamily(ctx, f.ID, influxdb.BucketFamilyUpdate{MaxMembers: &max}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := svc.FindBucketFamilyByID(ctx, f.ID); err != nil || got.MaxMembers != 3 {
+		t.Errorf("expected the family to keep 3 members, got %+v, %v", got, err)
+	}
+
+	if err := svc.DeleteBucketFamily(ctx, f.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindBucketFamily(ctx, influxdb.BucketFamilyFilter{OrgID: &org.ID, Name: &name}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected a deleted family to be not found, got %v", err)
+	}
+}
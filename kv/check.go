@@ -287,6 +287,10 @@ func (s *Service) createCheck(ctx context.Context, tx Tx, c influxdb.CheckCreate
 		return err
 	}
 
+	if err := s.recordCheckRevision(ctx, tx, c.Check); err != nil {
+		return err
+	}
+
 	return s.createUserResourceMappingForOrg(ctx, tx, c.GetOrgID(), c.GetID(), influxdb.ChecksResourceType)
 }
 
@@ -430,6 +434,10 @@ func (s *Service) updateCheck(ctx context.Context, tx Tx, id influxdb.ID, chk in
 		return nil, err
 	}
 
+	if err := s.recordCheckRevision(ctx, tx, chk.Check); err != nil {
+		return nil, err
+	}
+
 	return chk.Check, nil
 }
 
@@ -467,6 +475,10 @@ func (s *Service) patchCheck(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		return nil, err
 	}
 
+	if err := s.recordCheckRevision(ctx, tx, c); err != nil {
+		return nil, err
+	}
+
 	if _, err := s.updateTask(ctx, tx, c.GetTaskID(), tu); err != nil {
 		return nil, err
 	}
@@ -493,6 +505,10 @@ func (s *Service) DeleteCheck(ctx context.Context, id influxdb.ID) error {
 			return err
 		}
 
+		if err := s.deleteScriptRevisions(ctx, tx, id); err != nil {
+			return err
+		}
+
 		return s.deleteUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
 			ResourceID:   id,
 			ResourceType: influxdb.ChecksResourceType,
@@ -500,6 +516,18 @@ func (s *Service) DeleteCheck(ctx context.Context, id influxdb.ID) error {
 	})
 }
 
+// recordCheckRevision records the definition of a check in its revisions.
+func (s *Service) recordCheckRevision(ctx context.Context, tx Tx, c influxdb.Check) error {
+	script, err := definitionScript(c)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return s.recordScriptRevision(ctx, tx, influxdb.ChecksResourceType, c.GetID(), c.GetOrgID(), script)
+}
+
 func strPtr(s string) *string {
 	ss := new(string)
 	*ss = s
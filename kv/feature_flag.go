@@ -0,0 +1,334 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+var (
+	featureFlagSettingBucket = []byte("featureflagsettingsv1")
+	featureFlagChangeBucket  = []byte("featureflagchangesv1")
+)
+
+var _ influxdb.FeatureFlagService = (*Service)(nil)
+
+func (s *Service) initializeFeatureFlags(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(featureFlagSettingBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(featureFlagChangeBucket)
+		return err
+	})
+}
+
+// featureFlagSettingKey returns the key of the setting of a flag, which is
+// the encoded organization ID, or zeros for the instance, followed by the
+// flag key.
+func featureFlagSettingKey(key string, orgID *influxdb.ID) ([]byte, error) {
+	k := make([]byte, influxdb.IDLength, influxdb.IDLength+len(key))
+	if orgID != nil {
+		encodedID, err := orgID.Encode()
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+		copy(k, encodedID)
+	} else {
+		for i := range k {
+			k[i] = '0'
+		}
+	}
+	return append(k, key...), nil
+}
+
+// FindFeatureFlagSettings returns the settings that match filter.
+func (s *Service) FindFeatureFlagSettings(ctx context.Context, filter influxdb.FeatureFlagSettingFilter) ([]*influxdb.FeatureFlagSetting, error) {
+	ss := []*influxdb.FeatureFlagSetting{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(featureFlagSettingBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			fs := &influxdb.FeatureFlagSetting{}
+			if err := json.Unmarshal(v, fs); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+			if filter.Match(fs) {
+				ss = append(ss, fs)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindFeatureFlagSettings,
+			Err: err,
+		}
+	}
+	return ss, nil
+}
+
+// SetFeatureFlagSetting sets the value of a flag for the instance, or for an
+// organization if fs.OrgID is set, and records the change.
+func (s *Service) SetFeatureFlagSetting(ctx context.Context, fs *influxdb.FeatureFlagSetting) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := fs.Valid(); err != nil {
+			return err
+		}
+		if fs.OrgID != nil {
+			if _, err := s.findOrganizationByID(ctx, tx, *fs.OrgID); err != nil {
+				return err
+			}
+		}
+
+		old, err := s.findFeatureFlagSetting(ctx, tx, fs.Key, fs.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+
+		fs.UpdatedAt = s.Now()
+		if err := s.putFeatureFlagSetting(ctx, tx, fs); err != nil {
+			return err
+		}
+
+		var oldValue *string
+		if old != nil {
+			if old.Value == fs.Value {
+				return nil
+			}
+			oldValue = &old.Value
+		}
+		value := fs.Value
+		return s.recordFeatureFlagChange(ctx, tx, fs.Key, fs.OrgID, oldValue, &value)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpSetFeatureFlagSetting,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteFeatureFlagSetting removes the value of a flag set for the instance,
+// or for the organization orgID if it is not nil, and records the change.
+func (s *Service) DeleteFeatureFlagSetting(ctx context.Context, key string, orgID *influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		old, err := s.findFeatureFlagSetting(ctx, tx, key, orgID)
+		if err != nil {
+			return err
+		}
+
+		k, err := featureFlagSettingKey(key, orgID)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(featureFlagSettingBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+		return s.recordFeatureFlagChange(ctx, tx, key, orgID, &old.Value, nil)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteFeatureFlagSetting,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// deleteOrganizationFeatureFlagSettings removes the settings of an
+// organization. Their removal is not recorded as a change.
+func (s *Service) deleteOrganizationFeatureFlagSettings(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	prefix, err := featureFlagSettingKey("", &orgID)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(featureFlagSettingBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return err
+	}
+	var keys [][]byte
+	for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+		keys = append(keys, k)
+	}
+	if err := cur.Err(); err != nil {
+		cur.Close()
+		return err
+	}
+	cur.Close()
+
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) findFeatureFlagSetting(ctx context.Context, tx Tx, key string, orgID *influxdb.ID) (*influxdb.FeatureFlagSetting, error) {
+	k, err := featureFlagSettingKey(key, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := tx.Bucket(featureFlagSettingBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(k)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrFeatureFlagSettingNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fs influxdb.FeatureFlagSetting
+	if err := json.Unmarshal(v, &fs); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &fs, nil
+}
+
+func (s *Service) putFeatureFlagSetting(ctx context.Context, tx Tx, fs *influxdb.FeatureFlagSetting) error {
+	k, err := featureFlagSettingKey(fs.Key, fs.OrgID)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(fs)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(featureFlagSettingBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(k, v)
+}
+
+func (s *Service) recordFeatureFlagChange(ctx context.Context, tx Tx, key string, orgID *influxdb.ID, oldValue, newValue *string) error {
+	uid, _ := icontext.GetUserID(ctx)
+	c := &influxdb.FeatureFlagChange{
+		ID:       s.IDGenerator.ID(),
+		Key:      key,
+		OrgID:    orgID,
+		OldValue: oldValue,
+		NewValue: newValue,
+		UserID:   uid,
+		Time:     s.Now(),
+	}
+
+	encodedID, err := c.ID.Encode()
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(c)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(featureFlagChangeBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// FindFeatureFlagChanges returns the changes made to the settings that match
+// filter, from the oldest to the latest unless opts are descending.
+func (s *Service) FindFeatureFlagChanges(ctx context.Context, filter influxdb.FeatureFlagChangeFilter, opts ...influxdb.FindOptions) ([]*influxdb.FeatureFlagChange, error) {
+	var opt influxdb.FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cs := []*influxdb.FeatureFlagChange{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(featureFlagChangeBucket)
+		if err != nil {
+			return err
+		}
+
+		direction := CursorAscending
+		if opt.Descending {
+			direction = CursorDescending
+		}
+		cur, err := b.ForwardCursor(nil, WithCursorDirection(direction))
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		offset := opt.Offset
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			c := &influxdb.FeatureFlagChange{}
+			if err := json.Unmarshal(v, c); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+			if !filter.Match(c) {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			cs = append(cs, c)
+			if opt.Limit > 0 && len(cs) >= opt.Limit {
+				break
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindFeatureFlagChanges,
+			Err: err,
+		}
+	}
+	return cs, nil
+}
@@ -0,0 +1,452 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	fluxFragmentBucket        = []byte("fluxfragmentsv1")
+	fluxFragmentVersionBucket = []byte("fluxfragmentversionsv1")
+)
+
+var _ influxdb.FluxFragmentService = (*Service)(nil)
+
+func (s *Service) initializeFluxFragments(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(fluxFragmentBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(fluxFragmentVersionBucket)
+		return err
+	})
+}
+
+// FindFluxFragmentByID returns a single fragment by ID.
+func (s *Service) FindFluxFragmentByID(ctx context.Context, id influxdb.ID) (*influxdb.FluxFragment, error) {
+	var f *influxdb.FluxFragment
+	err := s.kv.View(ctx, func(tx Tx) error {
+		fragment, err := s.findFluxFragmentByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		f = fragment
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindFluxFragmentByID,
+			Err: err,
+		}
+	}
+	return f, nil
+}
+
+func (s *Service) findFluxFragmentByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.FluxFragment, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(fluxFragmentBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrFluxFragmentNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f influxdb.FluxFragment
+	if err := json.Unmarshal(v, &f); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &f, nil
+}
+
+// FindFluxFragment returns the first fragment that matches filter.
+func (s *Service) FindFluxFragment(ctx context.Context, filter influxdb.FluxFragmentFilter) (*influxdb.FluxFragment, error) {
+	if filter.ID != nil {
+		return s.FindFluxFragmentByID(ctx, *filter.ID)
+	}
+
+	var f *influxdb.FluxFragment
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachFluxFragment(ctx, tx, func(fragment *influxdb.FluxFragment) bool {
+			if filter.Match(fragment) {
+				f = fragment
+				return false
+			}
+			return true
+		})
+	})
+	if err == nil && f == nil {
+		err = &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrFluxFragmentNotFound,
+		}
+	}
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindFluxFragment,
+			Err: err,
+		}
+	}
+	return f, nil
+}
+
+// FindFluxFragments returns a list of fragments that match filter.
+func (s *Service) FindFluxFragments(ctx context.Context, filter influxdb.FluxFragmentFilter) ([]*influxdb.FluxFragment, error) {
+	fs := []*influxdb.FluxFragment{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachFluxFragment(ctx, tx, func(f *influxdb.FluxFragment) bool {
+			if filter.Match(f) {
+				fs = append(fs, f)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindFluxFragments,
+			Err: err,
+		}
+	}
+	return fs, nil
+}
+
+// forEachFluxFragment will iterate through all fragments while fn returns true.
+func (s *Service) forEachFluxFragment(ctx context.Context, tx Tx, fn func(*influxdb.FluxFragment) bool) error {
+	b, err := tx.Bucket(fluxFragmentBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		f := &influxdb.FluxFragment{}
+		if err := json.Unmarshal(v, f); err != nil {
+			return err
+		}
+		if !fn(f) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// CreateFluxFragment creates a new fragment at version 1 and sets f.ID with
+// the new identifier.
+func (s *Service) CreateFluxFragment(ctx context.Context, f *influxdb.FluxFragment) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.createFluxFragment(ctx, tx, f)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateFluxFragment,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) createFluxFragment(ctx context.Context, tx Tx, f *influxdb.FluxFragment) error {
+	if err := f.Valid(); err != nil {
+		return err
+	}
+	if _, err := s.findOrganizationByID(ctx, tx, f.OrgID); err != nil {
+		return err
+	}
+
+	var exists bool
+	err := s.forEachFluxFragment(ctx, tx, func(e *influxdb.FluxFragment) bool {
+		exists = e.OrgID == f.OrgID && e.Name == f.Name
+		return !exists
+	})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "flux fragment with name " + f.Name + " already exists",
+		}
+	}
+
+	f.ID = s.IDGenerator.ID()
+	f.Version = 1
+	f.SetCreatedAt(s.Now())
+	f.SetUpdatedAt(s.Now())
+	if err := s.putFluxFragment(ctx, tx, f); err != nil {
+		return err
+	}
+	return s.putFluxFragmentVersion(ctx, tx, f)
+}
+
+// UpdateFluxFragment updates a single fragment with changeset. A change of
+// its Flux adds a new version of the fragment.
+func (s *Service) UpdateFluxFragment(ctx context.Context, id influxdb.ID, upd influxdb.FluxFragmentUpdate) (*influxdb.FluxFragment, error) {
+	var f *influxdb.FluxFragment
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		fragment, err := s.findFluxFragmentByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		flux := fragment.Flux
+		upd.Apply(fragment)
+		if err := fragment.Valid(); err != nil {
+			return err
+		}
+		fragment.SetUpdatedAt(s.Now())
+		if fragment.Flux != flux {
+			fragment.Version++
+			if err := s.putFluxFragmentVersion(ctx, tx, fragment); err != nil {
+				return err
+			}
+		}
+		f = fragment
+		return s.putFluxFragment(ctx, tx, fragment)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateFluxFragment,
+			Err: err,
+		}
+	}
+	return f, nil
+}
+
+func (s *Service) putFluxFragment(ctx context.Context, tx Tx, f *influxdb.FluxFragment) error {
+	v, err := json.Marshal(f)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := f.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(fluxFragmentBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// DeleteFluxFragment removes a fragment and all its versions by ID.
+func (s *Service) DeleteFluxFragment(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findFluxFragmentByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+
+		versions, err := s.findFluxFragmentVersions(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		vb, err := tx.Bucket(fluxFragmentVersionBucket)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			key, err := fluxFragmentVersionKey(id, v.Version)
+			if err != nil {
+				return err
+			}
+			if err := vb.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		b, err := tx.Bucket(fluxFragmentBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteFluxFragment,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// fluxFragmentVersionKey returns the key of the version of fragment id, or
+// the prefix of all its versions if version is zero. Versions are stored in
+// the order of their numbers.
+func fluxFragmentVersionKey(id influxdb.ID, version int) ([]byte, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	if version == 0 {
+		return encodedID, nil
+	}
+	key := make([]byte, len(encodedID)+8)
+	copy(key, encodedID)
+	binary.BigEndian.PutUint64(key[len(encodedID):], uint64(version))
+	return key, nil
+}
+
+// putFluxFragmentVersion stores the Flux of f as its current version.
+func (s *Service) putFluxFragmentVersion(ctx context.Context, tx Tx, f *influxdb.FluxFragment) error {
+	key, err := fluxFragmentVersionKey(f.ID, f.Version)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(&influxdb.FluxFragmentVersion{
+		FragmentID: f.ID,
+		Version:    f.Version,
+		Flux:       f.Flux,
+		CreatedAt:  f.UpdatedAt,
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(fluxFragmentVersionBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, v)
+}
+
+// FindFluxFragmentVersion returns a single version of a fragment.
+func (s *Service) FindFluxFragmentVersion(ctx context.Context, id influxdb.ID, version int) (*influxdb.FluxFragmentVersion, error) {
+	var fv *influxdb.FluxFragmentVersion
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if version < 1 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "flux fragment version must be positive",
+			}
+		}
+		key, err := fluxFragmentVersionKey(id, version)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(fluxFragmentVersionBucket)
+		if err != nil {
+			return err
+		}
+
+		v, err := b.Get(key)
+		if IsNotFound(err) {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrFluxFragmentNotFound,
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		fv = &influxdb.FluxFragmentVersion{}
+		if err := json.Unmarshal(v, fv); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindFluxFragmentVersion,
+			Err: err,
+		}
+	}
+	return fv, nil
+}
+
+// FindFluxFragmentVersions returns the versions of a fragment, from the
+// oldest to the latest.
+func (s *Service) FindFluxFragmentVersions(ctx context.Context, id influxdb.ID) ([]*influxdb.FluxFragmentVersion, error) {
+	var fvs []*influxdb.FluxFragmentVersion
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if _, err := s.findFluxFragmentByID(ctx, tx, id); err != nil {
+			return err
+		}
+		versions, err := s.findFluxFragmentVersions(ctx, tx, id)
+		fvs = versions
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindFluxFragmentVersions,
+			Err: err,
+		}
+	}
+	return fvs, nil
+}
+
+func (s *Service) findFluxFragmentVersions(ctx context.Context, tx Tx, id influxdb.ID) ([]*influxdb.FluxFragmentVersion, error) {
+	prefix, err := fluxFragmentVersionKey(id, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := tx.Bucket(fluxFragmentVersionBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	fvs := []*influxdb.FluxFragmentVersion{}
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		fv := &influxdb.FluxFragmentVersion{}
+		if err := json.Unmarshal(v, fv); err != nil {
+			return nil, err
+		}
+		fvs = append(fvs, fv)
+	}
+	return fvs, cur.Err()
+}
@@ -0,0 +1,88 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_FluxFragments(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing flux fragment service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, flux := range []string{``, `from(bucket: "b")`, `option now = () => 2020-01-01T00:00:00Z`, `x = `} {
+		f := &influxdb.FluxFragment{OrgID: org.ID, Name: "invalid", Flux: flux}
+		if err := svc.CreateFluxFragment(ctx, f); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("expected a fragment of %q to be invalid, got %v", flux, err)
+		}
+	}
+
+	f := &influxdb.FluxFragment{OrgID: org.ID, Name: "filters", Flux: `cpu = (tables=<-) => tables |> filter(fn: (r) => r._measurement == "cpu")`}
+	if err := svc.CreateFluxFragment(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if !f.ID.Valid() || f.Version != 1 {
+		t.Fatalf("expected a new fragment to be given an ID and version 1, got %+v", f)
+	}
+
+	dup := &influxdb.FluxFragment{OrgID: org.ID, Name: "filters", Flux: `x = 1`}
+	if err := svc.CreateFluxFragment(ctx, dup); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected creating a fragment with a duplicate name to conflict, got %v", err)
+	}
+
+	desc := "shared filters"
+	if f, err = svc.UpdateFluxFragment(ctx, f.ID, influxdb.FluxFragmentUpdate{Description: &desc}); err != nil {
+		t.Fatal(err)
+	} else if f.Version != 1 {
+		t.Errorf("expected a change of the description to keep version 1, got %d", f.Version)
+	}
+
+	flux := `cpu = (tables=<-) => tables |> filter(fn: (r) => r._measurement == "cpu" and r.host != "test")`
+	if f, err = svc.UpdateFluxFragment(ctx, f.ID, influxdb.FluxFragmentUpdate{Flux: &flux}); err != nil {
+		t.Fatal(err)
+	} else if f.Version != 2 {
+		t.Errorf("expected a change of the flux to add version 2, got %d", f.Version)
+	}
+
+	fvs, err := svc.FindFluxFragmentVersions(ctx, f.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fvs) != 2 || fvs[0].Version != 1 || fvs[1].Version != 2 || fvs[1].Flux != flux {
+		t.Fatalf("unexpected versions %+v", fvs)
+	}
+	fv, err := svc.FindFluxFragmentVersion(ctx, f.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fv.Flux != fvs[0].Flux {
+		t.Errorf("expected version 1 to be %q, got %q", fvs[0].Flux, fv.Flux)
+	}
+	if _, err := svc.FindFluxFragmentVersion(ctx, f.ID, 3); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected version 3 to be not found, got %v", err)
+	}
+
+	if err := svc.DeleteFluxFragment(ctx, f.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindFluxFragmentVersion(ctx, f.ID, 1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the versions of a deleted fragment to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var httpSinkBucket = []byte("httpsinksv1")
+
+var _ influxdb.HTTPSinkService = (*Service)(nil)
+
+func (s *Service) initializeHTTPSinks(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(httpSinkBucket)
+		return err
+	})
+}
+
+// FindHTTPSinkByID returns a single HTTP sink by ID.
+func (s *Service) FindHTTPSinkByID(ctx context.Context, id influxdb.ID) (*influxdb.HTTPSink, error) {
+	var hs *influxdb.HTTPSink
+	err := s.kv.View(ctx, func(tx Tx) error {
+		sink, err := s.findHTTPSinkByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		hs = sink
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindHTTPSinkByID,
+			Err: err,
+		}
+	}
+	return hs, nil
+}
+
+func (s *Service) findHTTPSinkByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.HTTPSink, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(httpSinkBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrHTTPSinkNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hs influxdb.HTTPSink
+	if err := json.Unmarshal(v, &hs); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &hs, nil
+}
+
+// FindHTTPSink returns the first HTTP sink that matches filter.
+func (s *Service) FindHTTPSink(ctx context.Context, filter influxdb.HTTPSinkFilter) (*influxdb.HTTPSink, error) {
+	if filter.ID != nil {
+		hs, err := s.FindHTTPSinkByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Op:  influxdb.OpFindHTTPSink,
+				Err: err,
+			}
+		}
+		if !filter.Match(hs) {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Op:   influxdb.OpFindHTTPSink,
+				Msg:  influxdb.ErrHTTPSinkNotFound,
+			}
+		}
+		return hs, nil
+	}
+
+	var hs *influxdb.HTTPSink
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachHTTPSink(ctx, tx, func(sink *influxdb.HTTPSink) bool {
+			if filter.Match(sink) {
+				hs = sink
+				return false
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindHTTPSink,
+			Err: err,
+		}
+	}
+	if hs == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Op:   influxdb.OpFindHTTPSink,
+			Msg:  influxdb.ErrHTTPSinkNotFound,
+		}
+	}
+	return hs, nil
+}
+
+// FindHTTPSinks returns a list of HTTP sinks that match filter.
+func (s *Service) FindHTTPSinks(ctx context.Context, filter influxdb.HTTPSinkFilter) ([]*influxdb.HTTPSink, error) {
+	hss := []*influxdb.HTTPSink{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachHTTPSink(ctx, tx, func(hs *influxdb.HTTPSink) bool {
+			if filter.Match(hs) {
+				hss = append(hss, hs)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindHTTPSinks,
+			Err: err,
+		}
+	}
+	return hss, nil
+}
+
+// forEachHTTPSink will iterate through all HTTP sinks while fn returns true.
+func (s *Service) forEachHTTPSink(ctx context.Context, tx Tx, fn func(*influxdb.HTTPSink) bool) error {
+	b, err := tx.Bucket(httpSinkBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		hs := &influxdb.HTTPSink{}
+		if err := json.Unmarshal(v, hs); err != nil {
+			return err
+		}
+		if !fn(hs) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// uniqueHTTPSinkName returns an error if another sink of the organization of
+// hs has its name.
+func (s *Service) uniqueHTTPSinkName(ctx context.Context, tx Tx, hs *influxdb.HTTPSink) error {
+	var conflict bool
+	err := s.forEachHTTPSink(ctx, tx, func(other *influxdb.HTTPSink) bool {
+		conflict = other.ID != hs.ID && other.OrgID == hs.OrgID && other.Name == hs.Name
+		return !conflict
+	})
+	if err != nil {
+		return err
+	}
+	if conflict {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("http sink with name %s already exists", hs.Name),
+		}
+	}
+	return nil
+}
+
+// CreateHTTPSink creates a new HTTP sink and sets hs.ID with the new identifier.
+func (s *Service) CreateHTTPSink(ctx context.Context, hs *influxdb.HTTPSink) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.createHTTPSink(ctx, tx, hs)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateHTTPSink,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) createHTTPSink(ctx context.Context, tx Tx, hs *influxdb.HTTPSink) error {
+	hs.SetDefaults()
+	if err := hs.Valid(); err != nil {
+		return err
+	}
+	if _, err := s.findOrganizationByID(ctx, tx, hs.OrgID); err != nil {
+		return err
+	}
+
+	hs.ID = s.IDGenerator.ID()
+	if err := s.uniqueHTTPSinkName(ctx, tx, hs); err != nil {
+		return err
+	}
+	hs.Credentials.Key = ""
+	if err := s.putHTTPSinkSecret(ctx, tx, hs); err != nil {
+		return err
+	}
+	hs.SetCreatedAt(s.Now())
+	hs.SetUpdatedAt(s.Now())
+	return s.putHTTPSink(ctx, tx, hs)
+}
+
+// putHTTPSinkSecret stores a new value of the credentials of hs, and
+// replaces it with the key of the secret.
+func (s *Service) putHTTPSinkSecret(ctx context.Context, tx Tx, hs *influxdb.HTTPSink) error {
+	if hs.Credentials.Value == nil {
+		return nil
+	}
+	hs.Credentials.Key = hs.SecretKey()
+	if err := s.putSecret(ctx, tx, hs.OrgID, hs.Credentials.Key, *hs.Credentials.Value); err != nil {
+		return err
+	}
+	hs.Credentials.Value = nil
+	return nil
+}
+
+// UpdateHTTPSink updates a single HTTP sink with changeset.
+func (s *Service) UpdateHTTPSink(ctx context.Context, id influxdb.ID, upd influxdb.HTTPSinkUpdate) (*influxdb.HTTPSink, error) {
+	var hs *influxdb.HTTPSink
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		sink, err := s.findHTTPSinkByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		secretKey := sink.Credentials.Key
+		upd.Apply(sink)
+		if err := sink.Valid(); err != nil {
+			return err
+		}
+		if upd.Name != nil {
+			if err := s.uniqueHTTPSinkName(ctx, tx, sink); err != nil {
+				return err
+			}
+		}
+		if secretKey != "" && sink.Credentials.Key == "" {
+			if err := s.deleteSecret(ctx, tx, sink.OrgID, secretKey); err != nil {
+				return err
+			}
+		}
+		if err := s.putHTTPSinkSecret(ctx, tx, sink); err != nil {
+			return err
+		}
+		sink.SetUpdatedAt(s.Now())
+		hs = sink
+		return s.putHTTPSink(ctx, tx, sink)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateHTTPSink,
+			Err: err,
+		}
+	}
+	return hs, nil
+}
+
+func (s *Service) putHTTPSink(ctx context.Context, tx Tx, hs *influxdb.HTTPSink) error {
+	v, err := json.Marshal(hs)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := hs.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(httpSinkBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// DeleteHTTPSink removes an HTTP sink and its credentials by ID.
+func (s *Service) DeleteHTTPSink(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		hs, err := s.findHTTPSinkByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if hs.Credentials.Key != "" {
+			if err := s.deleteSecret(ctx, tx, hs.OrgID, hs.Credentials.Key); err != nil {
+				return err
+			}
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(httpSinkBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteHTTPSink,
+			Err: err,
+		}
+	}
+	return nil
+}
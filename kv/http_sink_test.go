@@ -0,0 +1,89 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_HTTPSinks(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing http sink service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	token := "s3cr3t"
+	hs := &influxdb.HTTPSink{
+		OrgID:       org.ID,
+		Name:        "alerts",
+		URL:         "https://example.com/ingest",
+		AuthMethod:  influxdb.HTTPSinkAuthBearer,
+		Credentials: influxdb.SecretField{Value: &token},
+	}
+	if err := svc.CreateHTTPSink(ctx, hs); err != nil {
+		t.Fatal(err)
+	}
+	if hs.Method != "POST" || hs.Retry.MaxAttempts != influxdb.DefaultHTTPSinkMaxAttempts {
+		t.Fatalf("expected defaults to be set, got %+v", hs)
+	}
+	if hs.Credentials.Value != nil || hs.Credentials.Key != hs.SecretKey() {
+		t.Fatalf("expected credentials to be replaced by their key, got %+v", hs.Credentials)
+	}
+	if v, err := svc.LoadSecret(ctx, org.ID, hs.Credentials.Key); err != nil || v != token {
+		t.Fatalf("expected credentials to be stored, got %q, %v", v, err)
+	}
+
+	dup := &influxdb.HTTPSink{OrgID: org.ID, Name: "alerts", URL: "https://example.com/other"}
+	if err := svc.CreateHTTPSink(ctx, dup); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected duplicate name to conflict, got %v", err)
+	}
+
+	noCreds := &influxdb.HTTPSink{OrgID: org.ID, Name: "basic", URL: "https://example.com", AuthMethod: influxdb.HTTPSinkAuthBasic, Username: "u"}
+	if err := svc.CreateHTTPSink(ctx, noCreds); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected basic authentication without credentials to be rejected, got %v", err)
+	}
+
+	name := "alerts"
+	found, err := svc.FindHTTPSink(ctx, influxdb.HTTPSinkFilter{OrgID: &org.ID, Name: &name})
+	if err != nil || found.ID != hs.ID {
+		t.Fatalf("expected to find sink by name, got %v, %v", found, err)
+	}
+
+	none := influxdb.HTTPSinkAuthNone
+	empty := ""
+	hs, err = svc.UpdateHTTPSink(ctx, hs.ID, influxdb.HTTPSinkUpdate{
+		AuthMethod:  &none,
+		Credentials: &empty,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hs.Credentials.Key != "" {
+		t.Fatalf("expected credentials to be removed, got %q", hs.Credentials.Key)
+	}
+	if _, err := svc.LoadSecret(ctx, org.ID, hs.SecretKey()); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected credentials to be deleted, got %v", err)
+	}
+
+	if err := svc.DeleteHTTPSink(ctx, hs.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindHTTPSink(ctx, influxdb.HTTPSinkFilter{OrgID: &org.ID, Name: &name}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected deleted sink not to be found, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+var (
+	legalHoldsBucket = []byte("legalholdsv1")
+)
+
+var _ influxdb.LegalHoldService = (*Service)(nil)
+
+func (s *Service) initializeLegalHolds(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(legalHoldsBucket)
+		return err
+	})
+}
+
+// FindLegalHoldByID returns a single hold by ID.
+func (s *Service) FindLegalHoldByID(ctx context.Context, id influxdb.ID) (*influxdb.LegalHold, error) {
+	var h *influxdb.LegalHold
+	err := s.kv.View(ctx, func(tx Tx) error {
+		hold, err := s.findLegalHoldByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		h = hold
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindLegalHoldByID,
+			Err: err,
+		}
+	}
+	return h, nil
+}
+
+func (s *Service) findLegalHoldByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.LegalHold, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(legalHoldsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "legal hold not found",
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var h influxdb.LegalHold
+	if err := json.Unmarshal(v, &h); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &h, nil
+}
+
+// FindLegalHolds returns the holds matching filter, oldest first.
+func (s *Service) FindLegalHolds(ctx context.Context, filter influxdb.LegalHoldFilter) ([]*influxdb.LegalHold, error) {
+	hs := []*influxdb.LegalHold{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(legalHoldsBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			var h influxdb.LegalHold
+			if err := json.Unmarshal(v, &h); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+			if (filter.OrgID == nil || *filter.OrgID == h.OrgID) &&
+				(filter.BucketID == nil || *filter.BucketID == h.BucketID) &&
+				(filter.Active == nil || *filter.Active == h.Active()) {
+				hs = append(hs, &h)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindLegalHolds,
+			Err: err,
+		}
+	}
+	sort.SliceStable(hs, func(i, j int) bool {
+		return hs[i].CreatedAt.Before(hs[j].CreatedAt)
+	})
+	return hs, nil
+}
+
+// CreateLegalHold places a hold on the data of a bucket of the organization
+// of the hold. The hold is created by the user of the authorizer on ctx, if
+// any.
+func (s *Service) CreateLegalHold(ctx context.Context, h *influxdb.LegalHold) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := h.Valid(); err != nil {
+			return err
+		}
+		b, err := s.findBucketByID(ctx, tx, h.BucketID)
+		if err != nil {
+			return err
+		}
+		if b.OrgID != h.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  "bucket not found",
+			}
+		}
+
+		h.ID = s.IDGenerator.ID()
+		h.CreatedAt = s.Now()
+		h.CreatedBy = legalHoldUser(ctx)
+		h.ReleasedAt, h.ReleasedBy, h.ReleaseReason = nil, nil, ""
+		return s.putLegalHold(ctx, tx, h)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateLegalHold,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// ReleaseLegalHold releases an active hold, recording the user of the
+// authorizer on ctx, if any, and reason.
+func (s *Service) ReleaseLegalHold(ctx context.Context, id influxdb.ID, reason string) (*influxdb.LegalHold, error) {
+	var h *influxdb.LegalHold
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		hold, err := s.findLegalHoldByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !hold.Active() {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "legal hold is already released",
+			}
+		}
+
+		now := s.Now()
+		hold.ReleasedAt = &now
+		hold.ReleasedBy = legalHoldUser(ctx)
+		hold.ReleaseReason = reason
+		if err := s.putLegalHold(ctx, tx, hold); err != nil {
+			return err
+		}
+		h = hold
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpReleaseLegalHold,
+			Err: err,
+		}
+	}
+	return h, nil
+}
+
+// legalHoldUser returns the ID of the user of the authorizer on ctx, or nil
+// if there is none.
+func legalHoldUser(ctx context.Context) *influxdb.ID {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil
+	}
+	id := a.GetUserID()
+	if !id.Valid() {
+		return nil
+	}
+	return &id
+}
+
+func (s *Service) putLegalHold(ctx context.Context, tx Tx, h *influxdb.LegalHold) error {
+	encodedID, err := h.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	v, err := json.Marshal(h)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(legalHoldsBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
@@ -0,0 +1,88 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_LegalHolds(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing legal hold service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &influxdb.Bucket{OrgID: org.ID, Name: "bucket"}
+	if err := svc.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &influxdb.LegalHold{
+		OrgID:     org.ID,
+		BucketID:  bucket.ID,
+		Predicate: `host="a"`,
+		Reason:    "litigation",
+	}
+	if err := svc.CreateLegalHold(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+	if !h.ID.Valid() || h.CreatedAt.IsZero() || !h.Active() {
+		t.Fatalf("expected a new hold to be active and given an ID and a creation time, got %+v", h)
+	}
+
+	other := &influxdb.LegalHold{OrgID: 1000, BucketID: bucket.ID, Reason: "litigation"}
+	if err := svc.CreateLegalHold(ctx, other); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected holding the bucket of another organization to be not found, got %v", err)
+	}
+
+	active := true
+	hs, err := svc.FindLegalHolds(ctx, influxdb.LegalHoldFilter{BucketID: &bucket.ID, Active: &active})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hs) != 1 || hs[0].ID != h.ID {
+		t.Errorf("unexpected active holds %+v", hs)
+	}
+
+	released, err := svc.ReleaseLegalHold(ctx, h.ID, "settled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released.Active() || released.ReleaseReason != "settled" {
+		t.Errorf("expected the hold to be released, got %+v", released)
+	}
+	if _, err := svc.ReleaseLegalHold(ctx, h.ID, "again"); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected releasing a released hold to conflict, got %v", err)
+	}
+
+	hs, err = svc.FindLegalHolds(ctx, influxdb.LegalHoldFilter{BucketID: &bucket.ID, Active: &active})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hs) != 0 {
+		t.Errorf("expected no active holds, got %+v", hs)
+	}
+
+	// Released holds are kept as the audit trail.
+	found, err := svc.FindLegalHoldByID(ctx, h.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Active() || found.Reason != "litigation" {
+		t.Errorf("unexpected released hold %+v", found)
+	}
+}
@@ -0,0 +1,285 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	lifecyclePolicyBucket = []byte("lifecyclepoliciesv1")
+)
+
+var _ influxdb.LifecyclePolicyService = (*Service)(nil)
+
+func (s *Service) initializeLifecyclePolicies(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(lifecyclePolicyBucket)
+		return err
+	})
+}
+
+// FindLifecyclePolicyByID returns a single lifecycle policy by ID.
+func (s *Service) FindLifecyclePolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.LifecyclePolicy, error) {
+	var p *influxdb.LifecyclePolicy
+	err := s.kv.View(ctx, func(tx Tx) error {
+		policy, err := s.findLifecyclePolicyByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		p = policy
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindLifecyclePolicyByID,
+			Err: err,
+		}
+	}
+	return p, nil
+}
+
+func (s *Service) findLifecyclePolicyByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.LifecyclePolicy, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(lifecyclePolicyBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrLifecyclePolicyNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p influxdb.LifecyclePolicy
+	if err := json.Unmarshal(v, &p); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &p, nil
+}
+
+// FindLifecyclePolicies returns a list of lifecycle policies that match filter.
+func (s *Service) FindLifecyclePolicies(ctx context.Context, filter influxdb.LifecyclePolicyFilter) ([]*influxdb.LifecyclePolicy, error) {
+	ps := []*influxdb.LifecyclePolicy{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachLifecyclePolicy(ctx, tx, func(p *influxdb.LifecyclePolicy) bool {
+			if filter.Match(p) {
+				ps = append(ps, p)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindLifecyclePolicies,
+			Err: err,
+		}
+	}
+	return ps, nil
+}
+
+// forEachLifecyclePolicy will iterate through all lifecycle policies while fn returns true.
+func (s *Service) forEachLifecyclePolicy(ctx context.Context, tx Tx, fn func(*influxdb.LifecyclePolicy) bool) error {
+	b, err := tx.Bucket(lifecyclePolicyBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		p := &influxdb.LifecyclePolicy{}
+		if err := json.Unmarshal(v, p); err != nil {
+			return err
+		}
+		if !fn(p) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// CreateLifecyclePolicy creates a new lifecycle policy and sets p.ID with
+// the new identifier.
+func (s *Service) CreateLifecyclePolicy(ctx context.Context, p *influxdb.LifecyclePolicy) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.createLifecyclePolicy(ctx, tx, p)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateLifecyclePolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) createLifecyclePolicy(ctx context.Context, tx Tx, p *influxdb.LifecyclePolicy) error {
+	if err := s.validLifecyclePolicy(ctx, tx, p); err != nil {
+		return err
+	}
+
+	var exists bool
+	err := s.forEachLifecyclePolicy(ctx, tx, func(e *influxdb.LifecyclePolicy) bool {
+		exists = e.BucketID == p.BucketID
+		return !exists
+	})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "bucket already has a lifecycle policy",
+		}
+	}
+
+	p.ID = s.IDGenerator.ID()
+	p.Status = influxdb.LifecyclePolicyStatus{}
+	p.SetCreatedAt(s.Now())
+	p.SetUpdatedAt(s.Now())
+	return s.putLifecyclePolicy(ctx, tx, p)
+}
+
+// validLifecyclePolicy returns an error if p is invalid, or if the buckets
+// it reads and writes do not belong to its organization.
+func (s *Service) validLifecyclePolicy(ctx context.Context, tx Tx, p *influxdb.LifecyclePolicy) error {
+	if err := p.Valid(); err != nil {
+		return err
+	}
+
+	ids := []influxdb.ID{p.BucketID}
+	for _, r := range p.Downsample {
+		ids = append(ids, r.DestinationBucketID)
+	}
+	for _, id := range ids {
+		b, err := s.findBucketByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if b.OrgID != p.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "bucket does not belong to the organization of the policy",
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateLifecyclePolicy updates a single lifecycle policy with changeset.
+func (s *Service) UpdateLifecyclePolicy(ctx context.Context, id influxdb.ID, upd influxdb.LifecyclePolicyUpdate) (*influxdb.LifecyclePolicy, error) {
+	var p *influxdb.LifecyclePolicy
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		policy, err := s.findLifecyclePolicyByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		upd.Apply(policy)
+		if err := s.validLifecyclePolicy(ctx, tx, policy); err != nil {
+			return err
+		}
+		policy.SetUpdatedAt(s.Now())
+		p = policy
+		return s.putLifecyclePolicy(ctx, tx, policy)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateLifecyclePolicy,
+			Err: err,
+		}
+	}
+	return p, nil
+}
+
+// UpdateLifecyclePolicyStatus replaces the status of a lifecycle policy.
+func (s *Service) UpdateLifecyclePolicyStatus(ctx context.Context, id influxdb.ID, status influxdb.LifecyclePolicyStatus) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		p, err := s.findLifecyclePolicyByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		p.Status = status
+		return s.putLifecyclePolicy(ctx, tx, p)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpUpdateLifecyclePolicyStatus,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putLifecyclePolicy(ctx context.Context, tx Tx, p *influxdb.LifecyclePolicy) error {
+	v, err := json.Marshal(p)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := p.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(lifecyclePolicyBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// DeleteLifecyclePolicy removes a lifecycle policy by ID.
+func (s *Service) DeleteLifecyclePolicy(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findLifecyclePolicyByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(lifecyclePolicyBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteLifecyclePolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
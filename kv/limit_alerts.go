@@ -0,0 +1,153 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	limitAlertsBucket = []byte("limitalertsv1")
+)
+
+var _ influxdb.LimitAlertsService = (*Service)(nil)
+
+func (s *Service) initializeLimitAlerts(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(limitAlertsBucket)
+		return err
+	})
+}
+
+// FindLimitAlerts returns the checks of the platform limits of an
+// organization. An organization which has not configured them has the
+// default checks.
+func (s *Service) FindLimitAlerts(ctx context.Context, orgID influxdb.ID) (*influxdb.LimitAlerts, error) {
+	var a *influxdb.LimitAlerts
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, orgID); err != nil {
+			return err
+		}
+		alerts, err := s.findLimitAlerts(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		a = alerts
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindLimitAlerts,
+			Err: err,
+		}
+	}
+	return a, nil
+}
+
+func (s *Service) findLimitAlerts(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.LimitAlerts, error) {
+	encodedID, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(limitAlertsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return influxdb.DefaultLimitAlerts(orgID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var a influxdb.LimitAlerts
+	if err := json.Unmarshal(v, &a); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &a, nil
+}
+
+// UpdateLimitAlerts updates the checks of the platform limits of an
+// organization with changeset.
+func (s *Service) UpdateLimitAlerts(ctx context.Context, orgID influxdb.ID, upd influxdb.LimitAlertsUpdate) (*influxdb.LimitAlerts, error) {
+	var a *influxdb.LimitAlerts
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, orgID); err != nil {
+			return err
+		}
+		alerts, err := s.findLimitAlerts(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+
+		upd.Apply(alerts)
+		if err := alerts.Valid(); err != nil {
+			return err
+		}
+
+		alerts.UpdatedAt = s.Now()
+		if err := s.putLimitAlerts(ctx, tx, alerts); err != nil {
+			return err
+		}
+		a = alerts
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateLimitAlerts,
+			Err: err,
+		}
+	}
+	return a, nil
+}
+
+func (s *Service) putLimitAlerts(ctx context.Context, tx Tx, a *influxdb.LimitAlerts) error {
+	encodedID, err := a.OrgID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	v, err := json.Marshal(a)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(limitAlertsBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// deleteLimitAlerts removes the checks of the platform limits of a deleted
+// organization.
+func (s *Service) deleteLimitAlerts(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	encodedID, err := orgID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(limitAlertsBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(encodedID)
+}
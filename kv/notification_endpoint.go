@@ -323,6 +323,9 @@ func (s *Service) deleteNotificationEndpoint(ctx context.Context, tx Tx, id infl
 	if err := s.endpointStore.DeleteEnt(ctx, tx, Entity{PK: EncID(id)}); err != nil {
 		return nil, 0, err
 	}
+	if err := s.deleteEndpointSlackThreads(ctx, tx, id); err != nil {
+		return nil, 0, err
+	}
 
 	return edp.SecretFields(), edp.GetOrgID(), s.deleteUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
 		ResourceID:   id,
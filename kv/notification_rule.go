@@ -99,6 +99,10 @@ func (s *Service) createNotificationRule(ctx context.Context, tx Tx, nr influxdb
 		return err
 	}
 
+	if err := s.recordNotificationRuleRevision(ctx, tx, nr.NotificationRule); err != nil {
+		return err
+	}
+
 	urm := &influxdb.UserResourceMapping{
 		ResourceID:   id,
 		UserID:       userID,
@@ -208,6 +212,10 @@ func (s *Service) updateNotificationRule(ctx context.Context, tx Tx, id influxdb
 		return nil, err
 	}
 
+	if err := s.recordNotificationRuleRevision(ctx, tx, nr.NotificationRule); err != nil {
+		return nil, err
+	}
+
 	return nr.NotificationRule, nil
 }
 
@@ -261,6 +269,10 @@ func (s *Service) patchNotificationRule(ctx context.Context, tx Tx, id influxdb.
 		return nil, err
 	}
 
+	if err := s.recordNotificationRuleRevision(ctx, tx, nr); err != nil {
+		return nil, err
+	}
+
 	return nr, nil
 }
 
@@ -298,6 +310,19 @@ func (s *Service) putNotificationRule(ctx context.Context, tx Tx, nr influxdb.No
 	return nil
 }
 
+// recordNotificationRuleRevision records the definition of a notification
+// rule in its revisions.
+func (s *Service) recordNotificationRuleRevision(ctx context.Context, tx Tx, nr influxdb.NotificationRule) error {
+	script, err := definitionScript(nr)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return s.recordScriptRevision(ctx, tx, influxdb.NotificationRuleResourceType, nr.GetID(), nr.GetOrgID(), script)
+}
+
 // FindNotificationRuleByID returns a single notification rule by ID.
 func (s *Service) FindNotificationRuleByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationRule, error) {
 	var (
@@ -470,6 +495,10 @@ func (s *Service) deleteNotificationRule(ctx context.Context, tx Tx, id influxdb
 		return err
 	}
 
+	if err := s.deleteScriptRevisions(ctx, tx, id); err != nil {
+		return err
+	}
+
 	encodedID, err := id.Encode()
 	if err != nil {
 		return ErrInvalidNotificationRuleID
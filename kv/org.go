@@ -495,6 +495,10 @@ func (s *Service) updateOrganization(ctx context.Context, tx Tx, id influxdb.ID,
 		o.Description = *upd.Description
 	}
 
+	if upd.DefaultBucketID != nil {
+		o.DefaultBucketID = upd.DefaultBucketID
+	}
+
 	o.UpdatedAt = s.Now()
 
 	if err := s.appendOrganizationEventToLog(ctx, tx, o.ID, organizationUpdatedEvent); err != nil {
@@ -529,26 +533,47 @@ func (s *Service) updateOrganization(ctx context.Context, tx Tx, id influxdb.ID,
 	return o, nil
 }
 
-func (s *Service) deleteOrganizationsBuckets(ctx context.Context, tx Tx, id influxdb.ID) error {
+// deleteOrganizationsBuckets deletes the buckets of the organization with the
+// id in tx, and returns the functions of the bucket delete hooks to call once
+// tx commits.
+func (s *Service) deleteOrganizationsBuckets(ctx context.Context, tx Tx, id influxdb.ID) ([]func(), error) {
 	filter := influxdb.BucketFilter{
 		OrganizationID: &id,
 	}
 	bs, err := s.findBuckets(ctx, tx, filter)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	var committed []func()
 	for _, b := range bs {
-		if err := s.deleteBucket(ctx, tx, b.ID); err != nil {
+		fns, err := s.deleteBucket(ctx, tx, b.ID)
+		if err != nil {
 			s.log.Warn("Bucket was not deleted", zap.Stringer("bucketID", b.ID), zap.Stringer("orgID", b.OrgID))
+			continue
 		}
+		committed = append(committed, fns...)
 	}
-	return nil
+	return committed, nil
 }
 
 // DeleteOrganization deletes a organization and prunes it from the index.
 func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error {
+	var committed []func()
 	err := s.kv.Update(ctx, func(tx Tx) error {
-		if err := s.deleteOrganizationsBuckets(ctx, tx, id); err != nil {
+		var err error
+		if committed, err = s.deleteOrganizationsBuckets(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := s.deleteOrganizationsRoles(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := s.deleteOrganizationFeatureFlagSettings(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := s.clearDefaultOrganization(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := s.deleteLimitAlerts(ctx, tx, id); err != nil {
 			return err
 		}
 		if pe := s.deleteOrganization(ctx, tx, id); pe != nil {
@@ -570,6 +595,10 @@ func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error
 			Err: err,
 		}
 	}
+
+	for _, fn := range committed {
+		fn()
+	}
 	return nil
 }
 
@@ -709,7 +738,7 @@ func (s *Service) FindResourceOrganizationID(ctx context.Context, rt influxdb.Re
 			return influxdb.InvalidID(), err
 		}
 		return r.OrgID, nil
-	case influxdb.BucketsResourceType:
+	case influxdb.BucketsResourceType, influxdb.UnmaskedResourceType:
 		r, err := s.FindBucketByID(ctx, id)
 		if err != nil {
 			return influxdb.InvalidID(), err
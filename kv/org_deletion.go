@@ -0,0 +1,168 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var orgDeletionBucket = []byte("orgdeletionsv1")
+
+func (s *Service) initializeOrgDeletions(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(orgDeletionBucket)
+		return err
+	})
+}
+
+// FindOrgDeletionByID returns a single organization deletion by ID.
+func (s *Service) FindOrgDeletionByID(ctx context.Context, id influxdb.ID) (*influxdb.OrgDeletion, error) {
+	var d *influxdb.OrgDeletion
+	err := s.kv.View(ctx, func(tx Tx) error {
+		del, err := s.findOrgDeletionByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		d = del
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindOrgDeletionByID,
+			Err: err,
+		}
+	}
+	return d, nil
+}
+
+func (s *Service) findOrgDeletionByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.OrgDeletion, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(orgDeletionBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrOrgDeletionNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var d influxdb.OrgDeletion
+	if err := json.Unmarshal(v, &d); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &d, nil
+}
+
+// FindOrgDeletions returns a list of organization deletions that match filter.
+func (s *Service) FindOrgDeletions(ctx context.Context, filter influxdb.OrgDeletionFilter) ([]*influxdb.OrgDeletion, error) {
+	ds := []*influxdb.OrgDeletion{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(orgDeletionBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			d := &influxdb.OrgDeletion{}
+			if err := json.Unmarshal(v, d); err != nil {
+				return err
+			}
+			if (filter.OrgID == nil || *filter.OrgID == d.OrgID) &&
+				(filter.Status == nil || *filter.Status == d.Status) {
+				ds = append(ds, d)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindOrgDeletions,
+			Err: err,
+		}
+	}
+	return ds, nil
+}
+
+// CreateOrgDeletion stores a new organization deletion and sets d.ID with
+// the new identifier.
+func (s *Service) CreateOrgDeletion(ctx context.Context, d *influxdb.OrgDeletion) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		d.ID = s.IDGenerator.ID()
+		d.StartedAt = s.Now()
+		d.UpdatedAt = d.StartedAt
+		return s.putOrgDeletion(ctx, tx, d)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateOrgDeletion,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// PutOrgDeletion stores the progress of an organization deletion.
+func (s *Service) PutOrgDeletion(ctx context.Context, d *influxdb.OrgDeletion) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrgDeletionByID(ctx, tx, d.ID); err != nil {
+			return err
+		}
+		d.UpdatedAt = s.Now()
+		return s.putOrgDeletion(ctx, tx, d)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutOrgDeletion,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putOrgDeletion(ctx context.Context, tx Tx, d *influxdb.OrgDeletion) error {
+	v, err := json.Marshal(d)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := d.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(orgDeletionBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
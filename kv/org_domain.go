@@ -0,0 +1,171 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	orgDomainsBucket = []byte("orgdomainsv1")
+)
+
+var _ influxdb.OrgDomainService = (*Service)(nil)
+
+func (s *Service) initializeOrgDomains(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(orgDomainsBucket)
+		return err
+	})
+}
+
+// FindOrgDomain returns the mapping of a subdomain. Subdomains are matched
+// regardless of case.
+func (s *Service) FindOrgDomain(ctx context.Context, subdomain string) (*influxdb.OrgDomain, error) {
+	var d *influxdb.OrgDomain
+	err := s.kv.View(ctx, func(tx Tx) error {
+		od, err := s.findOrgDomain(ctx, tx, strings.ToLower(subdomain))
+		if err != nil {
+			return err
+		}
+		d = od
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindOrgDomain,
+			Err: err,
+		}
+	}
+	return d, nil
+}
+
+func (s *Service) findOrgDomain(ctx context.Context, tx Tx, subdomain string) (*influxdb.OrgDomain, error) {
+	b, err := tx.Bucket(orgDomainsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get([]byte(subdomain))
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "org domain not found",
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var d influxdb.OrgDomain
+	if err := json.Unmarshal(v, &d); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &d, nil
+}
+
+// FindOrgDomains returns the mappings matching filter, by subdomain.
+func (s *Service) FindOrgDomains(ctx context.Context, filter influxdb.OrgDomainFilter) ([]*influxdb.OrgDomain, error) {
+	ds := []*influxdb.OrgDomain{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(orgDomainsBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			var d influxdb.OrgDomain
+			if err := json.Unmarshal(v, &d); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+			if filter.OrgID == nil || *filter.OrgID == d.OrgID {
+				ds = append(ds, &d)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindOrgDomains,
+			Err: err,
+		}
+	}
+	return ds, nil
+}
+
+// CreateOrgDomain maps a subdomain, lowercased, to an existing organization.
+func (s *Service) CreateOrgDomain(ctx context.Context, d *influxdb.OrgDomain) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		d.Subdomain = strings.ToLower(d.Subdomain)
+		if err := d.Valid(); err != nil {
+			return err
+		}
+		if _, err := s.findOrganizationByID(ctx, tx, d.OrgID); err != nil {
+			return err
+		}
+		if _, err := s.findOrgDomain(ctx, tx, d.Subdomain); err == nil {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "subdomain is already mapped to an organization",
+			}
+		} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+
+		d.CreatedAt = s.Now()
+		v, err := json.Marshal(d)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		b, err := tx.Bucket(orgDomainsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(d.Subdomain), v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateOrgDomain,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteOrgDomain removes the mapping of a subdomain.
+func (s *Service) DeleteOrgDomain(ctx context.Context, subdomain string) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		subdomain = strings.ToLower(subdomain)
+		if _, err := s.findOrgDomain(ctx, tx, subdomain); err != nil {
+			return err
+		}
+		b, err := tx.Bucket(orgDomainsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte(subdomain))
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteOrgDomain,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_OrgDomains(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing org domain service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &influxdb.OrgDomain{Subdomain: "Org1", OrgID: org.ID}
+	if err := svc.CreateOrgDomain(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Subdomain != "org1" || d.CreatedAt.IsZero() {
+		t.Fatalf("expected a new subdomain to be lowercased and given a creation time, got %+v", d)
+	}
+
+	if err := svc.CreateOrgDomain(ctx, &influxdb.OrgDomain{Subdomain: "org1", OrgID: org.ID}); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected mapping a subdomain twice to conflict, got %v", err)
+	}
+	if err := svc.CreateOrgDomain(ctx, &influxdb.OrgDomain{Subdomain: "org2", OrgID: 1000}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected mapping a subdomain to a missing organization to be not found, got %v", err)
+	}
+	if err := svc.CreateOrgDomain(ctx, &influxdb.OrgDomain{Subdomain: "org.2", OrgID: org.ID}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a subdomain of more than one label to be invalid, got %v", err)
+	}
+
+	found, err := svc.FindOrgDomain(ctx, "ORG1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.OrgID != org.ID {
+		t.Errorf("unexpected org domain %+v", found)
+	}
+
+	ds, err := svc.FindOrgDomains(ctx, influxdb.OrgDomainFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 || ds[0].Subdomain != "org1" {
+		t.Errorf("unexpected org domains %+v", ds)
+	}
+
+	if err := svc.DeleteOrgDomain(ctx, "org1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindOrgDomain(ctx, "org1"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected a deleted subdomain to be not found, got %v", err)
+	}
+	if err := svc.DeleteOrgDomain(ctx, "org1"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected deleting a missing subdomain to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,365 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// EPasswordReused is used when a password matches one of the recent
+	// passwords of the user retained by the password policy.
+	EPasswordReused = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "password has been used recently",
+	}
+
+	// EUserLocked is returned when a user has been locked out after too
+	// many failed password attempts.
+	EUserLocked = &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  "user is locked out due to too many failed password attempts",
+	}
+)
+
+var (
+	userpasswordHistoryBucket = []byte("userspasswordhistoryv1")
+	userpasswordLockoutBucket = []byte("userspasswordlockoutv1")
+)
+
+var _ influxdb.PasswordsService = (*PasswordPolicyService)(nil)
+var _ influxdb.PasswordLockoutService = (*PasswordPolicyService)(nil)
+
+func (s *Service) initializePasswordPolicy(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(userpasswordHistoryBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(userpasswordLockoutBucket)
+		return err
+	})
+}
+
+// PasswordPolicyService enforces a password policy on the passwords of
+// another influxdb.PasswordsService, such as the kv or tenant service. The
+// password history and failed attempts of users, and the lockout events of
+// their operation log, are kept by the kv service.
+type PasswordPolicyService struct {
+	s      *Service
+	next   influxdb.PasswordsService
+	policy influxdb.PasswordPolicy
+}
+
+// NewPasswordPolicyService constructs a PasswordPolicyService enforcing
+// policy on next, whose state is stored by s.
+func NewPasswordPolicyService(s *Service, next influxdb.PasswordsService, policy influxdb.PasswordPolicy) *PasswordPolicyService {
+	return &PasswordPolicyService{
+		s:      s,
+		next:   next,
+		policy: policy,
+	}
+}
+
+// SetPassword overrides the password of a known user if the password meets
+// the requirements of the policy and has not been used recently.
+func (p *PasswordPolicyService) SetPassword(ctx context.Context, userID influxdb.ID, password string) error {
+	if err := p.policy.Validate(password); err != nil {
+		return err
+	}
+
+	if p.policy.HistorySize > 0 {
+		if err := p.checkPasswordHistory(ctx, userID, password); err != nil {
+			return err
+		}
+	}
+
+	if err := p.next.SetPassword(ctx, userID, password); err != nil {
+		return err
+	}
+
+	if p.policy.HistorySize > 0 {
+		return p.updatePasswordHistory(ctx, userID, password)
+	}
+	return nil
+}
+
+// ComparePassword checks if the password matches the password recorded,
+// locking out users after too many consecutive failed attempts.
+func (p *PasswordPolicyService) ComparePassword(ctx context.Context, userID influxdb.ID, password string) error {
+	if p.policy.MaxFailedAttempts <= 0 {
+		return p.next.ComparePassword(ctx, userID, password)
+	}
+
+	var lockout passwordLockout
+	err := p.s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		lockout, err = p.findPasswordLockout(ctx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if lockout.locked(p.s.Now(), p.policy.LockoutDuration) {
+		return EUserLocked
+	}
+
+	cmpErr := p.next.ComparePassword(ctx, userID, password)
+	switch {
+	case cmpErr == nil:
+		if lockout == (passwordLockout{}) {
+			return nil
+		}
+		return p.s.kv.Update(ctx, func(tx Tx) error {
+			return p.deletePasswordLockout(ctx, tx, userID)
+		})
+	case influxdb.ErrorCode(cmpErr) == influxdb.EForbidden:
+		err := p.s.kv.Update(ctx, func(tx Tx) error {
+			return p.recordFailedAttempt(ctx, tx, userID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return cmpErr
+}
+
+// CompareAndSetPassword checks the password and if they match
+// updates to the new password.
+func (p *PasswordPolicyService) CompareAndSetPassword(ctx context.Context, userID influxdb.ID, old, new string) error {
+	// The failed attempt must be recorded even though the new password is
+	// not set, so the comparison is not in the transaction of the update.
+	if err := p.ComparePassword(ctx, userID, old); err != nil {
+		return err
+	}
+	return p.SetPassword(ctx, userID, new)
+}
+
+// UnlockUser clears the failed password attempts of a user, unlocking
+// the user if they were locked out.
+func (p *PasswordPolicyService) UnlockUser(ctx context.Context, userID influxdb.ID) error {
+	return p.s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := p.s.findUserByID(ctx, tx, userID); err != nil {
+			return err
+		}
+		if err := p.deletePasswordLockout(ctx, tx, userID); err != nil {
+			return err
+		}
+		return p.s.appendUserEventToLog(ctx, tx, userID, userUnlockedEvent)
+	})
+}
+
+func (p *PasswordPolicyService) hasher() Crypt {
+	if p.s.Hash != nil {
+		return p.s.Hash
+	}
+	return &Bcrypt{}
+}
+
+// findPasswordHistory returns the hashes of the recent passwords of a user,
+// starting with the most recent.
+func (p *PasswordPolicyService) findPasswordHistory(ctx context.Context, tx Tx, encodedID []byte) ([][]byte, error) {
+	b, err := tx.Bucket(userpasswordHistoryBucket)
+	if err != nil {
+		return nil, UnavailablePasswordServiceError(err)
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, UnavailablePasswordServiceError(err)
+	}
+
+	var history [][]byte
+	if err := json.Unmarshal(v, &history); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return history, nil
+}
+
+// checkPasswordHistory returns EPasswordReused if password is the current
+// password of the user, or one of the recent passwords retained by the
+// policy.
+func (p *PasswordPolicyService) checkPasswordHistory(ctx context.Context, userID influxdb.ID, password string) error {
+	// The current password may have been set before the policy was.
+	if err := p.next.ComparePassword(ctx, userID, password); err == nil {
+		return EPasswordReused
+	}
+
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return CorruptUserIDError(userID.String(), err)
+	}
+
+	var history [][]byte
+	err = p.s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		history, err = p.findPasswordHistory(ctx, tx, encodedID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The current password counts towards the history size.
+	if n := p.policy.HistorySize; len(history) > n {
+		history = history[:n]
+	}
+	hasher := p.hasher()
+	for _, hash := range history {
+		if err := hasher.CompareHashAndPassword(hash, []byte(password)); err == nil {
+			return EPasswordReused
+		}
+	}
+	return nil
+}
+
+// updatePasswordHistory adds the new password of a user to its history,
+// retaining only as many passwords as the policy requires.
+func (p *PasswordPolicyService) updatePasswordHistory(ctx context.Context, userID influxdb.ID, password string) error {
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return CorruptUserIDError(userID.String(), err)
+	}
+
+	hash, err := p.hasher().GenerateFromPassword([]byte(password), DefaultCost)
+	if err != nil {
+		return InternalPasswordHashError(err)
+	}
+
+	return p.s.kv.Update(ctx, func(tx Tx) error {
+		history, err := p.findPasswordHistory(ctx, tx, encodedID)
+		if err != nil {
+			return err
+		}
+
+		history = append([][]byte{hash}, history...)
+		if n := p.policy.HistorySize; len(history) > n {
+			history = history[:n]
+		}
+
+		v, err := json.Marshal(history)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+
+		b, err := tx.Bucket(userpasswordHistoryBucket)
+		if err != nil {
+			return UnavailablePasswordServiceError(err)
+		}
+		if err := b.Put(encodedID, v); err != nil {
+			return UnavailablePasswordServiceError(err)
+		}
+		return nil
+	})
+}
+
+// passwordLockout records the consecutive failed password attempts of a user.
+type passwordLockout struct {
+	FailedAttempts int       `json:"failedAttempts"`
+	LockedAt       time.Time `json:"lockedAt,omitempty"`
+}
+
+// locked returns true if the user is locked out at now.
+func (l *passwordLockout) locked(now time.Time, d time.Duration) bool {
+	if l.LockedAt.IsZero() {
+		return false
+	}
+	return d == 0 || now.Before(l.LockedAt.Add(d))
+}
+
+func (p *PasswordPolicyService) findPasswordLockout(ctx context.Context, tx Tx, userID influxdb.ID) (passwordLockout, error) {
+	var lockout passwordLockout
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return lockout, CorruptUserIDError(userID.String(), err)
+	}
+
+	b, err := tx.Bucket(userpasswordLockoutBucket)
+	if err != nil {
+		return lockout, UnavailablePasswordServiceError(err)
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return lockout, nil
+	}
+	if err != nil {
+		return lockout, UnavailablePasswordServiceError(err)
+	}
+
+	if err := json.Unmarshal(v, &lockout); err != nil {
+		return lockout, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return lockout, nil
+}
+
+// recordFailedAttempt counts a failed password attempt of a user, locking
+// out the user once it has reached the maximum of the policy.
+func (p *PasswordPolicyService) recordFailedAttempt(ctx context.Context, tx Tx, userID influxdb.ID) error {
+	lockout, err := p.findPasswordLockout(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !lockout.LockedAt.IsZero() {
+		// The previous lockout has expired.
+		lockout = passwordLockout{}
+	}
+	lockout.FailedAttempts++
+	if lockout.FailedAttempts >= p.policy.MaxFailedAttempts {
+		lockout.LockedAt = p.s.Now()
+		if err := p.s.appendUserEventToLog(ctx, tx, userID, userLockedEvent); err != nil {
+			return err
+		}
+	}
+
+	v, err := json.Marshal(lockout)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return CorruptUserIDError(userID.String(), err)
+	}
+	b, err := tx.Bucket(userpasswordLockoutBucket)
+	if err != nil {
+		return UnavailablePasswordServiceError(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return UnavailablePasswordServiceError(err)
+	}
+	return nil
+}
+
+func (p *PasswordPolicyService) deletePasswordLockout(ctx context.Context, tx Tx, userID influxdb.ID) error {
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return CorruptUserIDError(userID.String(), err)
+	}
+
+	b, err := tx.Bucket(userpasswordLockoutBucket)
+	if err != nil {
+		return UnavailablePasswordServiceError(err)
+	}
+	if err := b.Delete(encodedID); err != nil {
+		return UnavailablePasswordServiceError(err)
+	}
+	return nil
+}
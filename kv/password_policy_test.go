@@ -0,0 +1,179 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"go.uber.org/zap/zaptest"
+)
+
+func newPasswordPolicyService(t *testing.T, policy influxdb.PasswordPolicy) (*kv.PasswordPolicyService, *kv.Service, *mock.TimeGenerator, func()) {
+	t.Helper()
+
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new bolt kv store: %v", err)
+	}
+
+	tg := &mock.TimeGenerator{FakeValue: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	svc.IDGenerator = mock.NewIDGenerator("0000000000000001", t)
+	svc.TimeGenerator = tg
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing password service: %v", err)
+	}
+	if err := svc.PutUser(ctx, &influxdb.User{ID: 1, Name: "user1"}); err != nil {
+		t.Fatalf("error populating users: %v", err)
+	}
+	return kv.NewPasswordPolicyService(svc, svc, policy), svc, tg, closeStore
+}
+
+func TestPasswordPolicyService_SetPassword(t *testing.T) {
+	svc, _, _, done := newPasswordPolicyService(t, influxdb.PasswordPolicy{
+		MinLength:           10,
+		MinCharacterClasses: 3,
+		HistorySize:         2,
+	})
+	defer done()
+
+	ctx := context.Background()
+	// "Pässwört1" is 9 characters long, but 11 bytes long.
+	for _, pw := range []string{"Short1!", "alllowercase", "Pässwört1", "lowerUPPER1234"} {
+		err := svc.SetPassword(ctx, 1, pw)
+		if pw == "lowerUPPER1234" {
+			if err != nil {
+				t.Fatalf("unexpected error setting password %q: %v", pw, err)
+			}
+		} else if influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("expected invalid error setting password %q, got %v", pw, err)
+		}
+	}
+
+	if err := svc.SetPassword(ctx, 1, "lowerUPPER1234"); err != kv.EPasswordReused {
+		t.Fatalf("expected current password to be rejected, got %v", err)
+	}
+	if err := svc.SetPassword(ctx, 1, "secondUPPER1234"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.SetPassword(ctx, 1, "lowerUPPER1234"); err != kv.EPasswordReused {
+		t.Fatalf("expected previous password to be rejected, got %v", err)
+	}
+	if err := svc.CompareAndSetPassword(ctx, 1, "secondUPPER1234", "thirdUPPER1234"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Only the current and one previous password are retained.
+	if err := svc.SetPassword(ctx, 1, "lowerUPPER1234"); err != nil {
+		t.Fatalf("expected password outside of history to be accepted, got %v", err)
+	}
+}
+
+func TestPasswordPolicyService_Lockout(t *testing.T) {
+	svc, kvSvc, tg, done := newPasswordPolicyService(t, influxdb.PasswordPolicy{
+		MaxFailedAttempts: 2,
+		LockoutDuration:   time.Hour,
+	})
+	defer done()
+
+	ctx := context.Background()
+	if err := svc.SetPassword(ctx, 1, "howdydoody"); err != nil {
+		t.Fatal(err)
+	}
+	tg.FakeValue = tg.FakeValue.Add(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if err := svc.ComparePassword(ctx, 1, "wrongpassword"); err != kv.EIncorrectPassword {
+			t.Fatalf("expected incorrect password, got %v", err)
+		}
+	}
+	if err := svc.ComparePassword(ctx, 1, "howdydoody"); err != kv.EUserLocked {
+		t.Fatalf("expected user to be locked out, got %v", err)
+	}
+
+	// The lockout expires after the lockout duration.
+	tg.FakeValue = tg.FakeValue.Add(time.Hour)
+	if err := svc.ComparePassword(ctx, 1, "howdydoody"); err != nil {
+		t.Fatalf("expected lockout to have expired, got %v", err)
+	}
+
+	// Log entries are keyed by time, so advance the clock between events.
+	tg.FakeValue = tg.FakeValue.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := svc.CompareAndSetPassword(ctx, 1, "wrongpassword", "newpassword"); err != kv.EIncorrectPassword {
+			t.Fatalf("expected incorrect password, got %v", err)
+		}
+	}
+	if err := svc.ComparePassword(ctx, 1, "howdydoody"); err != kv.EUserLocked {
+		t.Fatalf("expected user to be locked out, got %v", err)
+	}
+	tg.FakeValue = tg.FakeValue.Add(time.Minute)
+	if err := svc.UnlockUser(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ComparePassword(ctx, 1, "howdydoody"); err != nil {
+		t.Fatalf("expected user to be unlocked, got %v", err)
+	}
+
+	log, _, err := kvSvc.GetUserOperationLog(ctx, 1, influxdb.FindOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var locked, unlocked int
+	for _, e := range log {
+		switch e.Description {
+		case "User Locked":
+			locked++
+		case "User Unlocked":
+			unlocked++
+		}
+	}
+	if locked != 2 || unlocked != 1 {
+		t.Errorf("unexpected lockout events: got %d locked and %d unlocked", locked, unlocked)
+	}
+}
+
+func TestPasswordPolicyService_Tenant(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new bolt kv store: %v", err)
+	}
+	defer closeStore()
+
+	ctx := context.Background()
+	kvSvc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := kvSvc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+	ts, err := tenant.NewStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenantSvc := tenant.NewService(ts)
+	u := &influxdb.User{Name: "user1"}
+	if err := tenantSvc.CreateUser(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := kv.NewPasswordPolicyService(kvSvc, tenantSvc, influxdb.PasswordPolicy{
+		MinCharacterClasses: 2,
+		MaxFailedAttempts:   1,
+	})
+	if err := svc.SetPassword(ctx, u.ID, "alllowercase"); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a password of one character class to be invalid, got %v", err)
+	}
+	if err := svc.SetPassword(ctx, u.ID, "lowerUPPER"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ComparePassword(ctx, u.ID, "wrongpassword"); err != tenant.EIncorrectPassword {
+		t.Fatalf("expected incorrect password, got %v", err)
+	}
+	if err := svc.ComparePassword(ctx, u.ID, "lowerUPPER"); err != kv.EUserLocked {
+		t.Fatalf("expected user to be locked out, got %v", err)
+	}
+}
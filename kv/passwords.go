@@ -135,7 +135,7 @@ func (s *Service) setPassword(ctx context.Context, tx Tx, userID influxdb.ID, pa
 	if err := b.Put(encodedID, hash); err != nil {
 		return UnavailablePasswordServiceError(err)
 	}
-	return nil
+	return s.appendUserEventToLog(ctx, tx, userID, userPasswordUpdatedEvent)
 }
 
 func (s *Service) comparePassword(ctx context.Context, tx Tx, userID influxdb.ID, password string) error {
@@ -0,0 +1,316 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	queryPoliciesBucket          = []byte("querypoliciesv1")
+	queryPolicyEvaluationsBucket = []byte("querypolicyevaluationsv1")
+)
+
+var _ influxdb.QueryPolicyService = (*Service)(nil)
+
+func (s *Service) initializeQueryPolicies(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(queryPoliciesBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(queryPolicyEvaluationsBucket)
+		return err
+	})
+}
+
+// FindQueryPolicyByID returns a single policy by ID.
+func (s *Service) FindQueryPolicyByID(ctx context.Context, id influxdb.ID) (*influxdb.QueryPolicy, error) {
+	var p *influxdb.QueryPolicy
+	err := s.kv.View(ctx, func(tx Tx) error {
+		qp, err := s.findQueryPolicyByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		p = qp
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindQueryPolicyByID,
+			Err: err,
+		}
+	}
+	return p, nil
+}
+
+func (s *Service) findQueryPolicyByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.QueryPolicy, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(queryPoliciesBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "query policy not found",
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p influxdb.QueryPolicy
+	if err := json.Unmarshal(v, &p); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &p, nil
+}
+
+// FindQueryPolicies returns the policies matching filter, oldest first.
+func (s *Service) FindQueryPolicies(ctx context.Context, filter influxdb.QueryPolicyFilter) ([]*influxdb.QueryPolicy, error) {
+	ps := []*influxdb.QueryPolicy{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(queryPoliciesBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			var p influxdb.QueryPolicy
+			if err := json.Unmarshal(v, &p); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+			if filter.Match(&p) {
+				ps = append(ps, &p)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindQueryPolicies,
+			Err: err,
+		}
+	}
+	sort.SliceStable(ps, func(i, j int) bool {
+		return ps[i].CreatedAt.Before(ps[j].CreatedAt)
+	})
+	return ps, nil
+}
+
+// CreateQueryPolicy creates a policy, setting its ID and creation time.
+func (s *Service) CreateQueryPolicy(ctx context.Context, p *influxdb.QueryPolicy) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := p.Valid(); err != nil {
+			return err
+		}
+		if p.OrgID != nil {
+			if _, err := s.findOrganizationByID(ctx, tx, *p.OrgID); err != nil {
+				return err
+			}
+		}
+
+		p.ID = s.IDGenerator.ID()
+		p.CreatedAt = s.Now()
+		p.UpdatedAt = p.CreatedAt
+		return s.putQueryPolicy(ctx, tx, p)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateQueryPolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// UpdateQueryPolicy replaces the policy with the ID of p by p, keeping its
+// creation time.
+func (s *Service) UpdateQueryPolicy(ctx context.Context, p *influxdb.QueryPolicy) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := p.Valid(); err != nil {
+			return err
+		}
+		existing, err := s.findQueryPolicyByID(ctx, tx, p.ID)
+		if err != nil {
+			return err
+		}
+		if p.OrgID != nil {
+			if _, err := s.findOrganizationByID(ctx, tx, *p.OrgID); err != nil {
+				return err
+			}
+		}
+
+		p.CreatedAt = existing.CreatedAt
+		p.UpdatedAt = s.Now()
+		return s.putQueryPolicy(ctx, tx, p)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpUpdateQueryPolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteQueryPolicy removes a policy. Its evaluations are kept.
+func (s *Service) DeleteQueryPolicy(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findQueryPolicyByID(ctx, tx, id); err != nil {
+			return err
+		}
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(queryPoliciesBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteQueryPolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putQueryPolicy(ctx context.Context, tx Tx, p *influxdb.QueryPolicy) error {
+	encodedID, err := p.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	v, err := json.Marshal(p)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(queryPoliciesBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// RecordQueryPolicyEvaluation records the evaluation of a policy applied to
+// a query, setting its ID and time.
+func (s *Service) RecordQueryPolicyEvaluation(ctx context.Context, e *influxdb.QueryPolicyEvaluation) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		e.ID = s.IDGenerator.ID()
+		e.Time = s.Now()
+
+		encodedID, err := e.ID.Encode()
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(e)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+
+		b, err := tx.Bucket(queryPolicyEvaluationsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(encodedID, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRecordQueryPolicyEvaluation,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindQueryPolicyEvaluations returns the evaluations matching filter, from
+// the oldest to the latest unless opts are descending.
+func (s *Service) FindQueryPolicyEvaluations(ctx context.Context, filter influxdb.QueryPolicyEvaluationFilter, opts ...influxdb.FindOptions) ([]*influxdb.QueryPolicyEvaluation, error) {
+	var opt influxdb.FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	es := []*influxdb.QueryPolicyEvaluation{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(queryPolicyEvaluationsBucket)
+		if err != nil {
+			return err
+		}
+
+		direction := CursorAscending
+		if opt.Descending {
+			direction = CursorDescending
+		}
+		cur, err := b.ForwardCursor(nil, WithCursorDirection(direction))
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		offset := opt.Offset
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			e := &influxdb.QueryPolicyEvaluation{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+			if !filter.Match(e) {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			es = append(es, e)
+			if opt.Limit > 0 && len(es) >= opt.Limit {
+				break
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindQueryPolicyEvaluations,
+			Err: err,
+		}
+	}
+	return es, nil
+}
@@ -0,0 +1,98 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_QueryPolicies(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing query policy service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	other := &influxdb.Organization{Name: "other"}
+	for _, o := range []*influxdb.Organization{org, other} {
+		if err := svc.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	instance := &influxdb.QueryPolicy{Name: "instance", Effect: influxdb.QueryPolicyDeny, Networks: []string{"10.0.0.0/8"}, OutsideNetworks: true}
+	orgPolicy := &influxdb.QueryPolicy{OrgID: &org.ID, Name: "exports", Effect: influxdb.QueryPolicyDeny, MaxRows: 1000}
+	otherPolicy := &influxdb.QueryPolicy{OrgID: &other.ID, Name: "other", Effect: influxdb.QueryPolicyDeny}
+	for _, p := range []*influxdb.QueryPolicy{instance, orgPolicy, otherPolicy} {
+		if err := svc.CreateQueryPolicy(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := influxdb.ID(1000)
+	if err := svc.CreateQueryPolicy(ctx, &influxdb.QueryPolicy{OrgID: &missing, Name: "p", Effect: influxdb.QueryPolicyDeny}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected a policy of a missing organization to be rejected, got %v", err)
+	}
+
+	ps, err := svc.FindQueryPolicies(ctx, influxdb.QueryPolicyFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 || ps[0].ID != instance.ID || ps[1].ID != orgPolicy.ID {
+		t.Fatalf("expected the policies of the instance and the organization, got %+v", ps)
+	}
+
+	orgPolicy.MaxRows = 10
+	if err := svc.UpdateQueryPolicy(ctx, orgPolicy); err != nil {
+		t.Fatal(err)
+	}
+	p, err := svc.FindQueryPolicyByID(ctx, orgPolicy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MaxRows != 10 || !p.CreatedAt.Equal(ps[1].CreatedAt) {
+		t.Errorf("unexpected updated policy %+v", p)
+	}
+
+	for _, e := range []*influxdb.QueryPolicyEvaluation{
+		{PolicyID: instance.ID, Effect: influxdb.QueryPolicyDeny, OrgID: org.ID},
+		{PolicyID: orgPolicy.ID, Effect: influxdb.QueryPolicyAllow, OrgID: org.ID},
+		{PolicyID: otherPolicy.ID, Effect: influxdb.QueryPolicyDeny, OrgID: other.ID},
+	} {
+		if err := svc.RecordQueryPolicyEvaluation(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := svc.DeleteQueryPolicy(ctx, instance.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindQueryPolicyByID(ctx, instance.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected a deleted policy to be not found, got %v", err)
+	}
+
+	deny := influxdb.QueryPolicyDeny
+	es, err := svc.FindQueryPolicyEvaluations(ctx, influxdb.QueryPolicyEvaluationFilter{OrgID: &org.ID, Effect: &deny})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 1 || es[0].PolicyID != instance.ID {
+		t.Errorf("expected the evaluations of a deleted policy to be kept, got %+v", es)
+	}
+	es, err = svc.FindQueryPolicyEvaluations(ctx, influxdb.QueryPolicyEvaluationFilter{}, influxdb.FindOptions{Limit: 1, Descending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(es) != 1 || es[0].PolicyID != otherPolicy.ID {
+		t.Errorf("expected the latest evaluation, got %+v", es)
+	}
+}
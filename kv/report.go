@@ -0,0 +1,271 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	reportBucket = []byte("reportsv1")
+)
+
+var _ influxdb.ReportService = (*Service)(nil)
+
+func (s *Service) initializeReports(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(reportBucket)
+		return err
+	})
+}
+
+// FindReportByID returns a single report by ID.
+func (s *Service) FindReportByID(ctx context.Context, id influxdb.ID) (*influxdb.Report, error) {
+	var r *influxdb.Report
+	err := s.kv.View(ctx, func(tx Tx) error {
+		report, err := s.findReportByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		r = report
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindReportByID,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) findReportByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Report, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(reportBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrReportNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r influxdb.Report
+	if err := json.Unmarshal(v, &r); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &r, nil
+}
+
+// FindReports returns a list of reports that match filter.
+func (s *Service) FindReports(ctx context.Context, filter influxdb.ReportFilter) ([]*influxdb.Report, error) {
+	rs := []*influxdb.Report{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachReport(ctx, tx, func(r *influxdb.Report) bool {
+			if filter.Match(r) {
+				rs = append(rs, r)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindReports,
+			Err: err,
+		}
+	}
+	return rs, nil
+}
+
+// forEachReport will iterate through all reports while fn returns true.
+func (s *Service) forEachReport(ctx context.Context, tx Tx, fn func(*influxdb.Report) bool) error {
+	b, err := tx.Bucket(reportBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		r := &influxdb.Report{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return err
+		}
+		if !fn(r) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// CreateReport creates a new report and sets r.ID with the new identifier.
+func (s *Service) CreateReport(ctx context.Context, r *influxdb.Report) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := s.validReport(ctx, tx, r); err != nil {
+			return err
+		}
+		r.ID = s.IDGenerator.ID()
+		r.Status = influxdb.ReportStatus{}
+		r.SetCreatedAt(s.Now())
+		r.SetUpdatedAt(s.Now())
+		return s.putReport(ctx, tx, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateReport,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// validReport returns an error if r is invalid, or if its authorization or
+// dashboard do not belong to its organization.
+func (s *Service) validReport(ctx context.Context, tx Tx, r *influxdb.Report) error {
+	if err := r.Valid(); err != nil {
+		return err
+	}
+
+	a, err := s.findAuthorizationByID(ctx, tx, r.AuthorizationID)
+	if err != nil {
+		return err
+	}
+	if a.OrgID != r.OrgID {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "authorization does not belong to the organization of the report",
+		}
+	}
+
+	if r.DashboardID.Valid() {
+		d, err := s.findDashboardByID(ctx, tx, r.DashboardID)
+		if err != nil {
+			return err
+		}
+		if d.OrganizationID != r.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "dashboard does not belong to the organization of the report",
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateReport updates a single report with changeset.
+func (s *Service) UpdateReport(ctx context.Context, id influxdb.ID, upd influxdb.ReportUpdate) (*influxdb.Report, error) {
+	var r *influxdb.Report
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		report, err := s.findReportByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		upd.Apply(report)
+		if err := s.validReport(ctx, tx, report); err != nil {
+			return err
+		}
+		report.SetUpdatedAt(s.Now())
+		r = report
+		return s.putReport(ctx, tx, report)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateReport,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+// UpdateReportStatus replaces the status of a report.
+func (s *Service) UpdateReportStatus(ctx context.Context, id influxdb.ID, status influxdb.ReportStatus) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		r, err := s.findReportByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		r.Status = status
+		return s.putReport(ctx, tx, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpUpdateReportStatus,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putReport(ctx context.Context, tx Tx, r *influxdb.Report) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := r.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(reportBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// DeleteReport removes a report by ID.
+func (s *Service) DeleteReport(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findReportByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(reportBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteReport,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	resourceGrantBucket = []byte("resourcegrantsv1")
+)
+
+var _ influxdb.ResourceGrantService = (*Service)(nil)
+
+func (s *Service) initializeResourceGrants(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(resourceGrantBucket)
+		return err
+	})
+}
+
+// FindResourceGrantByID returns a single resource grant by ID.
+func (s *Service) FindResourceGrantByID(ctx context.Context, id influxdb.ID) (*influxdb.ResourceGrant, error) {
+	var g *influxdb.ResourceGrant
+	err := s.kv.View(ctx, func(tx Tx) error {
+		grant, err := s.findResourceGrantByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		g = grant
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindResourceGrantByID,
+			Err: err,
+		}
+	}
+	return g, nil
+}
+
+func (s *Service) findResourceGrantByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.ResourceGrant, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(resourceGrantBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrResourceGrantNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var g influxdb.ResourceGrant
+	if err := json.Unmarshal(v, &g); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &g, nil
+}
+
+// FindResourceGrants returns a list of resource grants that match filter
+// and the total count of matching resource grants.
+func (s *Service) FindResourceGrants(ctx context.Context, filter influxdb.ResourceGrantFilter, opt ...influxdb.FindOptions) ([]*influxdb.ResourceGrant, int, error) {
+	gs := []*influxdb.ResourceGrant{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachResourceGrant(ctx, tx, func(g *influxdb.ResourceGrant) bool {
+			if filter.Match(g) {
+				gs = append(gs, g)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindResourceGrants,
+			Err: err,
+		}
+	}
+
+	n := len(gs)
+	if len(opt) > 0 {
+		if o := opt[0].Offset; o > 0 {
+			if o > len(gs) {
+				o = len(gs)
+			}
+			gs = gs[o:]
+		}
+		if l := opt[0].Limit; l > 0 && l < len(gs) {
+			gs = gs[:l]
+		}
+	}
+	return gs, n, nil
+}
+
+// forEachResourceGrant will iterate through all resource grants while fn returns true.
+func (s *Service) forEachResourceGrant(ctx context.Context, tx Tx, fn func(*influxdb.ResourceGrant) bool) error {
+	b, err := tx.Bucket(resourceGrantBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		g := &influxdb.ResourceGrant{}
+		if err := json.Unmarshal(v, g); err != nil {
+			return err
+		}
+		if !fn(g) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// CreateResourceGrant creates a new resource grant and sets g.ID with
+// the new identifier.
+func (s *Service) CreateResourceGrant(ctx context.Context, g *influxdb.ResourceGrant) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.createResourceGrant(ctx, tx, g)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateResourceGrant,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) createResourceGrant(ctx context.Context, tx Tx, g *influxdb.ResourceGrant) error {
+	if err := g.Valid(); err != nil {
+		return err
+	}
+
+	// The shared resource must belong to the organization of the grant.
+	var ownerID influxdb.ID
+	switch g.ResourceType {
+	case influxdb.BucketsResourceType:
+		b, err := s.findBucketByID(ctx, tx, g.ResourceID)
+		if err != nil {
+			return err
+		}
+		ownerID = b.OrgID
+	case influxdb.DashboardsResourceType:
+		d, err := s.findDashboardByID(ctx, tx, g.ResourceID)
+		if err != nil {
+			return err
+		}
+		ownerID = d.OrganizationID
+	}
+	if ownerID != g.OrgID {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "resource does not belong to the organization of the grant",
+		}
+	}
+
+	if _, err := s.findOrganizationByID(ctx, tx, g.GranteeOrgID); err != nil {
+		return err
+	}
+
+	var exists bool
+	err := s.forEachResourceGrant(ctx, tx, func(e *influxdb.ResourceGrant) bool {
+		exists = e.ResourceType == g.ResourceType && e.ResourceID == g.ResourceID && e.GranteeOrgID == g.GranteeOrgID
+		return !exists
+	})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "resource is already shared with the organization",
+		}
+	}
+
+	g.ID = s.IDGenerator.ID()
+	g.SetCreatedAt(s.Now())
+	g.SetUpdatedAt(s.Now())
+	return s.putResourceGrant(ctx, tx, g)
+}
+
+func (s *Service) putResourceGrant(ctx context.Context, tx Tx, g *influxdb.ResourceGrant) error {
+	v, err := json.Marshal(g)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := g.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(resourceGrantBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// DeleteResourceGrant removes a resource grant by ID.
+func (s *Service) DeleteResourceGrant(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findResourceGrantByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(resourceGrantBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteResourceGrant,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,582 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	roleBucket       = []byte("rolesv1")
+	roleMemberBucket = []byte("rolemembersv1")
+)
+
+var _ influxdb.RoleService = (*Service)(nil)
+
+func (s *Service) initializeRoles(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(roleBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(roleMemberBucket)
+		return err
+	})
+}
+
+// FindRoleByID returns a single role by ID.
+func (s *Service) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.kv.View(ctx, func(tx Tx) error {
+		role, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		r = role
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindRoleByID,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) findRoleByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Role, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrRoleNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r influxdb.Role
+	if err := json.Unmarshal(v, &r); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &r, nil
+}
+
+// FindRoles returns a list of roles that match filter and the total count of matching roles.
+func (s *Service) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	var rs []*influxdb.Role
+	err := s.kv.View(ctx, func(tx Tx) error {
+		roles, err := s.findRoles(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		rs = roles
+		return nil
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindRoles,
+			Err: err,
+		}
+	}
+
+	n := len(rs)
+	if len(opt) > 0 {
+		if o := opt[0].Offset; o > 0 {
+			if o > len(rs) {
+				o = len(rs)
+			}
+			rs = rs[o:]
+		}
+		if l := opt[0].Limit; l > 0 && l < len(rs) {
+			rs = rs[:l]
+		}
+	}
+	return rs, n, nil
+}
+
+func (s *Service) findRoles(ctx context.Context, tx Tx, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+	var memberOf map[influxdb.ID]bool
+	if filter.UserID != nil {
+		ids, err := s.findUserRoleIDs(ctx, tx, *filter.UserID)
+		if err != nil {
+			return nil, err
+		}
+		memberOf = make(map[influxdb.ID]bool, len(ids))
+		for _, id := range ids {
+			memberOf[id] = true
+		}
+	}
+
+	rs := []*influxdb.Role{}
+	err := s.forEachRole(ctx, tx, func(r *influxdb.Role) bool {
+		if (filter.ID == nil || *filter.ID == r.ID) &&
+			(filter.OrgID == nil || *filter.OrgID == r.OrgID) &&
+			(filter.Name == nil || *filter.Name == r.Name) &&
+			(memberOf == nil || memberOf[r.ID]) {
+			rs = append(rs, r)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// forEachRole will iterate through all roles while fn returns true.
+func (s *Service) forEachRole(ctx context.Context, tx Tx, fn func(*influxdb.Role) bool) error {
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		r := &influxdb.Role{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return err
+		}
+		if !fn(r) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// CreateRole creates a new role and sets r.ID with the new identifier.
+func (s *Service) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := r.Valid(); err != nil {
+			return err
+		}
+		if _, err := s.findOrganizationByID(ctx, tx, r.OrgID); err != nil {
+			return err
+		}
+		if err := s.uniqueRoleName(ctx, tx, r); err != nil {
+			return err
+		}
+
+		r.ID = s.IDGenerator.ID()
+		r.SetCreatedAt(s.Now())
+		r.SetUpdatedAt(s.Now())
+		return s.putRole(ctx, tx, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateRole,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) uniqueRoleName(ctx context.Context, tx Tx, r *influxdb.Role) error {
+	rs, err := s.findRoles(ctx, tx, influxdb.RoleFilter{OrgID: &r.OrgID, Name: &r.Name})
+	if err != nil {
+		return err
+	}
+	for _, e := range rs {
+		if e.ID != r.ID {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "role with name " + r.Name + " already exists",
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) putRole(ctx context.Context, tx Tx, r *influxdb.Role) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := r.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// UpdateRole updates a single role with changeset.
+func (s *Service) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		role, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := upd.Apply(role); err != nil {
+			return err
+		}
+		if err := s.uniqueRoleName(ctx, tx, role); err != nil {
+			return err
+		}
+
+		role.SetUpdatedAt(s.Now())
+		r = role
+		return s.putRole(ctx, tx, role)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateRole,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+// DeleteRole removes a role by ID along with its memberships.
+func (s *Service) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.deleteRole(ctx, tx, id)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteRole,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteRole(ctx context.Context, tx Tx, id influxdb.ID) error {
+	if _, err := s.findRoleByID(ctx, tx, id); err != nil {
+		return err
+	}
+
+	members, err := s.findRoleMembers(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	for _, userID := range members {
+		if err := s.removeRoleMember(ctx, tx, id, userID); err != nil {
+			return err
+		}
+	}
+
+	encodedID, err := id.Encode()
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(encodedID)
+}
+
+// deleteOrganizationsRoles removes all roles of the organization.
+func (s *Service) deleteOrganizationsRoles(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	rs, err := s.findRoles(ctx, tx, influxdb.RoleFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		if err := s.deleteRole(ctx, tx, r.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func roleMemberKey(roleID, userID influxdb.ID) ([]byte, error) {
+	rk, err := roleID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	uk, err := userID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(rk, uk...), nil
+}
+
+// AddRoleMember grants the permissions of the role to the user.
+func (s *Service) AddRoleMember(ctx context.Context, roleID, userID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findRoleByID(ctx, tx, roleID); err != nil {
+			return err
+		}
+		if _, err := s.findUserByID(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		key, err := roleMemberKey(roleID, userID)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(roleMemberBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, key[influxdb.IDLength:])
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpAddRoleMember,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// RemoveRoleMember revokes the permissions of the role from the user.
+func (s *Service) RemoveRoleMember(ctx context.Context, roleID, userID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.removeRoleMember(ctx, tx, roleID, userID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRemoveRoleMember,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) removeRoleMember(ctx context.Context, tx Tx, roleID, userID influxdb.ID) error {
+	key, err := roleMemberKey(roleID, userID)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(roleMemberBucket)
+	if err != nil {
+		return err
+	}
+	if _, err := b.Get(key); IsNotFound(err) {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "user is not a member of the role",
+		}
+	} else if err != nil {
+		return err
+	}
+	return b.Delete(key)
+}
+
+// FindRoleMembers returns the IDs of the users that are members of the role.
+func (s *Service) FindRoleMembers(ctx context.Context, roleID influxdb.ID) ([]influxdb.ID, error) {
+	var ids []influxdb.ID
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if _, err := s.findRoleByID(ctx, tx, roleID); err != nil {
+			return err
+		}
+		members, err := s.findRoleMembers(ctx, tx, roleID)
+		if err != nil {
+			return err
+		}
+		ids = members
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindRoleMembers,
+			Err: err,
+		}
+	}
+	return ids, nil
+}
+
+func (s *Service) findRoleMembers(ctx context.Context, tx Tx, roleID influxdb.ID) ([]influxdb.ID, error) {
+	prefix, err := roleID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(roleMemberBucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	ids := []influxdb.ID{}
+	for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+		var id influxdb.ID
+		if err := id.Decode(k[len(prefix):]); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, cur.Err()
+}
+
+// findUserRoleIDs returns the IDs of the roles the user is a member of.
+func (s *Service) findUserRoleIDs(ctx context.Context, tx Tx, userID influxdb.ID) ([]influxdb.ID, error) {
+	b, err := tx.Bucket(roleMemberBucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	var ids []influxdb.ID
+	for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+		var roleID, memberID influxdb.ID
+		if err := memberID.Decode(k[influxdb.IDLength:]); err != nil {
+			return nil, err
+		}
+		if memberID != userID {
+			continue
+		}
+		if err := roleID.Decode(k[:influxdb.IDLength]); err != nil {
+			return nil, err
+		}
+		ids = append(ids, roleID)
+	}
+	return ids, cur.Err()
+}
+
+// deleteUsersRoleMemberships removes the user from all roles.
+func (s *Service) deleteUsersRoleMemberships(ctx context.Context, tx Tx, userID influxdb.ID) error {
+	ids, err := s.findUserRoleIDs(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	for _, roleID := range ids {
+		if err := s.removeRoleMember(ctx, tx, roleID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rolePermissions returns the permissions granted to the user by its roles.
+func (s *Service) rolePermissions(ctx context.Context, tx Tx, userID influxdb.ID) ([]influxdb.Permission, error) {
+	rs, err := s.findRoles(ctx, tx, influxdb.RoleFilter{UserID: &userID})
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []influxdb.Permission
+	for _, r := range rs {
+		ps = append(ps, r.Permissions...)
+	}
+	return ps, nil
+}
+
+// isRoleMember returns true if the user is a member of the role.
+func (s *Service) isRoleMember(ctx context.Context, tx Tx, roleID, userID influxdb.ID) (bool, error) {
+	key, err := roleMemberKey(roleID, userID)
+	if err != nil {
+		return false, err
+	}
+	b, err := tx.Bucket(roleMemberBucket)
+	if err != nil {
+		return false, err
+	}
+	if _, err := b.Get(key); err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// validateAuthorizationRoles ensures the roles of an authorization belong to
+// its organization, and that its user is a member of each of them, so that
+// the authorization cannot grant more than the user has been granted.
+func (s *Service) validateAuthorizationRoles(ctx context.Context, tx Tx, a *influxdb.Authorization) error {
+	for _, id := range a.RoleIDs {
+		r, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if r.OrgID != a.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("role %s is not for org id %s", id, a.OrgID),
+			}
+		}
+		ok, err := s.isRoleMember(ctx, tx, id, a.UserID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Msg:  fmt.Sprintf("user %s is not a member of role %s", a.UserID, id),
+			}
+		}
+	}
+	return nil
+}
+
+// resolveAuthorizationRoles appends the permissions of the roles of an
+// authorization to its permissions. Roles that have been deleted, or that
+// the user of the authorization is no longer a member of, grant nothing.
+func (s *Service) resolveAuthorizationRoles(ctx context.Context, tx Tx, a *influxdb.Authorization) error {
+	for _, id := range a.RoleIDs {
+		ok, err := s.isRoleMember(ctx, tx, id, a.UserID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		r, err := s.findRoleByID(ctx, tx, id)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		a.Permissions = append(a.Permissions, r.Permissions...)
+	}
+	return nil
+}
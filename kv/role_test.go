@@ -0,0 +1,211 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_Roles(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing role service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	other := &influxdb.Organization{Name: "other"}
+	for _, o := range []*influxdb.Organization{org, other} {
+		if err := svc.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := &influxdb.User{Name: "user", Status: influxdb.Active}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	readBuckets := influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &org.ID},
+	}
+	otherBuckets := influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &other.ID},
+	}
+
+	err = svc.CreateRole(ctx, &influxdb.Role{OrgID: org.ID, Name: "escalate", Permissions: []influxdb.Permission{otherBuckets}})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected permission of another organization to be rejected, got %v", err)
+	}
+
+	role := &influxdb.Role{OrgID: org.ID, Name: "reader", Permissions: []influxdb.Permission{readBuckets}}
+	if err := svc.CreateRole(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateRole(ctx, &influxdb.Role{OrgID: org.ID, Name: "reader"}); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected duplicate role name to conflict, got %v", err)
+	}
+
+	sessionAllowed := func() bool {
+		t.Helper()
+		sn, err := svc.CreateSession(ctx, user.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sn, err = svc.FindSession(ctx, sn.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sn.Allowed(readBuckets)
+	}
+
+	if sessionAllowed() {
+		t.Fatal("expected user without role to be denied")
+	}
+	if err := svc.AddRoleMember(ctx, role.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if !sessionAllowed() {
+		t.Fatal("expected role member to be granted the permissions of the role")
+	}
+
+	rs, _, err := svc.FindRoles(ctx, influxdb.RoleFilter{UserID: &user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].ID != role.ID {
+		t.Fatalf("unexpected roles of user: %v", rs)
+	}
+
+	if err := svc.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	members, err := svc.FindRoleMembers(ctx, role.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 0 {
+		t.Fatalf("expected deleted user to be removed from role, got %v", members)
+	}
+
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindRoleByID(ctx, role.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected role to be deleted with its organization, got %v", err)
+	}
+}
+
+func TestService_AuthorizationRoles(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing role service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	other := &influxdb.Organization{Name: "other"}
+	for _, o := range []*influxdb.Organization{org, other} {
+		if err := svc.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := &influxdb.User{Name: "user", Status: influxdb.Active}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	readBuckets := influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &org.ID},
+	}
+	writeBuckets := influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &org.ID},
+	}
+
+	role := &influxdb.Role{OrgID: org.ID, Name: "reader", Permissions: []influxdb.Permission{readBuckets}}
+	otherRole := &influxdb.Role{OrgID: other.ID, Name: "reader"}
+	for _, r := range []*influxdb.Role{role, otherRole} {
+		if err := svc.CreateRole(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := &influxdb.Authorization{OrgID: org.ID, UserID: user.ID, RoleIDs: []influxdb.ID{role.ID}}
+	if err := svc.CreateAuthorization(ctx, a); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected role the user is not a member of to be rejected, got %v", err)
+	}
+	if err := svc.AddRoleMember(ctx, otherRole.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	a.RoleIDs = []influxdb.ID{otherRole.ID}
+	if err := svc.CreateAuthorization(ctx, a); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected role of another organization to be rejected, got %v", err)
+	}
+
+	if err := svc.AddRoleMember(ctx, role.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	a.RoleIDs = []influxdb.ID{role.ID}
+	if err := svc.CreateAuthorization(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenAllowed := func(p influxdb.Permission) bool {
+		t.Helper()
+		auth, err := svc.FindAuthorizationByToken(ctx, a.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return auth.Allowed(p)
+	}
+
+	if !tokenAllowed(readBuckets) {
+		t.Fatal("expected authorization to be granted the permissions of its role")
+	}
+	if tokenAllowed(writeBuckets) {
+		t.Fatal("expected authorization to be denied permissions not granted by its role")
+	}
+
+	// Changes to the role apply to the existing authorization.
+	ps := []influxdb.Permission{readBuckets, writeBuckets}
+	if _, err := svc.UpdateRole(ctx, role.ID, influxdb.RoleUpdate{Permissions: &ps}); err != nil {
+		t.Fatal(err)
+	}
+	if !tokenAllowed(writeBuckets) {
+		t.Fatal("expected authorization to be granted the updated permissions of its role")
+	}
+
+	// The stored authorization only has its own permissions.
+	stored, err := svc.FindAuthorizationByID(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Permissions) != 0 {
+		t.Fatalf("expected role permissions not to be stored, got %v", stored.Permissions)
+	}
+
+	if err := svc.RemoveRoleMember(ctx, role.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if tokenAllowed(readBuckets) {
+		t.Fatal("expected authorization to be denied the permissions of a role its user was removed from")
+	}
+}
@@ -0,0 +1,306 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+var (
+	scriptRevisionBucket      = []byte("scriptrevisionsv1")
+	scriptRevisionIndexBucket = []byte("scriptrevisionindexv1")
+)
+
+var _ influxdb.ScriptRevisionService = (*Service)(nil)
+
+func (s *Service) initializeScriptRevisions(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(scriptRevisionBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(scriptRevisionIndexBucket)
+		return err
+	})
+}
+
+// FindScriptRevisionByID returns a single script revision by ID.
+func (s *Service) FindScriptRevisionByID(ctx context.Context, id influxdb.ID) (*influxdb.ScriptRevision, error) {
+	var rev *influxdb.ScriptRevision
+	err := s.kv.View(ctx, func(tx Tx) error {
+		r, err := s.findScriptRevisionByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		rev = r
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindScriptRevisionByID,
+			Err: err,
+		}
+	}
+	return rev, nil
+}
+
+func (s *Service) findScriptRevisionByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.ScriptRevision, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(scriptRevisionBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrScriptRevisionNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rev influxdb.ScriptRevision
+	if err := json.Unmarshal(v, &rev); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &rev, nil
+}
+
+// FindScriptRevisions returns the revisions of the script of a resource, from
+// the oldest to the latest.
+func (s *Service) FindScriptRevisions(ctx context.Context, filter influxdb.ScriptRevisionFilter) ([]*influxdb.ScriptRevision, error) {
+	revs := []*influxdb.ScriptRevision{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		ids, err := s.scriptRevisionIDs(ctx, tx, filter.ResourceID)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			rev, err := s.findScriptRevisionByID(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			revs = append(revs, rev)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindScriptRevisions,
+			Err: err,
+		}
+	}
+	return revs, nil
+}
+
+// scriptRevisionIndexKey returns the key of the index of the revision of
+// resourceID with version, or the prefix of all its revisions if version is
+// zero. Revisions are indexed in the order of their versions.
+func scriptRevisionIndexKey(resourceID influxdb.ID, version int) ([]byte, error) {
+	encodedID, err := resourceID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	if version == 0 {
+		return encodedID, nil
+	}
+	key := make([]byte, len(encodedID)+8)
+	copy(key, encodedID)
+	binary.BigEndian.PutUint64(key[len(encodedID):], uint64(version))
+	return key, nil
+}
+
+// scriptRevisionIDs returns the IDs of the revisions of resourceID, from the
+// oldest to the latest.
+func (s *Service) scriptRevisionIDs(ctx context.Context, tx Tx, resourceID influxdb.ID) ([]influxdb.ID, error) {
+	prefix, err := scriptRevisionIndexKey(resourceID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := tx.Bucket(scriptRevisionIndexBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := idx.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	var ids []influxdb.ID
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		var id influxdb.ID
+		if err := id.Decode(v); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, cur.Err()
+}
+
+// recordScriptRevision adds a revision of the script of a resource unless
+// its latest revision has the same script, and removes the revisions beyond
+// influxdb.MaxScriptRevisions.
+func (s *Service) recordScriptRevision(ctx context.Context, tx Tx, rt influxdb.ResourceType, resourceID, orgID influxdb.ID, script string) error {
+	ids, err := s.scriptRevisionIDs(ctx, tx, resourceID)
+	if err != nil {
+		return err
+	}
+
+	version := 1
+	if len(ids) > 0 {
+		latest, err := s.findScriptRevisionByID(ctx, tx, ids[len(ids)-1])
+		if err != nil {
+			return err
+		}
+		if latest.Script == script {
+			return nil
+		}
+		version = latest.Version + 1
+	}
+
+	uid, _ := icontext.GetUserID(ctx)
+	rev := &influxdb.ScriptRevision{
+		ID:           s.IDGenerator.ID(),
+		ResourceID:   resourceID,
+		ResourceType: rt,
+		OrgID:        orgID,
+		Version:      version,
+		Script:       script,
+		UserID:       uid,
+		CreatedAt:    s.Now(),
+	}
+	if err := s.putScriptRevision(ctx, tx, rev); err != nil {
+		return err
+	}
+
+	ids = append(ids, rev.ID)
+	for len(ids) > influxdb.MaxScriptRevisions {
+		if err := s.deleteScriptRevision(ctx, tx, ids[0]); err != nil {
+			return err
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
+
+func (s *Service) putScriptRevision(ctx context.Context, tx Tx, rev *influxdb.ScriptRevision) error {
+	encodedID, err := rev.ID.Encode()
+	if err != nil {
+		return err
+	}
+	key, err := scriptRevisionIndexKey(rev.ResourceID, rev.Version)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(rev)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(scriptRevisionBucket)
+	if err != nil {
+		return err
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(scriptRevisionIndexBucket)
+	if err != nil {
+		return err
+	}
+	return idx.Put(key, encodedID)
+}
+
+func (s *Service) deleteScriptRevision(ctx context.Context, tx Tx, id influxdb.ID) error {
+	rev, err := s.findScriptRevisionByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	encodedID, err := id.Encode()
+	if err != nil {
+		return err
+	}
+	key, err := scriptRevisionIndexKey(rev.ResourceID, rev.Version)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(scriptRevisionBucket)
+	if err != nil {
+		return err
+	}
+	if err := b.Delete(encodedID); err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(scriptRevisionIndexBucket)
+	if err != nil {
+		return err
+	}
+	return idx.Delete(key)
+}
+
+// deleteScriptRevisions removes all the revisions of a deleted resource.
+func (s *Service) deleteScriptRevisions(ctx context.Context, tx Tx, resourceID influxdb.ID) error {
+	ids, err := s.scriptRevisionIDs(ctx, tx, resourceID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.deleteScriptRevision(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definitionScript returns the script of the revisions of a check or
+// notification rule: its definition as indented JSON, without the times of
+// its creation and update, so that revisions only differ by their changes.
+func definitionScript(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	var m map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return "", err
+	}
+	delete(m, "createdAt")
+	delete(m, "updatedAt")
+
+	b, err = json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
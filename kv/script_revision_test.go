@@ -0,0 +1,90 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_ScriptRevisions(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing script revision service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	v1 := `option task = {name: "t", every: 1h}
+from(bucket: "b") |> range(start: -1h) |> yield()`
+	task, err := svc.CreateTask(ctx, influxdb.TaskCreate{
+		Type:           influxdb.TaskSystemType,
+		OrganizationID: org.ID,
+		Flux:           v1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := `option task = {name: "t", every: 1h}
+from(bucket: "b") |> range(start: -2h) |> yield()`
+	if _, err := svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Flux: &v2}); err != nil {
+		t.Fatal(err)
+	}
+	// Updates which do not change the script do not add revisions.
+	desc := "description"
+	if _, err := svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Description: &desc}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Flux: &v2}); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, err := svc.FindScriptRevisions(ctx, influxdb.ScriptRevisionFilter{ResourceID: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(revs))
+	}
+	for i, want := range []string{v1, v2} {
+		rev := revs[i]
+		if rev.Version != i+1 || rev.Script != want || rev.ResourceType != influxdb.TasksResourceType || rev.OrgID != org.ID {
+			t.Fatalf("unexpected revision %d: %+v", i, rev)
+		}
+	}
+
+	rev, err := svc.FindScriptRevisionByID(ctx, revs[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Script != v1 {
+		t.Fatalf("expected first script, got %q", rev.Script)
+	}
+
+	if err := svc.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	revs, err = svc.FindScriptRevisions(ctx, influxdb.ScriptRevisionFilter{ResourceID: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 0 {
+		t.Fatalf("expected the revisions of the deleted task to be removed, got %d", len(revs))
+	}
+	if _, err := svc.FindScriptRevisionByID(ctx, rev.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected revision to be removed, got %v", err)
+	}
+}
@@ -48,6 +48,8 @@ type Service struct {
 
 	urmByUserIndex *Index
 
+	bucketDeleteHooks []BucketDeleteHook
+
 	disableAuthorizationsForMaxPermissions func(context.Context) bool
 }
 
@@ -99,6 +101,203 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 		),
 		// add index user resource mappings by user id
 		s.urmByUserIndex.Migration(),
+		// add password history and lockout buckets
+		NewAnonymousMigration(
+			"create password history and lockout buckets",
+			s.initializePasswordPolicy,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add resource grants bucket
+		NewAnonymousMigration(
+			"create resource grants bucket",
+			s.initializeResourceGrants,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add roles buckets
+		NewAnonymousMigration(
+			"create roles and role members buckets",
+			s.initializeRoles,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add signed query key
+		NewAnonymousMigration(
+			"create query signing key",
+			s.initializeSignedQueries,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add organization deletions bucket
+		NewAnonymousMigration(
+			"create organization deletions bucket",
+			s.initializeOrgDeletions,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add lifecycle policies bucket
+		NewAnonymousMigration(
+			"create lifecycle policies bucket",
+			s.initializeLifecyclePolicies,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add webhooks and webhook deliveries buckets
+		NewAnonymousMigration(
+			"create webhooks buckets",
+			s.initializeWebhooks,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add http sinks bucket
+		NewAnonymousMigration(
+			"create http sinks bucket",
+			s.initializeHTTPSinks,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add script revisions buckets
+		NewAnonymousMigration(
+			"create script revisions buckets",
+			s.initializeScriptRevisions,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add alert history buckets
+		NewAnonymousMigration(
+			"create alert history buckets",
+			s.initializeAlertHistory,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add feature flag setting and change buckets
+		NewAnonymousMigration(
+			"create feature flag buckets",
+			s.initializeFeatureFlags,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add user preferences bucket
+		NewAnonymousMigration(
+			"create user preferences bucket",
+			s.initializeUserPreferences,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add smtp config bucket
+		NewAnonymousMigration(
+			"create smtp config bucket",
+			s.initializeSMTPConfig,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add slack threads bucket
+		NewAnonymousMigration(
+			"create slack threads bucket",
+			s.initializeSlackThreads,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add bucket families bucket
+		NewAnonymousMigration(
+			"create bucket families bucket",
+			s.initializeBucketFamilies,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add flux fragments buckets
+		NewAnonymousMigration(
+			"create flux fragments buckets",
+			s.initializeFluxFragments,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add limit alerts bucket
+		NewAnonymousMigration(
+			"create limit alerts bucket",
+			s.initializeLimitAlerts,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add legal holds bucket
+		NewAnonymousMigration(
+			"create legal holds bucket",
+			s.initializeLegalHolds,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add org domains bucket
+		NewAnonymousMigration(
+			"create org domains bucket",
+			s.initializeOrgDomains,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add query policies and evaluations buckets
+		NewAnonymousMigration(
+			"create query policies buckets",
+			s.initializeQueryPolicies,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		NewAnonymousMigration(
+			"create stale resources buckets",
+			s.initializeStaleResources,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
+		// add reports bucket
+		NewAnonymousMigration(
+			"create reports bucket",
+			s.initializeReports,
+			// down is a noop
+			func(context.Context, Store) error {
+				return nil
+			},
+		),
 		// and new migrations below here (and move this comment down):
 	)
 
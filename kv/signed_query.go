@@ -0,0 +1,180 @@
+package kv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	signedQueryKeyBucket = []byte("signedquerykeysv1")
+	signedQueryKey       = []byte("hmac")
+)
+
+var _ influxdb.SignedQueryService = (*Service)(nil)
+
+// initializeSignedQueries creates the key used to sign queries. The key is
+// generated once so that signatures remain valid across restarts.
+func (s *Service) initializeSignedQueries(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(signedQueryKeyBucket)
+		if err != nil {
+			return err
+		}
+		if _, err := b.Get(signedQueryKey); err == nil || !IsNotFound(err) {
+			return err
+		}
+
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+		return b.Put(signedQueryKey, key)
+	})
+}
+
+func (s *Service) signedQueryKey(ctx context.Context) ([]byte, error) {
+	var key []byte
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(signedQueryKeyBucket)
+		if err != nil {
+			return err
+		}
+		v, err := b.Get(signedQueryKey)
+		if err != nil {
+			return err
+		}
+		key = append([]byte(nil), v...)
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "unable to load query signing key",
+			Err:  err,
+		}
+	}
+	return key, nil
+}
+
+func signQueryPayload(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignQuery returns the signature of the query. The signature embeds the
+// query so it can be used in a URL on its own.
+func (s *Service) SignQuery(ctx context.Context, q *influxdb.SignedQuery) (string, error) {
+	if err := q.Valid(s.Now()); err != nil {
+		return "", &influxdb.Error{
+			Op:  influxdb.OpSignQuery,
+			Err: err,
+		}
+	}
+	if !q.UserID.Valid() {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpSignQuery,
+			Msg:  "user ID is required",
+		}
+	}
+
+	key, err := s.signedQueryKey(ctx)
+	if err != nil {
+		return "", &influxdb.Error{
+			Op:  influxdb.OpSignQuery,
+			Err: err,
+		}
+	}
+
+	v, err := json.Marshal(q)
+	if err != nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInternal,
+			Op:   influxdb.OpSignQuery,
+			Err:  err,
+		}
+	}
+	payload := base64.RawURLEncoding.EncodeToString(v)
+	return payload + "." + signQueryPayload(key, payload), nil
+}
+
+// VerifySignedQuery returns the query of a signature if the signature is
+// authentic and has not expired, and the user that signed it is still active
+// and may still read its bucket.
+func (s *Service) VerifySignedQuery(ctx context.Context, signature string) (*influxdb.SignedQuery, error) {
+	key, err := s.signedQueryKey(ctx)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpVerifySignedQuery,
+			Err: err,
+		}
+	}
+
+	parts := strings.SplitN(signature, ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(signQueryPayload(key, parts[0]))) {
+		return nil, influxdb.ErrSignedQueryInvalid
+	}
+
+	v, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, influxdb.ErrSignedQueryInvalid
+	}
+	var q influxdb.SignedQuery
+	if err := json.Unmarshal(v, &q); err != nil {
+		return nil, influxdb.ErrSignedQueryInvalid
+	}
+	if !q.ExpiresAt.After(s.Now()) {
+		return nil, influxdb.ErrSignedQueryInvalid
+	}
+
+	err = s.kv.View(ctx, func(tx Tx) error {
+		return s.verifySignedQueryUser(ctx, tx, &q)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// verifySignedQueryUser returns ErrSignedQueryInvalid unless the user that
+// signed the query is active and has the permission of the query, so that
+// deactivating the user or revoking its access revokes its signed queries.
+func (s *Service) verifySignedQueryUser(ctx context.Context, tx Tx, q *influxdb.SignedQuery) error {
+	if !q.UserID.Valid() {
+		return influxdb.ErrSignedQueryInvalid
+	}
+
+	u, err := s.findUserByID(ctx, tx, q.UserID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return influxdb.ErrSignedQueryInvalid
+	}
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpVerifySignedQuery,
+			Err: err,
+		}
+	}
+	if u.Status == influxdb.Inactive {
+		return influxdb.ErrSignedQueryInvalid
+	}
+
+	ps, err := s.maxPermissions(ctx, tx, q.UserID)
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpVerifySignedQuery,
+			Err: err,
+		}
+	}
+	if !influxdb.PermissionAllowed(q.Permission(), ps) {
+		return influxdb.ErrSignedQueryInvalid
+	}
+	return nil
+}
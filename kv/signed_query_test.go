@@ -0,0 +1,120 @@
+package kv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_SignedQuery(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tg := &mock.TimeGenerator{FakeValue: now}
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	svc.TimeGenerator = tg
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing signed query service: %v", err)
+	}
+
+	user := &influxdb.User{Name: "signer"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	urm := &influxdb.UserResourceMapping{
+		UserID:       user.ID,
+		UserType:     influxdb.Member,
+		ResourceType: influxdb.BucketsResourceType,
+		ResourceID:   2,
+	}
+	if err := svc.CreateUserResourceMapping(ctx, urm); err != nil {
+		t.Fatal(err)
+	}
+
+	q := &influxdb.SignedQuery{
+		OrgID:     1,
+		BucketID:  2,
+		Query:     `from(bucket: "b") |> range(start: -1h)`,
+		ExpiresAt: now.Add(time.Hour),
+		UserID:    user.ID,
+	}
+	signature, err := svc.SignQuery(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.VerifySignedQuery(ctx, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Query != q.Query || got.BucketID != q.BucketID || !got.ExpiresAt.Equal(q.ExpiresAt) {
+		t.Errorf("unexpected signed query: %+v", got)
+	}
+
+	// Reinitializing the service must keep the signing key.
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.VerifySignedQuery(ctx, signature); err != nil {
+		t.Errorf("expected signature to be valid after reinitializing, got %v", err)
+	}
+
+	forged, err := svc.SignQuery(ctx, &influxdb.SignedQuery{OrgID: 1, BucketID: 3, Query: q.Query, ExpiresAt: q.ExpiresAt, UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Use the payload of one signature with the MAC of another.
+	tampered := strings.SplitN(forged, ".", 2)[0] + "." + strings.SplitN(signature, ".", 2)[1]
+	for _, s := range []string{"", "garbage", tampered} {
+		if _, err := svc.VerifySignedQuery(ctx, s); err != influxdb.ErrSignedQueryInvalid {
+			t.Errorf("expected invalid signature for %q, got %v", s, err)
+		}
+	}
+
+	// Revoking the access of the user to the bucket revokes the signature.
+	if err := svc.DeleteUserResourceMapping(ctx, urm.ResourceID, urm.UserID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.VerifySignedQuery(ctx, signature); err != influxdb.ErrSignedQueryInvalid {
+		t.Errorf("expected signature of a user without access to be invalid, got %v", err)
+	}
+	if err := svc.CreateUserResourceMapping(ctx, urm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.VerifySignedQuery(ctx, signature); err != nil {
+		t.Errorf("expected signature to be valid once access is restored, got %v", err)
+	}
+
+	// So does deactivating the user.
+	inactive := influxdb.Inactive
+	if _, err := svc.UpdateUser(ctx, user.ID, influxdb.UserUpdate{Status: &inactive}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.VerifySignedQuery(ctx, signature); err != influxdb.ErrSignedQueryInvalid {
+		t.Errorf("expected signature of an inactive user to be invalid, got %v", err)
+	}
+
+	if _, err := svc.SignQuery(ctx, &influxdb.SignedQuery{OrgID: 1, BucketID: 2, Query: q.Query, ExpiresAt: q.ExpiresAt}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a query without a user to be rejected, got %v", err)
+	}
+
+	tg.FakeValue = now.Add(2 * time.Hour)
+	if _, err := svc.VerifySignedQuery(ctx, signature); err != influxdb.ErrSignedQueryInvalid {
+		t.Errorf("expected expired signature to be invalid, got %v", err)
+	}
+
+	if _, err := svc.SignQuery(ctx, &influxdb.SignedQuery{OrgID: 1, BucketID: 2, Query: q.Query, ExpiresAt: tg.FakeValue.Add(influxdb.MaxSignedQueryDuration + time.Hour), UserID: user.ID}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected expiration beyond the maximum to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var slackThreadBucket = []byte("slackthreadsv1")
+
+var _ influxdb.SlackThreadService = (*Service)(nil)
+
+func (s *Service) initializeSlackThreads(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(slackThreadBucket)
+		return err
+	})
+}
+
+// FindSlackThreadByID returns a single slack thread by ID.
+func (s *Service) FindSlackThreadByID(ctx context.Context, id influxdb.ID) (*influxdb.SlackThread, error) {
+	var t *influxdb.SlackThread
+	err := s.kv.View(ctx, func(tx Tx) error {
+		thread, err := s.findSlackThreadByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		t = thread
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindSlackThreadByID,
+			Err: err,
+		}
+	}
+	return t, nil
+}
+
+func (s *Service) findSlackThreadByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.SlackThread, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(slackThreadBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrSlackThreadNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t influxdb.SlackThread
+	if err := json.Unmarshal(v, &t); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &t, nil
+}
+
+// FindSlackThreads returns a list of slack threads that match filter.
+func (s *Service) FindSlackThreads(ctx context.Context, filter influxdb.SlackThreadFilter) ([]*influxdb.SlackThread, error) {
+	ts := []*influxdb.SlackThread{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachSlackThread(ctx, tx, func(t *influxdb.SlackThread) bool {
+			if filter.Match(t) {
+				ts = append(ts, t)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindSlackThreads,
+			Err: err,
+		}
+	}
+	return ts, nil
+}
+
+// forEachSlackThread will iterate through all slack threads while fn returns true.
+func (s *Service) forEachSlackThread(ctx context.Context, tx Tx, fn func(*influxdb.SlackThread) bool) error {
+	b, err := tx.Bucket(slackThreadBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		t := &influxdb.SlackThread{}
+		if err := json.Unmarshal(v, t); err != nil {
+			return err
+		}
+		if !fn(t) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// PutSlackThread creates or updates a slack thread.
+func (s *Service) PutSlackThread(ctx context.Context, t *influxdb.SlackThread) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		now := s.Now()
+		if !t.ID.Valid() {
+			t.ID = s.IDGenerator.ID()
+			t.SetCreatedAt(now)
+		} else if _, err := s.findSlackThreadByID(ctx, tx, t.ID); err != nil {
+			return err
+		}
+		t.SetUpdatedAt(now)
+		return s.putSlackThread(ctx, tx, t)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutSlackThread,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putSlackThread(ctx context.Context, tx Tx, t *influxdb.SlackThread) error {
+	v, err := json.Marshal(t)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := t.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(slackThreadBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// DeleteSlackThread removes a slack thread by ID.
+func (s *Service) DeleteSlackThread(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findSlackThreadByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.deleteSlackThread(ctx, tx, id)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteSlackThread,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteSlackThread(ctx context.Context, tx Tx, id influxdb.ID) error {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(slackThreadBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(encodedID)
+}
+
+// deleteEndpointSlackThreads removes the slack threads of a deleted
+// notification endpoint.
+func (s *Service) deleteEndpointSlackThreads(ctx context.Context, tx Tx, endpointID influxdb.ID) error {
+	var ids []influxdb.ID
+	err := s.forEachSlackThread(ctx, tx, func(t *influxdb.SlackThread) bool {
+		if t.EndpointID == endpointID {
+			ids = append(ids, t.ID)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.deleteSlackThread(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
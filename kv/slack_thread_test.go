@@ -0,0 +1,79 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_SlackThreads(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing slack thread service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	token := "xoxb-token"
+	edp := &endpoint.Slack{
+		Base:  endpoint.Base{Name: "slack", OrgID: &org.ID, Status: influxdb.Active},
+		App:   true,
+		Token: influxdb.SecretField{Value: &token},
+	}
+	if err := svc.CreateNotificationEndpoint(ctx, edp, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	th := &influxdb.SlackThread{
+		OrgID:      org.ID,
+		EndpointID: edp.GetID(),
+		AlertKey:   "rule/check/host=a",
+		Channel:    "C1",
+		TS:         "1.1",
+		Level:      "crit",
+	}
+	if err := svc.PutSlackThread(ctx, th); err != nil {
+		t.Fatal(err)
+	}
+	if !th.ID.Valid() || th.CreatedAt.IsZero() {
+		t.Fatalf("expected a new thread to be given an ID and a creation time, got %+v", th)
+	}
+
+	th.Level = "warn"
+	if err := svc.PutSlackThread(ctx, th); err != nil {
+		t.Fatal(err)
+	}
+	key := "rule/check/host=a"
+	ts, err := svc.FindSlackThreads(ctx, influxdb.SlackThreadFilter{AlertKey: &key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].Level != "warn" || ts[0].ID != th.ID {
+		t.Errorf("unexpected threads %+v", ts)
+	}
+
+	missing := &influxdb.SlackThread{ID: 1000, OrgID: org.ID}
+	if err := svc.PutSlackThread(ctx, missing); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected updating a missing thread to be not found, got %v", err)
+	}
+
+	if _, _, err := svc.DeleteNotificationEndpoint(ctx, edp.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindSlackThreadByID(ctx, th.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the threads of a deleted endpoint to be removed, got %v", err)
+	}
+}
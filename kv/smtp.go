@@ -0,0 +1,158 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	smtpConfigBucket = []byte("smtpconfigv1")
+	smtpConfigKey    = []byte("smtp")
+)
+
+var _ influxdb.SMTPConfigService = (*Service)(nil)
+
+func (s *Service) initializeSMTPConfig(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(smtpConfigBucket)
+		return err
+	})
+}
+
+// FindSMTPConfig returns the SMTP server of the instance.
+func (s *Service) FindSMTPConfig(ctx context.Context) (*influxdb.SMTPConfig, error) {
+	var c *influxdb.SMTPConfig
+	err := s.kv.View(ctx, func(tx Tx) error {
+		cfg, err := s.findSMTPConfig(ctx, tx)
+		if err != nil {
+			return err
+		}
+		c = cfg
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindSMTPConfig,
+			Err: err,
+		}
+	}
+	return c, nil
+}
+
+func (s *Service) findSMTPConfig(ctx context.Context, tx Tx) (*influxdb.SMTPConfig, error) {
+	b, err := tx.Bucket(smtpConfigBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(smtpConfigKey)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrSMTPConfigNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c influxdb.SMTPConfig
+	if err := json.Unmarshal(v, &c); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &c, nil
+}
+
+// PutSMTPConfig replaces the SMTP server of the instance.
+func (s *Service) PutSMTPConfig(ctx context.Context, c *influxdb.SMTPConfig) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.putSMTPConfig(ctx, tx, c)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutSMTPConfig,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putSMTPConfig(ctx context.Context, tx Tx, c *influxdb.SMTPConfig) error {
+	c.Password.Key = ""
+	c.SetDefaults()
+	if err := c.Valid(); err != nil {
+		return err
+	}
+
+	prev, err := s.findSMTPConfig(ctx, tx)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+
+	c.ID = s.IDGenerator.ID()
+	if prev != nil {
+		c.ID = prev.ID
+	}
+	switch {
+	case c.Password.Value != nil:
+		c.Password.Key = c.SecretKey()
+		if err := s.putSecret(ctx, tx, c.ID, c.Password.Key, *c.Password.Value); err != nil {
+			return err
+		}
+		c.Password.Value = nil
+	case prev != nil && prev.Password.Key != "" && prev.Username == c.Username:
+		// the password of the same username is kept.
+		c.Password.Key = prev.Password.Key
+	case prev != nil && prev.Password.Key != "":
+		if err := s.deleteSecret(ctx, tx, prev.ID, prev.Password.Key); err != nil {
+			return err
+		}
+	}
+
+	c.UpdatedAt = s.Now()
+	v, err := json.Marshal(c)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	b, err := tx.Bucket(smtpConfigBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(smtpConfigKey, v)
+}
+
+// DeleteSMTPConfig removes the SMTP server of the instance and its password.
+func (s *Service) DeleteSMTPConfig(ctx context.Context) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		c, err := s.findSMTPConfig(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if c.Password.Key != "" {
+			if err := s.deleteSecret(ctx, tx, c.ID, c.Password.Key); err != nil {
+				return err
+			}
+		}
+
+		b, err := tx.Bucket(smtpConfigBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(smtpConfigKey)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteSMTPConfig,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_SMTPConfig(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing smtp config service: %v", err)
+	}
+
+	if _, err := svc.FindSMTPConfig(ctx); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected smtp config to be not found, got %v", err)
+	}
+
+	password := "s3cr3t"
+	c := &influxdb.SMTPConfig{
+		Host:     "smtp.example.com",
+		Username: "influx",
+		Password: influxdb.SecretField{Value: &password},
+		From:     "InfluxDB <alerts@example.com>",
+	}
+	if err := svc.PutSMTPConfig(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 587 || c.TLS != influxdb.SMTPTLSStartTLS {
+		t.Errorf("expected starttls on port 587 by default, got %s on port %d", c.TLS, c.Port)
+	}
+	if c.Password.Value != nil || c.Password.Key == "" {
+		t.Errorf("expected the password to be replaced by its secret key, got %+v", c.Password)
+	}
+	if v, err := svc.LoadSecret(ctx, c.ID, c.Password.Key); err != nil || v != password {
+		t.Errorf("expected the password to be stored as a secret, got %q: %v", v, err)
+	}
+
+	// A config without a password keeps the password of the same username.
+	id := c.ID
+	c = &influxdb.SMTPConfig{Host: "smtp.example.com", TLS: influxdb.SMTPTLSImplicit, Username: "influx", From: "alerts@example.com"}
+	if err := svc.PutSMTPConfig(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.FindSMTPConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != id || got.Port != 465 || got.Password.Key == "" {
+		t.Errorf("unexpected smtp config: %+v", got)
+	}
+
+	for _, c := range []*influxdb.SMTPConfig{
+		{From: "alerts@example.com"},
+		{Host: "smtp.example.com", From: "not an address"},
+		{Host: "smtp.example.com", TLS: "ssl", From: "alerts@example.com"},
+		{Host: "smtp.example.com", Password: influxdb.SecretField{Value: &password}, From: "alerts@example.com"},
+	} {
+		if err := svc.PutSMTPConfig(ctx, c); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("expected smtp config %+v to be invalid, got %v", c, err)
+		}
+	}
+
+	// Changing the username removes the password.
+	if err := svc.PutSMTPConfig(ctx, &influxdb.SMTPConfig{Host: "smtp.example.com", Username: "other", From: "alerts@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = svc.FindSMTPConfig(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got.Password.Key != "" {
+		t.Errorf("expected the password to be removed, got %+v", got.Password)
+	}
+	if _, err := svc.LoadSecret(ctx, id, "smtp-password"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the password secret to be deleted, got %v", err)
+	}
+
+	if err := svc.DeleteSMTPConfig(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindSMTPConfig(ctx); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected deleted smtp config to be not found, got %v", err)
+	}
+}
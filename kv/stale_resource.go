@@ -0,0 +1,289 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	resourceActivityBucket    = []byte("resourceactivityv1")
+	staleResourceReportBucket = []byte("staleresourcereportsv1")
+)
+
+var _ influxdb.ResourceActivityService = (*Service)(nil)
+
+func (s *Service) initializeStaleResources(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(resourceActivityBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(staleResourceReportBucket)
+		return err
+	})
+}
+
+// resourceActivityPrefix is the prefix of the keys of the activity of the
+// resources of the type.
+func resourceActivityPrefix(rt influxdb.ResourceType) []byte {
+	return []byte(string(rt) + "/")
+}
+
+// RecordResourceActivity records that the resource was read or written at
+// the time.
+func (s *Service) RecordResourceActivity(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID, kind influxdb.ResourceActivityKind, at time.Time) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		encodedID, err := id.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+		key := append(resourceActivityPrefix(rt), encodedID...)
+
+		b, err := tx.Bucket(resourceActivityBucket)
+		if err != nil {
+			return err
+		}
+
+		a := &influxdb.ResourceActivity{ResourceType: rt, ResourceID: id}
+		v, err := b.Get(key)
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			if err := json.Unmarshal(v, a); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+		}
+
+		// Activity may be recorded out of order by concurrent requests.
+		last := &a.LastReadAt
+		if kind == influxdb.ResourceActivityWrite {
+			last = &a.LastWrittenAt
+		}
+		if *last != nil && (*last).After(at) {
+			return nil
+		}
+		*last = &at
+
+		v, err = json.Marshal(a)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		return b.Put(key, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRecordResourceActivity,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindResourceActivities returns the activity recorded for the resources of
+// the type.
+func (s *Service) FindResourceActivities(ctx context.Context, rt influxdb.ResourceType) ([]*influxdb.ResourceActivity, error) {
+	as := []*influxdb.ResourceActivity{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(resourceActivityBucket)
+		if err != nil {
+			return err
+		}
+
+		prefix := resourceActivityPrefix(rt)
+		cur, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			a := &influxdb.ResourceActivity{}
+			if err := json.Unmarshal(v, a); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+			as = append(as, a)
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindResourceActivities,
+			Err: err,
+		}
+	}
+	return as, nil
+}
+
+// FindStaleResourceReportByID returns a single stale resource report by ID.
+func (s *Service) FindStaleResourceReportByID(ctx context.Context, id influxdb.ID) (*influxdb.StaleResourceReport, error) {
+	var r *influxdb.StaleResourceReport
+	err := s.kv.View(ctx, func(tx Tx) error {
+		rep, err := s.findStaleResourceReportByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		r = rep
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindStaleResourceReportByID,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) findStaleResourceReportByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.StaleResourceReport, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(staleResourceReportBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrStaleResourceReportNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r influxdb.StaleResourceReport
+	if err := json.Unmarshal(v, &r); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &r, nil
+}
+
+// FindStaleResourceReports returns the stale resource reports that match
+// filter, without their resources, most recently started first.
+func (s *Service) FindStaleResourceReports(ctx context.Context, filter influxdb.StaleResourceReportFilter) ([]*influxdb.StaleResourceReport, error) {
+	rs := []*influxdb.StaleResourceReport{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(staleResourceReportBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			r := &influxdb.StaleResourceReport{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Err:  err,
+				}
+			}
+			if filter.Status == nil || *filter.Status == r.Status {
+				r.Resources = nil
+				rs = append(rs, r)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindStaleResourceReports,
+			Err: err,
+		}
+	}
+	sort.SliceStable(rs, func(i, j int) bool {
+		return rs[i].StartedAt.After(rs[j].StartedAt)
+	})
+	return rs, nil
+}
+
+// CreateStaleResourceReport stores a new stale resource report and sets r.ID
+// with the new identifier.
+func (s *Service) CreateStaleResourceReport(ctx context.Context, r *influxdb.StaleResourceReport) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		r.ID = s.IDGenerator.ID()
+		r.StartedAt = s.Now()
+		r.UpdatedAt = r.StartedAt
+		return s.putStaleResourceReport(ctx, tx, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateStaleResourceReport,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// PutStaleResourceReport stores the progress of a stale resource report.
+func (s *Service) PutStaleResourceReport(ctx context.Context, r *influxdb.StaleResourceReport) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findStaleResourceReportByID(ctx, tx, r.ID); err != nil {
+			return err
+		}
+		r.UpdatedAt = s.Now()
+		return s.putStaleResourceReport(ctx, tx, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutStaleResourceReport,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putStaleResourceReport(ctx context.Context, tx Tx, r *influxdb.StaleResourceReport) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := r.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(staleResourceReportBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
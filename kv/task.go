@@ -671,6 +671,10 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		s.log.Info("Error creating user resource mapping for task", zap.Stringer("taskID", task.ID), zap.Error(err))
 	}
 
+	if err := s.recordTaskRevision(ctx, tx, task); err != nil {
+		return nil, err
+	}
+
 	// populate permissions so the task can be used immediately
 	// if we cant populate here we shouldn't error.
 	ps, _ := s.maxPermissions(ctx, tx, task.OwnerID)
@@ -697,6 +701,16 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 	return task, nil
 }
 
+// recordTaskRevision records the Flux of a task in its revisions. The tasks
+// of checks and notification rules are skipped, their definitions are
+// recorded instead.
+func (s *Service) recordTaskRevision(ctx context.Context, tx Tx, task *influxdb.Task) error {
+	if task.Type != "" && task.Type != influxdb.TaskSystemType {
+		return nil
+	}
+	return s.recordScriptRevision(ctx, tx, influxdb.TasksResourceType, task.ID, task.OrganizationID, task.Flux)
+}
+
 func (s *Service) createTaskURM(ctx context.Context, tx Tx, t *influxdb.Task) error {
 	// TODO(jsteenb2): should not be getting authorizer inside the store, should terminate at the
 	//  transport layer then pass user id everywhere else.
@@ -834,6 +848,12 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	if !upd.Options.IsZero() || upd.Flux != nil {
+		if err := s.recordTaskRevision(ctx, tx, task); err != nil {
+			return nil, err
+		}
+	}
+
 	uid, _ := icontext.GetUserID(ctx)
 	if err := s.audit.Log(resource.Change{
 		Type:           resource.Update,
@@ -940,6 +960,10 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id influxdb.ID) error {
 		s.log.Info("Error deleting user resource mapping for task", zap.Stringer("taskID", task.ID), zap.Error(err))
 	}
 
+	if err := s.deleteScriptRevisions(ctx, tx, task.ID); err != nil {
+		return err
+	}
+
 	uid, _ := icontext.GetUserID(ctx)
 	return s.audit.Log(resource.Change{
 		Type:           resource.Delete,
@@ -423,6 +423,14 @@ func (s *Service) deleteUser(ctx context.Context, tx Tx, id influxdb.ID) error {
 		return err
 	}
 
+	if err := s.deleteUsersRoleMemberships(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := s.deleteUserPreferences(ctx, tx, id); err != nil {
+		return err
+	}
+
 	encodedID, err := id.Encode()
 	if err != nil {
 		return InvalidUserIDError(err)
@@ -505,8 +513,11 @@ const userOperationLogKeyPrefix = "user"
 
 // TODO(desa): what do we want these to be?
 const (
-	userCreatedEvent = "User Created"
-	userUpdatedEvent = "User Updated"
+	userCreatedEvent         = "User Created"
+	userUpdatedEvent         = "User Updated"
+	userPasswordUpdatedEvent = "User Password Updated"
+	userLockedEvent          = "User Locked"
+	userUnlockedEvent        = "User Unlocked"
 )
 
 func encodeUserOperationLogKey(id influxdb.ID) ([]byte, error) {
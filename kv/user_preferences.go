@@ -0,0 +1,188 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	userPreferencesBucket = []byte("userpreferencesv1")
+)
+
+var _ influxdb.UserPreferencesService = (*Service)(nil)
+
+func (s *Service) initializeUserPreferences(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		_, err := tx.Bucket(userPreferencesBucket)
+		return err
+	})
+}
+
+// FindUserPreferences returns the preferences of a user. A user who has not
+// set any preference has the zero value preferences.
+func (s *Service) FindUserPreferences(ctx context.Context, userID influxdb.ID) (*influxdb.UserPreferences, error) {
+	var p *influxdb.UserPreferences
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if _, err := s.findUserByID(ctx, tx, userID); err != nil {
+			return err
+		}
+		prefs, err := s.findUserPreferences(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		p = prefs
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindUserPreferences,
+			Err: err,
+		}
+	}
+	return p, nil
+}
+
+func (s *Service) findUserPreferences(ctx context.Context, tx Tx, userID influxdb.ID) (*influxdb.UserPreferences, error) {
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return nil, InvalidUserIDError(err)
+	}
+
+	b, err := tx.Bucket(userPreferencesBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return &influxdb.UserPreferences{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p influxdb.UserPreferences
+	if err := json.Unmarshal(v, &p); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &p, nil
+}
+
+// UpdateUserPreferences updates the preferences of a user with changeset.
+func (s *Service) UpdateUserPreferences(ctx context.Context, userID influxdb.ID, upd influxdb.UserPreferencesUpdate) (*influxdb.UserPreferences, error) {
+	var p *influxdb.UserPreferences
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findUserByID(ctx, tx, userID); err != nil {
+			return err
+		}
+		prefs, err := s.findUserPreferences(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		upd.Apply(prefs)
+		if err := prefs.Valid(); err != nil {
+			return err
+		}
+		if upd.DefaultOrgID != nil && prefs.DefaultOrgID != nil {
+			if _, err := s.findOrganizationByID(ctx, tx, *prefs.DefaultOrgID); err != nil {
+				return err
+			}
+		}
+
+		prefs.UpdatedAt = s.Now()
+		if err := s.putUserPreferences(ctx, tx, prefs); err != nil {
+			return err
+		}
+		p = prefs
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateUserPreferences,
+			Err: err,
+		}
+	}
+	return p, nil
+}
+
+func (s *Service) putUserPreferences(ctx context.Context, tx Tx, p *influxdb.UserPreferences) error {
+	encodedID, err := p.UserID.Encode()
+	if err != nil {
+		return InvalidUserIDError(err)
+	}
+	v, err := json.Marshal(p)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(userPreferencesBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// deleteUserPreferences removes the preferences of a deleted user.
+func (s *Service) deleteUserPreferences(ctx context.Context, tx Tx, userID influxdb.ID) error {
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return InvalidUserIDError(err)
+	}
+
+	b, err := tx.Bucket(userPreferencesBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(encodedID)
+}
+
+// clearDefaultOrganization resets the default organization of the users
+// whose default is the deleted organization orgID.
+func (s *Service) clearDefaultOrganization(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	b, err := tx.Bucket(userPreferencesBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	var ps []*influxdb.UserPreferences
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		p := &influxdb.UserPreferences{}
+		if err := json.Unmarshal(v, p); err != nil {
+			cur.Close()
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		if p.DefaultOrgID != nil && *p.DefaultOrgID == orgID {
+			ps = append(ps, p)
+		}
+	}
+	if err := cur.Err(); err != nil {
+		cur.Close()
+		return err
+	}
+	cur.Close()
+
+	for _, p := range ps {
+		p.DefaultOrgID = nil
+		p.UpdatedAt = s.Now()
+		if err := s.putUserPreferences(ctx, tx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_UserPreferences(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing user preferences service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user", Status: influxdb.Active}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := svc.FindUserPreferences(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UserID != user.ID || p.Timezone != "" || p.DefaultOrgID != nil || p.AlertDigest.Enabled() {
+		t.Errorf("expected empty preferences, got %+v", p)
+	}
+
+	tz, compact := "Europe/Paris", influxdb.UIDensityCompact
+	p, err = svc.UpdateUserPreferences(ctx, user.ID, influxdb.UserPreferencesUpdate{
+		Timezone:     &tz,
+		DefaultOrgID: &org.ID,
+		UIDensity:    &compact,
+		AlertDigest:  &influxdb.AlertDigest{Frequency: influxdb.DigestDaily, Email: "user@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Timezone != tz || p.DefaultOrgID == nil || *p.DefaultOrgID != org.ID || p.UIDensity != compact || !p.AlertDigest.Enabled() {
+		t.Errorf("unexpected preferences: %+v", p)
+	}
+
+	badTZ := "Mars/Olympus_Mons"
+	missing := influxdb.ID(1000)
+	for _, upd := range []influxdb.UserPreferencesUpdate{
+		{Timezone: &badTZ},
+		{DefaultOrgID: &missing},
+		{AlertDigest: &influxdb.AlertDigest{Frequency: influxdb.DigestWeekly}},
+		{AlertDigest: &influxdb.AlertDigest{Frequency: "hourly", Email: "user@example.com"}},
+	} {
+		if _, err := svc.UpdateUserPreferences(ctx, user.ID, upd); err == nil {
+			t.Errorf("expected update %+v to fail", upd)
+		}
+	}
+
+	// Only the preferences which are set are updated.
+	empty := ""
+	p, err = svc.UpdateUserPreferences(ctx, user.ID, influxdb.UserPreferencesUpdate{Timezone: &empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Timezone != "" || p.UIDensity != compact {
+		t.Errorf("unexpected preferences: %+v", p)
+	}
+
+	// Deleting the default organization resets it.
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	if p, err = svc.FindUserPreferences(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if p.DefaultOrgID != nil {
+		t.Errorf("expected default organization to be reset, got %v", p.DefaultOrgID)
+	}
+
+	if err := svc.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindUserPreferences(ctx, user.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected preferences of deleted user to be not found, got %v", err)
+	}
+}
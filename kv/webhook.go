@@ -0,0 +1,416 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	webhookBucket         = []byte("webhooksv1")
+	webhookDeliveryBucket = []byte("webhookdeliveriesv1")
+)
+
+var _ influxdb.WebhookService = (*Service)(nil)
+
+func (s *Service) initializeWebhooks(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		if _, err := tx.Bucket(webhookBucket); err != nil {
+			return err
+		}
+		_, err := tx.Bucket(webhookDeliveryBucket)
+		return err
+	})
+}
+
+// FindWebhookByID returns a single webhook by ID.
+func (s *Service) FindWebhookByID(ctx context.Context, id influxdb.ID) (*influxdb.Webhook, error) {
+	var w *influxdb.Webhook
+	err := s.kv.View(ctx, func(tx Tx) error {
+		webhook, err := s.findWebhookByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		w = webhook
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindWebhookByID,
+			Err: err,
+		}
+	}
+	return w, nil
+}
+
+func (s *Service) findWebhookByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Webhook, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(webhookBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrWebhookNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var w influxdb.Webhook
+	if err := json.Unmarshal(v, &w); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return &w, nil
+}
+
+// FindWebhooks returns a list of webhooks that match filter.
+func (s *Service) FindWebhooks(ctx context.Context, filter influxdb.WebhookFilter) ([]*influxdb.Webhook, error) {
+	ws := []*influxdb.Webhook{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachWebhook(ctx, tx, func(w *influxdb.Webhook) bool {
+			if filter.Match(w) {
+				ws = append(ws, w)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindWebhooks,
+			Err: err,
+		}
+	}
+	return ws, nil
+}
+
+// forEachWebhook will iterate through all webhooks while fn returns true.
+func (s *Service) forEachWebhook(ctx context.Context, tx Tx, fn func(*influxdb.Webhook) bool) error {
+	b, err := tx.Bucket(webhookBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		w := &influxdb.Webhook{}
+		if err := json.Unmarshal(v, w); err != nil {
+			return err
+		}
+		if !fn(w) {
+			break
+		}
+	}
+
+	return cur.Err()
+}
+
+// CreateWebhook creates a new webhook and sets w.ID with the new identifier.
+func (s *Service) CreateWebhook(ctx context.Context, w *influxdb.Webhook) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.createWebhook(ctx, tx, w)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateWebhook,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) createWebhook(ctx context.Context, tx Tx, w *influxdb.Webhook) error {
+	if err := w.Valid(); err != nil {
+		return err
+	}
+	if _, err := s.findOrganizationByID(ctx, tx, w.OrgID); err != nil {
+		return err
+	}
+
+	w.ID = s.IDGenerator.ID()
+	if w.Status == "" {
+		w.Status = influxdb.Active
+	}
+	w.Secret.Key = ""
+	if err := s.putWebhookSecret(ctx, tx, w); err != nil {
+		return err
+	}
+	w.SetCreatedAt(s.Now())
+	w.SetUpdatedAt(s.Now())
+	return s.putWebhook(ctx, tx, w)
+}
+
+// putWebhookSecret stores a new value of the secret of w, and replaces it
+// with the key of the secret.
+func (s *Service) putWebhookSecret(ctx context.Context, tx Tx, w *influxdb.Webhook) error {
+	if w.Secret.Value == nil {
+		return nil
+	}
+	w.Secret.Key = w.SecretKey()
+	if err := s.putSecret(ctx, tx, w.OrgID, w.Secret.Key, *w.Secret.Value); err != nil {
+		return err
+	}
+	w.Secret.Value = nil
+	return nil
+}
+
+// UpdateWebhook updates a single webhook with changeset.
+func (s *Service) UpdateWebhook(ctx context.Context, id influxdb.ID, upd influxdb.WebhookUpdate) (*influxdb.Webhook, error) {
+	var w *influxdb.Webhook
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		webhook, err := s.findWebhookByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		secretKey := webhook.Secret.Key
+		upd.Apply(webhook)
+		if err := webhook.Valid(); err != nil {
+			return err
+		}
+		if secretKey != "" && webhook.Secret.Key == "" {
+			if err := s.deleteSecret(ctx, tx, webhook.OrgID, secretKey); err != nil {
+				return err
+			}
+		}
+		if err := s.putWebhookSecret(ctx, tx, webhook); err != nil {
+			return err
+		}
+		webhook.SetUpdatedAt(s.Now())
+		w = webhook
+		return s.putWebhook(ctx, tx, webhook)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateWebhook,
+			Err: err,
+		}
+	}
+	return w, nil
+}
+
+func (s *Service) putWebhook(ctx context.Context, tx Tx, w *influxdb.Webhook) error {
+	v, err := json.Marshal(w)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := w.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(webhookBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encodedID, v)
+}
+
+// DeleteWebhook removes a webhook, its secret and its deliveries by ID.
+func (s *Service) DeleteWebhook(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		w, err := s.findWebhookByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if w.Secret.Key != "" {
+			if err := s.deleteSecret(ctx, tx, w.OrgID, w.Secret.Key); err != nil {
+				return err
+			}
+		}
+
+		keys, err := s.webhookDeliveryKeys(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.deleteWebhookDeliveries(ctx, tx, keys); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(webhookBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encodedID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteWebhook,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindWebhookDeliveries returns the latest deliveries of a webhook, newest
+// first.
+func (s *Service) FindWebhookDeliveries(ctx context.Context, webhookID influxdb.ID) ([]*influxdb.WebhookDelivery, error) {
+	ds := []*influxdb.WebhookDelivery{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if _, err := s.findWebhookByID(ctx, tx, webhookID); err != nil {
+			return err
+		}
+
+		prefix, err := webhookID.Encode()
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(webhookDeliveryBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			d := &influxdb.WebhookDelivery{}
+			if err := json.Unmarshal(v, d); err != nil {
+				return err
+			}
+			ds = append(ds, d)
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindWebhookDeliveries,
+			Err: err,
+		}
+	}
+
+	for i, j := 0, len(ds)-1; i < j; i, j = i+1, j-1 {
+		ds[i], ds[j] = ds[j], ds[i]
+	}
+	return ds, nil
+}
+
+// AddWebhookDelivery records a delivery of a webhook and sets d.ID with the
+// new identifier. Only the latest MaxWebhookDeliveries are kept.
+func (s *Service) AddWebhookDelivery(ctx context.Context, d *influxdb.WebhookDelivery) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findWebhookByID(ctx, tx, d.WebhookID); err != nil {
+			return err
+		}
+
+		d.ID = s.IDGenerator.ID()
+		key, err := webhookDeliveryKey(d.WebhookID, d.ID)
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(d)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		b, err := tx.Bucket(webhookDeliveryBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, v); err != nil {
+			return err
+		}
+
+		keys, err := s.webhookDeliveryKeys(ctx, tx, d.WebhookID)
+		if err != nil {
+			return err
+		}
+		if len(keys) <= influxdb.MaxWebhookDeliveries {
+			return nil
+		}
+		return s.deleteWebhookDeliveries(ctx, tx, keys[:len(keys)-influxdb.MaxWebhookDeliveries])
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpAddWebhookDelivery,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// webhookDeliveryKey returns the key of a delivery, which sorts the
+// deliveries of a webhook by ID.
+func webhookDeliveryKey(webhookID, id influxdb.ID) ([]byte, error) {
+	w, err := webhookID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	d, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return append(w, d...), nil
+}
+
+// webhookDeliveryKeys returns the keys of the deliveries of a webhook, oldest
+// first.
+func (s *Service) webhookDeliveryKeys(ctx context.Context, tx Tx, webhookID influxdb.ID) ([][]byte, error) {
+	prefix, err := webhookID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	b, err := tx.Bucket(webhookDeliveryBucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	var keys [][]byte
+	for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	return keys, cur.Err()
+}
+
+func (s *Service) deleteWebhookDeliveries(ctx context.Context, tx Tx, keys [][]byte) error {
+	b, err := tx.Bucket(webhookDeliveryBucket)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_Webhooks(t *testing.T) {
+	store, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing webhook service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := "s3cr3t"
+	w := &influxdb.Webhook{
+		OrgID:  org.ID,
+		Name:   "automation",
+		URL:    "https://example.com/hooks",
+		Secret: influxdb.SecretField{Value: &secret},
+		Events: []string{influxdb.WebhookEventBucketCreated},
+	}
+	if err := svc.CreateWebhook(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+	if w.Status != influxdb.Active || w.Secret.Value != nil || w.Secret.Key != w.SecretKey() {
+		t.Fatalf("unexpected webhook %+v", w)
+	}
+	if v, err := svc.LoadSecret(ctx, org.ID, w.Secret.Key); err != nil || v != secret {
+		t.Fatalf("expected secret to be stored, got %q, %v", v, err)
+	}
+
+	invalid := &influxdb.Webhook{OrgID: org.ID, Name: "bad", URL: "ftp://example.com", Events: []string{influxdb.WebhookEventBucketCreated}}
+	if err := svc.CreateWebhook(ctx, invalid); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid URL to be rejected, got %v", err)
+	}
+
+	event := influxdb.WebhookEventTaskFailed
+	if ws, err := svc.FindWebhooks(ctx, influxdb.WebhookFilter{OrgID: &org.ID, Event: &event}); err != nil || len(ws) != 0 {
+		t.Fatalf("expected no webhook subscribed to %s, got %v, %v", event, ws, err)
+	}
+
+	inactive := influxdb.Inactive
+	empty := ""
+	w, err = svc.UpdateWebhook(ctx, w.ID, influxdb.WebhookUpdate{
+		Events: []string{influxdb.WebhookEventBucketCreated, influxdb.WebhookEventTaskFailed},
+		Status: &inactive,
+		Secret: &empty,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Secret.Key != "" {
+		t.Fatalf("expected secret to be removed, got %q", w.Secret.Key)
+	}
+	if _, err := svc.LoadSecret(ctx, org.ID, w.SecretKey()); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected secret to be deleted, got %v", err)
+	}
+	if ws, err := svc.FindWebhooks(ctx, influxdb.WebhookFilter{Event: &event}); err != nil || len(ws) != 0 {
+		t.Fatalf("expected inactive webhook not to match, got %v, %v", ws, err)
+	}
+
+	for i := 0; i < influxdb.MaxWebhookDeliveries+5; i++ {
+		d := &influxdb.WebhookDelivery{WebhookID: w.ID, EventType: event, Attempts: i}
+		if err := svc.AddWebhookDelivery(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ds, err := svc.FindWebhookDeliveries(ctx, w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != influxdb.MaxWebhookDeliveries {
+		t.Fatalf("got %d deliveries, want %d", len(ds), influxdb.MaxWebhookDeliveries)
+	}
+	if ds[0].Attempts != influxdb.MaxWebhookDeliveries+4 || ds[len(ds)-1].Attempts != 5 {
+		t.Fatalf("expected the newest deliveries first, got %d to %d", ds[0].Attempts, ds[len(ds)-1].Attempts)
+	}
+
+	if err := svc.DeleteWebhook(ctx, w.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindWebhookDeliveries(ctx, w.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected deleted webhook not to be found, got %v", err)
+	}
+	if err := svc.AddWebhookDelivery(ctx, &influxdb.WebhookDelivery{WebhookID: w.ID}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected delivery of deleted webhook to fail, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// LastValueService finds the last values of the series of the buckets whose
+// last value cache is enabled. The last values are kept in memory as points
+// are written, so that the current state of many series is read without
+// reading storage.
+type LastValueService interface {
+	// FindLastValues returns the last values of the series of the bucket
+	// matching filter, in the order of their measurements, fields and
+	// series.
+	FindLastValues(ctx context.Context, orgID, bucketID ID, filter LastValueFilter) ([]*LastValue, error)
+}
+
+// LastValue is the last value written to the field of a series.
+type LastValue struct {
+	Measurement string            `json:"measurement"`
+	Tags        map[string]string `json:"tags"`
+	Field       string            `json:"field"`
+	Time        time.Time         `json:"time"`
+	Value       interface{}       `json:"value"`
+}
+
+// LastValueFilter selects the last values of the series of a bucket. Empty
+// fields of the filter select every series.
+type LastValueFilter struct {
+	Measurement string
+	Field       string
+	// Tags are the values of tags the series must all have.
+	Tags map[string]string
+}
+
+// Matches returns whether the last value v is selected by f.
+func (f LastValueFilter) Matches(v *LastValue) bool {
+	if f.Measurement != "" && v.Measurement != f.Measurement {
+		return false
+	}
+	if f.Field != "" && v.Field != f.Field {
+		return false
+	}
+	for k, tv := range f.Tags {
+		if v.Tags[k] != tv {
+			return false
+		}
+	}
+	return true
+}
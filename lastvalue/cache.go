@@ -0,0 +1,308 @@
+// Package lastvalue keeps the last value of each series of the buckets whose
+// last value cache is enabled, as points are written, so that the current
+// state of many series is read from memory rather than from storage.
+//
+// The cache is empty when the process starts, and is filled as series are
+// written again. Values deleted from storage are not removed from the cache;
+// the values of a bucket are dropped when its cache is disabled or the bucket
+// is deleted.
+package lastvalue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxSeries is the default number of series whose last values are
+// kept by a Cache.
+const DefaultMaxSeries = 1000000
+
+// bucketCheckInterval is how long whether the cache of a bucket is enabled
+// is remembered before the bucket is found again.
+const bucketCheckInterval = 10 * time.Second
+
+// BucketFinder finds the buckets written to.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+var _ influxdb.LastValueService = (*Cache)(nil)
+
+// Cache keeps the last values of the series of the buckets whose last value
+// cache is enabled. At most maxSeries series are kept; once that many are
+// kept the values of new series are not cached until series are dropped.
+type Cache struct {
+	log       *zap.Logger
+	buckets   BucketFinder
+	maxSeries int
+	now       func() time.Time
+
+	mu      sync.RWMutex
+	values  map[influxdb.ID]*bucketValues
+	enabled map[influxdb.ID]bucketCheck
+	seriesN int
+
+	cachedSeries  prometheus.Gauge
+	droppedSeries prometheus.Counter
+}
+
+// bucketValues are the last values of the series of a bucket, by the keys of
+// their series and fields.
+type bucketValues struct {
+	orgID  influxdb.ID
+	values map[string]*influxdb.LastValue
+}
+
+// bucketCheck remembers whether the cache of a bucket is enabled.
+type bucketCheck struct {
+	enabled bool
+	expires time.Time
+}
+
+// NewCache returns a Cache keeping the last values of at most maxSeries
+// series of the buckets found with buckets.
+func NewCache(log *zap.Logger, buckets BucketFinder, maxSeries int) *Cache {
+	if maxSeries <= 0 {
+		maxSeries = DefaultMaxSeries
+	}
+	const namespace, subsystem = "storage", "last_value_cache"
+	return &Cache{
+		log:       log,
+		buckets:   buckets,
+		maxSeries: maxSeries,
+		now:       time.Now,
+		values:    make(map[influxdb.ID]*bucketValues),
+		enabled:   make(map[influxdb.ID]bucketCheck),
+		cachedSeries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "series",
+			Help:      "Number of series whose last values are cached.",
+		}),
+		droppedSeries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dropped_series_total",
+			Help:      "Number of values of new series not cached because the cache was full.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (c *Cache) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.cachedSeries,
+		c.droppedSeries,
+	}
+}
+
+// enabledFor returns whether the cache of the bucket is enabled. The values
+// of buckets whose cache is disabled, or which were deleted, are dropped.
+func (c *Cache) enabledFor(ctx context.Context, bucketID influxdb.ID) (bool, error) {
+	now := c.now()
+	c.mu.RLock()
+	check, ok := c.enabled[bucketID]
+	c.mu.RUnlock()
+	if ok && now.Before(check.expires) {
+		return check.enabled, nil
+	}
+
+	var enabled bool
+	b, err := c.buckets.FindBucketByID(ctx, bucketID)
+	if err == nil {
+		enabled = b.LastValueCache
+	} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled[bucketID] = bucketCheck{enabled: enabled, expires: now.Add(bucketCheckInterval)}
+	if bv, ok := c.values[bucketID]; ok && !enabled {
+		c.seriesN -= len(bv.values)
+		delete(c.values, bucketID)
+		c.cachedSeries.Set(float64(c.seriesN))
+	}
+	return enabled, nil
+}
+
+// Add caches the values of points, which are exploded points named by the
+// encoded IDs of their organization and bucket, if they are the last values
+// of their series written to buckets whose cache is enabled.
+func (c *Cache) Add(ctx context.Context, points []models.Point) error {
+	enabled := make(map[influxdb.ID]bool)
+	for _, p := range points {
+		name := p.Name()
+		// The names of buckets are the 16 bytes of the IDs of their
+		// organization and their own.
+		if len(name) != 16 {
+			continue
+		}
+		orgID, bucketID := tsdb.DecodeNameSlice(name)
+		on, ok := enabled[bucketID]
+		if !ok {
+			var err error
+			if on, err = c.enabledFor(ctx, bucketID); err != nil {
+				return err
+			}
+			enabled[bucketID] = on
+		}
+		if !on {
+			continue
+		}
+		c.add(orgID, bucketID, p)
+	}
+	return nil
+}
+
+func (c *Cache) add(orgID, bucketID influxdb.ID, p models.Point) {
+	key := string(p.Key())
+	t := p.Time()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bv, ok := c.values[bucketID]
+	if !ok {
+		bv = &bucketValues{orgID: orgID, values: make(map[string]*influxdb.LastValue)}
+		c.values[bucketID] = bv
+	}
+	if v, ok := bv.values[key]; ok {
+		// Values written out of order do not replace later values.
+		if t.Before(v.Time) {
+			return
+		}
+		if value, ok := pointValue(p); ok {
+			v.Time, v.Value = t.UTC(), value
+		}
+		return
+	}
+	if c.seriesN >= c.maxSeries {
+		c.droppedSeries.Inc()
+		return
+	}
+
+	value, ok := pointValue(p)
+	if !ok {
+		return
+	}
+	v := &influxdb.LastValue{
+		Tags:  make(map[string]string),
+		Time:  t.UTC(),
+		Value: value,
+	}
+	for _, tag := range p.Tags() {
+		switch string(tag.Key) {
+		case models.MeasurementTagKey:
+			v.Measurement = string(tag.Value)
+		case models.FieldKeyTagKey:
+			v.Field = string(tag.Value)
+		default:
+			v.Tags[string(tag.Key)] = string(tag.Value)
+		}
+	}
+	bv.values[key] = v
+	c.seriesN++
+	c.cachedSeries.Set(float64(c.seriesN))
+}
+
+// pointValue returns the value of the single field of an exploded point.
+func pointValue(p models.Point) (interface{}, bool) {
+	fields, err := p.Fields()
+	if err != nil {
+		return nil, false
+	}
+	for _, v := range fields {
+		return v, true
+	}
+	return nil, false
+}
+
+// FindLastValues returns the cached last values of the series of the bucket
+// matching filter. It returns an error if the cache of the bucket is
+// disabled.
+func (c *Cache) FindLastValues(ctx context.Context, orgID, bucketID influxdb.ID, filter influxdb.LastValueFilter) ([]*influxdb.LastValue, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	enabled, err := c.enabledFor(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "the last value cache of the bucket is disabled",
+		}
+	}
+
+	c.mu.RLock()
+	bv, ok := c.values[bucketID]
+	if !ok || bv.orgID != orgID {
+		c.mu.RUnlock()
+		return []*influxdb.LastValue{}, nil
+	}
+	keys := make([]string, 0, len(bv.values))
+	vs := make(map[string]influxdb.LastValue)
+	for key, v := range bv.values {
+		if filter.Matches(v) {
+			keys = append(keys, key)
+			vs[key] = *v
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		vi, vj := vs[keys[i]], vs[keys[j]]
+		if vi.Measurement != vj.Measurement {
+			return vi.Measurement < vj.Measurement
+		}
+		if vi.Field != vj.Field {
+			return vi.Field < vj.Field
+		}
+		return keys[i] < keys[j]
+	})
+	res := make([]*influxdb.LastValue, 0, len(keys))
+	for _, key := range keys {
+		v := vs[key]
+		res = append(res, &v)
+	}
+	return res, nil
+}
+
+// PointsWriter writes points with another writer, and caches the last values
+// of their series.
+type PointsWriter struct {
+	w     storage.PointsWriter
+	cache *Cache
+}
+
+// NewPointsWriter returns a PointsWriter writing with w and caching the last
+// values in cache.
+func NewPointsWriter(w storage.PointsWriter, cache *Cache) *PointsWriter {
+	return &PointsWriter{
+		w:     w,
+		cache: cache,
+	}
+}
+
+// WritePoints writes points, then caches their values. Failing to cache the
+// values does not fail the write.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if err := w.w.WritePoints(ctx, points); err != nil {
+		return err
+	}
+	if err := w.cache.Add(ctx, points); err != nil {
+		w.cache.log.Info("Failed to cache last values", zap.Error(err))
+	}
+	return nil
+}
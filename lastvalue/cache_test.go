@@ -0,0 +1,75 @@
+package lastvalue
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPointsWriter_WritePoints(t *testing.T) {
+	ctx := context.Background()
+	const orgID, cachedID, uncachedID = influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: orgID, LastValueCache: id == cachedID}, nil
+	}
+	cache := NewCache(zaptest.NewLogger(t), buckets, 2)
+	pw := &mock.PointsWriter{}
+	w := NewPointsWriter(pw, cache)
+
+	write := func(bucketID influxdb.ID, host string, v float64, sec int64) {
+		t.Helper()
+		p := models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": host}), models.Fields{"usage": v}, time.Unix(sec, 0))
+		points, err := tsdb.ExplodePoints(orgID, bucketID, []models.Point{p})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WritePoints(ctx, points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(cachedID, "a", 1, 10)
+	write(cachedID, "a", 2, 20)
+	// Values written out of order are stored, but not cached.
+	write(cachedID, "a", 3, 15)
+	write(cachedID, "b", 4, 10)
+	// The cache is full.
+	write(cachedID, "c", 5, 10)
+	write(uncachedID, "a", 6, 10)
+
+	if got := len(pw.Points); got != 6 {
+		t.Fatalf("expected 6 points written, got %d", got)
+	}
+
+	vs, err := cache.FindLastValues(ctx, orgID, cachedID, influxdb.LastValueFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*influxdb.LastValue{
+		{Measurement: "cpu", Tags: map[string]string{"host": "a"}, Field: "usage", Time: time.Unix(20, 0).UTC(), Value: 2.0},
+		{Measurement: "cpu", Tags: map[string]string{"host": "b"}, Field: "usage", Time: time.Unix(10, 0).UTC(), Value: 4.0},
+	}
+	if !reflect.DeepEqual(vs, exp) {
+		t.Fatalf("expected last values %+v, got %+v", exp, vs)
+	}
+
+	vs, err = cache.FindLastValues(ctx, orgID, cachedID, influxdb.LastValueFilter{Tags: map[string]string{"host": "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vs, exp[1:]) {
+		t.Fatalf("expected last values %+v, got %+v", exp[1:], vs)
+	}
+
+	if _, err := cache.FindLastValues(ctx, orgID, uncachedID, influxdb.LastValueFilter{}); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected the cache of the bucket to be disabled, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package influxdb
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const (
+	OpFindLegalHoldByID = "FindLegalHoldByID"
+	OpFindLegalHolds    = "FindLegalHolds"
+	OpCreateLegalHold   = "CreateLegalHold"
+	OpReleaseLegalHold  = "ReleaseLegalHold"
+)
+
+// LegalHoldService manages the legal holds of buckets. Holds are never
+// deleted: released holds remain as the audit trail of the holds of a
+// bucket.
+type LegalHoldService interface {
+	// FindLegalHoldByID returns a single hold by ID.
+	FindLegalHoldByID(ctx context.Context, id ID) (*LegalHold, error)
+
+	// FindLegalHolds returns the holds matching filter, oldest first.
+	FindLegalHolds(ctx context.Context, filter LegalHoldFilter) ([]*LegalHold, error)
+
+	// CreateLegalHold places a hold on the data of a bucket of the
+	// organization of the hold, setting its ID and creation time.
+	CreateLegalHold(ctx context.Context, h *LegalHold) error
+
+	// ReleaseLegalHold releases an active hold. Returns the released hold.
+	ReleaseLegalHold(ctx context.Context, id ID, reason string) (*LegalHold, error)
+}
+
+// LegalHold pins the series of a bucket matching its predicate, within its
+// time range, so that neither retention enforcement nor deletes remove them
+// until the hold is released.
+type LegalHold struct {
+	ID       ID `json:"id"`
+	OrgID    ID `json:"orgID"`
+	BucketID ID `json:"bucketID"`
+	// Predicate selects the series held, in the syntax of the predicates of
+	// deletes. An empty predicate holds every series of the bucket.
+	Predicate string `json:"predicate,omitempty"`
+	// Start and Stop bound the time range held, inclusively. A nil bound
+	// leaves the range open on that side.
+	Start  *time.Time `json:"start,omitempty"`
+	Stop   *time.Time `json:"stop,omitempty"`
+	Reason string     `json:"reason"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy *ID       `json:"createdBy,omitempty"`
+
+	ReleasedAt    *time.Time `json:"releasedAt,omitempty"`
+	ReleasedBy    *ID        `json:"releasedBy,omitempty"`
+	ReleaseReason string     `json:"releaseReason,omitempty"`
+}
+
+// Active returns whether the hold has not been released.
+func (h *LegalHold) Active() bool {
+	return h.ReleasedAt == nil
+}
+
+// Range returns the time range held in nanoseconds since the epoch, open
+// bounds being the smallest and largest times.
+func (h *LegalHold) Range() (start, stop int64) {
+	start, stop = math.MinInt64, math.MaxInt64
+	if h.Start != nil {
+		start = h.Start.UnixNano()
+	}
+	if h.Stop != nil {
+		stop = h.Stop.UnixNano()
+	}
+	return start, stop
+}
+
+// Valid returns an error if the hold is invalid. Its predicate is checked by
+// the services which parse it.
+func (h *LegalHold) Valid() error {
+	if !h.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "legal hold requires an organization",
+		}
+	}
+	if !h.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "legal hold requires a bucket",
+		}
+	}
+	if h.Reason == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "legal hold requires a reason",
+		}
+	}
+	if h.Start != nil && h.Stop != nil && h.Stop.Before(*h.Start) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "legal hold stop must not be before its start",
+		}
+	}
+	return nil
+}
+
+// LegalHoldFilter represents a set of filters that restrict the returned
+// holds.
+type LegalHoldFilter struct {
+	OrgID    *ID
+	BucketID *ID
+	// Active restricts the holds to those active, or to those released.
+	Active *bool
+}
@@ -0,0 +1,257 @@
+// Package lifecycle runs the lifecycle policies of buckets.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+// checkInterval is how often policies are checked to see if they are due.
+var checkInterval = time.Minute
+
+// Runner runs each lifecycle policy every time it is due.
+//
+// A run applies the stages of a policy in order. The retention stage sets
+// the retention period of the bucket, which the retention enforcer of the
+// storage engine then applies. Each downsample stage aggregates the windows
+// of data that became old enough since the previous run with a Flux query
+// writing to the destination bucket. Each delete stage deletes the data
+// matching its predicate that is old enough. A stage failing does not stop
+// the stages after it; the status of the policy reports each of them.
+type Runner struct {
+	log      *zap.Logger
+	policies platform.LifecyclePolicyService
+	buckets  platform.BucketService
+	deletes  platform.DeleteService
+	qs       query.QueryService
+	now      func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner returns a Runner running the policies of policies.
+func NewRunner(log *zap.Logger, policies platform.LifecyclePolicyService, buckets platform.BucketService, deletes platform.DeleteService, qs query.QueryService) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		log:      log,
+		policies: policies,
+		buckets:  buckets,
+		deletes:  deletes,
+		qs:       qs,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Open starts running policies as they become due.
+func (r *Runner) Open(ctx context.Context) error {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			if err := r.runDue(r.ctx); err != nil {
+				r.log.Error("Failed to run lifecycle policies", zap.Error(err))
+			}
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops running policies, and waits for the running policy to finish.
+func (r *Runner) Close() error {
+	r.cancel()
+	r.wg.Wait()
+	return nil
+}
+
+func (r *Runner) runDue(ctx context.Context) error {
+	ps, err := r.policies.FindLifecyclePolicies(ctx, platform.LifecyclePolicyFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range ps {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !p.Due(r.now()) {
+			continue
+		}
+		if err := r.Run(ctx, p); err != nil {
+			r.log.Info("Lifecycle policy failed",
+				zap.Stringer("policy_id", p.ID),
+				zap.Stringer("bucket_id", p.BucketID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Run runs every stage of p, and updates its status. It returns an error if
+// any stage failed.
+func (r *Runner) Run(ctx context.Context, p *platform.LifecyclePolicy) error {
+	now := r.now().UTC()
+	status := platform.LifecyclePolicyStatus{LastRunAt: &now}
+
+	var failed int
+	stage := func(s platform.LifecycleStageStatus, err error) {
+		s.LastRunAt = now
+		if err != nil {
+			s.Error = err.Error()
+			failed++
+		}
+		status.Stages = append(status.Stages, s)
+	}
+
+	stage(platform.LifecycleStageStatus{Stage: platform.LifecycleStageRetention}, r.applyRetention(ctx, p))
+
+	for i, rule := range p.Downsample {
+		s := platform.LifecycleStageStatus{Stage: platform.LifecycleStageDownsample, Rule: i}
+		if prev := p.Status.StageStatus(platform.LifecycleStageDownsample, i); prev != nil {
+			s.Through = prev.Through
+		}
+		through, err := r.downsample(ctx, p, rule, now, s.Through)
+		if err == nil {
+			s.Through = through
+		}
+		stage(s, err)
+	}
+
+	for i, rule := range p.Deletes {
+		through := now.Add(-rule.After.Duration)
+		err := r.delete(ctx, p, rule, through)
+		s := platform.LifecycleStageStatus{Stage: platform.LifecycleStageDelete, Rule: i}
+		if err == nil {
+			s.Through = &through
+		} else if prev := p.Status.StageStatus(platform.LifecycleStageDelete, i); prev != nil {
+			s.Through = prev.Through
+		}
+		stage(s, err)
+	}
+
+	var err error
+	if failed > 0 {
+		err = fmt.Errorf("%d of %d stages failed", failed, len(status.Stages))
+		status.Error = err.Error()
+	}
+	p.Status = status
+	if uerr := r.policies.UpdateLifecyclePolicyStatus(ctx, p.ID, status); uerr != nil {
+		return uerr
+	}
+	return err
+}
+
+// applyRetention sets the retention period of the bucket of p.
+func (r *Runner) applyRetention(ctx context.Context, p *platform.LifecyclePolicy) error {
+	b, err := r.buckets.FindBucketByID(ctx, p.BucketID)
+	if err != nil {
+		return err
+	}
+	if b.RetentionPeriod == p.Retention.Duration {
+		return nil
+	}
+	_, err = r.buckets.UpdateBucket(ctx, p.BucketID, platform.BucketUpdate{RetentionPeriod: &p.Retention.Duration})
+	return err
+}
+
+// downsample aggregates the windows of data older than the age of rule that
+// end after through, and returns the end of the last window aggregated.
+func (r *Runner) downsample(ctx context.Context, p *platform.LifecyclePolicy, rule platform.DownsampleRule, now time.Time, through *time.Time) (*time.Time, error) {
+	start := time.Unix(0, 0).UTC()
+	if through != nil {
+		start = *through
+	}
+	stop := now.Add(-rule.After.Duration).Truncate(rule.Every.Duration)
+	if !stop.After(start) {
+		return through, nil
+	}
+
+	orgID, srcID, dstID := p.OrgID, p.BucketID, rule.DestinationBucketID
+	auth := &platform.Authorization{
+		ID:     p.ID,
+		OrgID:  p.OrgID,
+		Status: platform.Active,
+		Permissions: []platform.Permission{
+			{
+				Action:   platform.ReadAction,
+				Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID, ID: &srcID},
+			},
+			{
+				Action:   platform.WriteAction,
+				Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID, ID: &dstID},
+			},
+		},
+	}
+	req := &query.Request{
+		Authorization:  auth,
+		OrganizationID: p.OrgID,
+		Compiler:       lang.FluxCompiler{Query: downsampleScript(p, rule, start, stop)},
+	}
+	it, err := r.qs.Query(icontext.SetAuthorizer(ctx, auth), req)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Release()
+
+	for it.More() {
+		if err := it.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(flux.ColReader) error {
+				return nil
+			})
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return &stop, nil
+}
+
+// downsampleScript returns the Flux query downsampling the data of p in
+// [start, stop) with rule.
+func downsampleScript(p *platform.LifecyclePolicy, rule platform.DownsampleRule, start, stop time.Time) string {
+	return fmt.Sprintf(`from(bucketID: %q)
+	|> range(start: %s, stop: %s)
+	|> aggregateWindow(every: %dns, fn: %s, createEmpty: false)
+	|> to(bucketID: %q, orgID: %q)`,
+		p.BucketID.String(),
+		start.Format(time.RFC3339Nano), stop.Format(time.RFC3339Nano),
+		rule.Every.Nanoseconds(), rule.Aggregate,
+		rule.DestinationBucketID.String(), p.OrgID.String())
+}
+
+// delete deletes the data of p matching rule written before through.
+func (r *Runner) delete(ctx context.Context, p *platform.LifecyclePolicy, rule platform.DeleteRule, through time.Time) error {
+	node, err := predicate.Parse(rule.Predicate)
+	if err != nil {
+		return err
+	}
+	pred, err := predicate.New(node)
+	if err != nil {
+		return err
+	}
+	return r.deletes.DeleteBucketRangePredicate(ctx, p.OrgID, p.BucketID, math.MinInt64, through.UnixNano(), pred)
+}
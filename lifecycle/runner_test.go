@@ -0,0 +1,186 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	qmock "github.com/influxdata/influxdb/v2/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRunner_Run(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	src := &influxdb.Bucket{OrgID: org.ID, Name: "raw"}
+	if err := store.CreateBucket(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	dst := &influxdb.Bucket{OrgID: org.ID, Name: "hourly"}
+	if err := store.CreateBucket(ctx, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &influxdb.LifecyclePolicy{
+		OrgID:     org.ID,
+		BucketID:  src.ID,
+		Every:     influxdb.Duration{Duration: time.Hour},
+		Retention: influxdb.Duration{Duration: 30 * 24 * time.Hour},
+		Downsample: []influxdb.DownsampleRule{{
+			After:               influxdb.Duration{Duration: 7 * 24 * time.Hour},
+			Every:               influxdb.Duration{Duration: time.Hour},
+			Aggregate:           "mean",
+			DestinationBucketID: dst.ID,
+		}},
+		Deletes: []influxdb.DeleteRule{{
+			After:     influxdb.Duration{Duration: 24 * time.Hour},
+			Predicate: `_measurement="debug"`,
+		}},
+	}
+	if err := store.CreateLifecyclePolicy(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		scripts    []string
+		queryErr   error
+		deletedMax int64
+	)
+	qs := &qmock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			scripts = append(scripts, req.Compiler.(lang.FluxCompiler).Query)
+			if queryErr != nil {
+				return nil, queryErr
+			}
+			return flux.NewSliceResultIterator(nil), nil
+		},
+	}
+	deletes := mock.NewDeleteService()
+	deletes.DeleteBucketRangePredicateF = func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+		if bucketID != src.ID {
+			t.Errorf("deleted from bucket %s, want %s", bucketID, src.ID)
+		}
+		deletedMax = max
+		return nil
+	}
+
+	now := time.Date(2020, 6, 15, 10, 30, 0, 0, time.UTC)
+	r := NewRunner(zaptest.NewLogger(t), store, store, deletes, qs)
+	r.now = func() time.Time { return now }
+
+	if err := r.Run(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := store.FindBucketByID(ctx, src.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.RetentionPeriod != p.Retention.Duration {
+		t.Errorf("got retention %v, want %v", b.RetentionPeriod, p.Retention.Duration)
+	}
+	if len(scripts) != 1 || !strings.Contains(scripts[0], "range(start: 1970-01-01T00:00:00Z, stop: 2020-06-08T10:00:00Z)") {
+		t.Errorf("unexpected downsample scripts %q", scripts)
+	}
+	if want := now.Add(-24 * time.Hour).UnixNano(); deletedMax != want {
+		t.Errorf("got delete before %d, want %d", deletedMax, want)
+	}
+
+	got, err := store.FindLifecyclePolicyByID(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.LastRunAt == nil || !got.Status.LastRunAt.Equal(now) || got.Status.Error != "" {
+		t.Fatalf("unexpected status %+v", got.Status)
+	}
+	if len(got.Status.Stages) != 3 {
+		t.Fatalf("got %d stages, want 3", len(got.Status.Stages))
+	}
+	ds := got.Status.StageStatus(influxdb.LifecycleStageDownsample, 0)
+	if ds == nil || ds.Through == nil || !ds.Through.Equal(time.Date(2020, 6, 8, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected downsample status %+v", ds)
+	}
+	if got.Due(now.Add(time.Minute)) || !got.Due(now.Add(time.Hour)) {
+		t.Error("policy should be due an hour after it was run")
+	}
+
+	// The next run downsamples from where the previous one stopped, and a
+	// failing stage keeps its progress.
+	now = now.Add(2 * time.Hour)
+	scripts = nil
+	queryErr = errors.New("query failed")
+	if err := r.Run(ctx, got); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(scripts) != 1 || !strings.Contains(scripts[0], "range(start: 2020-06-08T10:00:00Z, stop: 2020-06-08T12:00:00Z)") {
+		t.Errorf("unexpected downsample scripts %q", scripts)
+	}
+
+	got, err = store.FindLifecyclePolicyByID(ctx, p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Error != "1 of 3 stages failed" {
+		t.Errorf("got status error %q", got.Status.Error)
+	}
+	ds = got.Status.StageStatus(influxdb.LifecycleStageDownsample, 0)
+	if ds == nil || ds.Error != "query failed" || ds.Through == nil || !ds.Through.Equal(time.Date(2020, 6, 8, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected downsample status %+v", ds)
+	}
+}
+
+func TestLifecyclePolicy_Valid(t *testing.T) {
+	valid := func() *influxdb.LifecyclePolicy {
+		return &influxdb.LifecyclePolicy{
+			OrgID:     1,
+			BucketID:  2,
+			Every:     influxdb.Duration{Duration: time.Hour},
+			Retention: influxdb.Duration{Duration: 24 * time.Hour},
+			Downsample: []influxdb.DownsampleRule{{
+				After:               influxdb.Duration{Duration: time.Hour},
+				Every:               influxdb.Duration{Duration: time.Hour},
+				Aggregate:           "max",
+				DestinationBucketID: 3,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *influxdb.LifecyclePolicy)
+		ok     bool
+	}{
+		{name: "valid", modify: func(p *influxdb.LifecyclePolicy) {}, ok: true},
+		{name: "no interval", modify: func(p *influxdb.LifecyclePolicy) { p.Every.Duration = 0 }},
+		{name: "unknown aggregate", modify: func(p *influxdb.LifecyclePolicy) { p.Downsample[0].Aggregate = "spread" }},
+		{name: "same bucket", modify: func(p *influxdb.LifecyclePolicy) { p.Downsample[0].DestinationBucketID = 2 }},
+		{name: "after retention", modify: func(p *influxdb.LifecyclePolicy) { p.Downsample[0].After.Duration = 23 * time.Hour }},
+		{name: "no predicate", modify: func(p *influxdb.LifecyclePolicy) { p.Deletes = []influxdb.DeleteRule{{}} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.modify(p)
+			if err := p.Valid(); (err == nil) != tt.ok {
+				t.Errorf("got error %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrLifecyclePolicyNotFound is the error for a missing lifecycle policy.
+const ErrLifecyclePolicyNotFound = "lifecycle policy not found"
+
+const (
+	OpFindLifecyclePolicyByID     = "FindLifecyclePolicyByID"
+	OpFindLifecyclePolicies       = "FindLifecyclePolicies"
+	OpCreateLifecyclePolicy       = "CreateLifecyclePolicy"
+	OpUpdateLifecyclePolicy       = "UpdateLifecyclePolicy"
+	OpUpdateLifecyclePolicyStatus = "UpdateLifecyclePolicyStatus"
+	OpDeleteLifecyclePolicy       = "DeleteLifecyclePolicy"
+)
+
+// Stages of a lifecycle policy, in the order they are run.
+const (
+	LifecycleStageRetention  = "retention"
+	LifecycleStageDownsample = "downsample"
+	LifecycleStageDelete     = "delete"
+)
+
+// LifecyclePolicyService manages the lifecycle policies of buckets.
+type LifecyclePolicyService interface {
+	// FindLifecyclePolicyByID returns a single lifecycle policy by ID.
+	FindLifecyclePolicyByID(ctx context.Context, id ID) (*LifecyclePolicy, error)
+
+	// FindLifecyclePolicies returns a list of lifecycle policies that match filter.
+	FindLifecyclePolicies(ctx context.Context, filter LifecyclePolicyFilter) ([]*LifecyclePolicy, error)
+
+	// CreateLifecyclePolicy creates a new lifecycle policy and sets p.ID
+	// with the new identifier. A bucket has at most one policy.
+	CreateLifecyclePolicy(ctx context.Context, p *LifecyclePolicy) error
+
+	// UpdateLifecyclePolicy updates a single lifecycle policy with changeset.
+	UpdateLifecyclePolicy(ctx context.Context, id ID, upd LifecyclePolicyUpdate) (*LifecyclePolicy, error)
+
+	// UpdateLifecyclePolicyStatus replaces the status of a lifecycle policy.
+	UpdateLifecyclePolicyStatus(ctx context.Context, id ID, status LifecyclePolicyStatus) error
+
+	// DeleteLifecyclePolicy removes a lifecycle policy by ID.
+	DeleteLifecyclePolicy(ctx context.Context, id ID) error
+}
+
+// LifecyclePolicy manages the data of a bucket over its lifetime. Each run
+// of a policy applies its stages in order: the retention period is set on the
+// bucket, data old enough to be downsampled is aggregated into other buckets,
+// and data matching delete rules is deleted.
+type LifecyclePolicy struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	BucketID    ID     `json:"bucketID"`
+	Description string `json:"description,omitempty"`
+
+	// Every is how often the policy is run.
+	Every Duration `json:"every"`
+
+	// Retention is the retention period of the bucket. Zero keeps data
+	// forever.
+	Retention Duration `json:"retention"`
+
+	Downsample []DownsampleRule `json:"downsample,omitempty"`
+	Deletes    []DeleteRule     `json:"deletes,omitempty"`
+
+	Status LifecyclePolicyStatus `json:"status"`
+	CRUDLog
+}
+
+// DownsampleRule aggregates the data of a bucket into windows once it is
+// older than After, and writes the aggregates to another bucket.
+type DownsampleRule struct {
+	After               Duration `json:"after"`
+	Every               Duration `json:"every"`
+	Aggregate           string   `json:"aggregate"`
+	DestinationBucketID ID       `json:"destinationBucketID"`
+}
+
+// DeleteRule deletes the data of a bucket matching a delete predicate once
+// it is older than After.
+type DeleteRule struct {
+	After     Duration `json:"after"`
+	Predicate string   `json:"predicate"`
+}
+
+// LifecyclePolicyStatus reports the runs of a lifecycle policy.
+type LifecyclePolicyStatus struct {
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	Error     string     `json:"error,omitempty"`
+
+	// Stages reports the last run of each stage of the policy.
+	Stages []LifecycleStageStatus `json:"stages,omitempty"`
+}
+
+// LifecycleStageStatus reports the last run of a stage of a lifecycle policy.
+// Rule is the index of the downsample or delete rule run by the stage.
+type LifecycleStageStatus struct {
+	Stage     string    `json:"stage"`
+	Rule      int       `json:"rule"`
+	LastRunAt time.Time `json:"lastRunAt"`
+	Error     string    `json:"error,omitempty"`
+
+	// Through is the time data was processed up to: the end of the last
+	// window downsampled, or the time data was deleted before.
+	Through *time.Time `json:"through,omitempty"`
+}
+
+// StageStatus returns the status of the stage running rule, or nil if the
+// stage has not run.
+func (s *LifecyclePolicyStatus) StageStatus(stage string, rule int) *LifecycleStageStatus {
+	for i := range s.Stages {
+		if s.Stages[i].Stage == stage && s.Stages[i].Rule == rule {
+			return &s.Stages[i]
+		}
+	}
+	return nil
+}
+
+// Due reports whether the policy should be run at now.
+func (p *LifecyclePolicy) Due(now time.Time) bool {
+	return p.Status.LastRunAt == nil || !now.Before(p.Status.LastRunAt.Add(p.Every.Duration))
+}
+
+// Valid returns an error if the lifecycle policy is invalid.
+func (p *LifecyclePolicy) Valid() error {
+	if !p.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	if !p.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket ID is required",
+		}
+	}
+	if p.Every.Duration <= 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "policy must run every positive duration",
+		}
+	}
+	if p.Retention.Duration < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "retention must not be negative",
+		}
+	}
+	for i, r := range p.Downsample {
+		if r.After.Duration < 0 || r.Every.Duration <= 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("downsample rule %d must have a positive window and a non-negative age", i),
+			}
+		}
+		switch r.Aggregate {
+		case "count", "first", "last", "max", "mean", "median", "min", "sum":
+		default:
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("downsample rule %d must aggregate with one of count, first, last, max, mean, median, min or sum", i),
+			}
+		}
+		if !r.DestinationBucketID.Valid() || r.DestinationBucketID == p.BucketID {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("downsample rule %d must write to another bucket", i),
+			}
+		}
+		// Data must be downsampled before it is removed by retention, even
+		// if it became old enough just after the policy was last run.
+		if p.Retention.Duration > 0 && r.After.Duration+r.Every.Duration+p.Every.Duration > p.Retention.Duration {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("downsample rule %d would downsample data after it is removed by retention", i),
+			}
+		}
+	}
+	for i, r := range p.Deletes {
+		if r.After.Duration < 0 || r.Predicate == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("delete rule %d must have a predicate and a non-negative age", i),
+			}
+		}
+	}
+	return nil
+}
+
+// LifecyclePolicyUpdate is the changeset of a lifecycle policy. Changing the
+// downsample rules restarts downsampling from the oldest data.
+type LifecyclePolicyUpdate struct {
+	Description *string          `json:"description,omitempty"`
+	Every       *Duration        `json:"every,omitempty"`
+	Retention   *Duration        `json:"retention,omitempty"`
+	Downsample  []DownsampleRule `json:"downsample"`
+	Deletes     []DeleteRule     `json:"deletes"`
+}
+
+// Apply applies the changeset to p.
+func (u LifecyclePolicyUpdate) Apply(p *LifecyclePolicy) {
+	if u.Description != nil {
+		p.Description = *u.Description
+	}
+	if u.Every != nil {
+		p.Every = *u.Every
+	}
+	if u.Retention != nil {
+		p.Retention = *u.Retention
+	}
+	if u.Downsample != nil {
+		p.Downsample = u.Downsample
+		stages := p.Status.Stages[:0]
+		for _, s := range p.Status.Stages {
+			if s.Stage != LifecycleStageDownsample {
+				stages = append(stages, s)
+			}
+		}
+		p.Status.Stages = stages
+	}
+	if u.Deletes != nil {
+		p.Deletes = u.Deletes
+	}
+}
+
+// LifecyclePolicyFilter represents a set of filters that restrict the
+// returned lifecycle policies.
+type LifecyclePolicyFilter struct {
+	OrgID    *ID
+	BucketID *ID
+}
+
+// Match returns true if the lifecycle policy matches the filter.
+func (f LifecyclePolicyFilter) Match(p *LifecyclePolicy) bool {
+	return (f.OrgID == nil || *f.OrgID == p.OrgID) &&
+		(f.BucketID == nil || *f.BucketID == p.BucketID)
+}
@@ -0,0 +1,163 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	OpFindLimitAlerts   = "FindLimitAlerts"
+	OpUpdateLimitAlerts = "UpdateLimitAlerts"
+)
+
+// The limits monitored by the built-in checks of an organization. They are
+// the values of the limit tag of the statuses of the checks.
+const (
+	// LimitAlertSeries checks the series cardinality of the organization
+	// against its series limit.
+	LimitAlertSeries = "series"
+	// LimitAlertStorage checks the bytes stored by the organization against
+	// its storage quota.
+	LimitAlertStorage = "storage"
+	// LimitAlertTaskFailureRate checks the share of the runs of the tasks
+	// of the organization which fail against its failure rate budget.
+	LimitAlertTaskFailureRate = "task_failure_rate"
+)
+
+// LimitAlertsCheckName is the check name of the statuses written by the
+// built-in checks of the platform limits.
+const LimitAlertsCheckName = "Platform limits"
+
+// LimitAlertsService manages the built-in checks which monitor the usage of
+// the platform by an organization against its budgets. The checks write
+// statuses to the _monitoring bucket of the organization, which are sent to
+// notification endpoints by its notification rules.
+type LimitAlertsService interface {
+	// FindLimitAlerts returns the checks of an organization. An organization
+	// which has not configured them has the default checks.
+	FindLimitAlerts(ctx context.Context, orgID ID) (*LimitAlerts, error)
+
+	// UpdateLimitAlerts updates the checks of an organization with
+	// changeset. Returns the new checks after update.
+	UpdateLimitAlerts(ctx context.Context, orgID ID, upd LimitAlertsUpdate) (*LimitAlerts, error)
+}
+
+// Defaults of the budgets of the built-in checks.
+const (
+	DefaultLimitAlertsSeriesLimit     = 1000000
+	DefaultLimitAlertsStorageQuota    = 100 << 30
+	DefaultLimitAlertsTaskFailureRate = 0.25
+	DefaultLimitAlertsMinTaskRuns     = 10
+	DefaultLimitAlertsWarnRatio       = 0.8
+	DefaultLimitAlertsCritRatio       = 0.95
+)
+
+// LimitAlerts are the budgets of an organization checked by its built-in
+// checks. A budget of zero disables its check.
+type LimitAlerts struct {
+	OrgID ID `json:"orgID"`
+	// Disabled turns off all the checks of the organization.
+	Disabled bool `json:"disabled"`
+	// SeriesLimit is the number of series of the organization.
+	SeriesLimit int64 `json:"seriesLimit"`
+	// StorageQuota is the number of bytes of the data stored by the
+	// organization, as compressed on disk.
+	StorageQuota int64 `json:"storageQuota"`
+	// TaskFailureRate is the share of the runs of the tasks of the
+	// organization finished between two checks which fail, from 0 to 1.
+	TaskFailureRate float64 `json:"taskFailureRate"`
+	// MinTaskRuns is the number of runs finished between two checks below
+	// which the task failure rate is not checked, so that a single failure
+	// of a rarely run task does not alert.
+	MinTaskRuns int `json:"minTaskRuns"`
+	// WarnRatio and CritRatio are the shares of a budget from which the
+	// level of its check is warn and crit.
+	WarnRatio float64   `json:"warnRatio"`
+	CritRatio float64   `json:"critRatio"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// DefaultLimitAlerts returns the checks of an organization which has not
+// configured them.
+func DefaultLimitAlerts(orgID ID) *LimitAlerts {
+	return &LimitAlerts{
+		OrgID:           orgID,
+		SeriesLimit:     DefaultLimitAlertsSeriesLimit,
+		StorageQuota:    DefaultLimitAlertsStorageQuota,
+		TaskFailureRate: DefaultLimitAlertsTaskFailureRate,
+		MinTaskRuns:     DefaultLimitAlertsMinTaskRuns,
+		WarnRatio:       DefaultLimitAlertsWarnRatio,
+		CritRatio:       DefaultLimitAlertsCritRatio,
+	}
+}
+
+// Valid returns an error if the checks are invalid.
+func (a *LimitAlerts) Valid() error {
+	if a.SeriesLimit < 0 || a.StorageQuota < 0 || a.MinTaskRuns < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "series limit, storage quota and min task runs must not be negative",
+		}
+	}
+	if a.TaskFailureRate < 0 || a.TaskFailureRate > 1 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "task failure rate must be between 0 and 1",
+		}
+	}
+	if a.WarnRatio <= 0 || a.WarnRatio > a.CritRatio || a.CritRatio > 1 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "warn ratio must be greater than 0 and at most crit ratio, and crit ratio at most 1",
+		}
+	}
+	return nil
+}
+
+// Level returns the level of a check whose usage of a budget is usage.
+func (a *LimitAlerts) Level(usage, budget float64) string {
+	switch {
+	case usage >= budget*a.CritRatio:
+		return "crit"
+	case usage >= budget*a.WarnRatio:
+		return "warn"
+	}
+	return "ok"
+}
+
+// LimitAlertsUpdate represents updates to the checks of an organization.
+// Only fields which are set are updated.
+type LimitAlertsUpdate struct {
+	Disabled        *bool    `json:"disabled,omitempty"`
+	SeriesLimit     *int64   `json:"seriesLimit,omitempty"`
+	StorageQuota    *int64   `json:"storageQuota,omitempty"`
+	TaskFailureRate *float64 `json:"taskFailureRate,omitempty"`
+	MinTaskRuns     *int     `json:"minTaskRuns,omitempty"`
+	WarnRatio       *float64 `json:"warnRatio,omitempty"`
+	CritRatio       *float64 `json:"critRatio,omitempty"`
+}
+
+// Apply applies the update to the checks a.
+func (u LimitAlertsUpdate) Apply(a *LimitAlerts) {
+	if u.Disabled != nil {
+		a.Disabled = *u.Disabled
+	}
+	if u.SeriesLimit != nil {
+		a.SeriesLimit = *u.SeriesLimit
+	}
+	if u.StorageQuota != nil {
+		a.StorageQuota = *u.StorageQuota
+	}
+	if u.TaskFailureRate != nil {
+		a.TaskFailureRate = *u.TaskFailureRate
+	}
+	if u.MinTaskRuns != nil {
+		a.MinTaskRuns = *u.MinTaskRuns
+	}
+	if u.WarnRatio != nil {
+		a.WarnRatio = *u.WarnRatio
+	}
+	if u.CritRatio != nil {
+		a.CritRatio = *u.CritRatio
+	}
+}
@@ -0,0 +1,299 @@
+// Package limitalert runs the built-in checks of the platform limits of
+// organizations. The checks compare the series cardinality, the bytes stored
+// and the task failure rate of each organization to its budgets, and write
+// their statuses to the _monitoring bucket of the organization as checks
+// do, so that its notification rules send them to its notification
+// endpoints.
+package limitalert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/tsi1"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+// DefaultInterval is how often the checks run by default.
+const DefaultInterval = 5 * time.Minute
+
+const (
+	statusesMeasurement = "statuses"
+	sourceMeasurement   = "platform_limits"
+	checkType           = "limit"
+	limitTag            = "limit"
+)
+
+// Usage reports the storage used by the buckets of all organizations, by the
+// encoded names of the buckets.
+type Usage interface {
+	// MeasurementCardinalityStats returns the number of series of each
+	// bucket.
+	MeasurementCardinalityStats() (tsi1.MeasurementCardinalityStats, error)
+	// MeasurementStats returns the number of bytes of the TSM data of each
+	// bucket.
+	MeasurementStats() (tsm1.MeasurementStats, error)
+}
+
+// taskRuns counts the runs of the tasks of an organization finished since the
+// previous checks.
+type taskRuns struct {
+	finished int
+	failed   int
+}
+
+// Checker periodically runs the built-in checks of the organizations.
+type Checker struct {
+	log      *zap.Logger
+	alerts   influxdb.LimitAlertsService
+	orgs     influxdb.OrganizationService
+	buckets  influxdb.BucketService
+	usage    Usage
+	pw       storage.PointsWriter
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	runs map[influxdb.ID]*taskRuns
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewChecker returns a Checker running the checks of alerts every interval,
+// which writes their statuses with pw.
+func NewChecker(log *zap.Logger, alerts influxdb.LimitAlertsService, orgs influxdb.OrganizationService, buckets influxdb.BucketService, usage Usage, pw storage.PointsWriter, interval time.Duration) *Checker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Checker{
+		log:      log,
+		alerts:   alerts,
+		orgs:     orgs,
+		buckets:  buckets,
+		usage:    usage,
+		pw:       pw,
+		interval: interval,
+		now:      time.Now,
+		runs:     make(map[influxdb.ID]*taskRuns),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Open starts running the checks periodically. An interval of zero disables
+// the checks.
+func (c *Checker) Open(ctx context.Context) error {
+	if c.interval <= 0 {
+		return nil
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := c.Check(c.ctx); err != nil {
+				c.log.Error("Failed to check platform limits", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops running the checks, and waits for the checks in progress to
+// finish.
+func (c *Checker) Close() error {
+	c.cancel()
+	c.wg.Wait()
+	return nil
+}
+
+// RecordRun counts a run of a task of the organization orgID which has
+// finished, for the next checks of the task failure rate.
+func (c *Checker) RecordRun(orgID influxdb.ID, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.runs[orgID]
+	if !ok {
+		r = &taskRuns{}
+		c.runs[orgID] = r
+	}
+	r.finished++
+	if failed {
+		r.failed++
+	}
+}
+
+// Check runs the checks of every organization once, and writes their
+// statuses. The runs of tasks counted since the previous checks are reset.
+func (c *Checker) Check(ctx context.Context) error {
+	orgs, _, err := c.orgs.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		return err
+	}
+
+	cardinality, err := c.usage.MeasurementCardinalityStats()
+	if err != nil {
+		return err
+	}
+	sizes, err := c.usage.MeasurementStats()
+	if err != nil {
+		return err
+	}
+	series, bytes := sumByOrg(cardinality), sumByOrg(sizes)
+
+	c.mu.Lock()
+	runs := c.runs
+	c.runs = make(map[influxdb.ID]*taskRuns)
+	c.mu.Unlock()
+
+	now := c.now()
+	for _, o := range orgs {
+		a, err := c.alerts.FindLimitAlerts(ctx, o.ID)
+		if err != nil {
+			c.log.Error("Failed to find limit alerts", zap.Stringer("orgID", o.ID), zap.Error(err))
+			continue
+		}
+		if a.Disabled {
+			continue
+		}
+
+		statuses := checkLimits(a, series[o.ID], bytes[o.ID], runs[o.ID])
+		if len(statuses) == 0 {
+			continue
+		}
+		if err := c.writeStatuses(ctx, o.ID, statuses, now); err != nil {
+			c.log.Error("Failed to write limit alert statuses", zap.Stringer("orgID", o.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// sumByOrg adds up the values of the buckets of each organization.
+func sumByOrg(stats map[string]int) map[influxdb.ID]int64 {
+	sums := make(map[influxdb.ID]int64)
+	for name, n := range stats {
+		// The names of buckets are the 16 bytes of the IDs of their
+		// organization and their own.
+		if len(name) != 16 {
+			continue
+		}
+		orgID, _ := tsdb.DecodeNameSlice([]byte(name))
+		sums[orgID] += int64(n)
+	}
+	return sums
+}
+
+// status is the status of a check of a limit.
+type status struct {
+	limit   string
+	level   string
+	message string
+	usage   float64
+	budget  float64
+}
+
+// checkLimits returns the statuses of the checks of a whose budgets are set.
+func checkLimits(a *influxdb.LimitAlerts, series, bytes int64, runs *taskRuns) []status {
+	var statuses []status
+	if a.SeriesLimit > 0 {
+		usage, budget := float64(series), float64(a.SeriesLimit)
+		statuses = append(statuses, status{
+			limit:   influxdb.LimitAlertSeries,
+			level:   a.Level(usage, budget),
+			message: fmt.Sprintf("Series cardinality is %d of the limit of %d (%.0f%%)", series, a.SeriesLimit, 100*usage/budget),
+			usage:   usage,
+			budget:  budget,
+		})
+	}
+	if a.StorageQuota > 0 {
+		usage, budget := float64(bytes), float64(a.StorageQuota)
+		statuses = append(statuses, status{
+			limit:   influxdb.LimitAlertStorage,
+			level:   a.Level(usage, budget),
+			message: fmt.Sprintf("Storage is %s of the quota of %s (%.0f%%)", formatBytes(bytes), formatBytes(a.StorageQuota), 100*usage/budget),
+			usage:   usage,
+			budget:  budget,
+		})
+	}
+	// Too few runs do not tell the failure rate, and leave the level of
+	// the check as it was.
+	if a.TaskFailureRate > 0 && runs != nil && runs.finished > 0 && runs.finished >= a.MinTaskRuns {
+		usage := float64(runs.failed) / float64(runs.finished)
+		statuses = append(statuses, status{
+			limit:   influxdb.LimitAlertTaskFailureRate,
+			level:   a.Level(usage, a.TaskFailureRate),
+			message: fmt.Sprintf("%d of %d task runs failed (%.0f%%), the budget is %.0f%%", runs.failed, runs.finished, 100*usage, 100*a.TaskFailureRate),
+			usage:   usage,
+			budget:  a.TaskFailureRate,
+		})
+	}
+	return statuses
+}
+
+// writeStatuses writes statuses to the _monitoring bucket of the
+// organization orgID, as the statuses of a check whose ID is the ID of the
+// organization.
+func (c *Checker) writeStatuses(ctx context.Context, orgID influxdb.ID, statuses []status, now time.Time) error {
+	b, err := c.buckets.FindBucketByName(ctx, orgID, influxdb.MonitoringSystemBucketName)
+	if err != nil {
+		return err
+	}
+
+	points := make([]models.Point, 0, len(statuses))
+	for _, st := range statuses {
+		tags := models.NewTags(map[string]string{
+			"_check_id":           orgID.String(),
+			"_check_name":         influxdb.LimitAlertsCheckName,
+			"_level":              st.level,
+			"_source_measurement": sourceMeasurement,
+			"_type":               checkType,
+			limitTag:              st.limit,
+		})
+		fields := map[string]interface{}{
+			"_message":          st.message,
+			"_source_timestamp": now.UnixNano(),
+			"usage":             st.usage,
+			"budget":            st.budget,
+		}
+		p, err := models.NewPoint(statusesMeasurement, tags, fields, now)
+		if err != nil {
+			return err
+		}
+		points = append(points, p)
+	}
+
+	exploded, err := tsdb.ExplodePoints(orgID, b.ID, points)
+	if err != nil {
+		return err
+	}
+	return c.pw.WritePoints(ctx, exploded)
+}
+
+// formatBytes formats n bytes with binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
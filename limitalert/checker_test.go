@@ -0,0 +1,113 @@
+package limitalert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/alerthistory"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/tsi1"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"go.uber.org/zap/zaptest"
+)
+
+type usage struct {
+	series tsi1.MeasurementCardinalityStats
+	bytes  tsm1.MeasurementStats
+}
+
+func (u *usage) MeasurementCardinalityStats() (tsi1.MeasurementCardinalityStats, error) {
+	return u.series, nil
+}
+
+func (u *usage) MeasurementStats() (tsm1.MeasurementStats, error) {
+	return u.bytes, nil
+}
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	quiet := &influxdb.Organization{Name: "quiet"}
+	for _, o := range []*influxdb.Organization{org, quiet} {
+		if err := store.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	disabled := true
+	if _, err := store.UpdateLimitAlerts(ctx, quiet.ID, influxdb.LimitAlertsUpdate{Disabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	seriesLimit, minRuns := int64(1000), 4
+	if _, err := store.UpdateLimitAlerts(ctx, org.ID, influxdb.LimitAlertsUpdate{
+		SeriesLimit: &seriesLimit,
+		MinTaskRuns: &minRuns,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &usage{
+		series: tsi1.MeasurementCardinalityStats{
+			tsdb.EncodeNameString(org.ID, 1):   600,
+			tsdb.EncodeNameString(org.ID, 2):   360,
+			tsdb.EncodeNameString(quiet.ID, 3): 5000,
+		},
+		bytes: tsm1.MeasurementStats{
+			tsdb.EncodeNameString(org.ID, 1): 1 << 30,
+		},
+	}
+	pw := &mock.PointsWriter{}
+	c := NewChecker(zaptest.NewLogger(t), store, store, store, u, pw, DefaultInterval)
+
+	for i := 0; i < 9; i++ {
+		c.RecordRun(org.ID, i < 2)
+	}
+	if err := c.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	levels := make(map[string]string)
+	for _, st := range alerthistory.Statuses(pw.Points) {
+		if st.OrgID != org.ID || st.CheckID != org.ID || st.CheckName != influxdb.LimitAlertsCheckName {
+			t.Errorf("unexpected status %+v", st)
+		}
+		levels[st.Tags["limit"]] = st.Level
+	}
+	want := map[string]string{
+		// 960 of 1000 series is over the crit ratio of 95%.
+		influxdb.LimitAlertSeries: "crit",
+		// 1 GiB of the default quota of 100 GiB.
+		influxdb.LimitAlertStorage: "ok",
+		// 2 failed runs of 9 are over the warn ratio of 80% of 25%.
+		influxdb.LimitAlertTaskFailureRate: "warn",
+	}
+	if len(levels) != len(want) {
+		t.Errorf("expected statuses %v, got %v", want, levels)
+	}
+	for limit, level := range want {
+		if levels[limit] != level {
+			t.Errorf("expected %s level of %s, got %q", limit, level, levels[limit])
+		}
+	}
+
+	// The runs are counted again after each check, and too few runs do not
+	// check the failure rate.
+	pw.Points = nil
+	c.RecordRun(org.ID, true)
+	if err := c.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range alerthistory.Statuses(pw.Points) {
+		if st.Tags["limit"] == influxdb.LimitAlertTaskFailureRate {
+			t.Errorf("expected the task failure rate not to be checked with a single run, got %+v", st)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package limitalert
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/task/backend"
+)
+
+// TaskFinder finds the tasks whose runs finish.
+type TaskFinder interface {
+	FindTaskByID(ctx context.Context, id influxdb.ID) (*influxdb.Task, error)
+}
+
+// TaskControlService is a backend.TaskControlService which counts the runs
+// of the tasks of each organization which finish, and those which fail, for
+// the checks of the task failure rate of a Checker.
+type TaskControlService struct {
+	backend.TaskControlService
+	tasks   TaskFinder
+	checker *Checker
+}
+
+// NewTaskControlService returns a TaskControlService which counts the runs of
+// s finishing with c.
+func NewTaskControlService(s backend.TaskControlService, tasks TaskFinder, c *Checker) *TaskControlService {
+	return &TaskControlService{
+		TaskControlService: s,
+		tasks:              tasks,
+		checker:            c,
+	}
+}
+
+// UpdateRunState sets the state of a run, and counts the run when it
+// succeeds or fails. Canceled runs are not counted.
+func (s *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error {
+	if err := s.TaskControlService.UpdateRunState(ctx, taskID, runID, when, state); err != nil {
+		return err
+	}
+	if state != influxdb.RunSuccess && state != influxdb.RunFail {
+		return nil
+	}
+
+	t, err := s.tasks.FindTaskByID(ctx, taskID)
+	if err != nil {
+		// The task may have been deleted while it ran.
+		return nil
+	}
+	s.checker.RecordRun(t.OrganizationID, state == influxdb.RunFail)
+	return nil
+}
@@ -0,0 +1,132 @@
+package influxdb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// MaskingMethod is how a masking rule hides the values it applies to.
+type MaskingMethod string
+
+const (
+	// MaskingHash replaces values with a hash of them, which keeps equal
+	// values equal so that series can still be grouped and joined.
+	MaskingHash MaskingMethod = "hash"
+	// MaskingTruncate keeps the first characters of values.
+	MaskingTruncate MaskingMethod = "truncate"
+	// MaskingRedact replaces values with RedactedValue.
+	MaskingRedact MaskingMethod = "redact"
+)
+
+// RedactedValue replaces the values of tags and string fields redacted by a
+// masking rule.
+const RedactedValue = "[redacted]"
+
+// MaskingRule masks the values of a field or tag of a bucket, so that series
+// holding personal data can be shared with analysts. Tokens with the read
+// permission on the unmasked resource of the bucket read the values as they
+// were written.
+//
+// Masking rules are applied by the query layer to the points and tag values
+// read from storage. Values of fields which are not strings cannot be
+// hashed or truncated, and are read as nulls.
+type MaskingRule struct {
+	// Measurement restricts the rule to the series of a measurement.
+	Measurement string `json:"measurement,omitempty"`
+	// Field is the key of the field masked. Exactly one of Field and Tag is
+	// set.
+	Field string `json:"field,omitempty"`
+	// Tag is the key of the tag masked.
+	Tag    string        `json:"tag,omitempty"`
+	Method MaskingMethod `json:"method"`
+	// Length is the number of characters kept by the truncate method.
+	Length int `json:"length,omitempty"`
+}
+
+// ValidMaskingRules returns an error if any of the masking rules of a
+// bucket is invalid, or if two of them mask the same field or tag.
+func ValidMaskingRules(rules []MaskingRule) error {
+	type target struct{ measurement, field, tag string }
+	seen := make(map[target]bool, len(rules))
+	for i, r := range rules {
+		if (r.Field == "") == (r.Tag == "") {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid masking rule %d: exactly one of field and tag must be set", i),
+			}
+		}
+		if r.Tag == "_measurement" || r.Tag == "_field" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid masking rule %d: tag %q cannot be masked", i, r.Tag),
+			}
+		}
+		switch r.Method {
+		case MaskingHash, MaskingRedact:
+		case MaskingTruncate:
+			if r.Length <= 0 {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("invalid masking rule %d: length must be positive", i),
+				}
+			}
+		default:
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid masking rule %d: unknown method %q", i, r.Method),
+			}
+		}
+		t := target{measurement: r.Measurement, field: r.Field, tag: r.Tag}
+		if seen[t] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("duplicate masking rule %d", i),
+			}
+		}
+		seen[t] = true
+	}
+	return nil
+}
+
+// Mask returns the masked value of v, a value of a tag or string field of
+// the bucket. Hashes are salted with the ID of the bucket, so that the
+// hashes of a value differ from one bucket to another.
+func (r MaskingRule) Mask(bucketID ID, v string) string {
+	switch r.Method {
+	case MaskingHash:
+		h := sha256.New()
+		h.Write([]byte(bucketID.String()))
+		h.Write([]byte(v))
+		return hex.EncodeToString(h.Sum(nil))[:32]
+	case MaskingTruncate:
+		if rs := []rune(v); len(rs) > r.Length {
+			return string(rs[:r.Length])
+		}
+		return v
+	default:
+		return RedactedValue
+	}
+}
+
+// FindMaskingRule returns the rule masking the field, if field is true, or
+// the tag key of measurement. The rule of the measurement is preferred to
+// the rule of every measurement.
+func FindMaskingRule(rules []MaskingRule, measurement, key string, field bool) (MaskingRule, bool) {
+	var (
+		def   MaskingRule
+		found bool
+	)
+	for _, r := range rules {
+		if field && r.Field != key || !field && r.Tag != key {
+			continue
+		}
+		switch r.Measurement {
+		case measurement:
+			return r, true
+		case "":
+			def, found = r, true
+		}
+	}
+	return def, found
+}
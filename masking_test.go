@@ -0,0 +1,21 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestMaskingRule_Mask(t *testing.T) {
+	hash := influxdb.MaskingRule{Method: influxdb.MaskingHash}
+	if a, b := hash.Mask(1, "alice"), hash.Mask(1, "alice"); a != b || a == "alice" {
+		t.Errorf("expected equal values to have equal hashes, got %q and %q", a, b)
+	}
+	if a, b := hash.Mask(1, "alice"), hash.Mask(2, "alice"); a == b {
+		t.Errorf("expected the hashes of buckets to differ, got %q", a)
+	}
+	truncate := influxdb.MaskingRule{Method: influxdb.MaskingTruncate, Length: 2}
+	if got := truncate.Mask(1, "ünïcode"); got != "ün" {
+		t.Errorf("expected the first characters to be kept, got %q", got)
+	}
+}
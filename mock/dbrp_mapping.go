@@ -7,11 +7,14 @@ import (
 )
 
 type DBRPMappingService struct {
-	FindByFn   func(ctx context.Context, cluster string, db string, rp string) (*platform.DBRPMapping, error)
-	FindFn     func(ctx context.Context, filter platform.DBRPMappingFilter) (*platform.DBRPMapping, error)
-	FindManyFn func(ctx context.Context, filter platform.DBRPMappingFilter, opt ...platform.FindOptions) ([]*platform.DBRPMapping, int, error)
-	CreateFn   func(ctx context.Context, dbrpMap *platform.DBRPMapping) error
-	DeleteFn   func(ctx context.Context, cluster string, db string, rp string) error
+	FindByFn     func(ctx context.Context, cluster string, db string, rp string) (*platform.DBRPMapping, error)
+	FindFn       func(ctx context.Context, filter platform.DBRPMappingFilter) (*platform.DBRPMapping, error)
+	FindManyFn   func(ctx context.Context, filter platform.DBRPMappingFilter, opt ...platform.FindOptions) ([]*platform.DBRPMapping, int, error)
+	CreateFn     func(ctx context.Context, dbrpMap *platform.DBRPMapping) error
+	UpsertFn     func(ctx context.Context, dbrpMap *platform.DBRPMapping) error
+	ReplaceFn    func(ctx context.Context, dbrpMap *platform.DBRPMapping, revision string) error
+	SetDefaultFn func(ctx context.Context, cluster string, db string, rp string) (*platform.DBRPMapping, error)
+	DeleteFn     func(ctx context.Context, cluster string, db string, rp string) error
 }
 
 func NewDBRPMappingService() *DBRPMappingService {
@@ -26,6 +29,13 @@ func NewDBRPMappingService() *DBRPMappingService {
 			return nil, 0, nil
 		},
 		CreateFn: func(ctx context.Context, dbrpMap *platform.DBRPMapping) error { return nil },
+		UpsertFn: func(ctx context.Context, dbrpMap *platform.DBRPMapping) error { return nil },
+		ReplaceFn: func(ctx context.Context, dbrpMap *platform.DBRPMapping, revision string) error {
+			return nil
+		},
+		SetDefaultFn: func(ctx context.Context, cluster string, db string, rp string) (*platform.DBRPMapping, error) {
+			return nil, nil
+		},
 		DeleteFn: func(ctx context.Context, cluster string, db string, rp string) error { return nil },
 	}
 }
@@ -46,6 +56,18 @@ func (s *DBRPMappingService) Create(ctx context.Context, dbrpMap *platform.DBRPM
 	return s.CreateFn(ctx, dbrpMap)
 }
 
+func (s *DBRPMappingService) Upsert(ctx context.Context, dbrpMap *platform.DBRPMapping) error {
+	return s.UpsertFn(ctx, dbrpMap)
+}
+
+func (s *DBRPMappingService) Replace(ctx context.Context, dbrpMap *platform.DBRPMapping, revision string) error {
+	return s.ReplaceFn(ctx, dbrpMap, revision)
+}
+
+func (s *DBRPMappingService) SetDefault(ctx context.Context, cluster string, db string, rp string) (*platform.DBRPMapping, error) {
+	return s.SetDefaultFn(ctx, cluster, db, rp)
+}
+
 func (s *DBRPMappingService) Delete(ctx context.Context, cluster string, db string, rp string) error {
 	return s.DeleteFn(ctx, cluster, db, rp)
 }
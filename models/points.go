@@ -431,6 +431,19 @@ func ParsePointsWithOptions(buf []byte, mm []byte, opts ...ParserOption) (_ []Po
 	return pp.points, err
 }
 
+// ParsePointsReader is similar to ParsePointsWithOptions, but parses the
+// line protocol read from r without reading all of it in memory. Lines are
+// read into a buffer of bufSize bytes, or DefaultParserBufferSize if bufSize
+// is 0, which only grows to hold a line longer than it.
+//
+// Unlike ParsePointsWithOptions, the returned Points do not refer to the data
+// read from r. Errors returned by r, other than io.EOF, are returned as is.
+func ParsePointsReader(r io.Reader, mm []byte, bufSize int, opts ...ParserOption) (_ []Point, err error) {
+	pp := newPointsParser(mm, opts...)
+	err = pp.parsePointsReader(r, bufSize)
+	return pp.points, err
+}
+
 // ParsePointsWithPrecision is similar to ParsePoints, but allows the
 // caller to provide a precision for time.
 //
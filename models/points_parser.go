@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 	"unsafe"
@@ -75,6 +76,14 @@ func WithParserStats(s *ParserStats) ParserOption {
 	}
 }
 
+// DefaultParserBufferSize is the default size of the buffer lines are read
+// into by ParsePointsReader.
+const DefaultParserBufferSize = 64 * 1024
+
+// parserSlabSize is the size of the slabs the fields of points are copied to
+// by ParsePointsReader.
+const parserSlabSize = 32 * 1024
+
 type parserState int
 
 const (
@@ -92,8 +101,14 @@ type pointsParser struct {
 	defaultTime time.Time // truncated time to assign to points which have no associated timestamp.
 	precision   string
 	points      []Point
+	failed      []string
 	state       parserState
 	stats       *ParserStats
+
+	// copyFields is set when the fields of the points are copied out of the
+	// parsed buffer, into slab.
+	copyFields bool
+	slab       []byte
 }
 
 func newPointsParser(orgBucket []byte, opts ...ParserOption) *pointsParser {
@@ -125,15 +140,72 @@ func (pp *pointsParser) parsePoints(buf []byte) (err error) {
 	}
 
 	pp.points = make([]Point, 0, lineCount+1)
+	pp.parseLines(buf, true)
+	return pp.err()
+}
+
+// parsePointsReader parses the points of the lines read from r. Lines are
+// read into a buffer of bufSize bytes, which only grows to hold lines longer
+// than it, and the fields of the points are copied out of it so that it is
+// reused for the following lines.
+func (pp *pointsParser) parsePointsReader(r io.Reader, bufSize int) error {
+	if bufSize <= 0 {
+		bufSize = DefaultParserBufferSize
+	}
+	pp.copyFields = true
 
 	var (
-		pos    int
-		block  []byte
-		failed []string
+		buf       = make([]byte, 0, bufSize)
+		lineCount int
+	)
+	for pp.state == parserStateOK {
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		eof := err == io.EOF
+		if err != nil && !eof {
+			return err
+		}
+
+		end := pp.parseLines(buf, eof)
+
+		lines := bytes.Count(buf[:end], []byte{'\n'})
+		lineCount += lines
+		if pp.maxLines > 0 && lineCount > pp.maxLines {
+			return ErrLimitMaxLinesExceeded
+		}
+		if !pp.checkAlloc(lines, int(unsafe.Sizeof(Point(nil)))) {
+			return ErrLimitMaxBytesExceeded
+		}
+
+		if eof {
+			break
+		}
+		if end == 0 && len(buf) == cap(buf) {
+			nbuf := make([]byte, len(buf), 2*cap(buf))
+			copy(nbuf, buf)
+			buf = nbuf
+			continue
+		}
+		buf = buf[:copy(buf, buf[end:])]
+	}
+	return pp.err()
+}
+
+// parseLines appends the points of the lines of buf, recording the lines which
+// could not be parsed, and returns the number of bytes parsed. Unless final is
+// set, the last line of buf is left unparsed, as the bytes following it are
+// needed to know whether it is complete.
+func (pp *pointsParser) parseLines(buf []byte, final bool) int {
+	var (
+		pos, next int
+		block     []byte
 	)
 	for pos < len(buf) && pp.state == parserStateOK {
-		pos, block = scanLine(buf, pos)
-		pos++
+		next, block = scanLine(buf, pos)
+		if !final && next+1 >= len(buf) {
+			break
+		}
+		pos = next + 1
 
 		if len(block) == 0 {
 			continue
@@ -156,7 +228,7 @@ func (pp *pointsParser) parsePoints(buf []byte) (err error) {
 			block = block[:len(block)-1]
 		}
 
-		err = pp.parsePointsAppend(block[start:])
+		err := pp.parsePointsAppend(block[start:])
 		if err != nil {
 			if errors.Is(err, errLimit) {
 				break
@@ -167,10 +239,17 @@ func (pp *pointsParser) parsePoints(buf []byte) (err error) {
 				break
 			}
 
-			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
+			pp.failed = append(pp.failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
 		}
 	}
+	if pos > len(buf) {
+		pos = len(buf)
+	}
+	return pos
+}
 
+// err returns the error of the lines parsed so far.
+func (pp *pointsParser) err() error {
 	if pp.stats != nil {
 		pp.stats.BytesN = pp.bytesN
 	}
@@ -186,8 +265,8 @@ func (pp *pointsParser) parsePoints(buf []byte) (err error) {
 		}
 	}
 
-	if len(failed) > 0 {
-		return fmt.Errorf("%s", strings.Join(failed, "\n"))
+	if len(pp.failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(pp.failed, "\n"))
 	}
 
 	return nil
@@ -235,6 +314,15 @@ func (pp *pointsParser) parsePointsAppend(buf []byte) error {
 		return fmt.Errorf("missing fields")
 	}
 
+	// The fields are referenced by the points, so they are copied when the
+	// buffer is reused.
+	if pp.copyFields {
+		if !pp.checkAlloc(1, len(fields)) {
+			return errLimit
+		}
+		fields = pp.copyBytes(fields)
+	}
+
 	// scan the last block which is an optional integer timestamp
 	pos, ts, err := scanTime(buf, pos)
 	if err != nil {
@@ -298,6 +386,21 @@ func (pp *pointsParser) append(p point) error {
 	return nil
 }
 
+// copyBytes returns a copy of b. Copies are allocated from slabs to avoid an
+// allocation per line.
+func (pp *pointsParser) copyBytes(b []byte) []byte {
+	if len(b) > cap(pp.slab)-len(pp.slab) {
+		n := parserSlabSize
+		if len(b) > n {
+			n = len(b)
+		}
+		pp.slab = make([]byte, 0, n)
+	}
+	i := len(pp.slab)
+	pp.slab = append(pp.slab, b...)
+	return pp.slab[i:len(pp.slab):len(pp.slab)]
+}
+
 func (pp *pointsParser) checkAlloc(n, size int) bool {
 	newBytes := pp.bytesN + (n * size)
 	if pp.maxBytes > 0 && newBytes > pp.maxBytes {
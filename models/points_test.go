@@ -14,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -2592,6 +2593,77 @@ func TestParsePointsWithOptions(t *testing.T) {
 	}
 }
 
+func TestParsePointsReader(t *testing.T) {
+	encoded := EncodeName(ID(1000), ID(2000))
+	mm := models.EscapeMeasurement(encoded[:])
+	now := time.Unix(0, 1000)
+
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{name: "test data", buf: mustReadTestData(t, "line-protocol.txt", 1)},
+		{name: "no trailing newline", buf: []byte("cpu value=1 1\ncpu value=2 2")},
+		{name: "carriage returns", buf: []byte("cpu value=1 1\r\ncpu value=2 2\r\n")},
+		{name: "comments and blank lines", buf: []byte("# comment\n\n   \ncpu value=1 1\n# cpu value=2 2\n")},
+		{name: "newline in string", buf: []byte("cpu str=\"foo\nbar\",value=1 1\ncpu str=\"\n\n\" 2\n")},
+		{name: "escaped characters", buf: []byte("cpu\\,a\\ b,t\\=1=v\\ 1 value=1,str=\"a\\\"b\" 1\n")},
+		{name: "default time", buf: []byte("cpu value=1\n")},
+		{name: "invalid lines", buf: []byte("cpu value=1 1\ncpu\ncpu value=2 2\ncpu value= 3\n")},
+		{name: "empty", buf: nil},
+	}
+
+	readers := map[string]func(io.Reader) io.Reader{
+		"reader":      func(r io.Reader) io.Reader { return r },
+		"one byte":    iotest.OneByteReader,
+		"half reader": iotest.HalfReader,
+	}
+
+	for _, test := range tests {
+		exp, expErr := models.ParsePointsWithOptions(test.buf, mm, models.WithParserDefaultTime(now))
+		for name, reader := range readers {
+			for _, bufSize := range []int{0, 1, 7, 64} {
+				t.Run(fmt.Sprintf("%s/%s/%d", test.name, name, bufSize), func(t *testing.T) {
+					got, err := models.ParsePointsReader(reader(bytes.NewReader(test.buf)), mm, bufSize, models.WithParserDefaultTime(now))
+					if fmt.Sprint(err) != fmt.Sprint(expErr) {
+						t.Fatalf("unexpected error: got %v, exp %v", err, expErr)
+					}
+					if len(got) != len(exp) {
+						t.Fatalf("unexpected number of points: got %d, exp %d", len(got), len(exp))
+					}
+					for i := range got {
+						if got[i].String() != exp[i].String() {
+							t.Fatalf("unexpected point %d: got %v, exp %v", i, got[i], exp[i])
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestParsePointsReader_Errors(t *testing.T) {
+	encoded := EncodeName(ID(1000), ID(2000))
+	mm := models.EscapeMeasurement(encoded[:])
+	buf := mustReadTestData(t, "line-protocol.txt", 1)
+
+	if _, err := models.ParsePointsReader(bytes.NewReader(buf), mm, 64, models.WithParserMaxLines(10)); err != models.ErrLimitMaxLinesExceeded {
+		t.Errorf("unexpected error: got %v, exp %v", err, models.ErrLimitMaxLinesExceeded)
+	}
+	if _, err := models.ParsePointsReader(bytes.NewReader(buf), mm, 64, models.WithParserMaxValues(10)); err != models.ErrLimitMaxValuesExceeded {
+		t.Errorf("unexpected error: got %v, exp %v", err, models.ErrLimitMaxValuesExceeded)
+	}
+	if _, err := models.ParsePointsReader(bytes.NewReader(buf), mm, 64, models.WithParserMaxBytes(10000)); err != models.ErrLimitMaxBytesExceeded {
+		t.Errorf("unexpected error: got %v, exp %v", err, models.ErrLimitMaxBytesExceeded)
+	}
+
+	// Errors of the reader are returned as is.
+	r := iotest.TimeoutReader(iotest.OneByteReader(bytes.NewReader(buf)))
+	if _, err := models.ParsePointsReader(r, mm, 64); err != iotest.ErrTimeout {
+		t.Errorf("unexpected error: got %v, exp %v", err, iotest.ErrTimeout)
+	}
+}
+
 func TestNewPointsWithBytesWithCorruptData(t *testing.T) {
 	corrupted := []byte{0, 0, 0, 3, 102, 111, 111, 0, 0, 0, 4, 61, 34, 65, 34, 1, 0, 0, 0, 14, 206, 86, 119, 24, 32, 72, 233, 168, 2, 148}
 	p, err := models.NewPointFromBytes(corrupted)
@@ -3230,3 +3302,43 @@ func BenchmarkParsePointsWithOptions(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkParsePointsReader(b *testing.B) {
+	cases := []struct {
+		name   string
+		repeat int
+	}{
+		{"line-protocol.txt", 1},
+		{"line-protocol.txt", 315},
+	}
+
+	for _, tc := range cases {
+		// Reading all the data before parsing it is the baseline.
+		b.Run(fmt.Sprintf("%s/%d/read all", tc.name, tc.repeat), func(b *testing.B) {
+			benchParseFile(b, tc.name, tc.repeat, func(b *testing.B, buf []byte, mm []byte, now time.Time) {
+				for i := 0; i < b.N; i++ {
+					data, err := ioutil.ReadAll(bytes.NewReader(buf))
+					if err != nil {
+						b.Fatal(err)
+					}
+					pts, err := models.ParsePointsWithOptions(data, mm)
+					if err != nil {
+						b.Errorf("error parsing points: %v", err)
+					}
+					_ = pts
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("%s/%d/stream", tc.name, tc.repeat), func(b *testing.B) {
+			benchParseFile(b, tc.name, tc.repeat, func(b *testing.B, buf []byte, mm []byte, now time.Time) {
+				for i := 0; i < b.N; i++ {
+					pts, err := models.ParsePointsReader(bytes.NewReader(buf), mm, 0)
+					if err != nil {
+						b.Errorf("error parsing points: %v", err)
+					}
+					_ = pts
+				}
+			})
+		})
+	}
+}
@@ -23,6 +23,13 @@ type Deadman struct {
 	// TODO(desa): Is this implemented in Flux?
 	ReportZero bool                    `json:"reportZero"`
 	Level      notification.CheckLevel `json:"level"`
+	// GroupBy are the tag keys of the groups checked, such as host to alert
+	// for each host that stops reporting. The groups are discovered from the
+	// data within StaleTime. If it is empty each series is checked.
+	GroupBy []string `json:"groupBy,omitempty"`
+	// ResolvedMessageTemplate is the message of the statuses of the groups
+	// which report again, instead of StatusMessageTemplate.
+	ResolvedMessageTemplate string `json:"resolvedMessageTemplate,omitempty"`
 }
 
 // Type returns the type of the check.
@@ -30,6 +37,23 @@ func (c Deadman) Type() string {
 	return "deadman"
 }
 
+// Valid returns an error if the deadman check is invalid.
+func (c Deadman) Valid() error {
+	if err := c.Base.Valid(); err != nil {
+		return err
+	}
+	for _, key := range c.GroupBy {
+		switch key {
+		case "", "_time", "_value", "_field", "_start", "_stop":
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("deadman check cannot group by %q", key),
+			}
+		}
+	}
+	return nil
+}
+
 // GenerateFlux returns a flux script for the Deadman provided.
 func (c Deadman) GenerateFlux() (string, error) {
 	p, err := c.GenerateFluxAST()
@@ -77,6 +101,18 @@ func (c Deadman) generateFluxASTBody() []ast.Statement {
 	return append(statements, c.generateFluxASTChecksFunction())
 }
 
+func (c Deadman) generateFluxASTMessageFunction() ast.Statement {
+	if c.ResolvedMessageTemplate == "" {
+		return c.Base.generateFluxASTMessageFunction()
+	}
+	msg := flux.If(
+		flux.Equal(flux.Member("r", "_level"), flux.String("ok")),
+		flux.String(c.ResolvedMessageTemplate),
+		flux.String(c.StatusMessageTemplate),
+	)
+	return flux.DefineVariable("messageFn", flux.Function(flux.FunctionParams("r"), msg))
+}
+
 func (c Deadman) generateLevelFn() ast.Statement {
 	fn := flux.Function(flux.FunctionParams("r"), flux.Member("r", "dead"))
 
@@ -89,12 +125,20 @@ func (c Deadman) generateFluxASTChecksFunction() ast.Statement {
 	dur := (*ast.DurationLiteral)(c.TimeSince)
 	now := flux.Call(flux.Identifier("now"), flux.Object())
 	sub := flux.Call(flux.Member("experimental", "subDuration"), flux.Object(flux.Property("from", now), flux.Property("d", dur)))
-	return flux.ExpressionStatement(flux.Pipe(
-		flux.Identifier("data"),
+	var calls []*ast.CallExpression
+	if len(c.GroupBy) > 0 {
+		columns := []ast.Expression{flux.String("_measurement")}
+		for _, key := range c.GroupBy {
+			columns = append(columns, flux.String(key))
+		}
+		calls = append(calls, flux.Call(flux.Identifier("group"), flux.Object(flux.Property("columns", flux.Array(columns...)))))
+	}
+	calls = append(calls,
 		flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
 		flux.Call(flux.Member("monitor", "deadman"), flux.Object(flux.Property("t", sub))),
 		c.generateFluxASTChecksCall(),
-	))
+	)
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("data"), calls...))
 }
 
 func (c Deadman) generateFluxASTChecksCall() *ast.CallExpression {
@@ -1,6 +1,7 @@
 package check_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
@@ -228,3 +229,45 @@ data
 	}
 
 }
+
+func TestDeadman_GroupBy(t *testing.T) {
+	deadman := check.Deadman{
+		Base: check.Base{
+			ID:                    10,
+			Name:                  "moo",
+			OrgID:                 20,
+			OwnerID:               30,
+			Every:                 mustDuration("1m"),
+			StatusMessageTemplate: "dead",
+			Query: influxdb.DashboardQuery{
+				Text: `from(bucket: "foo") |> range(start: -1d) |> filter(fn: (r) => r._measurement == "cpu")`,
+			},
+		},
+		TimeSince:               mustDuration("90s"),
+		StaleTime:               mustDuration("1h"),
+		Level:                   notification.Critical,
+		GroupBy:                 []string{"host"},
+		ResolvedMessageTemplate: "alive",
+	}
+	if err := deadman.Valid(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s, err := deadman.GenerateFlux()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		`group(columns: ["_measurement", "host"])`,
+		`if r["_level"] == "ok" then "alive" else "dead"`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected script to contain %s, got:\n%s", want, s)
+		}
+	}
+
+	deadman.GroupBy = []string{"_field"}
+	if err := deadman.Valid(); err == nil {
+		t.Fatal("expected grouping by _field to be invalid")
+	}
+}
@@ -18,6 +18,11 @@ var _ influxdb.Check = (*Threshold)(nil)
 type Threshold struct {
 	Base
 	Thresholds []ThresholdConfig `json:"thresholds"`
+	// Streaming evaluates the thresholds against each point written to the
+	// bucket of the query as it is written, in addition to the task of the
+	// check, so that the statuses of critical signals change within the
+	// write. The query must be built with the query builder.
+	Streaming bool `json:"streaming,omitempty"`
 }
 
 // Type returns the type of the check.
@@ -35,12 +40,32 @@ func (t Threshold) Valid() error {
 			return err
 		}
 	}
+	if t.Streaming && (t.Query.EditMode != "builder" || len(t.Query.BuilderConfig.Buckets) != 1) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "streaming threshold check must query a single bucket with the query builder",
+		}
+	}
 	return nil
 }
 
+// Level returns the greatest level of the thresholds which the value v
+// meets, or notification.Ok if it meets none of them. Each value is compared
+// on its own, so AllValues does not apply.
+func (t Threshold) Level(v float64) notification.CheckLevel {
+	level := notification.Ok
+	for _, cc := range t.Thresholds {
+		if cc.GetLevel() > level && cc.matches(v) {
+			level = cc.GetLevel()
+		}
+	}
+	return level
+}
+
 type thresholdDecode struct {
 	Base
 	Thresholds []thresholdConfigDecode `json:"thresholds"`
+	Streaming  bool                    `json:"streaming,omitempty"`
 }
 
 type thresholdConfigDecode struct {
@@ -59,6 +84,7 @@ func (t *Threshold) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	t.Base = tdRaws.Base
+	t.Streaming = tdRaws.Streaming
 	for _, tdRaw := range tdRaws.Thresholds {
 		switch tdRaw.Type {
 		case "lesser":
@@ -298,6 +324,21 @@ func (t Threshold) generateFluxASTThresholdFunctions(field string) []ast.Stateme
 	return thresholdStatements
 }
 
+func (td Greater) matches(v float64) bool {
+	return v > td.Value
+}
+
+func (td Lesser) matches(v float64) bool {
+	return v < td.Value
+}
+
+func (td Range) matches(v float64) bool {
+	if !td.Within {
+		return v < td.Min || v > td.Max
+	}
+	return v < td.Max && v > td.Min
+}
+
 func (td Greater) generateFluxASTThresholdFunction(field string) ast.Statement {
 	fnBody := flux.GreaterThan(flux.Member("r", field), flux.Float(td.Value))
 	fn := flux.Function(flux.FunctionParams("r"), fnBody)
@@ -357,6 +398,7 @@ type ThresholdConfig interface {
 	Valid() error
 	Type() string
 	generateFluxASTThresholdFunction(string) ast.Statement
+	matches(v float64) bool
 	GetLevel() notification.CheckLevel
 }
 
@@ -406,3 +406,25 @@ data
 	}
 
 }
+
+func TestThreshold_Level(t *testing.T) {
+	th := check.Threshold{
+		Thresholds: []check.ThresholdConfig{
+			&check.Lesser{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Info}, Value: 10},
+			&check.Range{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Warn}, Min: 50, Max: 90, Within: true},
+			&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 80},
+		},
+	}
+	for _, tt := range []struct {
+		value float64
+		level notification.CheckLevel
+	}{
+		{value: 5, level: notification.Info},
+		{value: 30, level: notification.Ok},
+		{value: 60, level: notification.Warn},
+		// The greatest level of the thresholds met wins.
+		{value: 85, level: notification.Critical},
+	} {
+		assert.Equal(t, tt.level, th.Level(tt.value), "level of %v", tt.value)
+	}
+}
@@ -0,0 +1,73 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"net/mail"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.NotificationEndpoint = &Email{}
+
+// Email is the notification endpoint config of email. Mail is sent through
+// the SMTP server of the instance.
+type Email struct {
+	Base
+	// To are the addresses mail is sent to.
+	To []string `json:"to"`
+	// Cc are the addresses mail is copied to.
+	Cc []string `json:"cc,omitempty"`
+}
+
+// BackfillSecretKeys is a noop, the credentials of email endpoints are those
+// of the SMTP server of the instance.
+func (s *Email) BackfillSecretKeys() {}
+
+// SecretFields return available secret fields.
+func (s Email) SecretFields() []influxdb.SecretField {
+	return []influxdb.SecretField{}
+}
+
+// Valid returns error if some configuration is invalid
+func (s Email) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if len(s.To) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "email endpoint requires at least one to address",
+		}
+	}
+	for _, addrs := range [][]string{s.To, s.Cc} {
+		for _, addr := range addrs {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "email endpoint address " + addr + " is invalid",
+					Err:  err,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type emailAlias Email
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Email) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			emailAlias
+			Type string `json:"type"`
+		}{
+			emailAlias: emailAlias(s),
+			Type:       s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Email) Type() string {
+	return EmailType
+}
@@ -136,6 +136,58 @@ func TestValidEndpoint(t *testing.T) {
 				Msg:  "invalid http username/password for basic auth",
 			},
 		},
+		{
+			name: "slack app without token",
+			src: &endpoint.Slack{
+				Base: goodBase,
+				App:  true,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack app endpoint token must be provided",
+			},
+		},
+		{
+			name: "slack signing secret without app",
+			src: &endpoint.Slack{
+				Base:          goodBase,
+				URL:           "localhost",
+				SigningSecret: influxdb.SecretField{Key: id1 + "-signing-secret"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack signing secret requires an app endpoint",
+			},
+		},
+		{
+			name: "slack app",
+			src: &endpoint.Slack{
+				Base:          goodBase,
+				App:           true,
+				Token:         influxdb.SecretField{Key: id1 + "-token"},
+				SigningSecret: influxdb.SecretField{Key: id1 + "-signing-secret"},
+			},
+			err: nil,
+		},
+		{
+			name: "empty email to",
+			src: &endpoint.Email{
+				Base: goodBase,
+				Cc:   []string{"ops@example.com"},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "email endpoint requires at least one to address",
+			},
+		},
+		{
+			name: "valid email",
+			src: &endpoint.Email{
+				Base: goodBase,
+				To:   []string{"Ops <ops@example.com>", "oncall@example.com"},
+			},
+			err: nil,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -186,6 +238,24 @@ func TestJSON(t *testing.T) {
 				URL: "https://hooks.slack.com/services/x/y/z",
 			},
 		},
+		{
+			name: "Slack app",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				App:           true,
+				Token:         influxdb.SecretField{Key: "token-key-1"},
+				SigningSecret: influxdb.SecretField{Key: "signing-secret-key-1"},
+			},
+		},
 		{
 			name: "simple pagerduty",
 			src: &endpoint.PagerDuty{
@@ -226,6 +296,23 @@ func TestJSON(t *testing.T) {
 				Password:   influxdb.SecretField{Key: "password-key"},
 			},
 		},
+		{
+			name: "simple email",
+			src: &endpoint.Email{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				To: []string{"ops@example.com"},
+				Cc: []string{"oncall@example.com"},
+			},
+		},
 	}
 	for _, c := range cases {
 		b, err := json.Marshal(c.src)
@@ -284,6 +371,37 @@ func TestBackFill(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Slack app",
+			src: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+				},
+				App:           true,
+				Token:         influxdb.SecretField{Value: strPtr("token-value")},
+				SigningSecret: influxdb.SecretField{Value: strPtr("signing-secret-value")},
+			},
+			target: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+				},
+				App: true,
+				Token: influxdb.SecretField{
+					Key:   id1 + "-token",
+					Value: strPtr("token-value"),
+				},
+				SigningSecret: influxdb.SecretField{
+					Key:   id1 + "-signing-secret",
+					Value: strPtr("signing-secret-value"),
+				},
+			},
+		},
 		{
 			name: "simple pagerduty",
 			src: &endpoint.PagerDuty{
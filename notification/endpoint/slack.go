@@ -10,7 +10,13 @@ import (
 
 var _ influxdb.NotificationEndpoint = &Slack{}
 
-const slackTokenSuffix = "-token"
+const (
+	slackTokenSuffix         = "-token"
+	slackSigningSecretSuffix = "-signing-secret"
+)
+
+// SlackAPIURL is the default base URL of the Web API of a slack app.
+const SlackAPIURL = "https://slack.com/api"
 
 // Slack is the notification endpoint config of slack.
 type Slack struct {
@@ -21,6 +27,13 @@ type Slack struct {
 	URL string `json:"url"`
 	// Token is the bearer token for authorization
 	Token influxdb.SecretField `json:"token"`
+	// App posts alerts with the Web API of a slack app, as the bot of Token,
+	// and threads the updates of an alert onto its message. URL is then the
+	// base URL of the Web API, SlackAPIURL if it is empty.
+	App bool `json:"app,omitempty"`
+	// SigningSecret verifies the interactions of users with the messages of
+	// a slack app. If it is set, alerts may be acknowledged from slack.
+	SigningSecret influxdb.SecretField `json:"signingSecret"`
 }
 
 // BackfillSecretKeys fill back fill the secret field key during the unmarshalling
@@ -29,6 +42,9 @@ func (s *Slack) BackfillSecretKeys() {
 	if s.Token.Key == "" && s.Token.Value != nil {
 		s.Token.Key = s.idStr() + slackTokenSuffix
 	}
+	if s.SigningSecret.Key == "" && s.SigningSecret.Value != nil {
+		s.SigningSecret.Key = s.idStr() + slackSigningSecretSuffix
+	}
 }
 
 // SecretFields return available secret fields.
@@ -37,20 +53,44 @@ func (s Slack) SecretFields() []influxdb.SecretField {
 	if s.Token.Key != "" {
 		arr = append(arr, s.Token)
 	}
+	if s.SigningSecret.Key != "" {
+		arr = append(arr, s.SigningSecret)
+	}
 	return arr
 }
 
+// APIURL returns the base URL of the Web API of a slack app.
+func (s Slack) APIURL() string {
+	if s.URL == "" {
+		return SlackAPIURL
+	}
+	return s.URL
+}
+
 // Valid returns error if some configuration is invalid
 func (s Slack) Valid() error {
 	if err := s.Base.valid(); err != nil {
 		return err
 	}
-	if s.URL == "" {
+	if s.App {
+		if s.Token.Key == "" && s.Token.Value == nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "slack app endpoint token must be provided",
+			}
+		}
+	} else if s.URL == "" {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Msg:  "slack endpoint URL must be provided",
 		}
 	}
+	if !s.App && (s.SigningSecret.Key != "" || s.SigningSecret.Value != nil) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "slack signing secret requires an app endpoint",
+		}
+	}
 	if s.URL != "" {
 		if _, err := url.Parse(s.URL); err != nil {
 			return &influxdb.Error{
@@ -0,0 +1,142 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// Email is the notification rule config of email. The templates are in the
+// template syntax of the rule; Flux strings may interpolate the columns of a
+// status, e.g. ${r._message}.
+type Email struct {
+	Base
+	SubjectTemplate string `json:"subjectTemplate"`
+	BodyTemplate    string `json:"bodyTemplate"`
+}
+
+// GenerateFlux generates a flux script for the email notification rule.
+func (s *Email) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
+	emailEndpoint, ok := e.(*endpoint.Email)
+	if !ok {
+		return "", fmt.Errorf("endpoint provided is a %s, not an Email endpoint", e.Type())
+	}
+	p, err := s.GenerateFluxAST(emailEndpoint)
+	if err != nil {
+		return "", err
+	}
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST generates a flux AST for the email notification rule.
+func (s *Email) GenerateFluxAST(e *endpoint.Email) (*ast.Package, error) {
+	body, err := s.generateFluxASTBody(e)
+	if err != nil {
+		return nil, err
+	}
+	imports := append([]string{"influxdata/influxdb/monitor", "influxdata/influxdb/email", "experimental"}, s.templateImports()...)
+	f := flux.File(
+		s.Name,
+		flux.Imports(imports...),
+		body,
+	)
+	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
+}
+
+func (s *Email) generateFluxASTBody(e *endpoint.Email) ([]ast.Statement, error) {
+	var statements []ast.Statement
+	statements = append(statements, s.generateTaskOption())
+	statements = append(statements, s.generateFluxASTEndpoint(e))
+	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
+	statements = append(statements, s.generateFluxASTStatuses())
+	statements = append(statements, s.generateLevelChecks()...)
+	notify, err := s.generateFluxASTNotifyPipe()
+	if err != nil {
+		return nil, err
+	}
+	statements = append(statements, notify)
+
+	return statements, nil
+}
+
+func (s *Email) generateFluxASTEndpoint(e *endpoint.Email) ast.Statement {
+	props := []*ast.Property{}
+	props = append(props, flux.Property("to", flux.String(strings.Join(e.To, ", "))))
+	if len(e.Cc) > 0 {
+		props = append(props, flux.Property("cc", flux.String(strings.Join(e.Cc, ", "))))
+	}
+	call := flux.Call(flux.Member("email", "endpoint"), flux.Object(props...))
+
+	return flux.DefineVariable("email_endpoint", call)
+}
+
+func (s *Email) generateFluxASTNotifyPipe() (ast.Statement, error) {
+	subject, err := s.templateExpr(s.SubjectTemplate)
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.templateExpr(s.BodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+	endpointProps := []*ast.Property{}
+	endpointProps = append(endpointProps, flux.Property("subject", subject))
+	endpointProps = append(endpointProps, flux.Property("body", body))
+	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(endpointProps...))
+
+	props := []*ast.Property{}
+	props = append(props, flux.Property("data", flux.Identifier("notification")))
+	props = append(props, flux.Property("endpoint",
+		flux.Call(flux.Identifier("email_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+
+	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
+
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call)), nil
+}
+
+type emailAlias Email
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Email) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			emailAlias
+			Type string `json:"type"`
+		}{
+			emailAlias: emailAlias(s),
+			Type:       s.Type(),
+		})
+}
+
+// Valid returns where the config is valid.
+func (s Email) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.SubjectTemplate == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "email subject template is empty",
+		}
+	}
+	if s.BodyTemplate == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "email body template is empty",
+		}
+	}
+	if err := s.validTemplate("email subject template", s.SubjectTemplate); err != nil {
+		return err
+	}
+	return s.validTemplate("email body template", s.BodyTemplate)
+}
+
+// Type returns the type of the rule config.
+func (s Email) Type() string {
+	return "email"
+}
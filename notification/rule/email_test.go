@@ -0,0 +1,85 @@
+package rule_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+func TestEmail_GenerateFlux(t *testing.T) {
+	want := `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "influxdata/influxdb/email"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+email_endpoint = email["endpoint"](to: "ops@example.com, Oncall <oncall@example.com>", cc: "lead@example.com")
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000002",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h, fn: (r) =>
+	(r["foo"] == "bar"))
+crit = statuses
+	|> filter(fn: (r) =>
+		(r["_level"] == "crit"))
+all_statuses = crit
+	|> filter(fn: (r) =>
+		(r["_time"] > experimental["subDuration"](from: now(), d: 1h)))
+
+all_statuses
+	|> monitor["notify"](data: notification, endpoint: email_endpoint(mapFn: (r) =>
+		({subject: "${r._check_name} is ${r._level}", body: "${r._message}"})))`
+
+	r := &rule.Email{
+		SubjectTemplate: "${r._check_name} is ${r._level}",
+		BodyTemplate:    "${r._message}",
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			TagRules: []notification.TagRule{
+				{
+					Tag: influxdb.Tag{
+						Key:   "foo",
+						Value: "bar",
+					},
+					Operator: influxdb.Equal,
+				},
+			},
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+		},
+	}
+	e := &endpoint.Email{
+		Base: endpoint.Base{
+			ID:   idPtr(2),
+			Name: "foo",
+		},
+		To: []string{"ops@example.com", "Oncall <oncall@example.com>"},
+		Cc: []string{"lead@example.com"},
+	}
+
+	f, err := r.GenerateFlux(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != want {
+		t.Errorf("scripts did not match. want:\n%v\n\ngot:\n%v", want, f)
+	}
+
+	if _, err := r.GenerateFlux(&endpoint.Slack{}); err == nil {
+		t.Error("expected generating with a slack endpoint to fail")
+	}
+}
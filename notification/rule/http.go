@@ -10,9 +10,13 @@ import (
 	"github.com/influxdata/influxdb/v2/notification/flux"
 )
 
-// HTTP is the notification rule config of http.
+// HTTP is the notification rule config of http. The body posted is the
+// status as JSON, unless the body template is set.
 type HTTP struct {
 	Base
+	// BodyTemplate is the template of the body posted, in the template
+	// syntax of the rule.
+	BodyTemplate string `json:"bodyTemplate,omitempty"`
 }
 
 // GenerateFlux generates a flux script for the http notification rule.
@@ -30,10 +34,14 @@ func (s *HTTP) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
 
 // GenerateFluxAST generates a flux AST for the http notification rule.
 func (s *HTTP) GenerateFluxAST(e *endpoint.HTTP) (*ast.Package, error) {
+	body, err := s.generateFluxASTBody(e)
+	if err != nil {
+		return nil, err
+	}
 	f := flux.File(
 		s.Name,
 		s.imports(e),
-		s.generateFluxASTBody(e),
+		body,
 	)
 	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
 }
@@ -49,11 +57,14 @@ func (s *HTTP) imports(e *endpoint.HTTP) []*ast.ImportDeclaration {
 	if e.AuthMethod == "bearer" || e.AuthMethod == "basic" {
 		packages = append(packages, "influxdata/influxdb/secrets")
 	}
+	if s.BodyTemplate != "" {
+		packages = append(packages, s.templateImports()...)
+	}
 
 	return flux.Imports(packages...)
 }
 
-func (s *HTTP) generateFluxASTBody(e *endpoint.HTTP) []ast.Statement {
+func (s *HTTP) generateFluxASTBody(e *endpoint.HTTP) ([]ast.Statement, error) {
 	var statements []ast.Statement
 	statements = append(statements, s.generateTaskOption())
 	statements = append(statements, s.generateHeaders(e))
@@ -61,9 +72,13 @@ func (s *HTTP) generateFluxASTBody(e *endpoint.HTTP) []ast.Statement {
 	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
 	statements = append(statements, s.generateFluxASTStatuses())
 	statements = append(statements, s.generateLevelChecks()...)
-	statements = append(statements, s.generateFluxASTNotifyPipe())
+	notify, err := s.generateFluxASTNotifyPipe()
+	if err != nil {
+		return nil, err
+	}
+	statements = append(statements, notify)
 
-	return statements
+	return statements, nil
 }
 
 func (s *HTTP) generateHeaders(e *endpoint.HTTP) ast.Statement {
@@ -121,11 +136,18 @@ func (s *HTTP) generateFluxASTEndpoint(e *endpoint.HTTP) ast.Statement {
 	return flux.DefineVariable("endpoint", call)
 }
 
-func (s *HTTP) generateFluxASTNotifyPipe() ast.Statement {
+func (s *HTTP) generateFluxASTNotifyPipe() (ast.Statement, error) {
 	endpointBody := flux.Call(
 		flux.Member("json", "encode"),
 		flux.Object(flux.Property("v", flux.Identifier("body"))),
 	)
+	if s.BodyTemplate != "" {
+		tmpl, err := s.templateExpr(s.BodyTemplate)
+		if err != nil {
+			return nil, err
+		}
+		endpointBody = flux.Call(flux.Identifier("bytes"), flux.Object(flux.Property("v", tmpl)))
+	}
 	headers := flux.Property("headers", flux.Identifier("headers"))
 
 	endpointProps := []*ast.Property{
@@ -146,7 +168,7 @@ func (s *HTTP) generateFluxASTNotifyPipe() ast.Statement {
 
 	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
 
-	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call)), nil
 }
 
 func (s *HTTP) generateBody() ast.Statement {
@@ -180,7 +202,7 @@ func (s HTTP) Valid() error {
 	if err := s.Base.valid(); err != nil {
 		return err
 	}
-	return nil
+	return s.validTemplate("http body template", s.BodyTemplate)
 }
 
 // Type returns the type of the rule config.
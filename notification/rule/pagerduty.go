@@ -10,7 +10,9 @@ import (
 	"github.com/influxdata/influxdb/v2/notification/flux"
 )
 
-// PagerDuty is the rule config of pagerduty notification.
+// PagerDuty is the rule config of pagerduty notification. The summary of an
+// event is the message of its status, unless the message template is a Go
+// template.
 type PagerDuty struct {
 	Base
 	MessageTemplate string `json:"messageTemplate"`
@@ -41,7 +43,7 @@ func (s PagerDuty) Valid() error {
 			Msg:  "pagerduty invalid message template",
 		}
 	}
-	return nil
+	return s.validTemplate("pagerduty message template", s.MessageTemplate)
 }
 
 // Type returns the type of the rule config.
@@ -64,15 +66,20 @@ func (s *PagerDuty) GenerateFlux(e influxdb.NotificationEndpoint) (string, error
 
 // GenerateFluxAST generates a flux AST for the pagerduty notification rule.
 func (s *PagerDuty) GenerateFluxAST(e *endpoint.PagerDuty) (*ast.Package, error) {
+	body, err := s.generateFluxASTBody(e)
+	if err != nil {
+		return nil, err
+	}
+	imports := append([]string{"influxdata/influxdb/monitor", "pagerduty", "influxdata/influxdb/secrets", "experimental"}, s.templateImports()...)
 	f := flux.File(
 		s.Name,
-		flux.Imports("influxdata/influxdb/monitor", "pagerduty", "influxdata/influxdb/secrets", "experimental"),
-		s.generateFluxASTBody(e),
+		flux.Imports(imports...),
+		body,
 	)
 	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
 }
 
-func (s *PagerDuty) generateFluxASTBody(e *endpoint.PagerDuty) []ast.Statement {
+func (s *PagerDuty) generateFluxASTBody(e *endpoint.PagerDuty) ([]ast.Statement, error) {
 	var statements []ast.Statement
 	statements = append(statements, s.generateTaskOption())
 	statements = append(statements, s.generateFluxASTSecrets(e))
@@ -80,9 +87,13 @@ func (s *PagerDuty) generateFluxASTBody(e *endpoint.PagerDuty) []ast.Statement {
 	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
 	statements = append(statements, s.generateFluxASTStatuses())
 	statements = append(statements, s.generateLevelChecks()...)
-	statements = append(statements, s.generateFluxASTNotifyPipe(e.ClientURL))
+	notify, err := s.generateFluxASTNotifyPipe(e.ClientURL)
+	if err != nil {
+		return nil, err
+	}
+	statements = append(statements, notify)
 
-	return statements
+	return statements, nil
 }
 
 func (s *PagerDuty) generateFluxASTSecrets(e *endpoint.PagerDuty) ast.Statement {
@@ -99,7 +110,15 @@ func (s *PagerDuty) generateFluxASTEndpoint(e *endpoint.PagerDuty) ast.Statement
 	return flux.DefineVariable("pagerduty_endpoint", call)
 }
 
-func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
+func (s *PagerDuty) generateFluxASTNotifyPipe(url string) (ast.Statement, error) {
+	var summary ast.Expression = flux.Member("r", "_message")
+	if s.goTemplates() {
+		var err error
+		if summary, err = s.templateExpr(s.MessageTemplate); err != nil {
+			return nil, err
+		}
+	}
+
 	endpointProps := []*ast.Property{}
 
 	// routing_key:
@@ -154,7 +173,7 @@ func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
 	// required
 	// string
 	// A brief text summary of the event, used to generate the summaries/titles of any associated alerts. The maximum permitted length of this property is 1024 characters.
-	endpointProps = append(endpointProps, flux.Property("summary", flux.Member("r", "_message")))
+	endpointProps = append(endpointProps, flux.Property("summary", summary))
 
 	// timestamp:
 	// optional
@@ -171,7 +190,7 @@ func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
 
 	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
 
-	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call)), nil
 }
 
 func severityFromLevel() *ast.CallExpression {
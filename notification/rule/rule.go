@@ -16,6 +16,7 @@ var typeToRule = map[string](func() influxdb.NotificationRule){
 	"slack":     func() influxdb.NotificationRule { return &Slack{} },
 	"pagerduty": func() influxdb.NotificationRule { return &PagerDuty{} },
 	"http":      func() influxdb.NotificationRule { return &HTTP{} },
+	"email":     func() influxdb.NotificationRule { return &Email{} },
 }
 
 // UnmarshalJSON will convert
@@ -57,6 +58,9 @@ type Base struct {
 	RunbookLink string                    `json:"runbookLink"`
 	TagRules    []notification.TagRule    `json:"tagRules,omitempty"`
 	StatusRules []notification.StatusRule `json:"statusRules,omitempty"`
+	// TemplateSyntax is the syntax of the message templates of the rule,
+	// TemplateSyntaxFlux if it is empty.
+	TemplateSyntax string `json:"templateSyntax,omitempty"`
 	*influxdb.Limit
 	influxdb.CRUDLog
 }
@@ -103,6 +107,9 @@ func (b Base) valid() error {
 			return err
 		}
 	}
+	if err := templateSyntaxValid(b.TemplateSyntax); err != nil {
+		return err
+	}
 	if b.Limit != nil {
 		if b.Limit.Every <= 0 || b.Limit.Rate <= 0 {
 			return &influxdb.Error{
@@ -10,7 +10,8 @@ import (
 	"github.com/influxdata/influxdb/v2/notification/flux"
 )
 
-// Slack is the notification rule config of slack.
+// Slack is the notification rule config of slack. The message template is
+// in the template syntax of the rule.
 type Slack struct {
 	Base
 	Channel         string `json:"channel"`
@@ -32,15 +33,24 @@ func (s *Slack) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
 
 // GenerateFluxAST generates a flux AST for the slack notification rule.
 func (s *Slack) GenerateFluxAST(e *endpoint.Slack) (*ast.Package, error) {
+	slackPkg := "slack"
+	if e.App {
+		slackPkg = "influxdata/influxdb/slackapp"
+	}
+	body, err := s.generateFluxASTBody(e)
+	if err != nil {
+		return nil, err
+	}
+	imports := append([]string{"influxdata/influxdb/monitor", slackPkg, "influxdata/influxdb/secrets", "experimental"}, s.templateImports()...)
 	f := flux.File(
 		s.Name,
-		flux.Imports("influxdata/influxdb/monitor", "slack", "influxdata/influxdb/secrets", "experimental"),
-		s.generateFluxASTBody(e),
+		flux.Imports(imports...),
+		body,
 	)
 	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
 }
 
-func (s *Slack) generateFluxASTBody(e *endpoint.Slack) []ast.Statement {
+func (s *Slack) generateFluxASTBody(e *endpoint.Slack) ([]ast.Statement, error) {
 	var statements []ast.Statement
 	statements = append(statements, s.generateTaskOption())
 	if e.Token.Key != "" {
@@ -50,9 +60,13 @@ func (s *Slack) generateFluxASTBody(e *endpoint.Slack) []ast.Statement {
 	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
 	statements = append(statements, s.generateFluxASTStatuses())
 	statements = append(statements, s.generateLevelChecks()...)
-	statements = append(statements, s.generateFluxASTNotifyPipe())
+	notify, err := s.generateFluxASTNotifyPipe()
+	if err != nil {
+		return nil, err
+	}
+	statements = append(statements, notify)
 
-	return statements
+	return statements, nil
 }
 
 func (s *Slack) generateFluxASTSecrets(e *endpoint.Slack) ast.Statement {
@@ -62,6 +76,9 @@ func (s *Slack) generateFluxASTSecrets(e *endpoint.Slack) ast.Statement {
 }
 
 func (s *Slack) generateFluxASTEndpoint(e *endpoint.Slack) ast.Statement {
+	if e.App {
+		return s.generateFluxASTAppEndpoint(e)
+	}
 	props := []*ast.Property{}
 	if e.Token.Key != "" {
 		props = append(props, flux.Property("token", flux.Identifier("slack_secret")))
@@ -74,11 +91,32 @@ func (s *Slack) generateFluxASTEndpoint(e *endpoint.Slack) ast.Statement {
 	return flux.DefineVariable("slack_endpoint", call)
 }
 
-func (s *Slack) generateFluxASTNotifyPipe() ast.Statement {
+// generateFluxASTAppEndpoint generates the endpoint of a slack app, which
+// threads the statuses of an alert onto its message.
+func (s *Slack) generateFluxASTAppEndpoint(e *endpoint.Slack) ast.Statement {
+	props := []*ast.Property{}
+	if e.URL != "" {
+		props = append(props, flux.Property("url", flux.String(e.URL)))
+	}
+	props = append(props, flux.Property("token", flux.Identifier("slack_secret")))
+	props = append(props, flux.Property("endpointID", flux.String(e.GetID().String())))
+	if e.SigningSecret.Key != "" {
+		props = append(props, flux.Property("acknowledge", flux.Bool(true)))
+	}
+	call := flux.Call(flux.Member("slackapp", "endpoint"), flux.Object(props...))
+
+	return flux.DefineVariable("slack_endpoint", call)
+}
+
+func (s *Slack) generateFluxASTNotifyPipe() (ast.Statement, error) {
+	text, err := s.templateExpr(s.MessageTemplate)
+	if err != nil {
+		return nil, err
+	}
 	endpointProps := []*ast.Property{}
 	endpointProps = append(endpointProps, flux.Property("channel", flux.String(s.Channel)))
 	// TODO(desa): are these values correct?
-	endpointProps = append(endpointProps, flux.Property("text", flux.String(s.MessageTemplate)))
+	endpointProps = append(endpointProps, flux.Property("text", text))
 	endpointProps = append(endpointProps, flux.Property("color", s.generateSlackColors()))
 	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(endpointProps...))
 
@@ -89,7 +127,7 @@ func (s *Slack) generateFluxASTNotifyPipe() ast.Statement {
 
 	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
 
-	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call)), nil
 }
 
 func (s *Slack) generateSlackColors() ast.Expression {
@@ -130,7 +168,7 @@ func (s Slack) Valid() error {
 			Msg:  "slack msg template is empty",
 		}
 	}
-	return nil
+	return s.validTemplate("slack msg template", s.MessageTemplate)
 }
 
 // Type returns the type of the rule config.
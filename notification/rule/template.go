@@ -0,0 +1,376 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// The syntaxes of the message templates of a rule.
+const (
+	// TemplateSyntaxFlux templates are Flux strings, which may interpolate
+	// the columns of a status, e.g. ${r._message}. It is the default.
+	TemplateSyntaxFlux = "flux"
+	// TemplateSyntaxGo templates are Go templates, e.g. {{.Message}}, which
+	// are compiled to Flux when the script of the rule is generated.
+	TemplateSyntaxGo = "go"
+)
+
+// templateColumns are the columns of a status by the fields of the data of
+// a Go template.
+var templateColumns = map[string]string{
+	"CheckID":                  "_check_id",
+	"CheckName":                "_check_name",
+	"Level":                    "_level",
+	"Message":                  "_message",
+	"NotificationRuleID":       "_notification_rule_id",
+	"NotificationRuleName":     "_notification_rule_name",
+	"NotificationEndpointID":   "_notification_endpoint_id",
+	"NotificationEndpointName": "_notification_endpoint_name",
+	"SourceMeasurement":        "_source_measurement",
+	"Type":                     "_type",
+}
+
+// templateCompiler compiles a Go template to a Flux expression of a string,
+// evaluated for each status r notified.
+//
+// The data of a template is a status. Its fields are those of
+// templateColumns, Time, the time of the status, Tags and Values, the tags
+// and field values of the status by key, e.g. {{.Tags.host}} or
+// {{index .Values "usage_idle"}}, and Links, the Runbook link of the rule
+// and the Check path of the UI. Templates may only output those fields and
+// branch on them with if, else, eq, ne, not, and and or; a bare field is
+// true if it is set. They are compiled to Flux so that they are rendered by
+// the task of the rule, like Flux templates are.
+type templateCompiler struct {
+	orgID       influxdb.ID
+	runbookLink string
+}
+
+// compile returns the Flux expression of the Go template tmpl.
+func (c templateCompiler) compile(tmpl string) (ast.Expression, error) {
+	t, err := template.New("message").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if t.Tree == nil {
+		return flux.String(""), nil
+	}
+	return c.list(t.Tree.Root)
+}
+
+// list returns the expression concatenating the nodes of l.
+func (c templateCompiler) list(l *parse.ListNode) (ast.Expression, error) {
+	var parts []ast.Expression
+	if l != nil {
+		for _, n := range l.Nodes {
+			switch n := n.(type) {
+			case *parse.TextNode:
+				parts = append(parts, literal(string(n.Text))...)
+			case *parse.ActionNode:
+				if len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) != 1 {
+					return nil, fmt.Errorf("%s: only fields may be output", n)
+				}
+				e, err := c.output(n.Pipe.Cmds[0].Args)
+				if err != nil {
+					return nil, err
+				}
+				parts = append(parts, e)
+			case *parse.IfNode:
+				e, err := c.branch(n)
+				if err != nil {
+					return nil, err
+				}
+				parts = append(parts, e)
+			default:
+				return nil, fmt.Errorf("%s: only fields, if and else are supported", n)
+			}
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return flux.String(""), nil
+	case 1:
+		return parts[0], nil
+	}
+	return flux.Call(flux.Member("strings", "joinStr"), flux.Object(
+		flux.Property("arr", flux.Array(parts...)),
+		flux.Property("v", flux.String("")),
+	)), nil
+}
+
+// literal returns the expressions of the text s. Text containing ${ is
+// split so that Flux does not interpolate it.
+func literal(s string) []ast.Expression {
+	var parts []ast.Expression
+	for i, p := range strings.Split(s, "${") {
+		if i > 0 {
+			parts = append(parts, flux.String("$"))
+			p = "{" + p
+		}
+		if p != "" {
+			parts = append(parts, flux.String(p))
+		}
+	}
+	return parts
+}
+
+func (c templateCompiler) branch(n *parse.IfNode) (ast.Expression, error) {
+	test, err := c.condition(n.Pipe)
+	if err != nil {
+		return nil, err
+	}
+	consequent, err := c.list(n.List)
+	if err != nil {
+		return nil, err
+	}
+	alternate, err := c.list(n.ElseList)
+	if err != nil {
+		return nil, err
+	}
+	return flux.If(test, consequent, alternate), nil
+}
+
+func (c templateCompiler) condition(p *parse.PipeNode) (ast.Expression, error) {
+	if len(p.Decl) > 0 || len(p.Cmds) != 1 {
+		return nil, fmt.Errorf("%s: unsupported condition", p)
+	}
+	cmd := p.Cmds[0]
+	fn, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok || fn.Ident == "index" {
+		return c.isSet(cmd.Args)
+	}
+
+	args := cmd.Args[1:]
+	switch fn.Ident {
+	case "eq", "ne":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s: %s takes two arguments", cmd, fn.Ident)
+		}
+		lhs, err := c.operand(args[0])
+		if err != nil {
+			return nil, err
+		}
+		rhs, err := c.operand(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if fn.Ident == "ne" {
+			return &ast.BinaryExpression{Operator: ast.NotEqualOperator, Left: lhs, Right: rhs}, nil
+		}
+		return flux.Equal(lhs, rhs), nil
+	case "not":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s: not takes one argument", cmd)
+		}
+		e, err := c.argCondition(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return &ast.UnaryExpression{Operator: ast.NotOperator, Argument: e}, nil
+	case "and", "or":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("%s: %s takes at least two arguments", cmd, fn.Ident)
+		}
+		var e ast.Expression
+		for _, arg := range args {
+			ae, err := c.argCondition(arg)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case e == nil:
+				e = ae
+			case fn.Ident == "and":
+				e = flux.And(e, ae)
+			default:
+				e = flux.Or(e, ae)
+			}
+		}
+		return e, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported function %s", cmd, fn.Ident)
+}
+
+// argCondition returns the condition of an argument of not, and or or.
+func (c templateCompiler) argCondition(arg parse.Node) (ast.Expression, error) {
+	switch arg := arg.(type) {
+	case *parse.PipeNode:
+		return c.condition(arg)
+	case *parse.FieldNode:
+		return c.isSet([]parse.Node{arg})
+	}
+	return nil, fmt.Errorf("%s: unsupported condition", arg)
+}
+
+// isSet returns the condition that the field of args is set.
+func (c templateCompiler) isSet(args []parse.Node) (ast.Expression, error) {
+	column, err := c.column(args)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.UnaryExpression{Operator: ast.ExistsOperator, Argument: flux.Member("r", column)}, nil
+}
+
+// operand returns the expression of an operand of eq or ne.
+func (c templateCompiler) operand(arg parse.Node) (ast.Expression, error) {
+	switch arg := arg.(type) {
+	case *parse.StringNode:
+		return flux.String(arg.Text), nil
+	case *parse.NumberNode:
+		if arg.IsInt {
+			return flux.Integer(arg.Int64), nil
+		}
+		if arg.IsFloat {
+			return flux.Float(arg.Float64), nil
+		}
+	case *parse.BoolNode:
+		return flux.Bool(arg.True), nil
+	case *parse.FieldNode:
+		column, err := c.column([]parse.Node{arg})
+		if err != nil {
+			return nil, err
+		}
+		return flux.Member("r", column), nil
+	case *parse.PipeNode:
+		if len(arg.Decl) == 0 && len(arg.Cmds) == 1 {
+			column, err := c.column(arg.Cmds[0].Args)
+			if err != nil {
+				return nil, err
+			}
+			return flux.Member("r", column), nil
+		}
+	}
+	return nil, fmt.Errorf("%s: unsupported operand", arg)
+}
+
+// column returns the column of the status of the field, or index of a field,
+// of the arguments of a command.
+func (c templateCompiler) column(args []parse.Node) (string, error) {
+	var ident []string
+	switch arg := args[0].(type) {
+	case *parse.FieldNode:
+		if len(args) != 1 {
+			return "", fmt.Errorf("%s: unsupported command", arg)
+		}
+		ident = arg.Ident
+	case *parse.IdentifierNode:
+		if arg.Ident != "index" || len(args) != 3 {
+			return "", fmt.Errorf("%s: unsupported function", arg)
+		}
+		f, ok := args[1].(*parse.FieldNode)
+		key, kok := args[2].(*parse.StringNode)
+		if !ok || !kok || len(f.Ident) != 1 {
+			return "", fmt.Errorf("%s: index takes .Tags or .Values and a key", arg)
+		}
+		ident = []string{f.Ident[0], key.Text}
+	default:
+		return "", fmt.Errorf("%s: unsupported command", arg)
+	}
+
+	switch {
+	case len(ident) == 1 && templateColumns[ident[0]] != "":
+		return templateColumns[ident[0]], nil
+	case len(ident) == 1 && ident[0] == "Time":
+		return "_source_timestamp", nil
+	case len(ident) == 2 && (ident[0] == "Tags" || ident[0] == "Values"):
+		return ident[1], nil
+	}
+	return "", fmt.Errorf("%s: unknown field", args[0])
+}
+
+// output returns the expression of the string of the field, or index of a
+// field, of the arguments of a command.
+func (c templateCompiler) output(args []parse.Node) (ast.Expression, error) {
+	if f, ok := args[0].(*parse.FieldNode); ok && len(args) == 1 && len(f.Ident) == 2 && f.Ident[0] == "Links" {
+		switch f.Ident[1] {
+		case "Runbook":
+			return flux.String(c.runbookLink), nil
+		case "Check":
+			return flux.Call(flux.Member("strings", "joinStr"), flux.Object(
+				flux.Property("arr", flux.Array(
+					flux.String("/orgs/"+c.orgID.String()+"/alerting/checks/"),
+					flux.Member("r", "_check_id"),
+					flux.String("/edit"),
+				)),
+				flux.Property("v", flux.String("")),
+			)), nil
+		}
+		return nil, fmt.Errorf("%s: unknown link", f)
+	}
+
+	column, err := c.column(args)
+	if err != nil {
+		return nil, err
+	}
+	v := flux.Member("r", column)
+	switch f := args[0].(type) {
+	case *parse.FieldNode:
+		if f.Ident[0] == "Time" {
+			// The time of a status is in nanoseconds since the epoch.
+			t := flux.Call(flux.Identifier("time"), flux.Object(flux.Property("v", v)))
+			return flux.Call(flux.Identifier("string"), flux.Object(flux.Property("v", t))), nil
+		}
+		if f.Ident[0] != "Values" {
+			return v, nil
+		}
+	case *parse.IdentifierNode:
+		if args[1].(*parse.FieldNode).Ident[0] != "Values" {
+			return v, nil
+		}
+	}
+	// Values may not be strings.
+	return flux.Call(flux.Identifier("string"), flux.Object(flux.Property("v", v))), nil
+}
+
+// templateSyntaxValid returns an error if syntax is not a template syntax.
+func templateSyntaxValid(syntax string) error {
+	switch syntax {
+	case "", TemplateSyntaxFlux, TemplateSyntaxGo:
+		return nil
+	}
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("invalid template syntax %q, must be %q or %q", syntax, TemplateSyntaxFlux, TemplateSyntaxGo),
+	}
+}
+
+// goTemplates returns whether the templates of the rule are Go templates.
+func (b *Base) goTemplates() bool {
+	return b.TemplateSyntax == TemplateSyntaxGo
+}
+
+// templateExpr returns the Flux expression of the template tmpl of the rule.
+func (b *Base) templateExpr(tmpl string) (ast.Expression, error) {
+	if !b.goTemplates() {
+		return flux.String(tmpl), nil
+	}
+	return templateCompiler{orgID: b.OrgID, runbookLink: b.RunbookLink}.compile(tmpl)
+}
+
+// validTemplate returns an error if the template tmpl, named name, of the
+// rule does not compile.
+func (b *Base) validTemplate(name, tmpl string) error {
+	if _, err := b.templateExpr(tmpl); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid %s: %v", name, err),
+		}
+	}
+	return nil
+}
+
+// templateImports returns the packages imported by the Flux of the
+// templates of the rule.
+func (b *Base) templateImports() []string {
+	if b.goTemplates() {
+		return []string{"strings"}
+	}
+	return nil
+}
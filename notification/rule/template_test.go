@@ -0,0 +1,126 @@
+package rule_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+func TestSlack_GoTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+		contains []string
+	}{
+		{
+			name:     "fields",
+			template: `{{.CheckName}} is {{.Level}} on {{.Tags.host}}: {{index .Values "usage_idle"}} at {{.Time}}, see {{.Links.Runbook}}`,
+			contains: []string{`strings["joinStr"]`, `r["_check_name"]`, `r["host"]`, `string(v: r["usage_idle"])`, `"http://runbook"`},
+		},
+		{
+			name:     "conditions",
+			template: `{{if eq .Level "crit"}}PAGE {{else if and .Tags.team (ne .Level "ok")}}{{.Tags.team}} {{end}}{{.Message}}`,
+			contains: []string{`r["_level"] == "crit"`, `exists r["team"]`, `r["_level"] != "ok"`},
+		},
+		{
+			name:     "interpolation is literal",
+			template: `${r._message} {{.Message}}`,
+			contains: []string{`"$"`, `"{r._message} "`},
+		},
+		{
+			name:     "unknown field",
+			template: `{{.Unknown}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown function",
+			template: `{{upper .Level}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "range",
+			template: `{{range .Tags}}{{.}}{{end}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "syntax error",
+			template: `{{.Level`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rule.Slack{
+				Channel:         "bar",
+				MessageTemplate: tt.template,
+				Base: rule.Base{
+					ID:             1,
+					Name:           "foo",
+					OwnerID:        2,
+					OrgID:          3,
+					EndpointID:     4,
+					Every:          mustDuration("1h"),
+					RunbookLink:    "http://runbook",
+					TemplateSyntax: rule.TemplateSyntaxGo,
+					StatusRules: []notification.StatusRule{
+						{CurrentLevel: notification.Any},
+					},
+				},
+			}
+
+			err := r.Valid()
+			if tt.wantErr {
+				if influxdb.ErrorCode(err) != influxdb.EInvalid {
+					t.Fatalf("expected the template to be invalid, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			script, err := r.GenerateFlux(&endpoint.Slack{
+				Base: endpoint.Base{ID: idPtr(4), Name: "foo"},
+				URL:  "http://localhost:7777",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := ast.Check(parser.ParseSource(script)); n > 0 {
+				t.Fatalf("expected the script to parse, got %d errors:\n%s", n, script)
+			}
+			if !strings.Contains(script, `import "strings"`) {
+				t.Errorf("expected the script to import strings:\n%s", script)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(script, s) {
+					t.Errorf("expected the script to contain %s:\n%s", s, script)
+				}
+			}
+		})
+	}
+}
+
+func TestBase_TemplateSyntax(t *testing.T) {
+	r := &rule.Slack{
+		MessageTemplate: "blah",
+		Base: rule.Base{
+			ID:             1,
+			Name:           "foo",
+			OwnerID:        2,
+			OrgID:          3,
+			EndpointID:     4,
+			TemplateSyntax: "jinja",
+		},
+	}
+	if err := r.Valid(); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an unknown template syntax to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+// Package slackapp posts the alerts of the slack app notification endpoints
+// with the Web API of slack, threading the updates of an alert onto its
+// message, and handles the acknowledgements of alerts made in slack.
+package slackapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxResponseSize bounds the responses read from the Web API.
+const maxResponseSize = 1 << 20
+
+// Client calls the methods of the Web API of slack as the bot of a slack app.
+type Client struct {
+	HTTPClient *http.Client
+	// URL is the base URL of the Web API, such as https://slack.com/api.
+	URL string
+	// Token is the bot token of the slack app.
+	Token string
+}
+
+// Message is a message posted with chat.postMessage or updated with
+// chat.update.
+type Message struct {
+	Channel string `json:"channel"`
+	// TS identifies the message updated by chat.update.
+	TS string `json:"ts,omitempty"`
+	// ThreadTS is the message which a posted message replies to.
+	ThreadTS    string       `json:"thread_ts,omitempty"`
+	Text        string       `json:"text"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a secondary attachment of a message, which gives the
+// message its colored bar.
+type Attachment struct {
+	Color  string  `json:"color,omitempty"`
+	Blocks []Block `json:"blocks"`
+}
+
+// Block is a layout block of slack.
+type Block struct {
+	Type string `json:"type"`
+	Text *Text  `json:"text,omitempty"`
+	// Elements are the Elements of an actions block or the Texts of a
+	// context block.
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+// Element is an interactive element of an actions block.
+type Element struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	ActionID string `json:"action_id,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Style    string `json:"style,omitempty"`
+}
+
+// Text is a text object of slack.
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// response is the response of the chat methods of the Web API.
+type response struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error"`
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// Error is an error returned by the Web API.
+type Error struct {
+	Method string
+	Code   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+// PostMessage posts m, and returns the channel ID and timestamp identifying
+// the posted message.
+func (c *Client) PostMessage(ctx context.Context, m *Message) (channel, ts string, err error) {
+	resp, err := c.call(ctx, "chat.postMessage", m)
+	if err != nil {
+		return "", "", err
+	}
+	return resp.Channel, resp.TS, nil
+}
+
+// UpdateMessage replaces the message identified by m.Channel and m.TS with m.
+func (c *Client) UpdateMessage(ctx context.Context, m *Message) error {
+	_, err := c.call(ctx, "chat.update", m)
+	return err
+}
+
+func (c *Client) call(ctx context.Context, method string, body interface{}) (*response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.URL, "/")+"/"+method, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil, &Error{Method: method, Code: resp.Status}
+	}
+	var r response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&r); err != nil {
+		return nil, err
+	}
+	if !r.OK {
+		return nil, &Error{Method: method, Code: r.Error}
+	}
+	return &r, nil
+}
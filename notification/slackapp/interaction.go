@@ -0,0 +1,82 @@
+package slackapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRequestAge bounds the age of the interaction requests accepted, so
+// that a request cannot be replayed later.
+const maxRequestAge = 5 * time.Minute
+
+var (
+	// ErrInvalidSignature is returned for a request which was not signed
+	// with the signing secret of the slack app.
+	ErrInvalidSignature = errors.New("invalid slack request signature")
+	// ErrExpiredRequest is returned for a request which is too old.
+	ErrExpiredRequest = errors.New("expired slack request")
+)
+
+// VerifyRequest verifies that a request from slack with header and body was
+// signed with secret, the signing secret of the slack app, no longer than 5
+// minutes from now.
+func VerifyRequest(secret string, header http.Header, body []byte, now time.Time) error {
+	sec, err := strconv.ParseInt(header.Get("X-Slack-Request-Timestamp"), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > maxRequestAge || d < -maxRequestAge {
+		return ErrExpiredRequest
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + strconv.FormatInt(sec, 10) + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(header.Get("X-Slack-Signature"))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Interaction is the payload of an interaction of a user with a message.
+type Interaction struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// ParseInteraction parses the payload form value of an interaction request.
+func ParseInteraction(payload string) (*Interaction, error) {
+	var i Interaction
+	if err := json.Unmarshal([]byte(payload), &i); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// Acknowledgement returns the value of the acknowledge action of the
+// interaction, the ID of the slack thread of the acknowledged alert.
+func (i *Interaction) Acknowledgement() (string, bool) {
+	if i.Type != "block_actions" {
+		return "", false
+	}
+	for _, a := range i.Actions {
+		if a.ActionID == AcknowledgeActionID {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,188 @@
+package slackapp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// AcknowledgeActionID is the action ID of the button acknowledging an alert,
+// whose value is the ID of the slack thread of the alert.
+const AcknowledgeActionID = "acknowledge"
+
+// levelOK is the level of a resolved alert.
+const levelOK = "ok"
+
+// Alert is a status of an alert sent by a notification rule.
+type Alert struct {
+	OrgID      influxdb.ID
+	EndpointID influxdb.ID
+	// Key identifies the alert among the alerts of the endpoint.
+	Key     string
+	Channel string
+	Level   string
+	Text    string
+	Color   string
+	// Acknowledge adds a button acknowledging the alert to its message.
+	Acknowledge bool
+}
+
+// Notifier posts alerts as slack messages, and keeps the message of each
+// open alert so that its updates are threaded onto it.
+type Notifier struct {
+	Client  *Client
+	Threads influxdb.SlackThreadService
+}
+
+// Notify sends a status of an alert. The first status of an alert that is
+// not ok is posted as a new message, the next ones reply to it and update
+// it, and an ok status resolves it. An ok status of an alert without a
+// message is posted on its own.
+func (n *Notifier) Notify(ctx context.Context, a *Alert) error {
+	t, err := n.findThread(ctx, a)
+	if err != nil {
+		return err
+	}
+
+	if t == nil {
+		if a.Level == levelOK {
+			_, _, err := n.Client.PostMessage(ctx, &Message{
+				Channel: a.Channel,
+				Text:    a.Text,
+				Attachments: []Attachment{
+					{Color: a.Color, Blocks: []Block{textSection(a.Text)}},
+				},
+			})
+			return err
+		}
+
+		t = &influxdb.SlackThread{
+			OrgID:      a.OrgID,
+			EndpointID: a.EndpointID,
+			AlertKey:   a.Key,
+			Level:      a.Level,
+			Text:       a.Text,
+			Color:      a.Color,
+		}
+		// The thread is created first so that the message may be given
+		// its acknowledge button.
+		if err := n.Threads.PutSlackThread(ctx, t); err != nil {
+			return err
+		}
+		channel, ts, err := n.Client.PostMessage(ctx, render(a.Channel, "", t, a.Acknowledge, false))
+		if err != nil {
+			if derr := n.Threads.DeleteSlackThread(ctx, t.ID); derr != nil {
+				return derr
+			}
+			return err
+		}
+		t.Channel, t.TS = channel, ts
+		return n.Threads.PutSlackThread(ctx, t)
+	}
+
+	if _, _, err := n.Client.PostMessage(ctx, reply(t, a.Text, a.Color)); err != nil {
+		return err
+	}
+	t.Level, t.Text, t.Color = a.Level, a.Text, a.Color
+
+	if a.Level == levelOK {
+		if err := n.Client.UpdateMessage(ctx, render(t.Channel, t.TS, t, false, true)); err != nil {
+			return err
+		}
+		return n.Threads.DeleteSlackThread(ctx, t.ID)
+	}
+	if err := n.Client.UpdateMessage(ctx, render(t.Channel, t.TS, t, a.Acknowledge, false)); err != nil {
+		return err
+	}
+	return n.Threads.PutSlackThread(ctx, t)
+}
+
+// Acknowledge records that the slack user with ID userID acknowledged the
+// alert of t at now, and updates its message.
+func (n *Notifier) Acknowledge(ctx context.Context, t *influxdb.SlackThread, userID string, now time.Time) error {
+	if t.Acknowledged() {
+		return nil
+	}
+	t.AcknowledgedBy = userID
+	t.AcknowledgedAt = &now
+	if err := n.Threads.PutSlackThread(ctx, t); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("Acknowledged by <@%s>", userID)
+	if _, _, err := n.Client.PostMessage(ctx, reply(t, text, "")); err != nil {
+		return err
+	}
+	return n.Client.UpdateMessage(ctx, render(t.Channel, t.TS, t, false, false))
+}
+
+func (n *Notifier) findThread(ctx context.Context, a *Alert) (*influxdb.SlackThread, error) {
+	ts, err := n.Threads.FindSlackThreads(ctx, influxdb.SlackThreadFilter{
+		OrgID:      &a.OrgID,
+		EndpointID: &a.EndpointID,
+		AlertKey:   &a.Key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ts) == 0 {
+		return nil, nil
+	}
+	return ts[0], nil
+}
+
+// render returns the message of the alert of t, posted to channel or
+// updating the message ts of channel.
+func render(channel, ts string, t *influxdb.SlackThread, acknowledge, resolved bool) *Message {
+	blocks := []Block{textSection(t.Text)}
+	if acknowledge && !t.Acknowledged() {
+		blocks = append(blocks, Block{
+			Type: "actions",
+			Elements: []interface{}{
+				&Element{
+					Type:     "button",
+					Text:     &Text{Type: "plain_text", Text: "Acknowledge"},
+					ActionID: AcknowledgeActionID,
+					Value:    t.ID.String(),
+					Style:    "primary",
+				},
+			},
+		})
+	}
+	if t.Acknowledged() {
+		blocks = append(blocks, contextLine(fmt.Sprintf("Acknowledged by <@%s>", t.AcknowledgedBy)))
+	}
+	if resolved {
+		blocks = append(blocks, contextLine("Resolved"))
+	}
+	return &Message{
+		Channel: channel,
+		TS:      ts,
+		Text:    t.Text,
+		Attachments: []Attachment{
+			{Color: t.Color, Blocks: blocks},
+		},
+	}
+}
+
+// reply returns a message replying with text in the thread of t.
+func reply(t *influxdb.SlackThread, text, color string) *Message {
+	return &Message{
+		Channel:  t.Channel,
+		ThreadTS: t.TS,
+		Text:     text,
+		Attachments: []Attachment{
+			{Color: color, Blocks: []Block{textSection(text)}},
+		},
+	}
+}
+
+func textSection(text string) Block {
+	return Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: text}}
+}
+
+func contextLine(text string) Block {
+	return Block{Type: "context", Elements: []interface{}{&Text{Type: "mrkdwn", Text: text}}}
+}
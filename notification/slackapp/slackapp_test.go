@@ -0,0 +1,234 @@
+package slackapp_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/slackapp"
+)
+
+// threads is an in-memory slack thread service.
+type threads struct {
+	nextID influxdb.ID
+	m      map[influxdb.ID]influxdb.SlackThread
+}
+
+func (s *threads) FindSlackThreadByID(ctx context.Context, id influxdb.ID) (*influxdb.SlackThread, error) {
+	t, ok := s.m[id]
+	if !ok {
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrSlackThreadNotFound}
+	}
+	return &t, nil
+}
+
+func (s *threads) FindSlackThreads(ctx context.Context, filter influxdb.SlackThreadFilter) ([]*influxdb.SlackThread, error) {
+	var ts []*influxdb.SlackThread
+	for _, t := range s.m {
+		t := t
+		if filter.Match(&t) {
+			ts = append(ts, &t)
+		}
+	}
+	return ts, nil
+}
+
+func (s *threads) PutSlackThread(ctx context.Context, t *influxdb.SlackThread) error {
+	if !t.ID.Valid() {
+		s.nextID++
+		t.ID = s.nextID
+	}
+	s.m[t.ID] = *t
+	return nil
+}
+
+func (s *threads) DeleteSlackThread(ctx context.Context, id influxdb.ID) error {
+	delete(s.m, id)
+	return nil
+}
+
+type call struct {
+	method string
+	msg    slackapp.Message
+}
+
+func newSlack(t *testing.T) (*httptest.Server, *[]call) {
+	var calls []call
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer xoxb-token" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		var m slackapp.Message
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		calls = append(calls, call{method: strings.TrimPrefix(r.URL.Path, "/"), msg: m})
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok":      true,
+			"channel": "C1",
+			"ts":      strconv.Itoa(len(calls)) + ".0",
+		})
+	}))
+	return srv, &calls
+}
+
+func TestNotifier(t *testing.T) {
+	srv, calls := newSlack(t)
+	defer srv.Close()
+
+	store := &threads{m: make(map[influxdb.ID]influxdb.SlackThread)}
+	n := &slackapp.Notifier{
+		Client:  &slackapp.Client{URL: srv.URL, Token: "xoxb-token"},
+		Threads: store,
+	}
+	ctx := context.Background()
+	alert := func(level, color string) *slackapp.Alert {
+		return &slackapp.Alert{
+			OrgID:       1,
+			EndpointID:  2,
+			Key:         "rule/check/host=a",
+			Channel:     "#alerts",
+			Level:       level,
+			Text:        "cpu is " + level,
+			Color:       color,
+			Acknowledge: true,
+		}
+	}
+
+	if err := n.Notify(ctx, alert("warn", "warning")); err != nil {
+		t.Fatal(err)
+	}
+	if len(*calls) != 1 || (*calls)[0].method != "chat.postMessage" || (*calls)[0].msg.ThreadTS != "" {
+		t.Fatalf("expected the alert to be posted, got %+v", *calls)
+	}
+	if len(store.m) != 1 {
+		t.Fatalf("expected a thread for the alert, got %+v", store.m)
+	}
+	th := store.m[1]
+	if th.Channel != "C1" || th.TS != "1.0" {
+		t.Errorf("expected the thread to be the posted message, got %+v", th)
+	}
+	if blocks := (*calls)[0].msg.Attachments[0].Blocks; len(blocks) != 2 || blocks[1].Type != "actions" {
+		t.Errorf("expected the message to have an acknowledge button, got %+v", blocks)
+	}
+
+	if err := n.Notify(ctx, alert("crit", "danger")); err != nil {
+		t.Fatal(err)
+	}
+	if len(*calls) != 3 {
+		t.Fatalf("expected a reply and an update, got %+v", *calls)
+	}
+	if c := (*calls)[1]; c.method != "chat.postMessage" || c.msg.ThreadTS != "1.0" || c.msg.Text != "cpu is crit" {
+		t.Errorf("expected a reply in the thread, got %+v", c)
+	}
+	if c := (*calls)[2]; c.method != "chat.update" || c.msg.TS != "1.0" || c.msg.Attachments[0].Color != "danger" {
+		t.Errorf("expected the message to be updated, got %+v", c)
+	}
+
+	th = store.m[1]
+	if err := n.Acknowledge(ctx, &th, "U1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if th := store.m[1]; !th.Acknowledged() || th.AcknowledgedBy != "U1" {
+		t.Errorf("expected the thread to be acknowledged, got %+v", th)
+	}
+	if c := (*calls)[4]; c.method != "chat.update" || len(c.msg.Attachments[0].Blocks) != 2 || c.msg.Attachments[0].Blocks[1].Type != "context" {
+		t.Errorf("expected the acknowledge button to be replaced, got %+v", c)
+	}
+
+	if err := n.Notify(ctx, alert("ok", "good")); err != nil {
+		t.Fatal(err)
+	}
+	if c := (*calls)[6]; c.method != "chat.update" || c.msg.Attachments[0].Color != "good" {
+		t.Errorf("expected the message to be resolved, got %+v", c)
+	}
+	if len(store.m) != 0 {
+		t.Errorf("expected the thread of a resolved alert to be removed, got %+v", store.m)
+	}
+
+	if err := n.Notify(ctx, alert("ok", "good")); err != nil {
+		t.Fatal(err)
+	}
+	if len(*calls) != 8 || (*calls)[7].msg.ThreadTS != "" || len(store.m) != 0 {
+		t.Errorf("expected an ok status without an alert to be posted on its own, got %+v", *calls)
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+	}))
+	defer srv.Close()
+
+	c := &slackapp.Client{URL: srv.URL, Token: "xoxb-token"}
+	_, _, err := c.PostMessage(context.Background(), &slackapp.Message{Channel: "#missing", Text: "x"})
+	if err == nil || err.Error() != "slack chat.postMessage failed: channel_not_found" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	body := []byte("payload=%7B%7D")
+	sign := func(secret string, ts int64) http.Header {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + strconv.FormatInt(ts, 10) + ":"))
+		mac.Write(body)
+		h := make(http.Header)
+		h.Set("X-Slack-Request-Timestamp", strconv.FormatInt(ts, 10))
+		h.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		return h
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   error
+	}{
+		{
+			name:   "valid",
+			header: sign("secret", now.Unix()-10),
+		},
+		{
+			name:   "wrong secret",
+			header: sign("other", now.Unix()),
+			want:   slackapp.ErrInvalidSignature,
+		},
+		{
+			name:   "expired",
+			header: sign("secret", now.Unix()-600),
+			want:   slackapp.ErrExpiredRequest,
+		},
+		{
+			name:   "unsigned",
+			header: http.Header{},
+			want:   slackapp.ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := slackapp.VerifyRequest("secret", tt.header, body, now); err != tt.want {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInteraction(t *testing.T) {
+	i, err := slackapp.ParseInteraction(`{"type":"block_actions","user":{"id":"U1"},"actions":[{"action_id":"acknowledge","value":"0000000000000001"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := i.Acknowledgement(); !ok || v != "0000000000000001" || i.User.ID != "U1" {
+		t.Errorf("unexpected interaction %+v", i)
+	}
+}
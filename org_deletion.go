@@ -0,0 +1,96 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrOrgDeletionNotFound is the error for a missing organization deletion.
+const ErrOrgDeletionNotFound = "organization deletion not found"
+
+const (
+	OpStartOrgDeletion    = "StartOrgDeletion"
+	OpFindOrgDeletionByID = "FindOrgDeletionByID"
+	OpFindOrgDeletions    = "FindOrgDeletions"
+	OpCreateOrgDeletion   = "CreateOrgDeletion"
+	OpPutOrgDeletion      = "PutOrgDeletion"
+)
+
+// OrgDeletionStage is a step of an organization deletion. Stages run in
+// the order they are declared.
+type OrgDeletionStage string
+
+const (
+	// OrgDeletionStageExport records the resources of the organization
+	// in the manifest of the deletion.
+	OrgDeletionStageExport OrgDeletionStage = "export"
+	// OrgDeletionStageRevokeTokens deactivates every token of the organization.
+	OrgDeletionStageRevokeTokens OrgDeletionStage = "revoke-tokens"
+	// OrgDeletionStageSuspendTasks deactivates every task of the organization.
+	OrgDeletionStageSuspendTasks OrgDeletionStage = "suspend-tasks"
+	// OrgDeletionStageDeleteResources deletes the resources of the
+	// organization, its data and finally the organization itself.
+	OrgDeletionStageDeleteResources OrgDeletionStage = "delete-resources"
+	// OrgDeletionStageComplete is the stage of a finished deletion.
+	OrgDeletionStageComplete OrgDeletionStage = "complete"
+)
+
+// OrgDeletionStatus is the status of an organization deletion.
+type OrgDeletionStatus string
+
+const (
+	OrgDeletionRunning  OrgDeletionStatus = "running"
+	OrgDeletionFailed   OrgDeletionStatus = "failed"
+	OrgDeletionComplete OrgDeletionStatus = "complete"
+)
+
+// OrgDeletion tracks the progress of deleting an organization. Deletions
+// are kept once complete as the audit record of what was deleted and by whom.
+type OrgDeletion struct {
+	ID          ID                   `json:"id,omitempty"`
+	OrgID       ID                   `json:"orgID"`
+	OrgName     string               `json:"orgName"`
+	Stage       OrgDeletionStage     `json:"stage"`
+	Status      OrgDeletionStatus    `json:"status"`
+	Error       string               `json:"error,omitempty"`
+	Manifest    *OrgDeletionManifest `json:"manifest,omitempty"`
+	RequestedBy ID                   `json:"requestedBy,omitempty"`
+	StartedAt   time.Time            `json:"startedAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+	CompletedAt *time.Time           `json:"completedAt,omitempty"`
+}
+
+// OrgDeletionManifest lists the resources of an organization at the time
+// its deletion started.
+type OrgDeletionManifest struct {
+	Buckets        []OrgDeletionResource `json:"buckets"`
+	Dashboards     []OrgDeletionResource `json:"dashboards"`
+	Tasks          []OrgDeletionResource `json:"tasks"`
+	Authorizations []OrgDeletionResource `json:"authorizations"`
+	DBRPMappings   []DBRPMapping         `json:"dbrpMappings"`
+}
+
+// OrgDeletionResource identifies a resource in the manifest of a deletion.
+type OrgDeletionResource struct {
+	ID   ID     `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// OrgDeletionFilter represents a set of filters that restrict the returned deletions.
+type OrgDeletionFilter struct {
+	OrgID  *ID
+	Status *OrgDeletionStatus
+}
+
+// OrgDeletionService deletes organizations along with all of their resources.
+type OrgDeletionService interface {
+	// StartOrgDeletion starts deleting the organization in the background.
+	// Starting the deletion of an organization whose deletion failed resumes it.
+	StartOrgDeletion(ctx context.Context, orgID ID) (*OrgDeletion, error)
+
+	// FindOrgDeletionByID returns a single organization deletion by ID.
+	FindOrgDeletionByID(ctx context.Context, id ID) (*OrgDeletion, error)
+
+	// FindOrgDeletions returns a list of organization deletions that match filter.
+	FindOrgDeletions(ctx context.Context, filter OrgDeletionFilter) ([]*OrgDeletion, error)
+}
@@ -0,0 +1,78 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	OpFindOrgDomain   = "FindOrgDomain"
+	OpFindOrgDomains  = "FindOrgDomains"
+	OpCreateOrgDomain = "CreateOrgDomain"
+	OpDeleteOrgDomain = "DeleteOrgDomain"
+)
+
+// maxSubdomainLength is the longest DNS label.
+const maxSubdomainLength = 63
+
+// OrgDomainService manages the subdomains which scope the requests made to
+// them to an organization.
+type OrgDomainService interface {
+	// FindOrgDomain returns the mapping of a subdomain.
+	FindOrgDomain(ctx context.Context, subdomain string) (*OrgDomain, error)
+
+	// FindOrgDomains returns the mappings matching filter, by subdomain.
+	FindOrgDomains(ctx context.Context, filter OrgDomainFilter) ([]*OrgDomain, error)
+
+	// CreateOrgDomain maps a subdomain to an organization, setting its
+	// creation time. A subdomain is mapped to a single organization.
+	CreateOrgDomain(ctx context.Context, d *OrgDomain) error
+
+	// DeleteOrgDomain removes the mapping of a subdomain.
+	DeleteOrgDomain(ctx context.Context, subdomain string) error
+}
+
+// OrgDomain maps a subdomain of the domain of an instance, such as org1 of
+// org1.example.com, to the organization the requests made to it are scoped
+// to.
+type OrgDomain struct {
+	Subdomain string    `json:"subdomain"`
+	OrgID     ID        `json:"orgID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Valid returns an error if the mapping is invalid. Subdomains are single
+// lowercase DNS labels.
+func (d *OrgDomain) Valid() error {
+	if !validSubdomain(d.Subdomain) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "subdomain must be 1 to 63 lowercase letters, digits and hyphens, and not begin or end with a hyphen",
+		}
+	}
+	if !d.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "org domain requires an organization",
+		}
+	}
+	return nil
+}
+
+func validSubdomain(s string) bool {
+	if s == "" || len(s) > maxSubdomainLength || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// OrgDomainFilter represents a set of filters that restrict the returned
+// mappings.
+type OrgDomainFilter struct {
+	OrgID *ID
+}
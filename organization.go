@@ -10,6 +10,9 @@ type Organization struct {
 	ID          ID     `json:"id,omitempty"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// DefaultBucketID, if set, is the bucket written to by write requests
+	// to the organization which omit the bucket.
+	DefaultBucketID *ID `json:"defaultBucketID,omitempty"`
 	CRUDLog
 }
 
@@ -59,8 +62,9 @@ type OrganizationService interface {
 // OrganizationUpdate represents updates to a organization.
 // Only fields which are set are updated.
 type OrganizationUpdate struct {
-	Name        *string
-	Description *string `json:"description,omitempty"`
+	Name            *string
+	Description     *string `json:"description,omitempty"`
+	DefaultBucketID *ID     `json:"defaultBucketID,omitempty"`
 }
 
 // ErrInvalidOrgFilter is the error indicate org filter is empty
@@ -0,0 +1,318 @@
+// Package orgdeletion deletes organizations in stages so that no data or
+// mappings of a deleted organization are left behind.
+package orgdeletion
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/jobs"
+	"go.uber.org/zap"
+)
+
+// Store persists the progress of organization deletions.
+type Store interface {
+	CreateOrgDeletion(ctx context.Context, d *influxdb.OrgDeletion) error
+	PutOrgDeletion(ctx context.Context, d *influxdb.OrgDeletion) error
+	FindOrgDeletionByID(ctx context.Context, id influxdb.ID) (*influxdb.OrgDeletion, error)
+	FindOrgDeletions(ctx context.Context, filter influxdb.OrgDeletionFilter) ([]*influxdb.OrgDeletion, error)
+}
+
+// ShardDeleter deletes the data of a bucket from the storage engine.
+type ShardDeleter interface {
+	DeleteBucket(ctx context.Context, orgID, bucketID influxdb.ID) error
+}
+
+// stages are the stages of a deletion in the order they run.
+var stages = []influxdb.OrgDeletionStage{
+	influxdb.OrgDeletionStageExport,
+	influxdb.OrgDeletionStageRevokeTokens,
+	influxdb.OrgDeletionStageSuspendTasks,
+	influxdb.OrgDeletionStageDeleteResources,
+}
+
+// Service runs organization deletions in the background.
+type Service struct {
+	log    *zap.Logger
+	store  Store
+	shards ShardDeleter
+
+	orgSvc       influxdb.OrganizationService
+	bucketSvc    influxdb.BucketService
+	dashboardSvc influxdb.DashboardService
+	authSvc      influxdb.AuthorizationService
+	taskSvc      influxdb.TaskService
+
+	// DBRPMappingService is optional. If set, the DBRP mappings of the
+	// organization are deleted with it.
+	DBRPMappingService influxdb.DBRPMappingService
+
+	// Jobs is optional. If set, deletions run as its jobs, which may be
+	// listed and canceled.
+	Jobs *jobs.Manager
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[influxdb.ID]bool
+}
+
+var _ influxdb.OrgDeletionService = (*Service)(nil)
+
+// NewService constructs a new Service. The bucket service must not remove
+// data from the storage engine itself; shards are deleted through shards.
+func NewService(log *zap.Logger, store Store, shards ShardDeleter, orgSvc influxdb.OrganizationService, bucketSvc influxdb.BucketService, dashboardSvc influxdb.DashboardService, authSvc influxdb.AuthorizationService, taskSvc influxdb.TaskService) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		log:          log,
+		store:        store,
+		shards:       shards,
+		orgSvc:       orgSvc,
+		bucketSvc:    bucketSvc,
+		dashboardSvc: dashboardSvc,
+		authSvc:      authSvc,
+		taskSvc:      taskSvc,
+		ctx:          ctx,
+		cancel:       cancel,
+		running:      make(map[influxdb.ID]bool),
+	}
+}
+
+// Open resumes the deletions that were running when the service was closed.
+func (s *Service) Open(ctx context.Context) error {
+	status := influxdb.OrgDeletionRunning
+	ds, err := s.store.FindOrgDeletions(ctx, influxdb.OrgDeletionFilter{Status: &status})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range ds {
+		s.log.Info("Resuming organization deletion", zap.Stringer("orgID", d.OrgID), zap.String("stage", string(d.Stage)))
+		s.start(d)
+	}
+	return nil
+}
+
+// Close stops running deletions at the end of their current step and
+// waits for them to return. Stopped deletions are resumed by Open.
+func (s *Service) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// StartOrgDeletion starts deleting the organization in the background.
+// Starting the deletion of an organization whose deletion failed resumes it.
+func (s *Service) StartOrgDeletion(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgDeletion, error) {
+	org, err := s.orgSvc.FindOrganizationByID(ctx, orgID)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpStartOrgDeletion,
+			Err: err,
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[orgID] {
+		return nil, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Op:   influxdb.OpStartOrgDeletion,
+			Msg:  "organization is already being deleted",
+		}
+	}
+
+	status := influxdb.OrgDeletionFailed
+	failed, err := s.store.FindOrgDeletions(ctx, influxdb.OrgDeletionFilter{OrgID: &orgID, Status: &status})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpStartOrgDeletion,
+			Err: err,
+		}
+	}
+
+	var d *influxdb.OrgDeletion
+	if len(failed) > 0 {
+		d = failed[len(failed)-1]
+		d.Status = influxdb.OrgDeletionRunning
+		d.Error = ""
+		if err := s.store.PutOrgDeletion(ctx, d); err != nil {
+			return nil, &influxdb.Error{
+				Op:  influxdb.OpStartOrgDeletion,
+				Err: err,
+			}
+		}
+	} else {
+		d = &influxdb.OrgDeletion{
+			OrgID:   org.ID,
+			OrgName: org.Name,
+			Stage:   influxdb.OrgDeletionStageExport,
+			Status:  influxdb.OrgDeletionRunning,
+		}
+		if uid, err := icontext.GetUserID(ctx); err == nil {
+			d.RequestedBy = uid
+		}
+		if err := s.store.CreateOrgDeletion(ctx, d); err != nil {
+			return nil, &influxdb.Error{
+				Op:  influxdb.OpStartOrgDeletion,
+				Err: err,
+			}
+		}
+	}
+
+	res := *d
+	s.start(d)
+	return &res, nil
+}
+
+// start runs the deletion in the background. s.mu must be held.
+func (s *Service) start(d *influxdb.OrgDeletion) {
+	s.running[d.OrgID] = true
+	s.wg.Add(1)
+	fn := func(ctx context.Context, p *jobs.Progress) error {
+		defer s.wg.Done()
+		err := s.run(ctx, d, p)
+
+		s.mu.Lock()
+		delete(s.running, d.OrgID)
+		s.mu.Unlock()
+		return err
+	}
+
+	if s.Jobs == nil {
+		go fn(s.ctx, nil)
+		return
+	}
+	s.Jobs.Start(s.ctx, &influxdb.Job{
+		Kind:       influxdb.JobKindOrgDeletion,
+		OrgID:      d.OrgID,
+		ResourceID: d.ID,
+	}, fn)
+}
+
+func (s *Service) run(ctx context.Context, d *influxdb.OrgDeletion, p *jobs.Progress) error {
+	log := s.log.With(zap.Stringer("orgID", d.OrgID), zap.Stringer("deletionID", d.ID))
+
+	p.SetTotal(int64(len(stages)))
+	for _, stage := range stages {
+		if stageIndex(d.Stage) > stageIndex(stage) {
+			p.Add(1)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return s.stop(d, err)
+		}
+
+		d.Stage = stage
+		p.SetStage(string(stage))
+		if err := s.store.PutOrgDeletion(ctx, d); err != nil {
+			if ctx.Err() != nil {
+				return s.stop(d, ctx.Err())
+			}
+			log.Error("Failed to store organization deletion progress", zap.Error(err))
+			return err
+		}
+
+		if err := s.runStage(ctx, d); err != nil {
+			if ctx.Err() != nil {
+				return s.stop(d, ctx.Err())
+			}
+			log.Error("Organization deletion failed", zap.String("stage", string(stage)), zap.Error(err))
+			d.Status = influxdb.OrgDeletionFailed
+			d.Error = err.Error()
+			if err := s.store.PutOrgDeletion(context.Background(), d); err != nil {
+				log.Error("Failed to store organization deletion progress", zap.Error(err))
+			}
+			return err
+		}
+		p.Add(1)
+	}
+
+	now := time.Now().UTC()
+	d.Stage = influxdb.OrgDeletionStageComplete
+	d.Status = influxdb.OrgDeletionComplete
+	d.CompletedAt = &now
+	if err := s.store.PutOrgDeletion(context.Background(), d); err != nil {
+		log.Error("Failed to store organization deletion progress", zap.Error(err))
+		return err
+	}
+	log.Info("Organization deleted",
+		zap.String("orgName", d.OrgName),
+		zap.Stringer("requestedBy", d.RequestedBy),
+		zap.Int("buckets", len(d.Manifest.Buckets)),
+		zap.Int("dashboards", len(d.Manifest.Dashboards)),
+		zap.Int("tasks", len(d.Manifest.Tasks)),
+		zap.Int("authorizations", len(d.Manifest.Authorizations)),
+		zap.Int("dbrpMappings", len(d.Manifest.DBRPMappings)))
+	return nil
+}
+
+// stop stops the deletion d at the end of its current stage. A deletion
+// stopped by Close is left running so that it is resumed by Open, and one
+// whose job was canceled fails, so that it may be resumed by starting it
+// again.
+func (s *Service) stop(d *influxdb.OrgDeletion, err error) error {
+	if s.ctx.Err() != nil {
+		return err
+	}
+	d.Status = influxdb.OrgDeletionFailed
+	d.Error = "deletion canceled"
+	if err := s.store.PutOrgDeletion(context.Background(), d); err != nil {
+		s.log.Error("Failed to store organization deletion progress", zap.Stringer("orgID", d.OrgID), zap.Error(err))
+	}
+	return err
+}
+
+func stageIndex(stage influxdb.OrgDeletionStage) int {
+	if stage == influxdb.OrgDeletionStageComplete {
+		return len(stages)
+	}
+	for i, s := range stages {
+		if s == stage {
+			return i
+		}
+	}
+	return 0
+}
+
+// runStage runs a single stage of the deletion. Every stage may be run
+// again after a failure or restart.
+func (s *Service) runStage(ctx context.Context, d *influxdb.OrgDeletion) error {
+	switch d.Stage {
+	case influxdb.OrgDeletionStageExport:
+		if d.Manifest != nil {
+			return nil
+		}
+		m, err := s.exportManifest(ctx, d.OrgID)
+		if err != nil {
+			return err
+		}
+		d.Manifest = m
+		return s.store.PutOrgDeletion(ctx, d)
+	case influxdb.OrgDeletionStageRevokeTokens:
+		return s.revokeTokens(ctx, d.OrgID)
+	case influxdb.OrgDeletionStageSuspendTasks:
+		return s.suspendTasks(ctx, d.OrgID)
+	case influxdb.OrgDeletionStageDeleteResources:
+		return s.deleteResources(ctx, d.OrgID)
+	}
+	return nil
+}
+
+// FindOrgDeletionByID returns a single organization deletion by ID.
+func (s *Service) FindOrgDeletionByID(ctx context.Context, id influxdb.ID) (*influxdb.OrgDeletion, error) {
+	return s.store.FindOrgDeletionByID(ctx, id)
+}
+
+// FindOrgDeletions returns a list of organization deletions that match filter.
+func (s *Service) FindOrgDeletions(ctx context.Context, filter influxdb.OrgDeletionFilter) ([]*influxdb.OrgDeletion, error) {
+	return s.store.FindOrgDeletions(ctx, filter)
+}
@@ -0,0 +1,191 @@
+package orgdeletion_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/jobs"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/orgdeletion"
+	"go.uber.org/zap/zaptest"
+)
+
+type shardDeleter struct {
+	mu      sync.Mutex
+	deleted map[influxdb.ID]bool
+}
+
+func (s *shardDeleter) DeleteBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted[bucketID] = true
+	return nil
+}
+
+func TestService_StartOrgDeletion(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user", Status: influxdb.Active}
+	if err := store.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &influxdb.Bucket{OrgID: org.ID, Name: "bucket"}
+	if err := store.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+	dashboard := &influxdb.Dashboard{OrganizationID: org.ID, Name: "dashboard"}
+	if err := store.CreateDashboard(ctx, dashboard); err != nil {
+		t.Fatal(err)
+	}
+	auth := &influxdb.Authorization{
+		OrgID:  org.ID,
+		UserID: user.ID,
+		Permissions: []influxdb.Permission{{
+			Action:   influxdb.ReadAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &org.ID},
+		}},
+	}
+	if err := store.CreateAuthorization(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	shards := &shardDeleter{deleted: make(map[influxdb.ID]bool)}
+	svc := orgdeletion.NewService(zaptest.NewLogger(t), store, shards, store, store, store, store, store)
+	defer svc.Close()
+
+	d, err := svc.StartOrgDeletion(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for d.Status == influxdb.OrgDeletionRunning {
+		if time.Now().After(deadline) {
+			t.Fatalf("organization deletion did not finish, stuck in stage %q", d.Stage)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if d, err = svc.FindOrgDeletionByID(ctx, d.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if d.Status != influxdb.OrgDeletionComplete || d.Stage != influxdb.OrgDeletionStageComplete || d.CompletedAt == nil {
+		t.Fatalf("expected deletion to complete, got status %q in stage %q: %s", d.Status, d.Stage, d.Error)
+	}
+	m := d.Manifest
+	if len(m.Dashboards) != 1 || len(m.Authorizations) != 1 {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+	// The manifest includes the system buckets of the organization.
+	if len(m.Buckets) < 2 {
+		t.Errorf("expected manifest to include the buckets of the organization, got %v", m.Buckets)
+	}
+	for _, b := range m.Buckets {
+		if !shards.deleted[b.ID] {
+			t.Errorf("expected shards of bucket %q to be deleted", b.Name)
+		}
+	}
+
+	if _, err := store.FindOrganizationByID(ctx, org.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected organization to be deleted, got %v", err)
+	}
+	if _, err := store.FindAuthorizationByID(ctx, auth.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected authorization to be deleted, got %v", err)
+	}
+	if _, err := store.FindDashboardByID(ctx, dashboard.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected dashboard to be deleted, got %v", err)
+	}
+
+	if _, err := svc.StartOrgDeletion(ctx, org.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected deleting a deleted organization to fail, got %v", err)
+	}
+}
+
+type blockingShardDeleter struct {
+	started chan struct{}
+}
+
+func (s *blockingShardDeleter) DeleteBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
+	select {
+	case s.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestService_CancelOrgDeletionJob(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := jobs.NewManager(zaptest.NewLogger(t))
+	defer manager.Close()
+	shards := &blockingShardDeleter{started: make(chan struct{}, 1)}
+	svc := orgdeletion.NewService(zaptest.NewLogger(t), store, shards, store, store, store, store, store)
+	svc.Jobs = manager
+	defer svc.Close()
+
+	d, err := svc.StartOrgDeletion(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-shards.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("organization deletion did not reach the deletion of its buckets")
+	}
+
+	kind := influxdb.JobKindOrgDeletion
+	js, err := manager.FindJobs(ctx, influxdb.JobFilter{OrgID: &org.ID, Kind: &kind})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(js) != 1 || js[0].ResourceID != d.ID || js[0].Stage != string(influxdb.OrgDeletionStageDeleteResources) {
+		t.Fatalf("expected a job deleting the organization, got %+v", js)
+	}
+	if _, err := manager.CancelJob(ctx, js[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	j := js[0]
+	for !j.Done() {
+		if time.Now().After(deadline) {
+			t.Fatal("organization deletion was not canceled")
+		}
+		time.Sleep(10 * time.Millisecond)
+		if j, err = manager.FindJobByID(ctx, j.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if j.Status != influxdb.JobCanceled {
+		t.Errorf("expected the job to be canceled, got %q", j.Status)
+	}
+
+	if d, err = svc.FindOrgDeletionByID(ctx, d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if d.Status != influxdb.OrgDeletionFailed || d.Error != "deletion canceled" {
+		t.Errorf("expected the canceled deletion to fail, got status %q: %s", d.Status, d.Error)
+	}
+}
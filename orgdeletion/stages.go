@@ -0,0 +1,201 @@
+package orgdeletion
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func (s *Service) exportManifest(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgDeletionManifest, error) {
+	m := &influxdb.OrgDeletionManifest{
+		Buckets:        []influxdb.OrgDeletionResource{},
+		Dashboards:     []influxdb.OrgDeletionResource{},
+		Tasks:          []influxdb.OrgDeletionResource{},
+		Authorizations: []influxdb.OrgDeletionResource{},
+		DBRPMappings:   []influxdb.DBRPMapping{},
+	}
+
+	bs, _, err := s.bucketSvc.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bs {
+		m.Buckets = append(m.Buckets, influxdb.OrgDeletionResource{ID: b.ID, Name: b.Name})
+	}
+
+	ds, _, err := s.dashboardSvc.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &orgID}, influxdb.FindOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range ds {
+		m.Dashboards = append(m.Dashboards, influxdb.OrgDeletionResource{ID: d.ID, Name: d.Name})
+	}
+
+	ts, err := s.findTasks(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range ts {
+		m.Tasks = append(m.Tasks, influxdb.OrgDeletionResource{ID: t.ID, Name: t.Name})
+	}
+
+	as, _, err := s.authSvc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range as {
+		m.Authorizations = append(m.Authorizations, influxdb.OrgDeletionResource{ID: a.ID, Name: a.Description})
+	}
+
+	mappings, err := s.findDBRPMappings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mapping := range mappings {
+		m.DBRPMappings = append(m.DBRPMappings, *mapping)
+	}
+	return m, nil
+}
+
+// revokeTokens deactivates the tokens of the organization so that no
+// writes or queries happen while its resources are deleted.
+func (s *Service) revokeTokens(ctx context.Context, orgID influxdb.ID) error {
+	as, _, err := s.authSvc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+	inactive := influxdb.Inactive
+	for _, a := range as {
+		if a.Status == influxdb.Inactive {
+			continue
+		}
+		if _, err := s.authSvc.UpdateAuthorization(ctx, a.ID, &influxdb.AuthorizationUpdate{Status: &inactive}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// suspendTasks deactivates the tasks of the organization so that they are
+// not scheduled while its resources are deleted.
+func (s *Service) suspendTasks(ctx context.Context, orgID influxdb.ID) error {
+	ts, err := s.findTasks(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	inactive := influxdb.TaskStatusInactive
+	for _, t := range ts {
+		if t.Status == inactive {
+			continue
+		}
+		if _, err := s.taskSvc.UpdateTask(ctx, t.ID, influxdb.TaskUpdate{Status: &inactive}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteResources deletes the resources and data of the organization and
+// finally the organization itself.
+func (s *Service) deleteResources(ctx context.Context, orgID influxdb.ID) error {
+	ts, err := s.findTasks(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, t := range ts {
+		if err := s.taskSvc.DeleteTask(ctx, t.ID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+	}
+
+	ds, _, err := s.dashboardSvc.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &orgID}, influxdb.FindOptions{})
+	if err != nil {
+		return err
+	}
+	for _, d := range ds {
+		if err := s.dashboardSvc.DeleteDashboard(ctx, d.ID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+	}
+
+	mappings, err := s.findDBRPMappings(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, m := range mappings {
+		if err := s.DBRPMappingService.Delete(ctx, m.Cluster, m.Database, m.RetentionPolicy); err != nil {
+			return err
+		}
+	}
+
+	bs, _, err := s.bucketSvc.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &orgID})
+	if err != nil {
+		return err
+	}
+	for _, b := range bs {
+		if err := s.shards.DeleteBucket(ctx, orgID, b.ID); err != nil {
+			return err
+		}
+		// System buckets cannot be deleted on their own and are removed
+		// along with the organization.
+		if b.Type == influxdb.BucketTypeSystem {
+			continue
+		}
+		if err := s.bucketSvc.DeleteBucket(ctx, b.ID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+	}
+
+	as, _, err := s.authSvc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+	for _, a := range as {
+		if err := s.authSvc.DeleteAuthorization(ctx, a.ID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+	}
+
+	if err := s.orgSvc.DeleteOrganization(ctx, orgID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) findTasks(ctx context.Context, orgID influxdb.ID) ([]*influxdb.Task, error) {
+	var all []*influxdb.Task
+	filter := influxdb.TaskFilter{
+		OrganizationID: &orgID,
+		Limit:          influxdb.TaskMaxPageSize,
+	}
+	for {
+		ts, _, err := s.taskSvc.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, ts...)
+		if len(ts) < filter.Limit {
+			return all, nil
+		}
+		filter.After = &ts[len(ts)-1].ID
+	}
+}
+
+// findDBRPMappings returns the DBRP mappings of the organization. The
+// mapping filter has no organization so mappings are filtered here.
+func (s *Service) findDBRPMappings(ctx context.Context, orgID influxdb.ID) ([]*influxdb.DBRPMapping, error) {
+	if s.DBRPMappingService == nil {
+		return nil, nil
+	}
+	ms, _, err := s.DBRPMappingService.FindMany(ctx, influxdb.DBRPMappingFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var res []*influxdb.DBRPMapping
+	for _, m := range ms {
+		if m.OrganizationID == orgID {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
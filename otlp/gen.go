@@ -0,0 +1,3 @@
+package otlp
+
+//go:generate protoc -I . --plugin ../scripts/protoc-gen-gogofaster --gogofaster_out=. metrics.proto
@@ -0,0 +1,173 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterMetricsService registers the OTLP/gRPC metrics service on s.
+//
+// Export requests are served by h as OTLP/HTTP requests to path, so that
+// they are authenticated, authorized and written like any other write. The
+// "authorization", "org" and "bucket" metadata of a request are the
+// Authorization header, and the org and bucket query parameters of the HTTP
+// request.
+func RegisterMetricsService(s *grpc.Server, h http.Handler, path string) {
+	s.RegisterService(&metricsServiceDesc, &metricsService{handler: h, path: path})
+}
+
+type metricsServer interface {
+	export(ctx context.Context, req *rawMessage) (*rawMessage, error)
+}
+
+var metricsServiceDesc = grpc.ServiceDesc{
+	ServiceName: "opentelemetry.proto.collector.metrics.v1.MetricsService",
+	HandlerType: (*metricsServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Export",
+		Handler:    exportHandler,
+	}},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "opentelemetry/proto/collector/metrics/v1/metrics_service.proto",
+}
+
+func exportHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := &rawMessage{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(metricsServer).export(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export",
+	}
+	return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(metricsServer).export(ctx, req.(*rawMessage))
+	})
+}
+
+// rawMessage is a protobuf message that is not decoded, so that requests are
+// passed to the HTTP handler as they were sent.
+type rawMessage struct {
+	data []byte
+}
+
+func (m *rawMessage) Reset()         { m.data = nil }
+func (m *rawMessage) String() string { return "" }
+func (*rawMessage) ProtoMessage()    {}
+
+func (m *rawMessage) Marshal() ([]byte, error) {
+	return m.data, nil
+}
+
+func (m *rawMessage) Unmarshal(b []byte) error {
+	m.data = append([]byte(nil), b...)
+	return nil
+}
+
+type metricsService struct {
+	handler http.Handler
+	path    string
+}
+
+func (s *metricsService) export(ctx context.Context, req *rawMessage) (*rawMessage, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	params := url.Values{}
+	params.Set("org", first("org"))
+	params.Set("bucket", first("bucket"))
+	r, err := http.NewRequest(http.MethodPost, s.path+"?"+params.Encode(), bytes.NewReader(req.data))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	r = r.WithContext(ctx)
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	if auth := first("authorization"); auth != "" {
+		r.Header.Set("Authorization", auth)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+
+	w := &responseRecorder{header: http.Header{}, code: http.StatusOK}
+	s.handler.ServeHTTP(w, r)
+	if w.code >= 200 && w.code < 300 {
+		// ExportMetricsServiceResponse is empty when every point is written.
+		return &rawMessage{}, nil
+	}
+	return nil, status.Error(statusCode(w.code), errorMessage(w))
+}
+
+// responseRecorder records the response of the HTTP handler.
+type responseRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if !w.wrote {
+		w.code = code
+		w.wrote = true
+	}
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// statusCode returns the gRPC status code of an HTTP status code. Following
+// OTLP, only failures that may succeed later are retryable codes.
+func statusCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// errorMessage returns the message of an error response.
+func errorMessage(w *responseRecorder) string {
+	var e struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(w.body.Bytes(), &e); err == nil && e.Message != "" {
+		return e.Message
+	}
+	if w.body.Len() > 0 {
+		return w.body.String()
+	}
+	return http.StatusText(w.code)
+}
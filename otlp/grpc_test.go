@@ -0,0 +1,81 @@
+package otlp
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRegisterMetricsService(t *testing.T) {
+	var (
+		got  *http.Request
+		body []byte
+		code = http.StatusNoContent
+	)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = ioutil.ReadAll(r.Body)
+		if code != http.StatusNoContent {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			w.Write([]byte(`{"code":"forbidden","message":"insufficient permissions for write"}`))
+			return
+		}
+		w.WriteHeader(code)
+	})
+
+	s := grpc.NewServer()
+	RegisterMetricsService(s, h, "/api/v2/write/otlp/v1/metrics")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(ln)
+	defer s.Stop()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"authorization", "Token secret",
+		"org", "my org",
+		"bucket", "metrics",
+	)
+	const method = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	req := &rawMessage{data: []byte{0x0a, 0x00}}
+
+	if err := conn.Invoke(ctx, method, req, &rawMessage{}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != http.MethodPost || got.URL.Path != "/api/v2/write/otlp/v1/metrics" {
+		t.Fatalf("unexpected request %s %s", got.Method, got.URL.Path)
+	}
+	if got.URL.Query().Get("org") != "my org" || got.URL.Query().Get("bucket") != "metrics" {
+		t.Fatalf("unexpected query %s", got.URL.RawQuery)
+	}
+	if got.Header.Get("Authorization") != "Token secret" {
+		t.Fatalf("unexpected authorization %q", got.Header.Get("Authorization"))
+	}
+	if got.Header.Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("unexpected content type %q", got.Header.Get("Content-Type"))
+	}
+	if string(body) != string(req.data) {
+		t.Fatalf("unexpected body %v", body)
+	}
+
+	code = http.StatusForbidden
+	err = conn.Invoke(ctx, method, req, &rawMessage{})
+	if s, ok := status.FromError(err); !ok || s.Code() != codes.PermissionDenied || s.Message() != "insufficient permissions for write" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package otlp
+
+// The messages of an ExportMetricsServiceRequest are generated from
+// metrics.proto.
+
+// flagNoRecordedValue marks data points without a value.
+const flagNoRecordedValue = 1
+
+// decodeMetricsRequest decodes an ExportMetricsServiceRequest.
+func decodeMetricsRequest(b []byte) (*ExportMetricsServiceRequest, error) {
+	req := &ExportMetricsServiceRequest{}
+	if err := req.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// numberValue returns the value of a NumberDataPoint or Exemplar, which is a
+// float64, an int64, or nil when it is missing.
+func numberValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *NumberDataPoint_AsDouble:
+		return v.AsDouble
+	case *NumberDataPoint_AsInt:
+		return v.AsInt
+	case *Exemplar_AsDouble:
+		return v.AsDouble
+	case *Exemplar_AsInt:
+		return v.AsInt
+	}
+	return nil
+}
+
+// anyValue returns the value of an AnyValue, which is a string, bool, int64,
+// float64, []byte, []interface{} or map[string]interface{}, or nil when it is
+// missing.
+func anyValue(v *AnyValue) interface{} {
+	switch v := v.GetValue().(type) {
+	case *AnyValue_StringValue:
+		return v.StringValue
+	case *AnyValue_BoolValue:
+		return v.BoolValue
+	case *AnyValue_IntValue:
+		return v.IntValue
+	case *AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *AnyValue_BytesValue:
+		return v.BytesValue
+	case *AnyValue_ArrayValue:
+		values := make([]interface{}, 0, len(v.ArrayValue.GetValues()))
+		for _, e := range v.ArrayValue.GetValues() {
+			values = append(values, anyValue(e))
+		}
+		return values
+	case *AnyValue_KvlistValue:
+		kv := make(map[string]interface{}, len(v.KvlistValue.GetValues()))
+		for _, a := range v.KvlistValue.GetValues() {
+			kv[a.GetKey()] = anyValue(a.GetValue())
+		}
+		return kv
+	}
+	return nil
+}
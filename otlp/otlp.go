@@ -0,0 +1,245 @@
+// Package otlp receives OpenTelemetry metrics sent with the OpenTelemetry
+// protocol (OTLP), and converts them to points.
+//
+// The points of a metric are written to a measurement with the name of the
+// metric, tagged with the attributes of the resource and the data point,
+// following the Prometheus metric format of Telegraf:
+//
+//   - gauges and non-monotonic sums have a "gauge" field
+//   - monotonic sums have a "counter" field
+//   - histograms have "count", "sum", "min" and "max" fields, and a field per
+//     bucket with the cumulative count of values up to its upper bound, such
+//     as "0.5" or "+Inf"
+//   - summaries have "count" and "sum" fields, and a field per quantile, such
+//     as "0.99"
+//
+// Exemplars are written to a measurement with the name of the metric and an
+// "_exemplar" suffix, tagged like their data point and with their filtered
+// attributes, with "value", "trace_id" and "span_id" fields.
+//
+// Exponential histograms are not supported, and are ignored.
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// MetricsToLineProtocol converts an ExportMetricsServiceRequest, encoded in
+// protobuf, to line protocol.
+func MetricsToLineProtocol(data []byte) ([]byte, error) {
+	req, err := decodeMetricsRequest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	for _, rm := range req.GetResourceMetrics() {
+		resource := rm.GetResource().GetAttributes()
+		for _, scopes := range [][]*ScopeMetrics{rm.GetScopeMetrics(), rm.GetInstrumentationLibraryMetrics()} {
+			for _, sm := range scopes {
+				for _, m := range sm.GetMetrics() {
+					if buf, err = appendMetric(buf, resource, m); err != nil {
+						return nil, fmt.Errorf("metric %q: %v", m.GetName(), err)
+					}
+				}
+			}
+		}
+	}
+	return buf, nil
+}
+
+func appendMetric(buf []byte, resource []*KeyValue, m *Metric) ([]byte, error) {
+	switch data := m.GetData().(type) {
+	case *Metric_Gauge:
+		return appendNumbers(buf, m.GetName(), "gauge", resource, data.Gauge.GetDataPoints())
+	case *Metric_Sum:
+		key := "gauge"
+		if data.Sum.GetIsMonotonic() {
+			key = "counter"
+		}
+		return appendNumbers(buf, m.GetName(), key, resource, data.Sum.GetDataPoints())
+	case *Metric_Histogram:
+		var err error
+		for _, p := range data.Histogram.GetDataPoints() {
+			if p.GetFlags()&flagNoRecordedValue != 0 {
+				continue
+			}
+			tags := newTags(resource, p.GetAttributes())
+			fields := models.Fields{}
+			addField(fields, "count", float64(p.GetCount()))
+			if p.GetSumValue() != nil {
+				addField(fields, "sum", p.GetSum())
+			}
+			if p.GetMinValue() != nil {
+				addField(fields, "min", p.GetMin())
+			}
+			if p.GetMaxValue() != nil {
+				addField(fields, "max", p.GetMax())
+			}
+			bounds := p.GetExplicitBounds()
+			var cumulative uint64
+			for i, c := range p.GetBucketCounts() {
+				cumulative += c
+				bound := "+Inf"
+				if i < len(bounds) {
+					bound = formatFloat(bounds[i])
+				}
+				addField(fields, bound, float64(cumulative))
+			}
+			if buf, err = appendPoint(buf, m.GetName(), tags, fields, p.GetTimeUnixNano()); err != nil {
+				return buf, err
+			}
+			if buf, err = appendExemplars(buf, m.GetName(), tags, p.GetExemplars()); err != nil {
+				return buf, err
+			}
+		}
+	case *Metric_Summary:
+		var err error
+		for _, p := range data.Summary.GetDataPoints() {
+			if p.GetFlags()&flagNoRecordedValue != 0 {
+				continue
+			}
+			fields := models.Fields{}
+			addField(fields, "count", float64(p.GetCount()))
+			addField(fields, "sum", p.GetSum())
+			for _, q := range p.GetQuantileValues() {
+				addField(fields, formatFloat(q.GetQuantile()), q.GetValue())
+			}
+			if buf, err = appendPoint(buf, m.GetName(), newTags(resource, p.GetAttributes()), fields, p.GetTimeUnixNano()); err != nil {
+				return buf, err
+			}
+		}
+	}
+	return buf, nil
+}
+
+// appendNumbers appends the points of a gauge or sum, with a key field.
+func appendNumbers(buf []byte, name, key string, resource []*KeyValue, points []*NumberDataPoint) ([]byte, error) {
+	var err error
+	for _, p := range points {
+		if p.GetFlags()&flagNoRecordedValue != 0 {
+			continue
+		}
+		tags := newTags(resource, p.GetAttributes())
+		fields := models.Fields{}
+		addField(fields, key, numberValue(p.GetValue()))
+		if buf, err = appendPoint(buf, name, tags, fields, p.GetTimeUnixNano()); err != nil {
+			return buf, err
+		}
+		if buf, err = appendExemplars(buf, name, tags, p.GetExemplars()); err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
+
+func appendExemplars(buf []byte, name string, tags map[string]string, exemplars []*Exemplar) ([]byte, error) {
+	for _, e := range exemplars {
+		et := tags
+		if attributes := e.GetFilteredAttributes(); len(attributes) > 0 {
+			et = make(map[string]string, len(tags)+len(attributes))
+			for k, v := range tags {
+				et[k] = v
+			}
+			addTags(et, attributes)
+		}
+
+		fields := models.Fields{}
+		addField(fields, "value", numberValue(e.GetValue()))
+		if len(e.GetTraceId()) > 0 {
+			fields["trace_id"] = hex.EncodeToString(e.GetTraceId())
+		}
+		if len(e.GetSpanId()) > 0 {
+			fields["span_id"] = hex.EncodeToString(e.GetSpanId())
+		}
+
+		var err error
+		if buf, err = appendPoint(buf, name+"_exemplar", et, fields, e.GetTimeUnixNano()); err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
+
+// appendPoint appends the line of a point to buf, unless it has no fields.
+// A zero time is the time the point is written.
+func appendPoint(buf []byte, name string, tags map[string]string, fields models.Fields, ts uint64) ([]byte, error) {
+	if len(fields) == 0 {
+		return buf, nil
+	}
+	var t time.Time
+	if ts > 0 {
+		t = time.Unix(0, int64(ts))
+	}
+	p, err := models.NewPoint(name, models.NewTags(tags), fields, t)
+	if err != nil {
+		return buf, err
+	}
+	buf = p.AppendString(buf)
+	return append(buf, '\n'), nil
+}
+
+// addField adds a number to fields, unless it is NaN or infinite, which
+// cannot be written.
+func addField(fields models.Fields, key string, v interface{}) {
+	if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return
+	}
+	if v != nil {
+		fields[key] = v
+	}
+}
+
+// newTags returns the tags of a data point, whose attributes override the
+// attributes of its resource.
+func newTags(resource, attributes []*KeyValue) map[string]string {
+	tags := make(map[string]string, len(resource)+len(attributes))
+	addTags(tags, resource)
+	addTags(tags, attributes)
+	return tags
+}
+
+func addTags(tags map[string]string, attributes []*KeyValue) {
+	for _, a := range attributes {
+		if v := tagValue(anyValue(a.GetValue())); a.GetKey() != "" && v != "" {
+			tags[a.GetKey()] = v
+		}
+	}
+}
+
+// tagValue formats the value of an attribute. Arrays and key value lists are
+// formatted as JSON.
+func tagValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return formatFloat(v)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,184 @@
+package otlp
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func keyValue(key string, value *AnyValue) *KeyValue {
+	return &KeyValue{Key: key, Value: value}
+}
+
+func stringValue(v string) *AnyValue {
+	return &AnyValue{Value: &AnyValue_StringValue{StringValue: v}}
+}
+
+func intValue(v int64) *AnyValue {
+	return &AnyValue{Value: &AnyValue_IntValue{IntValue: v}}
+}
+
+func marshal(t *testing.T, req *ExportMetricsServiceRequest) []byte {
+	t.Helper()
+	b, err := req.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestMetricsToLineProtocol(t *testing.T) {
+	resource := &Resource{Attributes: []*KeyValue{
+		keyValue("service.name", stringValue("api")),
+		keyValue("host.id", intValue(7)),
+		keyValue("empty", stringValue("")),
+	}}
+
+	gauge := &Metric{
+		Name: "cpu",
+		Data: &Metric_Gauge{Gauge: &Gauge{DataPoints: []*NumberDataPoint{
+			{
+				Attributes: []*KeyValue{
+					keyValue("cpu", stringValue("0")),
+					keyValue("service.name", stringValue("worker")),
+				},
+				TimeUnixNano: 1e9,
+				Value:        &NumberDataPoint_AsDouble{AsDouble: 0.5},
+			},
+			{
+				TimeUnixNano: 2e9,
+				Flags:        flagNoRecordedValue,
+			},
+		}}},
+	}
+
+	sum := &Metric{
+		Name: "requests",
+		Data: &Metric_Sum{Sum: &Sum{
+			DataPoints: []*NumberDataPoint{{
+				Attributes:   []*KeyValue{keyValue("method", stringValue("GET"))},
+				TimeUnixNano: 2e9,
+				Value:        &NumberDataPoint_AsInt{AsInt: 10},
+				Exemplars: []*Exemplar{{
+					FilteredAttributes: []*KeyValue{keyValue("path", stringValue("/a b"))},
+					TimeUnixNano:       15e8,
+					Value:              &Exemplar_AsDouble{AsDouble: 0.25},
+					SpanId:             []byte{0xab, 0xcd},
+					TraceId:            []byte{0x01, 0x02},
+				}},
+			}},
+			IsMonotonic: true,
+		}},
+	}
+
+	histogram := &Metric{
+		Name: "latency",
+		Data: &Metric_Histogram{Histogram: &Histogram{DataPoints: []*HistogramDataPoint{{
+			Attributes: []*KeyValue{keyValue("codes", &AnyValue{Value: &AnyValue_ArrayValue{ArrayValue: &ArrayValue{
+				Values: []*AnyValue{intValue(200), stringValue("5xx")},
+			}}})},
+			TimeUnixNano:   3e9,
+			Count:          6,
+			SumValue:       &HistogramDataPoint_Sum{Sum: 3.5},
+			MinValue:       &HistogramDataPoint_Min{Min: 0.05},
+			MaxValue:       &HistogramDataPoint_Max{Max: math.NaN()},
+			BucketCounts:   []uint64{1, 2, 3},
+			ExplicitBounds: []float64{0.1, 1},
+		}}}},
+	}
+
+	summary := &Metric{
+		Name: "rpc",
+		Data: &Metric_Summary{Summary: &Summary{DataPoints: []*SummaryDataPoint{{
+			TimeUnixNano: 4e9,
+			Count:        2,
+			Sum:          1.5,
+			QuantileValues: []*ValueAtQuantile{
+				{Quantile: 0.5, Value: 0.7},
+				{Quantile: 0.99, Value: 0.8},
+			},
+		}}}},
+	}
+
+	req := marshal(t, &ExportMetricsServiceRequest{
+		ResourceMetrics: []*ResourceMetrics{{
+			Resource: resource,
+			ScopeMetrics: []*ScopeMetrics{{
+				Metrics: []*Metric{gauge, sum, histogram, summary},
+			}},
+		}},
+	})
+	// Fields that are not declared in metrics.proto are skipped: a resource
+	// with the exponential histogram "sizes", and an unknown varint field.
+	req = append(req,
+		0x0a, 0x0d, // resource_metrics
+		0x12, 0x0b, // scope_metrics
+		0x12, 0x09, // metrics
+		0x0a, 0x05, 's', 'i', 'z', 'e', 's', // name
+		0x52, 0x00, // exponential_histogram
+		0x48, 0x01, // field 9
+	)
+
+	got, err := MetricsToLineProtocol(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		`cpu,cpu=0,host.id=7,service.name=worker gauge=0.5 1000000000`,
+		`requests,host.id=7,method=GET,service.name=api counter=10i 2000000000`,
+		`requests_exemplar,host.id=7,method=GET,path=/a\ b,service.name=api span_id="abcd",trace_id="0102",value=0.25 1500000000`,
+		`latency,codes=[200\,"5xx"],host.id=7,service.name=api +Inf=6,0.1=1,1=3,count=6,min=0.05,sum=3.5 3000000000`,
+		`rpc,host.id=7,service.name=api 0.5=0.7,0.99=0.8,count=2,sum=1.5 4000000000`,
+		``,
+	}, "\n")
+	if string(got) != want {
+		t.Fatalf("unexpected lines:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMetricsToLineProtocol_LegacyScope(t *testing.T) {
+	// Collectors before OTLP 0.15 send instrumentation_library_metrics.
+	req := marshal(t, &ExportMetricsServiceRequest{
+		ResourceMetrics: []*ResourceMetrics{{
+			InstrumentationLibraryMetrics: []*ScopeMetrics{{
+				Metrics: []*Metric{{
+					Name: "up",
+					Data: &Metric_Gauge{Gauge: &Gauge{DataPoints: []*NumberDataPoint{{
+						Value: &NumberDataPoint_AsDouble{AsDouble: 1},
+					}}}},
+				}},
+			}},
+		}},
+	})
+
+	got, err := MetricsToLineProtocol(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "up gauge=1\n"; string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestMetricsToLineProtocol_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated message", data: []byte{0x0a, 0x02, 0x0a, 0x05}},
+		{name: "truncated length", data: []byte{0x0a, 0x80}},
+		{name: "group", data: []byte{0x13}},
+		{
+			name: "truncated packed",
+			// A histogram with 3 bytes of bucket_counts.
+			data: []byte{0x0a, 0x0d, 0x12, 0x0b, 0x12, 0x09, 0x4a, 0x07, 0x0a, 0x05, 0x32, 0x03, 1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := MetricsToLineProtocol(tt.data); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
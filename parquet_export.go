@@ -0,0 +1,29 @@
+package influxdb
+
+import (
+	"context"
+	"io"
+)
+
+// ErrNoExportData is returned when exporting a measurement with no data in
+// the exported range.
+var ErrNoExportData = &Error{
+	Code: ENotFound,
+	Msg:  "measurement has no data in range",
+}
+
+// ParquetExportService exports the data of buckets as Apache Parquet files,
+// so that other engines can read historical data without the query engine.
+// Exports are partitioned by measurement, since each measurement has its own
+// columns.
+type ParquetExportService interface {
+	// FindExportMeasurements returns the measurements of a bucket with data
+	// in [start, end).
+	FindExportMeasurements(ctx context.Context, orgID, bucketID ID, start, end int64) ([]string, error)
+
+	// ExportParquet writes the data of a measurement of a bucket in
+	// [start, end) to w as a Parquet file, and returns the number of rows
+	// written. It returns ErrNoExportData without writing anything if there
+	// is no data.
+	ExportParquet(ctx context.Context, w io.Writer, orgID, bucketID ID, measurement string, start, end int64) (int64, error)
+}
@@ -1,6 +1,12 @@
 package influxdb
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 // PasswordsService is the service for managing basic auth passwords.
 type PasswordsService interface {
@@ -13,3 +19,83 @@ type PasswordsService interface {
 	// updates to the new password.
 	CompareAndSetPassword(ctx context.Context, userID ID, old, new string) error
 }
+
+// PasswordLockoutService is the service for managing users locked out after
+// too many failed password attempts.
+type PasswordLockoutService interface {
+	// UnlockUser clears the failed password attempts of a user, unlocking
+	// the user if they were locked out.
+	UnlockUser(ctx context.Context, userID ID) error
+}
+
+// PasswordPolicy describes the complexity requirements of passwords and
+// the lockout applied to users after repeated failed password attempts.
+// The zero value places no requirements beyond those of the service.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters in a password.
+	MinLength int
+	// MinCharacterClasses is the minimum number of distinct character
+	// classes (lower case, upper case, digits and symbols) in a password.
+	MinCharacterClasses int
+	// HistorySize is the number of most recent passwords, including the
+	// current one, that may not be reused.
+	HistorySize int
+	// MaxFailedAttempts is the number of consecutive failed password
+	// attempts after which a user is locked out. Zero disables lockout.
+	MaxFailedAttempts int
+	// LockoutDuration is how long a user stays locked out. Zero keeps the
+	// user locked out until they are explicitly unlocked.
+	LockoutDuration time.Duration
+}
+
+// Valid returns an error if the policy is invalid.
+func (p PasswordPolicy) Valid() error {
+	if p.MinLength < 0 || p.HistorySize < 0 || p.MaxFailedAttempts < 0 || p.LockoutDuration < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "password policy settings must not be negative",
+		}
+	}
+	if p.MinCharacterClasses < 0 || p.MinCharacterClasses > 4 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "password policy character classes must be between 0 and 4",
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the password does not meet the length and
+// complexity requirements of the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("passwords must be at least %d characters long", p.MinLength),
+		}
+	}
+
+	if p.MinCharacterClasses > 0 {
+		var lower, upper, digit, symbol int
+		for _, r := range password {
+			switch {
+			case unicode.IsLower(r):
+				lower = 1
+			case unicode.IsUpper(r):
+				upper = 1
+			case unicode.IsDigit(r):
+				digit = 1
+			default:
+				symbol = 1
+			}
+		}
+		if lower+upper+digit+symbol < p.MinCharacterClasses {
+			return &Error{
+				Code: EInvalid,
+				Msg: fmt.Sprintf("passwords must contain at least %d of: lower case letters, upper case letters, digits and symbols",
+					p.MinCharacterClasses),
+			}
+		}
+	}
+	return nil
+}
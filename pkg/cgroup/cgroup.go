@@ -0,0 +1,139 @@
+// Package cgroup detects the CPU and memory limits of the cgroup of the
+// process, such as those set on the container it runs in.
+package cgroup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Root is where the cgroup filesystem is mounted.
+const Root = "/sys/fs/cgroup"
+
+// unlimitedMemory is the least memory limit of cgroups v1 treated as no
+// limit, as v1 reports the largest page aligned int64 when unset.
+const unlimitedMemory = 1 << 62
+
+// Limits are the limits of a cgroup. A limit of zero is unlimited.
+type Limits struct {
+	// Version is the version of cgroups, 1 or 2.
+	Version int
+	// CPUs is the number of CPUs the cgroup may use, which may be fractional.
+	CPUs float64
+	// MemoryBytes is the memory the cgroup may use.
+	MemoryBytes int64
+}
+
+// Detect returns the limits of the cgroup mounted at Root, or nil if there
+// is none.
+func Detect() (*Limits, error) {
+	return Read(Root)
+}
+
+// Read returns the limits of the cgroup mounted at root, or nil if there is
+// none. Within a container, the cgroup of the container is mounted at the
+// root of the cgroup filesystem.
+func Read(root string) (*Limits, error) {
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return readV2(root)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cpu")); err == nil {
+		return readV1(root)
+	}
+	if _, err := os.Stat(filepath.Join(root, "memory")); err == nil {
+		return readV1(root)
+	}
+	return nil, nil
+}
+
+// readV2 reads the limits of a unified cgroup, where cpu.max is the quota
+// and period of the cgroup, and either may be max.
+func readV2(root string) (*Limits, error) {
+	l := &Limits{Version: 2}
+
+	cpu, err := readFields(filepath.Join(root, "cpu.max"))
+	if err != nil {
+		return nil, err
+	}
+	if len(cpu) == 2 && cpu[0] != "max" {
+		if l.CPUs, err = cpus(cpu[0], cpu[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	mem, err := readFields(filepath.Join(root, "memory.max"))
+	if err != nil {
+		return nil, err
+	}
+	if len(mem) == 1 && mem[0] != "max" {
+		if l.MemoryBytes, err = strconv.ParseInt(mem[0], 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// readV1 reads the limits of the cpu and memory hierarchies of a cgroup,
+// where a quota of -1 is unlimited.
+func readV1(root string) (*Limits, error) {
+	l := &Limits{Version: 1}
+
+	quota, err := readFields(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return nil, err
+	}
+	period, err := readFields(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return nil, err
+	}
+	if len(quota) == 1 && len(period) == 1 && quota[0] != "-1" {
+		if l.CPUs, err = cpus(quota[0], period[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	mem, err := readFields(filepath.Join(root, "memory", "memory.limit_in_bytes"))
+	if err != nil {
+		return nil, err
+	}
+	if len(mem) == 1 {
+		n, err := strconv.ParseInt(mem[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if n < unlimitedMemory {
+			l.MemoryBytes = n
+		}
+	}
+	return l, nil
+}
+
+// readFields returns the fields of the file at path, or none if it does not
+// exist, as a controller may not be enabled for a cgroup.
+func readFields(path string) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(b)), nil
+}
+
+func cpus(quota, period string) (float64, error) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, err
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p <= 0 {
+		return 0, nil
+	}
+	return q / p, nil
+}
@@ -0,0 +1,90 @@
+package cgroup_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/pkg/cgroup"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root, err := ioutil.TempDir("", "cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  *cgroup.Limits
+	}{
+		{
+			name: "no cgroup",
+		},
+		{
+			name: "v2 limited",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory\n",
+				"cpu.max":            "150000 100000\n",
+				"memory.max":         "1073741824\n",
+			},
+			want: &cgroup.Limits{Version: 2, CPUs: 1.5, MemoryBytes: 1 << 30},
+		},
+		{
+			name: "v2 unlimited",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory\n",
+				"cpu.max":            "max 100000\n",
+				"memory.max":         "max\n",
+			},
+			want: &cgroup.Limits{Version: 2},
+		},
+		{
+			name: "v1 limited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "200000\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "536870912\n",
+			},
+			want: &cgroup.Limits{Version: 1, CPUs: 2, MemoryBytes: 1 << 29},
+		},
+		{
+			name: "v1 unlimited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "-1\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+			want: &cgroup.Limits{Version: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeFiles(t, tt.files)
+			defer os.RemoveAll(root)
+
+			got, err := cgroup.Read(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("unexpected limits: got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+// Package deltalake maintains the transaction log of Delta Lake tables, so
+// that Parquet files written elsewhere can be registered in a table.
+//
+// Only the parts of the protocol needed to add and remove files are
+// implemented. Tables are read from their JSON commits, so tables with
+// checkpoints, or with features of later protocol versions, are not
+// supported. Stores that cannot create files atomically, such as S3, must only
+// be written by a single writer.
+package deltalake
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Protocol versions of the tables written.
+const (
+	minReaderVersion = 1
+	minWriterVersion = 2
+)
+
+const logDir = "_delta_log"
+
+var commitFile = regexp.MustCompile(`^[0-9]{20}\.json$`)
+
+// Store reads and writes the files of a table. Names are slash separated and
+// relative to the root of the table.
+type Store interface {
+	// List returns the names of the files in dir, or none if dir does not
+	// exist.
+	List(ctx context.Context, dir string) ([]string, error)
+	ReadFile(ctx context.Context, name string) ([]byte, error)
+	// CreateFile creates a file that is expected not to exist.
+	CreateFile(ctx context.Context, name string, data []byte) error
+}
+
+// Field is a column of the schema of a table.
+type Field struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// AddFile is a data file of a table.
+type AddFile struct {
+	// Path is the URL encoded path of the file, relative to the root of the
+	// table.
+	Path             string            `json:"path"`
+	PartitionValues  map[string]string `json:"partitionValues"`
+	Size             int64             `json:"size"`
+	ModificationTime int64             `json:"modificationTime"` // milliseconds since the epoch
+	DataChange       bool              `json:"dataChange"`
+}
+
+type removeFile struct {
+	Path              string `json:"path"`
+	DeletionTimestamp int64  `json:"deletionTimestamp"`
+	DataChange        bool   `json:"dataChange"`
+}
+
+type protocol struct {
+	MinReaderVersion int `json:"minReaderVersion"`
+	MinWriterVersion int `json:"minWriterVersion"`
+}
+
+type format struct {
+	Provider string            `json:"provider"`
+	Options  map[string]string `json:"options"`
+}
+
+type metaData struct {
+	ID               string            `json:"id"`
+	Format           format            `json:"format"`
+	SchemaString     string            `json:"schemaString"`
+	PartitionColumns []string          `json:"partitionColumns"`
+	Configuration    map[string]string `json:"configuration"`
+	CreatedTime      int64             `json:"createdTime"`
+}
+
+type structType struct {
+	Type   string        `json:"type"`
+	Fields []structField `json:"fields"`
+}
+
+type structField struct {
+	Field
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type commitInfo struct {
+	Timestamp           int64             `json:"timestamp"`
+	Operation           string            `json:"operation"`
+	OperationParameters map[string]string `json:"operationParameters"`
+}
+
+// action is a line of a commit, with one of its fields set. Actions not
+// needed to add and remove files are ignored.
+type action struct {
+	Protocol   *protocol   `json:"protocol,omitempty"`
+	MetaData   *metaData   `json:"metaData,omitempty"`
+	Add        *AddFile    `json:"add,omitempty"`
+	Remove     *removeFile `json:"remove,omitempty"`
+	CommitInfo *commitInfo `json:"commitInfo,omitempty"`
+}
+
+// Table is a Delta Lake table.
+type Table struct {
+	store            Store
+	partitionColumns []string
+
+	version int64
+	meta    *metaData
+	schema  []Field
+	files   map[string]AddFile
+}
+
+// Open reads the table of store. A table that does not exist is created with
+// partitionColumns by its first commit, and an existing table must be
+// partitioned by them.
+func Open(ctx context.Context, store Store, partitionColumns []string) (*Table, error) {
+	t := &Table{
+		store:            store,
+		partitionColumns: partitionColumns,
+		version:          -1,
+		files:            make(map[string]AddFile),
+	}
+
+	names, err := store.List(ctx, logDir)
+	if err != nil {
+		return nil, err
+	}
+	var commits []string
+	for _, name := range names {
+		if name == "_last_checkpoint" {
+			return nil, fmt.Errorf("tables with checkpoints are not supported")
+		}
+		if commitFile.MatchString(name) {
+			commits = append(commits, name)
+		}
+	}
+	sort.Strings(commits)
+
+	for i, name := range commits {
+		if name != commitName(int64(i)) {
+			return nil, fmt.Errorf("missing commit %s", commitName(int64(i)))
+		}
+		data, err := store.ReadFile(ctx, path.Join(logDir, name))
+		if err != nil {
+			return nil, err
+		}
+		if err := t.apply(data); err != nil {
+			return nil, fmt.Errorf("invalid commit %s: %v", name, err)
+		}
+		t.version = int64(i)
+	}
+
+	if t.meta != nil && !equalStrings(t.meta.PartitionColumns, partitionColumns) {
+		return nil, fmt.Errorf("table is partitioned by %v, not %v", t.meta.PartitionColumns, partitionColumns)
+	}
+	return t, nil
+}
+
+// apply applies the actions of a commit to the state of the table.
+func (t *Table) apply(data []byte) error {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var a action
+		if err := json.Unmarshal(line, &a); err != nil {
+			return err
+		}
+		switch {
+		case a.Protocol != nil:
+			if a.Protocol.MinReaderVersion > minReaderVersion || a.Protocol.MinWriterVersion > minWriterVersion {
+				return fmt.Errorf("protocol %d/%d is not supported", a.Protocol.MinReaderVersion, a.Protocol.MinWriterVersion)
+			}
+		case a.MetaData != nil:
+			var schema structType
+			if err := json.Unmarshal([]byte(a.MetaData.SchemaString), &schema); err != nil {
+				return fmt.Errorf("unsupported schema: %v", err)
+			}
+			t.meta = a.MetaData
+			t.schema = t.schema[:0]
+			for _, f := range schema.Fields {
+				t.schema = append(t.schema, f.Field)
+			}
+		case a.Add != nil:
+			t.files[a.Add.Path] = *a.Add
+		case a.Remove != nil:
+			delete(t.files, a.Remove.Path)
+		}
+	}
+	return nil
+}
+
+// Version returns the version of the table, or -1 if it does not exist.
+func (t *Table) Version() int64 {
+	return t.version
+}
+
+// Schema returns the schema of the table.
+func (t *Table) Schema() []Field {
+	return append([]Field(nil), t.schema...)
+}
+
+// Files returns the data files of the table, sorted by path.
+func (t *Table) Files() []AddFile {
+	files := make([]AddFile, 0, len(t.files))
+	for _, f := range t.files {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// Commit commits a version of the table that adds the files of add, and
+// removes the files with the paths of remove. The fields of schema the table
+// lacks are added to its schema, and must include the partition columns of a
+// new table. Removed files are left in the store for readers of previous
+// versions.
+func (t *Table) Commit(ctx context.Context, schema []Field, add []AddFile, remove []string) error {
+	merged, changed, err := mergeSchema(t.schema, schema)
+	if err != nil {
+		return err
+	}
+	for _, p := range remove {
+		if _, ok := t.files[p]; !ok {
+			return fmt.Errorf("file %q is not in the table", p)
+		}
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	var actions []action
+	meta := t.meta
+	if meta == nil {
+		for _, c := range t.partitionColumns {
+			if !hasField(merged, c) {
+				return fmt.Errorf("schema has no partition column %q", c)
+			}
+		}
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		actions = append(actions, action{Protocol: &protocol{
+			MinReaderVersion: minReaderVersion,
+			MinWriterVersion: minWriterVersion,
+		}})
+		meta = &metaData{
+			ID:               id,
+			Format:           format{Provider: "parquet", Options: map[string]string{}},
+			PartitionColumns: append([]string{}, t.partitionColumns...),
+			Configuration:    map[string]string{},
+			CreatedTime:      now,
+		}
+		changed = true
+	}
+	if changed {
+		m := *meta
+		fields := make([]structField, len(merged))
+		for i, f := range merged {
+			fields[i] = structField{Field: f, Metadata: map[string]interface{}{}}
+		}
+		s, err := json.Marshal(structType{Type: "struct", Fields: fields})
+		if err != nil {
+			return err
+		}
+		m.SchemaString = string(s)
+		meta = &m
+		actions = append(actions, action{MetaData: meta})
+	}
+
+	for _, p := range remove {
+		actions = append(actions, action{Remove: &removeFile{Path: p, DeletionTimestamp: now, DataChange: true}})
+	}
+	for i := range add {
+		actions = append(actions, action{Add: &add[i]})
+	}
+	partitionBy, err := json.Marshal(meta.PartitionColumns)
+	if err != nil {
+		return err
+	}
+	actions = append(actions, action{CommitInfo: &commitInfo{
+		Timestamp: now,
+		Operation: "WRITE",
+		OperationParameters: map[string]string{
+			"mode":        "Append",
+			"partitionBy": string(partitionBy),
+		},
+	}})
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, a := range actions {
+		if err := enc.Encode(a); err != nil {
+			return err
+		}
+	}
+	version := t.version + 1
+	if err := t.store.CreateFile(ctx, path.Join(logDir, commitName(version)), buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to commit version %d: %v", version, err)
+	}
+
+	t.version = version
+	t.meta = meta
+	t.schema = merged
+	for _, p := range remove {
+		delete(t.files, p)
+	}
+	for _, f := range add {
+		t.files[f.Path] = f
+	}
+	return nil
+}
+
+// mergeSchema returns schema with the fields of fields it lacks appended, and
+// whether any were.
+func mergeSchema(schema, fields []Field) ([]Field, bool, error) {
+	merged := append([]Field(nil), schema...)
+	var changed bool
+	for _, f := range fields {
+		i := 0
+		for i < len(merged) && merged[i].Name != f.Name {
+			i++
+		}
+		if i == len(merged) {
+			merged = append(merged, f)
+			changed = true
+			continue
+		}
+		if merged[i].Type != f.Type {
+			return nil, false, fmt.Errorf("column %q has type %s in the table, not %s", f.Name, merged[i].Type, f.Type)
+		}
+		if f.Nullable && !merged[i].Nullable {
+			merged[i].Nullable = true
+			changed = true
+		}
+	}
+	return merged, changed, nil
+}
+
+func hasField(fields []Field, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func commitName(version int64) string {
+	return fmt.Sprintf("%020d.json", version)
+}
+
+// newID returns a random UUID identifying a table.
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,151 @@
+package deltalake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// memStore is a Store of files in memory.
+type memStore map[string][]byte
+
+func (s memStore) List(ctx context.Context, dir string) ([]string, error) {
+	var names []string
+	for name := range s {
+		if path.Dir(name) == dir {
+			names = append(names, path.Base(name))
+		}
+	}
+	return names, nil
+}
+
+func (s memStore) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, fmt.Errorf("%s does not exist", name)
+	}
+	return data, nil
+}
+
+func (s memStore) CreateFile(ctx context.Context, name string, data []byte) error {
+	if _, ok := s[name]; ok {
+		return fmt.Errorf("%s already exists", name)
+	}
+	s[name] = data
+	return nil
+}
+
+func addFile(p, date string) AddFile {
+	return AddFile{
+		Path:            p,
+		PartitionValues: map[string]string{"date": date},
+		Size:            100,
+		DataChange:      true,
+	}
+}
+
+func TestTable(t *testing.T) {
+	ctx := context.Background()
+	store := make(memStore)
+	partitionColumns := []string{"date"}
+
+	tbl, err := Open(ctx, store, partitionColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tbl.Version(); got != -1 {
+		t.Fatalf("got version %d for new table", got)
+	}
+
+	schema := []Field{
+		{Name: "time", Type: "timestamp"},
+		{Name: "host", Type: "string", Nullable: true},
+		{Name: "date", Type: "date", Nullable: true},
+	}
+	if err := tbl.Commit(ctx, schema[:2], nil, nil); err == nil {
+		t.Fatal("expected error creating table without partition column")
+	}
+	a := addFile("date=2020-01-01/part-1.parquet", "2020-01-01")
+	b := addFile("date=2020-01-02/part-1.parquet", "2020-01-02")
+	if err := tbl.Commit(ctx, schema, []AddFile{a, b}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first commit creates the table.
+	var actions []map[string]json.RawMessage
+	for _, line := range bytes.Split(bytes.TrimSpace(store["_delta_log/00000000000000000000.json"]), []byte("\n")) {
+		var a map[string]json.RawMessage
+		if err := json.Unmarshal(line, &a); err != nil {
+			t.Fatal(err)
+		}
+		actions = append(actions, a)
+	}
+	var kinds []string
+	for _, a := range actions {
+		for k := range a {
+			kinds = append(kinds, k)
+		}
+	}
+	if want := []string{"protocol", "metaData", "add", "add", "commitInfo"}; !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("got actions %v, want %v", kinds, want)
+	}
+
+	// Replace a file, with a schema with a new column.
+	c := addFile("date=2020-01-02/part-2.parquet", "2020-01-02")
+	schema2 := []Field{
+		{Name: "time", Type: "timestamp"},
+		{Name: "usage", Type: "double", Nullable: true},
+		{Name: "date", Type: "date", Nullable: true},
+	}
+	if err := tbl.Commit(ctx, schema2, []AddFile{c}, []string{b.Path}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Commit(ctx, nil, nil, []string{b.Path}); err == nil {
+		t.Fatal("expected error removing file not in table")
+	}
+	conflict := []Field{{Name: "usage", Type: "long", Nullable: true}}
+	if err := tbl.Commit(ctx, conflict, nil, nil); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Fatalf("expected error for column type conflict, got %v", err)
+	}
+
+	tbl, err = Open(ctx, store, partitionColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tbl.Version(); got != 1 {
+		t.Fatalf("got version %d, want 1", got)
+	}
+	wantSchema := []Field{
+		{Name: "time", Type: "timestamp"},
+		{Name: "host", Type: "string", Nullable: true},
+		{Name: "date", Type: "date", Nullable: true},
+		{Name: "usage", Type: "double", Nullable: true},
+	}
+	if got := tbl.Schema(); !reflect.DeepEqual(got, wantSchema) {
+		t.Fatalf("got schema %v, want %v", got, wantSchema)
+	}
+	if got, want := tbl.Files(), []AddFile{a, c}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got files %v, want %v", got, want)
+	}
+
+	// A writer with a stale version fails to commit.
+	stale, err := Open(ctx, store, partitionColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Commit(ctx, nil, []AddFile{addFile("date=2020-01-03/part-1.parquet", "2020-01-03")}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := stale.Commit(ctx, nil, []AddFile{addFile("date=2020-01-04/part-1.parquet", "2020-01-04")}, nil); err == nil {
+		t.Fatal("expected error committing over existing version")
+	}
+
+	if _, err := Open(ctx, store, []string{"day"}); err == nil {
+		t.Fatal("expected error opening table with other partition columns")
+	}
+}
@@ -0,0 +1,200 @@
+// Package jsonpath evaluates a subset of JSONPath expressions on decoded JSON
+// values.
+//
+// Expressions start with "$", the root of a document, or "@", the current
+// value, followed by member names (.name or ['name']), array indexes ([0], or
+// [-1] for the last element) and wildcards (.* or [*]), which select every
+// element of an array or member of an object. Filters, slices and recursive
+// descent are not supported.
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type stepKind int
+
+const (
+	stepMember stepKind = iota
+	stepIndex
+	stepWildcard
+)
+
+type step struct {
+	kind  stepKind
+	name  string
+	index int
+}
+
+// Path is a parsed JSONPath expression.
+type Path struct {
+	expr     string
+	relative bool
+	steps    []step
+}
+
+// Parse parses a JSONPath expression.
+func Parse(expr string) (*Path, error) {
+	p := &Path{expr: expr}
+	if expr == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	switch expr[0] {
+	case '$':
+	case '@':
+		p.relative = true
+	default:
+		return nil, fmt.Errorf("path %q must start with $ or @", expr)
+	}
+
+	s := expr[1:]
+	for len(s) > 0 {
+		var (
+			st  step
+			err error
+		)
+		switch s[0] {
+		case '.':
+			st, s, err = parseDot(s[1:])
+		case '[':
+			st, s, err = parseBracket(s[1:])
+		default:
+			err = fmt.Errorf("unexpected %q", s[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %v", expr, err)
+		}
+		p.steps = append(p.steps, st)
+	}
+	return p, nil
+}
+
+// parseDot parses the step after a dot, and returns the rest of s.
+func parseDot(s string) (step, string, error) {
+	if strings.HasPrefix(s, "*") {
+		return step{kind: stepWildcard}, s[1:], nil
+	}
+	if strings.HasPrefix(s, ".") {
+		return step{}, "", fmt.Errorf("recursive descent is not supported")
+	}
+	n := strings.IndexAny(s, ".[")
+	if n < 0 {
+		n = len(s)
+	}
+	if n == 0 {
+		return step{}, "", fmt.Errorf("missing member name")
+	}
+	return step{kind: stepMember, name: s[:n]}, s[n:], nil
+}
+
+// parseBracket parses the step after an opening bracket, and returns the rest
+// of s after the closing bracket.
+func parseBracket(s string) (step, string, error) {
+	if strings.HasPrefix(s, "*]") {
+		return step{kind: stepWildcard}, s[2:], nil
+	}
+
+	if len(s) > 0 && (s[0] == '\'' || s[0] == '"') {
+		quote := s[0]
+		var name strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s):
+				i++
+				name.WriteByte(s[i])
+			case c == quote:
+				if i+1 >= len(s) || s[i+1] != ']' {
+					return step{}, "", fmt.Errorf("missing ] after member name")
+				}
+				return step{kind: stepMember, name: name.String()}, s[i+2:], nil
+			default:
+				name.WriteByte(c)
+			}
+		}
+		return step{}, "", fmt.Errorf("unterminated member name")
+	}
+
+	n := strings.IndexByte(s, ']')
+	if n < 0 {
+		return step{}, "", fmt.Errorf("missing ]")
+	}
+	i, err := strconv.Atoi(s[:n])
+	if err != nil {
+		return step{}, "", fmt.Errorf("invalid index %q", s[:n])
+	}
+	return step{kind: stepIndex, index: i}, s[n+1:], nil
+}
+
+// String returns the expression of the path.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Relative reports whether the path starts from the current value.
+func (p *Path) Relative() bool {
+	return p.relative
+}
+
+// Definite reports whether the path selects at most one value.
+func (p *Path) Definite() bool {
+	for _, st := range p.steps {
+		if st.kind == stepWildcard {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the values selected by the path, as decoded by encoding/json
+// into an interface{}. Relative paths start from current, others from root.
+// The members of objects selected by wildcards are in the order of their
+// names.
+func (p *Path) Select(root, current interface{}) []interface{} {
+	values := []interface{}{root}
+	if p.relative {
+		values[0] = current
+	}
+
+	for _, st := range p.steps {
+		var next []interface{}
+		for _, v := range values {
+			switch st.kind {
+			case stepMember:
+				if obj, ok := v.(map[string]interface{}); ok {
+					if m, ok := obj[st.name]; ok {
+						next = append(next, m)
+					}
+				}
+			case stepIndex:
+				if arr, ok := v.([]interface{}); ok {
+					i := st.index
+					if i < 0 {
+						i += len(arr)
+					}
+					if i >= 0 && i < len(arr) {
+						next = append(next, arr[i])
+					}
+				}
+			case stepWildcard:
+				switch v := v.(type) {
+				case []interface{}:
+					next = append(next, v...)
+				case map[string]interface{}:
+					names := make([]string, 0, len(v))
+					for name := range v {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					for _, name := range names {
+						next = append(next, v[name])
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
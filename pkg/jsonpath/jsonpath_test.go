@@ -0,0 +1,89 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPath_Select(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"repository": {"full_name": "influxdata/influxdb", "stars": 10},
+		"commits": [
+			{"id": "a", "author": {"name": "x"}},
+			{"id": "b", "author": {"name": "y"}}
+		],
+		"odd key": {"b": 2, "a": 1}
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	commit := doc.(map[string]interface{})["commits"].([]interface{})[1]
+
+	tests := []struct {
+		expr string
+		want []interface{}
+	}{
+		{expr: "$", want: []interface{}{doc}},
+		{expr: "$.repository.full_name", want: []interface{}{"influxdata/influxdb"}},
+		{expr: "$['repository']['stars']", want: []interface{}{10.0}},
+		{expr: `$["odd key"].*`, want: []interface{}{1.0, 2.0}},
+		{expr: "$.commits[*].id", want: []interface{}{"a", "b"}},
+		{expr: "$.commits.*.author.name", want: []interface{}{"x", "y"}},
+		{expr: "$.commits[0].id", want: []interface{}{"a"}},
+		{expr: "$.commits[-1].id", want: []interface{}{"b"}},
+		{expr: "$.commits[2].id"},
+		{expr: "$.missing.id"},
+		{expr: "$.repository[0]"},
+		{expr: "@.author.name", want: []interface{}{"y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Select(doc, commit); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr     string
+		definite bool
+		relative bool
+		wantErr  bool
+	}{
+		{expr: "$", definite: true},
+		{expr: "@.a['b\\'c'][3]", definite: true, relative: true},
+		{expr: "$.a[*].b", definite: false},
+		{expr: "", wantErr: true},
+		{expr: "a.b", wantErr: true},
+		{expr: "$..a", wantErr: true},
+		{expr: "$.", wantErr: true},
+		{expr: "$[1", wantErr: true},
+		{expr: "$[a]", wantErr: true},
+		{expr: "$['a'", wantErr: true},
+		{expr: "$a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			p, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.Definite() != tt.definite || p.Relative() != tt.relative {
+				t.Fatalf("got definite %v relative %v", p.Definite(), p.Relative())
+			}
+			if p.String() != tt.expr {
+				t.Fatalf("got expression %q", p.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ReadColumns reads the metadata of the file of size bytes read by r, and
+// returns its columns and number of rows. Only the flat schemas and types of
+// files written by Writer are supported.
+func ReadColumns(r io.ReaderAt, size int64) ([]Column, int64, error) {
+	if size < int64(2*len(magic)+4) {
+		return nil, 0, fmt.Errorf("file of %d bytes is too small", size)
+	}
+	var tail [8]byte
+	if _, err := r.ReadAt(tail[:], size-8); err != nil {
+		return nil, 0, err
+	}
+	if !bytes.Equal(tail[4:], magic) {
+		return nil, 0, fmt.Errorf("file is not a parquet file")
+	}
+	n := int64(binary.LittleEndian.Uint32(tail[:4]))
+	if n > size-8-int64(len(magic)) {
+		return nil, 0, fmt.Errorf("metadata of %d bytes overflows file", n)
+	}
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, size-8-n); err != nil {
+		return nil, 0, err
+	}
+
+	meta, err := readThriftStruct(bytes.NewReader(buf))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read metadata: %v", err)
+	}
+	schema, _ := meta[2].([]interface{})
+	if len(schema) == 0 {
+		return nil, 0, fmt.Errorf("metadata has no schema")
+	}
+	cols := make([]Column, 0, len(schema)-1)
+	for _, v := range schema[1:] {
+		el, _ := v.(map[int16]interface{})
+		if _, ok := el[5]; ok {
+			return nil, 0, fmt.Errorf("nested schemas are not supported")
+		}
+		name, _ := el[4].(string)
+		physical, _ := el[1].(int64)
+		converted, ok := el[6].(int64)
+		if !ok {
+			converted = convertedNone
+		}
+		typ, ok := columnType(int32(physical), int32(converted))
+		if !ok {
+			return nil, 0, fmt.Errorf("column %q has unsupported type %d/%d", name, physical, converted)
+		}
+		repetition, _ := el[3].(int64)
+		if repetition > 1 {
+			return nil, 0, fmt.Errorf("repeated column %q is not supported", name)
+		}
+		cols = append(cols, Column{Name: name, Type: typ, Optional: repetition == 1})
+	}
+	numRows, _ := meta[3].(int64)
+	return cols, numRows, nil
+}
+
+// columnType returns the Type of a column with a physical and converted type.
+func columnType(physical, converted int32) (Type, bool) {
+	for _, t := range []Type{Boolean, Int64, Uint64, Double, String, Timestamp} {
+		if t.physical() == physical && t.converted() == converted {
+			return t, true
+		}
+	}
+	return 0, false
+}
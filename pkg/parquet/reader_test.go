@@ -0,0 +1,45 @@
+package parquet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReadColumns(t *testing.T) {
+	cols := []Column{
+		{Name: "time", Type: Timestamp},
+		{Name: "host", Type: String, Optional: true},
+		{Name: "usage", Type: Double, Optional: true},
+		{Name: "count", Type: Int64, Optional: true},
+		{Name: "bytes", Type: Uint64, Optional: true},
+		{Name: "up", Type: Boolean},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, cols)
+	for i := 0; i < 3; i++ {
+		if err := w.Write([]interface{}{int64(i), nil, nil, nil, nil, true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	got, n, err := ReadColumns(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cols) {
+		t.Fatalf("got columns %v, want %v", got, cols)
+	}
+	if n != 3 {
+		t.Fatalf("got %d rows, want 3", n)
+	}
+
+	if _, _, err := ReadColumns(bytes.NewReader(data[:len(data)-1]), int64(len(data)-1)); err == nil {
+		t.Fatal("expected error reading truncated file")
+	}
+}
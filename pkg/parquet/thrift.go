@@ -0,0 +1,218 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The metadata of Parquet files is serialized with the Thrift compact
+// protocol. Only the parts of the protocol needed to write the metadata, and
+// to read it back, are implemented here.
+
+// Thrift compact protocol field types.
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftByte      = 3
+	thriftI16       = 4
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftDouble    = 7
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftSet       = 10
+	thriftStruct    = 12
+)
+
+// thriftWriter serializes a struct with the Thrift compact protocol.
+type thriftWriter struct {
+	buf []byte
+
+	// last is the last field ID written to each open struct, since field
+	// IDs are written as deltas.
+	last []int16
+}
+
+// begin starts the top-level struct.
+func (w *thriftWriter) begin() {
+	w.last = append(w.last[:0], 0)
+}
+
+// end ends the top-level struct, and returns the serialized struct.
+func (w *thriftWriter) end() []byte {
+	w.endStruct()
+	return w.buf
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	for v >= 0x80 {
+		w.buf = append(w.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	w.buf = append(w.buf, byte(v))
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if d := id - *last; d > 0 && d <= 15 {
+		w.buf = append(w.buf, byte(d)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) bool(id int16, v bool) {
+	if v {
+		w.field(id, thriftBoolTrue)
+	} else {
+		w.field(id, thriftBoolFalse)
+	}
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.zigzag(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.zigzag(v)
+}
+
+func (w *thriftWriter) binary(id int16, v string) {
+	w.field(id, thriftBinary)
+	w.binaryValue(v)
+}
+
+func (w *thriftWriter) binaryValue(v string) {
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// beginStruct starts a struct field, which is ended by endStruct.
+func (w *thriftWriter) beginStruct(id int16) {
+	w.field(id, thriftStruct)
+	w.beginElem()
+}
+
+func (w *thriftWriter) endStruct() {
+	w.buf = append(w.buf, 0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// list starts a list field of n elements of typ. The elements are written
+// with beginElem and endStruct for structs, or with the value methods.
+func (w *thriftWriter) list(id int16, typ byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|typ)
+	} else {
+		w.buf = append(w.buf, 0xf0|typ)
+		w.varint(uint64(n))
+	}
+}
+
+// beginElem starts a struct element of a list.
+func (w *thriftWriter) beginElem() {
+	w.last = append(w.last, 0)
+}
+
+// readThriftStruct reads a struct serialized with the Thrift compact protocol
+// from r, as a map of field IDs to values. Integers are read as int64,
+// binaries as string, lists and sets as []interface{} and structs as
+// map[int16]interface{}.
+func readThriftStruct(r *bytes.Reader) (map[int16]interface{}, error) {
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return fields, nil
+		}
+		if d := int16(b >> 4); d != 0 {
+			id += d
+		} else {
+			v, err := readZigzag(r)
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		if fields[id], err = readThriftValue(r, b&0x0f); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func readThriftValue(r *bytes.Reader, typ byte) (interface{}, error) {
+	switch typ {
+	case thriftBoolTrue:
+		return true, nil
+	case thriftBoolFalse:
+		return false, nil
+	case thriftByte:
+		b, err := r.ReadByte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return readZigzag(r)
+	case thriftDouble:
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+	case thriftBinary:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(r, b)
+		return string(b), err
+	case thriftList, thriftSet:
+		h, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(h >> 4)
+		if n == 15 {
+			if n, err = binary.ReadUvarint(r); err != nil {
+				return nil, err
+			}
+		}
+		if n > uint64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			if list[i], err = readThriftValue(r, h&0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case thriftStruct:
+		return readThriftStruct(r)
+	default:
+		return nil, fmt.Errorf("unsupported thrift type %d", typ)
+	}
+}
+
+func readZigzag(r *bytes.Reader) (int64, error) {
+	v, err := binary.ReadUvarint(r)
+	return int64(v>>1) ^ -int64(v&1), err
+}
@@ -0,0 +1,426 @@
+// Package parquet writes Apache Parquet files.
+//
+// Files have a flat schema of optional or required columns. Each row group
+// holds a single snappy compressed data page per column, with values in the
+// plain encoding. Logical types are set with the converted types of the
+// format, which every reader supports.
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/golang/snappy"
+)
+
+// DefaultRowGroupSize is the default number of rows of a row group.
+const DefaultRowGroupSize = 128 * 1024
+
+var magic = []byte("PAR1")
+
+// Type is the type of the values of a column.
+type Type int
+
+// Types of column values, and the Go types of the values written to them.
+const (
+	Boolean   Type = iota // bool
+	Int64                 // int64
+	Uint64                // uint64
+	Double                // float64
+	String                // string
+	Timestamp             // int64 nanoseconds since the epoch, stored in microseconds
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "boolean"
+	case Int64:
+		return "int64"
+	case Uint64:
+		return "uint64"
+	case Double:
+		return "double"
+	case String:
+		return "string"
+	case Timestamp:
+		return "timestamp"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Parquet physical types.
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+)
+
+// Parquet converted types.
+const (
+	convertedNone            = -1
+	convertedUTF8            = 0
+	convertedTimestampMicros = 10
+	convertedUint64          = 14
+)
+
+// accepts reports whether v is a value of the Go type of t.
+func (t Type) accepts(v interface{}) bool {
+	var ok bool
+	switch t {
+	case Boolean:
+		_, ok = v.(bool)
+	case Int64, Timestamp:
+		_, ok = v.(int64)
+	case Uint64:
+		_, ok = v.(uint64)
+	case Double:
+		_, ok = v.(float64)
+	case String:
+		_, ok = v.(string)
+	}
+	return ok
+}
+
+func (t Type) physical() int32 {
+	switch t {
+	case Boolean:
+		return physicalBoolean
+	case Double:
+		return physicalDouble
+	case String:
+		return physicalByteArray
+	default:
+		return physicalInt64
+	}
+}
+
+func (t Type) converted() int32 {
+	switch t {
+	case String:
+		return convertedUTF8
+	case Timestamp:
+		return convertedTimestampMicros
+	case Uint64:
+		return convertedUint64
+	default:
+		return convertedNone
+	}
+}
+
+// Column is a column of the schema of a file.
+type Column struct {
+	Name string
+	Type Type
+
+	// Optional columns may have null values.
+	Optional bool
+}
+
+// Writer writes rows to a Parquet file.
+type Writer struct {
+	w    io.Writer
+	n    int64
+	err  error
+	cols []Column
+
+	// RowGroupSize is the number of rows buffered before a row group is
+	// written.
+	RowGroupSize int
+
+	chunks    []columnChunk
+	rows      int
+	numRows   int64
+	rowGroups []rowGroup
+}
+
+// NewWriter returns a Writer writing rows with columns cols to w. Close must
+// be called to complete the file.
+func NewWriter(w io.Writer, cols []Column) *Writer {
+	pw := &Writer{
+		w:            w,
+		cols:         cols,
+		RowGroupSize: DefaultRowGroupSize,
+		chunks:       make([]columnChunk, len(cols)),
+	}
+	pw.write(magic)
+	return pw
+}
+
+func (w *Writer) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+}
+
+// Write buffers a row, with a value for each column. Nil values are null.
+func (w *Writer) Write(row []interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(row) != len(w.cols) {
+		return fmt.Errorf("row has %d values, expected %d", len(row), len(w.cols))
+	}
+	for i, v := range row {
+		if v == nil && !w.cols[i].Optional {
+			return fmt.Errorf("column %q is not optional", w.cols[i].Name)
+		}
+		if v != nil && !w.cols[i].Type.accepts(v) {
+			return fmt.Errorf("cannot write %T to %s column %q", v, w.cols[i].Type, w.cols[i].Name)
+		}
+	}
+	for i, v := range row {
+		w.chunks[i].append(w.cols[i].Type, v)
+	}
+	w.rows++
+	if w.rows >= w.RowGroupSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered rows as a row group.
+func (w *Writer) Flush() error {
+	if w.err != nil || w.rows == 0 {
+		return w.err
+	}
+
+	rg := rowGroup{numRows: int64(w.rows), columns: make([]columnChunkMeta, len(w.cols))}
+	for i, col := range w.cols {
+		c := &w.chunks[i]
+		payload := c.encode(col.Optional)
+		compressed := snappy.Encode(nil, payload)
+
+		var t thriftWriter
+		t.begin()
+		t.i32(1, 0) // DATA_PAGE
+		t.i32(2, int32(len(payload)))
+		t.i32(3, int32(len(compressed)))
+		t.beginStruct(5)
+		t.i32(1, int32(w.rows))
+		t.i32(2, 0) // PLAIN
+		t.i32(3, 3) // RLE
+		t.i32(4, 3) // RLE
+		t.endStruct()
+		header := t.end()
+
+		rg.columns[i] = columnChunkMeta{
+			offset:            w.n,
+			numValues:         int64(w.rows),
+			uncompressedBytes: int64(len(header) + len(payload)),
+			compressedBytes:   int64(len(header) + len(compressed)),
+		}
+		rg.totalBytes += rg.columns[i].uncompressedBytes
+		w.write(header)
+		w.write(compressed)
+		c.reset()
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	w.rowGroups = append(w.rowGroups, rg)
+	w.numRows += int64(w.rows)
+	w.rows = 0
+	return nil
+}
+
+// Close writes the buffered rows and the metadata of the file. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	meta := w.metadata()
+	w.write(meta)
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(meta)))
+	w.write(size[:])
+	w.write(magic)
+	return w.err
+}
+
+// NumRows returns the number of rows written.
+func (w *Writer) NumRows() int64 {
+	return w.numRows + int64(w.rows)
+}
+
+// metadata returns the serialized FileMetaData of the file.
+func (w *Writer) metadata() []byte {
+	var t thriftWriter
+	t.begin()
+	t.i32(1, 1)
+
+	t.list(2, thriftStruct, len(w.cols)+1)
+	t.beginElem()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(w.cols)))
+	t.endStruct()
+	for _, col := range w.cols {
+		t.beginElem()
+		t.i32(1, col.Type.physical())
+		if col.Optional {
+			t.i32(3, 1)
+		} else {
+			t.i32(3, 0)
+		}
+		t.binary(4, col.Name)
+		if c := col.Type.converted(); c != convertedNone {
+			t.i32(6, c)
+		}
+		t.endStruct()
+	}
+
+	t.i64(3, w.numRows)
+
+	t.list(4, thriftStruct, len(w.rowGroups))
+	for _, rg := range w.rowGroups {
+		t.beginElem()
+		t.list(1, thriftStruct, len(rg.columns))
+		for i, c := range rg.columns {
+			t.beginElem()
+			t.i64(2, c.offset)
+			t.beginStruct(3)
+			t.i32(1, w.cols[i].Type.physical())
+			t.list(2, thriftI32, 2)
+			t.zigzag(0) // PLAIN
+			t.zigzag(3) // RLE
+			t.list(3, thriftBinary, 1)
+			t.binaryValue(w.cols[i].Name)
+			t.i32(4, 1) // SNAPPY
+			t.i64(5, c.numValues)
+			t.i64(6, c.uncompressedBytes)
+			t.i64(7, c.compressedBytes)
+			t.i64(9, c.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, rg.totalBytes)
+		t.i64(3, rg.numRows)
+		t.endStruct()
+	}
+
+	t.binary(6, "influxdb")
+	return t.end()
+}
+
+type rowGroup struct {
+	columns    []columnChunkMeta
+	totalBytes int64
+	numRows    int64
+}
+
+type columnChunkMeta struct {
+	offset            int64
+	numValues         int64
+	uncompressedBytes int64
+	compressedBytes   int64
+}
+
+// columnChunk buffers the values of a column of a row group.
+type columnChunk struct {
+	defs   []byte // definition level of each row
+	values []byte // plain encoded values, except booleans
+	bools  []bool
+}
+
+// append appends v, which is nil or accepted by typ.
+func (c *columnChunk) append(typ Type, v interface{}) {
+	if v == nil {
+		c.defs = append(c.defs, 0)
+		return
+	}
+	c.defs = append(c.defs, 1)
+
+	switch typ {
+	case Boolean:
+		c.bools = append(c.bools, v.(bool))
+	case Int64:
+		c.values = appendUint64(c.values, uint64(v.(int64)))
+	case Timestamp:
+		c.values = appendUint64(c.values, uint64(floorDiv(v.(int64), 1000)))
+	case Uint64:
+		c.values = appendUint64(c.values, v.(uint64))
+	case Double:
+		c.values = appendUint64(c.values, math.Float64bits(v.(float64)))
+	case String:
+		s := v.(string)
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], uint32(len(s)))
+		c.values = append(c.values, size[:]...)
+		c.values = append(c.values, s...)
+	}
+}
+
+// encode returns the definition levels, if the column is optional, followed
+// by the values.
+func (c *columnChunk) encode(optional bool) []byte {
+	var buf []byte
+	if optional {
+		levels := encodeLevels(c.defs)
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], uint32(len(levels)))
+		buf = append(buf, size[:]...)
+		buf = append(buf, levels...)
+	}
+	if c.bools != nil {
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, b := range c.bools {
+			if b {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		return append(buf, packed...)
+	}
+	return append(buf, c.values...)
+}
+
+func (c *columnChunk) reset() {
+	c.defs = c.defs[:0]
+	c.values = c.values[:0]
+	c.bools = c.bools[:0]
+}
+
+// encodeLevels encodes levels of bit width 1 as runs of the RLE/bit-packing
+// hybrid encoding.
+func encodeLevels(levels []byte) []byte {
+	var (
+		buf    []byte
+		header [binary.MaxVarintLen64]byte
+	)
+	for i := 0; i < len(levels); {
+		j := i + 1
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		n := binary.PutUvarint(header[:], uint64(j-i)<<1)
+		buf = append(buf, header[:n]...)
+		buf = append(buf, levels[i])
+		i = j
+	}
+	return buf
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// floorDiv returns x/y rounded down, so that times before the epoch are
+// truncated to the previous microsecond.
+func floorDiv(x, y int64) int64 {
+	q := x / y
+	if x%y < 0 {
+		q--
+	}
+	return q
+}
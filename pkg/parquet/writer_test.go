@@ -0,0 +1,169 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/snappy"
+)
+
+func TestWriter(t *testing.T) {
+	cols := []Column{
+		{Name: "time", Type: Timestamp},
+		{Name: "host", Type: String, Optional: true},
+		{Name: "usage", Type: Double, Optional: true},
+		{Name: "count", Type: Int64, Optional: true},
+		{Name: "bytes", Type: Uint64, Optional: true},
+		{Name: "up", Type: Boolean, Optional: true},
+	}
+	rows := [][]interface{}{
+		{int64(1000), "a", 1.5, int64(-1), uint64(10), true},
+		{int64(2000), nil, nil, nil, nil, nil},
+		{int64(-1500), "b", 2.5, int64(3), nil, false},
+		{int64(4000), "c", nil, int64(4), uint64(math.MaxUint64), true},
+		{int64(5000), nil, 5.5, nil, uint64(1), true},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, cols)
+	w.RowGroupSize = 3
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Write([]interface{}{nil, "a", nil, nil, nil, nil}); err == nil {
+		t.Fatal("expected error writing null to required column")
+	}
+	if err := w.Write([]interface{}{int64(0), 1.0, nil, nil, nil, nil}); err == nil {
+		t.Fatal("expected error writing float to string column")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readFile(t, buf.Bytes(), cols)
+
+	// Timestamps are stored in microseconds.
+	want := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		want[i] = append([]interface{}{floorDiv(row[0].(int64), 1000)}, row[1:]...)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got rows\n%v\nwant\n%v", got, want)
+	}
+}
+
+// readFile reads the rows of a file written by Writer.
+func readFile(t *testing.T, data []byte, cols []Column) [][]interface{} {
+	t.Helper()
+
+	if !bytes.HasPrefix(data, magic) || !bytes.HasSuffix(data, magic) {
+		t.Fatal("missing magic")
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := readStruct(t, bytes.NewReader(data[len(data)-8-size:len(data)-8]))
+
+	schema := meta[2].([]interface{})
+	if len(schema) != len(cols)+1 {
+		t.Fatalf("got %d schema elements", len(schema))
+	}
+	for i, col := range cols {
+		el := schema[i+1].(map[int16]interface{})
+		if el[4] != col.Name || el[1] != int64(col.Type.physical()) {
+			t.Fatalf("unexpected schema element %v for %v", el, col)
+		}
+	}
+
+	var rows [][]interface{}
+	for _, rg := range meta[4].([]interface{}) {
+		rg := rg.(map[int16]interface{})
+		n := int(rg[3].(int64))
+		group := make([][]interface{}, n)
+		for i := range group {
+			group[i] = make([]interface{}, len(cols))
+		}
+
+		for i, cc := range rg[1].([]interface{}) {
+			cm := cc.(map[int16]interface{})[3].(map[int16]interface{})
+			r := bytes.NewReader(data[cm[9].(int64):])
+			header := readStruct(t, r)
+			if got := header[5].(map[int16]interface{})[1]; got != int64(n) {
+				t.Fatalf("page has %v values, want %d", got, n)
+			}
+			page := make([]byte, header[3].(int64))
+			if _, err := r.Read(page); err != nil {
+				t.Fatal(err)
+			}
+			payload, err := snappy.Decode(nil, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(len(payload)) != header[2].(int64) {
+				t.Fatalf("got %d bytes, want %v", len(payload), header[2])
+			}
+
+			defs := make([]byte, n)
+			for j := range defs {
+				defs[j] = 1
+			}
+			if cols[i].Optional {
+				size := binary.LittleEndian.Uint32(payload)
+				levels := bytes.NewReader(payload[4 : 4+size])
+				payload = payload[4+size:]
+				for j := 0; j < n; {
+					run, err := binary.ReadUvarint(levels)
+					if err != nil {
+						t.Fatal(err)
+					}
+					v, _ := levels.ReadByte()
+					for k := 0; k < int(run>>1); k++ {
+						defs[j] = v
+						j++
+					}
+				}
+			}
+
+			var v int
+			for j := 0; j < n; j++ {
+				if defs[j] == 0 {
+					continue
+				}
+				switch cols[i].Type {
+				case Boolean:
+					group[j][i] = payload[v/8]&(1<<uint(v%8)) != 0
+				case Int64, Timestamp:
+					group[j][i] = int64(binary.LittleEndian.Uint64(payload[8*v:]))
+				case Uint64:
+					group[j][i] = binary.LittleEndian.Uint64(payload[8*v:])
+				case Double:
+					group[j][i] = math.Float64frombits(binary.LittleEndian.Uint64(payload[8*v:]))
+				case String:
+					size := binary.LittleEndian.Uint32(payload)
+					group[j][i] = string(payload[4 : 4+size])
+					payload = payload[4+size:]
+				}
+				v++
+			}
+		}
+		rows = append(rows, group...)
+	}
+
+	if got := meta[3].(int64); got != int64(len(rows)) {
+		t.Fatalf("metadata has %d rows, read %d", got, len(rows))
+	}
+	return rows
+}
+
+func readStruct(t *testing.T, r *bytes.Reader) map[int16]interface{} {
+	t.Helper()
+
+	v, err := readThriftStruct(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
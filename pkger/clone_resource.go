@@ -184,9 +184,9 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		return nil
 	}
 
-	mapResource := func(orgID, uniqResID influxdb.ID, k Kind, object Object) {
+	mapResourceNamed := func(orgID, uniqResID influxdb.ID, k Kind, object Object, pkgName string) {
 		// overwrite the default metadata.name field with export generated one here
-		object.Metadata[fieldName] = ex.uniqName()
+		object.Metadata[fieldName] = pkgName
 
 		if len(ass) > 0 {
 			object.Spec[fieldAssociations] = ass
@@ -194,6 +194,9 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		key := newExportKey(orgID, uniqResID, k, object.Spec.stringShort(fieldName))
 		ex.mObjects[key] = object
 	}
+	mapResource := func(orgID, uniqResID influxdb.ID, k Kind, object Object) {
+		mapResourceNamed(orgID, uniqResID, k, object, ex.uniqName())
+	}
 
 	uniqByNameResID := ex.uniqByNameResID()
 
@@ -217,7 +220,9 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		if err != nil {
 			return err
 		}
-		mapResource(dash.OrganizationID, dash.ID, KindDashboard, DashboardToObject(r.Name, *dash))
+		// dashboards are named by their slug, so that applying the exported
+		// package again updates them instead of creating new ones.
+		mapResourceNamed(dash.OrganizationID, dash.ID, KindDashboard, DashboardToObject(r.Name, *dash), ex.uniqSlug(DashboardSlug(*dash)))
 	case r.Kind.is(KindLabel):
 		l, err := ex.labelSVC.FindLabelByID(ctx, r.ID)
 		if err != nil {
@@ -388,6 +393,48 @@ func (ex *resourceExporter) uniqName() string {
 	return uuid
 }
 
+// uniqSlug returns slug, or slug with a numeric suffix if another resource
+// of the export is already named slug.
+func (ex *resourceExporter) uniqSlug(slug string) string {
+	if slug == "" {
+		return ex.uniqName()
+	}
+
+	name := slug
+	for i := 2; ex.mPkgNames[name]; i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		base := slug
+		if len(base)+len(suffix) > dns1123LabelMaxLength {
+			base = strings.TrimRight(base[:dns1123LabelMaxLength-len(suffix)], "-")
+		}
+		name = base + suffix
+	}
+	ex.mPkgNames[name] = true
+	return name
+}
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify returns s as a DNS-1123 label fit to be the name of a resource of
+// a package, such as "CPU Usage (prod)" as "cpu-usage-prod". It returns an
+// empty string if s has no alphanumeric characters.
+func Slugify(s string) string {
+	slug := strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(slug) > dns1123LabelMaxLength {
+		slug = strings.TrimRight(slug[:dns1123LabelMaxLength], "-")
+	}
+	return slug
+}
+
+// DashboardSlug returns the name of the dashboard in the packages it is
+// exported to, which is its slug, or a slug of its name if it has none.
+func DashboardSlug(dash influxdb.Dashboard) string {
+	if dash.Slug != "" {
+		return Slugify(dash.Slug)
+	}
+	return Slugify(dash.Name)
+}
+
 func uniqResourcesToClone(resources []ResourceToClone) []ResourceToClone {
 	type key struct {
 		kind Kind
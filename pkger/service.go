@@ -811,11 +811,17 @@ func (s *Service) dryRunChecks(ctx context.Context, orgID influxdb.ID, checks ma
 }
 
 func (s *Service) dryRunDashboards(ctx context.Context, orgID influxdb.ID, dashs map[string]*stateDashboard) {
+	var bySlug map[string]*influxdb.Dashboard
 	for _, stateDash := range dashs {
 		stateDash.orgID = orgID
 		var existing *influxdb.Dashboard
 		if stateDash.ID() != 0 {
 			existing, _ = s.dashSVC.FindDashboardByID(ctx, stateDash.ID())
+		} else {
+			if bySlug == nil {
+				bySlug = s.findDashboardsBySlug(ctx, orgID)
+			}
+			existing = bySlug[stateDash.parserDash.PkgName()]
 		}
 		if IsNew(stateDash.stateStatus) && existing != nil {
 			stateDash.stateStatus = StateStatusExists
@@ -824,6 +830,29 @@ func (s *Service) dryRunDashboards(ctx context.Context, orgID influxdb.ID, dashs
 	}
 }
 
+// findDashboardsBySlug returns the dashboards of the organization by the
+// names they are exported with. A dashboard with a slug takes precedence
+// over one without whose name has the same slug.
+func (s *Service) findDashboardsBySlug(ctx context.Context, orgID influxdb.ID) map[string]*influxdb.Dashboard {
+	dashs, _, _ := s.dashSVC.FindDashboards(ctx, influxdb.DashboardFilter{
+		OrganizationID: &orgID,
+	}, influxdb.DefaultDashboardFindOptions)
+
+	bySlug := make(map[string]*influxdb.Dashboard, len(dashs))
+	for _, d := range dashs {
+		if d.Slug != "" {
+			bySlug[DashboardSlug(*d)] = d
+		}
+	}
+	for _, d := range dashs {
+		slug := DashboardSlug(*d)
+		if _, ok := bySlug[slug]; d.Slug == "" && slug != "" && !ok {
+			bySlug[slug] = d
+		}
+	}
+	return bySlug
+}
+
 func (s *Service) dryRunLabels(ctx context.Context, orgID influxdb.ID, labels map[string]*stateLabel) {
 	for _, pkgLabel := range labels {
 		pkgLabel.orgID = orgID
@@ -1581,12 +1610,13 @@ func (s *Service) applyDashboard(ctx context.Context, d *stateDashboard) (influx
 		}
 		return *d.existing, nil
 	case IsExisting(d.stateStatus) && d.existing != nil:
-		name := d.parserDash.Name()
+		name, slug := d.parserDash.Name(), d.parserDash.PkgName()
 		cells := convertChartsToCells(d.parserDash.Charts)
 		dash, err := s.dashSVC.UpdateDashboard(ctx, d.ID(), influxdb.DashboardUpdate{
 			Name:        &name,
 			Description: &d.parserDash.Description,
 			Cells:       &cells,
+			Slug:        &slug,
 		})
 		if err != nil {
 			return influxdb.Dashboard{}, ierrors.Wrap(err, "failed to update dashboard")
@@ -1599,6 +1629,7 @@ func (s *Service) applyDashboard(ctx context.Context, d *stateDashboard) (influx
 			Description:    d.parserDash.Description,
 			Name:           d.parserDash.Name(),
 			Cells:          cells,
+			Slug:           d.parserDash.PkgName(),
 		}
 		err := s.dashSVC.CreateDashboard(ctx, &influxDashboard)
 		if err != nil {
@@ -1623,6 +1654,7 @@ func (s *Service) rollbackDashboards(ctx context.Context, dashs []*stateDashboar
 				Name:        &d.existing.Name,
 				Description: &d.existing.Description,
 				Cells:       &d.existing.Cells,
+				Slug:        &d.existing.Slug,
 			})
 			return ierrors.Wrap(err, "failed to update dashboard")
 		default:
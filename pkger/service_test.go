@@ -827,6 +827,40 @@ func TestService(t *testing.T) {
 				})
 			})
 
+			t.Run("updates the dashboards with the slug of their name", func(t *testing.T) {
+				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
+					fakeDashSVC := mock.NewDashboardService()
+					fakeDashSVC.FindDashboardsF = func(context.Context, influxdb.DashboardFilter, influxdb.FindOptions) ([]*influxdb.Dashboard, int, error) {
+						return []*influxdb.Dashboard{
+							{ID: 3, Name: "old name", Slug: "dash-1"},
+							// a dashboard without a slug matches by its name.
+							{ID: 4, Name: "Dash 2"},
+						}, 2, nil
+					}
+					fakeDashSVC.UpdateDashboardF = func(_ context.Context, id influxdb.ID, upd influxdb.DashboardUpdate) (*influxdb.Dashboard, error) {
+						d := &influxdb.Dashboard{ID: id}
+						if err := upd.Apply(d); err != nil {
+							return nil, err
+						}
+						return d, nil
+					}
+					fakeDashSVC.UpdateDashboardCellViewF = func(ctx context.Context, dID influxdb.ID, cID influxdb.ID, upd influxdb.ViewUpdate) (*influxdb.View, error) {
+						return &influxdb.View{}, nil
+					}
+
+					svc := newTestService(WithDashboardSVC(fakeDashSVC))
+
+					sum, _, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					assert.Zero(t, fakeDashSVC.CreateDashboardCalls.Count())
+					assert.Equal(t, 2, fakeDashSVC.UpdateDashboardCalls.Count())
+					require.Len(t, sum.Dashboards, 2)
+					assert.Equal(t, SafeID(3), sum.Dashboards[0].ID)
+					assert.Equal(t, SafeID(4), sum.Dashboards[1].ID)
+				})
+			})
+
 			t.Run("rolls back created dashboard on an error", func(t *testing.T) {
 				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
 					fakeDashSVC := mock.NewDashboardService()
@@ -2089,6 +2123,36 @@ func TestService(t *testing.T) {
 						assert.Equal(t, "desc", actual.Description)
 					}
 				})
+
+				t.Run("names dashboards by their slug", func(t *testing.T) {
+					dashSVC := mock.NewDashboardService()
+					dashSVC.FindDashboardByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+						d := &influxdb.Dashboard{
+							ID:   id,
+							Name: "CPU Usage (prod)",
+						}
+						if id == 2 {
+							d.Slug = "cpu"
+						}
+						return d, nil
+					}
+
+					svc := newTestService(WithDashboardSVC(dashSVC), WithLabelSVC(mock.NewLabelService()))
+
+					pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(
+						ResourceToClone{Kind: KindDashboard, ID: 1},
+						ResourceToClone{Kind: KindDashboard, ID: 2},
+					))
+					require.NoError(t, err)
+
+					newPkg := encodeAndDecode(t, pkg)
+
+					var pkgNames []string
+					for _, d := range newPkg.Summary().Dashboards {
+						pkgNames = append(pkgNames, d.PkgName)
+					}
+					assert.ElementsMatch(t, []string{"cpu-usage-prod", "cpu"}, pkgNames)
+				})
 			})
 
 			t.Run("label", func(t *testing.T) {
@@ -0,0 +1,48 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// BlockQuarantineService lists the blocks of storage files which failed
+// checksum verification when read, for example after bit rot. Reads of such
+// blocks fail rather than return corrupt data, and the series and time
+// ranges they held are reported here so the lost data can be restored.
+type BlockQuarantineService interface {
+	// FindQuarantinedBlocks returns the blocks quarantined since the storage
+	// engine opened which match the filter.
+	FindQuarantinedBlocks(ctx context.Context, filter QuarantinedBlockFilter) ([]*QuarantinedBlock, error)
+}
+
+// QuarantinedBlockFilter filters quarantined blocks by organization and
+// bucket.
+type QuarantinedBlockFilter struct {
+	OrgID    *ID
+	BucketID *ID
+}
+
+// QuarantinedBlock is a block of a storage file which failed checksum
+// verification, and the points of one series it held.
+type QuarantinedBlock struct {
+	OrgID       ID                `json:"orgID"`
+	BucketID    ID                `json:"bucketID"`
+	Measurement string            `json:"measurement"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Field       string            `json:"field"`
+
+	// MinTime and MaxTime are the times of the first and last points of
+	// the block.
+	MinTime time.Time `json:"minTime"`
+	MaxTime time.Time `json:"maxTime"`
+
+	// Path is the storage file of the block, and Offset the position of
+	// the block in it.
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+
+	QuarantinedAt time.Time `json:"quarantinedAt"`
+	// Dropped is set once a compaction has rewritten the file without the
+	// block, so its points are no longer in storage.
+	Dropped bool `json:"dropped"`
+}
@@ -139,6 +139,9 @@ func (b ProxyQueryServiceAsyncBridge) Query(ctx context.Context, w io.Writer, re
 	}
 
 	results := flux.NewResultIteratorFromQuery(q)
+	if l, ok := RowLimitFromContext(ctx); ok {
+		results = LimitRows(results, l)
+	}
 	defer results.Release()
 
 	encoder := req.Dialect.Encoder()
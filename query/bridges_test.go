@@ -65,3 +65,34 @@ func TestProxyQueryServiceAsyncBridge_StatsOnClientDisconnect(t *testing.T) {
 		t.Fatalf("stats were missing or had wrong metadata: exp metadata[foo]=[bar], got %v", md)
 	}
 }
+
+func TestProxyQueryServiceAsyncBridge_RowLimit(t *testing.T) {
+	q := mock.NewQuery()
+	q.SetResults(executetest.NewResult([]*executetest.Table{{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TInt}},
+		Data:    [][]interface{}{{int64(1)}, {int64(2)}, {int64(3)}},
+	}}))
+	bridge := query.ProxyQueryServiceAsyncBridge{
+		AsyncQueryService: &mock.AsyncQueryService{
+			QueryF: func(ctx context.Context, req *query.Request) (flux.Query, error) {
+				return q, nil
+			},
+		},
+	}
+
+	errLimit := errors.New("too many rows")
+	var exceeded int
+	ctx := query.ContextWithRowLimit(context.Background(), query.RowLimit{
+		Max:        2,
+		Err:        errLimit,
+		OnExceeded: func() { exceeded++ },
+	})
+	var sb strings.Builder
+	_, err := bridge.Query(ctx, &sb, &query.ProxyRequest{Dialect: csv.DefaultDialect()})
+	if err == nil || !strings.Contains(err.Error(), errLimit.Error()) {
+		t.Fatalf("expected the query to fail with the row limit error, got %v", err)
+	}
+	if exceeded != 1 {
+		t.Errorf("expected the row limit to be exceeded once, got %d", exceeded)
+	}
+}
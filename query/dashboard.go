@@ -0,0 +1,38 @@
+package query
+
+import (
+	"time"
+
+	"github.com/influxdata/flux/ast"
+)
+
+// DashboardExtern returns the v option of the time range that the UI sets
+// for the queries of dashboards, with a window period of about a pixel of a
+// chart of the width.
+func DashboardExtern(start, stop time.Time, width int) *ast.File {
+	windowPeriod := stop.Sub(start) / time.Duration(width)
+	if windowPeriod < time.Millisecond {
+		windowPeriod = time.Millisecond
+	}
+	property := func(key string, value ast.Expression) *ast.Property {
+		return &ast.Property{Key: &ast.Identifier{Name: key}, Value: value}
+	}
+	return &ast.File{
+		Body: []ast.Statement{
+			&ast.OptionStatement{
+				Assignment: &ast.VariableAssignment{
+					ID: &ast.Identifier{Name: "v"},
+					Init: &ast.ObjectExpression{
+						Properties: []*ast.Property{
+							property("timeRangeStart", &ast.DateTimeLiteral{Value: start}),
+							property("timeRangeStop", &ast.DateTimeLiteral{Value: stop}),
+							property("windowPeriod", &ast.DurationLiteral{
+								Values: []ast.Duration{{Magnitude: int64(windowPeriod / time.Millisecond), Unit: "ms"}},
+							}),
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+func TestDashboardExtern(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	extern := query.DashboardExtern(start, start.Add(time.Hour), 360)
+
+	obj := extern.Body[0].(*ast.OptionStatement).Assignment.(*ast.VariableAssignment).Init.(*ast.ObjectExpression)
+	wp := obj.Properties[2].Value.(*ast.DurationLiteral).Values[0]
+	if wp.Magnitude != 10000 || wp.Unit != "ms" {
+		t.Errorf("expected a window period of 10s, got %d%s", wp.Magnitude, wp.Unit)
+	}
+}
@@ -31,6 +31,7 @@ type Compiler struct {
 	logicalPlannerOptions []plan.LogicalOption
 
 	dbrpMappingSvc platform.DBRPMappingService
+	bucketFinder   BucketFinder
 }
 
 var _ flux.Compiler = &Compiler{}
@@ -57,6 +58,7 @@ func (c *Compiler) Compile(ctx context.Context) (flux.Program, error) {
 			DefaultDatabase:        c.DB,
 			DefaultRetentionPolicy: c.RP,
 			Now:                    now,
+			Buckets:                c.bucketFinder,
 		},
 	)
 	astPkg, err := transpiler.Transpile(ctx, c.Query)
@@ -74,3 +76,9 @@ func (c *Compiler) CompilerType() flux.CompilerType {
 func (c *Compiler) WithLogicalPlannerOptions(opts ...plan.LogicalOption) {
 	c.logicalPlannerOptions = opts
 }
+
+// WithBucketFinder sets the service used to find the aggregation hints of
+// the buckets queried.
+func (c *Compiler) WithBucketFinder(f BucketFinder) {
+	c.bucketFinder = f
+}
@@ -1,7 +1,10 @@
 package influxql
 
 import (
+	"context"
 	"time"
+
+	"github.com/influxdata/influxdb/v2"
 )
 
 // Config modifies the behavior of the Transpiler.
@@ -16,4 +19,13 @@ type Config struct {
 	// FallbackToDBRP if true will use the naming convention of `db/rp`
 	// for a bucket name when an mapping is not found
 	FallbackToDBRP bool
+	// Buckets finds the bucket of the dbrp mapping to aggregate the raw
+	// fields grouped by time with its aggregation hint. If it is nil the
+	// hints are not used.
+	Buckets BucketFinder
+}
+
+// BucketFinder finds the buckets of dbrp mappings.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
 }
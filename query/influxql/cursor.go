@@ -1,6 +1,7 @@
 package influxql
 
 import (
+	"context"
 	"errors"
 
 	"github.com/influxdata/flux/ast"
@@ -32,7 +33,7 @@ type varRefCursor struct {
 
 // createVarRefCursor creates a new cursor from a variable reference using the sources
 // in the transpilerState.
-func createVarRefCursor(t *transpilerState, ref *influxql.VarRef) (cursor, error) {
+func createVarRefCursor(ctx context.Context, t *transpilerState, ref *influxql.VarRef) (cursor, error) {
 	if len(t.stmt.Sources) != 1 {
 		// TODO(jsternberg): Support multiple sources.
 		return nil, errors.New("unimplemented: only one source is allowed")
@@ -45,7 +46,7 @@ func createVarRefCursor(t *transpilerState, ref *influxql.VarRef) (cursor, error
 	}
 
 	// Create the from spec and add it to the list of operations.
-	from, err := t.from(mm)
+	from, err := t.from(ctx, mm)
 	if err != nil {
 		return nil, err
 	}
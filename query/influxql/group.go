@@ -1,6 +1,7 @@
 package influxql
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -107,7 +108,7 @@ func identifyGroups(stmt *influxql.SelectStatement) ([]*groupInfo, error) {
 	return groups, nil
 }
 
-func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
+func (gr *groupInfo) createCursor(ctx context.Context, t *transpilerState) (cursor, error) {
 	// Create all of the cursors for every variable reference.
 	// TODO(jsternberg): Determine which of these cursors are from fields and which are tags.
 	var cursors []cursor
@@ -117,7 +118,7 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 			// TODO(jsternberg): This should be validated and figured out somewhere else.
 			return nil, fmt.Errorf("first argument to %q must be a variable", gr.call.Name)
 		}
-		cur, err := createVarRefCursor(t, ref)
+		cur, err := createVarRefCursor(ctx, t, ref)
 		if err != nil {
 			return nil, err
 		}
@@ -125,7 +126,7 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 	}
 
 	for _, ref := range gr.refs {
-		cur, err := createVarRefCursor(t, ref)
+		cur, err := createVarRefCursor(ctx, t, ref)
 		if err != nil {
 			return nil, err
 		}
@@ -173,7 +174,7 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 					// Add this variable name to the listing of tags.
 					tags[*ref] = struct{}{}
 				default:
-					cur, err := createVarRefCursor(t, ref)
+					cur, err := createVarRefCursor(ctx, t, ref)
 					if err != nil {
 						condErr = err
 						return
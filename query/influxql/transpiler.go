@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxql"
 )
 
@@ -140,7 +141,7 @@ func (t *transpilerState) transpileShowTagValues(ctx context.Context, stmt *infl
 		stmt.Database = t.config.DefaultDatabase
 	}
 
-	expr, err := t.from(&influxql.Measurement{Database: stmt.Database})
+	expr, err := t.from(ctx, &influxql.Measurement{Database: stmt.Database})
 	if err != nil {
 		return nil, err
 	}
@@ -618,6 +619,10 @@ func (t *transpilerState) transpileSelect(ctx context.Context, stmt *influxql.Se
 	t.stmt = stmt.Clone()
 	t.stmt.OmitTime = true
 
+	if err := t.applyAggregationHint(ctx); err != nil {
+		return nil, err
+	}
+
 	groups, err := identifyGroups(t.stmt)
 	if err != nil {
 		return nil, err
@@ -630,7 +635,7 @@ func (t *transpilerState) transpileSelect(ctx context.Context, stmt *influxql.Se
 
 	cursors := make([]cursor, 0, len(groups))
 	for _, gr := range groups {
-		cur, err := gr.createCursor(t)
+		cur, err := gr.createCursor(ctx, t)
 		if err != nil {
 			return nil, err
 		}
@@ -654,7 +659,7 @@ func (t *transpilerState) mapType(ref *influxql.VarRef) influxql.DataType {
 	return influxql.Tag
 }
 
-func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error) {
+func (t *transpilerState) from(ctx context.Context, m *influxql.Measurement) (ast.Expression, error) {
 	var args []ast.Expression
 	// Use the bucket inteasd of dbrp mapping if it exists.
 	if t.config.Bucket != "" {
@@ -673,41 +678,16 @@ func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error)
 			},
 		}
 	} else {
-		if t.dbrpMappingSvc == nil {
-			return nil, &influxdb.Error{
-				Code: influxdb.EInternal,
-				Msg:  "unable to transpile: db and rp mappings need to be created by some way",
-			}
-		}
-		db, rp := m.Database, m.RetentionPolicy
-		if db == "" {
-			if t.config.DefaultDatabase == "" {
-				return nil, &influxdb.Error{
-					Code: influxdb.EInvalid,
-					Msg:  "unable to transpile: database is required",
-				}
-			}
-			db = t.config.DefaultDatabase
-		}
-		if rp == "" {
-			if t.config.DefaultRetentionPolicy != "" {
-				rp = t.config.DefaultRetentionPolicy
-			}
-		}
-
-		var filter influxdb.DBRPMappingFilter
-		filter.Cluster = &t.config.Cluster
-		if db != "" {
-			filter.Database = &db
-		}
-		if rp != "" {
-			filter.RetentionPolicy = &rp
+		db, rp, filter, err := t.dbrpFilter(m)
+		if err != nil {
+			return nil, err
 		}
-		defaultRP := rp == ""
-		filter.Default = &defaultRP
-		mapping, err := t.dbrpMappingSvc.Find(context.TODO(), filter)
+		auth := dbrpAuthorization(ctx)
+		mapping, err := t.dbrpMappingSvc.Find(ctx, filter)
 		if err != nil {
-			if !t.config.FallbackToDBRP {
+			// An authorization scoped to a dbrp may only read through its
+			// mapping, so it never falls back to the bucket named after it.
+			if !t.config.FallbackToDBRP || (auth != nil && auth.DBRP != nil) {
 				return nil, err
 			}
 			// use `db/rp` naming convention
@@ -726,6 +706,12 @@ func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error)
 				},
 			}
 		} else {
+			if auth != nil && !auth.AllowedDBRP(influxdb.ReadAction, mapping) {
+				return nil, &influxdb.Error{
+					Code: influxdb.EForbidden,
+					Msg:  fmt.Sprintf("insufficient permissions to read database %q and retention policy %q", mapping.Database, mapping.RetentionPolicy),
+				}
+			}
 			// use mapping bucket id
 			args = []ast.Expression{
 				&ast.ObjectExpression{
@@ -752,6 +738,111 @@ func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error)
 	}, nil
 }
 
+// dbrpAuthorization returns the authorization of ctx that the buckets of
+// dbrp mappings are read with, or nil if ctx has none. Sessions and other
+// authorizers are checked when the bucket is read.
+func dbrpAuthorization(ctx context.Context) *influxdb.Authorization {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil
+	}
+	auth, _ := a.(*influxdb.Authorization)
+	return auth
+}
+
+// dbrpFilter returns the database and retention policy of the measurement,
+// with the defaults of the config, and the filter of their mapping.
+func (t *transpilerState) dbrpFilter(m *influxql.Measurement) (db, rp string, filter influxdb.DBRPMappingFilter, err error) {
+	if t.dbrpMappingSvc == nil {
+		return "", "", filter, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "unable to transpile: db and rp mappings need to be created by some way",
+		}
+	}
+	db, rp = m.Database, m.RetentionPolicy
+	if db == "" {
+		if t.config.DefaultDatabase == "" {
+			return "", "", filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "unable to transpile: database is required",
+			}
+		}
+		db = t.config.DefaultDatabase
+	}
+	if rp == "" {
+		if t.config.DefaultRetentionPolicy != "" {
+			rp = t.config.DefaultRetentionPolicy
+		}
+	}
+
+	filter.Cluster = &t.config.Cluster
+	if db != "" {
+		filter.Database = &db
+	}
+	if rp != "" {
+		filter.RetentionPolicy = &rp
+	}
+	defaultRP := rp == ""
+	filter.Default = &defaultRP
+	return db, rp, filter, nil
+}
+
+// applyAggregationHint aggregates the raw fields of a statement which groups
+// them by time with the aggregation hint of the bucket of its measurement.
+// The statement is left as is if it aggregates any field, if it does not
+// select from a single measurement, or if the bucket has no hint for it.
+func (t *transpilerState) applyAggregationHint(ctx context.Context) error {
+	if t.config.Buckets == nil || t.config.Bucket != "" {
+		return nil
+	}
+	if interval, err := t.stmt.GroupByInterval(); err != nil || interval <= 0 {
+		return nil
+	}
+	for _, f := range t.stmt.Fields {
+		if _, ok := f.Expr.(*influxql.VarRef); !ok {
+			return nil
+		}
+	}
+	if len(t.stmt.Sources) != 1 {
+		return nil
+	}
+	m, ok := t.stmt.Sources[0].(*influxql.Measurement)
+	if !ok || m.Name == "" {
+		return nil
+	}
+
+	_, _, filter, err := t.dbrpFilter(m)
+	if err != nil {
+		return err
+	}
+	mapping, err := t.dbrpMappingSvc.Find(ctx, filter)
+	if err != nil {
+		// The mapping is looked up again and reported when the measurement
+		// is read.
+		return nil
+	}
+	b, err := t.config.Buckets.FindBucketByID(ctx, mapping.BucketID)
+	if err != nil {
+		return err
+	}
+	hint := influxdb.FindAggregationHint(b.AggregationHints, m.Name)
+	if hint == nil {
+		return nil
+	}
+
+	for _, f := range t.stmt.Fields {
+		ref := f.Expr.(*influxql.VarRef)
+		if f.Alias == "" {
+			f.Alias = ref.Val
+		}
+		f.Expr = &influxql.Call{
+			Name: hint.Function,
+			Args: []influxql.Expr{ref},
+		}
+	}
+	return nil
+}
+
 func (t *transpilerState) assignment(expr ast.Expression) *ast.Identifier {
 	for i := 0; ; i++ {
 		key := fmt.Sprintf("t%d", i)
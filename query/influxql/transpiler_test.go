@@ -4,8 +4,12 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/andreyvit/diff"
+	"github.com/influxdata/flux/ast"
 	platform "github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/query/influxql"
 	"github.com/influxdata/influxdb/v2/query/influxql/spectests"
@@ -407,3 +411,118 @@ func TestTranspiler_Compile(t *testing.T) {
 		})
 	}
 }
+
+func TestTranspiler_AggregationHint(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{
+			ID: id,
+			AggregationHints: []platform.AggregationHint{
+				{Function: "max"},
+				{Measurement: "requests", Function: "sum"},
+			},
+		}, nil
+	}
+
+	transpile := func(t *testing.T, s string, cfg influxql.Config) string {
+		t.Helper()
+		cfg.DefaultDatabase = "db0"
+		cfg.Now = time.Unix(0, 0)
+		pkg, err := influxql.NewTranspilerWithConfig(dbrpMappingSvc, cfg).Transpile(context.Background(), s)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", s, err)
+		}
+		return ast.Format(pkg)
+	}
+
+	for _, tt := range []struct {
+		s    string
+		want string
+	}{
+		{
+			s:    `SELECT value FROM requests WHERE time >= now() - 1h GROUP BY time(1m)`,
+			want: `SELECT sum(value) AS value FROM requests WHERE time >= now() - 1h GROUP BY time(1m)`,
+		},
+		{
+			s:    `SELECT value, other AS o FROM cpu WHERE time >= now() - 1h GROUP BY time(1m)`,
+			want: `SELECT max(value) AS value, max(other) AS o FROM cpu WHERE time >= now() - 1h GROUP BY time(1m)`,
+		},
+		{
+			s:    `SELECT mean(value) FROM requests WHERE time >= now() - 1h GROUP BY time(1m)`,
+			want: `SELECT mean(value) FROM requests WHERE time >= now() - 1h GROUP BY time(1m)`,
+		},
+	} {
+		t.Run(tt.s, func(t *testing.T) {
+			got := transpile(t, tt.s, influxql.Config{Buckets: buckets})
+			if want := transpile(t, tt.want, influxql.Config{}); got != want {
+				t.Fatalf("unexpected ast\n%s", diff.LineDiff(want, got))
+			}
+		})
+	}
+
+	transpiler := influxql.NewTranspilerWithConfig(dbrpMappingSvc, influxql.Config{DefaultDatabase: "db0"})
+	if _, err := transpiler.Transpile(context.Background(), `SELECT value FROM requests WHERE time >= now() - 1h GROUP BY time(1m)`); err == nil {
+		t.Fatal("expected raw fields grouped by time to fail without the bucket finder")
+	}
+}
+
+func TestTranspiler_DBRPAuthorization(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("aaaaaaaaaaaaaaaa")
+	bucketID := platformtesting.MustIDBase16("bbbbbbbbbbbbbbbb")
+	read := platform.Permission{
+		Action: platform.ReadAction,
+		Resource: platform.Resource{
+			Type:  platform.BucketsResourceType,
+			ID:    &bucketID,
+			OrgID: &orgID,
+		},
+	}
+
+	for _, tt := range []struct {
+		name    string
+		auth    *platform.Authorization
+		wantErr bool
+	}{
+		{
+			name: "token of the bucket",
+			auth: &platform.Authorization{Status: platform.Active, OrgID: orgID, Permissions: []platform.Permission{read}},
+		},
+		{
+			name: "token scoped to the dbrp",
+			auth: &platform.Authorization{
+				Status:      platform.Active,
+				OrgID:       orgID,
+				Permissions: []platform.Permission{read},
+				DBRP:        &platform.AuthorizationDBRP{Database: "db0", RetentionPolicy: "autogen"},
+			},
+		},
+		{
+			name: "token scoped to another dbrp",
+			auth: &platform.Authorization{
+				Status:      platform.Active,
+				OrgID:       orgID,
+				Permissions: []platform.Permission{read},
+				DBRP:        &platform.AuthorizationDBRP{Database: "db1", RetentionPolicy: "autogen"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "token without read on the bucket",
+			auth:    &platform.Authorization{Status: platform.Active, OrgID: orgID},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := icontext.SetAuthorizer(context.Background(), tt.auth)
+			transpiler := influxql.NewTranspilerWithConfig(dbrpMappingSvc, influxql.Config{DefaultDatabase: "db0"})
+			_, err := transpiler.Transpile(ctx, `SELECT value FROM cpu`)
+			if tt.wantErr {
+				if platform.ErrorCode(err) != platform.EForbidden {
+					t.Fatalf("expected forbidden error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
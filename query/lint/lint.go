@@ -0,0 +1,226 @@
+// Package lint finds the patterns of Flux scripts which make them slow or
+// unsafe to run as tasks and dashboards, so that they can be reviewed before
+// they are deployed.
+package lint
+
+import (
+	"time"
+
+	"github.com/influxdata/flux/ast"
+)
+
+// Severities of findings.
+const (
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Rules of findings.
+const (
+	// RuleUnboundedRange reports reads from storage which are not bounded by
+	// range, or whose range starts at the epoch.
+	RuleUnboundedRange = "unbounded-range"
+	// RuleMissingAggregateWindow reports raw data which is yielded without
+	// being aggregated.
+	RuleMissingAggregateWindow = "missing-aggregate-window"
+	// RulePushdownBreaking reports a range or filter which cannot be pushed
+	// down to storage because of the functions before it.
+	RulePushdownBreaking = "pushdown-breaking"
+)
+
+// Finding is a pattern found in a script.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+}
+
+// aggregates are the functions which aggregate or select a window of data,
+// so that a table yielded after them is not raw.
+var aggregates = map[string]bool{
+	"aggregateWindow":    true,
+	"window":             true,
+	"count":              true,
+	"mean":               true,
+	"median":             true,
+	"sum":                true,
+	"min":                true,
+	"max":                true,
+	"first":              true,
+	"last":               true,
+	"spread":             true,
+	"stddev":             true,
+	"quantile":           true,
+	"mode":               true,
+	"distinct":           true,
+	"unique":             true,
+	"limit":              true,
+	"tail":               true,
+	"top":                true,
+	"bottom":             true,
+	"sample":             true,
+	"histogram":          true,
+	"integral":           true,
+	"reduce":             true,
+	"increase":           true,
+	"derivative":         true,
+	"difference":         true,
+	"movingAverage":      true,
+	"timedMovingAverage": true,
+	"keys":               true,
+	"keyValues":          true,
+}
+
+// Lint returns the findings of the script pkg, in the order of its
+// statements.
+func Lint(pkg *ast.Package) []Finding {
+	l := &linter{vars: make(map[string][]*ast.CallExpression)}
+	for _, f := range pkg.Files {
+		for _, s := range f.Body {
+			l.statement(s)
+		}
+	}
+	if l.findings == nil {
+		return []Finding{}
+	}
+	return l.findings
+}
+
+type linter struct {
+	// vars are the pipelines assigned to variables.
+	vars     map[string][]*ast.CallExpression
+	findings []Finding
+}
+
+func (l *linter) statement(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.VariableAssignment:
+		if p := l.pipeline(s.Init); p != nil {
+			l.vars[s.ID.Name] = p
+		}
+	case *ast.ExpressionStatement:
+		// An expression statement is yielded, whether or not it ends with
+		// a call to yield.
+		if p := l.pipeline(s.Expression); p != nil {
+			l.lint(p)
+		}
+	}
+}
+
+// pipeline returns the calls of the pipeline of expr which reads from
+// storage, starting with from, or nil if expr is not one.
+func (l *linter) pipeline(expr ast.Expression) []*ast.CallExpression {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		return l.vars[e.Name]
+	case *ast.CallExpression:
+		// Only the builtin from reads from storage, csv.from and sql.from
+		// do not.
+		if id, ok := e.Callee.(*ast.Identifier); ok && id.Name == "from" {
+			return []*ast.CallExpression{e}
+		}
+	case *ast.PipeExpression:
+		p := l.pipeline(e.Argument)
+		if p == nil {
+			return nil
+		}
+		// Copy the calls, so that the pipelines of variables used by
+		// several statements do not share them.
+		return append(p[:len(p):len(p)], e.Call)
+	}
+	return nil
+}
+
+// lint adds the findings of pipeline p, which is yielded.
+func (l *linter) lint(p []*ast.CallExpression) {
+	// Storage reads from, then range and filters, until any other function.
+	pushable := len(p) > 1 && name(p[1]) == "range"
+	var ranged, aggregated, yieldedRaw bool
+	for i, call := range p[1:] {
+		fn := name(call)
+		switch fn {
+		case "range":
+			if i != 0 {
+				l.add(call, RulePushdownBreaking, SeverityWarning, "range is not pushed down to storage unless it directly follows from")
+			}
+			if start := arg(call, "start"); start != nil && isEpoch(start) {
+				l.add(call, RuleUnboundedRange, SeverityWarning, "range starts at the epoch and reads all the data of the bucket")
+			}
+			ranged = true
+		case "filter":
+			if !pushable {
+				l.add(call, RulePushdownBreaking, SeverityWarning, "filter is not pushed down to storage unless it follows range and other filters")
+			}
+		case "yield":
+			if !aggregated && !yieldedRaw {
+				l.add(call, RuleMissingAggregateWindow, SeverityInfo, "raw data is yielded without aggregateWindow or another aggregate")
+				yieldedRaw = true
+			}
+		default:
+			if aggregates[fn] {
+				aggregated = true
+			}
+		}
+		if fn != "filter" && !(fn == "range" && i == 0) {
+			pushable = false
+		}
+	}
+	if !ranged {
+		l.add(p[0], RuleUnboundedRange, SeverityWarning, "from is not bounded by range")
+	}
+	if !aggregated && !yieldedRaw {
+		l.add(p[len(p)-1], RuleMissingAggregateWindow, SeverityInfo, "raw data is yielded without aggregateWindow or another aggregate")
+	}
+}
+
+func (l *linter) add(n ast.Node, rule, severity, msg string) {
+	loc := n.Location()
+	l.findings = append(l.findings, Finding{
+		Rule:     rule,
+		Severity: severity,
+		Line:     loc.Start.Line,
+		Column:   loc.Start.Column,
+		Message:  msg,
+	})
+}
+
+// name returns the name of the function called, without its package.
+func name(call *ast.CallExpression) string {
+	switch callee := call.Callee.(type) {
+	case *ast.Identifier:
+		return callee.Name
+	case *ast.MemberExpression:
+		return callee.Property.Key()
+	}
+	return ""
+}
+
+// arg returns the value of the named argument of call, or nil.
+func arg(call *ast.CallExpression, key string) ast.Expression {
+	if len(call.Arguments) == 0 {
+		return nil
+	}
+	obj, ok := call.Arguments[0].(*ast.ObjectExpression)
+	if !ok {
+		return nil
+	}
+	for _, p := range obj.Properties {
+		if p.Key.Key() == key {
+			return p.Value
+		}
+	}
+	return nil
+}
+
+// isEpoch reports whether the start of a range is the epoch.
+func isEpoch(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return e.Value == 0
+	case *ast.DateTimeLiteral:
+		return !e.Value.After(time.Unix(0, 0))
+	}
+	return false
+}
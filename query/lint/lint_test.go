@@ -0,0 +1,72 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2/query/lint"
+)
+
+func TestLint(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		script string
+		want   []lint.Finding
+	}{
+		{
+			name: "aggregated",
+			script: `from(bucket: "b")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._measurement == "cpu")
+	|> aggregateWindow(every: 1m, fn: mean)`,
+			want: []lint.Finding{},
+		},
+		{
+			name:   "unbounded",
+			script: `from(bucket: "b") |> last()`,
+			want: []lint.Finding{
+				{Rule: lint.RuleUnboundedRange, Severity: lint.SeverityWarning, Line: 1, Column: 1, Message: "from is not bounded by range"},
+			},
+		},
+		{
+			name:   "epoch",
+			script: `from(bucket: "b") |> range(start: 0) |> count()`,
+			want: []lint.Finding{
+				{Rule: lint.RuleUnboundedRange, Severity: lint.SeverityWarning, Line: 1, Column: 22, Message: "range starts at the epoch and reads all the data of the bucket"},
+			},
+		},
+		{
+			name: "raw yield",
+			script: `data = from(bucket: "b") |> range(start: -1h)
+data |> yield(name: "raw")
+data |> aggregateWindow(every: 1m, fn: max) |> yield(name: "max")`,
+			want: []lint.Finding{
+				{Rule: lint.RuleMissingAggregateWindow, Severity: lint.SeverityInfo, Line: 2, Column: 9, Message: "raw data is yielded without aggregateWindow or another aggregate"},
+			},
+		},
+		{
+			name: "pushdown",
+			script: `from(bucket: "b")
+	|> range(start: -1h)
+	|> map(fn: (r) => ({r with _value: r._value * 2.0}))
+	|> filter(fn: (r) => r._value > 1.0)
+	|> mean()`,
+			want: []lint.Finding{
+				{Rule: lint.RulePushdownBreaking, Severity: lint.SeverityWarning, Line: 4, Column: 5, Message: "filter is not pushed down to storage unless it follows range and other filters"},
+			},
+		},
+		{
+			name:   "csv",
+			script: `import "csv" csv.from(csv: "") |> yield()`,
+			want:   []lint.Finding{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lint.Lint(parser.ParseSource(tt.script))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatalf("unexpected findings -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Package merge merges the tables read from several sources into a single
+// stream of tables.
+package merge
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+)
+
+// Merger merges the tables read from every source which have the same group
+// key, and drops the rows read from more than one source.
+//
+// Tables are buffered in memory until every source has been read, since rows
+// of the same table may come from any source.
+type Merger struct {
+	sortBy string
+	tables map[string]*mergedTable
+}
+
+// New returns a Merger which sorts the rows of each table by the column
+// sortBy.
+func New(sortBy string) *Merger {
+	return &Merger{
+		sortBy: sortBy,
+		tables: make(map[string]*mergedTable),
+	}
+}
+
+type mergedTable struct {
+	key  flux.GroupKey
+	cols []flux.ColMeta
+	rows [][]values.Value
+}
+
+// Add adds the rows of tbl to the table with its group key.
+func (m *Merger) Add(tbl flux.Table) error {
+	key := tbl.Key()
+	id := groupKeyID(key)
+	mt, ok := m.tables[id]
+	if !ok {
+		mt = &mergedTable{key: key}
+		m.tables[id] = mt
+	}
+
+	return tbl.Do(func(cr flux.ColReader) error {
+		idx := make([]int, len(cr.Cols()))
+		for j, c := range cr.Cols() {
+			i, err := mt.colIndex(c)
+			if err != nil {
+				return err
+			}
+			idx[j] = i
+		}
+
+		for i := 0; i < cr.Len(); i++ {
+			row := make([]values.Value, len(mt.cols))
+			for j, c := range cr.Cols() {
+				row[idx[j]] = valueForRow(cr, c, i, j)
+			}
+			mt.rows = append(mt.rows, row)
+		}
+		return nil
+	})
+}
+
+// Do calls f with every merged table in group key order.
+func (m *Merger) Do(alloc *memory.Allocator, f func(flux.Table) error) error {
+	tables := make([]*mergedTable, 0, len(m.tables))
+	for _, mt := range m.tables {
+		if len(mt.rows) > 0 {
+			tables = append(tables, mt)
+		}
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].key.Less(tables[j].key)
+	})
+
+	for _, mt := range tables {
+		mt.dedupe(m.sortBy)
+		tbl, err := mt.table(alloc)
+		if err != nil {
+			return err
+		}
+		if err := f(tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupKeyID identifies the group key regardless of the order of its columns,
+// which may differ between sources.
+func groupKeyID(key flux.GroupKey) string {
+	cols := append([]flux.ColMeta(nil), key.Cols()...)
+	vs := append([]values.Value(nil), key.Values()...)
+	sort.Sort(byLabel{cols: cols, vs: vs})
+	return execute.NewGroupKey(cols, vs).String()
+}
+
+type byLabel struct {
+	cols []flux.ColMeta
+	vs   []values.Value
+}
+
+func (b byLabel) Len() int           { return len(b.cols) }
+func (b byLabel) Less(i, j int) bool { return b.cols[i].Label < b.cols[j].Label }
+func (b byLabel) Swap(i, j int) {
+	b.cols[i], b.cols[j] = b.cols[j], b.cols[i]
+	b.vs[i], b.vs[j] = b.vs[j], b.vs[i]
+}
+
+// colIndex returns the index of the column c, adding it when no table read
+// so far had it.
+func (mt *mergedTable) colIndex(c flux.ColMeta) (int, error) {
+	for i, col := range mt.cols {
+		if col.Label != c.Label {
+			continue
+		}
+		if col.Type != c.Type {
+			return 0, fmt.Errorf("schema collision: column %q is both %s and %s", c.Label, col.Type, c.Type)
+		}
+		return i, nil
+	}
+	mt.cols = append(mt.cols, c)
+	return len(mt.cols) - 1, nil
+}
+
+// dedupe sorts the rows by the column sortBy and drops the rows which are
+// equal to another.
+func (mt *mergedTable) dedupe(sortBy string) {
+	j := execute.ColIdx(sortBy, mt.cols)
+	if j < 0 {
+		return
+	}
+	typ := mt.cols[j].Type
+	sort.SliceStable(mt.rows, func(a, b int) bool {
+		return less(typ, value(mt.rows[a], j), value(mt.rows[b], j))
+	})
+
+	rows := mt.rows[:0]
+	for _, row := range mt.rows {
+		// Rows which are equal have the same value in the sorted column, so
+		// they are among the last rows kept.
+		dup := false
+		for k := len(rows) - 1; k >= 0 && equal(value(rows[k], j), value(row, j)); k-- {
+			if rowsEqual(rows[k], row, len(mt.cols)) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			rows = append(rows, row)
+		}
+	}
+	mt.rows = rows
+}
+
+func (mt *mergedTable) table(alloc *memory.Allocator) (flux.Table, error) {
+	b := execute.NewColListTableBuilder(mt.key, alloc)
+	for _, c := range mt.cols {
+		if _, err := b.AddCol(c); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range mt.rows {
+		for j := range mt.cols {
+			var err error
+			if v := value(row, j); v == nil {
+				err = b.AppendNil(j)
+			} else {
+				err = b.AppendValue(j, v)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.Table()
+}
+
+// valueForRow returns the value of row i of column j, or nil if it is null.
+// Strings are copied, since they may refer to the memory of the table.
+func valueForRow(cr flux.ColReader, c flux.ColMeta, i, j int) values.Value {
+	if c.Type == flux.TString {
+		vs := cr.Strings(j)
+		if vs.IsNull(i) {
+			return nil
+		}
+		return values.NewString(string(vs.Value(i)))
+	}
+
+	v := execute.ValueForRow(cr, i, j)
+	if v.IsNull() {
+		return nil
+	}
+	return v
+}
+
+// value returns the value of column j of row, or nil if it is null. Rows
+// read before column j was added are shorter than the columns of the table.
+func value(row []values.Value, j int) values.Value {
+	if j >= len(row) {
+		return nil
+	}
+	return row[j]
+}
+
+func less(typ flux.ColType, a, b values.Value) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	switch typ {
+	case flux.TTime:
+		return a.Time() < b.Time()
+	case flux.TString:
+		return a.Str() < b.Str()
+	case flux.TInt:
+		return a.Int() < b.Int()
+	case flux.TUInt:
+		return a.UInt() < b.UInt()
+	case flux.TFloat:
+		return a.Float() < b.Float()
+	}
+	return false
+}
+
+func equal(a, b values.Value) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(b)
+}
+
+func rowsEqual(a, b []values.Value, n int) bool {
+	for j := 0; j < n; j++ {
+		if !equal(value(a, j), value(b, j)) {
+			return false
+		}
+	}
+	return true
+}
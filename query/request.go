@@ -14,6 +14,10 @@ const (
 	PreferHeaderKey                = "Prefer"
 	PreferNoContentHeaderValue     = "return-no-content"
 	PreferNoContentWErrHeaderValue = "return-no-content-with-error"
+
+	// FederatedHeaderKey marks a query sent by a node which federates reads
+	// across nodes. Such queries only read the storage of the receiving node.
+	FederatedHeaderKey = "X-Influx-Federated"
 )
 
 // Request represents the query to run.
@@ -64,6 +68,15 @@ func (r *Request) WithReturnNoContent(withError bool) {
 	})
 }
 
+// WithFederated marks this Request as federated, so that the node receiving
+// it only reads its own storage.
+func (r *Request) WithFederated() {
+	r.WithOption(func(header http.Header) error {
+		header.Set(FederatedHeaderKey, "true")
+		return nil
+	})
+}
+
 // ApplyOptions applies every option added to this Request to the given header.
 func (r *Request) ApplyOptions(header http.Header) error {
 	for _, visitor := range r.options {
@@ -139,6 +152,20 @@ func RequestFromContext(ctx context.Context) *Request {
 	return v.(*Request)
 }
 
+type federatedContextKey struct{}
+
+// ContextWithFederated returns a new context marking the query as federated.
+func ContextWithFederated(ctx context.Context) context.Context {
+	return context.WithValue(ctx, federatedContextKey{}, true)
+}
+
+// IsFederated reports whether the query of the context was sent by a node
+// federating reads across nodes.
+func IsFederated(ctx context.Context) bool {
+	federated, _ := ctx.Value(federatedContextKey{}).(bool)
+	return federated
+}
+
 // ProxyRequest specifies a query request and the dialect for the results.
 type ProxyRequest struct {
 	// Request is the basic query request
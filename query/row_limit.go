@@ -0,0 +1,108 @@
+package query
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/influxdata/flux"
+)
+
+// RowLimit limits the number of rows of the results of a query.
+type RowLimit struct {
+	// Max is the number of rows above which reading the results fails.
+	Max int64
+	// Err is the error reading the results fails with.
+	Err error
+	// OnExceeded, if set, is called once when the results exceed Max.
+	OnExceeded func()
+}
+
+type rowLimitContextKey struct{}
+
+// ContextWithRowLimit returns a new context limiting the rows of the results
+// of the query run with it.
+func ContextWithRowLimit(ctx context.Context, l RowLimit) context.Context {
+	return context.WithValue(ctx, rowLimitContextKey{}, l)
+}
+
+// RowLimitFromContext returns the limit of the rows of the results of the
+// query run with ctx, if any.
+func RowLimitFromContext(ctx context.Context) (RowLimit, bool) {
+	l, ok := ctx.Value(rowLimitContextKey{}).(RowLimit)
+	return l, ok
+}
+
+// LimitRows returns an iterator over results failing with the error of l
+// once the tables of all of the results have more than l.Max rows.
+func LimitRows(results flux.ResultIterator, l RowLimit) flux.ResultIterator {
+	return &rowLimitResultIterator{
+		ResultIterator: results,
+		l:              &rowLimiter{RowLimit: l},
+	}
+}
+
+type rowLimiter struct {
+	RowLimit
+	n        int64
+	exceeded int32
+}
+
+// add counts n rows, returning an error if the limit is exceeded.
+func (l *rowLimiter) add(n int) error {
+	if atomic.AddInt64(&l.n, int64(n)) <= l.Max {
+		return nil
+	}
+	if atomic.CompareAndSwapInt32(&l.exceeded, 0, 1) && l.OnExceeded != nil {
+		l.OnExceeded()
+	}
+	return l.Err
+}
+
+type rowLimitResultIterator struct {
+	flux.ResultIterator
+	l *rowLimiter
+}
+
+func (ri *rowLimitResultIterator) Next() flux.Result {
+	return &rowLimitResult{
+		Result: ri.ResultIterator.Next(),
+		l:      ri.l,
+	}
+}
+
+type rowLimitResult struct {
+	flux.Result
+	l *rowLimiter
+}
+
+func (r *rowLimitResult) Tables() flux.TableIterator {
+	return &rowLimitTableIterator{
+		TableIterator: r.Result.Tables(),
+		l:             r.l,
+	}
+}
+
+type rowLimitTableIterator struct {
+	flux.TableIterator
+	l *rowLimiter
+}
+
+func (ti *rowLimitTableIterator) Do(f func(t flux.Table) error) error {
+	return ti.TableIterator.Do(func(t flux.Table) error {
+		return f(&rowLimitTable{Table: t, l: ti.l})
+	})
+}
+
+type rowLimitTable struct {
+	flux.Table
+	l *rowLimiter
+}
+
+func (t *rowLimitTable) Do(f func(flux.ColReader) error) error {
+	return t.Table.Do(func(cr flux.ColReader) error {
+		if err := t.l.add(cr.Len()); err != nil {
+			return err
+		}
+		return f(cr)
+	})
+}
@@ -0,0 +1,280 @@
+// Package http replaces the http.post function of Flux with one which can
+// also post to the HTTP sinks of the organization of the query.
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	fluxurl "github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/semantic"
+	_ "github.com/influxdata/flux/stdlib/http" // registers the http.post being replaced
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+	"golang.org/x/time/rate"
+)
+
+const PostKind = "post"
+
+func init() {
+	postSignature := semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{
+			"url":     semantic.String,
+			"sink":    semantic.String,
+			"headers": semantic.Tvar(1),
+			"data":    semantic.Bytes,
+		},
+		Required: nil,
+		Return:   semantic.Int,
+	}
+
+	flux.ReplacePackageValue("http", PostKind, values.NewFunction(PostKind, semantic.NewFunctionPolyType(postSignature), post, true))
+}
+
+type key int
+
+const dependenciesKey key = iota
+
+// SecretLoader loads the credentials of HTTP sinks.
+type SecretLoader interface {
+	LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error)
+}
+
+// SinkDependencies are the services used to post to HTTP sinks. It holds
+// the rate limiters of the sinks, so it must be shared by all queries.
+type SinkDependencies struct {
+	HTTPSinkService influxdb.HTTPSinkService
+	SecretService   SecretLoader
+
+	mu       sync.Mutex
+	limiters map[influxdb.ID]*rate.Limiter
+}
+
+// NewSinkDependencies returns the dependencies used to post to the sinks of
+// s with the credentials of ss.
+func NewSinkDependencies(s influxdb.HTTPSinkService, ss SecretLoader) *SinkDependencies {
+	return &SinkDependencies{
+		HTTPSinkService: s,
+		SecretService:   ss,
+		limiters:        make(map[influxdb.ID]*rate.Limiter),
+	}
+}
+
+func (d *SinkDependencies) Inject(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dependenciesKey, d)
+}
+
+func GetSinkDependencies(ctx context.Context) *SinkDependencies {
+	d, _ := ctx.Value(dependenciesKey).(*SinkDependencies)
+	return d
+}
+
+// limiter returns the rate limiter of hs, or nil if it has no rate limit.
+func (d *SinkDependencies) limiter(hs *influxdb.HTTPSink) *rate.Limiter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if hs.RateLimit <= 0 {
+		delete(d.limiters, hs.ID)
+		return nil
+	}
+	l, ok := d.limiters[hs.ID]
+	if !ok {
+		l = rate.NewLimiter(rate.Limit(hs.RateLimit), 1)
+		d.limiters[hs.ID] = l
+	} else if l.Limit() != rate.Limit(hs.RateLimit) {
+		l.SetLimit(rate.Limit(hs.RateLimit))
+	}
+	return l
+}
+
+func post(ctx context.Context, args values.Object) (values.Value, error) {
+	uV, hasURL := args.Get("url")
+	sV, hasSink := args.Get("sink")
+	if hasURL == hasSink {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  "must specify one of url or sink",
+		}
+	}
+
+	var data []byte
+	if dataV, ok := args.Get("data"); ok {
+		data = dataV.Bytes()
+	}
+
+	header := make(http.Header)
+	if headersV, ok := args.Get("headers"); ok {
+		var rangeErr error
+		headersV.Object().Range(func(k string, v values.Value) {
+			if v.Type() == semantic.String {
+				header.Set(k, v.Str())
+			} else {
+				rangeErr = &flux.Error{
+					Code: codes.Invalid,
+					Msg:  fmt.Sprintf("header value %q must be a string", k),
+				}
+			}
+		})
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+	}
+
+	deps := flux.GetDependencies(ctx)
+	client, err := deps.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	validator, err := deps.URLValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	var statusCode int
+	if hasURL {
+		statusCode, err = postURL(ctx, client, validator, uV.Str(), header, data)
+	} else {
+		statusCode, err = postSink(ctx, client, validator, sV.Str(), header, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return values.NewInt(int64(statusCode)), nil
+}
+
+// postURL posts data to rawURL once, as the http.post function of Flux does.
+func postURL(ctx context.Context, client *http.Client, validator fluxurl.Validator, rawURL string, header http.Header, data []byte) (int, error) {
+	if err := validate(validator, rawURL); err != nil {
+		return 0, err
+	}
+	return do(ctx, client, http.MethodPost, rawURL, header, data)
+}
+
+// postSink posts data to the sink of the organization of the query named
+// name, with its credentials, retry policy and rate limit.
+func postSink(ctx context.Context, client *http.Client, validator fluxurl.Validator, name string, header http.Header, data []byte) (int, error) {
+	d := GetSinkDependencies(ctx)
+	if d == nil {
+		return 0, &flux.Error{
+			Code: codes.Unimplemented,
+			Msg:  "http sinks are not available",
+		}
+	}
+	req := query.RequestFromContext(ctx)
+	if req == nil {
+		return 0, &flux.Error{
+			Code: codes.Internal,
+			Msg:  "missing request on context",
+		}
+	}
+
+	hs, err := d.HTTPSinkService.FindHTTPSink(ctx, influxdb.HTTPSinkFilter{
+		OrgID: &req.OrganizationID,
+		Name:  &name,
+	})
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return 0, &flux.Error{
+				Code: codes.NotFound,
+				Msg:  fmt.Sprintf("http sink %q not found", name),
+			}
+		}
+		return 0, err
+	}
+	if err := validate(validator, hs.URL); err != nil {
+		return 0, err
+	}
+
+	for k, v := range hs.Headers {
+		if header.Get(k) == "" {
+			header.Set(k, v)
+		}
+	}
+	if hs.AuthMethod != influxdb.HTTPSinkAuthNone {
+		credentials, err := d.SecretService.LoadSecret(ctx, hs.OrgID, hs.Credentials.Key)
+		if err != nil {
+			return 0, err
+		}
+		switch hs.AuthMethod {
+		case influxdb.HTTPSinkAuthBasic:
+			auth := base64.StdEncoding.EncodeToString([]byte(hs.Username + ":" + credentials))
+			header.Set("Authorization", "Basic "+auth)
+		case influxdb.HTTPSinkAuthBearer:
+			header.Set("Authorization", "Bearer "+credentials)
+		}
+	}
+
+	limiter := d.limiter(hs)
+	backoff := hs.Retry.MinBackoff.Duration
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return 0, err
+			}
+		}
+		statusCode, err := do(ctx, client, hs.Method, hs.URL, header, data)
+		if !retryable(statusCode, err) || attempt >= hs.Retry.MaxAttempts {
+			return statusCode, err
+		}
+
+		// Jitter the backoff so that the runs of tasks failing together do
+		// not retry together.
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(wait):
+		}
+		if backoff *= 2; backoff > hs.Retry.MaxBackoff.Duration {
+			backoff = hs.Retry.MaxBackoff.Duration
+		}
+	}
+}
+
+// validate returns an error if the URL rawURL is invalid or not allowed by
+// validator.
+func validate(validator fluxurl.Validator, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	return validator.Validate(u)
+}
+
+// retryable reports whether a request which returned statusCode or err
+// should be retried.
+func retryable(statusCode int, err error) bool {
+	return err != nil || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func do(ctx context.Context, client *http.Client, method, rawURL string, header http.Header, data []byte) (int, error) {
+	req, err := http.NewRequest(method, rawURL, bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+type httpSinkService struct {
+	influxdb.HTTPSinkService
+	sink *influxdb.HTTPSink
+}
+
+func (s *httpSinkService) FindHTTPSink(ctx context.Context, filter influxdb.HTTPSinkFilter) (*influxdb.HTTPSink, error) {
+	if !filter.Match(s.sink) {
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrHTTPSinkNotFound}
+	}
+	return s.sink, nil
+}
+
+type secretLoader map[string]string
+
+func (l secretLoader) LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+	return l[k], nil
+}
+
+func TestPostSink(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer s3cr3t" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("unexpected content type %q", got)
+		}
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	orgID := influxdb.ID(1)
+	hs := &influxdb.HTTPSink{
+		ID:          2,
+		OrgID:       orgID,
+		Name:        "alerts",
+		URL:         srv.URL,
+		Headers:     map[string]string{"Content-Type": "application/json"},
+		AuthMethod:  influxdb.HTTPSinkAuthBearer,
+		Credentials: influxdb.SecretField{Key: "2-http-sink-credentials"},
+		Retry: influxdb.HTTPSinkRetry{
+			MaxAttempts: 3,
+			MinBackoff:  influxdb.Duration{Duration: time.Millisecond},
+			MaxBackoff:  influxdb.Duration{Duration: time.Millisecond},
+		},
+	}
+	hs.SetDefaults()
+
+	deps := NewSinkDependencies(&httpSinkService{sink: hs}, secretLoader{hs.Credentials.Key: "s3cr3t"})
+	ctx := deps.Inject(context.Background())
+	ctx = query.ContextWithRequest(ctx, &query.Request{OrganizationID: orgID})
+
+	statusCode, err := postSink(ctx, http.DefaultClient, url.PassValidator{}, "alerts", make(http.Header), []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusAccepted || atomic.LoadInt32(&requests) != 3 {
+		t.Fatalf("got status %d after %d requests, want %d after 3", statusCode, requests, http.StatusAccepted)
+	}
+
+	hs.Retry.MaxAttempts = 1
+	atomic.StoreInt32(&requests, 0)
+	statusCode, err = postSink(ctx, http.DefaultClient, url.PassValidator{}, "alerts", make(http.Header), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&requests) != 1 {
+		t.Fatalf("got status %d after %d requests, want %d after 1", statusCode, requests, http.StatusServiceUnavailable)
+	}
+
+	if _, err := postSink(ctx, http.DefaultClient, url.PassValidator{}, "missing", make(http.Header), nil); err == nil {
+		t.Fatal("expected missing sink to fail")
+	}
+}
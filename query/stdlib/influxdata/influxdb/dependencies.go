@@ -13,7 +13,10 @@ import (
 
 type key int
 
-const dependenciesKey key = iota
+const (
+	dependenciesKey key = iota
+	internalStatsDependenciesKey
+)
 
 type StorageDependencies struct {
 	FromDeps   FromDependencies
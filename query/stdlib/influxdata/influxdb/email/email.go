@@ -0,0 +1,310 @@
+// Package email adds the influxdata/influxdb/email package to Flux, which
+// sends mail through the SMTP server of the instance.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2"
+)
+
+const (
+	PackagePath = "influxdata/influxdb/email"
+	SendKind    = "send"
+)
+
+// source is the Flux source of the package. The endpoint function is used
+// with monitor.notify like the endpoints of the slack and pagerduty
+// packages, and mapFn returns the subject and body of the email of a row.
+const source = `package email
+
+builtin send
+
+endpoint = (to, cc="") => (mapFn) => (tables=<-) => tables
+	|> map(fn: (r) => {
+		obj = mapFn(r: r)
+		return {r with _sent: string(v: send(to: to, cc: cc, subject: obj.subject, body: obj.body))}
+	})
+`
+
+// sendTimeout bounds sending a single email when the query has no deadline.
+const sendTimeout = 30 * time.Second
+
+func init() {
+	pkg := parser.ParseSource(source)
+	if ast.Check(pkg) > 0 {
+		panic(ast.GetError(pkg))
+	}
+	pkg.Path = PackagePath
+	pkg.Files[0].Name = "email.flux"
+	flux.RegisterPackage(pkg)
+
+	sendSignature := semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{
+			"to":      semantic.String,
+			"cc":      semantic.String,
+			"subject": semantic.String,
+			"body":    semantic.String,
+		},
+		Required: semantic.LabelSet{"to", "subject", "body"},
+		Return:   semantic.Bool,
+	}
+	flux.RegisterPackageValue(PackagePath, SendKind, values.NewFunction(SendKind, semantic.NewFunctionPolyType(sendSignature), send, true))
+}
+
+type key int
+
+const dependenciesKey key = iota
+
+// SecretLoader loads the password of the SMTP server.
+type SecretLoader interface {
+	LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error)
+}
+
+// Dependencies are the services used to send mail.
+type Dependencies struct {
+	SMTPConfigService influxdb.SMTPConfigService
+	SecretService     SecretLoader
+}
+
+// NewDependencies returns the dependencies used to send mail through the
+// SMTP server of s with the password in ss.
+func NewDependencies(s influxdb.SMTPConfigService, ss SecretLoader) *Dependencies {
+	return &Dependencies{
+		SMTPConfigService: s,
+		SecretService:     ss,
+	}
+}
+
+func (d *Dependencies) Inject(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dependenciesKey, d)
+}
+
+func GetDependencies(ctx context.Context) *Dependencies {
+	d, _ := ctx.Value(dependenciesKey).(*Dependencies)
+	return d
+}
+
+func send(ctx context.Context, args values.Object) (values.Value, error) {
+	m := &message{}
+	for name, dst := range map[string]*string{
+		"subject": &m.subject,
+		"body":    &m.body,
+	} {
+		if v, ok := args.Get(name); ok {
+			*dst = v.Str()
+		}
+	}
+	var err error
+	if v, ok := args.Get("to"); ok {
+		if m.to, err = parseAddressList(v.Str()); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := args.Get("cc"); ok {
+		if m.cc, err = parseAddressList(v.Str()); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.to) == 0 {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  "email requires at least one to address",
+		}
+	}
+
+	d := GetDependencies(ctx)
+	if d == nil {
+		return nil, &flux.Error{
+			Code: codes.Unimplemented,
+			Msg:  "email is not available",
+		}
+	}
+	c, err := d.SMTPConfigService.FindSMTPConfig(ctx)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, &flux.Error{
+				Code: codes.FailedPrecondition,
+				Msg:  influxdb.ErrSMTPConfigNotFound,
+			}
+		}
+		return nil, err
+	}
+	var password string
+	if c.Password.Key != "" {
+		if password, err = d.SecretService.LoadSecret(ctx, c.ID, c.Password.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := sendMail(ctx, c, password, m); err != nil {
+		return nil, &flux.Error{
+			Code: codes.Unavailable,
+			Msg:  "failed to send email",
+			Err:  err,
+		}
+	}
+	return values.NewBool(true), nil
+}
+
+// parseAddressList parses a comma separated list of addresses, which may be empty.
+func parseAddressList(s string) ([]*mail.Address, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	addrs, err := mail.ParseAddressList(s)
+	if err != nil {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  fmt.Sprintf("invalid email address list %q", s),
+			Err:  err,
+		}
+	}
+	return addrs, nil
+}
+
+// message is an email to send.
+type message struct {
+	to, cc        []*mail.Address
+	subject, body string
+}
+
+// bytes returns the message sent by from at date, with its headers and a
+// quoted-printable body with CRLF line endings.
+func (m *message) bytes(from *mail.Address, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(k, v string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, v)
+	}
+	header("From", from.String())
+	header("To", joinAddresses(m.to))
+	if len(m.cc) > 0 {
+		header("Cc", joinAddresses(m.cc))
+	}
+	// A subject rendered from a template must not add headers.
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(m.subject)
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(m.body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func joinAddresses(addrs []*mail.Address) string {
+	ss := make([]string, len(addrs))
+	for i, a := range addrs {
+		ss[i] = a.String()
+	}
+	return strings.Join(ss, ", ")
+}
+
+// sendMail sends m through the SMTP server c, authenticating with password
+// if c has a username.
+func sendMail(ctx context.Context, c *influxdb.SMTPConfig, password string, m *message) error {
+	rcpts := make([]*mail.Address, 0, len(m.to)+len(m.cc))
+	rcpts = append(append(rcpts, m.to...), m.cc...)
+	return Send(ctx, c, password, rcpts, func(from *mail.Address) ([]byte, error) {
+		return m.bytes(from, time.Now())
+	})
+}
+
+// Send sends the message built by msg, from the address of the SMTP server
+// c, to rcpts through c, authenticating with password if c has a username.
+// The message must include its headers and use CRLF line endings.
+func Send(ctx context.Context, c *influxdb.SMTPConfig, password string, rcpts []*mail.Address, msg func(from *mail.Address) ([]byte, error)) error {
+	from, err := mail.ParseAddress(c.From)
+	if err != nil {
+		return err
+	}
+	data, err := msg(from)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         c.Host,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(sendTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	if c.TLS == influxdb.SMTPTLSImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.TLS == influxdb.SMTPTLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.Username, password, c.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, a := range rcpts {
+		if err := client.Rcpt(a.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
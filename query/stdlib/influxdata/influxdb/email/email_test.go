@@ -0,0 +1,119 @@
+package email
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// smtpServer accepts a single session on l and returns the recipients and
+// the data of the mail it received.
+func smtpServer(t *testing.T, l net.Listener) <-chan []string {
+	ch := make(chan []string, 1)
+	go func() {
+		defer close(ch)
+		conn, err := l.Accept()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		var got []string
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO", "MAIL":
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				got = append(got, line)
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 Go ahead")
+				lines, err := tp.ReadDotLines()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				got = append(got, strings.Join(lines, "\n"))
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 Bye")
+				ch <- got
+				return
+			default:
+				tp.PrintfLine("502 Unknown command %s", cmd)
+			}
+		}
+	}()
+	return ch
+}
+
+func TestSendMail(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	received := smtpServer(t, l)
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	p, _ := strconv.Atoi(port)
+	c := &influxdb.SMTPConfig{
+		Host: host,
+		Port: p,
+		TLS:  influxdb.SMTPTLSNone,
+		From: "InfluxDB <alerts@example.com>",
+	}
+	to, _ := mail.ParseAddressList("ops@example.com")
+	cc, _ := mail.ParseAddressList("Oncall <oncall@example.com>")
+	m := &message{
+		to:      to,
+		cc:      cc,
+		subject: "cpu is crit\r\nBcc: evil@example.com",
+		body:    "host a is at 99% ≥ 90%\nsince 10:00",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := sendMail(ctx, c, "", m); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if len(got) != 3 {
+		t.Fatalf("expected 2 recipients and the data, got %q", got)
+	}
+	if got[0] != "RCPT TO:<ops@example.com>" || got[1] != "RCPT TO:<oncall@example.com>" {
+		t.Errorf("unexpected recipients %q", got[:2])
+	}
+	for _, want := range []string{
+		`From: "InfluxDB" <alerts@example.com>`,
+		"To: <ops@example.com>",
+		`Cc: "Oncall" <oncall@example.com>`,
+		"Subject: cpu is crit  Bcc: evil@example.com",
+		"Content-Type: text/plain; charset=utf-8",
+		"host a is at 99% =E2=89=A5 90%",
+		"since 10:00",
+	} {
+		if !strings.Contains(got[2], want) {
+			t.Errorf("expected mail to contain %q, got:\n%s", want, got[2])
+		}
+	}
+	if strings.Contains(got[2], "\nBcc:") {
+		t.Errorf("expected subject not to add headers, got:\n%s", got[2])
+	}
+}
@@ -16,6 +16,7 @@ const FromKind = "influxDBFrom"
 type FromOpSpec struct {
 	Bucket   string `json:"bucket,omitempty"`
 	BucketID string `json:"bucketID,omitempty"`
+	Snapshot string `json:"snapshot,omitempty"`
 }
 
 func init() {
@@ -23,6 +24,7 @@ func init() {
 		Parameters: map[string]semantic.PolyType{
 			"bucket":   semantic.String,
 			"bucketID": semantic.String,
+			"snapshot": semantic.String,
 		},
 		Required: nil,
 		Return:   flux.TableObjectType,
@@ -48,6 +50,12 @@ func createFromOpSpec(args flux.Arguments, a *flux.Administration) (flux.Operati
 		spec.BucketID = bucketID
 	}
 
+	if snapshot, ok, err := args.GetString("snapshot"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Snapshot = snapshot
+	}
+
 	if spec.Bucket == "" && spec.BucketID == "" {
 		return nil, &flux.Error{
 			Code: codes.Invalid,
@@ -99,6 +107,10 @@ func (s *FromOpSpec) BucketsAccessed(orgID *platform.ID) (readBuckets, writeBuck
 type FromProcedureSpec struct {
 	Bucket   string
 	BucketID string
+
+	// Snapshot is the name or ID of the snapshot of the bucket to read,
+	// or empty to read the bucket.
+	Snapshot string
 }
 
 func newFromProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
@@ -113,6 +125,7 @@ func newFromProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.Proce
 	return &FromProcedureSpec{
 		Bucket:   spec.Bucket,
 		BucketID: spec.BucketID,
+		Snapshot: spec.Snapshot,
 	}, nil
 }
 
@@ -125,6 +138,7 @@ func (s *FromProcedureSpec) Copy() plan.ProcedureSpec {
 
 	ns.Bucket = s.Bucket
 	ns.BucketID = s.BucketID
+	ns.Snapshot = s.Snapshot
 
 	return ns
 }
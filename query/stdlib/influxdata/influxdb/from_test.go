@@ -59,6 +59,21 @@ func TestFrom_NewQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "from snapshot",
+			Raw:  `from(bucket:"prod", snapshot:"2024-05-01")`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket:   "prod",
+							Snapshot: "2024-05-01",
+						},
+					},
+				},
+			},
+		},
 		{
 			Name: "from with database",
 			Raw:  `from(bucket:"mybucket") |> range(start:-4h, stop:-2h) |> sum()`,
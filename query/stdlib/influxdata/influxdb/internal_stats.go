@@ -0,0 +1,195 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/internalstats"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+// InternalStatsKind is the kind of the internalStats() source, which reads
+// the counters of the write and query requests served by the instance for
+// the organization of the query.
+const InternalStatsKind = "internalStats"
+
+type InternalStatsOpSpec struct{}
+
+func init() {
+	internalStatsSignature := semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{},
+		Return:     flux.TableObjectType,
+	}
+	flux.RegisterPackageValue("influxdata/influxdb", InternalStatsKind, flux.FunctionValue(InternalStatsKind, createInternalStatsOpSpec, internalStatsSignature))
+	flux.RegisterOpSpec(InternalStatsKind, newInternalStatsOp)
+	plan.RegisterProcedureSpec(InternalStatsKind, newInternalStatsProcedure, InternalStatsKind)
+	execute.RegisterSource(InternalStatsKind, createInternalStatsSource)
+}
+
+func createInternalStatsOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	return new(InternalStatsOpSpec), nil
+}
+
+func newInternalStatsOp() flux.OperationSpec {
+	return new(InternalStatsOpSpec)
+}
+
+func (s *InternalStatsOpSpec) Kind() flux.OperationKind {
+	return InternalStatsKind
+}
+
+type InternalStatsProcedureSpec struct {
+	plan.DefaultCost
+}
+
+func newInternalStatsProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	if _, ok := qs.(*InternalStatsOpSpec); !ok {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  fmt.Sprintf("invalid spec type %T", qs),
+		}
+	}
+	return &InternalStatsProcedureSpec{}, nil
+}
+
+func (s *InternalStatsProcedureSpec) Kind() plan.ProcedureKind {
+	return InternalStatsKind
+}
+
+func (s *InternalStatsProcedureSpec) Copy() plan.ProcedureSpec {
+	return new(InternalStatsProcedureSpec)
+}
+
+// InternalStatsDecoder decodes the stats of an organization as a single
+// table with a row per counter. The measurement of a row is the kind of the
+// requests, and its field the name of the counter.
+type InternalStatsDecoder struct {
+	orgID platform.ID
+	deps  InternalStatsDependencies
+	now   time.Time
+	stats []internalstats.Stat
+	alloc *memory.Allocator
+}
+
+func (d *InternalStatsDecoder) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (d *InternalStatsDecoder) Fetch(ctx context.Context) (bool, error) {
+	d.stats = d.deps.Stats.Find(d.orgID)
+	return false, nil
+}
+
+func (d *InternalStatsDecoder) Decode(ctx context.Context) (flux.Table, error) {
+	kb := execute.NewGroupKeyBuilder(nil)
+	kb.AddKeyValue("organizationID", values.NewString(d.orgID.String()))
+	gk, err := kb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	b := execute.NewColListTableBuilder(gk, d.alloc)
+	for _, c := range []flux.ColMeta{
+		{Label: "organizationID", Type: flux.TString},
+		{Label: execute.DefaultTimeColLabel, Type: flux.TTime},
+		{Label: "_measurement", Type: flux.TString},
+		{Label: "bucketID", Type: flux.TString},
+		{Label: "_field", Type: flux.TString},
+		{Label: execute.DefaultValueColLabel, Type: flux.TInt},
+	} {
+		if _, err := b.AddCol(c); err != nil {
+			return nil, err
+		}
+	}
+
+	now := values.ConvertTime(d.now)
+	for _, s := range d.stats {
+		var bucketID string
+		if s.BucketID.Valid() {
+			bucketID = s.BucketID.String()
+		}
+		for _, f := range []struct {
+			name  string
+			value int64
+		}{
+			{"accepted", s.Accepted},
+			{"rejected", s.Rejected},
+			{"points_accepted", s.PointsAccepted},
+			{"points_rejected", s.PointsRejected},
+			{"request_bytes", s.RequestBytes},
+			{"response_bytes", s.ResponseBytes},
+			{"duration_total", int64(s.DurationTotal)},
+			{"duration_max", int64(s.DurationMax)},
+		} {
+			_ = b.AppendString(0, s.OrgID.String())
+			_ = b.AppendTime(1, now)
+			_ = b.AppendString(2, s.Kind)
+			_ = b.AppendString(3, bucketID)
+			_ = b.AppendString(4, f.name)
+			_ = b.AppendInt(5, f.value)
+		}
+	}
+
+	return b.Table()
+}
+
+func (d *InternalStatsDecoder) Close() error {
+	return nil
+}
+
+func createInternalStatsSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	if _, ok := prSpec.(*InternalStatsProcedureSpec); !ok {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  fmt.Sprintf("invalid spec type %T", prSpec),
+		}
+	}
+
+	deps, ok := GetInternalStatsDependencies(a.Context())
+	if !ok {
+		return nil, &flux.Error{
+			Code: codes.Unimplemented,
+			Msg:  "internal stats are not available",
+		}
+	}
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  "missing request on context",
+		}
+	}
+
+	d := &InternalStatsDecoder{
+		orgID: req.OrganizationID,
+		deps:  deps,
+		now:   time.Now().UTC(),
+		alloc: a.Allocator(),
+	}
+	return execute.CreateSourceFromDecoder(d, dsid, a)
+}
+
+// InternalStatsDependencies are the dependencies of the internalStats() source.
+type InternalStatsDependencies struct {
+	Stats *internalstats.Stats
+}
+
+func (d InternalStatsDependencies) Inject(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalStatsDependenciesKey, d)
+}
+
+// GetInternalStatsDependencies returns the dependencies of the internalStats()
+// source, if they were injected into ctx.
+func GetInternalStatsDependencies(ctx context.Context) (InternalStatsDependencies, bool) {
+	d, ok := ctx.Value(internalStatsDependenciesKey).(InternalStatsDependencies)
+	return d, ok && d.Stats != nil
+}
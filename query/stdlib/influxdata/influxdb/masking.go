@@ -0,0 +1,306 @@
+package influxdb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+// maskingReader is a Reader which masks the values of the fields and tags
+// of a bucket matched by its masking rules.
+type maskingReader struct {
+	Reader
+	buckets platform.BucketService
+}
+
+// NewMaskingReader returns a Reader which reads through reader, and masks
+// the values of the fields and tags matched by the masking rules of the
+// bucket read, found with buckets, unless the authorizer of the query has
+// the read permission on the unmasked resource of the bucket. Queries
+// without an authorizer read masked values.
+func NewMaskingReader(reader Reader, buckets platform.BucketService) Reader {
+	return &maskingReader{
+		Reader:  reader,
+		buckets: buckets,
+	}
+}
+
+// maskingRules returns the masking rules of the bucket read by spec, or nil
+// if the values read are not masked.
+func (r *maskingReader) maskingRules(ctx context.Context, spec ReadFilterSpec) ([]platform.MaskingRule, error) {
+	b, err := r.buckets.FindBucketByID(ctx, spec.BucketID)
+	if platform.ErrorCode(err) == platform.ENotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(b.MaskingRules) == 0 {
+		return nil, nil
+	}
+	if a, err := icontext.GetAuthorizer(ctx); err == nil && a.Allowed(platform.Permission{
+		Action: platform.ReadAction,
+		Resource: platform.Resource{
+			Type:  platform.UnmaskedResourceType,
+			OrgID: &b.OrgID,
+			ID:    &b.ID,
+		},
+	}) {
+		return nil, nil
+	}
+	return b.MaskingRules, nil
+}
+
+func (r *maskingReader) ReadFilter(ctx context.Context, spec ReadFilterSpec, alloc *memory.Allocator) (TableIterator, error) {
+	rules, err := r.maskingRules(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	ti, err := r.Reader.ReadFilter(ctx, spec, alloc)
+	if err != nil || rules == nil {
+		return ti, err
+	}
+	return &maskingIterator{TableIterator: ti, rules: rules, bucketID: spec.BucketID, alloc: alloc}, nil
+}
+
+func (r *maskingReader) ReadGroup(ctx context.Context, spec ReadGroupSpec, alloc *memory.Allocator) (TableIterator, error) {
+	rules, err := r.maskingRules(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	}
+	ti, err := r.Reader.ReadGroup(ctx, spec, alloc)
+	if err != nil || rules == nil {
+		return ti, err
+	}
+	return &maskingIterator{TableIterator: ti, rules: rules, bucketID: spec.BucketID, alloc: alloc}, nil
+}
+
+func (r *maskingReader) ReadTagValues(ctx context.Context, spec ReadTagValuesSpec, alloc *memory.Allocator) (TableIterator, error) {
+	rules, err := r.maskingRules(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	}
+	ti, err := r.Reader.ReadTagValues(ctx, spec, alloc)
+	if err != nil || rules == nil {
+		return ti, err
+	}
+	// The measurements of the values are not known, so the values are
+	// masked by any rule masking the tag.
+	rule, ok := anyTagMaskingRule(rules, spec.TagKey)
+	if !ok {
+		return ti, nil
+	}
+	return &tagValuesMaskingIterator{TableIterator: ti, rule: rule, bucketID: spec.BucketID, alloc: alloc}, nil
+}
+
+// anyTagMaskingRule returns the rule masking the tag key in every
+// measurement, or else the first rule masking it in any measurement.
+func anyTagMaskingRule(rules []platform.MaskingRule, key string) (platform.MaskingRule, bool) {
+	if r, ok := platform.FindMaskingRule(rules, "", key, false); ok {
+		return r, true
+	}
+	for _, r := range rules {
+		if r.Tag == key {
+			return r, true
+		}
+	}
+	return platform.MaskingRule{}, false
+}
+
+// isTagColumn returns whether col holds the values of a tag.
+func isTagColumn(col flux.ColMeta) bool {
+	return col.Type == flux.TString && !strings.HasPrefix(col.Label, "_")
+}
+
+// maskingIterator masks the values of the tags and fields of the tables it
+// reads matched by masking rules.
+type maskingIterator struct {
+	TableIterator
+	rules    []platform.MaskingRule
+	bucketID platform.ID
+	alloc    *memory.Allocator
+}
+
+// masks returns whether any of the rules may mask the values of the
+// columns cols.
+func (mi *maskingIterator) masks(cols []flux.ColMeta) bool {
+	hasField := execute.ColIdx("_field", cols) >= 0
+	for _, r := range mi.rules {
+		if r.Field != "" && hasField {
+			return true
+		}
+		if j := execute.ColIdx(r.Tag, cols); r.Tag != "" && j >= 0 && isTagColumn(cols[j]) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskKey returns key with its tag values masked. The tag values of tables
+// without a single measurement are masked by any rule masking the tag.
+func (mi *maskingIterator) maskKey(key flux.GroupKey) flux.GroupKey {
+	measurement, known := "", false
+	if j := execute.ColIdx("_measurement", key.Cols()); j >= 0 && key.Cols()[j].Type == flux.TString {
+		measurement, known = key.ValueString(j), true
+	}
+	vs := make([]values.Value, len(key.Cols()))
+	for j, col := range key.Cols() {
+		vs[j] = key.Value(j)
+		if !isTagColumn(col) || vs[j].IsNull() {
+			continue
+		}
+		var (
+			rule platform.MaskingRule
+			ok   bool
+		)
+		if known {
+			rule, ok = platform.FindMaskingRule(mi.rules, measurement, col.Label, false)
+		} else {
+			rule, ok = anyTagMaskingRule(mi.rules, col.Label)
+		}
+		if ok {
+			vs[j] = values.NewString(rule.Mask(mi.bucketID, vs[j].Str()))
+		}
+	}
+	return execute.NewGroupKey(key.Cols(), vs)
+}
+
+func (mi *maskingIterator) Do(f func(flux.Table) error) error {
+	return mi.TableIterator.Do(func(tbl flux.Table) error {
+		cols := tbl.Cols()
+		if !mi.masks(cols) {
+			return f(tbl)
+		}
+		key := mi.maskKey(tbl.Key())
+		mIdx := execute.ColIdx("_measurement", cols)
+		fIdx := execute.ColIdx("_field", cols)
+		vIdx := execute.ColIdx(execute.DefaultValueColLabel, cols)
+		keyIdx := make([]int, len(cols))
+		for j, col := range cols {
+			keyIdx[j] = execute.ColIdx(col.Label, key.Cols())
+		}
+
+		b := execute.NewColListTableBuilder(key, mi.alloc)
+		if err := execute.AddTableCols(tbl, b); err != nil {
+			return err
+		}
+		if err := tbl.Do(func(cr flux.ColReader) error {
+			for i := 0; i < cr.Len(); i++ {
+				var measurement string
+				if mIdx >= 0 {
+					if m := execute.ValueForRow(cr, i, mIdx); !m.IsNull() {
+						measurement = m.Str()
+					}
+				}
+				for j, col := range cols {
+					v, null := execute.ValueForRow(cr, i, j), false
+					switch {
+					case keyIdx[j] >= 0:
+						// Key columns hold the masked values of the key.
+						v = key.Value(keyIdx[j])
+					case v.IsNull():
+					case isTagColumn(col):
+						if rule, ok := platform.FindMaskingRule(mi.rules, measurement, col.Label, false); ok {
+							v = values.NewString(rule.Mask(mi.bucketID, v.Str()))
+						}
+					case j == vIdx && fIdx >= 0:
+						field := execute.ValueForRow(cr, i, fIdx)
+						if field.IsNull() {
+							break
+						}
+						if rule, ok := platform.FindMaskingRule(mi.rules, measurement, field.Str(), true); ok {
+							if col.Type == flux.TString {
+								v = values.NewString(rule.Mask(mi.bucketID, v.Str()))
+							} else {
+								null = true
+							}
+						}
+					}
+
+					var err error
+					if null || v.IsNull() {
+						err = b.AppendNil(j)
+					} else {
+						err = b.AppendValue(j, v)
+					}
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		out, err := b.Table()
+		if err != nil {
+			return err
+		}
+		return f(out)
+	})
+}
+
+// tagValuesMaskingIterator masks the tag values of the tables it reads with
+// a masking rule, dropping the masked values already read.
+type tagValuesMaskingIterator struct {
+	TableIterator
+	rule     platform.MaskingRule
+	bucketID platform.ID
+	alloc    *memory.Allocator
+}
+
+func (ti *tagValuesMaskingIterator) Do(f func(flux.Table) error) error {
+	return ti.TableIterator.Do(func(tbl flux.Table) error {
+		vIdx := execute.ColIdx(execute.DefaultValueColLabel, tbl.Cols())
+		if vIdx < 0 || tbl.Cols()[vIdx].Type != flux.TString {
+			return f(tbl)
+		}
+
+		b := execute.NewColListTableBuilder(tbl.Key(), ti.alloc)
+		if err := execute.AddTableCols(tbl, b); err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		if err := tbl.Do(func(cr flux.ColReader) error {
+			vs := cr.Strings(vIdx)
+			for i := 0; i < cr.Len(); i++ {
+				if !vs.IsValid(i) {
+					continue
+				}
+				v := ti.rule.Mask(ti.bucketID, string(vs.Value(i)))
+				if seen[v] {
+					continue
+				}
+				seen[v] = true
+				for j := range tbl.Cols() {
+					var err error
+					if j == vIdx {
+						err = b.AppendValue(j, values.NewString(v))
+					} else if cv := execute.ValueForRow(cr, i, j); cv.IsNull() {
+						err = b.AppendNil(j)
+					} else {
+						err = b.AppendValue(j, cv)
+					}
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		out, err := b.Table()
+		if err != nil {
+			return err
+		}
+		return f(out)
+	})
+}
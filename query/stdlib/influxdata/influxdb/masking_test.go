@@ -0,0 +1,95 @@
+package influxdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+	platform "github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+func TestMaskingReader_ReadFilter(t *testing.T) {
+	table := func(field, host string, typ flux.ColType, vs ...interface{}) *executetest.Table {
+		tbl := &executetest.Table{
+			KeyCols: []string{"_field", "_measurement", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: typ},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+			},
+		}
+		for i, v := range vs {
+			tbl.Data = append(tbl.Data, []interface{}{execute.Time(i), v, field, "login", host})
+		}
+		return tbl
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{
+			ID:    id,
+			OrgID: 1,
+			MaskingRules: []platform.MaskingRule{
+				{Tag: "host", Method: platform.MaskingRedact},
+				{Measurement: "login", Field: "email", Method: platform.MaskingTruncate, Length: 3},
+				{Field: "attempts", Method: platform.MaskingHash},
+			},
+		}, nil
+	}
+	r := NewMaskingReader(&softTTLTestReader{tables: []*executetest.Table{
+		table("email", "a", flux.TString, "alice@example.com", "al"),
+		table("attempts", "b", flux.TInt, int64(1)),
+		table("duration", "b", flux.TFloat, 2.5),
+	}}, buckets)
+
+	read := func(ctx context.Context) []*executetest.Table {
+		t.Helper()
+		ti, err := r.ReadFilter(ctx, ReadFilterSpec{OrganizationID: 1, BucketID: 2}, &memory.Allocator{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []*executetest.Table
+		if err := ti.Do(func(tbl flux.Table) error {
+			tt, err := executetest.ConvertTable(tbl)
+			if err != nil {
+				return err
+			}
+			got = append(got, tt)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		executetest.NormalizeTables(got)
+		return got
+	}
+
+	// Queries without the unmasked permission read masked values.
+	want := []*executetest.Table{
+		table("email", platform.RedactedValue, flux.TString, "ali", "al"),
+		table("attempts", platform.RedactedValue, flux.TInt, nil),
+		table("duration", platform.RedactedValue, flux.TFloat, 2.5),
+	}
+	executetest.NormalizeTables(want)
+	if got := read(context.Background()); !cmp.Equal(want, got) {
+		t.Errorf("unexpected masked tables -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	want = []*executetest.Table{
+		table("email", "a", flux.TString, "alice@example.com", "al"),
+		table("attempts", "b", flux.TInt, int64(1)),
+		table("duration", "b", flux.TFloat, 2.5),
+	}
+	executetest.NormalizeTables(want)
+	ctx := icontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(true, nil))
+	if got := read(ctx); !cmp.Equal(want, got) {
+		t.Errorf("unexpected unmasked tables -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
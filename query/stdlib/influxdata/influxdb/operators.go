@@ -50,6 +50,7 @@ type ReadRangePhysSpec struct {
 
 	Bucket   string
 	BucketID string
+	Snapshot string
 
 	// FilterSet is set to true if there is a filter.
 	FilterSet bool
@@ -69,6 +70,7 @@ func (s *ReadRangePhysSpec) Copy() plan.ProcedureSpec {
 
 	ns.Bucket = s.Bucket
 	ns.BucketID = s.BucketID
+	ns.Snapshot = s.Snapshot
 
 	ns.FilterSet = s.FilterSet
 	if ns.FilterSet {
@@ -81,6 +81,7 @@ func (rule PushDownRangeRule) Rewrite(ctx context.Context, node plan.Node) (plan
 	return plan.CreatePhysicalNode("ReadRange", &ReadRangePhysSpec{
 		Bucket:   fromSpec.Bucket,
 		BucketID: fromSpec.BucketID,
+		Snapshot: fromSpec.Snapshot,
 		Bounds:   rangeSpec.Bounds,
 	}), true, nil
 }
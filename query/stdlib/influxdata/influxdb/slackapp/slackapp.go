@@ -0,0 +1,198 @@
+// Package slackapp adds the influxdata/influxdb/slackapp package to Flux,
+// which posts alerts as the messages of a slack app and threads the updates
+// of an alert onto its message.
+package slackapp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/slackapp"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+const (
+	PackagePath = "influxdata/influxdb/slackapp"
+	MessageKind = "message"
+)
+
+// source is the Flux source of the package. The endpoint function is used
+// with monitor.notify like the endpoint of the slack package, and the row
+// is given to message to identify the alert of a status.
+const source = `package slackapp
+
+builtin message
+
+endpoint = (url="https://slack.com/api", token, endpointID, acknowledge=false) => (mapFn) => (tables=<-) => tables
+	|> map(fn: (r) => {
+		obj = mapFn(r: r)
+		return {r with _sent: string(v: message(url: url, token: token, endpointID: endpointID, acknowledge: acknowledge, channel: obj.channel, text: obj.text, color: obj.color, r: r))}
+	})
+`
+
+func init() {
+	pkg := parser.ParseSource(source)
+	if ast.Check(pkg) > 0 {
+		panic(ast.GetError(pkg))
+	}
+	pkg.Path = PackagePath
+	pkg.Files[0].Name = "slackapp.flux"
+	flux.RegisterPackage(pkg)
+
+	messageSignature := semantic.FunctionPolySignature{
+		Parameters: map[string]semantic.PolyType{
+			"url":         semantic.String,
+			"token":       semantic.String,
+			"endpointID":  semantic.String,
+			"acknowledge": semantic.Bool,
+			"channel":     semantic.String,
+			"text":        semantic.String,
+			"color":       semantic.String,
+			"r":           semantic.Tvar(1),
+		},
+		Required: semantic.LabelSet{"url", "token", "endpointID", "channel", "text", "r"},
+		Return:   semantic.Bool,
+	}
+	flux.RegisterPackageValue(PackagePath, MessageKind, values.NewFunction(MessageKind, semantic.NewFunctionPolyType(messageSignature), message, true))
+}
+
+type key int
+
+const dependenciesKey key = iota
+
+// Dependencies are the services used to thread the messages of alerts.
+type Dependencies struct {
+	SlackThreadService influxdb.SlackThreadService
+}
+
+// NewDependencies returns the dependencies keeping the messages of alerts in s.
+func NewDependencies(s influxdb.SlackThreadService) *Dependencies {
+	return &Dependencies{
+		SlackThreadService: s,
+	}
+}
+
+func (d *Dependencies) Inject(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dependenciesKey, d)
+}
+
+func GetDependencies(ctx context.Context) *Dependencies {
+	d, _ := ctx.Value(dependenciesKey).(*Dependencies)
+	return d
+}
+
+func message(ctx context.Context, args values.Object) (values.Value, error) {
+	str := func(name string) string {
+		if v, ok := args.Get(name); ok {
+			return v.Str()
+		}
+		return ""
+	}
+	a := &slackapp.Alert{
+		Channel: str("channel"),
+		Text:    str("text"),
+		Color:   str("color"),
+	}
+	if v, ok := args.Get("acknowledge"); ok {
+		a.Acknowledge = v.Bool()
+	}
+	if a.Channel == "" {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  "slack app message requires a channel",
+		}
+	}
+	if err := a.EndpointID.DecodeFromString(str("endpointID")); err != nil {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  "invalid slack app endpoint ID",
+			Err:  err,
+		}
+	}
+	r, _ := args.Get("r")
+	a.Key, a.Level = alertKey(r.Object())
+
+	d := GetDependencies(ctx)
+	if d == nil {
+		return nil, &flux.Error{
+			Code: codes.Unimplemented,
+			Msg:  "slack app messages are not available",
+		}
+	}
+	req := query.RequestFromContext(ctx)
+	if req == nil {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  "missing request on context",
+		}
+	}
+	a.OrgID = req.OrganizationID
+
+	deps := flux.GetDependencies(ctx)
+	client, err := deps.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	validator, err := deps.URLValidator()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := str("url")
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.Validate(u); err != nil {
+		return nil, err
+	}
+
+	n := &slackapp.Notifier{
+		Client: &slackapp.Client{
+			HTTPClient: client,
+			URL:        apiURL,
+			Token:      str("token"),
+		},
+		Threads: d.SlackThreadService,
+	}
+	if err := n.Notify(ctx, a); err != nil {
+		return nil, &flux.Error{
+			Code: codes.Unavailable,
+			Msg:  "failed to send slack app message",
+			Err:  err,
+		}
+	}
+	return values.NewBool(true), nil
+}
+
+// alertKey returns the key identifying the alert of the status r, its rule,
+// its check and its series, and the level of the status.
+func alertKey(r values.Object) (key, level string) {
+	var ruleID, checkID string
+	tags := make(map[string]string)
+	r.Range(func(k string, v values.Value) {
+		if v.IsNull() || v.Type() != semantic.String {
+			return
+		}
+		switch {
+		case k == "_notification_rule_id":
+			ruleID = v.Str()
+		case k == "_check_id":
+			checkID = v.Str()
+		case k == "_level":
+			level = v.Str()
+		case !strings.HasPrefix(k, "_"):
+			tags[k] = v.Str()
+		}
+	})
+	series := influxdb.AlertStatus{Tags: tags}.SeriesKey()
+	return fmt.Sprintf("%s/%s/%s", ruleID, checkID, series), level
+}
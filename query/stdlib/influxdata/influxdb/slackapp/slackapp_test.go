@@ -0,0 +1,26 @@
+package slackapp
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux/values"
+)
+
+func TestAlertKey(t *testing.T) {
+	r := values.NewObjectWithValues(map[string]values.Value{
+		"_notification_rule_id": values.NewString("0000000000000001"),
+		"_check_id":             values.NewString("0000000000000002"),
+		"_level":                values.NewString("crit"),
+		"_message":              values.NewString("cpu is high"),
+		"host":                  values.NewString("a"),
+		"cpu":                   values.NewString("cpu0"),
+		"_value":                values.NewFloat(95),
+	})
+	key, level := alertKey(r)
+	if want := "0000000000000001/0000000000000002/cpu=cpu0,host=a"; key != want {
+		t.Errorf("got key %q, want %q", key, want)
+	}
+	if level != "crit" {
+		t.Errorf("got level %q, want crit", level)
+	}
+}
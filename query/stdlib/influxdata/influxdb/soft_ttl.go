@@ -0,0 +1,157 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// softTTLReader is a Reader which hides the points of a bucket older than
+// the soft TTL of their measurement.
+type softTTLReader struct {
+	Reader
+	buckets platform.BucketService
+	now     func() time.Time
+}
+
+// NewSoftTTLReader returns a Reader which reads through reader, and drops
+// the points older than the soft TTLs of the bucket read, found with
+// buckets. Tag keys and values are read from every series, since the age
+// of the points they come from is not known.
+func NewSoftTTLReader(reader Reader, buckets platform.BucketService) Reader {
+	return &softTTLReader{
+		Reader:  reader,
+		buckets: buckets,
+		now:     time.Now,
+	}
+}
+
+// softTTLs returns the soft TTLs of the bucket read by spec, or nil if no
+// point in the bounds of spec is old enough to be hidden.
+func (r *softTTLReader) softTTLs(ctx context.Context, spec ReadFilterSpec) ([]platform.SoftTTL, error) {
+	b, err := r.buckets.FindBucketByID(ctx, spec.BucketID)
+	if platform.ErrorCode(err) == platform.ENotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var max time.Duration
+	for _, t := range b.SoftTTLs {
+		if t.TTL.Duration > max {
+			max = t.TTL.Duration
+		}
+	}
+	if max == 0 || spec.Bounds.Start.Time().After(r.now().Add(-max)) {
+		return nil, nil
+	}
+	return b.SoftTTLs, nil
+}
+
+func (r *softTTLReader) ReadFilter(ctx context.Context, spec ReadFilterSpec, alloc *memory.Allocator) (TableIterator, error) {
+	ttls, err := r.softTTLs(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	ti, err := r.Reader.ReadFilter(ctx, spec, alloc)
+	if err != nil || ttls == nil {
+		return ti, err
+	}
+	return r.filter(ti, ttls, alloc), nil
+}
+
+func (r *softTTLReader) ReadGroup(ctx context.Context, spec ReadGroupSpec, alloc *memory.Allocator) (TableIterator, error) {
+	ttls, err := r.softTTLs(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	}
+	ti, err := r.Reader.ReadGroup(ctx, spec, alloc)
+	if err != nil || ttls == nil {
+		return ti, err
+	}
+	return r.filter(ti, ttls, alloc), nil
+}
+
+func (r *softTTLReader) filter(ti TableIterator, ttls []platform.SoftTTL, alloc *memory.Allocator) *softTTLIterator {
+	return &softTTLIterator{
+		TableIterator: ti,
+		ttls:          ttls,
+		now:           r.now(),
+		alloc:         alloc,
+		cutoffs:       make(map[string]int64),
+	}
+}
+
+// softTTLIterator drops the rows of the tables it reads which are older
+// than the soft TTL of their measurement.
+type softTTLIterator struct {
+	TableIterator
+	ttls    []platform.SoftTTL
+	now     time.Time
+	alloc   *memory.Allocator
+	cutoffs map[string]int64
+}
+
+// cutoff returns the time of the oldest points of measurement kept, or
+// models.MinNanoTime if every point is kept.
+func (si *softTTLIterator) cutoff(measurement string) int64 {
+	c, ok := si.cutoffs[measurement]
+	if !ok {
+		c = models.MinNanoTime
+		if ttl := platform.FindSoftTTL(si.ttls, measurement); ttl > 0 {
+			c = si.now.Add(-ttl).UnixNano()
+		}
+		si.cutoffs[measurement] = c
+	}
+	return c
+}
+
+func (si *softTTLIterator) Do(f func(flux.Table) error) error {
+	return si.TableIterator.Do(func(tbl flux.Table) error {
+		timeIdx := execute.ColIdx(execute.DefaultTimeColLabel, tbl.Cols())
+		mIdx := execute.ColIdx("_measurement", tbl.Cols())
+		if timeIdx < 0 || mIdx < 0 {
+			return f(tbl)
+		}
+
+		b := execute.NewColListTableBuilder(tbl.Key(), si.alloc)
+		if err := execute.AddTableCols(tbl, b); err != nil {
+			return err
+		}
+		if err := tbl.Do(func(cr flux.ColReader) error {
+			times, ms := cr.Times(timeIdx), cr.Strings(mIdx)
+			for i := 0; i < cr.Len(); i++ {
+				if times.IsValid(i) && ms.IsValid(i) && times.Value(i) < si.cutoff(string(ms.Value(i))) {
+					continue
+				}
+				for j := range cr.Cols() {
+					var err error
+					if v := execute.ValueForRow(cr, i, j); v.IsNull() {
+						err = b.AppendNil(j)
+					} else {
+						err = b.AppendValue(j, v)
+					}
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if b.NRows() == 0 {
+			return nil
+		}
+
+		out, err := b.Table()
+		if err != nil {
+			return err
+		}
+		return f(out)
+	})
+}
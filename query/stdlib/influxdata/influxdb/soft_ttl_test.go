@@ -0,0 +1,120 @@
+package influxdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+type softTTLTableIterator struct {
+	tables []*executetest.Table
+}
+
+func (ti *softTTLTableIterator) Do(f func(flux.Table) error) error {
+	for _, tbl := range ti.tables {
+		if err := f(tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ti *softTTLTableIterator) Statistics() cursors.CursorStats { return cursors.CursorStats{} }
+
+type softTTLTestReader struct {
+	Reader
+	tables []*executetest.Table
+}
+
+func (r *softTTLTestReader) ReadFilter(ctx context.Context, spec ReadFilterSpec, alloc *memory.Allocator) (TableIterator, error) {
+	return &softTTLTableIterator{tables: r.tables}, nil
+}
+
+func TestSoftTTLReader_ReadFilter(t *testing.T) {
+	now := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	start := execute.Time(now.Add(-24 * time.Hour).UnixNano())
+	stop := execute.Time(now.UnixNano())
+	ago := func(d time.Duration) execute.Time { return execute.Time(now.Add(-d).UnixNano()) }
+
+	table := func(measurement string, times ...execute.Time) *executetest.Table {
+		tbl := &executetest.Table{
+			KeyCols: []string{"_start", "_stop", "_field", "_measurement"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_start", Type: flux.TTime},
+				{Label: "_stop", Type: flux.TTime},
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_measurement", Type: flux.TString},
+			},
+		}
+		for _, ts := range times {
+			tbl.Data = append(tbl.Data, []interface{}{start, stop, ts, 1.0, "v", measurement})
+		}
+		return tbl
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{
+			ID: id,
+			SoftTTLs: []platform.SoftTTL{
+				{Measurement: "cpu", TTL: platform.Duration{Duration: time.Hour}},
+				{Measurement: "audit", TTL: platform.Duration{Duration: time.Minute}},
+			},
+		}, nil
+	}
+	r := NewSoftTTLReader(&softTTLTestReader{tables: []*executetest.Table{
+		table("cpu", ago(2*time.Hour), ago(30*time.Minute)),
+		table("mem", ago(2*time.Hour), ago(30*time.Minute)),
+		table("audit", ago(2*time.Hour), ago(30*time.Minute)),
+	}}, buckets).(*softTTLReader)
+	r.now = func() time.Time { return now }
+
+	spec := ReadFilterSpec{BucketID: 1, Bounds: execute.Bounds{Start: start, Stop: stop}}
+	ti, err := r.ReadFilter(context.Background(), spec, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*executetest.Table
+	if err := ti.Do(func(tbl flux.Table) error {
+		tt, err := executetest.ConvertTable(tbl)
+		if err != nil {
+			return err
+		}
+		got = append(got, tt)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every point of audit is hidden, so it has no table.
+	want := []*executetest.Table{
+		table("cpu", ago(30*time.Minute)),
+		table("mem", ago(2*time.Hour), ago(30*time.Minute)),
+	}
+	executetest.NormalizeTables(got)
+	executetest.NormalizeTables(want)
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected tables -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	// Nothing in the bounds is older than the longest TTL.
+	spec.Bounds.Start = ago(time.Minute)
+	ti, err = r.ReadFilter(context.Background(), spec, &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ti.(*softTTLIterator); ok {
+		t.Error("expected a read of recent data not to be filtered")
+	}
+}
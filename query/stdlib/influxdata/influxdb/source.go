@@ -36,6 +36,10 @@ type Source struct {
 	alloc *memory.Allocator
 	stats cursors.CursorStats
 
+	// pushdowns are the operations of the query performed by the storage
+	// engine, reported in the metadata.
+	pushdowns []string
+
 	runner runner
 
 	m     *metrics
@@ -65,9 +69,18 @@ func (s *Source) AddTransformation(t execute.Transformation) {
 }
 
 func (s *Source) Metadata() flux.Metadata {
+	pushdowns := make([]interface{}, len(s.pushdowns))
+	for i, p := range s.pushdowns {
+		pushdowns[i] = p
+	}
 	return flux.Metadata{
 		"influxdb/scanned-bytes":  []interface{}{s.stats.ScannedBytes},
 		"influxdb/scanned-values": []interface{}{s.stats.ScannedValues},
+		"influxdb/blocks-decoded": []interface{}{s.stats.BlocksDecoded},
+		"influxdb/blocks-merged":  []interface{}{s.stats.BlocksMerged},
+		"influxdb/blocks-skipped": []interface{}{s.stats.BlocksSkipped},
+		"influxdb/scanned-series": []interface{}{s.stats.ScannedSeries},
+		"influxdb/pushdowns":      pushdowns,
 	}
 }
 
@@ -79,10 +92,8 @@ func (s *Source) processTables(ctx context.Context, tables TableIterator, waterm
 		return err
 	}
 
-	// Track the number of bytes and values scanned.
-	stats := tables.Statistics()
-	s.stats.ScannedValues += stats.ScannedValues
-	s.stats.ScannedBytes += stats.ScannedBytes
+	// Track the number of bytes and values scanned, and of blocks read.
+	s.stats.Add(tables.Statistics())
 
 	for _, t := range s.ts {
 		if err := t.UpdateWatermark(s.id, watermark); err != nil {
@@ -134,6 +145,7 @@ func ReadFilterSource(id execute.DatasetID, r Reader, readSpec ReadFilterSpec, a
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
 	src.op = "readFilter"
+	src.pushdowns = readSpec.Pushdowns()
 
 	src.runner = src
 	return src
@@ -192,6 +204,7 @@ func createReadFilterSource(s plan.ProcedureSpec, id execute.DatasetID, a execut
 		ReadFilterSpec{
 			OrganizationID: orgID,
 			BucketID:       bucketID,
+			Snapshot:       spec.Snapshot,
 			Bounds:         *bounds,
 			Predicate:      filter,
 		},
@@ -217,6 +230,7 @@ func ReadGroupSource(id execute.DatasetID, r Reader, readSpec ReadGroupSpec, a e
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
 	src.op = "readGroup"
+	src.pushdowns = readSpec.Pushdowns()
 
 	src.runner = src
 	return src
@@ -270,6 +284,7 @@ func createReadGroupSource(s plan.ProcedureSpec, id execute.DatasetID, a execute
 			ReadFilterSpec: ReadFilterSpec{
 				OrganizationID: orgID,
 				BucketID:       bucketID,
+				Snapshot:       spec.Snapshot,
 				Bounds:         *bounds,
 				Predicate:      filter,
 			},
@@ -299,6 +314,7 @@ func ReadWindowAggregateSource(id execute.DatasetID, r WindowAggregateReader, re
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
 	src.op = "readWindowAggregate"
+	src.pushdowns = readSpec.Pushdowns()
 
 	src.runner = src
 	return src
@@ -359,6 +375,7 @@ func createReadWindowAggregateSource(s plan.ProcedureSpec, id execute.DatasetID,
 			ReadFilterSpec: ReadFilterSpec{
 				OrganizationID: orgID,
 				BucketID:       bucketID,
+				Snapshot:       spec.Snapshot,
 				Bounds:         *bounds,
 				Predicate:      filter,
 			},
@@ -399,6 +416,7 @@ func createReadTagKeysSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID,
 			ReadFilterSpec: ReadFilterSpec{
 				OrganizationID: orgID,
 				BucketID:       bucketID,
+				Snapshot:       spec.Snapshot,
 				Bounds:         *bounds,
 				Predicate:      filter,
 			},
@@ -425,6 +443,7 @@ func ReadTagKeysSource(id execute.DatasetID, r Reader, readSpec ReadTagKeysSpec,
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
 	src.op = "readTagKeys"
+	src.pushdowns = readSpec.Pushdowns()
 
 	src.runner = src
 	return src
@@ -468,6 +487,7 @@ func createReadTagValuesSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID
 			ReadFilterSpec: ReadFilterSpec{
 				OrganizationID: orgID,
 				BucketID:       bucketID,
+				Snapshot:       spec.Snapshot,
 				Bounds:         *bounds,
 				Predicate:      filter,
 			},
@@ -495,6 +515,7 @@ func ReadTagValuesSource(id execute.DatasetID, r Reader, readSpec ReadTagValuesS
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
 	src.op = "readTagValues"
+	src.pushdowns = readSpec.Pushdowns()
 
 	src.runner = src
 	return src
@@ -105,11 +105,25 @@ type ReadFilterSpec struct {
 	OrganizationID platform.ID
 	BucketID       platform.ID
 
+	// Snapshot is the name or ID of the snapshot of the bucket to read,
+	// or empty to read the bucket.
+	Snapshot string
+
 	Bounds execute.Bounds
 
 	Predicate *semantic.FunctionExpression
 }
 
+// Pushdowns returns the operations of the query performed by the storage
+// engine when reading with the spec.
+func (spec ReadFilterSpec) Pushdowns() []string {
+	pushdowns := []string{"range"}
+	if spec.Predicate != nil {
+		pushdowns = append(pushdowns, "filter")
+	}
+	return pushdowns
+}
+
 type ReadGroupSpec struct {
 	ReadFilterSpec
 
@@ -119,15 +133,37 @@ type ReadGroupSpec struct {
 	AggregateMethod string
 }
 
+// Pushdowns returns the operations of the query performed by the storage
+// engine when reading with the spec.
+func (spec ReadGroupSpec) Pushdowns() []string {
+	pushdowns := append(spec.ReadFilterSpec.Pushdowns(), "group")
+	if spec.AggregateMethod != "" {
+		pushdowns = append(pushdowns, spec.AggregateMethod)
+	}
+	return pushdowns
+}
+
 type ReadTagKeysSpec struct {
 	ReadFilterSpec
 }
 
+// Pushdowns returns the operations of the query performed by the storage
+// engine when reading with the spec.
+func (spec ReadTagKeysSpec) Pushdowns() []string {
+	return append(spec.ReadFilterSpec.Pushdowns(), "keys")
+}
+
 type ReadTagValuesSpec struct {
 	ReadFilterSpec
 	TagKey string
 }
 
+// Pushdowns returns the operations of the query performed by the storage
+// engine when reading with the spec.
+func (spec ReadTagValuesSpec) Pushdowns() []string {
+	return append(spec.ReadFilterSpec.Pushdowns(), "distinct")
+}
+
 type Reader interface {
 	ReadFilter(ctx context.Context, spec ReadFilterSpec, alloc *memory.Allocator) (TableIterator, error)
 	ReadGroup(ctx context.Context, spec ReadGroupSpec, alloc *memory.Allocator) (TableIterator, error)
@@ -150,6 +186,12 @@ type ReadWindowAggregateSpec struct {
 	Aggregates  []string
 }
 
+// Pushdowns returns the operations of the query performed by the storage
+// engine when reading with the spec.
+func (spec ReadWindowAggregateSpec) Pushdowns() []string {
+	return append(append(spec.ReadFilterSpec.Pushdowns(), "window"), spec.Aggregates...)
+}
+
 // WindowAggregateCapability describes what is supported by WindowAggregateReader.
 type WindowAggregateCapability struct{}
 
@@ -0,0 +1,61 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+)
+
+func TestReadSpec_Pushdowns(t *testing.T) {
+	filter := influxdb.ReadFilterSpec{Predicate: &semantic.FunctionExpression{}}
+
+	for _, tt := range []struct {
+		name string
+		spec interface{ Pushdowns() []string }
+		want []string
+	}{
+		{
+			name: "range",
+			spec: influxdb.ReadFilterSpec{},
+			want: []string{"range"},
+		},
+		{
+			name: "filter",
+			spec: filter,
+			want: []string{"range", "filter"},
+		},
+		{
+			name: "group",
+			spec: influxdb.ReadGroupSpec{ReadFilterSpec: filter, GroupMode: influxdb.GroupModeBy},
+			want: []string{"range", "filter", "group"},
+		},
+		{
+			name: "group aggregate",
+			spec: influxdb.ReadGroupSpec{GroupMode: influxdb.GroupModeBy, AggregateMethod: "count"},
+			want: []string{"range", "group", "count"},
+		},
+		{
+			name: "window aggregate",
+			spec: influxdb.ReadWindowAggregateSpec{WindowEvery: 10, Aggregates: []string{"sum"}},
+			want: []string{"range", "window", "sum"},
+		},
+		{
+			name: "tag keys",
+			spec: influxdb.ReadTagKeysSpec{ReadFilterSpec: filter},
+			want: []string{"range", "filter", "keys"},
+		},
+		{
+			name: "tag values",
+			spec: influxdb.ReadTagValuesSpec{TagKey: "host"},
+			want: []string{"range", "distinct"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.Pushdowns(); !cmp.Equal(got, tt.want) {
+				t.Errorf("unexpected pushdowns -want/+got:\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
@@ -92,9 +92,18 @@ func (bd *DatabasesDecoder) Decode(ctx context.Context) (flux.Table, error) {
 		RetentionPeriod time.Duration
 	}
 
-	databases := make([]databaseInfo, 0, len(bd.databases))
+	// Find the buckets of each organization at once rather than the bucket of
+	// each mapping. Buckets which may not be read are not found.
+	buckets := make(map[platform.ID]*platform.Bucket)
+	orgs := make(map[platform.ID]bool)
 	for _, db := range bd.databases {
-		bucket, err := bd.deps.BucketLookup.FindBucketByID(ctx, db.BucketID)
+		if orgs[db.OrganizationID] {
+			continue
+		}
+		orgs[db.OrganizationID] = true
+
+		orgID := db.OrganizationID
+		bs, _, err := bd.deps.BucketLookup.FindBuckets(ctx, platform.BucketFilter{OrganizationID: &orgID})
 		if err != nil {
 			code := platform.ErrorCode(err)
 			if code == platform.EUnauthorized || code == platform.EForbidden {
@@ -102,6 +111,17 @@ func (bd *DatabasesDecoder) Decode(ctx context.Context) (flux.Table, error) {
 			}
 			return nil, err
 		}
+		for _, b := range bs {
+			buckets[b.ID] = b
+		}
+	}
+
+	databases := make([]databaseInfo, 0, len(bd.databases))
+	for _, db := range bd.databases {
+		bucket, ok := buckets[db.BucketID]
+		if !ok {
+			continue
+		}
 		databases = append(databases, databaseInfo{
 			DBRPMapping:     db,
 			RetentionPeriod: bucket.RetentionPeriod,
@@ -3,7 +3,10 @@ package stdlib
 // Import all stdlib packages
 import (
 	_ "github.com/influxdata/influxdb/v2/query/stdlib/experimental"
+	_ "github.com/influxdata/influxdb/v2/query/stdlib/http"
 	_ "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	_ "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb/email"
+	_ "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb/slackapp"
 	_ "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb/v1"
 	_ "github.com/influxdata/influxdb/v2/query/stdlib/testing"
 )
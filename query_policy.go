@@ -0,0 +1,271 @@
+package influxdb
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+const (
+	OpFindQueryPolicyByID         = "FindQueryPolicyByID"
+	OpFindQueryPolicies           = "FindQueryPolicies"
+	OpCreateQueryPolicy           = "CreateQueryPolicy"
+	OpUpdateQueryPolicy           = "UpdateQueryPolicy"
+	OpDeleteQueryPolicy           = "DeleteQueryPolicy"
+	OpRecordQueryPolicyEvaluation = "RecordQueryPolicyEvaluation"
+	OpFindQueryPolicyEvaluations  = "FindQueryPolicyEvaluations"
+)
+
+// QueryPolicyService manages the policies allowing and denying queries, and
+// records the evaluations of the policies which applied to queries as their
+// audit trail.
+type QueryPolicyService interface {
+	// FindQueryPolicyByID returns a single policy by ID.
+	FindQueryPolicyByID(ctx context.Context, id ID) (*QueryPolicy, error)
+
+	// FindQueryPolicies returns the policies matching filter, oldest first.
+	FindQueryPolicies(ctx context.Context, filter QueryPolicyFilter) ([]*QueryPolicy, error)
+
+	// CreateQueryPolicy creates a policy, setting its ID and creation time.
+	CreateQueryPolicy(ctx context.Context, p *QueryPolicy) error
+
+	// UpdateQueryPolicy replaces the policy with the ID of p by p.
+	UpdateQueryPolicy(ctx context.Context, p *QueryPolicy) error
+
+	// DeleteQueryPolicy removes a policy. Its evaluations are kept.
+	DeleteQueryPolicy(ctx context.Context, id ID) error
+
+	// RecordQueryPolicyEvaluation records the evaluation of a policy
+	// applied to a query, setting its ID and time.
+	RecordQueryPolicyEvaluation(ctx context.Context, e *QueryPolicyEvaluation) error
+
+	// FindQueryPolicyEvaluations returns the evaluations matching filter,
+	// from the oldest to the latest unless opts are descending.
+	FindQueryPolicyEvaluations(ctx context.Context, filter QueryPolicyEvaluationFilter, opts ...FindOptions) ([]*QueryPolicyEvaluation, error)
+}
+
+// QueryPolicyEffect is the effect of a policy on the queries it applies to.
+type QueryPolicyEffect string
+
+const (
+	// QueryPolicyAllow exempts the queries it applies to from the deny
+	// policies.
+	QueryPolicyAllow QueryPolicyEffect = "allow"
+	// QueryPolicyDeny rejects the queries it applies to, or aborts those
+	// returning more than its maximum number of rows if it has one.
+	QueryPolicyDeny QueryPolicyEffect = "deny"
+)
+
+// QueryPolicy allows or denies the queries matching all of its conditions.
+// A policy without conditions applies to every query of its organization,
+// or of every organization if it has none.
+type QueryPolicy struct {
+	ID ID `json:"id,omitempty"`
+	// OrgID is the organization the policy applies to. A policy without
+	// one applies to every organization.
+	OrgID       *ID               `json:"orgID,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Effect      QueryPolicyEffect `json:"effect"`
+	// BucketIDs restricts the policy to the queries reading any of the
+	// buckets.
+	BucketIDs []ID `json:"bucketIDs,omitempty"`
+	// Networks restricts the policy to the queries sent from any of the
+	// networks, in CIDR notation, or from none of them if OutsideNetworks.
+	Networks        []string `json:"networks,omitempty"`
+	OutsideNetworks bool     `json:"outsideNetworks,omitempty"`
+	// MaxRows is the number of rows above which a deny policy aborts a
+	// query. A deny policy without one rejects the query before it runs.
+	MaxRows   int64     `json:"maxRows,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Valid returns an error if the policy is invalid.
+func (p *QueryPolicy) Valid() error {
+	if p.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "query policy name is required",
+		}
+	}
+	if p.Effect != QueryPolicyAllow && p.Effect != QueryPolicyDeny {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "query policy effect must be allow or deny",
+		}
+	}
+	if p.OrgID != nil && !p.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "query policy organization ID is invalid",
+		}
+	}
+	for _, id := range p.BucketIDs {
+		if !id.Valid() {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "query policy bucket ID is invalid",
+			}
+		}
+	}
+	for _, n := range p.Networks {
+		if _, _, err := net.ParseCIDR(n); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "query policy network must be in CIDR notation",
+				Err:  err,
+			}
+		}
+	}
+	if p.OutsideNetworks && len(p.Networks) == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "query policy outside networks requires networks",
+		}
+	}
+	if p.MaxRows < 0 || p.MaxRows > 0 && p.Effect != QueryPolicyDeny {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "query policy max rows must be positive and of a deny policy",
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the policy applies to the query r.
+func (p *QueryPolicy) Matches(r QueryPolicyRequest) bool {
+	if p.OrgID != nil && *p.OrgID != r.OrgID {
+		return false
+	}
+	if len(p.BucketIDs) > 0 && !p.readsAnyBucket(r.BucketIDs) {
+		return false
+	}
+	if len(p.Networks) > 0 && p.fromAnyNetwork(r.RemoteIP) == p.OutsideNetworks {
+		return false
+	}
+	return true
+}
+
+func (p *QueryPolicy) readsAnyBucket(ids []ID) bool {
+	for _, pid := range p.BucketIDs {
+		for _, id := range ids {
+			if pid == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fromAnyNetwork returns true if ip is in any of the networks of p. An
+// unknown ip is in none of them.
+func (p *QueryPolicy) fromAnyNetwork(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range p.Networks {
+		if _, ipnet, err := net.ParseCIDR(n); err == nil && ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryPolicyFilter represents a set of filters that restrict the returned
+// policies. OrgID selects the policies of the organization and those of
+// every organization.
+type QueryPolicyFilter struct {
+	OrgID *ID
+}
+
+// Match returns true if the policy matches the filter.
+func (f QueryPolicyFilter) Match(p *QueryPolicy) bool {
+	return f.OrgID == nil || p.OrgID == nil || *p.OrgID == *f.OrgID
+}
+
+// QueryPolicyRequest is what the policies evaluated for a query know of it.
+type QueryPolicyRequest struct {
+	OrgID  ID
+	UserID ID
+	// BucketIDs are the buckets read by the query, as far as they are known.
+	BucketIDs []ID
+	// RemoteIP is the address the query was sent from, or nil if unknown.
+	RemoteIP net.IP
+}
+
+// QueryPolicyDecision is the result of the evaluation of the policies for a
+// query.
+type QueryPolicyDecision struct {
+	Effect QueryPolicyEffect
+	// PolicyID is the policy deciding the effect, or the deny policy
+	// limiting the rows of an allowed query. It is invalid if no policy
+	// applies to the query.
+	PolicyID ID
+	// MaxRows is the number of rows above which the query is aborted, or 0
+	// if its rows are not limited.
+	MaxRows int64
+}
+
+// EvaluateQueryPolicies decides whether the policies allow the query r. A
+// query to which an allow policy applies is allowed. Otherwise, a query to
+// which a deny policy without a maximum number of rows applies is denied,
+// and the rows of a query to which the other deny policies apply are limited
+// to the lowest of their maximums. Queries to which no policy applies are
+// allowed.
+func EvaluateQueryPolicies(ps []*QueryPolicy, r QueryPolicyRequest) QueryPolicyDecision {
+	var deny, limit *QueryPolicy
+	for _, p := range ps {
+		if !p.Matches(r) {
+			continue
+		}
+		switch {
+		case p.Effect == QueryPolicyAllow:
+			return QueryPolicyDecision{Effect: QueryPolicyAllow, PolicyID: p.ID}
+		case p.MaxRows == 0:
+			if deny == nil {
+				deny = p
+			}
+		case limit == nil || p.MaxRows < limit.MaxRows:
+			limit = p
+		}
+	}
+	if deny != nil {
+		return QueryPolicyDecision{Effect: QueryPolicyDeny, PolicyID: deny.ID}
+	}
+	if limit != nil {
+		return QueryPolicyDecision{Effect: QueryPolicyAllow, PolicyID: limit.ID, MaxRows: limit.MaxRows}
+	}
+	return QueryPolicyDecision{Effect: QueryPolicyAllow}
+}
+
+// QueryPolicyEvaluation records the evaluation of a policy which applied to
+// a query. A query allowed with a limit of its rows which then exceeds it is
+// recorded again, as denied.
+type QueryPolicyEvaluation struct {
+	ID         ID                `json:"id"`
+	PolicyID   ID                `json:"policyID"`
+	Effect     QueryPolicyEffect `json:"effect"`
+	Reason     string            `json:"reason,omitempty"`
+	OrgID      ID                `json:"orgID"`
+	UserID     ID                `json:"userID,omitempty"`
+	BucketIDs  []ID              `json:"bucketIDs,omitempty"`
+	RemoteAddr string            `json:"remoteAddr,omitempty"`
+	Query      string            `json:"query,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+// QueryPolicyEvaluationFilter represents a set of filters that restrict the
+// returned evaluations.
+type QueryPolicyEvaluationFilter struct {
+	OrgID    *ID
+	PolicyID *ID
+	Effect   *QueryPolicyEffect
+}
+
+// Match returns true if the evaluation matches the filter.
+func (f QueryPolicyEvaluationFilter) Match(e *QueryPolicyEvaluation) bool {
+	return (f.OrgID == nil || *f.OrgID == e.OrgID) &&
+		(f.PolicyID == nil || *f.PolicyID == e.PolicyID) &&
+		(f.Effect == nil || *f.Effect == e.Effect)
+}
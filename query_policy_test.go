@@ -0,0 +1,109 @@
+package influxdb_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestEvaluateQueryPolicies(t *testing.T) {
+	org := influxdb.ID(1)
+	otherOrg := influxdb.ID(2)
+	secret := influxdb.ID(10)
+	corporate := []string{"10.0.0.0/8"}
+
+	denyOutside := &influxdb.QueryPolicy{ID: 100, Name: "secret", Effect: influxdb.QueryPolicyDeny, BucketIDs: []influxdb.ID{secret}, Networks: corporate, OutsideNetworks: true}
+	limitExports := &influxdb.QueryPolicy{ID: 101, OrgID: &org, Name: "exports", Effect: influxdb.QueryPolicyDeny, MaxRows: 1000}
+	smallerLimit := &influxdb.QueryPolicy{ID: 102, OrgID: &org, Name: "smaller exports", Effect: influxdb.QueryPolicyDeny, MaxRows: 10}
+	allowAudit := &influxdb.QueryPolicy{ID: 103, Name: "audit", Effect: influxdb.QueryPolicyAllow, Networks: []string{"192.0.2.0/24"}}
+
+	tests := []struct {
+		name string
+		ps   []*influxdb.QueryPolicy
+		r    influxdb.QueryPolicyRequest
+		want influxdb.QueryPolicyDecision
+	}{
+		{
+			name: "no policy applies",
+			ps:   []*influxdb.QueryPolicy{denyOutside},
+			r:    influxdb.QueryPolicyRequest{OrgID: org, BucketIDs: []influxdb.ID{11}, RemoteIP: net.ParseIP("192.0.2.1")},
+			want: influxdb.QueryPolicyDecision{Effect: influxdb.QueryPolicyAllow},
+		},
+		{
+			name: "bucket read from outside the networks",
+			ps:   []*influxdb.QueryPolicy{denyOutside},
+			r:    influxdb.QueryPolicyRequest{OrgID: org, BucketIDs: []influxdb.ID{11, secret}, RemoteIP: net.ParseIP("192.0.2.1")},
+			want: influxdb.QueryPolicyDecision{Effect: influxdb.QueryPolicyDeny, PolicyID: 100},
+		},
+		{
+			name: "bucket read from an unknown address",
+			ps:   []*influxdb.QueryPolicy{denyOutside},
+			r:    influxdb.QueryPolicyRequest{OrgID: org, BucketIDs: []influxdb.ID{secret}},
+			want: influxdb.QueryPolicyDecision{Effect: influxdb.QueryPolicyDeny, PolicyID: 100},
+		},
+		{
+			name: "bucket read from inside the networks",
+			ps:   []*influxdb.QueryPolicy{denyOutside},
+			r:    influxdb.QueryPolicyRequest{OrgID: org, BucketIDs: []influxdb.ID{secret}, RemoteIP: net.ParseIP("10.2.3.4")},
+			want: influxdb.QueryPolicyDecision{Effect: influxdb.QueryPolicyAllow},
+		},
+		{
+			name: "lowest row limit",
+			ps:   []*influxdb.QueryPolicy{limitExports, smallerLimit},
+			r:    influxdb.QueryPolicyRequest{OrgID: org},
+			want: influxdb.QueryPolicyDecision{Effect: influxdb.QueryPolicyAllow, PolicyID: 102, MaxRows: 10},
+		},
+		{
+			name: "row limit of another organization",
+			ps:   []*influxdb.QueryPolicy{limitExports},
+			r:    influxdb.QueryPolicyRequest{OrgID: otherOrg},
+			want: influxdb.QueryPolicyDecision{Effect: influxdb.QueryPolicyAllow},
+		},
+		{
+			name: "deny takes precedence over row limits",
+			ps:   []*influxdb.QueryPolicy{limitExports, denyOutside},
+			r:    influxdb.QueryPolicyRequest{OrgID: org, BucketIDs: []influxdb.ID{secret}},
+			want: influxdb.QueryPolicyDecision{Effect: influxdb.QueryPolicyDeny, PolicyID: 100},
+		},
+		{
+			name: "allow exempts from deny",
+			ps:   []*influxdb.QueryPolicy{denyOutside, limitExports, allowAudit},
+			r:    influxdb.QueryPolicyRequest{OrgID: org, BucketIDs: []influxdb.ID{secret}, RemoteIP: net.ParseIP("192.0.2.5")},
+			want: influxdb.QueryPolicyDecision{Effect: influxdb.QueryPolicyAllow, PolicyID: 103},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := influxdb.EvaluateQueryPolicies(tt.ps, tt.r); got != tt.want {
+				t.Errorf("got decision %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryPolicy_Valid(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     influxdb.QueryPolicy
+		valid bool
+	}{
+		{name: "deny", p: influxdb.QueryPolicy{Name: "p", Effect: influxdb.QueryPolicyDeny, Networks: []string{"10.0.0.0/8"}}, valid: true},
+		{name: "no name", p: influxdb.QueryPolicy{Effect: influxdb.QueryPolicyDeny}},
+		{name: "unknown effect", p: influxdb.QueryPolicy{Name: "p", Effect: "block"}},
+		{name: "invalid network", p: influxdb.QueryPolicy{Name: "p", Effect: influxdb.QueryPolicyDeny, Networks: []string{"10.0.0.1"}}},
+		{name: "outside no networks", p: influxdb.QueryPolicy{Name: "p", Effect: influxdb.QueryPolicyDeny, OutsideNetworks: true}},
+		{name: "allow with max rows", p: influxdb.QueryPolicy{Name: "p", Effect: influxdb.QueryPolicyAllow, MaxRows: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.Valid()
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tt.valid && influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Errorf("expected the policy to be invalid, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrReportNotFound is the error for a missing report.
+const ErrReportNotFound = "report not found"
+
+const (
+	OpFindReportByID     = "FindReportByID"
+	OpFindReports        = "FindReports"
+	OpCreateReport       = "CreateReport"
+	OpUpdateReport       = "UpdateReport"
+	OpUpdateReportStatus = "UpdateReportStatus"
+	OpDeleteReport       = "DeleteReport"
+)
+
+// Formats reports are rendered as.
+const (
+	ReportFormatCSV = "csv"
+	ReportFormatPDF = "pdf"
+)
+
+// Targets reports are delivered to.
+const (
+	ReportDeliveryEmail   = "email"
+	ReportDeliveryWebhook = "webhook"
+	ReportDeliveryS3      = "s3"
+)
+
+// MinReportEvery is the shortest interval reports are generated at.
+const MinReportEvery = time.Minute
+
+// ReportService manages the scheduled reports of organizations.
+type ReportService interface {
+	// FindReportByID returns a single report by ID.
+	FindReportByID(ctx context.Context, id ID) (*Report, error)
+
+	// FindReports returns a list of reports that match filter.
+	FindReports(ctx context.Context, filter ReportFilter) ([]*Report, error)
+
+	// CreateReport creates a new report and sets r.ID with the new identifier.
+	CreateReport(ctx context.Context, r *Report) error
+
+	// UpdateReport updates a single report with changeset.
+	UpdateReport(ctx context.Context, id ID, upd ReportUpdate) (*Report, error)
+
+	// UpdateReportStatus replaces the status of a report.
+	UpdateReportStatus(ctx context.Context, id ID, status ReportStatus) error
+
+	// DeleteReport removes a report by ID.
+	DeleteReport(ctx context.Context, id ID) error
+}
+
+// Report is generated on a schedule from the results of the queries of a
+// dashboard, or of its own queries, over the range of time up to each run,
+// rendered as CSV or PDF and delivered by email, to a webhook or to S3.
+type Report struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// AuthorizationID is the authorization the queries of the report run
+	// with.
+	AuthorizationID ID `json:"authorizationID"`
+
+	// Every is how often the report is generated.
+	Every Duration `json:"every"`
+	// Range is how far back from each run the queries read.
+	Range Duration `json:"range"`
+
+	// DashboardID is the dashboard whose cells are reported. Otherwise the
+	// queries of the report are.
+	DashboardID ID            `json:"dashboardID,omitempty"`
+	Queries     []ReportQuery `json:"queries,omitempty"`
+
+	// Format is one of csv or pdf.
+	Format   string         `json:"format"`
+	Delivery ReportDelivery `json:"delivery"`
+
+	Status ReportStatus `json:"status"`
+	CRUDLog
+}
+
+// ReportQuery is a named Flux query of a report.
+type ReportQuery struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// ReportDelivery is where a report is delivered.
+type ReportDelivery struct {
+	// Type is one of email, webhook or s3.
+	Type string `json:"type"`
+
+	// To are the addresses an email report is sent to, through the SMTP
+	// server of the instance.
+	To []string `json:"to,omitempty"`
+
+	// URL is the http or https URL a webhook report is posted to.
+	URL string `json:"url,omitempty"`
+
+	// Bucket, Region and Prefix are where a report is uploaded to S3, with
+	// the credentials of the AWS environment of the instance.
+	Bucket string `json:"bucket,omitempty"`
+	Region string `json:"region,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// ReportStatus reports the runs of a report.
+type ReportStatus struct {
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	Error     string     `json:"error,omitempty"`
+
+	// LastDeliveredAt is when the report was last delivered successfully.
+	LastDeliveredAt *time.Time `json:"lastDeliveredAt,omitempty"`
+}
+
+// Due reports whether the report should be generated at now.
+func (r *Report) Due(now time.Time) bool {
+	return r.Status.LastRunAt == nil || !now.Before(r.Status.LastRunAt.Add(r.Every.Duration))
+}
+
+// Valid returns an error if the report is invalid.
+func (r *Report) Valid() error {
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "report name is required",
+		}
+	}
+	if !r.AuthorizationID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "authorization ID is required",
+		}
+	}
+	if r.Every.Duration < MinReportEvery {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("report must be generated every %s or more", MinReportEvery),
+		}
+	}
+	if r.Range.Duration <= 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "report range must be positive",
+		}
+	}
+	if r.DashboardID.Valid() == (len(r.Queries) > 0) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "report must have either a dashboard or queries",
+		}
+	}
+	for i, q := range r.Queries {
+		if strings.TrimSpace(q.Text) == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("report query %d must have text", i),
+			}
+		}
+	}
+	switch r.Format {
+	case ReportFormatCSV, ReportFormatPDF:
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  "report format must be one of csv or pdf",
+		}
+	}
+	return r.Delivery.Valid()
+}
+
+// Valid returns an error if the delivery is invalid.
+func (d *ReportDelivery) Valid() error {
+	switch d.Type {
+	case ReportDeliveryEmail:
+		if len(d.To) == 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "email report requires at least one to address",
+			}
+		}
+		for _, a := range d.To {
+			if _, err := mail.ParseAddress(a); err != nil {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("invalid email address %q", a),
+					Err:  err,
+				}
+			}
+		}
+	case ReportDeliveryWebhook:
+		u, err := url.Parse(d.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "webhook report requires an http or https url",
+			}
+		}
+	case ReportDeliveryS3:
+		if d.Bucket == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "s3 report requires a bucket",
+			}
+		}
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  "report delivery type must be one of email, webhook or s3",
+		}
+	}
+	return nil
+}
+
+// ReportUpdate is the changeset of a report.
+type ReportUpdate struct {
+	Name        *string         `json:"name,omitempty"`
+	Description *string         `json:"description,omitempty"`
+	Every       *Duration       `json:"every,omitempty"`
+	Range       *Duration       `json:"range,omitempty"`
+	DashboardID *ID             `json:"dashboardID,omitempty"`
+	Queries     []ReportQuery   `json:"queries"`
+	Format      *string         `json:"format,omitempty"`
+	Delivery    *ReportDelivery `json:"delivery,omitempty"`
+}
+
+// Apply applies the changeset to r. Setting the queries clears the
+// dashboard, and setting the dashboard clears the queries.
+func (u ReportUpdate) Apply(r *Report) {
+	if u.Name != nil {
+		r.Name = *u.Name
+	}
+	if u.Description != nil {
+		r.Description = *u.Description
+	}
+	if u.Every != nil {
+		r.Every = *u.Every
+	}
+	if u.Range != nil {
+		r.Range = *u.Range
+	}
+	if u.DashboardID != nil {
+		r.DashboardID = *u.DashboardID
+		r.Queries = nil
+	}
+	if u.Queries != nil {
+		r.Queries = u.Queries
+		r.DashboardID = 0
+	}
+	if u.Format != nil {
+		r.Format = *u.Format
+	}
+	if u.Delivery != nil {
+		r.Delivery = *u.Delivery
+	}
+}
+
+// ReportFilter represents a set of filters that restrict the returned
+// reports.
+type ReportFilter struct {
+	OrgID *ID
+}
+
+// Match returns true if the report matches the filter.
+func (f ReportFilter) Match(r *Report) bool {
+	return f.OrgID == nil || *f.OrgID == r.OrgID
+}
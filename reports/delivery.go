@@ -0,0 +1,218 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb/email"
+)
+
+// Delivery delivers the rendered reports of a type of delivery.
+type Delivery interface {
+	Deliver(ctx context.Context, rep *influxdb.Report, f *File) error
+}
+
+// EmailDelivery mails reports as an attachment through the SMTP server of
+// the instance.
+type EmailDelivery struct {
+	smtp    influxdb.SMTPConfigService
+	secrets email.SecretLoader
+	now     func() time.Time
+}
+
+// NewEmailDelivery returns a delivery mailing reports through the SMTP
+// server of smtp, with its password in secrets.
+func NewEmailDelivery(smtp influxdb.SMTPConfigService, secrets email.SecretLoader) *EmailDelivery {
+	return &EmailDelivery{
+		smtp:    smtp,
+		secrets: secrets,
+		now:     time.Now,
+	}
+}
+
+// Deliver mails f to the addresses of the delivery of rep.
+func (d *EmailDelivery) Deliver(ctx context.Context, rep *influxdb.Report, f *File) error {
+	c, err := d.smtp.FindSMTPConfig(ctx)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  influxdb.ErrSMTPConfigNotFound,
+			}
+		}
+		return err
+	}
+	var password string
+	if c.Password.Key != "" {
+		if password, err = d.secrets.LoadSecret(ctx, c.ID, c.Password.Key); err != nil {
+			return err
+		}
+	}
+
+	rcpts := make([]*mail.Address, 0, len(rep.Delivery.To))
+	for _, to := range rep.Delivery.To {
+		a, err := mail.ParseAddress(to)
+		if err != nil {
+			return err
+		}
+		rcpts = append(rcpts, a)
+	}
+	return email.Send(ctx, c, password, rcpts, func(from *mail.Address) ([]byte, error) {
+		return mailMessage(from, rcpts, rep, f, d.now())
+	})
+}
+
+// mailMessage returns the message with f attached sent by from to rcpts at
+// date, with CRLF line endings.
+func mailMessage(from *mail.Address, rcpts []*mail.Address, rep *influxdb.Report, f *File, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	to := make([]string, len(rcpts))
+	for i, a := range rcpts {
+		to[i] = a.String()
+	}
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(rep.Name)
+	for _, h := range [][2]string{
+		{"From", from.String()},
+		{"To", strings.Join(to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()})},
+	} {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+
+	body := fmt.Sprintf("The %s report %q generated at %s is attached.\r\n", rep.Format, rep.Name, date.UTC().Format(time.RFC3339))
+	if rep.Description != "" {
+		body += "\r\n" + rep.Description + "\r\n"
+	}
+	pw, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=utf-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := pw.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+
+	pw, err = mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {f.ContentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": f.Name})},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Lines of base64 must not be longer than 76 characters.
+	enc := base64.StdEncoding.EncodeToString(f.Data)
+	for len(enc) > 0 {
+		n := 76
+		if len(enc) < n {
+			n = len(enc)
+		}
+		if _, err := fmt.Fprintf(pw, "%s\r\n", enc[:n]); err != nil {
+			return nil, err
+		}
+		enc = enc[n:]
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DefaultWebhookTimeout is the default timeout of posting a report to a
+// webhook.
+const DefaultWebhookTimeout = 30 * time.Second
+
+// WebhookDelivery posts reports to the URL of their delivery.
+type WebhookDelivery struct {
+	client *http.Client
+}
+
+// NewWebhookDelivery returns a delivery posting reports to webhooks.
+func NewWebhookDelivery() *WebhookDelivery {
+	return &WebhookDelivery{
+		client: &http.Client{Timeout: DefaultWebhookTimeout},
+	}
+}
+
+// Deliver posts f to the URL of the delivery of rep. The request identifies
+// the report and names the file, and any response other than 2xx fails.
+func (d *WebhookDelivery) Deliver(ctx context.Context, rep *influxdb.Report, f *File) error {
+	req, err := http.NewRequest(http.MethodPost, rep.Delivery.URL, bytes.NewReader(f.Data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", f.ContentType)
+	req.Header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": f.Name}))
+	req.Header.Set("X-Influxdb-Report-Id", rep.ID.String())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+// S3Delivery uploads reports to S3 with the credentials of the AWS
+// environment of the instance, such as its environment variables, shared
+// config or instance role.
+type S3Delivery struct {
+	once sync.Once
+	sess *session.Session
+	err  error
+}
+
+// NewS3Delivery returns a delivery uploading reports to S3.
+func NewS3Delivery() *S3Delivery {
+	return &S3Delivery{}
+}
+
+// Deliver uploads f to the bucket of the delivery of rep, under its prefix.
+func (d *S3Delivery) Deliver(ctx context.Context, rep *influxdb.Report, f *File) error {
+	d.once.Do(func() {
+		d.sess, d.err = session.NewSessionWithOptions(session.Options{
+			SharedConfigState: session.SharedConfigEnable,
+		})
+	})
+	if d.err != nil {
+		return d.err
+	}
+
+	cfg := aws.NewConfig()
+	if rep.Delivery.Region != "" {
+		cfg = cfg.WithRegion(rep.Delivery.Region)
+	}
+	_, err := s3.New(d.sess, cfg).PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(rep.Delivery.Bucket),
+		Key:         aws.String(path.Join(rep.Delivery.Prefix, f.Name)),
+		Body:        bytes.NewReader(f.Data),
+		ContentType: aws.String(f.ContentType),
+	})
+	return err
+}
@@ -0,0 +1,55 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWritePDF(t *testing.T) {
+	lines := make([]string, pdfPageLines+1)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	lines[0] = "cpu (usage) \\ " + strings.Repeat("x", pdfLineChars)
+	lines[1] = "température ✓"
+
+	var buf bytes.Buffer
+	if err := writePDF(&buf, "Weekly capacity", lines); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.String()
+
+	if !strings.HasPrefix(doc, "%PDF-1.4\n") || !strings.HasSuffix(doc, "%%EOF\n") {
+		t.Fatal("expected a PDF document")
+	}
+	if !strings.Contains(doc, "/Count 2") {
+		t.Error("expected the lines to span two pages")
+	}
+	if !strings.Contains(doc, "/Title (Weekly capacity)") {
+		t.Error("expected the title of the document")
+	}
+	if !strings.Contains(doc, "(cpu \\(usage\\) \\\\ xxx") || strings.Contains(doc, strings.Repeat("x", pdfLineChars-13)) {
+		t.Error("expected the first line to be escaped and truncated")
+	}
+	if !strings.Contains(doc, "(temp\\351rature ?)") {
+		t.Error("expected Latin-1 characters to be encoded and others replaced")
+	}
+
+	// Every entry of the cross-reference table points at its object.
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(doc)
+	if m == nil {
+		t.Fatal("expected a startxref")
+	}
+	xref, _ := strconv.Atoi(m[1])
+	entries := strings.Split(doc[xref:], "\n")[3:]
+	for i := 1; strings.HasSuffix(entries[i-1], " n "); i++ {
+		off, _ := strconv.Atoi(entries[i-1][:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i); !strings.HasPrefix(doc[off:], want) {
+			t.Errorf("expected object %d at offset %d", i, off)
+		}
+	}
+}
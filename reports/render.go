@@ -0,0 +1,204 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2"
+)
+
+// File is a rendered report.
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// queryResult is the results of a query of a report, as annotated CSV.
+type queryResult struct {
+	name string
+	csv  []byte
+}
+
+// render renders the results of the queries of rep in [start, stop) in the
+// format of rep.
+func render(rep *influxdb.Report, start, stop time.Time, results []queryResult) (*File, error) {
+	f := &File{Name: fileName(rep, stop)}
+	var buf bytes.Buffer
+	switch rep.Format {
+	case influxdb.ReportFormatPDF:
+		f.ContentType = "application/pdf"
+		lines, err := reportLines(rep, start, stop, results)
+		if err != nil {
+			return nil, err
+		}
+		if err := writePDF(&buf, rep.Name, lines); err != nil {
+			return nil, err
+		}
+	default:
+		// The results of each query are separated by an empty line, which
+		// the decoders of annotated CSV read as separate results.
+		f.ContentType = "text/csv; charset=utf-8"
+		for i, res := range results {
+			if i > 0 {
+				buf.WriteString("\r\n")
+			}
+			buf.Write(res.csv)
+		}
+	}
+	f.Data = buf.Bytes()
+	return f, nil
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fileName returns the name of the file of rep generated at t, such as
+// weekly-capacity-20200108T000000Z.pdf.
+func fileName(rep *influxdb.Report, t time.Time) string {
+	name := strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(rep.Name), "-"), "-")
+	if name == "" {
+		name = rep.ID.String()
+	}
+	return fmt.Sprintf("%s-%s.%s", name, t.UTC().Format("20060102T150405Z"), rep.Format)
+}
+
+// reportLines returns the text of the PDF of a report: its name and range,
+// followed by a table for each table of the results of each query.
+func reportLines(rep *influxdb.Report, start, stop time.Time, results []queryResult) ([]string, error) {
+	lines := []string{
+		rep.Name,
+		fmt.Sprintf("%s to %s", start.Format(time.RFC3339), stop.Format(time.RFC3339)),
+	}
+	if rep.Description != "" {
+		lines = append(lines, rep.Description)
+	}
+
+	dec := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	for _, res := range results {
+		lines = append(lines, "", "== "+res.name+" ==")
+		itr, err := dec.Decode(ioutil.NopCloser(bytes.NewReader(res.csv)))
+		if err != nil {
+			return nil, err
+		}
+		var tables int
+		for itr.More() {
+			if err := itr.Next().Tables().Do(func(tbl flux.Table) error {
+				tl, err := tableLines(tbl)
+				if err != nil {
+					return err
+				}
+				lines = append(lines, "")
+				lines = append(lines, tl...)
+				tables++
+				return nil
+			}); err != nil {
+				itr.Release()
+				return nil, err
+			}
+		}
+		itr.Release()
+		if err := itr.Err(); err != nil {
+			return nil, err
+		}
+		if tables == 0 {
+			lines = append(lines, "", "(no results)")
+		}
+	}
+	return lines, nil
+}
+
+// tableLines returns a line with the group key of tbl, followed by its other
+// columns aligned, one row per line. The start and stop of the range are
+// left out of the group key, since they are those of the report.
+func tableLines(tbl flux.Table) ([]string, error) {
+	key := tbl.Key()
+	var kvs []string
+	for j, c := range key.Cols() {
+		if c.Label == execute.DefaultStartColLabel || c.Label == execute.DefaultStopColLabel {
+			continue
+		}
+		kvs = append(kvs, c.Label+"="+formatValue(key.Value(j)))
+	}
+
+	var cols []int
+	header := []string{}
+	for j, c := range tbl.Cols() {
+		if key.HasCol(c.Label) {
+			continue
+		}
+		cols = append(cols, j)
+		header = append(header, c.Label)
+	}
+	rows := [][]string{header}
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			row := make([]string, len(cols))
+			for k, j := range cols {
+				row[k] = formatValue(execute.ValueForRow(cr, i, j))
+			}
+			rows = append(rows, row)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	widths := make([]int, len(cols))
+	for _, row := range rows {
+		for k, v := range row {
+			if n := len([]rune(v)); n > widths[k] {
+				widths[k] = n
+			}
+		}
+	}
+	var lines []string
+	if len(kvs) > 0 {
+		lines = append(lines, strings.Join(kvs, " "))
+	}
+	for _, row := range rows {
+		var b strings.Builder
+		for k, v := range row {
+			if k > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(v)
+			if k < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[k]-len([]rune(v))))
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return lines, nil
+}
+
+func formatValue(v values.Value) string {
+	if v == nil || v.IsNull() {
+		return ""
+	}
+	switch v.Type() {
+	case semantic.String:
+		return v.Str()
+	case semantic.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case semantic.UInt:
+		return strconv.FormatUint(v.UInt(), 10)
+	case semantic.Float:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case semantic.Bool:
+		return strconv.FormatBool(v.Bool())
+	case semantic.Time:
+		return v.Time().Time().UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
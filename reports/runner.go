@@ -0,0 +1,256 @@
+// Package reports generates scheduled reports and delivers them.
+package reports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+// checkInterval is how often reports are checked to see if they are due.
+var checkInterval = time.Minute
+
+// reportWidth is the width in pixels of the chart the window period of the
+// queries of dashboards is set for, like it is when viewing the dashboard.
+const reportWidth = influxdb.DefaultCellRenderWidth
+
+// Runner generates each report every time it is due, and delivers it.
+//
+// A run queries the range of the report up to the time of the run with the
+// queries of the cells of its dashboard, or with its own queries, using the
+// authorization of the report, so it only reads what the authorization can.
+// The results are rendered as annotated CSV, or as a PDF of text tables, and
+// delivered by the delivery of the type of the report. The status of the
+// report reports the last run and the last successful delivery.
+type Runner struct {
+	log        *zap.Logger
+	reports    influxdb.ReportService
+	dashboards influxdb.DashboardService
+	auths      influxdb.AuthorizationService
+	qs         query.ProxyQueryService
+	deliveries map[string]Delivery
+	now        func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner returns a Runner generating the reports of reports, and
+// delivering them with the delivery of their type in deliveries.
+func NewRunner(log *zap.Logger, reports influxdb.ReportService, dashboards influxdb.DashboardService, auths influxdb.AuthorizationService, qs query.ProxyQueryService, deliveries map[string]Delivery) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		log:        log,
+		reports:    reports,
+		dashboards: dashboards,
+		auths:      auths,
+		qs:         qs,
+		deliveries: deliveries,
+		now:        time.Now,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Open starts generating reports as they become due.
+func (r *Runner) Open(ctx context.Context) error {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			if err := r.runDue(r.ctx); err != nil {
+				r.log.Error("Failed to run reports", zap.Error(err))
+			}
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops generating reports, and waits for the running report to finish.
+func (r *Runner) Close() error {
+	r.cancel()
+	r.wg.Wait()
+	return nil
+}
+
+func (r *Runner) runDue(ctx context.Context) error {
+	rs, err := r.reports.FindReports(ctx, influxdb.ReportFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, rep := range rs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !rep.Due(r.now()) {
+			continue
+		}
+		if err := r.Run(ctx, rep); err != nil {
+			r.log.Info("Report failed",
+				zap.Stringer("report_id", rep.ID),
+				zap.Stringer("org_id", rep.OrgID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Run generates and delivers rep, and updates its status.
+func (r *Runner) Run(ctx context.Context, rep *influxdb.Report) error {
+	now := r.now().UTC()
+	status := influxdb.ReportStatus{
+		LastRunAt:       &now,
+		LastDeliveredAt: rep.Status.LastDeliveredAt,
+	}
+
+	err := r.run(ctx, rep, now)
+	if err == nil {
+		status.LastDeliveredAt = &now
+	} else {
+		status.Error = err.Error()
+	}
+	rep.Status = status
+	if uerr := r.reports.UpdateReportStatus(ctx, rep.ID, status); uerr != nil {
+		return uerr
+	}
+	return err
+}
+
+func (r *Runner) run(ctx context.Context, rep *influxdb.Report, now time.Time) error {
+	d, ok := r.deliveries[rep.Delivery.Type]
+	if !ok {
+		return fmt.Errorf("report delivery %q is not available", rep.Delivery.Type)
+	}
+
+	start := now.Add(-rep.Range.Duration)
+	results, err := r.query(ctx, rep, start, now)
+	if err != nil {
+		return err
+	}
+	f, err := render(rep, start, now, results)
+	if err != nil {
+		return err
+	}
+	if err := d.Deliver(ctx, rep, f); err != nil {
+		return fmt.Errorf("failed to deliver report: %v", err)
+	}
+	return nil
+}
+
+// query runs the queries of rep over [start, stop) with the authorization of
+// rep, and returns their results.
+func (r *Runner) query(ctx context.Context, rep *influxdb.Report, start, stop time.Time) ([]queryResult, error) {
+	auth, err := r.auths.FindAuthorizationByID(ctx, rep.AuthorizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !auth.IsActive() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "authorization of the report is inactive",
+		}
+	}
+	qs, err := r.queries(ctx, rep)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = icontext.SetAuthorizer(ctx, auth)
+	extern := query.DashboardExtern(start, stop, reportWidth)
+	results := make([]queryResult, 0, len(qs))
+	for _, q := range qs {
+		var buf bytes.Buffer
+		req := &query.ProxyRequest{
+			Request: query.Request{
+				Authorization:  auth,
+				OrganizationID: rep.OrgID,
+				Compiler: lang.FluxCompiler{
+					Now:    stop,
+					Extern: extern,
+					Query:  q.Text,
+				},
+			},
+			Dialect: &csv.Dialect{
+				ResultEncoderConfig: csv.ResultEncoderConfig{
+					Delimiter:   ',',
+					Annotations: []string{"datatype", "group", "default"},
+				},
+			},
+		}
+		if _, err := r.qs.Query(ctx, &buf, req); err != nil {
+			return nil, fmt.Errorf("query %q failed: %v", q.Name, err)
+		}
+		results = append(results, queryResult{name: q.Name, csv: buf.Bytes()})
+	}
+	return results, nil
+}
+
+// queries returns the queries of rep, or of the cells of its dashboard in
+// the order they are laid out, named after their cell.
+func (r *Runner) queries(ctx context.Context, rep *influxdb.Report) ([]influxdb.ReportQuery, error) {
+	if !rep.DashboardID.Valid() {
+		return rep.Queries, nil
+	}
+
+	d, err := r.dashboards.FindDashboardByID(ctx, rep.DashboardID)
+	if err != nil {
+		return nil, err
+	}
+	cells := append([]*influxdb.Cell(nil), d.Cells...)
+	sort.SliceStable(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+
+	var qs []influxdb.ReportQuery
+	for _, c := range cells {
+		view, err := r.dashboards.GetDashboardCellView(ctx, d.ID, c.ID)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			}
+			return nil, err
+		}
+		vqs := influxdb.ViewQueries(view.Properties)
+		for i, q := range vqs {
+			if strings.TrimSpace(q.Text) == "" {
+				continue
+			}
+			name := view.Name
+			if len(vqs) > 1 {
+				name = fmt.Sprintf("%s (%d)", name, i+1)
+			}
+			qs = append(qs, influxdb.ReportQuery{Name: name, Text: q.Text})
+		}
+	}
+	if len(qs) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "dashboard of the report has no queries",
+		}
+	}
+	return qs, nil
+}
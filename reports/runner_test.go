@@ -0,0 +1,221 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/query"
+	qmock "github.com/influxdata/influxdb/v2/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+const results = `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string
+#group,false,false,true,true,false,false,true
+#default,_result,,,,,,
+,result,table,_start,_stop,_time,_value,host
+,,0,2020-06-08T10:30:00Z,2020-06-15T10:30:00Z,2020-06-14T00:00:00Z,0.5,a
+,,0,2020-06-08T10:30:00Z,2020-06-15T10:30:00Z,2020-06-15T00:00:00Z,0.75,a
+`
+
+func TestRunner_Run(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user"}
+	if err := store.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	auth := &influxdb.Authorization{
+		OrgID:       org.ID,
+		UserID:      user.ID,
+		Permissions: influxdb.OperPermissions(),
+	}
+	if err := store.CreateAuthorization(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+	d := &influxdb.Dashboard{OrganizationID: org.ID, Name: "capacity"}
+	if err := store.CreateDashboard(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		y    int32
+		name string
+	}{{1, "disk"}, {0, "cpu"}} {
+		if err := store.AddDashboardCell(ctx, d.ID, &influxdb.Cell{CellProperty: influxdb.CellProperty{Y: c.y}}, influxdb.AddDashboardCellOptions{
+			View: &influxdb.View{
+				ViewContents: influxdb.ViewContents{Name: c.name},
+				Properties: influxdb.XYViewProperties{
+					Type:    influxdb.ViewPropertyTypeXY,
+					Queries: []influxdb.DashboardQuery{{Text: `from(bucket: "` + c.name + `") |> range(start: v.timeRangeStart)`}},
+				},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		received []byte
+		header   http.Header
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer hook.Close()
+
+	rep := &influxdb.Report{
+		OrgID:           org.ID,
+		Name:            "Weekly capacity",
+		AuthorizationID: auth.ID,
+		Every:           influxdb.Duration{Duration: 7 * 24 * time.Hour},
+		Range:           influxdb.Duration{Duration: 7 * 24 * time.Hour},
+		DashboardID:     d.ID,
+		Format:          influxdb.ReportFormatCSV,
+		Delivery:        influxdb.ReportDelivery{Type: influxdb.ReportDeliveryWebhook, URL: hook.URL},
+	}
+	if err := store.CreateReport(ctx, rep); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		scripts  []string
+		queryErr error
+	)
+	qs := &qmock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			if req.Request.Authorization.ID != auth.ID {
+				t.Errorf("queried with authorization %s, want %s", req.Request.Authorization.ID, auth.ID)
+			}
+			scripts = append(scripts, req.Request.Compiler.(lang.FluxCompiler).Query)
+			if queryErr != nil {
+				return flux.Statistics{}, queryErr
+			}
+			_, err := io.WriteString(w, results)
+			return flux.Statistics{}, err
+		},
+	}
+
+	now := time.Date(2020, 6, 15, 10, 30, 0, 0, time.UTC)
+	r := NewRunner(zaptest.NewLogger(t), store, store, store, qs, map[string]Delivery{
+		influxdb.ReportDeliveryWebhook: NewWebhookDelivery(),
+	})
+	r.now = func() time.Time { return now }
+
+	if err := r.Run(ctx, rep); err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) != 2 || !strings.Contains(scripts[0], `"cpu"`) || !strings.Contains(scripts[1], `"disk"`) {
+		t.Errorf("expected the queries of the cells in layout order, got %q", scripts)
+	}
+	if want := results + "\r\n" + results; string(received) != want {
+		t.Errorf("got report %q, want %q", received, want)
+	}
+	if got, want := header.Get("Content-Disposition"), `attachment; filename=weekly-capacity-20200615T103000Z.csv`; got != want {
+		t.Errorf("got content disposition %q, want %q", got, want)
+	}
+
+	got, err := store.FindReportByID(ctx, rep.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Error != "" || got.Status.LastDeliveredAt == nil || !got.Status.LastDeliveredAt.Equal(now) {
+		t.Errorf("unexpected status after a delivery: %+v", got.Status)
+	}
+	if got.Due(now.Add(24 * time.Hour)) {
+		t.Error("expected the report not to be due before a week")
+	}
+
+	// A failed run keeps when the report was last delivered.
+	queryErr = errors.New("boom")
+	now = now.Add(7 * 24 * time.Hour)
+	if err := r.Run(ctx, got); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	got, err = store.FindReportByID(ctx, rep.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got.Status.Error, "boom") || !got.Status.LastRunAt.Equal(now) || !got.Status.LastDeliveredAt.Equal(now.Add(-7*24*time.Hour)) {
+		t.Errorf("unexpected status after a failure: %+v", got.Status)
+	}
+}
+
+func TestRender_PDF(t *testing.T) {
+	rep := &influxdb.Report{Name: "Weekly capacity", Format: influxdb.ReportFormatPDF}
+	stop := time.Date(2020, 6, 15, 10, 30, 0, 0, time.UTC)
+	f, err := render(rep, stop.Add(-7*24*time.Hour), stop, []queryResult{
+		{name: "cpu", csv: []byte(results)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.ContentType != "application/pdf" || f.Name != "weekly-capacity-20200615T103000Z.pdf" {
+		t.Errorf("unexpected file %s of type %s", f.Name, f.ContentType)
+	}
+
+	doc := string(f.Data)
+	for _, want := range []string{
+		"(== cpu ==)",
+		"(host=a)",
+		"(_time                 _value)",
+		"(2020-06-15T00:00:00Z  0.75)",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected the PDF to contain %s", want)
+		}
+	}
+}
+
+func TestReport_Valid(t *testing.T) {
+	valid := influxdb.Report{
+		OrgID:           1,
+		Name:            "weekly",
+		AuthorizationID: 2,
+		Every:           influxdb.Duration{Duration: time.Hour},
+		Range:           influxdb.Duration{Duration: time.Hour},
+		Queries:         []influxdb.ReportQuery{{Name: "cpu", Text: `from(bucket: "b")`}},
+		Format:          influxdb.ReportFormatCSV,
+		Delivery:        influxdb.ReportDelivery{Type: influxdb.ReportDeliveryEmail, To: []string{"ops@example.com"}},
+	}
+	if err := valid.Valid(); err != nil {
+		t.Fatalf("expected the report to be valid, got %v", err)
+	}
+
+	for name, fn := range map[string]func(r *influxdb.Report){
+		"both dashboard and queries":    func(r *influxdb.Report) { r.DashboardID = 3 },
+		"neither dashboard nor queries": func(r *influxdb.Report) { r.Queries = nil },
+		"too frequent":                  func(r *influxdb.Report) { r.Every.Duration = time.Second },
+		"unknown format":                func(r *influxdb.Report) { r.Format = "xlsx" },
+		"invalid address":               func(r *influxdb.Report) { r.Delivery.To = []string{"ops"} },
+		"webhook without url":           func(r *influxdb.Report) { r.Delivery = influxdb.ReportDelivery{Type: influxdb.ReportDeliveryWebhook} },
+		"s3 without bucket":             func(r *influxdb.Report) { r.Delivery = influxdb.ReportDelivery{Type: influxdb.ReportDeliveryS3} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := valid
+			fn(&r)
+			if err := r.Valid(); influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Errorf("expected an invalid report, got %v", err)
+			}
+		})
+	}
+}
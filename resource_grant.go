@@ -0,0 +1,128 @@
+package influxdb
+
+import (
+	"context"
+)
+
+// ErrResourceGrantNotFound is the error for a missing resource grant.
+const ErrResourceGrantNotFound = "resource grant not found"
+
+const (
+	OpFindResourceGrants    = "FindResourceGrants"
+	OpFindResourceGrantByID = "FindResourceGrantByID"
+	OpCreateResourceGrant   = "CreateResourceGrant"
+	OpDeleteResourceGrant   = "DeleteResourceGrant"
+)
+
+// ResourceGrantService represents a service for sharing single resources
+// with other organizations.
+type ResourceGrantService interface {
+	// FindResourceGrantByID returns a single resource grant by ID.
+	FindResourceGrantByID(ctx context.Context, id ID) (*ResourceGrant, error)
+
+	// FindResourceGrants returns a list of resource grants that match filter
+	// and the total count of matching resource grants.
+	FindResourceGrants(ctx context.Context, filter ResourceGrantFilter, opt ...FindOptions) ([]*ResourceGrant, int, error)
+
+	// CreateResourceGrant creates a new resource grant and sets g.ID with
+	// the new identifier.
+	CreateResourceGrant(ctx context.Context, g *ResourceGrant) error
+
+	// DeleteResourceGrant removes a resource grant by ID.
+	DeleteResourceGrant(ctx context.Context, id ID) error
+}
+
+// ResourceGrant shares a single resource owned by one organization with
+// another organization. Members of the grantee organization that may read
+// resources of the same type within the grantee organization may read the
+// shared resource.
+type ResourceGrant struct {
+	ID           ID           `json:"id,omitempty"`
+	OrgID        ID           `json:"orgID"`
+	ResourceType ResourceType `json:"resourceType"`
+	ResourceID   ID           `json:"resourceID"`
+	GranteeOrgID ID           `json:"granteeOrgID"`
+	CRUDLog
+}
+
+// Valid returns an error if the resource grant is invalid.
+func (g *ResourceGrant) Valid() error {
+	switch g.ResourceType {
+	case BucketsResourceType, DashboardsResourceType:
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  "only buckets and dashboards may be shared with other organizations",
+		}
+	}
+	if !g.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	if !g.ResourceID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "resource ID is required",
+		}
+	}
+	if !g.GranteeOrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "grantee organization ID is required",
+		}
+	}
+	if g.GranteeOrgID == g.OrgID {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "resources cannot be shared with the organization that owns them",
+		}
+	}
+	return nil
+}
+
+// Permission returns the read permission on the shared resource.
+func (g *ResourceGrant) Permission() Permission {
+	orgID, id := g.OrgID, g.ResourceID
+	return Permission{
+		Action: ReadAction,
+		Resource: Resource{
+			Type:  g.ResourceType,
+			OrgID: &orgID,
+			ID:    &id,
+		},
+	}
+}
+
+// GranteePermission returns the permission an authorizer requires within
+// the grantee organization to be given the permission of the grant.
+func (g *ResourceGrant) GranteePermission() Permission {
+	orgID := g.GranteeOrgID
+	return Permission{
+		Action: ReadAction,
+		Resource: Resource{
+			Type:  g.ResourceType,
+			OrgID: &orgID,
+		},
+	}
+}
+
+// ResourceGrantFilter represents a set of filters that restrict the returned
+// resource grants.
+type ResourceGrantFilter struct {
+	ID           *ID
+	OrgID        *ID
+	GranteeOrgID *ID
+	ResourceType *ResourceType
+	ResourceID   *ID
+}
+
+// Match returns true if the resource grant matches the filter.
+func (f ResourceGrantFilter) Match(g *ResourceGrant) bool {
+	return (f.ID == nil || *f.ID == g.ID) &&
+		(f.OrgID == nil || *f.OrgID == g.OrgID) &&
+		(f.GranteeOrgID == nil || *f.GranteeOrgID == g.GranteeOrgID) &&
+		(f.ResourceType == nil || *f.ResourceType == g.ResourceType) &&
+		(f.ResourceID == nil || *f.ResourceID == g.ResourceID)
+}
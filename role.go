@@ -0,0 +1,134 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrRoleNotFound is the error for a missing role.
+const ErrRoleNotFound = "role not found"
+
+const (
+	OpFindRoleByID     = "FindRoleByID"
+	OpFindRoles        = "FindRoles"
+	OpCreateRole       = "CreateRole"
+	OpUpdateRole       = "UpdateRole"
+	OpDeleteRole       = "DeleteRole"
+	OpAddRoleMember    = "AddRoleMember"
+	OpRemoveRoleMember = "RemoveRoleMember"
+	OpFindRoleMembers  = "FindRoleMembers"
+)
+
+// RoleService represents a service for managing named sets of permissions
+// that are granted to the members of the role.
+type RoleService interface {
+	// FindRoleByID returns a single role by ID.
+	FindRoleByID(ctx context.Context, id ID) (*Role, error)
+
+	// FindRoles returns a list of roles that match filter and the total count of matching roles.
+	FindRoles(ctx context.Context, filter RoleFilter, opt ...FindOptions) ([]*Role, int, error)
+
+	// CreateRole creates a new role and sets r.ID with the new identifier.
+	CreateRole(ctx context.Context, r *Role) error
+
+	// UpdateRole updates a single role with changeset.
+	// Returns the new role state after update.
+	UpdateRole(ctx context.Context, id ID, upd RoleUpdate) (*Role, error)
+
+	// DeleteRole removes a role by ID along with its memberships.
+	DeleteRole(ctx context.Context, id ID) error
+
+	// AddRoleMember grants the permissions of the role to the user.
+	AddRoleMember(ctx context.Context, roleID, userID ID) error
+
+	// RemoveRoleMember revokes the permissions of the role from the user.
+	RemoveRoleMember(ctx context.Context, roleID, userID ID) error
+
+	// FindRoleMembers returns the IDs of the users that are members of the role.
+	FindRoleMembers(ctx context.Context, roleID ID) ([]ID, error)
+}
+
+// Role is a named set of permissions within an organization. Members of
+// a role are given all of its permissions.
+type Role struct {
+	ID          ID           `json:"id,omitempty"`
+	OrgID       ID           `json:"orgID"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+	CRUDLog
+}
+
+// Valid returns an error if the role is invalid.
+func (r *Role) Valid() error {
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	if r.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role name is required",
+		}
+	}
+	return validRolePermissions(r.OrgID, r.Permissions)
+}
+
+// validRolePermissions requires every permission to be valid and scoped to
+// the organization of the role, so that a role cannot grant access to
+// resources of other organizations.
+func validRolePermissions(orgID ID, ps []Permission) error {
+	for _, p := range ps {
+		if err := p.Valid(); err != nil {
+			return err
+		}
+		if p.Resource.OrgID == nil || *p.Resource.OrgID != orgID {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("permission %s is not scoped to the organization of the role", p),
+			}
+		}
+	}
+	return nil
+}
+
+// RoleUpdate represents updates to a role.
+// Only fields which are set are updated.
+type RoleUpdate struct {
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Permissions *[]Permission `json:"permissions,omitempty"`
+}
+
+// Apply applies the update to the role.
+func (u RoleUpdate) Apply(r *Role) error {
+	if u.Name != nil {
+		if *u.Name == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "role name is required",
+			}
+		}
+		r.Name = *u.Name
+	}
+	if u.Description != nil {
+		r.Description = *u.Description
+	}
+	if u.Permissions != nil {
+		if err := validRolePermissions(r.OrgID, *u.Permissions); err != nil {
+			return err
+		}
+		r.Permissions = *u.Permissions
+	}
+	return nil
+}
+
+// RoleFilter represents a set of filters that restrict the returned roles.
+type RoleFilter struct {
+	ID     *ID
+	OrgID  *ID
+	Name   *string
+	UserID *ID
+}
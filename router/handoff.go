@@ -0,0 +1,181 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const hintExt = ".hint"
+
+var errHandoffFull = errors.New("hinted handoff queue is full")
+
+// write holds what is needed to send a batch of line protocol to a node,
+// other than the batch itself.
+type write struct {
+	// Params are the org and bucket query parameters of the write.
+	Params map[string]string `json:"params"`
+
+	// Authorization is the authorization header of the write.
+	Authorization string `json:"authorization"`
+
+	// BatchID is the batch ID of the write, which lets nodes drop writes
+	// which are delivered more than once.
+	BatchID string `json:"batchID,omitempty"`
+}
+
+// handoff is the queue of writes, or hints, waiting to be delivered to a node
+// which was unavailable. Each hint is a file holding the write as JSON on its
+// first line, followed by the batch of line protocol.
+type handoff struct {
+	mu      sync.Mutex
+	dir     string
+	maxSize int64
+	size    int64
+	hints   []string
+	seq     uint64
+}
+
+func openHandoff(dir string, maxSize int64) (*handoff, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &handoff{dir: dir, maxSize: maxSize}
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasSuffix(name, hintExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, hintExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		if seq >= h.seq {
+			h.seq = seq + 1
+		}
+		h.size += info.Size()
+		h.hints = append(h.hints, name)
+	}
+	sort.Strings(h.hints)
+	return h, nil
+}
+
+// Len returns the number of hints and their size in bytes.
+func (h *handoff) Len() (int, int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.hints), h.size
+}
+
+// Append queues the batch of w.
+func (h *handoff) Append(w write, batch []byte) error {
+	header, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	size := int64(len(header) + 1 + len(batch))
+	if h.maxSize > 0 && h.size+size > h.maxSize {
+		return errHandoffFull
+	}
+
+	name := fmt.Sprintf("%020d%s", h.seq, hintExt)
+	h.seq++
+
+	// Hints are written to a temporary file first so that a partially
+	// written hint is never delivered.
+	tmp := filepath.Join(h.dir, name+".tmp")
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	bw.Write(header)
+	bw.WriteByte('\n')
+	bw.Write(batch)
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(h.dir, name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	h.size += size
+	h.hints = append(h.hints, name)
+	return nil
+}
+
+// Oldest returns the name of the oldest hint, or false if there is none.
+func (h *handoff) Oldest() (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.hints) == 0 {
+		return "", false
+	}
+	return h.hints[0], true
+}
+
+// Read returns the write and batch of the hint name.
+func (h *handoff) Read(name string) (write, []byte, error) {
+	var w write
+	b, err := ioutil.ReadFile(filepath.Join(h.dir, name))
+	if err != nil {
+		return w, nil, err
+	}
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return w, nil, fmt.Errorf("invalid hint %s", name)
+	}
+	if err := json.Unmarshal(b[:i], &w); err != nil {
+		return w, nil, err
+	}
+	return w, b[i+1:], nil
+}
+
+// Remove removes the hint name, once it has been delivered.
+func (h *handoff) Remove(name string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path := filepath.Join(h.dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	h.size -= info.Size()
+	for i, hint := range h.hints {
+		if hint == name {
+			h.hints = append(h.hints[:i], h.hints[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
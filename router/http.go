@@ -0,0 +1,280 @@
+package router
+
+import (
+	"compress/gzip"
+	"crypto/subtle"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	platform "github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/models"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixWrite  = "/api/v2/write"
+	prefixRouter = "/api/v2/router"
+)
+
+// Handler serves the writes routed by a router, and its admin API.
+//
+// Writes are authorized by the nodes they are routed to, using the
+// authorization of the write. The admin API is authorized by the token of
+// the router.
+type Handler struct {
+	chi.Router
+	api    *kithttp.API
+	log    *zap.Logger
+	router *Router
+}
+
+// NewHandler returns a Handler for router.
+func NewHandler(log *zap.Logger, router *Router) *Handler {
+	h := &Handler{
+		api:    kithttp.NewAPI(kithttp.WithLog(log)),
+		log:    log,
+		router: router,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Get("/health", h.handleGetHealth)
+	r.Post(prefixWrite, h.handlePostWrite)
+	r.Route(prefixRouter, func(r chi.Router) {
+		r.Use(h.authenticate)
+		r.Get("/nodes", h.handleGetNodes)
+		r.Post("/nodes", h.handlePostNode)
+		r.Delete("/nodes", h.handleDeleteNode)
+		r.Get("/rebalance", h.handleGetRebalance)
+		r.Post("/rebalance", h.handlePostRebalance)
+		r.Get("/repair", h.handleGetRepair)
+		r.Post("/repair", h.handlePostRepair)
+		r.Get("/repair/schedules", h.handleGetRepairSchedules)
+		r.Post("/repair/schedules", h.handlePostRepairSchedule)
+		r.Delete("/repair/schedules", h.handleDeleteRepairSchedule)
+	})
+
+	h.Router = r
+	return h
+}
+
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := ihttp.GetToken(r)
+		if err != nil || h.router.config.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.router.config.Token)) != 1 {
+			h.api.Err(w, &platform.Error{
+				Code: platform.EUnauthorized,
+				Msg:  "invalid router token",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGetHealth is the HTTP handler for the GET /health route.
+func (h *Handler) handleGetHealth(w http.ResponseWriter, r *http.Request) {
+	h.api.Respond(w, http.StatusOK, map[string]string{
+		"name":   "influx-router",
+		"status": "pass",
+	})
+}
+
+// handlePostWrite is the HTTP handler for the POST /api/v2/write route.
+func (h *Handler) handlePostWrite(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		h.api.Err(w, &platform.Error{
+			Code: platform.EUnauthorized,
+			Msg:  "authorization is missing in the write request",
+		})
+		return
+	}
+
+	qp := r.URL.Query()
+	precision := qp.Get("precision")
+	if precision == "" {
+		precision = "ns"
+	}
+	if !models.ValidPrecision(precision) {
+		h.api.Err(w, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid precision; valid precision units are ns, us, ms, and s",
+		})
+		return
+	}
+
+	var body io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip", "x-gzip":
+		gr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			h.api.Err(w, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid gzip encoding",
+				Err:  err,
+			})
+			return
+		}
+		defer gr.Close()
+		body = gr
+	}
+	batch, err := ioutil.ReadAll(body)
+	if err != nil {
+		h.api.Err(w, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to read data",
+			Err:  err,
+		})
+		return
+	}
+
+	req := write{
+		Params: map[string]string{
+			"org":    qp.Get("org"),
+			"bucket": qp.Get("bucket"),
+		},
+		Authorization: auth,
+		BatchID:       r.Header.Get(ihttp.BatchIDHeader),
+	}
+	if err := h.router.Write(r.Context(), req, precision, batch); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type nodesResponse struct {
+	ReplicationFactor int          `json:"replicationFactor"`
+	Nodes             []NodeStatus `json:"nodes"`
+}
+
+// handleGetNodes is the HTTP handler for the GET /api/v2/router/nodes route.
+func (h *Handler) handleGetNodes(w http.ResponseWriter, r *http.Request) {
+	h.api.Respond(w, http.StatusOK, nodesResponse{
+		ReplicationFactor: h.router.config.ReplicationFactor,
+		Nodes:             h.router.Nodes(),
+	})
+}
+
+type nodeRequest struct {
+	URL string `json:"url"`
+}
+
+// handlePostNode is the HTTP handler for the POST /api/v2/router/nodes route.
+func (h *Handler) handlePostNode(w http.ResponseWriter, r *http.Request) {
+	var req nodeRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	if err := h.router.AddNode(r.Context(), req.URL); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.handleGetNodes(w, r)
+}
+
+// handleDeleteNode is the HTTP handler for the DELETE /api/v2/router/nodes route.
+// The node is given by the url query parameter.
+func (h *Handler) handleDeleteNode(w http.ResponseWriter, r *http.Request) {
+	if err := h.router.RemoveNode(r.Context(), r.URL.Query().Get("url")); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusNoContent, nil)
+}
+
+// handleGetRebalance is the HTTP handler for the GET /api/v2/router/rebalance route.
+func (h *Handler) handleGetRebalance(w http.ResponseWriter, r *http.Request) {
+	h.api.Respond(w, http.StatusOK, h.router.RebalanceStatus())
+}
+
+// handlePostRebalance is the HTTP handler for the POST /api/v2/router/rebalance route.
+func (h *Handler) handlePostRebalance(w http.ResponseWriter, r *http.Request) {
+	var req RebalanceRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	if err := h.router.Rebalance(r.Context(), req); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusAccepted, h.router.RebalanceStatus())
+}
+
+// handleGetRepair is the HTTP handler for the GET /api/v2/router/repair route.
+func (h *Handler) handleGetRepair(w http.ResponseWriter, r *http.Request) {
+	h.api.Respond(w, http.StatusOK, h.router.RepairStatus())
+}
+
+// handlePostRepair is the HTTP handler for the POST /api/v2/router/repair route.
+func (h *Handler) handlePostRepair(w http.ResponseWriter, r *http.Request) {
+	var req RepairRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	if err := h.router.Repair(r.Context(), req); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusAccepted, h.router.RepairStatus())
+}
+
+type repairSchedulesResponse struct {
+	Schedules []RepairSchedule `json:"schedules"`
+}
+
+// handleGetRepairSchedules is the HTTP handler for the GET /api/v2/router/repair/schedules route.
+func (h *Handler) handleGetRepairSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules := h.router.RepairSchedules()
+	if schedules == nil {
+		schedules = []RepairSchedule{}
+	}
+	h.api.Respond(w, http.StatusOK, repairSchedulesResponse{Schedules: schedules})
+}
+
+// handlePostRepairSchedule is the HTTP handler for the POST /api/v2/router/repair/schedules route.
+func (h *Handler) handlePostRepairSchedule(w http.ResponseWriter, r *http.Request) {
+	var s RepairSchedule
+	if err := h.api.DecodeJSON(r.Body, &s); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	if err := h.router.SetRepairSchedule(r.Context(), s); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.handleGetRepairSchedules(w, r)
+}
+
+// handleDeleteRepairSchedule is the HTTP handler for the DELETE /api/v2/router/repair/schedules route.
+// The bucket is given by the bucketID query parameter.
+func (h *Handler) handleDeleteRepairSchedule(w http.ResponseWriter, r *http.Request) {
+	var bucketID platform.ID
+	if err := bucketID.DecodeFromString(r.URL.Query().Get("bucketID")); err != nil {
+		h.api.Err(w, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		})
+		return
+	}
+	if err := h.router.DeleteRepairSchedule(r.Context(), bucketID); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.api.Respond(w, http.StatusNoContent, nil)
+}
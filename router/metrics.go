@@ -0,0 +1,69 @@
+package router
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "router"
+
+type routerMetrics struct {
+	points       prometheus.Counter
+	hinted       *prometheus.CounterVec
+	dropped      *prometheus.CounterVec
+	handoffBytes *prometheus.GaugeVec
+	copiedPoints prometheus.Counter
+
+	repairedPoints prometheus.Counter
+}
+
+func newRouterMetrics() *routerMetrics {
+	return &routerMetrics{
+		points: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "points_total",
+			Help:      "Number of points routed to nodes.",
+		}),
+		hinted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "handoff",
+			Name:      "writes_total",
+			Help:      "Number of writes queued for a node while it was unavailable.",
+		}, []string{"node"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "handoff",
+			Name:      "dropped_writes_total",
+			Help:      "Number of queued writes which were dropped rather than delivered.",
+		}, []string{"node"}),
+		handoffBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "handoff",
+			Name:      "bytes",
+			Help:      "Size of the writes queued for a node.",
+		}, []string{"node"}),
+		copiedPoints: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rebalance",
+			Name:      "copied_points_total",
+			Help:      "Number of points copied to the nodes which own them while rebalancing.",
+		}),
+		repairedPoints: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repair",
+			Name:      "repaired_points_total",
+			Help:      "Number of points written to the nodes which were missing them while repairing.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *routerMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.points,
+		m.hinted,
+		m.dropped,
+		m.handoffBytes,
+		m.copiedPoints,
+		m.repairedPoints,
+	}
+}
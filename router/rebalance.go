@@ -0,0 +1,303 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+// The states of rebalancing.
+const (
+	RebalanceIdle    = "idle"
+	RebalanceRunning = "running"
+	RebalanceDone    = "done"
+	RebalanceFailed  = "failed"
+)
+
+// maxRebalanceBatchSize is the size in bytes at which the points copied to
+// a node while rebalancing or repairing are written.
+const maxRebalanceBatchSize = 1 << 20
+
+// RebalanceRequest selects the points copied by rebalancing.
+type RebalanceRequest struct {
+	OrgID    platform.ID `json:"orgID"`
+	BucketID platform.ID `json:"bucketID"`
+	Start    time.Time   `json:"start"`
+	Stop     time.Time   `json:"stop"`
+}
+
+// RebalanceStatus is the progress of the last rebalancing.
+type RebalanceStatus struct {
+	State        string            `json:"state"`
+	Request      *RebalanceRequest `json:"request,omitempty"`
+	StartedAt    *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time        `json:"finishedAt,omitempty"`
+	CopiedPoints int64             `json:"copiedPoints"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// Rebalance starts copying the points of a bucket to the nodes which own
+// their series, such as after nodes were added. Points are left on the nodes
+// they were read from; federated reads only return them once, and they
+// expire with the retention period of the bucket. Only one rebalancing runs
+// at a time.
+func (r *Router) Rebalance(ctx context.Context, req RebalanceRequest) error {
+	if !req.OrgID.Valid() || !req.BucketID.Valid() {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "rebalancing requires a valid orgID and bucketID",
+		}
+	}
+	if req.Stop.IsZero() {
+		req.Stop = time.Now().UTC()
+	}
+	if !req.Start.Before(req.Stop) {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "rebalancing requires a start before its stop",
+		}
+	}
+
+	r.rebalanceMu.Lock()
+	defer r.rebalanceMu.Unlock()
+	if r.rebalance.State == RebalanceRunning {
+		return &platform.Error{
+			Code: platform.EConflict,
+			Msg:  "rebalancing is already running",
+		}
+	}
+	now := time.Now().UTC()
+	r.rebalance = RebalanceStatus{
+		State:     RebalanceRunning,
+		Request:   &req,
+		StartedAt: &now,
+	}
+
+	r.mu.RLock()
+	ring := r.ring
+	r.mu.RUnlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.runRebalance(r.ctx, ring, req)
+	}()
+	return nil
+}
+
+// RebalanceStatus returns the progress of the last rebalancing.
+func (r *Router) RebalanceStatus() RebalanceStatus {
+	r.rebalanceMu.Lock()
+	defer r.rebalanceMu.Unlock()
+	return r.rebalance
+}
+
+func (r *Router) runRebalance(ctx context.Context, ring *Ring, req RebalanceRequest) {
+	log := r.log.With(zap.Stringer("org_id", req.OrgID), zap.Stringer("bucket_id", req.BucketID))
+	log.Info("Starting rebalancing")
+
+	var err error
+	for _, node := range ring.Nodes() {
+		if err = r.rebalanceNode(ctx, ring, node, req); err != nil {
+			err = fmt.Errorf("rebalancing node %s: %v", node, err)
+			break
+		}
+	}
+
+	r.rebalanceMu.Lock()
+	defer r.rebalanceMu.Unlock()
+	now := time.Now().UTC()
+	r.rebalance.FinishedAt = &now
+	if err != nil {
+		log.Error("Rebalancing failed", zap.Error(err))
+		r.rebalance.State = RebalanceFailed
+		r.rebalance.Error = err.Error()
+		return
+	}
+	log.Info("Finished rebalancing", zap.Int64("copied_points", r.rebalance.CopiedPoints))
+	r.rebalance.State = RebalanceDone
+}
+
+// rebalanceNode copies the points node stores to the other nodes which own
+// their series.
+func (r *Router) rebalanceNode(ctx context.Context, ring *Ring, node string, req RebalanceRequest) error {
+	bw := r.newBatchWriter(req.OrgID, req.BucketID)
+	if err := r.readPoints(ctx, node, req.OrgID, req.BucketID, req.Start, req.Stop, func(p models.Point) error {
+		for _, owner := range ring.Owners(p.Key(), r.config.ReplicationFactor) {
+			if owner == node {
+				continue
+			}
+			if err := bw.add(ctx, owner, p); err != nil {
+				return err
+			}
+		}
+
+		r.metrics.copiedPoints.Inc()
+		r.rebalanceMu.Lock()
+		r.rebalance.CopiedPoints++
+		r.rebalanceMu.Unlock()
+		return nil
+	}); err != nil {
+		return err
+	}
+	return bw.flush(ctx)
+}
+
+// readPoints calls fn with each point of a bucket stored by node, between
+// start and stop.
+func (r *Router) readPoints(ctx context.Context, node string, orgID, bucketID platform.ID, start, stop time.Time, fn func(models.Point) error) error {
+	svc := &ihttp.FluxQueryService{
+		Addr:               node,
+		Token:              r.config.Token,
+		Name:               "influx-router",
+		InsecureSkipVerify: r.config.InsecureSkipVerify,
+	}
+	qreq := &query.Request{
+		OrganizationID: orgID,
+		Compiler: lang.FluxCompiler{
+			Query: fmt.Sprintf("from(bucketID: %q) |> range(start: %s, stop: %s)",
+				bucketID.String(),
+				start.UTC().Format(time.RFC3339Nano),
+				stop.UTC().Format(time.RFC3339Nano)),
+		},
+	}
+	// Nodes which federate reads must only return the points they store.
+	qreq.WithFederated()
+
+	results, err := svc.Query(ctx, qreq)
+	if err != nil {
+		return err
+	}
+	defer results.Release()
+
+	for results.More() {
+		if err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				for i := 0; i < cr.Len(); i++ {
+					p, err := rowPoint(cr, i)
+					if err != nil {
+						return err
+					}
+					if p == nil {
+						continue
+					}
+					if err := fn(p); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+	}
+	return results.Err()
+}
+
+// batchWriter batches the points the router copies between nodes, writing
+// them with the token of the router.
+type batchWriter struct {
+	r       *Router
+	w       write
+	batches map[string][]byte
+}
+
+func (r *Router) newBatchWriter(orgID, bucketID platform.ID) *batchWriter {
+	return &batchWriter{
+		r: r,
+		w: write{
+			Params: map[string]string{
+				"org":    orgID.String(),
+				"bucket": bucketID.String(),
+			},
+			Authorization: "Token " + r.config.Token,
+		},
+		batches: make(map[string][]byte),
+	}
+}
+
+// add adds p to the batch of node, writing the batch once it is full.
+func (b *batchWriter) add(ctx context.Context, node string, p models.Point) error {
+	b.batches[node] = append(p.AppendString(b.batches[node]), '\n')
+	if len(b.batches[node]) >= maxRebalanceBatchSize {
+		return b.flushNode(ctx, node)
+	}
+	return nil
+}
+
+// flush writes the batches of every node.
+func (b *batchWriter) flush(ctx context.Context) error {
+	for node := range b.batches {
+		if err := b.flushNode(ctx, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *batchWriter) flushNode(ctx context.Context, node string) error {
+	if len(b.batches[node]) == 0 {
+		return nil
+	}
+	if err := b.r.writeNode(ctx, node, b.w, b.batches[node]); err != nil {
+		return err
+	}
+	b.batches[node] = b.batches[node][:0]
+	return nil
+}
+
+// rowPoint returns the point of row i of a table read from storage, or nil
+// if the row has no value.
+func rowPoint(cr flux.ColReader, i int) (models.Point, error) {
+	var (
+		measurement, field string
+		value              interface{}
+		ts                 time.Time
+		tags               = make(map[string]string)
+	)
+	for j, c := range cr.Cols() {
+		v := execute.ValueForRow(cr, i, j)
+		if v.IsNull() {
+			continue
+		}
+		switch c.Label {
+		case execute.DefaultTimeColLabel:
+			ts = v.Time().Time()
+		case execute.DefaultValueColLabel:
+			switch c.Type {
+			case flux.TFloat:
+				value = v.Float()
+			case flux.TInt:
+				value = v.Int()
+			case flux.TUInt:
+				value = v.UInt()
+			case flux.TString:
+				value = v.Str()
+			case flux.TBool:
+				value = v.Bool()
+			}
+		case "_measurement":
+			measurement = v.Str()
+		case "_field":
+			field = v.Str()
+		default:
+			if c.Type == flux.TString && !strings.HasPrefix(c.Label, "_") {
+				tags[c.Label] = v.Str()
+			}
+		}
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return models.NewPoint(measurement, models.NewTags(tags), models.Fields{field: value}, ts)
+}
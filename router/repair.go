@@ -0,0 +1,540 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cespare/xxhash"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"go.uber.org/zap"
+)
+
+// The states of a repair.
+const (
+	RepairIdle    = "idle"
+	RepairRunning = "running"
+	RepairDone    = "done"
+	RepairFailed  = "failed"
+)
+
+// DefaultRepairShardDuration is the default duration of the shards whose
+// digests are compared by a repair.
+const DefaultRepairShardDuration = time.Hour
+
+const repairSchedulesFile = "repair.json"
+
+// repairCheckInterval is how often repair schedules are checked.
+var repairCheckInterval = time.Minute
+
+// RepairRequest selects the points compared by a repair.
+type RepairRequest struct {
+	OrgID    platform.ID `json:"orgID"`
+	BucketID platform.ID `json:"bucketID"`
+	Start    time.Time   `json:"start"`
+	Stop     time.Time   `json:"stop"`
+}
+
+// RepairStatus is the progress of the last repair.
+type RepairStatus struct {
+	State      string         `json:"state"`
+	Request    *RepairRequest `json:"request,omitempty"`
+	StartedAt  *time.Time     `json:"startedAt,omitempty"`
+	FinishedAt *time.Time     `json:"finishedAt,omitempty"`
+
+	// ComparedShards is the number of shards of a series compared between
+	// its primary and a replica, and MismatchedShards the number whose
+	// digests differed.
+	ComparedShards   int64 `json:"comparedShards"`
+	MismatchedShards int64 `json:"mismatchedShards"`
+
+	// RepairedShards is the number of mismatched shards which were
+	// repaired, and RepairedPoints the number of points written to repair
+	// them.
+	RepairedShards int64  `json:"repairedShards"`
+	RepairedPoints int64  `json:"repairedPoints"`
+	Error          string `json:"error,omitempty"`
+}
+
+// RepairSchedule repairs the last Window of a bucket every Every.
+type RepairSchedule struct {
+	OrgID    platform.ID       `json:"orgID"`
+	BucketID platform.ID       `json:"bucketID"`
+	Every    platform.Duration `json:"every"`
+	Window   platform.Duration `json:"window"`
+	LastRun  *time.Time        `json:"lastRun,omitempty"`
+}
+
+// shardKey identifies the points of a series within a shard.
+type shardKey struct {
+	series string
+	shard  int64
+}
+
+// digests are the digests of the shards of each series stored by a node.
+type digests map[shardKey]uint64
+
+// Repair starts an anti-entropy repair of a bucket. The digests of the shards
+// of each series are compared between its primary, the first node which owns
+// it, and its replicas. The shards which differ are read from each node, and
+// the points missing from a node, or which differ from the primary, are
+// written to it, so that the nodes converge after writes to them were lost,
+// such as when the writes queued for an unavailable node were dropped. Only
+// one repair runs at a time.
+func (r *Router) Repair(ctx context.Context, req RepairRequest) error {
+	if !req.OrgID.Valid() || !req.BucketID.Valid() {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "repair requires a valid orgID and bucketID",
+		}
+	}
+	if req.Stop.IsZero() {
+		req.Stop = time.Now().UTC()
+	}
+	if !req.Start.Before(req.Stop) {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "repair requires a start before its stop",
+		}
+	}
+
+	r.repairMu.Lock()
+	defer r.repairMu.Unlock()
+	if r.repair.State == RepairRunning {
+		return &platform.Error{
+			Code: platform.EConflict,
+			Msg:  "repair is already running",
+		}
+	}
+	now := time.Now().UTC()
+	r.repair = RepairStatus{
+		State:     RepairRunning,
+		Request:   &req,
+		StartedAt: &now,
+	}
+
+	r.mu.RLock()
+	ring := r.ring
+	r.mu.RUnlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.runRepair(r.ctx, ring, req)
+	}()
+	return nil
+}
+
+// RepairStatus returns the progress of the last repair.
+func (r *Router) RepairStatus() RepairStatus {
+	r.repairMu.Lock()
+	defer r.repairMu.Unlock()
+	return r.repair
+}
+
+func (r *Router) runRepair(ctx context.Context, ring *Ring, req RepairRequest) {
+	log := r.log.With(zap.Stringer("org_id", req.OrgID), zap.Stringer("bucket_id", req.BucketID))
+	log.Info("Starting repair")
+
+	err := r.repairBucket(ctx, ring, req)
+
+	r.repairMu.Lock()
+	defer r.repairMu.Unlock()
+	now := time.Now().UTC()
+	r.repair.FinishedAt = &now
+	if err != nil {
+		log.Error("Repair failed", zap.Error(err))
+		r.repair.State = RepairFailed
+		r.repair.Error = err.Error()
+		return
+	}
+	log.Info("Finished repair",
+		zap.Int64("mismatched_shards", r.repair.MismatchedShards),
+		zap.Int64("repaired_points", r.repair.RepairedPoints))
+	r.repair.State = RepairDone
+}
+
+func (r *Router) repairBucket(ctx context.Context, ring *Ring, req RepairRequest) error {
+	nodeDigests := make(map[string]digests)
+	for _, node := range ring.Nodes() {
+		d, err := r.readDigests(ctx, ring, node, req)
+		if err != nil {
+			return fmt.Errorf("reading digests of node %s: %v", node, err)
+		}
+		nodeDigests[node] = d
+	}
+
+	compared, mismatched := compareDigests(ring, r.config.ReplicationFactor, nodeDigests)
+	r.repairMu.Lock()
+	r.repair.ComparedShards = int64(compared)
+	for _, series := range mismatched {
+		r.repair.MismatchedShards += int64(len(series))
+	}
+	r.repairMu.Unlock()
+
+	shards := make([]int64, 0, len(mismatched))
+	for shard := range mismatched {
+		shards = append(shards, shard)
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i] < shards[j] })
+
+	for _, shard := range shards {
+		if err := r.repairShard(ctx, ring, req, shard, mismatched[shard]); err != nil {
+			return fmt.Errorf("repairing shard %s: %v", r.shardStart(shard).Format(time.RFC3339), err)
+		}
+	}
+	return nil
+}
+
+// readDigests returns the digests of the shards of the series node owns.
+// Points of series node does not own, such as those left by rebalancing, are
+// ignored.
+func (r *Router) readDigests(ctx context.Context, ring *Ring, node string, req RepairRequest) (digests, error) {
+	d := make(digests)
+	var buf []byte
+	err := r.readPoints(ctx, node, req.OrgID, req.BucketID, req.Start, req.Stop, func(p models.Point) error {
+		key := p.Key()
+		if !ownedBy(ring.Owners(key, r.config.ReplicationFactor), node) {
+			return nil
+		}
+		// The digest of a shard is the sum of the hashes of its points, so
+		// that it does not depend on the order they are read in.
+		buf = p.AppendString(buf[:0])
+		d[shardKey{series: string(key), shard: r.shardOf(p.Time())}] += xxhash.Sum64(buf)
+		return nil
+	})
+	return d, err
+}
+
+// compareDigests compares the digests of each shard of a series between its
+// primary and its replicas. It returns the number of shards compared, and
+// the series of each mismatched shard with the replicas which differ from
+// the primary.
+func compareDigests(ring *Ring, replicationFactor int, nodeDigests map[string]digests) (int, map[int64]map[string][]string) {
+	keys := make(map[shardKey]struct{})
+	for _, d := range nodeDigests {
+		for key := range d {
+			keys[key] = struct{}{}
+		}
+	}
+
+	var compared int
+	mismatched := make(map[int64]map[string][]string)
+	for key := range keys {
+		owners := ring.Owners([]byte(key.series), replicationFactor)
+		primary := nodeDigests[owners[0]][key]
+		for _, replica := range owners[1:] {
+			compared++
+			if nodeDigests[replica][key] == primary {
+				continue
+			}
+			if mismatched[key.shard] == nil {
+				mismatched[key.shard] = make(map[string][]string)
+			}
+			mismatched[key.shard][key.series] = append(mismatched[key.shard][key.series], replica)
+		}
+	}
+	return compared, mismatched
+}
+
+// repairShard reads the mismatched series of a shard from their primaries
+// and replicas, and writes the points each node is missing to it.
+func (r *Router) repairShard(ctx context.Context, ring *Ring, req RepairRequest, shard int64, series map[string][]string) error {
+	start, stop := r.shardStart(shard), r.shardStart(shard+1)
+	if start.Before(req.Start) {
+		start = req.Start
+	}
+	if stop.After(req.Stop) {
+		stop = req.Stop
+	}
+
+	// The points of the mismatched series read from each of their owners,
+	// keyed by their series, field and time.
+	nodes := make(map[string]struct{})
+	for key := range series {
+		for _, owner := range ring.Owners([]byte(key), r.config.ReplicationFactor) {
+			nodes[owner] = struct{}{}
+		}
+	}
+	points := make(map[string]map[string]models.Point)
+	for node := range nodes {
+		pts := make(map[string]models.Point)
+		if err := r.readPoints(ctx, node, req.OrgID, req.BucketID, start, stop, func(p models.Point) error {
+			if _, ok := series[string(p.Key())]; ok {
+				pts[pointKey(p)] = p
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("reading node %s: %v", node, err)
+		}
+		points[node] = pts
+	}
+
+	bw := r.newBatchWriter(req.OrgID, req.BucketID)
+	var repaired int64
+	for node, pts := range diffShard(ring, r.config.ReplicationFactor, series, points) {
+		for _, p := range pts {
+			if err := bw.add(ctx, node, p); err != nil {
+				return err
+			}
+		}
+		repaired += int64(len(pts))
+	}
+	if err := bw.flush(ctx); err != nil {
+		return err
+	}
+
+	r.metrics.repairedPoints.Add(float64(repaired))
+	r.repairMu.Lock()
+	for _, replicas := range series {
+		r.repair.RepairedShards += int64(len(replicas))
+	}
+	r.repair.RepairedPoints += repaired
+	r.repairMu.Unlock()
+	return nil
+}
+
+// diffShard returns the points to write to each node to repair the
+// mismatched series of a shard. Points only stored by replicas are written
+// to the primary and the other replicas, and points of the primary are
+// written to the replicas which are missing them or store another value.
+func diffShard(ring *Ring, replicationFactor int, series map[string][]string, points map[string]map[string]models.Point) map[string][]models.Point {
+	diff := make(map[string][]models.Point)
+	for key := range series {
+		owners := ring.Owners([]byte(key), replicationFactor)
+
+		// The points of the series as every owner should store them once
+		// repaired, taking the value of the primary when owners differ.
+		want := make(map[string]models.Point)
+		for _, owner := range owners {
+			for k, p := range points[owner] {
+				if _, ok := want[k]; !ok && string(p.Key()) == key {
+					want[k] = p
+				}
+			}
+		}
+
+		for _, owner := range owners {
+			for k, p := range want {
+				if q, ok := points[owner][k]; ok && q.String() == p.String() {
+					continue
+				}
+				diff[owner] = append(diff[owner], p)
+			}
+		}
+	}
+	for _, pts := range diff {
+		sort.Slice(pts, func(i, j int) bool { return pointKey(pts[i]) < pointKey(pts[j]) })
+	}
+	return diff
+}
+
+// pointKey identifies a point by its series, field and time.
+func pointKey(p models.Point) string {
+	var field []byte
+	if iter := p.FieldIterator(); iter.Next() {
+		field = iter.FieldKey()
+	}
+	return string(p.Key()) + "\xff" + string(field) + "\xff" + strconv.FormatInt(p.UnixNano(), 10)
+}
+
+func ownedBy(owners []string, node string) bool {
+	for _, owner := range owners {
+		if owner == node {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) shardOf(t time.Time) int64 {
+	ns, d := t.UnixNano(), int64(r.config.RepairShardDuration)
+	shard := ns / d
+	if ns < 0 && ns%d != 0 {
+		shard--
+	}
+	return shard
+}
+
+func (r *Router) shardStart(shard int64) time.Time {
+	return time.Unix(0, shard*int64(r.config.RepairShardDuration)).UTC()
+}
+
+// RepairSchedules returns the repair schedules of the router.
+func (r *Router) RepairSchedules() []RepairSchedule {
+	r.repairMu.Lock()
+	defer r.repairMu.Unlock()
+	return append([]RepairSchedule(nil), r.schedules...)
+}
+
+// SetRepairSchedule adds a schedule repairing a bucket, replacing any
+// schedule of the bucket.
+func (r *Router) SetRepairSchedule(ctx context.Context, s RepairSchedule) error {
+	if !s.OrgID.Valid() || !s.BucketID.Valid() {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "repair schedule requires a valid orgID and bucketID",
+		}
+	}
+	if s.Every.Duration <= 0 || s.Window.Duration <= 0 {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "repair schedule requires a positive every and window",
+		}
+	}
+	s.LastRun = nil
+
+	r.repairMu.Lock()
+	defer r.repairMu.Unlock()
+
+	schedules := make([]RepairSchedule, 0, len(r.schedules)+1)
+	for _, existing := range r.schedules {
+		if existing.BucketID != s.BucketID {
+			schedules = append(schedules, existing)
+		}
+	}
+	schedules = append(schedules, s)
+	if err := r.saveRepairSchedules(schedules); err != nil {
+		return err
+	}
+	r.schedules = schedules
+	return nil
+}
+
+// DeleteRepairSchedule removes the repair schedule of a bucket.
+func (r *Router) DeleteRepairSchedule(ctx context.Context, bucketID platform.ID) error {
+	r.repairMu.Lock()
+	defer r.repairMu.Unlock()
+
+	schedules := make([]RepairSchedule, 0, len(r.schedules))
+	for _, s := range r.schedules {
+		if s.BucketID != bucketID {
+			schedules = append(schedules, s)
+		}
+	}
+	if len(schedules) == len(r.schedules) {
+		return &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  fmt.Sprintf("repair schedule of bucket %s not found", bucketID),
+		}
+	}
+	if err := r.saveRepairSchedules(schedules); err != nil {
+		return err
+	}
+	r.schedules = schedules
+	return nil
+}
+
+// runRepairSchedules starts the repairs which are due, one at a time.
+func (r *Router) runRepairSchedules(ctx context.Context) {
+	ticker := time.NewTicker(repairCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now().UTC()
+		due, ok := r.dueRepair(now)
+		if !ok {
+			continue
+		}
+		req := RepairRequest{
+			OrgID:    due.OrgID,
+			BucketID: due.BucketID,
+			Start:    now.Add(-due.Window.Duration),
+			Stop:     now,
+		}
+		if err := r.Repair(ctx, req); err != nil {
+			if platform.ErrorCode(err) != platform.EConflict {
+				r.log.Error("Failed to start scheduled repair", zap.Stringer("bucket_id", due.BucketID), zap.Error(err))
+			}
+			continue
+		}
+		r.markRepairRun(due.BucketID, now)
+	}
+}
+
+// dueRepair returns the schedule which has waited the longest for its next
+// run, if any is due.
+func (r *Router) dueRepair(now time.Time) (RepairSchedule, bool) {
+	r.repairMu.Lock()
+	defer r.repairMu.Unlock()
+
+	var (
+		due     RepairSchedule
+		overdue time.Duration
+		ok      bool
+	)
+	for _, s := range r.schedules {
+		d := s.Every.Duration
+		if s.LastRun != nil {
+			d = now.Sub(s.LastRun.Add(s.Every.Duration))
+		}
+		if d >= 0 && (!ok || d > overdue) {
+			due, overdue, ok = s, d, true
+		}
+	}
+	return due, ok
+}
+
+func (r *Router) markRepairRun(bucketID platform.ID, now time.Time) {
+	r.repairMu.Lock()
+	defer r.repairMu.Unlock()
+
+	schedules := append([]RepairSchedule(nil), r.schedules...)
+	for i := range schedules {
+		if schedules[i].BucketID == bucketID {
+			schedules[i].LastRun = &now
+		}
+	}
+	if err := r.saveRepairSchedules(schedules); err != nil {
+		r.log.Error("Failed to save repair schedules", zap.Error(err))
+	}
+	r.schedules = schedules
+}
+
+type repairState struct {
+	Schedules []RepairSchedule `json:"schedules"`
+}
+
+func (r *Router) loadRepairSchedules() ([]RepairSchedule, error) {
+	b, err := ioutil.ReadFile(filepath.Join(r.config.Path, repairSchedulesFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var state repairState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, err
+	}
+	return state.Schedules, nil
+}
+
+func (r *Router) saveRepairSchedules(schedules []RepairSchedule) error {
+	b, err := json.Marshal(repairState{Schedules: schedules})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.config.Path, 0700); err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.config.Path, repairSchedulesFile)
+	if err := ioutil.WriteFile(path+".tmp", b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
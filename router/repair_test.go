@@ -0,0 +1,124 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+func mustPoint(t *testing.T, host string, v float64, sec int64) models.Point {
+	t.Helper()
+	p, err := models.NewPoint("cpu", models.NewTags(map[string]string{"host": host}), models.Fields{"v": v}, time.Unix(sec, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestCompareDigests(t *testing.T) {
+	ring := NewRing([]string{"http://a", "http://b", "http://c"})
+	owners := ring.Owners([]byte("cpu,host=a"), 2)
+	primary, replica := owners[0], owners[1]
+
+	same := shardKey{series: "cpu,host=a", shard: 1}
+	differ := shardKey{series: "cpu,host=a", shard: 2}
+	missing := shardKey{series: "cpu,host=a", shard: 3}
+	nodeDigests := map[string]digests{
+		primary: {same: 1, differ: 2},
+		replica: {same: 1, differ: 3, missing: 4},
+	}
+
+	compared, mismatched := compareDigests(ring, 2, nodeDigests)
+	if compared != 3 {
+		t.Fatalf("expected 3 shards to be compared, got %d", compared)
+	}
+	if len(mismatched) != 2 {
+		t.Fatalf("expected 2 mismatched shards, got %v", mismatched)
+	}
+	for _, shard := range []int64{2, 3} {
+		if got := mismatched[shard]["cpu,host=a"]; len(got) != 1 || got[0] != replica {
+			t.Fatalf("expected shard %d to be mismatched on %s, got %v", shard, replica, mismatched[shard])
+		}
+	}
+}
+
+func TestDiffShard(t *testing.T) {
+	ring := NewRing([]string{"http://a", "http://b", "http://c"})
+	owners := ring.Owners([]byte("cpu,host=a"), 2)
+	primary, replica := owners[0], owners[1]
+
+	points := func(pts ...models.Point) map[string]models.Point {
+		m := make(map[string]models.Point)
+		for _, p := range pts {
+			m[pointKey(p)] = p
+		}
+		return m
+	}
+	nodePoints := map[string]map[string]models.Point{
+		// The primary missed the point at 3s, and the replica missed the
+		// point at 1s and stores another value for the point at 2s.
+		primary: points(mustPoint(t, "a", 1, 1), mustPoint(t, "a", 2, 2)),
+		replica: points(mustPoint(t, "a", 5, 2), mustPoint(t, "a", 3, 3)),
+	}
+
+	diff := diffShard(ring, 2, map[string][]string{"cpu,host=a": {replica}}, nodePoints)
+	if got := diff[primary]; len(got) != 1 || got[0].String() != mustPoint(t, "a", 3, 3).String() {
+		t.Fatalf("unexpected points written to primary: %v", got)
+	}
+	if got := diff[replica]; len(got) != 2 ||
+		got[0].String() != mustPoint(t, "a", 1, 1).String() ||
+		got[1].String() != mustPoint(t, "a", 2, 2).String() {
+		t.Fatalf("unexpected points written to replica: %v", got)
+	}
+}
+
+func TestRouter_RepairSchedules(t *testing.T) {
+	a := newNode()
+	defer a.Close()
+	r, cleanup := newTestRouter(t, a)
+	defer cleanup()
+
+	s := RepairSchedule{
+		OrgID:    platform.ID(1),
+		BucketID: platform.ID(2),
+		Every:    platform.Duration{Duration: time.Hour},
+		Window:   platform.Duration{Duration: 2 * time.Hour},
+	}
+	if err := r.SetRepairSchedule(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetRepairSchedule(context.Background(), RepairSchedule{OrgID: 1, BucketID: 3}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("expected schedule without an interval to be rejected, got %v", err)
+	}
+
+	now := time.Now().UTC()
+	if due, ok := r.dueRepair(now); !ok || due.BucketID != s.BucketID {
+		t.Fatalf("expected a new schedule to be due, got %+v", due)
+	}
+	r.markRepairRun(s.BucketID, now)
+	if _, ok := r.dueRepair(now.Add(time.Minute)); ok {
+		t.Fatal("expected schedule not to be due before its interval")
+	}
+	if _, ok := r.dueRepair(now.Add(time.Hour)); !ok {
+		t.Fatal("expected schedule to be due after its interval")
+	}
+
+	// Schedules are saved.
+	loaded, err := r.loadRepairSchedules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].BucketID != s.BucketID || loaded[0].LastRun == nil || !loaded[0].LastRun.Equal(now) {
+		t.Fatalf("unexpected saved schedules %+v", loaded)
+	}
+
+	if err := r.DeleteRepairSchedule(context.Background(), s.BucketID); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeleteRepairSchedule(context.Background(), s.BucketID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Fatalf("expected missing schedule not to be found, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/cespare/xxhash"
+)
+
+// virtualNodes is the number of points each node has on the ring. More
+// points spread series more evenly between nodes.
+const virtualNodes = 256
+
+// Ring is a consistent hash ring of nodes. Adding or removing a node only
+// moves the series owned by that node.
+type Ring struct {
+	nodes  []string
+	hashes []uint64
+	owners []int
+}
+
+// NewRing returns a ring of nodes.
+func NewRing(nodes []string) *Ring {
+	r := &Ring{nodes: append([]string(nil), nodes...)}
+	sort.Strings(r.nodes)
+
+	type point struct {
+		hash uint64
+		node int
+	}
+	points := make([]point, 0, len(r.nodes)*virtualNodes)
+	for i, node := range r.nodes {
+		for v := 0; v < virtualNodes; v++ {
+			points = append(points, point{
+				hash: xxhash.Sum64String(node + "#" + strconv.Itoa(v)),
+				node: i,
+			})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+
+	r.hashes = make([]uint64, len(points))
+	r.owners = make([]int, len(points))
+	for i, p := range points {
+		r.hashes[i] = p.hash
+		r.owners[i] = p.node
+	}
+	return r
+}
+
+// Nodes returns the nodes of the ring, sorted.
+func (r *Ring) Nodes() []string {
+	return append([]string(nil), r.nodes...)
+}
+
+// Owners returns the n distinct nodes which own key, in order of preference.
+// Fewer nodes are returned when the ring has less than n nodes.
+func (r *Ring) Owners(key []byte, n int) []string {
+	if n > len(r.nodes) {
+		n = len(r.nodes)
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	h := xxhash.Sum64(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+
+	owners := make([]string, 0, n)
+	seen := make([]bool, len(r.nodes))
+	for j := 0; len(owners) < n; j++ {
+		node := r.owners[(i+j)%len(r.owners)]
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		owners = append(owners, r.nodes[node])
+	}
+	return owners
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRing_Owners(t *testing.T) {
+	ring := NewRing([]string{"http://c", "http://a", "http://b"})
+
+	owners := ring.Owners([]byte("cpu,host=a"), 2)
+	if len(owners) != 2 || owners[0] == owners[1] {
+		t.Fatalf("expected 2 distinct owners, got %v", owners)
+	}
+	if got := ring.Owners([]byte("cpu,host=a"), 2); got[0] != owners[0] || got[1] != owners[1] {
+		t.Fatalf("expected owners to be stable, got %v and %v", owners, got)
+	}
+	if got := ring.Owners([]byte("cpu,host=a"), 5); len(got) != 3 {
+		t.Fatalf("expected every node to own the key, got %v", got)
+	}
+}
+
+func TestRing_AddNodeMovesFewSeries(t *testing.T) {
+	before := NewRing([]string{"http://a", "http://b", "http://c"})
+	after := NewRing([]string{"http://a", "http://b", "http://c", "http://d"})
+
+	const n = 10000
+	counts := make(map[string]int)
+	moved := 0
+	for i := 0; i < n; i++ {
+		key := []byte(fmt.Sprintf("cpu,host=%d", i))
+		b, a := before.Owners(key, 1)[0], after.Owners(key, 1)[0]
+		if a != b {
+			if a != "http://d" {
+				t.Fatalf("series %s moved from %s to %s rather than to the new node", key, b, a)
+			}
+			moved++
+		}
+		counts[a]++
+	}
+
+	// Roughly a quarter of the series move to the new node.
+	if moved < n/8 || moved > n/2 {
+		t.Errorf("expected about %d series to move, got %d", n/4, moved)
+	}
+	for node, c := range counts {
+		if c < n/8 {
+			t.Errorf("expected series to be spread between nodes, %s owns %d", node, c)
+		}
+	}
+}
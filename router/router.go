@@ -0,0 +1,636 @@
+// Package router implements a router which shards writes across several
+// influxd nodes by series.
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash"
+	platform "github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultReplicationFactor is the default number of nodes each series is
+	// written to.
+	DefaultReplicationFactor = 2
+
+	// DefaultMaxHandoffSize is the default maximum size in bytes of the
+	// writes queued for a node while it is unavailable.
+	DefaultMaxHandoffSize = 1 << 30
+
+	// DefaultHandoffInterval is the default interval at which queued writes
+	// are delivered.
+	DefaultHandoffInterval = time.Second
+
+	nodesFile = "nodes.json"
+	nodeFile  = "node"
+)
+
+// Config configures a Router.
+type Config struct {
+	// Nodes are the addresses of the nodes writes are sharded across. Once
+	// nodes have been added or removed through the router, the nodes saved
+	// in Path are used instead.
+	Nodes []string
+
+	// ReplicationFactor is the number of nodes each series is written to.
+	ReplicationFactor int
+
+	// Path is the directory holding the nodes of the router and the writes
+	// queued for unavailable nodes.
+	Path string
+
+	// MaxHandoffSize is the maximum size in bytes of the writes queued for
+	// each node. Writes which cannot be queued fail. A value of zero means
+	// the queue is unbounded.
+	MaxHandoffSize int64
+
+	// HandoffInterval is how often queued writes are delivered.
+	HandoffInterval time.Duration
+
+	// RepairShardDuration is the duration of the shards whose digests are
+	// compared between the nodes which own a series by a repair.
+	RepairShardDuration time.Duration
+
+	// Token authorizes the requests of the admin API of the router, and the
+	// reads and writes of the nodes made while rebalancing or repairing.
+	Token string
+
+	// InsecureSkipVerify skips verification of the nodes' TLS certificates.
+	InsecureSkipVerify bool
+}
+
+// NodeStatus is the status of a node of the router.
+type NodeStatus struct {
+	URL string `json:"url"`
+
+	// Hints and HintsBytes are the number and size of the writes queued
+	// for the node.
+	Hints      int   `json:"hints"`
+	HintsBytes int64 `json:"hintsBytes"`
+}
+
+// Router shards writes across nodes by series using a consistent hash ring,
+// writing each series to ReplicationFactor nodes.
+//
+// Writes to a node which is unavailable are queued on disk as hints, and
+// delivered once it is available again; a write succeeds once each of its
+// batches has been written or queued. When a node is removed, its queued
+// writes are routed to the new owners of their series. Writes which nodes
+// reject, such as unauthorized or invalid writes, fail without being queued.
+type Router struct {
+	log     *zap.Logger
+	config  Config
+	client  *http.Client
+	metrics *routerMetrics
+
+	mu       sync.RWMutex
+	ring     *Ring
+	handoffs map[string]*handoff
+
+	rebalanceMu sync.Mutex
+	rebalance   RebalanceStatus
+
+	repairMu  sync.Mutex
+	repair    RepairStatus
+	schedules []RepairSchedule
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRouter returns a Router configured by config. It must be opened before
+// use.
+func NewRouter(log *zap.Logger, config Config) *Router {
+	if config.ReplicationFactor <= 0 {
+		config.ReplicationFactor = DefaultReplicationFactor
+	}
+	if config.HandoffInterval <= 0 {
+		config.HandoffInterval = DefaultHandoffInterval
+	}
+	if config.RepairShardDuration <= 0 {
+		config.RepairShardDuration = DefaultRepairShardDuration
+	}
+	return &Router{
+		log:      log,
+		config:   config,
+		client:   ihttp.NewClient("https", config.InsecureSkipVerify),
+		metrics:  newRouterMetrics(),
+		handoffs: make(map[string]*handoff),
+		rebalance: RebalanceStatus{
+			State: RebalanceIdle,
+		},
+		repair: RepairStatus{
+			State: RepairIdle,
+		},
+	}
+}
+
+// Open loads the nodes of the router, starts delivering queued writes and
+// runs scheduled repairs.
+func (r *Router) Open(ctx context.Context) error {
+	nodes, err := r.loadNodes()
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "router requires at least one node",
+		}
+	}
+	for _, node := range nodes {
+		if err := validateNode(node); err != nil {
+			return err
+		}
+	}
+
+	schedules, err := r.loadRepairSchedules()
+	if err != nil {
+		return err
+	}
+	r.schedules = schedules
+
+	r.ring = NewRing(nodes)
+	for _, node := range nodes {
+		h, err := r.openHandoff(node)
+		if err != nil {
+			return err
+		}
+		r.handoffs[node] = h
+	}
+
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	// Writes queued for nodes which have since been removed are routed to
+	// the nodes which now own them.
+	if err := r.drainRemoved(ctx); err != nil {
+		return err
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.deliverHints(r.ctx)
+	}()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.runRepairSchedules(r.ctx)
+	}()
+	return nil
+}
+
+// Close stops delivering queued writes, and any rebalancing or repair.
+func (r *Router) Close() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	return nil
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (r *Router) PrometheusCollectors() []prometheus.Collector {
+	return r.metrics.PrometheusCollectors()
+}
+
+// Write routes the points of the line protocol in batch to the nodes which
+// own their series.
+func (r *Router) Write(ctx context.Context, w write, precision string, batch []byte) error {
+	points, err := models.ParsePointsWithPrecision(batch, []byte("router"), time.Now().UTC(), precision)
+	if err != nil {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to parse points",
+			Err:  err,
+		}
+	}
+
+	batches, err := r.route(points)
+	if err != nil {
+		return err
+	}
+	r.metrics.points.Add(float64(len(points)))
+	return r.send(ctx, w, batches)
+}
+
+// route returns the line protocol of points, batched by the nodes which own
+// their series.
+func (r *Router) route(points []models.Point) (map[string][]byte, error) {
+	r.mu.RLock()
+	ring := r.ring
+	r.mu.RUnlock()
+
+	batches := make(map[string][]byte)
+	var tags models.Tags
+	for _, pt := range points {
+		var measurement []byte
+		tags = tags[:0]
+		pt.ForEachTag(func(k, v []byte) bool {
+			switch {
+			case bytes.Equal(k, models.MeasurementTagKeyBytes):
+				measurement = v
+			case bytes.Equal(k, models.FieldKeyTagKeyBytes):
+			default:
+				tags = append(tags, models.NewTag(k, v))
+			}
+			return true
+		})
+
+		fields, err := pt.Fields()
+		if err != nil {
+			return nil, err
+		}
+		p, err := models.NewPoint(string(measurement), tags, fields, pt.Time())
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range ring.Owners(p.Key(), r.config.ReplicationFactor) {
+			batches[node] = append(p.AppendString(batches[node]), '\n')
+		}
+	}
+	return batches, nil
+}
+
+// send writes each batch to its node, and returns the first error of a
+// batch which could neither be written nor queued.
+func (r *Router) send(ctx context.Context, w write, batches map[string][]byte) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for node, batch := range batches {
+		wg.Add(1)
+		go func(node string, batch []byte) {
+			defer wg.Done()
+			if err := r.writeNode(ctx, node, w, batch); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(node, batch)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// writeNode writes batch to node, queueing it when node is unavailable.
+func (r *Router) writeNode(ctx context.Context, node string, w write, batch []byte) error {
+	r.mu.RLock()
+	h := r.handoffs[node]
+	r.mu.RUnlock()
+	if h == nil {
+		return &platform.Error{
+			Code: platform.EUnavailable,
+			Msg:  fmt.Sprintf("node %s was removed while writing", node),
+		}
+	}
+
+	// Writes wait behind the writes already queued for the node, so that
+	// points are delivered in the order they were written.
+	if n, _ := h.Len(); n == 0 {
+		err := r.post(ctx, node, w, batch)
+		if err == nil || !retryable(err) {
+			return err
+		}
+		r.log.Info("Failed to write to node, queueing write", zap.String("node", node), zap.Error(err))
+	}
+
+	if err := h.Append(w, batch); err != nil {
+		return &platform.Error{
+			Code: platform.EUnavailable,
+			Msg:  fmt.Sprintf("node %s is unavailable and writes to it cannot be queued", node),
+			Err:  err,
+		}
+	}
+	r.metrics.hinted.WithLabelValues(node).Inc()
+	r.updateHandoffSize(node, h)
+	return nil
+}
+
+// post writes batch to node.
+func (r *Router) post(ctx context.Context, node string, w write, batch []byte) error {
+	u, err := ihttp.NewURL(node, "/api/v2/write")
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	for k, v := range w.Params {
+		params.Set(k, v)
+	}
+	params.Set("precision", "ns")
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", w.Authorization)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.BatchID != "" {
+		req.Header.Set(ihttp.BatchIDHeader, w.BatchID)
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return ihttp.CheckError(resp)
+}
+
+// retryable reports whether a write which failed with err may succeed later.
+// Writes which nodes reject are not retried.
+func retryable(err error) bool {
+	if _, ok := err.(*platform.Error); !ok {
+		return true
+	}
+	switch platform.ErrorCode(err) {
+	case platform.EInternal, platform.EUnavailable, platform.ETooManyRequests:
+		return true
+	}
+	return false
+}
+
+func (r *Router) deliverHints(ctx context.Context) {
+	ticker := time.NewTicker(r.config.HandoffInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.RLock()
+		handoffs := make(map[string]*handoff, len(r.handoffs))
+		for node, h := range r.handoffs {
+			handoffs[node] = h
+		}
+		r.mu.RUnlock()
+
+		for node, h := range handoffs {
+			r.deliver(ctx, node, h)
+		}
+	}
+}
+
+// deliver delivers the writes queued for node, in order, until one fails.
+func (r *Router) deliver(ctx context.Context, node string, h *handoff) {
+	defer r.updateHandoffSize(node, h)
+
+	for ctx.Err() == nil {
+		name, ok := h.Oldest()
+		if !ok {
+			return
+		}
+
+		w, batch, err := h.Read(name)
+		if err != nil {
+			r.log.Error("Dropping unreadable queued write", zap.String("node", node), zap.String("hint", name), zap.Error(err))
+			r.metrics.dropped.WithLabelValues(node).Inc()
+		} else if err := r.post(ctx, node, w, batch); err != nil {
+			if retryable(err) {
+				return
+			}
+			r.log.Warn("Dropping queued write rejected by node", zap.String("node", node), zap.String("hint", name), zap.Error(err))
+			r.metrics.dropped.WithLabelValues(node).Inc()
+		}
+
+		if err := h.Remove(name); err != nil {
+			r.log.Error("Failed to remove queued write", zap.String("node", node), zap.String("hint", name), zap.Error(err))
+			return
+		}
+	}
+}
+
+func (r *Router) updateHandoffSize(node string, h *handoff) {
+	_, size := h.Len()
+	r.metrics.handoffBytes.WithLabelValues(node).Set(float64(size))
+}
+
+// Nodes returns the status of the nodes of the router.
+func (r *Router) Nodes() []NodeStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes := r.ring.Nodes()
+	statuses := make([]NodeStatus, len(nodes))
+	for i, node := range nodes {
+		statuses[i].URL = node
+		statuses[i].Hints, statuses[i].HintsBytes = r.handoffs[node].Len()
+	}
+	return statuses
+}
+
+// AddNode adds node to the router. Only new writes are routed to it; existing
+// series are moved by rebalancing.
+func (r *Router) AddNode(ctx context.Context, node string) error {
+	if err := validateNode(node); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.handoffs[node]; ok {
+		return &platform.Error{
+			Code: platform.EConflict,
+			Msg:  fmt.Sprintf("node %s already exists", node),
+		}
+	}
+
+	h, err := r.openHandoff(node)
+	if err != nil {
+		return err
+	}
+	nodes := append(r.ring.Nodes(), node)
+	if err := r.saveNodes(nodes); err != nil {
+		return err
+	}
+	r.ring = NewRing(nodes)
+	r.handoffs[node] = h
+	r.log.Info("Added node", zap.String("node", node))
+	return nil
+}
+
+// RemoveNode removes node from the router, and routes the writes queued for
+// it to the nodes which now own them.
+func (r *Router) RemoveNode(ctx context.Context, node string) error {
+	r.mu.Lock()
+	h, ok := r.handoffs[node]
+	if !ok {
+		r.mu.Unlock()
+		return &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  fmt.Sprintf("node %s not found", node),
+		}
+	}
+	if len(r.handoffs) == 1 {
+		r.mu.Unlock()
+		return &platform.Error{
+			Code: platform.EConflict,
+			Msg:  "cannot remove the last node",
+		}
+	}
+
+	var nodes []string
+	for _, n := range r.ring.Nodes() {
+		if n != node {
+			nodes = append(nodes, n)
+		}
+	}
+	if err := r.saveNodes(nodes); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	r.ring = NewRing(nodes)
+	delete(r.handoffs, node)
+	r.metrics.handoffBytes.DeleteLabelValues(node)
+	r.mu.Unlock()
+
+	r.log.Info("Removed node", zap.String("node", node))
+	return r.drain(ctx, h)
+}
+
+// drain routes the writes queued in h to the nodes which own them, and
+// removes h once it is empty.
+func (r *Router) drain(ctx context.Context, h *handoff) error {
+	for {
+		name, ok := h.Oldest()
+		if !ok {
+			return os.RemoveAll(h.dir)
+		}
+
+		w, batch, err := h.Read(name)
+		if err != nil {
+			return err
+		}
+		points, err := models.ParsePointsWithPrecision(batch, []byte("router"), time.Now().UTC(), "ns")
+		if err != nil {
+			return err
+		}
+		batches, err := r.route(points)
+		if err != nil {
+			return err
+		}
+		if err := r.send(ctx, w, batches); err != nil {
+			return err
+		}
+		if err := h.Remove(name); err != nil {
+			return err
+		}
+	}
+}
+
+// drainRemoved drains the queues of nodes which are no longer part of the
+// router.
+func (r *Router) drainRemoved(ctx context.Context) error {
+	dirs, err := ioutil.ReadDir(filepath.Join(r.config.Path, "hints"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(r.config.Path, "hints", dir.Name())
+		node, err := ioutil.ReadFile(filepath.Join(path, nodeFile))
+		if err != nil {
+			continue
+		}
+		if _, ok := r.handoffs[string(node)]; ok {
+			continue
+		}
+
+		h, err := openHandoff(path, 0)
+		if err != nil {
+			return err
+		}
+		r.log.Info("Routing writes queued for removed node", zap.String("node", string(node)))
+		if err := r.drain(ctx, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Router) openHandoff(node string) (*handoff, error) {
+	dir := filepath.Join(r.config.Path, "hints", fmt.Sprintf("%016x", xxhash.Sum64String(node)))
+	h, err := openHandoff(dir, r.config.MaxHandoffSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, nodeFile), []byte(node), 0600); err != nil {
+		return nil, err
+	}
+	r.updateHandoffSize(node, h)
+	return h, nil
+}
+
+type nodesState struct {
+	Nodes []string `json:"nodes"`
+}
+
+func (r *Router) loadNodes() ([]string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(r.config.Path, nodesFile))
+	if os.IsNotExist(err) {
+		return r.config.Nodes, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var state nodesState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, err
+	}
+	return state.Nodes, nil
+}
+
+func (r *Router) saveNodes(nodes []string) error {
+	b, err := json.Marshal(nodesState{Nodes: nodes})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.config.Path, 0700); err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.config.Path, nodesFile)
+	if err := ioutil.WriteFile(path+".tmp", b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func validateNode(node string) error {
+	u, err := url.Parse(node)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("invalid node address %q", node),
+			Err:  err,
+		}
+	}
+	return nil
+}
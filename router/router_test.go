@@ -0,0 +1,225 @@
+package router
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap/zaptest"
+)
+
+// node is an influxd node which records the line protocol written to it.
+type node struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	status int
+	lines  []string
+	auth   []string
+}
+
+func newNode() *node {
+	n := &node{status: http.StatusNoContent}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		switch n.status {
+		case http.StatusUnauthorized:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(n.status)
+			_, _ = w.Write([]byte(`{"code":"unauthorized","message":"unauthorized access"}`))
+			return
+		case http.StatusServiceUnavailable:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(n.status)
+			_, _ = w.Write([]byte(`{"code":"unavailable","message":"unavailable"}`))
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		n.lines = append(n.lines, strings.Split(strings.TrimSpace(string(b)), "\n")...)
+		n.auth = append(n.auth, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return n
+}
+
+func (n *node) setStatus(status int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.status = status
+}
+
+func (n *node) Lines() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.lines...)
+}
+
+func (n *node) Auth() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.auth...)
+}
+
+func newTestRouter(t *testing.T, nodes ...*node) (*Router, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "router")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{
+		ReplicationFactor: 2,
+		Path:              dir,
+		HandoffInterval:   10 * time.Millisecond,
+	}
+	for _, n := range nodes {
+		config.Nodes = append(config.Nodes, n.URL)
+	}
+	r := NewRouter(zaptest.NewLogger(t), config)
+	if err := r.Open(context.Background()); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return r, func() {
+		r.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+var testWrite = write{
+	Params:        map[string]string{"org": "o", "bucket": "b"},
+	Authorization: "Token t",
+}
+
+func TestRouter_HintedHandoff(t *testing.T) {
+	a, b := newNode(), newNode()
+	defer a.Close()
+	defer b.Close()
+	r, cleanup := newTestRouter(t, a, b)
+	defer cleanup()
+
+	b.setStatus(http.StatusServiceUnavailable)
+	if err := r.Write(context.Background(), testWrite, "s", []byte("cpu,host=a v=1 1\ncpu,host=b v=2 2")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"cpu,host=a v=1 1000000000", "cpu,host=b v=2 2000000000"}
+	if got := a.Lines(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected lines written to available node: %v", got)
+	}
+	if got := b.Lines(); len(got) != 0 {
+		t.Fatalf("expected no lines written to unavailable node, got %v", got)
+	}
+	for _, n := range r.Nodes() {
+		if n.URL == b.URL && n.Hints != 1 {
+			t.Fatalf("expected write to be queued for unavailable node, got %+v", n)
+		}
+	}
+
+	// Queued writes are delivered once the node is available again.
+	b.setStatus(http.StatusNoContent)
+	waitFor(t, func() bool { return len(b.Lines()) == 2 })
+	if got := b.Lines(); got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected lines delivered: %v", got)
+	}
+	if auth := b.Auth(); auth[0] != "Token t" {
+		t.Fatalf("expected write to be delivered with its authorization, got %q", auth[0])
+	}
+	waitFor(t, func() bool {
+		for _, n := range r.Nodes() {
+			if n.Hints != 0 {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestRouter_RejectedWrite(t *testing.T) {
+	a := newNode()
+	defer a.Close()
+	r, cleanup := newTestRouter(t, a)
+	defer cleanup()
+
+	a.setStatus(http.StatusUnauthorized)
+	err := r.Write(context.Background(), testWrite, "ns", []byte("cpu v=1 1"))
+	if platform.ErrorCode(err) != platform.EUnauthorized {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if n := r.Nodes()[0]; n.Hints != 0 {
+		t.Fatalf("expected rejected write not to be queued, got %+v", n)
+	}
+
+	if err := r.Write(context.Background(), testWrite, "ns", []byte("cpu v=")); platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("expected invalid line protocol to be rejected, got %v", err)
+	}
+}
+
+func TestRouter_RemoveNode(t *testing.T) {
+	a, b := newNode(), newNode()
+	defer a.Close()
+	defer b.Close()
+	r, cleanup := newTestRouter(t, a, b)
+	defer cleanup()
+	r.config.ReplicationFactor = 1
+
+	// Stop delivering so that the writes queued for b stay queued.
+	r.cancel()
+	r.wg.Wait()
+
+	b.setStatus(http.StatusServiceUnavailable)
+	var lines []string
+	for _, host := range strings.Split("abcdefghijklmnop", "") {
+		lines = append(lines, "cpu,host="+host+" v=1 1")
+	}
+	if err := r.Write(context.Background(), testWrite, "ns", []byte(strings.Join(lines, "\n"))); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Lines()) == len(lines) {
+		t.Fatal("expected some series to be owned by the unavailable node")
+	}
+
+	// The writes queued for a removed node are routed to its remaining owners.
+	if err := r.RemoveNode(context.Background(), b.URL); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Lines(); len(got) != len(lines) {
+		t.Fatalf("expected every series to be written to the remaining node, got %v", got)
+	}
+	if nodes := r.Nodes(); len(nodes) != 1 || nodes[0].URL != a.URL {
+		t.Fatalf("unexpected nodes %+v", nodes)
+	}
+
+	// The nodes of the router are saved.
+	loaded, err := r.loadNodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0] != a.URL {
+		t.Fatalf("unexpected saved nodes %v", loaded)
+	}
+
+	if err := r.RemoveNode(context.Background(), a.URL); platform.ErrorCode(err) != platform.EConflict {
+		t.Fatalf("expected the last node not to be removed, got %v", err)
+	}
+}
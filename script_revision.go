@@ -0,0 +1,49 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrScriptRevisionNotFound is the error for a missing script revision.
+const ErrScriptRevisionNotFound = "script revision not found"
+
+// MaxScriptRevisions is the number of revisions kept for each resource. The
+// oldest revision is removed when a new one exceeds it.
+const MaxScriptRevisions = 100
+
+const (
+	OpFindScriptRevisionByID = "FindScriptRevisionByID"
+	OpFindScriptRevisions    = "FindScriptRevisions"
+)
+
+// ScriptRevisionService finds the revisions of the scripts of tasks, checks
+// and notification rules, which are recorded whenever they change.
+type ScriptRevisionService interface {
+	// FindScriptRevisionByID returns a single script revision by ID.
+	FindScriptRevisionByID(ctx context.Context, id ID) (*ScriptRevision, error)
+
+	// FindScriptRevisions returns the revisions of the script of a resource,
+	// from the oldest to the latest.
+	FindScriptRevisions(ctx context.Context, filter ScriptRevisionFilter) ([]*ScriptRevision, error)
+}
+
+// ScriptRevision is a version of the script of a resource. The script of a
+// task is its Flux, and the script of a check or notification rule is its
+// definition encoded as indented JSON, from which its Flux is generated.
+type ScriptRevision struct {
+	ID           ID           `json:"id"`
+	ResourceID   ID           `json:"resourceID"`
+	ResourceType ResourceType `json:"resourceType"`
+	OrgID        ID           `json:"orgID"`
+	// Version is the number of the revision of the resource, starting at 1.
+	Version   int       `json:"version"`
+	Script    string    `json:"script"`
+	UserID    ID        `json:"userID,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ScriptRevisionFilter selects the revisions of a resource.
+type ScriptRevisionFilter struct {
+	ResourceID ID
+}
@@ -0,0 +1,102 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// MaxSignedQueryDuration is the longest time a signed query may be valid for.
+const MaxSignedQueryDuration = 7 * 24 * time.Hour
+
+const (
+	OpSignQuery         = "SignQuery"
+	OpVerifySignedQuery = "VerifySignedQuery"
+)
+
+// ErrSignedQueryInvalid is returned for signatures that are malformed,
+// forged, expired or revoked.
+var ErrSignedQueryInvalid = &Error{
+	Code: EUnauthorized,
+	Msg:  "signed query is invalid, has expired or has been revoked",
+}
+
+// SignedQuery is a query that may be run without a token until it expires.
+// It may only read from its bucket.
+type SignedQuery struct {
+	OrgID     ID        `json:"orgID"`
+	BucketID  ID        `json:"bucketID"`
+	Query     string    `json:"query"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// UserID is the user that signed the query.
+	UserID ID `json:"userID,omitempty"`
+}
+
+// Valid returns an error if the signed query is invalid at time now.
+func (q *SignedQuery) Valid(now time.Time) error {
+	if !q.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	if !q.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket ID is required",
+		}
+	}
+	if q.Query == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "query is required",
+		}
+	}
+	if !q.ExpiresAt.After(now) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "expiration must be in the future",
+		}
+	}
+	if q.ExpiresAt.Sub(now) > MaxSignedQueryDuration {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "expiration must be within " + MaxSignedQueryDuration.String(),
+		}
+	}
+	return nil
+}
+
+// Permission returns the only permission given to the signed query.
+func (q *SignedQuery) Permission() Permission {
+	orgID, bucketID := q.OrgID, q.BucketID
+	return Permission{
+		Action: ReadAction,
+		Resource: Resource{
+			Type:  BucketsResourceType,
+			OrgID: &orgID,
+			ID:    &bucketID,
+		},
+	}
+}
+
+// Authorization returns an ephemeral authorization for running the signed query.
+func (q *SignedQuery) Authorization() *Authorization {
+	return &Authorization{
+		OrgID:       q.OrgID,
+		UserID:      q.UserID,
+		Status:      Active,
+		Permissions: []Permission{q.Permission()},
+	}
+}
+
+// SignedQueryService signs queries so that they can be shared with clients
+// that have no token.
+type SignedQueryService interface {
+	// SignQuery returns the signature of the query.
+	SignQuery(ctx context.Context, q *SignedQuery) (string, error)
+
+	// VerifySignedQuery returns the query of a signature if the signature
+	// is authentic, has not expired, and the user that signed it is active
+	// and may still read its bucket.
+	VerifySignedQuery(ctx context.Context, signature string) (*SignedQuery, error)
+}
@@ -0,0 +1,74 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrSlackThreadNotFound is the error for a missing slack thread.
+const ErrSlackThreadNotFound = "slack thread not found"
+
+const (
+	OpFindSlackThreadByID = "FindSlackThreadByID"
+	OpFindSlackThreads    = "FindSlackThreads"
+	OpPutSlackThread      = "PutSlackThread"
+	OpDeleteSlackThread   = "DeleteSlackThread"
+)
+
+// SlackThreadService keeps the slack messages of the open alerts posted by
+// the slack app notification endpoints, so that the updates of an alert are
+// threaded onto its message until it is resolved.
+type SlackThreadService interface {
+	// FindSlackThreadByID returns a single slack thread by ID.
+	FindSlackThreadByID(ctx context.Context, id ID) (*SlackThread, error)
+
+	// FindSlackThreads returns a list of slack threads that match filter.
+	FindSlackThreads(ctx context.Context, filter SlackThreadFilter) ([]*SlackThread, error)
+
+	// PutSlackThread creates or updates a slack thread. A new thread, whose
+	// ID is not set, is set t.ID with the new identifier.
+	PutSlackThread(ctx context.Context, t *SlackThread) error
+
+	// DeleteSlackThread removes a slack thread by ID.
+	DeleteSlackThread(ctx context.Context, id ID) error
+}
+
+// SlackThread is the slack message of an open alert of a notification rule,
+// which identifies the alert with the check and the series of its statuses.
+type SlackThread struct {
+	ID         ID     `json:"id,omitempty"`
+	OrgID      ID     `json:"orgID"`
+	EndpointID ID     `json:"endpointID"`
+	AlertKey   string `json:"alertKey"`
+	// Channel and TS identify the message in slack.
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+	// Level, Text and Color are those of the latest status of the alert.
+	Level string `json:"level"`
+	Text  string `json:"text"`
+	Color string `json:"color,omitempty"`
+	// AcknowledgedBy is the slack user who acknowledged the alert.
+	AcknowledgedBy string     `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+	CRUDLog
+}
+
+// Acknowledged returns true if the alert was acknowledged.
+func (t *SlackThread) Acknowledged() bool {
+	return t.AcknowledgedAt != nil
+}
+
+// SlackThreadFilter represents a set of filters that restrict the returned
+// slack threads.
+type SlackThreadFilter struct {
+	OrgID      *ID
+	EndpointID *ID
+	AlertKey   *string
+}
+
+// Match returns true if the thread matches the filter.
+func (f SlackThreadFilter) Match(t *SlackThread) bool {
+	return (f.OrgID == nil || *f.OrgID == t.OrgID) &&
+		(f.EndpointID == nil || *f.EndpointID == t.EndpointID) &&
+		(f.AlertKey == nil || *f.AlertKey == t.AlertKey)
+}
@@ -0,0 +1,122 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"time"
+)
+
+// ErrSMTPConfigNotFound is the error when the SMTP server of the instance is
+// not configured.
+const ErrSMTPConfigNotFound = "smtp server is not configured"
+
+const (
+	OpFindSMTPConfig   = "FindSMTPConfig"
+	OpPutSMTPConfig    = "PutSMTPConfig"
+	OpDeleteSMTPConfig = "DeleteSMTPConfig"
+)
+
+// TLS modes of the connection to an SMTP server.
+const (
+	// SMTPTLSNone sends mail in plain text.
+	SMTPTLSNone = "none"
+	// SMTPTLSStartTLS upgrades the connection with the STARTTLS command.
+	SMTPTLSStartTLS = "starttls"
+	// SMTPTLSImplicit connects with TLS from the start.
+	SMTPTLSImplicit = "tls"
+)
+
+// SMTPConfigService manages the SMTP server of the instance, which email
+// notification endpoints send mail through.
+type SMTPConfigService interface {
+	// FindSMTPConfig returns the SMTP server of the instance. The password
+	// is not returned, only the key of its secret.
+	FindSMTPConfig(ctx context.Context) (*SMTPConfig, error)
+
+	// PutSMTPConfig replaces the SMTP server of the instance. The value of
+	// the password, if any, is stored in the secret store. A config without
+	// a password value keeps the stored password of the same username.
+	PutSMTPConfig(ctx context.Context, c *SMTPConfig) error
+
+	// DeleteSMTPConfig removes the SMTP server of the instance and its password.
+	DeleteSMTPConfig(ctx context.Context) error
+}
+
+// SMTPConfig is the SMTP server of the instance. It does not belong to an
+// organization, so its password is stored in the secret store under ID.
+type SMTPConfig struct {
+	ID   ID     `json:"id,omitempty"`
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	// TLS is one of none, starttls or tls.
+	TLS string `json:"tls"`
+	// InsecureSkipVerify disables the verification of the certificate of
+	// the server.
+	InsecureSkipVerify bool        `json:"insecureSkipVerify,omitempty"`
+	Username           string      `json:"username,omitempty"`
+	Password           SecretField `json:"password"`
+	// From is the address mail is sent from.
+	From      string    `json:"from"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SecretKey returns the key of the secret of the password.
+func (c *SMTPConfig) SecretKey() string {
+	return "smtp-password"
+}
+
+// SetDefaults sets the default TLS mode and the default port of the mode.
+func (c *SMTPConfig) SetDefaults() {
+	if c.TLS == "" {
+		c.TLS = SMTPTLSStartTLS
+	}
+	if c.Port == 0 {
+		switch c.TLS {
+		case SMTPTLSImplicit:
+			c.Port = 465
+		case SMTPTLSStartTLS:
+			c.Port = 587
+		default:
+			c.Port = 25
+		}
+	}
+}
+
+// Valid returns an error if the config is invalid.
+func (c *SMTPConfig) Valid() error {
+	if c.Host == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "smtp host is required",
+		}
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "smtp port must be between 1 and 65535",
+		}
+	}
+	switch c.TLS {
+	case SMTPTLSNone, SMTPTLSStartTLS, SMTPTLSImplicit:
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("unknown smtp tls mode %q", c.TLS),
+		}
+	}
+	if c.Username == "" && (c.Password.Key != "" || c.Password.Value != nil) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "smtp password requires a username",
+		}
+	}
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "smtp from address is invalid",
+			Err:  err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package influxdb
+
+import (
+	"fmt"
+	"time"
+)
+
+// SoftTTL hides the data of a bucket, or of one of its measurements, older
+// than TTL from queries without deleting it. It keeps data which must be
+// retained, for legal reasons for instance, out of day-to-day queries.
+//
+// Soft TTLs are applied by the query layer to the points read from storage;
+// tag keys and values are still read from every series of the bucket.
+type SoftTTL struct {
+	// Measurement restricts the TTL to the data of a measurement. The TTL
+	// without a measurement is the default of the bucket.
+	Measurement string `json:"measurement,omitempty"`
+	// TTL is the age of the oldest data queries read.
+	TTL Duration `json:"ttl"`
+}
+
+// ValidSoftTTLs returns an error if any of the soft TTLs of a bucket is
+// invalid, or if two of them apply to the same measurement.
+func ValidSoftTTLs(ttls []SoftTTL) error {
+	seen := make(map[string]bool, len(ttls))
+	for i, t := range ttls {
+		if t.TTL.Duration <= 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid soft TTL %d: TTL must be positive", i),
+			}
+		}
+		if seen[t.Measurement] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("duplicate soft TTL for measurement %q", t.Measurement),
+			}
+		}
+		seen[t.Measurement] = true
+	}
+	return nil
+}
+
+// FindSoftTTL returns the TTL of the data of measurement, which is the TTL
+// of the measurement if there is one and the default of the bucket
+// otherwise. It returns zero if neither exists.
+func FindSoftTTL(ttls []SoftTTL, measurement string) time.Duration {
+	var def time.Duration
+	for _, t := range ttls {
+		switch t.Measurement {
+		case measurement:
+			return t.TTL.Duration
+		case "":
+			def = t.TTL.Duration
+		}
+	}
+	return def
+}
@@ -0,0 +1,127 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrStaleResourceReportNotFound is the error for a missing stale resource report.
+const ErrStaleResourceReportNotFound = "stale resource report not found"
+
+const (
+	OpStartStaleResourceReport    = "StartStaleResourceReport"
+	OpFindStaleResourceReportByID = "FindStaleResourceReportByID"
+	OpFindStaleResourceReports    = "FindStaleResourceReports"
+	OpCreateStaleResourceReport   = "CreateStaleResourceReport"
+	OpPutStaleResourceReport      = "PutStaleResourceReport"
+	OpRecordResourceActivity      = "RecordResourceActivity"
+	OpFindResourceActivities      = "FindResourceActivities"
+)
+
+// ResourceActivityKind is a way a resource is used.
+type ResourceActivityKind string
+
+const (
+	// ResourceActivityRead is the reading of a bucket by a query or of a
+	// dashboard by a client.
+	ResourceActivityRead ResourceActivityKind = "read"
+	// ResourceActivityWrite is the writing of points to a bucket.
+	ResourceActivityWrite ResourceActivityKind = "write"
+	// ResourceActivityRun is the run of a task.
+	ResourceActivityRun ResourceActivityKind = "run"
+	// ResourceActivityUse is the use of a token to authenticate a request.
+	ResourceActivityUse ResourceActivityKind = "use"
+)
+
+// ResourceActivity is when a resource was last read and written. Activity
+// is sampled, so the times may lag the actual activity by the sampling
+// interval.
+type ResourceActivity struct {
+	ResourceType  ResourceType `json:"resourceType"`
+	ResourceID    ID           `json:"resourceID"`
+	LastReadAt    *time.Time   `json:"lastReadAt,omitempty"`
+	LastWrittenAt *time.Time   `json:"lastWrittenAt,omitempty"`
+}
+
+// ResourceActivityService records when buckets and dashboards are read and
+// written. The runs of tasks and the use of tokens are recorded on the
+// tasks and tokens themselves.
+type ResourceActivityService interface {
+	// RecordResourceActivity records that the resource was read or written
+	// at the time. Activity older than the activity recorded is ignored.
+	RecordResourceActivity(ctx context.Context, rt ResourceType, id ID, kind ResourceActivityKind, at time.Time) error
+
+	// FindResourceActivities returns the activity recorded for the
+	// resources of the type.
+	FindResourceActivities(ctx context.Context, rt ResourceType) ([]*ResourceActivity, error)
+}
+
+// StaleResourceReportStatus is the status of a stale resource report.
+type StaleResourceReportStatus string
+
+const (
+	StaleResourceReportRunning  StaleResourceReportStatus = "running"
+	StaleResourceReportFailed   StaleResourceReportStatus = "failed"
+	StaleResourceReportComplete StaleResourceReportStatus = "complete"
+)
+
+// StaleResourceReport lists the buckets, dashboards, tasks and tokens of
+// every organization with no activity in the last Days days, for the owners
+// of an instance to clean up the resources no longer in use.
+type StaleResourceReport struct {
+	ID          ID                        `json:"id,omitempty"`
+	Days        int                       `json:"days"`
+	Status      StaleResourceReportStatus `json:"status"`
+	Error       string                    `json:"error,omitempty"`
+	Resources   []StaleResource           `json:"resources"`
+	RequestedBy ID                        `json:"requestedBy,omitempty"`
+	StartedAt   time.Time                 `json:"startedAt"`
+	UpdatedAt   time.Time                 `json:"updatedAt"`
+	CompletedAt *time.Time                `json:"completedAt,omitempty"`
+}
+
+// Valid returns an error if the report covers no days.
+func (r *StaleResourceReport) Valid() error {
+	if r.Days < 1 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "days must be greater than 0",
+		}
+	}
+	return nil
+}
+
+// StaleResource is a resource of a stale resource report.
+type StaleResource struct {
+	Type      ResourceType `json:"type"`
+	ID        ID           `json:"id"`
+	OrgID     ID           `json:"orgID"`
+	Name      string       `json:"name"`
+	CreatedAt time.Time    `json:"createdAt"`
+	// LastActivity is the kind of the last activity of the resource, and
+	// LastActiveAt its time. They are missing if the resource was never
+	// used since activity was first recorded.
+	LastActivity ResourceActivityKind `json:"lastActivity,omitempty"`
+	LastActiveAt *time.Time           `json:"lastActiveAt,omitempty"`
+}
+
+// StaleResourceReportFilter represents a set of filters that restrict the
+// returned reports.
+type StaleResourceReportFilter struct {
+	Status *StaleResourceReportStatus
+}
+
+// StaleResourceReportService reports the resources of an instance which are
+// no longer in use.
+type StaleResourceReportService interface {
+	// StartStaleResourceReport starts finding the resources with no
+	// activity in the last days days in the background.
+	StartStaleResourceReport(ctx context.Context, days int) (*StaleResourceReport, error)
+
+	// FindStaleResourceReportByID returns a single report by ID.
+	FindStaleResourceReportByID(ctx context.Context, id ID) (*StaleResourceReport, error)
+
+	// FindStaleResourceReports returns the reports that match filter,
+	// without their resources.
+	FindStaleResourceReports(ctx context.Context, filter StaleResourceReportFilter) ([]*StaleResourceReport, error)
+}
@@ -0,0 +1,52 @@
+package staleresource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+type activityKey struct {
+	rt   influxdb.ResourceType
+	id   influxdb.ID
+	kind influxdb.ResourceActivityKind
+}
+
+// ActivityRecorder is an influxdb.ResourceActivityService which records each
+// kind of activity of a resource at most once per interval, so that busy
+// buckets and dashboards are not recorded on every request.
+type ActivityRecorder struct {
+	influxdb.ResourceActivityService
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[activityKey]time.Time
+}
+
+// NewActivityRecorder returns an ActivityRecorder recording the activity of
+// resources in s at most once per interval.
+func NewActivityRecorder(s influxdb.ResourceActivityService, interval time.Duration) *ActivityRecorder {
+	return &ActivityRecorder{
+		ResourceActivityService: s,
+		interval:                interval,
+		last:                    make(map[activityKey]time.Time),
+	}
+}
+
+// RecordResourceActivity records the activity of the resource unless the
+// same kind of activity of the resource was recorded within the interval.
+func (r *ActivityRecorder) RecordResourceActivity(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID, kind influxdb.ResourceActivityKind, at time.Time) error {
+	k := activityKey{rt: rt, id: id, kind: kind}
+
+	r.mu.Lock()
+	if last, ok := r.last[k]; ok && at.Sub(last) < r.interval {
+		r.mu.Unlock()
+		return nil
+	}
+	r.last[k] = at
+	r.mu.Unlock()
+
+	return r.ResourceActivityService.RecordResourceActivity(ctx, rt, id, kind, at)
+}
@@ -0,0 +1,172 @@
+package staleresource
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// stale returns true if the resource was neither active nor created since
+// cutoff.
+func stale(r *influxdb.StaleResource, cutoff time.Time) bool {
+	if r.LastActiveAt != nil {
+		return r.LastActiveAt.Before(cutoff)
+	}
+	return r.CreatedAt.Before(cutoff)
+}
+
+// setLastActivity sets the last activity of r to the latest of the reads
+// and writes of a, if any.
+func setLastActivity(r *influxdb.StaleResource, a *influxdb.ResourceActivity) {
+	if a == nil {
+		return
+	}
+	if a.LastReadAt != nil {
+		r.LastActivity = influxdb.ResourceActivityRead
+		r.LastActiveAt = a.LastReadAt
+	}
+	if a.LastWrittenAt != nil && (r.LastActiveAt == nil || a.LastWrittenAt.After(*r.LastActiveAt)) {
+		r.LastActivity = influxdb.ResourceActivityWrite
+		r.LastActiveAt = a.LastWrittenAt
+	}
+}
+
+// findActivities returns the activity recorded for the resources of the
+// type by their ID.
+func (s *Service) findActivities(ctx context.Context, rt influxdb.ResourceType) (map[influxdb.ID]*influxdb.ResourceActivity, error) {
+	as, err := s.activity.FindResourceActivities(ctx, rt)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[influxdb.ID]*influxdb.ResourceActivity, len(as))
+	for _, a := range as {
+		m[a.ResourceID] = a
+	}
+	return m, nil
+}
+
+// staleBuckets returns the buckets neither read nor written since cutoff.
+// System buckets are written by the instance itself, and never reported.
+func (s *Service) staleBuckets(ctx context.Context, cutoff time.Time) ([]influxdb.StaleResource, error) {
+	activities, err := s.findActivities(ctx, influxdb.BucketsResourceType)
+	if err != nil {
+		return nil, err
+	}
+	bs, _, err := s.bucketSvc.FindBuckets(ctx, influxdb.BucketFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []influxdb.StaleResource
+	for _, b := range bs {
+		if b.Type == influxdb.BucketTypeSystem {
+			continue
+		}
+		r := influxdb.StaleResource{
+			Type:      influxdb.BucketsResourceType,
+			ID:        b.ID,
+			OrgID:     b.OrgID,
+			Name:      b.Name,
+			CreatedAt: b.CreatedAt,
+		}
+		setLastActivity(&r, activities[b.ID])
+		if stale(&r, cutoff) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// staleDashboards returns the dashboards not read since cutoff.
+func (s *Service) staleDashboards(ctx context.Context, cutoff time.Time) ([]influxdb.StaleResource, error) {
+	activities, err := s.findActivities(ctx, influxdb.DashboardsResourceType)
+	if err != nil {
+		return nil, err
+	}
+	ds, _, err := s.dashboardSvc.FindDashboards(ctx, influxdb.DashboardFilter{}, influxdb.FindOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []influxdb.StaleResource
+	for _, d := range ds {
+		r := influxdb.StaleResource{
+			Type:      influxdb.DashboardsResourceType,
+			ID:        d.ID,
+			OrgID:     d.OrganizationID,
+			Name:      d.Name,
+			CreatedAt: d.Meta.CreatedAt,
+		}
+		setLastActivity(&r, activities[d.ID])
+		if stale(&r, cutoff) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// staleTasks returns the tasks which have not run since cutoff, including
+// the inactive ones.
+func (s *Service) staleTasks(ctx context.Context, cutoff time.Time) ([]influxdb.StaleResource, error) {
+	var res []influxdb.StaleResource
+	filter := influxdb.TaskFilter{
+		Limit: influxdb.TaskMaxPageSize,
+	}
+	for {
+		ts, _, err := s.taskSvc.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range ts {
+			r := influxdb.StaleResource{
+				Type:      influxdb.TasksResourceType,
+				ID:        t.ID,
+				OrgID:     t.OrganizationID,
+				Name:      t.Name,
+				CreatedAt: t.CreatedAt,
+			}
+			// The latest completed run of a task which never ran is
+			// the time it was created.
+			if t.LatestCompleted.After(t.CreatedAt) {
+				latest := t.LatestCompleted
+				r.LastActivity = influxdb.ResourceActivityRun
+				r.LastActiveAt = &latest
+			}
+			if stale(&r, cutoff) {
+				res = append(res, r)
+			}
+		}
+		if len(ts) < filter.Limit {
+			return res, nil
+		}
+		filter.After = &ts[len(ts)-1].ID
+	}
+}
+
+// staleAuthorizations returns the tokens not used since cutoff.
+func (s *Service) staleAuthorizations(ctx context.Context, cutoff time.Time) ([]influxdb.StaleResource, error) {
+	as, _, err := s.authSvc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var res []influxdb.StaleResource
+	for _, a := range as {
+		r := influxdb.StaleResource{
+			Type:      influxdb.AuthorizationsResourceType,
+			ID:        a.ID,
+			OrgID:     a.OrgID,
+			Name:      a.Description,
+			CreatedAt: a.CreatedAt,
+		}
+		if a.LastUsedAt != nil {
+			r.LastActivity = influxdb.ResourceActivityUse
+			r.LastActiveAt = a.LastUsedAt
+		}
+		if stale(&r, cutoff) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,196 @@
+// Package staleresource reports the buckets, dashboards, tasks and tokens of
+// an instance which are no longer read, written, run or used, so that the
+// owners of the instance may clean them up.
+package staleresource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/jobs"
+	"go.uber.org/zap"
+)
+
+// Store persists the stale resource reports.
+type Store interface {
+	CreateStaleResourceReport(ctx context.Context, r *influxdb.StaleResourceReport) error
+	PutStaleResourceReport(ctx context.Context, r *influxdb.StaleResourceReport) error
+	FindStaleResourceReportByID(ctx context.Context, id influxdb.ID) (*influxdb.StaleResourceReport, error)
+	FindStaleResourceReports(ctx context.Context, filter influxdb.StaleResourceReportFilter) ([]*influxdb.StaleResourceReport, error)
+}
+
+// Service runs stale resource reports in the background.
+type Service struct {
+	log      *zap.Logger
+	store    Store
+	activity influxdb.ResourceActivityService
+
+	bucketSvc    influxdb.BucketService
+	dashboardSvc influxdb.DashboardService
+	authSvc      influxdb.AuthorizationService
+	taskSvc      influxdb.TaskService
+
+	// Jobs is optional. If set, reports run as its jobs, which may be
+	// listed and canceled.
+	Jobs *jobs.Manager
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ influxdb.StaleResourceReportService = (*Service)(nil)
+
+// NewService constructs a new Service. The last reads and writes of
+// buckets and dashboards are found in activity.
+func NewService(log *zap.Logger, store Store, activity influxdb.ResourceActivityService, bucketSvc influxdb.BucketService, dashboardSvc influxdb.DashboardService, authSvc influxdb.AuthorizationService, taskSvc influxdb.TaskService) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		log:          log,
+		store:        store,
+		activity:     activity,
+		bucketSvc:    bucketSvc,
+		dashboardSvc: dashboardSvc,
+		authSvc:      authSvc,
+		taskSvc:      taskSvc,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Open runs again the reports that were running when the service was closed.
+func (s *Service) Open(ctx context.Context) error {
+	status := influxdb.StaleResourceReportRunning
+	rs, err := s.store.FindStaleResourceReports(ctx, influxdb.StaleResourceReportFilter{Status: &status})
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		s.log.Info("Restarting stale resource report", zap.Stringer("reportID", r.ID))
+		s.start(r)
+	}
+	return nil
+}
+
+// Close stops the running reports and waits for them to return. Stopped
+// reports are run again by Open.
+func (s *Service) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// StartStaleResourceReport starts finding the resources with no activity in
+// the last days days in the background.
+func (s *Service) StartStaleResourceReport(ctx context.Context, days int) (*influxdb.StaleResourceReport, error) {
+	r := &influxdb.StaleResourceReport{
+		Days:      days,
+		Status:    influxdb.StaleResourceReportRunning,
+		Resources: []influxdb.StaleResource{},
+	}
+	if err := r.Valid(); err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpStartStaleResourceReport,
+			Err: err,
+		}
+	}
+	if uid, err := icontext.GetUserID(ctx); err == nil {
+		r.RequestedBy = uid
+	}
+	if err := s.store.CreateStaleResourceReport(ctx, r); err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpStartStaleResourceReport,
+			Err: err,
+		}
+	}
+
+	res := *r
+	s.start(r)
+	return &res, nil
+}
+
+// start runs the report in the background.
+func (s *Service) start(r *influxdb.StaleResourceReport) {
+	s.wg.Add(1)
+	fn := func(ctx context.Context, p *jobs.Progress) error {
+		defer s.wg.Done()
+		return s.run(ctx, r, p)
+	}
+
+	if s.Jobs == nil {
+		go fn(s.ctx, nil)
+		return
+	}
+	s.Jobs.Start(s.ctx, &influxdb.Job{
+		Kind:       influxdb.JobKindStaleResourceReport,
+		ResourceID: r.ID,
+	}, fn)
+}
+
+func (s *Service) run(ctx context.Context, r *influxdb.StaleResourceReport, p *jobs.Progress) error {
+	log := s.log.With(zap.Stringer("reportID", r.ID), zap.Int("days", r.Days))
+
+	cutoff := time.Now().UTC().Add(-time.Duration(r.Days) * 24 * time.Hour)
+	finders := []struct {
+		stage string
+		find  func(ctx context.Context, cutoff time.Time) ([]influxdb.StaleResource, error)
+	}{
+		{"buckets", s.staleBuckets},
+		{"dashboards", s.staleDashboards},
+		{"tasks", s.staleTasks},
+		{"tokens", s.staleAuthorizations},
+	}
+
+	resources := []influxdb.StaleResource{}
+	p.SetTotal(int64(len(finders)))
+	for _, f := range finders {
+		p.SetStage(f.stage)
+		found, err := f.find(ctx, cutoff)
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			if ctx.Err() != nil && s.ctx.Err() != nil {
+				// The report is run again by Open.
+				return err
+			}
+			log.Error("Stale resource report failed", zap.String("stage", f.stage), zap.Error(err))
+			r.Status = influxdb.StaleResourceReportFailed
+			r.Error = err.Error()
+			if ctx.Err() != nil {
+				r.Error = "report canceled"
+			}
+			if err := s.store.PutStaleResourceReport(context.Background(), r); err != nil {
+				log.Error("Failed to store stale resource report", zap.Error(err))
+			}
+			return err
+		}
+		resources = append(resources, found...)
+		p.Add(1)
+	}
+
+	now := time.Now().UTC()
+	r.Resources = resources
+	r.Status = influxdb.StaleResourceReportComplete
+	r.CompletedAt = &now
+	if err := s.store.PutStaleResourceReport(context.Background(), r); err != nil {
+		log.Error("Failed to store stale resource report", zap.Error(err))
+		return err
+	}
+	log.Info("Stale resource report complete", zap.Int("resources", len(resources)))
+	return nil
+}
+
+// FindStaleResourceReportByID returns a single report by ID.
+func (s *Service) FindStaleResourceReportByID(ctx context.Context, id influxdb.ID) (*influxdb.StaleResourceReport, error) {
+	return s.store.FindStaleResourceReportByID(ctx, id)
+}
+
+// FindStaleResourceReports returns the reports that match filter, without
+// their resources.
+func (s *Service) FindStaleResourceReports(ctx context.Context, filter influxdb.StaleResourceReportFilter) ([]*influxdb.StaleResourceReport, error) {
+	return s.store.FindStaleResourceReports(ctx, filter)
+}
@@ -0,0 +1,137 @@
+package staleresource_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/staleresource"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_StartStaleResourceReport(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Create the resources a year ago.
+	store.TimeGenerator = mock.TimeGenerator{FakeValue: time.Now().UTC().AddDate(-1, 0, 0)}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	user := &influxdb.User{Name: "user", Status: influxdb.Active}
+	if err := store.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	unused := &influxdb.Bucket{OrgID: org.ID, Name: "unused"}
+	if err := store.CreateBucket(ctx, unused); err != nil {
+		t.Fatal(err)
+	}
+	written := &influxdb.Bucket{OrgID: org.ID, Name: "written"}
+	if err := store.CreateBucket(ctx, written); err != nil {
+		t.Fatal(err)
+	}
+	dashboard := &influxdb.Dashboard{OrganizationID: org.ID, Name: "dashboard"}
+	if err := store.CreateDashboard(ctx, dashboard); err != nil {
+		t.Fatal(err)
+	}
+	auth := &influxdb.Authorization{
+		OrgID:  org.ID,
+		UserID: user.ID,
+		Permissions: []influxdb.Permission{{
+			Action:   influxdb.ReadAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &org.ID},
+		}},
+	}
+	if err := store.CreateAuthorization(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+	store.TimeGenerator = influxdb.RealTimeGenerator{}
+
+	if err := store.RecordResourceActivity(ctx, influxdb.BucketsResourceType, written.ID, influxdb.ResourceActivityWrite, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := staleresource.NewService(zaptest.NewLogger(t), store, store, store, store, store, store)
+	defer svc.Close()
+
+	if _, err := svc.StartStaleResourceReport(ctx, 0); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a report of no days to be invalid, got %v", err)
+	}
+
+	r, err := svc.StartStaleResourceReport(ctx, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Status == influxdb.StaleResourceReportRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("stale resource report did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r, err = svc.FindStaleResourceReportByID(ctx, r.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.Status != influxdb.StaleResourceReportComplete || r.CompletedAt == nil {
+		t.Fatalf("expected report to complete, got status %q: %s", r.Status, r.Error)
+	}
+
+	stale := make(map[influxdb.ID]influxdb.StaleResource)
+	for _, res := range r.Resources {
+		stale[res.ID] = res
+	}
+	for _, id := range []influxdb.ID{unused.ID, dashboard.ID, auth.ID} {
+		if _, ok := stale[id]; !ok {
+			t.Errorf("expected resource %s to be stale", id)
+		}
+	}
+	if _, ok := stale[written.ID]; ok {
+		t.Errorf("expected written bucket not to be stale")
+	}
+	// System buckets are never reported.
+	if len(r.Resources) != 3 {
+		t.Errorf("expected 3 stale resources, got %+v", r.Resources)
+	}
+}
+
+type activityCounter struct {
+	influxdb.ResourceActivityService
+	recorded int
+}
+
+func (c *activityCounter) RecordResourceActivity(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID, kind influxdb.ResourceActivityKind, at time.Time) error {
+	c.recorded++
+	return nil
+}
+
+func TestActivityRecorder(t *testing.T) {
+	ctx := context.Background()
+	c := &activityCounter{}
+	r := staleresource.NewActivityRecorder(c, time.Hour)
+
+	now := time.Now()
+	record := func(id influxdb.ID, kind influxdb.ResourceActivityKind, at time.Time) {
+		t.Helper()
+		if err := r.RecordResourceActivity(ctx, influxdb.BucketsResourceType, id, kind, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record(1, influxdb.ResourceActivityWrite, now)
+	record(1, influxdb.ResourceActivityWrite, now.Add(time.Minute))
+	record(1, influxdb.ResourceActivityRead, now.Add(time.Minute))
+	record(2, influxdb.ResourceActivityWrite, now.Add(time.Minute))
+	record(1, influxdb.ResourceActivityWrite, now.Add(time.Hour))
+
+	if c.recorded != 4 {
+		t.Errorf("expected 4 activities to be recorded, got %d", c.recorded)
+	}
+}
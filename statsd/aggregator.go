@@ -0,0 +1,184 @@
+package statsd
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// series is the aggregated values of a metric with a type and tags.
+type series struct {
+	name       string
+	tags       models.Tags
+	metricType string
+	// updated is set when a sample was added since the last flush.
+	updated bool
+
+	// value is the sum of counters, or the value of gauges.
+	value float64
+	set   map[string]struct{}
+	timer timerStats
+}
+
+// timerStats are the statistics of the values of a timer.
+type timerStats struct {
+	count  int64
+	sum    float64
+	sumSq  float64
+	lower  float64
+	upper  float64
+	values []float64
+}
+
+func (t *timerStats) add(v float64) {
+	if t.count == 0 || v < t.lower {
+		t.lower = v
+	}
+	if t.count == 0 || v > t.upper {
+		t.upper = v
+	}
+	t.count++
+	t.sum += v
+	t.sumSq += v * v
+
+	// Reservoir sampling bounds the values that percentiles are computed from.
+	if len(t.values) < percentileLimit {
+		t.values = append(t.values, v)
+	} else if i := rand.Int63n(t.count); i < percentileLimit {
+		t.values[i] = v
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func (t *timerStats) percentile(p float64) float64 {
+	i := int(math.Ceil(p/100*float64(len(t.values)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return t.values[i]
+}
+
+// aggregator aggregates the samples of metrics until they are flushed.
+type aggregator struct {
+	percentiles []float64
+	series      map[string]*series
+}
+
+func newAggregator(percentiles []float64) *aggregator {
+	return &aggregator{
+		percentiles: percentiles,
+		series:      make(map[string]*series),
+	}
+}
+
+func (a *aggregator) add(s sample) {
+	key := seriesKey(s)
+	ser, ok := a.series[key]
+	if !ok {
+		tags := models.NewTags(s.tags)
+		tags.SetString("metric_type", s.metricType)
+		ser = &series{
+			name:       s.name,
+			tags:       tags,
+			metricType: s.metricType,
+		}
+		a.series[key] = ser
+	}
+	ser.updated = true
+
+	switch s.metricType {
+	case typeCounter:
+		ser.value += s.value / s.rate
+	case typeGauge:
+		if s.relative {
+			ser.value += s.value
+		} else {
+			ser.value = s.value
+		}
+	case typeSet:
+		if ser.set == nil {
+			ser.set = make(map[string]struct{})
+		}
+		ser.set[s.set] = struct{}{}
+	case typeTiming:
+		// A sampled value stands for the values that were not sent.
+		n := int(math.Round(1 / s.rate))
+		if n < 1 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			ser.timer.add(s.value)
+		}
+	}
+}
+
+func seriesKey(s sample) string {
+	keys := make([]string, 0, len(s.tags))
+	for k := range s.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(s.metricType)
+	b.WriteByte(0)
+	b.WriteString(s.name)
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(s.tags[k])
+	}
+	return b.String()
+}
+
+// flush returns the points of the metrics updated since the last flush, at
+// time t, and resets them. Gauges keep their value, so that later samples
+// can change it relatively.
+func (a *aggregator) flush(t time.Time) []models.Point {
+	var points []models.Point
+	for key, ser := range a.series {
+		if !ser.updated {
+			continue
+		}
+
+		fields := models.Fields{}
+		switch ser.metricType {
+		case typeCounter, typeGauge:
+			fields["value"] = ser.value
+		case typeSet:
+			fields["value"] = int64(len(ser.set))
+		case typeTiming:
+			tm := &ser.timer
+			mean := tm.sum / float64(tm.count)
+			fields["count"] = tm.count
+			fields["sum"] = tm.sum
+			fields["mean"] = mean
+			fields["lower"] = tm.lower
+			fields["upper"] = tm.upper
+			fields["stddev"] = math.Sqrt(math.Max(tm.sumSq/float64(tm.count)-mean*mean, 0))
+			sort.Float64s(tm.values)
+			for _, p := range a.percentiles {
+				fields[strconv.FormatFloat(p, 'f', -1, 64)+"_percentile"] = tm.percentile(p)
+			}
+		}
+
+		// Points with values that cannot be written, such as infinite
+		// gauges, are dropped.
+		if p, err := models.NewPoint(ser.name, ser.tags, fields, t); err == nil {
+			points = append(points, p)
+		}
+
+		if ser.metricType == typeGauge {
+			ser.updated = false
+		} else {
+			delete(a.series, key)
+		}
+	}
+	return points
+}
@@ -0,0 +1,76 @@
+package statsd
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func flushLines(a *aggregator, t time.Time) string {
+	var lines []string
+	for _, p := range a.flush(t) {
+		lines = append(lines, p.String())
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func TestAggregator(t *testing.T) {
+	a := newAggregator([]float64{50, 90})
+	for _, line := range []string{
+		"requests,host=a:1|c",
+		"requests,host=a:1|c|@0.5",
+		"requests,host=b:1|c",
+		"temperature:20|g",
+		"temperature:+2|g",
+		"users:alice|s",
+		"users:bob|s",
+		"users:alice|s",
+		"latency:10|ms",
+		"latency:20|ms|@0.5",
+		"latency:40|ms",
+	} {
+		s, err := parseLine(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.add(s)
+	}
+
+	got := flushLines(a, time.Unix(0, 1000))
+	want := strings.Join([]string{
+		`latency,metric_type=timing 50_percentile=20,90_percentile=40,count=4i,lower=10,mean=22.5,stddev=10.897247358851684,sum=90,upper=40 1000`,
+		`requests,host=a,metric_type=counter value=3 1000`,
+		`requests,host=b,metric_type=counter value=1 1000`,
+		`temperature,metric_type=gauge value=22 1000`,
+		`users,metric_type=set value=2i 1000`,
+	}, "\n")
+	if got != want {
+		t.Fatalf("unexpected points:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Only gauges are kept between flushes, and they are only written when
+	// they are updated.
+	if got := flushLines(a, time.Unix(0, 2000)); got != "" {
+		t.Fatalf("unexpected points:\n%s", got)
+	}
+	s, _ := parseLine("temperature:-5|g")
+	a.add(s)
+	if got, want := flushLines(a, time.Unix(0, 3000)), `temperature,metric_type=gauge value=17 3000`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestTimerStats_Reservoir(t *testing.T) {
+	var tm timerStats
+	for i := 0; i < 10*percentileLimit; i++ {
+		tm.add(float64(i))
+	}
+	if len(tm.values) != percentileLimit {
+		t.Fatalf("got %d values, want %d", len(tm.values), percentileLimit)
+	}
+	if tm.count != 10*percentileLimit || tm.lower != 0 || tm.upper != 10*percentileLimit-1 {
+		t.Fatalf("unexpected stats count=%d lower=%v upper=%v", tm.count, tm.lower, tm.upper)
+	}
+}
@@ -0,0 +1,88 @@
+package statsd
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+const (
+	// DefaultFlushInterval is the default interval at which aggregated
+	// metrics are written.
+	DefaultFlushInterval = 10 * time.Second
+
+	// percentileLimit is the maximum number of values of a timer that
+	// percentiles are computed from.
+	percentileLimit = 1000
+)
+
+// DefaultPercentiles are the default percentiles of timers.
+var DefaultPercentiles = []float64{90}
+
+// Config configures a Listener.
+type Config struct {
+	// Network is "udp" or "tcp".
+	Network string
+
+	// BindAddress is the address the listener binds to.
+	BindAddress string
+
+	// BucketID is the bucket metrics are written to.
+	BucketID influxdb.ID
+
+	// FlushInterval is how often aggregated metrics are written.
+	FlushInterval time.Duration
+
+	// Percentiles are the percentiles of the values of timers that are
+	// written, between 0 and 100.
+	Percentiles []float64
+}
+
+// ParseConfig parses the configuration of a listener in the form
+// "<udp|tcp>://<bind address>?bucket=<bucket id>", with optional
+// "flush-interval=<duration>" and "percentiles=<percentile>,..." parameters.
+func ParseConfig(s string) (Config, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid statsd listener %q: %v", s, err)
+	}
+
+	c := Config{
+		Network:       u.Scheme,
+		BindAddress:   u.Host,
+		FlushInterval: DefaultFlushInterval,
+		Percentiles:   DefaultPercentiles,
+	}
+	if c.Network != "udp" && c.Network != "tcp" {
+		return Config{}, fmt.Errorf("invalid statsd listener %q: expected udp:// or tcp://", s)
+	}
+
+	q := u.Query()
+	id, err := influxdb.IDFromString(q.Get("bucket"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid bucket ID for statsd listener %q: %v", s, err)
+	}
+	c.BucketID = *id
+
+	if v := q.Get("flush-interval"); v != "" {
+		if c.FlushInterval, err = time.ParseDuration(v); err != nil || c.FlushInterval <= 0 {
+			return Config{}, fmt.Errorf("invalid flush interval %q for statsd listener %q", v, s)
+		}
+	}
+
+	if v := q.Get("percentiles"); v != "" {
+		c.Percentiles = nil
+		for _, p := range strings.Split(v, ",") {
+			f, err := strconv.ParseFloat(p, 64)
+			if err != nil || f <= 0 || f > 100 {
+				return Config{}, fmt.Errorf("invalid percentile %q for statsd listener %q", p, s)
+			}
+			c.Percentiles = append(c.Percentiles, f)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,65 @@
+package statsd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Config
+	}{
+		{
+			s: "udp://:8125?bucket=020f755c3c082000",
+			want: Config{
+				Network:       "udp",
+				BindAddress:   ":8125",
+				BucketID:      influxdb.ID(0x020f755c3c082000),
+				FlushInterval: DefaultFlushInterval,
+				Percentiles:   DefaultPercentiles,
+			},
+		},
+		{
+			s: "tcp://127.0.0.1:8126?bucket=020f755c3c082000&flush-interval=1m&percentiles=50,99.9",
+			want: Config{
+				Network:       "tcp",
+				BindAddress:   "127.0.0.1:8126",
+				BucketID:      influxdb.ID(0x020f755c3c082000),
+				FlushInterval: time.Minute,
+				Percentiles:   []float64{50, 99.9},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseConfig(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	for _, s := range []string{
+		"http://:8125?bucket=020f755c3c082000",
+		"udp://:8125",
+		"udp://:8125?bucket=020f755c3c082000&flush-interval=0s",
+		"udp://:8125?bucket=020f755c3c082000&flush-interval=soon",
+		"udp://:8125?bucket=020f755c3c082000&percentiles=0",
+		"udp://:8125?bucket=020f755c3c082000&percentiles=50,101",
+	} {
+		t.Run(s, func(t *testing.T) {
+			if _, err := ParseConfig(s); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
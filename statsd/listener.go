@@ -0,0 +1,257 @@
+// Package statsd implements listeners of the StatsD protocol, which aggregate
+// metrics and write them to a bucket at a flush interval.
+package statsd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+// maxPacketSize is the maximum size of a UDP packet.
+const maxPacketSize = 64 * 1024
+
+// BucketFinder finds the bucket metrics are written to.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// Listener receives StatsD metrics over UDP or TCP. Samples are aggregated
+// per metric, type and tags, and written to the bucket of the config every
+// flush interval.
+type Listener struct {
+	log     *zap.Logger
+	config  Config
+	writer  storage.PointsWriter
+	buckets BucketFinder
+
+	mu  sync.Mutex
+	agg *aggregator
+
+	packetConn net.PacketConn
+	ln         net.Listener
+	conns      map[net.Conn]struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewListener returns a Listener which writes the metrics it receives to w.
+// It must be opened before use.
+func NewListener(log *zap.Logger, config Config, w storage.PointsWriter, buckets BucketFinder) *Listener {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	return &Listener{
+		log:     log,
+		config:  config,
+		writer:  w,
+		buckets: buckets,
+		agg:     newAggregator(config.Percentiles),
+		conns:   make(map[net.Conn]struct{}),
+	}
+}
+
+// Open binds the listener and starts receiving and flushing metrics.
+func (l *Listener) Open(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	switch l.config.Network {
+	case "tcp":
+		ln, err := net.Listen("tcp", l.config.BindAddress)
+		if err != nil {
+			cancel()
+			return err
+		}
+		l.ln = ln
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			l.serveTCP(ctx)
+		}()
+	default:
+		conn, err := net.ListenPacket("udp", l.config.BindAddress)
+		if err != nil {
+			cancel()
+			return err
+		}
+		l.packetConn = conn
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			l.serveUDP(ctx)
+		}()
+	}
+
+	l.cancel = cancel
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		l.run(ctx)
+	}()
+	return nil
+}
+
+// Addr returns the address the listener is bound to.
+func (l *Listener) Addr() net.Addr {
+	if l.ln != nil {
+		return l.ln.Addr()
+	}
+	if l.packetConn != nil {
+		return l.packetConn.LocalAddr()
+	}
+	return nil
+}
+
+// Close stops receiving metrics and writes the metrics aggregated since the
+// last flush.
+func (l *Listener) Close() error {
+	if l.cancel == nil {
+		return nil
+	}
+	l.cancel()
+
+	var err error
+	if l.ln != nil {
+		err = l.ln.Close()
+		l.mu.Lock()
+		for c := range l.conns {
+			c.Close()
+		}
+		l.mu.Unlock()
+	}
+	if l.packetConn != nil {
+		err = l.packetConn.Close()
+	}
+	l.wg.Wait()
+
+	l.flush(context.Background())
+	return err
+}
+
+func (l *Listener) serveUDP(ctx context.Context) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := l.packetConn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			l.log.Debug("Failed to read StatsD packet", zap.Error(err))
+			continue
+		}
+		for _, line := range bytes.Split(buf[:n], []byte("\n")) {
+			l.handleLine(string(line))
+		}
+	}
+}
+
+func (l *Listener) serveTCP(ctx context.Context) {
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			l.log.Debug("Failed to accept StatsD connection", zap.Error(err))
+			continue
+		}
+
+		l.mu.Lock()
+		if ctx.Err() != nil {
+			l.mu.Unlock()
+			conn.Close()
+			return
+		}
+		l.conns[conn] = struct{}{}
+		l.mu.Unlock()
+
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			l.handleConn(conn)
+		}()
+	}
+}
+
+func (l *Listener) handleConn(conn net.Conn) {
+	defer func() {
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), maxPacketSize)
+	for scanner.Scan() {
+		l.handleLine(scanner.Text())
+	}
+}
+
+func (l *Listener) handleLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	s, err := parseLine(line)
+	if err != nil {
+		l.log.Debug("Failed to parse StatsD line", zap.String("line", line), zap.Error(err))
+		return
+	}
+
+	l.mu.Lock()
+	l.agg.add(s)
+	l.mu.Unlock()
+}
+
+func (l *Listener) run(ctx context.Context) {
+	ticker := time.NewTicker(l.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.flush(ctx)
+		}
+	}
+}
+
+// flush writes the metrics aggregated since the last flush. Metrics which
+// cannot be written are dropped.
+func (l *Listener) flush(ctx context.Context) {
+	l.mu.Lock()
+	points := l.agg.flush(time.Now())
+	l.mu.Unlock()
+	if len(points) == 0 {
+		return
+	}
+
+	// The organization is looked up on every flush so that metrics are
+	// written once a missing bucket is created.
+	bucket, err := l.buckets.FindBucketByID(ctx, l.config.BucketID)
+	if err != nil {
+		l.log.Warn("Failed to find StatsD bucket, dropping metrics",
+			zap.Stringer("bucket_id", l.config.BucketID), zap.Int("points", len(points)), zap.Error(err))
+		return
+	}
+
+	points, err = tsdb.ExplodePoints(bucket.OrgID, bucket.ID, points)
+	if err != nil {
+		l.log.Warn("Failed to write StatsD metrics", zap.Error(err))
+		return
+	}
+	if err := l.writer.WritePoints(ctx, points); err != nil {
+		l.log.Warn("Failed to write StatsD metrics", zap.Int("points", len(points)), zap.Error(err))
+	}
+}
@@ -0,0 +1,119 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+type bucketFinder map[influxdb.ID]*influxdb.Bucket
+
+func (f bucketFinder) FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	if b, ok := f[id]; ok {
+		return b, nil
+	}
+	return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+}
+
+func TestListener(t *testing.T) {
+	const (
+		orgID    = influxdb.ID(1)
+		bucketID = influxdb.ID(2)
+	)
+	buckets := bucketFinder{bucketID: {ID: bucketID, OrgID: orgID}}
+
+	for _, network := range []string{"udp", "tcp"} {
+		t.Run(network, func(t *testing.T) {
+			w := &mock.PointsWriter{}
+			l := NewListener(zaptest.NewLogger(t), Config{
+				Network:       network,
+				BindAddress:   "127.0.0.1:0",
+				BucketID:      bucketID,
+				FlushInterval: time.Hour,
+			}, w, buckets)
+			if err := l.Open(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			conn, err := net.Dial(network, l.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := conn.Write([]byte("requests,host=a:1|c\nrequests,host=a:2|c\ninvalid\n")); err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+
+			// Wait for the lines to be aggregated before closing flushes them.
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				var value float64
+				l.mu.Lock()
+				for _, ser := range l.agg.series {
+					value += ser.value
+				}
+				l.mu.Unlock()
+				if value == 3 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("got value %v, want 3", value)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(w.Points) != 1 {
+				t.Fatalf("got %d points, want 1", len(w.Points))
+			}
+			p := w.Points[0]
+			wantName := tsdb.EncodeName(orgID, bucketID)
+			if string(p.Name()) != string(wantName[:]) {
+				t.Fatalf("unexpected name %q", p.Name())
+			}
+			if got := p.Tags().GetString(models.MeasurementTagKey); got != "requests" {
+				t.Fatalf("unexpected measurement %q", got)
+			}
+			if got := p.Tags().GetString("host"); got != "a" {
+				t.Fatalf("unexpected host tag %q", got)
+			}
+			if got := p.Tags().GetString(models.FieldKeyTagKey); got != "value" {
+				t.Fatalf("unexpected field %q", got)
+			}
+		})
+	}
+}
+
+func TestListener_MissingBucket(t *testing.T) {
+	w := &mock.PointsWriter{}
+	l := NewListener(zaptest.NewLogger(t), Config{
+		Network:     "udp",
+		BindAddress: "127.0.0.1:0",
+		BucketID:    influxdb.ID(2),
+	}, w, bucketFinder{})
+	if err := l.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := parseLine("requests:1|c")
+	l.mu.Lock()
+	l.agg.add(s)
+	l.mu.Unlock()
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.Points) != 0 {
+		t.Fatalf("got %d points, want 0", len(w.Points))
+	}
+}
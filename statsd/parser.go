@@ -0,0 +1,107 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Metric types.
+const (
+	typeCounter = "counter"
+	typeGauge   = "gauge"
+	typeSet     = "set"
+	typeTiming  = "timing"
+)
+
+// sample is a value of a metric, sent in a line such as
+// "requests,host=a:1|c|@0.5|#region:east".
+type sample struct {
+	name       string
+	tags       map[string]string
+	metricType string
+	value      float64
+	// relative is set for gauges whose value has a sign, which is added to
+	// the gauge.
+	relative bool
+	// set is the value of sets, which may be any string.
+	set  string
+	rate float64
+}
+
+// parseLine parses a line of the StatsD protocol. Tags may be sent in the
+// name, as in "name,key=value", or in DogStatsD form, as in "|#key:value".
+func parseLine(line string) (sample, error) {
+	s := sample{rate: 1}
+
+	i := strings.LastIndexByte(line[:strings.IndexByte(line+"|", '|')], ':')
+	if i <= 0 {
+		return s, errors.New("missing metric name or value")
+	}
+	name, rest := line[:i], line[i+1:]
+	parts := strings.Split(rest, "|")
+	if len(parts) < 2 {
+		return s, errors.New("missing metric type")
+	}
+
+	var tags []string
+	if j := strings.IndexByte(name, ','); j >= 0 {
+		tags = append(tags, strings.Split(name[j+1:], ",")...)
+		name = name[:j]
+	}
+	if name == "" {
+		return s, errors.New("missing metric name")
+	}
+	s.name = name
+
+	value := parts[0]
+	switch parts[1] {
+	case "c":
+		s.metricType = typeCounter
+	case "g":
+		s.metricType = typeGauge
+		s.relative = strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")
+	case "s":
+		s.metricType = typeSet
+		s.set = value
+	case "ms", "h", "d":
+		s.metricType = typeTiming
+	default:
+		return s, fmt.Errorf("unsupported metric type %q", parts[1])
+	}
+	if s.metricType != typeSet {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return s, fmt.Errorf("invalid value %q", value)
+		}
+		s.value = v
+	}
+
+	for _, p := range parts[2:] {
+		switch {
+		case strings.HasPrefix(p, "@"):
+			r, err := strconv.ParseFloat(p[1:], 64)
+			if err != nil || r <= 0 || r > 1 {
+				return s, fmt.Errorf("invalid sample rate %q", p[1:])
+			}
+			s.rate = r
+		case strings.HasPrefix(p, "#"):
+			for _, t := range strings.Split(p[1:], ",") {
+				tags = append(tags, strings.Replace(t, ":", "=", 1))
+			}
+		}
+	}
+
+	for _, t := range tags {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			continue
+		}
+		if s.tags == nil {
+			s.tags = make(map[string]string, len(tags))
+		}
+		s.tags[kv[0]] = kv[1]
+	}
+	return s, nil
+}
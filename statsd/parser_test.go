@@ -0,0 +1,76 @@
+package statsd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want sample
+	}{
+		{
+			line: "requests:1|c",
+			want: sample{name: "requests", metricType: typeCounter, value: 1, rate: 1},
+		},
+		{
+			line: "requests,host=a,region=east:3|c|@0.5",
+			want: sample{name: "requests", tags: map[string]string{"host": "a", "region": "east"}, metricType: typeCounter, value: 3, rate: 0.5},
+		},
+		{
+			line: "temperature:-2.5|g|#room:kitchen,floor:1",
+			want: sample{name: "temperature", tags: map[string]string{"room": "kitchen", "floor": "1"}, metricType: typeGauge, value: -2.5, relative: true, rate: 1},
+		},
+		{
+			line: "temperature:20|g",
+			want: sample{name: "temperature", metricType: typeGauge, value: 20, rate: 1},
+		},
+		{
+			line: "users:alice|s",
+			want: sample{name: "users", metricType: typeSet, set: "alice", rate: 1},
+		},
+		{
+			line: "latency:12.5|ms|@0.1",
+			want: sample{name: "latency", metricType: typeTiming, value: 12.5, rate: 0.1},
+		},
+		{
+			line: "size:100|h",
+			want: sample{name: "size", metricType: typeTiming, value: 100, rate: 1},
+		},
+		{
+			line: "size:100|d|#invalid",
+			want: sample{name: "size", metricType: typeTiming, value: 100, rate: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := parseLine(tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLine_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"requests",
+		":1|c",
+		"requests:1",
+		",host=a:1|c",
+		"requests:1|x",
+		"requests:abc|c",
+		"requests:1|c|@0",
+		"requests:1|c|@2",
+	} {
+		t.Run(line, func(t *testing.T) {
+			if _, err := parseLine(line); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
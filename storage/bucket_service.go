@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/jobs"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 )
 
@@ -21,6 +22,14 @@ type BucketDeleter interface {
 type BucketService struct {
 	inner  influxdb.BucketService
 	engine BucketDeleter
+
+	// Jobs is optional. If set, the data of buckets is deleted by a job of
+	// it, which may be listed while the bucket is deleted.
+	Jobs *jobs.Manager
+
+	// LegalHolds is optional. If set, buckets with active legal holds are
+	// not deleted.
+	LegalHolds LegalHoldFinder
 }
 
 // NewBucketService returns a new BucketService for the provided BucketDeleter,
@@ -110,10 +119,39 @@ func (s *BucketService) DeleteBucket(ctx context.Context, bucketID influxdb.ID)
 		return err
 	}
 
+	if s.LegalHolds != nil {
+		active := true
+		holds, err := s.LegalHolds.FindLegalHolds(ctx, influxdb.LegalHoldFilter{
+			BucketID: &bucketID,
+			Active:   &active,
+		})
+		if err != nil {
+			return err
+		}
+		if len(holds) > 0 {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "bucket has active legal holds",
+			}
+		}
+	}
+
 	// The data is dropped first from the storage engine. If this fails for any
 	// reason, then the bucket will still be available in the future to retrieve
 	// the orgID, which is needed for the engine.
-	if err := s.engine.DeleteBucket(ctx, bucket.OrgID, bucketID); err != nil {
+	deleteData := func(ctx context.Context, p *jobs.Progress) error {
+		return s.engine.DeleteBucket(ctx, bucket.OrgID, bucketID)
+	}
+	if s.Jobs != nil {
+		err = s.Jobs.Run(ctx, &influxdb.Job{
+			Kind:       influxdb.JobKindBucketDeletion,
+			OrgID:      bucket.OrgID,
+			ResourceID: bucketID,
+		}, deleteData)
+	} else {
+		err = deleteData(ctx, nil)
+	}
+	if err != nil {
 		return err
 	}
 	return s.inner.DeleteBucket(ctx, bucketID)
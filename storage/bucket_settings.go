@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+// bucketSettingsTTL is how long the settings of a bucket are cached before
+// they are fetched from the bucket service again.
+const bucketSettingsTTL = 30 * time.Second
+
+// bucketSettings caches buckets so that per-bucket storage settings can be
+// consulted on the write path without a call to the bucket service per write.
+type bucketSettings struct {
+	finder BucketFinder
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu      sync.RWMutex
+	buckets map[influxdb.ID]cachedBucket
+}
+
+type cachedBucket struct {
+	bucket  *influxdb.Bucket // nil if the bucket does not exist.
+	expires time.Time
+}
+
+func newBucketSettings(finder BucketFinder) *bucketSettings {
+	return &bucketSettings{
+		finder:  finder,
+		logger:  zap.NewNop(),
+		now:     time.Now,
+		buckets: make(map[influxdb.ID]cachedBucket),
+	}
+}
+
+// find returns the bucket identified by bucketID, or nil if the bucket does
+// not exist or could not be retrieved.
+func (s *bucketSettings) find(bucketID influxdb.ID) *influxdb.Bucket {
+	now := s.now()
+
+	s.mu.RLock()
+	cb, ok := s.buckets[bucketID]
+	s.mu.RUnlock()
+	if ok && now.Before(cb.expires) {
+		return cb.bucket
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bucketAPITimeout)
+	defer cancel()
+
+	buckets, _, err := s.finder.FindBuckets(ctx, influxdb.BucketFilter{ID: &bucketID})
+	if err != nil {
+		s.logger.Info("Unable to retrieve bucket settings", zap.Stringer("bucket_id", bucketID), zap.Error(err))
+		// Keep using the previous settings, if any, rather than flapping
+		// between them and the defaults while the bucket service is unavailable.
+		return cb.bucket
+	}
+
+	cb = cachedBucket{expires: now.Add(bucketSettingsTTL)}
+	if len(buckets) > 0 {
+		cb.bucket = buckets[0]
+	}
+
+	s.mu.Lock()
+	s.buckets[bucketID] = cb
+	s.mu.Unlock()
+	return cb.bucket
+}
+
+// duplicatePolicy returns the policy the engine uses to resolve duplicate
+// points written to the bucket.
+func (s *bucketSettings) duplicatePolicy(_, bucketID influxdb.ID) tsm1.DuplicatePolicy {
+	b := s.find(bucketID)
+	if b == nil {
+		return tsm1.DuplicatePolicyLastWriteWins
+	}
+
+	switch b.DuplicatePolicy {
+	case influxdb.DuplicatePointFirstWriteWins:
+		return tsm1.DuplicatePolicyFirstWriteWins
+	case influxdb.DuplicatePointReject:
+		return tsm1.DuplicatePolicyReject
+	default:
+		return tsm1.DuplicatePolicyLastWriteWins
+	}
+}
+
+// compressionCodec returns the codec the engine uses to compress the string
+// values written for the bucket.
+func (s *bucketSettings) compressionCodec(_, bucketID influxdb.ID) tsm1.CompressionCodec {
+	b := s.find(bucketID)
+	if b == nil {
+		return tsm1.CompressionSnappy
+	}
+
+	switch b.CompressionCodec {
+	case influxdb.CompressionCodecZstd:
+		return tsm1.CompressionZstd
+	default:
+		return tsm1.CompressionSnappy
+	}
+}
+
+// writeWindow returns the organization and write window of the bucket
+// identified by the encoded name of a point. The window is nil if the bucket
+// has no write window.
+func (s *bucketSettings) writeWindow(name []byte) (influxdb.ID, *influxdb.WriteWindow) {
+	if len(name) != influxdb.IDLength {
+		return 0, nil
+	}
+
+	orgID, bucketID := tsdb.DecodeNameSlice(name)
+	b := s.find(bucketID)
+	if b == nil {
+		return orgID, nil
+	}
+	return orgID, b.WriteWindow
+}
+
+// writeConsistency returns the default consistency of writes to the bucket
+// identified by the encoded name of a point.
+func (s *bucketSettings) writeConsistency(name []byte) influxdb.WriteConsistency {
+	if len(name) != influxdb.IDLength {
+		return influxdb.WriteConsistencyDurable
+	}
+
+	_, bucketID := tsdb.DecodeNameSlice(name)
+	b := s.find(bucketID)
+	if b == nil {
+		return influxdb.WriteConsistencyDurable
+	}
+	return b.WriteConsistency.OrDefault()
+}
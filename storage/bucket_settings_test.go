@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func TestEngine_WriteWindow(t *testing.T) {
+	var (
+		org       = influxdb.ID(0x5000)
+		bucket    = influxdb.ID(0x6000)
+		errBucket = influxdb.ID(0x6001)
+		now       = time.Unix(1000000, 0)
+	)
+
+	tests := []struct {
+		name       string
+		window     *influxdb.WriteWindow
+		expWritten int
+		expRouted  int
+		expDropped int
+	}{
+		{
+			name:       "no window",
+			expWritten: 3,
+		},
+		{
+			name:       "reject past and future",
+			window:     &influxdb.WriteWindow{Past: time.Hour, Future: time.Minute},
+			expWritten: 1,
+			expDropped: 2,
+		},
+		{
+			name:       "reject past",
+			window:     &influxdb.WriteWindow{Past: time.Hour},
+			expWritten: 2,
+			expDropped: 1,
+		},
+		{
+			name:       "route to error bucket",
+			window:     &influxdb.WriteWindow{Past: time.Hour, Future: time.Minute, ErrorBucketID: &errBucket},
+			expWritten: 1,
+			expRouted:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := NewTestBucketFinder()
+			finder.FindBucketsFn = func(_ context.Context, filter influxdb.BucketFilter, _ ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+				if *filter.ID != bucket {
+					return nil, 0, nil
+				}
+				return []*influxdb.Bucket{{ID: bucket, OrgID: org, WriteWindow: tt.window}}, 1, nil
+			}
+
+			path, err := ioutil.TempDir("", "storage_engine_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(path)
+
+			engine := NewEngine(path, NewConfig(), WithWriteWindows(finder))
+			engine.buckets.now = func() time.Time { return now }
+			if err := engine.Open(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer engine.Close()
+
+			var points []models.Point
+			for i, ts := range []time.Time{now.Add(-2 * time.Hour), now, now.Add(time.Hour)} {
+				points = append(points, models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": string(rune('a' + i))}), models.Fields{"value": 1.0}, ts))
+			}
+			points, err = tsdb.ExplodePoints(org, bucket, points)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = engine.WritePoints(context.Background(), points)
+			if tt.expDropped == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if tt.expDropped > 0 {
+				pwe, ok := err.(tsdb.PartialWriteError)
+				if !ok {
+					t.Fatalf("expected partial write error, got %v", err)
+				}
+				if pwe.Dropped != tt.expDropped {
+					t.Fatalf("unexpected number of dropped points: got %d, exp %d", pwe.Dropped, tt.expDropped)
+				}
+			}
+
+			if got := countSeries(t, engine, org, bucket); got != tt.expWritten {
+				t.Errorf("unexpected number of series in bucket: got %d, exp %d", got, tt.expWritten)
+			}
+			if got := countSeries(t, engine, org, errBucket); got != tt.expRouted {
+				t.Errorf("unexpected number of series in error bucket: got %d, exp %d", got, tt.expRouted)
+			}
+		})
+	}
+}
+
+func countSeries(t *testing.T, engine *Engine, orgID, bucketID influxdb.ID) int {
+	t.Helper()
+
+	cur, err := engine.CreateSeriesCursor(context.Background(), orgID, bucketID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cur.Close()
+
+	var n int
+	for {
+		row, err := cur.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if row == nil {
+			return n
+		}
+		n++
+	}
+}
@@ -0,0 +1,582 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/jobs"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	snapshotManifestFile = "manifest.json"
+
+	// cloneBatchSize is the number of points written at a time when cloning
+	// a snapshot.
+	cloneBatchSize = 10000
+)
+
+// snapshotExpiryInterval is how often expired snapshots are removed.
+var snapshotExpiryInterval = time.Minute
+
+// SnapshotEngine is the storage engine snapshots of buckets are linked from.
+type SnapshotEngine interface {
+	LinkSnapshot(ctx context.Context, dir string) error
+	SnapshotsPath() string
+}
+
+// BucketSnapshotService snapshots the data of buckets by hard linking the
+// TSM files of the engine, and clones snapshots into new buckets by writing
+// the data of their bucket read from the linked files.
+//
+// Each snapshot is a directory of SnapshotsPath named by its ID, holding the
+// linked files and the snapshot as JSON. Expired snapshots are removed in
+// the background once the service is opened.
+type BucketSnapshotService struct {
+	log     *zap.Logger
+	engine  SnapshotEngine
+	writer  PointsWriter
+	buckets influxdb.BucketService
+	idGen   influxdb.IDGenerator
+	now     func() time.Time
+
+	// Jobs is optional. If set, snapshots are cloned by jobs of it, which
+	// may be listed and canceled while the data is written.
+	Jobs *jobs.Manager
+
+	mu      sync.Mutex
+	viewers map[influxdb.ID]*SnapshotViewer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ influxdb.BucketSnapshotService = (*BucketSnapshotService)(nil)
+
+// NewBucketSnapshotService returns a BucketSnapshotService linking snapshots
+// from engine. Clones are created with buckets and written with writer.
+func NewBucketSnapshotService(log *zap.Logger, engine SnapshotEngine, writer PointsWriter, buckets influxdb.BucketService) *BucketSnapshotService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BucketSnapshotService{
+		log:     log,
+		engine:  engine,
+		writer:  writer,
+		buckets: buckets,
+		idGen:   snowflake.NewIDGenerator(),
+		now:     time.Now,
+		viewers: make(map[influxdb.ID]*SnapshotViewer),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Open starts removing expired snapshots.
+func (s *BucketSnapshotService) Open(ctx context.Context) error {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(snapshotExpiryInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.deleteExpired(s.ctx); err != nil {
+				s.log.Error("Failed to remove expired bucket snapshots", zap.Error(err))
+			}
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops removing expired snapshots, and closes the viewers opened by
+// SnapshotViewer.
+func (s *BucketSnapshotService) Close() error {
+	s.cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for id, v := range s.viewers {
+		err = multierr.Append(err, v.Close())
+		delete(s.viewers, id)
+	}
+	return err
+}
+
+// CreateBucketSnapshot snapshots the data written to the bucket of snap
+// before the call.
+func (s *BucketSnapshotService) CreateBucketSnapshot(ctx context.Context, snap *influxdb.BucketSnapshot) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.buckets.FindBucketByID(ctx, snap.BucketID)
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateBucketSnapshot,
+			Err: err,
+		}
+	}
+	if snap.OrgID.Valid() && snap.OrgID != b.OrgID {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpCreateBucketSnapshot,
+			Msg:  "bucket does not belong to the organization",
+		}
+	}
+	now := s.now().UTC()
+	if snap.Expired(now) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpCreateBucketSnapshot,
+			Msg:  "snapshot must expire in the future",
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if snap.Name != "" {
+		if _, err := s.findSnapshotByName(b.ID, snap.Name); err == nil {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Op:   influxdb.OpCreateBucketSnapshot,
+				Msg:  fmt.Sprintf("bucket already has a snapshot named %q", snap.Name),
+			}
+		} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return &influxdb.Error{
+				Op:  influxdb.OpCreateBucketSnapshot,
+				Err: err,
+			}
+		}
+	}
+
+	snap.ID = s.idGen.ID()
+	snap.OrgID = b.OrgID
+	snap.CreatedAt = now
+
+	dir := s.snapshotPath(snap.ID)
+	if err := s.engine.LinkSnapshot(ctx, dir); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateBucketSnapshot,
+			Err: err,
+		}
+	}
+
+	files, err := snapshotFiles(dir)
+	if err == nil {
+		snap.Files = len(files)
+		for _, f := range files {
+			snap.Size += f.Size()
+		}
+		err = writeSnapshotManifest(dir, snap)
+	}
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateBucketSnapshot,
+			Err: multierr.Append(err, os.RemoveAll(dir)),
+		}
+	}
+	s.log.Info("Created bucket snapshot",
+		zap.Stringer("snapshot_id", snap.ID),
+		zap.Stringer("bucket_id", snap.BucketID),
+		zap.Int("files", snap.Files))
+	return nil
+}
+
+// FindBucketSnapshotByID returns a single snapshot by ID.
+func (s *BucketSnapshotService) FindBucketSnapshotByID(ctx context.Context, id influxdb.ID) (*influxdb.BucketSnapshot, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	snap, err := s.readSnapshot(id)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindBucketSnapshotByID,
+			Err: err,
+		}
+	}
+	return snap, nil
+}
+
+// FindBucketSnapshots returns a list of snapshots that match filter, oldest
+// first. Expired snapshots are not returned.
+func (s *BucketSnapshotService) FindBucketSnapshots(ctx context.Context, filter influxdb.BucketSnapshotFilter) ([]*influxdb.BucketSnapshot, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	snaps, err := s.snapshots()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindBucketSnapshots,
+			Err: err,
+		}
+	}
+
+	now := s.now()
+	filtered := make([]*influxdb.BucketSnapshot, 0, len(snaps))
+	for _, snap := range snaps {
+		if snap.Expired(now) ||
+			(filter.OrgID != nil && snap.OrgID != *filter.OrgID) ||
+			(filter.BucketID != nil && snap.BucketID != *filter.BucketID) ||
+			(filter.Name != nil && snap.Name != *filter.Name) {
+			continue
+		}
+		filtered = append(filtered, snap)
+	}
+	return filtered, nil
+}
+
+// DeleteBucketSnapshot removes a snapshot.
+func (s *BucketSnapshotService) DeleteBucketSnapshot(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.readSnapshot(id); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteBucketSnapshot,
+			Err: err,
+		}
+	}
+	if v, ok := s.viewers[id]; ok {
+		delete(s.viewers, id)
+		if err := v.Close(); err != nil {
+			s.log.Error("Failed to close bucket snapshot viewer", zap.Stringer("snapshot_id", id), zap.Error(err))
+		}
+	}
+	if err := os.RemoveAll(s.snapshotPath(id)); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteBucketSnapshot,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// CloneBucketSnapshot creates b in the organization of the snapshot and
+// writes the data of the snapshot to it. b keeps the retention period of
+// the snapshotted bucket unless it sets its own. b is deleted if the data
+// cannot be written.
+func (s *BucketSnapshotService) CloneBucketSnapshot(ctx context.Context, id influxdb.ID, b *influxdb.Bucket) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	snap, err := s.readSnapshot(id)
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCloneBucketSnapshot,
+			Err: err,
+		}
+	}
+
+	b.ID = 0
+	b.OrgID = snap.OrgID
+	if b.RetentionPeriod == 0 {
+		if src, err := s.buckets.FindBucketByID(ctx, snap.BucketID); err == nil {
+			b.RetentionPeriod = src.RetentionPeriod
+		}
+	}
+	if err := s.buckets.CreateBucket(ctx, b); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCloneBucketSnapshot,
+			Err: err,
+		}
+	}
+
+	var n int
+	clone := func(ctx context.Context, p *jobs.Progress) (err error) {
+		n, err = s.clone(ctx, snap, b, p)
+		return err
+	}
+	if s.Jobs != nil {
+		err = s.Jobs.Run(ctx, &influxdb.Job{
+			Kind:       influxdb.JobKindBucketSnapshotClone,
+			OrgID:      b.OrgID,
+			ResourceID: b.ID,
+		}, clone)
+	} else {
+		err = clone(ctx, nil)
+	}
+	if err != nil {
+		if derr := s.buckets.DeleteBucket(ctx, b.ID); derr != nil {
+			err = multierr.Append(err, derr)
+		}
+		return &influxdb.Error{
+			Op:  influxdb.OpCloneBucketSnapshot,
+			Err: err,
+		}
+	}
+	s.log.Info("Cloned bucket snapshot",
+		zap.Stringer("snapshot_id", snap.ID),
+		zap.Stringer("bucket_id", b.ID),
+		zap.Int("points", n))
+	return nil
+}
+
+// SnapshotViewer returns a viewer reading the data of the bucket as of the
+// snapshot of bucketID named name. name may also be the ID of the snapshot.
+// Viewers are opened on first use, and stay open until their snapshot is
+// removed or the service is closed.
+func (s *BucketSnapshotService) SnapshotViewer(ctx context.Context, orgID, bucketID influxdb.ID, name string) (*SnapshotViewer, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, err := s.findSnapshotByName(bucketID, name)
+	if err != nil {
+		return nil, err
+	}
+	if snap.OrgID != orgID {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrBucketSnapshotNotFound,
+		}
+	}
+
+	if v, ok := s.viewers[snap.ID]; ok {
+		return v, nil
+	}
+	v, err := OpenSnapshotViewer(ctx, s.snapshotPath(snap.ID), snap)
+	if err != nil {
+		return nil, err
+	}
+	s.viewers[snap.ID] = v
+	return v, nil
+}
+
+// findSnapshotByName returns the unexpired snapshot of bucketID named name,
+// or with the ID name.
+func (s *BucketSnapshotService) findSnapshotByName(bucketID influxdb.ID, name string) (*influxdb.BucketSnapshot, error) {
+	snaps, err := s.snapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	for _, snap := range snaps {
+		if snap.BucketID != bucketID || snap.Expired(now) {
+			continue
+		}
+		if snap.Name == name || snap.ID.String() == name {
+			return snap, nil
+		}
+	}
+	return nil, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  fmt.Sprintf("bucket has no snapshot named %q", name),
+	}
+}
+
+// clone writes the data of the bucket of snap to b, and returns the number
+// of points written. The points written are reported to p as they are.
+func (s *BucketSnapshotService) clone(ctx context.Context, snap *influxdb.BucketSnapshot, b *influxdb.Bucket, p *jobs.Progress) (int, error) {
+	dir := s.snapshotPath(snap.ID)
+	files, err := snapshotFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	srcName := tsdb.EncodeName(snap.OrgID, snap.BucketID)
+	prefix := models.EscapeMeasurement(srcName[:])
+	dstName := tsdb.EncodeNameString(b.OrgID, b.ID)
+
+	var (
+		n      int
+		points = make([]models.Point, 0, cloneBatchSize)
+	)
+	flush := func() error {
+		if len(points) == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.writer.WritePoints(ctx, points); err != nil {
+			return err
+		}
+		n += len(points)
+		p.Add(int64(len(points)))
+		points = points[:0]
+		return nil
+	}
+
+	// Files are named by generation, so later files overwrite the points
+	// of earlier ones as they do in the engine.
+	for _, f := range files {
+		if err := readSnapshotFile(filepath.Join(dir, f.Name()), prefix, func(key []byte, values []tsm1.Value) error {
+			seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+			_, tags := models.ParseKeyBytes(seriesKey)
+			for _, v := range values {
+				p, err := models.NewPoint(dstName, tags, models.Fields{string(field): v.Value()}, time.Unix(0, v.UnixNano()))
+				if err != nil {
+					return err
+				}
+				points = append(points, p)
+				if len(points) == cloneBatchSize {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}); err != nil {
+			return n, err
+		}
+	}
+	return n, flush()
+}
+
+// readSnapshotFile calls fn with the values of each key of the TSM file at
+// path starting with prefix. Tombstones linked with the file are applied.
+func readSnapshotFile(path string, prefix []byte, fn func(key []byte, values []tsm1.Value) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	defer r.Close()
+
+	iter := r.Iterator(prefix)
+	for iter.Next() {
+		key := iter.Key()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		values, err := r.ReadAll(key)
+		if err != nil {
+			return err
+		}
+		if err := fn(key, values); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func (s *BucketSnapshotService) deleteExpired(ctx context.Context) error {
+	snaps, err := s.snapshots()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	for _, snap := range snaps {
+		if !snap.Expired(now) {
+			continue
+		}
+		if err := s.DeleteBucketSnapshot(ctx, snap.ID); err != nil {
+			return err
+		}
+		s.log.Info("Removed expired bucket snapshot", zap.Stringer("snapshot_id", snap.ID))
+	}
+	return nil
+}
+
+func (s *BucketSnapshotService) snapshotPath(id influxdb.ID) string {
+	return filepath.Join(s.engine.SnapshotsPath(), id.String())
+}
+
+// snapshots returns every snapshot, oldest first.
+func (s *BucketSnapshotService) snapshots() ([]*influxdb.BucketSnapshot, error) {
+	infos, err := ioutil.ReadDir(s.engine.SnapshotsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var snaps []*influxdb.BucketSnapshot
+	for _, info := range infos {
+		var id influxdb.ID
+		if !info.IsDir() || id.DecodeFromString(info.Name()) != nil {
+			continue
+		}
+		snap, err := s.readSnapshot(id)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			// The snapshot is still being linked, or was removed.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].CreatedAt.Before(snaps[j].CreatedAt) })
+	return snaps, nil
+}
+
+func (s *BucketSnapshotService) readSnapshot(id influxdb.ID) (*influxdb.BucketSnapshot, error) {
+	b, err := ioutil.ReadFile(filepath.Join(s.snapshotPath(id), snapshotManifestFile))
+	if os.IsNotExist(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrBucketSnapshotNotFound,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var snap influxdb.BucketSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func writeSnapshotManifest(dir string, snap *influxdb.BucketSnapshot) error {
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, snapshotManifestFile)
+	if err := ioutil.WriteFile(path+".tmp", b, 0666); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// snapshotFiles returns the TSM files of the snapshot in dir, in the order
+// of their generations.
+func snapshotFiles(dir string) ([]os.FileInfo, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := infos[:0]
+	for _, info := range infos {
+		if strings.HasSuffix(info.Name(), "."+tsm1.TSMFileExtension) {
+			files = append(files, info)
+		}
+	}
+	return files, nil
+}
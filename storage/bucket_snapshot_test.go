@@ -0,0 +1,245 @@
+package storage_test
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap/zaptest"
+)
+
+// pointsRecorder records the points written to it.
+type pointsRecorder struct {
+	points []models.Point
+}
+
+func (r *pointsRecorder) WritePoints(ctx context.Context, points []models.Point) error {
+	r.points = append(r.points, points...)
+	return nil
+}
+
+func TestBucketSnapshotService(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	other := influxdb.ID(0x3333333333333333)
+	point := func(bucket influxdb.ID, host string, v float64) models.Point {
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, bucket),
+			models.NewTags(map[string]string{models.MeasurementTagKey: "cpu", "host": host, models.FieldKeyTagKey: "value"}),
+			map[string]interface{}{"value": v},
+			time.Unix(1, 0),
+		)
+	}
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{
+		point(engine.bucket, "a", 1),
+		point(engine.bucket, "b", 2),
+		point(other, "c", 3),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: engine.org, RetentionPeriod: time.Hour}, nil
+	}
+	clone := influxdb.ID(0x3434343434343434)
+	buckets.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+		b.ID = clone
+		return nil
+	}
+	writer := &pointsRecorder{}
+	svc := storage.NewBucketSnapshotService(zaptest.NewLogger(t), engine.Engine, writer, buckets)
+
+	snap := &influxdb.BucketSnapshot{BucketID: engine.bucket, Description: "before"}
+	if err := svc.CreateBucketSnapshot(context.Background(), snap); err != nil {
+		t.Fatal(err)
+	}
+	if !snap.ID.Valid() || snap.OrgID != engine.org || snap.Files == 0 || snap.Size == 0 {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+
+	// Points written after the snapshot are not part of it.
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{point(engine.bucket, "d", 4)}); err != nil {
+		t.Fatal(err)
+	}
+
+	snaps, err := svc.FindBucketSnapshots(context.Background(), influxdb.BucketSnapshotFilter{BucketID: &engine.bucket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 1 || snaps[0].ID != snap.ID || snaps[0].Description != "before" {
+		t.Fatalf("unexpected snapshots %+v", snaps)
+	}
+
+	b := &influxdb.Bucket{Name: "staging"}
+	if err := svc.CloneBucketSnapshot(context.Background(), snap.ID, b); err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != clone || b.OrgID != engine.org || b.RetentionPeriod != time.Hour {
+		t.Fatalf("unexpected clone %+v", b)
+	}
+
+	var got []string
+	for _, p := range writer.points {
+		got = append(got, p.String())
+	}
+	sort.Strings(got)
+	want := []string{
+		models.MustNewPoint(tsdb.EncodeNameString(engine.org, clone), models.NewTags(map[string]string{models.MeasurementTagKey: "cpu", "host": "a", models.FieldKeyTagKey: "value"}), map[string]interface{}{"value": 1.0}, time.Unix(1, 0)).String(),
+		models.MustNewPoint(tsdb.EncodeNameString(engine.org, clone), models.NewTags(map[string]string{models.MeasurementTagKey: "cpu", "host": "b", models.FieldKeyTagKey: "value"}), map[string]interface{}{"value": 2.0}, time.Unix(1, 0)).String(),
+	}
+	sort.Strings(want)
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected cloned points:\ngot  %q\nwant %q", got, want)
+	}
+
+	if err := svc.DeleteBucketSnapshot(context.Background(), snap.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindBucketSnapshotByID(context.Background(), snap.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected deleted snapshot not to be found, got %v", err)
+	}
+}
+
+func TestBucketSnapshotService_Expired(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: engine.org}, nil
+	}
+	svc := storage.NewBucketSnapshotService(zaptest.NewLogger(t), engine.Engine, &pointsRecorder{}, buckets)
+
+	past := time.Now().Add(-time.Minute)
+	err := svc.CreateBucketSnapshot(context.Background(), &influxdb.BucketSnapshot{BucketID: engine.bucket, ExpiresAt: &past})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected snapshot expiring in the past to be rejected, got %v", err)
+	}
+}
+
+func TestBucketSnapshotService_SnapshotViewer(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	point := func(host string, v float64, ts int64) models.Point {
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{models.MeasurementTagKey: "cpu", "host": host, models.FieldKeyTagKey: "value"}),
+			map[string]interface{}{"value": v},
+			time.Unix(0, ts),
+		)
+	}
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{
+		point("a", 1, 1),
+		point("a", 2, 2),
+		point("b", 3, 1),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: engine.org}, nil
+	}
+	svc := storage.NewBucketSnapshotService(zaptest.NewLogger(t), engine.Engine, &pointsRecorder{}, buckets)
+	defer svc.Close()
+
+	if err := svc.CreateBucketSnapshot(context.Background(), &influxdb.BucketSnapshot{BucketID: engine.bucket, Name: "2024-05-01"}); err != nil {
+		t.Fatal(err)
+	}
+	err := svc.CreateBucketSnapshot(context.Background(), &influxdb.BucketSnapshot{BucketID: engine.bucket, Name: "2024-05-01"})
+	if influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected snapshot with a duplicate name to be rejected, got %v", err)
+	}
+
+	// Deleting the data of the bucket does not change its snapshot.
+	if err := engine.DeleteBucket(context.Background(), engine.org, engine.bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.SnapshotViewer(context.Background(), engine.org, engine.bucket, "missing"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected missing snapshot not to be found, got %v", err)
+	}
+	v, err := svc.SnapshotViewer(context.Background(), engine.org, engine.bucket, "2024-05-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cond, err := influxql.ParseExpr(`host = 'a'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur, err := v.CreateSeriesCursor(context.Background(), engine.org, engine.bucket, cond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err := cur.Next()
+	if err != nil {
+		t.Fatal(err)
+	} else if row == nil || string(row.Tags.Get([]byte("host"))) != "a" {
+		t.Fatalf("unexpected series %+v", row)
+	}
+	if next, err := cur.Next(); err != nil || next != nil {
+		t.Fatalf("expected a single series, got %+v, %v", next, err)
+	}
+
+	itr, err := v.CreateCursorIterator(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		req        cursors.CursorRequest
+		timestamps []int64
+		values     []float64
+	}{
+		{
+			req:        cursors.CursorRequest{Ascending: true, StartTime: 0, EndTime: 2},
+			timestamps: []int64{1},
+			values:     []float64{1},
+		},
+		{
+			req:        cursors.CursorRequest{Ascending: false, StartTime: math.MinInt64, EndTime: math.MaxInt64},
+			timestamps: []int64{2, 1},
+			values:     []float64{2, 1},
+		},
+	} {
+		req := tt.req
+		req.Name, req.Tags, req.Field = row.Name, row.Tags, "value"
+		c, err := itr.Next(context.Background(), &req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := c.(cursors.FloatArrayCursor).Next()
+		if !reflect.DeepEqual(a.Timestamps, tt.timestamps) || !reflect.DeepEqual(a.Values, tt.values) {
+			t.Fatalf("unexpected values %v %v for %+v", a.Timestamps, a.Values, tt.req)
+		}
+		c.Close()
+	}
+
+	values, err := v.TagValues(context.Background(), engine.org, engine.bucket, "host", math.MinInt64, math.MaxInt64, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hosts []string
+	for values.Next() {
+		hosts = append(hosts, values.Value())
+	}
+	if !reflect.DeepEqual(hosts, []string{"a", "b"}) {
+		t.Fatalf("unexpected tag values %q", hosts)
+	}
+}
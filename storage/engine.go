@@ -9,6 +9,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -38,6 +39,13 @@ var timeBytes = []byte("time")
 // it's closed.
 var ErrEngineClosed = errors.New("engine is closed")
 
+// ErrEngineReadOnly is returned when a caller attempts to write to, or delete
+// from, an engine opened read-only.
+var ErrEngineReadOnly = &influxdb.Error{
+	Code: influxdb.EForbidden,
+	Msg:  "storage engine is read-only",
+}
+
 // runner lets us mock out the retention enforcer in tests
 type runner interface{ run() }
 
@@ -62,6 +70,29 @@ type Engine struct {
 	retentionEnforcer        runner
 	retentionEnforcerLimiter runnable
 
+	// maxRetention caps the retention period of every bucket. It is zero if
+	// the retention periods of buckets are not capped.
+	maxRetention time.Duration
+
+	// buckets caches the per-bucket settings consulted when writing points.
+	buckets *bucketSettings
+
+	// writeWindows is true if points are checked against the write window of
+	// the bucket they are written to.
+	writeWindows bool
+
+	// legalHolds finds the holds on the data of buckets spared by deletes. It
+	// is nil if deletes ignore legal holds.
+	legalHolds LegalHoldFinder
+
+	// io shares IO bandwidth between organizations. It is nil if IO is not
+	// scheduled.
+	io *ioScheduler
+
+	// readOnly is true if the engine rejects writes and deletes, and never
+	// changes the data directory itself.
+	readOnly bool
+
 	defaultMetricLabels prometheus.Labels
 
 	// Tracks all goroutines started by the Engine.
@@ -115,6 +146,64 @@ func WithRetentionEnforcer(finder BucketFinder) Option {
 	}
 }
 
+// WithDuplicatePolicies configures the engine to resolve points with the same
+// series and timestamp according to the duplicate policy of the bucket they
+// are written to. Changes to a bucket's policy take effect within
+// bucketSettingsTTL.
+func WithDuplicatePolicies(finder BucketFinder) Option {
+	return func(e *Engine) {
+		e.bucketSettings(finder)
+		e.engine.WithDuplicatePolicyFunc(e.buckets.duplicatePolicy)
+	}
+}
+
+// WithCompressionCodecs configures the engine to compress the string values
+// of the TSM files it writes with the compression codec of the bucket they
+// belong to. Changes to a bucket's codec take effect for new files within
+// bucketSettingsTTL, and for existing blocks as they are compacted.
+func WithCompressionCodecs(finder BucketFinder) Option {
+	return func(e *Engine) {
+		e.bucketSettings(finder)
+		e.engine.WithCompressionCodecFunc(e.buckets.compressionCodec)
+	}
+}
+
+// WithIOBandwidth configures the engine to share bytesPerSec of read and
+// write bandwidth fairly between organizations when more than one of them is
+// reading or writing. An organization using the engine on its own is not
+// throttled.
+func WithIOBandwidth(bytesPerSec int64) Option {
+	return func(e *Engine) {
+		if bytesPerSec > 0 {
+			e.io = newIOScheduler(bytesPerSec)
+		}
+	}
+}
+
+// WithMaxRetention configures the retention enforcer to delete data older
+// than d from every bucket, regardless of the bucket's own retention period.
+// It keeps a rolling window of recent data on instances with limited disk,
+// such as edge instances which forward their writes elsewhere.
+func WithMaxRetention(d time.Duration) Option {
+	return func(e *Engine) {
+		e.maxRetention = d
+	}
+}
+
+// WithReadOnly opens the engine read-only, to inspect a data directory safely.
+// The WAL is neither replayed nor written, so the points which were not yet
+// snapshotted are not readable, and the series file, the index and the TSM
+// files are never compacted. The retention enforcer does not run, and writes
+// and deletes fail with ErrEngineReadOnly.
+func WithReadOnly() Option {
+	return func(e *Engine) {
+		e.readOnly = true
+		e.wal.SetEnabled(false)
+		tsi1.DisableCompactions()(e.index)
+		e.engine.SetReadOnly(true)
+	}
+}
+
 // WithRetentionEnforcerLimiter sets a limiter used to control when the
 // retention enforcer can proceed. If this option is not used then the default
 // limiter (or the absence of one) is a no-op, and no limitations will be put
@@ -194,6 +283,10 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 	e.wal.SetDefaultMetricLabels(e.defaultMetricLabels)
 	if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
 		r.SetDefaultMetricLabels(e.defaultMetricLabels)
+		r.MaxRetention = e.maxRetention
+	}
+	if e.io != nil {
+		e.io.setDefaultMetricLabels(e.defaultMetricLabels)
 	}
 
 	return e
@@ -219,6 +312,29 @@ func (e *Engine) WithLogger(log *zap.Logger) {
 	if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
 		r.WithLogger(e.logger)
 	}
+	if e.buckets != nil {
+		e.buckets.logger = e.logger.With(zap.String("component", "bucket_settings"))
+	}
+}
+
+// WithWriteWindows configures the engine to check the timestamps of points
+// against the write window of the bucket they are written to. Points outside
+// of the window are either written to the window's error bucket or dropped
+// and reported as a partial write.
+func WithWriteWindows(finder BucketFinder) Option {
+	return func(e *Engine) {
+		e.bucketSettings(finder)
+		e.writeWindows = true
+	}
+}
+
+// bucketSettings returns the engine's bucket settings cache, initialising it
+// with finder if necessary.
+func (e *Engine) bucketSettings(finder BucketFinder) *bucketSettings {
+	if e.buckets == nil {
+		e.buckets = newBucketSettings(finder)
+	}
+	return e.buckets
 }
 
 // PrometheusCollectors returns all the prometheus collectors associated with
@@ -230,6 +346,7 @@ func (e *Engine) PrometheusCollectors() []prometheus.Collector {
 	metrics = append(metrics, tsm1.PrometheusCollectors()...)
 	metrics = append(metrics, wal.PrometheusCollectors()...)
 	metrics = append(metrics, RetentionPrometheusCollectors()...)
+	metrics = append(metrics, IOSchedulerPrometheusCollectors()...)
 	return metrics
 }
 
@@ -256,7 +373,10 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 		return err
 	}
 
-	if err := e.replayWAL(); err != nil {
+	if e.readOnly {
+		e.sfile.DisableCompactions()
+		e.logReadOnly()
+	} else if err := e.replayWAL(); err != nil {
 		return err
 	}
 
@@ -265,13 +385,25 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	// TODO(edd) background tasks will be run in priority order via a scheduler.
 	// For now we will just run on an interval as we only have the retention
 	// policy enforcer.
-	if e.retentionEnforcer != nil {
+	if e.retentionEnforcer != nil && !e.readOnly {
 		e.runRetentionEnforcer()
 	}
 
 	return nil
 }
 
+// logReadOnly warns that the engine is read-only, and whether the WAL holds
+// segments which are not replayed.
+func (e *Engine) logReadOnly() {
+	walPaths, err := wal.SegmentFileNames(e.wal.Path())
+	if err != nil {
+		e.logger.Warn("Opened read-only, the WAL cannot be listed", zap.Error(err))
+		return
+	}
+	e.logger.Warn("Opened read-only, writes and deletes are rejected and the WAL is not replayed",
+		zap.Int("wal_segments", len(walPaths)))
+}
+
 // replayWAL reads the WAL segment files and replays them.
 func (e *Engine) replayWAL() error {
 	if !e.config.WAL.Enabled {
@@ -455,7 +587,11 @@ func (e *Engine) CreateCursorIterator(ctx context.Context) (cursors.CursorIterat
 	if e.closing == nil {
 		return nil, ErrEngineClosed
 	}
-	return e.engine.CreateCursorIterator(ctx)
+	itr, err := e.engine.CreateCursorIterator(ctx)
+	if err != nil || itr == nil || e.io == nil {
+		return itr, err
+	}
+	return &ioCursorIterator{CursorIterator: itr, s: e.io}, nil
 }
 
 // WritePoints writes the provided points to the engine.
@@ -469,8 +605,20 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if e.readOnly {
+		return ErrEngineReadOnly
+	}
+
 	collection, j := tsdb.NewSeriesCollection(points), 0
 
+	// routed holds the points which fall outside of their bucket's write window
+	// and are to be written to its error bucket instead.
+	var routed []models.Point
+	var now time.Time
+	if e.writeWindows {
+		now = e.buckets.now()
+	}
+
 	// dropPoint should be called whenever there is reason to drop a point from
 	// the batch.
 	dropPoint := func(key []byte, reason string) {
@@ -523,11 +671,43 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 			continue
 		}
 
+		if e.writeWindows {
+			if orgID, ww := e.buckets.writeWindow(iter.Name()); !ww.Contains(now, iter.Point().Time()) {
+				if ww.ErrorBucketID == nil {
+					dropPoint(iter.Key(), fmt.Sprintf("point time %s is outside of the bucket write window", iter.Point().Time().UTC().Format(time.RFC3339Nano)))
+					continue
+				}
+				pt := iter.Point()
+				name := tsdb.EncodeName(orgID, *ww.ErrorBucketID)
+				pt.SetName(string(name[:]))
+				routed = append(routed, pt)
+				continue
+			}
+		}
+
 		collection.Copy(j, iter.Index())
 		j++
 	}
 	collection.Truncate(j)
 
+	var routedCollection *tsdb.SeriesCollection
+	if len(routed) > 0 {
+		routedCollection = tsdb.NewSeriesCollection(routed)
+	}
+
+	// Wait for IO bandwidth before taking the lock so that throttled
+	// organizations do not hold up other writes or closing the engine.
+	if e.io != nil {
+		for _, c := range []*tsdb.SeriesCollection{collection, routedCollection} {
+			if c == nil {
+				continue
+			}
+			if err := e.io.waitWrite(ctx, c); err != nil {
+				return err
+			}
+		}
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -535,6 +715,17 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 		return ErrEngineClosed
 	}
 
+	if routedCollection != nil {
+		if err := e.writeCollectionLocked(ctx, routedCollection); err != nil {
+			return err
+		}
+	}
+	return e.writeCollectionLocked(ctx, collection)
+}
+
+// writeCollectionLocked writes the collection to the WAL and then to the engine.
+// It must be called with e.mu held.
+func (e *Engine) writeCollectionLocked(ctx context.Context, collection *tsdb.SeriesCollection) error {
 	// Convert the collection to values for adding to the WAL/Cache.
 	values, err := tsm1.CollectionToValues(collection)
 	if err != nil {
@@ -542,13 +733,31 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	}
 
 	// Add the write to the WAL to be replayed if there is a crash or shutdown.
-	if _, err := e.wal.WriteMulti(ctx, values); err != nil {
+	if e.writeConsistency(ctx, collection) == influxdb.WriteConsistencyFast {
+		_, err = e.wal.WriteMultiNoSync(ctx, values)
+	} else {
+		_, err = e.wal.WriteMulti(ctx, values)
+	}
+	if err != nil {
 		return err
 	}
 
 	return e.writePointsLocked(ctx, collection, values)
 }
 
+// writeConsistency returns the consistency the collection is written with:
+// the one requested on ctx, or else the default of the bucket it is written
+// to.
+func (e *Engine) writeConsistency(ctx context.Context, collection *tsdb.SeriesCollection) influxdb.WriteConsistency {
+	if c := influxdb.WriteConsistencyFromContext(ctx); c != "" {
+		return c
+	}
+	if e.buckets == nil || len(collection.Names) == 0 {
+		return influxdb.WriteConsistencyDurable
+	}
+	return e.buckets.writeConsistency(collection.Names[0])
+}
+
 // writePointsLocked does the work of writing points and must be called under some sort of lock.
 func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesCollection, values map[string][]value.Value) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
@@ -608,6 +817,10 @@ func (e *Engine) CommitSegments(ctx context.Context, segs []string, fn func() er
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if e.readOnly {
+		return ErrEngineReadOnly
+	}
+
 	if err := fn(); err != nil {
 		return err
 	}
@@ -622,53 +835,56 @@ func (e *Engine) DeleteBucket(ctx context.Context, orgID, bucketID influxdb.ID)
 	return e.DeleteBucketRange(ctx, orgID, bucketID, math.MinInt64, math.MaxInt64)
 }
 
-// DeleteBucketRange deletes an entire bucket from the storage engine.
+// DeleteBucketRange deletes an entire bucket from the storage engine. Data
+// held by the legal holds of the bucket is kept.
 func (e *Engine) DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-	if e.closing == nil {
-		return ErrEngineClosed
-	}
-
-	// Add the delete to the WAL to be replayed if there is a crash or shutdown.
-	if _, err := e.wal.DeleteBucketRange(orgID, bucketID, min, max, nil); err != nil {
-		return err
-	}
-
-	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, nil)
+	return e.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, nil)
 }
 
 // DeleteBucketRangePredicate deletes data within a bucket from the storage engine. Any data
-// deleted must be in [min, max], and the key must match the predicate if provided.
+// deleted must be in [min, max], and the key must match the predicate if provided. Data
+// held by the legal holds of the bucket is kept.
 func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	deletes, err := e.heldDeletes(ctx, bucketID, min, max, pred)
+	if err != nil {
+		return err
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	if e.closing == nil {
 		return ErrEngineClosed
 	}
+	if e.readOnly {
+		return ErrEngineReadOnly
+	}
 
-	var predData []byte
-	var err error
-	if pred != nil {
-		// Marshal the predicate to add it to the WAL.
-		predData, err = pred.Marshal()
-		if err != nil {
+	for _, d := range deletes {
+		var predData []byte
+		if d.pred != nil {
+			// Marshal the predicate to add it to the WAL.
+			predData, err = d.pred.Marshal()
+			if err != nil {
+				return err
+			}
+		}
+
+		// Add the delete to the WAL to be replayed if there is a crash or shutdown.
+		if _, err := e.wal.DeleteBucketRange(orgID, bucketID, d.min, d.max, predData); err != nil {
 			return err
 		}
-	}
 
-	// Add the delete to the WAL to be replayed if there is a crash or shutdown.
-	if _, err := e.wal.DeleteBucketRange(orgID, bucketID, min, max, predData); err != nil {
-		return err
+		if err := e.deleteBucketRangeLocked(ctx, orgID, bucketID, d.min, d.max, d.pred); err != nil {
+			return err
+		}
 	}
-
-	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, pred)
+	return nil
 }
 
 // deleteBucketRangeLocked does the work of deleting a bucket range and must be called under
@@ -693,6 +909,9 @@ func (e *Engine) CreateBackup(ctx context.Context) (int, []string, error) {
 	if e.closing == nil {
 		return 0, nil, ErrEngineClosed
 	}
+	if e.readOnly {
+		return 0, nil, ErrEngineReadOnly
+	}
 
 	if err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusBackup); err != nil {
 		return 0, nil, err
@@ -715,6 +934,135 @@ func (e *Engine) CreateBackup(ctx context.Context) (int, []string, error) {
 	return id, filenames, nil
 }
 
+// LinkSnapshot writes the cache to a new TSM file, then hard links every TSM
+// file of the engine and their tombstones into dir, which is created under
+// SnapshotsPath. The TSM files are shared by all buckets, so dir holds the
+// data written to every bucket before the call.
+func (e *Engine) LinkSnapshot(ctx context.Context, dir string) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+	if e.readOnly {
+		return ErrEngineReadOnly
+	}
+
+	if err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusBackup); err == tsm1.ErrSnapshotInProgress {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "a cache snapshot is already in progress",
+			Err:  err,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	_, backupPath, err := e.engine.FileStore.CreateSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0777); err != nil {
+		return multierr.Append(err, os.RemoveAll(backupPath))
+	}
+	if err := os.Rename(backupPath, dir); err != nil {
+		return multierr.Append(err, os.RemoveAll(backupPath))
+	}
+	return nil
+}
+
+// SnapshotsPath returns the directory holding the snapshots linked by
+// LinkSnapshot.
+func (e *Engine) SnapshotsPath() string {
+	return filepath.Join(e.path, "snapshots")
+}
+
+// FlushBucket snapshots the cache to a new TSM file and removes the WAL segments
+// the snapshot was written from. The cache is shared by all buckets, so all
+// cached data is flushed, including that of buckets other than bucketID.
+func (e *Engine) FlushBucket(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.CacheFlushResult, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+	if e.readOnly {
+		return nil, ErrEngineReadOnly
+	}
+
+	start := time.Now()
+	size := e.engine.Cache.Size()
+	if err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusFlush); err == tsm1.ErrSnapshotInProgress {
+		return nil, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "a cache snapshot is already in progress",
+			Err:  err,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &influxdb.CacheFlushResult{
+		FlushedBytes: size,
+		Duration:     time.Since(start),
+	}, nil
+}
+
+// FindQuarantinedBlocks returns the TSM blocks which failed checksum
+// verification since the engine opened. Checksums are only verified when the
+// engine is configured to, so there are none otherwise.
+func (e *Engine) FindQuarantinedBlocks(ctx context.Context, filter influxdb.QuarantinedBlockFilter) ([]*influxdb.QuarantinedBlock, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	blocks := e.engine.FileStore.QuarantinedBlocks()
+	qbs := make([]*influxdb.QuarantinedBlock, 0, len(blocks))
+	for _, b := range blocks {
+		qb := &influxdb.QuarantinedBlock{
+			MinTime:       time.Unix(0, b.MinTime).UTC(),
+			MaxTime:       time.Unix(0, b.MaxTime).UTC(),
+			Path:          b.Path,
+			Offset:        b.Offset,
+			QuarantinedAt: b.QuarantinedAt,
+			Dropped:       b.Dropped,
+		}
+
+		seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(b.Key)
+		name, tags := models.ParseKeyBytes(seriesKey)
+		// The name is the encoded organization and bucket IDs.
+		if len(name) == 16 {
+			qb.OrgID, qb.BucketID = tsdb.DecodeNameSlice(name)
+		}
+		qb.Measurement = tags.GetString(models.MeasurementTagKey)
+		qb.Field = string(field)
+		for _, t := range tags {
+			switch string(t.Key) {
+			case models.MeasurementTagKey, models.FieldKeyTagKey:
+				continue
+			}
+			if qb.Tags == nil {
+				qb.Tags = make(map[string]string, len(tags))
+			}
+			qb.Tags[string(t.Key)] = string(t.Value)
+		}
+
+		if filter.OrgID != nil && qb.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.BucketID != nil && qb.BucketID != *filter.BucketID {
+			continue
+		}
+		qbs = append(qbs, qb)
+	}
+	return qbs, nil
+}
+
 // FetchBackupFile writes a given backup file to the provided writer.
 // After a successful write, the internal copy is removed.
 func (e *Engine) FetchBackupFile(ctx context.Context, backupID int, backupFile string, w io.Writer) error {
@@ -810,6 +1158,203 @@ func (e *Engine) MeasurementCardinalityStats() (tsi1.MeasurementCardinalityStats
 	return e.index.MeasurementCardinalityStats()
 }
 
+// SeriesCardinalityReport returns the series cardinality of the bucket broken
+// down by tag key and value. It reads every series of the bucket.
+func (e *Engine) SeriesCardinalityReport(ctx context.Context, orgID, bucketID influxdb.ID, limit int) (*influxdb.SeriesCardinalityReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	seriesN, keys, err := e.index.TagKeyCardinalities(ctx, tsdb.EncodeNameSlice(orgID, bucketID), limit)
+	if err != nil {
+		return nil, err
+	}
+	return &influxdb.SeriesCardinalityReport{
+		OrgID:    orgID,
+		BucketID: bucketID,
+		SeriesN:  seriesN,
+		TagKeys:  keys,
+	}, nil
+}
+
+// FieldTypeConflicts returns the fields of the bucket with values of more
+// than one type.
+func (e *Engine) FieldTypeConflicts(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.FieldTypeConflict, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+	return e.engine.FieldTypeConflicts(ctx, orgID, bucketID)
+}
+
+// ConvertFieldType casts the values of the field of c to the type of c, and
+// returns the number of series converted. The cache is snapshotted first,
+// so that the values it holds are not written back once converted. The
+// converted values are added to the WAL before they replace those stored.
+func (e *Engine) ConvertFieldType(ctx context.Context, c influxdb.FieldTypeConversion, progress func(done, total int)) (int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return 0, ErrEngineClosed
+	}
+	if e.readOnly {
+		return 0, ErrEngineReadOnly
+	}
+
+	if err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusFlush); err == tsm1.ErrSnapshotInProgress {
+		return 0, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "a cache snapshot is in progress",
+			Err:  err,
+		}
+	} else if err != nil {
+		return 0, err
+	}
+
+	return e.engine.ConvertFieldType(ctx, c.OrgID, c.BucketID, c.Measurement, c.Field, tsm1.FieldTypeToBlockType(c.Type), func(values map[string][]value.Value) error {
+		_, err := e.wal.WriteMulti(ctx, values)
+		return err
+	}, progress)
+}
+
+// FindBucketMeasurements returns the measurements of the bucket with the
+// number of their series from the index, and the number of their fields and
+// their last written timestamps from the TSM index and the cache.
+func (e *Engine) FindBucketMeasurements(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.MeasurementMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	cards, err := e.index.MeasurementCardinalities(ctx, tsdb.EncodeNameSlice(orgID, bucketID))
+	if err != nil {
+		return nil, err
+	}
+	fields, err := e.engine.FieldKeyStats(ctx, orgID, bucketID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ms := make(map[string]*influxdb.MeasurementMetadata, len(cards))
+	for name, n := range cards {
+		ms[name] = &influxdb.MeasurementMetadata{Name: name, SeriesN: n}
+	}
+	for _, f := range fields {
+		m, ok := ms[f.Measurement]
+		if !ok {
+			// The series of the measurement were deleted from the index, but
+			// not yet from the TSM files.
+			continue
+		}
+		m.FieldN++
+		if t := lastWritten(f.MaxTime); t != nil && (m.LastWritten == nil || t.After(*m.LastWritten)) {
+			m.LastWritten = t
+		}
+	}
+
+	res := make([]*influxdb.MeasurementMetadata, 0, len(ms))
+	for _, m := range ms {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// FindBucketTagKeys returns the tag keys of the series of the measurement of
+// the bucket, or of all of its series if measurement is empty. The series
+// are all read from the index.
+func (e *Engine) FindBucketTagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*influxdb.TagKeyMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	var (
+		keys []influxdb.TagKeyCardinality
+		err  error
+	)
+	name := tsdb.EncodeNameSlice(orgID, bucketID)
+	if measurement == "" {
+		_, keys, err = e.index.TagKeyCardinalities(ctx, name, 0)
+	} else {
+		_, keys, err = e.index.MeasurementTagKeyCardinalities(ctx, name, measurement, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*influxdb.TagKeyMetadata, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, &influxdb.TagKeyMetadata{
+			Key:     k.Key,
+			SeriesN: k.SeriesN,
+			ValueN:  k.ValueN,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res, nil
+}
+
+// FindBucketFieldKeys returns the field keys of the measurement of the
+// bucket, or of all of its measurements if measurement is empty, from the
+// TSM index and the cache.
+func (e *Engine) FindBucketFieldKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*influxdb.FieldKeyMetadata, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	fields, err := e.engine.FieldKeyStats(ctx, orgID, bucketID, measurement)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*influxdb.FieldKeyMetadata, 0, len(fields))
+	for _, f := range fields {
+		res = append(res, &influxdb.FieldKeyMetadata{
+			Measurement: f.Measurement,
+			Key:         f.Field,
+			Types:       f.Types,
+			SeriesN:     f.SeriesN,
+			LastWritten: lastWritten(f.MaxTime),
+		})
+	}
+	return res, nil
+}
+
+// lastWritten returns the time of the timestamp t, or nil if no value was
+// written.
+func lastWritten(t int64) *time.Time {
+	if t == math.MinInt64 {
+		return nil
+	}
+	ts := time.Unix(0, t).UTC()
+	return &ts
+}
+
 // MeasurementStats returns the current measurement stats for the engine.
 func (e *Engine) MeasurementStats() (tsm1.MeasurementStats, error) {
 	e.mu.RLock()
@@ -7,6 +7,7 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -490,6 +491,200 @@ func TestEngine_WriteConflictingBatch(t *testing.T) {
 	}
 }
 
+func TestEngine_FlushBucket(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := engine.FlushBucket(context.TODO(), engine.org, engine.bucket)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.FlushedBytes == 0 {
+		t.Fatal("expected cached data to be flushed")
+	}
+
+	// The cache is now empty, so there is nothing more to flush.
+	res, err = engine.FlushBucket(context.TODO(), engine.org, engine.bucket)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.FlushedBytes != 0 {
+		t.Fatalf("unexpected flushed bytes: got %d, exp 0", res.FlushedBytes)
+	}
+}
+
+func TestEngine_ReadOnly(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	pt := models.MustNewPoint(
+		name,
+		models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.FlushBucket(context.TODO(), engine.org, engine.bucket); err != nil {
+		t.Fatal(err)
+	}
+	engine.Engine.Close() // Don't remove the data
+
+	ro := storage.NewEngine(engine.path, storage.NewConfig(), storage.WithReadOnly())
+	if err := ro.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer ro.Close()
+
+	if got, exp := ro.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %v series, exp %v series in index", got, exp)
+	}
+	if got, exp := ro.WritePoints(context.TODO(), []models.Point{pt}), storage.ErrEngineReadOnly; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	if got, exp := ro.DeleteBucket(context.TODO(), engine.org, engine.bucket), storage.ErrEngineReadOnly; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	if _, err := ro.FlushBucket(context.TODO(), engine.org, engine.bucket); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected flushing to be forbidden, got %v", err)
+	}
+}
+
+func TestEngine_SeriesCardinalityReport(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	var points []models.Point
+	for _, host := range []string{"a", "a", "b"} {
+		for _, region := range []string{"east", "west"} {
+			points = append(points, models.MustNewPoint(
+				name,
+				models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host, "region": region}),
+				map[string]interface{}{"value": 1.0},
+				time.Unix(1, 2),
+			))
+		}
+	}
+	if err := engine.Engine.WritePoints(context.TODO(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.SeriesCardinalityReport(context.TODO(), engine.org, engine.bucket, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SeriesN != 4 {
+		t.Fatalf("got %d series, exp 4", report.SeriesN)
+	}
+	exp := []influxdb.TagKeyCardinality{{
+		Key:     "host",
+		SeriesN: 4,
+		ValueN:  2,
+		Values:  []influxdb.TagValueCardinality{{Value: "a", SeriesN: 2}},
+	}}
+	if !reflect.DeepEqual(report.TagKeys, exp) {
+		t.Fatalf("got tag keys %+v, exp %+v", report.TagKeys, exp)
+	}
+}
+
+func TestEngine_BucketMetadata(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	point := func(measurement, host, field string, v interface{}, sec int64) models.Point {
+		return models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: field, models.MeasurementTagKey: measurement, "host": host}),
+			map[string]interface{}{field: v},
+			time.Unix(sec, 0),
+		)
+	}
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		point("cpu", "a", "value", 1.0, 1),
+		point("cpu", "b", "value", int64(1), 2),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The values of the measurements are read from both the TSM files and
+	// the cache.
+	if _, err := engine.FlushBucket(context.TODO(), engine.org, engine.bucket); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		point("cpu", "a", "value", 2.0, 3),
+		point("cpu2", "a", "usage", 1.0, 2),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := func(sec int64) *time.Time {
+		v := time.Unix(sec, 0).UTC()
+		return &v
+	}
+
+	ms, err := engine.FindBucketMeasurements(context.TODO(), engine.org, engine.bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expMs := []*influxdb.MeasurementMetadata{
+		{Name: "cpu", SeriesN: 2, FieldN: 1, LastWritten: ts(3)},
+		{Name: "cpu2", SeriesN: 1, FieldN: 1, LastWritten: ts(2)},
+	}
+	if !reflect.DeepEqual(ms, expMs) {
+		t.Fatalf("got measurements %+v, exp %+v", ms, expMs)
+	}
+
+	keys, err := engine.FindBucketTagKeys(context.TODO(), engine.org, engine.bucket, "cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expKeys := []*influxdb.TagKeyMetadata{
+		{Key: "_field", SeriesN: 2, ValueN: 1},
+		{Key: "_measurement", SeriesN: 2, ValueN: 1},
+		{Key: "host", SeriesN: 2, ValueN: 2},
+	}
+	if !reflect.DeepEqual(keys, expKeys) {
+		t.Fatalf("got tag keys %+v, exp %+v", keys, expKeys)
+	}
+
+	// The fields of cpu2 share the prefix of the series keys of cpu, but are
+	// not fields of cpu.
+	fields, err := engine.FindBucketFieldKeys(context.TODO(), engine.org, engine.bucket, "cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expFields := []*influxdb.FieldKeyMetadata{{
+		Measurement: "cpu",
+		Key:         "value",
+		Types:       []influxdb.FieldType{influxdb.FieldTypeFloat, influxdb.FieldTypeInteger},
+		SeriesN:     2,
+		LastWritten: ts(3),
+	}}
+	if !reflect.DeepEqual(fields, expFields) {
+		t.Fatalf("got field keys %+v, exp %+v", fields, expFields)
+	}
+}
+
 // BenchmarkWritePoints_100K demonstrates the impact that batch size has on
 // writing a fixed number of points into storage. In this case 100K points are
 // written according to varying batch sizes.
@@ -0,0 +1,367 @@
+// Package export exports the data of buckets as Apache Parquet files, so
+// that it can be read by other engines without the query engine.
+package export
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/parquet"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxql"
+)
+
+// Viewer is the part of the storage engine read by an Exporter.
+type Viewer interface {
+	CreateCursorIterator(ctx context.Context) (cursors.CursorIterator, error)
+	CreateSeriesCursor(ctx context.Context, orgID, bucketID influxdb.ID, cond influxql.Expr) (storage.SeriesCursor, error)
+	TagValues(ctx context.Context, orgID, bucketID influxdb.ID, tagKey string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error)
+}
+
+var _ influxdb.ParquetExportService = (*Exporter)(nil)
+
+// Exporter exports the data of a measurement of a bucket as a Parquet file.
+//
+// Files have a row for each series and timestamp, with a "time" column, a
+// column for each tag key and a column for each field key of the measurement.
+// Times are stored in microseconds. A field with values of several types has a
+// column for each type, suffixed with the type. Fields named as a tag key are
+// suffixed with "_field", and tags and fields named "time" with "_tag" and
+// "_field".
+type Exporter struct {
+	viewer Viewer
+}
+
+// NewExporter returns an Exporter reading from viewer.
+func NewExporter(viewer Viewer) *Exporter {
+	return &Exporter{viewer: viewer}
+}
+
+// FindExportMeasurements returns the measurements of the bucket with data in
+// [start, end).
+func (e *Exporter) FindExportMeasurements(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64) ([]string, error) {
+	itr, err := e.viewer.TagValues(ctx, orgID, bucketID, models.MeasurementTagKey, start, end, nil)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for itr.Next() {
+		names = append(names, itr.Value())
+	}
+	return names, nil
+}
+
+// series is a series of a measurement, and its fields.
+type series struct {
+	key    string
+	tags   models.Tags // the tags of the series, without the measurement and field
+	fields []string
+}
+
+// column identifies the column of a field of a given type.
+type column struct {
+	field string
+	typ   parquet.Type
+}
+
+// ExportParquet writes the data of measurement in [start, end) to w as a
+// Parquet file, and returns the number of rows written. It returns
+// influxdb.ErrNoExportData without writing anything if there is no data.
+func (e *Exporter) ExportParquet(ctx context.Context, w io.Writer, orgID, bucketID influxdb.ID, measurement string, start, end int64) (int64, error) {
+	ss, err := e.series(ctx, orgID, bucketID, measurement)
+	if err != nil {
+		return 0, err
+	}
+
+	// Find the type of every field before writing, since the schema of a
+	// Parquet file comes before its data.
+	tagKeys := make(map[string]bool)
+	fieldTypes := make(map[string]map[parquet.Type]bool)
+	for _, s := range ss {
+		for _, f := range s.fields {
+			cur, err := e.cursor(ctx, orgID, bucketID, measurement, s, f, start, end)
+			if err != nil {
+				return 0, err
+			}
+			if cur == nil {
+				continue
+			}
+			typ := cursorType(cur)
+			cur.Close()
+
+			if fieldTypes[f] == nil {
+				fieldTypes[f] = make(map[parquet.Type]bool)
+			}
+			fieldTypes[f][typ] = true
+			for _, t := range s.tags {
+				tagKeys[string(t.Key)] = true
+			}
+		}
+	}
+	if len(fieldTypes) == 0 {
+		return 0, influxdb.ErrNoExportData
+	}
+
+	cols, tagCols, fieldCols := schema(tagKeys, fieldTypes)
+	pw := parquet.NewWriter(w, cols)
+	row := make([]interface{}, len(cols))
+	for _, s := range ss {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		for i := range row {
+			row[i] = nil
+		}
+		for _, t := range s.tags {
+			row[tagCols[string(t.Key)]] = string(t.Value)
+		}
+
+		var fcs []*fieldCursor
+		for _, f := range s.fields {
+			cur, err := e.cursor(ctx, orgID, bucketID, measurement, s, f, start, end)
+			if err != nil {
+				closeAll(fcs)
+				return 0, err
+			}
+			if cur == nil {
+				continue
+			}
+			fc := &fieldCursor{col: fieldCols[column{field: f, typ: cursorType(cur)}], cur: cur}
+			if fc.next() {
+				fcs = append(fcs, fc)
+			} else {
+				cur.Close()
+			}
+		}
+
+		err := writeRows(pw, row, fcs)
+		closeAll(fcs)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := pw.Close(); err != nil {
+		return 0, err
+	}
+	return pw.NumRows(), nil
+}
+
+// series returns the series of measurement, sorted by key.
+func (e *Exporter) series(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]*series, error) {
+	cond := &influxql.BinaryExpr{
+		Op:  influxql.EQ,
+		LHS: &influxql.VarRef{Val: models.MeasurementTagKey, Type: influxql.Tag},
+		RHS: &influxql.StringLiteral{Val: measurement},
+	}
+	cur, err := e.viewer.CreateSeriesCursor(ctx, orgID, bucketID, cond)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	byKey := make(map[string]*series)
+	var ss []*series
+	for {
+		row, err := cur.Next()
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			break
+		}
+
+		// The tags of the row are only valid until the next call.
+		tags := row.Tags.Clone()
+		field := string(tags.Get(models.FieldKeyTagKeyBytes))
+		tags.Delete(models.MeasurementTagKeyBytes)
+		tags.Delete(models.FieldKeyTagKeyBytes)
+
+		key := string(tags.HashKey())
+		s := byKey[key]
+		if s == nil {
+			s = &series{key: key, tags: tags}
+			byKey[key] = s
+			ss = append(ss, s)
+		}
+		s.fields = append(s.fields, field)
+	}
+
+	sort.Slice(ss, func(i, j int) bool { return ss[i].key < ss[j].key })
+	return ss, nil
+}
+
+// cursor returns a cursor over the values of field of s in [start, end), or
+// nil if there are none.
+func (e *Exporter) cursor(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, s *series, field string, start, end int64) (cursors.Cursor, error) {
+	itr, err := e.viewer.CreateCursorIterator(ctx)
+	if err != nil || itr == nil {
+		return nil, err
+	}
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	tags := make(models.Tags, 0, len(s.tags)+2)
+	tags = append(tags, models.NewTag(models.MeasurementTagKeyBytes, []byte(measurement)))
+	tags = append(tags, s.tags...)
+	tags = append(tags, models.NewTag(models.FieldKeyTagKeyBytes, []byte(field)))
+	return itr.Next(ctx, &cursors.CursorRequest{
+		Name:      name[:],
+		Tags:      tags,
+		Field:     field,
+		Ascending: true,
+		StartTime: start,
+		EndTime:   end,
+	})
+}
+
+// schema returns the columns of a file with tagKeys and fieldTypes, and the
+// indexes of the column of each tag key and of each field and type.
+func schema(tagKeys map[string]bool, fieldTypes map[string]map[parquet.Type]bool) ([]parquet.Column, map[string]int, map[column]int) {
+	cols := []parquet.Column{{Name: "time", Type: parquet.Timestamp}}
+
+	tagCols := make(map[string]int, len(tagKeys))
+	for _, k := range sortedKeys(tagKeys) {
+		name := k
+		if name == "time" {
+			name += "_tag"
+		}
+		tagCols[k] = len(cols)
+		cols = append(cols, parquet.Column{Name: name, Type: parquet.String, Optional: true})
+	}
+
+	fields := make(map[string]bool, len(fieldTypes))
+	for f := range fieldTypes {
+		fields[f] = true
+	}
+	fieldCols := make(map[column]int)
+	for _, f := range sortedKeys(fields) {
+		name := f
+		if name == "time" || tagKeys[name] {
+			name += "_field"
+		}
+
+		types := make([]parquet.Type, 0, len(fieldTypes[f]))
+		for typ := range fieldTypes[f] {
+			types = append(types, typ)
+		}
+		sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+		for _, typ := range types {
+			colName := name
+			if len(types) > 1 {
+				colName += "_" + typ.String()
+			}
+			fieldCols[column{field: f, typ: typ}] = len(cols)
+			cols = append(cols, parquet.Column{Name: colName, Type: typ, Optional: true})
+		}
+	}
+	return cols, tagCols, fieldCols
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeRows writes a row for each timestamp of the values of fcs, with the
+// tag values already set in row.
+func writeRows(pw *parquet.Writer, row []interface{}, fcs []*fieldCursor) error {
+	all := fcs
+	fcs = append([]*fieldCursor(nil), fcs...)
+	for len(fcs) > 0 {
+		ts := fcs[0].ts[fcs[0].i]
+		for _, fc := range fcs[1:] {
+			if t := fc.ts[fc.i]; t < ts {
+				ts = t
+			}
+		}
+
+		row[0] = ts
+		for _, fc := range all {
+			row[fc.col] = nil
+		}
+		active := fcs[:0]
+		for _, fc := range fcs {
+			if fc.ts[fc.i] == ts {
+				row[fc.col] = fc.value(fc.i)
+				if !fc.next() {
+					continue
+				}
+			}
+			active = append(active, fc)
+		}
+		if err := pw.Write(row); err != nil {
+			return err
+		}
+		fcs = active
+	}
+	return nil
+}
+
+func closeAll(fcs []*fieldCursor) {
+	for _, fc := range fcs {
+		fc.cur.Close()
+	}
+}
+
+func cursorType(cur cursors.Cursor) parquet.Type {
+	switch cur.(type) {
+	case cursors.FloatArrayCursor:
+		return parquet.Double
+	case cursors.IntegerArrayCursor:
+		return parquet.Int64
+	case cursors.UnsignedArrayCursor:
+		return parquet.Uint64
+	case cursors.StringArrayCursor:
+		return parquet.String
+	default:
+		return parquet.Boolean
+	}
+}
+
+// fieldCursor reads the values of a field of a series one at a time.
+type fieldCursor struct {
+	col   int
+	cur   cursors.Cursor
+	ts    []int64
+	value func(i int) interface{}
+	i     int
+}
+
+// next moves to the next value, and returns false if there are none.
+func (c *fieldCursor) next() bool {
+	if c.i+1 < len(c.ts) {
+		c.i++
+		return true
+	}
+
+	c.i = 0
+	switch cur := c.cur.(type) {
+	case cursors.FloatArrayCursor:
+		a := cur.Next()
+		c.ts, c.value = a.Timestamps, func(i int) interface{} { return a.Values[i] }
+	case cursors.IntegerArrayCursor:
+		a := cur.Next()
+		c.ts, c.value = a.Timestamps, func(i int) interface{} { return a.Values[i] }
+	case cursors.UnsignedArrayCursor:
+		a := cur.Next()
+		c.ts, c.value = a.Timestamps, func(i int) interface{} { return a.Values[i] }
+	case cursors.StringArrayCursor:
+		a := cur.Next()
+		c.ts, c.value = a.Timestamps, func(i int) interface{} { return a.Values[i] }
+	case cursors.BooleanArrayCursor:
+		a := cur.Next()
+		c.ts, c.value = a.Timestamps, func(i int) interface{} { return a.Values[i] }
+	default:
+		c.ts = nil
+	}
+	return len(c.ts) > 0
+}
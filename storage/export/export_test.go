@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/parquet"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func TestExporter_Export(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	engine := storage.NewEngine(dir, storage.NewConfig())
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	org, bucket := influxdb.ID(0x3131313131313131), influxdb.ID(0x3232323232323232)
+	point := func(m string, tags map[string]string, fields map[string]interface{}, ts time.Time) models.Point {
+		return models.MustNewPoint(m, models.NewTags(tags), fields, ts)
+	}
+	day := time.Date(2020, 6, 15, 0, 0, 0, 0, time.UTC)
+	points, err := tsdb.ExplodePoints(org, bucket, []models.Point{
+		point("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.5, "cores": int64(4)}, day.Add(time.Hour)),
+		point("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 2.5}, day.Add(2*time.Hour)),
+		point("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 3.5, "up": true}, day.Add(time.Hour)),
+		point("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 4.5}, day.Add(25*time.Hour)),
+		point("mem", map[string]string{"host": "a"}, map[string]interface{}{"free": int64(10)}, day.Add(time.Hour)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExporter(engine)
+	start, end := day.UnixNano(), day.Add(24*time.Hour).UnixNano()
+
+	names, err := e.FindExportMeasurements(context.Background(), org, bucket, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"cpu", "mem"}) {
+		t.Fatalf("got measurements %v", names)
+	}
+
+	var buf bytes.Buffer
+	n, err := e.ExportParquet(context.Background(), &buf, org, bucket, "cpu", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// host a has values at 1h and 2h, and host b at 1h; the value of host b
+	// on the next day is not exported.
+	if n != 3 {
+		t.Fatalf("got %d rows, want 3", n)
+	}
+	if data := buf.Bytes(); !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("not a parquet file")
+	}
+
+	buf.Reset()
+	if _, err := e.ExportParquet(context.Background(), &buf, org, bucket, "disk", start, end); err != influxdb.ErrNoExportData {
+		t.Fatalf("got error %v, want ErrNoExportData", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatal("expected nothing to be written")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	cols, tagCols, fieldCols := schema(
+		map[string]bool{"host": true, "time": true},
+		map[string]map[parquet.Type]bool{
+			"host":  {parquet.String: true},
+			"usage": {parquet.Double: true, parquet.Int64: true},
+		},
+	)
+
+	want := []parquet.Column{
+		{Name: "time", Type: parquet.Timestamp},
+		{Name: "host", Type: parquet.String, Optional: true},
+		{Name: "time_tag", Type: parquet.String, Optional: true},
+		{Name: "host_field", Type: parquet.String, Optional: true},
+		{Name: "usage_int64", Type: parquet.Int64, Optional: true},
+		{Name: "usage_double", Type: parquet.Double, Optional: true},
+	}
+	if !reflect.DeepEqual(cols, want) {
+		t.Fatalf("got columns %v, want %v", cols, want)
+	}
+	if tagCols["time"] != 2 || fieldCols[column{field: "usage", typ: parquet.Double}] != 5 {
+		t.Fatalf("unexpected column indexes %v %v", tagCols, fieldCols)
+	}
+}
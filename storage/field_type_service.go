@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/jobs"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"go.uber.org/zap"
+)
+
+// FieldTypeEngine is the storage engine of which the field type conflicts
+// are found and converted.
+type FieldTypeEngine interface {
+	FieldTypeConflicts(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.FieldTypeConflict, error)
+	ConvertFieldType(ctx context.Context, c influxdb.FieldTypeConversion, progress func(done, total int)) (int, error)
+}
+
+// FieldTypeConflictService finds the fields of buckets with values of more
+// than one type in the engine, and converts them in the background.
+type FieldTypeConflictService struct {
+	log    *zap.Logger
+	engine FieldTypeEngine
+
+	// Jobs is optional. If set, conversions run as its jobs, which may be
+	// listed and canceled. Otherwise ConvertFieldType returns once the
+	// field is converted, and returns no job.
+	Jobs *jobs.Manager
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ influxdb.FieldTypeConflictService = (*FieldTypeConflictService)(nil)
+
+// NewFieldTypeConflictService returns a FieldTypeConflictService of engine.
+func NewFieldTypeConflictService(log *zap.Logger, engine FieldTypeEngine) *FieldTypeConflictService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &FieldTypeConflictService{
+		log:    log,
+		engine: engine,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Close cancels the running conversions and waits for them to return.
+func (s *FieldTypeConflictService) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// FindFieldTypeConflicts returns the fields of the bucket with values of
+// more than one type.
+func (s *FieldTypeConflictService) FindFieldTypeConflicts(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.FieldTypeConflict, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	cs, err := s.engine.FieldTypeConflicts(ctx, orgID, bucketID)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindFieldTypeConflicts,
+			Err: err,
+		}
+	}
+	return cs, nil
+}
+
+// ConvertFieldType starts casting the values of the field of c to the type
+// of c, and returns the job converting them.
+func (s *FieldTypeConflictService) ConvertFieldType(ctx context.Context, c influxdb.FieldTypeConversion) (*influxdb.Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := c.Valid(); err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpConvertFieldType,
+			Err: err,
+		}
+	}
+
+	log := s.log.With(
+		zap.Stringer("bucket_id", c.BucketID),
+		zap.String("measurement", c.Measurement),
+		zap.String("field", c.Field),
+		zap.String("type", string(c.Type)))
+	convert := func(ctx context.Context, p *jobs.Progress) error {
+		p.SetStage("converting series")
+		var total bool
+		n, err := s.engine.ConvertFieldType(ctx, c, func(done, n int) {
+			if !total {
+				p.SetTotal(int64(n))
+				total = true
+			}
+			p.Add(1)
+		})
+		if err != nil {
+			return err
+		}
+		log.Info("Converted field type", zap.Int("series", n))
+		return nil
+	}
+
+	if s.Jobs == nil {
+		if err := convert(ctx, nil); err != nil {
+			return nil, &influxdb.Error{
+				Op:  influxdb.OpConvertFieldType,
+				Err: err,
+			}
+		}
+		return nil, nil
+	}
+
+	s.wg.Add(1)
+	return s.Jobs.Start(s.ctx, &influxdb.Job{
+		Kind:       influxdb.JobKindFieldTypeConversion,
+		OrgID:      c.OrgID,
+		ResourceID: c.BucketID,
+	}, func(ctx context.Context, p *jobs.Progress) error {
+		defer s.wg.Done()
+		return convert(ctx, p)
+	}), nil
+}
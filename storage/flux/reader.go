@@ -10,6 +10,7 @@ import (
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/values"
+	platform "github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/errors"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
@@ -51,19 +52,54 @@ type storageTable interface {
 	Statistics() cursors.CursorStats
 }
 
+// SnapshotStoreFunc returns the store reading the snapshot of the bucket
+// with the given name.
+type SnapshotStoreFunc func(ctx context.Context, orgID, bucketID platform.ID, name string) (storage.Store, error)
+
 type storeReader struct {
-	s storage.Store
+	s         storage.Store
+	snapshots SnapshotStoreFunc
+}
+
+// ReaderOption is a functional option for configuring a reader.
+type ReaderOption func(*storeReader)
+
+// WithSnapshots sets the function resolving the stores of the snapshots
+// read by queries. Reads of snapshots fail if it is not set.
+func WithSnapshots(fn SnapshotStoreFunc) ReaderOption {
+	return func(r *storeReader) {
+		r.snapshots = fn
+	}
 }
 
 // NewReader returns a new storageflux reader
-func NewReader(s storage.Store) influxdb.Reader {
-	return &storeReader{s: s}
+func NewReader(s storage.Store, opts ...ReaderOption) influxdb.Reader {
+	r := &storeReader{s: s}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// store returns the store to read spec from.
+func (r *storeReader) store(ctx context.Context, spec influxdb.ReadFilterSpec) (storage.Store, error) {
+	if spec.Snapshot == "" {
+		return r.s, nil
+	}
+	if r.snapshots == nil {
+		return nil, errors.New("storage does not support reading snapshots.")
+	}
+	return r.snapshots(ctx, spec.OrganizationID, spec.BucketID, spec.Snapshot)
 }
 
 func (r *storeReader) ReadFilter(ctx context.Context, spec influxdb.ReadFilterSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	s, err := r.store(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
 	return &filterIterator{
 		ctx:   ctx,
-		s:     r.s,
+		s:     s,
 		spec:  spec,
 		cache: newTagsCache(0),
 		alloc: alloc,
@@ -71,9 +107,13 @@ func (r *storeReader) ReadFilter(ctx context.Context, spec influxdb.ReadFilterSp
 }
 
 func (r *storeReader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	s, err := r.store(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	}
 	return &groupIterator{
 		ctx:   ctx,
-		s:     r.s,
+		s:     s,
 		spec:  spec,
 		cache: newTagsCache(0),
 		alloc: alloc,
@@ -88,9 +128,13 @@ func (r *storeReader) HasWindowAggregateCapability(ctx context.Context, capabili
 }
 
 func (r *storeReader) ReadWindowAggregate(ctx context.Context, spec influxdb.ReadWindowAggregateSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	s, err := r.store(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	}
 	return &windowAggregateIterator{
 		ctx:   ctx,
-		s:     r.s,
+		s:     s,
 		spec:  spec,
 		cache: newTagsCache(0),
 		alloc: alloc,
@@ -98,6 +142,11 @@ func (r *storeReader) ReadWindowAggregate(ctx context.Context, spec influxdb.Rea
 }
 
 func (r *storeReader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeysSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	s, err := r.store(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	}
+
 	var predicate *datatypes.Predicate
 	if spec.Predicate != nil {
 		p, err := toStoragePredicate(spec.Predicate)
@@ -110,7 +159,7 @@ func (r *storeReader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeys
 	return &tagKeysIterator{
 		ctx:       ctx,
 		bounds:    spec.Bounds,
-		s:         r.s,
+		s:         s,
 		readSpec:  spec,
 		predicate: predicate,
 		alloc:     alloc,
@@ -118,6 +167,11 @@ func (r *storeReader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeys
 }
 
 func (r *storeReader) ReadTagValues(ctx context.Context, spec influxdb.ReadTagValuesSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	s, err := r.store(ctx, spec.ReadFilterSpec)
+	if err != nil {
+		return nil, err
+	}
+
 	var predicate *datatypes.Predicate
 	if spec.Predicate != nil {
 		p, err := toStoragePredicate(spec.Predicate)
@@ -130,7 +184,7 @@ func (r *storeReader) ReadTagValues(ctx context.Context, spec influxdb.ReadTagVa
 	return &tagValuesIterator{
 		ctx:       ctx,
 		bounds:    spec.Bounds,
-		s:         r.s,
+		s:         s,
 		readSpec:  spec,
 		predicate: predicate,
 		alloc:     alloc,
@@ -255,8 +309,7 @@ READ:
 		}
 
 		stats := table.Statistics()
-		fi.stats.ScannedValues += stats.ScannedValues
-		fi.stats.ScannedBytes += stats.ScannedBytes
+		fi.stats.Add(stats)
 		table.Close()
 		table = nil
 	}
@@ -399,8 +452,7 @@ READ:
 		}
 
 		stats := table.Statistics()
-		gi.stats.ScannedValues += stats.ScannedValues
-		gi.stats.ScannedBytes += stats.ScannedBytes
+		gi.stats.Add(stats)
 		table.Close()
 		table = nil
 
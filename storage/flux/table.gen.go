@@ -71,6 +71,10 @@ func (t *floatTable) Statistics() cursors.CursorStats {
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
+		BlocksDecoded: cs.BlocksDecoded,
+		BlocksMerged:  cs.BlocksMerged,
+		BlocksSkipped: cs.BlocksSkipped,
+		ScannedSeries: 1,
 	}
 }
 
@@ -101,9 +105,10 @@ func (t *floatTable) advance() bool {
 
 type floatGroupTable struct {
 	table
-	mu  sync.Mutex
-	gc  storage.GroupCursor
-	cur cursors.FloatArrayCursor
+	mu    sync.Mutex
+	gc    storage.GroupCursor
+	cur   cursors.FloatArrayCursor
+	stats cursors.CursorStats
 }
 
 func newFloatGroupTable(
@@ -122,6 +127,7 @@ func newFloatGroupTable(
 		table: newTable(done, bounds, key, cols, defs, cache, alloc),
 		gc:    gc,
 		cur:   cur,
+		stats: cursors.CursorStats{ScannedSeries: 1},
 	}
 	t.readTags(tags)
 	t.advance()
@@ -171,6 +177,7 @@ RETRY:
 }
 
 func (t *floatGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -193,6 +200,7 @@ func (t *floatGroupTable) advanceCursor() bool {
 		} else {
 			t.readTags(t.gc.Tags())
 			t.cur = typedCur
+			t.stats.ScannedSeries++
 			return true
 		}
 	}
@@ -200,14 +208,11 @@ func (t *floatGroupTable) advanceCursor() bool {
 }
 
 func (t *floatGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
-	}
-	cs := t.cur.Stats()
-	return cursors.CursorStats{
-		ScannedValues: cs.ScannedValues,
-		ScannedBytes:  cs.ScannedBytes,
+	stats := t.stats
+	if t.cur != nil {
+		stats.Add(t.cur.Stats())
 	}
+	return stats
 }
 
 //
@@ -262,6 +267,10 @@ func (t *integerTable) Statistics() cursors.CursorStats {
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
+		BlocksDecoded: cs.BlocksDecoded,
+		BlocksMerged:  cs.BlocksMerged,
+		BlocksSkipped: cs.BlocksSkipped,
+		ScannedSeries: 1,
 	}
 }
 
@@ -292,9 +301,10 @@ func (t *integerTable) advance() bool {
 
 type integerGroupTable struct {
 	table
-	mu  sync.Mutex
-	gc  storage.GroupCursor
-	cur cursors.IntegerArrayCursor
+	mu    sync.Mutex
+	gc    storage.GroupCursor
+	cur   cursors.IntegerArrayCursor
+	stats cursors.CursorStats
 }
 
 func newIntegerGroupTable(
@@ -313,6 +323,7 @@ func newIntegerGroupTable(
 		table: newTable(done, bounds, key, cols, defs, cache, alloc),
 		gc:    gc,
 		cur:   cur,
+		stats: cursors.CursorStats{ScannedSeries: 1},
 	}
 	t.readTags(tags)
 	t.advance()
@@ -362,6 +373,7 @@ RETRY:
 }
 
 func (t *integerGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -384,6 +396,7 @@ func (t *integerGroupTable) advanceCursor() bool {
 		} else {
 			t.readTags(t.gc.Tags())
 			t.cur = typedCur
+			t.stats.ScannedSeries++
 			return true
 		}
 	}
@@ -391,14 +404,11 @@ func (t *integerGroupTable) advanceCursor() bool {
 }
 
 func (t *integerGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
-	}
-	cs := t.cur.Stats()
-	return cursors.CursorStats{
-		ScannedValues: cs.ScannedValues,
-		ScannedBytes:  cs.ScannedBytes,
+	stats := t.stats
+	if t.cur != nil {
+		stats.Add(t.cur.Stats())
 	}
+	return stats
 }
 
 //
@@ -453,6 +463,10 @@ func (t *unsignedTable) Statistics() cursors.CursorStats {
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
+		BlocksDecoded: cs.BlocksDecoded,
+		BlocksMerged:  cs.BlocksMerged,
+		BlocksSkipped: cs.BlocksSkipped,
+		ScannedSeries: 1,
 	}
 }
 
@@ -483,9 +497,10 @@ func (t *unsignedTable) advance() bool {
 
 type unsignedGroupTable struct {
 	table
-	mu  sync.Mutex
-	gc  storage.GroupCursor
-	cur cursors.UnsignedArrayCursor
+	mu    sync.Mutex
+	gc    storage.GroupCursor
+	cur   cursors.UnsignedArrayCursor
+	stats cursors.CursorStats
 }
 
 func newUnsignedGroupTable(
@@ -504,6 +519,7 @@ func newUnsignedGroupTable(
 		table: newTable(done, bounds, key, cols, defs, cache, alloc),
 		gc:    gc,
 		cur:   cur,
+		stats: cursors.CursorStats{ScannedSeries: 1},
 	}
 	t.readTags(tags)
 	t.advance()
@@ -553,6 +569,7 @@ RETRY:
 }
 
 func (t *unsignedGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -575,6 +592,7 @@ func (t *unsignedGroupTable) advanceCursor() bool {
 		} else {
 			t.readTags(t.gc.Tags())
 			t.cur = typedCur
+			t.stats.ScannedSeries++
 			return true
 		}
 	}
@@ -582,14 +600,11 @@ func (t *unsignedGroupTable) advanceCursor() bool {
 }
 
 func (t *unsignedGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
-	}
-	cs := t.cur.Stats()
-	return cursors.CursorStats{
-		ScannedValues: cs.ScannedValues,
-		ScannedBytes:  cs.ScannedBytes,
+	stats := t.stats
+	if t.cur != nil {
+		stats.Add(t.cur.Stats())
 	}
+	return stats
 }
 
 //
@@ -644,6 +659,10 @@ func (t *stringTable) Statistics() cursors.CursorStats {
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
+		BlocksDecoded: cs.BlocksDecoded,
+		BlocksMerged:  cs.BlocksMerged,
+		BlocksSkipped: cs.BlocksSkipped,
+		ScannedSeries: 1,
 	}
 }
 
@@ -674,9 +693,10 @@ func (t *stringTable) advance() bool {
 
 type stringGroupTable struct {
 	table
-	mu  sync.Mutex
-	gc  storage.GroupCursor
-	cur cursors.StringArrayCursor
+	mu    sync.Mutex
+	gc    storage.GroupCursor
+	cur   cursors.StringArrayCursor
+	stats cursors.CursorStats
 }
 
 func newStringGroupTable(
@@ -695,6 +715,7 @@ func newStringGroupTable(
 		table: newTable(done, bounds, key, cols, defs, cache, alloc),
 		gc:    gc,
 		cur:   cur,
+		stats: cursors.CursorStats{ScannedSeries: 1},
 	}
 	t.readTags(tags)
 	t.advance()
@@ -744,6 +765,7 @@ RETRY:
 }
 
 func (t *stringGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -766,6 +788,7 @@ func (t *stringGroupTable) advanceCursor() bool {
 		} else {
 			t.readTags(t.gc.Tags())
 			t.cur = typedCur
+			t.stats.ScannedSeries++
 			return true
 		}
 	}
@@ -773,14 +796,11 @@ func (t *stringGroupTable) advanceCursor() bool {
 }
 
 func (t *stringGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
-	}
-	cs := t.cur.Stats()
-	return cursors.CursorStats{
-		ScannedValues: cs.ScannedValues,
-		ScannedBytes:  cs.ScannedBytes,
+	stats := t.stats
+	if t.cur != nil {
+		stats.Add(t.cur.Stats())
 	}
+	return stats
 }
 
 //
@@ -835,6 +855,10 @@ func (t *booleanTable) Statistics() cursors.CursorStats {
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
+		BlocksDecoded: cs.BlocksDecoded,
+		BlocksMerged:  cs.BlocksMerged,
+		BlocksSkipped: cs.BlocksSkipped,
+		ScannedSeries: 1,
 	}
 }
 
@@ -865,9 +889,10 @@ func (t *booleanTable) advance() bool {
 
 type booleanGroupTable struct {
 	table
-	mu  sync.Mutex
-	gc  storage.GroupCursor
-	cur cursors.BooleanArrayCursor
+	mu    sync.Mutex
+	gc    storage.GroupCursor
+	cur   cursors.BooleanArrayCursor
+	stats cursors.CursorStats
 }
 
 func newBooleanGroupTable(
@@ -886,6 +911,7 @@ func newBooleanGroupTable(
 		table: newTable(done, bounds, key, cols, defs, cache, alloc),
 		gc:    gc,
 		cur:   cur,
+		stats: cursors.CursorStats{ScannedSeries: 1},
 	}
 	t.readTags(tags)
 	t.advance()
@@ -935,6 +961,7 @@ RETRY:
 }
 
 func (t *booleanGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -957,6 +984,7 @@ func (t *booleanGroupTable) advanceCursor() bool {
 		} else {
 			t.readTags(t.gc.Tags())
 			t.cur = typedCur
+			t.stats.ScannedSeries++
 			return true
 		}
 	}
@@ -964,12 +992,9 @@ func (t *booleanGroupTable) advanceCursor() bool {
 }
 
 func (t *booleanGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
-	}
-	cs := t.cur.Stats()
-	return cursors.CursorStats{
-		ScannedValues: cs.ScannedValues,
-		ScannedBytes:  cs.ScannedBytes,
+	stats := t.stats
+	if t.cur != nil {
+		stats.Add(t.cur.Stats())
 	}
+	return stats
 }
@@ -0,0 +1,3 @@
+package storage
+
+//go:generate env GO111MODULE=on go run github.com/benbjohnson/tmpl -data=@types.tmpldata snapshot_cursor.gen.go.tmpl
@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"golang.org/x/time/rate"
+)
+
+// ioActivityWindow is how long an organization is considered to be using the
+// engine after its last read or write.
+const ioActivityWindow = time.Second
+
+const (
+	ioRead  = "read"
+	ioWrite = "write"
+)
+
+// ioScheduler shares the IO bandwidth of the engine between organizations.
+// While a single organization is using the engine it is not throttled. When
+// several organizations are, each one is limited to an equal share of the
+// bandwidth so that no organization can starve the others.
+type ioScheduler struct {
+	bandwidth float64 // bytes per second
+	burst     int
+	now       func() time.Time
+
+	mu     sync.Mutex
+	orgs   map[influxdb.ID]*orgIO
+	active int
+
+	metrics *ioSchedulerMetrics
+}
+
+// orgIO is the IO state of a single organization.
+type orgIO struct {
+	limiter    *rate.Limiter
+	lastActive time.Time
+}
+
+func newIOScheduler(bandwidth int64) *ioScheduler {
+	burst := int(bandwidth)
+	if burst < 1 {
+		burst = 1
+	}
+	return &ioScheduler{
+		bandwidth: float64(bandwidth),
+		burst:     burst,
+		now:       time.Now,
+		orgs:      make(map[influxdb.ID]*orgIO),
+	}
+}
+
+// setDefaultMetricLabels sets the labels of the scheduler's metrics. It must
+// be called before the scheduler is used.
+func (s *ioScheduler) setDefaultMetricLabels(labels map[string]string) {
+	mmu.Lock()
+	if ioms == nil {
+		ioms = newIOSchedulerMetrics(labels)
+	}
+	mmu.Unlock()
+	s.metrics = ioms
+}
+
+// limiter marks the organization as active and returns its limiter. The
+// share of every active organization is updated when organizations start or
+// stop contending for IO.
+func (s *ioScheduler) limiter(orgID influxdb.ID) *rate.Limiter {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orgs[orgID]
+	if !ok {
+		o = &orgIO{limiter: rate.NewLimiter(rate.Inf, s.burst)}
+		s.orgs[orgID] = o
+	}
+	o.lastActive = now
+
+	for id, o := range s.orgs {
+		if now.Sub(o.lastActive) > ioActivityWindow {
+			delete(s.orgs, id)
+		}
+	}
+
+	if len(s.orgs) != s.active || !ok {
+		s.active = len(s.orgs)
+		limit := rate.Inf
+		if s.active > 1 {
+			limit = rate.Limit(s.bandwidth / float64(s.active))
+		}
+		for _, o := range s.orgs {
+			o.limiter.SetLimitAt(now, limit)
+		}
+		if s.metrics != nil {
+			s.metrics.ActiveOrgs.With(s.metrics.Labels()).Set(float64(s.active))
+		}
+	}
+	return o.limiter
+}
+
+// wait blocks until the organization may use n bytes of IO.
+func (s *ioScheduler) wait(ctx context.Context, orgID influxdb.ID, op string, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	var labels map[string]string
+	if s.metrics != nil {
+		labels = s.metrics.Labels()
+		labels["org_id"] = orgID.String()
+		labels["op"] = op
+		s.metrics.Bytes.With(labels).Add(float64(n))
+	}
+
+	lim := s.limiter(orgID)
+	start := s.now()
+	for n > 0 {
+		chunk := n
+		if chunk > s.burst {
+			chunk = s.burst
+		}
+		if err := lim.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+
+	if d := s.now().Sub(start); d > 0 && s.metrics != nil {
+		s.metrics.Throttled.With(labels).Add(d.Seconds())
+	}
+	return nil
+}
+
+// writeSizes returns the number of bytes written per organization by the
+// points of the collection.
+func writeSizes(collection *tsdb.SeriesCollection) map[influxdb.ID]int {
+	sizes := make(map[influxdb.ID]int)
+	for iter := collection.Iterator(); iter.Next(); {
+		if name := iter.Name(); len(name) == influxdb.IDLength {
+			orgID, _ := tsdb.DecodeNameSlice(name)
+			sizes[orgID] += iter.Point().StringSize()
+		}
+	}
+	return sizes
+}
+
+// waitWrite blocks until every organization of the collection may write its points.
+func (s *ioScheduler) waitWrite(ctx context.Context, collection *tsdb.SeriesCollection) error {
+	for orgID, n := range writeSizes(collection) {
+		if err := s.wait(ctx, orgID, ioWrite, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ioCursorIterator throttles the cursors of an iterator by the organization
+// of the series they read.
+type ioCursorIterator struct {
+	cursors.CursorIterator
+	s *ioScheduler
+}
+
+func (itr *ioCursorIterator) Next(ctx context.Context, r *cursors.CursorRequest) (cursors.Cursor, error) {
+	cur, err := itr.CursorIterator.Next(ctx, r)
+	if err != nil || cur == nil || len(r.Name) != influxdb.IDLength {
+		return cur, err
+	}
+
+	orgID, _ := tsdb.DecodeNameSlice(r.Name)
+	t := &ioThrottle{ctx: ctx, s: itr.s, orgID: orgID, cur: cur}
+	switch c := cur.(type) {
+	case cursors.IntegerArrayCursor:
+		return &ioIntegerArrayCursor{IntegerArrayCursor: c, t: t}, nil
+	case cursors.FloatArrayCursor:
+		return &ioFloatArrayCursor{FloatArrayCursor: c, t: t}, nil
+	case cursors.UnsignedArrayCursor:
+		return &ioUnsignedArrayCursor{UnsignedArrayCursor: c, t: t}, nil
+	case cursors.BooleanArrayCursor:
+		return &ioBooleanArrayCursor{BooleanArrayCursor: c, t: t}, nil
+	case cursors.StringArrayCursor:
+		return &ioStringArrayCursor{StringArrayCursor: c, t: t}, nil
+	default:
+		return cur, nil
+	}
+}
+
+// ioThrottle waits for the bytes scanned by a cursor since it last waited.
+type ioThrottle struct {
+	ctx     context.Context
+	s       *ioScheduler
+	orgID   influxdb.ID
+	cur     cursors.Cursor
+	scanned int
+	err     error
+}
+
+// wait reports whether the cursor may continue reading.
+func (t *ioThrottle) wait() bool {
+	if t.err != nil {
+		return false
+	}
+	scanned := t.cur.Stats().ScannedBytes
+	if err := t.s.wait(t.ctx, t.orgID, ioRead, scanned-t.scanned); err != nil {
+		t.err = err
+		return false
+	}
+	t.scanned = scanned
+	return true
+}
+
+func (t *ioThrottle) Err() error {
+	if t.err != nil {
+		return t.err
+	}
+	return t.cur.Err()
+}
+
+type ioIntegerArrayCursor struct {
+	cursors.IntegerArrayCursor
+	t *ioThrottle
+}
+
+func (c *ioIntegerArrayCursor) Next() *cursors.IntegerArray {
+	if !c.t.wait() {
+		return cursors.NewIntegerArrayLen(0)
+	}
+	return c.IntegerArrayCursor.Next()
+}
+
+func (c *ioIntegerArrayCursor) Err() error { return c.t.Err() }
+
+type ioFloatArrayCursor struct {
+	cursors.FloatArrayCursor
+	t *ioThrottle
+}
+
+func (c *ioFloatArrayCursor) Next() *cursors.FloatArray {
+	if !c.t.wait() {
+		return cursors.NewFloatArrayLen(0)
+	}
+	return c.FloatArrayCursor.Next()
+}
+
+func (c *ioFloatArrayCursor) Err() error { return c.t.Err() }
+
+type ioUnsignedArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	t *ioThrottle
+}
+
+func (c *ioUnsignedArrayCursor) Next() *cursors.UnsignedArray {
+	if !c.t.wait() {
+		return cursors.NewUnsignedArrayLen(0)
+	}
+	return c.UnsignedArrayCursor.Next()
+}
+
+func (c *ioUnsignedArrayCursor) Err() error { return c.t.Err() }
+
+type ioBooleanArrayCursor struct {
+	cursors.BooleanArrayCursor
+	t *ioThrottle
+}
+
+func (c *ioBooleanArrayCursor) Next() *cursors.BooleanArray {
+	if !c.t.wait() {
+		return cursors.NewBooleanArrayLen(0)
+	}
+	return c.BooleanArrayCursor.Next()
+}
+
+func (c *ioBooleanArrayCursor) Err() error { return c.t.Err() }
+
+type ioStringArrayCursor struct {
+	cursors.StringArrayCursor
+	t *ioThrottle
+}
+
+func (c *ioStringArrayCursor) Next() *cursors.StringArray {
+	if !c.t.wait() {
+		return cursors.NewStringArrayLen(0)
+	}
+	return c.StringArrayCursor.Next()
+}
+
+func (c *ioStringArrayCursor) Err() error { return c.t.Err() }
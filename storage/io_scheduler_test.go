@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"golang.org/x/time/rate"
+)
+
+func TestIOScheduler_FairShare(t *testing.T) {
+	var (
+		org1 = influxdb.ID(0x5000)
+		org2 = influxdb.ID(0x5001)
+		now  = time.Unix(1000000, 0)
+	)
+
+	s := newIOScheduler(1000)
+	s.now = func() time.Time { return now }
+
+	if got := s.limiter(org1).Limit(); got != rate.Inf {
+		t.Fatalf("expected a single organization not to be throttled, got limit %v", got)
+	}
+
+	lim2 := s.limiter(org2)
+	if got := lim2.Limit(); got != 500 {
+		t.Fatalf("expected organizations to share bandwidth, got limit %v", got)
+	}
+	if got := s.limiter(org1).Limit(); got != 500 {
+		t.Fatalf("expected organizations to share bandwidth, got limit %v", got)
+	}
+
+	// Once org2 is idle, org1 has the engine to itself again.
+	now = now.Add(2 * ioActivityWindow)
+	if got := s.limiter(org1).Limit(); got != rate.Inf {
+		t.Fatalf("expected throttling to stop without contention, got limit %v", got)
+	}
+}
+
+func TestIOScheduler_WaitCanceled(t *testing.T) {
+	s := newIOScheduler(10)
+	s.limiter(influxdb.ID(0x5001))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.wait(ctx, influxdb.ID(0x5000), ioRead, 100); err == nil {
+		t.Fatal("expected wait to fail once its context is canceled")
+	}
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+// LegalHoldFinder finds the legal holds placed on buckets.
+type LegalHoldFinder interface {
+	FindLegalHolds(ctx context.Context, filter influxdb.LegalHoldFilter) ([]*influxdb.LegalHold, error)
+}
+
+// WithLegalHolds configures the engine to spare the data pinned by the active
+// legal holds of a bucket, found with finder, when deleting from the bucket,
+// whether by retention enforcement or by deletes with a predicate.
+func WithLegalHolds(finder LegalHoldFinder) Option {
+	return func(e *Engine) {
+		e.legalHolds = finder
+	}
+}
+
+// rangeDelete is a delete of the series of a bucket matching pred within
+// [min, max]. A nil pred matches every series.
+type rangeDelete struct {
+	min, max int64
+	pred     influxdb.Predicate
+}
+
+// heldDeletes returns the deletes which remove the data of a bucket within
+// [min, max] matching pred, except for the data held by the active legal
+// holds of the bucket.
+func (e *Engine) heldDeletes(ctx context.Context, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) ([]rangeDelete, error) {
+	if e.legalHolds == nil {
+		return []rangeDelete{{min: min, max: max, pred: pred}}, nil
+	}
+	active := true
+	holds, err := e.legalHolds.FindLegalHolds(ctx, influxdb.LegalHoldFilter{
+		BucketID: &bucketID,
+		Active:   &active,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return legalHoldDeletes(holds, min, max, pred)
+}
+
+// legalHoldDeletes splits the delete of [min, max] at the bounds of the
+// holds overlapping it. The data of each part is deleted if it matches pred
+// and none of the predicates of the holds covering the part; parts covered by
+// a hold without a predicate are not deleted at all.
+//
+// Series without the tags compared by the predicate of a hold match neither
+// it nor its negation, so they are conservatively kept.
+func legalHoldDeletes(holds []*influxdb.LegalHold, min, max int64, pred influxdb.Predicate) ([]rangeDelete, error) {
+	type held struct {
+		start, stop int64
+		// not is the negation of the predicate of the hold, or nil if the
+		// hold holds every series.
+		not *datatypes.Node
+	}
+
+	var hs []held
+	starts := []int64{min}
+	for _, h := range holds {
+		start, stop := h.Range()
+		if stop < min || start > max {
+			continue
+		}
+		hd := held{start: start, stop: stop}
+		n, err := predicate.Parse(h.Predicate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid predicate of legal hold %s: %v", h.ID, err)
+		}
+		if n != nil {
+			root, err := n.ToDataType()
+			if err != nil {
+				return nil, fmt.Errorf("invalid predicate of legal hold %s: %v", h.ID, err)
+			}
+			if hd.not, err = negatePredicateNode(root); err != nil {
+				return nil, fmt.Errorf("invalid predicate of legal hold %s: %v", h.ID, err)
+			}
+		}
+		hs = append(hs, hd)
+
+		if start > min {
+			starts = append(starts, start)
+		}
+		if stop < max {
+			starts = append(starts, stop+1)
+		}
+	}
+	if len(hs) == 0 {
+		return []rangeDelete{{min: min, max: max, pred: pred}}, nil
+	}
+
+	root, err := predicateRoot(pred)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	var deletes []rangeDelete
+	for i, start := range starts {
+		if i > 0 && start == starts[i-1] {
+			continue
+		}
+		stop := max
+		for _, next := range starts[i+1:] {
+			if next != start {
+				stop = next - 1
+				break
+			}
+		}
+
+		node, all := root, false
+		for _, h := range hs {
+			if h.start > start || h.stop < stop {
+				continue
+			}
+			if h.not == nil {
+				all = true
+				break
+			}
+			node = andPredicateNodes(node, h.not)
+		}
+		if all {
+			continue
+		}
+
+		d := rangeDelete{min: start, max: stop, pred: pred}
+		if node != root {
+			if d.pred, err = tsm1.NewProtobufPredicate(&datatypes.Predicate{Root: node}); err != nil {
+				return nil, err
+			}
+		}
+		deletes = append(deletes, d)
+	}
+	return deletes, nil
+}
+
+// predicateRoot returns the root node of pred, or nil if pred is nil.
+func predicateRoot(pred influxdb.Predicate) (*datatypes.Node, error) {
+	if pred == nil {
+		return nil, nil
+	}
+	data, err := pred.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	// The protobuf predicate is prefixed with its version byte.
+	if data[0] != 0 {
+		return nil, fmt.Errorf("unknown predicate version: %x", data[0])
+	}
+	var pb datatypes.Predicate
+	if err := pb.Unmarshal(data[1:]); err != nil {
+		return nil, err
+	}
+	return pb.Root, nil
+}
+
+// andPredicateNodes returns the conjunction of left and right. A nil left is
+// true.
+func andPredicateNodes(left, right *datatypes.Node) *datatypes.Node {
+	if left == nil {
+		return right
+	}
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLogicalExpression,
+		Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+		Children: []*datatypes.Node{left, right},
+	}
+}
+
+// negatePredicateNode returns the negation of the predicate node n.
+func negatePredicateNode(n *datatypes.Node) (*datatypes.Node, error) {
+	switch n.GetNodeType() {
+	case datatypes.NodeTypeParenExpression:
+		if len(n.GetChildren()) != 1 {
+			return nil, fmt.Errorf("invalid number of children for paren expression: %d", len(n.GetChildren()))
+		}
+		return negatePredicateNode(n.GetChildren()[0])
+
+	case datatypes.NodeTypeLogicalExpression:
+		logical := datatypes.LogicalAnd
+		if n.GetLogical() == datatypes.LogicalAnd {
+			logical = datatypes.LogicalOr
+		}
+		children := make([]*datatypes.Node, len(n.GetChildren()))
+		for i, c := range n.GetChildren() {
+			var err error
+			if children[i], err = negatePredicateNode(c); err != nil {
+				return nil, err
+			}
+		}
+		return &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: logical},
+			Children: children,
+		}, nil
+
+	case datatypes.NodeTypeComparisonExpression:
+		var comp datatypes.Node_Comparison
+		switch n.GetComparison() {
+		case datatypes.ComparisonEqual:
+			comp = datatypes.ComparisonNotEqual
+		case datatypes.ComparisonNotEqual:
+			comp = datatypes.ComparisonEqual
+		case datatypes.ComparisonRegex:
+			comp = datatypes.ComparisonNotRegex
+		case datatypes.ComparisonNotRegex:
+			comp = datatypes.ComparisonRegex
+		default:
+			return nil, fmt.Errorf("unsupported comparison in legal hold: %v", n.GetComparison())
+		}
+		return &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: comp},
+			Children: n.GetChildren(),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported predicate node in legal hold: %v", n.GetNodeType())
+	}
+}
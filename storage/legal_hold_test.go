@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/predicate"
+)
+
+func TestLegalHoldDeletes(t *testing.T) {
+	at := func(ns int64) *time.Time {
+		ts := time.Unix(0, ns).UTC()
+		return &ts
+	}
+	holds := []*influxdb.LegalHold{
+		{ID: 1, Predicate: `host="a"`, Start: at(10), Stop: at(20)},
+		{ID: 2, Start: at(30), Stop: at(40)},
+		{ID: 3, Start: at(100)},
+	}
+
+	n, err := predicate.Parse(`region="west"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	west, err := predicate.New(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type match struct {
+		key     string
+		matches bool
+	}
+	for _, tc := range []struct {
+		name string
+		pred influxdb.Predicate
+		want []struct {
+			min, max int64
+			matches  []match
+		}
+	}{
+		{
+			name: "without predicate",
+			want: []struct {
+				min, max int64
+				matches  []match
+			}{
+				{min: 0, max: 9},
+				{min: 10, max: 20, matches: []match{
+					{"bucketorg,host=a,region=west", false},
+					{"bucketorg,host=b,region=west", true},
+					{"bucketorg,host=b,region=east", true},
+				}},
+				{min: 21, max: 29},
+				{min: 41, max: 50},
+			},
+		},
+		{
+			name: "with predicate",
+			pred: west,
+			want: []struct {
+				min, max int64
+				matches  []match
+			}{
+				{min: 0, max: 9},
+				{min: 10, max: 20, matches: []match{
+					{"bucketorg,host=a,region=west", false},
+					{"bucketorg,host=b,region=west", true},
+					{"bucketorg,host=b,region=east", false},
+				}},
+				{min: 21, max: 29},
+				{min: 41, max: 50},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := legalHoldDeletes(holds, 0, 50, tc.pred)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d deletes, want %d: %v", len(got), len(tc.want), got)
+			}
+			for i, want := range tc.want {
+				d := got[i]
+				if d.min != want.min || d.max != want.max {
+					t.Errorf("delete %d: got range [%d, %d], want [%d, %d]", i, d.min, d.max, want.min, want.max)
+				}
+				if want.matches == nil {
+					if d.pred != tc.pred {
+						t.Errorf("delete %d: unexpected predicate", i)
+					}
+					continue
+				}
+				for _, m := range want.matches {
+					if got := d.pred.Matches([]byte(m.key)); got != m.matches {
+						t.Errorf("delete %d: %s matches %v, want %v", i, m.key, got, m.matches)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLegalHoldDeletes_NoHolds(t *testing.T) {
+	holds := []*influxdb.LegalHold{
+		{ID: 1, Start: func() *time.Time { ts := time.Unix(0, 100); return &ts }()},
+	}
+	got, err := legalHoldDeletes(holds, 0, 50, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].min != 0 || got[0].max != 50 || got[0].pred != nil {
+		t.Fatalf("unexpected deletes: %v", got)
+	}
+}
@@ -11,8 +11,9 @@ import (
 // storage.Engine instantiations. This allows multiple Engines to be
 // monitored within the same process.
 var (
-	rms *retentionMetrics
-	mmu sync.RWMutex
+	rms  *retentionMetrics
+	ioms *ioSchedulerMetrics
+	mmu  sync.RWMutex
 )
 
 // RetentionPrometheusCollectors returns all prometheus metrics for retention.
@@ -27,6 +28,18 @@ func RetentionPrometheusCollectors() []prometheus.Collector {
 	return collectors
 }
 
+// IOSchedulerPrometheusCollectors returns all prometheus metrics for the IO scheduler.
+func IOSchedulerPrometheusCollectors() []prometheus.Collector {
+	mmu.RLock()
+	defer mmu.RUnlock()
+
+	var collectors []prometheus.Collector
+	if ioms != nil {
+		collectors = append(collectors, ioms.PrometheusCollectors()...)
+	}
+	return collectors
+}
+
 // namespace is the leading part of all published metrics for the Storage service.
 const namespace = "storage"
 
@@ -88,3 +101,67 @@ func (rm *retentionMetrics) PrometheusCollectors() []prometheus.Collector {
 		rm.CheckDuration,
 	}
 }
+
+const ioSchedulerSubsystem = "io_scheduler" // sub-system associated with metrics for sharing IO between organizations.
+
+// ioSchedulerMetrics is a set of metrics concerned with tracking the IO of
+// each organization and how long it was throttled for.
+type ioSchedulerMetrics struct {
+	labels     prometheus.Labels
+	Bytes      *prometheus.CounterVec
+	Throttled  *prometheus.CounterVec
+	ActiveOrgs *prometheus.GaugeVec
+}
+
+func newIOSchedulerMetrics(labels prometheus.Labels) *ioSchedulerMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	orgNames := append(append([]string(nil), names...), "org_id", "op")
+	sort.Strings(orgNames)
+
+	return &ioSchedulerMetrics{
+		labels: labels,
+		Bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: ioSchedulerSubsystem,
+			Name:      "bytes_total",
+			Help:      "Number of bytes read or written by an organization.",
+		}, orgNames),
+
+		Throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: ioSchedulerSubsystem,
+			Name:      "throttled_seconds_total",
+			Help:      "Time an organization waited for its share of IO bandwidth.",
+		}, orgNames),
+
+		ActiveOrgs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: ioSchedulerSubsystem,
+			Name:      "active_orgs",
+			Help:      "Number of organizations sharing IO bandwidth.",
+		}, names),
+	}
+}
+
+// Labels returns a copy of labels for use with IO scheduler metrics.
+func (m *ioSchedulerMetrics) Labels() prometheus.Labels {
+	l := make(map[string]string, len(m.labels))
+	for k, v := range m.labels {
+		l[k] = v
+	}
+	return l
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *ioSchedulerMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.Bytes,
+		m.Throttled,
+		m.ActiveOrgs,
+	}
+}
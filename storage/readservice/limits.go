@@ -0,0 +1,309 @@
+package readservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+// Limits are the maximum resources a single read request may consume. A zero
+// value for a limit means the resource is unlimited.
+type Limits struct {
+	// MaxSeries is the maximum number of series a read request may match.
+	MaxSeries int64
+
+	// MaxPoints is the maximum number of points a read request may scan.
+	MaxPoints int64
+}
+
+func (l Limits) unlimited() bool {
+	return l.MaxSeries <= 0 && l.MaxPoints <= 0
+}
+
+// LimitsFunc returns the read limits which apply to requests made on behalf
+// of the organization identified by orgID.
+type LimitsFunc func(orgID influxdb.ID) Limits
+
+// NewLimitsFunc returns a LimitsFunc which returns the limits in overrides for
+// the organizations it contains and defaults for all other organizations.
+func NewLimitsFunc(defaults Limits, overrides map[influxdb.ID]Limits) LimitsFunc {
+	return func(orgID influxdb.ID) Limits {
+		if l, ok := overrides[orgID]; ok {
+			return l
+		}
+		return defaults
+	}
+}
+
+// ParseOrgLimits parses per-organization limits from a map of organization ID
+// to limits in the form "<max series>:<max points>".
+func ParseOrgLimits(m map[string]string) (map[influxdb.ID]Limits, error) {
+	overrides := make(map[influxdb.ID]Limits, len(m))
+	for k, v := range m {
+		orgID, err := influxdb.IDFromString(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid organization ID %q: %v", k, err)
+		}
+
+		parts := strings.Split(v, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid read limits %q for organization %s: expected <max series>:<max points>", v, orgID)
+		}
+
+		var l Limits
+		if l.MaxSeries, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid max series %q for organization %s: %v", parts[0], orgID, err)
+		}
+		if l.MaxPoints, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid max points %q for organization %s: %v", parts[1], orgID, err)
+		}
+		overrides[*orgID] = l
+	}
+	return overrides, nil
+}
+
+// LimitExceededError is returned by a ResultSet or GroupResultSet when a read
+// request is aborted because it exceeded one of its organization's limits.
+type LimitExceededError struct {
+	OrgID influxdb.ID
+	Limit string // "series" or "points"
+	Max   int64
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("read request exceeded the limit of %d %s per query for organization %s", e.Max, e.Limit, e.OrgID)
+}
+
+// readBudget tracks the resources consumed by a single read request and
+// records the first limit that was exceeded.
+type readBudget struct {
+	orgID  influxdb.ID
+	limits Limits
+	iters  []cursors.CursorIterator
+	err    error
+}
+
+// countSeries counts a series matched by a series cursor that has already
+// matched n series and reports whether the request may continue.
+func (b *readBudget) countSeries(n int64) bool {
+	if b.err != nil {
+		return false
+	}
+	if b.limits.MaxSeries > 0 && n > b.limits.MaxSeries {
+		b.err = &LimitExceededError{OrgID: b.orgID, Limit: "series", Max: b.limits.MaxSeries}
+		return false
+	}
+	return b.checkPoints()
+}
+
+// checkPoints reports whether the request may continue reading points.
+func (b *readBudget) checkPoints() bool {
+	if b.err != nil {
+		return false
+	}
+	if b.limits.MaxPoints <= 0 {
+		return true
+	}
+
+	var n int64
+	for _, itr := range b.iters {
+		n += int64(itr.Stats().ScannedValues)
+	}
+	if n > b.limits.MaxPoints {
+		b.err = &LimitExceededError{OrgID: b.orgID, Limit: "points", Max: b.limits.MaxPoints}
+		return false
+	}
+	return true
+}
+
+// limitedViewer records the cursor iterators created for a read request so that
+// the points they have scanned can be checked against the budget.
+type limitedViewer struct {
+	reads.Viewer
+	budget *readBudget
+}
+
+func (v *limitedViewer) CreateCursorIterator(ctx context.Context) (cursors.CursorIterator, error) {
+	itr, err := v.Viewer.CreateCursorIterator(ctx)
+	if err == nil && itr != nil {
+		v.budget.iters = append(v.budget.iters, itr)
+	}
+	return itr, err
+}
+
+// limitedSeriesCursor ends the series cursor early once the read request
+// exceeds its budget.
+type limitedSeriesCursor struct {
+	reads.SeriesCursor
+	budget *readBudget
+	n      int64
+}
+
+func (c *limitedSeriesCursor) Next() *reads.SeriesRow {
+	if !c.budget.checkPoints() {
+		return nil
+	}
+	row := c.SeriesCursor.Next()
+	if row == nil {
+		return nil
+	}
+	c.n++
+	if !c.budget.countSeries(c.n) {
+		return nil
+	}
+	return row
+}
+
+func (c *limitedSeriesCursor) Err() error {
+	if err := c.SeriesCursor.Err(); err != nil {
+		return err
+	}
+	return c.budget.err
+}
+
+type limitedResultSet struct {
+	reads.ResultSet
+	budget *readBudget
+}
+
+func (r *limitedResultSet) Next() bool {
+	return r.budget.checkPoints() && r.ResultSet.Next()
+}
+
+func (r *limitedResultSet) Cursor() cursors.Cursor {
+	return newLimitedCursor(r.ResultSet.Cursor(), r.budget)
+}
+
+func (r *limitedResultSet) Err() error {
+	if r.budget.err != nil {
+		return r.budget.err
+	}
+	return r.ResultSet.Err()
+}
+
+type limitedGroupResultSet struct {
+	reads.GroupResultSet
+	budget *readBudget
+}
+
+func (r *limitedGroupResultSet) Next() reads.GroupCursor {
+	if !r.budget.checkPoints() {
+		return nil
+	}
+	gc := r.GroupResultSet.Next()
+	if gc == nil {
+		return nil
+	}
+	return &limitedGroupCursor{GroupCursor: gc, budget: r.budget}
+}
+
+func (r *limitedGroupResultSet) Err() error {
+	if r.budget.err != nil {
+		return r.budget.err
+	}
+	return r.GroupResultSet.Err()
+}
+
+type limitedGroupCursor struct {
+	reads.GroupCursor
+	budget *readBudget
+}
+
+func (c *limitedGroupCursor) Next() bool {
+	return c.budget.checkPoints() && c.GroupCursor.Next()
+}
+
+func (c *limitedGroupCursor) Cursor() cursors.Cursor {
+	return newLimitedCursor(c.GroupCursor.Cursor(), c.budget)
+}
+
+func (c *limitedGroupCursor) Err() error {
+	if c.budget.err != nil {
+		return c.budget.err
+	}
+	return c.GroupCursor.Err()
+}
+
+// newLimitedCursor wraps cur so that reading stops as soon as the points
+// scanned by the request exceed its budget, rather than at the end of the series.
+func newLimitedCursor(cur cursors.Cursor, budget *readBudget) cursors.Cursor {
+	switch c := cur.(type) {
+	case cursors.IntegerArrayCursor:
+		return &limitedIntegerArrayCursor{IntegerArrayCursor: c, budget: budget}
+	case cursors.FloatArrayCursor:
+		return &limitedFloatArrayCursor{FloatArrayCursor: c, budget: budget}
+	case cursors.UnsignedArrayCursor:
+		return &limitedUnsignedArrayCursor{UnsignedArrayCursor: c, budget: budget}
+	case cursors.BooleanArrayCursor:
+		return &limitedBooleanArrayCursor{BooleanArrayCursor: c, budget: budget}
+	case cursors.StringArrayCursor:
+		return &limitedStringArrayCursor{StringArrayCursor: c, budget: budget}
+	default:
+		return cur
+	}
+}
+
+type limitedIntegerArrayCursor struct {
+	cursors.IntegerArrayCursor
+	budget *readBudget
+}
+
+func (c *limitedIntegerArrayCursor) Next() *cursors.IntegerArray {
+	if !c.budget.checkPoints() {
+		return cursors.NewIntegerArrayLen(0)
+	}
+	return c.IntegerArrayCursor.Next()
+}
+
+type limitedFloatArrayCursor struct {
+	cursors.FloatArrayCursor
+	budget *readBudget
+}
+
+func (c *limitedFloatArrayCursor) Next() *cursors.FloatArray {
+	if !c.budget.checkPoints() {
+		return cursors.NewFloatArrayLen(0)
+	}
+	return c.FloatArrayCursor.Next()
+}
+
+type limitedUnsignedArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	budget *readBudget
+}
+
+func (c *limitedUnsignedArrayCursor) Next() *cursors.UnsignedArray {
+	if !c.budget.checkPoints() {
+		return cursors.NewUnsignedArrayLen(0)
+	}
+	return c.UnsignedArrayCursor.Next()
+}
+
+type limitedBooleanArrayCursor struct {
+	cursors.BooleanArrayCursor
+	budget *readBudget
+}
+
+func (c *limitedBooleanArrayCursor) Next() *cursors.BooleanArray {
+	if !c.budget.checkPoints() {
+		return cursors.NewBooleanArrayLen(0)
+	}
+	return c.BooleanArrayCursor.Next()
+}
+
+type limitedStringArrayCursor struct {
+	cursors.StringArrayCursor
+	budget *readBudget
+}
+
+func (c *limitedStringArrayCursor) Next() *cursors.StringArray {
+	if !c.budget.checkPoints() {
+		return cursors.NewStringArrayLen(0)
+	}
+	return c.StringArrayCursor.Next()
+}
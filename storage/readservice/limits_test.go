@@ -0,0 +1,116 @@
+package readservice
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+type mockCursorIterator struct {
+	stats cursors.CursorStats
+}
+
+func (itr *mockCursorIterator) Next(ctx context.Context, r *cursors.CursorRequest) (cursors.Cursor, error) {
+	return nil, nil
+}
+
+func (itr *mockCursorIterator) Stats() cursors.CursorStats { return itr.stats }
+
+// mockSeriesCursor returns n series, each of which scans pointsPerSeries
+// points from itr.
+type mockSeriesCursor struct {
+	itr             *mockCursorIterator
+	n               int
+	pointsPerSeries int
+}
+
+func (c *mockSeriesCursor) Close()     {}
+func (c *mockSeriesCursor) Err() error { return nil }
+
+func (c *mockSeriesCursor) Next() *reads.SeriesRow {
+	if c.n == 0 {
+		return nil
+	}
+	c.n--
+	c.itr.stats.ScannedValues += c.pointsPerSeries
+	return &reads.SeriesRow{Query: c.itr}
+}
+
+func TestLimitedSeriesCursor(t *testing.T) {
+	orgID := influxdb.ID(0x1000)
+
+	tests := []struct {
+		name   string
+		limits Limits
+		expN   int
+		expErr error
+	}{
+		{
+			name: "unlimited",
+			expN: 10,
+		},
+		{
+			name:   "within limits",
+			limits: Limits{MaxSeries: 10, MaxPoints: 100},
+			expN:   10,
+		},
+		{
+			name:   "max series",
+			limits: Limits{MaxSeries: 4},
+			expN:   4,
+			expErr: &LimitExceededError{OrgID: orgID, Limit: "series", Max: 4},
+		},
+		{
+			name:   "max points",
+			limits: Limits{MaxPoints: 25},
+			expN:   2,
+			expErr: &LimitExceededError{OrgID: orgID, Limit: "points", Max: 25},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			itr := &mockCursorIterator{}
+			b := &readBudget{orgID: orgID, limits: tt.limits, iters: []cursors.CursorIterator{itr}}
+			cur := &limitedSeriesCursor{
+				SeriesCursor: &mockSeriesCursor{itr: itr, n: 10, pointsPerSeries: 10},
+				budget:       b,
+			}
+
+			var n int
+			for cur.Next() != nil {
+				n++
+			}
+			if n != tt.expN {
+				t.Errorf("unexpected number of series: got %d, exp %d", n, tt.expN)
+			}
+			if got := cur.Err(); !reflect.DeepEqual(got, tt.expErr) {
+				t.Errorf("unexpected error: got %v, exp %v", got, tt.expErr)
+			}
+		})
+	}
+}
+
+func TestParseOrgLimits(t *testing.T) {
+	got, err := ParseOrgLimits(map[string]string{"0000000000001000": "10:1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[influxdb.ID]Limits{0x1000: {MaxSeries: 10, MaxPoints: 1000}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected limits: got %v, exp %v", got, exp)
+	}
+
+	for _, v := range []string{"10", "a:10", "10:b", "1:2:3"} {
+		if _, err := ParseOrgLimits(map[string]string{"0000000000001000": v}); err == nil {
+			t.Errorf("expected error parsing %q", v)
+		}
+	}
+	if _, err := ParseOrgLimits(map[string]string{"bad": "1:1"}); err == nil {
+		t.Error("expected error parsing invalid organization ID")
+	}
+}
@@ -2,13 +2,12 @@ package readservice
 
 import (
 	"github.com/influxdata/influxdb/v2/query"
-	"github.com/influxdata/influxdb/v2/query/control"
 )
 
-// NewProxyQueryService returns a proxy query service based on the given queryController
-// suitable for the storage read service.
-func NewProxyQueryService(queryController *control.Controller) query.ProxyQueryService {
+// NewProxyQueryService returns a proxy query service based on the given query service,
+// such as the query controller, suitable for the storage read service.
+func NewProxyQueryService(queryService query.AsyncQueryService) query.ProxyQueryService {
 	return query.ProxyQueryServiceAsyncBridge{
-		AsyncQueryService: queryController,
+		AsyncQueryService: queryService,
 	}
 }
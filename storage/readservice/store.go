@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/reads"
@@ -15,11 +16,43 @@ import (
 
 type store struct {
 	viewer reads.Viewer
+	limits LimitsFunc
+}
+
+// StoreOption is a functional option for configuring a store.
+type StoreOption func(*store)
+
+// WithLimits sets the function used to determine the read limits of each
+// organization. Read requests which exceed their limits are aborted with a
+// *LimitExceededError.
+func WithLimits(fn LimitsFunc) StoreOption {
+	return func(s *store) {
+		s.limits = fn
+	}
 }
 
 // NewStore creates a store used to query time-series data.
-func NewStore(viewer reads.Viewer) reads.Store {
-	return &store{viewer: viewer}
+func NewStore(viewer reads.Viewer, opts ...StoreOption) reads.Store {
+	s := &store{viewer: viewer}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// budget returns the budget for a read request made on behalf of orgID along
+// with the viewer the request must use, or a nil budget if the organization
+// has no limits.
+func (s *store) budget(orgID influxdb.ID) (*readBudget, reads.Viewer) {
+	if s.limits == nil {
+		return nil, s.viewer
+	}
+	limits := s.limits(orgID)
+	if limits.unlimited() {
+		return nil, s.viewer
+	}
+	b := &readBudget{orgID: orgID, limits: limits}
+	return b, &limitedViewer{Viewer: s.viewer, budget: b}
 }
 
 func (s *store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
@@ -35,14 +68,20 @@ func (s *store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest
 		return nil, tracing.LogError(span, err)
 	}
 
+	budget, viewer := s.budget(source.GetOrgID())
+
 	var cur reads.SeriesCursor
-	if cur, err = reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), req.Predicate, s.viewer); err != nil {
+	if cur, err = reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), req.Predicate, viewer); err != nil {
 		return nil, tracing.LogError(span, err)
 	} else if cur == nil {
 		return nil, nil
 	}
 
-	return reads.NewFilteredResultSet(ctx, req, cur), nil
+	if budget == nil {
+		return reads.NewFilteredResultSet(ctx, req, cur), nil
+	}
+	cur = &limitedSeriesCursor{SeriesCursor: cur, budget: budget}
+	return &limitedResultSet{ResultSet: reads.NewFilteredResultSet(ctx, req, cur), budget: budget}, nil
 }
 
 func (s *store) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest) (reads.GroupResultSet, error) {
@@ -58,11 +97,27 @@ func (s *store) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest)
 		return nil, tracing.LogError(span, err)
 	}
 
+	budget, viewer := s.budget(source.GetOrgID())
+
 	newCursor := func() (reads.SeriesCursor, error) {
-		return reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), req.Predicate, s.viewer)
+		cur, err := reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), req.Predicate, viewer)
+		if budget == nil || cur == nil || err != nil {
+			return cur, err
+		}
+		return &limitedSeriesCursor{SeriesCursor: cur, budget: budget}, nil
 	}
 
-	return reads.NewGroupResultSet(ctx, req, newCursor), nil
+	rs := reads.NewGroupResultSet(ctx, req, newCursor)
+	if budget == nil {
+		return rs, nil
+	} else if rs == nil {
+		// The series limit may have been exceeded while the groups were sorted.
+		if budget.err != nil {
+			return nil, tracing.LogError(span, budget.err)
+		}
+		return nil, nil
+	}
+	return &limitedGroupResultSet{GroupResultSet: rs, budget: budget}, nil
 }
 
 func (s *store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
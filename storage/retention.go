@@ -49,6 +49,11 @@ type retentionEnforcer struct {
 	// organisations.
 	BucketService BucketFinder
 
+	// MaxRetention, if set, caps the retention period of every bucket so
+	// that no data older than it is kept, including in buckets with
+	// infinite retention.
+	MaxRetention time.Duration
+
 	logger *zap.Logger
 
 	tracker *retentionTracker
@@ -129,14 +134,15 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 
 	var skipInf, skipInvalid int
 	for _, b := range buckets {
+		retention := s.retentionPeriod(b)
 		bucketFields := []zapcore.Field{
 			zap.String("org_id", b.OrgID.String()),
 			zap.String("bucket_id", b.ID.String()),
-			zap.Duration("retention_period", b.RetentionPeriod),
+			zap.Duration("retention_period", retention),
 			zap.String("system_type", b.Type.String()),
 		}
 
-		if b.RetentionPeriod == 0 {
+		if retention == 0 {
 			logger.Debug("Skipping bucket with infinite retention", bucketFields...)
 			skipInf++
 			continue
@@ -147,14 +153,14 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 		}
 
 		min := int64(math.MinInt64)
-		max := now.Add(-b.RetentionPeriod).UnixNano()
+		max := now.Add(-retention).UnixNano()
 
 		span, ctx := tracing.StartSpanFromContext(ctx)
 		span.LogKV(
 			"bucket_id", b.ID,
 			"org_id", b.OrgID,
 			"system_type", b.Type,
-			"retention_period", b.RetentionPeriod,
+			"retention_period", retention,
 			"retention_policy", b.RetentionPolicyName,
 			"from", time.Unix(0, min).UTC(),
 			"to", time.Unix(0, max).UTC(),
@@ -175,6 +181,14 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 	}
 }
 
+// retentionPeriod returns the retention period enforced for the bucket.
+func (s *retentionEnforcer) retentionPeriod(b *influxdb.Bucket) time.Duration {
+	if s.MaxRetention > 0 && (b.RetentionPeriod == 0 || b.RetentionPeriod > s.MaxRetention) {
+		return s.MaxRetention
+	}
+	return b.RetentionPeriod
+}
+
 // getBucketInformation returns a slice of buckets to run retention on.
 func (s *retentionEnforcer) getBucketInformation(ctx context.Context) ([]*influxdb.Bucket, error) {
 	ctx, cancel := context.WithTimeout(ctx, bucketAPITimeout)
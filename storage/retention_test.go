@@ -240,6 +240,36 @@ func TestRetentionService(t *testing.T) {
 	})
 }
 
+func TestRetentionService_MaxRetention(t *testing.T) {
+	t.Parallel()
+	engine := NewTestEngine()
+	service := newRetentionEnforcer(engine, &TestSnapshotter{}, NewTestBucketFinder())
+	service.MaxRetention = time.Hour
+	now := time.Date(2018, 4, 10, 23, 12, 33, 0, time.UTC)
+
+	buckets := []*influxdb.Bucket{
+		{OrgID: 0x5000, ID: 0x6000, RetentionPeriod: 0},
+		{OrgID: 0x5000, ID: 0x6001, RetentionPeriod: 3 * time.Hour},
+		{OrgID: 0x5000, ID: 0x6002, RetentionPeriod: 30 * time.Minute},
+	}
+	want := map[influxdb.ID]int64{
+		0x6000: now.Add(-time.Hour).UnixNano(),
+		0x6001: now.Add(-time.Hour).UnixNano(),
+		0x6002: now.Add(-30 * time.Minute).UnixNano(),
+	}
+
+	got := map[influxdb.ID]int64{}
+	engine.DeleteBucketRangeFn = func(ctx context.Context, orgID, bucketID influxdb.ID, from, to int64) error {
+		got[bucketID] = to
+		return nil
+	}
+
+	service.expireData(context.Background(), buckets, now)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got\n%#v\nexpected\n%#v", got, want)
+	}
+}
+
 func TestMetrics_Retention(t *testing.T) {
 	t.Parallel()
 	// metrics to be shared by multiple file stores.
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"github.com/influxdata/influxql"
+)
+
+// SnapshotViewer reads the data of the bucket of a snapshot from the TSM
+// files it links. It implements the same methods as the Engine used by the
+// read service, so that queries can read a snapshot as they read the engine.
+//
+// Snapshots have no index, so series are found by scanning the keys of the
+// bucket in the files, and predicates are evaluated against the tags of each
+// series.
+type SnapshotViewer struct {
+	snap   *influxdb.BucketSnapshot
+	fs     *tsm1.FileStore
+	prefix []byte
+}
+
+// OpenSnapshotViewer opens a viewer of the files of snap in dir.
+func OpenSnapshotViewer(ctx context.Context, dir string, snap *influxdb.BucketSnapshot) (*SnapshotViewer, error) {
+	fs := tsm1.NewFileStore(dir)
+	if err := fs.Open(ctx); err != nil {
+		return nil, err
+	}
+
+	name := tsdb.EncodeName(snap.OrgID, snap.BucketID)
+	return &SnapshotViewer{
+		snap:   snap,
+		fs:     fs,
+		prefix: models.EscapeMeasurement(name[:]),
+	}, nil
+}
+
+// Close closes the files of the snapshot.
+func (v *SnapshotViewer) Close() error {
+	return v.fs.Close()
+}
+
+// checkBucket returns an error if orgID and bucketID are not those of the
+// snapshot.
+func (v *SnapshotViewer) checkBucket(orgID, bucketID influxdb.ID) error {
+	if orgID != v.snap.OrgID || bucketID != v.snap.BucketID {
+		return fmt.Errorf("snapshot %s is not a snapshot of bucket %s", v.snap.ID, bucketID)
+	}
+	return nil
+}
+
+// CreateSeriesCursor creates a SeriesCursor over the series of the snapshot
+// matching cond.
+func (v *SnapshotViewer) CreateSeriesCursor(ctx context.Context, orgID, bucketID influxdb.ID, cond influxql.Expr) (SeriesCursor, error) {
+	if err := v.checkBucket(orgID, bucketID); err != nil {
+		return nil, err
+	}
+
+	cur := &snapshotSeriesCursor{}
+	var last []byte
+	err := v.fs.WalkKeys(v.prefix, func(key []byte, typ byte) error {
+		if !bytes.HasPrefix(key, v.prefix) {
+			return errSnapshotKeysDone
+		}
+		// Keys are walked in order, once for every file holding them.
+		if bytes.Equal(key, last) {
+			return nil
+		}
+		last = append(last[:0], key...)
+
+		// key is only valid during the call, and the row outlives it.
+		seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+		name, tags := models.ParseKeyBytes(append([]byte(nil), seriesKey...))
+		if !snapshotTagsMatch(cond, tags) {
+			return nil
+		}
+		cur.rows = append(cur.rows, SeriesCursorRow{Name: name, Tags: tags})
+		return nil
+	})
+	if err != nil && err != errSnapshotKeysDone {
+		return nil, err
+	}
+	return cur, nil
+}
+
+// CreateCursorIterator creates a CursorIterator reading the values of the
+// snapshot.
+func (v *SnapshotViewer) CreateCursorIterator(ctx context.Context) (cursors.CursorIterator, error) {
+	return &snapshotCursorIterator{fs: v.fs}, nil
+}
+
+// TagKeys returns an iterator over the tag keys of the series of the
+// snapshot matching predicate with data in [start, end].
+func (v *SnapshotViewer) TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	if err := v.checkBucket(orgID, bucketID); err != nil {
+		return nil, err
+	}
+
+	var keyset models.TagKeysSet
+	stats, err := v.walkSeries(ctx, start, end, predicate, func(tags models.Tags) {
+		keyset.UnionKeys(tags)
+	})
+	return cursors.NewStringSliceIteratorWithStats(keyset.Keys(), stats), err
+}
+
+// TagValues returns an iterator over the values of tagKey of the series of
+// the snapshot matching predicate with data in [start, end].
+func (v *SnapshotViewer) TagValues(ctx context.Context, orgID, bucketID influxdb.ID, tagKey string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	if err := v.checkBucket(orgID, bucketID); err != nil {
+		return nil, err
+	}
+
+	key := []byte(tagKey)
+	values := make(map[string]struct{})
+	stats, err := v.walkSeries(ctx, start, end, predicate, func(tags models.Tags) {
+		if value := tags.Get(key); value != nil {
+			values[string(value)] = struct{}{}
+		}
+	})
+
+	vals := make([]string, 0, len(values))
+	for value := range values {
+		vals = append(vals, value)
+	}
+	sort.Strings(vals)
+	return cursors.NewStringSliceIteratorWithStats(vals, stats), err
+}
+
+// walkSeries calls fn with the tags of every key of the snapshot matching
+// predicate with data in [start, end]. Keys held by several files may be
+// passed more than once.
+func (v *SnapshotViewer) walkSeries(ctx context.Context, start, end int64, predicate influxql.Expr, fn func(tags models.Tags)) (cursors.CursorStats, error) {
+	var (
+		stats cursors.CursorStats
+		tags  models.Tags
+		err   error
+	)
+	v.fs.ForEachFile(func(f tsm1.TSMFile) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		if !f.OverlapsTimeRange(start, end) || !f.OverlapsKeyPrefixRange(v.prefix, v.prefix) {
+			return true
+		}
+
+		iter := f.TimeRangeIterator(v.prefix, start, end)
+		for iter.Next() {
+			sfkey := iter.Key()
+			if !bytes.HasPrefix(sfkey, v.prefix) {
+				break
+			}
+
+			key, _ := tsm1.SeriesAndFieldFromCompositeKey(sfkey)
+			tags = models.ParseTagsWithTags(key, tags[:0])
+			if snapshotTagsMatch(predicate, tags) && iter.HasData() {
+				fn(tags)
+			}
+		}
+		stats.Add(iter.Stats())
+		err = iter.Err()
+		return err == nil
+	})
+	return stats, err
+}
+
+// errSnapshotKeysDone stops walking the keys of a snapshot once past those
+// of its bucket.
+var errSnapshotKeysDone = errors.New("snapshot keys done")
+
+// snapshotTagsMatch reports whether tags match cond. Missing tags compare
+// as empty, as they do in the index.
+func snapshotTagsMatch(cond influxql.Expr, tags models.Tags) bool {
+	if cond == nil {
+		return true
+	}
+	eval := influxql.ValuerEval{Valuer: snapshotTagsValuer(tags)}
+	return eval.EvalBool(cond)
+}
+
+type snapshotTagsValuer models.Tags
+
+func (v snapshotTagsValuer) Value(key string) (interface{}, bool) {
+	return string(models.Tags(v).Get([]byte(key))), true
+}
+
+// snapshotSeriesCursor is a SeriesCursor over the series found in a
+// snapshot.
+type snapshotSeriesCursor struct {
+	rows []SeriesCursorRow
+	ofs  int
+}
+
+func (cur *snapshotSeriesCursor) Close() {}
+
+func (cur *snapshotSeriesCursor) Next() (*SeriesCursorRow, error) {
+	if cur.ofs >= len(cur.rows) {
+		return nil, nil
+	}
+	row := &cur.rows[cur.ofs]
+	cur.ofs++
+	return row, nil
+}
+
+// snapshotCursorIterator creates cursors reading the values of a key from
+// the files of a snapshot.
+type snapshotCursorIterator struct {
+	fs    *tsm1.FileStore
+	key   []byte
+	stats cursors.CursorStats
+}
+
+func (q *snapshotCursorIterator) Next(ctx context.Context, r *cursors.CursorRequest) (cursors.Cursor, error) {
+	q.key = models.AppendMakeKey(q.key[:0], r.Name, r.Tags)
+	q.key = append(q.key, tsm1.KeyFieldSeparatorBytes...)
+	q.key = append(q.key, r.Field...)
+
+	typ, err := q.fs.Type(q.key)
+	if err != nil {
+		// The key is not in any file of the snapshot.
+		return nil, nil
+	}
+
+	seek := r.StartTime
+	if !r.Ascending {
+		seek = r.EndTime
+	}
+	kc := q.fs.KeyCursor(ctx, q.key, seek, r.Ascending)
+
+	switch typ {
+	case tsm1.BlockFloat64:
+		return newFloatSnapshotCursor(kc, r, &q.stats), nil
+	case tsm1.BlockInteger:
+		return newIntegerSnapshotCursor(kc, r, &q.stats), nil
+	case tsm1.BlockUnsigned:
+		return newUnsignedSnapshotCursor(kc, r, &q.stats), nil
+	case tsm1.BlockString:
+		return newStringSnapshotCursor(kc, r, &q.stats), nil
+	case tsm1.BlockBoolean:
+		return newBooleanSnapshotCursor(kc, r, &q.stats), nil
+	default:
+		kc.Close()
+		return nil, fmt.Errorf("unknown block type %d for key %q", typ, q.key)
+	}
+}
+
+// Stats returns the cumulative stats of the cursors created by the iterator.
+func (q *snapshotCursorIterator) Stats() cursors.CursorStats {
+	return q.stats
+}
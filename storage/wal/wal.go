@@ -321,7 +321,33 @@ func (l *WAL) WriteMulti(ctx context.Context, values map[string][]value.Value) (
 		Values: values,
 	}
 
-	id, err := l.writeToLog(entry)
+	id, err := l.writeToLog(entry, true)
+	if err != nil {
+		l.tracker.IncWritesErr()
+		return -1, err
+	}
+	l.tracker.IncWritesOK()
+
+	return id, nil
+}
+
+// WriteMultiNoSync writes the given values to the WAL like WriteMulti, but
+// returns once they are written without waiting for the WAL to be synced to
+// disk. The values may be lost if the process crashes before the sync which
+// is scheduled for them completes.
+func (l *WAL) WriteMultiNoSync(ctx context.Context, values map[string][]value.Value) (int, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if !l.enabled {
+		return -1, nil
+	}
+
+	entry := &WriteWALEntry{
+		Values: values,
+	}
+
+	id, err := l.writeToLog(entry, false)
 	if err != nil {
 		l.tracker.IncWritesErr()
 		return -1, err
@@ -417,7 +443,9 @@ func (l *WAL) DiskSizeBytes() int64 {
 	return int64(l.tracker.OldSegmentSize() + l.tracker.CurrentSegmentSize())
 }
 
-func (l *WAL) writeToLog(entry WALEntry) (int, error) {
+// writeToLog writes entry to the current segment and schedules a sync. If
+// wait is true, it returns once the sync completes.
+func (l *WAL) writeToLog(entry WALEntry, wait bool) (int, error) {
 	// limit how many concurrent encodings can be in flight.  Since we can only
 	// write one at a time to disk, a slow disk can cause the allocations below
 	// to increase quickly.  If we're backed up, wait until others have completed.
@@ -434,7 +462,14 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 	compressed := snappy.Encode(encBuf, b)
 	bytesPool.Put(bytes)
 
-	syncErr := make(chan error)
+	// The sync sends its error whether or not it is waited for, so the
+	// channel is buffered for writes which do not wait.
+	var syncErr chan error
+	if wait {
+		syncErr = make(chan error)
+	} else {
+		syncErr = make(chan error, 1)
+	}
 
 	segID, err := func() (int, error) {
 		l.mu.Lock()
@@ -474,7 +509,7 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 
 	bytesPool.Put(encBuf)
 
-	if err != nil {
+	if err != nil || !wait {
 		return segID, err
 	}
 
@@ -532,7 +567,7 @@ func (l *WAL) DeleteBucketRange(orgID, bucketID influxdb.ID, min, max int64, pre
 		Predicate: pred,
 	}
 
-	id, err := l.writeToLog(entry)
+	id, err := l.writeToLog(entry, true)
 	if err != nil {
 		return -1, err
 	}
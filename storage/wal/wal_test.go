@@ -262,6 +262,59 @@ func TestWALWriter_DeleteBucketRange(t *testing.T) {
 	}
 }
 
+func TestWAL_WriteMultiNoSync(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+
+	values := map[string][]value.Value{
+		"cpu,host=A#!~#value": []value.Value{
+			value.NewValue(1, 1.1),
+		},
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := w.WriteMultiNoSync(context.Background(), values); err != nil {
+			t.Fatalf("error writing points: %v", err)
+		}
+	}
+	path := w.currentSegmentWriter.path()
+
+	// Closing the WAL flushes the writes which were not waited for.
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing wal: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("error opening segment: %v", err)
+	}
+	defer f.Close()
+
+	r := NewWALSegmentReader(f)
+	var n int
+	for r.Next() {
+		we, err := r.Read()
+		if err != nil {
+			fatal(t, "read entry", err)
+		}
+		e, ok := we.(*WriteWALEntry)
+		if !ok {
+			t.Fatalf("expected WriteWALEntry: got %#v", we)
+		}
+		if got, exp := e.Values["cpu,host=A#!~#value"][0].String(), values["cpu,host=A#!~#value"][0].String(); got != exp {
+			t.Fatalf("points mismatch: got %v, exp %v", got, exp)
+		}
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("got %d entries, exp 3", n)
+	}
+}
+
 func TestWAL_ClosedSegments(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
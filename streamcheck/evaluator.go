@@ -0,0 +1,433 @@
+// Package streamcheck evaluates the streaming threshold checks against the
+// points written to their buckets as they are written. A status is written
+// to the _monitoring bucket of the organization of a check when the level of
+// a series changes, so that it reaches the alert history and the
+// notification rules of the check without waiting for the task of the check
+// to query the data.
+//
+// The streaming checks are found again periodically, so changes to checks
+// are applied within the refresh interval. The levels of series are kept in
+// memory; after a restart the first value of a series which meets a
+// threshold writes a status.
+package streamcheck
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+// DefaultRefreshInterval is how often the streaming checks are found again by
+// default.
+const DefaultRefreshInterval = 10 * time.Second
+
+const (
+	statusesMeasurement = "statuses"
+	checkType           = "threshold"
+)
+
+// TaskFinder finds the tasks of checks, whose status is the status of their
+// check.
+type TaskFinder interface {
+	FindTaskByID(ctx context.Context, id influxdb.ID) (*influxdb.Task, error)
+}
+
+// streamingCheck is an active streaming threshold check, with the tag values
+// of its query by the keys of the tags of exploded points.
+type streamingCheck struct {
+	check        *check.Threshold
+	monitoringID influxdb.ID
+	filters      map[string][]string
+}
+
+// matches returns whether the tags of a point match the query of c.
+func (c *streamingCheck) matches(tags models.Tags) bool {
+	for key, values := range c.filters {
+		v := tags.Get([]byte(key))
+		found := false
+		for _, want := range values {
+			if string(v) == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// levelKey identifies the level of a series of a check.
+type levelKey struct {
+	checkID influxdb.ID
+	series  string
+}
+
+// Evaluator evaluates the streaming threshold checks against the points
+// written to their buckets.
+type Evaluator struct {
+	log      *zap.Logger
+	orgs     influxdb.OrganizationService
+	checks   influxdb.CheckService
+	tasks    TaskFinder
+	buckets  influxdb.BucketService
+	pw       storage.PointsWriter
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	byBucket map[influxdb.ID][]*streamingCheck
+
+	levelsMu sync.Mutex
+	levels   map[levelKey]notification.CheckLevel
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEvaluator returns an Evaluator finding the streaming checks every
+// interval, which writes their statuses with pw.
+func NewEvaluator(log *zap.Logger, orgs influxdb.OrganizationService, checks influxdb.CheckService, tasks TaskFinder, buckets influxdb.BucketService, pw storage.PointsWriter, interval time.Duration) *Evaluator {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Evaluator{
+		log:      log,
+		orgs:     orgs,
+		checks:   checks,
+		tasks:    tasks,
+		buckets:  buckets,
+		pw:       pw,
+		interval: interval,
+		now:      time.Now,
+		byBucket: make(map[influxdb.ID][]*streamingCheck),
+		levels:   make(map[levelKey]notification.CheckLevel),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Open finds the streaming checks, and starts finding them again
+// periodically. An interval of zero disables the streaming checks.
+func (e *Evaluator) Open(ctx context.Context) error {
+	if e.interval <= 0 {
+		return nil
+	}
+	if err := e.Refresh(ctx); err != nil {
+		return err
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := e.Refresh(e.ctx); err != nil {
+				e.log.Error("Failed to find streaming checks", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops finding the streaming checks.
+func (e *Evaluator) Close() error {
+	e.cancel()
+	e.wg.Wait()
+	return nil
+}
+
+// Refresh finds the active streaming threshold checks of every organization.
+// The levels of the series of checks which are no longer streaming are
+// forgotten.
+func (e *Evaluator) Refresh(ctx context.Context) error {
+	orgs, _, err := e.orgs.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		return err
+	}
+
+	byBucket := make(map[influxdb.ID][]*streamingCheck)
+	active := make(map[influxdb.ID]bool)
+	for _, o := range orgs {
+		orgID := o.ID
+		checks, _, err := e.checks.FindChecks(ctx, influxdb.CheckFilter{OrgID: &orgID})
+		if err != nil {
+			e.log.Error("Failed to find checks", zap.Stringer("orgID", o.ID), zap.Error(err))
+			continue
+		}
+		for _, c := range checks {
+			t, ok := c.(*check.Threshold)
+			if !ok || !t.Streaming {
+				continue
+			}
+			bucketID, sc, err := e.streamingCheck(ctx, t)
+			if err != nil {
+				e.log.Info("Skipping streaming check", zap.Stringer("checkID", t.ID), zap.Error(err))
+				continue
+			}
+			if sc == nil {
+				continue
+			}
+			byBucket[bucketID] = append(byBucket[bucketID], sc)
+			active[t.ID] = true
+		}
+	}
+
+	e.mu.Lock()
+	e.byBucket = byBucket
+	e.mu.Unlock()
+
+	e.levelsMu.Lock()
+	defer e.levelsMu.Unlock()
+	for k := range e.levels {
+		if !active[k.checkID] {
+			delete(e.levels, k)
+		}
+	}
+	return nil
+}
+
+// streamingCheck returns the bucket of the query of t and t as a streaming
+// check, or a nil check if t is inactive.
+func (e *Evaluator) streamingCheck(ctx context.Context, t *check.Threshold) (influxdb.ID, *streamingCheck, error) {
+	task, err := e.tasks.FindTaskByID(ctx, t.TaskID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if task.Status != string(influxdb.TaskActive) {
+		return 0, nil, nil
+	}
+
+	cfg := t.Query.BuilderConfig
+	if len(cfg.Buckets) != 1 {
+		return 0, nil, fmt.Errorf("query of %d buckets", len(cfg.Buckets))
+	}
+	b, err := e.buckets.FindBucketByName(ctx, t.OrgID, cfg.Buckets[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	mon, err := e.buckets.FindBucketByName(ctx, t.OrgID, influxdb.MonitoringSystemBucketName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sc := &streamingCheck{
+		check:        t,
+		monitoringID: mon.ID,
+		filters:      make(map[string][]string),
+	}
+	for _, tag := range cfg.Tags {
+		if len(tag.Values) == 0 {
+			continue
+		}
+		key := tag.Key
+		switch key {
+		case "_measurement":
+			key = models.MeasurementTagKey
+		case "_field":
+			key = models.FieldKeyTagKey
+		}
+		sc.filters[key] = append(sc.filters[key], tag.Values...)
+	}
+	return b.ID, sc, nil
+}
+
+// Evaluate evaluates the streaming checks of the buckets of points, which
+// are exploded points named by the encoded IDs of their organization and
+// bucket, and writes the statuses of the series whose level changes.
+func (e *Evaluator) Evaluate(ctx context.Context, points []models.Point) error {
+	e.mu.RLock()
+	byBucket := e.byBucket
+	e.mu.RUnlock()
+	if len(byBucket) == 0 {
+		return nil
+	}
+
+	type monitoringBucket struct{ orgID, bucketID influxdb.ID }
+	statuses := make(map[monitoringBucket][]models.Point)
+	now := e.now()
+	for _, p := range points {
+		name := p.Name()
+		// The names of buckets are the 16 bytes of the IDs of their
+		// organization and their own.
+		if len(name) != 16 {
+			continue
+		}
+		_, bucketID := tsdb.DecodeNameSlice(name)
+		checks := byBucket[bucketID]
+		if len(checks) == 0 {
+			continue
+		}
+
+		v, ok := pointValue(p)
+		if !ok {
+			continue
+		}
+		tags := p.Tags()
+		for _, c := range checks {
+			if !c.matches(tags) {
+				continue
+			}
+			level := c.check.Level(v)
+			if !e.levelChanged(levelKey{checkID: c.check.ID, series: string(p.Key())}, level) {
+				continue
+			}
+			st, err := newStatus(c.check, level, p, v, now)
+			if err != nil {
+				e.log.Info("Failed to create streaming check status", zap.Stringer("checkID", c.check.ID), zap.Error(err))
+				continue
+			}
+			mb := monitoringBucket{orgID: c.check.OrgID, bucketID: c.monitoringID}
+			statuses[mb] = append(statuses[mb], st)
+		}
+	}
+
+	for mb, sts := range statuses {
+		exploded, err := tsdb.ExplodePoints(mb.orgID, mb.bucketID, sts)
+		if err != nil {
+			return err
+		}
+		if err := e.pw.WritePoints(ctx, exploded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// levelChanged records the level of the series of a check, and returns
+// whether a status is written for it. The first value of a series writes a
+// status only if it meets a threshold, so that a restart does not write the
+// statuses of every series.
+func (e *Evaluator) levelChanged(k levelKey, level notification.CheckLevel) bool {
+	e.levelsMu.Lock()
+	defer e.levelsMu.Unlock()
+	prev, seen := e.levels[k]
+	e.levels[k] = level
+	if !seen {
+		return level != notification.Ok
+	}
+	return prev != level
+}
+
+// pointValue returns the value of the single field of an exploded point as a
+// float, if it is numeric.
+func pointValue(p models.Point) (float64, bool) {
+	fields, err := p.Fields()
+	if err != nil {
+		return 0, false
+	}
+	for _, v := range fields {
+		switch v := v.(type) {
+		case float64:
+			return v, true
+		case int64:
+			return float64(v), true
+		case uint64:
+			return float64(v), true
+		}
+	}
+	return 0, false
+}
+
+// newStatus returns the status of the series of the point p of check c, in
+// the measurement of statuses and with the columns the task of the check
+// writes.
+func newStatus(c *check.Threshold, level notification.CheckLevel, p models.Point, v float64, now time.Time) (models.Point, error) {
+	cols := map[string]string{
+		"_check_id":           c.ID.String(),
+		"_check_name":         c.Name,
+		"_level":              strings.ToLower(level.String()),
+		"_type":               checkType,
+		"_source_measurement": "",
+	}
+	var field string
+	for _, t := range p.Tags() {
+		switch string(t.Key) {
+		case models.MeasurementTagKey:
+			cols["_source_measurement"] = string(t.Value)
+		case models.FieldKeyTagKey:
+			field = string(t.Value)
+		default:
+			cols[string(t.Key)] = string(t.Value)
+		}
+	}
+	for _, t := range c.Tags {
+		cols[t.Key] = t.Value
+	}
+
+	tags := models.NewTags(cols)
+	fields := models.Fields{
+		"_message":          expandMessage(c.StatusMessageTemplate, cols, field, v),
+		"_source_timestamp": p.Time().UnixNano(),
+	}
+	if field != "" {
+		fields[field] = v
+	}
+	return models.NewPoint(statusesMeasurement, tags, fields, now)
+}
+
+// messageRefs matches the references to the columns of the status in a
+// message template, such as ${r._level} or ${string(v: r.usage)}.
+var messageRefs = regexp.MustCompile(`\$\{\s*(?:string\(v:\s*)?r\.(\w+)\s*\)?\s*\}`)
+
+// expandMessage replaces the references to the columns of the status in the
+// message template, the way the task of the check would. References to
+// other columns, and other expressions, are left as they are.
+func expandMessage(tmpl string, cols map[string]string, field string, v float64) string {
+	return messageRefs.ReplaceAllStringFunc(tmpl, func(ref string) string {
+		col := messageRefs.FindStringSubmatch(ref)[1]
+		if col == field || col == "_value" {
+			return fmt.Sprint(v)
+		}
+		if s, ok := cols[col]; ok {
+			return s
+		}
+		return ref
+	})
+}
+
+// PointsWriter writes points with another writer, and evaluates the
+// streaming checks against them.
+type PointsWriter struct {
+	w         storage.PointsWriter
+	evaluator *Evaluator
+}
+
+// NewPointsWriter returns a PointsWriter writing with w and evaluating the
+// streaming checks of e.
+func NewPointsWriter(w storage.PointsWriter, e *Evaluator) *PointsWriter {
+	return &PointsWriter{
+		w:         w,
+		evaluator: e,
+	}
+}
+
+// WritePoints writes points, then evaluates the streaming checks against
+// them. Failing to evaluate the checks does not fail the write.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if err := w.w.WritePoints(ctx, points); err != nil {
+		return err
+	}
+	if err := w.evaluator.Evaluate(ctx, points); err != nil {
+		w.evaluator.log.Info("Failed to evaluate streaming checks", zap.Error(err))
+	}
+	return nil
+}
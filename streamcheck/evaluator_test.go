@@ -0,0 +1,87 @@
+package streamcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/alerthistory"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	const orgID, bucketID, monitoringID = influxdb.ID(10), influxdb.ID(20), influxdb.ID(30)
+
+	c := &check.Threshold{
+		Base: check.Base{
+			ID:                    1,
+			Name:                  "CPU",
+			OrgID:                 orgID,
+			StatusMessageTemplate: "${r._check_name} is ${r._level}: ${string(v: r.usage)} on ${r.host}",
+		},
+		Thresholds: []check.ThresholdConfig{
+			&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Warn}, Value: 70},
+			&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 90},
+		},
+		Streaming: true,
+	}
+	pw := &mock.PointsWriter{}
+	e := NewEvaluator(zaptest.NewLogger(t), nil, nil, nil, nil, pw, DefaultRefreshInterval)
+	e.byBucket = map[influxdb.ID][]*streamingCheck{
+		bucketID: {{
+			check:        c,
+			monitoringID: monitoringID,
+			filters:      map[string][]string{models.MeasurementTagKey: {"cpu"}},
+		}},
+	}
+
+	w := NewPointsWriter(&mock.PointsWriter{}, e)
+	write := func(measurement, host string, v float64) {
+		t.Helper()
+		p := models.MustNewPoint(measurement, models.NewTags(map[string]string{"host": host}), models.Fields{"usage": v}, time.Unix(1, 0))
+		points, err := tsdb.ExplodePoints(orgID, bucketID, []models.Point{p})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WritePoints(ctx, points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first value of a series writes a status only if it meets a
+	// threshold, and later values only if the level changes.
+	write("cpu", "a", 50)
+	write("cpu", "a", 80)
+	write("cpu", "a", 85)
+	write("cpu", "b", 95)
+	write("mem", "a", 99)
+	write("cpu", "a", 10)
+
+	statuses := alerthistory.Statuses(pw.Points)
+	exp := []struct {
+		host, level, message string
+	}{
+		{"a", "warn", "CPU is warn: 80 on a"},
+		{"b", "crit", "CPU is crit: 95 on b"},
+		{"a", "ok", "CPU is ok: 10 on a"},
+	}
+	if len(statuses) != len(exp) {
+		t.Fatalf("expected %d statuses, got %+v", len(exp), statuses)
+	}
+	for i, st := range statuses {
+		if st.OrgID != orgID || st.CheckID != c.ID || st.Tags["host"] != exp[i].host || st.Level != exp[i].level || st.Message != exp[i].message {
+			t.Errorf("expected status %d to be %+v, got %+v", i, exp[i], st)
+		}
+	}
+	for _, p := range pw.Points {
+		if _, id := tsdb.DecodeNameSlice(p.Name()); id != monitoringID {
+			t.Fatalf("expected statuses to be written to the monitoring bucket, got %s", id)
+		}
+	}
+}
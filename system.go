@@ -0,0 +1,51 @@
+package influxdb
+
+import "context"
+
+// SystemInfoService returns the build and runtime environment of a server,
+// so that support may triage an instance without access to its host.
+type SystemInfoService interface {
+	FindSystemInfo(ctx context.Context) (*SystemInfo, error)
+}
+
+// SystemInfo is the build and runtime environment of a server.
+type SystemInfo struct {
+	Build       BuildInfo `json:"build"`
+	GoVersion   string    `json:"goVersion"`
+	FluxVersion string    `json:"fluxVersion"`
+	// StorageFormats are the versions of the file formats of the storage
+	// engine by the name of the format.
+	StorageFormats map[string]int `json:"storageFormats"`
+	Runtime        SystemRuntime  `json:"runtime"`
+	// Cgroup are the limits of the cgroup of the server, if it runs in one.
+	Cgroup *SystemCgroupLimits `json:"cgroup,omitempty"`
+	// FeatureFlags are the values of the feature flags for the request.
+	FeatureFlags map[string]interface{} `json:"featureFlags"`
+	Dependencies []SystemDependency     `json:"dependencies"`
+}
+
+// SystemRuntime is the environment the Go runtime of a server detected.
+type SystemRuntime struct {
+	OS           string `json:"os"`
+	Arch         string `json:"arch"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	NumCPU       int    `json:"numCPU"`
+	NumGoroutine int    `json:"numGoroutine"`
+}
+
+// SystemCgroupLimits are the limits of the cgroup of a server. A limit of
+// zero is unlimited.
+type SystemCgroupLimits struct {
+	Version int `json:"version"`
+	// CPUs is the number of CPUs the cgroup may use, which may be fractional.
+	CPUs        float64 `json:"cpus"`
+	MemoryBytes int64   `json:"memoryBytes"`
+}
+
+// SystemDependency is a module the server was built with.
+type SystemDependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Replace is the module replacing the dependency, if any.
+	Replace string `json:"replace,omitempty"`
+}
@@ -0,0 +1,90 @@
+// Package systeminfo reports the build and runtime environment of the
+// server: the versions it was built with, the formats of its storage, the
+// limits it runs under and the feature flags it runs with.
+package systeminfo
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/pkg/cgroup"
+	"github.com/influxdata/influxdb/v2/tsdb/seriesfile"
+	"github.com/influxdata/influxdb/v2/tsdb/tsi1"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+const fluxModule = "github.com/influxdata/flux"
+
+// Service is an influxdb.SystemInfoService of the running process.
+type Service struct {
+	log *zap.Logger
+}
+
+var _ influxdb.SystemInfoService = (*Service)(nil)
+
+// NewService constructs a new Service.
+func NewService(log *zap.Logger) *Service {
+	return &Service{log: log}
+}
+
+// FindSystemInfo returns the environment of the process. The feature flags
+// are those computed for ctx.
+func (s *Service) FindSystemInfo(ctx context.Context) (*influxdb.SystemInfo, error) {
+	info := &influxdb.SystemInfo{
+		Build:     influxdb.GetBuildInfo(),
+		GoVersion: runtime.Version(),
+		StorageFormats: map[string]int{
+			"tsm":           int(tsm1.Version),
+			"tsi":           tsi1.IndexFileVersion,
+			"seriesIndex":   seriesfile.SeriesIndexVersion,
+			"seriesSegment": seriesfile.SeriesSegmentVersion,
+		},
+		Runtime: influxdb.SystemRuntime{
+			OS:           runtime.GOOS,
+			Arch:         runtime.GOARCH,
+			GOMAXPROCS:   runtime.GOMAXPROCS(0),
+			NumCPU:       runtime.NumCPU(),
+			NumGoroutine: runtime.NumGoroutine(),
+		},
+		FeatureFlags: feature.FlagsFromContext(ctx),
+		Dependencies: []influxdb.SystemDependency{},
+	}
+	if info.FeatureFlags == nil {
+		info.FeatureFlags = map[string]interface{}{}
+	}
+
+	// The limits of the cgroup are informational, so failing to read them
+	// does not fail the request.
+	limits, err := cgroup.Detect()
+	if err != nil {
+		s.log.Info("Failed to detect cgroup limits", zap.Error(err))
+	} else if limits != nil {
+		info.Cgroup = &influxdb.SystemCgroupLimits{
+			Version:     limits.Version,
+			CPUs:        limits.CPUs,
+			MemoryBytes: limits.MemoryBytes,
+		}
+	}
+
+	// Binaries built outside of module mode have no dependencies recorded.
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, m := range bi.Deps {
+			d := influxdb.SystemDependency{
+				Path:    m.Path,
+				Version: m.Version,
+			}
+			if m.Replace != nil {
+				d.Replace = m.Replace.Path + "@" + m.Replace.Version
+			}
+			if m.Path == fluxModule {
+				info.FluxVersion = m.Version
+			}
+			info.Dependencies = append(info.Dependencies, d)
+		}
+	}
+	return info, nil
+}
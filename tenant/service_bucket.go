@@ -186,7 +186,8 @@ func (s *Service) UpdateBucket(ctx context.Context, id influxdb.ID, upd influxdb
 
 // DeleteBucket removes a bucket by ID.
 func (s *Service) DeleteBucket(ctx context.Context, id influxdb.ID) error {
-	return s.store.Update(ctx, func(tx kv.Tx) error {
+	var committed []func()
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
 		bucket, err := s.store.GetBucket(ctx, tx, id)
 		if err != nil {
 			return err
@@ -199,6 +200,17 @@ func (s *Service) DeleteBucket(ctx context.Context, id influxdb.ID) error {
 		if err := s.store.DeleteBucket(ctx, tx, id); err != nil {
 			return err
 		}
+		if committed, err = s.store.bucketDeleted(ctx, tx, bucket); err != nil {
+			return err
+		}
 		return s.removeResourceRelations(ctx, tx, id)
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range committed {
+		fn()
+	}
+	return nil
 }
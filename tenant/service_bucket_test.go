@@ -2,6 +2,8 @@ package tenant_test
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
@@ -55,3 +57,68 @@ func initBucketService(s kv.Store, f influxdbtesting.BucketFields, t *testing.T)
 		}
 	}
 }
+
+func TestBucketService_DeleteHooks(t *testing.T) {
+	ctx := context.Background()
+	s, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+	storage, err := tenant.NewStore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var deleted, committed []influxdb.ID
+	fail := false
+	storage.AddBucketDeleteHook(func(ctx context.Context, tx kv.Tx, b *influxdb.Bucket) (func(), error) {
+		if fail {
+			return nil, errors.New("hook failed")
+		}
+		if b.Type == influxdb.BucketTypeSystem {
+			// The system buckets of the organization are deleted with it.
+			return nil, nil
+		}
+		deleted = append(deleted, b.ID)
+		return func() { committed = append(committed, b.ID) }, nil
+	})
+	svc := tenant.NewService(storage)
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	b1 := &influxdb.Bucket{OrgID: org.ID, Name: "b1"}
+	b2 := &influxdb.Bucket{OrgID: org.ID, Name: "b2"}
+	for _, b := range []*influxdb.Bucket{b1, b2} {
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A failing hook rolls the delete back.
+	fail = true
+	if err := svc.DeleteBucket(ctx, b1.ID); err == nil {
+		t.Fatal("expected the failing hook to fail the delete")
+	}
+	if _, err := svc.FindBucketByID(ctx, b1.ID); err != nil {
+		t.Fatalf("expected the bucket to be kept: %v", err)
+	}
+	fail = false
+
+	if err := svc.DeleteBucket(ctx, b1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []influxdb.ID{b1.ID, b2.ID}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("unexpected buckets deleted by the hook: got %v want %v", deleted, want)
+	}
+	if !reflect.DeepEqual(committed, want) {
+		t.Errorf("unexpected bucket deletes committed: got %v want %v", committed, want)
+	}
+}
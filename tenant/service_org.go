@@ -177,6 +177,7 @@ func (s *Service) UpdateOrganization(ctx context.Context, id influxdb.ID, upd in
 
 // DeleteOrganization removes a organization by ID and its dependent resources.
 func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error {
+	var committed []func()
 	err := s.store.Update(ctx, func(tx kv.Tx) error {
 		// clean up the buckets for this organization
 		filter := BucketFilter{
@@ -192,6 +193,11 @@ func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error
 					return err
 				}
 			}
+			fns, err := s.store.bucketDeleted(ctx, tx, b)
+			if err != nil {
+				return err
+			}
+			committed = append(committed, fns...)
 			if err := s.removeResourceRelations(ctx, tx, b.ID); err != nil {
 				return err
 			}
@@ -203,5 +209,12 @@ func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error
 
 		return s.store.DeleteOrg(ctx, tx, id)
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range committed {
+		fn()
+	}
+	return nil
 }
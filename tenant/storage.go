@@ -17,6 +17,8 @@ type Store struct {
 	kvStore        kv.Store
 	IDGen          influxdb.IDGenerator
 	urmByUserIndex *kv.Index
+
+	bucketDeleteHooks []kv.BucketDeleteHook
 }
 
 func NewStore(kvStore kv.Store) (*Store, error) {
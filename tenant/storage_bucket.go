@@ -294,6 +294,42 @@ func (s *Store) CreateBucket(ctx context.Context, tx kv.Tx, bucket *influxdb.Buc
 		return err
 	}
 
+	if err := bucket.DuplicatePolicy.Valid(); err != nil {
+		return err
+	}
+
+	if err := bucket.WriteWindow.Valid(); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidDedupeWindow(bucket.DedupeWindow); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidJSONWriteRules(bucket.JSONWriteRules); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidAggregationHints(bucket.AggregationHints); err != nil {
+		return err
+	}
+
+	if err := bucket.CompressionCodec.Valid(); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidSoftTTLs(bucket.SoftTTLs); err != nil {
+		return err
+	}
+
+	if err := influxdb.ValidMaskingRules(bucket.MaskingRules); err != nil {
+		return err
+	}
+
+	if err := bucket.WriteConsistency.Valid(); err != nil {
+		return err
+	}
+
 	bucket.SetCreatedAt(time.Now())
 	bucket.SetUpdatedAt(time.Now())
 	idx, err := tx.Bucket(bucketIndex)
@@ -381,6 +417,77 @@ func (s *Store) UpdateBucket(ctx context.Context, tx kv.Tx, id influxdb.ID, upd
 		bucket.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.DuplicatePolicy != nil {
+		if err := upd.DuplicatePolicy.Valid(); err != nil {
+			return nil, err
+		}
+		bucket.DuplicatePolicy = *upd.DuplicatePolicy
+	}
+
+	if upd.WriteWindow != nil {
+		if err := upd.WriteWindow.Valid(); err != nil {
+			return nil, err
+		}
+		// An empty write window removes the bounds from the bucket.
+		bucket.WriteWindow = upd.WriteWindow
+		if *upd.WriteWindow == (influxdb.WriteWindow{}) {
+			bucket.WriteWindow = nil
+		}
+	}
+
+	if upd.DedupeWindow != nil {
+		if err := influxdb.ValidDedupeWindow(*upd.DedupeWindow); err != nil {
+			return nil, err
+		}
+		bucket.DedupeWindow = *upd.DedupeWindow
+	}
+
+	if upd.JSONWriteRules != nil {
+		if err := influxdb.ValidJSONWriteRules(*upd.JSONWriteRules); err != nil {
+			return nil, err
+		}
+		bucket.JSONWriteRules = *upd.JSONWriteRules
+	}
+
+	if upd.AggregationHints != nil {
+		if err := influxdb.ValidAggregationHints(*upd.AggregationHints); err != nil {
+			return nil, err
+		}
+		bucket.AggregationHints = *upd.AggregationHints
+	}
+
+	if upd.CompressionCodec != nil {
+		if err := upd.CompressionCodec.Valid(); err != nil {
+			return nil, err
+		}
+		bucket.CompressionCodec = *upd.CompressionCodec
+	}
+
+	if upd.SoftTTLs != nil {
+		if err := influxdb.ValidSoftTTLs(*upd.SoftTTLs); err != nil {
+			return nil, err
+		}
+		bucket.SoftTTLs = *upd.SoftTTLs
+	}
+
+	if upd.LastValueCache != nil {
+		bucket.LastValueCache = *upd.LastValueCache
+	}
+
+	if upd.MaskingRules != nil {
+		if err := influxdb.ValidMaskingRules(*upd.MaskingRules); err != nil {
+			return nil, err
+		}
+		bucket.MaskingRules = *upd.MaskingRules
+	}
+
+	if upd.WriteConsistency != nil {
+		if err := upd.WriteConsistency.Valid(); err != nil {
+			return nil, err
+		}
+		bucket.WriteConsistency = *upd.WriteConsistency
+	}
+
 	v, err := marshalBucket(bucket)
 	if err != nil {
 		return nil, err
@@ -432,3 +539,26 @@ func (s *Store) DeleteBucket(ctx context.Context, tx kv.Tx, id influxdb.ID) erro
 
 	return nil
 }
+
+// AddBucketDeleteHook adds a hook called in the transaction deleting a
+// bucket, to delete the resources of the store referring to the bucket along
+// with it. Hooks must be added before the store is used.
+func (s *Store) AddBucketDeleteHook(hook kv.BucketDeleteHook) {
+	s.bucketDeleteHooks = append(s.bucketDeleteHooks, hook)
+}
+
+// bucketDeleted calls the bucket delete hooks of b in tx, and returns their
+// functions to call once tx commits.
+func (s *Store) bucketDeleted(ctx context.Context, tx kv.Tx, b *influxdb.Bucket) ([]func(), error) {
+	var committed []func()
+	for _, hook := range s.bucketDeleteHooks {
+		fn, err := hook(ctx, tx, b)
+		if err != nil {
+			return nil, err
+		}
+		if fn != nil {
+			committed = append(committed, fn)
+		}
+	}
+	return committed, nil
+}
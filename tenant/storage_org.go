@@ -237,6 +237,10 @@ func (s *Store) UpdateOrg(ctx context.Context, tx kv.Tx, id influxdb.ID, upd inf
 		u.Description = *upd.Description
 	}
 
+	if upd.DefaultBucketID != nil {
+		u.DefaultBucketID = upd.DefaultBucketID
+	}
+
 	v, err := marshalOrg(u)
 	if err != nil {
 		return nil, err
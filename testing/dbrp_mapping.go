@@ -409,6 +409,177 @@ func FindDBRPMappings(
 				},
 			},
 		},
+		{
+			name: "find dbrpMappings by bucket IDs",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster1",
+						Database:        "database1",
+						RetentionPolicy: "retention_policy1",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+					{
+						Cluster:         "cluster2",
+						Database:        "database2",
+						RetentionPolicy: "retention_policy2",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket2ID),
+					},
+					{
+						Cluster:         "cluster3",
+						Database:        "database3",
+						RetentionPolicy: "retention_policy3",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucketAID),
+					},
+				},
+			},
+			args: args{
+				filter: platform.DBRPMappingFilter{
+					BucketIDs: []platform.ID{MustIDBase16(dbrpBucket1ID), MustIDBase16(dbrpBucketAID)},
+				},
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster1",
+						Database:        "database1",
+						RetentionPolicy: "retention_policy1",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+					{
+						Cluster:         "cluster3",
+						Database:        "database3",
+						RetentionPolicy: "retention_policy3",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucketAID),
+					},
+				},
+			},
+		},
+		{
+			name: "find dbrpMappings by database prefix",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster",
+						Database:        "tenant_a",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+					{
+						Cluster:         "cluster",
+						Database:        "tenant_b",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket2ID),
+					},
+					{
+						Cluster:         "cluster",
+						Database:        "other_tenant_a",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucketAID),
+					},
+				},
+			},
+			args: args{
+				filter: platform.DBRPMappingFilter{
+					DatabasePrefix: strPtr("tenant_"),
+				},
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster",
+						Database:        "tenant_a",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+					{
+						Cluster:         "cluster",
+						Database:        "tenant_b",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket2ID),
+					},
+				},
+			},
+		},
+		{
+			name: "find dbrpMappings by database pattern",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster",
+						Database:        "tenant_a",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+					{
+						Cluster:         "cluster",
+						Database:        "tenant_b",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket2ID),
+					},
+					{
+						Cluster:         "cluster",
+						Database:        "other_tenant_a",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucketAID),
+					},
+				},
+			},
+			args: args{
+				filter: platform.DBRPMappingFilter{
+					DatabasePattern: strPtr("*tenant_[a-z]"),
+					DatabasePrefix:  strPtr("other_"),
+				},
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster",
+						Database:        "other_tenant_a",
+						RetentionPolicy: "autogen",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucketAID),
+					},
+				},
+			},
+		},
+		{
+			name: "find dbrpMappings by a key which does not exist",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster",
+						Database:        "database",
+						RetentionPolicy: "retention_policyA",
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucketAID),
+					},
+				},
+			},
+			args: args{
+				filter: platform.DBRPMappingFilter{
+					Cluster:         strPtr("cluster"),
+					Database:        strPtr("database"),
+					RetentionPolicy: strPtr("retention_policyX"),
+				},
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -417,7 +588,7 @@ func FindDBRPMappings(
 			defer done()
 			ctx := context.Background()
 
-			dbrpMappings, _, err := s.FindMany(ctx, tt.args.filter)
+			dbrpMappings, n, err := s.FindMany(ctx, tt.args.filter)
 			if (err != nil) != (tt.wants.err != nil) {
 				t.Fatalf("expected errors to be equal '%v' got '%v'", tt.wants.err, err)
 			}
@@ -431,6 +602,9 @@ func FindDBRPMappings(
 			if diff := cmp.Diff(dbrpMappings, tt.wants.dbrpMappings, dbrpMappingCmpOptions...); diff != "" {
 				t.Errorf("dbrpMappings are different -got/+want\ndiff %s", diff)
 			}
+			if n != len(tt.wants.dbrpMappings) {
+				t.Errorf("expected a total of %d dbrpMappings, got %d", len(tt.wants.dbrpMappings), n)
+			}
 		})
 	}
 }
@@ -840,6 +1014,434 @@ func DeleteDBRPMapping(
 	}
 }
 
+// UpsertDBRPMapping testing
+func UpsertDBRPMapping(
+	init func(DBRPMappingFields, *testing.T) (platform.DBRPMappingService, func()),
+	t *testing.T,
+) {
+	type args struct {
+		dbrpMapping *platform.DBRPMapping
+	}
+	type wants struct {
+		err          error
+		dbrpMappings []*platform.DBRPMapping
+	}
+
+	existing := &platform.DBRPMapping{
+		Cluster:         "cluster1",
+		Database:        "database1",
+		RetentionPolicy: "retention_policy1",
+		Default:         false,
+		OrganizationID:  MustIDBase16(dbrpOrg1ID),
+		BucketID:        MustIDBase16(dbrpBucket1ID),
+	}
+
+	tests := []struct {
+		name   string
+		fields DBRPMappingFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "upsert creates a new dbrpMapping",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{existing},
+			},
+			args: args{
+				dbrpMapping: &platform.DBRPMapping{
+					Cluster:         "cluster1",
+					Database:        "database2",
+					RetentionPolicy: "retention_policy1",
+					Default:         true,
+					OrganizationID:  MustIDBase16(dbrpOrg1ID),
+					BucketID:        MustIDBase16(dbrpBucket2ID),
+				},
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{
+					existing,
+					{
+						Cluster:         "cluster1",
+						Database:        "database2",
+						RetentionPolicy: "retention_policy1",
+						Default:         true,
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket2ID),
+					},
+				},
+			},
+		},
+		{
+			name: "upsert replaces the dbrpMapping of the same organization",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{existing},
+			},
+			args: args{
+				dbrpMapping: &platform.DBRPMapping{
+					Cluster:         "cluster1",
+					Database:        "database1",
+					RetentionPolicy: "retention_policy1",
+					Default:         true,
+					OrganizationID:  MustIDBase16(dbrpOrg1ID),
+					BucketID:        MustIDBase16(dbrpBucket2ID),
+				},
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{{
+					Cluster:         "cluster1",
+					Database:        "database1",
+					RetentionPolicy: "retention_policy1",
+					Default:         true,
+					OrganizationID:  MustIDBase16(dbrpOrg1ID),
+					BucketID:        MustIDBase16(dbrpBucket2ID),
+				}},
+			},
+		},
+		{
+			name: "upsert of a default dbrpMapping clears the default of the others of its database",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster1",
+						Database:        "database1",
+						RetentionPolicy: "retention_policy1",
+						Default:         true,
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+					{
+						Cluster:         "cluster1",
+						Database:        "database2",
+						RetentionPolicy: "retention_policy1",
+						Default:         true,
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+				},
+			},
+			args: args{
+				dbrpMapping: &platform.DBRPMapping{
+					Cluster:         "cluster1",
+					Database:        "database1",
+					RetentionPolicy: "retention_policy2",
+					Default:         true,
+					OrganizationID:  MustIDBase16(dbrpOrg1ID),
+					BucketID:        MustIDBase16(dbrpBucket2ID),
+				},
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{
+					{
+						Cluster:         "cluster1",
+						Database:        "database1",
+						RetentionPolicy: "retention_policy1",
+						Default:         false,
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+					{
+						Cluster:         "cluster1",
+						Database:        "database1",
+						RetentionPolicy: "retention_policy2",
+						Default:         true,
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket2ID),
+					},
+					{
+						Cluster:         "cluster1",
+						Database:        "database2",
+						RetentionPolicy: "retention_policy1",
+						Default:         true,
+						OrganizationID:  MustIDBase16(dbrpOrg1ID),
+						BucketID:        MustIDBase16(dbrpBucket1ID),
+					},
+				},
+			},
+		},
+		{
+			name: "upsert does not replace the dbrpMapping of another organization",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{existing},
+			},
+			args: args{
+				dbrpMapping: &platform.DBRPMapping{
+					Cluster:         "cluster1",
+					Database:        "database1",
+					RetentionPolicy: "retention_policy1",
+					Default:         false,
+					OrganizationID:  MustIDBase16(dbrpOrg2ID),
+					BucketID:        MustIDBase16(dbrpBucket2ID),
+				},
+			},
+			wants: wants{
+				err:          errors.New("dbrp mapping already exists in another organization"),
+				dbrpMappings: []*platform.DBRPMapping{existing},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+			err := s.Upsert(ctx, tt.args.dbrpMapping)
+			if (err != nil) != (tt.wants.err != nil) {
+				t.Fatalf("expected error '%v' got '%v'", tt.wants.err, err)
+			}
+
+			if err != nil && tt.wants.err != nil {
+				if err.Error() != tt.wants.err.Error() {
+					t.Fatalf("expected error messages to match '%v' got '%v'", tt.wants.err, err.Error())
+				}
+			}
+
+			filter := platform.DBRPMappingFilter{}
+			dbrpMappings, _, err := s.FindMany(ctx, filter)
+			if err != nil {
+				t.Fatalf("failed to retrieve dbrpMappings: %v", err)
+			}
+			if diff := cmp.Diff(dbrpMappings, tt.wants.dbrpMappings, dbrpMappingCmpOptions...); diff != "" {
+				t.Errorf("dbrpMappings are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// SetDefaultDBRPMapping testing
+func SetDefaultDBRPMapping(
+	init func(DBRPMappingFields, *testing.T) (platform.DBRPMappingService, func()),
+	t *testing.T,
+) {
+	type args struct {
+		Cluster,
+		Database,
+		RetentionPolicy string
+	}
+	type wants struct {
+		err          error
+		dbrpMapping  *platform.DBRPMapping
+		dbrpMappings []*platform.DBRPMapping
+	}
+
+	mappings := func(defaultRP string) []*platform.DBRPMapping {
+		return []*platform.DBRPMapping{
+			{
+				Cluster:         "cluster1",
+				Database:        "database1",
+				RetentionPolicy: "retention_policy1",
+				Default:         defaultRP == "retention_policy1",
+				OrganizationID:  MustIDBase16(dbrpOrg1ID),
+				BucketID:        MustIDBase16(dbrpBucket1ID),
+			},
+			{
+				Cluster:         "cluster1",
+				Database:        "database1",
+				RetentionPolicy: "retention_policy2",
+				Default:         defaultRP == "retention_policy2",
+				OrganizationID:  MustIDBase16(dbrpOrg1ID),
+				BucketID:        MustIDBase16(dbrpBucket2ID),
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		fields DBRPMappingFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "set default clears the default of the other dbrpMappings of the database",
+			fields: DBRPMappingFields{
+				DBRPMappings: mappings("retention_policy1"),
+			},
+			args: args{
+				Cluster:         "cluster1",
+				Database:        "database1",
+				RetentionPolicy: "retention_policy2",
+			},
+			wants: wants{
+				dbrpMapping:  mappings("retention_policy2")[1],
+				dbrpMappings: mappings("retention_policy2"),
+			},
+		},
+		{
+			name: "set default of the default dbrpMapping",
+			fields: DBRPMappingFields{
+				DBRPMappings: mappings("retention_policy1"),
+			},
+			args: args{
+				Cluster:         "cluster1",
+				Database:        "database1",
+				RetentionPolicy: "retention_policy1",
+			},
+			wants: wants{
+				dbrpMapping:  mappings("retention_policy1")[0],
+				dbrpMappings: mappings("retention_policy1"),
+			},
+		},
+		{
+			name: "set default of a dbrpMapping that does not exist",
+			fields: DBRPMappingFields{
+				DBRPMappings: mappings("retention_policy1"),
+			},
+			args: args{
+				Cluster:         "cluster1",
+				Database:        "database1",
+				RetentionPolicy: "retention_policy3",
+			},
+			wants: wants{
+				err:          errors.New("dbrp mapping not found"),
+				dbrpMappings: mappings("retention_policy1"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+			m, err := s.SetDefault(ctx, tt.args.Cluster, tt.args.Database, tt.args.RetentionPolicy)
+			if (err != nil) != (tt.wants.err != nil) {
+				t.Fatalf("expected error '%v' got '%v'", tt.wants.err, err)
+			}
+
+			if err != nil && tt.wants.err != nil {
+				if err.Error() != tt.wants.err.Error() {
+					t.Fatalf("expected error messages to match '%v' got '%v'", tt.wants.err, err.Error())
+				}
+			}
+			if diff := cmp.Diff(m, tt.wants.dbrpMapping, dbrpMappingCmpOptions...); diff != "" {
+				t.Errorf("dbrpMapping is different -got/+want\ndiff %s", diff)
+			}
+
+			dbrpMappings, _, err := s.FindMany(ctx, platform.DBRPMappingFilter{})
+			if err != nil {
+				t.Fatalf("failed to retrieve dbrpMappings: %v", err)
+			}
+			if diff := cmp.Diff(dbrpMappings, tt.wants.dbrpMappings, dbrpMappingCmpOptions...); diff != "" {
+				t.Errorf("dbrpMappings are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// ReplaceDBRPMapping testing
+func ReplaceDBRPMapping(
+	init func(DBRPMappingFields, *testing.T) (platform.DBRPMappingService, func()),
+	t *testing.T,
+) {
+	type args struct {
+		dbrpMapping *platform.DBRPMapping
+		revision    string
+	}
+	type wants struct {
+		err          error
+		dbrpMappings []*platform.DBRPMapping
+	}
+
+	mapping := func(rp string, def bool, bucketID string) *platform.DBRPMapping {
+		return &platform.DBRPMapping{
+			Cluster:         "cluster1",
+			Database:        "database1",
+			RetentionPolicy: rp,
+			Default:         def,
+			OrganizationID:  MustIDBase16(dbrpOrg1ID),
+			BucketID:        MustIDBase16(bucketID),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		fields DBRPMappingFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "replace a dbrpMapping at its revision",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					mapping("retention_policy1", true, dbrpBucket1ID),
+					mapping("retention_policy2", false, dbrpBucket1ID),
+				},
+			},
+			args: args{
+				dbrpMapping: mapping("retention_policy2", true, dbrpBucket2ID),
+				revision:    mapping("retention_policy2", false, dbrpBucket1ID).Revision(),
+			},
+			wants: wants{
+				dbrpMappings: []*platform.DBRPMapping{
+					mapping("retention_policy1", false, dbrpBucket1ID),
+					mapping("retention_policy2", true, dbrpBucket2ID),
+				},
+			},
+		},
+		{
+			name: "replace a dbrpMapping changed since its revision",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					mapping("retention_policy1", true, dbrpBucket2ID),
+				},
+			},
+			args: args{
+				dbrpMapping: mapping("retention_policy1", true, dbrpBucketAID),
+				revision:    mapping("retention_policy1", true, dbrpBucket1ID).Revision(),
+			},
+			wants: wants{
+				err: platform.ErrDBRPMappingChanged,
+				dbrpMappings: []*platform.DBRPMapping{
+					mapping("retention_policy1", true, dbrpBucket2ID),
+				},
+			},
+		},
+		{
+			name: "replace a dbrpMapping that does not exist",
+			fields: DBRPMappingFields{
+				DBRPMappings: []*platform.DBRPMapping{
+					mapping("retention_policy1", true, dbrpBucket1ID),
+				},
+			},
+			args: args{
+				dbrpMapping: mapping("retention_policy2", false, dbrpBucket1ID),
+				revision:    mapping("retention_policy2", false, dbrpBucket1ID).Revision(),
+			},
+			wants: wants{
+				err: platform.ErrDBRPMappingChanged,
+				dbrpMappings: []*platform.DBRPMapping{
+					mapping("retention_policy1", true, dbrpBucket1ID),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+			err := s.Replace(ctx, tt.args.dbrpMapping, tt.args.revision)
+			if (err != nil) != (tt.wants.err != nil) {
+				t.Fatalf("expected error '%v' got '%v'", tt.wants.err, err)
+			}
+			if err != nil && tt.wants.err != nil {
+				if platform.ErrorCode(err) != platform.ErrorCode(tt.wants.err) {
+					t.Fatalf("expected error code '%s' got '%s'", platform.ErrorCode(tt.wants.err), platform.ErrorCode(err))
+				}
+			}
+
+			dbrpMappings, _, err := s.FindMany(ctx, platform.DBRPMappingFilter{})
+			if err != nil {
+				t.Fatalf("failed to retrieve dbrpMappings: %v", err)
+			}
+			if diff := cmp.Diff(dbrpMappings, tt.wants.dbrpMappings, dbrpMappingCmpOptions...); diff != "" {
+				t.Errorf("dbrpMappings are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -57,10 +57,18 @@ type CursorIterator interface {
 type CursorStats struct {
 	ScannedValues int // number of values scanned
 	ScannedBytes  int // number of uncompressed bytes scanned
+	BlocksDecoded int // number of storage blocks decoded
+	BlocksMerged  int // number of decoded blocks merged with overlapping blocks
+	BlocksSkipped int // number of storage blocks skipped without being decoded
+	ScannedSeries int // number of series scanned
 }
 
 // Add adds other to s and updates s.
 func (s *CursorStats) Add(other CursorStats) {
 	s.ScannedValues += other.ScannedValues
 	s.ScannedBytes += other.ScannedBytes
+	s.BlocksDecoded += other.BlocksDecoded
+	s.BlocksMerged += other.BlocksMerged
+	s.BlocksSkipped += other.BlocksSkipped
+	s.ScannedSeries += other.ScannedSeries
 }
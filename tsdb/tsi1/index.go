@@ -254,6 +254,9 @@ func (i *Index) Open(ctx context.Context) error {
 		p.nosync = i.disableFsync
 		p.logbufferSize = i.logfileBufferSize
 		p.logger = i.logger.With(zap.String("tsi1_partition", fmt.Sprint(j+1)))
+		if i.disableCompactions {
+			p.compactionsDisabled++
+		}
 
 		// Each of the trackers needs to be given slightly different default
 		// labels to ensure the correct partition ids are set as labels.
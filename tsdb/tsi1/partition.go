@@ -283,8 +283,10 @@ func (p *Partition) Open() (err error) {
 	// Mark opened.
 	p.res.Open()
 
-	// Send a compaction request on start up.
-	p.compact()
+	// Send a compaction request on start up, unless compactions are disabled.
+	if p.compactionsDisabled == 0 {
+		p.compact()
+	}
 
 	return nil
 }
@@ -42,7 +42,7 @@ type ReportCommand struct {
 
 	TopN          int
 	ByMeasurement bool
-	byTagKey      bool
+	ByTagKey      bool
 
 	start time.Time
 }
@@ -54,7 +54,7 @@ func NewReportCommand() *ReportCommand {
 		byBucketMeasurement: make(map[influxdb.ID]map[string]*cardinality),
 		orgToBucket:         make(map[influxdb.ID][]influxdb.ID),
 		TopN:                0,
-		byTagKey:            false,
+		ByTagKey:            false,
 	}
 }
 
@@ -64,6 +64,7 @@ type Summary struct {
 	OrgCardinality               map[influxdb.ID]int64
 	BucketByOrgCardinality       map[influxdb.ID]map[influxdb.ID]int64
 	BucketMeasurementCardinality map[influxdb.ID]map[string]int64
+	BucketTagKeyCardinality      map[influxdb.ID][]influxdb.TagKeyCardinality
 }
 
 func newSummary() *Summary {
@@ -71,6 +72,7 @@ func newSummary() *Summary {
 		OrgCardinality:               make(map[influxdb.ID]int64),
 		BucketByOrgCardinality:       make(map[influxdb.ID]map[influxdb.ID]int64),
 		BucketMeasurementCardinality: make(map[influxdb.ID]map[string]int64),
+		BucketTagKeyCardinality:      make(map[influxdb.ID][]influxdb.TagKeyCardinality),
 	}
 }
 
@@ -278,6 +280,19 @@ func (report *ReportCommand) calculateOrgBucketCardinality() (*Summary, error) {
 		}
 	}
 
+	if report.ByTagKey {
+		for orgID, bucketMap := range report.byOrgBucket {
+			for bucketID := range bucketMap {
+				name := tsdb.EncodeNameSlice(orgID, bucketID)
+				_, keys, err := report.indexFile.TagKeyCardinalities(context.Background(), name, report.TopN)
+				if err != nil {
+					return nil, err
+				}
+				summary.BucketTagKeyCardinality[bucketID] = keys
+			}
+		}
+	}
+
 	return summary, nil
 }
 
@@ -309,6 +324,16 @@ func (report *ReportCommand) printCardinalitySummary(summary *Summary) {
 					fmt.Fprintf(tw, "\t\t_m=%s\t%d\n", measResult.id, measResult.card)
 				}
 			}
+
+			if report.ByTagKey {
+				bucketID, _ := influxdb.IDFromString(bucketResult.id)
+				for _, key := range summary.BucketTagKeyCardinality[*bucketID] {
+					fmt.Fprintf(tw, "\t\t%s\t%d values\t%d series\n", key.Key, key.ValueN, key.SeriesN)
+					for _, value := range key.Values {
+						fmt.Fprintf(tw, "\t\t\t%s=%s\t%d\n", key.Key, value.Value, value.SeriesN)
+					}
+				}
+			}
 		}
 		if i == len(sortedOrgs)-1 {
 			fmt.Fprintln(tw, "===============")
@@ -0,0 +1,180 @@
+package tsi1
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// TagKeyCardinalities returns the number of series of the measurement name,
+// and the series cardinality of its tag keys and values. The keys with the
+// most values come first, and the values with the most series. At most limit
+// keys, and limit values of each key, are returned if limit is greater
+// than 0.
+//
+// The series of the measurement are all read, so this is expensive for
+// measurements with many series.
+func (i *Index) TagKeyCardinalities(ctx context.Context, name []byte, limit int) (int64, []influxdb.TagKeyCardinality, error) {
+	itr, err := i.MeasurementSeriesIDIterator(name)
+	if err != nil {
+		return 0, nil, err
+	} else if itr == nil {
+		return 0, nil, nil
+	}
+	defer itr.Close()
+	return i.tagKeyCardinalities(ctx, itr, limit)
+}
+
+// MeasurementTagKeyCardinalities is TagKeyCardinalities for the series of
+// the measurement name whose _measurement tag is measurement.
+func (i *Index) MeasurementTagKeyCardinalities(ctx context.Context, name []byte, measurement string, limit int) (int64, []influxdb.TagKeyCardinality, error) {
+	itr, err := i.TagValueSeriesIDIterator(name, models.MeasurementTagKeyBytes, []byte(measurement))
+	if err != nil {
+		return 0, nil, err
+	} else if itr == nil {
+		return 0, nil, nil
+	}
+	defer itr.Close()
+	return i.tagKeyCardinalities(ctx, itr, limit)
+}
+
+// MeasurementCardinalities returns the number of series of each value of the
+// _measurement tag of the measurement name. Only the postings of the values
+// are read, not the series.
+func (i *Index) MeasurementCardinalities(ctx context.Context, name []byte) (map[string]int64, error) {
+	vitr, err := i.TagValueIterator(name, models.MeasurementTagKeyBytes)
+	if err != nil {
+		return nil, err
+	} else if vitr == nil {
+		return nil, nil
+	}
+	defer vitr.Close()
+
+	cards := make(map[string]int64)
+	for {
+		value, err := vitr.Next()
+		if err != nil {
+			return nil, err
+		} else if value == nil {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		itr, err := i.TagValueSeriesIDIterator(name, models.MeasurementTagKeyBytes, value)
+		if err != nil {
+			return nil, err
+		} else if itr == nil {
+			continue
+		}
+		var n int64
+		for {
+			e, err := itr.Next()
+			if err != nil {
+				itr.Close()
+				return nil, err
+			} else if e.SeriesID.ID == 0 {
+				break
+			}
+			n++
+		}
+		itr.Close()
+		if n > 0 {
+			cards[string(value)] = n
+		}
+	}
+	return cards, nil
+}
+
+// tagKeyCardinalities returns the number of series of itr, and the series
+// cardinality of their tag keys and values.
+func (i *Index) tagKeyCardinalities(ctx context.Context, itr tsdb.SeriesIDIterator, limit int) (int64, []influxdb.TagKeyCardinality, error) {
+	var seriesN int64
+	keys := make(map[string]*tagKeyCardinality)
+	for {
+		e, err := itr.Next()
+		if err != nil {
+			return 0, nil, err
+		} else if e.SeriesID.ID == 0 {
+			break
+		}
+
+		if seriesN%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, nil, err
+			}
+		}
+
+		_, tags := i.sfile.Series(e.SeriesID)
+		if len(tags) == 0 {
+			continue
+		}
+		seriesN++
+
+		for _, t := range tags {
+			key := tagKeyName(t.Key)
+			k, ok := keys[key]
+			if !ok {
+				k = &tagKeyCardinality{values: make(map[string]int64)}
+				keys[key] = k
+			}
+			k.seriesN++
+			k.values[string(t.Value)]++
+		}
+	}
+
+	cards := make([]influxdb.TagKeyCardinality, 0, len(keys))
+	for key, k := range keys {
+		values := make([]influxdb.TagValueCardinality, 0, len(k.values))
+		for v, n := range k.values {
+			values = append(values, influxdb.TagValueCardinality{Value: v, SeriesN: n})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].SeriesN != values[j].SeriesN {
+				return values[i].SeriesN > values[j].SeriesN
+			}
+			return values[i].Value < values[j].Value
+		})
+		if limit > 0 && len(values) > limit {
+			values = values[:limit]
+		}
+
+		cards = append(cards, influxdb.TagKeyCardinality{
+			Key:     key,
+			SeriesN: k.seriesN,
+			ValueN:  int64(len(k.values)),
+			Values:  values,
+		})
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].ValueN != cards[j].ValueN {
+			return cards[i].ValueN > cards[j].ValueN
+		}
+		return cards[i].Key < cards[j].Key
+	})
+	if limit > 0 && len(cards) > limit {
+		cards = cards[:limit]
+	}
+	return seriesN, cards, nil
+}
+
+type tagKeyCardinality struct {
+	seriesN int64
+	values  map[string]int64
+}
+
+// tagKeyName returns the name under which the tag key is reported, which is
+// _measurement and _field for the keys holding the measurement and the field.
+func tagKeyName(key []byte) string {
+	switch string(key) {
+	case models.MeasurementTagKey:
+		return "_measurement"
+	case models.FieldKeyTagKey:
+		return "_field"
+	}
+	return string(key)
+}
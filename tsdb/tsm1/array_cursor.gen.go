@@ -59,6 +59,7 @@ func (c *floatArrayAscendingCursor) Err() error { return nil }
 // close closes the cursor and any dependent cursors.
 func (c *floatArrayAscendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -66,7 +67,15 @@ func (c *floatArrayAscendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *floatArrayAscendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *floatArrayAscendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 // Next returns the next key/value for the cursor.
 func (c *floatArrayAscendingCursor) Next() *cursors.FloatArray {
@@ -226,6 +235,7 @@ func (c *floatArrayDescendingCursor) Err() error { return nil }
 
 func (c *floatArrayDescendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -233,7 +243,15 @@ func (c *floatArrayDescendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *floatArrayDescendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *floatArrayDescendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 func (c *floatArrayDescendingCursor) Next() *cursors.FloatArray {
 	pos := 0
@@ -369,6 +387,7 @@ func (c *integerArrayAscendingCursor) Err() error { return nil }
 // close closes the cursor and any dependent cursors.
 func (c *integerArrayAscendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -376,7 +395,15 @@ func (c *integerArrayAscendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *integerArrayAscendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *integerArrayAscendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 // Next returns the next key/value for the cursor.
 func (c *integerArrayAscendingCursor) Next() *cursors.IntegerArray {
@@ -536,6 +563,7 @@ func (c *integerArrayDescendingCursor) Err() error { return nil }
 
 func (c *integerArrayDescendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -543,7 +571,15 @@ func (c *integerArrayDescendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *integerArrayDescendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *integerArrayDescendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 func (c *integerArrayDescendingCursor) Next() *cursors.IntegerArray {
 	pos := 0
@@ -679,6 +715,7 @@ func (c *unsignedArrayAscendingCursor) Err() error { return nil }
 // close closes the cursor and any dependent cursors.
 func (c *unsignedArrayAscendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -686,7 +723,15 @@ func (c *unsignedArrayAscendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *unsignedArrayAscendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *unsignedArrayAscendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 // Next returns the next key/value for the cursor.
 func (c *unsignedArrayAscendingCursor) Next() *cursors.UnsignedArray {
@@ -846,6 +891,7 @@ func (c *unsignedArrayDescendingCursor) Err() error { return nil }
 
 func (c *unsignedArrayDescendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -853,7 +899,15 @@ func (c *unsignedArrayDescendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *unsignedArrayDescendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *unsignedArrayDescendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 func (c *unsignedArrayDescendingCursor) Next() *cursors.UnsignedArray {
 	pos := 0
@@ -989,6 +1043,7 @@ func (c *stringArrayAscendingCursor) Err() error { return nil }
 // close closes the cursor and any dependent cursors.
 func (c *stringArrayAscendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -996,7 +1051,15 @@ func (c *stringArrayAscendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *stringArrayAscendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *stringArrayAscendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 // Next returns the next key/value for the cursor.
 func (c *stringArrayAscendingCursor) Next() *cursors.StringArray {
@@ -1158,6 +1221,7 @@ func (c *stringArrayDescendingCursor) Err() error { return nil }
 
 func (c *stringArrayDescendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -1165,7 +1229,15 @@ func (c *stringArrayDescendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *stringArrayDescendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *stringArrayDescendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 func (c *stringArrayDescendingCursor) Next() *cursors.StringArray {
 	pos := 0
@@ -1303,6 +1375,7 @@ func (c *booleanArrayAscendingCursor) Err() error { return nil }
 // close closes the cursor and any dependent cursors.
 func (c *booleanArrayAscendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -1310,7 +1383,15 @@ func (c *booleanArrayAscendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *booleanArrayAscendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *booleanArrayAscendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 // Next returns the next key/value for the cursor.
 func (c *booleanArrayAscendingCursor) Next() *cursors.BooleanArray {
@@ -1470,6 +1551,7 @@ func (c *booleanArrayDescendingCursor) Err() error { return nil }
 
 func (c *booleanArrayDescendingCursor) Close() {
 	if c.tsm.keyCursor != nil {
+		c.stats.Add(c.tsm.keyCursor.Stats())
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
@@ -1477,7 +1559,15 @@ func (c *booleanArrayDescendingCursor) Close() {
 	c.tsm.values = nil
 }
 
-func (c *booleanArrayDescendingCursor) Stats() cursors.CursorStats { return c.stats }
+// Stats returns the stats of the values scanned and of the blocks read by the
+// cursor.
+func (c *booleanArrayDescendingCursor) Stats() cursors.CursorStats {
+	stats := c.stats
+	if c.tsm.keyCursor != nil {
+		stats.Add(c.tsm.keyCursor.Stats())
+	}
+	return stats
+}
 
 func (c *booleanArrayDescendingCursor) Next() *cursors.BooleanArray {
 	pos := 0
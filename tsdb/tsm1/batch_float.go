@@ -502,6 +502,17 @@ func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
 				// IsNaN, eof
 				break
 			}
+		} else if n := bits.LeadingZeros64(brCachedVal); n > 0 && brValidBits > 0 {
+			// The control bits which follow are also 0, so the value repeats
+			// for each of them. Series of constant or rarely changing values
+			// are common, and the run of cached 0 bits is appended at once
+			// rather than bit by bit.
+			if n > int(brValidBits) {
+				n = int(brValidBits)
+			}
+			brValidBits -= uint8(n)
+			brCachedVal = bits.RotateLeft64(brCachedVal, n)
+			dst = appendRepeated(dst, val, n)
 		}
 
 		dst = append(dst, val)
@@ -512,3 +523,15 @@ func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
 ERROR:
 	return (*(*[]float64)(unsafe.Pointer(&dst)))[:0], io.EOF
 }
+
+// appendRepeated appends n copies of v to dst.
+func appendRepeated(dst []uint64, v uint64, n int) []uint64 {
+	i := len(dst)
+	if cap(dst)-i < n {
+		dst = append(dst, make([]uint64, n)...)
+	} else {
+		dst = dst[:i+n]
+	}
+	fillUint64(dst[i:], v)
+	return dst
+}
@@ -257,6 +257,37 @@ func TestFloatArrayDecodeAll_Empty(t *testing.T) {
 	}
 }
 
+func TestFloatArrayDecodeAll_Runs(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	// Runs of up to 150 repeated values span several refills of the cached
+	// control bits, and begin and end at every offset within them.
+	for _, maxRun := range []int{1, 2, 8, 70, 150} {
+		var exp []float64
+		for len(exp) < 1000 {
+			v := float64(rng.Intn(10))
+			for n := rng.Intn(maxRun) + 1; n > 0; n-- {
+				exp = append(exp, v)
+			}
+		}
+
+		b, err := tsm1.FloatArrayEncodeAll(exp, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Decode into buffers both too small and large enough for the values.
+		for _, buf := range [][]float64{nil, make([]float64, 10), make([]float64, len(exp))} {
+			got, err := tsm1.FloatArrayDecodeAll(b, buf)
+			if err != nil {
+				t.Fatalf("unexpected decode error %q", err)
+			}
+			if !cmp.Equal(got, exp) {
+				t.Fatalf("unexpected values for runs of up to %d -got/+exp\n%s", maxRun, cmp.Diff(got, exp))
+			}
+		}
+	}
+}
+
 var bufResult []byte
 
 func BenchmarkEncodeFloats(b *testing.B) {
@@ -371,6 +402,33 @@ func BenchmarkDecodeFloats(b *testing.B) {
 			}
 		})
 
+		b.Run(fmt.Sprintf("%d_rep", n), func(b *testing.B) {
+			s := tsm1.NewFloatEncoder()
+			for i := 0; i < n; i++ {
+				s.Write(float64(i / 20))
+			}
+			s.Flush()
+			data, err := s.Bytes()
+			if err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			dst := make([]float64, n)
+			for i := 0; i < b.N; i++ {
+
+				got, err := tsm1.FloatArrayDecodeAll(data, dst)
+				if err != nil {
+					b.Fatalf("unexpected error\n%s", err.Error())
+				}
+				if len(got) != n {
+					b.Fatalf("unexpected length -got/+exp\n%s", cmp.Diff(len(got), n))
+				}
+			}
+		})
+
 		b.Run(fmt.Sprintf("%d_ran", n), func(b *testing.B) {
 			s := tsm1.NewFloatEncoder()
 			for i := 0; i < n; i++ {
@@ -177,11 +177,11 @@ func integerBatchDecodeAllUncompressed(b []byte, dst []int64) ([]int64, error) {
 		dst = dst[:count]
 	}
 
-	prev := int64(0)
-	for i := range dst {
-		prev += ZigZagDecode(binary.BigEndian.Uint64(b[i*8:]))
-		dst[i] = prev
+	buf := reintepretInt64ToUint64Slice(dst)
+	for i := range buf {
+		buf[i] = binary.BigEndian.Uint64(b[i*8:])
 	}
+	zigZagDeltaDecode(buf, 0)
 
 	return dst, nil
 }
@@ -218,11 +218,7 @@ func integerBatchDecodeAllSimple(b []byte, dst []int64) ([]int64, error) {
 	}
 
 	// calculate prefix sum
-	prev := dst[0]
-	for i := 1; i < len(dst); i++ {
-		prev += ZigZagDecode(uint64(dst[i]))
-		dst[i] = prev
-	}
+	zigZagDeltaDecode(buf[1:], dst[0])
 
 	return dst, nil
 }
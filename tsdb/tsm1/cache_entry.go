@@ -1,6 +1,7 @@
 package tsm1
 
 import (
+	"math"
 	"sync"
 	"sync/atomic"
 
@@ -130,6 +131,20 @@ func (e *entry) AppendTimestamps(ts []int64) []int64 {
 	return ts
 }
 
+// MaxTime returns the greatest timestamp of the values of the entry, which
+// may not be sorted.
+func (e *entry) MaxTime() int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	max := int64(math.MinInt64)
+	for _, v := range e.values {
+		if t := v.UnixNano(); t > max {
+			max = t
+		}
+	}
+	return max
+}
+
 // InfluxQLType returns for the entry the data type of its values.
 func (e *entry) InfluxQLType() (influxql.DataType, error) {
 	e.mu.RLock()
@@ -15,11 +15,12 @@ func _() {
 	_ = x[CacheStatusRetention-4]
 	_ = x[CacheStatusFullCompaction-5]
 	_ = x[CacheStatusBackup-6]
+	_ = x[CacheStatusFlush-7]
 }
 
-const _CacheStatus_name = "CacheStatusOkayCacheStatusSizeExceededCacheStatusAgeExceededCacheStatusColdNoWritesCacheStatusRetentionCacheStatusFullCompactionCacheStatusBackup"
+const _CacheStatus_name = "CacheStatusOkayCacheStatusSizeExceededCacheStatusAgeExceededCacheStatusColdNoWritesCacheStatusRetentionCacheStatusFullCompactionCacheStatusBackupCacheStatusFlush"
 
-var _CacheStatus_index = [...]uint8{0, 15, 38, 60, 83, 103, 128, 145}
+var _CacheStatus_index = [...]uint8{0, 15, 38, 60, 83, 103, 128, 145, 161}
 
 func (i CacheStatus) String() string {
 	if i < 0 || i >= CacheStatus(len(_CacheStatus_index)-1) {
@@ -0,0 +1,109 @@
+package tsm1
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// CompressionCodec is the compression applied to the values of the string
+// blocks of TSM files. Blocks of other types use encodings specific to their
+// values and are not affected.
+type CompressionCodec int
+
+const (
+	// CompressionSnappy compresses string values with snappy. It is the default.
+	CompressionSnappy CompressionCodec = iota
+
+	// CompressionZstd compresses string values with zstd, which uses more CPU
+	// than snappy but produces significantly smaller blocks.
+	CompressionZstd
+)
+
+// String returns the name of the codec.
+func (c CompressionCodec) String() string {
+	switch c {
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return "snappy"
+	}
+}
+
+// ParseCompressionCodec returns the codec named s.
+func ParseCompressionCodec(s string) (CompressionCodec, error) {
+	switch s {
+	case "snappy":
+		return CompressionSnappy, nil
+	case "zstd":
+		return CompressionZstd, nil
+	}
+	return 0, fmt.Errorf("unknown compression codec %q", s)
+}
+
+// CompressionCodecFunc returns the compression codec of the bucket identified
+// by orgID and bucketID.
+type CompressionCodecFunc func(orgID, bucketID influxdb.ID) CompressionCodec
+
+// WithCompressionCodecFunc sets the function used to determine the codec of the
+// string blocks written for each bucket by snapshots and compactions.
+//
+// Blocks are compressed with the codec of their bucket as they are written, so
+// blocks of existing files only change codec when they are compacted, or when
+// the files are converted with ConvertTSMCodec. Blocks of either codec are
+// always readable.
+func (e *Engine) WithCompressionCodecFunc(fn CompressionCodecFunc) {
+	e.Compactor.CompressionCodec = fn
+}
+
+// keyCompressionCodec returns the codec of the bucket of the TSM key, using
+// codecs to cache the codec of each bucket.
+func keyCompressionCodec(fn CompressionCodecFunc, codecs map[string]CompressionCodec, key []byte) CompressionCodec {
+	name := models.ParseName(key)
+	if c, ok := codecs[string(name)]; ok {
+		return c
+	}
+
+	c := CompressionSnappy
+	if len(name) == influxdb.IDLength {
+		c = fn(tsdb.DecodeNameSlice(name))
+	}
+	codecs[string(name)] = c
+	return c
+}
+
+// recompressStringBlock returns block with its values compressed with codec,
+// and whether the block was recompressed. Blocks which are not string blocks,
+// or which are already compressed with codec, are returned unchanged.
+func recompressStringBlock(block []byte, codec CompressionCodec) ([]byte, bool, error) {
+	if len(block) <= encodedBlockHeaderSize || block[0] != BlockString {
+		return block, false, nil
+	}
+
+	tb, vb, err := unpackBlock(block[encodedBlockHeaderSize:])
+	if err != nil {
+		return nil, false, err
+	}
+	if len(vb) == 0 || stringCompressionCodec(vb) == codec {
+		return block, false, nil
+	}
+
+	data, err := decompressStrings(vb)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode string block: %v", err)
+	}
+	if vb, err = compressStrings(nil, data, codec); err != nil {
+		return nil, false, err
+	}
+	return packBlock(nil, BlockString, tb, vb), true, nil
+}
+
+// stringCompressionCodec returns the codec of the encoded string values b.
+func stringCompressionCodec(b []byte) CompressionCodec {
+	if b[0]>>4 == stringCompressedZstd {
+		return CompressionZstd
+	}
+	return CompressionSnappy
+}
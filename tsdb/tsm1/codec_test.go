@@ -0,0 +1,166 @@
+package tsm1
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func TestRecompressStringBlock(t *testing.T) {
+	values := make(Values, 100)
+	for i := range values {
+		values[i] = NewValue(int64(i), strings.Repeat(fmt.Sprintf("value %d ", i%3), 10))
+	}
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error encoding block: %v", err)
+	}
+
+	zb, ok, err := recompressStringBlock(block, CompressionZstd)
+	if err != nil {
+		t.Fatalf("unexpected error recompressing block: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected block to be recompressed")
+	}
+	if len(zb) >= len(block) {
+		t.Errorf("expected zstd block to be smaller: got %d bytes, snappy %d bytes", len(zb), len(block))
+	}
+
+	// Both the iterator and the batch decoders read zstd blocks.
+	var got []StringValue
+	if got, err = DecodeStringBlock(zb, &got); err != nil {
+		t.Fatalf("unexpected error decoding block: %v", err)
+	}
+	if len(got) != len(values) {
+		t.Fatalf("unexpected number of values: got %d, exp %d", len(got), len(values))
+	}
+	for i := range got {
+		if got[i].String() != values[i].String() {
+			t.Fatalf("unexpected value %d: got %v, exp %v", i, got[i], values[i])
+		}
+	}
+
+	_, vb, err := unpackBlock(zb[1:])
+	if err != nil {
+		t.Fatalf("unexpected error unpacking block: %v", err)
+	}
+	strs, err := StringArrayDecodeAll(vb, nil)
+	if err != nil {
+		t.Fatalf("unexpected error decoding values: %v", err)
+	}
+	for i := range strs {
+		if strs[i] != values[i].Value() {
+			t.Fatalf("unexpected value %d: got %q, exp %q", i, strs[i], values[i].Value())
+		}
+	}
+
+	if _, ok, _ := recompressStringBlock(zb, CompressionZstd); ok {
+		t.Error("expected zstd block not to be recompressed to zstd")
+	}
+
+	sb, ok, err := recompressStringBlock(zb, CompressionSnappy)
+	if err != nil {
+		t.Fatalf("unexpected error recompressing block: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected block to be recompressed")
+	}
+	if !cmp.Equal(sb, block) {
+		t.Error("expected block converted back to snappy to equal the original block")
+	}
+}
+
+func TestConvertTSMCodec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsm1-convert-codec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	org, bucket, other := influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+	key := func(bucketID influxdb.ID) []byte {
+		name := tsdb.EncodeName(org, bucketID)
+		return append(name[:], ",t=v#!~#f"...)
+	}
+	keys := [][]byte{key(bucket), key(other)}
+	values := Values{NewValue(0, "a"), NewValue(1, "b")}
+
+	path := filepath.Join(dir, "000000001-000000001.tsm")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if err := w.Write(k, values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	convert := ConvertTSMCodec{
+		Stdout:   ioutil.Discard,
+		Paths:    []string{path},
+		OrgID:    org,
+		BucketID: bucket,
+		Codec:    CompressionZstd,
+	}
+	if err := convert.Run(); err != nil {
+		t.Fatalf("unexpected error converting file: %v", err)
+	}
+
+	f, err = os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for i, exp := range []CompressionCodec{CompressionZstd, CompressionSnappy} {
+		entries, err := r.ReadEntries(keys[i], nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("unexpected number of blocks for key %d: %d", i, len(entries))
+		}
+		_, block, err := r.ReadBytes(&entries[0], nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, vb, err := unpackBlock(block[1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := stringCompressionCodec(vb); got != exp {
+			t.Errorf("unexpected codec for key %d: got %v, exp %v", i, got, exp)
+		}
+
+		got, err := r.ReadAll(keys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(values) || got[0].String() != values[0].String() || got[1].String() != values[1].String() {
+			t.Errorf("unexpected values for key %d: got %v, exp %v", i, got, values)
+		}
+	}
+}
@@ -698,6 +698,10 @@ type Compactor struct {
 	// RateLimit is the limit for disk writes for all concurrent compactions.
 	RateLimit limiter.Rate
 
+	// CompressionCodec, when set, determines the codec of the string blocks
+	// written for each bucket. Otherwise blocks are written as they are read.
+	CompressionCodec CompressionCodecFunc
+
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
@@ -1141,6 +1145,11 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 		}
 	}()
 
+	var codecs map[string]CompressionCodec
+	if c.CompressionCodec != nil {
+		codecs = make(map[string]CompressionCodec)
+	}
+
 	for iter.Next() {
 		c.mu.RLock()
 		enabled := c.snapshotsEnabled || c.compactionsEnabled
@@ -1161,6 +1170,14 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 			return fmt.Errorf("invalid index entry for block. min=%d, max=%d", minTime, maxTime)
 		}
 
+		// Compress string blocks with the codec of their bucket.
+		if codecs != nil {
+			codec := keyCompressionCodec(c.CompressionCodec, codecs, key)
+			if block, _, err = recompressStringBlock(block, codec); err != nil {
+				return err
+			}
+		}
+
 		// Write the key and value
 		if err := w.WriteBlock(key, minTime, maxTime, block); err == ErrMaxBlocksExceeded {
 			if err := w.WriteIndex(); err != nil {
@@ -1410,9 +1427,12 @@ RETRY:
 	for i, v := range k.buf {
 		if len(v) == 0 {
 			iter := k.iterators[i]
-			if iter.Next() {
+			for iter.Next() {
 				key, minTime, maxTime, typ, _, b, err := iter.Read()
 				if err != nil {
+					if dropCorruptBlock(iter.r, err) {
+						continue
+					}
 					k.err = err
 				}
 
@@ -1445,6 +1465,9 @@ RETRY:
 					iter.Next()
 					key, minTime, maxTime, typ, _, b, err := iter.Read()
 					if err != nil {
+						if dropCorruptBlock(iter.r, err) {
+							continue
+						}
 						k.err = err
 					}
 
@@ -1470,6 +1493,7 @@ RETRY:
 					blk.readMax = math.MinInt64
 					blk.tombstones = iter.r.TombstoneRange(key, blk.tombstones[:0])
 				}
+				break
 			}
 
 			if iter.Err() != nil {
@@ -1699,9 +1723,12 @@ RETRY:
 		}
 
 		iter := k.iterators[i]
-		if iter.Next() {
+		for iter.Next() {
 			key, minTime, maxTime, typ, _, b, err := iter.Read()
 			if err != nil {
+				if dropCorruptBlock(iter.r, err) {
+					continue
+				}
 				k.err = err
 			}
 
@@ -1734,6 +1761,9 @@ RETRY:
 				iter.Next()
 				key, minTime, maxTime, typ, _, b, err := iter.Read()
 				if err != nil {
+					if dropCorruptBlock(iter.r, err) {
+						continue
+					}
 					k.err = err
 				}
 
@@ -1759,6 +1789,7 @@ RETRY:
 				blk.readMax = math.MinInt64
 				blk.tombstones = iter.r.TombstoneRange(key, blk.tombstones[:0])
 			}
+			break
 		}
 
 		if iter.Err() != nil {
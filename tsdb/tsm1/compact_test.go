@@ -1106,6 +1106,87 @@ func TestCompactor_CompactFull_MaxKeys(t *testing.T) {
 	}
 }
 
+// Tests that a block which fails checksum verification is dropped from a
+// compaction, rather than failing it, and recorded in the quarantine.
+func TestCompactor_CompactFull_CorruptBlock(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.1)},
+	})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(2, 1.2)},
+		"cpu,host=B#!~#value": {tsm1.NewValue(10, 2.1), tsm1.NewValue(20, 2.2)},
+	})
+
+	// Flip the last byte of the block of host=B, as bit rot would.
+	r := MustOpenTSMReader(f2)
+	entries, err := r.ReadEntries([]byte("cpu,host=B#!~#value"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error reading entries: %v", err)
+	}
+	entry := entries[0]
+	r.Close()
+
+	f, err := os.OpenFile(f2, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 1)
+	pos := entry.Offset + int64(entry.Size) - 1
+	if _, err := f.ReadAt(b, pos); err != nil {
+		t.Fatal(err)
+	}
+	b[0] ^= 0xff
+	if _, err := f.WriteAt(b, pos); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	fs := tsm1.NewFileStore(dir)
+	fs.SetVerifyChecksums(true)
+	if err := fs.Open(context.Background()); err != nil {
+		t.Fatalf("unexpected error opening file store: %v", err)
+	}
+	defer fs.Close()
+
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting a corrupt block: %v", err)
+	}
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	r = MustOpenTSMReader(files[0])
+	defer r.Close()
+	if got, exp := r.KeyCount(), 1; got != exp {
+		t.Fatalf("keys length mismatch: got %v, exp %v", got, exp)
+	}
+	values, err := r.ReadAll([]byte("cpu,host=A#!~#value"))
+	if err != nil {
+		t.Fatalf("unexpected error reading values: %v", err)
+	}
+	if got, exp := len(values), 2; got != exp {
+		t.Fatalf("values length mismatch: got %v, exp %v", got, exp)
+	}
+
+	blocks := fs.QuarantinedBlocks()
+	if got, exp := len(blocks), 1; got != exp {
+		t.Fatalf("quarantined blocks mismatch: got %v, exp %v", got, exp)
+	}
+	got := blocks[0]
+	if string(got.Key) != "cpu,host=B#!~#value" || got.MinTime != 10 || got.MaxTime != 20 || !got.Dropped {
+		t.Fatalf("unexpected quarantined block %+v", got)
+	}
+}
+
 // Tests that a single TSM file can be read and iterated over
 func TestTSMKeyIterator_Single(t *testing.T) {
 	dir := MustTempDir()
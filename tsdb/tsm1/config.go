@@ -12,6 +12,10 @@ var DefaultMaxConcurrentOpens = runtime.GOMAXPROCS(0)
 const (
 	DefaultMADVWillNeed = false
 
+	// DefaultVerifyChecksums is whether the checksums of TSM blocks are
+	// verified when they are read, unless configured otherwise.
+	DefaultVerifyChecksums = false
+
 	// DefaultLargeSeriesWriteThreshold is the number of series per write
 	// that requires the series index be pregrown before insert.
 	DefaultLargeSeriesWriteThreshold = 10000
@@ -29,6 +33,12 @@ type Config struct {
 	// slow disks.
 	MADVWillNeed bool `toml:"use-madv-willneed"`
 
+	// VerifyChecksums controls whether the checksum of each TSM block is
+	// verified when it is read. Blocks which fail verification are
+	// quarantined and their reads fail, rather than returning corrupt data.
+	// Verification costs a CRC32 of every block read, so it is off by default.
+	VerifyChecksums bool `toml:"verify-checksums"`
+
 	// LargeSeriesWriteThreshold is the threshold before a write requires
 	// preallocation to improve throughput. Currently used in the series file.
 	LargeSeriesWriteThreshold int `toml:"large-series-write-threshold"`
@@ -42,6 +52,7 @@ func NewConfig() Config {
 	return Config{
 		MaxConcurrentOpens:        DefaultMaxConcurrentOpens,
 		MADVWillNeed:              DefaultMADVWillNeed,
+		VerifyChecksums:           DefaultVerifyChecksums,
 		LargeSeriesWriteThreshold: DefaultLargeSeriesWriteThreshold,
 
 		Cache: NewCacheConfig(),
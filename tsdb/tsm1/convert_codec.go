@@ -0,0 +1,146 @@
+package tsm1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// ConvertTSMCodec rewrites TSM files with their string blocks compressed with
+// Codec. The files must not be in use by a running engine.
+type ConvertTSMCodec struct {
+	Stdout   io.Writer
+	Paths    []string
+	OrgID    influxdb.ID
+	BucketID influxdb.ID
+	Codec    CompressionCodec
+}
+
+// Run converts each of the files of c.Paths. Files without blocks to convert
+// are left untouched.
+func (c *ConvertTSMCodec) Run() error {
+	for _, path := range c.Paths {
+		if err := c.processFile(path); err != nil {
+			return fmt.Errorf("error converting file %q: %v", path, err)
+		}
+	}
+	return nil
+}
+
+func (c *ConvertTSMCodec) processFile(path string) error {
+	fmt.Fprintln(c.Stdout, "processing file: "+path)
+
+	file, err := os.OpenFile(path, os.O_RDONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("OpenFile: %v", err)
+	}
+
+	reader, err := NewTSMReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to create TSM reader for %q: %v", path, err)
+	}
+
+	tmpPath := fmt.Sprintf("%s.%s", path, CompactionTempExtension)
+	converted, total, err := c.writeFile(reader, tmpPath)
+
+	// The mapping of the file must be released before the file is replaced.
+	if closeErr := reader.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || converted == 0 {
+		os.Remove(tmpPath)
+		if err == nil {
+			fmt.Fprintf(c.Stdout, "No blocks to convert in %d block(s)\n", total)
+		}
+		return err
+	}
+
+	if err := fs.RenameFileWithReplacement(tmpPath, path); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Stdout, "Converted %d of %d block(s) to %s\n", converted, total, c.Codec)
+	return nil
+}
+
+// writeFile writes the blocks of r to a new TSM file at path. It returns the
+// number of blocks converted and the total number of blocks.
+func (c *ConvertTSMCodec) writeFile(r *TSMReader, path string) (converted, total int, err error) {
+	var prefix []byte
+	if c.OrgID.Valid() {
+		if c.BucketID.Valid() {
+			v := tsdb.EncodeName(c.OrgID, c.BucketID)
+			prefix = v[:]
+		} else {
+			v := tsdb.EncodeOrgName(c.OrgID)
+			prefix = v[:]
+		}
+	}
+
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Use a disk based TSM buffer if the index might be big, as compactions do.
+	var w TSMWriter
+	if r.IndexSize() > 64*1024*1024 {
+		w, err = NewTSMWriterWithDiskBuffer(fd)
+	} else {
+		w, err = NewTSMWriter(fd)
+	}
+	if err != nil {
+		fd.Close()
+		return 0, 0, err
+	}
+
+	converted, total, err = c.rewrite(r, w, prefix)
+	if err == nil {
+		err = w.WriteIndex()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return converted, total, err
+}
+
+// rewrite writes the blocks of r to w, compressing the string blocks of the
+// keys with prefix with c.Codec. It returns the number of blocks converted and
+// the total number of blocks.
+func (c *ConvertTSMCodec) rewrite(r *TSMReader, w TSMWriter, prefix []byte) (converted, total int, err error) {
+	iter := r.Iterator(nil)
+	for iter.Next() {
+		key := iter.Key()
+		match := bytes.HasPrefix(key, prefix)
+
+		entries := iter.Entries()
+		for i := range entries {
+			entry := &entries[i]
+
+			_, block, err := r.ReadBytes(entry, nil)
+			if err != nil {
+				return converted, total, err
+			}
+			total++
+
+			if match {
+				var ok bool
+				if block, ok, err = recompressStringBlock(block, c.Codec); err != nil {
+					return converted, total, err
+				} else if ok {
+					converted++
+				}
+			}
+
+			if err := w.WriteBlock(key, entry.MinTime, entry.MaxTime, block); err != nil {
+				return converted, total, err
+			}
+		}
+	}
+	return converted, total, iter.Err()
+}
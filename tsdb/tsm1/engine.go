@@ -161,6 +161,10 @@ type Engine struct {
 	// Controls whether to enabled compactions when the engine is open
 	enableCompactionsOnOpen bool
 
+	// readOnly is true if the engine never changes the files of its
+	// directory.
+	readOnly bool
+
 	compactionTracker   *compactionTracker // Used to track state of compactions.
 	readTracker         *readTracker       // Used to track number of reads.
 	defaultMetricLabels prometheus.Labels  // N.B this must not be mutated after Open is called.
@@ -174,6 +178,11 @@ type Engine struct {
 
 	scheduler   *scheduler
 	snapshotter Snapshotter
+
+	// duplicatePolicy determines how duplicate values are resolved for each
+	// bucket. When nil all buckets use DuplicatePolicyLastWriteWins.
+	duplicatePolicy DuplicatePolicyFunc
+	duplicateLocks  duplicateLocks
 }
 
 // NewEngine returns a new instance of Engine.
@@ -181,6 +190,7 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 	fs := NewFileStore(path)
 	fs.openLimiter = limiter.NewFixed(config.MaxConcurrentOpens)
 	fs.tsmMMAPWillNeed = config.MADVWillNeed
+	fs.SetVerifyChecksums(config.VerifyChecksums)
 
 	cache := NewCache(uint64(config.Cache.MaxMemorySize))
 
@@ -290,6 +300,16 @@ func (e *Engine) SetEnabled(enabled bool) {
 	e.SetCompactionsEnabled(enabled)
 }
 
+// SetReadOnly sets whether the engine is opened read-only, in which case it
+// leaves the files of failed snapshots and compactions, and the corrupt TSM
+// files, in place and never compacts. It must be called before the Engine
+// is opened.
+func (e *Engine) SetReadOnly(readOnly bool) {
+	e.readOnly = readOnly
+	e.enableCompactionsOnOpen = !readOnly
+	e.FileStore.SetReadOnly(readOnly)
+}
+
 // SetCompactionsEnabled enables compactions on the engine.  When disabled
 // all running compactions are aborted and new compactions stop running.
 func (e *Engine) SetCompactionsEnabled(enabled bool) {
@@ -547,12 +567,14 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 
 	e.initTrackers()
 
-	if err := os.MkdirAll(e.path, 0777); err != nil {
-		return err
-	}
+	if !e.readOnly {
+		if err := os.MkdirAll(e.path, 0777); err != nil {
+			return err
+		}
 
-	if err := e.cleanup(); err != nil {
-		return err
+		if err := e.cleanup(); err != nil {
+			return err
+		}
 	}
 
 	if err := e.FileStore.Open(ctx); err != nil {
@@ -632,6 +654,10 @@ func (e *Engine) WriteValues(values map[string][]Value) error {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
+	if e.duplicatePolicy != nil {
+		return e.writeValuesWithDuplicatePolicy(values)
+	}
+
 	if err := e.Cache.WriteMulti(values); err != nil {
 		return err
 	}
@@ -940,6 +966,7 @@ const (
 	CacheStatusRetention                         // The cache was snapshotted before running retention.
 	CacheStatusFullCompaction                    // The cache was snapshotted as part of a full compaction.
 	CacheStatusBackup                            // The cache was snapshotted before running backup.
+	CacheStatusFlush                             // The cache was snapshotted because a flush was requested.
 )
 
 // ShouldCompactCache returns a status indicating if the Cache should be
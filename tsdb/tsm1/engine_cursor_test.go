@@ -110,8 +110,9 @@ func TestEngine_CursorIterator_Stats(t *testing.T) {
 	for a := ic.Next(); a.Len() > 0; a = ic.Next() {
 	}
 
-	// iterator should report integer array stats
-	if got, exp := cursorIterator.Stats(), (cursors.CursorStats{ScannedValues: 3, ScannedBytes: 24}); exp != got {
+	// iterator should report integer array stats, and the block read by each
+	// cursor
+	if got, exp := cursorIterator.Stats(), (cursors.CursorStats{ScannedValues: 3, ScannedBytes: 24, BlocksDecoded: 2}); exp != got {
 		t.Fatalf("expected %v, got %v", exp, got)
 	}
 }
@@ -0,0 +1,198 @@
+package tsm1
+
+import (
+	"sync"
+
+	"github.com/cespare/xxhash"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/bytesutil"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// DuplicatePolicy determines how the engine resolves a value that has the same
+// series key, field and timestamp as a value that has already been written.
+type DuplicatePolicy int
+
+const (
+	// DuplicatePolicyLastWriteWins replaces the existing value with the new one.
+	// This is how the cache and compactions resolve duplicates, and is the default.
+	DuplicatePolicyLastWriteWins DuplicatePolicy = iota
+
+	// DuplicatePolicyFirstWriteWins keeps the existing value and silently drops
+	// the new one.
+	DuplicatePolicyFirstWriteWins
+
+	// DuplicatePolicyReject keeps the existing value, drops the new one and
+	// reports the dropped values to the caller as a partial write.
+	DuplicatePolicyReject
+)
+
+// DuplicatePolicyFunc returns the duplicate policy of the bucket identified by
+// orgID and bucketID.
+type DuplicatePolicyFunc func(orgID, bucketID influxdb.ID) DuplicatePolicy
+
+// WithDuplicatePolicyFunc sets the function used to determine how duplicate
+// values are resolved for each bucket.
+//
+// Values written to buckets with a policy other than DuplicatePolicyLastWriteWins
+// are checked against the cache and TSM files before they are admitted, so the
+// cache and compactions never see duplicates for those buckets. Duplicates which
+// were written before the policy was changed are still resolved by compactions
+// as last-write-wins.
+func (e *Engine) WithDuplicatePolicyFunc(fn DuplicatePolicyFunc) {
+	e.duplicatePolicy = fn
+}
+
+// writeValuesWithDuplicatePolicy writes values to the cache after removing any
+// values that would be a duplicate under the policy of the bucket they belong to.
+//
+// It must be called with e.mu held for reading.
+func (e *Engine) writeValuesWithDuplicatePolicy(values map[string][]Value) error {
+	policies := make(map[string]DuplicatePolicy)
+	checked := make([]string, 0, len(values))
+	for k := range values {
+		if e.keyDuplicatePolicy(policies, []byte(k)) != DuplicatePolicyLastWriteWins {
+			checked = append(checked, k)
+		}
+	}
+
+	if len(checked) == 0 {
+		return e.Cache.WriteMulti(values)
+	}
+
+	// Concurrent writes of the same timestamp must not both be admitted, so the
+	// existence check and the write into the cache have to happen atomically
+	// for the series keys of the checked values.
+	unlock := e.duplicateLocks.lock(checked)
+	defer unlock()
+
+	var (
+		rejected     int
+		rejectedKeys [][]byte
+	)
+	for _, k := range checked {
+		vals, dropped, err := e.dropDuplicateValues([]byte(k), values[k])
+		if err != nil {
+			return err
+		}
+
+		if dropped > 0 && e.keyDuplicatePolicy(policies, []byte(k)) == DuplicatePolicyReject {
+			rejected += dropped
+			seriesKey, _ := SeriesAndFieldFromCompositeKey([]byte(k))
+			rejectedKeys = append(rejectedKeys, seriesKey)
+		}
+
+		if len(vals) == 0 {
+			delete(values, k)
+			continue
+		}
+		values[k] = vals
+	}
+
+	if err := e.Cache.WriteMulti(values); err != nil {
+		return err
+	}
+
+	if rejected > 0 {
+		return tsdb.PartialWriteError{
+			Reason:      "duplicate points rejected by bucket duplicate policy",
+			Dropped:     rejected,
+			DroppedKeys: bytesutil.SortDedup(rejectedKeys),
+		}
+	}
+	return nil
+}
+
+// duplicateLockStripes is the number of locks shared by the series keys of the
+// values checked for duplicates.
+const duplicateLockStripes = 256
+
+// duplicateLocks serializes the duplicate checks of the values of a series key,
+// so that writes of different series keys are checked concurrently.
+type duplicateLocks [duplicateLockStripes]sync.Mutex
+
+// lock locks the stripes of the series keys of the composite keys, and returns
+// the function unlocking them. The stripes are locked in order, so that writes
+// sharing several stripes cannot deadlock.
+func (l *duplicateLocks) lock(keys []string) func() {
+	var locked [duplicateLockStripes]bool
+	for _, k := range keys {
+		seriesKey, _ := SeriesAndFieldFromCompositeKey([]byte(k))
+		locked[xxhash.Sum64(seriesKey)%duplicateLockStripes] = true
+	}
+
+	for i := range l {
+		if locked[i] {
+			l[i].Lock()
+		}
+	}
+	return func() {
+		for i := range l {
+			if locked[i] {
+				l[i].Unlock()
+			}
+		}
+	}
+}
+
+// keyDuplicatePolicy returns the duplicate policy for the bucket that the
+// composite key belongs to, caching the result by measurement name.
+func (e *Engine) keyDuplicatePolicy(policies map[string]DuplicatePolicy, key []byte) DuplicatePolicy {
+	name := models.ParseName(key)
+	if p, ok := policies[string(name)]; ok {
+		return p
+	}
+
+	p := DuplicatePolicyLastWriteWins
+	if len(name) == influxdb.IDLength {
+		p = e.duplicatePolicy(tsdb.DecodeNameSlice(name))
+	}
+	policies[string(name)] = p
+	return p
+}
+
+// dropDuplicateValues removes any value from vals whose timestamp already exists
+// for key, either in the cache or in a TSM file, or which appears earlier in vals.
+// It returns the remaining values and the number of values dropped.
+func (e *Engine) dropDuplicateValues(key []byte, vals []Value) ([]Value, int, error) {
+	if len(vals) == 0 {
+		return vals, 0, nil
+	}
+
+	min, max := vals[0].UnixNano(), vals[0].UnixNano()
+	for _, v := range vals[1:] {
+		if t := v.UnixNano(); t < min {
+			min = t
+		} else if t > max {
+			max = t
+		}
+	}
+
+	// The cache must be consulted before the file store: a snapshot remains
+	// visible in the cache until its values are visible in the file store.
+	ts := e.Cache.AppendTimestamps(key, nil)
+	ts, err := e.FileStore.AppendTimestamps(key, min, max, ts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	seen := make(map[int64]struct{}, len(ts)+len(vals))
+	for _, t := range ts {
+		if t >= min && t <= max {
+			seen[t] = struct{}{}
+		}
+	}
+
+	n := 0
+	for _, v := range vals {
+		t := v.UnixNano()
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		vals[n] = v
+		n++
+	}
+	return vals[:n], len(vals) - n, nil
+}
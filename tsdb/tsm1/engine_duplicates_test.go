@@ -0,0 +1,238 @@
+package tsm1_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+func TestEngine_DuplicatePolicy(t *testing.T) {
+	var (
+		org         = influxdb.ID(0x5000)
+		lastWins    = influxdb.ID(0x6000)
+		firstWins   = influxdb.ID(0x6001)
+		rejectDupes = influxdb.ID(0x6002)
+	)
+
+	policies := map[influxdb.ID]tsm1.DuplicatePolicy{
+		lastWins:    tsm1.DuplicatePolicyLastWriteWins,
+		firstWins:   tsm1.DuplicatePolicyFirstWriteWins,
+		rejectDupes: tsm1.DuplicatePolicyReject,
+	}
+
+	tests := []struct {
+		name      string
+		bucket    influxdb.ID
+		snapshot  bool
+		exp       map[int64]float64
+		expReject int
+	}{
+		{
+			name:   "last write wins",
+			bucket: lastWins,
+			exp:    map[int64]float64{10: 2, 20: 3, 30: 5},
+		},
+		{
+			name:   "first write wins",
+			bucket: firstWins,
+			exp:    map[int64]float64{10: 1, 20: 3, 30: 4},
+		},
+		{
+			name:     "first write wins with snapshotted value",
+			bucket:   firstWins,
+			snapshot: true,
+			exp:      map[int64]float64{20: 3, 30: 4},
+		},
+		{
+			name:      "reject",
+			bucket:    rejectDupes,
+			exp:       map[int64]float64{10: 1, 20: 3, 30: 4},
+			expReject: 2,
+		},
+		{
+			name:      "reject with snapshotted value",
+			bucket:    rejectDupes,
+			snapshot:  true,
+			exp:       map[int64]float64{20: 3, 30: 4},
+			expReject: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEngine(tsm1.NewConfig(), t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.WithDuplicatePolicyFunc(func(_, bucketID influxdb.ID) tsm1.DuplicatePolicy {
+				return policies[bucketID]
+			})
+			if err := e.Open(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer e.Close()
+
+			mm := string(models.EscapeMeasurement(tsdb.EncodeNameSlice(org, tt.bucket)))
+			if err := e.WritePointsString(mm, `cpu,host=a v=1 10`); err != nil {
+				t.Fatal(err)
+			}
+			if tt.snapshot {
+				e.MustWriteSnapshot()
+			}
+
+			err = e.WritePointsString(mm,
+				`cpu,host=a v=2 10`,
+				`cpu,host=a v=3 20`,
+				`cpu,host=a v=4 30`,
+				`cpu,host=a v=5 30`,
+			)
+			if tt.expReject == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if tt.expReject > 0 {
+				pwe, ok := err.(tsdb.PartialWriteError)
+				if !ok {
+					t.Fatalf("expected partial write error, got %v", err)
+				}
+				if pwe.Dropped != tt.expReject {
+					t.Fatalf("unexpected number of rejected values: got %d, exp %d", pwe.Dropped, tt.expReject)
+				}
+			}
+
+			keys := e.Cache.Keys()
+			if len(keys) != 1 {
+				t.Fatalf("unexpected number of cache keys: got %d, exp 1", len(keys))
+			}
+
+			got := make(map[int64]float64)
+			for _, v := range e.Cache.Values(keys[0]) {
+				got[v.UnixNano()] = v.Value().(float64)
+			}
+			if !reflect.DeepEqual(got, tt.exp) {
+				t.Fatalf("unexpected values: got %v, exp %v", got, tt.exp)
+			}
+		})
+	}
+}
+
+func TestEngine_DuplicatePolicy_Concurrent(t *testing.T) {
+	org, bucket := influxdb.ID(0x5000), influxdb.ID(0x6000)
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.WithDuplicatePolicyFunc(func(_, _ influxdb.ID) tsm1.DuplicatePolicy {
+		return tsm1.DuplicatePolicyReject
+	})
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// Concurrent writes of the same value must admit exactly one of them,
+	// while the writes of the other series are not affected.
+	const writers = 8
+	mm := string(models.EscapeMeasurement(tsdb.EncodeNameSlice(org, bucket)))
+	var (
+		wg       sync.WaitGroup
+		rejected int32
+	)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := e.WritePointsString(mm, fmt.Sprintf("cpu,host=a v=%d 10", i), fmt.Sprintf("cpu,host=%d v=1 10", i))
+			if pwe, ok := err.(tsdb.PartialWriteError); ok {
+				atomic.AddInt32(&rejected, int32(pwe.Dropped))
+			} else if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if rejected != writers-1 {
+		t.Fatalf("unexpected number of rejected values: got %d, exp %d", rejected, writers-1)
+	}
+	if keys := e.Cache.Keys(); len(keys) != writers+1 {
+		t.Fatalf("unexpected number of cache keys: got %d, exp %d", len(keys), writers+1)
+	}
+	for _, k := range e.Cache.Keys() {
+		if n := len(e.Cache.Values(k)); n != 1 {
+			t.Fatalf("unexpected number of values for %s: got %d, exp 1", k, n)
+		}
+	}
+}
+
+// BenchmarkEngine_WritePoints_DuplicatePolicy measures the cost of checking
+// written values against the cache and TSM files, compared to writing them
+// under DuplicatePolicyLastWriteWins, which is not checked. Every value is a
+// duplicate of a value of a TSM file, so that the check reads its blocks.
+func BenchmarkEngine_WritePoints_DuplicatePolicy(b *testing.B) {
+	org, bucket := influxdb.ID(0x5000), influxdb.ID(0x6000)
+	mm := string(models.EscapeMeasurement(tsdb.EncodeNameSlice(org, bucket)))
+	cpus := runtime.GOMAXPROCS(0)
+
+	for _, policy := range []struct {
+		name   string
+		policy tsm1.DuplicatePolicy
+	}{
+		{"last-write-wins", tsm1.DuplicatePolicyLastWriteWins},
+		{"first-write-wins", tsm1.DuplicatePolicyFirstWriteWins},
+	} {
+		for _, sz := range []int{100, 1000, 5000} {
+			e, err := NewEngine(tsm1.NewConfig(), b)
+			if err != nil {
+				b.Fatal(err)
+			}
+			p := policy.policy
+			e.WithDuplicatePolicyFunc(func(_, _ influxdb.ID) tsm1.DuplicatePolicy {
+				return p
+			})
+			if err := e.Open(context.Background()); err != nil {
+				b.Fatal(err)
+			}
+
+			// Each writer of the parallel benchmark writes its own series.
+			pp := make([]models.Point, 0, sz*cpus)
+			for i := 0; i < sz*cpus; i++ {
+				pp = append(pp, MustParsePointString(fmt.Sprintf("cpu,host=%d value=1.2 10", i), mm))
+			}
+			if err := e.WritePoints(pp); err != nil {
+				b.Fatal(err)
+			}
+			e.MustWriteSnapshot()
+
+			b.Run(fmt.Sprintf("%s/%d", policy.name, sz), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := e.WritePoints(pp[:sz]); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(fmt.Sprintf("%s/%d/parallel", policy.name, sz), func(b *testing.B) {
+				b.ReportAllocs()
+				var writer int32
+				b.RunParallel(func(pb *testing.PB) {
+					i := int(atomic.AddInt32(&writer, 1)-1) % cpus
+					for pb.Next() {
+						if err := e.WritePoints(pp[i*sz : (i+1)*sz]); err != nil {
+							b.Error(err)
+							return
+						}
+					}
+				})
+			})
+			e.Close()
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package tsm1
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+// FieldKeyStats describes the values of a field of a measurement, read from
+// the TSM index and the cache.
+type FieldKeyStats struct {
+	Measurement string
+	Field       string
+	Types       []influxdb.FieldType
+	SeriesN     int64
+	// MaxTime is the greatest timestamp of the values of the field.
+	MaxTime int64
+}
+
+// FieldKeyStats returns the fields of the measurement of the bucket, or of
+// all of its measurements if measurement is empty, in the order of their
+// measurements and fields. The values of the fields are not read.
+func (e *Engine) FieldKeyStats(ctx context.Context, orgID, bucketID influxdb.ID, measurement string) ([]FieldKeyStats, error) {
+	orgBucket := tsdb.EncodeName(orgID, bucketID)
+	prefix := models.EscapeMeasurement(orgBucket[:])
+	if measurement != "" {
+		mt := models.Tags{models.NewTag(models.MeasurementTagKeyBytes, []byte(measurement))}
+		prefix = mt.AppendHashKey(prefix)
+	}
+
+	stats, err := e.keyStats(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	type fieldKey struct{ measurement, field string }
+	fields := make(map[fieldKey]*FieldKeyStats)
+	masks := make(map[fieldKey]uint8)
+	for key, st := range stats {
+		seriesKey, field := SeriesAndFieldFromCompositeKey([]byte(key))
+		name, err := models.ParseMeasurement(seriesKey)
+		if err != nil {
+			e.logger.Error("Invalid series key in TSM index", zap.Error(err), zap.Binary("key", seriesKey))
+			continue
+		}
+		// The prefix of a measurement is also the prefix of the
+		// measurements whose names begin with its name.
+		if measurement != "" && string(name) != measurement {
+			continue
+		}
+
+		k := fieldKey{measurement: string(name), field: string(field)}
+		fs, ok := fields[k]
+		if !ok {
+			fs = &FieldKeyStats{Measurement: k.measurement, Field: k.field, MaxTime: math.MinInt64}
+			fields[k] = fs
+		}
+		fs.SeriesN++
+		if st.maxTime > fs.MaxTime {
+			fs.MaxTime = st.maxTime
+		}
+		masks[k] |= st.types
+	}
+
+	res := make([]FieldKeyStats, 0, len(fields))
+	for k, fs := range fields {
+		for b, t := range blockTypeFieldTypes {
+			if masks[k]&(1<<b) != 0 {
+				fs.Types = append(fs.Types, t)
+			}
+		}
+		sort.Slice(fs.Types, func(i, j int) bool { return fs.Types[i] < fs.Types[j] })
+		res = append(res, *fs)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Measurement != res[j].Measurement {
+			return res[i].Measurement < res[j].Measurement
+		}
+		return res[i].Field < res[j].Field
+	})
+	return res, nil
+}
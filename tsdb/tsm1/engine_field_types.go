@@ -0,0 +1,418 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+var blockTypeFieldTypes = map[byte]influxdb.FieldType{
+	BlockFloat64:  influxdb.FieldTypeFloat,
+	BlockInteger:  influxdb.FieldTypeInteger,
+	BlockUnsigned: influxdb.FieldTypeUnsigned,
+	BlockString:   influxdb.FieldTypeString,
+	BlockBoolean:  influxdb.FieldTypeBoolean,
+}
+
+// FieldTypeToBlockType returns the block type of the values of field type
+// typ, or BlockUndefined if typ is not a field type.
+func FieldTypeToBlockType(typ influxdb.FieldType) byte {
+	for b, t := range blockTypeFieldTypes {
+		if t == typ {
+			return b
+		}
+	}
+	return BlockUndefined
+}
+
+// FieldTypeConflicts returns the fields of the bucket with values of more
+// than one type, either because some of their series have blocks of more
+// than one type, or because their series are not all of the same type.
+func (e *Engine) FieldTypeConflicts(ctx context.Context, orgID, bucketID influxdb.ID) ([]*influxdb.FieldTypeConflict, error) {
+	orgBucket := tsdb.EncodeName(orgID, bucketID)
+	prefix := models.EscapeMeasurement(orgBucket[:])
+
+	types, err := e.keyTypes(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	type fieldKey struct{ measurement, field string }
+	fields := make(map[fieldKey]*influxdb.FieldTypeConflict)
+	masks := make(map[fieldKey]uint8)
+	for key, mask := range types {
+		seriesKey, field := SeriesAndFieldFromCompositeKey([]byte(key))
+		name, err := models.ParseMeasurement(seriesKey)
+		if err != nil {
+			e.logger.Error("Invalid series key in TSM index", zap.Error(err), zap.Binary("key", seriesKey))
+			continue
+		}
+
+		k := fieldKey{measurement: string(name), field: string(field)}
+		c, ok := fields[k]
+		if !ok {
+			c = &influxdb.FieldTypeConflict{Measurement: k.measurement, Field: k.field}
+			fields[k] = c
+		}
+		c.SeriesN++
+		if bits.OnesCount8(mask) > 1 {
+			c.ConflictingSeriesN++
+		}
+		masks[k] |= mask
+	}
+
+	var conflicts []*influxdb.FieldTypeConflict
+	for k, c := range fields {
+		mask := masks[k]
+		if bits.OnesCount8(mask) < 2 {
+			continue
+		}
+		for b, t := range blockTypeFieldTypes {
+			if mask&(1<<b) != 0 {
+				c.Types = append(c.Types, t)
+			}
+		}
+		sort.Slice(c.Types, func(i, j int) bool { return c.Types[i] < c.Types[j] })
+		conflicts = append(conflicts, c)
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Measurement != conflicts[j].Measurement {
+			return conflicts[i].Measurement < conflicts[j].Measurement
+		}
+		return conflicts[i].Field < conflicts[j].Field
+	})
+	return conflicts, nil
+}
+
+// keyTypes returns the keys beginning with prefix, in the TSM files and in
+// the cache, with the types of their blocks as a mask of 1<<type.
+func (e *Engine) keyTypes(ctx context.Context, prefix []byte) (map[string]uint8, error) {
+	stats, err := e.keyStats(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]uint8, len(stats))
+	for key, st := range stats {
+		types[key] = st.types
+	}
+	return types, nil
+}
+
+// keyStat describes the values of a key in the TSM files and in the cache.
+type keyStat struct {
+	// types is the mask of 1<<type of the types of the blocks of the key.
+	types uint8
+	// maxTime is the greatest timestamp of the values of the key.
+	maxTime int64
+}
+
+// keyStats returns the keys beginning with prefix, in the TSM files and in
+// the cache, with the types and the greatest timestamp of their values. The
+// timestamps are read from the index entries of the blocks, and may be
+// those of values deleted by tombstones.
+func (e *Engine) keyStats(ctx context.Context, prefix []byte) (map[string]*keyStat, error) {
+	stats := make(map[string]*keyStat)
+	stat := func(key string) *keyStat {
+		st, ok := stats[key]
+		if !ok {
+			st = &keyStat{maxTime: math.MinInt64}
+			stats[key] = st
+		}
+		return st
+	}
+
+	var err error
+	e.FileStore.ForEachFile(func(f TSMFile) bool {
+		// Check the context before reading each tsm file
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return false
+		default:
+		}
+		if !f.OverlapsKeyPrefixRange(prefix, prefix) {
+			return true
+		}
+
+		iter := f.Iterator(prefix)
+		for iter.Next() {
+			key := iter.Key()
+			if !bytes.HasPrefix(key, prefix) {
+				// end of prefix
+				break
+			}
+			st := stat(string(key))
+			st.types |= 1 << iter.Type()
+			for _, ie := range iter.Entries() {
+				if ie.MaxTime > st.maxTime {
+					st.maxTime = ie.MaxTime
+				}
+			}
+		}
+		err = iter.Err()
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prefixStr := string(prefix)
+	_ = e.Cache.ApplyEntryFn(func(key string, entry *entry) error {
+		if strings.HasPrefix(key, prefixStr) {
+			st := stat(key)
+			st.types |= 1 << entry.BlockType()
+			if t := entry.MaxTime(); t > st.maxTime {
+				st.maxTime = t
+			}
+		}
+		return nil
+	})
+	return stats, nil
+}
+
+// ConvertFieldType casts the values of field of measurement in the bucket
+// to typ, in every series of the field with values of another type. It
+// returns the number of series converted.
+//
+// The values of a series are read from the TSM files and the cache, and the
+// converted values replace them in the cache once they have been passed to
+// log, which should make them durable. progress is called after each series
+// with the number of series converted and the number to convert.
+//
+// A series is left untouched, and an error returned, if one of its values
+// cannot be cast, or if values of another type than typ are written to it
+// while it is converted. Level compactions are disabled until the
+// conversion is done, so that they do not write the values converted back.
+func (e *Engine) ConvertFieldType(ctx context.Context, orgID, bucketID influxdb.ID, measurement, field string, typ byte, log func(values map[string][]Value) error, progress func(done, total int)) (int, error) {
+	if _, ok := blockTypeFieldTypes[typ]; !ok {
+		return 0, fmt.Errorf("unknown block type %d", typ)
+	}
+
+	orgBucket := tsdb.EncodeName(orgID, bucketID)
+	orgBucketEsc := models.EscapeMeasurement(orgBucket[:])
+	mt := models.Tags{models.NewTag(models.MeasurementTagKeyBytes, []byte(measurement))}
+	prefix := mt.AppendHashKey(orgBucketEsc)
+
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	types, err := e.keyTypes(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	var keys []string
+	for key, mask := range types {
+		if _, f := SeriesAndFieldFromCompositeKey([]byte(key)); string(f) != field {
+			continue
+		}
+		if mask != 1<<typ {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if err := e.convertKey([]byte(key), typ, log); err != nil {
+			return i, err
+		}
+		if progress != nil {
+			progress(i+1, len(keys))
+		}
+	}
+	return len(keys), nil
+}
+
+// convertKey replaces the values of key with their cast to typ.
+func (e *Engine) convertKey(key []byte, typ byte, log func(values map[string][]Value) error) error {
+	// Files are ordered by generation, so the values of later files replace
+	// those of earlier ones with the same timestamp, as they do in queries.
+	var values Values
+	var err error
+	e.FileStore.ForEachFile(func(f TSMFile) bool {
+		if !f.Contains(key) {
+			return true
+		}
+		var vs Values
+		if vs, err = readAllValues(f, key); err != nil {
+			return false
+		}
+		values = values.Merge(vs)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	cached := e.Cache.Values(key)
+	if len(cached) > 0 && valueTypeToBlockType(valueType(cached[0])) != typ {
+		return fmt.Errorf("values of type %s are being written to %q", BlockTypeName(valueTypeToBlockType(valueType(cached[0]))), key)
+	}
+	values = values.Merge(cached)
+	if len(values) == 0 {
+		return nil
+	}
+
+	converted := make([]Value, len(values))
+	for i, v := range values {
+		if converted[i], err = castValue(v, typ); err != nil {
+			return fmt.Errorf("cannot convert value at %d of %q to %s: %v", v.UnixNano(), key, BlockTypeName(typ), err)
+		}
+	}
+
+	vs := map[string][]Value{string(key): converted}
+	if err := log(vs); err != nil {
+		return err
+	}
+	if err := e.FileStore.DeleteRange([][]byte{key}, math.MinInt64, math.MaxInt64); err != nil {
+		return err
+	}
+	// The duplicate policy of the bucket does not apply, since the values
+	// replace those stored.
+	return e.Cache.WriteMulti(vs)
+}
+
+// readAllValues returns the values of key in f, without the values deleted
+// by the tombstones of f.
+func readAllValues(f TSMFile, key []byte) (Values, error) {
+	entries, err := f.ReadEntries(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	tombstones := f.TombstoneRange(key, nil)
+
+	var values Values
+	for i := range entries {
+		vs, err := f.ReadAt(&entries[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tombstones {
+			vs = Values(vs).Exclude(t.Min, t.Max)
+		}
+		values = values.Merge(vs)
+	}
+	return values, nil
+}
+
+// castValue returns v cast to the values of block type typ.
+func castValue(v Value, typ byte) (Value, error) {
+	t := v.UnixNano()
+	switch typ {
+	case BlockFloat64:
+		switch x := v.Value().(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return NewFloatValue(t, float64(x)), nil
+		case uint64:
+			return NewFloatValue(t, float64(x)), nil
+		case bool:
+			if x {
+				return NewFloatValue(t, 1), nil
+			}
+			return NewFloatValue(t, 0), nil
+		case string:
+			f, err := strconv.ParseFloat(x, 64)
+			if err != nil {
+				return nil, err
+			}
+			return NewFloatValue(t, f), nil
+		}
+	case BlockInteger:
+		switch x := v.Value().(type) {
+		case float64:
+			if math.IsNaN(x) || x < math.MinInt64 || x >= math.MaxInt64 {
+				return nil, fmt.Errorf("%v is out of the range of integers", x)
+			}
+			return NewIntegerValue(t, int64(x)), nil
+		case int64:
+			return v, nil
+		case uint64:
+			if x > math.MaxInt64 {
+				return nil, fmt.Errorf("%d is out of the range of integers", x)
+			}
+			return NewIntegerValue(t, int64(x)), nil
+		case bool:
+			if x {
+				return NewIntegerValue(t, 1), nil
+			}
+			return NewIntegerValue(t, 0), nil
+		case string:
+			i, err := strconv.ParseInt(x, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return NewIntegerValue(t, i), nil
+		}
+	case BlockUnsigned:
+		switch x := v.Value().(type) {
+		case float64:
+			if math.IsNaN(x) || x < 0 || x >= math.MaxUint64 {
+				return nil, fmt.Errorf("%v is out of the range of unsigned integers", x)
+			}
+			return NewUnsignedValue(t, uint64(x)), nil
+		case int64:
+			if x < 0 {
+				return nil, fmt.Errorf("%d is out of the range of unsigned integers", x)
+			}
+			return NewUnsignedValue(t, uint64(x)), nil
+		case uint64:
+			return v, nil
+		case bool:
+			if x {
+				return NewUnsignedValue(t, 1), nil
+			}
+			return NewUnsignedValue(t, 0), nil
+		case string:
+			u, err := strconv.ParseUint(x, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return NewUnsignedValue(t, u), nil
+		}
+	case BlockString:
+		switch x := v.Value().(type) {
+		case float64:
+			return NewStringValue(t, strconv.FormatFloat(x, 'f', -1, 64)), nil
+		case int64:
+			return NewStringValue(t, strconv.FormatInt(x, 10)), nil
+		case uint64:
+			return NewStringValue(t, strconv.FormatUint(x, 10)), nil
+		case bool:
+			return NewStringValue(t, strconv.FormatBool(x)), nil
+		case string:
+			return v, nil
+		}
+	case BlockBoolean:
+		switch x := v.Value().(type) {
+		case float64:
+			return NewBooleanValue(t, x != 0), nil
+		case int64:
+			return NewBooleanValue(t, x != 0), nil
+		case uint64:
+			return NewBooleanValue(t, x != 0), nil
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(x)
+			if err != nil {
+				return nil, err
+			}
+			return NewBooleanValue(t, b), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot cast %T to %s", v.Value(), BlockTypeName(typ))
+}
@@ -0,0 +1,89 @@
+package tsm1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+func TestEngine_ConvertFieldType(t *testing.T) {
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	var (
+		org    influxdb.ID = 0x6000
+		bucket influxdb.ID = 0x6100
+		ctx                = context.Background()
+	)
+
+	// The type of the values of a field is only checked against the cache,
+	// so each type is written to a TSM file of its own.
+	e.MustWritePointsString(org, bucket, `
+cpu,host=a value=1i 10
+cpu,host=b value=2.5 10
+mem,host=a value=1i 10`)
+	e.MustWriteSnapshot()
+	e.MustWritePointsString(org, bucket, `
+cpu,host=a value=1.5 20
+cpu,host=b value=3.5 20
+mem,host=a value=2i 20`)
+	e.MustWriteSnapshot()
+
+	conflicts, err := e.FieldTypeConflicts(ctx, org, bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*influxdb.FieldTypeConflict{{
+		Measurement:        "cpu",
+		Field:              "value",
+		Types:              []influxdb.FieldType{influxdb.FieldTypeFloat, influxdb.FieldTypeInteger},
+		SeriesN:            2,
+		ConflictingSeriesN: 1,
+	}}
+	if !cmp.Equal(want, conflicts) {
+		t.Fatalf("unexpected conflicts -want/+got:\n%s", cmp.Diff(want, conflicts))
+	}
+
+	var logged []interface{}
+	n, err := e.ConvertFieldType(ctx, org, bucket, "cpu", "value", tsm1.BlockFloat64, func(values map[string][]tsm1.Value) error {
+		for _, vs := range values {
+			for _, v := range vs {
+				logged = append(logged, v.UnixNano(), v.Value())
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("got %d series converted, want 1", n)
+	}
+	wantValues := []interface{}{int64(10), 1.0, int64(20), 1.5}
+	if !cmp.Equal(wantValues, logged) {
+		t.Errorf("unexpected values -want/+got:\n%s", cmp.Diff(wantValues, logged))
+	}
+
+	e.MustWriteSnapshot()
+	if conflicts, err = e.FieldTypeConflicts(ctx, org, bucket); err != nil {
+		t.Fatal(err)
+	} else if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts once converted, got %v", conflicts)
+	}
+
+	// A string which is not a number cannot be cast.
+	e.MustWritePointsString(org, bucket, `mem,host=a value="n/a" 30`)
+	e.MustWriteSnapshot()
+	if _, err := e.ConvertFieldType(ctx, org, bucket, "mem", "value", tsm1.BlockInteger, func(map[string][]tsm1.Value) error { return nil }, nil); err == nil {
+		t.Error("expected a value which cannot be cast to fail the conversion")
+	}
+}
@@ -22,6 +22,7 @@ LOOP:
 		c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(floatBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values = values.Exclude(first.readMin, first.readMax)
@@ -95,6 +96,8 @@ LOOP:
 				c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(floatBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -158,6 +161,8 @@ LOOP:
 				c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(floatBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			v = excludeTombstonesFloatValues(c.trbuf, v)
@@ -208,6 +213,7 @@ LOOP:
 		c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(integerBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values = values.Exclude(first.readMin, first.readMax)
@@ -281,6 +287,8 @@ LOOP:
 				c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(integerBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -344,6 +352,8 @@ LOOP:
 				c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(integerBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			v = excludeTombstonesIntegerValues(c.trbuf, v)
@@ -394,6 +404,7 @@ LOOP:
 		c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values = values.Exclude(first.readMin, first.readMax)
@@ -467,6 +478,8 @@ LOOP:
 				c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -530,6 +543,8 @@ LOOP:
 				c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			v = excludeTombstonesUnsignedValues(c.trbuf, v)
@@ -580,6 +595,7 @@ LOOP:
 		c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(stringBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values = values.Exclude(first.readMin, first.readMax)
@@ -653,6 +669,8 @@ LOOP:
 				c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(stringBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -716,6 +734,8 @@ LOOP:
 				c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(stringBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			v = excludeTombstonesStringValues(c.trbuf, v)
@@ -766,6 +786,7 @@ LOOP:
 		c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values = values.Exclude(first.readMin, first.readMax)
@@ -839,6 +860,8 @@ LOOP:
 				c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -902,6 +925,8 @@ LOOP:
 				c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			v = excludeTombstonesBooleanValues(c.trbuf, v)
@@ -210,8 +210,9 @@ type FileStore struct {
 	dir                   string
 
 	files           []TSMFile
-	tsmMMAPWillNeed bool          // If true then the kernel will be advised MMAP_WILLNEED for TSM files.
-	openLimiter     limiter.Fixed // limit the number of concurrent opening TSM files.
+	tsmMMAPWillNeed bool             // If true then the kernel will be advised MMAP_WILLNEED for TSM files.
+	quarantine      *BlockQuarantine // If set then block checksums are verified when read.
+	openLimiter     limiter.Fixed    // limit the number of concurrent opening TSM files.
 
 	logger *zap.Logger // Logger to be used for important messages
 
@@ -223,6 +224,9 @@ type FileStore struct {
 	parseFileName ParseFileNameFunc
 
 	obs FileStoreObserver
+
+	// readOnly is true if corrupt TSM files are skipped rather than renamed.
+	readOnly bool
 }
 
 // FileStat holds information about a TSM file on disk.
@@ -278,6 +282,12 @@ func (f *FileStore) WithObserver(obs FileStoreObserver) {
 	f.obs = obs
 }
 
+// SetReadOnly sets whether the file store skips the corrupt TSM files it
+// opens instead of renaming them. It must be called before Open.
+func (f *FileStore) SetReadOnly(readOnly bool) {
+	f.readOnly = readOnly
+}
+
 func (f *FileStore) WithParseFileNameFunc(parseFileNameFunc ParseFileNameFunc) {
 	f.parseFileName = parseFileNameFunc
 }
@@ -295,6 +305,31 @@ func (f *FileStore) SetCurrentGenerationFunc(fn func() int) {
 func (f *FileStore) WithLogger(log *zap.Logger) {
 	f.logger = log.With(zap.String("service", "filestore"))
 	f.purger.logger = f.logger
+	if f.quarantine != nil {
+		f.quarantine.WithLogger(f.logger)
+	}
+}
+
+// SetVerifyChecksums sets whether the checksum of each block is verified when
+// it is read. Reads of blocks which fail verification return a
+// *BlockChecksumError, and the blocks are quarantined. It must be called
+// before Open.
+func (f *FileStore) SetVerifyChecksums(verify bool) {
+	if !verify {
+		f.quarantine = nil
+		return
+	}
+	f.quarantine = NewBlockQuarantine()
+	f.quarantine.WithLogger(f.logger)
+}
+
+// QuarantinedBlocks returns the blocks which failed checksum verification
+// since the file store was created, ordered by file and offset.
+func (f *FileStore) QuarantinedBlocks() []QuarantinedBlock {
+	if f.quarantine == nil {
+		return nil
+	}
+	return f.quarantine.Blocks()
 }
 
 // FileStoreStatistics keeps statistics about the file store.
@@ -669,6 +704,7 @@ func (f *FileStore) Open(ctx context.Context) error {
 			start := time.Now()
 			df, err := NewTSMReader(file,
 				WithMadviseWillNeed(f.tsmMMAPWillNeed),
+				WithBlockQuarantine(f.quarantine),
 				WithTSMReaderLogger(f.logger))
 			f.logger.Info("Opened file",
 				zap.String("path", file.Name()),
@@ -676,7 +712,14 @@ func (f *FileStore) Open(ctx context.Context) error {
 				zap.Duration("duration", time.Since(start)))
 
 			// If we are unable to read a TSM file then log the error, rename
-			// the file, and continue loading the shard without it.
+			// the file, and continue loading the shard without it. A read-only
+			// file store leaves the file as it is.
+			if err != nil && f.readOnly {
+				f.logger.Error("Cannot read corrupt tsm file, skipping", zap.String("path", file.Name()), zap.Int("id", idx), zap.Error(err))
+				file.Close()
+				readerC <- &res{}
+				return
+			}
 			if err != nil {
 				f.logger.Error("Cannot read corrupt tsm file, renaming", zap.String("path", file.Name()), zap.Int("id", idx), zap.Error(err))
 				if e := fs.RenameFile(file.Name(), file.Name()+"."+BadTSMFileExtension); e != nil {
@@ -787,6 +830,56 @@ func (f *FileStore) Read(key []byte, t int64) ([]Value, error) {
 	return nil, nil
 }
 
+// AppendTimestamps appends ts with the timestamps for key within [min, max]
+// that are stored in TSM files and have not been deleted by a tombstone.
+// It is the responsibility of the caller to sort and or deduplicate the slice.
+func (f *FileStore) AppendTimestamps(key []byte, min, max int64, ts []int64) ([]int64, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var (
+		entries    []IndexEntry
+		tombstones []TimeRange
+		values     []Value
+		err        error
+	)
+	for _, r := range f.files {
+		if !r.OverlapsTimeRange(min, max) || !r.Contains(key) {
+			continue
+		}
+
+		if entries, err = r.ReadEntries(key, entries[:0]); err != nil {
+			return nil, err
+		}
+		tombstones = r.TombstoneRange(key, tombstones[:0])
+
+		for i := range entries {
+			if !entries[i].OverlapsTimeRange(min, max) {
+				continue
+			}
+
+			if values, err = r.ReadAt(&entries[i], values[:0]); err != nil {
+				return nil, err
+			}
+
+		VALUES:
+			for _, v := range values {
+				t := v.UnixNano()
+				if t < min || t > max {
+					continue
+				}
+				for _, tr := range tombstones {
+					if tr.Overlaps(t, t) {
+						continue VALUES
+					}
+				}
+				ts = append(ts, t)
+			}
+		}
+	}
+	return ts, nil
+}
+
 func (f *FileStore) Cost(key []byte, min, max int64) query.IteratorCost {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
@@ -910,6 +1003,7 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 
 		tsm, err := NewTSMReader(fd,
 			WithMadviseWillNeed(f.tsmMMAPWillNeed),
+			WithBlockQuarantine(f.quarantine),
 			WithTSMReaderLogger(f.logger))
 		if err != nil {
 			return err
@@ -1142,12 +1236,15 @@ func (f *FileStore) cost(key []byte, min, max int64) query.IteratorCost {
 // locations returns the files and index blocks for a key and time.  ascending indicates
 // whether the key will be scan in ascending time order or descenging time order.
 // This function assumes the read-lock has been taken.
-func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
+//
+// Blocks whose values from t in the direction of the cursor are all deleted by
+// tombstones are skipped without being read, and counted in skipped.
+func (f *FileStore) locations(key []byte, t int64, ascending bool) (locations []*location, skipped int) {
 	var entries []IndexEntry
 	var err error
 	var trbuf []TimeRange
 
-	locations := make([]*location, 0, len(f.files))
+	locations = make([]*location, 0, len(f.files))
 	for _, fd := range f.files {
 		minTime, maxTime := fd.TimeRange()
 
@@ -1170,14 +1267,20 @@ func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
 			continue
 		}
 
-	LOOP:
 		for i := 0; i < len(entries); i++ {
 			ie := entries[i]
 
 			// Skip any blocks only contain values that are tombstoned.
-			for _, t := range trbuf {
-				if t.Min <= ie.MinTime && t.Max >= ie.MaxTime {
-					continue LOOP
+			if len(trbuf) > 0 {
+				min, max := ie.MinTime, ie.MaxTime
+				if ascending && t > min {
+					min = t
+				} else if !ascending && t < max {
+					max = t
+				}
+				if min <= max && timeRangesCover(trbuf, min, max) {
+					skipped++
+					continue
 				}
 			}
 
@@ -1211,7 +1314,25 @@ func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
 			locations = append(locations, location)
 		}
 	}
-	return locations
+	return locations, skipped
+}
+
+// timeRangesCover returns true if the union of ranges covers [min, max].
+func timeRangesCover(ranges []TimeRange, min, max int64) bool {
+	for {
+		extended := false
+		for _, r := range ranges {
+			if r.Min <= min && r.Max >= min {
+				if r.Max >= max {
+					return true
+				}
+				min, extended = r.Max+1, true
+			}
+		}
+		if !extended {
+			return false
+		}
+	}
 }
 
 // CreateSnapshot creates hardlinks for all tsm and tombstone files
@@ -1346,6 +1467,9 @@ type KeyCursor struct {
 	// decrement through the size of seeks slice.
 	pos       int
 	ascending bool
+
+	// stats counts the blocks skipped, decoded and merged by the cursor.
+	stats cursors.CursorStats
 }
 
 type location struct {
@@ -1398,11 +1522,11 @@ func (a ascLocations) Less(i, j int) bool {
 func newKeyCursor(ctx context.Context, fs *FileStore, key []byte, t int64, ascending bool) *KeyCursor {
 	c := &KeyCursor{
 		key:       key,
-		seeks:     fs.locations(key, t, ascending),
 		ctx:       ctx,
 		col:       metrics.GroupFromContext(ctx),
 		ascending: ascending,
 	}
+	c.seeks, c.stats.BlocksSkipped = fs.locations(key, t, ascending)
 
 	if ascending {
 		sort.Sort(ascLocations(c.seeks))
@@ -1431,6 +1555,11 @@ func (c *KeyCursor) Close() {
 	c.current = nil
 }
 
+// Stats returns the number of blocks the cursor skipped, decoded and merged.
+func (c *KeyCursor) Stats() cursors.CursorStats {
+	return c.stats
+}
+
 // seek positions the cursor at the given time.
 func (c *KeyCursor) seek(t int64) {
 	if len(c.seeks) == 0 {
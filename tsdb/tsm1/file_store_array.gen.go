@@ -26,6 +26,7 @@ LOOP:
 		c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(floatBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values.Exclude(first.readMin, first.readMax)
@@ -98,6 +99,8 @@ LOOP:
 				c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(floatBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -160,6 +163,8 @@ LOOP:
 				c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(floatBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			excludeTombstonesFloatArray(c.trbuf, v)
@@ -210,6 +215,7 @@ LOOP:
 		c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(integerBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values.Exclude(first.readMin, first.readMax)
@@ -282,6 +288,8 @@ LOOP:
 				c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(integerBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -344,6 +352,8 @@ LOOP:
 				c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(integerBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			excludeTombstonesIntegerArray(c.trbuf, v)
@@ -394,6 +404,7 @@ LOOP:
 		c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values.Exclude(first.readMin, first.readMax)
@@ -466,6 +477,8 @@ LOOP:
 				c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -528,6 +541,8 @@ LOOP:
 				c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			excludeTombstonesUnsignedArray(c.trbuf, v)
@@ -578,6 +593,7 @@ LOOP:
 		c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(stringBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values.Exclude(first.readMin, first.readMax)
@@ -650,6 +666,8 @@ LOOP:
 				c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(stringBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -712,6 +730,8 @@ LOOP:
 				c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(stringBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			excludeTombstonesStringArray(c.trbuf, v)
@@ -762,6 +782,7 @@ LOOP:
 		c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
 		c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(first.entry.Size))
 	}
+	c.stats.BlocksDecoded++
 
 	// Remove values we already read
 	values.Exclude(first.readMin, first.readMax)
@@ -834,6 +855,8 @@ LOOP:
 				c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
@@ -896,6 +919,8 @@ LOOP:
 				c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
 				c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(cur.entry.Size))
 			}
+			c.stats.BlocksDecoded++
+			c.stats.BlocksMerged++
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
 			excludeTombstonesBooleanArray(c.trbuf, v)
@@ -15,6 +15,7 @@ import (
 
 	"github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/pkg/fs"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
 	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
 )
 
@@ -2084,6 +2085,51 @@ func TestKeyCursor_TombstoneRange(t *testing.T) {
 	}
 }
 
+func TestKeyCursor_TombstoneRange_Union(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	fs := tsm1.NewFileStore(dir)
+
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 0.0), tsm1.NewValue(1, 1.0), tsm1.NewValue(2, 2.0), tsm1.NewValue(3, 3.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(4, 4.0)}},
+	}
+
+	files, err := newFiles(dir, data...)
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	fs.Replace(nil, files)
+
+	// Delete all of the block of the first file with two ranges, neither of
+	// which covers it by itself.
+	for _, tr := range []tsm1.TimeRange{{Min: 0, Max: 1}, {Min: 2, Max: 3}} {
+		if err := fs.DeleteRange([][]byte{[]byte("cpu")}, tr.Min, tr.Max); err != nil {
+			t.Fatalf("unexpected error delete range: %v", err)
+		}
+	}
+
+	buf := make([]tsm1.FloatValue, 1000)
+	c := fs.KeyCursor(context.Background(), []byte("cpu"), 0, true)
+	defer c.Close()
+
+	values, err := c.ReadFloatBlock(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading values: %v", err)
+	}
+	if got, exp := len(values), 1; got != exp {
+		t.Fatalf("value length mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := values[0].String(), data[1].values[0].String(); got != exp {
+		t.Fatalf("read value mismatch: got %v, exp %v", got, exp)
+	}
+
+	if got, exp := c.Stats(), (cursors.CursorStats{BlocksDecoded: 1, BlocksSkipped: 1}); got != exp {
+		t.Fatalf("stats mismatch: got %+v, exp %+v", got, exp)
+	}
+}
+
 func TestKeyCursor_TombstoneRange_PartialFirst(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -0,0 +1,12 @@
+package tsm1
+
+// fillUint64Generic sets every value of dst to v.
+//
+// It is the portable version of fillUint64, which stores several values at
+// once with SIMD instructions on amd64 and arm64. Float blocks of constant or
+// rarely changing values decode to long runs of the same value.
+func fillUint64Generic(dst []uint64, v uint64) {
+	for i := range dst {
+		dst[i] = v
+	}
+}
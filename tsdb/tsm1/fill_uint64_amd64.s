@@ -0,0 +1,37 @@
+// +build !purego
+
+#include "textflag.h"
+
+// func fillUint64(dst []uint64, v uint64)
+TEXT ·fillUint64(SB), NOSPLIT, $0-32
+	MOVQ dst_base+0(FP), SI
+	MOVQ dst_len+8(FP), CX
+	MOVQ v+24(FP), AX
+
+	MOVQ       AX, X0
+	PUNPCKLQDQ X0, X0 // X0 = [v, v]
+
+	MOVQ CX, BX
+	SHRQ $2, BX
+	JZ   pair
+
+loop:
+	MOVOU X0, (SI)
+	MOVOU X0, 16(SI)
+	ADDQ  $32, SI
+	DECQ  BX
+	JNZ   loop
+
+pair:
+	BTQ   $1, CX
+	JNC   tail
+	MOVOU X0, (SI)
+	ADDQ  $16, SI
+
+tail:
+	BTQ  $0, CX
+	JNC  done
+	MOVQ AX, (SI)
+
+done:
+	RET
@@ -0,0 +1,31 @@
+// +build !purego
+
+#include "textflag.h"
+
+// func fillUint64(dst []uint64, v uint64)
+TEXT ·fillUint64(SB), NOSPLIT, $0-32
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R1
+	MOVD v+24(FP), R2
+
+	VDUP R2, V0.D2 // V0 = [v, v]
+	VDUP R2, V1.D2 // V1 = [v, v]
+
+	LSR $2, R1, R3
+	CBZ R3, pair
+
+loop:
+	VST1.P [V0.D2, V1.D2], 32(R0)
+	SUB    $1, R3, R3
+	CBNZ   R3, loop
+
+pair:
+	TBZ    $1, R1, tail
+	VST1.P [V0.D2], 16(R0)
+
+tail:
+	TBZ  $0, R1, done
+	MOVD R2, (R0)
+
+done:
+	RET
@@ -0,0 +1,11 @@
+// +build amd64 arm64
+// +build !purego
+
+package tsm1
+
+// fillUint64 sets every value of dst to v. It stores two values per
+// instruction with SSE2 on amd64 and NEON on arm64, which every CPU of these
+// architectures supports.
+//
+//go:noescape
+func fillUint64(dst []uint64, v uint64)
@@ -0,0 +1,8 @@
+// +build !amd64,!arm64 purego
+
+package tsm1
+
+// fillUint64 sets every value of dst to v.
+func fillUint64(dst []uint64, v uint64) {
+	fillUint64Generic(dst, v)
+}
@@ -0,0 +1,43 @@
+package tsm1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFillUint64(t *testing.T) {
+	// Lengths which are not a multiple of 4 exercise the values stored after
+	// the values stored in groups.
+	for n := 0; n < 70; n++ {
+		want := make([]uint64, n+1)
+		fillUint64Generic(want[:n], 0xdeadbeefcafe)
+		got := make([]uint64, n+1)
+		fillUint64(got[:n], 0xdeadbeefcafe)
+
+		// The value after dst must be left alone.
+		if !cmp.Equal(got, want) {
+			t.Fatalf("unexpected values for n=%d -got/+want\n%s", n, cmp.Diff(got, want))
+		}
+	}
+}
+
+func BenchmarkFillUint64(b *testing.B) {
+	for _, size := range []int{4, 16, 63} {
+		dst := make([]uint64, size)
+
+		b.Run(fmt.Sprintf("generic/%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size * 8))
+			for i := 0; i < b.N; i++ {
+				fillUint64Generic(dst, uint64(i))
+			}
+		})
+		b.Run(fmt.Sprintf("simd/%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size * 8))
+			for i := 0; i < b.N; i++ {
+				fillUint64(dst, uint64(i))
+			}
+		})
+	}
+}
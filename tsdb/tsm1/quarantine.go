@@ -0,0 +1,157 @@
+package tsm1
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// BlockChecksumError is returned when reading a TSM block whose data no
+// longer matches the checksum written with it, for example after bit rot.
+// The block is quarantined, so later reads of it fail with the same error
+// without decoding it.
+type BlockChecksumError struct {
+	Block QuarantinedBlock
+}
+
+func (e *BlockChecksumError) Error() string {
+	return fmt.Sprintf("tsm1: block at offset %d of %s failed checksum verification and is quarantined", e.Block.Offset, e.Block.Path)
+}
+
+// QuarantinedBlock describes a TSM block which failed checksum verification.
+type QuarantinedBlock struct {
+	Path   string
+	Offset int64
+	Size   uint32
+
+	// Key is the series key and field of the block, or nil if it is no
+	// longer in the index of its file.
+	Key              []byte
+	MinTime, MaxTime int64
+
+	// Checksum is the checksum written with the block and Computed the
+	// checksum of its data when it was read.
+	Checksum, Computed uint32
+
+	QuarantinedAt time.Time
+
+	// Dropped is set once a compaction has rewritten the file of the block
+	// without it, so its points are lost and must be restored from backup.
+	Dropped bool
+}
+
+type quarantineKey struct {
+	path   string
+	offset int64
+}
+
+// BlockQuarantine records the TSM blocks which failed checksum verification
+// since it was created. It is safe for concurrent use.
+type BlockQuarantine struct {
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	blocks map[quarantineKey]QuarantinedBlock
+}
+
+// NewBlockQuarantine returns an empty BlockQuarantine.
+func NewBlockQuarantine() *BlockQuarantine {
+	return &BlockQuarantine{
+		logger: zap.NewNop(),
+		blocks: make(map[quarantineKey]QuarantinedBlock),
+	}
+}
+
+// WithLogger sets the logger the quarantined blocks are reported to.
+func (q *BlockQuarantine) WithLogger(log *zap.Logger) {
+	q.logger = log
+}
+
+// Blocks returns the quarantined blocks, ordered by file and offset.
+func (q *BlockQuarantine) Blocks() []QuarantinedBlock {
+	q.mu.RLock()
+	blocks := make([]QuarantinedBlock, 0, len(q.blocks))
+	for _, b := range q.blocks {
+		blocks = append(blocks, b)
+	}
+	q.mu.RUnlock()
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Path != blocks[j].Path {
+			return blocks[i].Path < blocks[j].Path
+		}
+		return blocks[i].Offset < blocks[j].Offset
+	})
+	return blocks
+}
+
+// find returns the error of the block at offset of path if it is quarantined,
+// otherwise nil.
+func (q *BlockQuarantine) find(path string, offset int64) error {
+	q.mu.RLock()
+	b, ok := q.blocks[quarantineKey{path: path, offset: offset}]
+	q.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return &BlockChecksumError{Block: b}
+}
+
+// add quarantines b, unless its block already is, and returns its error.
+func (q *BlockQuarantine) add(b QuarantinedBlock) error {
+	k := quarantineKey{path: b.Path, offset: b.Offset}
+
+	q.mu.Lock()
+	if prev, ok := q.blocks[k]; ok {
+		q.mu.Unlock()
+		return &BlockChecksumError{Block: prev}
+	}
+	q.blocks[k] = b
+	q.mu.Unlock()
+
+	q.logger.Error("Quarantined TSM block which failed checksum verification",
+		zap.String("path", b.Path),
+		zap.Int64("offset", b.Offset),
+		zap.ByteString("key", b.Key),
+		zap.Int64("min_time", b.MinTime),
+		zap.Int64("max_time", b.MaxTime),
+		zap.Uint32("checksum", b.Checksum),
+		zap.Uint32("computed", b.Computed))
+	return &BlockChecksumError{Block: b}
+}
+
+// drop marks b as dropped by a compaction.
+func (q *BlockQuarantine) drop(b QuarantinedBlock) {
+	k := quarantineKey{path: b.Path, offset: b.Offset}
+
+	q.mu.Lock()
+	if prev, ok := q.blocks[k]; ok {
+		b = prev
+	}
+	b.Dropped = true
+	q.blocks[k] = b
+	q.mu.Unlock()
+
+	q.logger.Warn("Dropped quarantined TSM block from compaction",
+		zap.String("path", b.Path),
+		zap.Int64("offset", b.Offset),
+		zap.ByteString("key", b.Key),
+		zap.Int64("min_time", b.MinTime),
+		zap.Int64("max_time", b.MaxTime))
+}
+
+// dropCorruptBlock reports whether err is the checksum error of a block of r
+// which failed verification. Compactions skip such blocks, which are marked
+// as dropped in the quarantine of r, rather than fail on them every time the
+// files are planned.
+func dropCorruptBlock(r *TSMReader, err error) bool {
+	cerr, ok := err.(*BlockChecksumError)
+	if !ok || r.quarantine == nil {
+		return false
+	}
+	r.quarantine.drop(cerr.Block)
+	return true
+}
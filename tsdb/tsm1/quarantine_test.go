@@ -0,0 +1,77 @@
+package tsm1
+
+import (
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+func TestTSMReader_BlockQuarantine(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+
+	w, err := NewTSMWriter(f)
+	fatalIfErr(t, "creating writer", err)
+	fatalIfErr(t, "writing cpu", w.Write([]byte("cpu"), []Value{NewValue(1, 1.0), NewValue(2, 2.0)}))
+	fatalIfErr(t, "writing mem", w.Write([]byte("mem"), []Value{NewValue(10, 3.0), NewValue(20, 4.0)}))
+	fatalIfErr(t, "writing index", w.WriteIndex())
+	fatalIfErr(t, "closing writer", w.Close())
+
+	// Flip the last byte of the block of mem, as bit rot would.
+	f, err = os.OpenFile(f.Name(), os.O_RDWR, 0666)
+	fatalIfErr(t, "opening", err)
+	r, err := NewTSMReader(f)
+	fatalIfErr(t, "creating reader", err)
+	entries, err := r.ReadEntries([]byte("mem"), nil)
+	fatalIfErr(t, "reading entries", err)
+	entry := entries[0]
+	fatalIfErr(t, "closing reader", r.Close())
+
+	f, err = os.OpenFile(f.Name(), os.O_RDWR, 0666)
+	fatalIfErr(t, "opening", err)
+	b := make([]byte, 1)
+	pos := entry.Offset + int64(entry.Size) - 1
+	_, err = f.ReadAt(b, pos)
+	fatalIfErr(t, "reading block", err)
+	b[0] ^= 0xff
+	_, err = f.WriteAt(b, pos)
+	fatalIfErr(t, "corrupting block", err)
+
+	q := NewBlockQuarantine()
+	r, err = NewTSMReader(f, WithBlockQuarantine(q))
+	fatalIfErr(t, "creating reader", err)
+	defer r.Close()
+
+	if _, err := r.ReadAll([]byte("cpu")); err != nil {
+		t.Fatalf("unexpected error reading the intact block: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		var a cursors.FloatArray
+		err := r.ReadFloatArrayBlockAt(&entry, &a)
+		cerr, ok := err.(*BlockChecksumError)
+		if !ok {
+			t.Fatalf("expected a checksum error reading the corrupt block, got %v", err)
+		}
+		if got, exp := string(cerr.Block.Key), "mem"; got != exp {
+			t.Fatalf("unexpected key: got %s, exp %s", got, exp)
+		}
+	}
+	if _, err := r.ReadAll([]byte("mem")); err == nil {
+		t.Fatal("expected an error reading all values of the corrupt block")
+	}
+	if _, _, err := r.ReadBytes(&entry, nil); err == nil {
+		t.Fatal("expected an error reading the bytes of the corrupt block")
+	}
+
+	blocks := q.Blocks()
+	if len(blocks) != 1 {
+		t.Fatalf("expected 1 quarantined block, got %d", len(blocks))
+	}
+	got := blocks[0]
+	if got.Path != f.Name() || got.Offset != entry.Offset || got.MinTime != 10 || got.MaxTime != 20 || got.Checksum == got.Computed {
+		t.Fatalf("unexpected quarantined block %+v", got)
+	}
+}
@@ -20,7 +20,8 @@ type TSMReader struct {
 	refsWG sync.WaitGroup
 
 	logger          *zap.Logger
-	madviseWillNeed bool // Hint to the kernel with MADV_WILLNEED.
+	madviseWillNeed bool             // Hint to the kernel with MADV_WILLNEED.
+	quarantine      *BlockQuarantine // Verify block checksums and quarantine corrupt blocks.
 	mu              sync.RWMutex
 
 	// accessor provides access and decoding of blocks for the reader.
@@ -57,6 +58,15 @@ var WithTSMReaderLogger = func(logger *zap.Logger) tsmReaderOption {
 	}
 }
 
+// WithBlockQuarantine is an option for verifying the checksum of each block
+// read, and recording the blocks which fail verification in q. Checksums are
+// not verified if q is nil.
+var WithBlockQuarantine = func(q *BlockQuarantine) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.quarantine = q
+	}
+}
+
 // NewTSMReader returns a new TSMReader from the given file.
 func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	t := &TSMReader{
@@ -76,6 +86,7 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 		logger:       t.logger,
 		f:            f,
 		mmapWillNeed: t.madviseWillNeed,
+		quarantine:   t.quarantine,
 	}
 
 	index, err := t.accessor.init()
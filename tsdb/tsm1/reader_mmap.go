@@ -3,9 +3,11 @@ package tsm1
 import (
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"os"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/pkg/fs"
 	"go.uber.org/zap"
@@ -20,6 +22,10 @@ type mmapAccessor struct {
 	logger       *zap.Logger
 	mmapWillNeed bool // If true then mmap advise value MADV_WILLNEED will be provided the kernel for b.
 
+	// quarantine, if set, records the blocks whose checksum does not match
+	// their data. Checksums are only verified when it is set.
+	quarantine *BlockQuarantine
+
 	mu    sync.RWMutex
 	b     []byte
 	f     *os.File
@@ -145,12 +151,11 @@ func (m *mmapAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, er
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
-		return nil, ErrTSMClosed
+	b, err := m.block(entry)
+	if err != nil {
+		return nil, err
 	}
-	//TODO: Validate checksum
-	var err error
-	values, err = DecodeBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	values, err = DecodeBlock(b, values)
 	if err != nil {
 		return nil, err
 	}
@@ -161,14 +166,17 @@ func (m *mmapAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, er
 func (m *mmapAccessor) readBytes(entry *IndexEntry, b []byte) (uint32, []byte, error) {
 	m.incAccess()
 
+	// The raw blocks are verified too, so that compactions do not rewrite
+	// corrupt data with a new, valid checksum.
 	m.mu.RLock()
-	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+	block, err := m.block(entry)
+	if err != nil {
 		m.mu.RUnlock()
-		return 0, nil, ErrTSMClosed
+		return 0, nil, err
 	}
 
 	// return the bytes after the 4 byte checksum
-	crc, block := binary.BigEndian.Uint32(m.b[entry.Offset:entry.Offset+4]), m.b[entry.Offset+4:entry.Offset+int64(entry.Size)]
+	crc := binary.BigEndian.Uint32(m.b[entry.Offset : entry.Offset+4])
 	m.mu.RUnlock()
 
 	return crc, block, nil
@@ -190,7 +198,7 @@ func (m *mmapAccessor) readAll(key []byte) ([]Value, error) {
 
 	var temp []Value
 	var values []Value
-	for _, block := range blocks {
+	for i, block := range blocks {
 		var skip bool
 		for _, t := range tombstones {
 			// Should we skip this block because it contains points that have been deleted
@@ -203,10 +211,12 @@ func (m *mmapAccessor) readAll(key []byte) ([]Value, error) {
 		if skip {
 			continue
 		}
-		//TODO: Validate checksum
+		b, err := m.block(&blocks[i])
+		if err != nil {
+			return nil, err
+		}
 		temp = temp[:0]
-		// The +4 is the 4 byte checksum length
-		temp, err = DecodeBlock(m.b[block.Offset+4:block.Offset+int64(block.Size)], temp)
+		temp, err = DecodeBlock(b, temp)
 		if err != nil {
 			return nil, err
 		}
@@ -222,6 +232,52 @@ func (m *mmapAccessor) readAll(key []byte) ([]Value, error) {
 	return values, nil
 }
 
+// block returns the data of the block of entry, after its 4 byte checksum.
+// If the accessor has a quarantine, the checksum is verified first and a
+// block which fails verification is quarantined. m.mu must be read locked.
+func (m *mmapAccessor) block(entry *IndexEntry) ([]byte, error) {
+	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+		return nil, ErrTSMClosed
+	}
+	b := m.b[entry.Offset+4 : entry.Offset+int64(entry.Size)]
+	if m.quarantine == nil {
+		return b, nil
+	}
+
+	if err := m.quarantine.find(m._path, entry.Offset); err != nil {
+		return nil, err
+	}
+	checksum := binary.BigEndian.Uint32(m.b[entry.Offset : entry.Offset+4])
+	if computed := crc32.ChecksumIEEE(b); computed != checksum {
+		return nil, m.quarantine.add(QuarantinedBlock{
+			Path:          m._path,
+			Offset:        entry.Offset,
+			Size:          entry.Size,
+			Key:           m.blockKey(entry.Offset),
+			MinTime:       entry.MinTime,
+			MaxTime:       entry.MaxTime,
+			Checksum:      checksum,
+			Computed:      computed,
+			QuarantinedAt: time.Now().UTC(),
+		})
+	}
+	return b, nil
+}
+
+// blockKey returns the key of the block at offset, or nil if the index has
+// none. The index is scanned, so it is only used for corrupt blocks.
+func (m *mmapAccessor) blockKey(offset int64) []byte {
+	iter := m.index.Iterator(nil)
+	for iter.Next() {
+		for _, e := range iter.Entries() {
+			if e.Offset == offset {
+				return append([]byte(nil), iter.Key()...)
+			}
+		}
+	}
+	return nil
+}
+
 func (m *mmapAccessor) path() string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -3,19 +3,27 @@ package tsm1
 // String encoding uses snappy compression to compress each string.  Each string is
 // appended to byte slice prefixed with a variable byte length followed by the string
 // bytes.  The bytes are compressed using snappy compressor and a 1 byte header is used
-// to indicate the type of encoding.
+// to indicate the type of encoding.  Blocks may also be compressed with zstd when they
+// are written for a bucket configured with CompressionZstd.
 
 import (
 	"encoding/binary"
 	"fmt"
+	"sync"
 
 	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
 )
 
 // Note: an uncompressed format is not yet implemented.
 
-// stringCompressedSnappy is a compressed encoding using Snappy compression
-const stringCompressedSnappy = 1
+const (
+	// stringCompressedSnappy is a compressed encoding using Snappy compression
+	stringCompressedSnappy = 1
+
+	// stringCompressedZstd is a compressed encoding using zstd compression
+	stringCompressedZstd = 2
+)
 
 // StringEncoder encodes multiple strings into a byte slice.
 type StringEncoder struct {
@@ -68,12 +76,11 @@ type StringDecoder struct {
 // SetBytes initializes the decoder with bytes to read from.
 // This must be called before calling any other method.
 func (e *StringDecoder) SetBytes(b []byte) error {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
+	// First byte stores the encoding type.
 	var data []byte
 	if len(b) > 0 {
 		var err error
-		data, err = snappy.Decode(nil, b[1:])
+		data, err = decompressStrings(b)
 		if err != nil {
 			return fmt.Errorf("failed to decode string block: %v", err.Error())
 		}
@@ -127,3 +134,55 @@ func (e *StringDecoder) Read() string {
 func (e *StringDecoder) Error() error {
 	return e.err
 }
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+// initZstd creates the zstd encoder and decoder shared by all string blocks.
+// They are created on first use as the decoder starts goroutines.
+func initZstd() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+// compressStrings compresses the encoded strings in data with codec and
+// appends the result, prefixed with the 1 byte header, to dst.
+func compressStrings(dst []byte, data []byte, codec CompressionCodec) ([]byte, error) {
+	switch codec {
+	case CompressionZstd:
+		if err := initZstd(); err != nil {
+			return nil, err
+		}
+		dst = append(dst, stringCompressedZstd<<4)
+		return zstdEncoder.EncodeAll(data, dst), nil
+	default:
+		dst = append(dst, stringCompressedSnappy<<4)
+		return append(dst, snappy.Encode(nil, data)...), nil
+	}
+}
+
+// decompressStrings returns the encoded strings of b, which is prefixed with
+// the 1 byte header. The returned slice is always newly allocated, as the
+// decoded strings may reference it.
+func decompressStrings(b []byte) ([]byte, error) {
+	switch b[0] >> 4 {
+	case stringCompressedSnappy:
+		return snappy.Decode(nil, b[1:])
+	case stringCompressedZstd:
+		if err := initZstd(); err != nil {
+			return nil, err
+		}
+		return zstdDecoder.DecodeAll(b[1:], nil)
+	default:
+		return nil, fmt.Errorf("unknown string encoding %d", b[0]>>4)
+	}
+}
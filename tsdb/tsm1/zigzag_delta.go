@@ -0,0 +1,14 @@
+package tsm1
+
+// zigZagDeltaDecodeGeneric replaces the zig zag encoded deltas of dst with
+// their running sum, starting from prev.
+//
+// It is the portable version of zigZagDeltaDecode, which decodes several
+// values at once with SIMD instructions on amd64 and arm64. Decoding the
+// deltas of integer blocks dominates the CPU time of large range scans.
+func zigZagDeltaDecodeGeneric(dst []uint64, prev int64) {
+	for i, v := range dst {
+		prev += ZigZagDecode(v)
+		dst[i] = uint64(prev)
+	}
+}
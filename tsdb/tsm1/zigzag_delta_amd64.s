@@ -0,0 +1,59 @@
+// +build !purego
+
+#include "textflag.h"
+
+// func zigZagDeltaDecode(dst []uint64, prev int64)
+TEXT ·zigZagDeltaDecode(SB), NOSPLIT, $0-32
+	MOVQ dst_base+0(FP), SI
+	MOVQ dst_len+8(FP), CX
+	MOVQ prev+24(FP), AX
+
+	MOVQ       AX, X4
+	PUNPCKLQDQ X4, X4 // X4 = [prev, prev]
+	MOVQ       $1, DX
+	MOVQ       DX, X5
+	PUNPCKLQDQ X5, X5 // X5 = [1, 1]
+	PXOR       X6, X6 // X6 = [0, 0]
+
+	MOVQ CX, BX
+	SHRQ $1, BX
+	JZ   tail
+
+loop:
+	MOVOU (SI), X0 // X0 = [a, b]
+
+	// zig zag decode both lanes: (v >> 1) ^ -(v & 1)
+	MOVO  X0, X1
+	PAND  X5, X1
+	MOVO  X6, X2
+	PSUBQ X1, X2
+	PSRLQ $1, X0
+	PXOR  X2, X0
+
+	// prefix sum of the lanes plus the running sum
+	MOVO   X0, X1
+	PSLLDQ $8, X1 // X1 = [0, a]
+	PADDQ  X1, X0 // X0 = [a, a+b]
+	PADDQ  X4, X0
+	MOVOU  X0, (SI)
+	PSHUFD $0xee, X0, X4 // broadcast the running sum
+
+	ADDQ $16, SI
+	DECQ BX
+	JNZ  loop
+
+tail:
+	ANDQ $1, CX
+	JZ   done
+	MOVQ (SI), DX
+	MOVQ DX, R8
+	SHRQ $1, R8
+	ANDQ $1, DX
+	NEGQ DX
+	XORQ R8, DX
+	MOVQ X4, AX
+	ADDQ AX, DX
+	MOVQ DX, (SI)
+
+done:
+	RET
@@ -0,0 +1,50 @@
+// +build !purego
+
+#include "textflag.h"
+
+// func zigZagDeltaDecode(dst []uint64, prev int64)
+TEXT ·zigZagDeltaDecode(SB), NOSPLIT, $0-32
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R1
+	MOVD prev+24(FP), R2
+
+	VDUP R2, V4.D2              // V4 = [prev, prev]
+	MOVD $1, R3
+	VDUP R3, V5.D2              // V5 = [1, 1]
+	VEOR V6.B16, V6.B16, V6.B16 // V6 = [0, 0]
+
+	LSR $1, R1, R4
+	CBZ R4, tail
+
+loop:
+	VLD1 (R0), [V0.D2] // V0 = [a, b]
+
+	// zig zag decode both lanes: (v >> 1) ^ -(v & 1)
+	VAND  V5.B16, V0.B16, V1.B16
+	VSUB  V1.D2, V6.D2, V2.D2
+	VUSHR $1, V0.D2, V0.D2
+	VEOR  V2.B16, V0.B16, V0.B16
+
+	// prefix sum of the lanes plus the running sum
+	VEXT   $8, V0.B16, V6.B16, V1.B16 // V1 = [0, a]
+	VADD   V1.D2, V0.D2, V0.D2        // V0 = [a, a+b]
+	VADD   V4.D2, V0.D2, V0.D2
+	VST1.P [V0.D2], 16(R0)
+	VDUP   V0.D[1], V4.D2 // broadcast the running sum
+
+	SUB  $1, R4, R4
+	CBNZ R4, loop
+
+tail:
+	TBZ  $0, R1, done
+	MOVD (R0), R5
+	LSR  $1, R5, R6
+	AND  $1, R5, R5
+	NEG  R5, R5
+	EOR  R6, R5, R5
+	VMOV V4.D[0], R7
+	ADD  R7, R5, R5
+	MOVD R5, (R0)
+
+done:
+	RET
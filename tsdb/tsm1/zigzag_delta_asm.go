@@ -0,0 +1,12 @@
+// +build amd64 arm64
+// +build !purego
+
+package tsm1
+
+// zigZagDeltaDecode replaces the zig zag encoded deltas of dst with their
+// running sum, starting from prev. It decodes two deltas per instruction with
+// SSE2 on amd64 and NEON on arm64, which every CPU of these architectures
+// supports.
+//
+//go:noescape
+func zigZagDeltaDecode(dst []uint64, prev int64)
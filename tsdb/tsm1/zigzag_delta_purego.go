@@ -0,0 +1,9 @@
+// +build !amd64,!arm64 purego
+
+package tsm1
+
+// zigZagDeltaDecode replaces the zig zag encoded deltas of dst with their
+// running sum, starting from prev.
+func zigZagDeltaDecode(dst []uint64, prev int64) {
+	zigZagDeltaDecodeGeneric(dst, prev)
+}
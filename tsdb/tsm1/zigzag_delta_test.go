@@ -0,0 +1,58 @@
+package tsm1
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestZigZagDeltaDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	// Odd and even lengths exercise the values decoded one at a time after
+	// the values decoded in pairs.
+	for n := 0; n < 70; n++ {
+		for _, bits := range []uint{1, 8, 32, 64} {
+			src := make([]uint64, n)
+			for i := range src {
+				src[i] = rng.Uint64() >> (64 - bits)
+			}
+			prev := int64(rng.Uint64())
+
+			want := append([]uint64(nil), src...)
+			zigZagDeltaDecodeGeneric(want, prev)
+			got := append([]uint64(nil), src...)
+			zigZagDeltaDecode(got, prev)
+
+			if !cmp.Equal(got, want) {
+				t.Fatalf("unexpected values for n=%d, bits=%d -got/+want\n%s", n, bits, cmp.Diff(got, want))
+			}
+		}
+	}
+}
+
+func BenchmarkZigZagDeltaDecode(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		src := make([]uint64, size)
+		for i := range src {
+			src[i] = ZigZagEncode(int64(rand.Intn(1000) - 500))
+		}
+		dst := make([]uint64, size)
+
+		b.Run(fmt.Sprintf("generic/%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size * 8))
+			for i := 0; i < b.N; i++ {
+				copy(dst, src)
+				zigZagDeltaDecodeGeneric(dst, 0)
+			}
+		})
+		b.Run(fmt.Sprintf("simd/%d", size), func(b *testing.B) {
+			b.SetBytes(int64(size * 8))
+			for i := 0; i < b.N; i++ {
+				copy(dst, src)
+				zigZagDeltaDecode(dst, 0)
+			}
+		})
+	}
+}
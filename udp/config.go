@@ -0,0 +1,122 @@
+package udp
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+const (
+	// DefaultBatchSize is the default number of points written at once.
+	DefaultBatchSize = 5000
+
+	// DefaultBatchPending is the default number of batches which may wait to
+	// be written before points are dropped.
+	DefaultBatchPending = 10
+
+	// DefaultBatchTimeout is the default time a point waits for its batch to
+	// fill before the batch is written.
+	DefaultBatchTimeout = time.Second
+
+	// DefaultPrecision is the default precision of the timestamps of points.
+	DefaultPrecision = "ns"
+)
+
+// Config configures a Listener.
+type Config struct {
+	// BindAddress is the address the listener binds to.
+	BindAddress string
+
+	// BucketID is the bucket points are written to.
+	BucketID influxdb.ID
+
+	// BatchSize is the number of points written at once.
+	BatchSize int
+
+	// BatchPending is the number of batches which may wait to be written.
+	// Points received while as many batches wait are dropped.
+	BatchPending int
+
+	// BatchTimeout is the longest time a point waits for its batch to fill
+	// before the batch is written.
+	BatchTimeout time.Duration
+
+	// Precision is the precision of the timestamps of points: "ns", "us",
+	// "ms" or "s".
+	Precision string
+
+	// ReadBuffer is the size of the receive buffer of the socket in bytes.
+	// If 0, the default of the operating system is used.
+	ReadBuffer int
+}
+
+// ParseConfig parses the configuration of a listener in the form
+// "udp://<bind address>?bucket=<bucket id>", with optional
+// "batch-size=<points>", "batch-pending=<batches>",
+// "batch-timeout=<duration>", "precision=<ns|us|ms|s>" and
+// "read-buffer=<bytes>" parameters.
+func ParseConfig(s string) (Config, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid udp listener %q: %v", s, err)
+	}
+	if u.Scheme != "udp" {
+		return Config{}, fmt.Errorf("invalid udp listener %q: expected udp://", s)
+	}
+
+	c := Config{
+		BindAddress:  u.Host,
+		BatchSize:    DefaultBatchSize,
+		BatchPending: DefaultBatchPending,
+		BatchTimeout: DefaultBatchTimeout,
+		Precision:    DefaultPrecision,
+	}
+
+	q := u.Query()
+	id, err := influxdb.IDFromString(q.Get("bucket"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid bucket ID for udp listener %q: %v", s, err)
+	}
+	c.BucketID = *id
+
+	positive := func(name string, dst *int) error {
+		v := q.Get(name)
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid %s %q for udp listener %q", name, v, s)
+		}
+		*dst = n
+		return nil
+	}
+	if err := positive("batch-size", &c.BatchSize); err != nil {
+		return Config{}, err
+	}
+	if err := positive("batch-pending", &c.BatchPending); err != nil {
+		return Config{}, err
+	}
+	if err := positive("read-buffer", &c.ReadBuffer); err != nil {
+		return Config{}, err
+	}
+
+	if v := q.Get("batch-timeout"); v != "" {
+		if c.BatchTimeout, err = time.ParseDuration(v); err != nil || c.BatchTimeout <= 0 {
+			return Config{}, fmt.Errorf("invalid batch-timeout %q for udp listener %q", v, s)
+		}
+	}
+
+	if v := q.Get("precision"); v != "" {
+		switch v {
+		case "ns", "us", "ms", "s":
+			c.Precision = v
+		default:
+			return Config{}, fmt.Errorf("invalid precision %q for udp listener %q", v, s)
+		}
+	}
+	return c, nil
+}
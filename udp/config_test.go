@@ -0,0 +1,69 @@
+package udp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Config
+	}{
+		{
+			s: "udp://:8089?bucket=020f755c3c082000",
+			want: Config{
+				BindAddress:  ":8089",
+				BucketID:     influxdb.ID(0x020f755c3c082000),
+				BatchSize:    DefaultBatchSize,
+				BatchPending: DefaultBatchPending,
+				BatchTimeout: DefaultBatchTimeout,
+				Precision:    DefaultPrecision,
+			},
+		},
+		{
+			s: "udp://127.0.0.1:8089?bucket=020f755c3c082000&batch-size=100&batch-pending=2&batch-timeout=10ms&precision=s&read-buffer=8388608",
+			want: Config{
+				BindAddress:  "127.0.0.1:8089",
+				BucketID:     influxdb.ID(0x020f755c3c082000),
+				BatchSize:    100,
+				BatchPending: 2,
+				BatchTimeout: 10 * time.Millisecond,
+				Precision:    "s",
+				ReadBuffer:   8388608,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseConfig(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	for _, s := range []string{
+		"tcp://:8089?bucket=020f755c3c082000",
+		"udp://:8089",
+		"udp://:8089?bucket=020f755c3c082000&batch-size=0",
+		"udp://:8089?bucket=020f755c3c082000&batch-pending=many",
+		"udp://:8089?bucket=020f755c3c082000&batch-timeout=0s",
+		"udp://:8089?bucket=020f755c3c082000&precision=h",
+		"udp://:8089?bucket=020f755c3c082000&read-buffer=-1",
+	} {
+		t.Run(s, func(t *testing.T) {
+			if _, err := ParseConfig(s); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+// Package udp implements listeners of line protocol over UDP, which batch the
+// points they receive and write them to a bucket, for emitters which cannot
+// afford the overhead of writing over HTTP.
+package udp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// maxPacketSize is the maximum size of a UDP packet.
+const maxPacketSize = 64 * 1024
+
+// The reasons points are dropped for.
+const (
+	dropQueueFull = "queue_full"
+	dropBucket    = "bucket"
+	dropWrite     = "write"
+)
+
+// BucketFinder finds the bucket points are written to.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// Listener receives line protocol over UDP. Points are batched and written
+// to the bucket of the config once a batch is full or has waited for the
+// batch timeout. UDP offers no back pressure, so points which cannot be
+// written are dropped and counted rather than blocking the socket.
+type Listener struct {
+	log     *zap.Logger
+	config  Config
+	writer  storage.PointsWriter
+	buckets BucketFinder
+
+	mu      sync.Mutex
+	batch   []models.Point
+	started time.Time // when the first point of batch was received
+
+	batches chan []models.Point
+
+	packetConn net.PacketConn
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	writes sync.WaitGroup
+
+	packets        prometheus.Counter
+	parseErrors    prometheus.Counter
+	pointsReceived prometheus.Counter
+	pointsWritten  prometheus.Counter
+	batchesWritten prometheus.Counter
+	pointsDropped  *prometheus.CounterVec
+}
+
+// NewListener returns a Listener which writes the points it receives to w.
+// It must be opened before use.
+func NewListener(log *zap.Logger, config Config, w storage.PointsWriter, buckets BucketFinder) *Listener {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.BatchPending <= 0 {
+		config.BatchPending = DefaultBatchPending
+	}
+	if config.BatchTimeout <= 0 {
+		config.BatchTimeout = DefaultBatchTimeout
+	}
+	if config.Precision == "" {
+		config.Precision = DefaultPrecision
+	}
+
+	const namespace = "udp"
+	labels := prometheus.Labels{
+		"bind_address": config.BindAddress,
+		"bucket":       config.BucketID.String(),
+	}
+	return &Listener{
+		log:     log,
+		config:  config,
+		writer:  w,
+		buckets: buckets,
+		batches: make(chan []models.Point, config.BatchPending),
+
+		packets: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "packets_total",
+			Help:        "Number of packets received",
+			ConstLabels: labels,
+		}),
+		parseErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "parse_errors_total",
+			Help:        "Number of packets received with lines which failed to parse",
+			ConstLabels: labels,
+		}),
+		pointsReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "points_received_total",
+			Help:        "Number of points parsed from the packets received",
+			ConstLabels: labels,
+		}),
+		pointsWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "points_written_total",
+			Help:        "Number of points written to the bucket",
+			ConstLabels: labels,
+		}),
+		batchesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "batches_written_total",
+			Help:        "Number of batches of points written to the bucket",
+			ConstLabels: labels,
+		}),
+		pointsDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "points_dropped_total",
+			Help:        "Number of points dropped, by the reason they were dropped for",
+			ConstLabels: labels,
+		}, []string{"reason"}),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the listener.
+func (l *Listener) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		l.packets,
+		l.parseErrors,
+		l.pointsReceived,
+		l.pointsWritten,
+		l.batchesWritten,
+		l.pointsDropped,
+	}
+}
+
+// Open binds the listener and starts receiving and writing points.
+func (l *Listener) Open(ctx context.Context) error {
+	conn, err := net.ListenPacket("udp", l.config.BindAddress)
+	if err != nil {
+		return err
+	}
+	if l.config.ReadBuffer > 0 {
+		if uc, ok := conn.(*net.UDPConn); ok {
+			if err := uc.SetReadBuffer(l.config.ReadBuffer); err != nil {
+				conn.Close()
+				return err
+			}
+		}
+	}
+	l.packetConn = conn
+
+	ctx, l.cancel = context.WithCancel(context.Background())
+	l.wg.Add(2)
+	go func() {
+		defer l.wg.Done()
+		l.serve(ctx)
+	}()
+	go func() {
+		defer l.wg.Done()
+		l.run(ctx)
+	}()
+
+	l.writes.Add(1)
+	go func() {
+		defer l.writes.Done()
+		l.writeBatches()
+	}()
+	return nil
+}
+
+// Addr returns the address the listener is bound to.
+func (l *Listener) Addr() net.Addr {
+	if l.packetConn != nil {
+		return l.packetConn.LocalAddr()
+	}
+	return nil
+}
+
+// Close stops receiving points and writes the points received before it was
+// closed.
+func (l *Listener) Close() error {
+	if l.cancel == nil {
+		return nil
+	}
+	l.cancel()
+	err := l.packetConn.Close()
+	l.wg.Wait()
+
+	l.mu.Lock()
+	if len(l.batch) > 0 {
+		l.batches <- l.batch
+		l.batch = nil
+	}
+	l.mu.Unlock()
+	close(l.batches)
+	l.writes.Wait()
+	return err
+}
+
+func (l *Listener) serve(ctx context.Context) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := l.packetConn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			l.log.Debug("Failed to read UDP packet", zap.Error(err))
+			continue
+		}
+		l.packets.Inc()
+
+		// Points refer to the data they are parsed from, which buf is
+		// reused for.
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		points, err := models.ParsePointsWithPrecision(data, nil, time.Now().UTC(), l.config.Precision)
+		if err != nil {
+			// The points of the lines which do parse are still written.
+			l.log.Debug("Failed to parse UDP packet", zap.Error(err))
+			l.parseErrors.Inc()
+		}
+		if len(points) == 0 {
+			continue
+		}
+		l.pointsReceived.Add(float64(len(points)))
+
+		l.mu.Lock()
+		if len(l.batch) == 0 {
+			l.started = time.Now()
+		}
+		l.batch = append(l.batch, points...)
+		if len(l.batch) >= l.config.BatchSize {
+			l.enqueue()
+		}
+		l.mu.Unlock()
+	}
+}
+
+// run writes batches which have waited for the batch timeout without
+// filling.
+func (l *Listener) run(ctx context.Context) {
+	// Batches are checked twice per timeout, so none waits more than half
+	// as long again as the timeout.
+	interval := l.config.BatchTimeout / 2
+	if interval <= 0 {
+		interval = l.config.BatchTimeout
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			if len(l.batch) > 0 && time.Since(l.started) >= l.config.BatchTimeout {
+				l.enqueue()
+			}
+			l.mu.Unlock()
+		}
+	}
+}
+
+// enqueue queues the current batch to be written, dropping it if as many
+// batches as are allowed to be pending are already queued. l.mu must be
+// held.
+func (l *Listener) enqueue() {
+	select {
+	case l.batches <- l.batch:
+	default:
+		l.pointsDropped.WithLabelValues(dropQueueFull).Add(float64(len(l.batch)))
+	}
+	l.batch = nil
+}
+
+func (l *Listener) writeBatches() {
+	for batch := range l.batches {
+		l.write(context.Background(), batch)
+	}
+}
+
+// write writes a batch of points. Points which cannot be written are dropped.
+func (l *Listener) write(ctx context.Context, points []models.Point) {
+	// The organization is looked up on every write so that points are
+	// written once a missing bucket is created.
+	bucket, err := l.buckets.FindBucketByID(ctx, l.config.BucketID)
+	if err != nil {
+		l.log.Warn("Failed to find UDP bucket, dropping points",
+			zap.Stringer("bucket_id", l.config.BucketID), zap.Int("points", len(points)), zap.Error(err))
+		l.pointsDropped.WithLabelValues(dropBucket).Add(float64(len(points)))
+		return
+	}
+
+	n := len(points)
+	points, err = tsdb.ExplodePoints(bucket.OrgID, bucket.ID, points)
+	if err == nil {
+		err = l.writer.WritePoints(ctx, points)
+	}
+	if err != nil {
+		l.log.Warn("Failed to write UDP points", zap.Int("points", n), zap.Error(err))
+		l.pointsDropped.WithLabelValues(dropWrite).Add(float64(n))
+		return
+	}
+	l.pointsWritten.Add(float64(n))
+	l.batchesWritten.Inc()
+}
@@ -0,0 +1,146 @@
+package udp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
+)
+
+type bucketFinder map[influxdb.ID]*influxdb.Bucket
+
+func (f bucketFinder) FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	if b, ok := f[id]; ok {
+		return b, nil
+	}
+	return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+}
+
+func TestListener(t *testing.T) {
+	const (
+		orgID    = influxdb.ID(1)
+		bucketID = influxdb.ID(2)
+	)
+	buckets := bucketFinder{bucketID: {ID: bucketID, OrgID: orgID}}
+
+	w := &mock.PointsWriter{}
+	l := NewListener(zaptest.NewLogger(t), Config{
+		BindAddress:  "127.0.0.1:0",
+		BucketID:     bucketID,
+		BatchSize:    2,
+		BatchTimeout: time.Hour,
+		Precision:    "s",
+	}, w, buckets)
+	if err := l.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("udp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("cpu,host=a value=1 10\ncpu,host=b value=2 10\ninvalid\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The batch is full, so it is written without waiting for the timeout.
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(l.pointsWritten) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %v points written, want 2", testutil.ToFloat64(l.pointsWritten))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(l.parseErrors); got != 1 {
+		t.Errorf("got %v parse errors, want 1", got)
+	}
+
+	// A point is exploded into a point per field.
+	if len(w.Points) != 2 {
+		t.Fatalf("got %d points, want 2", len(w.Points))
+	}
+	p := w.Points[0]
+	wantName := tsdb.EncodeName(orgID, bucketID)
+	if string(p.Name()) != string(wantName[:]) {
+		t.Fatalf("unexpected name %q", p.Name())
+	}
+	if got := p.Tags().GetString(models.MeasurementTagKey); got != "cpu" {
+		t.Fatalf("unexpected measurement %q", got)
+	}
+	if got := p.Time(); !got.Equal(time.Unix(10, 0)) {
+		t.Fatalf("unexpected time %v", got)
+	}
+}
+
+func TestListener_BatchTimeout(t *testing.T) {
+	buckets := bucketFinder{2: {ID: 2, OrgID: 1}}
+	w := &mock.PointsWriter{}
+	l := NewListener(zaptest.NewLogger(t), Config{
+		BindAddress:  "127.0.0.1:0",
+		BucketID:     2,
+		BatchSize:    100,
+		BatchTimeout: 10 * time.Millisecond,
+	}, w, buckets)
+	if err := l.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	conn, err := net.Dial("udp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("cpu value=1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(l.batchesWritten) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the batch to be written after the batch timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestListener_MissingBucket(t *testing.T) {
+	w := &mock.PointsWriter{}
+	l := NewListener(zaptest.NewLogger(t), Config{
+		BindAddress: "127.0.0.1:0",
+		BucketID:    influxdb.ID(2),
+	}, w, bucketFinder{})
+	if err := l.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := models.ParsePointsString("cpu value=1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.mu.Lock()
+	l.batch = points
+	l.mu.Unlock()
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.Points) != 0 {
+		t.Fatalf("got %d points, want 0", len(w.Points))
+	}
+	if got := testutil.ToFloat64(l.pointsDropped.WithLabelValues(dropBucket)); got != 1 {
+		t.Fatalf("got %v points dropped, want 1", got)
+	}
+}
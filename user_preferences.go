@@ -0,0 +1,165 @@
+package influxdb
+
+import (
+	"context"
+	"net/mail"
+	"time"
+)
+
+const (
+	OpFindUserPreferences   = "FindUserPreferences"
+	OpUpdateUserPreferences = "UpdateUserPreferences"
+)
+
+// UserPreferencesService manages the preferences of users, which are stored
+// server-side so they follow a user across browsers and may be provisioned.
+type UserPreferencesService interface {
+	// FindUserPreferences returns the preferences of a user. A user who has
+	// not set any preference has the zero value preferences.
+	FindUserPreferences(ctx context.Context, userID ID) (*UserPreferences, error)
+
+	// UpdateUserPreferences updates the preferences of a user with changeset.
+	// Returns the new preferences after update.
+	UpdateUserPreferences(ctx context.Context, userID ID, upd UserPreferencesUpdate) (*UserPreferences, error)
+}
+
+// UIDensity is the density of the user interface.
+type UIDensity string
+
+const (
+	// UIDensityComfortable is the default density of the user interface.
+	UIDensityComfortable UIDensity = "comfortable"
+	// UIDensityCompact shows more on screen with less spacing.
+	UIDensityCompact UIDensity = "compact"
+)
+
+// Valid returns an error if the density is unknown. The empty density is
+// the default of the user interface.
+func (d UIDensity) Valid() error {
+	switch d {
+	case "", UIDensityComfortable, UIDensityCompact:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  "ui density must be one of comfortable or compact",
+	}
+}
+
+// DigestFrequency is how often a digest is sent.
+type DigestFrequency string
+
+const (
+	// DigestNever disables a digest. It is the default frequency.
+	DigestNever  DigestFrequency = "never"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// AlertDigest describes the emails a user receives summarizing the alerts
+// of the organizations they belong to.
+type AlertDigest struct {
+	Frequency DigestFrequency `json:"frequency,omitempty"`
+	// Email is the address the digest is sent to.
+	Email string `json:"email,omitempty"`
+}
+
+// Enabled returns true if the digest is sent.
+func (d AlertDigest) Enabled() bool {
+	return d.Frequency != "" && d.Frequency != DigestNever
+}
+
+// Valid returns an error if the digest is invalid.
+func (d AlertDigest) Valid() error {
+	switch d.Frequency {
+	case "", DigestNever, DigestDaily, DigestWeekly:
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  "alert digest frequency must be one of never, daily or weekly",
+		}
+	}
+	if d.Email != "" {
+		if _, err := mail.ParseAddress(d.Email); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "alert digest email is invalid",
+				Err:  err,
+			}
+		}
+	}
+	if d.Enabled() && d.Email == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "alert digest email is required to send a digest",
+		}
+	}
+	return nil
+}
+
+// UserPreferences are the preferences of a user.
+type UserPreferences struct {
+	UserID ID `json:"userID"`
+	// Timezone is the IANA name of the time zone times are shown in. If it
+	// is empty, the time zone of the browser is used.
+	Timezone string `json:"timezone,omitempty"`
+	// DefaultOrgID is the organization selected when the user signs in.
+	DefaultOrgID *ID         `json:"defaultOrgID,omitempty"`
+	AlertDigest  AlertDigest `json:"alertDigest"`
+	UIDensity    UIDensity   `json:"uiDensity,omitempty"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+}
+
+// Valid returns an error if the preferences are invalid.
+func (p *UserPreferences) Valid() error {
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "timezone must be an IANA time zone name",
+				Err:  err,
+			}
+		}
+	}
+	if p.DefaultOrgID != nil && !p.DefaultOrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "default organization ID is invalid",
+		}
+	}
+	if err := p.AlertDigest.Valid(); err != nil {
+		return err
+	}
+	return p.UIDensity.Valid()
+}
+
+// UserPreferencesUpdate represents updates to the preferences of a user.
+// Only fields which are set are updated. A field set to its zero value,
+// such as an empty timezone or the zero default organization ID, is reset
+// to its default.
+type UserPreferencesUpdate struct {
+	Timezone     *string
+	DefaultOrgID *ID
+	AlertDigest  *AlertDigest
+	UIDensity    *UIDensity
+}
+
+// Apply applies the update to the preferences p.
+func (u UserPreferencesUpdate) Apply(p *UserPreferences) {
+	if u.Timezone != nil {
+		p.Timezone = *u.Timezone
+	}
+	if u.DefaultOrgID != nil {
+		p.DefaultOrgID = nil
+		if *u.DefaultOrgID != 0 {
+			id := *u.DefaultOrgID
+			p.DefaultOrgID = &id
+		}
+	}
+	if u.AlertDigest != nil {
+		p.AlertDigest = *u.AlertDigest
+	}
+	if u.UIDensity != nil {
+		p.UIDensity = *u.UIDensity
+	}
+}
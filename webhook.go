@@ -0,0 +1,217 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrWebhookNotFound is the error for a missing webhook.
+const ErrWebhookNotFound = "webhook not found"
+
+const (
+	OpFindWebhookByID       = "FindWebhookByID"
+	OpFindWebhooks          = "FindWebhooks"
+	OpCreateWebhook         = "CreateWebhook"
+	OpUpdateWebhook         = "UpdateWebhook"
+	OpDeleteWebhook         = "DeleteWebhook"
+	OpFindWebhookDeliveries = "FindWebhookDeliveries"
+	OpAddWebhookDelivery    = "AddWebhookDelivery"
+)
+
+// Types of the resource lifecycle events webhooks subscribe to.
+const (
+	WebhookEventBucketCreated        = "bucket.created"
+	WebhookEventTaskFailed           = "task.failed"
+	WebhookEventDBRPChanged          = "dbrp.changed"
+	WebhookEventAuthorizationCreated = "authorization.created"
+)
+
+// WebhookEventTypes are the types of events webhooks can subscribe to.
+var WebhookEventTypes = []string{
+	WebhookEventBucketCreated,
+	WebhookEventTaskFailed,
+	WebhookEventDBRPChanged,
+	WebhookEventAuthorizationCreated,
+}
+
+// MaxWebhookDeliveries is the number of deliveries kept in the history of a
+// webhook.
+const MaxWebhookDeliveries = 100
+
+// WebhookService manages the webhooks of organizations and their history of
+// deliveries.
+type WebhookService interface {
+	// FindWebhookByID returns a single webhook by ID.
+	FindWebhookByID(ctx context.Context, id ID) (*Webhook, error)
+
+	// FindWebhooks returns a list of webhooks that match filter.
+	FindWebhooks(ctx context.Context, filter WebhookFilter) ([]*Webhook, error)
+
+	// CreateWebhook creates a new webhook and sets w.ID with the new
+	// identifier. The value of its secret is stored in the secrets of its
+	// organization.
+	CreateWebhook(ctx context.Context, w *Webhook) error
+
+	// UpdateWebhook updates a single webhook with changeset.
+	UpdateWebhook(ctx context.Context, id ID, upd WebhookUpdate) (*Webhook, error)
+
+	// DeleteWebhook removes a webhook, its secret and its deliveries by ID.
+	DeleteWebhook(ctx context.Context, id ID) error
+
+	// FindWebhookDeliveries returns the latest deliveries of a webhook,
+	// newest first.
+	FindWebhookDeliveries(ctx context.Context, webhookID ID) ([]*WebhookDelivery, error)
+
+	// AddWebhookDelivery records a delivery of a webhook and sets d.ID with
+	// the new identifier. Only the latest MaxWebhookDeliveries are kept.
+	AddWebhookDelivery(ctx context.Context, d *WebhookDelivery) error
+}
+
+// Webhook posts the resource lifecycle events of an organization to a URL.
+// Payloads are signed with an HMAC-SHA256 of the secret of the webhook.
+type Webhook struct {
+	ID          ID          `json:"id,omitempty"`
+	OrgID       ID          `json:"orgID"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	URL         string      `json:"url"`
+	Secret      SecretField `json:"secret"`
+	Events      []string    `json:"events"`
+	Status      Status      `json:"status"`
+	CRUDLog
+}
+
+// SecretKey returns the key of the secret of the webhook.
+func (w *Webhook) SecretKey() string {
+	return w.ID.String() + "-webhook-secret"
+}
+
+// Subscribed reports whether the webhook is active and subscribed to events
+// of type typ.
+func (w *Webhook) Subscribed(typ string) bool {
+	return w.Status != Inactive && containsString(w.Events, typ)
+}
+
+// Valid returns an error if the webhook is invalid.
+func (w *Webhook) Valid() error {
+	if !w.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization ID is required",
+		}
+	}
+	if w.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "webhook name is required",
+		}
+	}
+	if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "webhook URL must be an absolute http or https URL",
+		}
+	}
+	if len(w.Events) == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "webhook must subscribe to at least one event type",
+		}
+	}
+	for _, e := range w.Events {
+		if !containsString(WebhookEventTypes, e) {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("unknown webhook event type %q", e),
+			}
+		}
+	}
+	if w.Status != "" {
+		if err := w.Status.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WebhookUpdate is the changeset of a webhook.
+type WebhookUpdate struct {
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	URL         *string  `json:"url,omitempty"`
+	Secret      *string  `json:"secret,omitempty"`
+	Events      []string `json:"events,omitempty"`
+	Status      *Status  `json:"status,omitempty"`
+}
+
+// Apply applies the changeset to w. A new value of the secret is kept in
+// w.Secret to be stored, and an empty one removes the secret.
+func (u WebhookUpdate) Apply(w *Webhook) {
+	if u.Name != nil {
+		w.Name = *u.Name
+	}
+	if u.Description != nil {
+		w.Description = *u.Description
+	}
+	if u.URL != nil {
+		w.URL = *u.URL
+	}
+	if u.Secret != nil {
+		if *u.Secret == "" {
+			w.Secret = SecretField{}
+		} else {
+			w.Secret.Value = u.Secret
+		}
+	}
+	if u.Events != nil {
+		w.Events = u.Events
+	}
+	if u.Status != nil {
+		w.Status = *u.Status
+	}
+}
+
+// WebhookFilter represents a set of filters that restrict the returned
+// webhooks.
+type WebhookFilter struct {
+	OrgID *ID
+	// Event restricts the webhooks to the active ones subscribed to it.
+	Event *string
+}
+
+// Match returns true if the webhook matches the filter.
+func (f WebhookFilter) Match(w *Webhook) bool {
+	return (f.OrgID == nil || *f.OrgID == w.OrgID) &&
+		(f.Event == nil || w.Subscribed(*f.Event))
+}
+
+// WebhookEvent is a resource lifecycle event of an organization. Resource is
+// the JSON of the resource the event is about.
+type WebhookEvent struct {
+	Type       string          `json:"type"`
+	OrgID      ID              `json:"orgID"`
+	ResourceID ID              `json:"resourceID,omitempty"`
+	Time       time.Time       `json:"time"`
+	Resource   json.RawMessage `json:"resource,omitempty"`
+}
+
+// WebhookDelivery records the delivery of an event to a webhook. Attempts
+// counts the requests made, including retries.
+type WebhookDelivery struct {
+	ID         ID        `json:"id,omitempty"`
+	WebhookID  ID        `json:"webhookID"`
+	EventType  string    `json:"eventType"`
+	ResourceID ID        `json:"resourceID,omitempty"`
+	Time       time.Time `json:"time"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Succeeded reports whether the event was delivered.
+func (d *WebhookDelivery) Succeeded() bool {
+	return d.Error == "" && d.StatusCode >= 200 && d.StatusCode < 300
+}
@@ -0,0 +1,242 @@
+// Package webhook delivers the resource lifecycle events of organizations to
+// their webhooks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxAttempts is the default number of requests made to deliver
+	// an event to a webhook, including retries.
+	DefaultMaxAttempts = 5
+
+	// DefaultQueueSize is the default number of events waiting to be
+	// delivered. Events published while the queue is full are dropped.
+	DefaultQueueSize = 1024
+
+	// DefaultTimeout is the default timeout of a request to a webhook.
+	DefaultTimeout = 10 * time.Second
+
+	defaultWorkers = 4
+	minBackoff     = time.Second
+	maxBackoff     = time.Minute
+)
+
+// Headers of the requests to webhooks.
+const (
+	HeaderEvent     = "X-Influxdb-Event"
+	HeaderWebhookID = "X-Influxdb-Webhook-Id"
+	HeaderSignature = "X-Influxdb-Signature"
+)
+
+// Publisher publishes resource lifecycle events.
+type Publisher interface {
+	Publish(ctx context.Context, e influxdb.WebhookEvent)
+}
+
+// SecretLoader loads the secrets of webhooks.
+type SecretLoader interface {
+	LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error)
+}
+
+// Sign returns the signature of a payload sent to a webhook with secret,
+// which is the hex encoded HMAC-SHA256 of the payload prefixed by "sha256=".
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher is a Publisher which posts events to the webhooks subscribed to
+// them, and records each delivery in the history of the webhook.
+//
+// Events are delivered in the background: a request which fails with a
+// network error, a server error or too many requests is retried with an
+// exponential backoff, up to MaxAttempts requests.
+type Dispatcher struct {
+	log      *zap.Logger
+	webhooks influxdb.WebhookService
+	secrets  SecretLoader
+	client   *http.Client
+
+	// MaxAttempts is the number of requests made to deliver an event.
+	MaxAttempts int
+
+	events chan influxdb.WebhookEvent
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ Publisher = (*Dispatcher)(nil)
+
+// NewDispatcher returns a Dispatcher which delivers events to the webhooks of
+// webhooks. It must be opened before use.
+func NewDispatcher(log *zap.Logger, webhooks influxdb.WebhookService, secrets SecretLoader) *Dispatcher {
+	return &Dispatcher{
+		log:         log,
+		webhooks:    webhooks,
+		secrets:     secrets,
+		client:      &http.Client{Timeout: DefaultTimeout},
+		MaxAttempts: DefaultMaxAttempts,
+		events:      make(chan influxdb.WebhookEvent, DefaultQueueSize),
+	}
+}
+
+// Open starts delivering published events.
+func (d *Dispatcher) Open(ctx context.Context) error {
+	ctx, d.cancel = context.WithCancel(context.Background())
+	for i := 0; i < defaultWorkers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.run(ctx)
+		}()
+	}
+	return nil
+}
+
+// Close stops delivering events. Events waiting to be delivered are dropped.
+func (d *Dispatcher) Close() error {
+	if d.cancel == nil {
+		return nil
+	}
+	d.cancel()
+	d.wg.Wait()
+	return nil
+}
+
+// Publish queues e to be delivered. It does not block: if the queue is full,
+// the event is dropped.
+func (d *Dispatcher) Publish(ctx context.Context, e influxdb.WebhookEvent) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	select {
+	case d.events <- e:
+	default:
+		d.log.Warn("Webhook queue is full, dropping event", zap.String("event", e.Type), zap.Stringer("org_id", e.OrgID))
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-d.events:
+			d.dispatch(ctx, e)
+		}
+	}
+}
+
+// dispatch delivers e to each webhook of its organization subscribed to it.
+func (d *Dispatcher) dispatch(ctx context.Context, e influxdb.WebhookEvent) {
+	ws, err := d.webhooks.FindWebhooks(ctx, influxdb.WebhookFilter{OrgID: &e.OrgID, Event: &e.Type})
+	if err != nil {
+		d.log.Warn("Failed to find webhooks", zap.String("event", e.Type), zap.Error(err))
+		return
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		d.log.Warn("Failed to encode webhook event", zap.String("event", e.Type), zap.Error(err))
+		return
+	}
+
+	for _, w := range ws {
+		delivery := d.deliver(ctx, w, e, payload)
+		if ctx.Err() != nil {
+			return
+		}
+		if err := d.webhooks.AddWebhookDelivery(ctx, delivery); err != nil {
+			d.log.Warn("Failed to record webhook delivery", zap.Stringer("webhook_id", w.ID), zap.Error(err))
+		}
+	}
+}
+
+// deliver posts payload to w until it succeeds, fails permanently or the
+// attempts are exhausted.
+func (d *Dispatcher) deliver(ctx context.Context, w *influxdb.Webhook, e influxdb.WebhookEvent, payload []byte) *influxdb.WebhookDelivery {
+	delivery := &influxdb.WebhookDelivery{
+		WebhookID:  w.ID,
+		EventType:  e.Type,
+		ResourceID: e.ResourceID,
+		Time:       e.Time,
+	}
+
+	var secret string
+	if w.Secret.Key != "" {
+		s, err := d.secrets.LoadSecret(ctx, w.OrgID, w.Secret.Key)
+		if err != nil {
+			delivery.Error = fmt.Sprintf("failed to load secret: %v", err)
+			return delivery
+		}
+		secret = s
+	}
+
+	backoff := minBackoff
+	for {
+		delivery.Attempts++
+		code, err := d.post(ctx, w, e, payload, secret)
+		delivery.StatusCode = code
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		} else if code < 200 || code >= 300 {
+			delivery.Error = fmt.Sprintf("unexpected status %d", code)
+		}
+
+		retry := err != nil || code == http.StatusTooManyRequests || code >= 500
+		if !retry || delivery.Attempts >= d.MaxAttempts {
+			return delivery
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return delivery
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, w *influxdb.Webhook, e influxdb.WebhookEvent, payload []byte, secret string) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, e.Type)
+	req.Header.Set(HeaderWebhookID, w.ID.String())
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	return resp.StatusCode, nil
+}
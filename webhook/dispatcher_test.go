@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap/zaptest"
+)
+
+// webhookService is an in-memory influxdb.WebhookService.
+type webhookService struct {
+	influxdb.WebhookService
+
+	mu         sync.Mutex
+	webhooks   []*influxdb.Webhook
+	deliveries []*influxdb.WebhookDelivery
+}
+
+func (s *webhookService) FindWebhooks(ctx context.Context, filter influxdb.WebhookFilter) ([]*influxdb.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ws []*influxdb.Webhook
+	for _, w := range s.webhooks {
+		if filter.Match(w) {
+			ws = append(ws, w)
+		}
+	}
+	return ws, nil
+}
+
+func (s *webhookService) AddWebhookDelivery(ctx context.Context, d *influxdb.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, d)
+	return nil
+}
+
+func (s *webhookService) waitForDeliveries(t *testing.T, n int) []*influxdb.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		s.mu.Lock()
+		ds := append([]*influxdb.WebhookDelivery(nil), s.deliveries...)
+		s.mu.Unlock()
+		if len(ds) >= n {
+			return ds
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d deliveries, want %d", len(ds), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type secretLoader map[string]string
+
+func (l secretLoader) LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+	if v, ok := l[k]; ok {
+		return v, nil
+	}
+	return "", &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrSecretNotFound}
+}
+
+func TestDispatcher(t *testing.T) {
+	const orgID = influxdb.ID(1)
+
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+		bodies   [][]byte
+		fail     = 1
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		if fail > 0 {
+			fail--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer rejecting.Close()
+
+	svc := &webhookService{webhooks: []*influxdb.Webhook{
+		{
+			ID:     10,
+			OrgID:  orgID,
+			URL:    srv.URL,
+			Secret: influxdb.SecretField{Key: "000000000000000a-webhook-secret"},
+			Events: []string{influxdb.WebhookEventBucketCreated},
+		},
+		{
+			ID:     11,
+			OrgID:  orgID,
+			URL:    rejecting.URL,
+			Events: []string{influxdb.WebhookEventBucketCreated},
+		},
+		{
+			ID:     12,
+			OrgID:  orgID,
+			URL:    srv.URL,
+			Events: []string{influxdb.WebhookEventTaskFailed},
+		},
+		{
+			ID:     13,
+			OrgID:  2,
+			URL:    srv.URL,
+			Events: []string{influxdb.WebhookEventBucketCreated},
+		},
+	}}
+	secrets := secretLoader{"000000000000000a-webhook-secret": "s3cr3t"}
+
+	d := NewDispatcher(zaptest.NewLogger(t), svc, secrets)
+	if err := d.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	buckets := NewBucketService(&bucketService{}, d)
+	if err := buckets.CreateBucket(context.Background(), &influxdb.Bucket{OrgID: orgID, Name: "metrics"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ds := svc.waitForDeliveries(t, 2)
+	byWebhook := make(map[influxdb.ID]*influxdb.WebhookDelivery)
+	for _, d := range ds {
+		byWebhook[d.WebhookID] = d
+	}
+	if d := byWebhook[10]; d == nil || !d.Succeeded() || d.Attempts != 2 || d.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected delivery %+v", d)
+	}
+	if d := byWebhook[11]; d == nil || d.Succeeded() || d.Attempts != 1 || d.StatusCode != http.StatusGone {
+		t.Fatalf("unexpected delivery %+v", d)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	r, body := requests[1], bodies[1]
+	if got := r.Header.Get(HeaderEvent); got != influxdb.WebhookEventBucketCreated {
+		t.Fatalf("unexpected event header %q", got)
+	}
+	if got := r.Header.Get(HeaderWebhookID); got != "000000000000000a" {
+		t.Fatalf("unexpected webhook ID header %q", got)
+	}
+	if got, want := r.Header.Get(HeaderSignature), Sign("s3cr3t", body); got != want {
+		t.Fatalf("got signature %q, want %q", got, want)
+	}
+
+	var e influxdb.WebhookEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		t.Fatal(err)
+	}
+	var b influxdb.Bucket
+	if err := json.Unmarshal(e.Resource, &b); err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != influxdb.WebhookEventBucketCreated || e.OrgID != orgID || e.ResourceID != 100 || b.Name != "metrics" {
+		t.Fatalf("unexpected event %+v with bucket %+v", e, b)
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("key", []byte("The quick brown fox jumps over the lazy dog"))
+	if want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/task/backend"
+)
+
+// event returns an event about resource, which is encoded as JSON.
+func event(typ string, orgID, resourceID influxdb.ID, resource interface{}) influxdb.WebhookEvent {
+	e := influxdb.WebhookEvent{
+		Type:       typ,
+		OrgID:      orgID,
+		ResourceID: resourceID,
+		Time:       time.Now().UTC(),
+	}
+	if b, err := json.Marshal(resource); err == nil {
+		e.Resource = b
+	}
+	return e
+}
+
+// BucketService is an influxdb.BucketService which publishes an event when a
+// bucket is created.
+type BucketService struct {
+	influxdb.BucketService
+	publisher Publisher
+}
+
+// NewBucketService returns a BucketService which publishes the events of s to p.
+func NewBucketService(s influxdb.BucketService, p Publisher) *BucketService {
+	return &BucketService{
+		BucketService: s,
+		publisher:     p,
+	}
+}
+
+// CreateBucket creates a bucket and publishes a bucket.created event.
+func (s *BucketService) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
+	if err := s.BucketService.CreateBucket(ctx, b); err != nil {
+		return err
+	}
+	s.publisher.Publish(ctx, event(influxdb.WebhookEventBucketCreated, b.OrgID, b.ID, b))
+	return nil
+}
+
+// AuthorizationService is an influxdb.AuthorizationService which publishes
+// an event when a token is created.
+type AuthorizationService struct {
+	influxdb.AuthorizationService
+	publisher Publisher
+}
+
+// NewAuthorizationService returns an AuthorizationService which publishes the
+// events of s to p.
+func NewAuthorizationService(s influxdb.AuthorizationService, p Publisher) *AuthorizationService {
+	return &AuthorizationService{
+		AuthorizationService: s,
+		publisher:            p,
+	}
+}
+
+// CreateAuthorization creates an authorization and publishes an
+// authorization.created event. The token itself is not published.
+func (s *AuthorizationService) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
+	if err := s.AuthorizationService.CreateAuthorization(ctx, a); err != nil {
+		return err
+	}
+	redacted := *a
+	redacted.Token = ""
+	s.publisher.Publish(ctx, event(influxdb.WebhookEventAuthorizationCreated, a.OrgID, a.ID, redacted))
+	return nil
+}
+
+// DBRPEventListener is a dbrp.EventListener which publishes a dbrp.changed
+// event for each change of a mapping, whichever way the mapping is changed.
+type DBRPEventListener struct {
+	publisher Publisher
+}
+
+var _ dbrp.EventListener = (*DBRPEventListener)(nil)
+
+// NewDBRPEventListener returns a DBRPEventListener which publishes the
+// changes of mappings to p.
+func NewDBRPEventListener(p Publisher) *DBRPEventListener {
+	return &DBRPEventListener{
+		publisher: p,
+	}
+}
+
+// dbrpChange is the resource of a dbrp.changed event.
+type dbrpChange struct {
+	Action dbrp.EventType `json:"action"`
+	influxdb.DBRPMapping
+}
+
+// DBRPMappingChanged publishes a dbrp.changed event of e.
+func (l *DBRPEventListener) DBRPMappingChanged(ctx context.Context, e dbrp.Event) {
+	m := e.Mapping
+	l.publisher.Publish(ctx, event(influxdb.WebhookEventDBRPChanged, m.OrganizationID, m.BucketID, dbrpChange{Action: e.Type, DBRPMapping: m}))
+}
+
+// TaskFinder finds the tasks whose runs fail.
+type TaskFinder interface {
+	FindTaskByID(ctx context.Context, id influxdb.ID) (*influxdb.Task, error)
+}
+
+// TaskControlService is a backend.TaskControlService which publishes an
+// event when a run of a task fails.
+type TaskControlService struct {
+	backend.TaskControlService
+	tasks     TaskFinder
+	publisher Publisher
+}
+
+// NewTaskControlService returns a TaskControlService which publishes the
+// events of s to p.
+func NewTaskControlService(s backend.TaskControlService, tasks TaskFinder, p Publisher) *TaskControlService {
+	return &TaskControlService{
+		TaskControlService: s,
+		tasks:              tasks,
+		publisher:          p,
+	}
+}
+
+// failedRun is the resource of a task.failed event.
+type failedRun struct {
+	RunID    influxdb.ID `json:"runID"`
+	TaskID   influxdb.ID `json:"taskID"`
+	TaskName string      `json:"taskName"`
+	FailedAt time.Time   `json:"failedAt"`
+}
+
+// UpdateRunState sets the state of a run, and publishes a task.failed event
+// when the run fails.
+func (s *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error {
+	if err := s.TaskControlService.UpdateRunState(ctx, taskID, runID, when, state); err != nil {
+		return err
+	}
+	if state != influxdb.RunFail {
+		return nil
+	}
+
+	t, err := s.tasks.FindTaskByID(ctx, taskID)
+	if err != nil {
+		// The task may have been deleted while it ran.
+		return nil
+	}
+	s.publisher.Publish(ctx, event(influxdb.WebhookEventTaskFailed, t.OrganizationID, taskID, failedRun{
+		RunID:    runID,
+		TaskID:   taskID,
+		TaskName: t.Name,
+		FailedAt: when.UTC(),
+	}))
+	return nil
+}
@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/task/backend"
+)
+
+type publisher []influxdb.WebhookEvent
+
+func (p *publisher) Publish(ctx context.Context, e influxdb.WebhookEvent) {
+	*p = append(*p, e)
+}
+
+type bucketService struct {
+	influxdb.BucketService
+}
+
+func (s *bucketService) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
+	b.ID = 100
+	return nil
+}
+
+type authorizationService struct {
+	influxdb.AuthorizationService
+}
+
+func (s *authorizationService) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
+	a.ID = 200
+	a.Token = "secret-token"
+	return nil
+}
+
+type taskControlService struct {
+	backend.TaskControlService
+	states []influxdb.RunStatus
+}
+
+func (s *taskControlService) UpdateRunState(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error {
+	s.states = append(s.states, state)
+	return nil
+}
+
+type taskFinder map[influxdb.ID]*influxdb.Task
+
+func (f taskFinder) FindTaskByID(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+	if t, ok := f[id]; ok {
+		return t, nil
+	}
+	return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "task not found"}
+}
+
+func TestAuthorizationService(t *testing.T) {
+	var p publisher
+	s := NewAuthorizationService(&authorizationService{}, &p)
+
+	a := &influxdb.Authorization{OrgID: 1, Description: "ci"}
+	if err := s.CreateAuthorization(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Token != "secret-token" {
+		t.Fatalf("expected token to be returned to the caller, got %q", a.Token)
+	}
+	if len(p) != 1 || p[0].Type != influxdb.WebhookEventAuthorizationCreated || p[0].OrgID != 1 || p[0].ResourceID != 200 {
+		t.Fatalf("unexpected events %+v", p)
+	}
+	if strings.Contains(string(p[0].Resource), "secret-token") {
+		t.Fatalf("expected token to be redacted from %s", p[0].Resource)
+	}
+}
+
+func TestTaskControlService(t *testing.T) {
+	var p publisher
+	inner := &taskControlService{}
+	tasks := taskFinder{1: {ID: 1, OrganizationID: 2, Name: "downsample"}}
+	s := NewTaskControlService(inner, tasks, &p)
+
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, state := range []influxdb.RunStatus{influxdb.RunStarted, influxdb.RunSuccess, influxdb.RunFail} {
+		if err := s.UpdateRunState(ctx, 1, 10, now, state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Failures of deleted tasks are not published.
+	if err := s.UpdateRunState(ctx, 3, 11, now, influxdb.RunFail); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inner.states) != 4 {
+		t.Fatalf("expected all states to be updated, got %v", inner.states)
+	}
+	if len(p) != 1 || p[0].Type != influxdb.WebhookEventTaskFailed || p[0].OrgID != 2 || p[0].ResourceID != 1 {
+		t.Fatalf("unexpected events %+v", p)
+	}
+	var run failedRun
+	if err := json.Unmarshal(p[0].Resource, &run); err != nil {
+		t.Fatal(err)
+	}
+	if run.RunID != 10 || run.TaskName != "downsample" || !run.FailedAt.Equal(now) {
+		t.Fatalf("unexpected run %+v", run)
+	}
+}
@@ -2,6 +2,7 @@ package influxdb
 
 import (
 	"context"
+	"fmt"
 	"io"
 )
 
@@ -9,3 +10,55 @@ import (
 type WriteService interface {
 	Write(ctx context.Context, org, bucket ID, r io.Reader) error
 }
+
+// WriteConsistency is when a write is acknowledged: once its points are in
+// the cache, or once they are also synced to disk in the WAL.
+type WriteConsistency string
+
+const (
+	// WriteConsistencyFast acknowledges a write once its points are in the
+	// cache. The WAL is still synced, but a crash before it is may lose the
+	// points of writes already acknowledged.
+	WriteConsistencyFast WriteConsistency = "fast"
+	// WriteConsistencyDurable acknowledges a write once the WAL it is written
+	// to is synced to disk. It is the default consistency.
+	WriteConsistencyDurable WriteConsistency = "durable"
+)
+
+// Valid returns an error if the consistency is not one of the known
+// consistencies. The empty consistency is valid and equivalent to
+// WriteConsistencyDurable.
+func (c WriteConsistency) Valid() error {
+	switch c {
+	case "", WriteConsistencyFast, WriteConsistencyDurable:
+		return nil
+	}
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid write consistency %q; expected one of %q or %q", string(c), WriteConsistencyFast, WriteConsistencyDurable),
+	}
+}
+
+// OrDefault returns the consistency, or WriteConsistencyDurable if it is
+// unset.
+func (c WriteConsistency) OrDefault() WriteConsistency {
+	if c == "" {
+		return WriteConsistencyDurable
+	}
+	return c
+}
+
+type writeConsistencyContextKey struct{}
+
+// ContextWithWriteConsistency returns a new context requesting the
+// consistency for the points written with it.
+func ContextWithWriteConsistency(ctx context.Context, c WriteConsistency) context.Context {
+	return context.WithValue(ctx, writeConsistencyContextKey{}, c)
+}
+
+// WriteConsistencyFromContext returns the consistency requested for the
+// points written with ctx, or the empty consistency if none was requested.
+func WriteConsistencyFromContext(ctx context.Context) WriteConsistency {
+	c, _ := ctx.Value(writeConsistencyContextKey{}).(WriteConsistency)
+	return c
+}
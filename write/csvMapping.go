@@ -0,0 +1,268 @@
+package write
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Parts of protocol lines that CSV columns are mapped to
+const (
+	mappingMeasurement = "measurement"
+	mappingTag         = "tag"
+	mappingField       = "field"
+	mappingTime        = "time"
+)
+
+// time formats of mapped columns, other formats are time.Parse layouts
+var mappingTimeUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// CsvMapping maps the columns of CSV data without annotations to the parts
+// of protocol lines. The first row of the data is a header with column names,
+// columns missing in the mapping are ignored.
+type CsvMapping struct {
+	// Measurement is the measurement of rows with no measurement column value
+	Measurement string `json:"measurement,omitempty"`
+	// Columns are the mapped columns
+	Columns []CsvColumnMapping `json:"columns"`
+}
+
+// CsvColumnMapping maps a column, by its name in the header, to a part of protocol lines
+type CsvColumnMapping struct {
+	// Column is the name of the column in the header
+	Column string `json:"column"`
+	// Type is one of "measurement", "tag", "field" or "time"
+	Type string `json:"type"`
+	// Name is the tag or field key, the column name when empty
+	Name string `json:"name,omitempty"`
+	// DataType is the data type of a field, such as "long", "double" when empty
+	DataType string `json:"dataType,omitempty"`
+	// Format is the format of a time, one of "RFC3339" (when empty), "RFC3339Nano",
+	// "ns", "us", "ms" or "s" for numbers since the epoch, or a time.Parse layout
+	Format string `json:"format,omitempty"`
+	// Default is the value of rows where the column is an empty string
+	Default string `json:"default,omitempty"`
+}
+
+// key returns the tag or field key of the column
+func (c *CsvColumnMapping) key() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Column
+}
+
+// Validate returns an error if the mapping cannot produce protocol lines
+func (m *CsvMapping) Validate() error {
+	var measurement, timeColumn bool
+	var fields int
+	keys := make(map[string]string)
+	for _, c := range m.Columns {
+		if c.Column == "" {
+			return errors.New("mapped column has no name")
+		}
+		switch c.Type {
+		case mappingMeasurement:
+			if measurement {
+				return errors.New("at most one measurement column is allowed")
+			}
+			measurement = true
+		case mappingTime:
+			if timeColumn {
+				return errors.New("at most one time column is allowed")
+			}
+			timeColumn = true
+		case mappingTag, mappingField:
+			if t, ok := keys[c.key()]; ok {
+				return fmt.Errorf("column '%s': key '%s' is already mapped to a %s", c.Column, c.key(), t)
+			}
+			keys[c.key()] = c.Type
+			if c.Type == mappingTag {
+				continue
+			}
+			switch c.DataType {
+			case "", doubleDatatype, longDatatype, uLongDatatype, boolDatatype, stringDatatype:
+			default:
+				return fmt.Errorf("column '%s': data type '%s' is not supported", c.Column, c.DataType)
+			}
+			fields++
+		default:
+			return fmt.Errorf("column '%s': type '%s' is not one of measurement, tag, field or time", c.Column, c.Type)
+		}
+	}
+	if !measurement && m.Measurement == "" {
+		return errors.New("no measurement or measurement column")
+	}
+	if fields == 0 {
+		return errors.New("no field column")
+	}
+	return nil
+}
+
+// mappedColumn is a mapped column of the header
+type mappedColumn struct {
+	*CsvColumnMapping
+	index int
+}
+
+// value returns the value of the column in row, or its default
+func (c *mappedColumn) value(row []string) string {
+	if c.index < len(row) && len(row[c.index]) > 0 {
+		return row[c.index]
+	}
+	return c.Default
+}
+
+// csvMappedTable converts the rows of CSV data with a CsvMapping
+type csvMappedTable struct {
+	mapping *CsvMapping
+	// err is an error of the header, returned for every data row
+	err         error
+	header      bool
+	measurement *mappedColumn
+	time        *mappedColumn
+	tags        []mappedColumn
+	fields      []mappedColumn
+}
+
+// AddRow reads the header row, and returns true for data rows
+func (t *csvMappedTable) AddRow(row []string) bool {
+	if t.header {
+		return true
+	}
+	t.header = true
+
+	index := make(map[string]int, len(row))
+	for i, name := range row {
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+	for i := range t.mapping.Columns {
+		c := &t.mapping.Columns[i]
+		pos, ok := index[c.Column]
+		if !ok {
+			t.err = fmt.Errorf("column '%s' is not in the header", c.Column)
+			return false
+		}
+		col := mappedColumn{CsvColumnMapping: c, index: pos}
+		switch c.Type {
+		case mappingMeasurement:
+			t.measurement = &col
+		case mappingTime:
+			t.time = &col
+		case mappingTag:
+			t.tags = append(t.tags, col)
+		case mappingField:
+			t.fields = append(t.fields, col)
+		}
+	}
+	sort.Slice(t.tags, func(i, j int) bool {
+		return t.tags[i].key() < t.tags[j].key()
+	})
+	return false
+}
+
+// AppendLine appends a protocol line to the supplied buffer and returns appended buffer or an error if any
+func (t *csvMappedTable) AppendLine(buffer []byte, row []string) ([]byte, error) {
+	if t.err != nil {
+		return buffer, t.err
+	}
+
+	measurement := t.mapping.Measurement
+	if t.measurement != nil {
+		measurement = orDefault(t.measurement.value(row), measurement)
+	}
+	if measurement == "" {
+		return buffer, errors.New("no measurement supplied")
+	}
+	buffer = append(buffer, escapeMeasurement(measurement)...)
+	for _, tag := range t.tags {
+		if value := tag.value(row); len(value) > 0 {
+			buffer = append(buffer, ',')
+			buffer = append(buffer, escapeTag(tag.key())...)
+			buffer = append(buffer, '=')
+			buffer = append(buffer, escapeTag(value)...)
+		}
+	}
+	buffer = append(buffer, ' ')
+	fieldAdded := false
+	for _, field := range t.fields {
+		value := field.value(row)
+		if len(value) == 0 {
+			continue
+		}
+		if fieldAdded {
+			buffer = append(buffer, ',')
+		}
+		fieldAdded = true
+		buffer = append(buffer, escapeTag(field.key())...)
+		buffer = append(buffer, '=')
+		dataType := field.DataType
+		if dataType == "" {
+			dataType = doubleDatatype
+		}
+		var err error
+		buffer, err = appendConverted(buffer, value, dataType)
+		if err != nil {
+			return buffer, CsvColumnError{field.Column, err}
+		}
+	}
+	if !fieldAdded {
+		return buffer, errors.New("no field data found")
+	}
+
+	if t.time != nil {
+		if value := t.time.value(row); len(value) > 0 {
+			ts, err := parseMappedTime(value, t.time.Format)
+			if err != nil {
+				return buffer, CsvColumnError{t.time.Column, err}
+			}
+			buffer = append(buffer, ' ')
+			buffer = strconv.AppendInt(buffer, ts, 10)
+		}
+	}
+	return buffer, nil
+}
+
+// parseMappedTime returns the nanoseconds since the epoch of a time value in format
+func parseMappedTime(value, format string) (int64, error) {
+	if unit, ok := mappingTimeUnits[format]; ok {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return n * int64(unit), nil
+	}
+
+	layout := format
+	switch format {
+	case "", "RFC3339":
+		layout = time.RFC3339
+	case "RFC3339Nano":
+		layout = time.RFC3339Nano
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano(), nil
+}
+
+// CsvToProtocolLinesWithMapping transforms csv data without annotations into line protocol data,
+// the mapping must be valid
+func CsvToProtocolLinesWithMapping(reader io.Reader, mapping *CsvMapping) io.Reader {
+	return &lineReader{
+		csv:   csv.NewReader(reader),
+		table: &csvMappedTable{mapping: mapping},
+	}
+}
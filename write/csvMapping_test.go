@@ -0,0 +1,178 @@
+package write
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_CsvToProtocolLinesWithMapping tests conversion of csv data with a mapping
+func Test_CsvToProtocolLinesWithMapping(t *testing.T) {
+	var tests = []struct {
+		name    string
+		mapping CsvMapping
+		csv     string
+		lines   string
+		err     string
+	}{
+		{
+			"constantMeasurement",
+			CsvMapping{
+				Measurement: "cpu",
+				Columns: []CsvColumnMapping{
+					{Column: "ts", Type: "time"},
+					{Column: "host", Type: "tag"},
+					{Column: "usage", Type: "field"},
+					{Column: "count", Type: "field", DataType: "long"},
+				},
+			},
+			"ts,usage,host,ignored,count\n2020-01-01T00:00:00Z,1.5,a b,x,3\n,,,x,4\n",
+			"cpu,host=a\\ b usage=1.5,count=3i 1577836800000000000\ncpu count=4i\n",
+			"",
+		},
+		{
+			"measurementColumn",
+			CsvMapping{
+				Measurement: "default",
+				Columns: []CsvColumnMapping{
+					{Column: "name", Type: "measurement"},
+					{Column: "z", Type: "tag"},
+					{Column: "a", Type: "tag", Name: "y"},
+					{Column: "on", Type: "field", Name: "up", DataType: "boolean"},
+					{Column: "msg", Type: "field", DataType: "string", Default: "none"},
+					{Column: "t", Type: "time", Format: "ms"},
+				},
+			},
+			"name,a,z,on,msg,t\nsys,1,2,true,\"say \"\"hi\"\"\",1500\n,,,false,,2\n",
+			"sys,y=1,z=2 up=true,msg=\"say \\\"hi\\\"\" 1500000000\ndefault up=false,msg=\"none\" 2000000\n",
+			"",
+		},
+		{
+			"layout",
+			CsvMapping{
+				Measurement: "m",
+				Columns: []CsvColumnMapping{
+					{Column: "date", Type: "time", Format: "2006-01-02 15:04"},
+					{Column: "v", Type: "field", DataType: "unsignedLong"},
+				},
+			},
+			"date,v\n2020-01-02 03:04,7\n",
+			"m v=7u 1577934240000000000\n",
+			"",
+		},
+		{
+			"missingColumn",
+			CsvMapping{
+				Measurement: "m",
+				Columns:     []CsvColumnMapping{{Column: "v", Type: "field"}},
+			},
+			"a,b\n1,2\n",
+			"",
+			"column 'v' is not in the header",
+		},
+		{
+			"invalidValue",
+			CsvMapping{
+				Measurement: "m",
+				Columns:     []CsvColumnMapping{{Column: "v", Type: "field", DataType: "long"}},
+			},
+			"v\n1\n1.5\n",
+			"",
+			"line 3: column 'v'",
+		},
+		{
+			"invalidTime",
+			CsvMapping{
+				Measurement: "m",
+				Columns: []CsvColumnMapping{
+					{Column: "v", Type: "field"},
+					{Column: "t", Type: "time", Format: "s"},
+				},
+			},
+			"v,t\n1,now\n",
+			"",
+			"column 't'",
+		},
+		{
+			"noField",
+			CsvMapping{
+				Measurement: "m",
+				Columns:     []CsvColumnMapping{{Column: "v", Type: "field"}},
+			},
+			"v\n\n\"\"\n",
+			"",
+			"no field data",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, test.mapping.Validate())
+			lines, err := ioutil.ReadAll(CsvToProtocolLinesWithMapping(strings.NewReader(test.csv), &test.mapping))
+			if test.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.lines, string(lines))
+		})
+	}
+}
+
+// Test_CsvMapping_Validate tests validation of mappings
+func Test_CsvMapping_Validate(t *testing.T) {
+	var tests = []struct {
+		name    string
+		mapping CsvMapping
+		err     string
+	}{
+		{
+			"noMeasurement",
+			CsvMapping{Columns: []CsvColumnMapping{{Column: "v", Type: "field"}}},
+			"no measurement",
+		},
+		{
+			"noField",
+			CsvMapping{Measurement: "m", Columns: []CsvColumnMapping{{Column: "t", Type: "tag"}}},
+			"no field",
+		},
+		{
+			"unknownType",
+			CsvMapping{Measurement: "m", Columns: []CsvColumnMapping{{Column: "v", Type: "value"}}},
+			"type 'value'",
+		},
+		{
+			"unsupportedDataType",
+			CsvMapping{Measurement: "m", Columns: []CsvColumnMapping{{Column: "v", Type: "field", DataType: "duration"}}},
+			"data type 'duration'",
+		},
+		{
+			"duplicateKey",
+			CsvMapping{Measurement: "m", Columns: []CsvColumnMapping{
+				{Column: "a", Type: "tag", Name: "k"},
+				{Column: "k", Type: "field"},
+			}},
+			"key 'k' is already mapped to a tag",
+		},
+		{
+			"twoTimes",
+			CsvMapping{Measurement: "m", Columns: []CsvColumnMapping{
+				{Column: "v", Type: "field"},
+				{Column: "a", Type: "time"},
+				{Column: "b", Type: "time"},
+			}},
+			"at most one time column",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.mapping.Validate()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
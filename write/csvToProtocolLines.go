@@ -18,10 +18,18 @@ func (e CsvLineError) Error() string {
 	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
 }
 
+// csvRowConverter converts csv rows to protocol lines
+type csvRowConverter interface {
+	// AddRow adds a row and returns true if it is a data row to convert
+	AddRow(row []string) bool
+	// AppendLine appends the protocol line of a data row to buffer
+	AppendLine(buffer []byte, row []string) ([]byte, error)
+}
+
 type lineReader struct {
 	// csv reading
 	csv        *csv.Reader
-	table      CsvTable
+	table      csvRowConverter
 	lineNumber int
 
 	// reader results
@@ -82,6 +90,7 @@ func (state *lineReader) Read(p []byte) (n int, err error) {
 // CsvToProtocolLines transforms csv data into line protocol data
 func CsvToProtocolLines(reader io.Reader) io.Reader {
 	return &lineReader{
-		csv: csv.NewReader(reader),
+		csv:   csv.NewReader(reader),
+		table: &CsvTable{},
 	}
 }
@@ -0,0 +1,229 @@
+package write
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/jsonpath"
+)
+
+// jsonRule is a JSONWriteRule with parsed paths
+type jsonRule struct {
+	*platform.JSONWriteRule
+	path        *jsonpath.Path
+	measurement *jsonpath.Path
+	time        *jsonpath.Path
+	tags        []jsonValue
+	fields      []jsonValue
+}
+
+// jsonValue is a tag or field with a parsed path
+type jsonValue struct {
+	platform.JSONWriteValue
+	path *jsonpath.Path
+}
+
+func parseOptionalPath(expr string) (*jsonpath.Path, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return jsonpath.Parse(expr)
+}
+
+func newJSONRule(r *platform.JSONWriteRule) (*jsonRule, error) {
+	rule := &jsonRule{JSONWriteRule: r}
+	var err error
+	if rule.path, err = jsonpath.Parse(r.Path); err != nil {
+		return nil, err
+	}
+	if rule.measurement, err = parseOptionalPath(r.MeasurementPath); err != nil {
+		return nil, err
+	}
+	if rule.time, err = parseOptionalPath(r.TimePath); err != nil {
+		return nil, err
+	}
+	for _, v := range r.Tags {
+		p, err := jsonpath.Parse(v.Path)
+		if err != nil {
+			return nil, err
+		}
+		rule.tags = append(rule.tags, jsonValue{JSONWriteValue: v, path: p})
+	}
+	sort.Slice(rule.tags, func(i, j int) bool {
+		return rule.tags[i].Key < rule.tags[j].Key
+	})
+	for _, v := range r.Fields {
+		p, err := jsonpath.Parse(v.Path)
+		if err != nil {
+			return nil, err
+		}
+		rule.fields = append(rule.fields, jsonValue{JSONWriteValue: v, path: p})
+	}
+	return rule, nil
+}
+
+// selectOne returns the first value selected by path, or nil if there is none
+func selectOne(path *jsonpath.Path, root, current interface{}) interface{} {
+	if path == nil {
+		return nil
+	}
+	if values := path.Select(root, current); len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// jsonScalar returns the text of a JSON string, number or boolean
+func jsonScalar(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("value is not a string, number or boolean")
+	}
+}
+
+// jsonFieldValue converts a JSON value to the value of a field of type
+// dataType, a JSONWriteFieldTypes type or empty for the type of the value
+func jsonFieldValue(v interface{}, dataType string) (interface{}, error) {
+	if dataType == "" {
+		switch v := v.(type) {
+		case json.Number:
+			return strconv.ParseFloat(v.String(), 64)
+		case string, bool:
+			return v, nil
+		default:
+			return nil, fmt.Errorf("value is not a string, number or boolean")
+		}
+	}
+
+	s, err := jsonScalar(v)
+	if err != nil {
+		return nil, err
+	}
+	switch dataType {
+	case "float":
+		return toTypedValue(s, doubleDatatype)
+	case "integer":
+		return toTypedValue(s, longDatatype)
+	case "unsigned":
+		return toTypedValue(s, uLongDatatype)
+	case "boolean":
+		return toTypedValue(s, boolDatatype)
+	case "string":
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unsupported type '%s'", dataType)
+	}
+}
+
+// appendLine appends the protocol line extracted from the value selected by
+// the rule, it returns the buffer unchanged when the value has no field data
+func (r *jsonRule) appendLine(buffer []byte, root, current interface{}) ([]byte, error) {
+	measurement := r.Measurement
+	if v := selectOne(r.measurement, root, current); v != nil {
+		s, err := jsonScalar(v)
+		if err != nil {
+			return buffer, fmt.Errorf("measurement: %v", err)
+		}
+		if s != "" {
+			measurement = s
+		}
+	}
+	if measurement == "" {
+		return buffer, nil
+	}
+
+	line := append([]byte(nil), escapeMeasurement(measurement)...)
+	for _, tag := range r.tags {
+		v := selectOne(tag.path, root, current)
+		if v == nil {
+			continue
+		}
+		s, err := jsonScalar(v)
+		if err != nil {
+			return buffer, fmt.Errorf("tag '%s': %v", tag.Key, err)
+		}
+		if s == "" {
+			continue
+		}
+		line = append(line, ',')
+		line = append(line, escapeTag(tag.Key)...)
+		line = append(line, '=')
+		line = append(line, escapeTag(s)...)
+	}
+
+	line = append(line, ' ')
+	fieldAdded := false
+	for _, field := range r.fields {
+		v := selectOne(field.path, root, current)
+		if v == nil {
+			continue
+		}
+		value, err := jsonFieldValue(v, field.Type)
+		if err != nil {
+			return buffer, fmt.Errorf("field '%s': %v", field.Key, err)
+		}
+		if fieldAdded {
+			line = append(line, ',')
+		}
+		fieldAdded = true
+		line = append(line, escapeTag(field.Key)...)
+		line = append(line, '=')
+		if line, err = appendProtocolValue(line, value); err != nil {
+			return buffer, fmt.Errorf("field '%s': %v", field.Key, err)
+		}
+	}
+	if !fieldAdded {
+		return buffer, nil
+	}
+
+	if v := selectOne(r.time, root, current); v != nil {
+		s, err := jsonScalar(v)
+		if err != nil {
+			return buffer, fmt.Errorf("time: %v", err)
+		}
+		ts, err := parseMappedTime(s, r.TimeFormat)
+		if err != nil {
+			return buffer, fmt.Errorf("time: %v", err)
+		}
+		line = append(line, ' ')
+		line = strconv.AppendInt(line, ts, 10)
+	}
+	buffer = append(buffer, line...)
+	return append(buffer, '\n'), nil
+}
+
+// JSONToProtocolLines extracts protocol lines from a JSON document with the
+// rules of a bucket. Values selected by a rule without a measurement or any
+// field are skipped.
+func JSONToProtocolLines(data []byte, rules []platform.JSONWriteRule) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var buffer []byte
+	for i := range rules {
+		rule, err := newJSONRule(&rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i, err)
+		}
+		for _, v := range rule.path.Select(doc, doc) {
+			if buffer, err = rule.appendLine(buffer, doc, v); err != nil {
+				return nil, fmt.Errorf("rule %d: %v", i, err)
+			}
+		}
+	}
+	return buffer, nil
+}
@@ -0,0 +1,121 @@
+package write
+
+import (
+	"testing"
+
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_JSONToProtocolLines tests extraction of protocol lines from JSON documents
+func Test_JSONToProtocolLines(t *testing.T) {
+	var tests = []struct {
+		name  string
+		rules []platform.JSONWriteRule
+		json  string
+		lines string
+		err   string
+	}{
+		{
+			"webhook",
+			[]platform.JSONWriteRule{{
+				Path:        "$.commits[*]",
+				Measurement: "commits",
+				Tags: []platform.JSONWriteValue{
+					{Key: "repo", Path: "$.repository.full_name"},
+					{Key: "author", Path: "@.author.name"},
+				},
+				Fields: []platform.JSONWriteValue{
+					{Key: "id", Path: "@.id"},
+					{Key: "files", Path: "@.files", Type: "integer"},
+					{Key: "verified", Path: "@.verified"},
+				},
+				TimePath: "@.timestamp",
+			}},
+			`{
+				"repository": {"full_name": "influxdata/influxdb"},
+				"commits": [
+					{"id": "a", "author": {"name": "x y"}, "files": 3, "verified": true, "timestamp": "2020-01-01T00:00:00Z"},
+					{"id": "b", "author": {"name": null}, "files": "4"},
+					{"author": {"name": "z"}}
+				]
+			}`,
+			"commits,author=x\\ y,repo=influxdata/influxdb id=\"a\",files=3i,verified=true 1577836800000000000\n" +
+				"commits,repo=influxdata/influxdb id=\"b\",files=4i\n",
+			"",
+		},
+		{
+			"measurementPath",
+			[]platform.JSONWriteRule{
+				{
+					Path:            "$.readings.*",
+					Measurement:     "default",
+					MeasurementPath: "@.sensor",
+					Fields:          []platform.JSONWriteValue{{Key: "value", Path: "@.value"}},
+					TimePath:        "@.t",
+					TimeFormat:      "ms",
+				},
+				{
+					Path:        "$",
+					Measurement: "batch",
+					Fields:      []platform.JSONWriteValue{{Key: "size", Path: "$.size", Type: "unsigned"}},
+				},
+			},
+			`{"size": 2, "readings": {"b": {"value": 2, "t": 1500}, "a": {"sensor": "temp", "value": 1.5}}}`,
+			"temp value=1.5\ndefault value=2 1500000000\nbatch size=2u\n",
+			"",
+		},
+		{
+			"invalidJSON",
+			[]platform.JSONWriteRule{{Path: "$", Measurement: "m", Fields: []platform.JSONWriteValue{{Key: "v", Path: "$.v"}}}},
+			`{"v": `,
+			"",
+			"unexpected EOF",
+		},
+		{
+			"invalidType",
+			[]platform.JSONWriteRule{{Path: "$", Measurement: "m", Fields: []platform.JSONWriteValue{{Key: "v", Path: "$.v", Type: "integer"}}}},
+			`{"v": 1.5}`,
+			"",
+			"rule 0: field 'v'",
+		},
+		{
+			"nonScalarTag",
+			[]platform.JSONWriteRule{{
+				Path:        "$",
+				Measurement: "m",
+				Tags:        []platform.JSONWriteValue{{Key: "t", Path: "$.t"}},
+				Fields:      []platform.JSONWriteValue{{Key: "v", Path: "$.v"}},
+			}},
+			`{"t": [1], "v": 1}`,
+			"",
+			"tag 't'",
+		},
+		{
+			"invalidTime",
+			[]platform.JSONWriteRule{{
+				Path:        "$",
+				Measurement: "m",
+				Fields:      []platform.JSONWriteValue{{Key: "v", Path: "$.v"}},
+				TimePath:    "$.t",
+			}},
+			`{"v": 1, "t": 1577836800}`,
+			"",
+			"time",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, platform.ValidJSONWriteRules(test.rules))
+			lines, err := JSONToProtocolLines([]byte(test.json), test.rules)
+			if test.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.lines, string(lines))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultWriteSchemaSampleWindow is how long the writes to a bucket are
+	// sampled when no window is given.
+	DefaultWriteSchemaSampleWindow = 10 * time.Second
+	// MaxWriteSchemaSampleWindow is the longest the writes to a bucket may
+	// be sampled for.
+	MaxWriteSchemaSampleWindow = time.Minute
+)
+
+// WriteSchemaSampleService samples the points written to buckets and infers
+// the schema written to them by each producer, to find which client causes
+// a schema or series cardinality problem.
+type WriteSchemaSampleService interface {
+	// SampleWriteSchema samples the points written to the bucket for the
+	// window, and returns the schema inferred from them once the window has
+	// passed.
+	SampleWriteSchema(ctx context.Context, orgID, bucketID ID, window time.Duration) (*WriteSchemaSample, error)
+}
+
+// WriteSchemaSample is the schema inferred from the points written to a
+// bucket within a window.
+type WriteSchemaSample struct {
+	OrgID    ID        `json:"orgID"`
+	BucketID ID        `json:"bucketID"`
+	Start    time.Time `json:"start"`
+	Window   Duration  `json:"window"`
+	// LineN is the number of lines written.
+	LineN int64 `json:"lineN"`
+	// Truncated is whether more tag values or series were written than are
+	// counted, in which case their numbers are lower bounds.
+	Truncated bool `json:"truncated"`
+	// Measurements are the measurements written, sorted by name.
+	Measurements []MeasurementSchemaSample `json:"measurements"`
+	// Producers are the producers which wrote to the bucket, those writing
+	// the most lines first.
+	Producers []WriteProducerSample `json:"producers"`
+}
+
+// MeasurementSchemaSample is the schema of a measurement inferred from the
+// points written to it.
+type MeasurementSchemaSample struct {
+	Name  string `json:"name"`
+	LineN int64  `json:"lineN"`
+	// Tags are the tag keys written, the keys with the most values first.
+	Tags []TagKeySample `json:"tags"`
+	// Fields are the fields written, sorted by name.
+	Fields []FieldSample `json:"fields"`
+}
+
+// TagKeySample is a tag key of a measurement and the number of its values
+// written.
+type TagKeySample struct {
+	Key    string `json:"key"`
+	ValueN int64  `json:"valueN"`
+}
+
+// FieldSample is a field of a measurement and the types of its values
+// written. A field with more than one type conflicts.
+type FieldSample struct {
+	Name string `json:"name"`
+	// Types are sorted by name.
+	Types []FieldType `json:"types"`
+}
+
+// WriteProducerSample is what a producer wrote to a bucket. A producer is
+// the token, or session, the points were written with; points written
+// without one, such as by the statsd listener, are of a producer without an
+// authorization ID.
+type WriteProducerSample struct {
+	AuthorizationID ID     `json:"authorizationID,omitempty"`
+	Kind            string `json:"kind,omitempty"`
+	UserID          ID     `json:"userID,omitempty"`
+	LineN           int64  `json:"lineN"`
+	// LinesPerSecond is the rate of the lines written over the window.
+	LinesPerSecond float64 `json:"linesPerSecond"`
+	// SeriesN is the number of series written.
+	SeriesN int64 `json:"seriesN"`
+	// Measurements are the names of the measurements written, sorted.
+	Measurements []string `json:"measurements"`
+}
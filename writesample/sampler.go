@@ -0,0 +1,420 @@
+// Package writesample samples the points written to buckets on request and
+// infers the schema written to them by each producer, so that operators can
+// find which client writes conflicting field types or too many series.
+//
+// Points are only inspected while a bucket is being sampled, so sampling
+// costs nothing otherwise.
+package writesample
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+// maxCounted is the number of measurements, of fields and tag keys of each
+// measurement, of values of each tag key and of series of each producer
+// counted by a sample, so that sampling a bucket written with unbounded
+// series uses bounded memory.
+const maxCounted = 10000
+
+// fieldKeyTag separates the field of an exploded point from its series in
+// its key.
+var fieldKeyTag = []byte("," + models.FieldKeyTagKey + "=")
+
+var _ influxdb.WriteSchemaSampleService = (*Sampler)(nil)
+
+// Sampler samples the points written to buckets.
+type Sampler struct {
+	log *zap.Logger
+	now func() time.Time
+
+	// active is the number of samples being taken, accessed atomically so
+	// that writes are not slowed while no bucket is sampled.
+	active int32
+
+	mu       sync.RWMutex
+	sessions map[influxdb.ID][]*session
+}
+
+// NewSampler returns a Sampler.
+func NewSampler(log *zap.Logger) *Sampler {
+	return &Sampler{
+		log:      log,
+		now:      time.Now,
+		sessions: make(map[influxdb.ID][]*session),
+	}
+}
+
+// SampleWriteSchema samples the points written to the bucket for the window.
+// The bucket may be sampled by more than one request at a time.
+func (s *Sampler) SampleWriteSchema(ctx context.Context, orgID, bucketID influxdb.ID, window time.Duration) (*influxdb.WriteSchemaSample, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if window <= 0 || window > influxdb.MaxWriteSchemaSampleWindow {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("window must be greater than 0 and at most %s", influxdb.MaxWriteSchemaSampleWindow),
+		}
+	}
+
+	sess := newSession(orgID, s.now())
+	s.start(bucketID, sess)
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		s.stop(bucketID, sess)
+		return nil, ctx.Err()
+	}
+	s.stop(bucketID, sess)
+
+	s.log.Debug("Sampled write schema",
+		zap.Stringer("bucket_id", bucketID),
+		zap.Duration("window", window),
+		zap.Int64("lines", sess.lineN))
+	return sess.sample(bucketID, window), nil
+}
+
+func (s *Sampler) start(bucketID influxdb.ID, sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[bucketID] = append(s.sessions[bucketID], sess)
+	atomic.AddInt32(&s.active, 1)
+}
+
+func (s *Sampler) stop(bucketID influxdb.ID, sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := s.sessions[bucketID]
+	for i, o := range sessions {
+		if o == sess {
+			sessions = append(sessions[:i:i], sessions[i+1:]...)
+			break
+		}
+	}
+	if len(sessions) == 0 {
+		delete(s.sessions, bucketID)
+	} else {
+		s.sessions[bucketID] = sessions
+	}
+	atomic.AddInt32(&s.active, -1)
+}
+
+// Record samples points, which are exploded points named by the encoded IDs
+// of their organization and bucket, written by the producer authorized in
+// ctx. The points of a line are consecutive, as they are when parsed.
+func (s *Sampler) Record(ctx context.Context, points []models.Point) {
+	if atomic.LoadInt32(&s.active) == 0 {
+		return
+	}
+	p := producerOf(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var (
+		prevSeries  []byte
+		prevTime    int64
+		measurement string
+	)
+	for _, pt := range points {
+		name := pt.Name()
+		// The names of buckets are the 16 bytes of the IDs of their
+		// organization and their own.
+		if len(name) != 16 {
+			continue
+		}
+		orgID, bucketID := tsdb.DecodeNameSlice(name)
+		sessions := s.sessions[bucketID]
+
+		key := pt.Key()
+		series := key
+		if i := bytes.LastIndex(key, fieldKeyTag); i >= 0 {
+			series = key[:i]
+		}
+		t := pt.UnixNano()
+		newLine := prevSeries == nil || t != prevTime || !bytes.Equal(series, prevSeries)
+		prevSeries, prevTime = series, t
+		if len(sessions) == 0 {
+			continue
+		}
+
+		var tags models.Tags
+		if newLine {
+			tags = pt.Tags()
+			measurement = string(tags.Get(models.MeasurementTagKeyBytes))
+		}
+		itr := pt.FieldIterator()
+		if !itr.Next() {
+			continue
+		}
+		field, typ := string(itr.FieldKey()), fieldType(itr.Type())
+
+		for _, sess := range sessions {
+			if sess.orgID != orgID {
+				continue
+			}
+			sess.mu.Lock()
+			if newLine {
+				sess.addLine(p, measurement, series, tags)
+			}
+			sess.addField(measurement, field, typ)
+			sess.mu.Unlock()
+		}
+	}
+}
+
+// producer is who wrote points.
+type producer struct {
+	id     influxdb.ID
+	kind   string
+	userID influxdb.ID
+}
+
+// producerOf returns the producer authorized in ctx, or no producer if none
+// is.
+func producerOf(ctx context.Context) producer {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return producer{}
+	}
+	return producer{id: a.Identifier(), kind: a.Kind(), userID: a.GetUserID()}
+}
+
+func fieldType(t models.FieldType) influxdb.FieldType {
+	switch t {
+	case models.Float:
+		return influxdb.FieldTypeFloat
+	case models.Integer:
+		return influxdb.FieldTypeInteger
+	case models.Unsigned:
+		return influxdb.FieldTypeUnsigned
+	case models.String:
+		return influxdb.FieldTypeString
+	case models.Boolean:
+		return influxdb.FieldTypeBoolean
+	}
+	return ""
+}
+
+// session is a sample being taken of the points written to a bucket.
+type session struct {
+	orgID influxdb.ID
+	start time.Time
+
+	mu           sync.Mutex
+	lineN        int64
+	truncated    bool
+	measurements map[string]*measurementSample
+	producers    map[producer]*producerSample
+}
+
+type measurementSample struct {
+	lineN  int64
+	tags   map[string]map[string]struct{}
+	fields map[string]map[influxdb.FieldType]struct{}
+}
+
+type producerSample struct {
+	lineN        int64
+	series       map[string]struct{}
+	measurements map[string]struct{}
+}
+
+func newSession(orgID influxdb.ID, start time.Time) *session {
+	return &session{
+		orgID:        orgID,
+		start:        start,
+		measurements: make(map[string]*measurementSample),
+		producers:    make(map[producer]*producerSample),
+	}
+}
+
+// addLine counts a line of the measurement and series, with the tags, by
+// the producer.
+func (s *session) addLine(p producer, measurement string, series []byte, tags models.Tags) {
+	s.lineN++
+
+	ps, ok := s.producers[p]
+	if !ok {
+		ps = &producerSample{
+			series:       make(map[string]struct{}),
+			measurements: make(map[string]struct{}),
+		}
+		s.producers[p] = ps
+	}
+	ps.lineN++
+	ps.measurements[measurement] = struct{}{}
+	if _, ok := ps.series[string(series)]; !ok {
+		if len(ps.series) < maxCounted {
+			ps.series[string(series)] = struct{}{}
+		} else {
+			s.truncated = true
+		}
+	}
+
+	ms, ok := s.measurements[measurement]
+	if !ok {
+		if len(s.measurements) >= maxCounted {
+			s.truncated = true
+			return
+		}
+		ms = &measurementSample{
+			tags:   make(map[string]map[string]struct{}),
+			fields: make(map[string]map[influxdb.FieldType]struct{}),
+		}
+		s.measurements[measurement] = ms
+	}
+	ms.lineN++
+	for _, tag := range tags {
+		k := string(tag.Key)
+		if k == models.MeasurementTagKey || k == models.FieldKeyTagKey {
+			continue
+		}
+		values, ok := ms.tags[k]
+		if !ok {
+			if len(ms.tags) >= maxCounted {
+				s.truncated = true
+				continue
+			}
+			values = make(map[string]struct{})
+			ms.tags[k] = values
+		}
+		if _, ok := values[string(tag.Value)]; !ok {
+			if len(values) < maxCounted {
+				values[string(tag.Value)] = struct{}{}
+			} else {
+				s.truncated = true
+			}
+		}
+	}
+}
+
+// addField counts the type of a value of the field of the measurement.
+func (s *session) addField(measurement, field string, typ influxdb.FieldType) {
+	ms, ok := s.measurements[measurement]
+	if !ok {
+		return
+	}
+	types, ok := ms.fields[field]
+	if !ok {
+		if len(ms.fields) >= maxCounted {
+			s.truncated = true
+			return
+		}
+		types = make(map[influxdb.FieldType]struct{})
+		ms.fields[field] = types
+	}
+	types[typ] = struct{}{}
+}
+
+// sample returns the schema sampled by s of the bucket over the window.
+func (s *session) sample(bucketID influxdb.ID, window time.Duration) *influxdb.WriteSchemaSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &influxdb.WriteSchemaSample{
+		OrgID:        s.orgID,
+		BucketID:     bucketID,
+		Start:        s.start.UTC(),
+		Window:       influxdb.Duration{Duration: window},
+		LineN:        s.lineN,
+		Truncated:    s.truncated,
+		Measurements: make([]influxdb.MeasurementSchemaSample, 0, len(s.measurements)),
+		Producers:    make([]influxdb.WriteProducerSample, 0, len(s.producers)),
+	}
+
+	for name, ms := range s.measurements {
+		m := influxdb.MeasurementSchemaSample{
+			Name:   name,
+			LineN:  ms.lineN,
+			Tags:   make([]influxdb.TagKeySample, 0, len(ms.tags)),
+			Fields: make([]influxdb.FieldSample, 0, len(ms.fields)),
+		}
+		for k, values := range ms.tags {
+			m.Tags = append(m.Tags, influxdb.TagKeySample{Key: k, ValueN: int64(len(values))})
+		}
+		sort.Slice(m.Tags, func(i, j int) bool {
+			if m.Tags[i].ValueN != m.Tags[j].ValueN {
+				return m.Tags[i].ValueN > m.Tags[j].ValueN
+			}
+			return m.Tags[i].Key < m.Tags[j].Key
+		})
+		for f, types := range ms.fields {
+			fs := influxdb.FieldSample{Name: f, Types: make([]influxdb.FieldType, 0, len(types))}
+			for t := range types {
+				fs.Types = append(fs.Types, t)
+			}
+			sort.Slice(fs.Types, func(i, j int) bool { return fs.Types[i] < fs.Types[j] })
+			m.Fields = append(m.Fields, fs)
+		}
+		sort.Slice(m.Fields, func(i, j int) bool { return m.Fields[i].Name < m.Fields[j].Name })
+		res.Measurements = append(res.Measurements, m)
+	}
+	sort.Slice(res.Measurements, func(i, j int) bool {
+		return res.Measurements[i].Name < res.Measurements[j].Name
+	})
+
+	for p, ps := range s.producers {
+		w := influxdb.WriteProducerSample{
+			AuthorizationID: p.id,
+			Kind:            p.kind,
+			UserID:          p.userID,
+			LineN:           ps.lineN,
+			LinesPerSecond:  float64(ps.lineN) / window.Seconds(),
+			SeriesN:         int64(len(ps.series)),
+			Measurements:    make([]string, 0, len(ps.measurements)),
+		}
+		for m := range ps.measurements {
+			w.Measurements = append(w.Measurements, m)
+		}
+		sort.Strings(w.Measurements)
+		res.Producers = append(res.Producers, w)
+	}
+	sort.Slice(res.Producers, func(i, j int) bool {
+		if res.Producers[i].LineN != res.Producers[j].LineN {
+			return res.Producers[i].LineN > res.Producers[j].LineN
+		}
+		return res.Producers[i].AuthorizationID < res.Producers[j].AuthorizationID
+	})
+	return res
+}
+
+// PointsWriter samples the points it writes with another writer.
+type PointsWriter struct {
+	w       storage.PointsWriter
+	sampler *Sampler
+}
+
+// NewPointsWriter returns a PointsWriter writing with w and sampling the
+// points with sampler.
+func NewPointsWriter(w storage.PointsWriter, sampler *Sampler) *PointsWriter {
+	return &PointsWriter{
+		w:       w,
+		sampler: sampler,
+	}
+}
+
+// WritePoints samples points, then writes them. The points are sampled
+// before they are written so that rejected writes, such as those conflicting
+// with the types of fields, are sampled too.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	w.sampler.Record(ctx, points)
+	return w.w.WritePoints(ctx, points)
+}
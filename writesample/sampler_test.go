@@ -0,0 +1,106 @@
+package writesample
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSampler_SampleWriteSchema(t *testing.T) {
+	const orgID, bucketID, otherID = influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+
+	s := NewSampler(zaptest.NewLogger(t))
+	w := NewPointsWriter(&mock.PointsWriter{}, s)
+
+	write := func(auth *influxdb.Authorization, bucketID influxdb.ID, lines string) {
+		t.Helper()
+		encoded := tsdb.EncodeName(orgID, bucketID)
+		points, err := models.ParsePointsWithOptions([]byte(lines), models.EscapeMeasurement(encoded[:]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WritePoints(icontext.SetAuthorizer(context.Background(), auth), points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	agent := &influxdb.Authorization{ID: 10, UserID: 20}
+	script := &influxdb.Authorization{ID: 11, UserID: 20}
+
+	// Writes are not sampled until sampling starts.
+	write(agent, bucketID, "cpu,host=z usage=1 1")
+
+	type result struct {
+		sample *influxdb.WriteSchemaSample
+		err    error
+	}
+	done := make(chan result)
+	go func() {
+		sample, err := s.SampleWriteSchema(context.Background(), orgID, bucketID, 100*time.Millisecond)
+		done <- result{sample, err}
+	}()
+	for atomic.LoadInt32(&s.active) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	write(agent, bucketID, "cpu,host=a usage=1,idle=2 1\ncpu,host=b usage=1i 2")
+	write(script, bucketID, "mem,host=a free=1 1")
+	write(script, otherID, "disk,host=a free=1 1")
+
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if n := atomic.LoadInt32(&s.active); n != 0 {
+		t.Errorf("expected sampling to stop, got %d samples", n)
+	}
+
+	sample := res.sample
+	if sample.LineN != 3 || sample.Truncated {
+		t.Errorf("expected 3 lines without truncation, got %d lines, truncated %v", sample.LineN, sample.Truncated)
+	}
+	wantMeasurements := []influxdb.MeasurementSchemaSample{
+		{
+			Name:  "cpu",
+			LineN: 2,
+			Tags:  []influxdb.TagKeySample{{Key: "host", ValueN: 2}},
+			Fields: []influxdb.FieldSample{
+				{Name: "idle", Types: []influxdb.FieldType{influxdb.FieldTypeFloat}},
+				{Name: "usage", Types: []influxdb.FieldType{influxdb.FieldTypeFloat, influxdb.FieldTypeInteger}},
+			},
+		},
+		{
+			Name:   "mem",
+			LineN:  1,
+			Tags:   []influxdb.TagKeySample{{Key: "host", ValueN: 1}},
+			Fields: []influxdb.FieldSample{{Name: "free", Types: []influxdb.FieldType{influxdb.FieldTypeFloat}}},
+		},
+	}
+	if !reflect.DeepEqual(sample.Measurements, wantMeasurements) {
+		t.Errorf("unexpected measurements:\ngot  %+v\nwant %+v", sample.Measurements, wantMeasurements)
+	}
+	wantProducers := []influxdb.WriteProducerSample{
+		{AuthorizationID: 10, Kind: influxdb.AuthorizationKind, UserID: 20, LineN: 2, LinesPerSecond: 20, SeriesN: 2, Measurements: []string{"cpu"}},
+		{AuthorizationID: 11, Kind: influxdb.AuthorizationKind, UserID: 20, LineN: 1, LinesPerSecond: 10, SeriesN: 1, Measurements: []string{"mem"}},
+	}
+	if !reflect.DeepEqual(sample.Producers, wantProducers) {
+		t.Errorf("unexpected producers:\ngot  %+v\nwant %+v", sample.Producers, wantProducers)
+	}
+}
+
+func TestSampler_SampleWriteSchemaWindow(t *testing.T) {
+	s := NewSampler(zaptest.NewLogger(t))
+	for _, window := range []time.Duration{0, influxdb.MaxWriteSchemaSampleWindow + time.Second} {
+		if _, err := s.SampleWriteSchema(context.Background(), 1, 2, window); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("expected a window of %s to be invalid, got %v", window, err)
+		}
+	}
+}